@@ -0,0 +1,163 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// hedgedMethods holds the read methods of the Trillian log and map services,
+// which HedgedConn hedges.
+var hedgedMethods = map[string]bool{
+	"/trillian.TrillianLog/GetConsistencyProof":        true,
+	"/trillian.TrillianLog/GetEntryAndProof":           true,
+	"/trillian.TrillianLog/GetInclusionProof":          true,
+	"/trillian.TrillianLog/GetInclusionProofByHash":    true,
+	"/trillian.TrillianLog/GetInclusionProofs":         true,
+	"/trillian.TrillianLog/GetLatestSignedLogRoot":     true,
+	"/trillian.TrillianLog/GetLeavesByHash":            true,
+	"/trillian.TrillianLog/GetLeavesByIndex":           true,
+	"/trillian.TrillianLog/GetLeavesByRange":           true,
+	"/trillian.TrillianLog/GetSequencedLeafCount":      true,
+	"/trillian.TrillianMap/GetLastInRangeByRevision":   true,
+	"/trillian.TrillianMap/GetLeaf":                    true,
+	"/trillian.TrillianMap/GetLeafByRevision":          true,
+	"/trillian.TrillianMap/GetLeaves":                  true,
+	"/trillian.TrillianMap/GetLeavesByRevision":        true,
+	"/trillian.TrillianMap/GetLeavesByRevisionNoProof": true,
+	"/trillian.TrillianMap/GetLeavesByRevisions":       true,
+	"/trillian.TrillianMap/GetLeavesCompact":           true,
+	"/trillian.TrillianMap/GetSignedMapRoot":           true,
+	"/trillian.TrillianMap/GetSignedMapRootByRevision": true,
+}
+
+// HedgedConn is a connection which sends the read RPCs of the Trillian log and
+// map services to a list of read endpoints, such as replica servers in other
+// regions, hedging them to bound their tail latency: an RPC is sent to the
+// first endpoint, and also to the next one whenever the delay passes without a
+// response, or an endpoint fails. The first response to succeed is returned,
+// and the RPCs sent to other endpoints are cancelled. Other RPCs are sent to
+// the main connection.
+//
+// LogClient and MapClient hedge their reads when given clients on a
+// HedgedConn, e.g.:
+//
+//   conn := client.NewHedgedConn(primary, []grpc.ClientConnInterface{primary, replica}, 50*time.Millisecond)
+//   logClient := client.New(logID, trillian.NewTrillianLogClient(conn), verifier, root)
+//
+// Replicas may lag behind the main servers, which the clients already allow
+// for, as they verify responses against the roots they trust.
+type HedgedConn struct {
+	conn  grpc.ClientConnInterface
+	reads []grpc.ClientConnInterface
+	delay time.Duration
+}
+
+var _ grpc.ClientConnInterface = (*HedgedConn)(nil)
+
+// NewHedgedConn returns a HedgedConn which sends writes to conn, and reads to
+// the given read endpoints, in order, waiting delay for a response before
+// sending reads to the next one. If there are no read endpoints, reads are
+// sent to conn.
+func NewHedgedConn(conn grpc.ClientConnInterface, reads []grpc.ClientConnInterface, delay time.Duration) *HedgedConn {
+	return &HedgedConn{conn: conn, reads: reads, delay: delay}
+}
+
+// Invoke sends a unary RPC, hedged if it's a read.
+func (h *HedgedConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	msg, ok := reply.(proto.Message)
+	if !hedgedMethods[method] || len(h.reads) == 0 || !ok {
+		return h.conn.Invoke(ctx, method, args, reply, opts...)
+	}
+
+	// Cancel the RPCs which are still in flight once one succeeds.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		reply proto.Message
+		err   error
+	}
+	results := make(chan result, len(h.reads))
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+	next, pending := 0, 0
+	send := func() {
+		conn, r := h.reads[next], proto.Clone(msg)
+		r.Reset()
+		go func() {
+			results <- result{reply: r, err: conn.Invoke(ctx, method, args, r, opts...)}
+		}()
+		next++
+		pending++
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if next < len(h.reads) {
+			timer.Reset(h.delay)
+		}
+	}
+
+	send()
+	var firstErr error
+	for pending > 0 {
+		select {
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				msg.Reset()
+				proto.Merge(msg, res.reply)
+				return nil
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if !retryElsewhere(res.err) {
+				return res.err
+			}
+			if next < len(h.reads) {
+				send()
+			}
+		case <-timer.C:
+			send()
+		}
+	}
+	return firstErr
+}
+
+// retryElsewhere returns whether a read which failed with err may succeed if
+// sent to another endpoint.
+func retryElsewhere(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.PermissionDenied, codes.Unauthenticated, codes.Unimplemented:
+		return false
+	}
+	return true
+}
+
+// NewStream opens a streaming RPC on the main connection, as streaming RPCs
+// aren't hedged.
+func (h *HedgedConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return h.conn.NewStream(ctx, desc, method, opts...)
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeConn is a connection which responds to RPCs with a sequenced leaf count
+// of its id, after its delay, or fails them with its error.
+type fakeConn struct {
+	id    int64
+	delay time.Duration
+	err   error
+
+	mu        sync.Mutex
+	calls     int
+	cancelled int
+}
+
+func (c *fakeConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	select {
+	case <-time.After(c.delay):
+	case <-ctx.Done():
+		c.mu.Lock()
+		c.cancelled++
+		c.mu.Unlock()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if c.err != nil {
+		return c.err
+	}
+	switch reply := reply.(type) {
+	case *trillian.GetSequencedLeafCountResponse:
+		reply.LeafCount = c.id
+	case *trillian.QueueLeafResponse:
+		reply.QueuedLeaf = &trillian.QueuedLogLeaf{Leaf: &trillian.LogLeaf{LeafIndex: c.id}}
+	}
+	return nil
+}
+
+func (c *fakeConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Error(codes.Unimplemented, "no streams")
+}
+
+// counts returns the numbers of RPCs sent to c, and of those cancelled.
+func (c *fakeConn) counts() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls, c.cancelled
+}
+
+func TestHedgedConn(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	for _, test := range []struct {
+		desc     string
+		conns    []*fakeConn
+		want     int64
+		wantCode codes.Code
+		// wantCalls holds the numbers of RPCs wanted on each connection.
+		wantCalls []int
+	}{
+		{
+			desc:      "fast",
+			conns:     []*fakeConn{{id: 1}, {id: 2}},
+			want:      1,
+			wantCalls: []int{1, 0},
+		},
+		{
+			desc:      "slow",
+			conns:     []*fakeConn{{id: 1, delay: time.Minute}, {id: 2}},
+			want:      2,
+			wantCalls: []int{1, 1},
+		},
+		{
+			desc:      "all-slow",
+			conns:     []*fakeConn{{id: 1, delay: time.Minute}, {id: 2, delay: time.Minute}, {id: 3, delay: 2 * delay}},
+			want:      3,
+			wantCalls: []int{1, 1, 1},
+		},
+		{
+			desc:      "unavailable",
+			conns:     []*fakeConn{{id: 1, err: unavailable}, {id: 2, delay: delay / 2}},
+			want:      2,
+			wantCalls: []int{1, 1},
+		},
+		{
+			desc:      "invalid",
+			conns:     []*fakeConn{{id: 1, err: status.Error(codes.InvalidArgument, "bad")}, {id: 2}},
+			wantCode:  codes.InvalidArgument,
+			wantCalls: []int{1, 0},
+		},
+		{
+			desc:      "all-unavailable",
+			conns:     []*fakeConn{{id: 1, err: unavailable}, {id: 2, err: status.Error(codes.Internal, "broken")}},
+			wantCode:  codes.Unavailable,
+			wantCalls: []int{1, 1},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			main := &fakeConn{id: 100}
+			reads := make([]grpc.ClientConnInterface, 0, len(test.conns))
+			for _, c := range test.conns {
+				reads = append(reads, c)
+			}
+			client := trillian.NewTrillianLogClient(NewHedgedConn(main, reads, delay))
+
+			resp, err := client.GetSequencedLeafCount(context.Background(), &trillian.GetSequencedLeafCountRequest{LogId: 1})
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("GetSequencedLeafCount(): %v, want code %v", err, test.wantCode)
+			}
+			if err == nil && resp.LeafCount != test.want {
+				t.Errorf("GetSequencedLeafCount(): response of %d, want %d", resp.LeafCount, test.want)
+			}
+			for i, c := range test.conns {
+				// RPCs still in flight are cancelled.
+				deadline := time.Now().Add(5 * time.Second)
+				calls, cancelled := c.counts()
+				for c.delay == time.Minute && cancelled < calls && time.Now().Before(deadline) {
+					time.Sleep(time.Millisecond)
+					calls, cancelled = c.counts()
+				}
+				if calls != test.wantCalls[i] {
+					t.Errorf("conns[%d]: %d RPCs, want %d", i, calls, test.wantCalls[i])
+				}
+				if c.delay == time.Minute && cancelled != calls {
+					t.Errorf("conns[%d]: %d of %d RPCs cancelled, want all", i, cancelled, calls)
+				}
+			}
+			if calls, _ := main.counts(); calls != 0 {
+				t.Errorf("main conn: %d RPCs, want none", calls)
+			}
+
+			// Writes go to the main connection.
+			qresp, err := client.QueueLeaf(context.Background(), &trillian.QueueLeafRequest{LogId: 1})
+			if err != nil {
+				t.Fatalf("QueueLeaf(): %v", err)
+			}
+			if want := (&trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: &trillian.LogLeaf{LeafIndex: 100}}}); !proto.Equal(qresp, want) {
+				t.Errorf("QueueLeaf(): %v, want %v", qresp, want)
+			}
+		})
+	}
+}
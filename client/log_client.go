@@ -101,28 +101,37 @@ func (c *LogClient) GetByIndex(ctx context.Context, index int64) (*trillian.LogL
 	return resp.Leaves[0], nil
 }
 
-// ListByIndex returns the requested leaves by index.
+// ListByIndex returns the requested leaves by index. It follows the page
+// tokens of responses which the server truncated to fit its size limit.
 func (c *LogClient) ListByIndex(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
-	resp, err := c.client.GetLeavesByRange(ctx,
-		&trillian.GetLeavesByRangeRequest{
-			LogId:      c.LogID,
-			StartIndex: start,
-			Count:      count,
-		})
-	if err != nil {
-		return nil, err
+	req := &trillian.GetLeavesByRangeRequest{
+		LogId:      c.LogID,
+		StartIndex: start,
+		Count:      count,
+	}
+	var leaves []*trillian.LogLeaf
+	for {
+		resp, err := c.client.GetLeavesByRange(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, resp.Leaves...)
+		if len(resp.NextPageToken) == 0 {
+			break
+		}
+		req.PageToken = resp.NextPageToken
 	}
 	// Verify that we got back the requested leaves.
-	if len(resp.Leaves) < int(count) {
-		return nil, fmt.Errorf("len(Leaves)=%d, want %d", len(resp.Leaves), count)
+	if len(leaves) < int(count) {
+		return nil, fmt.Errorf("len(Leaves)=%d, want %d", len(leaves), count)
 	}
-	for i, l := range resp.Leaves {
+	for i, l := range leaves {
 		if want := start + int64(i); l.LeafIndex != want {
 			return nil, fmt.Errorf("Leaves[%d].LeafIndex=%d, want %d", i, l.LeafIndex, want)
 		}
 	}
 
-	return resp.Leaves, nil
+	return leaves, nil
 }
 
 // WaitForRootUpdate repeatedly fetches the latest root until there is an
@@ -332,6 +341,51 @@ func (c *LogClient) GetAndVerifyInclusionAtIndex(ctx context.Context, data []byt
 	return c.VerifyInclusionAtIndex(sth, data, index, resp.Proof.Hashes)
 }
 
+// GetAndVerifyInclusionAtIndices ensures that the given leaf data have been
+// included in the log at the indices they are keyed by, fetching all the
+// proofs in a single request.
+func (c *LogClient) GetAndVerifyInclusionAtIndices(ctx context.Context, dataByIndex map[int64][]byte, sth *types.LogRootV1) error {
+	indices := make([]int64, 0, len(dataByIndex))
+	for index := range dataByIndex {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(a, b int) bool { return indices[a] < indices[b] })
+
+	resp, err := c.client.GetInclusionProofs(ctx,
+		&trillian.GetInclusionProofsRequest{
+			LogId:     c.LogID,
+			LeafIndex: indices,
+			TreeSize:  int64(sth.TreeSize),
+		})
+	if err != nil {
+		return err
+	}
+
+	// As with GetAndVerifyInclusionAtIndex, an out-of-date server instance
+	// returns no proofs and an earlier SLR.
+	var proofRoot types.LogRootV1
+	if err := proofRoot.UnmarshalBinary(resp.GetSignedLogRoot().GetLogRoot()); err != nil {
+		return err
+	}
+	if proofRoot.TreeSize < sth.TreeSize {
+		return fmt.Errorf("response for InclusionProofs has a smaller (%d) root than requested (%d)", proofRoot.TreeSize, sth.TreeSize)
+	}
+	if got, want := len(resp.Proof), len(indices); got != want {
+		return fmt.Errorf("got %d proofs, want %d", got, want)
+	}
+
+	for i, index := range indices {
+		proof := resp.Proof[i]
+		if proof.GetLeafIndex() != index {
+			return fmt.Errorf("got proof for leaf %d, want %d", proof.GetLeafIndex(), index)
+		}
+		if err := c.VerifyInclusionAtIndex(sth, dataByIndex[index], index, proof.Hashes); err != nil {
+			return fmt.Errorf("VerifyInclusionAtIndex(%d): %v", index, err)
+		}
+	}
+	return nil
+}
+
 func (c *LogClient) getAndVerifyInclusionProof(ctx context.Context, leafHash []byte, sth *types.LogRootV1) (bool, error) {
 	resp, err := c.client.GetInclusionProofByHash(ctx,
 		&trillian.GetInclusionProofByHashRequest{
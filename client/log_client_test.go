@@ -178,6 +178,61 @@ func TestVerifyInclusionAtIndex(t *testing.T) {
 	}
 }
 
+func TestVerifyInclusionAtIndices(t *testing.T) {
+	ctx := context.Background()
+	env, client := clientEnvForTest(ctx, t, stestonly.PreorderedLogTree)
+	defer env.Close()
+
+	leafData := [][]byte{[]byte("A"), []byte("B"), []byte("C")}
+	if err := addSequencedLeaves(ctx, env, client, leafData); err != nil {
+		t.Fatalf("Failed to add leaves: %v", err)
+	}
+
+	root := client.GetRoot()
+	dataByIndex := map[int64][]byte{0: leafData[0], 2: leafData[2]}
+	if err := client.GetAndVerifyInclusionAtIndices(ctx, dataByIndex, root); err != nil {
+		t.Errorf("GetAndVerifyInclusionAtIndices() = %v, want nil", err)
+	}
+
+	// The data must match the leaves at the given indices.
+	dataByIndex[2] = leafData[1]
+	if err := client.GetAndVerifyInclusionAtIndices(ctx, dataByIndex, root); err == nil {
+		t.Error("GetAndVerifyInclusionAtIndices() with the wrong data = nil, want error")
+	}
+}
+
+func TestGetProofBundle(t *testing.T) {
+	ctx := context.Background()
+	env, client := clientEnvForTest(ctx, t, stestonly.PreorderedLogTree)
+	defer env.Close()
+
+	leafData := [][]byte{[]byte("A"), []byte("B"), []byte("C")}
+	if err := addSequencedLeaves(ctx, env, client, leafData); err != nil {
+		t.Fatalf("Failed to add leaves: %v", err)
+	}
+
+	for i, l := range leafData {
+		bundle, err := client.GetProofBundle(ctx, int64(i))
+		if err != nil {
+			t.Fatalf("GetProofBundle(%d): %v", i, err)
+		}
+		if !bytes.Equal(bundle.Leaf.LeafValue, l) {
+			t.Errorf("GetProofBundle(%d) returned leaf %s, want %s", i, bundle.Leaf.LeafValue, l)
+		}
+		v, err := NewLogVerifierFromBundle(bundle, client.PubKey)
+		if err != nil {
+			t.Fatalf("NewLogVerifierFromBundle(): %v", err)
+		}
+		if _, err := v.VerifyProofBundle(bundle); err != nil {
+			t.Errorf("VerifyProofBundle(%d): %v", i, err)
+		}
+	}
+
+	if _, err := client.GetProofBundle(ctx, int64(len(leafData))); err == nil {
+		t.Error("GetProofBundle() for a leaf beyond the tree returned no error")
+	}
+}
+
 func TestWaitForInclusion(t *testing.T) {
 	ctx := context.Background()
 	tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
@@ -41,6 +41,9 @@ type LogVerifier struct {
 	// SigHash computes the digest of LogRoot for signing.
 	SigHash crypto.Hash
 	v       logverifier.LogVerifier
+	// hashStrategy identifies Hasher in proof bundles. It's only known for
+	// verifiers created from a tree config or a proof bundle.
+	hashStrategy trillian.HashStrategy
 }
 
 // NewLogVerifier returns an object that can verify output from Trillian Logs.
@@ -79,7 +82,9 @@ func NewLogVerifierFromTree(config *trillian.Tree) (*LogVerifier, error) {
 		return nil, fmt.Errorf("client: NewLogVerifierFromTree(): Failed parsing Log signature hash: %v", err)
 	}
 
-	return NewLogVerifier(logHasher, logPubKey, sigHash), nil
+	v := NewLogVerifier(logHasher, logPubKey, sigHash)
+	v.hashStrategy = config.HashStrategy
+	return v, nil
 }
 
 // VerifyRoot verifies that newRoot is a valid append-only operation from
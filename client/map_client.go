@@ -18,8 +18,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
 	"github.com/google/trillian"
 	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -28,6 +30,9 @@ type MapClient struct {
 	*MapVerifier
 	MapID int64
 	Conn  trillian.TrillianMapClient
+	// CompactProofs requests inclusion proofs in compact form, which omits
+	// their empty entries.
+	CompactProofs bool
 }
 
 // NewMapClientFromTree returns a verifying Map client for the specified tree.
@@ -73,9 +78,14 @@ func (c *MapClient) GetAndVerifyMapRootByRevision(ctx context.Context, revision
 // GetAndVerifyMapLeaves verifies and returns the requested map leaves.
 // indexes may not contain duplicates.
 func (c *MapClient) GetAndVerifyMapLeaves(ctx context.Context, indexes [][]byte) ([]*trillian.MapLeaf, *types.MapRootV1, error) {
-	getResp, err := c.Conn.GetLeaves(ctx, &trillian.GetMapLeavesRequest{
-		MapId: c.MapID,
-		Index: indexes,
+	req := &trillian.GetMapLeavesRequest{
+		MapId:         c.MapID,
+		Index:         indexes,
+		CompactProofs: c.CompactProofs,
+	}
+	getResp, err := getAllMapLeaves(func(pageToken []byte) (*trillian.GetMapLeavesResponse, error) {
+		req.PageToken = pageToken
+		return c.Conn.GetLeaves(ctx, req)
 	})
 	if err != nil {
 		s := status.Convert(err)
@@ -87,10 +97,15 @@ func (c *MapClient) GetAndVerifyMapLeaves(ctx context.Context, indexes [][]byte)
 // GetAndVerifyMapLeavesByRevision verifies and returns the requested map leaves at a specific revision.
 // indexes may not contain duplicates.
 func (c *MapClient) GetAndVerifyMapLeavesByRevision(ctx context.Context, revision int64, indexes [][]byte) ([]*trillian.MapLeaf, *types.MapRootV1, error) {
-	getResp, err := c.Conn.GetLeavesByRevision(ctx, &trillian.GetMapLeavesByRevisionRequest{
-		MapId:    c.MapID,
-		Index:    indexes,
-		Revision: revision,
+	req := &trillian.GetMapLeavesByRevisionRequest{
+		MapId:         c.MapID,
+		Index:         indexes,
+		Revision:      revision,
+		CompactProofs: c.CompactProofs,
+	}
+	getResp, err := getAllMapLeaves(func(pageToken []byte) (*trillian.GetMapLeavesResponse, error) {
+		req.PageToken = pageToken
+		return c.Conn.GetLeavesByRevision(ctx, req)
 	})
 	if err != nil {
 		s := status.Convert(err)
@@ -98,3 +113,80 @@ func (c *MapClient) GetAndVerifyMapLeavesByRevision(ctx context.Context, revisio
 	}
 	return c.VerifyMapLeavesResponse(indexes, revision, getResp)
 }
+
+// getAllMapLeaves calls get, passing it the page token of the previous
+// response, for as long as the server truncates the responses to fit its size
+// limit, and returns all of the leaves in one response.
+func getAllMapLeaves(get func(pageToken []byte) (*trillian.GetMapLeavesResponse, error)) (*trillian.GetMapLeavesResponse, error) {
+	resp, err := get(nil)
+	if err != nil {
+		return nil, err
+	}
+	for len(resp.NextPageToken) > 0 {
+		page, err := get(resp.NextPageToken)
+		if err != nil {
+			return nil, err
+		}
+		if !proto.Equal(page.MapRoot, resp.MapRoot) {
+			return nil, status.Errorf(codes.Internal, "page of leaves read at a different map root")
+		}
+		resp.MapLeafInclusion = append(resp.MapLeafInclusion, page.MapLeafInclusion...)
+		resp.NextPageToken = page.NextPageToken
+	}
+	return resp, nil
+}
+
+// SetAndVerifyMapLeaves sets leaves in the map at consecutive revisions from
+// revision, in requests of at most maxLeaves leaves each, so that batches too
+// large for one request can still be written, and returns the verified root
+// of the last revision written. Only the last revision is given metadata; the
+// earlier ones keep the metadata of the root before revision, so that a
+// personality which fails partway resumes from where it was before the
+// batch. Readers may see the revisions holding part of the batch. If
+// maxLeaves is not positive, all leaves are set in one request.
+func (c *MapClient) SetAndVerifyMapLeaves(ctx context.Context, leaves []*trillian.MapLeaf, metadata []byte, revision int64, maxLeaves int) (*types.MapRootV1, error) {
+	if maxLeaves <= 0 || len(leaves) <= maxLeaves {
+		return c.setAndVerifyMapLeaves(ctx, leaves, metadata, revision)
+	}
+	prev, err := c.GetAndVerifyMapRootByRevision(ctx, revision-1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		chunk, meta := leaves, metadata
+		if len(leaves) > maxLeaves {
+			chunk, meta = leaves[:maxLeaves], prev.Metadata
+		}
+		leaves = leaves[len(chunk):]
+		root, err := c.setAndVerifyMapLeaves(ctx, chunk, meta, revision)
+		if err != nil || len(leaves) == 0 {
+			return root, err
+		}
+		revision++
+	}
+}
+
+// setAndVerifyMapLeaves sets leaves in the map at revision, and returns the
+// verified root of the revision.
+func (c *MapClient) setAndVerifyMapLeaves(ctx context.Context, leaves []*trillian.MapLeaf, metadata []byte, revision int64) (*types.MapRootV1, error) {
+	// SetLeaves is used rather than the write API, as it returns the signed
+	// root of the revision.
+	resp, err := c.Conn.SetLeaves(ctx, &trillian.SetMapLeavesRequest{ //nolint:staticcheck
+		MapId:    c.MapID,
+		Leaves:   leaves,
+		Metadata: metadata,
+		Revision: revision,
+	})
+	if err != nil {
+		s := status.Convert(err)
+		return nil, status.Errorf(s.Code(), "SetLeaves(%v, %d): %v", c.MapID, revision, s.Message())
+	}
+	root, err := c.VerifySignedMapRoot(resp.GetMapRoot())
+	if err != nil {
+		return nil, fmt.Errorf("SetAndVerifyMapLeaves(%v, %d) failed to verify root: %v", c.MapID, revision, err)
+	}
+	if int64(root.Revision) != revision {
+		return nil, fmt.Errorf("SetAndVerifyMapLeaves(%v, %d): got revision %d", c.MapID, revision, root.Revision)
+	}
+	return root, nil
+}
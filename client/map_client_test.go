@@ -154,12 +154,15 @@ func TestGetLeavesAtRevision(t *testing.T) {
 	for _, tc := range []struct {
 		desc     string
 		indexes  [][]byte
+		compact  bool
 		wantCode codes.Code
 	}{
 		{desc: "1", indexes: [][]byte{index}},
 		{desc: "2", indexes: [][]byte{index, index}, wantCode: codes.InvalidArgument},
+		{desc: "compact", indexes: [][]byte{index}, compact: true},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
+			client.CompactProofs = tc.compact
 			leaves, _, err := client.GetAndVerifyMapLeaves(ctx, tc.indexes)
 			if status.Code(err) != tc.wantCode {
 				t.Fatalf("GetAndVerifyMapLeavesAtRevision(): %v, wantErr %v", err, tc.wantCode)
@@ -179,3 +182,61 @@ func TestGetLeavesAtRevision(t *testing.T) {
 		})
 	}
 }
+
+func TestSetAndVerifyMapLeaves(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+	ctx := context.Background()
+	env, err := integration.NewMapEnv(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+	tree, err := CreateAndInitTree(ctx,
+		&trillian.CreateTreeRequest{Tree: testonly.MapTree},
+		env.Admin, env.Map, nil)
+	if err != nil {
+		t.Fatalf("Failed to create map: %v", err)
+	}
+
+	client, err := NewMapClientFromTree(env.Map, tree)
+	if err != nil {
+		t.Fatalf("NewMapClientFromTree(): %v", err)
+	}
+
+	var leaves []*trillian.MapLeaf
+	var indexes [][]byte
+	for _, c := range "ABCDE" {
+		index := bytes.Repeat([]byte{byte(c)}, 32)
+		indexes = append(indexes, index)
+		leaves = append(leaves, &trillian.MapLeaf{Index: index, LeafValue: []byte{byte(c)}})
+	}
+	root, err := client.SetAndVerifyMapLeaves(ctx, leaves, []byte("done"), 1, 2)
+	if err != nil {
+		t.Fatalf("SetAndVerifyMapLeaves(): %v", err)
+	}
+	// The leaves are set at revisions 1 to 3, only the last of which has the
+	// metadata.
+	if got, want := root.Revision, uint64(3); got != want {
+		t.Errorf("SetAndVerifyMapLeaves(): revision %d, want %d", got, want)
+	}
+	if got, want := root.Metadata, []byte("done"); !bytes.Equal(got, want) {
+		t.Errorf("SetAndVerifyMapLeaves(): metadata %q, want %q", got, want)
+	}
+	partial, err := client.GetAndVerifyMapRootByRevision(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetAndVerifyMapRootByRevision(2): %v", err)
+	}
+	if len(partial.Metadata) != 0 {
+		t.Errorf("GetAndVerifyMapRootByRevision(2): metadata %q, want that of revision 0", partial.Metadata)
+	}
+
+	got, _, err := client.GetAndVerifyMapLeaves(ctx, indexes)
+	if err != nil {
+		t.Fatalf("GetAndVerifyMapLeaves(): %v", err)
+	}
+	for i, leaf := range got {
+		if want := leaves[i].LeafValue; !bytes.Equal(leaf.LeafValue, want) {
+			t.Errorf("LeafValue[%d]: %v, want %v", i, leaf.LeafValue, want)
+		}
+	}
+}
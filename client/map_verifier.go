@@ -20,6 +20,7 @@ import (
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/maps"
+	"github.com/google/trillian/merkle"
 	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/merkle/hashers/registry"
 	"github.com/google/trillian/merkle/mapverifier"
@@ -59,7 +60,7 @@ func NewMapVerifier(config *trillian.Tree, rootVerifier *maps.RootVerifier) (*Ma
 		return nil, fmt.Errorf("client: NewMapVerifierFromTree(): TreeType: %v, want %v", got, want)
 	}
 
-	mapHasher, err := registry.NewMapHasher(config.HashStrategy)
+	mapHasher, err := registry.NewMapHasherForTree(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed creating MapHasher: %v", err)
 	}
@@ -81,8 +82,16 @@ func (m *MapVerifier) VerifyMapLeafInclusion(smr *trillian.SignedMapRoot, leafPr
 }
 
 // VerifyMapLeafInclusionHash verifies a MapLeafInclusion object against a root hash.
+// The inclusion proof may be in compact form.
 func (m *MapVerifier) VerifyMapLeafInclusionHash(rootHash []byte, leafProof *trillian.MapLeafInclusion) error {
-	return mapverifier.VerifyInclusionProof(m.MapID, leafProof.GetLeaf(), rootHash, leafProof.GetInclusion(), m.Hasher)
+	proof := leafProof.GetInclusion()
+	if bitmap := leafProof.GetInclusionBitmap(); len(bitmap) > 0 {
+		var err error
+		if proof, err = merkle.ExpandInclusionProof(bitmap, proof, m.Hasher.BitLen()); err != nil {
+			return fmt.Errorf("invalid compact inclusion proof: %v", err)
+		}
+	}
+	return mapverifier.VerifyInclusionProof(m.MapID, leafProof.GetLeaf(), rootHash, proof, m.Hasher)
 }
 
 // VerifyMapLeavesResponse verifies the responses of GetMapLeaves and GetMapLeavesByRevision.
@@ -0,0 +1,151 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/types"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// PublicKeyHint returns the hint which identifies pubKey in proof bundles,
+// which is the SHA-256 hash of its DER encoding.
+func PublicKeyHint(pubKey crypto.PublicKey) ([]byte, error) {
+	b, err := der.MarshalPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(b)
+	return h[:], nil
+}
+
+// NewLogVerifierFromBundle creates a LogVerifier for the log which issued a
+// proof bundle, using the algorithms recorded in the bundle and the given
+// public key of the log, which must be obtained from a trusted source. It
+// fails if the bundle hints at a different public key.
+func NewLogVerifierFromBundle(bundle *trillian.ProofBundle, pubKey crypto.PublicKey) (*LogVerifier, error) {
+	if bundle == nil {
+		return nil, errors.New("client: NewLogVerifierFromBundle(): nil bundle")
+	}
+	hasher, err := registry.NewLogHasher(bundle.HashStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("client: NewLogVerifierFromBundle(): NewLogHasher(): %v", err)
+	}
+	if bundle.HashAlgorithm != sigpb.DigitallySigned_SHA256 {
+		return nil, fmt.Errorf("client: NewLogVerifierFromBundle(): unexpected hash algorithm: %s", bundle.HashAlgorithm)
+	}
+	if hint := bundle.PublicKeyHint; len(hint) > 0 {
+		want, err := PublicKeyHint(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("client: NewLogVerifierFromBundle(): %v", err)
+		}
+		if !bytes.Equal(hint, want) {
+			return nil, fmt.Errorf("client: NewLogVerifierFromBundle(): bundle is for public key %x, not %x", hint, want)
+		}
+	}
+	v := NewLogVerifier(hasher, pubKey, crypto.SHA256)
+	v.hashStrategy = bundle.HashStrategy
+	return v, nil
+}
+
+// NewProofBundle packs a leaf of the log with ID logID, its inclusion proof
+// and the signed log root which the proof leads to into a proof bundle. The
+// LogVerifier must have been created from the tree config of the log or from
+// another bundle, so that the hash strategy of the log is known.
+func (c *LogVerifier) NewProofBundle(logID int64, leaf *trillian.LogLeaf, proof *trillian.Proof, root *trillian.SignedLogRoot) (*trillian.ProofBundle, error) {
+	if c.hashStrategy == trillian.HashStrategy_UNKNOWN_HASH_STRATEGY {
+		return nil, errors.New("hash strategy of the log is unknown")
+	}
+	if c.SigHash != crypto.SHA256 {
+		return nil, fmt.Errorf("unexpected signature hash: %v", c.SigHash)
+	}
+	hint, err := PublicKeyHint(c.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.ProofBundle{
+		LogId:         logID,
+		Leaf:          leaf,
+		Proof:         proof,
+		SignedLogRoot: root,
+		HashStrategy:  c.hashStrategy,
+		HashAlgorithm: sigpb.DigitallySigned_SHA256,
+		PublicKeyHint: hint,
+	}, nil
+}
+
+// VerifyProofBundle verifies the signature of the log root in a proof bundle,
+// and that the leaf of the bundle is included in the tree the root commits to.
+// It returns the verified log root.
+func (c *LogVerifier) VerifyProofBundle(bundle *trillian.ProofBundle) (*types.LogRootV1, error) {
+	leaf, proof := bundle.GetLeaf(), bundle.GetProof()
+	if leaf == nil || proof == nil || bundle.GetSignedLogRoot() == nil {
+		return nil, errors.New("VerifyProofBundle() error: incomplete bundle")
+	}
+	root, err := tcrypto.VerifySignedLogRoot(c.PubKey, c.SigHash, bundle.SignedLogRoot)
+	if err != nil {
+		return nil, err
+	}
+	leafHash := c.Hasher.HashLeaf(leaf.LeafValue)
+	if len(leaf.MerkleLeafHash) > 0 && !bytes.Equal(leaf.MerkleLeafHash, leafHash) {
+		return nil, fmt.Errorf("VerifyProofBundle() error: leaf hash %x doesn't match leaf value hash %x", leaf.MerkleLeafHash, leafHash)
+	}
+	if proof.LeafIndex != leaf.LeafIndex {
+		return nil, fmt.Errorf("VerifyProofBundle() error: proof is for leaf %d, not %d", proof.LeafIndex, leaf.LeafIndex)
+	}
+	if err := c.VerifyInclusionByHash(root, leafHash, proof); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// GetProofBundle fetches the leaf at index along with its inclusion proof in
+// the latest tree of the log, verifies them and returns them as a proof
+// bundle. The client must have been created with NewFromTree.
+func (c *LogClient) GetProofBundle(ctx context.Context, index int64) (*trillian.ProofBundle, error) {
+	// The server returns the proof for its latest tree if the requested tree
+	// size is beyond it, so that the proof matches the returned log root.
+	resp, err := c.client.GetEntryAndProof(ctx, &trillian.GetEntryAndProofRequest{
+		LogId:     c.LogID,
+		LeafIndex: index,
+		TreeSize:  math.MaxInt64,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Leaf == nil || resp.Proof == nil {
+		return nil, fmt.Errorf("leaf %d is not included in the log yet", index)
+	}
+	bundle, err := c.NewProofBundle(c.LogID, resp.Leaf, resp.Proof, resp.SignedLogRoot)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.VerifyProofBundle(bundle); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+
+	tcrypto "github.com/google/trillian/crypto"
+	_ "github.com/google/trillian/merkle/rfc6962" // Register the RFC6962 hasher.
+	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
+)
+
+// newTestBundle returns a proof bundle for the first leaf of a two-leaf log,
+// and the public key of the log.
+func newTestBundle(t *testing.T) (*trillian.ProofBundle, crypto.PublicKey) {
+	t.Helper()
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("Failed to open test key: %v", err)
+	}
+	pk, err := pem.UnmarshalPublicKey(testonly.DemoPublicKey)
+	if err != nil {
+		t.Fatalf("Failed to load public key: %v", err)
+	}
+
+	hasher := rfc6962.DefaultHasher
+	leaf0, leaf1 := []byte("A"), []byte("B")
+	hash0, hash1 := hasher.HashLeaf(leaf0), hasher.HashLeaf(leaf1)
+	root, err := tcrypto.NewSigner(0, key, crypto.SHA256).SignLogRoot(&types.LogRootV1{
+		TreeSize: 2,
+		RootHash: hasher.HashChildren(hash0, hash1),
+	})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+
+	v := NewLogVerifier(hasher, pk, crypto.SHA256)
+	v.hashStrategy = trillian.HashStrategy_RFC6962_SHA256
+	bundle, err := v.NewProofBundle(123,
+		&trillian.LogLeaf{LeafValue: leaf0, MerkleLeafHash: hash0},
+		&trillian.Proof{Hashes: [][]byte{hash1}},
+		root)
+	if err != nil {
+		t.Fatalf("NewProofBundle(): %v", err)
+	}
+	return bundle, pk
+}
+
+func TestVerifyProofBundle(t *testing.T) {
+	bundle, pk := newTestBundle(t)
+
+	// Bundles are verified from their serialized form.
+	b, err := proto.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("Marshal(): %v", err)
+	}
+	var got trillian.ProofBundle
+	if err := proto.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal(): %v", err)
+	}
+	v, err := NewLogVerifierFromBundle(&got, pk)
+	if err != nil {
+		t.Fatalf("NewLogVerifierFromBundle(): %v", err)
+	}
+	root, err := v.VerifyProofBundle(&got)
+	if err != nil {
+		t.Fatalf("VerifyProofBundle(): %v", err)
+	}
+	if root.TreeSize != 2 {
+		t.Errorf("VerifyProofBundle() returned root of size %d, want 2", root.TreeSize)
+	}
+}
+
+func TestVerifyProofBundleErrors(t *testing.T) {
+	for _, test := range []struct {
+		desc   string
+		modify func(b *trillian.ProofBundle)
+	}{
+		{desc: "tamperedValue", modify: func(b *trillian.ProofBundle) { b.Leaf.LeafValue = []byte("C") }},
+		{desc: "tamperedValueNoHash", modify: func(b *trillian.ProofBundle) {
+			b.Leaf.LeafValue, b.Leaf.MerkleLeafHash = []byte("C"), nil
+		}},
+		{desc: "wrongIndex", modify: func(b *trillian.ProofBundle) { b.Leaf.LeafIndex, b.Proof.LeafIndex = 1, 1 }},
+		{desc: "mismatchedIndex", modify: func(b *trillian.ProofBundle) { b.Proof.LeafIndex = 1 }},
+		{desc: "wrongProof", modify: func(b *trillian.ProofBundle) { b.Proof.Hashes[0] = b.Leaf.MerkleLeafHash }},
+		{desc: "badSignature", modify: func(b *trillian.ProofBundle) { b.SignedLogRoot.LogRootSignature = []byte("sig") }},
+		{desc: "noRoot", modify: func(b *trillian.ProofBundle) { b.SignedLogRoot = nil }},
+		{desc: "noProof", modify: func(b *trillian.ProofBundle) { b.Proof = nil }},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			bundle, pk := newTestBundle(t)
+			test.modify(bundle)
+			v, err := NewLogVerifierFromBundle(bundle, pk)
+			if err != nil {
+				t.Fatalf("NewLogVerifierFromBundle(): %v", err)
+			}
+			if _, err := v.VerifyProofBundle(bundle); err == nil {
+				t.Error("VerifyProofBundle() returned no error")
+			}
+		})
+	}
+}
+
+func TestNewLogVerifierFromBundleErrors(t *testing.T) {
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	for _, test := range []struct {
+		desc   string
+		modify func(b *trillian.ProofBundle)
+		pubKey crypto.PublicKey
+	}{
+		{desc: "otherKey", pubKey: other.Public()},
+		{desc: "unknownStrategy", modify: func(b *trillian.ProofBundle) { b.HashStrategy = trillian.HashStrategy_UNKNOWN_HASH_STRATEGY }},
+		{desc: "unknownAlgorithm", modify: func(b *trillian.ProofBundle) { b.HashAlgorithm = 0 }},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			bundle, pk := newTestBundle(t)
+			if test.modify != nil {
+				test.modify(bundle)
+			}
+			if test.pubKey != nil {
+				pk = test.pubKey
+			}
+			if _, err := NewLogVerifierFromBundle(bundle, pk); err == nil {
+				t.Error("NewLogVerifierFromBundle() returned no error")
+			}
+		})
+	}
+}
+
+func TestNewProofBundleUnknownStrategy(t *testing.T) {
+	pk, err := pem.UnmarshalPublicKey(testonly.DemoPublicKey)
+	if err != nil {
+		t.Fatalf("Failed to load public key: %v", err)
+	}
+	v := NewLogVerifier(rfc6962.DefaultHasher, pk, crypto.SHA256)
+	if _, err := v.NewProofBundle(1, &trillian.LogLeaf{}, &trillian.Proof{}, &trillian.SignedLogRoot{}); err == nil {
+		t.Error("NewProofBundle() for a verifier without hash strategy returned no error")
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rfc6962json
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PathPrefix is the path under which the endpoints of RFC 6962 are served.
+const PathPrefix = "/ct/v1/"
+
+// Handler serves the read-only endpoints of the RFC 6962 HTTP API for a
+// Trillian log: get-sth, get-sth-consistency, get-proof-by-hash, get-entries
+// and get-entry-and-proof. Requests are answered with the RPCs of the log
+// server, and responses are encoded with the helpers of this package. The
+// submission endpoints are left to personalities, which know how to validate
+// and build leaves.
+type Handler struct {
+	client trillian.TrillianLogClient
+	logID  int64
+	signer *tcrypto.Signer
+	prefix string
+}
+
+// NewHandler returns a Handler for the log with the given ID, served under
+// prefix, which is usually PathPrefix. Tree heads are signed with signer, as
+// described by NewSignedTreeHead.
+func NewHandler(client trillian.TrillianLogClient, logID int64, signer *tcrypto.Signer, prefix string) *Handler {
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &Handler{client: client, logID: logID, signer: signer, prefix: prefix}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var serve func(context.Context, *params) (interface{}, error)
+	switch strings.TrimPrefix(r.URL.Path, h.prefix) {
+	case "get-sth":
+		serve = h.getSTH
+	case "get-sth-consistency":
+		serve = h.getSTHConsistency
+	case "get-proof-by-hash":
+		serve = h.getProofByHash
+	case "get-entries":
+		serve = h.getEntries
+	case "get-entry-and-proof":
+		serve = h.getEntryAndProof
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	p := &params{values: r.URL.Query()}
+	resp, err := serve(r.Context(), p)
+	if err != nil {
+		code := httpStatus(err)
+		if code == http.StatusInternalServerError {
+			glog.Warningf("%v: failed to serve %v: %v", h.logID, r.URL.Path, err)
+		}
+		http.Error(w, status.Convert(err).Message(), code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		glog.Warningf("%v: failed to write response: %v", h.logID, err)
+	}
+}
+
+func (h *Handler) getSTH(ctx context.Context, _ *params) (interface{}, error) {
+	resp, err := h.client.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: h.logID})
+	if err != nil {
+		return nil, err
+	}
+	return NewSignedTreeHead(resp.SignedLogRoot, h.signer)
+}
+
+func (h *Handler) getSTHConsistency(ctx context.Context, p *params) (interface{}, error) {
+	first, second := p.int64("first"), p.int64("second")
+	if p.err != nil {
+		return nil, p.err
+	}
+	if first == 0 {
+		// Trillian has no proofs from the empty tree, which are empty.
+		return NewConsistencyProof(nil), nil
+	}
+	resp, err := h.client.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{
+		LogId:          h.logID,
+		FirstTreeSize:  first,
+		SecondTreeSize: second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Proof == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "tree size %d is beyond the size of the log", second)
+	}
+	return NewConsistencyProof(resp.Proof), nil
+}
+
+func (h *Handler) getProofByHash(ctx context.Context, p *params) (interface{}, error) {
+	hash, treeSize := p.bytes("hash"), p.int64("tree_size")
+	if p.err != nil {
+		return nil, p.err
+	}
+	resp, err := h.client.GetInclusionProofByHash(ctx, &trillian.GetInclusionProofByHashRequest{
+		LogId:           h.logID,
+		LeafHash:        hash,
+		TreeSize:        treeSize,
+		OrderBySequence: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Proof) == 0 {
+		return nil, status.Errorf(codes.NotFound, "leaf hash not found in tree of size %d", treeSize)
+	}
+	return NewInclusionProof(resp.Proof[0]), nil
+}
+
+func (h *Handler) getEntries(ctx context.Context, p *params) (interface{}, error) {
+	start, end := p.int64("start"), p.int64("end")
+	if p.err != nil {
+		return nil, p.err
+	}
+	if end < start {
+		return nil, status.Errorf(codes.InvalidArgument, "end %d is before start %d", end, start)
+	}
+	resp, err := h.client.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{
+		LogId:      h.logID,
+		StartIndex: start,
+		Count:      end - start + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewEntries(resp.Leaves), nil
+}
+
+func (h *Handler) getEntryAndProof(ctx context.Context, p *params) (interface{}, error) {
+	index, treeSize := p.int64("leaf_index"), p.int64("tree_size")
+	if p.err != nil {
+		return nil, p.err
+	}
+	resp, err := h.client.GetEntryAndProof(ctx, &trillian.GetEntryAndProofRequest{
+		LogId:     h.logID,
+		LeafIndex: index,
+		TreeSize:  treeSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Leaf == nil || resp.Proof == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "leaf %d is beyond the size of the log", index)
+	}
+	return NewEntryAndProof(resp.Leaf, resp.Proof), nil
+}
+
+// params reads the query parameters of a request, and records the first
+// parameter which is missing or malformed.
+type params struct {
+	values map[string][]string
+	err    error
+}
+
+func (p *params) get(name string) (string, bool) {
+	if p.err != nil {
+		return "", false
+	}
+	v := p.values[name]
+	if len(v) == 0 || v[0] == "" {
+		p.err = status.Errorf(codes.InvalidArgument, "missing parameter %q", name)
+		return "", false
+	}
+	return v[0], true
+}
+
+// int64 returns the value of a non-negative integer parameter.
+func (p *params) int64(name string) int64 {
+	v, ok := p.get(name)
+	if !ok {
+		return 0
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || i < 0 {
+		p.err = status.Errorf(codes.InvalidArgument, "parameter %q is %q, want a non-negative integer", name, v)
+		return 0
+	}
+	return i
+}
+
+// bytes returns the value of a base64 encoded parameter.
+func (p *params) bytes(name string) []byte {
+	v, ok := p.get(name)
+	if !ok {
+		return nil
+	}
+	b, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		p.err = status.Errorf(codes.InvalidArgument, "parameter %q is not base64: %v", name, err)
+		return nil
+	}
+	return b
+}
+
+// httpStatus returns the HTTP status code for an error returned by the log
+// server.
+func httpStatus(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rfc6962json
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeLogClient answers the read RPCs of a log of three leaves.
+type fakeLogClient struct {
+	trillian.TrillianLogClient
+	root *trillian.SignedLogRoot
+}
+
+var (
+	fakeProof = &trillian.Proof{LeafIndex: 1, Hashes: [][]byte{{0x02}, {0x03}}}
+	fakeLeaf  = &trillian.LogLeaf{LeafIndex: 1, LeafValue: []byte("value"), ExtraData: []byte("extra")}
+)
+
+func (f *fakeLogClient) GetLatestSignedLogRoot(ctx context.Context, req *trillian.GetLatestSignedLogRootRequest, _ ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	return &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: f.root}, nil
+}
+
+func (f *fakeLogClient) GetConsistencyProof(ctx context.Context, req *trillian.GetConsistencyProofRequest, _ ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	if req.SecondTreeSize > 3 {
+		return &trillian.GetConsistencyProofResponse{SignedLogRoot: f.root}, nil
+	}
+	return &trillian.GetConsistencyProofResponse{Proof: fakeProof, SignedLogRoot: f.root}, nil
+}
+
+func (f *fakeLogClient) GetInclusionProofByHash(ctx context.Context, req *trillian.GetInclusionProofByHashRequest, _ ...grpc.CallOption) (*trillian.GetInclusionProofByHashResponse, error) {
+	if string(req.LeafHash) != "hash" {
+		return nil, status.Error(codes.NotFound, "no such leaf")
+	}
+	return &trillian.GetInclusionProofByHashResponse{Proof: []*trillian.Proof{fakeProof}, SignedLogRoot: f.root}, nil
+}
+
+func (f *fakeLogClient) GetLeavesByRange(ctx context.Context, req *trillian.GetLeavesByRangeRequest, _ ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+	var leaves []*trillian.LogLeaf
+	for i := req.StartIndex; i < req.StartIndex+req.Count && i < 3; i++ {
+		leaves = append(leaves, fakeLeaf)
+	}
+	return &trillian.GetLeavesByRangeResponse{Leaves: leaves, SignedLogRoot: f.root}, nil
+}
+
+func (f *fakeLogClient) GetEntryAndProof(ctx context.Context, req *trillian.GetEntryAndProofRequest, _ ...grpc.CallOption) (*trillian.GetEntryAndProofResponse, error) {
+	if req.LeafIndex >= req.TreeSize {
+		return nil, status.Error(codes.InvalidArgument, "leaf index beyond tree size")
+	}
+	return &trillian.GetEntryAndProofResponse{Proof: fakeProof, Leaf: fakeLeaf, SignedLogRoot: f.root}, nil
+}
+
+func TestHandler(t *testing.T) {
+	logRoot, err := (&types.LogRootV1{TreeSize: 3, TimestampNanos: 5e9, RootHash: make([]byte, 32)}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	client := &fakeLogClient{root: &trillian.SignedLogRoot{LogRoot: logRoot}}
+	h := NewHandler(client, 1, nil /* signer */, PathPrefix)
+
+	for _, test := range []struct {
+		desc       string
+		method     string
+		url        string
+		wantStatus int
+		want       interface{}
+	}{
+		{
+			desc:       "sth",
+			url:        "/ct/v1/get-sth",
+			wantStatus: http.StatusOK,
+			want:       &SignedTreeHead{TreeSize: 3, Timestamp: 5000, SHA256RootHash: make([]byte, 32)},
+		},
+		{
+			desc:       "consistency",
+			url:        "/ct/v1/get-sth-consistency?first=1&second=3",
+			wantStatus: http.StatusOK,
+			want:       NewConsistencyProof(fakeProof),
+		},
+		{
+			desc:       "consistency-from-empty",
+			url:        "/ct/v1/get-sth-consistency?first=0&second=3",
+			wantStatus: http.StatusOK,
+			want:       &ConsistencyProof{Consistency: [][]byte{}},
+		},
+		{desc: "consistency-too-big", url: "/ct/v1/get-sth-consistency?first=1&second=4", wantStatus: http.StatusBadRequest},
+		{desc: "consistency-missing-param", url: "/ct/v1/get-sth-consistency?first=1", wantStatus: http.StatusBadRequest},
+		{desc: "consistency-negative", url: "/ct/v1/get-sth-consistency?first=-1&second=3", wantStatus: http.StatusBadRequest},
+		{
+			desc:       "proof-by-hash",
+			url:        "/ct/v1/get-proof-by-hash?hash=aGFzaA%3D%3D&tree_size=3",
+			wantStatus: http.StatusOK,
+			want:       NewInclusionProof(fakeProof),
+		},
+		{desc: "proof-by-hash-unknown", url: "/ct/v1/get-proof-by-hash?hash=b3RoZXI%3D&tree_size=3", wantStatus: http.StatusNotFound},
+		{desc: "proof-by-hash-bad-hash", url: "/ct/v1/get-proof-by-hash?hash=!!&tree_size=3", wantStatus: http.StatusBadRequest},
+		{
+			desc:       "entries",
+			url:        "/ct/v1/get-entries?start=1&end=5",
+			wantStatus: http.StatusOK,
+			want:       NewEntries([]*trillian.LogLeaf{fakeLeaf, fakeLeaf}),
+		},
+		{desc: "entries-backwards", url: "/ct/v1/get-entries?start=2&end=1", wantStatus: http.StatusBadRequest},
+		{
+			desc:       "entry-and-proof",
+			url:        "/ct/v1/get-entry-and-proof?leaf_index=1&tree_size=3",
+			wantStatus: http.StatusOK,
+			want:       NewEntryAndProof(fakeLeaf, fakeProof),
+		},
+		{desc: "entry-and-proof-beyond", url: "/ct/v1/get-entry-and-proof?leaf_index=3&tree_size=3", wantStatus: http.StatusBadRequest},
+		{desc: "unknown", url: "/ct/v1/add-chain", wantStatus: http.StatusNotFound},
+		{desc: "post", method: http.MethodPost, url: "/ct/v1/get-sth", wantStatus: http.StatusMethodNotAllowed},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(method, test.url, nil))
+			if got, want := w.Code, test.wantStatus; got != want {
+				t.Fatalf("ServeHTTP(%v %v): status=%v, want %v (body %q)", method, test.url, got, want, w.Body.String())
+			}
+			if test.want == nil {
+				return
+			}
+			want, err := json.Marshal(test.want)
+			if err != nil {
+				t.Fatalf("Marshal(): %v", err)
+			}
+			var gotJSON, wantJSON interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &gotJSON); err != nil {
+				t.Fatalf("Unmarshal(%q): %v", w.Body.String(), err)
+			}
+			if err := json.Unmarshal(want, &wantJSON); err != nil {
+				t.Fatalf("Unmarshal(): %v", err)
+			}
+			if diff := cmp.Diff(gotJSON, wantJSON); diff != "" {
+				t.Errorf("response diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rfc6962json encodes Trillian log roots, proofs and leaves in the
+// JSON structures of the RFC 6962 (Certificate Transparency) HTTP API, so that
+// CT-style personalities and tools can serve and consume them without their
+// own translation code. Byte strings are base64 encoded, as in RFC 6962.
+package rfc6962json
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/types"
+)
+
+// SignedTreeHead is the response of the get-sth endpoint (RFC 6962 s4.3).
+type SignedTreeHead struct {
+	TreeSize  uint64 `json:"tree_size"`
+	Timestamp uint64 `json:"timestamp"`
+	// SHA256RootHash is the root hash of the tree.
+	SHA256RootHash []byte `json:"sha256_root_hash"`
+	// TreeHeadSignature is a TLS-encoded DigitallySigned struct, signing the
+	// input returned by TreeHeadSignatureInput.
+	TreeHeadSignature []byte `json:"tree_head_signature"`
+}
+
+// ConsistencyProof is the response of the get-sth-consistency endpoint (RFC
+// 6962 s4.4).
+type ConsistencyProof struct {
+	Consistency [][]byte `json:"consistency"`
+}
+
+// InclusionProof is the response of the get-proof-by-hash endpoint (RFC 6962
+// s4.5).
+type InclusionProof struct {
+	LeafIndex int64    `json:"leaf_index"`
+	AuditPath [][]byte `json:"audit_path"`
+}
+
+// LeafEntry is an entry of the response of the get-entries endpoint (RFC 6962
+// s4.6).
+type LeafEntry struct {
+	LeafInput []byte `json:"leaf_input"`
+	ExtraData []byte `json:"extra_data"`
+}
+
+// Entries is the response of the get-entries endpoint (RFC 6962 s4.6).
+type Entries struct {
+	Entries []LeafEntry `json:"entries"`
+}
+
+// EntryAndProof is the response of the get-entry-and-proof endpoint (RFC 6962
+// s4.8).
+type EntryAndProof struct {
+	LeafInput []byte   `json:"leaf_input"`
+	ExtraData []byte   `json:"extra_data"`
+	AuditPath [][]byte `json:"audit_path"`
+}
+
+// Values of the TreeHeadSignature struct of RFC 6962 s3.5.
+const (
+	sthVersionV1      = 0
+	sthSignatureType  = 1 // tree_hash
+	sha256RootHashLen = 32
+)
+
+// NewSignedTreeHead returns the tree head of the given log root. The tree head
+// is signed with signer, which must hash with SHA-256, unless it is nil, in
+// which case the tree head is left unsigned. Trillian's own root signature
+// can't stand in for it, as it doesn't sign the RFC 6962 structure.
+func NewSignedTreeHead(slr *trillian.SignedLogRoot, signer *tcrypto.Signer) (*SignedTreeHead, error) {
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.GetLogRoot()); err != nil {
+		return nil, err
+	}
+	sth := &SignedTreeHead{
+		TreeSize:       root.TreeSize,
+		Timestamp:      root.TimestampNanos / 1e6,
+		SHA256RootHash: root.RootHash,
+	}
+	if signer != nil {
+		sig, err := SignTreeHead(signer, &root)
+		if err != nil {
+			return nil, err
+		}
+		sth.TreeHeadSignature = sig
+	}
+	return sth, nil
+}
+
+// TreeHeadSignatureInput returns the TLS encoding of the RFC 6962
+// TreeHeadSignature struct for the given log root, which is the input signed
+// by the tree_head_signature of a tree head. Timestamps are truncated to
+// milliseconds.
+func TreeHeadSignatureInput(root *types.LogRootV1) ([]byte, error) {
+	if got := len(root.RootHash); got != sha256RootHashLen {
+		return nil, fmt.Errorf("root hash has %d bytes, want %d", got, sha256RootHashLen)
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(sthVersionV1)
+	buf.WriteByte(sthSignatureType)
+	binary.Write(&buf, binary.BigEndian, root.TimestampNanos/1e6)
+	binary.Write(&buf, binary.BigEndian, root.TreeSize)
+	buf.Write(root.RootHash)
+	return buf.Bytes(), nil
+}
+
+// SignTreeHead signs the RFC 6962 TreeHeadSignature struct for the given log
+// root with signer, and returns the TLS encoding of the resulting
+// DigitallySigned struct. The signer must hash with SHA-256.
+func SignTreeHead(signer *tcrypto.Signer, root *types.LogRootV1) ([]byte, error) {
+	if signer.Hash != crypto.SHA256 {
+		return nil, fmt.Errorf("signer uses hash %v, but RFC 6962 tree heads are signed with SHA-256", signer.Hash)
+	}
+	alg := tcrypto.SignatureAlgorithm(signer.Public())
+	if alg != sigpb.DigitallySigned_ECDSA && alg != sigpb.DigitallySigned_RSA {
+		return nil, fmt.Errorf("signature algorithm %v isn't supported by RFC 6962", alg)
+	}
+	input, err := TreeHeadSignatureInput(root)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) > 0xffff {
+		return nil, fmt.Errorf("signature has %d bytes, too many for a DigitallySigned struct", len(sig))
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(byte(sigpb.DigitallySigned_SHA256))
+	buf.WriteByte(byte(alg))
+	binary.Write(&buf, binary.BigEndian, uint16(len(sig)))
+	buf.Write(sig)
+	return buf.Bytes(), nil
+}
+
+// NewConsistencyProof returns the encoding of a consistency proof.
+func NewConsistencyProof(proof *trillian.Proof) *ConsistencyProof {
+	return &ConsistencyProof{Consistency: hashes(proof)}
+}
+
+// NewInclusionProof returns the encoding of an inclusion proof.
+func NewInclusionProof(proof *trillian.Proof) *InclusionProof {
+	return &InclusionProof{LeafIndex: proof.GetLeafIndex(), AuditPath: hashes(proof)}
+}
+
+// NewEntries returns the encoding of a range of log leaves. The leaf_input of
+// each entry is its LeafValue, and extra_data its ExtraData.
+func NewEntries(leaves []*trillian.LogLeaf) *Entries {
+	entries := &Entries{Entries: make([]LeafEntry, 0, len(leaves))}
+	for _, leaf := range leaves {
+		entries.Entries = append(entries.Entries, LeafEntry{LeafInput: leaf.LeafValue, ExtraData: leaf.ExtraData})
+	}
+	return entries
+}
+
+// NewEntryAndProof returns the encoding of a log leaf and its inclusion proof.
+func NewEntryAndProof(leaf *trillian.LogLeaf, proof *trillian.Proof) *EntryAndProof {
+	return &EntryAndProof{LeafInput: leaf.GetLeafValue(), ExtraData: leaf.GetExtraData(), AuditPath: hashes(proof)}
+}
+
+// hashes returns the hashes of a proof, which are empty rather than nil, so
+// that they are encoded as an empty JSON array.
+func hashes(proof *trillian.Proof) [][]byte {
+	if h := proof.GetHashes(); h != nil {
+		return h
+	}
+	return [][]byte{}
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rfc6962json
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/types"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestTreeHeadSignatureInput(t *testing.T) {
+	root := &types.LogRootV1{TreeSize: 7, TimestampNanos: 1234567890123456, RootHash: bytes.Repeat([]byte{0xab}, 32)}
+	got, err := TreeHeadSignatureInput(root)
+	if err != nil {
+		t.Fatalf("TreeHeadSignatureInput(): %v", err)
+	}
+	want := []byte{0, 1, 0, 0, 0, 0, 0x49, 0x96, 0x02, 0xd2, 0, 0, 0, 0, 0, 0, 0, 7}
+	want = append(want, root.RootHash...)
+	if !bytes.Equal(got, want) {
+		t.Errorf("TreeHeadSignatureInput()=%x, want %x", got, want)
+	}
+
+	root.RootHash = root.RootHash[:20]
+	if _, err := TreeHeadSignatureInput(root); err == nil {
+		t.Error("TreeHeadSignatureInput() with a 20-byte root hash succeeded, want error")
+	}
+}
+
+func TestSignTreeHead(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	root := &types.LogRootV1{TreeSize: 7, TimestampNanos: 1234567890123456, RootHash: bytes.Repeat([]byte{0xab}, 32)}
+	sig, err := SignTreeHead(tcrypto.NewSigner(0, key, crypto.SHA256), root)
+	if err != nil {
+		t.Fatalf("SignTreeHead(): %v", err)
+	}
+	if len(sig) < 4 {
+		t.Fatalf("SignTreeHead()=%x, too short for a DigitallySigned struct", sig)
+	}
+	if got, want := sig[:2], []byte{4 /* sha256 */, 3 /* ecdsa */}; !bytes.Equal(got, want) {
+		t.Errorf("SignTreeHead() algorithms %v, want %v", got, want)
+	}
+	if got, want := int(binary.BigEndian.Uint16(sig[2:4])), len(sig)-4; got != want {
+		t.Errorf("SignTreeHead() signature length %d, want %d", got, want)
+	}
+	input, err := TreeHeadSignatureInput(root)
+	if err != nil {
+		t.Fatalf("TreeHeadSignatureInput(): %v", err)
+	}
+	digest := sha256.Sum256(input)
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], sig[4:]) {
+		t.Error("SignTreeHead() signature doesn't verify")
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	if _, err := SignTreeHead(tcrypto.NewSigner(0, edKey, crypto.SHA256), root); err == nil {
+		t.Error("SignTreeHead() with an Ed25519 key succeeded, want error")
+	}
+	if _, err := SignTreeHead(tcrypto.NewSigner(0, key, crypto.SHA512), root); err == nil {
+		t.Error("SignTreeHead() with SHA-512 succeeded, want error")
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	rootHash := bytes.Repeat([]byte{0x01}, 32)
+	logRoot, err := (&types.LogRootV1{TreeSize: 3, TimestampNanos: 5e9, RootHash: rootHash}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	sth, err := NewSignedTreeHead(&trillian.SignedLogRoot{LogRoot: logRoot}, nil)
+	if err != nil {
+		t.Fatalf("NewSignedTreeHead(): %v", err)
+	}
+	proof := &trillian.Proof{LeafIndex: 2, Hashes: [][]byte{{0x02}, {0x03}}}
+	leaf := &trillian.LogLeaf{LeafValue: []byte("value"), ExtraData: []byte("extra")}
+
+	for _, test := range []struct {
+		desc string
+		v    interface{}
+		want string
+	}{
+		{
+			desc: "sth",
+			v:    sth,
+			want: `{"tree_size":3,"timestamp":5000,"sha256_root_hash":"AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=","tree_head_signature":null}`,
+		},
+		{desc: "consistency", v: NewConsistencyProof(proof), want: `{"consistency":["Ag==","Aw=="]}`},
+		{desc: "empty-consistency", v: NewConsistencyProof(nil), want: `{"consistency":[]}`},
+		{desc: "inclusion", v: NewInclusionProof(proof), want: `{"leaf_index":2,"audit_path":["Ag==","Aw=="]}`},
+		{
+			desc: "entries",
+			v:    NewEntries([]*trillian.LogLeaf{leaf}),
+			want: `{"entries":[{"leaf_input":"dmFsdWU=","extra_data":"ZXh0cmE="}]}`,
+		},
+		{desc: "no-entries", v: NewEntries(nil), want: `{"entries":[]}`},
+		{
+			desc: "entry-and-proof",
+			v:    NewEntryAndProof(leaf, proof),
+			want: `{"leaf_input":"dmFsdWU=","extra_data":"ZXh0cmE=","audit_path":["Ag==","Aw=="]}`,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := json.Marshal(test.v)
+			if err != nil {
+				t.Fatalf("Marshal(): %v", err)
+			}
+			if string(got) != test.want {
+				t.Errorf("Marshal()=%s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"flag"
 
 	"github.com/golang/glog"
+	"github.com/google/trillian/util/compression"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -25,6 +26,11 @@ import (
 // tlsCertFile is the flag-assigned value for the path to the Trillian server's TLS certificate.
 var tlsCertFile = flag.String("tls_cert_file", "", "Path to the file containing the Trillian server's PEM-encoded public TLS certificate. If unset, unsecured connections will be used")
 
+var (
+	compressor        = flag.String("rpc_compression", "", "Name of the compressor, e.g. gzip, which responses of the methods set by --rpc_compressed_methods are compressed with. If unset, responses are not compressed")
+	compressedMethods = flag.String("rpc_compressed_methods", "", "Comma-separated list of methods whose responses are compressed if --rpc_compression is set, named by their full name or name alone. If unset, those returning many leaves are")
+)
+
 // NewClientDialOptionsFromFlags returns a list of grpc.DialOption values to be
 // passed as DialOption arguments to grpc.Dial
 func NewClientDialOptionsFromFlags() ([]grpc.DialOption, error) {
@@ -41,5 +47,11 @@ func NewClientDialOptionsFromFlags() ([]grpc.DialOption, error) {
 		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
 	}
 
+	compressOpts, err := compression.DialOptions(*compressor, compression.ParseMethods(*compressedMethods))
+	if err != nil {
+		return nil, err
+	}
+	dialOpts = append(dialOpts, compressOpts...)
+
 	return dialOpts, nil
 }
@@ -0,0 +1,98 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The archive_log binary writes the tree of a frozen log to a self-contained
+// archive for long-term escrow, which verify_log_archive can verify offline.
+//
+// Example usage:
+// $ ./archive_log --log_id=logid --output=log.tar.gz --note_key=key.txt --origin=example.com/log
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/tiles"
+	"github.com/google/trillian/tiles/archive"
+
+	// Register supported storage providers.
+	_ "github.com/google/trillian/storage/cloudspanner"
+	_ "github.com/google/trillian/storage/etcd"
+	_ "github.com/google/trillian/storage/fdb"
+	_ "github.com/google/trillian/storage/mysql"
+	_ "github.com/google/trillian/storage/postgres"
+	_ "github.com/google/trillian/storage/routing"
+	_ "github.com/google/trillian/storage/sqlite"
+
+	// Load hashers
+	_ "github.com/google/trillian/merkle/rfc6962"
+	_ "github.com/google/trillian/merkle/sumdb"
+)
+
+var (
+	logID         = flag.Int64("log_id", 0, "ID of the frozen log to archive")
+	output        = flag.String("output", "", "Path of the archive to write")
+	noteKey       = flag.String("note_key", "", "Path to the file holding the note signing key of the archived checkpoint")
+	origin        = flag.String("origin", "", "Origin of the archived checkpoint")
+	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+)
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	if *logID == 0 || *output == "" || *noteKey == "" || *origin == "" {
+		glog.Exit("--log_id, --output, --note_key and --origin are required")
+	}
+	skey, err := ioutil.ReadFile(*noteKey)
+	if err != nil {
+		glog.Exitf("Failed to read note key: %v", err)
+	}
+	signer, err := tiles.NewNoteSigner(strings.TrimSpace(string(skey)))
+	if err != nil {
+		glog.Exitf("Failed to load note key: %v", err)
+	}
+
+	sp, err := storage.NewProvider(*storageSystem, monitoring.InertMetricFactory{})
+	if err != nil {
+		glog.Exitf("Failed to get storage provider: %v", err)
+	}
+	defer sp.Close()
+
+	f, err := os.Create(*output)
+	if err != nil {
+		glog.Exitf("Failed to create archive: %v", err)
+	}
+	size, err := archive.Write(context.Background(), sp.AdminStorage(), sp.LogStorage(), f, archive.Options{
+		LogID:  *logID,
+		Origin: *origin,
+		Signer: signer,
+	})
+	if err != nil {
+		f.Close()
+		os.Remove(*output)
+		glog.Exitf("Failed to archive log: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		glog.Exitf("Failed to write archive: %v", err)
+	}
+	glog.Infof("Archived tree of size %d to %v", size, *output)
+}
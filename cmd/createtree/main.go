@@ -54,12 +54,19 @@ var (
 	treeState          = flag.String("tree_state", trillian.TreeState_ACTIVE.String(), "State of the new tree")
 	treeType           = flag.String("tree_type", trillian.TreeType_LOG.String(), "Type of the new tree")
 	hashStrategy       = flag.String("hash_strategy", trillian.HashStrategy_RFC6962_SHA256.String(), "Hash strategy (aka preimage protection) of the new tree")
+	mapHasher          = flag.String("map_hasher", "", "Name of the registered map hasher of the new map, if --hash_strategy is CUSTOM_MAP_HASHER")
+	autoInit           = flag.Bool("auto_init", false, "Whether the first write to the new map initializes it, without InitMap")
+	mapKeyIndex        = flag.Bool("map_key_index", false, "Whether the keys of the leaves of the new map are indexed, for ListLeavesByKeyPrefix")
+	maxStorageBytes    = flag.Int64("max_storage_bytes", 0, "Maximum bytes of leaves and subtrees written for the new tree; zero means no limit")
 	hashAlgorithm      = flag.String("hash_algorithm", sigpb.DigitallySigned_SHA256.String(), "Hash algorithm of the new tree")
 	signatureAlgorithm = flag.String("signature_algorithm", sigpb.DigitallySigned_ECDSA.String(), "Signature algorithm of the new tree")
 	displayName        = flag.String("display_name", "", "Display name of the new tree")
 	description        = flag.String("description", "", "Description of the new tree")
 	maxRootDuration    = flag.Duration("max_root_duration", time.Hour, "Interval after which a new signed root is produced despite no submissions; zero means never")
 	privateKeyFormat   = flag.String("private_key_format", "", "Type of protobuf message to send the key as (PrivateKey, PEMKeyFile, or PKCS11ConfigFile). If empty, a key will be generated for you by Trillian.")
+	requestID          = flag.String("request_id", "", "ID of the request, such as a UUID, so that running the command again with the same ID returns the tree it created instead of creating another")
+	treeID             = flag.Int64("tree_id", 0, "ID of the new tree; if zero, a random one is chosen unless --tree_id_name is set")
+	treeIDName         = flag.String("tree_id_name", "", "Name from which the ID of the new tree is derived, such as ct/argon2021")
 
 	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
 
@@ -125,12 +132,16 @@ func newRequest() (*trillian.CreateTreeRequest, error) {
 		TreeState:          trillian.TreeState(ts),
 		TreeType:           trillian.TreeType(tt),
 		HashStrategy:       trillian.HashStrategy(hs),
+		MapHasher:          *mapHasher,
 		HashAlgorithm:      sigpb.DigitallySigned_HashAlgorithm(ha),
 		SignatureAlgorithm: sigpb.DigitallySigned_SignatureAlgorithm(sa),
 		DisplayName:        *displayName,
 		Description:        *description,
 		MaxRootDuration:    ptypes.DurationProto(*maxRootDuration),
-	}}
+		AutoInit:           *autoInit,
+		MapKeyIndex:        *mapKeyIndex,
+		MaxStorageBytes:    *maxStorageBytes,
+	}, RequestId: *requestID, TreeId: *treeID, TreeIdName: *treeIDName}
 	glog.Infof("Creating tree %+v", ctr.Tree)
 
 	if *privateKeyFormat != "" {
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
@@ -24,11 +25,18 @@ import (
 	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keys/pem"
 	"github.com/google/trillian/crypto/keyspb"
+
+	// Register password sources for encrypted PEM keys, in addition to
+	// environment variables and files.
+	_ "github.com/google/trillian/crypto/keys/pem/awssecrets"
+	_ "github.com/google/trillian/crypto/keys/pem/gcpsecrets"
 )
 
 var (
-	pemKeyPath = flag.String("pem_key_path", "", "Path to the private key PEM file")
-	pemKeyPass = flag.String("pem_key_password", "", "Password of the private key PEM file")
+	pemKeyPath       = flag.String("pem_key_path", "", "Path to the private key PEM file")
+	pemKeyPass       = flag.String("pem_key_password", "", "Password of the private key PEM file")
+	pemKeyPassSource = flag.String("pem_key_password_source", "", "Where to fetch the password of the private key PEM file from, instead of --pem_key_password, e.g. env:KEY_PASSWORD or file:/path/to/password. "+
+		"For PEMKeyFile keys, the source is stored with the tree and the password is fetched by the servers whenever they load the key")
 )
 
 func init() {
@@ -40,13 +48,19 @@ func pemKeyFileProtoFromFlags() (proto.Message, error) {
 	if *pemKeyPath == "" {
 		return nil, errors.New("empty pem_key_path")
 	}
-	if *pemKeyPass == "" {
+	if *pemKeyPass == "" && *pemKeyPassSource == "" {
 		return nil, fmt.Errorf("empty password for PEM key file %q", *pemKeyPath)
 	}
 
+	if *pemKeyPass != "" {
+		return &keyspb.PEMKeyFile{
+			Path:     *pemKeyPath,
+			Password: *pemKeyPass,
+		}, nil
+	}
 	return &keyspb.PEMKeyFile{
-		Path:     *pemKeyPath,
-		Password: *pemKeyPass,
+		Path:           *pemKeyPath,
+		PasswordSource: *pemKeyPassSource,
 	}, nil
 }
 
@@ -55,7 +69,15 @@ func privateKeyProtoFromFlags() (proto.Message, error) {
 		return nil, errors.New("empty pem_key_path")
 	}
 
-	key, err := pem.ReadPrivateKeyFile(*pemKeyPath, *pemKeyPass)
+	password := *pemKeyPass
+	if password == "" && *pemKeyPassSource != "" {
+		var err error
+		if password, err = pem.FetchPassword(context.Background(), *pemKeyPassSource); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := pem.ReadPrivateKeyFile(*pemKeyPath, password)
 	if err != nil {
 		return nil, fmt.Errorf("error reading reading private key file: %v", err)
 	}
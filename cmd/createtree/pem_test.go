@@ -16,6 +16,7 @@ package main
 
 import (
 	"errors"
+	"os"
 	"testing"
 
 	"github.com/golang/protobuf/proto" //nolint:staticcheck
@@ -34,6 +35,12 @@ func TestWithPEMKeyFile(t *testing.T) {
 		Password: pemPassword,
 	})
 
+	wantSourceTree := proto.Clone(defaultTree).(*trillian.Tree)
+	wantSourceTree.PrivateKey = mustMarshalAny(&keyspb.PEMKeyFile{
+		Path:           pemPath,
+		PasswordSource: "env:PEM_KEY_PASSWORD",
+	})
+
 	runTest(t, []*testCase{
 		{
 			desc: "empty pemKeyPath",
@@ -64,11 +71,23 @@ func TestWithPEMKeyFile(t *testing.T) {
 			},
 			wantTree: wantTree,
 		},
+		{
+			desc: "valid pemKeyPath and pemKeyPassSource",
+			setFlags: func() {
+				*privateKeyFormat = "PEMKeyFile"
+				*pemKeyPath = pemPath
+				*pemKeyPass = ""
+				*pemKeyPassSource = "env:PEM_KEY_PASSWORD"
+			},
+			wantTree: wantSourceTree,
+		},
 	})
 }
 
 func TestWithPrivateKey(t *testing.T) {
 	pemPath, pemPassword := "../../testdata/log-rpc-server.privkey.pem", "towel"
+	os.Setenv("PEM_KEY_PASSWORD", pemPassword)
+	defer os.Unsetenv("PEM_KEY_PASSWORD")
 
 	key, err := pem.ReadPrivateKeyFile(pemPath, pemPassword)
 	if err != nil {
@@ -115,5 +134,26 @@ func TestWithPrivateKey(t *testing.T) {
 			},
 			wantTree: wantTree,
 		},
+		{
+			desc: "valid pemKeyPath and pemKeyPassSource",
+			setFlags: func() {
+				*privateKeyFormat = "PrivateKey"
+				*pemKeyPath = pemPath
+				*pemKeyPass = ""
+				*pemKeyPassSource = "env:PEM_KEY_PASSWORD"
+			},
+			wantTree: wantTree,
+		},
+		{
+			desc: "unset pemKeyPassSource",
+			setFlags: func() {
+				*privateKeyFormat = "PrivateKey"
+				*pemKeyPath = pemPath
+				*pemKeyPass = ""
+				*pemKeyPassSource = "env:PEM_KEY_PASSWORD_UNSET"
+			},
+			validateErr: errors.New("pemfile: failed to fetch password from env source"),
+			wantErr:     true,
+		},
 	})
 }
@@ -0,0 +1,129 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The export_tiles binary keeps a static copy of a Trillian log, in the
+// tlog-tiles layout or that of Go checksum databases, in a directory or in a
+// Google Cloud Storage or Amazon S3 bucket, from which it can be served by a
+// CDN or static web server.
+//
+// Example usage:
+// $ ./export_tiles --log_id=logid --destination=gs://bucket/log --note_key=key.txt --origin=example.com/log --interval=1m
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/monitoring/prometheus"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/tiles"
+	"github.com/google/trillian/tiles/export"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	// Register supported storage providers.
+	_ "github.com/google/trillian/storage/cloudspanner"
+	_ "github.com/google/trillian/storage/etcd"
+	_ "github.com/google/trillian/storage/fdb"
+	_ "github.com/google/trillian/storage/mysql"
+	_ "github.com/google/trillian/storage/postgres"
+	_ "github.com/google/trillian/storage/routing"
+	_ "github.com/google/trillian/storage/sqlite"
+
+	// Load hashers
+	_ "github.com/google/trillian/merkle/rfc6962"
+	_ "github.com/google/trillian/merkle/sumdb"
+)
+
+var (
+	logID       = flag.Int64("log_id", 0, "ID of the log to export")
+	destination = flag.String("destination", "", "Directory, or gs://<bucket>[/<prefix>] or s3://<bucket>[/<prefix>] URL, to export the log to")
+	noteKey     = flag.String("note_key", "", "Path to the file holding the note signing key of exported checkpoints")
+	origin      = flag.String("origin", "", "Origin of exported checkpoints")
+	sumDB       = flag.Bool("sumdb", false, "If true, the log is exported in the layout of Go checksum databases, rather than that of tlog-tiles")
+	interval    = flag.Duration("interval", 0, "How often the log is exported (0 means export once and exit)")
+
+	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	httpEndpoint  = flag.String("http_endpoint", "", "Endpoint for HTTP metrics (host:port, empty means disabled)")
+	configFile    = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
+)
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	if *configFile != "" {
+		if err := cmd.ParseFlagFile(*configFile); err != nil {
+			glog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
+		}
+	}
+	if *logID == 0 || *destination == "" || *noteKey == "" {
+		glog.Exit("--log_id, --destination and --note_key are required")
+	}
+
+	ctx := context.Background()
+	var mf monitoring.MetricFactory = monitoring.InertMetricFactory{}
+	if *httpEndpoint != "" {
+		mf = prometheus.MetricFactory{}
+		http.Handle("/metrics", promhttp.Handler())
+		go func() {
+			glog.Exitf("HTTP server exited: %v", http.ListenAndServe(*httpEndpoint, nil))
+		}()
+	}
+
+	skey, err := ioutil.ReadFile(*noteKey)
+	if err != nil {
+		glog.Exitf("Failed to read note key: %v", err)
+	}
+	signer, err := tiles.NewNoteSigner(strings.TrimSpace(string(skey)))
+	if err != nil {
+		glog.Exitf("Failed to load note key: %v", err)
+	}
+	bucket, err := export.OpenBucket(ctx, *destination)
+	if err != nil {
+		glog.Exitf("Failed to open %v: %v", *destination, err)
+	}
+
+	sp, err := storage.NewProvider(*storageSystem, mf)
+	if err != nil {
+		glog.Exitf("Failed to get storage provider: %v", err)
+	}
+	defer sp.Close()
+
+	e, err := export.New(sp.AdminStorage(), sp.LogStorage(), bucket, export.Options{
+		LogID:         *logID,
+		Origin:        *origin,
+		Signer:        signer,
+		SumDB:         *sumDB,
+		MetricFactory: mf,
+	})
+	if err != nil {
+		glog.Exitf("Failed to create exporter: %v", err)
+	}
+	if *interval == 0 {
+		size, err := e.Export(ctx)
+		if err != nil {
+			glog.Exitf("Export failed: %v", err)
+		}
+		glog.Infof("Exported tree of size %d", size)
+		return
+	}
+	e.Run(ctx, *interval)
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serverutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/clientv3"
+)
+
+// HealthCheck is a named check of one of the dependencies of a server, which
+// is run whenever "/readyz" is called on the mux.
+type HealthCheck struct {
+	Name string
+	// Check returns an error if the dependency isn't usable.
+	Check func(context.Context) error
+}
+
+// EtcdHealthCheck returns a HealthCheck which fails if the given etcd client
+// can't read from a quorum of the etcd cluster.
+func EtcdHealthCheck(client *clientv3.Client) HealthCheck {
+	return HealthCheck{
+		Name: "etcd",
+		Check: func(ctx context.Context) error {
+			// Reading a key which probably doesn't exist is cheap, but still
+			// requires a quorum as reads are linearizable by default.
+			_, err := client.Get(ctx, "health")
+			return err
+		},
+	}
+}
+
+// checkResult is the outcome of running a single HealthCheck.
+type checkResult struct {
+	name string
+	err  error
+}
+
+// runChecks runs all of the checks in parallel, and returns their results in
+// the same order. Checks which don't complete within timeout are reported as
+// failed, without waiting for them any longer.
+func runChecks(ctx context.Context, checks []HealthCheck, timeout time.Duration) []checkResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]checkResult, len(checks))
+	for i, c := range checks {
+		results[i] = checkResult{name: c.Name, err: fmt.Errorf("check timed out after %v", timeout)}
+	}
+	type indexedErr struct {
+		i   int
+		err error
+	}
+	done := make(chan indexedErr, len(checks))
+	for i, c := range checks {
+		go func(i int, check func(context.Context) error) {
+			done <- indexedErr{i: i, err: check(ctx)}
+		}(i, c.Check)
+	}
+	for range checks {
+		select {
+		case r := <-done:
+			results[r.i].err = r.err
+		case <-ctx.Done():
+			return results
+		}
+	}
+	return results
+}
+
+// readyChecks returns the checks that determine whether the server is ready
+// to serve: the storage check, if any, followed by ReadyChecks.
+func (m *Main) readyChecks() []HealthCheck {
+	var checks []HealthCheck
+	if m.IsHealthy != nil {
+		checks = append(checks, HealthCheck{Name: "storage", Check: m.IsHealthy})
+	}
+	return append(checks, m.ReadyChecks...)
+}
+
+// readyz reports whether all of the server's dependencies are usable. It
+// responds with 200-OK if they are, and 503 otherwise. The result of each
+// check is listed if any of them fail, or if the "verbose" query parameter is
+// set, in the format used by Kubernetes components.
+func (m *Main) readyz(rw http.ResponseWriter, req *http.Request) {
+	results := runChecks(req.Context(), m.readyChecks(), m.HealthyDeadline)
+
+	var b strings.Builder
+	failed := false
+	for _, r := range results {
+		if r.err != nil {
+			failed = true
+			fmt.Fprintf(&b, "[-]%s failed: %v\n", r.name, r.err)
+		} else {
+			fmt.Fprintf(&b, "[+]%s ok\n", r.name)
+		}
+	}
+
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	if failed {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(rw, "%sreadyz check failed\n", b.String())
+		return
+	}
+	if _, verbose := req.URL.Query()["verbose"]; verbose {
+		fmt.Fprintf(rw, "%sreadyz check passed\n", b.String())
+		return
+	}
+	rw.Write([]byte("ok"))
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serverutil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadyz(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errors.New("no quorum") }
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(time.Second)
+		return nil
+	}
+
+	for _, test := range []struct {
+		desc       string
+		isHealthy  func(context.Context) error
+		checks     []HealthCheck
+		url        string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			desc:       "no-checks",
+			url:        "/readyz",
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+		{
+			desc:       "all-ok",
+			isHealthy:  ok,
+			checks:     []HealthCheck{{Name: "election", Check: ok}},
+			url:        "/readyz",
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+		{
+			desc:       "all-ok-verbose",
+			isHealthy:  ok,
+			checks:     []HealthCheck{{Name: "election", Check: ok}},
+			url:        "/readyz?verbose",
+			wantStatus: http.StatusOK,
+			wantBody:   "[+]storage ok\n[+]election ok\nreadyz check passed\n",
+		},
+		{
+			desc:       "check-fails",
+			isHealthy:  ok,
+			checks:     []HealthCheck{{Name: "election", Check: fail}, {Name: "sequencing", Check: ok}},
+			url:        "/readyz",
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "[+]storage ok\n[-]election failed: no quorum\n[+]sequencing ok\nreadyz check failed\n",
+		},
+		{
+			desc:       "storage-fails",
+			isHealthy:  fail,
+			url:        "/readyz",
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "[-]storage failed: no quorum\nreadyz check failed\n",
+		},
+		{
+			desc:       "check-times-out",
+			checks:     []HealthCheck{{Name: "sequencing", Check: ok}, {Name: "etcd", Check: hang}},
+			url:        "/readyz",
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "[+]sequencing ok\n[-]etcd failed: check timed out after 100ms\nreadyz check failed\n",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			m := &Main{
+				IsHealthy:       test.isHealthy,
+				HealthyDeadline: 100 * time.Millisecond,
+				ReadyChecks:     test.checks,
+			}
+			w := httptest.NewRecorder()
+			m.readyz(w, httptest.NewRequest("GET", test.url, nil))
+			if got, want := w.Code, test.wantStatus; got != want {
+				t.Errorf("readyz: status=%v, want %v", got, want)
+			}
+			if got, want := w.Body.String(), test.wantBody; got != want {
+				t.Errorf("readyz: body=%q, want %q", got, want)
+			}
+		})
+	}
+}
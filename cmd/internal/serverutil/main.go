@@ -25,19 +25,27 @@ import (
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/server"
 	"github.com/google/trillian/server/admin"
 	"github.com/google/trillian/server/interceptor"
+	"github.com/google/trillian/storage"
 	"github.com/google/trillian/util"
 	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/compression"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.etcd.io/etcd/clientv3"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/naming"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/stats"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	etcdnaming "go.etcd.io/etcd/clientv3/naming"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 const (
@@ -68,6 +76,14 @@ type Main struct {
 	StatsPrefix string
 	QuotaDryRun bool
 
+	// Deadlines sets the deadlines of RPCs which arrive without one.
+	Deadlines interceptor.Deadlines
+	// LoadShedding configures the shedding of reads while the latency of RPCs
+	// is over a target. A zero Latency disables it.
+	LoadShedding interceptor.LoadSheddingOptions
+	// ConcurrencyLimits limits the number of RPCs in flight.
+	ConcurrencyLimits interceptor.ConcurrencyLimits
+
 	// RegisterServerFn is called to register RPC servers.
 	RegisterServerFn func(*grpc.Server, extension.Registry) error
 
@@ -76,8 +92,11 @@ type Main struct {
 	// on the /healthz endpoint.
 	IsHealthy func(context.Context) error
 	// HealthyDeadline is the maximum duration to wait wait for a successful
-	// IsHealthy() call.
+	// IsHealthy() call, or for the checks run by "/readyz".
 	HealthyDeadline time.Duration
+	// ReadyChecks are run, along with IsHealthy, whenever "/readyz" is called
+	// on the mux. The server is only reported as ready if all of them pass.
+	ReadyChecks []HealthCheck
 
 	// AllowedTreeTypes determines which types of trees may be created through the Admin Server
 	// bound by Main. nil means unrestricted.
@@ -87,6 +106,21 @@ type Main struct {
 	TreeDeleteThreshold   time.Duration
 	TreeDeleteMinInterval time.Duration
 
+	// QuotaStatsInterval is how often the quota tokens available for the
+	// global quotas and the quotas of each tree are exported as metrics.
+	// Zero disables the export, as do quota managers which can't peek tokens.
+	QuotaStatsInterval time.Duration
+
+	// TreeHealth configures the reporting of the health of each tree by the
+	// GRPC health service, which reports the health of the server as a whole
+	// regardless. A zero Interval disables it. Unset IsHealthy, Timeout and
+	// TreeTypes default to those of the server.
+	TreeHealth server.TreeHealthOptions
+
+	// StatsHandler, if set, is passed the stats of RPCs after the handler
+	// measuring the compression of responses, as GRPC servers only take one.
+	StatsHandler stats.Handler
+
 	// These will be added to the GRPC server options.
 	ExtraOptions []grpc.ServerOption
 }
@@ -125,10 +159,19 @@ func (m *Main) Run(ctx context.Context) error {
 	}
 	trillian.RegisterTrillianAdminServer(srv, admin.New(m.Registry, m.AllowedTreeTypes))
 	reflection.Register(srv)
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
 
 	if endpoint := m.HTTPEndpoint; endpoint != "" {
-		http.Handle("/metrics", promhttp.Handler())
+		// Exemplars are only exposed to scrapers which negotiate the
+		// OpenMetrics format; others get the usual text format.
+		http.Handle("/metrics", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+			promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})))
 		http.HandleFunc("/healthz", m.healthz)
+		http.HandleFunc("/readyz", m.readyz)
+		keys := admin.NewPublicKeysHandler(m.Registry.AdminStorage, m.AllowedTreeTypes, admin.PublicKeysPath)
+		http.Handle(admin.PublicKeysPath, keys)
+		http.Handle(admin.PublicKeysPath+"/", keys)
 
 		go func() {
 			glog.Infof("HTTP server starting on %v", endpoint)
@@ -166,6 +209,14 @@ func (m *Main) Run(ctx context.Context) error {
 		}()
 	}
 
+	if m.TreeHealth.Interval > 0 {
+		go server.NewTreeHealthChecker(m.Registry, hs, m.treeHealthOptions()).Run(ctx)
+	}
+
+	if m.QuotaStatsInterval > 0 && m.Registry.QuotaManager != nil {
+		go quota.MonitorAvailableTokens(ctx, m.Registry.QuotaManager, m.QuotaStatsInterval, m.quotaSpecs)
+	}
+
 	if err := srv.Serve(lis); err != nil {
 		glog.Errorf("RPC server terminated: %v", err)
 	}
@@ -179,17 +230,94 @@ func (m *Main) Run(ctx context.Context) error {
 	return nil
 }
 
+// treeHealthOptions returns m.TreeHealth, with the unset fields defaulted to
+// the settings of the server.
+func (m *Main) treeHealthOptions() server.TreeHealthOptions {
+	opts := m.TreeHealth
+	if opts.IsHealthy == nil {
+		opts.IsHealthy = m.IsHealthy
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = m.HealthyDeadline
+	}
+	if opts.TreeTypes == nil {
+		opts.TreeTypes = m.AllowedTreeTypes
+	}
+	return opts
+}
+
+// quotaSpecs returns the read and write specs of the global quotas and of the
+// quotas of every tree served by m.
+func (m *Main) quotaSpecs(ctx context.Context) ([]quota.Spec, error) {
+	trees, err := storage.ListTrees(ctx, m.Registry.AdminStorage, false /* includeDeleted */)
+	if err != nil {
+		return nil, err
+	}
+	specs := []quota.Spec{
+		{Group: quota.Global, Kind: quota.Read},
+		{Group: quota.Global, Kind: quota.Write},
+	}
+	for _, tree := range trees {
+		if !m.allowsTreeType(tree.TreeType) {
+			continue
+		}
+		specs = append(specs,
+			quota.Spec{Group: quota.Tree, Kind: quota.Read, TreeID: tree.TreeId},
+			quota.Spec{Group: quota.Tree, Kind: quota.Write, TreeID: tree.TreeId})
+	}
+	return specs, nil
+}
+
+func (m *Main) allowsTreeType(treeType trillian.TreeType) bool {
+	if m.AllowedTreeTypes == nil {
+		return true
+	}
+	for _, t := range m.AllowedTreeTypes {
+		if t == treeType {
+			return true
+		}
+	}
+	return false
+}
+
 // newGRPCServer starts a new Trillian gRPC server.
 func (m *Main) newGRPCServer() (*grpc.Server, error) {
 	stats := monitoring.NewRPCStatsInterceptor(clock.System, m.StatsPrefix, m.Registry.MetricFactory)
 	ti := interceptor.New(m.Registry.AdminStorage, m.Registry.QuotaManager, m.QuotaDryRun, m.Registry.MetricFactory)
+	ti.Maintenance = m.Registry.Maintenance
+
+	unary := []grpc.UnaryServerInterceptor{
+		interceptor.RequestID,
+		m.Deadlines.UnaryInterceptor,
+		stats.Interceptor(),
+	}
+	streaming := []grpc.StreamServerInterceptor{
+		m.Deadlines.StreamInterceptor,
+	}
+	if m.LoadShedding.Latency > 0 {
+		// Shed RPCs before they're charged quota.
+		ls, err := interceptor.NewLoadShedder(m.LoadShedding, m.Registry.MetricFactory, clock.System)
+		if err != nil {
+			return nil, err
+		}
+		unary = append(unary, ls.UnaryInterceptor)
+		streaming = append(streaming, ls.StreamInterceptor)
+	}
+	if m.ConcurrencyLimits.Global > 0 || len(m.ConcurrencyLimits.PerMethod) > 0 {
+		cl, err := interceptor.NewConcurrencyLimiter(m.ConcurrencyLimits, m.Registry.MetricFactory)
+		if err != nil {
+			return nil, err
+		}
+		unary = append(unary, cl.UnaryInterceptor)
+		streaming = append(streaming, cl.StreamInterceptor)
+	}
+	unary = append(unary, interceptor.ErrorWrapper, ti.UnaryInterceptor)
+	streaming = append(streaming, ti.StreamInterceptor)
 
 	serverOpts := []grpc.ServerOption{
-		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
-			stats.Interceptor(),
-			interceptor.ErrorWrapper,
-			ti.UnaryInterceptor,
-		)),
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unary...)),
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(streaming...)),
+		grpc.StatsHandler(compression.NewStatsHandler(m.StatsPrefix, m.Registry.MetricFactory, m.StatsHandler)),
 	}
 	serverOpts = append(serverOpts, m.ExtraOptions...)
 
@@ -237,3 +365,20 @@ func AnnounceSelf(ctx context.Context, client *clientv3.Client, etcdService, end
 		client.Revoke(ctx, leaseRsp.ID)
 	}
 }
+
+// TimeSource returns the time source for a binary to use. This is
+// clock.System unless fake is set, in which case it's a fake clock starting
+// at the current time, which only moves when advanced through clock.DebugPath
+// on the HTTP endpoint (see clock.FakeTimeSource.ServeHTTP). Fake clocks let
+// tests and load tests run binaries whose max root durations, guard windows
+// and mastership leases expire deterministically; they must not be used in
+// production.
+func TimeSource(fake bool) clock.TimeSource {
+	if !fake {
+		return clock.System
+	}
+	glog.Warningf("**** Running on a fake clock, advanced through %s ****", clock.DebugPath)
+	ts := clock.NewFake(time.Now())
+	http.Handle(clock.DebugPath, ts)
+	return ts
+}
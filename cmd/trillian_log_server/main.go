@@ -20,6 +20,8 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	_ "net/http/pprof" // Register pprof HTTP handlers.
 	"os"
 	"runtime/pprof"
@@ -34,6 +36,7 @@ import (
 	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/features"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/opencensus"
 	"github.com/google/trillian/monitoring/prometheus"
@@ -42,49 +45,112 @@ import (
 	"github.com/google/trillian/quota/etcd/quotaapi"
 	"github.com/google/trillian/quota/etcd/quotapb"
 	"github.com/google/trillian/server"
+	"github.com/google/trillian/server/interceptor"
+	"github.com/google/trillian/server/maintenance"
+	"github.com/google/trillian/server/validators"
 	"github.com/google/trillian/storage"
-	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/tiles"
+	"github.com/google/trillian/util/compression"
+	etcdelect "github.com/google/trillian/util/election2/etcd"
 	"go.etcd.io/etcd/clientv3"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
 
 	// Register key ProtoHandlers
 	_ "github.com/google/trillian/crypto/keys/der/proto"
 	_ "github.com/google/trillian/crypto/keys/pem/proto"
 	_ "github.com/google/trillian/crypto/keys/pkcs11/proto"
 
+	// Register password sources for encrypted PEM keys, in addition to
+	// environment variables and files.
+	_ "github.com/google/trillian/crypto/keys/pem/awssecrets"
+	_ "github.com/google/trillian/crypto/keys/pem/gcpsecrets"
+
+	// Register metrics exporters other than prometheus.
+	_ "github.com/google/trillian/monitoring/otlp"
+	_ "github.com/google/trillian/monitoring/statsd"
+
 	// Register supported storage providers.
 	_ "github.com/google/trillian/storage/cloudspanner"
+	_ "github.com/google/trillian/storage/etcd"
+	_ "github.com/google/trillian/storage/fdb"
 	_ "github.com/google/trillian/storage/mysql"
+	_ "github.com/google/trillian/storage/postgres"
+	_ "github.com/google/trillian/storage/routing"
+	_ "github.com/google/trillian/storage/sqlite"
 
 	// Load hashers
 	_ "github.com/google/trillian/merkle/rfc6962"
+	_ "github.com/google/trillian/merkle/sumdb"
 
-	// Load MySQL quota provider
+	// Load quota providers
 	_ "github.com/google/trillian/quota/mysqlqm"
+	_ "github.com/google/trillian/quota/redis/redisqm"
 )
 
 var (
 	rpcEndpoint     = flag.String("rpc_endpoint", "localhost:8090", "Endpoint for RPC requests (host:port)")
 	httpEndpoint    = flag.String("http_endpoint", "localhost:8091", "Endpoint for HTTP metrics (host:port, empty means disabled)")
+	fakeClock       = flag.Bool("fake_clock", false, "Test only: run on a fake clock which only moves when advanced through /debug/clock on --http_endpoint")
 	healthzTimeout  = flag.Duration("healthz_timeout", time.Second*5, "Timeout used during healthz checks")
 	tlsCertFile     = flag.String("tls_cert_file", "", "Path to the TLS server certificate. If unset, the server will use unsecured connections.")
 	tlsKeyFile      = flag.String("tls_key_file", "", "Path to the TLS server key. If unset, the server will use unsecured connections.")
 	etcdService     = flag.String("etcd_service", "trillian-logserver", "Service name to announce ourselves under")
 	etcdHTTPService = flag.String("etcd_http_service", "trillian-logserver-http", "Service name to announce our HTTP endpoint under")
+	signerLockDir   = flag.String("signer_lock_file_path", "/test/multimaster", "etcd lock file directory path of the log signers, which the MoveMastership admin RPC sets placement hints next to")
+
+	maintenanceReason = flag.String("maintenance_reason", "", "If set, the server starts in read-only maintenance for this reason: write RPCs fail with FAILED_PRECONDITION while reads continue, until the SetMaintenanceMode admin RPC takes it out of maintenance")
+
+	quotaSystem        = flag.String("quota_system", "mysql", fmt.Sprintf("Quota system to use. One of: %v", quota.Providers()))
+	quotaDryRun        = flag.Bool("quota_dry_run", false, "If true no requests are blocked due to lack of tokens")
+	quotaStatsInterval = flag.Duration("quota_stats_interval", time.Minute, "How often the available tokens of global and per-tree quotas are exported as metrics (0 means never)")
+
+	treeHealthInterval = flag.Duration("tree_health_interval", 30*time.Second, "How often the health of each tree, reported by the GRPC health service as trillian.log.<tree ID>, is checked (0 means never)")
+	maxRootAge         = flag.Duration("max_root_age", 0, "If non-zero, how much older than their max_root_duration the latest roots of active logs can get before the logs are reported as unhealthy by the GRPC health service, as their signer has stalled")
+
+	rpcDefaultTimeout = flag.Duration("rpc_default_timeout", 0, "If non-zero, the deadline set on unary RPCs which arrive without one, unless --rpc_method_timeouts sets another for their method")
+	rpcMethodTimeouts = flag.String("rpc_method_timeouts", "", "Comma-separated list of method=duration pairs setting the deadlines of RPCs of the given methods, unary or streaming, which arrive without one, e.g. GetLeavesByRange=30s,QueueLeaves=5s. Methods are named by their full name or name alone")
+
+	loadSheddingLatency    = flag.Duration("load_shedding_latency", 0, "If non-zero, reads are shed while the --load_shedding_percentile latency of RPCs is over this, bulk reads of leaves first, so that writes keep being served when storage is saturated")
+	loadSheddingPercentile = flag.Float64("load_shedding_percentile", interceptor.DefaultLoadSheddingPercentile, "Percentile of the latency of RPCs compared to --load_shedding_latency, from 0 to 1")
+	loadSheddingInterval   = flag.Duration("load_shedding_interval", interceptor.DefaultLoadSheddingInterval, "How often the latency of RPCs is compared to --load_shedding_latency, and how long clients of shed RPCs are told to wait before retrying")
+
+	maxConcurrentRPCs       = flag.Int("max_concurrent_rpcs", 0, "If non-zero, the number of RPCs which may be in flight at once, beyond which they're queued")
+	maxConcurrentMethodRPCs = flag.String("max_concurrent_method_rpcs", "", "Comma-separated list of method=limit pairs setting the numbers of RPCs of the given methods which may be in flight at once, beyond which they're queued, e.g. GetLeavesByRange=10. Methods are named by their full name or name alone")
+	concurrencyQueueTimeout = flag.Duration("concurrency_queue_timeout", time.Second, "How long RPCs queued by --max_concurrent_rpcs or --max_concurrent_method_rpcs wait for others to complete before failing (0 means they fail at once)")
+
+	rpcGzipLevel = flag.Int("rpc_gzip_level", -1, "Level of the gzip compression of responses to clients which ask for it, from 1 (fastest) to 9 (smallest), or -1 for the default level")
+
+	maxResponseBytes = flag.Int("max_response_bytes", server.DefaultMaxResponseBytes, "Size in bytes which read RPCs returning many leaves keep their responses under, by returning a page token to continue from instead of the rest of the leaves. Should be at most the maximum size of the messages clients receive. 0 means no limit")
+
+	leafValidators = flag.String("leaf_validators", "", fmt.Sprintf("Comma-separated list of key=validator pairs configuring the checks of the values of leaves written to trees, keyed by tree ID or tree type (LOG or PREORDERED_LOG), e.g. LOG=json,1234=x509-der. A validator for a tree ID takes precedence over one for its type. Validators are any of: %v, some taking an argument after a colon, e.g. json-schema:<URL>", validators.Names()))
+
+	rootAgeInterval = flag.Duration("root_age_interval", 30*time.Second, "How often the latest roots of active logs are read to export their ages (0 means never)")
+
+	verifyRootSignatures = flag.Bool("verify_root_signatures", false, "If true, read RPCs check the signatures of the log roots they read from storage, and fail rather than serve unverifiable roots")
 
-	quotaSystem = flag.String("quota_system", "mysql", fmt.Sprintf("Quota system to use. One of: %v", quota.Providers()))
-	quotaDryRun = flag.Bool("quota_dry_run", false, "If true no requests are blocked due to lack of tokens")
+	tlogTilesPath         = flag.String("tlog_tiles_path", "", "If set, logs are served in the C2SP tlog-tiles layout under this HTTP path, e.g. /tlog")
+	tlogTilesOriginPrefix = flag.String("tlog_tiles_origin_prefix", "", "Prefix of the checkpoint origins of logs served by --tlog_tiles_path, which is followed by the log ID")
+	tlogTilesNoteKey      = flag.String("tlog_tiles_note_key", "", "Path to the file holding the note signing key of checkpoints served by --tlog_tiles_path")
+
+	sumDBPath = flag.String("sumdb_path", "", "If set, logs with the SUMDB_TLOG_SHA256 hash strategy are served in the layout of Go checksum databases under this HTTP path, signed with --tlog_tiles_note_key")
 
 	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
 
+	metricsExporters = flag.String("metrics_exporters", prometheus.ExporterName, fmt.Sprintf("Comma-separated list of systems to export metrics to. Any of: %v", monitoring.Exporters()))
+
 	treeGCEnabled            = flag.Bool("tree_gc", true, "If true, tree garbage collection (hard-deletion) is periodically performed")
 	treeDeleteThreshold      = flag.Duration("tree_delete_threshold", serverutil.DefaultTreeDeleteThreshold, "Minimum period a tree has to remain deleted before being hard-deleted")
 	treeDeleteMinRunInterval = flag.Duration("tree_delete_min_run_interval", serverutil.DefaultTreeDeleteMinInterval, "Minimum interval between tree garbage collection sweeps. Actual runs happen randomly between [minInterval,2*minInterval).")
 
-	tracing          = flag.Bool("tracing", false, "If true opencensus Stackdriver tracing will be enabled. See https://opencensus.io/.")
+	tracing          = flag.Bool("tracing", false, "If true opencensus tracing will be enabled. See https://opencensus.io/.")
+	tracingExporter  = flag.String("tracing_exporter", opencensus.StackdriverExporterName, fmt.Sprintf("System to send traces to, if tracing is enabled. One of: %v", opencensus.TraceExporters()))
 	tracingProjectID = flag.String("tracing_project_id", "", "project ID to pass to stackdriver. Can be empty for GCP, consult docs for other platforms.")
 	tracingPercent   = flag.Int("tracing_percent", 0, "Percent of requests to be traced. Zero is a special case to use the DefaultSampler")
 
+	featureFlagsFile           = flag.String("feature_flags_file", "", "If set, path of a file of feature flag overrides, which turn features on and off for all trees or single trees without restarts, of the form name=bool or name@treeID=bool separated by commas or newlines, e.g. shared_subtree_cache=false. The SetFeatureFlags admin RPC overrides them until the file changes")
+	featureFlagsReloadInterval = flag.Duration("feature_flags_reload_interval", time.Minute, "How often --feature_flags_file is reloaded if it has changed (0 means never)")
+
 	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
 
 	// Profiling related flags.
@@ -104,17 +170,30 @@ func main() {
 
 	ctx := context.Background()
 
-	var options []grpc.ServerOption
-	mf := prometheus.MetricFactory{}
+	var statsHandler stats.Handler
+	mf, err := monitoring.NewExporterMetricFactory(strings.Split(*metricsExporters, ",")...)
+	if err != nil {
+		glog.Exitf("Failed to initialize metrics exporters: %v", err)
+	}
 	monitoring.SetStartSpan(opencensus.StartSpan)
+	monitoring.SetTraceID(opencensus.TraceID)
 
 	if *tracing {
-		opts, err := opencensus.EnableRPCServerTracing(*tracingProjectID, *tracingPercent)
+		// Enable the server request counter tracing etc.
+		statsHandler, err = opencensus.NewRPCServerStatsHandler(*tracingExporter, *tracingProjectID, *tracingPercent)
 		if err != nil {
-			glog.Exitf("Failed to initialize stackdriver / opencensus tracing: %v", err)
+			glog.Exitf("Failed to initialize %v / opencensus tracing: %v", *tracingExporter, err)
+		}
+	}
+
+	if err := compression.SetGzipLevel(*rpcGzipLevel); err != nil {
+		glog.Exitf("Invalid --rpc_gzip_level: %v", err)
+	}
+
+	if *featureFlagsFile != "" {
+		if err := features.Default.Watch(ctx, *featureFlagsFile, *featureFlagsReloadInterval); err != nil {
+			glog.Exitf("Failed to load --feature_flags_file: %v", err)
 		}
-		// Enable the server request counter tracing etc.
-		options = append(options, opts...)
 	}
 
 	sp, err := storage.NewProvider(*storageSystem, mf)
@@ -147,6 +226,7 @@ func main() {
 		glog.Exitf("Error creating quota manager: %v", err)
 	}
 
+	timeSource := serverutil.TimeSource(*fakeClock)
 	registry := extension.Registry{
 		AdminStorage:  sp.AdminStorage(),
 		LogStorage:    sp.LogStorage(),
@@ -155,6 +235,16 @@ func main() {
 		NewKeyProto: func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
 			return der.NewProtoFromSpec(spec)
 		},
+		Maintenance: &maintenance.Mode{},
+	}
+	if *maintenanceReason != "" {
+		registry.Maintenance.Set(*maintenanceReason)
+	}
+	if client != nil {
+		// The log server doesn't run elections, but sets placement hints for
+		// the signers' elections.
+		hostname, _ := os.Hostname()
+		registry.ElectionFactory = etcdelect.NewFactory(fmt.Sprintf("%s.%d", hostname, os.Getpid()), client, *signerLockDir)
 	}
 
 	// Enable CPU profile if requested.
@@ -164,22 +254,84 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
+	// Serving requires etcd if it's used for quotas or service discovery.
+	var readyChecks []serverutil.HealthCheck
+	if client != nil {
+		readyChecks = append(readyChecks, serverutil.EtcdHealthCheck(client))
+	}
+
+	if *tlogTilesPath != "" || *sumDBPath != "" {
+		if *tlogTilesNoteKey == "" {
+			glog.Exit("--tlog_tiles_note_key is required with --tlog_tiles_path and --sumdb_path")
+		}
+		skey, err := ioutil.ReadFile(*tlogTilesNoteKey)
+		if err != nil {
+			glog.Exitf("Failed to read note key: %v", err)
+		}
+		signer, err := tiles.NewNoteSigner(strings.TrimSpace(string(skey)))
+		if err != nil {
+			glog.Exitf("Failed to load note key: %v", err)
+		}
+		if *tlogTilesPath != "" {
+			path := strings.TrimRight(*tlogTilesPath, "/")
+			http.Handle(path+"/", tiles.NewHandler(sp.AdminStorage(), sp.LogStorage(), path, *tlogTilesOriginPrefix, signer))
+		}
+		if *sumDBPath != "" {
+			path := strings.TrimRight(*sumDBPath, "/")
+			http.Handle(path+"/", tiles.NewSumDBHandler(sp.AdminStorage(), sp.LogStorage(), path, signer))
+		}
+	}
+
+	methodTimeouts, err := interceptor.ParseMethodDeadlines(*rpcMethodTimeouts)
+	if err != nil {
+		glog.Exitf("Invalid --rpc_method_timeouts: %v", err)
+	}
+
+	methodLimits, err := interceptor.ParseMethodLimits(*maxConcurrentMethodRPCs)
+	if err != nil {
+		glog.Exitf("Invalid --max_concurrent_method_rpcs: %v", err)
+	}
+
+	leafValidatorConfig, err := validators.ParseConfig(*leafValidators)
+	if err != nil {
+		glog.Exitf("Invalid --leaf_validators: %v", err)
+	}
+
 	m := serverutil.Main{
 		RPCEndpoint:  *rpcEndpoint,
 		HTTPEndpoint: *httpEndpoint,
 		TLSCertFile:  *tlsCertFile,
 		TLSKeyFile:   *tlsKeyFile,
 		StatsPrefix:  "log",
-		ExtraOptions: options,
+		StatsHandler: statsHandler,
 		QuotaDryRun:  *quotaDryRun,
 		DBClose:      sp.Close,
 		Registry:     registry,
+		Deadlines:    interceptor.Deadlines{Default: *rpcDefaultTimeout, PerMethod: methodTimeouts},
+		LoadShedding: interceptor.LoadSheddingOptions{
+			Latency:    *loadSheddingLatency,
+			Percentile: *loadSheddingPercentile,
+			Interval:   *loadSheddingInterval,
+		},
+		ConcurrencyLimits: interceptor.ConcurrencyLimits{
+			Global:       *maxConcurrentRPCs,
+			PerMethod:    methodLimits,
+			QueueTimeout: *concurrencyQueueTimeout,
+		},
 		RegisterServerFn: func(s *grpc.Server, registry extension.Registry) error {
-			logServer := server.NewTrillianLogRPCServer(registry, clock.System)
+			logServer := server.NewTrillianLogRPCServer(registry, timeSource)
+			if *verifyRootSignatures {
+				logServer.VerifyRootSignatures()
+			}
+			logServer.LimitResponseSize(*maxResponseBytes)
+			logServer.ValidateLeaves(leafValidatorConfig)
 			if err := logServer.IsHealthy(); err != nil {
 				return err
 			}
 			trillian.RegisterTrillianLogServer(s, logServer)
+			if *rootAgeInterval > 0 {
+				go logServer.MonitorRootAges(ctx, *rootAgeInterval)
+			}
 			if *quotaSystem == etcd.QuotaManagerName {
 				quotapb.RegisterQuotaServer(s, quotaapi.NewServer(client))
 			}
@@ -190,10 +342,16 @@ func main() {
 			return as.CheckDatabaseAccessible(ctx)
 		},
 		HealthyDeadline:       *healthzTimeout,
+		ReadyChecks:           readyChecks,
 		AllowedTreeTypes:      []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG},
 		TreeGCEnabled:         *treeGCEnabled,
 		TreeDeleteThreshold:   *treeDeleteThreshold,
 		TreeDeleteMinInterval: *treeDeleteMinRunInterval,
+		QuotaStatsInterval:    *quotaStatsInterval,
+		TreeHealth: server.TreeHealthOptions{
+			Interval:   *treeHealthInterval,
+			MaxRootAge: *maxRootAge,
+		},
 	}
 
 	if err := m.Run(ctx); err != nil {
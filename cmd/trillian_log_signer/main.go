@@ -17,8 +17,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	_ "net/http/pprof" // Register pprof HTTP handlers.
 	"os"
 	"runtime/pprof"
@@ -29,18 +32,23 @@ import (
 	"github.com/google/trillian/cmd"
 	"github.com/google/trillian/cmd/internal/serverutil"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/features"
 	"github.com/google/trillian/log"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/opencensus"
 	"github.com/google/trillian/monitoring/prometheus"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/quota/etcd"
+	"github.com/google/trillian/server"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/tiles"
+	"github.com/google/trillian/tiles/export"
 	"github.com/google/trillian/util"
-	"github.com/google/trillian/util/clock"
 	"github.com/google/trillian/util/election"
 	"github.com/google/trillian/util/election2"
 	etcdelect "github.com/google/trillian/util/election2/etcd"
+	"github.com/google/trillian/util/mergedelay"
+	"github.com/google/trillian/util/priority"
 	"go.etcd.io/etcd/clientv3"
 	"google.golang.org/grpc"
 
@@ -51,31 +59,79 @@ import (
 	_ "github.com/google/trillian/crypto/keys/pem/proto"
 	_ "github.com/google/trillian/crypto/keys/pkcs11/proto"
 
+	// Register password sources for encrypted PEM keys, in addition to
+	// environment variables and files.
+	_ "github.com/google/trillian/crypto/keys/pem/awssecrets"
+	_ "github.com/google/trillian/crypto/keys/pem/gcpsecrets"
+
+	// Register metrics exporters other than prometheus.
+	_ "github.com/google/trillian/monitoring/otlp"
+	_ "github.com/google/trillian/monitoring/statsd"
+
 	// Register supported storage providers.
 	_ "github.com/google/trillian/storage/cloudspanner"
+	_ "github.com/google/trillian/storage/etcd"
+	_ "github.com/google/trillian/storage/fdb"
 	_ "github.com/google/trillian/storage/mysql"
+	_ "github.com/google/trillian/storage/postgres"
+	_ "github.com/google/trillian/storage/routing"
+	_ "github.com/google/trillian/storage/sqlite"
 
 	// Load hashers
 	_ "github.com/google/trillian/merkle/rfc6962"
+	_ "github.com/google/trillian/merkle/sumdb"
 
-	// Load MySQL quota provider
+	// Load quota providers
 	_ "github.com/google/trillian/quota/mysqlqm"
+	_ "github.com/google/trillian/quota/redis/redisqm"
 )
 
 var (
 	rpcEndpoint              = flag.String("rpc_endpoint", "localhost:8090", "Endpoint for RPC requests (host:port)")
 	httpEndpoint             = flag.String("http_endpoint", "localhost:8091", "Endpoint for HTTP (host:port, empty means disabled)")
+	fakeClock                = flag.Bool("fake_clock", false, "Test only: run on a fake clock which only moves when advanced through /debug/clock on --http_endpoint")
 	tlsCertFile              = flag.String("tls_cert_file", "", "Path to the TLS server certificate. If unset, the server will use unsecured connections.")
 	tlsKeyFile               = flag.String("tls_key_file", "", "Path to the TLS server key. If unset, the server will use unsecured connections.")
 	sequencerIntervalFlag    = flag.Duration("sequencer_interval", 100*time.Millisecond, "Time between each sequencing pass through all logs")
 	batchSizeFlag            = flag.Int("batch_size", 1000, "Max number of leaves to process per batch")
 	numSeqFlag               = flag.Int("num_sequencers", 10, "Number of sequencer workers to run in parallel")
 	sequencerGuardWindowFlag = flag.Duration("sequencer_guard_window", 0, "If set, the time elapsed before submitted leaves are eligible for sequencing")
+	quarantineAfterFailures  = flag.Int("quarantine_after_failures", 0, "If non-zero, the number of consecutive failed sequencing passes after which a log is sequenced one leaf at a time, and failing leaves are moved to the dead-letter store")
+	rootAgeInterval          = flag.Duration("root_age_interval", 10*time.Second, "How often the ages of the latest roots of logs this instance is master for are exported and checked against their max root durations (0 means never)")
 	forceMaster              = flag.Bool("force_master", false, "If true, assume master for all logs")
+	verifyOnly               = flag.Bool("verify_only", false, "If true, don't sequence, but re-verify the most recent leaves of every log against the stored tree and roots on each pass, without writing anything, and report mismatches. Consider raising --sequencer_interval in this mode")
+	verifyWindow             = flag.Int64("verify_window", 10000, "Number of most recent leaves of each log re-verified per pass with --verify_only (0 means all leaves)")
 	etcdHTTPService          = flag.String("etcd_http_service", "trillian-logsigner-http", "Service name to announce our HTTP endpoint under")
 	lockDir                  = flag.String("lock_file_path", "/test/multimaster", "etcd lock file directory path")
 	healthzTimeout           = flag.Duration("healthz_timeout", time.Second*5, "Timeout used during healthz checks")
 
+	treeHealthInterval = flag.Duration("tree_health_interval", 30*time.Second, "How often the health of each tree, reported by the GRPC health service as trillian.log.<tree ID>, is checked (0 means never)")
+	maxRootAge         = flag.Duration("max_root_age", 0, "If non-zero, how much older than their max_root_duration the latest roots of active logs can get before the logs are reported as unhealthy by the GRPC health service, as their signer has stalled")
+
+	auditAppendOnly = flag.Bool("audit_append_only", false, "If true, don't sequence, but check on each pass that the latest root of every log extends the previously audited one append-only, from the leaves in storage, without writing anything, and report violations")
+
+	maxMergeDelay      = flag.Duration("max_merge_delay", 0, "Maximum merge delay (MMD) of logs without one in --max_merge_delays, which the delay between queuing and integration of leaves is checked against (0 means none)")
+	maxMergeDelays     = flag.String("max_merge_delays", "", "Comma-separated list of treeID=duration pairs setting the MMDs of individual logs, e.g. 1234=24h,5678=30m")
+	mmdRiskFraction    = flag.Float64("mmd_risk_fraction", mergedelay.DefaultRiskFraction, "Fraction of its MMD which the merge delay of a log has to reach for the log to be at risk of violating it")
+	refuseLeavesAtRisk = flag.Bool("refuse_leaves_at_mmd_risk", false, "If true, the write quota of logs at risk of violating their MMD is drained until they catch up, so that new leaves are refused. Needs a quota system with per-tree write quotas, such as etcd")
+
+	sequencingPriority   = flag.String("sequencing_priority", "normal", "Priority class of logs without one in --sequencing_priorities, which decides the order logs are sequenced in each pass. One of: high, normal, best-effort")
+	sequencingPriorities = flag.String("sequencing_priorities", "", "Comma-separated list of treeID=class pairs setting the priority classes of individual logs, e.g. 1234=high,5678=best-effort")
+	starveAfterPasses    = flag.Int("starve_after_passes", 3, "Number of passes in a row a log can go without being sequenced before it is scheduled ahead of higher priority logs (0 means never)")
+
+	prefetchOnMastership = flag.Bool("prefetch_on_mastership", false, "If true, the subtrees which the first sequencing pass over a log reads are pre-fetched when this instance becomes master for it. Only useful with a shared subtree cache, such as --mysql_shared_subtree_cache_size")
+
+	vacuumInterval      = flag.Duration("subtree_vacuum_interval", 0, "If non-zero, how often superseded revisions of the subtrees of logs this instance is master for are deleted from storage. Only supported by MySQL and PostgreSQL storage")
+	vacuumKeepRevisions = flag.Int64("subtree_vacuum_keep_revisions", 1000, "Number of revisions before the latest one of each log whose subtrees are kept by --subtree_vacuum_interval, for reads which started before newer roots were written")
+	vacuumBatchSize     = flag.Int("subtree_vacuum_batch_size", 1000, "Maximum number of subtree revisions deleted per transaction by --subtree_vacuum_interval")
+	vacuumMaxBatches    = flag.Int("subtree_vacuum_max_batches", 100, "Maximum number of batches of subtree revisions deleted per log in each vacuum run (0 means no limit)")
+
+	replicateTo            = flag.String("replicate_to", "", "If set, a directory, or gs://<bucket>[/<prefix>] or s3://<bucket>[/<prefix>] URL, which the tiles and checkpoints of logs this instance is master for are replicated to as they grow, under <log ID>/")
+	replicateNoteKey       = flag.String("replicate_note_key", "", "Path to the file holding the note signing key of replicated checkpoints")
+	replicateOriginPrefix  = flag.String("replicate_origin_prefix", "", "Prefix of the origins of replicated checkpoints, which is followed by the log ID")
+	replicateRetryInterval = flag.Duration("replicate_retry_interval", 10*time.Second, "How often the replication of logs which failed is retried")
+	replicateWorkers       = flag.Int("replicate_workers", 4, "Number of logs replicated in parallel")
+
 	quotaSystem         = flag.String("quota_system", "mysql", fmt.Sprintf("Quota system to use. One of: %v", quota.Providers()))
 	quotaIncreaseFactor = flag.Float64("quota_increase_factor", log.QuotaIncreaseFactor,
 		"Increase factor for tokens replenished by sequencing-based quotas (1 means a 1:1 relationship between sequenced leaves and replenished tokens)."+
@@ -83,9 +139,19 @@ var (
 
 	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
 
+	metricsExporters = flag.String("metrics_exporters", prometheus.ExporterName, fmt.Sprintf("Comma-separated list of systems to export metrics to. Any of: %v", monitoring.Exporters()))
+
 	preElectionPause   = flag.Duration("pre_election_pause", 1*time.Second, "Maximum time to wait before starting elections")
 	masterHoldInterval = flag.Duration("master_hold_interval", 60*time.Second, "Minimum interval to hold mastership for")
 	masterHoldJitter   = flag.Duration("master_hold_jitter", 120*time.Second, "Maximal random addition to --master_hold_interval")
+	stickyMastership   = flag.Bool("sticky_mastership", false, "If true, mastership of a log is held until it's lost, or moved to another signer with the MoveMastership admin RPC, rather than resigned after --master_hold_interval")
+	instanceIDFlag     = flag.String("instance_id", "", "ID of this signer in elections, which MoveMastership names signers by. Must be unique, and should be stable across restarts. Defaults to <hostname>.<pid>")
+	shardMastership    = flag.Bool("shard_mastership", false, "If true, logs are sharded between the signers registered under --members_path by rendezvous hashing, and each signer only runs elections for the logs it owns. Needs --etcd_servers")
+	membersDir         = flag.String("members_path", "/test/members", "etcd directory path which signers register under with --shard_mastership")
+	shardReplicas      = flag.Int("shard_replicas", log.DefaultShardReplicas, "Number of signers which own each log with --shard_mastership, and run elections for it")
+
+	featureFlagsFile           = flag.String("feature_flags_file", "", "If set, path of a file of feature flag overrides, which turn features on and off for all trees or single trees without restarts, of the form name=bool or name@treeID=bool separated by commas or newlines, e.g. shared_subtree_cache=false")
+	featureFlagsReloadInterval = flag.Duration("feature_flags_reload_interval", time.Minute, "How often --feature_flags_file is reloaded if it has changed (0 means never)")
 
 	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
 
@@ -107,8 +173,12 @@ func main() {
 	glog.CopyStandardLogTo("WARNING")
 	glog.Info("**** Log Signer Starting ****")
 
-	mf := prometheus.MetricFactory{}
+	mf, err := monitoring.NewExporterMetricFactory(strings.Split(*metricsExporters, ",")...)
+	if err != nil {
+		glog.Exitf("Failed to initialize metrics exporters: %v", err)
+	}
 	monitoring.SetStartSpan(opencensus.StartSpan)
+	monitoring.SetTraceID(opencensus.TraceID)
 
 	sp, err := storage.NewProvider(*storageSystem, mf)
 	if err != nil {
@@ -131,10 +201,24 @@ func main() {
 	defer cancel()
 	go util.AwaitSignal(ctx, cancel)
 
-	hostname, _ := os.Hostname()
-	instanceID := fmt.Sprintf("%s.%d", hostname, os.Getpid())
+	if *featureFlagsFile != "" {
+		if err := features.Default.Watch(ctx, *featureFlagsFile, *featureFlagsReloadInterval); err != nil {
+			glog.Exitf("Failed to load --feature_flags_file: %v", err)
+		}
+	}
+
+	instanceID := *instanceIDFlag
+	if instanceID == "" {
+		hostname, _ := os.Hostname()
+		instanceID = fmt.Sprintf("%s.%d", hostname, os.Getpid())
+	}
 	var electionFactory election2.Factory
 	switch {
+	case *verifyOnly:
+		// Verification doesn't write anything, so every instance can verify
+		// every log.
+		glog.Warning("**** Verifying all logs, not sequencing ****")
+		electionFactory = election2.NoopFactory{}
 	case *forceMaster:
 		glog.Warning("**** Acting as master for all logs ****")
 		electionFactory = election2.NoopFactory{}
@@ -143,6 +227,9 @@ func main() {
 	default:
 		glog.Exit("Either --force_master or --etcd_servers must be supplied")
 	}
+	timeSource := serverutil.TimeSource(*fakeClock)
+	monitoredElections := election2.NewMonitoredFactory(electionFactory, mf, timeSource, nil)
+	electionFactory = monitoredElections
 
 	qm, err := quota.NewManager(*quotaSystem)
 	if err != nil {
@@ -162,28 +249,102 @@ func main() {
 		// Announce our endpoint to etcd if so configured.
 		unannounceHTTP := serverutil.AnnounceSelf(ctx, client, *etcdHTTPService, *httpEndpoint)
 		defer unannounceHTTP()
+		// Report which instance masters each log.
+		http.Handle("/debug/mastership", monitoredElections)
 	}
 
 	// Start the sequencing loop, which will run until we terminate the process. This controls
 	// both sequencing and signing.
 	// TODO(Martin2112): Should respect read only mode and the flags in tree control etc
 	log.QuotaIncreaseFactor = *quotaIncreaseFactor
-	sequencerManager := log.NewSequencerManager(registry, *sequencerGuardWindowFlag)
+	perTreeMMDs, err := mergedelay.ParsePerTree(*maxMergeDelays)
+	if err != nil {
+		glog.Exitf("Invalid --max_merge_delays: %v", err)
+	}
+	mergeDelays := mergedelay.Policy{
+		Default:          *maxMergeDelay,
+		PerTree:          perTreeMMDs,
+		RiskFraction:     *mmdRiskFraction,
+		RefuseWhenAtRisk: *refuseLeavesAtRisk,
+	}
+	defaultClass, err := priority.ParseClass(*sequencingPriority)
+	if err != nil {
+		glog.Exitf("Invalid --sequencing_priority: %v", err)
+	}
+	perTreeClasses, err := priority.ParsePerTree(*sequencingPriorities)
+	if err != nil {
+		glog.Exitf("Invalid --sequencing_priorities: %v", err)
+	}
+	var operation log.Operation = log.NewSequencerManager(registry, *sequencerGuardWindowFlag)
+	if *verifyOnly && *auditAppendOnly {
+		glog.Exit("Only one of --verify_only and --audit_append_only can be set")
+	}
+	if *verifyOnly {
+		operation = log.NewVerifierManager(registry, *verifyWindow)
+	}
+	if *auditAppendOnly {
+		operation = log.NewAuditorManager(registry)
+	}
 	info := log.OperationInfo{
-		Registry:    registry,
-		BatchSize:   *batchSizeFlag,
-		NumWorkers:  *numSeqFlag,
-		RunInterval: *sequencerIntervalFlag,
-		TimeSource:  clock.System,
+		Registry:                registry,
+		BatchSize:               *batchSizeFlag,
+		NumWorkers:              *numSeqFlag,
+		RunInterval:             *sequencerIntervalFlag,
+		TimeSource:              timeSource,
+		QuarantineAfterFailures: *quarantineAfterFailures,
+		RootAgeInterval:         *rootAgeInterval,
+		MergeDelays:             mergeDelays,
+		PrepareOnMastership:     *prefetchOnMastership,
+		Priorities: priority.Policy{
+			Default:     defaultClass,
+			PerTree:     perTreeClasses,
+			StarveAfter: *starveAfterPasses,
+		},
 		ElectionConfig: election.RunnerConfig{
 			PreElectionPause:   *preElectionPause,
 			MasterHoldInterval: *masterHoldInterval,
 			MasterHoldJitter:   *masterHoldJitter,
-			TimeSource:         clock.System,
+			Sticky:             *stickyMastership,
+			TimeSource:         timeSource,
 		},
 	}
-	sequencerTask := log.NewOperationManager(info, sequencerManager)
+	if *shardMastership {
+		if client == nil || *forceMaster || *verifyOnly {
+			glog.Exit("--shard_mastership needs --etcd_servers, and can't be set with --force_master or --verify_only")
+		}
+		members, err := etcdelect.NewMembership(ctx, instanceID, client, *membersDir)
+		if err != nil {
+			glog.Exitf("Failed to register as a member: %v", err)
+		}
+		defer members.Close()
+		info.Membership = members
+		info.InstanceID = instanceID
+		info.ShardReplicas = *shardReplicas
+	}
+	if *replicateTo != "" {
+		if *verifyOnly || *auditAppendOnly {
+			glog.Exit("--replicate_to can't be set with --verify_only or --audit_append_only")
+		}
+		r, err := newReplicator(ctx, registry)
+		if err != nil {
+			glog.Exitf("Failed to set up replication to %v: %v", *replicateTo, err)
+		}
+		go r.Run(ctx)
+		info.Replicator = r
+	}
+	if *vacuumInterval > 0 && (*verifyOnly || *auditAppendOnly) {
+		glog.Exit("--subtree_vacuum_interval can't be set with --verify_only or --audit_append_only")
+	}
+	sequencerTask := log.NewOperationManager(info, operation)
 	go sequencerTask.OperationLoop(ctx)
+	if *vacuumInterval > 0 {
+		vacuum := log.NewSubtreeVacuum(registry, log.SubtreeVacuumOptions{
+			KeepRevisions: *vacuumKeepRevisions,
+			BatchSize:     *vacuumBatchSize,
+			MaxBatches:    *vacuumMaxBatches,
+		}, sequencerTask.HeldLogIDs)
+		go vacuum.Run(ctx, *vacuumInterval)
+	}
 
 	// Enable CPU profile if requested
 	if *cpuProfile != "" {
@@ -206,6 +367,15 @@ func main() {
 		},
 		IsHealthy:       sp.AdminStorage().CheckDatabaseAccessible,
 		HealthyDeadline: *healthzTimeout,
+		ReadyChecks: []serverutil.HealthCheck{
+			{Name: "election", Check: monitoredElections.CheckHealth},
+			{Name: "sequencing", Check: sequencerTask.CheckProgress},
+		},
+		TreeHealth: server.TreeHealthOptions{
+			Interval:   *treeHealthInterval,
+			MaxRootAge: *maxRootAge,
+			TreeTypes:  []tpb.TreeType{tpb.TreeType_LOG, tpb.TreeType_PREORDERED_LOG},
+		},
 	}
 
 	if err := m.Run(ctx); err != nil {
@@ -222,6 +392,33 @@ func main() {
 	time.Sleep(time.Second * 5)
 }
 
+// newReplicator returns a Replicator of the logs in the registry's storage to
+// --replicate_to.
+func newReplicator(ctx context.Context, registry extension.Registry) (*export.Replicator, error) {
+	if *replicateNoteKey == "" {
+		return nil, errors.New("--replicate_note_key is required")
+	}
+	skey, err := ioutil.ReadFile(*replicateNoteKey)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := tiles.NewNoteSigner(strings.TrimSpace(string(skey)))
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := export.OpenBucket(ctx, *replicateTo)
+	if err != nil {
+		return nil, err
+	}
+	return export.NewReplicator(registry.AdminStorage, registry.LogStorage, bucket, export.ReplicatorOptions{
+		OriginPrefix:  *replicateOriginPrefix,
+		Signer:        signer,
+		RetryInterval: *replicateRetryInterval,
+		Workers:       *replicateWorkers,
+		MetricFactory: registry.MetricFactory,
+	})
+}
+
 func mustCreate(fileName string) *os.File {
 	f, err := os.Create(fileName)
 	if err != nil {
@@ -0,0 +1,143 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The trillian_map_export binary exports a revision of a Trillian map, i.e.
+// its signed root, tiles and leaves, from storage into a snapshot file, or
+// imports such a file into a map in another storage, such as when migrating
+// maps from MySQL to Cloud Spanner. It reads and writes storage directly,
+// rather than through a map server.
+//
+// The map imported into must have the ID and keys of the exported map, i.e.
+// it is created with createtree --tree_id and the same private key, and must
+// not have any roots other than that of revision 0. It must not be written by
+// a map server while it is imported; an import which fails can simply be run
+// again.
+//
+// Example usage:
+// $ ./trillian_map_export --map_id=mapid --file=map.snapshot --storage_system=mysql
+// $ ./trillian_map_export --map_id=mapid --file=map.snapshot --import --storage_system=cloud_spanner
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/maps/snapshot"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+
+	// Register supported storage providers.
+	_ "github.com/google/trillian/storage/cloudspanner"
+	_ "github.com/google/trillian/storage/mysql"
+
+	// Load hashers
+	_ "github.com/google/trillian/merkle/coniks"
+	_ "github.com/google/trillian/merkle/maphasher"
+)
+
+var (
+	mapID         = flag.Int64("map_id", 0, "ID of the map to export or import")
+	file          = flag.String("file", "", "Snapshot file to write, or to read with --import, or - for stdout or stdin")
+	importFile    = flag.Bool("import", false, "If true, the snapshot in --file is imported into the map, rather than the map exported into it")
+	revision      = flag.Int64("revision", -1, "Revision of the map to export, or -1 for the latest one")
+	leafBatchSize = flag.Int("leaf_batch_size", 1000, "Number of leaves written to storage at a time when importing")
+	tileBatchSize = flag.Int("tile_batch_size", 100, "Number of tiles written to storage at a time when importing")
+
+	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	configFile    = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
+)
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	if *configFile != "" {
+		if err := cmd.ParseFlagFile(*configFile); err != nil {
+			glog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
+		}
+	}
+	if *mapID == 0 || *file == "" {
+		glog.Exit("--map_id and --file are required")
+	}
+
+	ctx := context.Background()
+	sp, err := storage.NewProvider(*storageSystem, monitoring.InertMetricFactory{})
+	if err != nil {
+		glog.Exitf("Failed to get storage provider: %v", err)
+	}
+	defer sp.Close()
+
+	if *importFile {
+		err = importMap(ctx, sp)
+	} else {
+		err = exportMap(ctx, sp)
+	}
+	if err != nil {
+		glog.Exit(err)
+	}
+}
+
+// exportMap writes the requested revision of the map to the snapshot file.
+func exportMap(ctx context.Context, sp storage.Provider) error {
+	tree, err := trees.GetTree(ctx, sp.AdminStorage(), *mapID, trees.NewGetOpts(trees.Query, trillian.TreeType_MAP))
+	if err != nil {
+		return fmt.Errorf("failed to read map %d: %v", *mapID, err)
+	}
+	w := os.Stdout
+	if *file != "-" {
+		if w, err = os.Create(*file); err != nil {
+			return fmt.Errorf("failed to create snapshot: %v", err)
+		}
+		defer w.Close()
+	}
+	footer, err := snapshot.Export(ctx, sp.MapStorage(), tree, *revision, w)
+	if err != nil {
+		return fmt.Errorf("failed to export map %d: %v", *mapID, err)
+	}
+	if err := w.Sync(); err != nil && *file != "-" {
+		return fmt.Errorf("failed to write snapshot: %v", err)
+	}
+	glog.Infof("Exported %d tiles and %d leaves of map %d", footer.TileCount, footer.LeafCount, *mapID)
+	return nil
+}
+
+// importMap writes the revision in the snapshot file to the map.
+func importMap(ctx context.Context, sp storage.Provider) error {
+	tree, err := trees.GetTree(ctx, sp.AdminStorage(), *mapID, trees.NewGetOpts(trees.UpdateMap, trillian.TreeType_MAP))
+	if err != nil {
+		return fmt.Errorf("failed to read map %d: %v", *mapID, err)
+	}
+	r := os.Stdin
+	if *file != "-" {
+		if r, err = os.Open(*file); err != nil {
+			return fmt.Errorf("failed to open snapshot: %v", err)
+		}
+		defer r.Close()
+	}
+	root, err := snapshot.Import(ctx, sp.MapStorage(), tree, r, snapshot.Options{
+		LeafBatchSize: *leafBatchSize,
+		TileBatchSize: *tileBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import map %d: %v", *mapID, err)
+	}
+	glog.Infof("Imported revision %d of map %d", root.Revision, *mapID)
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The trillian_map_loader binary populates a fresh Trillian map, i.e. one
+// which has only been initialized, with leaves read from a file, as revision 1
+// of the map. It builds the sparse Merkle tree of the map bottom-up, and
+// writes its leaves and tiles directly to storage, which is far faster than
+// SetLeaves for the initial population of a large map. The map must not be
+// written by a map server while it is loaded.
+//
+// The file holds a MapLeaf per line, in the JSON encoding of protos, e.g.
+// {"index": "<base64>", "leafValue": "<base64>"}. Leaves are held in memory
+// and sorted by index, unless --sorted is set, in which case they must be
+// sorted by index already, and are streamed.
+//
+// Example usage:
+// $ ./trillian_map_loader --map_id=mapid --leaves=leaves.jsonl --sorted
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/maps/loader"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	// Register key ProtoHandlers
+	_ "github.com/google/trillian/crypto/keys/der/proto"
+	_ "github.com/google/trillian/crypto/keys/pem/proto"
+	_ "github.com/google/trillian/crypto/keys/pkcs11/proto"
+
+	// Register password sources for encrypted PEM keys, in addition to
+	// environment variables and files.
+	_ "github.com/google/trillian/crypto/keys/pem/awssecrets"
+	_ "github.com/google/trillian/crypto/keys/pem/gcpsecrets"
+
+	// Register supported storage providers.
+	_ "github.com/google/trillian/storage/mysql"
+
+	// Load hashers
+	_ "github.com/google/trillian/merkle/coniks"
+	_ "github.com/google/trillian/merkle/maphasher"
+)
+
+var (
+	mapID         = flag.Int64("map_id", 0, "ID of the map to load, which must be fresh")
+	leavesPath    = flag.String("leaves", "", "File with the leaves to load, a MapLeaf in JSON per line, or - for stdin")
+	sorted        = flag.Bool("sorted", false, "If true, the leaves in the file are sorted by index, and are streamed rather than held in memory")
+	leafBatchSize = flag.Int("leaf_batch_size", 1000, "Number of leaves written to storage at a time")
+	tileBatchSize = flag.Int("tile_batch_size", 100, "Number of tiles written to storage at a time")
+	metadata      = flag.String("metadata", "", "Metadata stored in the root of the loaded revision")
+	hashWorkers   = flag.Int("hash_workers", runtime.NumCPU(), "Number of goroutines hashing each shard of the map tree")
+
+	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	configFile    = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
+)
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	if *configFile != "" {
+		if err := cmd.ParseFlagFile(*configFile); err != nil {
+			glog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
+		}
+	}
+	if *mapID == 0 || *leavesPath == "" {
+		glog.Exit("--map_id and --leaves are required")
+	}
+
+	ctx := context.Background()
+	sp, err := storage.NewProvider(*storageSystem, monitoring.InertMetricFactory{})
+	if err != nil {
+		glog.Exitf("Failed to get storage provider: %v", err)
+	}
+	defer sp.Close()
+
+	tree, err := trees.GetTree(ctx, sp.AdminStorage(), *mapID, trees.NewGetOpts(trees.UpdateMap, trillian.TreeType_MAP))
+	if err != nil {
+		glog.Exitf("Failed to read map %d: %v", *mapID, err)
+	}
+	signer, err := trees.Signer(ctx, tree)
+	if err != nil {
+		glog.Exitf("Failed to load the signer of map %d: %v", *mapID, err)
+	}
+
+	r := os.Stdin
+	if *leavesPath != "-" {
+		if r, err = os.Open(*leavesPath); err != nil {
+			glog.Exitf("Failed to open leaves: %v", err)
+		}
+		defer r.Close()
+	}
+	next := newLeafReader(r)
+	if !*sorted {
+		if next, err = sortLeaves(next); err != nil {
+			glog.Exitf("Failed to read leaves: %v", err)
+		}
+	}
+
+	l, err := loader.New(ctx, sp.MapStorage(), tree, signer, loader.Options{
+		LeafBatchSize: *leafBatchSize,
+		TileBatchSize: *tileBatchSize,
+		Metadata:      []byte(*metadata),
+		HashWorkers:   *hashWorkers,
+	})
+	if err != nil {
+		glog.Exitf("Failed to start loading map %d: %v", *mapID, err)
+	}
+	for read := 1; ; read++ {
+		leaf, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			glog.Exitf("Failed to read leaves: %v", err)
+		}
+		if err := l.Add(ctx, leaf); err != nil {
+			glog.Exitf("Failed to load leaf: %v", err)
+		}
+		if read%1000000 == 0 {
+			glog.Infof("Read %d leaves", read)
+		}
+	}
+	if _, err := l.Finish(ctx); err != nil {
+		glog.Exitf("Failed to finish loading map %d: %v", *mapID, err)
+	}
+	glog.Infof("Loaded %d leaves into map %d", l.Count(), *mapID)
+}
+
+// leafReader returns the next leaf read, or io.EOF after the last one.
+type leafReader func() (*trillian.MapLeaf, error)
+
+// newLeafReader returns a leafReader of the leaves in r, a MapLeaf in JSON per
+// line. Empty lines are skipped.
+func newLeafReader(r io.Reader) leafReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 64<<20) // Leaf values can be large.
+	line := 0
+	return func() (*trillian.MapLeaf, error) {
+		for s.Scan() {
+			line++
+			if len(bytes.TrimSpace(s.Bytes())) == 0 {
+				continue
+			}
+			var leaf trillian.MapLeaf
+			if err := protojson.Unmarshal(s.Bytes(), proto.MessageV2(&leaf)); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			return &leaf, nil
+		}
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
+
+// sortLeaves reads all the leaves of next, and returns a leafReader of them
+// sorted by index.
+func sortLeaves(next leafReader) (leafReader, error) {
+	var leaves []*trillian.MapLeaf
+	for {
+		leaf, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leaf)
+	}
+	sort.Slice(leaves, func(i, j int) bool { return bytes.Compare(leaves[i].Index, leaves[j].Index) < 0 })
+	return func() (*trillian.MapLeaf, error) {
+		if len(leaves) == 0 {
+			return nil, io.EOF
+		}
+		leaf := leaves[0]
+		leaves = leaves[1:]
+		return leaf, nil
+	}, nil
+}
@@ -21,6 +21,7 @@ import (
 	_ "net/http/pprof" // Register pprof HTTP handlers.
 	"os"
 	"runtime/pprof"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/features"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/opencensus"
 	"github.com/google/trillian/monitoring/prometheus"
@@ -40,51 +42,122 @@ import (
 	"github.com/google/trillian/quota/etcd/quotaapi"
 	"github.com/google/trillian/quota/etcd/quotapb"
 	"github.com/google/trillian/server"
+	"github.com/google/trillian/server/interceptor"
+	"github.com/google/trillian/server/maintenance"
+	"github.com/google/trillian/server/validators"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/compression"
 	"go.etcd.io/etcd/clientv3"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
 
 	// Register key ProtoHandlers
 	_ "github.com/google/trillian/crypto/keys/der/proto"
 	_ "github.com/google/trillian/crypto/keys/pem/proto"
 	_ "github.com/google/trillian/crypto/keys/pkcs11/proto"
 
+	// Register password sources for encrypted PEM keys, in addition to
+	// environment variables and files.
+	_ "github.com/google/trillian/crypto/keys/pem/awssecrets"
+	_ "github.com/google/trillian/crypto/keys/pem/gcpsecrets"
+
+	// Register metrics exporters other than prometheus.
+	_ "github.com/google/trillian/monitoring/otlp"
+	_ "github.com/google/trillian/monitoring/statsd"
+
 	// Register supported storage providers.
 	_ "github.com/google/trillian/storage/cloudspanner"
 	_ "github.com/google/trillian/storage/mysql"
+	_ "github.com/google/trillian/storage/postgres"
+	_ "github.com/google/trillian/storage/routing"
+	_ "github.com/google/trillian/storage/sqlite"
 
 	// Load hashers
 	_ "github.com/google/trillian/merkle/coniks"
 	_ "github.com/google/trillian/merkle/maphasher"
 
-	// Load MySQL quota provider
+	// Load quota providers
 	_ "github.com/google/trillian/quota/mysqlqm"
+	_ "github.com/google/trillian/quota/redis/redisqm"
 )
 
 var (
 	rpcEndpoint    = flag.String("rpc_endpoint", "localhost:8090", "Endpoint for RPC requests (host:port)")
 	httpEndpoint   = flag.String("http_endpoint", "localhost:8091", "Endpoint for HTTP metrics (host:port, empty means disabled)")
+	fakeClock      = flag.Bool("fake_clock", false, "Test only: run on a fake clock which only moves when advanced through /debug/clock on --http_endpoint")
 	healthzTimeout = flag.Duration("healthz_timeout", time.Second*5, "Timeout used during healthz checks")
 	tlsCertFile    = flag.String("tls_cert_file", "", "Path to the TLS server certificate. If unset, the server will use unsecured connections.")
 	tlsKeyFile     = flag.String("tls_key_file", "", "Path to the TLS server key. If unset, the server will use unsecured connections.")
 
-	quotaSystem = flag.String("quota_system", "mysql", fmt.Sprintf("Quota system to use. One of: %v", quota.Providers()))
-	quotaDryRun = flag.Bool("quota_dry_run", false, "If true no requests are blocked due to lack of tokens")
+	maintenanceReason = flag.String("maintenance_reason", "", "If set, the server starts in read-only maintenance for this reason: write RPCs fail with FAILED_PRECONDITION while reads continue, until the SetMaintenanceMode admin RPC takes it out of maintenance")
+
+	quotaSystem        = flag.String("quota_system", "mysql", fmt.Sprintf("Quota system to use. One of: %v", quota.Providers()))
+	quotaDryRun        = flag.Bool("quota_dry_run", false, "If true no requests are blocked due to lack of tokens")
+	quotaStatsInterval = flag.Duration("quota_stats_interval", time.Minute, "How often the available tokens of global and per-tree quotas are exported as metrics (0 means never)")
+
+	treeHealthInterval = flag.Duration("tree_health_interval", 30*time.Second, "How often the health of each tree, reported by the GRPC health service as trillian.map.<tree ID>, is checked (0 means never)")
+
+	rpcDefaultTimeout = flag.Duration("rpc_default_timeout", 0, "If non-zero, the deadline set on unary RPCs which arrive without one, unless --rpc_method_timeouts sets another for their method")
+	rpcMethodTimeouts = flag.String("rpc_method_timeouts", "", "Comma-separated list of method=duration pairs setting the deadlines of RPCs of the given methods, unary or streaming, which arrive without one, e.g. GetLeaves=30s,WriteLeaves=5s. Methods are named by their full name or name alone")
+
+	loadSheddingLatency    = flag.Duration("load_shedding_latency", 0, "If non-zero, reads are shed while the --load_shedding_percentile latency of RPCs is over this, bulk reads of leaves first, so that writes keep being served when storage is saturated")
+	loadSheddingPercentile = flag.Float64("load_shedding_percentile", interceptor.DefaultLoadSheddingPercentile, "Percentile of the latency of RPCs compared to --load_shedding_latency, from 0 to 1")
+	loadSheddingInterval   = flag.Duration("load_shedding_interval", interceptor.DefaultLoadSheddingInterval, "How often the latency of RPCs is compared to --load_shedding_latency, and how long clients of shed RPCs are told to wait before retrying")
+
+	maxConcurrentRPCs       = flag.Int("max_concurrent_rpcs", 0, "If non-zero, the number of RPCs which may be in flight at once, beyond which they're queued")
+	maxConcurrentMethodRPCs = flag.String("max_concurrent_method_rpcs", "", "Comma-separated list of method=limit pairs setting the numbers of RPCs of the given methods which may be in flight at once, beyond which they're queued, e.g. GetLeaves=10. Methods are named by their full name or name alone")
+	concurrencyQueueTimeout = flag.Duration("concurrency_queue_timeout", time.Second, "How long RPCs queued by --max_concurrent_rpcs or --max_concurrent_method_rpcs wait for others to complete before failing (0 means they fail at once)")
+
+	rpcGzipLevel = flag.Int("rpc_gzip_level", -1, "Level of the gzip compression of responses to clients which ask for it, from 1 (fastest) to 9 (smallest), or -1 for the default level")
+
+	maxResponseBytes = flag.Int("max_response_bytes", server.DefaultMaxResponseBytes, "Size in bytes which read RPCs returning many leaves keep their responses under, by returning a page token to continue from instead of the rest of the leaves. Should be at most the maximum size of the messages clients receive. 0 means no limit")
+
+	leafValidators = flag.String("leaf_validators", "", fmt.Sprintf("Comma-separated list of key=validator pairs configuring the checks of the values of leaves written to trees, keyed by tree ID or tree type (MAP), e.g. MAP=json,1234=json-schema:file:///etc/schema.json. A validator for a tree ID takes precedence over one for its type. Validators are any of: %v, some taking an argument after a colon, e.g. json-schema:<URL>", validators.Names()))
 
 	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
 
+	metricsExporters = flag.String("metrics_exporters", prometheus.ExporterName, fmt.Sprintf("Comma-separated list of systems to export metrics to. Any of: %v", monitoring.Exporters()))
+
 	treeGCEnabled            = flag.Bool("tree_gc", true, "If true, tree garbage collection (hard-deletion) is periodically performed")
 	treeDeleteThreshold      = flag.Duration("tree_delete_threshold", serverutil.DefaultTreeDeleteThreshold, "Minimum period a tree has to remain deleted before being hard-deleted")
 	treeDeleteMinRunInterval = flag.Duration("tree_delete_min_run_interval", serverutil.DefaultTreeDeleteMinInterval, "Minimum interval between tree garbage collection sweeps. Actual runs happen randomly between [minInterval,2*minInterval).")
 
-	tracing          = flag.Bool("tracing", false, "If true opencensus Stackdriver tracing will be enabled. See https://opencensus.io/.")
+	tracing          = flag.Bool("tracing", false, "If true opencensus tracing will be enabled. See https://opencensus.io/.")
+	tracingExporter  = flag.String("tracing_exporter", opencensus.StackdriverExporterName, fmt.Sprintf("System to send traces to, if tracing is enabled. One of: %v", opencensus.TraceExporters()))
 	tracingProjectID = flag.String("tracing_project_id", "", "project ID to pass to Stackdriver client. Can be empty for GCP, consult docs for other platforms.")
 	tracingPercent   = flag.Int("tracing_percent", 0, "Percent of requests to be traced. Zero is a special case to use the DefaultSampler")
 
+	featureFlagsFile           = flag.String("feature_flags_file", "", "If set, path of a file of feature flag overrides, which turn features on and off for all trees or single trees without restarts, of the form name=bool or name@treeID=bool separated by commas or newlines, e.g. shared_subtree_cache=false. The SetFeatureFlags admin RPC overrides them until the file changes")
+	featureFlagsReloadInterval = flag.Duration("feature_flags_reload_interval", time.Minute, "How often --feature_flags_file is reloaded if it has changed (0 means never)")
+
 	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
 
 	useSingleTransaction = flag.Bool("single_transaction", false, "Experimental: use a single transaction when updating the map")
 	largePreload         = flag.Bool("large_preload_fix", true, "Experimental: work-around locking performance issues when using useSingleTransaction mode")
+	maxLeavesPerTX       = flag.Int("max_leaves_per_transaction", 0, "If non-zero, the leaves of larger SetLeaves and WriteLeaves batches are written in chunks of at most this many, each in its own storage transaction, still producing a single map root. Has no effect with --single_transaction")
+
+	skipUnchangedLeaves = flag.Bool("skip_unchanged_leaves", false, "If true, written map leaves whose hash and extra data are unchanged from the previous revision aren't stored again, at the cost of reading every written leaf")
+
+	hashWorkers = flag.Int("map_hash_workers", 1, "Number of goroutines hashing each shard of a map tree when leaves are written, or a map is audited or sequenced. Shards are hashed in parallel regardless, so this mostly speeds up writes whose leaves fall into few shards")
+
+	pinReadRevisions = flag.Bool("pin_read_revisions", false, "If true, reads of a specific map revision use storage transactions pinned to that revision up front")
+
+	verifyRootSignatures = flag.Bool("verify_root_signatures", false, "If true, read RPCs check the signatures of the map roots they read from storage, and fail rather than serve unverifiable roots")
+
+	auditMapIDs      = flag.String("audit_map_ids", "", "Comma-separated list of IDs of maps whose root hash is periodically recomputed from all of their leaves, and checked against the stored root and tiles")
+	mapAuditInterval = flag.Duration("map_audit_interval", time.Hour, "How often the maps in --audit_map_ids are audited")
+
+	gcMapIDs        = flag.String("gc_map_ids", "", "Comma-separated list of IDs of maps whose old revisions are periodically deleted from storage. Reads at deleted revisions fail. Only supported by MySQL storage")
+	mapGCInterval   = flag.Duration("map_gc_interval", time.Hour, "How often old revisions of the maps in --gc_map_ids are deleted")
+	mapGCKeepRevs   = flag.Int64("map_gc_keep_revisions", 1000, "Number of revisions before the latest one of each map in --gc_map_ids which are kept")
+	mapGCMaxAge     = flag.Duration("map_gc_max_age", 0, "If non-zero, revisions of the maps in --gc_map_ids written less than this long ago are also kept, along with the one latest at the time")
+	mapGCBatchSize  = flag.Int("map_gc_batch_size", 1000, "Maximum number of rows of old map revisions deleted per transaction")
+	mapGCMaxBatches = flag.Int("map_gc_max_batches", 100, "Maximum number of batches of rows deleted per map in each run of --map_gc_interval (0 means no limit)")
+
+	sequenceMapIDs        = flag.String("sequence_map_ids", "", "Comma-separated list of IDs of maps whose mutations queued by QueueMapMutations are periodically applied in new revisions. Only supported by MySQL storage. Each map should be sequenced by one server")
+	mapSequencerInterval  = flag.Duration("map_sequencer_interval", time.Second, "How often the queued mutations of the maps in --sequence_map_ids are applied")
+	mapSequencerBatchSize = flag.Int("map_sequencer_batch_size", 1000, "Maximum number of queued mutations applied in each map revision")
+	mapSequencerMaxBatch  = flag.Int("map_sequencer_max_batches", 10, "Maximum number of revisions written per map in each run of --map_sequencer_interval (0 means no limit)")
 
 	// Profiling related flags.
 	cpuProfile = flag.String("cpuprofile", "", "If set, write CPU profile to this file")
@@ -100,17 +173,30 @@ func main() {
 		}
 	}
 
-	var options []grpc.ServerOption
-	mf := prometheus.MetricFactory{}
+	var statsHandler stats.Handler
+	mf, err := monitoring.NewExporterMetricFactory(strings.Split(*metricsExporters, ",")...)
+	if err != nil {
+		glog.Exitf("Failed to initialize metrics exporters: %v", err)
+	}
 	monitoring.SetStartSpan(opencensus.StartSpan)
+	monitoring.SetTraceID(opencensus.TraceID)
 
 	if *tracing {
-		opts, err := opencensus.EnableRPCServerTracing(*tracingProjectID, *tracingPercent)
+		// Enable the server request counter tracing etc.
+		statsHandler, err = opencensus.NewRPCServerStatsHandler(*tracingExporter, *tracingProjectID, *tracingPercent)
 		if err != nil {
-			glog.Exitf("Failed to initialize stackdriver / opencensus tracing: %v", err)
+			glog.Exitf("Failed to initialize %v / opencensus tracing: %v", *tracingExporter, err)
+		}
+	}
+
+	if err := compression.SetGzipLevel(*rpcGzipLevel); err != nil {
+		glog.Exitf("Invalid --rpc_gzip_level: %v", err)
+	}
+
+	if *featureFlagsFile != "" {
+		if err := features.Default.Watch(context.Background(), *featureFlagsFile, *featureFlagsReloadInterval); err != nil {
+			glog.Exitf("Failed to load --feature_flags_file: %v", err)
 		}
-		// Enable the server request counter tracing etc.
-		options = append(options, opts...)
 	}
 
 	sp, err := storage.NewProvider(*storageSystem, mf)
@@ -135,6 +221,7 @@ func main() {
 		glog.Exitf("Error creating quota manager: %v", err)
 	}
 
+	timeSource := serverutil.TimeSource(*fakeClock)
 	registry := extension.Registry{
 		AdminStorage:  sp.AdminStorage(),
 		MapStorage:    sp.MapStorage(),
@@ -143,6 +230,10 @@ func main() {
 		NewKeyProto: func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
 			return der.NewProtoFromSpec(spec)
 		},
+		Maintenance: &maintenance.Mode{},
+	}
+	if *maintenanceReason != "" {
+		registry.Maintenance.Set(*maintenanceReason)
 	}
 
 	// Enable CPU profile if requested.
@@ -154,21 +245,55 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
+	methodTimeouts, err := interceptor.ParseMethodDeadlines(*rpcMethodTimeouts)
+	if err != nil {
+		glog.Exitf("Invalid --rpc_method_timeouts: %v", err)
+	}
+
+	methodLimits, err := interceptor.ParseMethodLimits(*maxConcurrentMethodRPCs)
+	if err != nil {
+		glog.Exitf("Invalid --max_concurrent_method_rpcs: %v", err)
+	}
+
+	leafValidatorConfig, err := validators.ParseConfig(*leafValidators)
+	if err != nil {
+		glog.Exitf("Invalid --leaf_validators: %v", err)
+	}
+
 	m := serverutil.Main{
 		RPCEndpoint:  *rpcEndpoint,
 		HTTPEndpoint: *httpEndpoint,
 		TLSCertFile:  *tlsCertFile,
 		TLSKeyFile:   *tlsKeyFile,
 		StatsPrefix:  "map",
-		ExtraOptions: options,
+		StatsHandler: statsHandler,
 		QuotaDryRun:  *quotaDryRun,
 		DBClose:      sp.Close,
 		Registry:     registry,
+		Deadlines:    interceptor.Deadlines{Default: *rpcDefaultTimeout, PerMethod: methodTimeouts},
+		LoadShedding: interceptor.LoadSheddingOptions{
+			Latency:    *loadSheddingLatency,
+			Percentile: *loadSheddingPercentile,
+			Interval:   *loadSheddingInterval,
+		},
+		ConcurrencyLimits: interceptor.ConcurrencyLimits{
+			Global:       *maxConcurrentRPCs,
+			PerMethod:    methodLimits,
+			QueueTimeout: *concurrencyQueueTimeout,
+		},
 		RegisterServerFn: func(s *grpc.Server, registry extension.Registry) error {
 			mapServer := server.NewTrillianMapServer(registry,
 				server.TrillianMapServerOptions{
-					UseSingleTransaction: *useSingleTransaction,
-					UseLargePreload:      *largePreload,
+					UseSingleTransaction:    *useSingleTransaction,
+					UseLargePreload:         *largePreload,
+					MaxLeavesPerTransaction: *maxLeavesPerTX,
+					PinReadRevisions:        *pinReadRevisions,
+					VerifyRootSignatures:    *verifyRootSignatures,
+					MaxResponseBytes:        *maxResponseBytes,
+					LeafValidators:          leafValidatorConfig,
+					HashWorkers:             *hashWorkers,
+					SkipUnchangedLeaves:     *skipUnchangedLeaves,
+					TimeSource:              timeSource,
 				})
 			if err := mapServer.IsHealthy(); err != nil {
 				return err
@@ -198,9 +323,64 @@ func main() {
 		TreeGCEnabled:         *treeGCEnabled,
 		TreeDeleteThreshold:   *treeDeleteThreshold,
 		TreeDeleteMinInterval: *treeDeleteMinRunInterval,
+		QuotaStatsInterval:    *quotaStatsInterval,
+		TreeHealth: server.TreeHealthOptions{
+			Interval: *treeHealthInterval,
+		},
 	}
 
 	ctx := context.Background()
+	if *auditMapIDs != "" {
+		mapIDs, err := parseMapIDs(*auditMapIDs)
+		if err != nil {
+			glog.Exitf("Invalid --audit_map_ids: %v", err)
+		}
+		if *mapAuditInterval <= 0 {
+			glog.Exitf("--map_audit_interval must be positive, got %v", *mapAuditInterval)
+		}
+		auditor := server.NewMapAuditor(registry, server.MapAuditorOptions{MapIDs: mapIDs, Interval: *mapAuditInterval, HashWorkers: *hashWorkers})
+		go auditor.Run(ctx)
+	}
+	if *gcMapIDs != "" {
+		mapIDs, err := parseMapIDs(*gcMapIDs)
+		if err != nil {
+			glog.Exitf("Invalid --gc_map_ids: %v", err)
+		}
+		if *mapGCInterval <= 0 {
+			glog.Exitf("--map_gc_interval must be positive, got %v", *mapGCInterval)
+		}
+		if *mapGCKeepRevs < 0 || *mapGCMaxAge < 0 {
+			glog.Exit("--map_gc_keep_revisions and --map_gc_max_age must not be negative")
+		}
+		gc := server.NewMapRevisionGC(registry, server.MapRevisionGCOptions{
+			MapIDs:        mapIDs,
+			Interval:      *mapGCInterval,
+			KeepRevisions: *mapGCKeepRevs,
+			MaxAge:        *mapGCMaxAge,
+			BatchSize:     *mapGCBatchSize,
+			MaxBatches:    *mapGCMaxBatches,
+		})
+		go gc.Run(ctx)
+	}
+	if *sequenceMapIDs != "" {
+		mapIDs, err := parseMapIDs(*sequenceMapIDs)
+		if err != nil {
+			glog.Exitf("Invalid --sequence_map_ids: %v", err)
+		}
+		if *mapSequencerInterval <= 0 || *mapSequencerBatchSize <= 0 {
+			glog.Exit("--map_sequencer_interval and --map_sequencer_batch_size must be positive")
+		}
+		seq := server.NewMapSequencer(registry, server.MapSequencerOptions{
+			MapIDs:              mapIDs,
+			Interval:            *mapSequencerInterval,
+			BatchSize:           *mapSequencerBatchSize,
+			MaxBatches:          *mapSequencerMaxBatch,
+			UseLargePreload:     *largePreload,
+			HashWorkers:         *hashWorkers,
+			SkipUnchangedLeaves: *skipUnchangedLeaves,
+		})
+		go seq.Run(ctx)
+	}
 	if err := m.Run(ctx); err != nil {
 		glog.Exitf("Server exited with error: %v", err)
 	}
@@ -211,6 +391,19 @@ func main() {
 	}
 }
 
+// parseMapIDs parses a comma-separated list of map IDs.
+func parseMapIDs(s string) ([]int64, error) {
+	var ids []int64
+	for _, field := range strings.Split(s, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad map ID %q: %v", field, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 func mustCreate(fileName string) *os.File {
 	f, err := os.Create(fileName)
 	if err != nil {
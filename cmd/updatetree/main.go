@@ -36,6 +36,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 )
@@ -46,6 +47,8 @@ var (
 	treeID          = flag.Int64("tree_id", 0, "The ID of the tree to be set updated")
 	treeState       = flag.String("tree_state", "", "If set the tree state will be updated")
 	treeType        = flag.String("tree_type", "", "If set the tree type will be updated")
+	rateLimits      = flag.String("rate_limits", "", `If set the rate limits of the tree will be replaced by these, as a TreeRateLimits proto in text format, e.g. "queries_per_second: 10 leaves_per_second: 1000", or removed if "none"`)
+	maxStorageBytes = flag.Int64("max_storage_bytes", -1, "If non-negative the maximum bytes of storage written for the tree will be updated, with zero meaning no limit")
 	printTree       = flag.Bool("print", false, "Print the resulting tree")
 )
 
@@ -84,6 +87,21 @@ func updateTree(ctx context.Context) (*trillian.Tree, error) {
 		paths = append(paths, "tree_type")
 	}
 
+	if len(*rateLimits) > 0 {
+		if *rateLimits != "none" {
+			tree.RateLimits = &trillian.TreeRateLimits{}
+			if err := prototext.Unmarshal([]byte(*rateLimits), tree.RateLimits); err != nil {
+				return nil, fmt.Errorf("invalid rate limits: %v", err)
+			}
+		}
+		paths = append(paths, "rate_limits")
+	}
+
+	if *maxStorageBytes >= 0 {
+		tree.MaxStorageBytes = *maxStorageBytes
+		paths = append(paths, "max_storage_bytes")
+	}
+
 	if len(paths) == 0 {
 		return nil, errors.New("nothing to change")
 	}
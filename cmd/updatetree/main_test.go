@@ -71,6 +71,28 @@ func TestFreezeTree(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			desc: "updateRateLimits",
+			setFlags: func() {
+				*treeID = 12345
+				*rateLimits = "leaves_per_second: 100"
+			},
+			wantRPC: true,
+			updateTree: &trillian.Tree{
+				TreeId:     12345,
+				TreeState:  trillian.TreeState_ACTIVE,
+				RateLimits: &trillian.TreeRateLimits{LeavesPerSecond: 100},
+			},
+			wantState: trillian.TreeState_ACTIVE,
+		},
+		{
+			desc: "updateInvalidRateLimits",
+			setFlags: func() {
+				*treeID = 12345
+				*rateLimits = "leaves_per_minute: 100"
+			},
+			wantErr: true,
+		},
 		{
 			desc: "unknownTree",
 			setFlags: func() {
@@ -0,0 +1,266 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The verify binary verifies proofs issued by Trillian logs and maps entirely
+// offline, given the public key of the tree. Proofs are read from files which
+// contain protos in binary form, or in text form if the file name ends with
+// .textproto.
+//
+// Example usage:
+// $ ./verify --public_key=log.pem --bundle=leaf.bundle
+// $ ./verify --public_key=log.pem --mode=inclusion --log_root=root.pb --leaf=leaf.dat --proof=proof.pb
+// $ ./verify --public_key=log.pem --mode=consistency --old_log_root=old.pb --log_root=root.pb --proof=proof.pb
+// $ ./verify --public_key=map.pem --mode=map_inclusion --map_id=123 --map_root=root.pb --map_leaf=leaf.pb
+package main
+
+import (
+	"crypto"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/merkle/logverifier"
+	"github.com/google/trillian/merkle/mapverifier"
+	"google.golang.org/protobuf/encoding/prototext"
+
+	tcrypto "github.com/google/trillian/crypto"
+
+	// Load hashers
+	_ "github.com/google/trillian/merkle/coniks"
+	_ "github.com/google/trillian/merkle/maphasher"
+	_ "github.com/google/trillian/merkle/rfc6962"
+)
+
+var (
+	mode         = flag.String("mode", "bundle", "What to verify: bundle, inclusion, consistency or map_inclusion")
+	publicKey    = flag.String("public_key", "", "PEM file with the public key of the tree, which must be obtained from a trusted source")
+	hashStrategy = flag.String("hash_strategy", "", "Hash strategy of the tree, for all modes except bundle. Defaults to RFC6962_SHA256 for logs and CONIKS_SHA512_256 for maps")
+	bundlePath   = flag.String("bundle", "", "File with a ProofBundle, for mode bundle")
+	logRootPath  = flag.String("log_root", "", "File with the SignedLogRoot to verify against, for modes inclusion and consistency")
+	oldRootPath  = flag.String("old_log_root", "", "File with the SignedLogRoot of the smaller tree, for mode consistency")
+	proofPath    = flag.String("proof", "", "File with the Proof, for modes inclusion and consistency")
+	leafPath     = flag.String("leaf", "", "File with the raw leaf value, for mode inclusion")
+	leafIndex    = flag.Int64("leaf_index", -1, "Index of the leaf, for mode inclusion. Defaults to the leaf index of the proof")
+	mapRootPath  = flag.String("map_root", "", "File with the SignedMapRoot to verify against, for mode map_inclusion")
+	mapLeafPath  = flag.String("map_leaf", "", "File with the MapLeafInclusion, for mode map_inclusion")
+	mapID        = flag.Int64("map_id", 0, "ID of the map, which is part of its leaf hashes, for mode map_inclusion")
+)
+
+// readProto reads the file at path into msg.
+func readProto(path string, msg proto.Message) error {
+	if path == "" {
+		return errors.New("no file given")
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(path, ".textproto") {
+		err = prototext.Unmarshal(b, proto.MessageV2(msg))
+	} else {
+		err = proto.Unmarshal(b, msg)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return nil
+}
+
+// strategy returns the hash strategy given with --hash_strategy, or def.
+func strategy(def trillian.HashStrategy) (trillian.HashStrategy, error) {
+	if *hashStrategy == "" {
+		return def, nil
+	}
+	s, ok := trillian.HashStrategy_value[*hashStrategy]
+	if !ok {
+		return 0, fmt.Errorf("unknown hash strategy: %s", *hashStrategy)
+	}
+	return trillian.HashStrategy(s), nil
+}
+
+// logVerifier returns a LogVerifier for the log with public key pk.
+func logVerifier(pk crypto.PublicKey) (*client.LogVerifier, error) {
+	s, err := strategy(trillian.HashStrategy_RFC6962_SHA256)
+	if err != nil {
+		return nil, err
+	}
+	hasher, err := registry.NewLogHasher(s)
+	if err != nil {
+		return nil, err
+	}
+	return client.NewLogVerifier(hasher, pk, crypto.SHA256), nil
+}
+
+func verifyBundle(pk crypto.PublicKey) (string, error) {
+	var bundle trillian.ProofBundle
+	if err := readProto(*bundlePath, &bundle); err != nil {
+		return "", fmt.Errorf("--bundle: %v", err)
+	}
+	v, err := client.NewLogVerifierFromBundle(&bundle, pk)
+	if err != nil {
+		return "", err
+	}
+	root, err := v.VerifyProofBundle(&bundle)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Verified inclusion of leaf %d in log %d at tree size %d, root hash %x", bundle.Leaf.LeafIndex, bundle.LogId, root.TreeSize, root.RootHash), nil
+}
+
+func verifyInclusion(pk crypto.PublicKey) (string, error) {
+	v, err := logVerifier(pk)
+	if err != nil {
+		return "", err
+	}
+	var slr trillian.SignedLogRoot
+	if err := readProto(*logRootPath, &slr); err != nil {
+		return "", fmt.Errorf("--log_root: %v", err)
+	}
+	var proof trillian.Proof
+	if err := readProto(*proofPath, &proof); err != nil {
+		return "", fmt.Errorf("--proof: %v", err)
+	}
+	if *leafPath == "" {
+		return "", errors.New("--leaf is required")
+	}
+	data, err := ioutil.ReadFile(*leafPath)
+	if err != nil {
+		return "", err
+	}
+	index := proof.LeafIndex
+	if *leafIndex >= 0 {
+		index = *leafIndex
+	}
+
+	root, err := tcrypto.VerifySignedLogRoot(v.PubKey, v.SigHash, &slr)
+	if err != nil {
+		return "", err
+	}
+	if err := v.VerifyInclusionAtIndex(root, data, index, proof.Hashes); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Verified inclusion of leaf %d at tree size %d, root hash %x", index, root.TreeSize, root.RootHash), nil
+}
+
+func verifyConsistency(pk crypto.PublicKey) (string, error) {
+	v, err := logVerifier(pk)
+	if err != nil {
+		return "", err
+	}
+	var oldSLR, newSLR trillian.SignedLogRoot
+	if err := readProto(*oldRootPath, &oldSLR); err != nil {
+		return "", fmt.Errorf("--old_log_root: %v", err)
+	}
+	if err := readProto(*logRootPath, &newSLR); err != nil {
+		return "", fmt.Errorf("--log_root: %v", err)
+	}
+	var proof trillian.Proof
+	if err := readProto(*proofPath, &proof); err != nil {
+		return "", fmt.Errorf("--proof: %v", err)
+	}
+
+	oldRoot, err := tcrypto.VerifySignedLogRoot(v.PubKey, v.SigHash, &oldSLR)
+	if err != nil {
+		return "", fmt.Errorf("--old_log_root: %v", err)
+	}
+	newRoot, err := tcrypto.VerifySignedLogRoot(v.PubKey, v.SigHash, &newSLR)
+	if err != nil {
+		return "", fmt.Errorf("--log_root: %v", err)
+	}
+	// Unlike LogVerifier.VerifyRoot, don't trust any root implicitly, even
+	// that of an empty tree.
+	if err := logverifier.New(v.Hasher).VerifyConsistencyProof(int64(oldRoot.TreeSize), int64(newRoot.TreeSize), oldRoot.RootHash, newRoot.RootHash, proof.Hashes); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Verified consistency of tree size %d, root hash %x with tree size %d, root hash %x", oldRoot.TreeSize, oldRoot.RootHash, newRoot.TreeSize, newRoot.RootHash), nil
+}
+
+func verifyMapInclusion(pk crypto.PublicKey) (string, error) {
+	s, err := strategy(trillian.HashStrategy_CONIKS_SHA512_256)
+	if err != nil {
+		return "", err
+	}
+	hasher, err := registry.NewMapHasher(s)
+	if err != nil {
+		return "", err
+	}
+	var smr trillian.SignedMapRoot
+	if err := readProto(*mapRootPath, &smr); err != nil {
+		return "", fmt.Errorf("--map_root: %v", err)
+	}
+	var leaf trillian.MapLeafInclusion
+	if err := readProto(*mapLeafPath, &leaf); err != nil {
+		return "", fmt.Errorf("--map_leaf: %v", err)
+	}
+
+	root, err := tcrypto.VerifySignedMapRoot(pk, crypto.SHA256, &smr)
+	if err != nil {
+		return "", err
+	}
+	proof := leaf.GetInclusion()
+	if bitmap := leaf.GetInclusionBitmap(); len(bitmap) > 0 {
+		if proof, err = merkle.ExpandInclusionProof(bitmap, proof, hasher.BitLen()); err != nil {
+			return "", fmt.Errorf("--map_leaf: %v", err)
+		}
+	}
+	if err := mapverifier.VerifyInclusionProof(*mapID, leaf.GetLeaf(), root.RootHash, proof, hasher); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Verified inclusion of leaf %x in map %d at revision %d, root hash %x", leaf.GetLeaf().GetIndex(), *mapID, root.Revision, root.RootHash), nil
+}
+
+// verify runs the verification selected with --mode, and returns a summary of
+// what was verified.
+func verify() (string, error) {
+	if *publicKey == "" {
+		return "", errors.New("--public_key is required")
+	}
+	pk, err := pem.ReadPublicKeyFile(*publicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read public key: %v", err)
+	}
+
+	switch *mode {
+	case "bundle":
+		return verifyBundle(pk)
+	case "inclusion":
+		return verifyInclusion(pk)
+	case "consistency":
+		return verifyConsistency(pk)
+	case "map_inclusion":
+		return verifyMapInclusion(pk)
+	default:
+		return "", fmt.Errorf("unknown mode: %s", *mode)
+	}
+}
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	msg, err := verify()
+	if err != nil {
+		glog.Exitf("Verification failed: %v", err)
+	}
+	fmt.Println(msg)
+}
@@ -0,0 +1,257 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/coniks"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/testonly/flagsaver"
+	"github.com/google/trillian/testonly/setup"
+	"github.com/google/trillian/types"
+	"google.golang.org/protobuf/encoding/prototext"
+
+	tcrypto "github.com/google/trillian/crypto"
+	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+// writeFiles writes the test proofs of a two-leaf log and an empty map into
+// dir.
+func writeFiles(t *testing.T, dir string) {
+	t.Helper()
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("Failed to open test key: %v", err)
+	}
+	signer := tcrypto.NewSigner(0, key, crypto.SHA256)
+	hint, err := client.PublicKeyHint(key.Public())
+	if err != nil {
+		t.Fatalf("PublicKeyHint(): %v", err)
+	}
+
+	hasher := rfc6962.DefaultHasher
+	leaf0, leaf1 := []byte("A"), []byte("B")
+	hash0, hash1 := hasher.HashLeaf(leaf0), hasher.HashLeaf(leaf1)
+	root1, err := signer.SignLogRoot(&types.LogRootV1{TreeSize: 1, RootHash: hash0})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	root2, err := signer.SignLogRoot(&types.LogRootV1{TreeSize: 2, RootHash: hasher.HashChildren(hash0, hash1)})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+
+	// The empty map proves that none of its leaves are set.
+	const mapID = 7
+	mapHasher := coniks.Default
+	index := make([]byte, mapHasher.Size())
+	mapRoot, err := signer.SignMapRoot(&types.MapRootV1{
+		Revision: 1,
+		RootHash: mapHasher.HashEmpty(mapID, index, mapHasher.BitLen()),
+	})
+	if err != nil {
+		t.Fatalf("SignMapRoot(): %v", err)
+	}
+	index[0] = 0x80
+	mapLeaf := &trillian.MapLeafInclusion{
+		Leaf:      &trillian.MapLeaf{Index: index},
+		Inclusion: make([][]byte, mapHasher.BitLen()),
+	}
+	compactLeaf := &trillian.MapLeafInclusion{Leaf: mapLeaf.Leaf}
+	compactLeaf.InclusionBitmap, compactLeaf.Inclusion = merkle.CompactInclusionProof(mapLeaf.Inclusion)
+	badCompactLeaf := &trillian.MapLeafInclusion{
+		Leaf:            mapLeaf.Leaf,
+		InclusionBitmap: []byte{1},
+	}
+	setLeaf := &trillian.MapLeafInclusion{
+		Leaf:      &trillian.MapLeaf{Index: index, LeafValue: []byte("A")},
+		Inclusion: make([][]byte, mapHasher.BitLen()),
+	}
+
+	files := map[string]proto.Message{
+		"bundle.pb": &trillian.ProofBundle{
+			LogId:         123,
+			Leaf:          &trillian.LogLeaf{LeafValue: leaf1, MerkleLeafHash: hash1, LeafIndex: 1},
+			Proof:         &trillian.Proof{LeafIndex: 1, Hashes: [][]byte{hash0}},
+			SignedLogRoot: root2,
+			HashStrategy:  trillian.HashStrategy_RFC6962_SHA256,
+			HashAlgorithm: sigpb.DigitallySigned_SHA256,
+			PublicKeyHint: hint,
+		},
+		"root1.pb":            root1,
+		"root2.pb":            root2,
+		"inclusion.pb":        &trillian.Proof{LeafIndex: 1, Hashes: [][]byte{hash0}},
+		"consistency.pb":      &trillian.Proof{Hashes: [][]byte{hash1}},
+		"map_root.pb":         mapRoot,
+		"map_leaf.pb":         mapLeaf,
+		"map_set_leaf.pb":     setLeaf,
+		"map_compact_leaf.pb": compactLeaf,
+		"map_bad_leaf.pb":     badCompactLeaf,
+		"inclusion.textproto": &trillian.Proof{LeafIndex: 1, Hashes: [][]byte{hash0}},
+	}
+	for name, msg := range files {
+		var b []byte
+		if strings.HasSuffix(name, ".textproto") {
+			b, err = prototext.Marshal(proto.MessageV2(msg))
+		} else {
+			b, err = proto.Marshal(msg)
+		}
+		if err != nil {
+			t.Fatalf("Marshal(%s): %v", name, err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), b, 0644); err != nil {
+			t.Fatalf("WriteFile(): %v", err)
+		}
+	}
+	raw := map[string]string{
+		"leaf.dat":  string(leaf1),
+		"key.pem":   testonly.DemoPublicKey,
+		"other.pem": stestonly.PublicKeyPEM,
+	}
+	for name, s := range raw {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(s), 0644); err != nil {
+			t.Fatalf("WriteFile(): %v", err)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir)
+
+	for _, tc := range []struct {
+		desc    string
+		flags   map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			desc:  "bundle",
+			flags: map[string]string{"bundle": "bundle.pb"},
+			want:  "Verified inclusion of leaf 1 in log 123 at tree size 2",
+		},
+		{
+			desc:    "bundle-wrong-key",
+			flags:   map[string]string{"bundle": "bundle.pb", "public_key": "other.pem"},
+			wantErr: true,
+		},
+		{
+			desc:    "bundle-missing",
+			flags:   map[string]string{},
+			wantErr: true,
+		},
+		{
+			desc:  "inclusion",
+			flags: map[string]string{"mode": "inclusion", "log_root": "root2.pb", "leaf": "leaf.dat", "proof": "inclusion.pb"},
+			want:  "Verified inclusion of leaf 1 at tree size 2",
+		},
+		{
+			desc:  "inclusion-textproto",
+			flags: map[string]string{"mode": "inclusion", "log_root": "root2.pb", "leaf": "leaf.dat", "proof": "inclusion.textproto"},
+			want:  "Verified inclusion of leaf 1 at tree size 2",
+		},
+		{
+			desc:    "inclusion-wrong-index",
+			flags:   map[string]string{"mode": "inclusion", "log_root": "root2.pb", "leaf": "leaf.dat", "proof": "inclusion.pb", "leaf_index": "0"},
+			wantErr: true,
+		},
+		{
+			desc:    "inclusion-wrong-key",
+			flags:   map[string]string{"mode": "inclusion", "log_root": "root2.pb", "leaf": "leaf.dat", "proof": "inclusion.pb", "public_key": "other.pem"},
+			wantErr: true,
+		},
+		{
+			desc:  "consistency",
+			flags: map[string]string{"mode": "consistency", "old_log_root": "root1.pb", "log_root": "root2.pb", "proof": "consistency.pb"},
+			want:  "Verified consistency of tree size 1",
+		},
+		{
+			desc:    "consistency-wrong-proof",
+			flags:   map[string]string{"mode": "consistency", "old_log_root": "root1.pb", "log_root": "root2.pb", "proof": "inclusion.pb"},
+			wantErr: true,
+		},
+		{
+			desc:  "map-inclusion",
+			flags: map[string]string{"mode": "map_inclusion", "map_id": "7", "map_root": "map_root.pb", "map_leaf": "map_leaf.pb"},
+			want:  "in map 7 at revision 1",
+		},
+		{
+			desc:  "map-inclusion-compact",
+			flags: map[string]string{"mode": "map_inclusion", "map_id": "7", "map_root": "map_root.pb", "map_leaf": "map_compact_leaf.pb"},
+			want:  "in map 7 at revision 1",
+		},
+		{
+			desc:    "map-inclusion-bad-compact",
+			flags:   map[string]string{"mode": "map_inclusion", "map_id": "7", "map_root": "map_root.pb", "map_leaf": "map_bad_leaf.pb"},
+			wantErr: true,
+		},
+		{
+			desc:    "map-inclusion-wrong-hash-strategy",
+			flags:   map[string]string{"mode": "map_inclusion", "map_id": "7", "map_root": "map_root.pb", "map_leaf": "map_leaf.pb", "hash_strategy": "CONIKS_SHA256"},
+			wantErr: true,
+		},
+		{
+			desc:    "map-inclusion-set-leaf",
+			flags:   map[string]string{"mode": "map_inclusion", "map_id": "7", "map_root": "map_root.pb", "map_leaf": "map_set_leaf.pb"},
+			wantErr: true,
+		},
+		{
+			desc:    "map-inclusion-wrong-map",
+			flags:   map[string]string{"mode": "map_inclusion", "map_id": "8", "map_root": "map_root.pb", "map_leaf": "map_leaf.pb"},
+			wantErr: true,
+		},
+		{
+			desc:    "unknown-mode",
+			flags:   map[string]string{"mode": "audit"},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			defer flagsaver.Save().MustRestore()
+			setup.SetFlag(t, "public_key", filepath.Join(dir, "key.pem"))
+			for name, value := range tc.flags {
+				if strings.HasSuffix(value, ".pb") || strings.HasSuffix(value, ".textproto") || strings.HasSuffix(value, ".dat") || strings.HasSuffix(value, ".pem") {
+					value = filepath.Join(dir, value)
+				}
+				setup.SetFlag(t, name, value)
+			}
+
+			got, err := verify()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("verify(): %v, wantErr %v", err, tc.wantErr)
+			}
+			if !strings.Contains(got, tc.want) {
+				t.Errorf("verify(): %q, want it to contain %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The verify_log_archive binary verifies an archive written by archive_log,
+// without access to the log it came from.
+//
+// Example usage:
+// $ ./verify_log_archive --archive=log.tar.gz --checkpoint_key=example.com/log+01234567+AbCd...
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/tiles/archive"
+
+	// Load hashers
+	_ "github.com/google/trillian/merkle/rfc6962"
+	_ "github.com/google/trillian/merkle/sumdb"
+)
+
+var (
+	archivePath   = flag.String("archive", "", "Path of the archive to verify")
+	checkpointKey = flag.String("checkpoint_key", "", "Note verifier key which the checkpoint of the archive must be signed with. If unset, the key in the archive is trusted, which only checks the integrity of the archive, not its origin")
+)
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	if *archivePath == "" {
+		glog.Exit("--archive is required")
+	}
+	if *checkpointKey == "" {
+		glog.Warning("No --checkpoint_key, trusting the key in the archive")
+	}
+	f, err := os.Open(*archivePath)
+	if err != nil {
+		glog.Exitf("Failed to open archive: %v", err)
+	}
+	defer f.Close()
+	cp, err := archive.Verify(f, *checkpointKey)
+	if err != nil {
+		glog.Exitf("Archive verification failed: %v", err)
+	}
+	fmt.Printf("Verified archive of %s: %d leaves, root hash %s\n", cp.Origin, cp.Size, base64.StdEncoding.EncodeToString(cp.Hash))
+}
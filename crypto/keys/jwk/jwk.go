@@ -0,0 +1,153 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jwk converts public keys to JSON Web Keys (RFC 7517), so that they
+// can be published in a standard format which verifiers can consume.
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// Key is a JSON Web Key holding a public key used for verifying signatures.
+type Key struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// Curve, X and Y are set for EC and OKP keys, except Y which is only set
+	// for EC keys.
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+	// N and E are set for RSA keys.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// TreeID is the ID of the Trillian tree whose roots the key verifies. It
+	// is a string, as JSON numbers can't represent all 64-bit IDs exactly.
+	TreeID string `json:"trillian_tree_id,omitempty"`
+	// RetireTime is set for keys the tree has been rotated away from, to the
+	// RFC 3339 time they were retired. Roots signed after that time can't be
+	// verified with the key.
+	RetireTime string `json:"trillian_retire_time,omitempty"`
+}
+
+// Set is a JSON Web Key Set.
+type Set struct {
+	Keys []*Key `json:"keys"`
+}
+
+// FromPublicKey returns the JSON Web Key for the given ECDSA, RSA or Ed25519
+// public key. Its key ID is the key's RFC 7638 thumbprint, and its algorithm
+// is the one Trillian signs with using a key of that type. RSA keys are taken
+// to be used with SHA-256, the only hash algorithm that trees support.
+func FromPublicKey(pub crypto.PublicKey) (*Key, error) {
+	var k *Key
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		var alg string
+		switch pub.Curve {
+		case elliptic.P256():
+			alg = "ES256"
+		case elliptic.P384():
+			alg = "ES384"
+		case elliptic.P521():
+			alg = "ES512"
+		default:
+			return nil, fmt.Errorf("jwk: unsupported elliptic curve %v", pub.Curve.Params().Name)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		k = &Key{
+			KeyType:   "EC",
+			Algorithm: alg,
+			Curve:     pub.Curve.Params().Name,
+			X:         encode(pad(pub.X, size)),
+			Y:         encode(pad(pub.Y, size)),
+		}
+	case *rsa.PublicKey:
+		k = &Key{
+			KeyType:   "RSA",
+			Algorithm: "RS256",
+			N:         encode(pub.N.Bytes()),
+			E:         encode(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		k = &Key{
+			KeyType:   "OKP",
+			Algorithm: "EdDSA",
+			Curve:     "Ed25519",
+			X:         encode(pub),
+		}
+	default:
+		return nil, fmt.Errorf("jwk: unsupported public key type %T", pub)
+	}
+	k.Use = "sig"
+	var err error
+	if k.KeyID, err = Thumbprint(k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of the key, encoded as
+// unpadded base64url. It only depends on the key material, so it changes if
+// and only if the key does.
+func Thumbprint(k *Key) (string, error) {
+	// RFC 7638 hashes the required members only, sorted and without
+	// whitespace, which is how encoding/json marshals maps.
+	var members map[string]string
+	switch k.KeyType {
+	case "EC":
+		members = map[string]string{"crv": k.Curve, "kty": k.KeyType, "x": k.X, "y": k.Y}
+	case "RSA":
+		members = map[string]string{"e": k.E, "kty": k.KeyType, "n": k.N}
+	case "OKP":
+		members = map[string]string{"crv": k.Curve, "kty": k.KeyType, "x": k.X}
+	default:
+		return "", fmt.Errorf("jwk: unsupported key type %q", k.KeyType)
+	}
+	b, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return encode(sum[:]), nil
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// pad returns the big-endian bytes of x, left-padded with zeros to size bytes,
+// as RFC 7518 requires for elliptic curve coordinates.
+func pad(x *big.Int, size int) []byte {
+	b := x.Bytes()
+	if len(b) >= size {
+		return b
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestThumbprint(t *testing.T) {
+	// The example from RFC 7638 section 3.1.
+	k := &Key{
+		KeyType: "RSA",
+		N:       "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:       "AQAB",
+		// Optional members don't affect the thumbprint.
+		Algorithm: "RS256",
+		KeyID:     "2011-04-29",
+	}
+	got, err := Thumbprint(k)
+	if err != nil {
+		t.Fatalf("Thumbprint()=%v", err)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("Thumbprint()=%q, want %q", got, want)
+	}
+
+	if _, err := Thumbprint(&Key{KeyType: "oct"}); err == nil {
+		t.Error("Thumbprint(oct)=nil, want error")
+	}
+}
+
+func TestFromPublicKey(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey()=%v", err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey()=%v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey()=%v", err)
+	}
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey()=%v", err)
+	}
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey()=%v", err)
+	}
+
+	for _, test := range []struct {
+		desc    string
+		pub     crypto.PublicKey
+		wantKty string
+		wantAlg string
+		wantErr bool
+	}{
+		{desc: "P-256", pub: p256.Public(), wantKty: "EC", wantAlg: "ES256"},
+		{desc: "P-384", pub: p384.Public(), wantKty: "EC", wantAlg: "ES384"},
+		{desc: "RSA", pub: rsaKey.Public(), wantKty: "RSA", wantAlg: "RS256"},
+		{desc: "Ed25519", pub: edPub, wantKty: "OKP", wantAlg: "EdDSA"},
+		{desc: "P-224", pub: p224.Public(), wantErr: true},
+		{desc: "unknown", pub: "key", wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			k, err := FromPublicKey(test.pub)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("FromPublicKey()=(_, %v), want err? %v", err, test.wantErr)
+			} else if gotErr {
+				return
+			}
+			if k.KeyType != test.wantKty || k.Algorithm != test.wantAlg || k.Use != "sig" {
+				t.Errorf("FromPublicKey()=%+v, want kty %q, alg %q, use sig", k, test.wantKty, test.wantAlg)
+			}
+			if want, err := Thumbprint(k); err != nil || k.KeyID != want {
+				t.Errorf("FromPublicKey() kid=%q, want thumbprint %q (err %v)", k.KeyID, want, err)
+			}
+			if got := publicKey(t, k); !equal(got, test.pub) {
+				t.Errorf("FromPublicKey() encoded %v, want %v", got, test.pub)
+			}
+		})
+	}
+}
+
+// publicKey decodes the public key in k.
+func publicKey(t *testing.T, k *Key) crypto.PublicKey {
+	t.Helper()
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("DecodeString(%q)=%v", s, err)
+		}
+		return b
+	}
+	switch k.KeyType {
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve := curves[k.Curve]
+		x, y := decode(k.X), decode(k.Y)
+		if size := (curve.Params().BitSize + 7) / 8; len(x) != size || len(y) != size {
+			t.Errorf("coordinates have %d and %d bytes, want %d", len(x), len(y), size)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	case "RSA":
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(decode(k.N)), E: int(new(big.Int).SetBytes(decode(k.E)).Int64())}
+	case "OKP":
+		return ed25519.PublicKey(decode(k.X))
+	}
+	t.Fatalf("unknown key type %q", k.KeyType)
+	return nil
+}
+
+func equal(a, b crypto.PublicKey) bool {
+	switch a := a.(type) {
+	case *ecdsa.PublicKey:
+		b, ok := b.(*ecdsa.PublicKey)
+		return ok && a.Curve == b.Curve && a.X.Cmp(b.X) == 0 && a.Y.Cmp(b.Y) == 0
+	case *rsa.PublicKey:
+		b, ok := b.(*rsa.PublicKey)
+		return ok && a.N.Cmp(b.N) == 0 && a.E == b.E
+	case ed25519.PublicKey:
+		b, ok := b.(ed25519.PublicKey)
+		return ok && a.Equal(b)
+	}
+	return false
+}
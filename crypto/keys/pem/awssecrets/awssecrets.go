@@ -0,0 +1,63 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package awssecrets registers a PEM password source which fetches passwords
+// from AWS Secrets Manager. Credentials and the region are taken from the
+// usual AWS environment variables and shared configuration files.
+//
+// Passwords are referenced by secret name or ARN, e.g.
+// "aws-secrets-manager:trillian/log-key". The current version of the secret
+// is used, and it may be stored as either a string or binary secret.
+package awssecrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/google/trillian/crypto/keys/pem"
+)
+
+// SourceName is the scheme under which the password source is registered.
+const SourceName = "aws-secrets-manager"
+
+func init() {
+	if err := pem.RegisterPasswordSource(SourceName, func(ctx context.Context, id string) (string, error) {
+		sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+		if err != nil {
+			return "", fmt.Errorf("failed to create AWS session: %v", err)
+		}
+		return Fetch(ctx, secretsmanager.New(sess), id)
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// Fetch returns the current value of the secret with the given name or ARN.
+func Fetch(ctx context.Context, client secretsmanageriface.SecretsManagerAPI, id string) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("empty secret ID")
+	}
+	out, err := client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %v", id, err)
+	}
+	if out.SecretString != nil {
+		return *out.SecretString, nil
+	}
+	return string(out.SecretBinary), nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awssecrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+)
+
+// fakeClient serves secrets from a map of secret IDs to values.
+type fakeClient struct {
+	secretsmanageriface.SecretsManagerAPI
+	secrets map[string]*secretsmanager.GetSecretValueOutput
+}
+
+func (c *fakeClient) GetSecretValueWithContext(_ aws.Context, in *secretsmanager.GetSecretValueInput, _ ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	if out, ok := c.secrets[aws.StringValue(in.SecretId)]; ok {
+		return out, nil
+	}
+	return nil, errors.New("ResourceNotFoundException")
+}
+
+func TestFetch(t *testing.T) {
+	client := &fakeClient{secrets: map[string]*secretsmanager.GetSecretValueOutput{
+		"trillian/string": {SecretString: aws.String("towel")},
+		"arn:aws:secretsmanager:us-east-1:123456789012:secret:trillian/binary": {SecretBinary: []byte("towel")},
+	}}
+
+	ctx := context.Background()
+	for _, test := range []struct {
+		id      string
+		wantErr bool
+	}{
+		{id: "trillian/string"},
+		{id: "arn:aws:secretsmanager:us-east-1:123456789012:secret:trillian/binary"},
+		{id: "trillian/missing", wantErr: true},
+		{id: "", wantErr: true},
+	} {
+		got, err := Fetch(ctx, client, test.id)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("Fetch(%q)=(_, %v), want err? %v", test.id, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && got != "towel" {
+			t.Errorf("Fetch(%q)=%q, want %q", test.id, got, "towel")
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gcpsecrets registers a PEM password source which fetches passwords
+// from Google Cloud Secret Manager, using Application Default Credentials.
+//
+// Passwords are referenced by the resource names of secret versions, e.g.
+// "gcp-secret-manager:projects/my-project/secrets/log-key/versions/3". If the
+// version is omitted, the latest version is used.
+package gcpsecrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/google/trillian/crypto/keys/pem"
+	"google.golang.org/api/option"
+	secretmanager "google.golang.org/api/secretmanager/v1"
+)
+
+// SourceName is the scheme under which the password source is registered.
+const SourceName = "gcp-secret-manager"
+
+func init() {
+	if err := pem.RegisterPasswordSource(SourceName, func(ctx context.Context, ref string) (string, error) {
+		return Fetch(ctx, ref)
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// Fetch returns the payload of the named secret version, which may also be
+// the name of a secret, in which case its latest version is used.
+func Fetch(ctx context.Context, name string, opts ...option.ClientOption) (string, error) {
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 4 && parts[0] == "projects" && parts[2] == "secrets":
+		name += "/versions/latest"
+	case len(parts) == 6 && parts[0] == "projects" && parts[2] == "secrets" && parts[4] == "versions":
+	default:
+		return "", fmt.Errorf("invalid secret name %q, want projects/*/secrets/*[/versions/*]", name)
+	}
+
+	svc, err := secretmanager.NewService(ctx, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Secret Manager client: %v", err)
+	}
+	rsp, err := svc.Projects.Secrets.Versions.Access(name).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to access %s: %v", name, err)
+	}
+	if rsp.Payload == nil {
+		return "", fmt.Errorf("no payload in %s", name)
+	}
+	data, err := base64.StdEncoding.DecodeString(rsp.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode payload of %s: %v", name, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpsecrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+func TestFetch(t *testing.T) {
+	const want = "towel"
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if r.URL.Path != "/v1/projects/p/secrets/key/versions/latest:access" && r.URL.Path != "/v1/projects/p/secrets/key/versions/3:access" {
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"name": %q, "payload": {"data": %q}}`, r.URL.Path, base64.StdEncoding.EncodeToString([]byte(want)))
+	}))
+	defer srv.Close()
+	opts := []option.ClientOption{option.WithEndpoint(srv.URL), option.WithoutAuthentication()}
+
+	ctx := context.Background()
+	for _, test := range []struct {
+		name     string
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "projects/p/secrets/key", wantPath: "/v1/projects/p/secrets/key/versions/latest:access"},
+		{name: "projects/p/secrets/key/versions/3", wantPath: "/v1/projects/p/secrets/key/versions/3:access"},
+		{name: "projects/p/secrets/missing", wantErr: true},
+		{name: "p/key", wantErr: true},
+		{name: "projects/p/keys/key", wantErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			gotPath = ""
+			got, err := Fetch(ctx, test.name, opts...)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Fetch()=(_, %v), want err? %v", err, test.wantErr)
+			} else if gotErr {
+				return
+			}
+			if got != want {
+				t.Errorf("Fetch()=%q, want %q", got, want)
+			}
+			if gotPath != test.wantPath {
+				t.Errorf("Fetch() requested %q, want %q", gotPath, test.wantPath)
+			}
+		})
+	}
+}
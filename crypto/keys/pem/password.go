@@ -0,0 +1,123 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pem
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PasswordSourceFunc is the signature of a function which can be registered
+// to fetch the passwords of private keys from a particular kind of source,
+// such as a secret manager. The ref identifies the password within the
+// source, and its format is specific to the source.
+type PasswordSourceFunc func(ctx context.Context, ref string) (string, error)
+
+const (
+	// EnvPasswordSource is the scheme of password sources which read the
+	// password from the environment variable named by the reference.
+	EnvPasswordSource = "env"
+	// FilePasswordSource is the scheme of password sources which read the
+	// password from the file named by the reference. Trailing newlines are
+	// removed.
+	FilePasswordSource = "file"
+)
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = make(map[string]PasswordSourceFunc)
+)
+
+func init() {
+	if err := RegisterPasswordSource(EnvPasswordSource, envPassword); err != nil {
+		panic(err)
+	}
+	if err := RegisterPasswordSource(FilePasswordSource, filePassword); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterPasswordSource registers the function used to fetch passwords from
+// sources with the given scheme.
+func RegisterPasswordSource(scheme string, f PasswordSourceFunc) error {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+
+	if _, exists := sources[scheme]; exists {
+		return fmt.Errorf("pemfile: password source %q already registered", scheme)
+	}
+	sources[scheme] = f
+	return nil
+}
+
+// PasswordSources returns the schemes of all registered password sources,
+// sorted.
+func PasswordSources() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+
+	r := []string{}
+	for k := range sources {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// FetchPassword fetches a password from the given source, which has the form
+// "<scheme>:<reference>". It is an error for the password to be empty.
+func FetchPassword(ctx context.Context, source string) (string, error) {
+	scheme, ref := source, ""
+	if i := strings.Index(source, ":"); i >= 0 {
+		scheme, ref = source[:i], source[i+1:]
+	}
+
+	sourcesMu.RLock()
+	f := sources[scheme]
+	sourcesMu.RUnlock()
+	if f == nil {
+		return "", fmt.Errorf("pemfile: unknown password source %q, want one of %v", scheme, PasswordSources())
+	}
+
+	password, err := f(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("pemfile: failed to fetch password from %s source: %v", scheme, err)
+	}
+	if password == "" {
+		return "", fmt.Errorf("pemfile: empty password from %s source", scheme)
+	}
+	return password, nil
+}
+
+func envPassword(_ context.Context, name string) (string, error) {
+	password, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %q not set", name)
+	}
+	return password, nil
+}
+
+func filePassword(_ context.Context, path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pem_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/google/trillian/crypto/keys/pem"
+	ktestonly "github.com/google/trillian/crypto/keys/testonly"
+	"github.com/google/trillian/crypto/keyspb"
+)
+
+func TestFetchPassword(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "pem")
+	if err != nil {
+		t.Fatalf("TempDir()=%v", err)
+	}
+	defer os.RemoveAll(dir)
+	passwordFile := filepath.Join(dir, "password")
+	if err := ioutil.WriteFile(passwordFile, []byte("towel\n"), 0600); err != nil {
+		t.Fatalf("WriteFile()=%v", err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := ioutil.WriteFile(emptyFile, nil, 0600); err != nil {
+		t.Fatalf("WriteFile()=%v", err)
+	}
+	os.Setenv("PEM_TEST_PASSWORD", "towel")
+	defer os.Unsetenv("PEM_TEST_PASSWORD")
+
+	if err := RegisterPasswordSource("test-secrets", func(_ context.Context, ref string) (string, error) {
+		if ref == "projects/p/secrets/key" {
+			return "towel", nil
+		}
+		return "", errors.New("no such secret")
+	}); err != nil {
+		t.Fatalf("RegisterPasswordSource()=%v", err)
+	}
+	if err := RegisterPasswordSource(EnvPasswordSource, nil); err == nil {
+		t.Error("RegisterPasswordSource(env)=nil, want error for duplicate")
+	}
+
+	for _, test := range []struct {
+		source  string
+		want    string
+		wantErr bool
+	}{
+		{source: "env:PEM_TEST_PASSWORD", want: "towel"},
+		{source: "env:PEM_TEST_UNSET_PASSWORD", wantErr: true},
+		{source: "file:" + passwordFile, want: "towel"},
+		{source: "file:" + emptyFile, wantErr: true},
+		{source: "file:" + filepath.Join(dir, "missing"), wantErr: true},
+		{source: "test-secrets:projects/p/secrets/key", want: "towel"},
+		{source: "test-secrets:projects/p/secrets/other", wantErr: true},
+		{source: "towel", wantErr: true},
+		{source: "vault:secret/key", wantErr: true},
+	} {
+		got, err := FetchPassword(ctx, test.source)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("FetchPassword(%q)=(_, %v), want err? %v", test.source, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("FetchPassword(%q)=%q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+func TestFromProtoContextPasswordSource(t *testing.T) {
+	ctx := context.Background()
+	os.Setenv("PEM_TEST_PASSWORD", "towel")
+	defer os.Unsetenv("PEM_TEST_PASSWORD")
+
+	for _, test := range []struct {
+		desc     string
+		keyProto *keyspb.PEMKeyFile
+		wantErr  bool
+	}{
+		{
+			desc: "password-source",
+			keyProto: &keyspb.PEMKeyFile{
+				Path:           "../../../testdata/log-rpc-server.privkey.pem",
+				PasswordSource: "env:PEM_TEST_PASSWORD",
+			},
+		},
+		{
+			desc: "password-overrides-source",
+			keyProto: &keyspb.PEMKeyFile{
+				Path:           "../../../testdata/log-rpc-server.privkey.pem",
+				Password:       "towel",
+				PasswordSource: "env:PEM_TEST_UNSET_PASSWORD",
+			},
+		},
+		{
+			desc: "password-source-fails",
+			keyProto: &keyspb.PEMKeyFile{
+				Path:           "../../../testdata/log-rpc-server.privkey.pem",
+				PasswordSource: "env:PEM_TEST_UNSET_PASSWORD",
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			signer, err := FromProtoContext(ctx, test.keyProto)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("FromProtoContext()=(_, %v), want err? %v", err, test.wantErr)
+			} else if gotErr {
+				return
+			}
+			if err := ktestonly.SignAndVerify(signer, signer.Public()); err != nil {
+				t.Errorf("SignAndVerify()=%v, want nil", err)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package pem
 
 import (
+	"context"
 	"crypto"
 	"crypto/x509"
 	"encoding/pem"
@@ -28,7 +29,20 @@ import (
 
 // FromProto takes a PEMKeyFile protobuf message and loads the private key it specifies.
 func FromProto(pb *keyspb.PEMKeyFile) (crypto.Signer, error) {
-	return ReadPrivateKeyFile(pb.GetPath(), pb.GetPassword())
+	return FromProtoContext(context.Background(), pb)
+}
+
+// FromProtoContext is like FromProto, but uses ctx for fetching the password
+// from the message's password source, if it has one rather than a password.
+func FromProtoContext(ctx context.Context, pb *keyspb.PEMKeyFile) (crypto.Signer, error) {
+	password := pb.GetPassword()
+	if source := pb.GetPasswordSource(); password == "" && source != "" {
+		var err error
+		if password, err = FetchPassword(ctx, source); err != nil {
+			return nil, err
+		}
+	}
+	return ReadPrivateKeyFile(pb.GetPath(), password)
 }
 
 // ReadPrivateKeyFile reads a PEM-encoded private key from a file.
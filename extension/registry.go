@@ -20,6 +20,7 @@ import (
 	"github.com/google/trillian/crypto/keys"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/quota"
+	"github.com/google/trillian/server/maintenance"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/util/election2"
 )
@@ -45,4 +46,7 @@ type Registry struct {
 	NewKeyProto keys.ProtoGenerator
 	// SetProcessStatus sets the current process status for diagnostic purposes.
 	SetProcessStatus func(string)
+	// Maintenance is the maintenance mode of the server, during which write
+	// RPCs are rejected. Nil if the server is never put into maintenance.
+	Maintenance *maintenance.Mode
 }
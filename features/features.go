@@ -0,0 +1,294 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package features provides runtime feature flags, which turn behaviors of
+// Trillian servers on and off, for all trees or for single trees, without
+// restarting them. This lets operators roll features out gradually, and roll
+// them back if they misbehave.
+//
+// Each feature is enabled or disabled by default. Overrides for all trees take
+// precedence over the default, and overrides for single trees over both.
+// Overrides are kept in memory, and are set by a file which servers reload
+// (see Set.Watch), or by the SetFeatureFlags admin RPC.
+package features
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Features gated by flags.
+const (
+	// SharedSubtreeCache is whether log transactions read subtrees through
+	// the shared subtree cache of the storage, if it has one.
+	SharedSubtreeCache = "shared_subtree_cache"
+	// CompactMapProofs is whether map reads return compact inclusion proofs
+	// to the clients which ask for them, rather than full proofs.
+	CompactMapProofs = "compact_map_proofs"
+	// TileEndpoints is whether logs are served by the tlog-tiles and
+	// checksum database HTTP endpoints, if the server has them.
+	TileEndpoints = "tile_endpoints"
+	// NodeID2Proofs is whether map reads compute the IDs of the nodes of
+	// full inclusion proofs as tree.NodeID2s, rather than legacy tree.NodeIDs.
+	NodeID2Proofs = "node_id2_proofs"
+)
+
+// defaults holds whether each known feature is enabled by default.
+var defaults = map[string]bool{
+	SharedSubtreeCache: true,
+	CompactMapProofs:   true,
+	TileEndpoints:      true,
+	NodeID2Proofs:      true,
+}
+
+// Default is the feature set of the process.
+var Default = &Set{}
+
+// Enabled returns whether the named feature is enabled for the tree with the
+// given ID in the Default set.
+func Enabled(name string, treeID int64) bool {
+	return Default.Enabled(name, treeID)
+}
+
+// Defaults returns whether each known feature is enabled by default, keyed by
+// feature name.
+func Defaults() map[string]bool {
+	ret := make(map[string]bool, len(defaults))
+	for name, enabled := range defaults {
+		ret[name] = enabled
+	}
+	return ret
+}
+
+// Override enables or disables a feature for a tree, or for all trees if
+// TreeID is 0.
+type Override struct {
+	Name    string
+	TreeID  int64
+	Enabled bool
+}
+
+// String returns the override in the format read by Parse.
+func (o Override) String() string {
+	if o.TreeID == 0 {
+		return fmt.Sprintf("%s=%t", o.Name, o.Enabled)
+	}
+	return fmt.Sprintf("%s@%d=%t", o.Name, o.TreeID, o.Enabled)
+}
+
+type key struct {
+	name   string
+	treeID int64
+}
+
+// Set is a set of feature overrides. The zero value has no overrides, and so
+// has every feature in its default state.
+type Set struct {
+	mu        sync.RWMutex
+	overrides map[key]bool
+}
+
+// Enabled returns whether the named feature is enabled for the tree with the
+// given ID. Unknown features are disabled. A nil Set has every feature in its
+// default state.
+func (s *Set) Enabled(name string, treeID int64) bool {
+	if s != nil {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		if enabled, ok := s.overrides[key{name, treeID}]; ok {
+			return enabled
+		}
+		if enabled, ok := s.overrides[key{name, 0}]; ok {
+			return enabled
+		}
+	}
+	return defaults[name]
+}
+
+// Apply sets the given overrides, replacing any of the same feature and tree,
+// and removes those of the features and trees of clear, whose Enabled fields
+// are ignored. Nothing is changed if any of them names an unknown feature.
+func (s *Set) Apply(set, clear []Override) error {
+	if err := check(set); err != nil {
+		return err
+	}
+	if err := check(clear); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.overrides == nil {
+		s.overrides = make(map[key]bool)
+	}
+	for _, o := range clear {
+		delete(s.overrides, key{o.Name, o.TreeID})
+	}
+	for _, o := range set {
+		s.overrides[key{o.Name, o.TreeID}] = o.Enabled
+	}
+	return nil
+}
+
+// Replace replaces all of the overrides with the given ones, unless any of
+// them names an unknown feature.
+func (s *Set) Replace(overrides []Override) error {
+	if err := check(overrides); err != nil {
+		return err
+	}
+	m := make(map[key]bool, len(overrides))
+	for _, o := range overrides {
+		m[key{o.Name, o.TreeID}] = o.Enabled
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides = m
+	return nil
+}
+
+// Overrides returns the overrides of the set, ordered by feature name and
+// then tree ID.
+func (s *Set) Overrides() []Override {
+	s.mu.RLock()
+	ret := make([]Override, 0, len(s.overrides))
+	for k, enabled := range s.overrides {
+		ret = append(ret, Override{Name: k.name, TreeID: k.treeID, Enabled: enabled})
+	}
+	s.mu.RUnlock()
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Name != ret[j].Name {
+			return ret[i].Name < ret[j].Name
+		}
+		return ret[i].TreeID < ret[j].TreeID
+	})
+	return ret
+}
+
+func check(overrides []Override) error {
+	for _, o := range overrides {
+		if _, ok := defaults[o.Name]; !ok {
+			return fmt.Errorf("unknown feature %q", o.Name)
+		}
+		if o.TreeID < 0 {
+			return fmt.Errorf("feature %q: invalid tree ID %d", o.Name, o.TreeID)
+		}
+	}
+	return nil
+}
+
+// Parse parses overrides separated by commas or whitespace, each of the form
+// name=bool for all trees, or name@treeID=bool for a single tree, e.g.
+// "shared_subtree_cache=false compact_map_proofs@1234=false". Lines starting
+// with '#' are comments.
+func Parse(text string) ([]Override, error) {
+	var ret []Override
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\r' }) {
+			o, err := parseOverride(field)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, o)
+		}
+	}
+	if err := check(ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func parseOverride(text string) (Override, error) {
+	i := strings.Index(text, "=")
+	if i < 0 {
+		return Override{}, fmt.Errorf("feature override %q: want name=bool or name@treeID=bool", text)
+	}
+	o := Override{Name: text[:i]}
+	enabled, err := strconv.ParseBool(text[i+1:])
+	if err != nil {
+		return Override{}, fmt.Errorf("feature override %q: %v", text, err)
+	}
+	o.Enabled = enabled
+	if j := strings.Index(o.Name, "@"); j >= 0 {
+		if o.TreeID, err = strconv.ParseInt(o.Name[j+1:], 10, 64); err != nil || o.TreeID <= 0 {
+			return Override{}, fmt.Errorf("feature override %q: invalid tree ID", text)
+		}
+		o.Name = o.Name[:j]
+	}
+	return o, nil
+}
+
+// Watch replaces the overrides of the set with those parsed from the file at
+// path, and then does so again every interval if the contents of the file have
+// changed, until ctx is done. Overrides set since the file was last read, e.g.
+// by the SetFeatureFlags admin RPC, are lost when it changes. An error is
+// returned if the file can't be loaded at first, while later errors are only
+// logged, and leave the overrides as they are.
+func (s *Set) Watch(ctx context.Context, path string, interval time.Duration) error {
+	last, err := s.load(path, "")
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return nil
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if contents, err := s.load(path, last); err != nil {
+				glog.Warningf("Failed to reload feature flags: %v", err)
+			} else {
+				last = contents
+			}
+		}
+	}()
+	return nil
+}
+
+// load replaces the overrides with those parsed from the file at path, unless
+// its contents are the same as last. It returns the contents of the file.
+func (s *Set) load(path, last string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read feature flags: %v", err)
+	}
+	contents := string(b)
+	if contents == last {
+		return contents, nil
+	}
+	overrides, err := Parse(contents)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse feature flags in %s: %v", path, err)
+	}
+	if err := s.Replace(overrides); err != nil {
+		return "", err
+	}
+	glog.Infof("Loaded feature flags from %s: %v", path, overrides)
+	return contents, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package features
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		desc    string
+		text    string
+		want    []Override
+		wantErr bool
+	}{
+		{desc: "empty"},
+		{
+			desc: "commas",
+			text: "shared_subtree_cache=false,compact_map_proofs@12=true",
+			want: []Override{{Name: SharedSubtreeCache}, {Name: CompactMapProofs, TreeID: 12, Enabled: true}},
+		},
+		{
+			desc: "lines",
+			text: "# Roll back tiles.\ntile_endpoints=0\n\ntile_endpoints@3=1 shared_subtree_cache@4=false\n",
+			want: []Override{{Name: TileEndpoints}, {Name: TileEndpoints, TreeID: 3, Enabled: true}, {Name: SharedSubtreeCache, TreeID: 4}},
+		},
+		{desc: "unknown", text: "warp_drive=true", wantErr: true},
+		{desc: "noValue", text: "tile_endpoints", wantErr: true},
+		{desc: "badValue", text: "tile_endpoints=maybe", wantErr: true},
+		{desc: "badTree", text: "tile_endpoints@x=true", wantErr: true},
+		{desc: "zeroTree", text: "tile_endpoints@0=true", wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := Parse(test.text)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Parse(%q): %v, wantErr %v", test.text, err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Parse(%q): %v, want %v", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetEnabled(t *testing.T) {
+	var nilSet *Set
+	if !nilSet.Enabled(TileEndpoints, 1) {
+		t.Error("nil Set: tile_endpoints disabled, want default")
+	}
+
+	var s Set
+	if err := s.Apply([]Override{{Name: TileEndpoints}, {Name: TileEndpoints, TreeID: 2, Enabled: true}}, nil); err != nil {
+		t.Fatalf("Apply(): %v", err)
+	}
+	for _, test := range []struct {
+		name   string
+		treeID int64
+		want   bool
+	}{
+		{name: TileEndpoints, treeID: 1, want: false},
+		{name: TileEndpoints, treeID: 2, want: true},
+		{name: CompactMapProofs, treeID: 1, want: true},
+		{name: "unknown", treeID: 1, want: false},
+	} {
+		if got := s.Enabled(test.name, test.treeID); got != test.want {
+			t.Errorf("Enabled(%s, %d): %v, want %v", test.name, test.treeID, got, test.want)
+		}
+	}
+
+	if err := s.Apply(nil, []Override{{Name: TileEndpoints}}); err != nil {
+		t.Fatalf("Apply(): %v", err)
+	}
+	if want := []Override{{Name: TileEndpoints, TreeID: 2, Enabled: true}}; !reflect.DeepEqual(s.Overrides(), want) {
+		t.Errorf("Overrides(): %v, want %v", s.Overrides(), want)
+	}
+	if err := s.Apply([]Override{{Name: SharedSubtreeCache}, {Name: "unknown"}}, nil); err == nil {
+		t.Error("Apply(unknown): nil error")
+	}
+	if !s.Enabled(SharedSubtreeCache, 1) {
+		t.Error("Apply(unknown) changed other overrides")
+	}
+}
+
+func TestSetWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "features")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "features")
+	write := func(text string) {
+		t.Helper()
+		if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatalf("WriteFile(): %v", err)
+		}
+	}
+
+	var s Set
+	if err := s.Watch(context.Background(), path, time.Millisecond); err == nil {
+		t.Fatal("Watch(missing file): nil error")
+	}
+	write("tile_endpoints=false")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := s.Watch(ctx, path, 10*time.Millisecond); err != nil {
+		t.Fatalf("Watch(): %v", err)
+	}
+	if s.Enabled(TileEndpoints, 1) {
+		t.Error("tile_endpoints enabled after Watch()")
+	}
+
+	// Bad contents leave the overrides as they are.
+	write("tile_endpoints=maybe")
+	time.Sleep(50 * time.Millisecond)
+	if s.Enabled(TileEndpoints, 1) {
+		t.Error("tile_endpoints enabled after bad reload")
+	}
+	write("compact_map_proofs=false")
+	for deadline := time.Now().Add(5 * time.Second); s.Enabled(CompactMapProofs, 1); {
+		if time.Now().After(deadline) {
+			t.Fatal("compact_map_proofs not disabled by reload")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !s.Enabled(TileEndpoints, 1) {
+		t.Error("tile_endpoints still disabled after reload")
+	}
+}
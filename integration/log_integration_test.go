@@ -20,6 +20,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
 	"google.golang.org/grpc"
 
 	"github.com/google/trillian"
@@ -29,9 +31,14 @@ import (
 	"github.com/google/trillian/storage/memory"
 	"github.com/google/trillian/storage/testdb"
 	"github.com/google/trillian/testonly/integration"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/election"
+	"github.com/google/trillian/util/election2"
 
 	_ "github.com/google/trillian/crypto/keys/der/proto" // Register PrivateKey ProtoHandler
 	stestonly "github.com/google/trillian/storage/testonly"
+	etestonly "github.com/google/trillian/util/election2/testonly"
 )
 
 var (
@@ -147,3 +154,144 @@ func TestInProcessLogIntegrationDuplicateLeaves(t *testing.T) {
 		t.Fatalf("Test failed: %v", err)
 	}
 }
+
+func TestInProcessLogIntegrationMaxRootDuration(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	fakeTime := clock.NewFake(start)
+
+	ts := memory.NewTreeStorage()
+	reggie := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   memory.NewLogStorage(ts, nil),
+		QuotaManager: quota.Noop(),
+	}
+	env, err := integration.NewLogEnvWithRegistryAndOptions(ctx, 1, reggie, integration.LogEnvOptions{TimeSource: fakeTime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+
+	const maxRootDuration = time.Hour
+	tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	tree.MaxRootDuration = ptypes.DurationProto(maxRootDuration)
+	tree, err = client.CreateAndInitTree(ctx, &trillian.CreateTreeRequest{Tree: tree}, env.Admin, nil, env.Log)
+	if err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+	latestRoot := func() types.LogRootV1 {
+		t.Helper()
+		resp, err := env.Log.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: tree.TreeId})
+		if err != nil {
+			t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(resp.GetSignedLogRoot().GetLogRoot()); err != nil {
+			t.Fatalf("UnmarshalBinary(): %v", err)
+		}
+		return root
+	}
+
+	// Just before the max root duration, a pass must not publish a new root.
+	fakeTime.Advance(maxRootDuration - time.Second)
+	env.Sequencer.OperationSingle(ctx)
+	if root := latestRoot(); root.Revision != 0 {
+		t.Fatalf("root revision = %d before the max root duration, want 0", root.Revision)
+	}
+
+	// Once it has passed, a pass must publish a fresh empty root.
+	fakeTime.Advance(time.Second)
+	env.Sequencer.OperationSingle(ctx)
+	root := latestRoot()
+	if root.Revision == 0 {
+		t.Fatalf("no new root published after advancing time by %v", maxRootDuration)
+	}
+	if got, min := time.Unix(0, int64(root.TimestampNanos)), start.Add(maxRootDuration); got.Before(min) {
+		t.Errorf("root timestamp = %v, want at least %v", got, min)
+	}
+	if root.TreeSize != 0 {
+		t.Errorf("root tree size = %d, want 0", root.TreeSize)
+	}
+}
+
+// leaseFactory creates elections which report the times, as measured by ts,
+// at which mastership is won and resigned.
+type leaseFactory struct {
+	ts                clock.TimeSource
+	elected, resigned chan time.Time
+}
+
+func (f *leaseFactory) NewElection(ctx context.Context, resourceID string) (election2.Election, error) {
+	return &leaseElection{Election: etestonly.NewElection(), f: f}, nil
+}
+
+type leaseElection struct {
+	*etestonly.Election
+	f *leaseFactory
+}
+
+func (e *leaseElection) Await(ctx context.Context) error {
+	if err := e.Election.Await(ctx); err != nil {
+		return err
+	}
+	e.f.elected <- e.f.ts.Now()
+	return nil
+}
+
+func (e *leaseElection) Resign(ctx context.Context) error {
+	e.f.resigned <- e.f.ts.Now()
+	return e.Election.Resign(ctx)
+}
+
+func TestInProcessLogIntegrationMastershipExpiry(t *testing.T) {
+	ctx := context.Background()
+	fakeTime := clock.NewFake(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	elections := &leaseFactory{ts: fakeTime, elected: make(chan time.Time, 10), resigned: make(chan time.Time, 10)}
+
+	ts := memory.NewTreeStorage()
+	reggie := extension.Registry{
+		AdminStorage:    memory.NewAdminStorage(ts),
+		LogStorage:      memory.NewLogStorage(ts, nil),
+		QuotaManager:    quota.Noop(),
+		ElectionFactory: elections,
+	}
+	env, err := integration.NewLogEnvWithRegistryAndOptions(ctx, 1, reggie, integration.LogEnvOptions{TimeSource: fakeTime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+
+	if _, err := client.CreateAndInitTree(ctx, &trillian.CreateTreeRequest{Tree: stestonly.LogTree}, env.Admin, nil, env.Log); err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	// awaitEvent advances time by step until the event happens, which needs
+	// the sequencer's goroutines to react to the timers that fire.
+	awaitEvent := func(event <-chan time.Time, step time.Duration, desc string) time.Time {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			select {
+			case at := <-event:
+				return at
+			case <-time.After(10 * time.Millisecond):
+			}
+			fakeTime.Advance(step)
+		}
+		t.Fatalf("sequencer never %s", desc)
+		return time.Time{}
+	}
+
+	// Let the sequencer start the election for the log, and win it.
+	env.Sequencer.OperationSingle(ctx)
+	elected := awaitEvent(elections.elected, election.MinPreElectionPause, "won mastership")
+
+	// Mastership must be resigned once it has been held for the hold
+	// interval, and won again afterwards.
+	resigned := awaitEvent(elections.resigned, time.Second, "resigned mastership")
+	if held := resigned.Sub(elected); held < election.MinMasterHoldInterval {
+		t.Errorf("mastership resigned after %v, want at least %v", held, election.MinMasterHoldInterval)
+	}
+	if reelected := awaitEvent(elections.elected, time.Second, "won mastership again"); reelected.Before(resigned) {
+		t.Errorf("mastership won again at %v, before it was resigned at %v", reelected, resigned)
+	}
+}
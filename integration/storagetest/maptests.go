@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/trillian"
@@ -31,6 +32,8 @@ import (
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/tree"
 	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/testing/protocmp"
 
 	storageto "github.com/google/trillian/storage/testonly"
@@ -298,3 +301,126 @@ func (*mapTests) TestLatestSignedMapRoot(ctx context.Context, t *testing.T, ms s
 		}
 	}
 }
+
+func (*mapTests) TestSnapshotAtRevision(ctx context.Context, t *testing.T, ms storage.MapStorage, as storage.AdminStorage) {
+	tree := mustCreateTree(ctx, t, as, storageto.MapTree)
+	timestamp := time.Now()
+	roots := make([]*types.MapRootV1, 4)
+	for i := range roots {
+		roots[i] = &types.MapRootV1{
+			TimestampNanos: uint64(timestamp.Add(time.Duration(i) * time.Second).UnixNano()),
+			RootHash:       []byte(fmt.Sprintf("roothash %d", i)),
+			Revision:       uint64(i),
+			Metadata:       []byte{byte(i)},
+		}
+		mustSignAndStoreMapRoot(ctx, t, ms, tree, roots[i])
+	}
+
+	for _, rev := range []int64{0, 2, 3} {
+		tx, err := ms.SnapshotAtRevision(ctx, tree, rev)
+		if err != nil {
+			t.Fatalf("SnapshotAtRevision(%d): %v", rev, err)
+		}
+		if got, err := tx.ReadRevision(ctx); err != nil || got != rev {
+			t.Errorf("SnapshotAtRevision(%d): ReadRevision() = %d, %v, want %d", rev, got, err, rev)
+		}
+		got, err := tx.LatestSignedMapRoot(ctx)
+		if err != nil {
+			t.Fatalf("SnapshotAtRevision(%d): LatestSignedMapRoot(): %v", rev, err)
+		}
+		var gotMR types.MapRootV1
+		if err := gotMR.UnmarshalBinary(got.MapRoot); err != nil {
+			t.Fatalf("UnmarshalMapRootV1(): %v", err)
+		}
+		if !reflect.DeepEqual(&gotMR, roots[rev]) {
+			t.Errorf("SnapshotAtRevision(%d): LatestSignedMapRoot() \ngot: %#v \nwant:%#v", rev, gotMR, roots[rev])
+		}
+		if err := tx.Commit(ctx); err != nil {
+			t.Errorf("SnapshotAtRevision(%d): Commit() = %v, want no error", rev, err)
+		}
+	}
+
+	if _, err := ms.SnapshotAtRevision(ctx, tree, 10); status.Code(err) != codes.NotFound {
+		t.Errorf("SnapshotAtRevision(10): %v, want code %v", err, codes.NotFound)
+	}
+}
+
+func (*mapTests) TestListLeavesByKeyPrefix(ctx context.Context, t *testing.T, ms storage.MapStorage, as storage.AdminStorage) {
+	keyIndexMap := proto.Clone(storageto.MapTree).(*trillian.Tree)
+	keyIndexMap.MapKeyIndex = true
+	tree, err := storage.CreateTree(ctx, as, keyIndexMap)
+	if err != nil {
+		t.Skipf("CreateTree() with map_key_index: %v", err)
+	}
+	mustSignAndStoreMapRoot(ctx, t, ms, tree, &types.MapRootV1{Revision: 0})
+
+	leaf := func(key, value string) *trillian.MapLeaf {
+		index := sha256.Sum256([]byte(key))
+		leaf := &trillian.MapLeaf{Index: index[:], Key: []byte(key)}
+		if value != "" {
+			leafHash := sha256.Sum256([]byte(value))
+			leaf.LeafValue, leaf.LeafHash = []byte(value), leafHash[:]
+		}
+		return leaf
+	}
+	unkeyed := leaf("unkeyed", "u")
+	unkeyed.Key = nil
+	// Revision 1 writes a few keys, and revision 2 updates one of them and
+	// deletes another.
+	for i, leaves := range [][]*trillian.MapLeaf{
+		{leaf("a1", "1"), leaf("a2", "2"), leaf("a3", "3"), leaf("b1", "4"), unkeyed},
+		{leaf("a1", "5"), leaf("a2", "")},
+	} {
+		rev := i + 1
+		if err := ms.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+			for _, l := range leaves {
+				if err := tx.Set(ctx, l.Index, l); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("ReadWriteTransaction(%d): %v", rev, err)
+		}
+		mustSignAndStoreMapRoot(ctx, t, ms, tree, &types.MapRootV1{Revision: uint64(rev), TimestampNanos: uint64(rev)})
+	}
+
+	tx, err := ms.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	lister, ok := tx.(storage.MapLeafKeyLister)
+	if !ok {
+		t.Skipf("%T doesn't implement storage.MapLeafKeyLister", tx)
+	}
+
+	for _, tc := range []struct {
+		rev    int64
+		prefix string
+		after  *trillian.MapLeaf
+		limit  int
+		want   []*trillian.MapLeaf
+	}{
+		{rev: 0, prefix: "", limit: 10},
+		{rev: 1, prefix: "a", limit: 10, want: []*trillian.MapLeaf{leaf("a1", "1"), leaf("a2", "2"), leaf("a3", "3")}},
+		{rev: 1, prefix: "a", limit: 2, want: []*trillian.MapLeaf{leaf("a1", "1"), leaf("a2", "2")}},
+		{rev: 1, prefix: "a", after: leaf("a2", "2"), limit: 2, want: []*trillian.MapLeaf{leaf("a3", "3")}},
+		{rev: 1, prefix: "a3", limit: 10, want: []*trillian.MapLeaf{leaf("a3", "3")}},
+		{rev: 2, prefix: "", limit: 10, want: []*trillian.MapLeaf{leaf("a1", "5"), leaf("a3", "3"), leaf("b1", "4")}},
+		{rev: 2, prefix: "", after: leaf("a1", "5"), limit: 1, want: []*trillian.MapLeaf{leaf("a3", "3")}},
+		{rev: 2, prefix: "c", limit: 10},
+	} {
+		var afterKey, afterIndex []byte
+		if tc.after != nil {
+			afterKey, afterIndex = tc.after.Key, tc.after.Index
+		}
+		got, err := lister.ListLeavesByKeyPrefix(ctx, tc.rev, []byte(tc.prefix), afterKey, afterIndex, tc.limit)
+		if err != nil {
+			t.Fatalf("ListLeavesByKeyPrefix(%d, %q): %v", tc.rev, tc.prefix, err)
+		}
+		if diff := cmp.Diff(got, tc.want, protocmp.Transform(), cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("ListLeavesByKeyPrefix(%d, %q, after %q, %d) diff (-got +want):\n%s", tc.rev, tc.prefix, afterKey, tc.limit, diff)
+		}
+	}
+}
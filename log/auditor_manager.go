@@ -0,0 +1,77 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/trees"
+)
+
+// AuditorManager provides append-only audits for a collection of Logs. It
+// keeps the compact range of the latest audited root of each Log, and checks
+// that every new root extends it append-only, from the leaves in storage, as
+// a continuous self-audit against the Log forking or being rewritten. See
+// Sequencer.auditAppendOnly.
+//
+// The audited state is kept in memory, so audits start over from the latest
+// root of each Log when the process restarts.
+type AuditorManager struct {
+	registry extension.Registry
+
+	mu      sync.Mutex
+	audited map[int64]*auditedLog
+}
+
+// NewAuditorManager creates a new AuditorManager instance.
+func NewAuditorManager(registry extension.Registry) *AuditorManager {
+	return &AuditorManager{
+		registry: registry,
+		audited:  make(map[int64]*auditedLog),
+	}
+}
+
+// ExecutePass audits the latest root of the specified Log against the
+// previously audited one, and returns the number of leaves audited.
+func (a *AuditorManager) ExecutePass(ctx context.Context, logID int64, info *OperationInfo) (int, error) {
+	tree, err := trees.GetTree(ctx, a.registry.AdminStorage, logID, verifyOpts)
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving log %v: %v", logID, err)
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return 0, fmt.Errorf("error getting hasher for log %v: %v", logID, err)
+	}
+
+	// Nothing is signed, so no signer is needed.
+	sequencer := NewSequencer(hasher, info.TimeSource, a.registry.LogStorage, nil, a.registry.MetricFactory, a.registry.QuotaManager)
+	a.mu.Lock()
+	prev := a.audited[logID]
+	a.mu.Unlock()
+	next, leaves, err := sequencer.auditAppendOnly(ctx, tree, prev)
+	a.mu.Lock()
+	a.audited[logID] = next
+	a.mu.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to audit %v: %v", logID, err)
+	}
+	return leaves, nil
+}
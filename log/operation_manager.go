@@ -18,6 +18,7 @@ package log
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
@@ -31,12 +32,20 @@ import (
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/util/clock"
 	"github.com/google/trillian/util/election"
+	"github.com/google/trillian/util/election2"
+	"github.com/google/trillian/util/mergedelay"
+	"github.com/google/trillian/util/priority"
+	"github.com/google/trillian/util/requestid"
+	"github.com/google/trillian/util/rootage"
 	"golang.org/x/sync/semaphore"
 )
 
 var (
 	// DefaultTimeout is the default timeout on a single log operation run.
 	DefaultTimeout = 60 * time.Second
+	// DefaultShardReplicas is the default number of instances which own each
+	// log when mastership is sharded, see OperationInfo.Membership.
+	DefaultShardReplicas = 2
 
 	once              sync.Once
 	knownLogs         monitoring.Gauge
@@ -46,6 +55,13 @@ var (
 	failedSigningRuns monitoring.Counter
 	entriesAdded      monitoring.Counter
 	batchesAdded      monitoring.Counter
+	rootAge           monitoring.Gauge
+	rootOverdue       monitoring.Gauge
+	mmdMaxDelay       monitoring.Gauge
+	mmdViolations     monitoring.Counter
+	mmdAtRisk         monitoring.Gauge
+	schedulingDelay   monitoring.Histogram
+	starvedPromotions monitoring.Counter
 )
 
 func createMetrics(mf monitoring.MetricFactory) {
@@ -68,6 +84,21 @@ func createMetrics(mf monitoring.MetricFactory) {
 	// entriesAdded / batchesAdded is average batch size. These can be used for
 	// tuning sequencing or evaluating performance.
 	batchesAdded = mf.NewCounter("batches_added", "Number of times a non zero number of entries was added", logIDLabel)
+	// rootAge is the age of the latest root of each log that this instance is
+	// master for, as seen by the signer. It keeps growing while signing runs
+	// fail or hang, and may disagree with the age seen by the log servers,
+	// e.g. if the signer can't write to storage.
+	rootAge = mf.NewGauge("sequencer_root_age_seconds", "Age of the latest signed root of logs this instance is master for, in seconds", logIDLabel)
+	rootOverdue = mf.NewGauge("sequencer_root_overdue", "Set to 1 for logs whose latest root is older than their max root duration allows (0/1)", logIDLabel)
+	// The MMD metrics are only exported for logs which have a maximum merge
+	// delay, see OperationInfo.MergeDelays.
+	mmdMaxDelay = mf.NewGauge("sequencer_mmd_max_delay_seconds", "Largest merge delay of the leaves in the latest batch of logs with an MMD, in seconds", logIDLabel)
+	mmdViolations = mf.NewCounter("sequencer_mmd_violations", "Number of leaves integrated later than the MMD of their log allows", logIDLabel)
+	mmdAtRisk = mf.NewGauge("sequencer_mmd_at_risk", "Set to 1 for logs at risk of violating their MMD (0/1)", logIDLabel)
+	// The scheduling metrics are labelled by priority class rather than log,
+	// see OperationInfo.Priorities.
+	schedulingDelay = mf.NewHistogram("sequencer_scheduling_delay_seconds", "Delay between the start of a pass and the start of the operation on each log in it, in seconds", "class")
+	starvedPromotions = mf.NewCounter("sequencer_starved_promotions", "Number of times a log was scheduled ahead of its priority class after not being operated on for too many passes", "class")
 }
 
 // Operation defines a task that operates on a log. Examples are scheduling, signing,
@@ -78,6 +109,14 @@ type Operation interface {
 	ExecutePass(ctx context.Context, logID int64, info *OperationInfo) (int, error)
 }
 
+// Preparer is implemented by Operations which can prepare for operating on a
+// log, e.g. by pre-fetching data which the first pass reads, so that the first
+// pass after this instance becomes master for the log is no slower than others.
+type Preparer interface {
+	// Prepare prepares for passes over a single log.
+	Prepare(ctx context.Context, logID int64, info *OperationInfo) error
+}
+
 // OperationInfo bundles up information needed for running a set of Operations.
 type OperationInfo struct {
 	// Registry provides access to Trillian storage.
@@ -89,12 +128,32 @@ type OperationInfo struct {
 	BatchSize int
 	// TimeSource should be used by the Operation to allow mocking for tests.
 	TimeSource clock.TimeSource
+	// QuarantineAfterFailures is the number of consecutive passes for a log
+	// which fail to integrate the leaves of their batch, after which
+	// sequencing falls back to single-leaf batches, in order to isolate leaves
+	// that can't be integrated. A leaf which keeps failing for as many passes
+	// again is moved to the dead-letter store. Failures of storage or signing
+	// don't count. Zero disables this.
+	QuarantineAfterFailures int
 
 	// The following parameters govern the overall scheduling of Operations
 	// by a OperationManager.
 
 	// Election-related configuration. Copied for each log.
 	ElectionConfig election.RunnerConfig
+	// Membership, if set, shards mastership of logs between the instances it
+	// lists by rendezvous hashing: this instance only runs elections for the
+	// logs which it is one of the ShardReplicas owners of, and resigns from
+	// the others. The elections decide between the owners of each log, so
+	// that a log is taken over by its next owner if its master stops before
+	// it's removed from the membership. If the members can't be listed, or
+	// don't include InstanceID, elections are run for all logs.
+	Membership election2.Membership
+	// InstanceID is the ID of this instance in Membership.
+	InstanceID string
+	// ShardReplicas is the number of instances which own each log when
+	// Membership is set. If unset, it defaults to DefaultShardReplicas.
+	ShardReplicas int
 
 	// RunInterval is the time between starting batches of processing.  If a
 	// batch takes longer than this interval to complete, the next batch
@@ -105,6 +164,41 @@ type OperationInfo struct {
 	// Timeout sets an optional timeout on each operation run.
 	// If unset, default to the value of DefaultTimeout.
 	Timeout time.Duration
+	// RootAgeInterval is how often the ages of the latest roots of the logs
+	// this instance is master for are exported, and checked against the logs'
+	// max root durations. Zero disables this.
+	RootAgeInterval time.Duration
+	// MergeDelays sets the maximum merge delays (MMDs) of logs, which the
+	// merge delays of the leaves integrated are checked against.
+	MergeDelays mergedelay.Policy
+	// Replicator, if set, is notified of each log which grows in a pass.
+	Replicator Replicator
+	// PrepareOnMastership says that Operations which implement Preparer are
+	// asked to prepare for each log that this instance becomes master for,
+	// before the first pass over it.
+	PrepareOnMastership bool
+	// Priorities sets the priority classes of logs, which decide the order
+	// that the logs are operated on in each pass. This matters when there are
+	// more logs than workers. The zero value puts all logs in the best-effort
+	// class, so they are operated on in the order they are listed.
+	Priorities priority.Policy
+
+	// rootAges tracks the latest roots of the logs this instance is master for.
+	// It is set up by NewOperationManager.
+	rootAges *rootage.Tracker
+	// mergeDelays tracks the merge delays of the logs this instance is master
+	// for. It is set up by NewOperationManager.
+	mergeDelays *mergedelay.Tracker
+	// scheduler orders the logs of each pass by priority. It is set up by
+	// NewOperationManager.
+	scheduler *priority.Scheduler
+}
+
+// Replicator copies logs elsewhere as they grow.
+type Replicator interface {
+	// Notify tells the Replicator that new leaves were integrated into the
+	// log with the given ID. It must not block.
+	Notify(logID int64)
 }
 
 // OperationManager controls scheduling activities for logs.
@@ -129,6 +223,12 @@ type OperationManager struct {
 	lastHeld []int64
 	// idsMutex guards logNames and lastHeld fields.
 	idsMutex sync.Mutex
+
+	// lastPass is when the operation loop was started, or last completed a
+	// pass successfully. A zero value means that the loop isn't running.
+	lastPass time.Time
+	// passMutex guards lastPass.
+	passMutex sync.Mutex
 }
 
 // NewOperationManager creates a new OperationManager instance.
@@ -139,6 +239,14 @@ func NewOperationManager(info OperationInfo, logOperation Operation) *OperationM
 	if info.Timeout == 0 {
 		info.Timeout = DefaultTimeout
 	}
+	if info.ShardReplicas == 0 {
+		info.ShardReplicas = DefaultShardReplicas
+	}
+	// A root can't be expected before the first pass that runs after the max
+	// root duration has passed has completed.
+	info.rootAges = rootage.NewTracker(rootAge, rootOverdue, info.RunInterval+info.Timeout, info.TimeSource)
+	info.mergeDelays = mergedelay.NewTracker(info.MergeDelays, mmdMaxDelay, mmdViolations, mmdAtRisk)
+	info.scheduler = priority.NewScheduler(info.Priorities, schedulingDelay, starvedPromotions)
 	tracker := election.NewMasterTracker(nil, func(id string, v bool) {
 		val := 0.0
 		if v {
@@ -156,6 +264,16 @@ func NewOperationManager(info OperationInfo, logOperation Operation) *OperationM
 	}
 }
 
+// HeldLogIDs returns the IDs of the active logs that this instance was master
+// for in the latest pass.
+func (o *OperationManager) HeldLogIDs() []int64 {
+	o.idsMutex.Lock()
+	defer o.idsMutex.Unlock()
+	ids := make([]int64, len(o.lastHeld))
+	copy(ids, o.lastHeld)
+	return ids
+}
+
 // getActiveLogIDs returns IDs of logs eligible for sequencing.
 func (o *OperationManager) getActiveLogIDs(ctx context.Context) ([]int64, error) {
 	tx, err := o.info.Registry.LogStorage.Snapshot(ctx)
@@ -223,14 +341,31 @@ func (o *OperationManager) masterFor(ctx context.Context, allIDs []int64) ([]int
 		allStringIDs = append(allStringIDs, s)
 	}
 
+	owned := o.ownedIDs(ctx, allStringIDs)
+
 	// Synchronize the set of log IDs with those we are tracking mastership for.
 	for _, logID := range allStringIDs {
 		knownLogs.Set(1, logID)
+		if owned != nil && !owned[logID] {
+			continue
+		}
 		if o.runnerCancels[logID] == nil {
 			o.tracker.Set(logID, false) // Initialise tracking for this ID.
 			o.runnerCancels[logID] = o.runElectionWithRestarts(ctx, logID)
 		}
 	}
+	// Stop the elections of logs which are owned by other instances, which
+	// resigns mastership of them.
+	if owned != nil {
+		for logID, cancel := range o.runnerCancels {
+			if !owned[logID] {
+				glog.Infof("%v: owned by other instances, leaving election", logID)
+				cancel()
+				delete(o.runnerCancels, logID)
+				o.tracker.Set(logID, false)
+			}
+		}
+	}
 
 	held := o.tracker.Held()
 	heldIDs := make([]int64, 0, len(allIDs))
@@ -240,6 +375,10 @@ func (o *OperationManager) masterFor(ctx context.Context, allIDs []int64) ([]int
 		if i := sort.SearchStrings(allStringIDs, s); i >= len(allStringIDs) || allStringIDs[i] != s {
 			continue
 		}
+		// Skip the log if its election is still being left.
+		if owned != nil && !owned[s] {
+			continue
+		}
 		id, err := strconv.ParseInt(s, 10, 64)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse logID %v as int64", s)
@@ -250,6 +389,37 @@ func (o *OperationManager) masterFor(ctx context.Context, allIDs []int64) ([]int
 	return heldIDs, nil
 }
 
+// ownedIDs returns the set of logs among logIDs which this instance is one of
+// the owners of, if mastership is sharded, or nil if elections are run for all
+// logs.
+func (o *OperationManager) ownedIDs(ctx context.Context, logIDs []string) map[string]bool {
+	if o.info.Membership == nil {
+		return nil
+	}
+	members, err := o.info.Membership.Members(ctx)
+	if err != nil {
+		glog.Warningf("Failed to list members, running elections for all logs: %v", err)
+		return nil
+	}
+	found := false
+	for _, id := range members {
+		found = found || id == o.info.InstanceID
+	}
+	if !found {
+		glog.Warningf("Instance %q is not a member, running elections for all logs", o.info.InstanceID)
+		return nil
+	}
+	owned := make(map[string]bool)
+	for _, logID := range logIDs {
+		for _, id := range election2.Owners(logID, members, o.info.ShardReplicas) {
+			if id == o.info.InstanceID {
+				owned[logID] = true
+			}
+		}
+	}
+	return owned
+}
+
 // runElectionWithRestarts runs the election/resignation loop for the given log
 // indefinitely, until the returned CancelFunc is invoked. Any failure during
 // the loop leads to a restart of the loop with a few seconds delay.
@@ -290,15 +460,29 @@ func (o *OperationManager) runElectionWithRestarts(ctx context.Context, logID st
 }
 
 // updateHeldIDs updates the process status with the number/list of logs that
-// the instance holds mastership for.
-func (o *OperationManager) updateHeldIDs(ctx context.Context, logIDs, activeIDs []int64) {
+// the instance holds mastership for. It returns the IDs of the logs which the
+// instance wasn't master for on the previous call.
+func (o *OperationManager) updateHeldIDs(ctx context.Context, logIDs, activeIDs []int64) []int64 {
 	heldInfo := o.heldInfo(ctx, logIDs)
 	msg := fmt.Sprintf("Acting as master for %d / %d active logs: %s", len(logIDs), len(activeIDs), heldInfo)
 	o.idsMutex.Lock()
 	defer o.idsMutex.Unlock()
+	var acquired []int64
 	if !reflect.DeepEqual(logIDs, o.lastHeld) {
+		held := make(map[int64]bool, len(o.lastHeld))
+		for _, id := range o.lastHeld {
+			held[id] = true
+		}
+		for _, id := range logIDs {
+			if !held[id] {
+				acquired = append(acquired, id)
+			}
+		}
 		o.lastHeld = make([]int64, len(logIDs))
 		copy(o.lastHeld, logIDs)
+		o.info.rootAges.Retain(logIDs)
+		o.info.mergeDelays.Retain(logIDs)
+		o.info.scheduler.Retain(logIDs)
 		glog.Info(msg)
 		if o.info.Registry.SetProcessStatus != nil {
 			o.info.Registry.SetProcessStatus(heldInfo)
@@ -306,6 +490,7 @@ func (o *OperationManager) updateHeldIDs(ctx context.Context, logIDs, activeIDs
 	} else {
 		glog.V(1).Info(msg)
 	}
+	return acquired
 }
 
 func (o *OperationManager) getLogsAndExecutePass(ctx context.Context) error {
@@ -323,12 +508,30 @@ func (o *OperationManager) getLogsAndExecutePass(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to determine log IDs we're master for: %v", err)
 	}
-	o.updateHeldIDs(ctx, logIDs, activeIDs)
+	acquired := o.updateHeldIDs(ctx, logIDs, activeIDs)
+	if p, ok := o.logOperation.(Preparer); ok && o.info.PrepareOnMastership && len(acquired) > 0 {
+		prepareAll(runCtx, &o.info, p, acquired)
+	}
 
-	executePassForAll(runCtx, &o.info, o.logOperation, logIDs)
+	executePassForAll(runCtx, &o.info, o.logOperation, logIDs, o.epochs(logIDs))
 	return nil
 }
 
+// epochs returns the signer epochs of the given logs, which are the fencing
+// tokens of this instance's mastership of them, if the elections provide any.
+func (o *OperationManager) epochs(logIDs []int64) map[int64]int64 {
+	if o.info.Registry.ElectionFactory == nil {
+		return nil
+	}
+	epochs := make(map[int64]int64)
+	for _, id := range logIDs {
+		if epoch := o.tracker.Epoch(strconv.FormatInt(id, 10)); epoch > 0 {
+			epochs[id] = epoch
+		}
+	}
+	return epochs
+}
+
 // OperationSingle performs a single pass of the manager.
 //
 // TODO(pavelkalinnikov): Deprecate this because it doesn't clean up any state,
@@ -343,6 +546,11 @@ func (o *OperationManager) OperationSingle(ctx context.Context) {
 // TODO(Martin2112): No mechanism for error reporting etc., this is OK for v1 but needs work
 func (o *OperationManager) OperationLoop(ctx context.Context) {
 	glog.Infof("Log operation manager starting")
+	o.setLastPass(o.info.TimeSource.Now())
+	defer o.setLastPass(time.Time{})
+	if o.info.RootAgeInterval > 0 {
+		go o.info.rootAges.Run(ctx, o.info.RootAgeInterval)
+	}
 
 	// Outer loop, runs until terminated.
 	for {
@@ -383,6 +591,8 @@ func (o *OperationManager) operateOnce(ctx context.Context) error {
 		if ctx.Err() != nil {
 			glog.Errorf("failed to execute operation on logs: %v", err)
 		}
+	} else {
+		o.setLastPass(o.info.TimeSource.Now())
 	}
 	glog.V(1).Infof("Log operation manager pass complete")
 
@@ -410,7 +620,7 @@ func (o *OperationManager) operateOnce(ctx context.Context) error {
 	wait := o.info.RunInterval - duration
 	if wait > 0 {
 		glog.V(1).Infof("Processing started at %v for %v; wait %v before next run", start, duration, wait)
-		if err := clock.SleepContext(ctx, wait); err != nil {
+		if err := clock.SleepSource(ctx, wait, o.info.TimeSource); err != nil {
 			return err
 		}
 	} else {
@@ -419,9 +629,37 @@ func (o *OperationManager) operateOnce(ctx context.Context) error {
 	return nil
 }
 
+func (o *OperationManager) setLastPass(t time.Time) {
+	o.passMutex.Lock()
+	defer o.passMutex.Unlock()
+	o.lastPass = t
+}
+
+// CheckProgress returns an error if the operation loop isn't running, or if
+// it hasn't completed a pass within twice the time that a pass is allowed to
+// take, i.e. RunInterval plus Timeout. Passes which fail to list the active
+// logs, or to determine which of them this instance is master for, don't count
+// as completed. Failures to operate on individual logs do.
+func (o *OperationManager) CheckProgress(ctx context.Context) error {
+	o.passMutex.Lock()
+	lastPass := o.lastPass
+	o.passMutex.Unlock()
+	if lastPass.IsZero() {
+		return errors.New("log operation manager is not running")
+	}
+	limit := 2 * (o.info.RunInterval + o.info.Timeout)
+	if since := o.info.TimeSource.Now().Sub(lastPass); since > limit {
+		return fmt.Errorf("no log operation pass completed for %v, want at most %v", since, limit)
+	}
+	return nil
+}
+
 // executePassForAll runs ExecutePass of the given operation for each of the
 // passed-in logs, allowing up to a configurable number of parallel operations.
-func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, logIDs []int64) {
+// Passes for logs with an entry in epochs write their roots with that signer
+// epoch, see storage.WithSignerEpoch. If the info has a scheduler, the logs
+// are started in the order it gives them.
+func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, logIDs []int64, epochs map[int64]int64) {
 	startBatch := info.TimeSource.Now()
 
 	numWorkers := info.NumWorkers
@@ -431,6 +669,9 @@ func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, l
 	}
 	glog.V(1).Infof("Running executor with %d worker(s)", numWorkers)
 
+	if info.scheduler != nil {
+		logIDs = info.scheduler.Order(logIDs)
+	}
 	sem := semaphore.NewWeighted(int64(numWorkers))
 	var wg sync.WaitGroup
 	for _, logID := range logIDs {
@@ -441,8 +682,16 @@ func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, l
 		go func(logID int64) {
 			defer wg.Done()
 			defer sem.Release(1)
+			if info.scheduler != nil {
+				info.scheduler.Started(logID, info.TimeSource.Now().Sub(startBatch))
+			}
+			// Give each pass its own ID, so that its log lines can be told apart.
+			ctx := requestid.NewContext(ctx, requestid.New())
+			if epoch, ok := epochs[logID]; ok {
+				ctx = storage.WithSignerEpoch(ctx, epoch)
+			}
 			if err := executePass(ctx, info, op, logID); err != nil {
-				glog.Errorf("ExecutePass(%v) failed: %v", logID, err)
+				glog.Errorf("%sExecutePass(%v) failed: %v", requestid.Prefix(ctx), logID, err)
 			}
 		}(logID)
 	}
@@ -453,6 +702,35 @@ func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, l
 	glog.V(1).Infof("Group run completed in %.2f seconds", d)
 }
 
+// prepareAll runs Prepare of the given Preparer for each of the passed-in logs,
+// allowing up to a configurable number of parallel preparations. Failures are
+// only logged, as the passes over the logs can go ahead without preparation.
+func prepareAll(ctx context.Context, info *OperationInfo, p Preparer, logIDs []int64) {
+	start := info.TimeSource.Now()
+	numWorkers := info.NumWorkers
+	if numWorkers <= 0 {
+		numWorkers = 1
+	}
+	sem := semaphore.NewWeighted(int64(numWorkers))
+	var wg sync.WaitGroup
+	for _, logID := range logIDs {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			break // Terminate because the context is canceled.
+		}
+		wg.Add(1)
+		go func(logID int64) {
+			defer wg.Done()
+			defer sem.Release(1)
+			ctx := requestid.NewContext(ctx, requestid.New())
+			if err := p.Prepare(ctx, logID, info); err != nil {
+				glog.Warningf("%sPrepare(%v) failed: %v", requestid.Prefix(ctx), logID, err)
+			}
+		}(logID)
+	}
+	wg.Wait()
+	glog.Infof("Prepared for %d newly held log(s) in %.2f seconds", len(logIDs), clock.SecondsSince(info.TimeSource, start))
+}
+
 // executePass runs ExecutePass of the given operation for the passed-in log.
 func executePass(ctx context.Context, info *OperationInfo, op Operation, logID int64) error {
 	label := strconv.FormatInt(logID, 10)
@@ -467,11 +745,14 @@ func executePass(ctx context.Context, info *OperationInfo, op Operation, logID i
 	signingRuns.Inc(label)
 	if count > 0 {
 		d := clock.SecondsSince(info.TimeSource, start)
-		glog.Infof("%v: processed %d items in %.2f seconds (%.2f qps)", logID, count, d, float64(count)/d)
+		glog.Infof("%s%v: processed %d items in %.2f seconds (%.2f qps)", requestid.Prefix(ctx), logID, count, d, float64(count)/d)
 		entriesAdded.Add(float64(count), label)
 		batchesAdded.Inc(label)
+		if info.Replicator != nil {
+			info.Replicator.Notify(logID)
+		}
 	} else {
-		glog.V(1).Infof("%v: no items to process", logID)
+		glog.V(1).Infof("%s%v: no items to process", requestid.Prefix(ctx), logID)
 	}
 	return nil
 }
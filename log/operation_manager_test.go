@@ -19,7 +19,9 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -33,6 +35,7 @@ import (
 	"github.com/google/trillian/util/election"
 	"github.com/google/trillian/util/election2"
 	eto "github.com/google/trillian/util/election2/testonly"
+	"github.com/google/trillian/util/priority"
 )
 
 const (
@@ -135,8 +138,9 @@ func (l logOpInfoMatcher) String() string {
 
 // Set up some log IDs in mock storage.
 // The following IDs have special behaviour:
-//  logIDThatFailsGetTreeOp: fail the GetTree() operation
-//  logIDWithNoDisplayName: return a tree with no DisplayName
+//
+//	logIDThatFailsGetTreeOp: fail the GetTree() operation
+//	logIDWithNoDisplayName: return a tree with no DisplayName
 func setupLogIDs(ctrl *gomock.Controller, logNames map[int64]string) (*storage.MockLogStorage, *storage.MockAdminStorage) {
 	ids := make([]int64, 0, len(logNames))
 	for id := range logNames {
@@ -196,6 +200,149 @@ func TestOperationManagerPassesIDs(t *testing.T) {
 	lom.OperationSingle(ctx)
 }
 
+func TestOperationManagerOrdersByPriority(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fakeStorage, mockAdmin := setupLogIDs(ctrl, map[int64]string{451: "LogID1", 145: "LogID2", 154: "LogID3"})
+	registry := extension.Registry{
+		LogStorage:   fakeStorage,
+		AdminStorage: mockAdmin,
+	}
+
+	// With a single worker, the logs are operated on one at a time, in order.
+	mockLogOp := NewMockOperation(ctrl)
+	gomock.InOrder(
+		mockLogOp.EXPECT().ExecutePass(gomock.Any(), int64(145), gomock.Any()).Return(0, nil),
+		mockLogOp.EXPECT().ExecutePass(gomock.Any(), int64(451), gomock.Any()).Return(0, nil),
+		mockLogOp.EXPECT().ExecutePass(gomock.Any(), int64(154), gomock.Any()).Return(0, nil),
+	)
+
+	info := defaultOperationInfo(registry)
+	info.Priorities = priority.Policy{
+		Default: priority.Normal,
+		PerTree: map[int64]priority.Class{145: priority.High, 154: priority.BestEffort},
+	}
+	lom := NewOperationManager(info, mockLogOp)
+	lom.OperationSingle(ctx)
+}
+
+// preparingOperation is an Operation which records the logs prepared for.
+type preparingOperation struct {
+	Operation
+	mu       sync.Mutex
+	prepared []int64
+}
+
+func (p *preparingOperation) Prepare(ctx context.Context, logID int64, info *OperationInfo) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prepared = append(p.prepared, logID)
+	return nil
+}
+
+func TestOperationManagerPreparesNewlyHeldLogs(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fakeStorage, mockAdmin := setupLogIDs(ctrl, map[int64]string{451: "LogID1", 145: "LogID2"})
+	registry := extension.Registry{
+		LogStorage:   fakeStorage,
+		AdminStorage: mockAdmin,
+	}
+
+	mockLogOp := NewMockOperation(ctrl)
+	mockLogOp.EXPECT().ExecutePass(gomock.Any(), gomock.Any(), gomock.Any()).Return(0, nil).Times(4)
+	op := &preparingOperation{Operation: mockLogOp}
+
+	info := defaultOperationInfo(registry)
+	info.PrepareOnMastership = true
+	lom := NewOperationManager(info, op)
+	// Only the first pass finds logs which weren't held before.
+	lom.OperationSingle(ctx)
+	lom.OperationSingle(ctx)
+
+	sort.Slice(op.prepared, func(i, j int) bool { return op.prepared[i] < op.prepared[j] })
+	if got, want := op.prepared, []int64{145, 451}; !reflect.DeepEqual(got, want) {
+		t.Errorf("prepared for logs %v, want %v", got, want)
+	}
+}
+
+// recordingReplicator is a Replicator which records the logs notified.
+type recordingReplicator struct {
+	mu       sync.Mutex
+	notified []int64
+}
+
+func (r *recordingReplicator) Notify(logID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notified = append(r.notified, logID)
+}
+
+func TestOperationManagerNotifiesReplicator(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fakeStorage, mockAdmin := setupLogIDs(ctrl, map[int64]string{451: "LogID1", 145: "LogID2", 154: "LogID3"})
+	registry := extension.Registry{
+		LogStorage:   fakeStorage,
+		AdminStorage: mockAdmin,
+	}
+
+	mockLogOp := NewMockOperation(ctrl)
+	mockLogOp.EXPECT().ExecutePass(gomock.Any(), int64(451), gomock.Any()).Return(3, nil)
+	mockLogOp.EXPECT().ExecutePass(gomock.Any(), int64(145), gomock.Any()).Return(0, nil)
+	mockLogOp.EXPECT().ExecutePass(gomock.Any(), int64(154), gomock.Any()).Return(0, errors.New("test error"))
+
+	r := &recordingReplicator{}
+	info := defaultOperationInfo(registry)
+	info.Replicator = r
+	lom := NewOperationManager(info, mockLogOp)
+	lom.OperationSingle(ctx)
+
+	if got, want := r.notified, []int64{451}; !reflect.DeepEqual(got, want) {
+		t.Errorf("notified logs %v, want %v", got, want)
+	}
+}
+
+func TestOperationManagerPassesEpochs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fakeStorage, mockAdmin := setupLogIDs(ctrl, map[int64]string{2: "LogID2", 3: "LogID3"})
+	registry := extension.Registry{
+		LogStorage:      fakeStorage,
+		AdminStorage:    mockAdmin,
+		ElectionFactory: fencedFactory{},
+	}
+
+	var passes int32
+	mockLogOp := NewMockOperation(ctrl)
+	mockLogOp.EXPECT().ExecutePass(gomock.Any(), int64(2), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, logID int64, _ *OperationInfo) (int, error) {
+			atomic.AddInt32(&passes, 1)
+			if epoch, ok := storage.SignerEpochFromContext(ctx); !ok || epoch != 200 {
+				t.Errorf("ExecutePass(%d): epoch=%d,%v, want 200,true", logID, epoch, ok)
+			}
+			return 0, nil
+		})
+
+	lom := NewOperationManager(defaultOperationInfo(registry), mockLogOp)
+	// The first pass starts the elections, give them a chance to report.
+	lom.OperationSingle(ctx)
+	time.Sleep(100 * time.Millisecond)
+	lom.OperationSingle(ctx)
+	if atomic.LoadInt32(&passes) == 0 {
+		t.Error("ExecutePass() not called for the log held")
+	}
+}
+
 func TestOperationManagerExecutePassError(t *testing.T) {
 	ctx := context.Background()
 	logID1 := int64(451)
@@ -394,6 +541,53 @@ func TestOperationManagerOperationLoopExecutePassError(t *testing.T) {
 	}
 }
 
+func TestOperationManagerCheckProgress(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fakeStorage, mockAdmin := setupLogIDs(ctrl, map[int64]string{451: "LogID1"})
+	registry := extension.Registry{
+		LogStorage:   fakeStorage,
+		AdminStorage: mockAdmin,
+	}
+	fakeTime := clock.NewFake(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	info := defaultOperationInfo(registry)
+	info.TimeSource = fakeTime
+	info.Timeout = 10 * time.Second
+
+	mockLogOp := NewMockOperation(ctrl)
+	// The pass takes a minute, and shuts the loop down.
+	mockLogOp.EXPECT().ExecutePass(gomock.Any(), int64(451), gomock.Any()).Do(func(_ context.Context, _ int64, _ *OperationInfo) {
+		fakeTime.Set(fakeTime.Now().Add(time.Minute))
+		cancel()
+	}).Return(1, nil)
+
+	lom := NewOperationManager(info, mockLogOp)
+	if err := lom.CheckProgress(ctx); err == nil {
+		t.Error("CheckProgress() succeeded before OperationLoop started, want error")
+	}
+
+	lom.setLastPass(fakeTime.Now())
+	if err := lom.CheckProgress(ctx); err != nil {
+		t.Errorf("CheckProgress()=%v when the loop has just started, want nil", err)
+	}
+	fakeTime.Set(fakeTime.Now().Add(30 * time.Second))
+	if err := lom.CheckProgress(ctx); err == nil {
+		t.Error("CheckProgress() succeeded with no pass for 30s, want error")
+	}
+
+	// A completed pass counts as progress, even though it took a long time.
+	if err := lom.operateOnce(ctx); err == nil {
+		t.Fatal("operateOnce() succeeded after the loop was shut down, want error")
+	}
+	if err := lom.CheckProgress(ctx); err != nil {
+		t.Errorf("CheckProgress()=%v after a completed pass, want nil", err)
+	}
+}
+
 func TestHeldInfo(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -473,6 +667,93 @@ func TestMasterFor(t *testing.T) {
 	}
 }
 
+func TestMasterForSharded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	allIDs := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	members := &fakeMembership{members: []string{"a", "b", "c"}}
+	info := OperationInfo{
+		Registry:      extension.Registry{ElectionFactory: alwaysMasterFactory{}},
+		TimeSource:    clock.System,
+		Membership:    members,
+		InstanceID:    "a",
+		ShardReplicas: 1,
+	}
+	lom := NewOperationManager(info, nil)
+
+	// masterFor checks mastership twice, to give the election threads a
+	// chance to get started and report.
+	masterFor := func() []int64 {
+		t.Helper()
+		lom.masterFor(ctx, allIDs)
+		time.Sleep(100 * time.Millisecond)
+		logIDs, err := lom.masterFor(ctx, allIDs)
+		if err != nil {
+			t.Fatalf("masterFor(): %v", err)
+		}
+		sort.Slice(logIDs, func(i, j int) bool { return logIDs[i] < logIDs[j] })
+		return logIDs
+	}
+	owned := func(members ...string) []int64 {
+		ids := []int64{}
+		for _, id := range allIDs {
+			if election2.Owners(strconv.FormatInt(id, 10), members, 1)[0] == "a" {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+
+	want := owned("a", "b", "c")
+	if len(want) == 0 || len(want) == len(allIDs) {
+		t.Fatalf("Instance a owns %v, want some but not all logs", want)
+	}
+	if got := masterFor(); !reflect.DeepEqual(got, want) {
+		t.Errorf("masterFor()=%v, want %v", got, want)
+	}
+	if got, want := len(lom.runnerCancels), len(want); got != want {
+		t.Errorf("masterFor() runs %d elections, want %d", got, want)
+	}
+
+	// Logs of instances which leave are taken over.
+	members.set("a", "b")
+	if got, want := masterFor(), owned("a", "b"); !reflect.DeepEqual(got, want) {
+		t.Errorf("masterFor()=%v, want %v", got, want)
+	}
+	// Logs of instances which join are given up.
+	members.set("a", "b", "c")
+	if got := masterFor(); !reflect.DeepEqual(got, want) {
+		t.Errorf("masterFor()=%v, want %v", got, want)
+	}
+	// Elections are run for all logs if the members can't be listed.
+	members.set()
+	if got := masterFor(); !reflect.DeepEqual(got, allIDs) {
+		t.Errorf("masterFor()=%v, want %v", got, allIDs)
+	}
+}
+
+// fakeMembership is an election2.Membership whose members can be changed, and
+// which fails to list them if there are none.
+type fakeMembership struct {
+	mu      sync.Mutex
+	members []string
+}
+
+func (m *fakeMembership) set(members ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.members = members
+}
+
+func (m *fakeMembership) Members(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.members) == 0 {
+		return nil, errors.New("no members")
+	}
+	return m.members, nil
+}
+
 type alwaysMasterFactory struct{}
 
 func (m alwaysMasterFactory) NewElection(ctx context.Context, treeID string) (election2.Election, error) {
@@ -493,6 +774,28 @@ func (m masterForEvenFactory) NewElection(ctx context.Context, treeID string) (e
 	return d, nil
 }
 
+// fencedFactory creates elections which are only won for even IDs, and which
+// hand out a fencing token of 100 times the ID.
+type fencedFactory struct{}
+
+func (f fencedFactory) NewElection(ctx context.Context, treeID string) (election2.Election, error) {
+	e, err := masterForEvenFactory{}.NewElection(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	id, _ := strconv.ParseInt(treeID, 10, 64)
+	return fencedElection{Election: e, token: id * 100}, nil
+}
+
+type fencedElection struct {
+	election2.Election
+	token int64
+}
+
+func (e fencedElection) FencingToken() int64 {
+	return e.token
+}
+
 type failureFactory struct{}
 
 func (ff failureFactory) NewElection(ctx context.Context, treeID string) (election2.Election, error) {
@@ -17,6 +17,7 @@ package log
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
@@ -33,6 +34,11 @@ import (
 	"github.com/google/trillian/storage/tree"
 	"github.com/google/trillian/types"
 	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/mergedelay"
+	"github.com/google/trillian/util/requestid"
+	"github.com/google/trillian/util/rootage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	tcrypto "github.com/google/trillian/crypto"
 )
@@ -54,6 +60,11 @@ var (
 	seqCounter             monitoring.Counter
 	seqMergeDelay          monitoring.Histogram
 	seqTimestamp           monitoring.Gauge
+	seqQuarantined         monitoring.Counter
+	seqVerified            monitoring.Counter
+	seqVerifyMismatches    monitoring.Counter
+	seqAuditedSize         monitoring.Gauge
+	seqAuditViolations     monitoring.Counter
 
 	// QuotaIncreaseFactor is the multiplier used for the number of tokens added back to
 	// sequencing-based quotas. The resulting PutTokens call is equivalent to
@@ -93,6 +104,11 @@ func createSequencerMetrics(mf monitoring.MetricFactory) {
 	seqStoreRootLatency = mf.NewHistogram("sequencer_latency_store_root", "Latency of store-root part of sequencer batch operation in seconds", logIDLabel)
 	seqCounter = mf.NewCounter("sequencer_sequenced", "Number of leaves sequenced", logIDLabel)
 	seqMergeDelay = mf.NewHistogram("sequencer_merge_delay", "Delay between queuing and integration of leaves", logIDLabel)
+	seqQuarantined = mf.NewCounter("sequencer_quarantined", "Number of queued leaves moved to the dead-letter store", logIDLabel)
+	seqVerified = mf.NewCounter("sequencer_verified", "Number of sequenced leaves re-verified against stored roots", logIDLabel)
+	seqVerifyMismatches = mf.NewCounter("sequencer_verify_mismatches", "Number of mismatches between re-verified leaves and stored tree data", logIDLabel)
+	seqAuditedSize = mf.NewGauge("sequencer_audited_tree_size", "Tree size of the latest root audited as an append-only extension of the previous one", logIDLabel)
+	seqAuditViolations = mf.NewCounter("sequencer_append_only_violations", "Number of roots found not to be append-only extensions of the previous audited root", logIDLabel)
 }
 
 // Sequencer instances are responsible for integrating new leaves into a single log.
@@ -105,6 +121,11 @@ type Sequencer struct {
 	logStorage storage.LogStorage
 	signer     *tcrypto.Signer
 	qm         quota.Manager
+	// rootAges, if set, is told about the latest roots of the logs sequenced.
+	rootAges *rootage.Tracker
+	// mergeDelays, if set, is told about the merge delays of the leaves
+	// integrated.
+	mergeDelays *mergedelay.Tracker
 }
 
 // maxTreeDepth sets an upper limit on the size of Log trees.
@@ -136,12 +157,33 @@ func NewSequencer(
 // initCompactRangeFromStorage builds a compact range that matches the latest
 // data in the database. Ensures that the root hash matches the passed in root.
 func (s Sequencer) initCompactRangeFromStorage(ctx context.Context, root *types.LogRootV1, tx storage.TreeTX) (*compact.Range, error) {
-	fact := compact.RangeFactory{Hash: s.hasher.HashChildren}
 	if root.TreeSize == 0 {
+		return s.readCompactRange(ctx, 0, 0, tx)
+	}
+	cr, err := s.readCompactRange(ctx, root.TreeSize, int64(root.Revision), tx)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := cr.GetRootHash(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the root hash: %v", err)
+	}
+	// Note: Tree size != 0 at this point, so we don't consider the empty hash.
+	if want := root.RootHash; !bytes.Equal(hash, want) {
+		return nil, fmt.Errorf("root hash mismatch: got %x, want %x", hash, want)
+	}
+	return cr, nil
+}
+
+// readCompactRange builds the compact range [0, size) from the tree nodes
+// stored at the given revision.
+func (s Sequencer) readCompactRange(ctx context.Context, size uint64, revision int64, nr storage.NodeReader) (*compact.Range, error) {
+	fact := compact.RangeFactory{Hash: s.hasher.HashChildren}
+	if size == 0 {
 		return fact.NewEmptyRange(0), nil
 	}
 
-	ids := compact.RangeNodes(0, root.TreeSize)
+	ids := compact.RangeNodes(0, size)
 	storIDs := make([]tree.NodeID, len(ids))
 	for i, id := range ids {
 		nodeID, err := tree.NewNodeIDForTreeCoords(int64(id.Level), int64(id.Index), maxTreeDepth)
@@ -151,12 +193,12 @@ func (s Sequencer) initCompactRangeFromStorage(ctx context.Context, root *types.
 		storIDs[i] = nodeID
 	}
 
-	nodes, err := tx.GetMerkleNodes(ctx, int64(root.Revision), storIDs)
+	nodes, err := nr.GetMerkleNodes(ctx, revision, storIDs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Merkle nodes: %v", err)
 	}
 	if got, want := len(nodes), len(storIDs); got != want {
-		return nil, fmt.Errorf("failed to get %d nodes at rev %d, got %d", want, root.Revision, got)
+		return nil, fmt.Errorf("failed to get %d nodes at rev %d, got %d", want, revision, got)
 	}
 	for i, id := range storIDs {
 		if !nodes[i].NodeID.Equivalent(id) {
@@ -169,18 +211,10 @@ func (s Sequencer) initCompactRangeFromStorage(ctx context.Context, root *types.
 		hashes[i] = node.Hash
 	}
 
-	cr, err := fact.NewRange(0, root.TreeSize, hashes)
+	cr, err := fact.NewRange(0, size, hashes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create compact.Range: %v", err)
 	}
-	hash, err := cr.GetRootHash(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute the root hash: %v", err)
-	}
-	// Note: Tree size != 0 at this point, so we don't consider the empty hash.
-	if want := root.RootHash; !bytes.Equal(hash, want) {
-		return nil, fmt.Errorf("root hash mismatch: got %x, want %x", hash, want)
-	}
 	return cr, nil
 }
 
@@ -196,16 +230,19 @@ func (s Sequencer) buildNodesFromNodeMap(nodeMap map[compact.NodeID][]byte, newV
 	return nodes, nil
 }
 
-func (s Sequencer) prepareLeaves(leaves []*trillian.LogLeaf, begin uint64, label string) error {
+// prepareLeaves sets the integration timestamps of the leaves, and returns
+// the merge delays of those which have a queue timestamp.
+func (s Sequencer) prepareLeaves(leaves []*trillian.LogLeaf, begin uint64, label string) ([]time.Duration, error) {
 	now := s.timeSource.Now()
 	integrateAt, err := ptypes.TimestampProto(now)
 	if err != nil {
-		return fmt.Errorf("got invalid integrate timestamp: %v", err)
+		return nil, fmt.Errorf("got invalid integrate timestamp: %v", err)
 	}
+	delays := make([]time.Duration, 0, len(leaves))
 	for i, leaf := range leaves {
 		// The leaf should already have the correct index before it's integrated.
 		if got, want := leaf.LeafIndex, begin+uint64(i); got < 0 || got != int64(want) {
-			return fmt.Errorf("got invalid leaf index: %v, want: %v", got, want)
+			return nil, fmt.Errorf("got invalid leaf index: %v, want: %v", got, want)
 		}
 		leaf.IntegrateTimestamp = integrateAt
 
@@ -214,13 +251,14 @@ func (s Sequencer) prepareLeaves(leaves []*trillian.LogLeaf, begin uint64, label
 		if leaf.QueueTimestamp != nil && leaf.QueueTimestamp.Seconds != 0 {
 			queueTS, err := ptypes.Timestamp(leaf.QueueTimestamp)
 			if err != nil {
-				return fmt.Errorf("got invalid queue timestamp: %v", queueTS)
+				return nil, fmt.Errorf("got invalid queue timestamp: %v", queueTS)
 			}
 			mergeDelay := now.Sub(queueTS)
 			seqMergeDelay.Observe(mergeDelay.Seconds(), label)
+			delays = append(delays, mergeDelay)
 		}
 	}
-	return nil
+	return delays, nil
 }
 
 // updateCompactRange adds the passed in leaves to the compact range. Returns a
@@ -281,7 +319,7 @@ func (s *logSequencingTask) fetch(ctx context.Context, limit int, cutoff time.Ti
 	if err != nil {
 		return nil, fmt.Errorf("%v: Sequencer failed to dequeue leaves: %v", s.label, err)
 	}
-	seqDequeueLatency.Observe(clock.SecondsSince(s.timeSource, start), s.label)
+	monitoring.ObserveContext(ctx, seqDequeueLatency, clock.SecondsSince(s.timeSource, start), s.label)
 
 	// Assign leaf sequence numbers.
 	for i, leaf := range leaves {
@@ -299,7 +337,7 @@ func (s *logSequencingTask) update(ctx context.Context, leaves []*trillian.LogLe
 	if err := s.tx.UpdateSequencedLeaves(ctx, leaves); err != nil {
 		return fmt.Errorf("%v: Sequencer failed to update sequenced leaves: %v", s.label, err)
 	}
-	seqUpdateLeavesLatency.Observe(clock.SecondsSince(s.timeSource, start), s.label)
+	monitoring.ObserveContext(ctx, seqUpdateLeavesLatency, clock.SecondsSince(s.timeSource, start), s.label)
 	return nil
 }
 
@@ -314,7 +352,7 @@ func (s *preorderedLogSequencingTask) fetch(ctx context.Context, limit int, cuto
 	if err != nil {
 		return nil, fmt.Errorf("%v: Sequencer failed to load sequenced leaves: %v", s.label, err)
 	}
-	seqDequeueLatency.Observe(clock.SecondsSince(s.timeSource, start), s.label)
+	monitoring.ObserveContext(ctx, seqDequeueLatency, clock.SecondsSince(s.timeSource, start), s.label)
 	return leaves, nil
 }
 
@@ -323,19 +361,44 @@ func (s *preorderedLogSequencingTask) update(ctx context.Context, leaves []*tril
 	return nil
 }
 
+// leafError is returned by IntegrateBatch when integrating the leaves of the
+// batch failed, rather than reading or writing the rest of the tree, so that
+// the failure can be pinned on the leaves rather than, e.g., on storage being
+// unavailable.
+type leafError struct {
+	err error
+}
+
+func (e leafError) Error() string {
+	return e.err.Error()
+}
+
+func (e leafError) Unwrap() error {
+	return e.err
+}
+
+// isLeafError returns whether err is a leafError.
+func isLeafError(err error) bool {
+	var le leafError
+	return errors.As(err, &le)
+}
+
 // IntegrateBatch wraps up all the operations needed to take a batch of queued
-// or sequenced leaves and integrate them into the tree.
+// or sequenced leaves and integrate them into the tree. Failures to integrate
+// the leaves themselves, as opposed to failures of storage or signing, are
+// reported as a leafError.
 func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limit int, guardWindow, maxRootDurationInterval time.Duration) (int, error) {
 	start := s.timeSource.Now()
 	label := strconv.FormatInt(tree.TreeId, 10)
 
 	numLeaves := 0
+	var mergeDelays []time.Duration
 	var newLogRoot *types.LogRootV1
 	var newSLR *trillian.SignedLogRoot
 	err := s.logStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
 		stageStart := s.timeSource.Now()
 		defer seqBatches.Inc(label)
-		defer func() { seqLatency.Observe(clock.SecondsSince(s.timeSource, start), label) }()
+		defer func() { monitoring.ObserveContext(ctx, seqLatency, clock.SecondsSince(s.timeSource, start), label) }()
 
 		// Get the latest known root from storage
 		sth, err := tx.LatestSignedLogRoot(ctx)
@@ -349,11 +412,12 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 		if err := currentRoot.UnmarshalBinary(sth.LogRoot); err != nil {
 			return fmt.Errorf("%v: Sequencer failed to unmarshal latest root: %v", tree.TreeId, err)
 		}
-		seqGetRootLatency.Observe(clock.SecondsSince(s.timeSource, stageStart), label)
+		monitoring.ObserveContext(ctx, seqGetRootLatency, clock.SecondsSince(s.timeSource, stageStart), label)
 		seqTreeSize.Set(float64(currentRoot.TreeSize), label)
+		s.observeRoot(tree.TreeId, &currentRoot, maxRootDurationInterval)
 
 		if currentRoot.RootHash == nil {
-			glog.Warningf("%v: Fresh log - no previous TreeHeads exist.", tree.TreeId)
+			glog.Warningf("%s%v: Fresh log - no previous TreeHeads exist.", requestid.Prefix(ctx), tree.TreeId)
 			return storage.ErrTreeNeedsInit
 		}
 
@@ -378,6 +442,11 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 			return fmt.Errorf("%v: Sequencer failed to load sequenced batch: %v", tree.TreeId, err)
 		}
 		numLeaves = len(sequencedLeaves)
+		if glog.V(2) {
+			for _, leaf := range sequencedLeaves {
+				glog.Infof("%s%v: sequencing leaf with identity hash %x at index %d", requestid.Prefix(ctx), tree.TreeId, leaf.LeafIdentityHash, leaf.LeafIndex)
+			}
+		}
 
 		// We need to create a signed root if entries were added or the latest root
 		// is too old.
@@ -386,10 +455,10 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 			interval := time.Duration(nowNanos - int64(currentRoot.TimestampNanos))
 			if maxRootDurationInterval == 0 || interval < maxRootDurationInterval {
 				// We have nothing to integrate into the tree.
-				glog.V(1).Infof("%v: No leaves sequenced in this signing operation", tree.TreeId)
+				glog.V(1).Infof("%s%v: No leaves sequenced in this signing operation", requestid.Prefix(ctx), tree.TreeId)
 				return nil
 			}
-			glog.Infof("%v: Force new root generation as %v since last root", tree.TreeId, interval)
+			glog.Infof("%s%v: Force new root generation as %v since last root", requestid.Prefix(ctx), tree.TreeId, interval)
 		}
 
 		stageStart = s.timeSource.Now()
@@ -397,7 +466,7 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 		if err != nil {
 			return fmt.Errorf("%v: compact range init failed: %v", tree.TreeId, err)
 		}
-		seqInitTreeLatency.Observe(clock.SecondsSince(s.timeSource, stageStart), label)
+		monitoring.ObserveContext(ctx, seqInitTreeLatency, clock.SecondsSince(s.timeSource, stageStart), label)
 		stageStart = s.timeSource.Now()
 
 		// We've done all the reads, can now do the updates in the same transaction.
@@ -413,18 +482,21 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 		}
 
 		// Collate node updates.
-		if err := s.prepareLeaves(sequencedLeaves, cr.End(), label); err != nil {
-			return err
+		if mergeDelays, err = s.prepareLeaves(sequencedLeaves, cr.End(), label); err != nil {
+			return leafError{err}
 		}
 		nodeMap, newRoot, err := s.updateCompactRange(cr, sequencedLeaves, label)
 		if err != nil {
-			return err
+			return leafError{err}
 		}
-		seqWriteTreeLatency.Observe(clock.SecondsSince(s.timeSource, stageStart), label)
+		monitoring.ObserveContext(ctx, seqWriteTreeLatency, clock.SecondsSince(s.timeSource, stageStart), label)
 
 		// Store the sequenced batch.
 		if err := st.update(ctx, sequencedLeaves); err != nil {
-			return err
+			if ctx.Err() != nil {
+				return err
+			}
+			return leafError{err}
 		}
 		stageStart = s.timeSource.Now()
 
@@ -442,7 +514,7 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 		if err := tx.SetMerkleNodes(ctx, targetNodes); err != nil {
 			return fmt.Errorf("%v: Sequencer failed to set Merkle nodes: %v", tree.TreeId, err)
 		}
-		seqSetNodesLatency.Observe(clock.SecondsSince(s.timeSource, stageStart), label)
+		monitoring.ObserveContext(ctx, seqSetNodesLatency, clock.SecondsSince(s.timeSource, stageStart), label)
 		stageStart = s.timeSource.Now()
 
 		// Create the log root ready for signing.
@@ -472,28 +544,408 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 		if err := tx.StoreSignedLogRoot(ctx, newSLR); err != nil {
 			return fmt.Errorf("%v: failed to write updated tree root: %v", tree.TreeId, err)
 		}
-		seqStoreRootLatency.Observe(clock.SecondsSince(s.timeSource, stageStart), label)
+		monitoring.ObserveContext(ctx, seqStoreRootLatency, clock.SecondsSince(s.timeSource, stageStart), label)
 		return nil
 	})
 	if err != nil {
 		return 0, err
 	}
 
+	s.observeMergeDelays(ctx, tree.TreeId, mergeDelays)
 	// Let quota.Manager know about newly-sequenced entries.
 	s.replenishQuota(ctx, numLeaves, tree.TreeId)
 
 	seqCounter.Add(float64(numLeaves), label)
 	if newSLR != nil {
-		glog.Infof("%v: sequenced %v leaves, size %v, tree-revision %v", tree.TreeId, numLeaves, newLogRoot.TreeSize, newLogRoot.Revision)
+		s.observeRoot(tree.TreeId, newLogRoot, maxRootDurationInterval)
+		glog.Infof("%s%v: sequenced %v leaves, size %v, tree-revision %v", requestid.Prefix(ctx), tree.TreeId, numLeaves, newLogRoot.TreeSize, newLogRoot.Revision)
 	}
 	return numLeaves, nil
 }
 
+// observeRoot tells s.rootAges, if set, about the latest root of a log.
+func (s Sequencer) observeRoot(treeID int64, root *types.LogRootV1, maxRootDuration time.Duration) {
+	if s.rootAges == nil || root.TimestampNanos == 0 {
+		return
+	}
+	s.rootAges.Observe(treeID, time.Unix(0, int64(root.TimestampNanos)), maxRootDuration)
+}
+
+// observeMergeDelays tells s.mergeDelays, if set, about the merge delays of a
+// batch of leaves integrated into a log. If the policy says that logs at risk
+// of violating their MMD should refuse new leaves, it drains the write quota
+// of the log when it becomes at risk, and resets it when it no longer is, or
+// when the log is first observed, e.g. after mastership of it changed hands.
+func (s Sequencer) observeMergeDelays(ctx context.Context, treeID int64, delays []time.Duration) {
+	if s.mergeDelays == nil {
+		return
+	}
+	atRisk, changed := s.mergeDelays.Observe(treeID, delays)
+	if !changed || !s.mergeDelays.Policy().RefuseWhenAtRisk {
+		return
+	}
+	specs := []quota.Spec{{Group: quota.Tree, Kind: quota.Write, TreeID: treeID}}
+	if !atRisk {
+		if err := s.qm.ResetQuota(ctx, specs); err != nil {
+			glog.Warningf("%s%v: failed to reset write quota: %v", requestid.Prefix(ctx), treeID, err)
+		}
+		return
+	}
+	peeker, ok := s.qm.(quota.TokenPeeker)
+	if !ok {
+		glog.Warningf("%s%v: can't refuse new leaves, the quota manager doesn't report available tokens", requestid.Prefix(ctx), treeID)
+		return
+	}
+	available, err := peeker.PeekTokens(ctx, specs)
+	if err != nil {
+		glog.Warningf("%s%v: failed to peek write quota: %v", requestid.Prefix(ctx), treeID, err)
+		return
+	}
+	switch tokens := available[specs[0]]; {
+	case tokens >= quota.MaxTokens:
+		glog.Warningf("%s%v: can't refuse new leaves, the log has no write quota", requestid.Prefix(ctx), treeID)
+	case tokens > 0:
+		if err := s.qm.GetTokens(ctx, tokens, specs); err != nil {
+			glog.Warningf("%s%v: failed to drain %d write tokens: %v", requestid.Prefix(ctx), treeID, tokens, err)
+		}
+	}
+}
+
+// refusingLeaves returns whether the log is refusing new leaves, because it is
+// at risk of violating its MMD.
+func (s Sequencer) refusingLeaves(treeID int64) bool {
+	return s.mergeDelays != nil && s.mergeDelays.Policy().RefuseWhenAtRisk && s.mergeDelays.AtRisk(treeID)
+}
+
+// QuarantineLeaves takes at most limit leaves from the head of the queue of a
+// LOG tree and moves them into the dead-letter store, so that they no longer
+// block sequencing of the rest of the queue. It returns the number of leaves
+// quarantined, or an Unimplemented error if the storage does not support it.
+func (s Sequencer) QuarantineLeaves(ctx context.Context, tree *trillian.Tree, limit int, guardWindow time.Duration, reason string) (int, error) {
+	if tree.TreeType != trillian.TreeType_LOG {
+		return 0, status.Errorf(codes.FailedPrecondition, "QuarantineLeaves not supported for TreeType %v", tree.TreeType)
+	}
+	start := s.timeSource.Now()
+	label := strconv.FormatInt(tree.TreeId, 10)
+
+	var quarantined []*trillian.LogLeaf
+	err := s.logStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dl, ok := tx.(storage.DeadLetterTX)
+		if !ok {
+			return status.Errorf(codes.Unimplemented, "%v: storage does not support quarantining leaves", tree.TreeId)
+		}
+		leaves, err := tx.DequeueLeaves(ctx, limit, start.Add(-guardWindow))
+		if err != nil {
+			return fmt.Errorf("%v: failed to dequeue leaves for quarantine: %v", tree.TreeId, err)
+		}
+		if len(leaves) == 0 {
+			return nil
+		}
+		if err := dl.QuarantineLeaves(ctx, leaves, reason, s.timeSource.Now()); err != nil {
+			return fmt.Errorf("%v: failed to quarantine leaves: %v", tree.TreeId, err)
+		}
+		quarantined = leaves
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Quarantined leaves will never be integrated, so release the tokens they
+	// were charged when queued.
+	s.replenishQuota(ctx, len(quarantined), tree.TreeId)
+
+	seqQuarantined.Add(float64(len(quarantined)), label)
+	for _, leaf := range quarantined {
+		glog.Warningf("%s%v: quarantined leaf with identity hash %x: %s", requestid.Prefix(ctx), tree.TreeId, leaf.LeafIdentityHash, reason)
+	}
+	return len(quarantined), nil
+}
+
+// PrefetchCompactRange reads the compact range of the latest root of a log,
+// which the next call to IntegrateBatch starts from, in a read-only
+// transaction, without checking or returning it. This is only useful with
+// storage which caches what it reads across transactions.
+func (s Sequencer) PrefetchCompactRange(ctx context.Context, tree *trillian.Tree) error {
+	tx, err := s.logStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return fmt.Errorf("%v: failed to start snapshot: %v", tree.TreeId, err)
+	}
+	defer tx.Close()
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil || slr == nil {
+		return fmt.Errorf("%v: failed to get latest root: %v", tree.TreeId, err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return fmt.Errorf("%v: failed to unmarshal latest root: %v", tree.TreeId, err)
+	}
+	if _, err := s.readCompactRange(ctx, root.TreeSize, int64(root.Revision), tx); err != nil {
+		return fmt.Errorf("%v: failed to read compact range: %v", tree.TreeId, err)
+	}
+	return tx.Commit(ctx)
+}
+
+// VerifyRecent re-integrates the most recent leaves of a log, up to window of
+// them (all of them if window is 0), on top of the compact range of the tree
+// which precedes them, in a read-only transaction. The recomputed leaf hashes,
+// tree nodes and root hash are checked against those in storage, and nothing
+// is written. This is a self-audit which detects silent corruption of the
+// recent part of the tree: mismatches are counted and logged, and returned as
+// a DataLoss error. It returns the number of leaves verified.
+func (s Sequencer) VerifyRecent(ctx context.Context, tree *trillian.Tree, window int64) (int, error) {
+	label := strconv.FormatInt(tree.TreeId, 10)
+	tx, err := s.logStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return 0, fmt.Errorf("%v: failed to start snapshot: %v", tree.TreeId, err)
+	}
+	defer tx.Close()
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil || slr == nil {
+		return 0, fmt.Errorf("%v: failed to get latest root: %v", tree.TreeId, err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return 0, fmt.Errorf("%v: failed to unmarshal latest root: %v", tree.TreeId, err)
+	}
+	if root.TreeSize == 0 {
+		// Nothing has been integrated yet.
+		return 0, tx.Commit(ctx)
+	}
+	begin := uint64(0)
+	if window > 0 && root.TreeSize > uint64(window) {
+		begin = root.TreeSize - uint64(window)
+	}
+
+	mismatches, err := s.verifyRange(ctx, tx, &root, begin)
+	if err != nil {
+		return 0, fmt.Errorf("%v: failed to verify leaves [%d, %d): %v", tree.TreeId, begin, root.TreeSize, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%v: failed to commit snapshot: %v", tree.TreeId, err)
+	}
+	if len(mismatches) > 0 {
+		seqVerifyMismatches.Add(float64(len(mismatches)), label)
+		for _, m := range mismatches {
+			glog.Errorf("%s%v: verification of leaves [%d, %d) at revision %d: %s", requestid.Prefix(ctx), tree.TreeId, begin, root.TreeSize, root.Revision, m)
+		}
+		return 0, status.Errorf(codes.DataLoss, "%v: %d mismatches verifying leaves [%d, %d) at revision %d, first: %s", tree.TreeId, len(mismatches), begin, root.TreeSize, root.Revision, mismatches[0])
+	}
+	n := int(root.TreeSize - begin)
+	seqVerified.Add(float64(n), label)
+	return n, nil
+}
+
+// verifyRange recomputes the part of the tree which integrating the leaves in
+// [begin, root.TreeSize) produced, and returns a description of every
+// difference from the stored leaves, tree nodes and root.
+func (s Sequencer) verifyRange(ctx context.Context, tx storage.ReadOnlyLogTreeTX, root *types.LogRootV1, begin uint64) ([]string, error) {
+	var mismatches []string
+	cr, err := s.readCompactRange(ctx, begin, int64(root.Revision), tx)
+	if err != nil {
+		return nil, fmt.Errorf("compact range init failed: %v", err)
+	}
+
+	leaves := make([]*trillian.LogLeaf, 0, root.TreeSize-begin)
+	for idx := begin; idx < root.TreeSize; {
+		batch, err := tx.GetLeavesByRange(ctx, int64(idx), int64(root.TreeSize-idx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get leaves: %v", err)
+		}
+		if len(batch) == 0 {
+			mismatches = append(mismatches, fmt.Sprintf("leaves [%d, %d) are missing", idx, root.TreeSize))
+			return mismatches, nil
+		}
+		for _, leaf := range batch {
+			if idx == root.TreeSize {
+				// PREORDERED_LOG trees return leaves beyond the tree size.
+				break
+			}
+			if leaf.LeafIndex != int64(idx) {
+				mismatches = append(mismatches, fmt.Sprintf("got leaf %d, want leaf %d", leaf.LeafIndex, idx))
+				return mismatches, nil
+			}
+			hash := s.hasher.HashLeaf(leaf.LeafValue)
+			if !bytes.Equal(leaf.MerkleLeafHash, hash) {
+				mismatches = append(mismatches, fmt.Sprintf("leaf %d has Merkle leaf hash %x, want %x", idx, leaf.MerkleLeafHash, hash))
+			}
+			leaves = append(leaves, &trillian.LogLeaf{LeafIndex: leaf.LeafIndex, MerkleLeafHash: hash})
+			idx++
+		}
+	}
+
+	nodeMap, hash, err := s.updateCompactRange(cr, leaves, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to recompute tree: %v", err)
+	}
+	if !bytes.Equal(hash, root.RootHash) {
+		mismatches = append(mismatches, fmt.Sprintf("root has hash %x, want %x", root.RootHash, hash))
+	}
+
+	want, err := s.buildNodesFromNodeMap(nodeMap, int64(root.Revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create nodes: %v", err)
+	}
+	ids := make([]tree.NodeID, len(want))
+	for i, node := range want {
+		ids[i] = node.NodeID
+	}
+	got, err := tx.GetMerkleNodes(ctx, int64(root.Revision), ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Merkle nodes: %v", err)
+	}
+	stored := make(map[string][]byte, len(got))
+	for _, node := range got {
+		stored[node.NodeID.String()] = node.Hash
+	}
+	for _, node := range want {
+		if hash, ok := stored[node.NodeID.String()]; !ok {
+			mismatches = append(mismatches, fmt.Sprintf("node %v is missing", node.NodeID.CoordString()))
+		} else if !bytes.Equal(hash, node.Hash) {
+			mismatches = append(mismatches, fmt.Sprintf("node %v has hash %x, want %x", node.NodeID.CoordString(), hash, node.Hash))
+		}
+	}
+	return mismatches, nil
+}
+
+// auditedLog is the state of a log after an append-only audit: its latest
+// audited root, and the compact range of the tree under that root.
+type auditedLog struct {
+	root types.LogRootV1
+	cr   *compact.Range
+}
+
+// auditAppendOnly checks that the latest root of a log extends the previously
+// audited one, prev, append-only. The leaves added since prev are read from
+// storage, their Merkle leaf hashes are recomputed, and they are appended to
+// the compact range of prev, which must then hash to the latest root. If prev
+// is nil, the audit starts from the latest root, whose compact range is read
+// from the stored tree nodes and checked against it.
+//
+// It returns the new audited state, and the number of leaves audited. Roots
+// which aren't append-only extensions of prev are counted and logged, and
+// reported as a DataLoss error along with prev, so that later roots keep being
+// audited against the last good one.
+func (s Sequencer) auditAppendOnly(ctx context.Context, tree *trillian.Tree, prev *auditedLog) (*auditedLog, int, error) {
+	label := strconv.FormatInt(tree.TreeId, 10)
+	tx, err := s.logStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return prev, 0, fmt.Errorf("%v: failed to start snapshot: %v", tree.TreeId, err)
+	}
+	defer tx.Close()
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil || slr == nil {
+		return prev, 0, fmt.Errorf("%v: failed to get latest root: %v", tree.TreeId, err)
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return prev, 0, fmt.Errorf("%v: failed to unmarshal latest root: %v", tree.TreeId, err)
+	}
+
+	var cr *compact.Range
+	var violation string
+	switch {
+	case prev == nil:
+		if cr, err = s.readCompactRange(ctx, root.TreeSize, int64(root.Revision), tx); err != nil {
+			return prev, 0, fmt.Errorf("%v: compact range init failed: %v", tree.TreeId, err)
+		}
+		violation, err = s.checkRootHash(cr, &root)
+	case root.TreeSize < prev.root.TreeSize:
+		violation = fmt.Sprintf("tree size went down from %d to %d", prev.root.TreeSize, root.TreeSize)
+	default:
+		fact := compact.RangeFactory{Hash: s.hasher.HashChildren}
+		if cr, err = fact.NewRange(0, prev.cr.End(), prev.cr.Hashes()); err != nil {
+			return prev, 0, fmt.Errorf("%v: failed to copy compact range: %v", tree.TreeId, err)
+		}
+		violation, err = s.appendLeaves(ctx, tx, cr, root.TreeSize)
+		if err == nil && violation == "" {
+			violation, err = s.checkRootHash(cr, &root)
+		}
+	}
+	if err != nil {
+		return prev, 0, fmt.Errorf("%v: failed to audit root at revision %d: %v", tree.TreeId, root.Revision, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return prev, 0, fmt.Errorf("%v: failed to commit snapshot: %v", tree.TreeId, err)
+	}
+	if violation != "" {
+		seqAuditViolations.Inc(label)
+		if prev != nil {
+			violation = fmt.Sprintf("root of size %d at revision %d doesn't extend audited root of size %d at revision %d: %s", root.TreeSize, root.Revision, prev.root.TreeSize, prev.root.Revision, violation)
+		} else {
+			violation = fmt.Sprintf("root of size %d at revision %d doesn't match the stored tree: %s", root.TreeSize, root.Revision, violation)
+		}
+		glog.Errorf("%s%v: append-only audit failed: %s", requestid.Prefix(ctx), tree.TreeId, violation)
+		return prev, 0, status.Errorf(codes.DataLoss, "%v: %s", tree.TreeId, violation)
+	}
+
+	n := 0
+	if prev != nil {
+		n = int(root.TreeSize - prev.root.TreeSize)
+	}
+	seqAuditedSize.Set(float64(root.TreeSize), label)
+	return &auditedLog{root: root, cr: cr}, n, nil
+}
+
+// appendLeaves reads the leaves in [cr.End(), size) from storage, and appends
+// their recomputed Merkle leaf hashes to cr. It returns a description of the
+// first problem with the stored leaves found, if any.
+func (s Sequencer) appendLeaves(ctx context.Context, tx storage.ReadOnlyLogTreeTX, cr *compact.Range, size uint64) (string, error) {
+	for idx := cr.End(); idx < size; {
+		batch, err := tx.GetLeavesByRange(ctx, int64(idx), int64(size-idx))
+		if err != nil {
+			return "", fmt.Errorf("failed to get leaves: %v", err)
+		}
+		if len(batch) == 0 {
+			return fmt.Sprintf("leaves [%d, %d) are missing", idx, size), nil
+		}
+		for _, leaf := range batch {
+			if idx == size {
+				// PREORDERED_LOG trees return leaves beyond the tree size.
+				break
+			}
+			if leaf.LeafIndex != int64(idx) {
+				return fmt.Sprintf("got leaf %d, want leaf %d", leaf.LeafIndex, idx), nil
+			}
+			hash := s.hasher.HashLeaf(leaf.LeafValue)
+			if !bytes.Equal(leaf.MerkleLeafHash, hash) {
+				return fmt.Sprintf("leaf %d has Merkle leaf hash %x, want %x", idx, leaf.MerkleLeafHash, hash), nil
+			}
+			if err := cr.Append(hash, nil); err != nil {
+				return "", err
+			}
+			idx++
+		}
+	}
+	return "", nil
+}
+
+// checkRootHash returns a description of the mismatch between the hash of cr
+// and that of root, if any.
+func (s Sequencer) checkRootHash(cr *compact.Range, root *types.LogRootV1) (string, error) {
+	hash := s.hasher.EmptyRoot()
+	if cr.End() > 0 {
+		var err error
+		if hash, err = cr.GetRootHash(nil); err != nil {
+			return "", fmt.Errorf("failed to compute the root hash: %v", err)
+		}
+	}
+	if !bytes.Equal(hash, root.RootHash) {
+		return fmt.Sprintf("root has hash %x, want %x", root.RootHash, hash), nil
+	}
+	return "", nil
+}
+
 // replenishQuota replenishes all quotas, such as {Tree/Global, Read/Write},
 // that are possibly influenced by sequencing numLeaves entries for the passed
 // in tree ID. Implementations are tasked with filtering quotas that shouldn't
 // be replenished.
 //
+// The write quota of a log which is refusing new leaves isn't replenished.
+//
 // TODO(codingllama): Consider adding a source-aware replenish method (e.g.,
 // qm.Replenish(ctx, tokens, specs, quota.SequencerSource)), so there's no
 // ambiguity as to where the tokens come from.
@@ -506,10 +958,13 @@ func (s Sequencer) replenishQuota(ctx context.Context, numLeaves int, treeID int
 			{Group: quota.Global, Kind: quota.Read},
 			{Group: quota.Global, Kind: quota.Write},
 		}
-		glog.V(2).Infof("%v: replenishing %d tokens (numLeaves = %d)", treeID, tokens, numLeaves)
+		if s.refusingLeaves(treeID) {
+			specs = append(specs[:1], specs[2:]...)
+		}
+		glog.V(2).Infof("%s%v: replenishing %d tokens (numLeaves = %d)", requestid.Prefix(ctx), treeID, tokens, numLeaves)
 		err := s.qm.PutTokens(ctx, tokens, specs)
 		if err != nil {
-			glog.Warningf("%v: failed to replenish %d tokens: %v", treeID, tokens, err)
+			glog.Warningf("%s%v: failed to replenish %d tokens: %v", requestid.Prefix(ctx), treeID, tokens, err)
 		}
 		quota.Metrics.IncReplenished(tokens, specs, err == nil)
 	}
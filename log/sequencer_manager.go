@@ -36,6 +36,11 @@ type SequencerManager struct {
 	registry     extension.Registry
 	signers      map[int64]*tcrypto.Signer
 	signersMutex sync.Mutex
+
+	// failures holds the number of consecutive passes per log which failed to
+	// integrate the leaves of their batch.
+	failures      map[int64]int
+	failuresMutex sync.Mutex
 }
 
 var seqOpts = trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
@@ -47,6 +52,7 @@ func NewSequencerManager(registry extension.Registry, gw time.Duration) *Sequenc
 		guardWindow: gw,
 		registry:    registry,
 		signers:     make(map[int64]*tcrypto.Signer),
+		failures:    make(map[int64]int),
 	}
 }
 
@@ -72,19 +78,97 @@ func (s *SequencerManager) ExecutePass(ctx context.Context, logID int64, info *O
 	}
 
 	sequencer := NewSequencer(hasher, info.TimeSource, s.registry.LogStorage, signer, s.registry.MetricFactory, s.registry.QuotaManager)
+	sequencer.rootAges = info.rootAges
+	sequencer.mergeDelays = info.mergeDelays
 
 	maxRootDuration, err := ptypes.Duration(tree.MaxRootDuration)
 	if err != nil {
 		glog.Warning("failed to parse tree.MaxRootDuration, using zero")
 		maxRootDuration = 0
 	}
-	leaves, err := sequencer.IntegrateBatch(ctx, tree, info.BatchSize, s.guardWindow, maxRootDuration)
+	threshold := info.QuarantineAfterFailures
+	batchSize := info.BatchSize
+	isolating := threshold > 0 && s.failureCount(logID) >= threshold
+	if isolating {
+		// Integrate one leaf at a time, so that a leaf which can't be integrated
+		// is at the head of the queue when it gets quarantined.
+		batchSize = 1
+	}
+	leaves, err := sequencer.IntegrateBatch(ctx, tree, batchSize, s.guardWindow, maxRootDuration)
 	if err != nil {
+		if !isLeafError(err) {
+			// Failures which aren't down to the leaves of the batch, such as
+			// storage being unavailable, would quarantine healthy leaves, so
+			// they don't count.
+			return 0, fmt.Errorf("failed to integrate batch for %v: %v", logID, err)
+		}
+		failures := s.recordFailure(logID)
+		if isolating && failures >= 2*threshold && tree.TreeType == trillian.TreeType_LOG {
+			// The batch held a single leaf, which is the one at fault.
+			reason := fmt.Sprintf("sequencing failed %d times in a row: %v", failures, err)
+			if n, qErr := sequencer.QuarantineLeaves(ctx, tree, 1, s.guardWindow, reason); qErr != nil {
+				glog.Warningf("%v: failed to quarantine leaf: %v", logID, qErr)
+			} else if n > 0 {
+				s.resetFailures(logID, threshold-1)
+			}
+		}
 		return 0, fmt.Errorf("failed to integrate batch for %v: %v", logID, err)
 	}
+	if isolating {
+		// Try a full batch on the next pass, but return to isolating leaves
+		// straight away if that fails again.
+		s.resetFailures(logID, threshold-1)
+	} else {
+		s.resetFailures(logID, 0)
+	}
 	return leaves, nil
 }
 
+// Prepare pre-fetches the parts of the Merkle tree of the specified Log which
+// the first sequencing pass over it reads.
+func (s *SequencerManager) Prepare(ctx context.Context, logID int64, info *OperationInfo) error {
+	tree, err := trees.GetTree(ctx, s.registry.AdminStorage, logID, seqOpts)
+	if err != nil {
+		return fmt.Errorf("error retrieving log %v: %v", logID, err)
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return fmt.Errorf("error getting hasher for log %v: %v", logID, err)
+	}
+	// Nothing is signed, so there's no need for a signer.
+	sequencer := NewSequencer(hasher, info.TimeSource, s.registry.LogStorage, nil, s.registry.MetricFactory, s.registry.QuotaManager)
+	return sequencer.PrefetchCompactRange(ctx, tree)
+}
+
+// failureCount returns the number of consecutive failed passes for the log.
+func (s *SequencerManager) failureCount(logID int64) int {
+	s.failuresMutex.Lock()
+	defer s.failuresMutex.Unlock()
+	return s.failures[logID]
+}
+
+// recordFailure increments the number of consecutive failed passes for the
+// log, and returns the new value.
+func (s *SequencerManager) recordFailure(logID int64) int {
+	s.failuresMutex.Lock()
+	defer s.failuresMutex.Unlock()
+	s.failures[logID]++
+	return s.failures[logID]
+}
+
+// resetFailures sets the number of consecutive failed passes for the log.
+func (s *SequencerManager) resetFailures(logID int64, count int) {
+	s.failuresMutex.Lock()
+	defer s.failuresMutex.Unlock()
+	if count <= 0 {
+		delete(s.failures, logID)
+		return
+	}
+	s.failures[logID] = count
+}
+
 // getSigner returns a signer for the given tree.
 // Signers are cached, so only one will be created per tree.
 func (s *SequencerManager) getSigner(ctx context.Context, tree *trillian.Tree) (*tcrypto.Signer, error) {
@@ -15,6 +15,7 @@
 package log
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"errors"
@@ -314,6 +315,151 @@ func TestSequencerManagerGuardWindow(t *testing.T) {
 	sm.ExecutePass(ctx, logID, createTestInfo(registry))
 }
 
+// poisonLogTX is a LogTreeTX over a fixed queue of leaves, which fails to
+// store the sequenced leaves of any batch holding the poison leaf, and can
+// quarantine leaves.
+type poisonLogTX struct {
+	storage.LogTreeTX
+	storage.DeadLetterTX
+	queue       []*trillian.LogLeaf
+	poison      []byte
+	limits      []int
+	quarantined [][]byte
+}
+
+func (tx *poisonLogTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	return testSignedRoot0, nil
+}
+
+func (tx *poisonLogTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error) {
+	tx.limits = append(tx.limits, limit)
+	var leaves []*trillian.LogLeaf
+	for _, l := range tx.queue {
+		if len(leaves) == limit {
+			break
+		}
+		leaves = append(leaves, proto.Clone(l).(*trillian.LogLeaf))
+	}
+	return leaves, nil
+}
+
+func (tx *poisonLogTX) WriteRevision(ctx context.Context) (int64, error) {
+	return int64(testRoot0.Revision + 1), nil
+}
+
+func (tx *poisonLogTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	for _, l := range leaves {
+		if bytes.Equal(l.LeafIdentityHash, tx.poison) {
+			return errors.New("row too large")
+		}
+	}
+	return nil
+}
+
+func (tx *poisonLogTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
+	return nil
+}
+
+func (tx *poisonLogTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
+	return nil
+}
+
+func (tx *poisonLogTX) QuarantineLeaves(ctx context.Context, leaves []*trillian.LogLeaf, reason string, quarantineTime time.Time) error {
+	for _, l := range leaves {
+		tx.quarantined = append(tx.quarantined, l.LeafIdentityHash)
+		for i, q := range tx.queue {
+			if bytes.Equal(q.LeafIdentityHash, l.LeafIdentityHash) {
+				tx.queue = append(tx.queue[:i], tx.queue[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (tx *poisonLogTX) Commit(ctx context.Context) error {
+	return nil
+}
+
+func (tx *poisonLogTX) Close() error {
+	return nil
+}
+
+func TestSequencerManagerQuarantine(t *testing.T) {
+	ctx := context.Background()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var keyProto ptypes.DynamicAny
+	if err := ptypes.UnmarshalAny(stestonly.LogTree.PrivateKey, &keyProto); err != nil {
+		t.Fatalf("Failed to unmarshal stestonly.LogTree.PrivateKey: %v", err)
+	}
+	keys.RegisterHandler(fakeKeyProtoHandler(keyProto.Message, fixedGoSigner, nil))
+	defer keys.UnregisterHandler(keyProto.Message)
+
+	logID := stestonly.LogTree.GetTreeId()
+	mockAdminTx := storage.NewMockReadOnlyAdminTX(mockCtrl)
+	mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).AnyTimes().Return(stestonly.LogTree, nil)
+	mockAdminTx.EXPECT().Commit().AnyTimes().Return(nil)
+	mockAdminTx.EXPECT().Close().AnyTimes().Return(nil)
+	mockAdmin := &stestonly.FakeAdminStorage{}
+
+	poison := &trillian.LogLeaf{LeafIdentityHash: []byte("poison"), MerkleLeafHash: leaf0Hash}
+	good := &trillian.LogLeaf{LeafIdentityHash: []byte("good"), MerkleLeafHash: leaf0Hash}
+	tx := &poisonLogTX{queue: []*trillian.LogLeaf{poison, good}, poison: poison.LeafIdentityHash}
+	fakeStorage := &stestonly.FakeLogStorage{TX: tx}
+	registry := extension.Registry{
+		AdminStorage: mockAdmin,
+		LogStorage:   fakeStorage,
+		QuotaManager: quota.Noop(),
+	}
+	info := createTestInfo(registry)
+	info.QuarantineAfterFailures = 2
+	sm := NewSequencerManager(registry, zeroDuration)
+
+	for i, step := range []struct {
+		desc string
+		// down makes the storage fail every transaction.
+		down            bool
+		wantLimits      []int
+		wantErr         bool
+		wantFailures    int
+		wantQuarantined int
+	}{
+		{desc: "storage down", down: true, wantErr: true},
+		{desc: "poisoned batch", wantLimits: []int{50}, wantErr: true, wantFailures: 1},
+		{desc: "poisoned batch again", wantLimits: []int{50}, wantErr: true, wantFailures: 2},
+		{desc: "isolated poison", wantLimits: []int{1}, wantErr: true, wantFailures: 3},
+		{desc: "storage down while isolating", down: true, wantErr: true, wantFailures: 3},
+		{desc: "quarantined poison", wantLimits: []int{1, 1}, wantErr: true, wantFailures: 1, wantQuarantined: 1},
+		{desc: "full batch", wantLimits: []int{50}, wantQuarantined: 1},
+	} {
+		mockAdmin.ReadOnlyTX = []storage.ReadOnlyAdminTX{mockAdminTx}
+		fakeStorage.TXErr = nil
+		if step.down {
+			fakeStorage.TXErr = errors.New("storage unavailable")
+		}
+		tx.limits = nil
+
+		_, err := sm.ExecutePass(ctx, logID, info)
+		if gotErr := err != nil; gotErr != step.wantErr {
+			t.Fatalf("%d: %s: ExecutePass(): %v, want err: %v", i, step.desc, err, step.wantErr)
+		}
+		if diff := cmp.Diff(tx.limits, step.wantLimits); diff != "" {
+			t.Errorf("%d: %s: dequeue limits diff (-got +want):\n%s", i, step.desc, diff)
+		}
+		if got, want := sm.failureCount(logID), step.wantFailures; got != want {
+			t.Errorf("%d: %s: failureCount()=%d, want %d", i, step.desc, got, want)
+		}
+		if got, want := len(tx.quarantined), step.wantQuarantined; got != want {
+			t.Errorf("%d: %s: %d leaves quarantined, want %d", i, step.desc, got, want)
+		}
+	}
+	if diff := cmp.Diff(tx.quarantined, [][]byte{poison.LeafIdentityHash}); diff != "" {
+		t.Errorf("quarantined leaves diff (-got +want):\n%s", diff)
+	}
+}
+
 func createTestInfo(registry extension.Registry) *OperationInfo {
 	// Set sign interval to 100 years so it won't trigger a root expiry signing unless overridden
 	return &OperationInfo{
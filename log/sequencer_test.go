@@ -19,19 +19,31 @@ import (
 	"crypto"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
 	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/merkle/compact"
 	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
+	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
 	"github.com/google/trillian/testonly"
 	"github.com/google/trillian/types"
 	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/mergedelay"
+	"github.com/google/trillian/util/rootage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	tcrypto "github.com/google/trillian/crypto"
 	stestonly "github.com/google/trillian/storage/testonly"
@@ -689,6 +701,97 @@ func TestIntegrateBatch(t *testing.T) {
 	}
 }
 
+func TestIntegrateBatch_RootAges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := testParameters{
+		logID:               154035,
+		dequeueLimit:        1,
+		shouldCommit:        true,
+		latestSignedRoot:    testSignedRoot16,
+		dequeuedLeaves:      []*trillian.LogLeaf{},
+		skipStoreSignedRoot: true,
+	}
+	c, ctx := createTestContext(ctrl, params)
+	mf := monitoring.InertMetricFactory{}
+	age := mf.NewGauge("age", "Test only", logIDLabel)
+	overdue := mf.NewGauge("overdue", "Test only", logIDLabel)
+	ts := clock.NewFake(fakeTime.Add(time.Hour))
+	c.sequencer.rootAges = rootage.NewTracker(age, overdue, time.Minute, ts)
+
+	tree := &trillian.Tree{TreeId: params.logID, TreeType: trillian.TreeType_LOG}
+	if _, err := c.sequencer.IntegrateBatch(ctx, tree, 1, 0, 0); err != nil {
+		t.Fatalf("IntegrateBatch()=%v", err)
+	}
+	if got := c.sequencer.rootAges.Update(); len(got) != 0 {
+		t.Errorf("Update()=%v, want no overdue logs without a max root duration", got)
+	}
+	label := strconv.FormatInt(params.logID, 10)
+	want := ts.Now().Sub(time.Unix(0, int64(testRoot16.TimestampNanos))).Seconds()
+	if got := age.Value(label); got != want {
+		t.Errorf("age[%v]=%v, want %v", label, got, want)
+	}
+}
+
+// peekingManager is a quota.Manager which reports a fixed number of available
+// tokens.
+type peekingManager struct {
+	*quota.MockManager
+	tokens int
+}
+
+func (m peekingManager) PeekTokens(ctx context.Context, specs []quota.Spec) (map[quota.Spec]int, error) {
+	tokens := make(map[quota.Spec]int)
+	for _, spec := range specs {
+		tokens[spec] = m.tokens
+	}
+	return tokens, nil
+}
+
+func TestObserveMergeDelays_RefuseWhenAtRisk(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const treeID int64 = 1234
+	writeSpecs := []quota.Spec{{Group: quota.Tree, Kind: quota.Write, TreeID: treeID}}
+	replenishSpecs := []quota.Spec{
+		{Group: quota.Tree, Kind: quota.Read, TreeID: treeID},
+		{Group: quota.Global, Kind: quota.Read},
+		{Group: quota.Global, Kind: quota.Write},
+	}
+	mf := monitoring.InertMetricFactory{}
+	policy := mergedelay.Policy{Default: time.Hour, RefuseWhenAtRisk: true}
+	qm := quota.NewMockManager(ctrl)
+	s := Sequencer{
+		qm:          peekingManager{MockManager: qm, tokens: 5},
+		mergeDelays: mergedelay.NewTracker(policy, mf.NewGauge("max_delay", "Test only", logIDLabel), mf.NewCounter("violations", "Test only", logIDLabel), mf.NewGauge("at_risk", "Test only", logIDLabel)),
+	}
+	ctx := context.Background()
+
+	gomock.InOrder(
+		// Write quota left drained by a previous master is reset.
+		qm.EXPECT().ResetQuota(gomock.Any(), writeSpecs).Return(nil),
+		// Becoming at risk drains the write quota.
+		qm.EXPECT().GetTokens(gomock.Any(), 5, writeSpecs).Return(nil),
+		// The write quota isn't replenished while at risk.
+		qm.EXPECT().PutTokens(gomock.Any(), gomock.Any(), replenishSpecs).Return(nil),
+		// Catching up resets the write quota.
+		qm.EXPECT().ResetQuota(gomock.Any(), writeSpecs).Return(nil),
+	)
+	s.observeMergeDelays(ctx, treeID, []time.Duration{time.Minute})
+	s.observeMergeDelays(ctx, treeID, []time.Duration{time.Minute})
+	s.observeMergeDelays(ctx, treeID, []time.Duration{40 * time.Minute})
+	if !s.refusingLeaves(treeID) {
+		t.Errorf("refusingLeaves()=false, want true")
+	}
+	s.replenishQuota(ctx, 10, treeID)
+	s.observeMergeDelays(ctx, treeID, nil)
+	if s.refusingLeaves(treeID) {
+		t.Errorf("refusingLeaves()=true, want false")
+	}
+}
+
 func TestIntegrateBatch_PutTokens(t *testing.T) {
 	cryptoSigner := newSignerWithFixedSig(testSignedRoot.LogRootSignature)
 
@@ -802,3 +905,309 @@ func TestIntegrateBatch_PutTokens(t *testing.T) {
 		}()
 	}
 }
+
+// deadLetterLogTX is a LogTreeTX which also supports storage.DeadLetterTX.
+type deadLetterLogTX struct {
+	*storage.MockLogTreeTX
+	storage.DeadLetterTX
+	quarantined []*trillian.LogLeaf
+	reason      string
+}
+
+func (tx *deadLetterLogTX) QuarantineLeaves(ctx context.Context, leaves []*trillian.LogLeaf, reason string, _ time.Time) error {
+	tx.quarantined = append(tx.quarantined, leaves...)
+	tx.reason = reason
+	return nil
+}
+
+func TestQuarantineLeaves(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ts := clock.NewFake(fakeTime)
+	signer := tcrypto.NewSigner(0, fixedGoSigner, crypto.SHA256)
+	const guardWindow = 10 * time.Second
+	logTree := &trillian.Tree{TreeId: 1234, TreeType: trillian.TreeType_LOG}
+	any := gomock.Any()
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		desc       string
+		tree       *trillian.Tree
+		leaves     []*trillian.LogLeaf
+		noSupport  bool
+		wantTokens int
+		wantCode   codes.Code
+	}{
+		{desc: "empty-queue", tree: logTree},
+		{desc: "one-leaf", tree: logTree, leaves: []*trillian.LogLeaf{getLeaf42()}, wantTokens: 1},
+		{desc: "unsupported", tree: logTree, noSupport: true, wantCode: codes.Unimplemented},
+		{desc: "preordered", tree: &trillian.Tree{TreeId: 1234, TreeType: trillian.TreeType_PREORDERED_LOG}, wantCode: codes.FailedPrecondition},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			mockTX := storage.NewMockLogTreeTX(ctrl)
+			tx := &deadLetterLogTX{MockLogTreeTX: mockTX}
+			logStorage := &stestonly.FakeLogStorage{TX: tx}
+			if test.noSupport {
+				logStorage.TX = mockTX
+			}
+			if test.tree.TreeType == trillian.TreeType_LOG {
+				if !test.noSupport {
+					mockTX.EXPECT().DequeueLeaves(any, 1, fakeTime.Add(-guardWindow)).Return(test.leaves, nil)
+					mockTX.EXPECT().Commit(any).Return(nil)
+				}
+				mockTX.EXPECT().Close().Return(nil)
+			}
+
+			qm := quota.NewMockManager(ctrl)
+			if test.wantTokens > 0 {
+				qm.EXPECT().PutTokens(any, any, any)
+			}
+
+			sequencer := NewSequencer(rfc6962.DefaultHasher, ts, logStorage, signer, nil /* mf */, qm)
+			n, err := sequencer.QuarantineLeaves(ctx, test.tree, 1, guardWindow, "poison")
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Fatalf("QuarantineLeaves()=%v, want code %v", err, want)
+			}
+			if got, want := n, len(test.leaves); err == nil && got != want {
+				t.Errorf("QuarantineLeaves()=%d, want %d", got, want)
+			}
+			if got, want := len(tx.quarantined), len(test.leaves); err == nil && got != want {
+				t.Errorf("quarantined %d leaves, want %d", got, want)
+			}
+			if len(tx.quarantined) > 0 && tx.reason != "poison" {
+				t.Errorf("quarantine reason=%q, want %q", tx.reason, "poison")
+			}
+		})
+	}
+}
+
+// tamperingLogStorage is a LogStorage whose snapshots return corrupted leaves
+// and tree nodes.
+type tamperingLogStorage struct {
+	storage.LogStorage
+	leafIndex int64
+	nodeID    *tree.NodeID
+}
+
+func (s *tamperingLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	tx, err := s.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return &tamperingLogTX{ReadOnlyLogTreeTX: tx, s: s}, nil
+}
+
+type tamperingLogTX struct {
+	storage.ReadOnlyLogTreeTX
+	s *tamperingLogStorage
+}
+
+func (t *tamperingLogTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	leaves, err := t.ReadOnlyLogTreeTX.GetLeavesByRange(ctx, start, count)
+	for i, leaf := range leaves {
+		if leaf.LeafIndex == t.s.leafIndex {
+			leaf = proto.Clone(leaf).(*trillian.LogLeaf)
+			leaf.LeafValue = []byte("tampered")
+			leaves[i] = leaf
+		}
+	}
+	return leaves, err
+}
+
+func (t *tamperingLogTX) GetMerkleNodes(ctx context.Context, treeRevision int64, ids []tree.NodeID) ([]tree.Node, error) {
+	nodes, err := t.ReadOnlyLogTreeTX.GetMerkleNodes(ctx, treeRevision, ids)
+	for i, node := range nodes {
+		if t.s.nodeID != nil && node.NodeID.Equivalent(*t.s.nodeID) {
+			nodes[i].Hash = []byte("tampered")
+		}
+	}
+	return nodes, err
+}
+
+func TestVerifyRecent(t *testing.T) {
+	ctx := context.Background()
+	// Other tests unregister the handler for the key of stestonly.LogTree,
+	// which CreateTree needs to check the key.
+	keys.RegisterHandler(&keyspb.PrivateKey{}, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		return der.FromProto(pb.(*keyspb.PrivateKey))
+	})
+	defer keys.UnregisterHandler(&keyspb.PrivateKey{})
+
+	ts := memory.NewTreeStorage()
+	ls := memory.NewLogStorage(ts, nil)
+	logTree, err := storage.CreateTree(ctx, memory.NewAdminStorage(ts), stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+
+	hasher := rfc6962.DefaultHasher
+	signer := tcrypto.NewSigner(0, fixedGoSigner, crypto.SHA256)
+	root, err := signer.SignLogRoot(&types.LogRootV1{RootHash: hasher.EmptyRoot(), TimestampNanos: uint64(fakeTime.UnixNano())})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return tx.StoreSignedLogRoot(ctx, root)
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+
+	// Integrate 10 leaves in two batches, so that the tree has several
+	// revisions.
+	const numLeaves = 10
+	leaves := make([]*trillian.LogLeaf, numLeaves)
+	for i := range leaves {
+		value := []byte(fmt.Sprintf("leaf %d", i))
+		leaves[i] = &trillian.LogLeaf{LeafValue: value, LeafIdentityHash: hasher.HashLeaf(value), MerkleLeafHash: hasher.HashLeaf(value)}
+	}
+	if _, err := ls.QueueLeaves(ctx, logTree, leaves, fakeTime.Add(-time.Second)); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	timeSource := clock.NewFake(fakeTime)
+	sequencer := NewSequencer(hasher, timeSource, ls, signer, nil /* mf */, quota.Noop())
+	for _, limit := range []int{6, 4} {
+		timeSource.Advance(time.Second)
+		if n, err := sequencer.IntegrateBatch(ctx, logTree, limit, 0, 0); err != nil || n != limit {
+			t.Fatalf("IntegrateBatch(%d)=%d, %v, want %d, nil", limit, n, err, limit)
+		}
+	}
+
+	leafNode := func(index int64) *tree.NodeID {
+		id, err := tree.NewNodeIDForTreeCoords(0, index, maxTreeDepth)
+		if err != nil {
+			t.Fatalf("NewNodeIDForTreeCoords(): %v", err)
+		}
+		return &id
+	}
+
+	for _, test := range []struct {
+		desc      string
+		window    int64
+		leafIndex int64
+		nodeID    *tree.NodeID
+		want      int
+		wantCode  codes.Code
+	}{
+		{desc: "all", leafIndex: -1, want: numLeaves},
+		{desc: "window", window: 3, leafIndex: -1, want: 3},
+		{desc: "large-window", window: 100, leafIndex: -1, want: numLeaves},
+		{desc: "tampered-leaf", window: 3, leafIndex: 8, wantCode: codes.DataLoss},
+		{desc: "tampered-leaf-outside-window", window: 3, leafIndex: 2, want: 3},
+		{desc: "tampered-node", window: 3, leafIndex: -1, nodeID: leafNode(9), wantCode: codes.DataLoss},
+		{desc: "tampered-compact-range", window: 3, leafIndex: -1, nodeID: leafNode(6), wantCode: codes.DataLoss},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			tampering := &tamperingLogStorage{LogStorage: ls, leafIndex: test.leafIndex, nodeID: test.nodeID}
+			verifier := NewSequencer(hasher, clock.NewFake(fakeTime), tampering, nil /* signer */, nil /* mf */, quota.Noop())
+			n, err := verifier.VerifyRecent(ctx, logTree, test.window)
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Fatalf("VerifyRecent()=%v, want code %v", err, want)
+			}
+			if got, want := n, test.want; got != want {
+				t.Errorf("VerifyRecent()=%d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestAuditAppendOnly(t *testing.T) {
+	ctx := context.Background()
+	// Other tests unregister the handler for the key of stestonly.LogTree,
+	// which CreateTree needs to check the key.
+	keys.RegisterHandler(&keyspb.PrivateKey{}, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		return der.FromProto(pb.(*keyspb.PrivateKey))
+	})
+	defer keys.UnregisterHandler(&keyspb.PrivateKey{})
+
+	ts := memory.NewTreeStorage()
+	ls := memory.NewLogStorage(ts, nil)
+	logTree, err := storage.CreateTree(ctx, memory.NewAdminStorage(ts), stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+
+	hasher := rfc6962.DefaultHasher
+	signer := tcrypto.NewSigner(0, fixedGoSigner, crypto.SHA256)
+	root, err := signer.SignLogRoot(&types.LogRootV1{RootHash: hasher.EmptyRoot(), TimestampNanos: uint64(fakeTime.UnixNano())})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return tx.StoreSignedLogRoot(ctx, root)
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+	const numLeaves = 10
+	leaves := make([]*trillian.LogLeaf, numLeaves)
+	for i := range leaves {
+		value := []byte(fmt.Sprintf("leaf %d", i))
+		leaves[i] = &trillian.LogLeaf{LeafValue: value, LeafIdentityHash: hasher.HashLeaf(value), MerkleLeafHash: hasher.HashLeaf(value)}
+	}
+	if _, err := ls.QueueLeaves(ctx, logTree, leaves, fakeTime.Add(-time.Second)); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	timeSource := clock.NewFake(fakeTime)
+	sequencer := NewSequencer(hasher, timeSource, ls, signer, nil /* mf */, quota.Noop())
+	integrate := func(limit int) {
+		t.Helper()
+		timeSource.Advance(time.Second)
+		if n, err := sequencer.IntegrateBatch(ctx, logTree, limit, 0, 0); err != nil || n != limit {
+			t.Fatalf("IntegrateBatch(%d)=%d, %v, want %d, nil", limit, n, err, limit)
+		}
+	}
+
+	audit := func(desc string, s storage.LogStorage, prev *auditedLog, wantLeaves int, wantCode codes.Code) *auditedLog {
+		t.Helper()
+		auditor := NewSequencer(hasher, clock.NewFake(fakeTime), s, nil /* signer */, nil /* mf */, quota.Noop())
+		next, n, err := auditor.auditAppendOnly(ctx, logTree, prev)
+		if got, want := status.Code(err), wantCode; got != want {
+			t.Fatalf("%s: auditAppendOnly()=%v, want code %v", desc, err, want)
+		}
+		if got, want := n, wantLeaves; got != want {
+			t.Errorf("%s: auditAppendOnly()=%d leaves, want %d", desc, got, want)
+		}
+		if err != nil && next != prev {
+			t.Errorf("%s: auditAppendOnly() advanced the audited state despite failing", desc)
+		}
+		return next
+	}
+
+	// Audits start from the empty tree, and follow it as it grows.
+	state := audit("empty", ls, nil, 0, codes.OK)
+	integrate(6)
+	state = audit("first-batch", ls, state, 6, codes.OK)
+	state = audit("no-change", ls, state, 0, codes.OK)
+	integrate(4)
+	tampered := &tamperingLogStorage{LogStorage: ls, leafIndex: 8}
+	state = audit("tampered-new-leaf", tampered, state, 0, codes.DataLoss)
+	// Old leaves aren't read again.
+	tampered = &tamperingLogStorage{LogStorage: ls, leafIndex: 2}
+	audited := audit("tampered-old-leaf", tampered, state, 4, codes.OK)
+	if got, want := audited.root.TreeSize, uint64(numLeaves); got != want {
+		t.Errorf("audited tree size %d, want %d", got, want)
+	}
+
+	// A tree which shrank, or doesn't extend the audited one, is reported.
+	bigger := &auditedLog{root: types.LogRootV1{TreeSize: numLeaves + 1, Revision: 100}, cr: state.cr}
+	audit("shrank", ls, bigger, 0, codes.DataLoss)
+	fact := compact.RangeFactory{Hash: hasher.HashChildren}
+	forkedCR := fact.NewEmptyRange(0)
+	for i := 0; i < 6; i++ {
+		if err := forkedCR.Append(hasher.HashLeaf([]byte(fmt.Sprintf("other leaf %d", i))), nil); err != nil {
+			t.Fatalf("Append(): %v", err)
+		}
+	}
+	forked := &auditedLog{root: state.root, cr: forkedCR}
+	audit("forked", ls, forked, 0, codes.DataLoss)
+
+	// The stored compact range is checked when audits start.
+	tampered = &tamperingLogStorage{LogStorage: ls, leafIndex: -1, nodeID: func() *tree.NodeID {
+		id, err := tree.NewNodeIDForTreeCoords(1, 4, maxTreeDepth)
+		if err != nil {
+			t.Fatalf("NewNodeIDForTreeCoords(): %v", err)
+		}
+		return &id
+	}()}
+	audit("tampered-compact-range", tampered, nil, 0, codes.DataLoss)
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/treebatch"
+)
+
+var (
+	vacuumOnce       sync.Once
+	vacuumReclaimed  monitoring.Counter
+	vacuumFailures   monitoring.Counter
+	vacuumRunSeconds monitoring.Histogram
+
+	vacuumOpts = trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
+)
+
+func createVacuumMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	vacuumReclaimed = mf.NewCounter("subtree_vacuum_reclaimed_rows", "Number of superseded subtree revisions deleted from storage", logIDLabel)
+	vacuumFailures = mf.NewCounter("subtree_vacuum_failures", "Number of times vacuuming the subtrees of a log failed", logIDLabel)
+	vacuumRunSeconds = mf.NewHistogram("subtree_vacuum_run_seconds", "Time taken to vacuum the subtrees of all logs held, in seconds")
+}
+
+// SubtreeVacuumOptions configures a SubtreeVacuum.
+type SubtreeVacuumOptions struct {
+	// KeepRevisions is the number of revisions before the latest one of each
+	// log which reads are still served at, e.g. by transactions which started
+	// before the latest root was written. Subtree revisions which only these
+	// revisions need are kept.
+	KeepRevisions int64
+	// BatchSize is the maximum number of subtree revisions deleted per
+	// transaction.
+	BatchSize int
+	// MaxBatches is the maximum number of batches deleted per log in each run,
+	// see treebatch.Runner. Zero means no limit.
+	MaxBatches int
+}
+
+// SubtreeVacuum deletes old revisions of the subtrees of logs. Each sequencing
+// pass writes new revisions of the subtrees it updates, but reads are only
+// served at the latest revision of a log, so older revisions of a subtree are
+// no longer needed once it has a revision which reads at all recent revisions
+// see. This is only supported by storage whose log transactions implement
+// storage.SubtreeVacuumTX.
+type SubtreeVacuum struct {
+	registry extension.Registry
+	opts     SubtreeVacuumOptions
+	runner   treebatch.Runner
+	// logIDs returns the IDs of the logs to vacuum.
+	logIDs func() []int64
+}
+
+// NewSubtreeVacuum returns a SubtreeVacuum for the logs returned by logIDs,
+// e.g. those an OperationManager is master for, see HeldLogIDs.
+func NewSubtreeVacuum(registry extension.Registry, opts SubtreeVacuumOptions, logIDs func() []int64) *SubtreeVacuum {
+	vacuumOnce.Do(func() {
+		createVacuumMetrics(registry.MetricFactory)
+	})
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1
+	}
+	return &SubtreeVacuum{
+		registry: registry,
+		opts:     opts,
+		runner: treebatch.Runner{
+			Name:       "vacuum log",
+			BatchSize:  opts.BatchSize,
+			MaxBatches: opts.MaxBatches,
+			Failures:   vacuumFailures,
+		},
+		logIDs: logIDs,
+	}
+}
+
+// Run vacuums the logs every interval until ctx is canceled.
+func (v *SubtreeVacuum) Run(ctx context.Context, interval time.Duration) {
+	for {
+		if err := clock.SleepContext(ctx, interval); err != nil {
+			return
+		}
+		start := time.Now()
+		if n, err := v.RunOnce(ctx); err != nil {
+			glog.Errorf("SubtreeVacuum: %v", err)
+		} else if n > 0 {
+			glog.Infof("SubtreeVacuum: deleted %d subtree revisions", n)
+		}
+		vacuumRunSeconds.Observe(time.Since(start).Seconds())
+	}
+}
+
+// RunOnce vacuums each of the logs once, and returns the number of subtree
+// revisions deleted. It carries on past failures, and returns the first one.
+func (v *SubtreeVacuum) RunOnce(ctx context.Context) (int, error) {
+	return v.runner.Run(ctx, v.logIDs(), v.vacuumLog)
+}
+
+// vacuumLog returns a function which deletes a batch of the superseded subtree
+// revisions of the log in its own transaction.
+func (v *SubtreeVacuum) vacuumLog(ctx context.Context, logID int64) (treebatch.BatchFunc, error) {
+	tree, err := trees.GetTree(ctx, v.registry.AdminStorage, logID, vacuumOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving log: %v", err)
+	}
+	ctx = trees.NewContext(ctx, tree)
+	label := strconv.FormatInt(logID, 10)
+
+	return func() (int, error) {
+		var deleted int
+		err := v.registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			vtx, ok := tx.(storage.SubtreeVacuumTX)
+			if !ok {
+				return treebatch.ErrUnsupported
+			}
+			rev, err := tx.ReadRevision(ctx)
+			if err != nil {
+				return err
+			}
+			horizon := rev - v.opts.KeepRevisions
+			if horizon <= 0 {
+				return nil
+			}
+			deleted, err = vtx.VacuumSubtrees(ctx, horizon, v.opts.BatchSize)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		vacuumReclaimed.Add(float64(deleted), label)
+		return deleted, nil
+	}, nil
+}
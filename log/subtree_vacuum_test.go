@@ -0,0 +1,122 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+// vacuumingTX is a LogTreeTX which has a number of superseded subtree
+// revisions to vacuum, and records the horizons it is vacuumed at.
+type vacuumingTX struct {
+	*storage.MockLogTreeTX
+	superseded int
+	horizons   []int64
+}
+
+func (t *vacuumingTX) VacuumSubtrees(ctx context.Context, horizon int64, limit int) (int, error) {
+	t.horizons = append(t.horizons, horizon)
+	n := limit
+	if t.superseded < n {
+		n = t.superseded
+	}
+	t.superseded -= n
+	return n, nil
+}
+
+func TestSubtreeVacuum(t *testing.T) {
+	ctx := context.Background()
+	logID := stestonly.LogTree.TreeId
+
+	for _, test := range []struct {
+		desc         string
+		opts         SubtreeVacuumOptions
+		unsupported  bool
+		wantDeleted  int
+		wantHorizons []int64
+	}{
+		{
+			desc:         "all-batches",
+			opts:         SubtreeVacuumOptions{KeepRevisions: 1000, BatchSize: 10},
+			wantDeleted:  25,
+			wantHorizons: []int64{500, 500, 500},
+		},
+		{
+			desc:         "max-batches",
+			opts:         SubtreeVacuumOptions{KeepRevisions: 100, BatchSize: 10, MaxBatches: 2},
+			wantDeleted:  20,
+			wantHorizons: []int64{1400, 1400},
+		},
+		{
+			desc: "young-log",
+			opts: SubtreeVacuumOptions{KeepRevisions: 1500, BatchSize: 10},
+		},
+		{
+			desc:        "unsupported",
+			opts:        SubtreeVacuumOptions{KeepRevisions: 100, BatchSize: 10},
+			unsupported: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockTX := storage.NewMockLogTreeTX(ctrl)
+			mockTX.EXPECT().ReadRevision(gomock.Any()).AnyTimes().Return(int64(1500), nil)
+			vtx := &vacuumingTX{MockLogTreeTX: mockTX, superseded: 25}
+			var tx storage.LogTreeTX = vtx
+			if test.unsupported {
+				tx = mockTX
+			}
+			fakeStorage := storage.NewMockLogStorage(ctrl)
+			fakeStorage.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+				func(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+					return f(ctx, tx)
+				})
+			mockAdminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			mockAdminTX.EXPECT().GetTree(gomock.Any(), logID).AnyTimes().Return(stestonly.LogTree, nil)
+			mockAdminTX.EXPECT().Commit().AnyTimes().Return(nil)
+			mockAdminTX.EXPECT().Close().AnyTimes().Return(nil)
+			registry := extension.Registry{
+				LogStorage:   fakeStorage,
+				AdminStorage: &stestonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{mockAdminTX}},
+			}
+
+			v := NewSubtreeVacuum(registry, test.opts, func() []int64 { return []int64{logID} })
+			deleted, err := v.RunOnce(ctx)
+			if err != nil {
+				t.Fatalf("RunOnce(): %v", err)
+			}
+			if deleted != test.wantDeleted {
+				t.Errorf("RunOnce()=%d, want %d", deleted, test.wantDeleted)
+			}
+			if got, want := len(vtx.horizons), len(test.wantHorizons); got != want {
+				t.Fatalf("vacuumed %d times, want %d", got, want)
+			}
+			for i, h := range vtx.horizons {
+				if h != test.wantHorizons[i] {
+					t.Errorf("vacuum %d at horizon %d, want %d", i, h, test.wantHorizons[i])
+				}
+			}
+		})
+	}
+}
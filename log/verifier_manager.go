@@ -0,0 +1,69 @@
+// Copyright 2016 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/trees"
+)
+
+// VerifierManager provides verification operations for a collection of Logs.
+// It re-runs the sequencing of the most recent leaves of each Log without
+// writing anything, and checks the result against the stored tree, as a
+// continuous self-audit for silent storage corruption.
+type VerifierManager struct {
+	registry extension.Registry
+	window   int64
+}
+
+var verifyOpts = trees.NewGetOpts(trees.Query, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
+
+// NewVerifierManager creates a new VerifierManager instance, which verifies
+// up to window of the most recent leaves of each Log per pass (all of them if
+// window is 0).
+func NewVerifierManager(registry extension.Registry, window int64) *VerifierManager {
+	return &VerifierManager{
+		registry: registry,
+		window:   window,
+	}
+}
+
+// ExecutePass performs verification for the specified Log. See
+// Sequencer.VerifyRecent.
+func (v *VerifierManager) ExecutePass(ctx context.Context, logID int64, info *OperationInfo) (int, error) {
+	tree, err := trees.GetTree(ctx, v.registry.AdminStorage, logID, verifyOpts)
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving log %v: %v", logID, err)
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return 0, fmt.Errorf("error getting hasher for log %v: %v", logID, err)
+	}
+
+	// Nothing is signed, so no signer is needed.
+	sequencer := NewSequencer(hasher, info.TimeSource, v.registry.LogStorage, nil, v.registry.MetricFactory, v.registry.QuotaManager)
+	leaves, err := sequencer.VerifyRecent(ctx, tree, v.window)
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify %v: %v", logID, err)
+	}
+	return leaves, nil
+}
@@ -0,0 +1,269 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package loader populates fresh Trillian maps in bulk, by building their
+// sparse Merkle trees bottom-up from sorted leaves, and writing the leaves and
+// tiles directly through a storage.MapBulkWriter. This is much faster than
+// SetLeaves for the initial load of a large map, as no tiles are read, and
+// each tile is written once.
+package loader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+const (
+	// splitHeight is the height of the top shard of the map tree, as in the
+	// map server. Leaves are loaded one shard below it at a time.
+	splitHeight = 8
+	// loadedRevision is the revision of the map which the leaves are loaded
+	// at.
+	loadedRevision = 1
+
+	defaultLeafBatchSize = 1000
+	defaultTileBatchSize = 100
+)
+
+// Options holds optional settings of a Loader.
+type Options struct {
+	// LeafBatchSize is the number of leaves written by each BulkWriteLeaves
+	// call. Defaults to 1000.
+	LeafBatchSize int
+	// TileBatchSize is the number of tiles written by each BulkWriteTiles
+	// call. Defaults to 100.
+	TileBatchSize int
+	// Metadata is stored in the root of the loaded revision.
+	Metadata []byte
+	// HashWorkers is the number of goroutines hashing each shard, see
+	// smt.NewParallelWriter. Below 2, shards are hashed on the calling
+	// goroutine.
+	HashWorkers int
+}
+
+// Loader loads the leaves of a fresh map, i.e. one which only has its empty
+// revision 0, as its revision 1. Leaves must be added in strictly increasing
+// order of their indices, so that the tree can be built one shard at a time,
+// and the map is never held in memory as a whole. A Loader is not safe for
+// concurrent use.
+type Loader struct {
+	tree   *trillian.Tree
+	w      storage.MapBulkWriter
+	hasher hashers.MapHasher
+	layout *tree.Layout
+	signer *tcrypto.Signer
+	writer *smt.Writer
+	opts   Options
+
+	last   []byte              // The index of the last leaf added.
+	leaves []*trillian.MapLeaf // Leaves not written yet.
+	shard  []smt.Node          // Leaf nodes of the current shard.
+	roots  []smt.Node          // Roots of the shards built so far.
+	top    []smt.Node          // Updates of tiles above the shards.
+	count  int64               // The number of leaves added.
+}
+
+// New returns a Loader of the given map, which must be fresh, and stored in
+// a storage which implements storage.MapBulkWriter. Anything left at revision
+// 1 by an earlier load which failed is removed. Roots are signed with signer.
+func New(ctx context.Context, ms storage.MapStorage, tree *trillian.Tree, signer *tcrypto.Signer, opts Options) (*Loader, error) {
+	w, ok := ms.(storage.MapBulkWriter)
+	if !ok {
+		return nil, errors.New("map storage does not support bulk writes")
+	}
+	hasher, err := registry.NewMapHasherForTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := ms.Layout(tree)
+	if err != nil {
+		return nil, err
+	}
+	if opts.LeafBatchSize <= 0 {
+		opts.LeafBatchSize = defaultLeafBatchSize
+	}
+	if opts.TileBatchSize <= 0 {
+		opts.TileBatchSize = defaultTileBatchSize
+	}
+	if err := w.BeginBulkWrite(ctx, tree); err != nil {
+		return nil, err
+	}
+	return &Loader{
+		tree:   tree,
+		w:      w,
+		hasher: hasher,
+		layout: layout,
+		signer: signer,
+		writer: smt.NewParallelWriter(tree.TreeId, hasher, uint(hasher.BitLen()), splitHeight, opts.HashWorkers),
+		opts:   opts,
+	}, nil
+}
+
+// Add adds a leaf to the map. Its index must be above that of the previous
+// leaf added. Its hash is set from its value, as by SetLeaves. Leaves with
+// neither a value nor extra data are skipped, as there is nothing to delete in
+// a fresh map.
+func (l *Loader) Add(ctx context.Context, leaf *trillian.MapLeaf) error {
+	if got, want := len(leaf.Index), l.hasher.Size(); got != want {
+		return fmt.Errorf("leaf index %x has %d bytes, want %d", leaf.Index, got, want)
+	}
+	if l.last != nil && bytes.Compare(leaf.Index, l.last) <= 0 {
+		return fmt.Errorf("leaf index %x not above the previous index %x, leaves must be sorted by index", leaf.Index, l.last)
+	}
+	l.last = leaf.Index
+	if len(leaf.LeafValue) == 0 && len(leaf.ExtraData) == 0 {
+		return nil
+	}
+
+	leaf.LeafHash = l.hasher.HashLeaf(l.tree.TreeId, leaf.Index, leaf.LeafValue)
+	node := smt.Node{ID: tree.NewNodeID2(string(leaf.Index), uint(l.hasher.BitLen())), Hash: leaf.LeafHash}
+	if len(l.shard) > 0 && l.shard[0].ID.Prefix(splitHeight) != node.ID.Prefix(splitHeight) {
+		if err := l.buildShard(ctx); err != nil {
+			return err
+		}
+	}
+	l.shard = append(l.shard, node)
+	l.count++
+
+	l.leaves = append(l.leaves, leaf)
+	if len(l.leaves) >= l.opts.LeafBatchSize {
+		return l.flushLeaves(ctx)
+	}
+	return nil
+}
+
+// Finish writes the rest of the map, and stores and returns its root, which
+// makes the loaded revision visible to readers. It fails if no leaves were
+// added.
+func (l *Loader) Finish(ctx context.Context) (*trillian.SignedMapRoot, error) {
+	if l.count == 0 {
+		return nil, errors.New("no leaves to load")
+	}
+	if err := l.flushLeaves(ctx); err != nil {
+		return nil, err
+	}
+	if err := l.buildShard(ctx); err != nil {
+		return nil, err
+	}
+	root, err := l.writer.Write(ctx, l.roots, &bulkAccessor{l: l, top: true})
+	if err != nil {
+		return nil, err
+	}
+	smr, err := l.signer.SignMapRoot(&types.MapRootV1{
+		RootHash:       root.Hash,
+		TimestampNanos: uint64(time.Now().UnixNano()),
+		Revision:       loadedRevision,
+		Metadata:       l.opts.Metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SignMapRoot(): %v", err)
+	}
+	if err := l.w.CommitBulkWrite(ctx, l.tree, smr); err != nil {
+		return nil, err
+	}
+	return smr, nil
+}
+
+// Count returns the number of leaves added to the map so far, not counting
+// those which were skipped.
+func (l *Loader) Count() int64 {
+	return l.count
+}
+
+// flushLeaves writes the leaves which haven't been written yet.
+func (l *Loader) flushLeaves(ctx context.Context) error {
+	if len(l.leaves) == 0 {
+		return nil
+	}
+	if err := l.w.BulkWriteLeaves(ctx, l.tree, l.leaves); err != nil {
+		return err
+	}
+	l.leaves = nil
+	return nil
+}
+
+// buildShard builds the tree of the current shard, and writes its tiles.
+func (l *Loader) buildShard(ctx context.Context) error {
+	if len(l.shard) == 0 {
+		return nil
+	}
+	root, err := l.writer.Write(ctx, l.shard, &bulkAccessor{l: l})
+	if err != nil {
+		return err
+	}
+	l.roots = append(l.roots, root)
+	l.shard = nil
+	return nil
+}
+
+// bulkAccessor is the smt.NodeBatchAccessor of a fresh map, which has no
+// nodes to read, and whose nodes are written as tiles built from scratch.
+//
+// Tiles rooted above the shards, such as the root tile if it is higher than
+// the top shard, are updated by multiple shards, so their updates are only
+// built into tiles with those of the top shard.
+type bulkAccessor struct {
+	l   *Loader
+	top bool // Whether this accessor writes the top shard.
+}
+
+// Get returns no hashes, as all the nodes of a fresh map are empty.
+func (a *bulkAccessor) Get(ctx context.Context, ids []tree.NodeID2) (map[tree.NodeID2][]byte, error) {
+	return nil, nil
+}
+
+// Set builds the tiles holding the given nodes, and writes them in batches.
+func (a *bulkAccessor) Set(ctx context.Context, nodes []smt.Node) error {
+	m := smt.NewTileSetMutation(smt.NewTileSet(a.l.tree.TreeId, a.l.hasher, a.l.layout))
+	for _, n := range nodes {
+		if a.l.layout.GetTileRootID(n.ID).BitLen() < splitHeight {
+			a.l.top = append(a.l.top, n)
+		} else {
+			m.Set(n.ID, n.Hash)
+		}
+	}
+	if a.top {
+		for _, n := range a.l.top {
+			m.Set(n.ID, n.Hash)
+		}
+	}
+	tiles, err := m.Build()
+	if err != nil {
+		return err
+	}
+	for len(tiles) > 0 {
+		batch := tiles
+		if len(batch) > a.l.opts.TileBatchSize {
+			batch = batch[:a.l.opts.TileBatchSize]
+		}
+		if err := a.l.w.BulkWriteTiles(ctx, a.l.tree, batch); err != nil {
+			return err
+		}
+		tiles = tiles[len(batch):]
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loader
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+
+	tcrypto "github.com/google/trillian/crypto"
+
+	_ "github.com/google/trillian/merkle/coniks"    // Register the CONIKS hasher.
+	_ "github.com/google/trillian/merkle/maphasher" // Register the test hasher.
+)
+
+// fakeBulkStorage is a MapStorage which keeps what is bulk written to it.
+type fakeBulkStorage struct {
+	storage.MapStorage
+	layout *tree.Layout
+	began  bool
+	leaves []*trillian.MapLeaf
+	tiles  map[tree.NodeID2]smt.Tile
+	root   *trillian.SignedMapRoot
+}
+
+func (s *fakeBulkStorage) Layout(*trillian.Tree) (*tree.Layout, error) {
+	return s.layout, nil
+}
+
+func (s *fakeBulkStorage) BeginBulkWrite(ctx context.Context, tree *trillian.Tree) error {
+	s.began = true
+	return nil
+}
+
+func (s *fakeBulkStorage) BulkWriteLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf) error {
+	s.leaves = append(s.leaves, leaves...)
+	return nil
+}
+
+func (s *fakeBulkStorage) BulkWriteTiles(ctx context.Context, tree *trillian.Tree, tiles []smt.Tile) error {
+	for _, tile := range tiles {
+		if _, ok := s.tiles[tile.ID]; ok {
+			return fmt.Errorf("tile %v written twice", tile.ID)
+		}
+		s.tiles[tile.ID] = tile
+	}
+	return nil
+}
+
+func (s *fakeBulkStorage) CommitBulkWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	s.root = root
+	return nil
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	var indices [][]byte
+	for i := 0; i < 2000; i++ {
+		h := sha256.Sum256([]byte(fmt.Sprintf("key %d", i)))
+		indices = append(indices, h[:])
+	}
+	sort.Slice(indices, func(i, j int) bool { return bytes.Compare(indices[i], indices[j]) < 0 })
+
+	for _, test := range []struct {
+		desc     string
+		strategy trillian.HashStrategy
+		strata   []int
+	}{
+		{desc: "default-layout", strategy: trillian.HashStrategy_TEST_MAP_HASHER, strata: []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 176}},
+		{desc: "coniks", strategy: trillian.HashStrategy_CONIKS_SHA256, strata: []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 176}},
+		// The root tile spans the top shard and the shards below it.
+		{desc: "tall-root-tile", strategy: trillian.HashStrategy_TEST_MAP_HASHER, strata: []int{16, 16, 224}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			mapTree := &trillian.Tree{TreeId: 12345, TreeType: trillian.TreeType_MAP, HashStrategy: test.strategy}
+			hasher, err := registry.NewMapHasher(test.strategy)
+			if err != nil {
+				t.Fatalf("NewMapHasher(): %v", err)
+			}
+			s := &fakeBulkStorage{layout: tree.NewLayout(test.strata), tiles: make(map[tree.NodeID2]smt.Tile)}
+			signer := tcrypto.NewSigner(mapTree.TreeId, testonly.NewSignerWithFixedSig(nil, []byte("sig")), crypto.SHA256)
+			l, err := New(ctx, s, mapTree, signer, Options{LeafBatchSize: 300, Metadata: []byte("meta")})
+			if err != nil {
+				t.Fatalf("New(): %v", err)
+			}
+			if !s.began {
+				t.Error("New() didn't begin the bulk write")
+			}
+
+			var want []*merkle.HStar2LeafHash
+			for i, index := range indices {
+				leaf := &trillian.MapLeaf{Index: index, LeafValue: []byte(fmt.Sprintf("value %d", i))}
+				if i%10 == 0 {
+					leaf.LeafValue = nil // Skipped.
+				} else {
+					want = append(want, &merkle.HStar2LeafHash{Index: new(big.Int).SetBytes(index), LeafHash: hasher.HashLeaf(mapTree.TreeId, index, leaf.LeafValue)})
+				}
+				if err := l.Add(ctx, leaf); err != nil {
+					t.Fatalf("Add(%d): %v", i, err)
+				}
+			}
+			smr, err := l.Finish(ctx)
+			if err != nil {
+				t.Fatalf("Finish(): %v", err)
+			}
+			if got := l.Count(); got != int64(len(want)) {
+				t.Errorf("Count()=%d, want %d", got, len(want))
+			}
+			if got := len(s.leaves); got != len(want) {
+				t.Errorf("wrote %d leaves, want %d", got, len(want))
+			}
+			if s.root != smr {
+				t.Error("Finish() didn't commit the root it returned")
+			}
+
+			hs := merkle.NewHStar2(mapTree.TreeId, hasher)
+			wantRoot, err := hs.HStar2Root(hasher.BitLen(), want)
+			if err != nil {
+				t.Fatalf("HStar2Root(): %v", err)
+			}
+			var root types.MapRootV1
+			if err := root.UnmarshalBinary(smr.MapRoot); err != nil {
+				t.Fatalf("UnmarshalBinary(): %v", err)
+			}
+			if !bytes.Equal(root.RootHash, wantRoot) {
+				t.Errorf("root hash %x, want %x", root.RootHash, wantRoot)
+			}
+			if root.Revision != 1 || string(root.Metadata) != "meta" {
+				t.Errorf("root of revision %d with metadata %q, want 1 and %q", root.Revision, root.Metadata, "meta")
+			}
+
+			// The tiles written hold the whole tree. Tile sets don't hold the
+			// hashes of tile roots, so the root hash is that of its children.
+			ts := smt.NewTileSet(mapTree.TreeId, hasher, s.layout)
+			for _, tile := range s.tiles {
+				if err := ts.Add(tile); err != nil {
+					t.Fatalf("Add(%v): %v", tile.ID, err)
+				}
+			}
+			hashes := ts.Hashes()
+			left, right := hashes[tree.NewNodeID2("\x00", 1)], hashes[tree.NewNodeID2("\x80", 1)]
+			if got := hasher.HashChildren(left, right); !bytes.Equal(got, wantRoot) {
+				t.Errorf("root hash of the tiles %x, want %x", got, wantRoot)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	ctx := context.Background()
+	mapTree := &trillian.Tree{TreeId: 12345, TreeType: trillian.TreeType_MAP, HashStrategy: trillian.HashStrategy_TEST_MAP_HASHER}
+	signer := tcrypto.NewSigner(mapTree.TreeId, testonly.NewSignerWithFixedSig(nil, []byte("sig")), crypto.SHA256)
+	newLoader := func() *Loader {
+		s := &fakeBulkStorage{layout: tree.NewLayout([]int{8, 248}), tiles: make(map[tree.NodeID2]smt.Tile)}
+		l, err := New(ctx, s, mapTree, signer, Options{})
+		if err != nil {
+			t.Fatalf("New(): %v", err)
+		}
+		return l
+	}
+	index := func(b byte) []byte { return bytes.Repeat([]byte{b}, 32) }
+
+	if _, err := New(ctx, struct{ storage.MapStorage }{}, mapTree, signer, Options{}); err == nil {
+		t.Error("New() without bulk writes succeeded")
+	}
+	if _, err := newLoader().Finish(ctx); err == nil {
+		t.Error("Finish() without leaves succeeded")
+	}
+	if err := newLoader().Add(ctx, &trillian.MapLeaf{Index: []byte("short"), LeafValue: []byte("v")}); err == nil {
+		t.Error("Add() of a short index succeeded")
+	}
+	for _, test := range []struct {
+		desc   string
+		second byte
+	}{
+		{desc: "unsorted", second: 1},
+		{desc: "duplicate", second: 2},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			l := newLoader()
+			if err := l.Add(ctx, &trillian.MapLeaf{Index: index(2), LeafValue: []byte("v")}); err != nil {
+				t.Fatalf("Add(): %v", err)
+			}
+			if err := l.Add(ctx, &trillian.MapLeaf{Index: index(test.second), LeafValue: []byte("v")}); err == nil {
+				t.Error("Add() succeeded")
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snapshot exports single revisions of Trillian maps, i.e. their
+// signed root, tiles and leaves, into a portable format, and imports them into
+// maps in other storage, such as when migrating maps from MySQL to Cloud
+// Spanner. Exporting needs map transactions which implement
+// storage.MapLeafScanner and storage.MapTileScanner, and importing needs a
+// storage which implements storage.MapSnapshotWriter.
+package snapshot
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/maps"
+	"github.com/google/trillian/maps/snapshot/snapshotpb"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+)
+
+const (
+	// maxRecordSize is the maximum size of a record read, which guards
+	// against allocating memory for the lengths read from corrupt files.
+	maxRecordSize = 256 << 20
+
+	defaultLeafBatchSize = 1000
+	defaultTileBatchSize = 100
+)
+
+// Export writes a snapshot of the given revision of the map to w, or of its
+// latest revision if revision is negative, and returns the footer written.
+// The leaves and tiles are read in batches, so the map is never held in
+// memory as a whole. Tiles emptied by deletions are skipped.
+func Export(ctx context.Context, ms storage.MapStorage, mapTree *trillian.Tree, revision int64, w io.Writer) (*snapshotpb.Footer, error) {
+	layout, err := ms.Layout(mapTree)
+	if err != nil {
+		return nil, err
+	}
+	var tx storage.ReadOnlyMapTreeTX
+	if revision < 0 {
+		tx, err = ms.SnapshotForTree(ctx, mapTree)
+	} else {
+		tx, err = ms.SnapshotAtRevision(ctx, mapTree, revision)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	leafScanner, ok := tx.(storage.MapLeafScanner)
+	if !ok {
+		return nil, fmt.Errorf("map storage %T can't scan leaves", tx)
+	}
+	tileScanner, ok := tx.(storage.MapTileScanner)
+	if !ok {
+		return nil, fmt.Errorf("map storage %T can't scan tiles", tx)
+	}
+	smr, err := tx.LatestSignedMapRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root types.MapRootV1
+	if err := root.UnmarshalBinary(smr.MapRoot); err != nil {
+		return nil, err
+	}
+	rev := int64(root.Revision)
+
+	bw := bufio.NewWriter(w)
+	header := &snapshotpb.Header{
+		TreeId:       mapTree.TreeId,
+		HashStrategy: mapTree.HashStrategy,
+		Strata:       strata(layout),
+		MapRoot:      smr,
+	}
+	if err := writeRecord(bw, &snapshotpb.Record{Record: &snapshotpb.Record_Header{Header: header}}); err != nil {
+		return nil, err
+	}
+	footer := &snapshotpb.Footer{}
+	err = tileScanner.ScanTiles(ctx, rev, func(tile smt.Tile) error {
+		if len(tile.Leaves) == 0 {
+			return nil
+		}
+		pb, err := tileToProto(tile)
+		if err != nil {
+			return err
+		}
+		footer.TileCount++
+		return writeRecord(bw, &snapshotpb.Record{Record: &snapshotpb.Record_Tile{Tile: pb}})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export tiles: %v", err)
+	}
+	err = leafScanner.ScanLeaves(ctx, rev, func(leaf *trillian.MapLeaf) error {
+		footer.LeafCount++
+		return writeRecord(bw, &snapshotpb.Record{Record: &snapshotpb.Record_Leaf{Leaf: leaf}})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export leaves: %v", err)
+	}
+	if err := writeRecord(bw, &snapshotpb.Record{Record: &snapshotpb.Record_Footer{Footer: footer}}); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return footer, nil
+}
+
+// Options holds optional settings of Import.
+type Options struct {
+	// LeafBatchSize is the number of leaves written by each
+	// SnapshotWriteLeaves call. Defaults to 1000.
+	LeafBatchSize int
+	// TileBatchSize is the number of tiles written by each
+	// SnapshotWriteTiles call. Defaults to 100.
+	TileBatchSize int
+}
+
+// Import reads a snapshot from r, and writes its revision into the given map,
+// which must have the ID, hash strategy, layout and public key of the exported
+// map, and no roots other than that of revision 0. The root of the snapshot is
+// verified before anything is written, and stored once all of its leaves and
+// tiles are, so an import which fails can be run again. It returns the root
+// imported.
+func Import(ctx context.Context, ms storage.MapStorage, mapTree *trillian.Tree, r io.Reader, opts Options) (*types.MapRootV1, error) {
+	w, ok := ms.(storage.MapSnapshotWriter)
+	if !ok {
+		return nil, errors.New("map storage does not support importing snapshots")
+	}
+	if opts.LeafBatchSize <= 0 {
+		opts.LeafBatchSize = defaultLeafBatchSize
+	}
+	if opts.TileBatchSize <= 0 {
+		opts.TileBatchSize = defaultTileBatchSize
+	}
+	br := bufio.NewReader(r)
+	rec, err := readRecord(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %v", err)
+	}
+	header := rec.GetHeader()
+	if header == nil {
+		return nil, errors.New("snapshot doesn't start with a header")
+	}
+	root, err := checkHeader(ms, mapTree, header)
+	if err != nil {
+		return nil, err
+	}
+	rev := int64(root.Revision)
+
+	if err := w.BeginSnapshotWrite(ctx, mapTree, rev); err != nil {
+		return nil, err
+	}
+	var leaves []*trillian.MapLeaf
+	var tiles []smt.Tile
+	flushLeaves := func() error {
+		if len(leaves) == 0 {
+			return nil
+		}
+		err := w.SnapshotWriteLeaves(ctx, mapTree, rev, leaves)
+		leaves = nil
+		return err
+	}
+	flushTiles := func() error {
+		if len(tiles) == 0 {
+			return nil
+		}
+		err := w.SnapshotWriteTiles(ctx, mapTree, rev, tiles)
+		tiles = nil
+		return err
+	}
+	var footer *snapshotpb.Footer
+	var leafCount, tileCount int64
+	for footer == nil {
+		rec, err := readRecord(br)
+		if err == io.EOF {
+			return nil, errors.New("snapshot is truncated, it has no footer")
+		} else if err != nil {
+			return nil, err
+		}
+		switch rec := rec.Record.(type) {
+		case *snapshotpb.Record_Tile:
+			var tile smt.Tile
+			if tile, err = tileFromProto(rec.Tile); err != nil {
+				return nil, err
+			}
+			tileCount++
+			if tiles = append(tiles, tile); len(tiles) >= opts.TileBatchSize {
+				err = flushTiles()
+			}
+		case *snapshotpb.Record_Leaf:
+			leafCount++
+			if leaves = append(leaves, rec.Leaf); len(leaves) >= opts.LeafBatchSize {
+				err = flushLeaves()
+			}
+		case *snapshotpb.Record_Footer:
+			footer = rec.Footer
+		default:
+			err = fmt.Errorf("unexpected record %T", rec)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if footer.TileCount != tileCount || footer.LeafCount != leafCount {
+		return nil, fmt.Errorf("snapshot has %d tiles and %d leaves, its footer says %d and %d", tileCount, leafCount, footer.TileCount, footer.LeafCount)
+	}
+	if _, err := readRecord(br); err != io.EOF {
+		return nil, fmt.Errorf("snapshot has records after its footer: %v", err)
+	}
+	if err := flushTiles(); err != nil {
+		return nil, err
+	}
+	if err := flushLeaves(); err != nil {
+		return nil, err
+	}
+	if err := w.CommitSnapshotWrite(ctx, mapTree, header.MapRoot); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// checkHeader checks that the snapshot with the given header can be imported
+// into the map, and returns its verified root.
+func checkHeader(ms storage.MapStorage, mapTree *trillian.Tree, header *snapshotpb.Header) (*types.MapRootV1, error) {
+	if got, want := header.TreeId, mapTree.TreeId; got != want {
+		return nil, fmt.Errorf("snapshot of map %d can't be imported into map %d", got, want)
+	}
+	if got, want := header.HashStrategy, mapTree.HashStrategy; got != want {
+		return nil, fmt.Errorf("snapshot has hash strategy %v, want %v", got, want)
+	}
+	layout, err := ms.Layout(mapTree)
+	if err != nil {
+		return nil, err
+	}
+	if got, want := header.Strata, strata(layout); !equalStrata(got, want) {
+		return nil, fmt.Errorf("snapshot has strata %v, map storage has %v", got, want)
+	}
+	verifier, err := maps.NewRootVerifierFromTree(mapTree)
+	if err != nil {
+		return nil, err
+	}
+	root, err := verifier.VerifySignedMapRoot(header.MapRoot)
+	if err != nil {
+		return nil, fmt.Errorf("root of the snapshot doesn't verify with the key of map %d: %v", mapTree.TreeId, err)
+	}
+	return root, nil
+}
+
+// strata returns the heights of the tiles of the given layout, from the root
+// down.
+func strata(layout *tree.Layout) []int32 {
+	var heights []int32
+	for depth := 0; depth < layout.Height; {
+		h := layout.TileHeight(depth)
+		heights = append(heights, int32(h))
+		depth += h
+	}
+	return heights
+}
+
+func equalStrata(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// tileToProto returns the record of the given tile.
+func tileToProto(tile smt.Tile) (*snapshotpb.Tile, error) {
+	id, err := wholeBytes(tile.ID)
+	if err != nil {
+		return nil, err
+	}
+	pb := &snapshotpb.Tile{Id: id, Leaves: make([]*snapshotpb.TileLeaf, 0, len(tile.Leaves))}
+	for _, node := range tile.Leaves {
+		id, err := wholeBytes(node.ID)
+		if err != nil {
+			return nil, err
+		}
+		pb.Leaves = append(pb.Leaves, &snapshotpb.TileLeaf{Id: id, Hash: node.Hash})
+	}
+	return pb, nil
+}
+
+// wholeBytes returns the path of the given node ID, which must be a whole
+// number of bytes long.
+func wholeBytes(id tree.NodeID2) ([]byte, error) {
+	if bits := id.BitLen(); bits%8 != 0 {
+		return nil, fmt.Errorf("node ID %v is %d bits long, want a multiple of 8", id, bits)
+	}
+	path := []byte(id.FullBytes())
+	if last, bits := id.LastByte(); bits > 0 {
+		path = append(path, last)
+	}
+	return path, nil
+}
+
+// tileFromProto returns the tile of the given record.
+func tileFromProto(pb *snapshotpb.Tile) (smt.Tile, error) {
+	nodes := make([]smt.Node, 0, len(pb.Leaves))
+	for _, leaf := range pb.Leaves {
+		nodes = append(nodes, smt.Node{ID: tree.NewNodeID2(string(leaf.Id), uint(len(leaf.Id))*8), Hash: leaf.Hash})
+	}
+	row, err := smt.NewNodesRow(nodes)
+	if err != nil {
+		return smt.Tile{}, fmt.Errorf("tile %x: %v", pb.Id, err)
+	}
+	return smt.Tile{ID: tree.NewNodeID2(string(pb.Id), uint(len(pb.Id))*8), Leaves: row}, nil
+}
+
+// writeRecord writes the given record to w, preceded by its length.
+func writeRecord(w *bufio.Writer, rec *snapshotpb.Record) error {
+	b, err := proto.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	var size [binary.MaxVarintLen64]byte
+	if _, err := w.Write(size[:binary.PutUvarint(size[:], uint64(len(b)))]); err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// readRecord reads the next record from r. It returns io.EOF if there are no
+// more records, and io.ErrUnexpectedEOF if r ends within one.
+func readRecord(r *bufio.Reader) (*snapshotpb.Record, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("record of %d bytes, want at most %d", size, maxRecordSize)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	var rec snapshotpb.Record
+	if err := proto.Unmarshal(b, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/maps/snapshot/snapshotpb"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// fakeSnapshotStorage is a MapStorage holding a single revision of a map,
+// which can be exported from it, or imported into it.
+type fakeSnapshotStorage struct {
+	storage.MapStorage
+	layout *tree.Layout
+	root   *trillian.SignedMapRoot
+	leaves []*trillian.MapLeaf
+	tiles  []smt.Tile
+	began  int64
+}
+
+func (s *fakeSnapshotStorage) Layout(*trillian.Tree) (*tree.Layout, error) {
+	return s.layout, nil
+}
+
+func (s *fakeSnapshotStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyMapTreeTX, error) {
+	return &fakeSnapshotTX{s: s}, nil
+}
+
+func (s *fakeSnapshotStorage) BeginSnapshotWrite(ctx context.Context, tree *trillian.Tree, revision int64) error {
+	s.began = revision
+	s.leaves, s.tiles = nil, nil
+	return nil
+}
+
+func (s *fakeSnapshotStorage) SnapshotWriteLeaves(ctx context.Context, tree *trillian.Tree, revision int64, leaves []*trillian.MapLeaf) error {
+	if revision != s.began {
+		return errors.New("wrong revision")
+	}
+	s.leaves = append(s.leaves, leaves...)
+	return nil
+}
+
+func (s *fakeSnapshotStorage) SnapshotWriteTiles(ctx context.Context, tree *trillian.Tree, revision int64, tiles []smt.Tile) error {
+	if revision != s.began {
+		return errors.New("wrong revision")
+	}
+	s.tiles = append(s.tiles, tiles...)
+	return nil
+}
+
+func (s *fakeSnapshotStorage) CommitSnapshotWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	s.root = root
+	return nil
+}
+
+type fakeSnapshotTX struct {
+	storage.ReadOnlyMapTreeTX
+	s *fakeSnapshotStorage
+}
+
+func (tx *fakeSnapshotTX) LatestSignedMapRoot(ctx context.Context) (*trillian.SignedMapRoot, error) {
+	return tx.s.root, nil
+}
+
+func (tx *fakeSnapshotTX) ScanLeaves(ctx context.Context, revision int64, fn func(*trillian.MapLeaf) error) error {
+	for _, leaf := range tx.s.leaves {
+		if err := fn(leaf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *fakeSnapshotTX) ScanTiles(ctx context.Context, revision int64, fn func(smt.Tile) error) error {
+	for _, tile := range tx.s.tiles {
+		if err := fn(tile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *fakeSnapshotTX) Commit(ctx context.Context) error { return nil }
+func (tx *fakeSnapshotTX) Close() error                     { return nil }
+
+// newSnapshotMap returns a map, and a storage holding a revision of it.
+func newSnapshotMap(t *testing.T) (*trillian.Tree, *fakeSnapshotStorage) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	pubDER, err := der.MarshalPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("MarshalPublicKey(): %v", err)
+	}
+	mapTree := &trillian.Tree{
+		TreeId:             12345,
+		TreeType:           trillian.TreeType_MAP,
+		HashStrategy:       trillian.HashStrategy_TEST_MAP_HASHER,
+		HashAlgorithm:      sigpb.DigitallySigned_SHA256,
+		SignatureAlgorithm: sigpb.DigitallySigned_ECDSA,
+		PublicKey:          &keyspb.PublicKey{Der: pubDER},
+	}
+	root, err := tcrypto.NewSigner(mapTree.TreeId, key, crypto.SHA256).SignMapRoot(&types.MapRootV1{RootHash: []byte("root"), Revision: 7})
+	if err != nil {
+		t.Fatalf("SignMapRoot(): %v", err)
+	}
+	node := func(id string, hash string) smt.Node {
+		return smt.Node{ID: tree.NewNodeID2(id, uint(len(id))*8), Hash: []byte(hash)}
+	}
+	return mapTree, &fakeSnapshotStorage{
+		layout: tree.NewLayout([]int{8, 248}),
+		root:   root,
+		leaves: []*trillian.MapLeaf{
+			{Index: bytes.Repeat([]byte{1}, 32), LeafHash: []byte("h1"), LeafValue: []byte("v1")},
+			{Index: bytes.Repeat([]byte{2}, 32), LeafHash: []byte("h2"), LeafValue: []byte("v2"), ExtraData: []byte("e2")},
+			{Index: bytes.Repeat([]byte{3}, 32), LeafHash: []byte("h3"), LeafValue: []byte("v3")},
+		},
+		tiles: []smt.Tile{
+			{ID: tree.NewNodeID2("", 0), Leaves: []smt.Node{node("\x01", "a"), node("\x02", "b"), node("\x03", "c")}},
+			{ID: tree.NewNodeID2("\x01", 8), Leaves: []smt.Node{node(string(bytes.Repeat([]byte{1}, 32)), "h1")}},
+			{ID: tree.NewNodeID2("\x04", 8)}, // Emptied, so not exported.
+		},
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	mapTree, src := newSnapshotMap(t)
+	var buf bytes.Buffer
+	footer, err := Export(ctx, src, mapTree, -1, &buf)
+	if err != nil {
+		t.Fatalf("Export(): %v", err)
+	}
+	if got, want := footer.LeafCount, int64(3); got != want {
+		t.Errorf("exported %d leaves, want %d", got, want)
+	}
+	if got, want := footer.TileCount, int64(2); got != want {
+		t.Errorf("exported %d tiles, want %d", got, want)
+	}
+
+	dst := &fakeSnapshotStorage{layout: src.layout}
+	root, err := Import(ctx, dst, mapTree, &buf, Options{LeafBatchSize: 2, TileBatchSize: 1})
+	if err != nil {
+		t.Fatalf("Import(): %v", err)
+	}
+	if got, want := root.Revision, uint64(7); got != want {
+		t.Errorf("imported revision %d, want %d", got, want)
+	}
+	if dst.began != 7 {
+		t.Errorf("import began at revision %d, want 7", dst.began)
+	}
+	if !proto.Equal(dst.root, src.root) {
+		t.Errorf("imported root %v, want %v", dst.root, src.root)
+	}
+	if diff := cmp.Diff(dst.leaves, src.leaves, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("imported leaves diff (-got +want):\n%s", diff)
+	}
+	opt := cmp.Comparer(func(x, y tree.NodeID2) bool { return x == y })
+	if diff := cmp.Diff(dst.tiles, src.tiles[:2], opt); diff != "" {
+		t.Errorf("imported tiles diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	ctx := context.Background()
+	mapTree, src := newSnapshotMap(t)
+	var buf bytes.Buffer
+	if _, err := Export(ctx, src, mapTree, -1, &buf); err != nil {
+		t.Fatalf("Export(): %v", err)
+	}
+	snapshot := buf.Bytes()
+	otherTree, _ := newSnapshotMap(t)
+	var footer bytes.Buffer
+	w := bufio.NewWriter(&footer)
+	if err := writeRecord(w, &snapshotpb.Record{Record: &snapshotpb.Record_Footer{Footer: &snapshotpb.Footer{TileCount: 2, LeafCount: 3}}}); err != nil {
+		t.Fatalf("writeRecord(): %v", err)
+	}
+	w.Flush()
+	if !bytes.HasSuffix(snapshot, footer.Bytes()) {
+		t.Fatal("snapshot doesn't end with its footer")
+	}
+
+	for _, test := range []struct {
+		desc     string
+		tree     func(*trillian.Tree)
+		layout   []int
+		snapshot []byte
+		noWriter bool
+	}{
+		{desc: "otherTreeID", tree: func(tr *trillian.Tree) { tr.TreeId++ }},
+		{desc: "otherHashStrategy", tree: func(tr *trillian.Tree) { tr.HashStrategy = trillian.HashStrategy_CONIKS_SHA256 }},
+		{desc: "otherKey", tree: func(tr *trillian.Tree) { tr.PublicKey = otherTree.PublicKey }},
+		{desc: "otherLayout", layout: []int{8, 8, 240}},
+		{desc: "truncated", snapshot: snapshot[:len(snapshot)-3]},
+		{desc: "noFooter", snapshot: snapshot[:len(snapshot)-footer.Len()]},
+		{desc: "trailingData", snapshot: append(append([]byte(nil), snapshot...), 5, 1)},
+		{desc: "empty", snapshot: []byte{}},
+		{desc: "noWriter", noWriter: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			tr := proto.Clone(mapTree).(*trillian.Tree)
+			if test.tree != nil {
+				test.tree(tr)
+			}
+			dst := &fakeSnapshotStorage{layout: src.layout}
+			if test.layout != nil {
+				dst.layout = tree.NewLayout(test.layout)
+			}
+			var ms storage.MapStorage = dst
+			if test.noWriter {
+				ms = struct{ storage.MapStorage }{dst}
+			}
+			in := snapshot
+			if test.snapshot != nil {
+				in = test.snapshot
+			}
+			if _, err := Import(ctx, ms, tr, bytes.NewReader(in), Options{}); err == nil {
+				t.Error("Import() succeeded")
+			}
+			if dst.root != nil {
+				t.Error("Import() failed, but committed the root")
+			}
+		})
+	}
+}
@@ -0,0 +1,586 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.12.4
+// source: maps/snapshot/snapshotpb/snapshot.proto
+
+package snapshotpb
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	trillian "github.com/google/trillian"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+// Header is the first record of a snapshot.
+type Header struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// tree_id is the ID of the exported map. Leaf and node hashes depend on
+	// it, so the snapshot can only be imported into a map with the same ID.
+	TreeId int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	// hash_strategy is the hash strategy of the exported map.
+	HashStrategy trillian.HashStrategy `protobuf:"varint,2,opt,name=hash_strategy,json=hashStrategy,proto3,enum=trillian.HashStrategy" json:"hash_strategy,omitempty"`
+	// strata are the heights of the tiles of the exported map, from the root
+	// down, which the storage importing the snapshot must use too.
+	Strata []int32 `protobuf:"varint,3,rep,packed,name=strata,proto3" json:"strata,omitempty"`
+	// map_root is the root of the exported revision, as it was signed.
+	MapRoot *trillian.SignedMapRoot `protobuf:"bytes,4,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
+}
+
+func (x *Header) Reset() {
+	*x = Header{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Header) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Header) ProtoMessage() {}
+
+func (x *Header) ProtoReflect() protoreflect.Message {
+	mi := &file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Header.ProtoReflect.Descriptor instead.
+func (*Header) Descriptor() ([]byte, []int) {
+	return file_maps_snapshot_snapshotpb_snapshot_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Header) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *Header) GetHashStrategy() trillian.HashStrategy {
+	if x != nil {
+		return x.HashStrategy
+	}
+	return trillian.HashStrategy_UNKNOWN_HASH_STRATEGY
+}
+
+func (x *Header) GetStrata() []int32 {
+	if x != nil {
+		return x.Strata
+	}
+	return nil
+}
+
+func (x *Header) GetMapRoot() *trillian.SignedMapRoot {
+	if x != nil {
+		return x.MapRoot
+	}
+	return nil
+}
+
+// TileLeaf is a non-empty node at the bottom of a tile.
+type TileLeaf struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// id is the path from the root of the map to the node, which is a whole
+	// number of bytes long.
+	Id   []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Hash []byte `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+}
+
+func (x *TileLeaf) Reset() {
+	*x = TileLeaf{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TileLeaf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TileLeaf) ProtoMessage() {}
+
+func (x *TileLeaf) ProtoReflect() protoreflect.Message {
+	mi := &file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TileLeaf.ProtoReflect.Descriptor instead.
+func (*TileLeaf) Descriptor() ([]byte, []int) {
+	return file_maps_snapshot_snapshotpb_snapshot_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TileLeaf) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *TileLeaf) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+// Tile is a tile of the sparse Merkle tree of a map.
+type Tile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// id is the path from the root of the map to the root of the tile, which
+	// is a whole number of bytes long, and empty for the root tile.
+	Id []byte `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// leaves are the non-empty nodes at the bottom of the tile, in order of
+	// their IDs.
+	Leaves []*TileLeaf `protobuf:"bytes,2,rep,name=leaves,proto3" json:"leaves,omitempty"`
+}
+
+func (x *Tile) Reset() {
+	*x = Tile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Tile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tile) ProtoMessage() {}
+
+func (x *Tile) ProtoReflect() protoreflect.Message {
+	mi := &file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tile.ProtoReflect.Descriptor instead.
+func (*Tile) Descriptor() ([]byte, []int) {
+	return file_maps_snapshot_snapshotpb_snapshot_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Tile) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *Tile) GetLeaves() []*TileLeaf {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+// Footer is the last record of a snapshot, which tells complete snapshots
+// from truncated ones.
+type Footer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TileCount int64 `protobuf:"varint,1,opt,name=tile_count,json=tileCount,proto3" json:"tile_count,omitempty"`
+	LeafCount int64 `protobuf:"varint,2,opt,name=leaf_count,json=leafCount,proto3" json:"leaf_count,omitempty"`
+}
+
+func (x *Footer) Reset() {
+	*x = Footer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Footer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Footer) ProtoMessage() {}
+
+func (x *Footer) ProtoReflect() protoreflect.Message {
+	mi := &file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Footer.ProtoReflect.Descriptor instead.
+func (*Footer) Descriptor() ([]byte, []int) {
+	return file_maps_snapshot_snapshotpb_snapshot_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Footer) GetTileCount() int64 {
+	if x != nil {
+		return x.TileCount
+	}
+	return 0
+}
+
+func (x *Footer) GetLeafCount() int64 {
+	if x != nil {
+		return x.LeafCount
+	}
+	return 0
+}
+
+// Record is a record of a snapshot. A snapshot is a sequence of records, each
+// preceded by its length as a varint: a header, the tiles of the map, the
+// leaves of the map in order of their indices, and a footer.
+type Record struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Record:
+	//	*Record_Header
+	//	*Record_Tile
+	//	*Record_Leaf
+	//	*Record_Footer
+	Record isRecord_Record `protobuf_oneof:"record"`
+}
+
+func (x *Record) Reset() {
+	*x = Record{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Record) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Record) ProtoMessage() {}
+
+func (x *Record) ProtoReflect() protoreflect.Message {
+	mi := &file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Record.ProtoReflect.Descriptor instead.
+func (*Record) Descriptor() ([]byte, []int) {
+	return file_maps_snapshot_snapshotpb_snapshot_proto_rawDescGZIP(), []int{4}
+}
+
+func (m *Record) GetRecord() isRecord_Record {
+	if m != nil {
+		return m.Record
+	}
+	return nil
+}
+
+func (x *Record) GetHeader() *Header {
+	if x, ok := x.GetRecord().(*Record_Header); ok {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *Record) GetTile() *Tile {
+	if x, ok := x.GetRecord().(*Record_Tile); ok {
+		return x.Tile
+	}
+	return nil
+}
+
+func (x *Record) GetLeaf() *trillian.MapLeaf {
+	if x, ok := x.GetRecord().(*Record_Leaf); ok {
+		return x.Leaf
+	}
+	return nil
+}
+
+func (x *Record) GetFooter() *Footer {
+	if x, ok := x.GetRecord().(*Record_Footer); ok {
+		return x.Footer
+	}
+	return nil
+}
+
+type isRecord_Record interface {
+	isRecord_Record()
+}
+
+type Record_Header struct {
+	Header *Header `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type Record_Tile struct {
+	Tile *Tile `protobuf:"bytes,2,opt,name=tile,proto3,oneof"`
+}
+
+type Record_Leaf struct {
+	Leaf *trillian.MapLeaf `protobuf:"bytes,3,opt,name=leaf,proto3,oneof"`
+}
+
+type Record_Footer struct {
+	Footer *Footer `protobuf:"bytes,4,opt,name=footer,proto3,oneof"`
+}
+
+func (*Record_Header) isRecord_Record() {}
+
+func (*Record_Tile) isRecord_Record() {}
+
+func (*Record_Leaf) isRecord_Record() {}
+
+func (*Record_Footer) isRecord_Record() {}
+
+var File_maps_snapshot_snapshotpb_snapshot_proto protoreflect.FileDescriptor
+
+var file_maps_snapshot_snapshotpb_snapshot_proto_rawDesc = []byte{
+	0x0a, 0x27, 0x6d, 0x61, 0x70, 0x73, 0x2f, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2f,
+	0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x70, 0x62, 0x2f, 0x73, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x73, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x70, 0x62, 0x1a, 0x0e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x16, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x5f,
+	0x6d, 0x61, 0x70, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xaa, 0x01,
+	0x0a, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49,
+	0x64, 0x12, 0x3b, 0x0a, 0x0d, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65,
+	0x67, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79,
+	0x52, 0x0c, 0x68, 0x61, 0x73, 0x68, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x74, 0x72, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x05, 0x52, 0x06,
+	0x73, 0x74, 0x72, 0x61, 0x74, 0x61, 0x12, 0x32, 0x0a, 0x08, 0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f,
+	0x6f, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f,
+	0x74, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x22, 0x2e, 0x0a, 0x08, 0x54, 0x69,
+	0x6c, 0x65, 0x4c, 0x65, 0x61, 0x66, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x22, 0x44, 0x0a, 0x04, 0x54, 0x69,
+	0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x2c, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x70, 0x62, 0x2e,
+	0x54, 0x69, 0x6c, 0x65, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73,
+	0x22, 0x46, 0x0a, 0x06, 0x46, 0x6f, 0x6f, 0x74, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x69,
+	0x6c, 0x65, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x74, 0x69, 0x6c, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x65, 0x61,
+	0x66, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c,
+	0x65, 0x61, 0x66, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0xbf, 0x01, 0x0a, 0x06, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x12, 0x2c, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x70, 0x62,
+	0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x48, 0x00, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x12, 0x26, 0x0a, 0x04, 0x74, 0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x70, 0x62, 0x2e, 0x54, 0x69, 0x6c,
+	0x65, 0x48, 0x00, 0x52, 0x04, 0x74, 0x69, 0x6c, 0x65, 0x12, 0x27, 0x0a, 0x04, 0x6c, 0x65, 0x61,
+	0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69,
+	0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x48, 0x00, 0x52, 0x04, 0x6c, 0x65,
+	0x61, 0x66, 0x12, 0x2c, 0x0a, 0x06, 0x66, 0x6f, 0x6f, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x12, 0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x70, 0x62, 0x2e,
+	0x46, 0x6f, 0x6f, 0x74, 0x65, 0x72, 0x48, 0x00, 0x52, 0x06, 0x66, 0x6f, 0x6f, 0x74, 0x65, 0x72,
+	0x42, 0x08, 0x0a, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2f, 0x6d, 0x61, 0x70, 0x73, 0x2f, 0x73, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2f, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x70,
+	0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_maps_snapshot_snapshotpb_snapshot_proto_rawDescOnce sync.Once
+	file_maps_snapshot_snapshotpb_snapshot_proto_rawDescData = file_maps_snapshot_snapshotpb_snapshot_proto_rawDesc
+)
+
+func file_maps_snapshot_snapshotpb_snapshot_proto_rawDescGZIP() []byte {
+	file_maps_snapshot_snapshotpb_snapshot_proto_rawDescOnce.Do(func() {
+		file_maps_snapshot_snapshotpb_snapshot_proto_rawDescData = protoimpl.X.CompressGZIP(file_maps_snapshot_snapshotpb_snapshot_proto_rawDescData)
+	})
+	return file_maps_snapshot_snapshotpb_snapshot_proto_rawDescData
+}
+
+var file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_maps_snapshot_snapshotpb_snapshot_proto_goTypes = []interface{}{
+	(*Header)(nil),                 // 0: snapshotpb.Header
+	(*TileLeaf)(nil),               // 1: snapshotpb.TileLeaf
+	(*Tile)(nil),                   // 2: snapshotpb.Tile
+	(*Footer)(nil),                 // 3: snapshotpb.Footer
+	(*Record)(nil),                 // 4: snapshotpb.Record
+	(trillian.HashStrategy)(0),     // 5: trillian.HashStrategy
+	(*trillian.SignedMapRoot)(nil), // 6: trillian.SignedMapRoot
+	(*trillian.MapLeaf)(nil),       // 7: trillian.MapLeaf
+}
+var file_maps_snapshot_snapshotpb_snapshot_proto_depIdxs = []int32{
+	5, // 0: snapshotpb.Header.hash_strategy:type_name -> trillian.HashStrategy
+	6, // 1: snapshotpb.Header.map_root:type_name -> trillian.SignedMapRoot
+	1, // 2: snapshotpb.Tile.leaves:type_name -> snapshotpb.TileLeaf
+	0, // 3: snapshotpb.Record.header:type_name -> snapshotpb.Header
+	2, // 4: snapshotpb.Record.tile:type_name -> snapshotpb.Tile
+	7, // 5: snapshotpb.Record.leaf:type_name -> trillian.MapLeaf
+	3, // 6: snapshotpb.Record.footer:type_name -> snapshotpb.Footer
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_maps_snapshot_snapshotpb_snapshot_proto_init() }
+func file_maps_snapshot_snapshotpb_snapshot_proto_init() {
+	if File_maps_snapshot_snapshotpb_snapshot_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Header); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TileLeaf); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Tile); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Footer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Record); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes[4].OneofWrappers = []interface{}{
+		(*Record_Header)(nil),
+		(*Record_Tile)(nil),
+		(*Record_Leaf)(nil),
+		(*Record_Footer)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_maps_snapshot_snapshotpb_snapshot_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_maps_snapshot_snapshotpb_snapshot_proto_goTypes,
+		DependencyIndexes: file_maps_snapshot_snapshotpb_snapshot_proto_depIdxs,
+		MessageInfos:      file_maps_snapshot_snapshotpb_snapshot_proto_msgTypes,
+	}.Build()
+	File_maps_snapshot_snapshotpb_snapshot_proto = out.File
+	file_maps_snapshot_snapshotpb_snapshot_proto_rawDesc = nil
+	file_maps_snapshot_snapshotpb_snapshot_proto_goTypes = nil
+	file_maps_snapshot_snapshotpb_snapshot_proto_depIdxs = nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compact
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// rangeFormatV1 is the first byte of compact ranges serialized by
+// MarshalBinary.
+const rangeFormatV1 = 1
+
+// MarshalBinary returns the serialized form of the range, which
+// RangeFactory.UnmarshalRange parses. In the TLS presentation language used
+// by RFC 9162, it is:
+//
+//	opaque NodeHash<1..2^8-1>;
+//
+//	struct {
+//	    uint8 version = 1;
+//	    uint64 begin;
+//	    uint64 end;
+//	    NodeHash hashes[N];
+//	} CompactRange;
+//
+// where hashes are those returned by Hashes, and their number N follows from
+// begin and end, see Decompose. The format is stable, so that ranges can be
+// exchanged between servers and stored.
+func (r *Range) MarshalBinary() ([]byte, error) {
+	size := 1 + 8 + 8
+	for i, hash := range r.hashes {
+		if len(hash) == 0 || len(hash) > 255 {
+			return nil, fmt.Errorf("hash %d has %d bytes, want 1 to 255", i, len(hash))
+		}
+		size += 1 + len(hash)
+	}
+	buf := make([]byte, 0, size)
+	buf = append(buf, rangeFormatV1)
+	buf = appendUint64(buf, r.begin)
+	buf = appendUint64(buf, r.end)
+	for _, hash := range r.hashes {
+		buf = append(buf, byte(len(hash)))
+		buf = append(buf, hash...)
+	}
+	return buf, nil
+}
+
+// UnmarshalRange parses a range serialized by Range.MarshalBinary, whose
+// hashes were computed with the hash function of this factory, so that it can
+// be merged with the ranges of the factory. The hashes of the range aren't
+// checked, which only comparing root hashes does.
+func (f *RangeFactory) UnmarshalRange(data []byte) (*Range, error) {
+	if len(data) < 1+8+8 {
+		return nil, fmt.Errorf("compact range has %d bytes, want at least %d", len(data), 1+8+8)
+	}
+	if data[0] != rangeFormatV1 {
+		return nil, fmt.Errorf("unknown compact range version %d", data[0])
+	}
+	begin := binary.BigEndian.Uint64(data[1:])
+	end := binary.BigEndian.Uint64(data[9:])
+	if end < begin {
+		return nil, fmt.Errorf("invalid range: end=%d, want >= %d", end, begin)
+	}
+	left, right := Decompose(begin, end)
+	count := bits.OnesCount64(left) + bits.OnesCount64(right)
+
+	rest := data[17:]
+	hashes := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		if len(rest) == 0 {
+			return nil, fmt.Errorf("compact range is truncated: got %d hashes, want %d", i, count)
+		}
+		size := int(rest[0])
+		if size == 0 {
+			return nil, fmt.Errorf("hash %d is empty", i)
+		}
+		if len(rest) < 1+size {
+			return nil, fmt.Errorf("compact range is truncated in hash %d", i)
+		}
+		hashes = append(hashes, append([]byte(nil), rest[1:1+size]...))
+		rest = rest[1+size:]
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("compact range has %d trailing bytes", len(rest))
+	}
+	return f.NewRange(begin, end, hashes)
+}
+
+// Merge returns the compact range which covers all the given ranges of this
+// factory, e.g. as received from different servers, which must cover a
+// contiguous span of leaves together, with no gaps, in any order. Ranges
+// which appear more than once must have the same hashes every time, and ranges
+// must not overlap otherwise. Empty ranges are ignored. The given ranges are
+// left unchanged. The hashes of new nodes are reported through the visitor
+// function (if non-nil), as by AppendRange.
+func (f *RangeFactory) Merge(ranges []*Range, visitor VisitFn) (*Range, error) {
+	if len(ranges) == 0 {
+		return nil, errors.New("no ranges to merge")
+	}
+	sorted := make([]*Range, 0, len(ranges))
+	for _, r := range ranges {
+		if r.f != f {
+			return nil, errors.New("incompatible ranges")
+		}
+		if r.begin != r.end {
+			sorted = append(sorted, r)
+		}
+	}
+	if len(sorted) == 0 {
+		return f.NewEmptyRange(ranges[0].begin), nil
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].begin != sorted[j].begin {
+			return sorted[i].begin < sorted[j].begin
+		}
+		return sorted[i].end < sorted[j].end
+	})
+
+	first := sorted[0]
+	// The hashes are copied, as appending to the range reuses their array.
+	ret := &Range{f: f, begin: first.begin, end: first.end, hashes: append([][]byte(nil), first.hashes...)}
+	for i, r := range sorted[1:] {
+		prev := sorted[i]
+		switch {
+		case r.begin == prev.begin && r.end == prev.end:
+			if !r.Equal(prev) {
+				return nil, fmt.Errorf("conflicting hashes of range [%d, %d)", r.begin, r.end)
+			}
+		case r.begin < ret.end:
+			return nil, fmt.Errorf("ranges [%d, %d) and [%d, %d) overlap", prev.begin, prev.end, r.begin, r.end)
+		case r.begin > ret.end:
+			return nil, fmt.Errorf("ranges leave a gap at [%d, %d)", ret.end, r.begin)
+		default:
+			if err := ret.AppendRange(r, visitor); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ret, nil
+}
+
+// appendUint64 appends the big-endian encoding of v to buf.
+func appendUint64(buf []byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return append(buf, b[:]...)
+}
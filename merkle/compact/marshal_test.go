@@ -0,0 +1,217 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compact
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// rangeOf returns the compact range of the tree for [begin, end).
+func (tr *tree) rangeOf(t *testing.T, begin, end uint64) *Range {
+	t.Helper()
+	ids := RangeNodes(begin, end)
+	hashes := make([][]byte, 0, len(ids))
+	for _, id := range ids {
+		hashes = append(hashes, tr.nodes[id.Level][id.Index].hash)
+	}
+	rng, err := factory.NewRange(begin, end, hashes)
+	if err != nil {
+		t.Fatalf("NewRange(%d, %d): %v", begin, end, err)
+	}
+	return rng
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	const numNodes = uint64(37)
+	tree, _ := newTree(t, numNodes)
+	for begin := uint64(0); begin <= numNodes; begin++ {
+		for end := begin; end <= numNodes; end++ {
+			rng := tree.rangeOf(t, begin, end)
+			data, err := rng.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary(%d, %d): %v", begin, end, err)
+			}
+			got, err := factory.UnmarshalRange(data)
+			if err != nil {
+				t.Fatalf("UnmarshalRange(%d, %d): %v", begin, end, err)
+			}
+			if !got.Equal(rng) {
+				t.Errorf("UnmarshalRange(MarshalBinary(%d, %d)): %v, want %v", begin, end, got, rng)
+			}
+		}
+	}
+}
+
+func TestMarshalGolden(t *testing.T) {
+	rng, err := factory.NewRange(3, 8, [][]byte{{0xaa}, {0xbb, 0xcc}})
+	if err != nil {
+		t.Fatalf("NewRange(): %v", err)
+	}
+	data, err := rng.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	want := "01" + "0000000000000003" + "0000000000000008" + "01aa" + "02bbcc"
+	if got := hex.EncodeToString(data); got != want {
+		t.Errorf("MarshalBinary(): %s, want %s", got, want)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	for _, hash := range [][]byte{{}, make([]byte, 256)} {
+		rng, err := factory.NewRange(0, 1, [][]byte{hash})
+		if err != nil {
+			t.Fatalf("NewRange(): %v", err)
+		}
+		if _, err := rng.MarshalBinary(); err == nil {
+			t.Errorf("MarshalBinary() with a hash of %d bytes: no error", len(hash))
+		}
+	}
+}
+
+func TestUnmarshalRangeErrors(t *testing.T) {
+	rng, err := factory.NewRange(3, 8, [][]byte{{0xaa}, {0xbb, 0xcc}})
+	if err != nil {
+		t.Fatalf("NewRange(): %v", err)
+	}
+	valid, err := rng.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	modified := func(i int, b byte) []byte {
+		data := append([]byte(nil), valid...)
+		data[i] = b
+		return data
+	}
+	for _, tc := range []struct {
+		desc    string
+		data    []byte
+		wantErr string
+	}{
+		{desc: "empty", data: nil, wantErr: "compact range has 0 bytes"},
+		{desc: "version", data: modified(0, 2), wantErr: "unknown compact range version"},
+		{desc: "end_before_begin", data: modified(16, 2), wantErr: "invalid range"},
+		{desc: "missing_hash", data: valid[:19], wantErr: "compact range is truncated: got 1 hashes"},
+		{desc: "truncated_hash", data: valid[:len(valid)-1], wantErr: "compact range is truncated in hash 1"},
+		{desc: "empty_hash", data: modified(17, 0), wantErr: "hash 0 is empty"},
+		{desc: "trailing_data", data: append(append([]byte(nil), valid...), 0), wantErr: "compact range has 1 trailing bytes"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := factory.UnmarshalRange(tc.data)
+			if err == nil || !strings.HasPrefix(err.Error(), tc.wantErr) {
+				t.Errorf("UnmarshalRange(): %v; want containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// Split trees into random ranges, which go through their serialized form as
+// if received from different servers, and merge them in random order.
+func TestMergeReceived(t *testing.T) {
+	for seed := int64(1); seed < 50; seed++ {
+		t.Run(fmt.Sprintf("seed:%d", seed), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(seed))
+			numNodes := 1 + uint64(rnd.Int63n(300))
+			tree, visit := newTree(t, numNodes)
+
+			var ranges []*Range
+			for begin := uint64(0); begin < numNodes; {
+				end := begin + uint64(rnd.Int63n(40))
+				if end > numNodes {
+					end = numNodes
+				}
+				data, err := tree.rangeOf(t, begin, end).MarshalBinary()
+				if err != nil {
+					t.Fatalf("MarshalBinary(%d, %d): %v", begin, end, err)
+				}
+				rng, err := factory.UnmarshalRange(data)
+				if err != nil {
+					t.Fatalf("UnmarshalRange(%d, %d): %v", begin, end, err)
+				}
+				ranges = append(ranges, rng)
+				// Some ranges are received twice.
+				if rnd.Intn(4) == 0 {
+					ranges = append(ranges, tree.rangeOf(t, begin, end))
+				}
+				begin = end
+			}
+			rnd.Shuffle(len(ranges), func(i, j int) { ranges[i], ranges[j] = ranges[j], ranges[i] })
+			before := make([]*Range, len(ranges))
+			for i, r := range ranges {
+				before[i] = tree.rangeOf(t, r.Begin(), r.End())
+			}
+
+			rng, err := factory.Merge(ranges, visit)
+			if err != nil {
+				t.Fatalf("Merge(): %v", err)
+			}
+			if rng.Begin() != 0 || rng.End() != numNodes {
+				t.Fatalf("Merge(): range [%d, %d), want [0, %d)", rng.Begin(), rng.End(), numNodes)
+			}
+			root, err := rng.GetRootHash(nil)
+			if err != nil {
+				t.Fatalf("GetRootHash(): %v", err)
+			}
+			if want := tree.rootHash(); !bytes.Equal(root, want) {
+				t.Errorf("GetRootHash(): %x, want %x", shorten(root), shorten(want))
+			}
+			for i, r := range ranges {
+				if !r.Equal(before[i]) {
+					t.Errorf("Merge() modified range [%d, %d)", r.Begin(), r.End())
+				}
+			}
+		})
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	tree, _ := newTree(t, 20)
+	conflicting := tree.rangeOf(t, 5, 10)
+	conflicting.hashes = [][]byte{[]byte("hash0"), []byte("hash1")}
+	anotherFactory := &RangeFactory{Hash: hashChildren}
+	for _, tc := range []struct {
+		desc    string
+		ranges  []*Range
+		wantErr string
+	}{
+		{desc: "none", wantErr: "no ranges to merge"},
+		{desc: "incompatible", ranges: []*Range{tree.rangeOf(t, 0, 5), anotherFactory.NewEmptyRange(5)}, wantErr: "incompatible ranges"},
+		{desc: "gap", ranges: []*Range{tree.rangeOf(t, 0, 5), tree.rangeOf(t, 6, 10)}, wantErr: "ranges leave a gap at [5, 6)"},
+		{desc: "overlap", ranges: []*Range{tree.rangeOf(t, 0, 7), tree.rangeOf(t, 5, 10)}, wantErr: "ranges [0, 7) and [5, 10) overlap"},
+		{desc: "contained", ranges: []*Range{tree.rangeOf(t, 0, 10), tree.rangeOf(t, 0, 5)}, wantErr: "ranges [0, 5) and [0, 10) overlap"},
+		{desc: "conflicting", ranges: []*Range{tree.rangeOf(t, 5, 10), conflicting}, wantErr: "conflicting hashes of range [5, 10)"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := factory.Merge(tc.ranges, nil)
+			if err == nil || !strings.HasPrefix(err.Error(), tc.wantErr) {
+				t.Errorf("Merge(): %v; want containing %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	// Empty ranges are ignored, wherever they are.
+	rng, err := factory.Merge([]*Range{factory.NewEmptyRange(15), tree.rangeOf(t, 5, 10), factory.NewEmptyRange(5)}, nil)
+	if err != nil {
+		t.Fatalf("Merge() with empty ranges: %v", err)
+	}
+	if want := tree.rangeOf(t, 5, 10); !rng.Equal(want) {
+		t.Errorf("Merge() with empty ranges: [%d, %d), want [5, 10)", rng.Begin(), rng.End())
+	}
+}
@@ -17,6 +17,7 @@ package registry
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/merkle/hashers"
@@ -25,6 +26,11 @@ import (
 var (
 	logHashers = make(map[trillian.HashStrategy]hashers.LogHasher)
 	mapHashers = make(map[trillian.HashStrategy]hashers.MapHasher)
+
+	// namedMapHashers holds the hashers of maps with the CUSTOM_MAP_HASHER
+	// strategy, which may be registered while trees are used.
+	namedMu         sync.RWMutex
+	namedMapHashers = make(map[string]hashers.MapHasher)
 )
 
 // RegisterLogHasher registers a hasher for use.
@@ -43,12 +49,31 @@ func RegisterMapHasher(h trillian.HashStrategy, f hashers.MapHasher) {
 	if h == trillian.HashStrategy_UNKNOWN_HASH_STRATEGY {
 		panic(fmt.Sprintf("RegisterMapHasher(%s) of unknown hasher", h))
 	}
+	if h == trillian.HashStrategy_CUSTOM_MAP_HASHER {
+		panic(fmt.Sprintf("RegisterMapHasher(%s): use RegisterNamedMapHasher", h))
+	}
 	if mapHashers[h] != nil {
 		panic(fmt.Sprintf("%v already registered as a MapHasher", h))
 	}
 	mapHashers[h] = f
 }
 
+// RegisterNamedMapHasher registers a hasher under a name, for use by maps with
+// the CUSTOM_MAP_HASHER hash strategy and that name as their map_hasher. This
+// allows personalities to use hashers which Trillian doesn't know of. Hashers
+// must be registered before the trees which use them are created or used.
+func RegisterNamedMapHasher(name string, f hashers.MapHasher) {
+	if name == "" {
+		panic("RegisterNamedMapHasher() with empty name")
+	}
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	if namedMapHashers[name] != nil {
+		panic(fmt.Sprintf("%q already registered as a MapHasher", name))
+	}
+	namedMapHashers[name] = f
+}
+
 // NewLogHasher returns a LogHasher.
 func NewLogHasher(h trillian.HashStrategy) (hashers.LogHasher, error) {
 	f := logHashers[h]
@@ -66,3 +91,23 @@ func NewMapHasher(h trillian.HashStrategy) (hashers.MapHasher, error) {
 	}
 	return nil, fmt.Errorf("MapHasher(%s) is an unknown hasher", h)
 }
+
+// NewNamedMapHasher returns the MapHasher registered under the given name.
+func NewNamedMapHasher(name string) (hashers.MapHasher, error) {
+	namedMu.RLock()
+	defer namedMu.RUnlock()
+	f := namedMapHashers[name]
+	if f != nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("MapHasher(%q) is an unknown hasher", name)
+}
+
+// NewMapHasherForTree returns the MapHasher of a map, which is the hasher of
+// its hash strategy, or the named hasher for the CUSTOM_MAP_HASHER strategy.
+func NewMapHasherForTree(tree *trillian.Tree) (hashers.MapHasher, error) {
+	if tree.HashStrategy == trillian.HashStrategy_CUSTOM_MAP_HASHER {
+		return NewNamedMapHasher(tree.MapHasher)
+	}
+	return NewMapHasher(tree.HashStrategy)
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+)
+
+// fakeMapHasher is a MapHasher which is only compared by identity.
+type fakeMapHasher struct {
+	hashers.MapHasher
+	name string
+}
+
+func TestNamedMapHasher(t *testing.T) {
+	builtin := &fakeMapHasher{name: "builtin"}
+	custom := &fakeMapHasher{name: "custom"}
+	RegisterMapHasher(trillian.HashStrategy_TEST_MAP_HASHER, builtin)
+	RegisterNamedMapHasher("custom", custom)
+
+	for _, tc := range []struct {
+		desc    string
+		tree    *trillian.Tree
+		want    hashers.MapHasher
+		wantErr bool
+	}{
+		{desc: "builtin", tree: &trillian.Tree{HashStrategy: trillian.HashStrategy_TEST_MAP_HASHER}, want: builtin},
+		{desc: "custom", tree: &trillian.Tree{HashStrategy: trillian.HashStrategy_CUSTOM_MAP_HASHER, MapHasher: "custom"}, want: custom},
+		// The name is only used with the CUSTOM_MAP_HASHER strategy.
+		{desc: "builtin-named", tree: &trillian.Tree{HashStrategy: trillian.HashStrategy_TEST_MAP_HASHER, MapHasher: "custom"}, want: builtin},
+		{desc: "unregistered", tree: &trillian.Tree{HashStrategy: trillian.HashStrategy_CUSTOM_MAP_HASHER, MapHasher: "other"}, wantErr: true},
+		{desc: "unnamed", tree: &trillian.Tree{HashStrategy: trillian.HashStrategy_CUSTOM_MAP_HASHER}, wantErr: true},
+		{desc: "unknown", tree: &trillian.Tree{HashStrategy: trillian.HashStrategy_CONIKS_SHA256}, wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := NewMapHasherForTree(tc.tree)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("NewMapHasherForTree(): %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("NewMapHasherForTree()=%v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	RegisterNamedMapHasher("duplicate", &fakeMapHasher{})
+	for _, tc := range []struct {
+		desc     string
+		register func()
+	}{
+		{desc: "unknown", register: func() { RegisterMapHasher(trillian.HashStrategy_UNKNOWN_HASH_STRATEGY, &fakeMapHasher{}) }},
+		{desc: "custom", register: func() { RegisterMapHasher(trillian.HashStrategy_CUSTOM_MAP_HASHER, &fakeMapHasher{}) }},
+		{desc: "empty-name", register: func() { RegisterNamedMapHasher("", &fakeMapHasher{}) }},
+		{desc: "duplicate-name", register: func() { RegisterNamedMapHasher("duplicate", &fakeMapHasher{}) }},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("registration didn't panic")
+				}
+			}()
+			tc.register()
+		})
+	}
+}
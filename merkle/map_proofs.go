@@ -0,0 +1,68 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merkle
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// CompactInclusionProof converts a sparse Merkle tree inclusion proof into
+// compact form, which omits the nil entries of the proof. It returns a bitmap
+// with one bit per entry, set for non-nil entries, and the non-nil entries.
+func CompactInclusionProof(proof [][]byte) ([]byte, [][]byte) {
+	bitmap := make([]byte, (len(proof)+7)/8)
+	var hashes [][]byte
+	for i, h := range proof {
+		if len(h) == 0 {
+			continue
+		}
+		bitmap[i/8] |= 1 << uint(i%8)
+		hashes = append(hashes, h)
+	}
+	return bitmap, hashes
+}
+
+// ExpandInclusionProof converts an inclusion proof in compact form, as
+// returned by CompactInclusionProof, back into a proof with height entries.
+func ExpandInclusionProof(bitmap []byte, hashes [][]byte, height int) ([][]byte, error) {
+	if got, want := len(bitmap), (height+7)/8; got != want {
+		return nil, fmt.Errorf("bitmap len: %d, want %d", got, want)
+	}
+	count := 0
+	for i, b := range bitmap {
+		if i == len(bitmap)-1 && height%8 != 0 && b>>uint(height%8) != 0 {
+			return nil, fmt.Errorf("bitmap has bits set beyond height %d", height)
+		}
+		count += bits.OnesCount8(b)
+	}
+	if got, want := len(hashes), count; got != want {
+		return nil, fmt.Errorf("got %d hashes, want %d", got, want)
+	}
+
+	proof := make([][]byte, height)
+	next := 0
+	for i := range proof {
+		if bitmap[i/8]&(1<<uint(i%8)) == 0 {
+			continue
+		}
+		if len(hashes[next]) == 0 {
+			return nil, fmt.Errorf("hash for level %d is empty", i)
+		}
+		proof[i] = hashes[next]
+		next++
+	}
+	return proof, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merkle
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompactInclusionProof(t *testing.T) {
+	h := func(s string) []byte { return []byte(s) }
+	for _, tc := range []struct {
+		desc       string
+		proof      [][]byte
+		wantBitmap []byte
+	}{
+		{desc: "empty-tree", proof: make([][]byte, 16), wantBitmap: []byte{0, 0}},
+		{desc: "full", proof: [][]byte{h("a"), h("b"), h("c"), h("d"), h("e"), h("f"), h("g"), h("h")}, wantBitmap: []byte{0xff}},
+		{desc: "sparse", proof: [][]byte{h("a"), nil, nil, nil, nil, nil, nil, nil, nil, h("j"), nil, nil, nil, nil, nil, h("p")}, wantBitmap: []byte{0x01, 0x82}},
+		{desc: "partial-byte", proof: [][]byte{nil, nil, h("c"), nil, nil, nil, nil, nil, nil, h("j")}, wantBitmap: []byte{0x04, 0x02}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			bitmap, hashes := CompactInclusionProof(tc.proof)
+			if !cmp.Equal(bitmap, tc.wantBitmap) {
+				t.Errorf("CompactInclusionProof(): bitmap %x, want %x", bitmap, tc.wantBitmap)
+			}
+			for _, hash := range hashes {
+				if len(hash) == 0 {
+					t.Error("CompactInclusionProof() returned an empty hash")
+				}
+			}
+
+			got, err := ExpandInclusionProof(bitmap, hashes, len(tc.proof))
+			if err != nil {
+				t.Fatalf("ExpandInclusionProof(): %v", err)
+			}
+			if diff := cmp.Diff(tc.proof, got, cmp.Comparer(func(a, b []byte) bool { return string(a) == string(b) })); diff != "" {
+				t.Errorf("ExpandInclusionProof(): diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExpandInclusionProofErrors(t *testing.T) {
+	h := []byte("hash")
+	for _, tc := range []struct {
+		desc    string
+		bitmap  []byte
+		hashes  [][]byte
+		height  int
+		wantErr string
+	}{
+		{desc: "short-bitmap", bitmap: []byte{0x01}, hashes: [][]byte{h}, height: 16, wantErr: "bitmap len"},
+		{desc: "long-bitmap", bitmap: []byte{0x01, 0x00}, hashes: [][]byte{h}, height: 8, wantErr: "bitmap len"},
+		{desc: "bits-beyond-height", bitmap: []byte{0x20}, hashes: [][]byte{h}, height: 5, wantErr: "beyond height"},
+		{desc: "too-few-hashes", bitmap: []byte{0x03}, hashes: [][]byte{h}, height: 8, wantErr: "got 1 hashes, want 2"},
+		{desc: "too-many-hashes", bitmap: []byte{0x01}, hashes: [][]byte{h, h}, height: 8, wantErr: "got 2 hashes, want 1"},
+		{desc: "empty-hash", bitmap: []byte{0x01}, hashes: [][]byte{nil}, height: 8, wantErr: "is empty"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := ExpandInclusionProof(tc.bitmap, tc.hashes, tc.height)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ExpandInclusionProof(): %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/merkle/smt"
 	"github.com/google/trillian/storage/tree"
 )
 
@@ -92,3 +93,31 @@ func VerifyInclusionProof(treeID int64, leaf *trillian.MapLeaf, expectedRoot []b
 	}
 	return nil
 }
+
+// VerifyMultiInclusionProof verifies that the passed in expectedRoot can be
+// reconstructed from the given leaves, and their multi-proof, which holds the
+// hashes of the nodes returned by smt.MultiProofIDs for the leaves, with nil
+// entries for empty subtrees.
+//
+// Returns nil on a successful verification, and an error otherwise.
+func VerifyMultiInclusionProof(treeID int64, leaves []*trillian.MapLeaf, expectedRoot []byte, proof [][]byte, h hashers.MapHasher) error {
+	nodes := make([]smt.Node, len(leaves))
+	for i, leaf := range leaves {
+		if got, want := len(leaf.Index)*8, h.BitLen(); got != want {
+			return fmt.Errorf("leaves[%d] index len: %d, want %d", i, got, want)
+		}
+		nodes[i].ID = tree.NewNodeID2(string(leaf.Index), uint(h.BitLen()))
+		// Leaves which have never been set are empty, as in VerifyInclusionProof.
+		if len(leaf.LeafValue) != 0 || len(leaf.LeafHash) != 0 {
+			nodes[i].Hash = h.HashLeaf(treeID, leaf.Index, leaf.LeafValue)
+		}
+	}
+	root, err := smt.MultiProofRoot(treeID, h, nodes, proof)
+	if err != nil {
+		return err
+	}
+	if got, want := root, expectedRoot; !bytes.Equal(got, want) {
+		return fmt.Errorf("calculated root: %x, want: %x", got, want)
+	}
+	return nil
+}
@@ -68,6 +68,10 @@ func TestConiksHasherTestVectors(t *testing.T) {
 			if err := VerifyInclusionProof(tc.treeID, &leaf, tc.root, tc.proof, h); err != nil {
 				t.Errorf("VerifyInclusionProof failed: %v", err)
 			}
+			// The multi-proof of a single leaf is its inclusion proof.
+			if err := VerifyMultiInclusionProof(tc.treeID, []*trillian.MapLeaf{&leaf}, tc.root, tc.proof, h); err != nil {
+				t.Errorf("VerifyMultiInclusionProof failed: %v", err)
+			}
 		})
 	}
 }
@@ -152,5 +156,9 @@ func TestMapHasherTestVectors(t *testing.T) {
 		if got := err == nil; got != tc.want {
 			t.Errorf("%v: VerifyInclusionProof(): %v, want %v", tc.desc, err, tc.want)
 		}
+		err = VerifyMultiInclusionProof(treeID, []*trillian.MapLeaf{&leaf}, tc.root, tc.proof, h)
+		if got := err == nil; got != tc.want {
+			t.Errorf("%v: VerifyMultiInclusionProof(): %v, want %v", tc.desc, err, tc.want)
+		}
 	}
 }
@@ -16,6 +16,7 @@
 package smt
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/google/trillian/storage/tree"
@@ -47,6 +48,10 @@ type HStar3 struct {
 	hash  HashChildrenFn
 	depth uint
 	top   uint
+	// empty, if set, returns the hash of an empty subtree with the given root
+	// ID. It makes HStar3 interpret nil hashes as empty subtrees, and propagate
+	// them up the tree as nil, so that no nodes are stored for them.
+	empty func(id tree.NodeID2) []byte
 }
 
 // NewHStar3 returns a new instance of HStar3 for the given set of node hash
@@ -156,30 +161,50 @@ func (h HStar3) updateAt(nodes []Node, depth uint, na NodeAccessor) ([]Node, err
 	// whenever two updated nodes share the same parent.
 	newLen := 0
 	for i, ln := 0, len(nodes); i < ln; i++ {
-		sib := nodes[i].ID.Sibling()
-		var left, right []byte
+		node := nodes[i]
+		sib := node.ID.Sibling()
+		var sibHash []byte
 		if next := i + 1; next < ln && nodes[next].ID == sib {
 			// The sibling is the right child here, as nodes are sorted.
-			left, right = nodes[i].Hash, nodes[next].Hash
+			sibHash = nodes[next].Hash
 			i = next // Skip the next update in the outer loop.
 		} else {
 			// The sibling is not updated, so fetch the original from NodeAccessor.
-			hash, err := na.Get(sib)
-			if err != nil {
+			var err error
+			if sibHash, err = na.Get(sib); err != nil {
 				return nil, err
 			}
-			left, right = nodes[i].Hash, hash
-			if isLeftChild(sib) {
-				left, right = right, left
-			}
 		}
-		hash := h.hash(left, right)
+		hash := h.hashChildren(node.ID, sib, node.Hash, sibHash)
 		nodes[newLen] = Node{ID: sib.Prefix(depth - 1), Hash: hash}
 		newLen++
 	}
 	return nodes[:newLen], nil
 }
 
+// hashChildren returns the hash of the parent of the node with the given ID
+// and its sibling. If empty subtrees are tracked, then a nil hash of either
+// child stands for an empty subtree, and the parent of two empty subtrees is
+// empty too, i.e. has a nil hash.
+func (h HStar3) hashChildren(id, sib tree.NodeID2, hash, sibHash []byte) []byte {
+	if h.empty != nil && (hash == nil || sibHash == nil) {
+		if hash == nil && (sibHash == nil || bytes.Equal(sibHash, h.empty(sib))) {
+			return nil
+		} else if sibHash == nil && bytes.Equal(hash, h.empty(id)) {
+			return nil
+		}
+		if hash == nil {
+			hash = h.empty(id)
+		} else if sibHash == nil {
+			sibHash = h.empty(sib)
+		}
+	}
+	if isLeftChild(sib) {
+		return h.hash(sibHash, hash)
+	}
+	return h.hash(hash, sibHash)
+}
+
 // isLeftChild returns whether the the given node is a left child.
 func isLeftChild(id tree.NodeID2) bool {
 	last, bits := id.LastByte()
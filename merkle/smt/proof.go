@@ -0,0 +1,117 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage/tree"
+)
+
+// A multi-proof is an inclusion proof of multiple leaves of a sparse Merkle
+// tree in its root. Unlike a list of inclusion proofs of each leaf, it holds
+// each node once, and omits the nodes which can be computed from the leaves,
+// i.e. the ancestors of the leaves. It consists of the hashes of the siblings
+// of the ancestors of the leaves which aren't ancestors of any of the leaves,
+// with nil hashes standing for empty subtrees.
+
+// MultiProofIDs returns the IDs of the nodes of the multi-proof of the leaves
+// with the given IDs, which must be unique and at the same depth. The IDs are
+// ordered from the bottom of the tree up, and from left to right within each
+// level.
+func MultiProofIDs(ids []tree.NodeID2) ([]tree.NodeID2, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	nodes := make([]Node, len(ids))
+	for i, id := range ids {
+		nodes[i].ID = id
+	}
+	hs, err := NewHStar3(nodes, nil, ids[0].BitLen(), 0)
+	if err != nil {
+		return nil, err
+	}
+	proof := hs.Prepare()
+	sort.Slice(proof, func(i, j int) bool {
+		if a, b := proof[i].BitLen(), proof[j].BitLen(); a != b {
+			return a > b
+		}
+		return compareHorizontal(proof[i], proof[j]) < 0
+	})
+	return proof, nil
+}
+
+// MultiProofRoot returns the root hash of the sparse Merkle tree computed from
+// the given leaves, and the hashes of their multi-proof, in the order of
+// MultiProofIDs. Leaves and proof entries with nil hashes are empty. The
+// leaves slice is not modified.
+func MultiProofRoot(treeID int64, hasher hashers.MapHasher, leaves []Node, proof [][]byte) ([]byte, error) {
+	if len(leaves) == 0 {
+		return nil, errors.New("no leaves")
+	}
+	nodes := make([]Node, len(leaves))
+	ids := make([]tree.NodeID2, len(leaves))
+	for i, leaf := range leaves {
+		nodes[i], ids[i] = leaf, leaf.ID
+	}
+	proofIDs, err := MultiProofIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if got, want := len(proof), len(proofIDs); got != want {
+		return nil, fmt.Errorf("got %d proof hashes, want %d", got, want)
+	}
+	acc := make(proofAccessor, len(proof))
+	for i, id := range proofIDs {
+		if hash := proof[i]; hash != nil && len(hash) != hasher.Size() {
+			return nil, fmt.Errorf("proof[%d] len: %d, want %d or 0", i, len(hash), hasher.Size())
+		}
+		acc[id] = proof[i]
+	}
+
+	h := bindHasher(hasher, treeID)
+	hs, err := NewHStar3(nodes, hasher.HashChildren, uint(hasher.BitLen()), 0)
+	if err != nil {
+		return nil, err
+	}
+	hs.empty = h.hashEmpty
+	top, err := hs.Update(acc)
+	if err != nil {
+		return nil, err
+	}
+	if root := top[0].Hash; root != nil {
+		return root, nil
+	}
+	return h.hashEmpty(tree.NodeID2{}), nil
+}
+
+// proofAccessor is a NodeAccessor of the nodes of a multi-proof.
+type proofAccessor map[tree.NodeID2][]byte
+
+// Get returns the hash of the given node of the proof, which is nil if the
+// node is empty.
+func (p proofAccessor) Get(id tree.NodeID2) ([]byte, error) {
+	hash, ok := p[id]
+	if !ok {
+		return nil, fmt.Errorf("node %v not in the proof", id)
+	}
+	return hash, nil
+}
+
+// Set does nothing, as only the root is needed.
+func (p proofAccessor) Set(id tree.NodeID2, hash []byte) {}
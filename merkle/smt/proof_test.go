@@ -0,0 +1,153 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/google/trillian/merkle/maphasher"
+	"github.com/google/trillian/storage/tree"
+)
+
+// nodeRecorder is a NodeAccessor of an empty tree, which records the nodes set.
+type nodeRecorder map[tree.NodeID2][]byte
+
+func (r nodeRecorder) Get(id tree.NodeID2) ([]byte, error) { return r[id], nil }
+func (r nodeRecorder) Set(id tree.NodeID2, hash []byte)    { r[id] = hash }
+
+func TestMultiProofIDs(t *testing.T) {
+	leaf := func(last byte) tree.NodeID2 {
+		return tree.NewNodeID2(string(append(make([]byte, 31), last)), 256)
+	}
+	for _, tc := range []struct {
+		desc string
+		ids  []tree.NodeID2
+		want int
+	}{
+		{desc: "none", want: 0},
+		{desc: "one", ids: []tree.NodeID2{leaf(0)}, want: 256},
+		// Siblings share all their ancestors, and are each other's proof.
+		{desc: "siblings", ids: []tree.NodeID2{leaf(1), leaf(0)}, want: 255},
+		{desc: "cousins", ids: []tree.NodeID2{leaf(0), leaf(2)}, want: 256},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ids, err := MultiProofIDs(tc.ids)
+			if err != nil {
+				t.Fatalf("MultiProofIDs(): %v", err)
+			}
+			if got := len(ids); got != tc.want {
+				t.Errorf("MultiProofIDs() returned %d IDs, want %d", got, tc.want)
+			}
+			for i := 1; i < len(ids); i++ {
+				a, b := ids[i-1], ids[i]
+				if a.BitLen() < b.BitLen() || (a.BitLen() == b.BitLen() && compareHorizontal(a, b) >= 0) {
+					t.Errorf("IDs %v and %v out of order", a, b)
+				}
+			}
+		})
+	}
+
+	if _, err := MultiProofIDs([]tree.NodeID2{leaf(0), leaf(0)}); err == nil {
+		t.Error("MultiProofIDs() with duplicate IDs succeeded")
+	}
+	if _, err := MultiProofIDs([]tree.NodeID2{leaf(0), tree.NewNodeID2("\x00", 8)}); err == nil {
+		t.Error("MultiProofIDs() with IDs at different depths succeeded")
+	}
+}
+
+func TestMultiProofRoot(t *testing.T) {
+	const treeID = 42
+	hasher := maphasher.Default
+	h := bindHasher(hasher, treeID)
+	index := func(i int) tree.NodeID2 {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("key %d", i)))
+		return tree.NewNodeID2(string(sum[:]), 256)
+	}
+
+	// Build a tree of 64 leaves, recording the hashes of all its nodes.
+	var leaves []Node
+	for i := 0; i < 64; i++ {
+		id := index(i)
+		leaves = append(leaves, Node{ID: id, Hash: hasher.HashLeaf(treeID, []byte(id.FullBytes()), []byte{byte(i)})})
+	}
+	hashes := make(nodeRecorder)
+	for _, leaf := range leaves {
+		hashes[leaf.ID] = leaf.Hash
+	}
+	hs, err := NewHStar3(append([]Node{}, leaves...), hasher.HashChildren, 256, 0)
+	if err != nil {
+		t.Fatalf("NewHStar3(): %v", err)
+	}
+	hs.empty = h.hashEmpty
+	top, err := hs.Update(hashes)
+	if err != nil {
+		t.Fatalf("Update(): %v", err)
+	}
+	root := top[0].Hash
+
+	for _, tc := range []struct {
+		desc string
+		keys []int
+	}{
+		{desc: "one", keys: []int{7}},
+		{desc: "some", keys: []int{3, 1, 40, 22}},
+		{desc: "absent", keys: []int{100, 101}},
+		{desc: "mixed", keys: []int{5, 100, 63}},
+		{desc: "all", keys: func() []int {
+			keys := make([]int, 64)
+			for i := range keys {
+				keys[i] = i
+			}
+			return keys
+		}()},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var req []Node
+			var ids []tree.NodeID2
+			for _, k := range tc.keys {
+				req = append(req, Node{ID: index(k), Hash: hashes[index(k)]})
+				ids = append(ids, index(k))
+			}
+			proofIDs, err := MultiProofIDs(ids)
+			if err != nil {
+				t.Fatalf("MultiProofIDs(): %v", err)
+			}
+			proof := make([][]byte, len(proofIDs))
+			for i, id := range proofIDs {
+				proof[i] = hashes[id]
+			}
+
+			got, err := MultiProofRoot(treeID, hasher, req, proof)
+			if err != nil {
+				t.Fatalf("MultiProofRoot(): %v", err)
+			}
+			if !bytes.Equal(got, root) {
+				t.Errorf("MultiProofRoot()=%x, want %x", got, root)
+			}
+			if _, err := MultiProofRoot(treeID, hasher, req, proof[1:]); err == nil {
+				t.Error("MultiProofRoot() with a short proof succeeded")
+			}
+			req[0].Hash = hasher.HashLeaf(treeID, []byte(req[0].ID.FullBytes()), []byte("other"))
+			if got, err := MultiProofRoot(treeID, hasher, req, proof); err != nil {
+				t.Errorf("MultiProofRoot() of a modified leaf: %v", err)
+			} else if bytes.Equal(got, root) {
+				t.Error("MultiProofRoot() of a modified leaf returned the root")
+			}
+		})
+	}
+}
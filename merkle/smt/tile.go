@@ -42,12 +42,13 @@ type Tile struct {
 
 // Merge returns a new tile which is a combination of this tile with the given
 // updates. The resulting tile contains all the nodes from the updates, and all
-// the nodes from the original tile not present in the updates.
+// the nodes from the original tile not present in the updates. An update with
+// a nil hash removes the node, as it denotes an empty subtree.
 func (t Tile) Merge(updates NodesRow) (Tile, error) {
 	if len(updates) == 0 {
 		return t, nil
 	} else if len(t.Leaves) == 0 {
-		return Tile{ID: t.ID, Leaves: updates}, nil
+		return Tile{ID: t.ID, Leaves: merge(nil, updates)}, nil
 	}
 	if at, want := updates[0].ID.BitLen(), t.Leaves[0].ID.BitLen(); at != want {
 		return Tile{}, fmt.Errorf("updates are at depth %d, want %d", at, want)
@@ -60,7 +61,8 @@ func (t Tile) Merge(updates NodesRow) (Tile, error) {
 
 // merge merges two sorted slices of nodes into one sorted slice. If a node ID
 // exists in both slices, then the one from the updates slice is taken, i.e. it
-// overrides the node from the nodes slice.
+// overrides the node from the nodes slice. The updates with a nil hash are
+// not added to the result, i.e. they delete the node if it exists.
 func merge(nodes, updates NodesRow) NodesRow {
 	res := make([]Node, 0, len(nodes)+len(updates))
 	i := 0
@@ -72,7 +74,9 @@ func merge(nodes, updates NodesRow) NodesRow {
 				break
 			}
 		}
-		res = append(res, u)
+		if u.Hash != nil {
+			res = append(res, u)
+		}
 	}
 	return append(res, nodes[i:]...)
 }
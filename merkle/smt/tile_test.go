@@ -72,6 +72,13 @@ func TestTileMerge(t *testing.T) {
 			upd:  []Node{n(1, "new1"), n(2, "new2")},
 			want: []Node{n(0, "old0"), n(1, "new1"), n(2, "new2"), n(3, "old3"), n(4, "old4")},
 		},
+		{
+			desc: "delete-some",
+			was:  []Node{n(0, "old0"), n(1, "old1"), n(3, "old3")},
+			upd:  []Node{{ID: ids[1]}, n(2, "new2"), {ID: ids[4]}},
+			want: []Node{n(0, "old0"), n(2, "new2"), n(3, "old3")},
+		},
+		{desc: "delete-from-empty", upd: []Node{{ID: ids[0]}}, want: []Node{}},
 		{
 			desc:    "wrong-depth",
 			was:     []Node{n(0, "old")},
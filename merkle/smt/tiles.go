@@ -99,12 +99,7 @@ func (t *TileSetMutation) Build() ([]Tile, error) {
 		sort.Slice(upd, func(i, j int) bool {
 			return compareHorizontal(upd[i].ID, upd[j].ID) < 0
 		})
-		had, ok := t.read.tiles[id]
-		if !ok {
-			res = append(res, Tile{ID: id, Leaves: upd})
-			continue
-		}
-		tile, err := Tile{ID: id, Leaves: had}.Merge(upd)
+		tile, err := Tile{ID: id, Leaves: t.read.tiles[id]}.Merge(upd)
 		if err != nil {
 			return nil, err
 		}
@@ -21,8 +21,13 @@ import (
 
 	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/storage/tree"
+	"golang.org/x/sync/errgroup"
 )
 
+// parallelHeight is the number of levels below the top of a shard at which a
+// parallel Writer splits the shard into subtrees hashed independently.
+const parallelHeight = 8
+
 // NodeBatchAccessor reads and writes batches of Merkle tree node hashes. It is
 // a batch interface for efficiency reasons, as it is designed to guard tree
 // storage / database access. The Writer type operates on a per-shard basis,
@@ -40,9 +45,10 @@ type NodeBatchAccessor interface {
 // and 2^split second-level shards each spanning levels from split to height.
 // If the split height is 0 then effectively there is only one "global" shard.
 type Writer struct {
-	h      mapHasher
-	height uint // The height of the tree.
-	split  uint // The height of the top shard.
+	h       mapHasher
+	height  uint // The height of the tree.
+	split   uint // The height of the top shard.
+	workers int  // The number of goroutines hashing each shard.
 }
 
 // NewWriter creates a new Writer for the specified tree of the given height,
@@ -54,6 +60,18 @@ func NewWriter(treeID int64, hasher hashers.MapHasher, height, split uint) *Writ
 	return &Writer{h: bindHasher(hasher, treeID), height: height, split: split}
 }
 
+// NewParallelWriter creates a Writer like NewWriter, which hashes each shard
+// on up to the given number of goroutines, rather than on the calling one. The
+// shard is split into subtrees parallelHeight levels below its top, which are
+// hashed independently, and then joined up to the shard root. This speeds up
+// large shards, such as the only one of a tree with a split height of 0. The
+// hasher must be safe for concurrent use.
+func NewParallelWriter(treeID int64, hasher hashers.MapHasher, height, split uint, workers int) *Writer {
+	w := NewWriter(treeID, hasher, height, split)
+	w.workers = workers
+	return w
+}
+
 // Split sorts and splits the given list of node hash updates into shards, i.e.
 // the subsets belonging to different subtrees. The nodes must belong to the
 // same tree level which is equal to the tree height.
@@ -86,6 +104,10 @@ func (w *Writer) Split(nodes []Node) ([][]Node, error) {
 //
 // In another case, Write can be performed without Split if the shard split
 // depth is 0, which effectively means that there is only one "global" shard.
+//
+// A node update with a nil hash deletes the node, i.e. makes it empty. The
+// returned shard root has a nil hash if the whole shard becomes empty, unless
+// it is the root of the tree.
 func (w *Writer) Write(ctx context.Context, nodes []Node, acc NodeBatchAccessor) (Node, error) {
 	if len(nodes) == 0 {
 		return Node{}, errors.New("nothing to write")
@@ -100,22 +122,97 @@ func (w *Writer) Write(ctx context.Context, nodes []Node, acc NodeBatchAccessor)
 	if err != nil {
 		return Node{}, err
 	}
+	hs.empty = w.h.hashEmpty
 	hashes, err := acc.Get(ctx, hs.Prepare())
 	if err != nil {
 		return Node{}, err
 	}
-	sa := w.newAccessor(hashes)
-	topUpd, err := hs.Update(sa)
+	var topUpd, writes []Node
+	if mid := top + parallelHeight; w.workers > 1 && mid < depth {
+		// NewHStar3 has sorted the nodes.
+		topUpd, writes, err = w.updateParallel(nodes, hashes, depth, mid, top)
+	} else {
+		sa := w.newAccessor(hashes)
+		topUpd, err = hs.Update(sa)
+		writes = sa.writes
+	}
 	if err != nil {
 		return Node{}, err
 	} else if ln := len(topUpd); ln != 1 {
 		return Node{}, fmt.Errorf("writing across %d shards, want 1", ln)
 	}
-	if err := acc.Set(ctx, sa.writes); err != nil {
+	if err := acc.Set(ctx, writes); err != nil {
 		return Node{}, err
 	}
 
-	return topUpd[0], nil
+	// The root of a shard which became empty has a nil hash, so that it is
+	// pruned from the shard above. The tree root hash is always defined.
+	root := topUpd[0]
+	if top == 0 && root.Hash == nil {
+		root.Hash = w.h.hashEmpty(root.ID)
+	}
+	return root, nil
+}
+
+// updateParallel updates the shard with the given sorted nodes at depth, as
+// HStar3.Update does, by updating the subtrees rooted at depth mid on the
+// worker goroutines, and then the nodes from mid to top. It returns the
+// updates at depth top, and the nodes written.
+func (w *Writer) updateParallel(nodes []Node, hashes map[tree.NodeID2][]byte, depth, mid, top uint) ([]Node, []Node, error) {
+	var subtrees [][]Node
+	for begin, i := 0, 0; i < len(nodes); i++ {
+		if next := i + 1; next == len(nodes) || nodes[next].ID.Prefix(mid) != nodes[i].ID.Prefix(mid) {
+			subtrees = append(subtrees, nodes[begin:next])
+			begin = next
+		}
+	}
+
+	// Each subtree writes its nodes to an accessor of its own, which share the
+	// read-only hashes.
+	roots := make([]Node, len(subtrees))
+	accs := make([]*shardAccessor, len(subtrees))
+	next := make(chan int, len(subtrees))
+	for i := range subtrees {
+		next <- i
+	}
+	close(next)
+	var g errgroup.Group
+	for i := 0; i < w.workers && i < len(subtrees); i++ {
+		g.Go(func() error {
+			for i := range next {
+				hs, err := NewHStar3(subtrees[i], w.h.mh.HashChildren, depth, mid)
+				if err != nil {
+					return err
+				}
+				hs.empty = w.h.hashEmpty
+				accs[i] = &shardAccessor{w: w, reads: hashes}
+				upd, err := hs.Update(accs[i])
+				if err != nil {
+					return err
+				}
+				roots[i] = upd[0]
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	hs, err := NewHStar3(roots, w.h.mh.HashChildren, mid, top)
+	if err != nil {
+		return nil, nil, err
+	}
+	hs.empty = w.h.hashEmpty
+	sa := w.newAccessor(hashes)
+	topUpd, err := hs.Update(sa)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, a := range accs {
+		sa.writes = append(sa.writes, a.writes...)
+	}
+	return topUpd, sa.writes, nil
 }
 
 // shardTop returns the depth of a shard top based on its bottom depth.
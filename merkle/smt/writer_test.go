@@ -214,6 +214,74 @@ func TestWriterBigBatchMultipleWrites(t *testing.T) {
 	}
 }
 
+func TestWriterDelete(t *testing.T) {
+	ctx := context.Background()
+	all := []Node{genNode("key1", "value1"), genNode("key2", "value2"), genNode("key3", "value3")}
+	del := func(n Node) Node { return Node{ID: n.ID} }
+	emptyRoot := hasher.HashEmpty(treeID, make([]byte, 32), 256)
+
+	w := NewWriter(treeID, hasher, 256, 8)
+	acc := &testAccessor{h: make(map[tree.NodeID2][]byte), save: true}
+	update(ctx, t, w, acc, []Node{all[0], all[1], all[2]})
+
+	// Deleting a leaf gives the same root as never having written it.
+	want := update(ctx, t, w, &testAccessor{}, []Node{all[0], all[2]}).Hash
+	if got := update(ctx, t, w, acc, []Node{del(all[1])}).Hash; !bytes.Equal(got, want) {
+		t.Errorf("root after deleting a leaf: got %x, want %x", got, want)
+	}
+	// Deleting a leaf which is not set changes nothing.
+	if got := update(ctx, t, w, acc, []Node{del(all[1])}).Hash; !bytes.Equal(got, want) {
+		t.Errorf("root after deleting a missing leaf: got %x, want %x", got, want)
+	}
+	// Deleting all the leaves gives the root of an empty tree, and prunes
+	// all the nodes.
+	if got := update(ctx, t, w, acc, []Node{del(all[0]), del(all[2])}).Hash; !bytes.Equal(got, emptyRoot) {
+		t.Errorf("root after deleting all leaves: got %x, want %x", got, emptyRoot)
+	}
+	for id, hash := range acc.h {
+		if hash != nil {
+			t.Errorf("node %v is not empty after deleting all leaves", id)
+		}
+	}
+}
+
+func TestWriterParallel(t *testing.T) {
+	ctx := context.Background()
+	var nodes, dels []Node
+	for i := 0; i < 300; i++ {
+		nodes = append(nodes, genNode(fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i)))
+		if i%3 == 0 {
+			dels = append(dels, Node{ID: nodes[i].ID})
+		}
+	}
+	// Rewrite some leaves along with the deletions.
+	for i := 1; i < 100; i += 3 {
+		dels = append(dels, genNode(fmt.Sprintf("key-%d", i), "new-value"))
+	}
+	copyNodes := func(nodes []Node) []Node { return append([]Node(nil), nodes...) }
+
+	for _, split := range []uint{0, 8} {
+		seqW := NewWriter(treeID, hasher, 256, split)
+		seqAcc := &testAccessor{h: make(map[tree.NodeID2][]byte), save: true}
+		seqRoots := []Node{update(ctx, t, seqW, seqAcc, copyNodes(nodes)), update(ctx, t, seqW, seqAcc, copyNodes(dels))}
+
+		for _, workers := range []int{1, 4} {
+			t.Run(fmt.Sprintf("split-%d-workers-%d", split, workers), func(t *testing.T) {
+				w := NewParallelWriter(treeID, hasher, 256, split, workers)
+				acc := &testAccessor{h: make(map[tree.NodeID2][]byte), save: true}
+				for i, batch := range [][]Node{nodes, dels} {
+					if got, want := update(ctx, t, w, acc, copyNodes(batch)).Hash, seqRoots[i].Hash; !bytes.Equal(got, want) {
+						t.Errorf("batch %d: got root %x, want %x", i, got, want)
+					}
+				}
+				if !reflect.DeepEqual(acc.h, seqAcc.h) {
+					t.Errorf("got %d nodes written, want %d, or different hashes", len(acc.h), len(seqAcc.h))
+				}
+			})
+		}
+	}
+}
+
 func update(ctx context.Context, t testing.TB, w *Writer, acc NodeBatchAccessor, nodes []Node) Node {
 	shards, err := w.Split(nodes)
 	if err != nil {
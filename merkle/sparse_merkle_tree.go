@@ -21,6 +21,7 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/merkle/smt"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/tree"
@@ -140,6 +141,97 @@ func (s SparseMerkleTreeReader) BatchInclusionProof(ctx context.Context, rev int
 	return r, nil
 }
 
+// BatchInclusionProofID2 returns the same proofs as BatchInclusionProof, but
+// computes the IDs of the proof nodes as tree.NodeID2s, which don't need the
+// bit manipulations of tree.NodeID.Siblings.
+func (s SparseMerkleTreeReader) BatchInclusionProofID2(ctx context.Context, rev int64, indices [][]byte) (map[string][][]byte, error) {
+	ctx, spanEnd := spanFor(ctx, "BatchInclusionProofID2")
+	defer spanEnd()
+
+	bits := uint(s.hasher.BitLen())
+	// The siblings of each leaf, from the leaf up to the children of the root.
+	indexToSibs := make(map[string][]tree.NodeID2, len(indices))
+	included := make(map[tree.NodeID2]bool)
+	allSibs := make([]tree.NodeID, 0, len(indices)*int(bits))
+	for _, index := range indices {
+		if got := uint(len(index)) * 8; got != bits {
+			return nil, fmt.Errorf("index %x has %d bits, want %d", index, got, bits)
+		}
+		id := tree.NewNodeID2(string(index), bits)
+		sibs := make([]tree.NodeID2, bits)
+		for height := range sibs {
+			sib := id.Prefix(bits - uint(height)).Sibling()
+			sibs[height] = sib
+			if !included[sib] {
+				included[sib] = true
+				allSibs = append(allSibs, tree.NewNodeIDFromID2(sib))
+			}
+		}
+		indexToSibs[string(index)] = sibs
+	}
+
+	nodes, err := s.tx.GetMerkleNodes(ctx, rev, allSibs)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[tree.NodeID2][]byte, len(nodes))
+	for _, n := range nodes {
+		hashes[n.NodeID.ToNodeID2()] = n.Hash
+	}
+
+	r := make(map[string][][]byte, len(indices))
+	for _, index := range indices {
+		sibs := indexToSibs[string(index)]
+		// Nodes which storage doesn't have are empty, and have nil hashes.
+		proof := make([][]byte, len(sibs))
+		for i, sib := range sibs {
+			proof[i] = hashes[sib]
+		}
+		r[string(index)] = proof
+	}
+	return r, nil
+}
+
+// MultiInclusionProof returns the multi-proof of the leaves at the specified
+// keys at the specified revision, i.e. a single inclusion proof of all of
+// them, which holds each node once. It holds the hashes of the nodes returned
+// by smt.MultiProofIDs, in that order, with nil hashes for empty subtrees.
+func (s SparseMerkleTreeReader) MultiInclusionProof(ctx context.Context, rev int64, indices [][]byte) ([][]byte, error) {
+	ctx, spanEnd := spanFor(ctx, "MultiInclusionProof")
+	defer spanEnd()
+
+	ids := make([]tree.NodeID2, len(indices))
+	for i, index := range indices {
+		if got, want := len(index)*8, s.hasher.BitLen(); got != want {
+			return nil, fmt.Errorf("index %x has %d bits, want %d", index, got, want)
+		}
+		ids[i] = tree.NewNodeID2(string(index), uint(s.hasher.BitLen()))
+	}
+	proofIDs, err := smt.MultiProofIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each node is read once, however many of the leaves it is shared by.
+	nodeIDs := make([]tree.NodeID, len(proofIDs))
+	for i, id := range proofIDs {
+		nodeIDs[i] = tree.NewNodeIDFromID2(id)
+	}
+	nodes, err := s.tx.GetMerkleNodes(ctx, rev, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[tree.NodeID2][]byte, len(nodes))
+	for _, n := range nodes {
+		hashes[n.NodeID.ToNodeID2()] = n.Hash
+	}
+	proof := make([][]byte, len(proofIDs))
+	for i, id := range proofIDs {
+		proof[i] = hashes[id]
+	}
+	return proof, nil
+}
+
 func spanFor(ctx context.Context, name string) (context.Context, func()) {
 	return monitoring.StartSpan(ctx, fmt.Sprintf("/trillian/m_sparse.%s", name))
 }
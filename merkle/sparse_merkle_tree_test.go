@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/trillian/merkle/maphasher"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/tree"
@@ -100,6 +101,53 @@ func TestBatchInclusionProofForNullEntriesInEmptyTrees(t *testing.T) {
 	}
 }
 
+func TestBatchInclusionProofID2(t *testing.T) {
+	ctx := context.Background()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	const rev = 100
+	r, tx := getSparseMerkleTreeReaderWithMockTX(mockCtrl, rev)
+	// Storage has the nodes at even depths only, whose hashes are their IDs.
+	tx.EXPECT().GetMerkleNodes(gomock.Any(), int64(rev), gomock.Any()).Times(2).DoAndReturn(
+		func(_ context.Context, _ int64, ids []tree.NodeID) ([]tree.Node, error) {
+			var nodes []tree.Node
+			for _, id := range ids {
+				if id.PrefixLenBits%2 == 0 {
+					nodes = append(nodes, tree.Node{NodeID: id, Hash: []byte(id.ToNodeID2().String())})
+				}
+			}
+			return nodes, nil
+		})
+	indices := [][]byte{testonly.HashKey("SomeArbitraryKey"), testonly.HashKey("SomeOtherArbitraryKey")}
+
+	want, err := r.BatchInclusionProof(ctx, rev, indices)
+	if err != nil {
+		t.Fatalf("BatchInclusionProof(): %v", err)
+	}
+	got, err := r.BatchInclusionProofID2(ctx, rev, indices)
+	if err != nil {
+		t.Fatalf("BatchInclusionProofID2(): %v", err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("BatchInclusionProofID2() diff (-got +want):\n%s", diff)
+	}
+	if got, want := len(got[string(indices[0])]), 256; got != want {
+		t.Errorf("proof length %d, want %d", got, want)
+	}
+}
+
+func TestBatchInclusionProofID2RejectsShortIndex(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	r, _ := getSparseMerkleTreeReaderWithMockTX(mockCtrl, 100)
+	if _, err := r.BatchInclusionProofID2(context.Background(), 100, [][]byte{[]byte("short")}); err == nil {
+		t.Error("BatchInclusionProofID2() returned no error for a short index")
+	}
+}
+
 // TODO(al): Add some more inclusion proof tests here
 
 func TestInclusionProofPassesThroughStorageError(t *testing.T) {
@@ -0,0 +1,44 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sumdb provides hashing compatible with the Go checksum database,
+// as implemented by golang.org/x/mod/sumdb/tlog.
+package sumdb
+
+import (
+	"crypto"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/merkle/rfc6962/hasher"
+)
+
+func init() {
+	registry.RegisterLogHasher(trillian.HashStrategy_SUMDB_TLOG_SHA256, DefaultHasher)
+}
+
+// DefaultHasher is the SHA256 based LogHasher of the Go checksum database.
+var DefaultHasher = &Hasher{Hasher: hasher.New(crypto.SHA256)}
+
+// Hasher implements the tree hashing algorithm of golang.org/x/mod/sumdb/tlog.
+// Leaf and node hashes are those of RFC6962, but the hash of the empty tree is
+// all zeros rather than the digest of an empty string.
+type Hasher struct {
+	*hasher.Hasher
+}
+
+// EmptyRoot returns the all-zeros hash of an empty tree.
+func (h *Hasher) EmptyRoot() []byte {
+	return make([]byte, h.Size())
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sumdb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers/registry"
+)
+
+func TestHasher(t *testing.T) {
+	hasher, err := registry.NewLogHasher(trillian.HashStrategy_SUMDB_TLOG_SHA256)
+	if err != nil {
+		t.Fatalf("NewLogHasher(): %v", err)
+	}
+	for _, tc := range []struct {
+		desc string
+		got  []byte
+		want string
+	}{
+		// tlog.TreeHash(0, ...) is the zero Hash.
+		{
+			desc: "Empty",
+			want: "0000000000000000000000000000000000000000000000000000000000000000",
+			got:  hasher.EmptyRoot(),
+		},
+		// tlog.RecordHash([]byte("L123456")), as RFC6962.
+		{
+			desc: "Leaf",
+			want: "395aa064aa4c29f7010acfe3f25db9485bbd4b91897b6ad7ad547639252b4d56",
+			got:  hasher.HashLeaf([]byte("L123456")),
+		},
+		// tlog.NodeHash of "N123" and "N456", as RFC6962.
+		{
+			desc: "Node",
+			want: "aa217fe888e47007fa15edab33c2b492a722cb106c64667fc2b044444de66bbb",
+			got:  hasher.HashChildren([]byte("N123"), []byte("N456")),
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			want, err := hex.DecodeString(tc.want)
+			if err != nil {
+				t.Fatalf("hex.DecodeString(%x): %v", tc.want, err)
+			}
+			if !bytes.Equal(tc.got, want) {
+				t.Errorf("got %x, want %x", tc.got, want)
+			}
+		})
+	}
+	if got, want := hasher.Size(), 32; got != want {
+		t.Errorf("Size()=%d, want %d", got, want)
+	}
+}
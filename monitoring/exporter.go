@@ -0,0 +1,89 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// NewExporterFunc is the signature of a function which can be registered to
+// create MetricFactory instances whose metrics are exported to a particular
+// monitoring system. Any configuration the exporter needs, such as the address
+// of the system, should be supplied through flags defined by the package
+// registering it.
+type NewExporterFunc func() (MetricFactory, error)
+
+var (
+	expMu     sync.RWMutex
+	expByName = make(map[string]NewExporterFunc)
+)
+
+// RegisterExporter registers the given metrics exporter under name.
+func RegisterExporter(name string, f NewExporterFunc) error {
+	expMu.Lock()
+	defer expMu.Unlock()
+
+	if _, exists := expByName[name]; exists {
+		return fmt.Errorf("metrics exporter %v already registered", name)
+	}
+	expByName[name] = f
+	return nil
+}
+
+// Exporters returns the names of all registered metrics exporters, sorted.
+func Exporters() []string {
+	expMu.RLock()
+	defer expMu.RUnlock()
+
+	r := []string{}
+	for k := range expByName {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// NewExporterMetricFactory returns a MetricFactory whose metrics are exported
+// to each of the named exporters. At least one exporter must be named, and
+// each may be named only once.
+func NewExporterMetricFactory(names ...string) (MetricFactory, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no metrics exporters specified, want one or more of %v", Exporters())
+	}
+
+	expMu.RLock()
+	defer expMu.RUnlock()
+
+	mfs := make([]MetricFactory, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			return nil, fmt.Errorf("metrics exporter %v specified more than once", name)
+		}
+		seen[name] = true
+		f := expByName[name]
+		if f == nil {
+			return nil, fmt.Errorf("no such metrics exporter %v", name)
+		}
+		mf, err := f()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create metrics exporter %v: %v", name, err)
+		}
+		mfs = append(mfs, mf)
+	}
+	return NewMultiMetricFactory(mfs...), nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/monitoring/testonly"
+)
+
+func TestMultiMetricFactory(t *testing.T) {
+	a, b := monitoring.InertMetricFactory{}, monitoring.InertMetricFactory{}
+	mf := monitoring.NewMultiMetricFactory(a, b)
+	testonly.TestCounter(t, mf)
+	testonly.TestGauge(t, mf)
+	testonly.TestHistogram(t, mf)
+}
+
+func TestMultiMetricFactoryUpdatesAll(t *testing.T) {
+	var created []monitoring.Counter
+	newFactory := func() monitoring.MetricFactory {
+		return recordingFactory{MetricFactory: monitoring.InertMetricFactory{}, created: &created}
+	}
+	mf := monitoring.NewMultiMetricFactory(newFactory(), newFactory())
+
+	c := mf.NewCounter("requests", "Test only", "method")
+	c.Add(3, "get")
+	if got, want := len(created), 2; got != want {
+		t.Fatalf("created %d counters, want %d", got, want)
+	}
+	for i, c := range created {
+		if got, want := c.Value("get"), 3.0; got != want {
+			t.Errorf("counter %d Value()=%v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestNewExporterMetricFactory(t *testing.T) {
+	if err := monitoring.RegisterExporter("test-inert", func() (monitoring.MetricFactory, error) {
+		return monitoring.InertMetricFactory{}, nil
+	}); err != nil {
+		t.Fatalf("RegisterExporter()=%v", err)
+	}
+	if err := monitoring.RegisterExporter("test-broken", func() (monitoring.MetricFactory, error) {
+		return nil, errors.New("no backend")
+	}); err != nil {
+		t.Fatalf("RegisterExporter()=%v", err)
+	}
+	if err := monitoring.RegisterExporter("test-inert", nil); err == nil {
+		t.Error("RegisterExporter(duplicate)=nil, want error")
+	}
+
+	for _, test := range []struct {
+		names   []string
+		wantErr bool
+	}{
+		{names: []string{"test-inert"}},
+		{names: nil, wantErr: true},
+		{names: []string{"test-missing"}, wantErr: true},
+		{names: []string{"test-broken"}, wantErr: true},
+		{names: []string{"test-inert", "test-inert"}, wantErr: true},
+	} {
+		mf, err := monitoring.NewExporterMetricFactory(test.names...)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("NewExporterMetricFactory(%v)=%v, want err? %v", test.names, err, test.wantErr)
+			continue
+		}
+		if err == nil && mf == nil {
+			t.Errorf("NewExporterMetricFactory(%v) returned nil factory", test.names)
+		}
+	}
+}
+
+// recordingFactory records the counters it creates.
+type recordingFactory struct {
+	monitoring.MetricFactory
+	created *[]monitoring.Counter
+}
+
+func (f recordingFactory) NewCounter(name, help string, labelNames ...string) monitoring.Counter {
+	c := f.MetricFactory.NewCounter(name, help, labelNames...)
+	*f.created = append(*f.created, c)
+	return c
+}
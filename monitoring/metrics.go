@@ -14,6 +14,8 @@
 
 package monitoring
 
+import "context"
+
 // MetricFactory allows the creation of different types of metric.
 type MetricFactory interface {
 	NewCounter(name, help string, labelNames ...string) Counter
@@ -48,3 +50,24 @@ type Histogram interface {
 	// This is only really useful for testing implementations.
 	Info(labelVals ...string) (uint64, float64)
 }
+
+// ExemplarHistogram is implemented by Histograms which can attach an exemplar,
+// i.e. a set of labels identifying an example of an observation such as the
+// trace it was made in, to the observations they record.
+type ExemplarHistogram interface {
+	Histogram
+	ObserveWithExemplar(val float64, exemplar map[string]string, labelVals ...string)
+}
+
+// ObserveContext adds an observation to the histogram. If ctx is part of a
+// sampled trace and the histogram implements ExemplarHistogram, the trace ID
+// is attached to the observation as an exemplar, under TraceIDLabel.
+func ObserveContext(ctx context.Context, h Histogram, val float64, labelVals ...string) {
+	if eh, ok := h.(ExemplarHistogram); ok {
+		if id := TraceID(ctx); id != "" {
+			eh.ObserveWithExemplar(val, map[string]string{TraceIDLabel: id}, labelVals...)
+			return
+		}
+	}
+	h.Observe(val, labelVals...)
+}
@@ -15,8 +15,10 @@
 package monitoring_test
 
 import (
+	"context"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/testonly"
 )
@@ -32,3 +34,41 @@ func TestGauge(t *testing.T) {
 func TestHistogram(t *testing.T) {
 	testonly.TestHistogram(t, monitoring.InertMetricFactory{})
 }
+
+// exemplarHistogram records the exemplars of the observations made on it.
+type exemplarHistogram struct {
+	monitoring.Histogram
+	exemplars []map[string]string
+}
+
+func (h *exemplarHistogram) ObserveWithExemplar(val float64, exemplar map[string]string, labelVals ...string) {
+	h.Observe(val, labelVals...)
+	h.exemplars = append(h.exemplars, exemplar)
+}
+
+type traceKey struct{}
+
+func TestObserveContext(t *testing.T) {
+	monitoring.SetTraceID(func(ctx context.Context) string {
+		id, _ := ctx.Value(traceKey{}).(string)
+		return id
+	})
+	defer monitoring.SetTraceID(func(context.Context) string { return "" })
+
+	mf := monitoring.InertMetricFactory{}
+	h := &exemplarHistogram{Histogram: mf.NewHistogram("exemplars", "Test only", "method")}
+	plain := mf.NewHistogram("plain", "Test only", "method")
+
+	traced := context.WithValue(context.Background(), traceKey{}, "4bf92f3577b34da6a3ce929d0e0e4736")
+	for _, hist := range []monitoring.Histogram{h, plain} {
+		monitoring.ObserveContext(traced, hist, 1, "get")
+		monitoring.ObserveContext(context.Background(), hist, 2, "get")
+		if count, sum := hist.Info("get"); count != 2 || sum != 3 {
+			t.Errorf("Info()=(%d, %v), want (2, 3)", count, sum)
+		}
+	}
+	want := []map[string]string{{monitoring.TraceIDLabel: "4bf92f3577b34da6a3ce929d0e0e4736"}}
+	if !cmp.Equal(h.exemplars, want) {
+		t.Errorf("exemplars=%v, want %v", h.exemplars, want)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+// NewMultiMetricFactory returns a MetricFactory whose metrics update the
+// corresponding metrics of all the given factories. Values are read from the
+// first factory. At least one factory must be given, and if only one is it is
+// returned unchanged.
+func NewMultiMetricFactory(mfs ...MetricFactory) MetricFactory {
+	if len(mfs) == 1 {
+		return mfs[0]
+	}
+	return multiMetricFactory(mfs)
+}
+
+type multiMetricFactory []MetricFactory
+
+// NewCounter creates a Counter in each of the underlying factories.
+func (mmf multiMetricFactory) NewCounter(name, help string, labelNames ...string) Counter {
+	m := make(multiCounter, 0, len(mmf))
+	for _, mf := range mmf {
+		m = append(m, mf.NewCounter(name, help, labelNames...))
+	}
+	return m
+}
+
+// NewGauge creates a Gauge in each of the underlying factories.
+func (mmf multiMetricFactory) NewGauge(name, help string, labelNames ...string) Gauge {
+	m := make(multiGauge, 0, len(mmf))
+	for _, mf := range mmf {
+		m = append(m, mf.NewGauge(name, help, labelNames...))
+	}
+	return m
+}
+
+// NewHistogram creates a Histogram in each of the underlying factories.
+func (mmf multiMetricFactory) NewHistogram(name, help string, labelNames ...string) Histogram {
+	m := make(multiHistogram, 0, len(mmf))
+	for _, mf := range mmf {
+		m = append(m, mf.NewHistogram(name, help, labelNames...))
+	}
+	return m
+}
+
+// NewHistogramWithBuckets creates a Histogram with the supplied buckets in
+// each of the underlying factories.
+func (mmf multiMetricFactory) NewHistogramWithBuckets(name, help string, buckets []float64, labelNames ...string) Histogram {
+	m := make(multiHistogram, 0, len(mmf))
+	for _, mf := range mmf {
+		m = append(m, mf.NewHistogramWithBuckets(name, help, buckets, labelNames...))
+	}
+	return m
+}
+
+type multiCounter []Counter
+
+func (m multiCounter) Inc(labelVals ...string) {
+	for _, c := range m {
+		c.Inc(labelVals...)
+	}
+}
+
+func (m multiCounter) Add(val float64, labelVals ...string) {
+	for _, c := range m {
+		c.Add(val, labelVals...)
+	}
+}
+
+func (m multiCounter) Value(labelVals ...string) float64 {
+	return m[0].Value(labelVals...)
+}
+
+type multiGauge []Gauge
+
+func (m multiGauge) Inc(labelVals ...string) {
+	for _, g := range m {
+		g.Inc(labelVals...)
+	}
+}
+
+func (m multiGauge) Dec(labelVals ...string) {
+	for _, g := range m {
+		g.Dec(labelVals...)
+	}
+}
+
+func (m multiGauge) Add(val float64, labelVals ...string) {
+	for _, g := range m {
+		g.Add(val, labelVals...)
+	}
+}
+
+func (m multiGauge) Set(val float64, labelVals ...string) {
+	for _, g := range m {
+		g.Set(val, labelVals...)
+	}
+}
+
+func (m multiGauge) Value(labelVals ...string) float64 {
+	return m[0].Value(labelVals...)
+}
+
+type multiHistogram []Histogram
+
+func (m multiHistogram) Observe(val float64, labelVals ...string) {
+	for _, h := range m {
+		h.Observe(val, labelVals...)
+	}
+}
+
+func (m multiHistogram) Info(labelVals ...string) (uint64, float64) {
+	return m[0].Info(labelVals...)
+}
+
+// ObserveWithExemplar implements ExemplarHistogram. The exemplar is dropped
+// for underlying histograms which don't support exemplars.
+func (m multiHistogram) ObserveWithExemplar(val float64, exemplar map[string]string, labelVals ...string) {
+	for _, h := range m {
+		if eh, ok := h.(ExemplarHistogram); ok {
+			eh.ObserveWithExemplar(val, exemplar, labelVals...)
+		} else {
+			h.Observe(val, labelVals...)
+		}
+	}
+}
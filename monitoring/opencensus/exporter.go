@@ -0,0 +1,130 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opencensus
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"contrib.go.opencensus.io/exporter/stackdriver"
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/trace"
+)
+
+// StackdriverExporterName identifies the Stackdriver metrics and trace
+// exporters.
+const StackdriverExporterName = "stackdriver"
+
+var (
+	stackdriverProjectID = flag.String("stackdriver_metrics_project_id", "", "Project ID to export metrics to. Can be empty for GCP, consult docs for other platforms. "+
+		"Only effective if metrics are exported to stackdriver.")
+	stackdriverInterval = flag.Duration("stackdriver_metrics_interval", time.Minute, "How often metrics are exported to stackdriver")
+)
+
+func init() {
+	if err := monitoring.RegisterExporter(StackdriverExporterName, newStackdriverMetricFactory); err != nil {
+		glog.Fatalf("Failed to register metrics exporter %v: %v", StackdriverExporterName, err)
+	}
+	if err := RegisterTraceExporter(StackdriverExporterName, func(projectID string) (trace.Exporter, error) {
+		return stackdriverExporter(projectID)
+	}); err != nil {
+		glog.Fatalf("Failed to register trace exporter %v: %v", StackdriverExporterName, err)
+	}
+}
+
+var (
+	sdMu        sync.Mutex
+	sdByProject = make(map[string]*stackdriver.Exporter)
+)
+
+// stackdriverExporter returns the Stackdriver exporter for projectID, which is
+// registered to export views. Exporters are shared so that views aren't
+// exported more than once when both metrics and traces go to Stackdriver.
+func stackdriverExporter(projectID string) (*stackdriver.Exporter, error) {
+	sdMu.Lock()
+	defer sdMu.Unlock()
+
+	if sde := sdByProject[projectID]; sde != nil {
+		return sde, nil
+	}
+	sde, err := stackdriver.NewExporter(stackdriver.Options{ProjectID: projectID})
+	if err != nil {
+		return nil, err
+	}
+	view.RegisterExporter(sde)
+	sdByProject[projectID] = sde
+	return sde, nil
+}
+
+func newStackdriverMetricFactory() (monitoring.MetricFactory, error) {
+	if _, err := stackdriverExporter(*stackdriverProjectID); err != nil {
+		return nil, err
+	}
+	view.SetReportingPeriod(*stackdriverInterval)
+	return MetricFactory{}, nil
+}
+
+// NewTraceExporterFunc is the signature of a function which can be registered
+// to create exporters that trace spans are sent to. The projectID is the cloud
+// project that spans belong to, and may be ignored by exporters which don't
+// need one.
+type NewTraceExporterFunc func(projectID string) (trace.Exporter, error)
+
+var (
+	traceMu     sync.RWMutex
+	traceByName = make(map[string]NewTraceExporterFunc)
+)
+
+// RegisterTraceExporter registers the given trace exporter under name.
+func RegisterTraceExporter(name string, f NewTraceExporterFunc) error {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+
+	if _, exists := traceByName[name]; exists {
+		return fmt.Errorf("trace exporter %v already registered", name)
+	}
+	traceByName[name] = f
+	return nil
+}
+
+// TraceExporters returns the names of all registered trace exporters, sorted.
+func TraceExporters() []string {
+	traceMu.RLock()
+	defer traceMu.RUnlock()
+
+	r := []string{}
+	for k := range traceByName {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// newTraceExporter creates a trace exporter of the type specified by name.
+func newTraceExporter(name, projectID string) (trace.Exporter, error) {
+	traceMu.RLock()
+	defer traceMu.RUnlock()
+
+	f := traceByName[name]
+	if f == nil {
+		return nil, fmt.Errorf("no such trace exporter %v", name)
+	}
+	return f(projectID)
+}
@@ -0,0 +1,201 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opencensus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+// MetricFactory allows the creation of metrics recorded with OpenCensus stats.
+// Each metric is aggregated by a view, which is exported to whichever view
+// exporters are registered, e.g. Stackdriver. The current values are also
+// tracked locally, so that they can be read back.
+type MetricFactory struct {
+	Prefix string
+}
+
+// NewCounter creates a new Counter, aggregated by a Sum view.
+func (mf MetricFactory) NewCounter(name, help string, labelNames ...string) monitoring.Counter {
+	return &Counter{
+		measure: mf.newMeasure(name, help, view.Sum(), labelNames),
+		local:   monitoring.InertMetricFactory{}.NewCounter(name, help, labelNames...),
+	}
+}
+
+// NewGauge creates a new Gauge, aggregated by a LastValue view.
+func (mf MetricFactory) NewGauge(name, help string, labelNames ...string) monitoring.Gauge {
+	return &Gauge{
+		measure: mf.newMeasure(name, help, view.LastValue(), labelNames),
+		local:   monitoring.InertMetricFactory{}.NewGauge(name, help, labelNames...),
+	}
+}
+
+// NewHistogram creates a new Histogram, aggregated by a Distribution view.
+// The buckets are coarser than monitoring.LatencyBuckets, as Cloud Monitoring
+// accepts at most 200 buckets per distribution.
+func (mf MetricFactory) NewHistogram(name, help string, labelNames ...string) monitoring.Histogram {
+	return mf.NewHistogramWithBuckets(name, help, monitoring.ExpBuckets(0.04, 1.15, 150), labelNames...)
+}
+
+// NewHistogramWithBuckets creates a new Histogram, aggregated by a
+// Distribution view with the supplied bucket boundaries.
+func (mf MetricFactory) NewHistogramWithBuckets(name, help string, buckets []float64, labelNames ...string) monitoring.Histogram {
+	return &Histogram{
+		measure: mf.newMeasure(name, help, view.Distribution(buckets...), labelNames),
+		local:   monitoring.InertMetricFactory{}.NewHistogram(name, help, labelNames...),
+	}
+}
+
+// newMeasure creates a measure and registers a view aggregating it.
+func (mf MetricFactory) newMeasure(name, help string, agg *view.Aggregation, labelNames []string) measure {
+	keys := make([]tag.Key, 0, len(labelNames))
+	for _, l := range labelNames {
+		k, err := tag.NewKey(l)
+		if err != nil {
+			glog.Fatalf("Invalid label %q for metric %v: %v", l, name, err)
+		}
+		keys = append(keys, k)
+	}
+	m := stats.Float64(mf.Prefix+name, help, stats.UnitDimensionless)
+	v := &view.View{
+		Name:        mf.Prefix + name,
+		Description: help,
+		Measure:     m,
+		TagKeys:     keys,
+		Aggregation: agg,
+	}
+	if err := view.Register(v); err != nil {
+		glog.Fatalf("Failed to register view for metric %v: %v", name, err)
+	}
+	return measure{m: m, keys: keys}
+}
+
+// measure is an OpenCensus measure, and the keys of the tags it is recorded
+// with.
+type measure struct {
+	m    *stats.Float64Measure
+	keys []tag.Key
+}
+
+// record records val, tagged with labelVals.
+func (m measure) record(val float64, labelVals []string) error {
+	if len(labelVals) != len(m.keys) {
+		return fmt.Errorf("invalid label count %d for %v; want %d", len(labelVals), m.m.Name(), len(m.keys))
+	}
+	mutators := make([]tag.Mutator, 0, len(labelVals))
+	for i, v := range labelVals {
+		mutators = append(mutators, tag.Upsert(m.keys[i], v))
+	}
+	return stats.RecordWithTags(context.Background(), mutators, m.m.M(val))
+}
+
+// Counter is a Counter recorded with OpenCensus.
+type Counter struct {
+	measure
+	local monitoring.Counter
+}
+
+// Inc adds 1 to a counter.
+func (c *Counter) Inc(labelVals ...string) {
+	c.Add(1.0, labelVals...)
+}
+
+// Add adds the given amount to a counter.
+func (c *Counter) Add(val float64, labelVals ...string) {
+	if err := c.record(val, labelVals); err != nil {
+		glog.Error(err.Error())
+		return
+	}
+	c.local.Add(val, labelVals...)
+}
+
+// Value returns the current amount of a counter.
+func (c *Counter) Value(labelVals ...string) float64 {
+	return c.local.Value(labelVals...)
+}
+
+// Gauge is a Gauge recorded with OpenCensus.
+type Gauge struct {
+	measure
+	local monitoring.Gauge
+	// mu ensures that values are recorded in the order they are computed.
+	mu sync.Mutex
+}
+
+// Inc adds 1 to a gauge.
+func (g *Gauge) Inc(labelVals ...string) {
+	g.Add(1.0, labelVals...)
+}
+
+// Dec subtracts 1 from a gauge.
+func (g *Gauge) Dec(labelVals ...string) {
+	g.Add(-1.0, labelVals...)
+}
+
+// Add adds given value to a gauge.
+func (g *Gauge) Add(val float64, labelVals ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.set(g.local.Value(labelVals...)+val, labelVals)
+}
+
+// Set sets the value of a gauge.
+func (g *Gauge) Set(val float64, labelVals ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.set(val, labelVals)
+}
+
+// set records val as the gauge's value. The caller must hold g.mu.
+func (g *Gauge) set(val float64, labelVals []string) {
+	if err := g.record(val, labelVals); err != nil {
+		glog.Error(err.Error())
+		return
+	}
+	g.local.Set(val, labelVals...)
+}
+
+// Value returns the current value of a gauge.
+func (g *Gauge) Value(labelVals ...string) float64 {
+	return g.local.Value(labelVals...)
+}
+
+// Histogram is a Histogram recorded with OpenCensus.
+type Histogram struct {
+	measure
+	local monitoring.Histogram
+}
+
+// Observe adds a single observation to the histogram.
+func (h *Histogram) Observe(val float64, labelVals ...string) {
+	if err := h.record(val, labelVals); err != nil {
+		glog.Error(err.Error())
+		return
+	}
+	h.local.Observe(val, labelVals...)
+}
+
+// Info returns the count and sum of observations for the histogram.
+func (h *Histogram) Info(labelVals ...string) (uint64, float64) {
+	return h.local.Info(labelVals...)
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opencensus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/trillian/monitoring/testonly"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/trace"
+)
+
+func TestCounter(t *testing.T) {
+	testonly.TestCounter(t, MetricFactory{Prefix: "TestCounter"})
+}
+
+func TestGauge(t *testing.T) {
+	testonly.TestGauge(t, MetricFactory{Prefix: "TestGauge"})
+}
+
+func TestHistogram(t *testing.T) {
+	testonly.TestHistogram(t, MetricFactory{Prefix: "TestHistogram"})
+}
+
+func TestViews(t *testing.T) {
+	mf := MetricFactory{Prefix: "TestViews_"}
+	counter := mf.NewCounter("counter", "Test only", "tree_id")
+	gauge := mf.NewGauge("gauge", "Test only")
+	histogram := mf.NewHistogramWithBuckets("histogram", "Test only", []float64{1, 2})
+
+	counter.Add(2, "1")
+	counter.Inc("1")
+	gauge.Set(5)
+	gauge.Dec()
+	histogram.Observe(0.5)
+	histogram.Observe(1.5)
+
+	rows := func(name string) []*view.Row {
+		t.Helper()
+		rows, err := view.RetrieveData(name)
+		if err != nil {
+			t.Fatalf("RetrieveData(%v)=%v", name, err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("RetrieveData(%v) returned %d rows, want 1", name, len(rows))
+		}
+		return rows
+	}
+	if r := rows("TestViews_counter"); r[0].Data.(*view.SumData).Value != 3 || r[0].Tags[0].Value != "1" {
+		t.Errorf("counter row = %v, want sum 3 tagged with tree_id 1", r[0])
+	}
+	if r := rows("TestViews_gauge"); r[0].Data.(*view.LastValueData).Value != 4 {
+		t.Errorf("gauge row = %v, want last value 4", r[0])
+	}
+	d := rows("TestViews_histogram")[0].Data.(*view.DistributionData)
+	if d.Count != 2 || d.CountPerBucket[0] != 1 || d.CountPerBucket[1] != 1 {
+		t.Errorf("histogram data = %+v, want one observation in each of the first two buckets", d)
+	}
+}
+
+type nopExporter struct{}
+
+func (nopExporter) ExportSpan(*trace.SpanData) {}
+
+func TestEnableRPCServerTracingWithExporter(t *testing.T) {
+	var gotProject string
+	if err := RegisterTraceExporter("test", func(projectID string) (trace.Exporter, error) {
+		gotProject = projectID
+		return nopExporter{}, nil
+	}); err != nil {
+		t.Fatalf("RegisterTraceExporter()=%v", err)
+	}
+	if err := RegisterTraceExporter("test", nil); err == nil {
+		t.Error("RegisterTraceExporter(duplicate)=nil, want error")
+	}
+
+	if _, err := EnableRPCServerTracingWithExporter("missing", "", 0); err == nil {
+		t.Error("EnableRPCServerTracingWithExporter(missing)=nil, want error")
+	}
+	opts, err := EnableRPCServerTracingWithExporter("test", "project", 0)
+	if err != nil {
+		t.Fatalf("EnableRPCServerTracingWithExporter()=%v", err)
+	}
+	if len(opts) == 0 {
+		t.Error("EnableRPCServerTracingWithExporter() returned no server options")
+	}
+	if gotProject != "project" {
+		t.Errorf("exporter created for project %q, want %q", gotProject, "project")
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	ctx := context.Background()
+	if got := TraceID(ctx); got != "" {
+		t.Errorf("TraceID(no span)=%q, want empty", got)
+	}
+
+	sampled, span := trace.StartSpan(ctx, "sampled", trace.WithSampler(trace.AlwaysSample()))
+	defer span.End()
+	if got, want := TraceID(sampled), span.SpanContext().TraceID.String(); got != want {
+		t.Errorf("TraceID(sampled)=%q, want %q", got, want)
+	}
+
+	unsampled, span := trace.StartSpan(ctx, "unsampled", trace.WithSampler(trace.NeverSample()))
+	defer span.End()
+	if got := TraceID(unsampled); got != "" {
+		t.Errorf("TraceID(unsampled)=%q, want empty", got)
+	}
+}
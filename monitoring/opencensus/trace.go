@@ -19,12 +19,15 @@ import (
 	"errors"
 	"net/http"
 
-	"contrib.go.opencensus.io/exporter/stackdriver"
 	"go.opencensus.io/plugin/ocgrpc"
 	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/propagation"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
 )
 
 // This is the same set of views that used to be the default before that
@@ -46,9 +49,34 @@ var serverViews = []*view.View{
 // of traced requests can be set between 0 and 100. Note that 0 does not
 // disable tracing entirely but causes the default configuration to be used.
 func EnableRPCServerTracing(projectID string, percent int) ([]grpc.ServerOption, error) {
-	if err := exporter(projectID); err != nil {
+	return EnableRPCServerTracingWithExporter(StackdriverExporterName, projectID, percent)
+}
+
+// EnableRPCServerTracingWithExporter is like EnableRPCServerTracing, but sends
+// spans to the named trace exporter, which must have been registered with
+// RegisterTraceExporter. The projectID is passed to the exporter.
+func EnableRPCServerTracingWithExporter(name, projectID string, percent int) ([]grpc.ServerOption, error) {
+	h, err := NewRPCServerStatsHandler(name, projectID, percent)
+	if err != nil {
 		return nil, err
 	}
+	return []grpc.ServerOption{grpc.StatsHandler(h)}, nil
+}
+
+// NewRPCServerStatsHandler is like EnableRPCServerTracingWithExporter, but
+// returns the stats handler to install on the GRPC server rather than an
+// option, for servers which chain it with other stats handlers, as GRPC
+// servers only take one.
+//
+// Spans of RPCs are children of the spans of their callers, which are
+// propagated in GRPC metadata either in the binary format of OpenCensus, or
+// in the W3C Trace Context format of OpenTelemetry.
+func NewRPCServerStatsHandler(name, projectID string, percent int) (stats.Handler, error) {
+	exp, err := newTraceExporter(name, projectID)
+	if err != nil {
+		return nil, err
+	}
+	trace.RegisterExporter(exp)
 	if err := applyConfig(percent); err != nil {
 		return nil, err
 	}
@@ -57,7 +85,49 @@ func EnableRPCServerTracing(projectID string, percent int) ([]grpc.ServerOption,
 		return nil, err
 	}
 
-	return []grpc.ServerOption{grpc.StatsHandler(&ocgrpc.ServerHandler{})}, nil
+	return traceContextHandler{&ocgrpc.ServerHandler{}}, nil
+}
+
+// Keys of the GRPC metadata which trace contexts are propagated in.
+const (
+	binaryTraceContextKey = "grpc-trace-bin"
+	traceParentKey        = "traceparent"
+	traceStateKey         = "tracestate"
+)
+
+// traceContextHandler is a stats.Handler which accepts the trace contexts of
+// callers in the W3C Trace Context format, as well as in the binary format
+// which the OpenCensus handler it wraps accepts.
+type traceContextHandler struct {
+	stats.Handler
+}
+
+// TagRPC implements stats.Handler.
+func (h traceContextHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return h.Handler.TagRPC(withBinaryTraceContext(ctx), info)
+}
+
+// withBinaryTraceContext returns ctx with the W3C trace context in its
+// incoming metadata, if any, also set in the binary format, unless the
+// metadata already has a binary trace context.
+func withBinaryTraceContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get(traceParentKey)) == 0 || len(md.Get(binaryTraceContextKey)) > 0 {
+		return ctx
+	}
+	req := &http.Request{Header: http.Header{}}
+	for _, key := range []string{traceParentKey, traceStateKey} {
+		for _, v := range md.Get(key) {
+			req.Header.Add(key, v)
+		}
+	}
+	sc, ok := (&tracecontext.HTTPFormat{}).SpanContextFromRequest(req)
+	if !ok {
+		return ctx
+	}
+	md = md.Copy()
+	md.Set(binaryTraceContextKey, string(propagation.Binary(sc)))
+	return metadata.NewIncomingContext(ctx, md)
 }
 
 // EnableHTTPServerTracing turns on Stackdriver tracing for HTTP requests
@@ -81,11 +151,10 @@ func EnableHTTPServerTracing(projectID string, percent int) (http.Handler, error
 }
 
 func exporter(projectID string) error {
-	sde, err := stackdriver.NewExporter(stackdriver.Options{ProjectID: projectID})
+	sde, err := stackdriverExporter(projectID)
 	if err != nil {
 		return err
 	}
-	view.RegisterExporter(sde)
 	trace.RegisterExporter(sde)
 
 	return nil
@@ -112,3 +181,18 @@ func StartSpan(ctx context.Context, name string) (context.Context, func()) {
 	ctx, span := trace.StartSpan(ctx, name)
 	return ctx, span.End
 }
+
+// TraceID returns the ID of the trace that ctx is part of, if the trace is
+// sampled, and an empty string otherwise. Traces which aren't sampled aren't
+// exported, so linking to them would be pointless.
+func TraceID(ctx context.Context) string {
+	span := trace.FromContext(ctx)
+	if span == nil {
+		return ""
+	}
+	sc := span.SpanContext()
+	if !sc.IsSampled() {
+		return ""
+	}
+	return sc.TraceID.String()
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opencensus
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/propagation"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWithBinaryTraceContext(t *testing.T) {
+	const traceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	other := string(propagation.Binary(trace.SpanContext{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}}))
+	for _, test := range []struct {
+		desc    string
+		md      metadata.MD
+		wantBin bool
+		want    trace.SpanContext
+	}{
+		{desc: "noMetadata"},
+		{desc: "noTraceParent", md: metadata.Pairs("foo", "bar")},
+		{
+			desc:    "traceParent",
+			md:      metadata.Pairs(traceParentKey, traceParent),
+			wantBin: true,
+			want: trace.SpanContext{
+				TraceID:      trace.TraceID{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd, 0x84, 0x48, 0xeb, 0x21, 0x1c, 0x80, 0x31, 0x9c},
+				SpanID:       trace.SpanID{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31},
+				TraceOptions: 1,
+			},
+		},
+		{desc: "badTraceParent", md: metadata.Pairs(traceParentKey, "00-xyz-b7ad6b7169203331-01")},
+		{
+			desc:    "binaryPreferred",
+			md:      metadata.Pairs(traceParentKey, traceParent, binaryTraceContextKey, other),
+			wantBin: true,
+			want:    trace.SpanContext{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			if test.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, test.md)
+			}
+			md, _ := metadata.FromIncomingContext(withBinaryTraceContext(ctx))
+			bin := md.Get(binaryTraceContextKey)
+			if got := len(bin) > 0; got != test.wantBin {
+				t.Fatalf("binary trace context set: %v, want %v", got, test.wantBin)
+			}
+			if !test.wantBin {
+				return
+			}
+			got, ok := propagation.FromBinary([]byte(bin[0]))
+			if !ok {
+				t.Fatalf("FromBinary(%x) failed", bin[0])
+			}
+			if got.TraceID != test.want.TraceID || got.SpanID != test.want.SpanID || got.TraceOptions != test.want.TraceOptions {
+				t.Errorf("binary trace context %v, want %v", got, test.want)
+			}
+		})
+	}
+}
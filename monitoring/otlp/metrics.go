@@ -0,0 +1,387 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+)
+
+// aggregationTemporalityCumulative is the OTLP AggregationTemporality of all
+// sums and histograms: each data point covers the time since the series was
+// first updated.
+const aggregationTemporalityCumulative = 2
+
+// MetricFactory allows the creation of metrics which are held in memory, and
+// periodically pushed to an OTLP receiver by Push.
+type MetricFactory struct {
+	Prefix string
+
+	mu          sync.Mutex
+	instruments []*instrument
+}
+
+// NewMetricFactory returns a MetricFactory whose metric names are prefixed by
+// prefix.
+func NewMetricFactory(prefix string) *MetricFactory {
+	return &MetricFactory{Prefix: prefix}
+}
+
+// NewCounter creates a new Counter, exported as a monotonic cumulative sum.
+func (mf *MetricFactory) NewCounter(name, help string, labelNames ...string) monitoring.Counter {
+	return &Counter{mf.newInstrument(name, help, kindCounter, nil, labelNames)}
+}
+
+// NewGauge creates a new Gauge.
+func (mf *MetricFactory) NewGauge(name, help string, labelNames ...string) monitoring.Gauge {
+	return &Gauge{mf.newInstrument(name, help, kindGauge, nil, labelNames)}
+}
+
+// NewHistogram creates a new Histogram with monitoring.LatencyBuckets.
+func (mf *MetricFactory) NewHistogram(name, help string, labelNames ...string) monitoring.Histogram {
+	return mf.NewHistogramWithBuckets(name, help, monitoring.LatencyBuckets(), labelNames...)
+}
+
+// NewHistogramWithBuckets creates a new Histogram with the supplied bucket
+// upper bounds, which must be sorted.
+func (mf *MetricFactory) NewHistogramWithBuckets(name, help string, buckets []float64, labelNames ...string) monitoring.Histogram {
+	return &Histogram{mf.newInstrument(name, help, kindHistogram, buckets, labelNames)}
+}
+
+func (mf *MetricFactory) newInstrument(name, help string, k kind, bounds []float64, labelNames []string) *instrument {
+	i := &instrument{
+		name:       mf.Prefix + name,
+		help:       help,
+		kind:       k,
+		bounds:     bounds,
+		labelNames: labelNames,
+		series:     make(map[string]*series),
+	}
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+	mf.instruments = append(mf.instruments, i)
+	return i
+}
+
+// Push pushes the current metric values to the receiver every interval, until
+// ctx is done.
+func (mf *MetricFactory) Push(ctx context.Context, c *Client, interval time.Duration) {
+	pushEvery(ctx, interval, "metrics", func(ctx context.Context) error {
+		return mf.Export(ctx, c)
+	})
+}
+
+// Export pushes the current metric values to the receiver once.
+func (mf *MetricFactory) Export(ctx context.Context, c *Client) error {
+	return c.post(ctx, "/v1/metrics", exportMetricsServiceRequest{
+		ResourceMetrics: []resourceMetrics{{
+			Resource: c.resource,
+			ScopeMetrics: []scopeMetrics{{
+				Scope:   instrumentationScope{Name: scopeName},
+				Metrics: mf.snapshot(time.Now()),
+			}},
+		}},
+	})
+}
+
+// snapshot returns the metrics which have at least one series.
+func (mf *MetricFactory) snapshot(now time.Time) []metric {
+	mf.mu.Lock()
+	instruments := append([]*instrument(nil), mf.instruments...)
+	mf.mu.Unlock()
+
+	var metrics []metric
+	for _, i := range instruments {
+		if m, ok := i.snapshot(now); ok {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics
+}
+
+type kind int
+
+const (
+	kindCounter kind = iota
+	kindGauge
+	kindHistogram
+)
+
+// instrument holds the state of all the series of a metric.
+type instrument struct {
+	name, help string
+	kind       kind
+	bounds     []float64
+	labelNames []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series is the state of a metric for one set of label values.
+type series struct {
+	labelVals []string
+	start     time.Time
+	value     float64  // Counters and gauges.
+	count     uint64   // Histograms.
+	sum       float64  // Histograms.
+	buckets   []uint64 // Histograms, with a final overflow bucket.
+}
+
+// update calls f with the series for labelVals, creating it if needed.
+func (i *instrument) update(labelVals []string, f func(*series)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	s, err := i.lookup(labelVals, true)
+	if err != nil {
+		glog.Error(err.Error())
+		return
+	}
+	f(s)
+}
+
+// read calls f with the series for labelVals, or with a zero series if there
+// isn't one yet.
+func (i *instrument) read(labelVals []string, f func(*series)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	s, err := i.lookup(labelVals, false)
+	if err != nil {
+		glog.Error(err.Error())
+		s = nil
+	}
+	if s == nil {
+		s = &series{}
+	}
+	f(s)
+}
+
+// lookup returns the series for labelVals, creating it if create is set. The
+// caller must hold i.mu.
+func (i *instrument) lookup(labelVals []string, create bool) (*series, error) {
+	if len(labelVals) != len(i.labelNames) {
+		return nil, fmt.Errorf("invalid label count %d for %v; want %d", len(labelVals), i.name, len(i.labelNames))
+	}
+	key := strings.Join(labelVals, "\x00")
+	s := i.series[key]
+	if s == nil && create {
+		s = &series{
+			labelVals: append([]string(nil), labelVals...),
+			start:     time.Now(),
+		}
+		if i.kind == kindHistogram {
+			s.buckets = make([]uint64, len(i.bounds)+1)
+		}
+		i.series[key] = s
+	}
+	return s, nil
+}
+
+// snapshot returns the OTLP representation of the instrument's series, or
+// false if there are none.
+func (i *instrument) snapshot(now time.Time) (metric, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.series) == 0 {
+		return metric{}, false
+	}
+	keys := make([]string, 0, len(i.series))
+	for k := range i.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	m := metric{Name: i.name, Description: i.help}
+	var numbers []numberDataPoint
+	var histograms []histogramDataPoint
+	for _, k := range keys {
+		s := i.series[k]
+		attrs := make([]keyValue, 0, len(s.labelVals))
+		for j, v := range s.labelVals {
+			attrs = append(attrs, stringKeyValue(i.labelNames[j], v))
+		}
+		switch i.kind {
+		case kindCounter, kindGauge:
+			numbers = append(numbers, numberDataPoint{
+				Attributes:        attrs,
+				StartTimeUnixNano: nanos(s.start),
+				TimeUnixNano:      nanos(now),
+				AsDouble:          s.value,
+			})
+		case kindHistogram:
+			counts := make([]string, 0, len(s.buckets))
+			for _, c := range s.buckets {
+				counts = append(counts, strconv.FormatUint(c, 10))
+			}
+			histograms = append(histograms, histogramDataPoint{
+				Attributes:        attrs,
+				StartTimeUnixNano: nanos(s.start),
+				TimeUnixNano:      nanos(now),
+				Count:             strconv.FormatUint(s.count, 10),
+				Sum:               s.sum,
+				BucketCounts:      counts,
+				ExplicitBounds:    i.bounds,
+			})
+		}
+	}
+	switch i.kind {
+	case kindCounter:
+		m.Sum = &sum{DataPoints: numbers, AggregationTemporality: aggregationTemporalityCumulative, IsMonotonic: true}
+	case kindGauge:
+		m.Gauge = &gauge{DataPoints: numbers}
+	case kindHistogram:
+		m.Histogram = &histogram{DataPoints: histograms, AggregationTemporality: aggregationTemporalityCumulative}
+	}
+	return m, true
+}
+
+// Counter is a Counter pushed to an OTLP receiver.
+type Counter struct {
+	*instrument
+}
+
+// Inc adds 1 to a counter.
+func (c *Counter) Inc(labelVals ...string) {
+	c.Add(1.0, labelVals...)
+}
+
+// Add adds the given amount to a counter.
+func (c *Counter) Add(val float64, labelVals ...string) {
+	c.update(labelVals, func(s *series) { s.value += val })
+}
+
+// Value returns the current amount of a counter.
+func (c *Counter) Value(labelVals ...string) float64 {
+	var v float64
+	c.read(labelVals, func(s *series) { v = s.value })
+	return v
+}
+
+// Gauge is a Gauge pushed to an OTLP receiver.
+type Gauge struct {
+	*instrument
+}
+
+// Inc adds 1 to a gauge.
+func (g *Gauge) Inc(labelVals ...string) {
+	g.Add(1.0, labelVals...)
+}
+
+// Dec subtracts 1 from a gauge.
+func (g *Gauge) Dec(labelVals ...string) {
+	g.Add(-1.0, labelVals...)
+}
+
+// Add adds given value to a gauge.
+func (g *Gauge) Add(val float64, labelVals ...string) {
+	g.update(labelVals, func(s *series) { s.value += val })
+}
+
+// Set sets the value of a gauge.
+func (g *Gauge) Set(val float64, labelVals ...string) {
+	g.update(labelVals, func(s *series) { s.value = val })
+}
+
+// Value returns the current value of a gauge.
+func (g *Gauge) Value(labelVals ...string) float64 {
+	var v float64
+	g.read(labelVals, func(s *series) { v = s.value })
+	return v
+}
+
+// Histogram is a Histogram pushed to an OTLP receiver.
+type Histogram struct {
+	*instrument
+}
+
+// Observe adds a single observation to the histogram.
+func (h *Histogram) Observe(val float64, labelVals ...string) {
+	h.update(labelVals, func(s *series) {
+		// Buckets include their upper bound.
+		s.buckets[sort.SearchFloat64s(h.bounds, val)]++
+		s.count++
+		s.sum += val
+	})
+}
+
+// Info returns the count and sum of observations for the histogram.
+func (h *Histogram) Info(labelVals ...string) (uint64, float64) {
+	var count uint64
+	var sum float64
+	h.read(labelVals, func(s *series) { count, sum = s.count, s.sum })
+	return count, sum
+}
+
+type exportMetricsServiceRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type scopeMetrics struct {
+	Scope   instrumentationScope `json:"scope"`
+	Metrics []metric             `json:"metrics"`
+}
+
+type metric struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Sum         *sum       `json:"sum,omitempty"`
+	Gauge       *gauge     `json:"gauge,omitempty"`
+	Histogram   *histogram `json:"histogram,omitempty"`
+}
+
+type sum struct {
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+type gauge struct {
+	DataPoints []numberDataPoint `json:"dataPoints"`
+}
+
+type histogram struct {
+	DataPoints             []histogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                  `json:"aggregationTemporality"`
+}
+
+type numberDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsDouble          float64    `json:"asDouble"`
+}
+
+type histogramDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	Count             string     `json:"count"`
+	Sum               float64    `json:"sum"`
+	BucketCounts      []string   `json:"bucketCounts"`
+	ExplicitBounds    []float64  `json:"explicitBounds"`
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp pushes metrics and trace spans to an OpenTelemetry collector,
+// or any other receiver of the OpenTelemetry Protocol (OTLP). Data is sent
+// over HTTP using the JSON encoding of the protocol, to the /v1/metrics and
+// /v1/traces paths of the configured endpoint.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/monitoring/opencensus"
+	"go.opencensus.io/trace"
+)
+
+// ExporterName identifies the OTLP metrics and trace exporters.
+const ExporterName = "otlp"
+
+// scopeName is the instrumentation scope that all data is reported under.
+const scopeName = "github.com/google/trillian"
+
+var (
+	endpoint = flag.String("otlp_endpoint", "http://localhost:4318", "Base URL of the OTLP/HTTP receiver to push metrics and spans to. "+
+		"Only effective if metrics or traces are exported to otlp.")
+	interval    = flag.Duration("otlp_push_interval", 30*time.Second, "How often metrics and spans are pushed to the OTLP receiver")
+	serviceName = flag.String("otlp_service_name", filepath.Base(os.Args[0]), "Service name that metrics and spans pushed to the OTLP receiver are attributed to")
+)
+
+func init() {
+	if err := monitoring.RegisterExporter(ExporterName, func() (monitoring.MetricFactory, error) {
+		mf := NewMetricFactory("")
+		go mf.Push(context.Background(), NewClient(*endpoint, *serviceName), *interval)
+		return mf, nil
+	}); err != nil {
+		glog.Fatalf("Failed to register metrics exporter %v: %v", ExporterName, err)
+	}
+	if err := opencensus.RegisterTraceExporter(ExporterName, func(string) (trace.Exporter, error) {
+		te := NewTraceExporter()
+		go te.Push(context.Background(), NewClient(*endpoint, *serviceName), *interval)
+		return te, nil
+	}); err != nil {
+		glog.Fatalf("Failed to register trace exporter %v: %v", ExporterName, err)
+	}
+}
+
+// Client sends OTLP requests to a receiver.
+type Client struct {
+	endpoint string
+	resource resource
+	hc       *http.Client
+}
+
+// NewClient returns a Client which sends requests to the receiver at the
+// given base URL, attributing data to the named service.
+func NewClient(endpoint, serviceName string) *Client {
+	return &Client{
+		endpoint: endpoint,
+		resource: resource{Attributes: []keyValue{stringKeyValue("service.name", serviceName)}},
+		hc:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// post sends req, encoded as JSON, to the given path of the receiver.
+func (c *Client) post(ctx context.Context, path string, req interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	hreq, err := http.NewRequest(http.MethodPost, c.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	rsp, err := c.hc.Do(hreq.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return fmt.Errorf("%v returned %v: %s", path, rsp.Status, msg)
+	}
+	return nil
+}
+
+// pushEvery calls export every interval until ctx is done, logging failures.
+func pushEvery(ctx context.Context, interval time.Duration, what string, export func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := export(ctx); err != nil {
+			glog.Warningf("Failed to push %s to OTLP receiver: %v", what, err)
+		}
+	}
+}
+
+// The types below mirror the JSON encoding of the OTLP protobuf messages that
+// are sent. Per the protobuf JSON mapping, 64-bit integers are encoded as
+// strings.
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type instrumentationScope struct {
+	Name string `json:"name"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func stringKeyValue(k, v string) keyValue {
+	return keyValue{Key: k, Value: anyValue{StringValue: &v}}
+}
+
+// nanos formats t as the string encoding of a fixed64 Unix nanosecond time.
+func nanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
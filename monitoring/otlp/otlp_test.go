@@ -0,0 +1,210 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/monitoring/testonly"
+	"go.opencensus.io/trace"
+)
+
+func TestCounter(t *testing.T) {
+	testonly.TestCounter(t, NewMetricFactory(""))
+}
+
+func TestGauge(t *testing.T) {
+	testonly.TestGauge(t, NewMetricFactory(""))
+}
+
+func TestHistogram(t *testing.T) {
+	testonly.TestHistogram(t, NewMetricFactory(""))
+}
+
+// receiver is a fake OTLP/HTTP receiver which records the last request body
+// for each path, decoded as generic JSON.
+type receiver struct {
+	bodies map[string]interface{}
+	status int
+}
+
+func newReceiver(t *testing.T) (*receiver, *Client) {
+	t.Helper()
+	r := &receiver{bodies: make(map[string]interface{}), status: http.StatusOK}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if got, want := req.Header.Get("Content-Type"), "application/json"; got != want {
+			t.Errorf("Content-Type=%q, want %q", got, want)
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Errorf("ReadAll()=%v", err)
+		}
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err != nil {
+			t.Errorf("Unmarshal(%s)=%v", body, err)
+		}
+		r.bodies[req.URL.Path] = v
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(srv.Close)
+	return r, NewClient(srv.URL, "test-service")
+}
+
+// decode round-trips v through JSON, to compare with what a receiver saw.
+func decode(t *testing.T, v string) interface{} {
+	t.Helper()
+	var r interface{}
+	if err := json.Unmarshal([]byte(v), &r); err != nil {
+		t.Fatalf("Unmarshal(%s)=%v", v, err)
+	}
+	return r
+}
+
+func TestMetricFactoryExport(t *testing.T) {
+	ctx := context.Background()
+	r, c := newReceiver(t)
+	mf := NewMetricFactory("test_")
+	counter := mf.NewCounter("requests", "Requests served", "method")
+	gauge := mf.NewGauge("depth", "Queue depth")
+	histogram := mf.NewHistogramWithBuckets("latency", "Latency", []float64{1, 2})
+	mf.NewCounter("unused", "Never updated")
+
+	counter.Add(2, "get")
+	gauge.Set(-3)
+	for _, v := range []float64{0.5, 1, 1.5, 3} {
+		histogram.Observe(v)
+	}
+
+	if err := mf.Export(ctx, c); err != nil {
+		t.Fatalf("Export()=%v", err)
+	}
+	got := r.bodies["/v1/metrics"]
+	// Drop the timestamps, which are checked for presence below.
+	var starts int
+	for _, m := range got.(map[string]interface{})["resourceMetrics"].([]interface{})[0].(map[string]interface{})["scopeMetrics"].([]interface{})[0].(map[string]interface{})["metrics"].([]interface{}) {
+		for _, data := range m.(map[string]interface{}) {
+			data, ok := data.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, dp := range data["dataPoints"].([]interface{}) {
+				dp := dp.(map[string]interface{})
+				if dp["startTimeUnixNano"] != "" && dp["timeUnixNano"] != "" {
+					starts++
+				}
+				delete(dp, "startTimeUnixNano")
+				delete(dp, "timeUnixNano")
+			}
+		}
+	}
+	if starts != 3 {
+		t.Errorf("found timestamps on %d data points, want 3", starts)
+	}
+
+	want := decode(t, `{"resourceMetrics": [{
+		"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "test-service"}}]},
+		"scopeMetrics": [{
+			"scope": {"name": "github.com/google/trillian"},
+			"metrics": [
+				{"name": "test_requests", "description": "Requests served", "sum": {
+					"dataPoints": [{"attributes": [{"key": "method", "value": {"stringValue": "get"}}], "asDouble": 2}],
+					"aggregationTemporality": 2, "isMonotonic": true}},
+				{"name": "test_depth", "description": "Queue depth", "gauge": {
+					"dataPoints": [{"asDouble": -3}]}},
+				{"name": "test_latency", "description": "Latency", "histogram": {
+					"dataPoints": [{"count": "4", "sum": 6, "bucketCounts": ["2", "1", "1"], "explicitBounds": [1, 2]}],
+					"aggregationTemporality": 2}}
+			]
+		}]
+	}]}`)
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("exported metrics diff (-got +want):\n%s", diff)
+	}
+
+	r.status = http.StatusServiceUnavailable
+	if err := mf.Export(ctx, c); err == nil {
+		t.Error("Export()=nil for failing receiver, want error")
+	}
+}
+
+func TestTraceExporterFlush(t *testing.T) {
+	ctx := context.Background()
+	r, c := newReceiver(t)
+	e := NewTraceExporter()
+
+	if err := e.Flush(ctx, c); err != nil {
+		t.Fatalf("Flush(empty)=%v", err)
+	}
+	if _, ok := r.bodies["/v1/traces"]; ok {
+		t.Error("Flush(empty) sent a request")
+	}
+
+	start := time.Unix(1600000000, 0)
+	e.ExportSpan(&trace.SpanData{
+		SpanContext: trace.SpanContext{
+			TraceID: trace.TraceID{0x01, 0x02},
+			SpanID:  trace.SpanID{0x03},
+		},
+		ParentSpanID: trace.SpanID{0x04},
+		SpanKind:     trace.SpanKindServer,
+		Name:         "trillian.TrillianLog.QueueLeaf",
+		StartTime:    start,
+		EndTime:      start.Add(time.Second),
+		Attributes:   map[string]interface{}{"tree_id": int64(12), "ok": false},
+		Status:       trace.Status{Code: 5, Message: "not found"},
+	})
+	if err := e.Flush(ctx, c); err != nil {
+		t.Fatalf("Flush()=%v", err)
+	}
+	want := decode(t, `{"resourceSpans": [{
+		"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "test-service"}}]},
+		"scopeSpans": [{
+			"scope": {"name": "github.com/google/trillian"},
+			"spans": [{
+				"traceId": "01020000000000000000000000000000",
+				"spanId": "0300000000000000",
+				"parentSpanId": "0400000000000000",
+				"name": "trillian.TrillianLog.QueueLeaf",
+				"kind": 2,
+				"startTimeUnixNano": "1600000000000000000",
+				"endTimeUnixNano": "1600000001000000000",
+				"attributes": [
+					{"key": "ok", "value": {"boolValue": false}},
+					{"key": "tree_id", "value": {"intValue": "12"}}
+				],
+				"status": {"code": 2, "message": "not found"}
+			}]
+		}]
+	}]}`)
+	if diff := cmp.Diff(r.bodies["/v1/traces"], want); diff != "" {
+		t.Errorf("exported spans diff (-got +want):\n%s", diff)
+	}
+
+	// Spans are sent only once.
+	delete(r.bodies, "/v1/traces")
+	if err := e.Flush(ctx, c); err != nil {
+		t.Fatalf("Flush()=%v", err)
+	}
+	if _, ok := r.bodies["/v1/traces"]; ok {
+		t.Error("Flush() resent spans")
+	}
+}
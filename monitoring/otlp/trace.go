@@ -0,0 +1,185 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"go.opencensus.io/trace"
+)
+
+// maxBufferedSpans is the number of spans buffered between pushes, beyond
+// which further spans are dropped.
+const maxBufferedSpans = 10000
+
+// OTLP Span.SpanKind and Status.StatusCode values.
+const (
+	spanKindInternal = 1
+	spanKindServer   = 2
+	spanKindClient   = 3
+
+	statusCodeError = 2
+)
+
+// TraceExporter is an OpenCensus trace.Exporter which buffers spans, to be
+// pushed to an OTLP receiver by Push.
+type TraceExporter struct {
+	mu      sync.Mutex
+	spans   []*trace.SpanData
+	dropped int
+}
+
+// NewTraceExporter returns a new TraceExporter.
+func NewTraceExporter() *TraceExporter {
+	return &TraceExporter{}
+}
+
+// ExportSpan buffers a completed span.
+func (e *TraceExporter) ExportSpan(sd *trace.SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.spans) >= maxBufferedSpans {
+		e.dropped++
+		return
+	}
+	e.spans = append(e.spans, sd)
+}
+
+// Push pushes the buffered spans to the receiver every interval, until ctx is
+// done.
+func (e *TraceExporter) Push(ctx context.Context, c *Client, interval time.Duration) {
+	pushEvery(ctx, interval, "spans", func(ctx context.Context) error {
+		return e.Flush(ctx, c)
+	})
+}
+
+// Flush pushes the buffered spans to the receiver. The spans are discarded
+// even if they can't be sent, so that a broken receiver can't cause them to
+// accumulate.
+func (e *TraceExporter) Flush(ctx context.Context, c *Client) error {
+	e.mu.Lock()
+	spans, dropped := e.spans, e.dropped
+	e.spans, e.dropped = nil, 0
+	e.mu.Unlock()
+
+	if dropped > 0 {
+		glog.Warningf("Dropped %d spans as the OTLP receiver isn't keeping up", dropped)
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	out := make([]span, 0, len(spans))
+	for _, sd := range spans {
+		out = append(out, toSpan(sd))
+	}
+	return c.post(ctx, "/v1/traces", exportTraceServiceRequest{
+		ResourceSpans: []resourceSpans{{
+			Resource: c.resource,
+			ScopeSpans: []scopeSpans{{
+				Scope: instrumentationScope{Name: scopeName},
+				Spans: out,
+			}},
+		}},
+	})
+}
+
+// toSpan converts an OpenCensus span to its OTLP representation.
+func toSpan(sd *trace.SpanData) span {
+	s := span{
+		TraceID:           sd.TraceID.String(),
+		SpanID:            sd.SpanID.String(),
+		Name:              sd.Name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: nanos(sd.StartTime),
+		EndTimeUnixNano:   nanos(sd.EndTime),
+	}
+	if sd.ParentSpanID != (trace.SpanID{}) {
+		s.ParentSpanID = sd.ParentSpanID.String()
+	}
+	switch sd.SpanKind {
+	case trace.SpanKindServer:
+		s.Kind = spanKindServer
+	case trace.SpanKindClient:
+		s.Kind = spanKindClient
+	}
+	keys := make([]string, 0, len(sd.Attributes))
+	for k := range sd.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s.Attributes = append(s.Attributes, attribute(k, sd.Attributes[k]))
+	}
+	if sd.Code != 0 {
+		s.Status = status{Code: statusCodeError, Message: sd.Message}
+	}
+	return s
+}
+
+// attribute converts an OpenCensus attribute value to an OTLP key/value.
+func attribute(k string, v interface{}) keyValue {
+	switch v := v.(type) {
+	case bool:
+		return keyValue{Key: k, Value: anyValue{BoolValue: &v}}
+	case int64:
+		i := strconv.FormatInt(v, 10)
+		return keyValue{Key: k, Value: anyValue{IntValue: &i}}
+	case float64:
+		return keyValue{Key: k, Value: anyValue{DoubleValue: &v}}
+	case string:
+		return stringKeyValue(k, v)
+	default:
+		return stringKeyValue(k, fmt.Sprint(v))
+	}
+}
+
+type exportTraceServiceRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type scopeSpans struct {
+	Scope instrumentationScope `json:"scope"`
+	Spans []span               `json:"spans"`
+}
+
+// span is an OTLP span. Per the OTLP JSON encoding, trace and span IDs are
+// hex strings.
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type status struct {
+	Message string `json:"message,omitempty"`
+	Code    int    `json:"code"`
+}
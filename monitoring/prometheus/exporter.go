@@ -0,0 +1,32 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+)
+
+// ExporterName identifies the Prometheus metrics exporter. Metrics are pulled
+// by Prometheus from the /metrics page of the binary's HTTP endpoint.
+const ExporterName = "prometheus"
+
+func init() {
+	if err := monitoring.RegisterExporter(ExporterName, func() (monitoring.MetricFactory, error) {
+		return MetricFactory{}, nil
+	}); err != nil {
+		glog.Fatalf("Failed to register metrics exporter %v: %v", ExporterName, err)
+	}
+}
@@ -18,6 +18,7 @@ package prometheus
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/golang/glog"
 	"github.com/google/trillian/monitoring"
@@ -277,6 +278,54 @@ func (m *Histogram) Observe(val float64, labelVals ...string) {
 	}
 }
 
+// ObserveWithExemplar adds a single observation to the histogram, and makes
+// the exemplar the one exported for the bucket the observation falls into.
+// Exemplars are only exposed when metrics are scraped in the OpenMetrics
+// format. Invalid exemplars, e.g. with labels that are too long, are dropped.
+func (m *Histogram) ObserveWithExemplar(val float64, exemplar map[string]string, labelVals ...string) {
+	labels, err := labelsFor(m.labelNames, labelVals)
+	if err != nil {
+		glog.Error(err.Error())
+		return
+	}
+	var obs prometheus.Observer = m.single
+	if m.vec != nil {
+		obs = m.vec.With(labels)
+	}
+	eo, ok := obs.(prometheus.ExemplarObserver)
+	if !ok || !validExemplar(exemplar) {
+		obs.Observe(val)
+		return
+	}
+	eo.ObserveWithExemplar(val, prometheus.Labels(exemplar))
+}
+
+// validExemplar returns whether the exemplar labels would be accepted by
+// Prometheus, which panics otherwise.
+func validExemplar(exemplar map[string]string) bool {
+	runes := 0
+	for k, v := range exemplar {
+		if !validLabelName(k) || !utf8.ValidString(v) {
+			return false
+		}
+		runes += utf8.RuneCountInString(k) + utf8.RuneCountInString(v)
+	}
+	return runes <= prometheus.ExemplarMaxRunes
+}
+
+// validLabelName returns whether name matches [a-zA-Z_][a-zA-Z0-9_]*.
+func validLabelName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= '0' && c <= '9' && i > 0) {
+			return false
+		}
+	}
+	return true
+}
+
 // Info returns the count and sum of observations for the histogram.
 func (m *Histogram) Info(labelVals ...string) (uint64, float64) {
 	labels, err := labelsFor(m.labelNames, labelVals)
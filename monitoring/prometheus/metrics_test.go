@@ -15,9 +15,13 @@
 package prometheus
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/testonly"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 )
 
 func TestCounter(t *testing.T) {
@@ -31,3 +35,46 @@ func TestGauge(t *testing.T) {
 func TestHistogram(t *testing.T) {
 	testonly.TestHistogram(t, MetricFactory{Prefix: "TestHistogram"})
 }
+
+func TestHistogramExemplars(t *testing.T) {
+	mf := MetricFactory{Prefix: "TestHistogramExemplars"}
+	for _, labelNames := range [][]string{nil, {"method"}} {
+		name := "single"
+		if len(labelNames) > 0 {
+			name = "vec"
+		}
+		t.Run(name, func(t *testing.T) {
+			h := mf.NewHistogramWithBuckets(name, "Test only", []float64{1, 10}, labelNames...).(monitoring.ExemplarHistogram)
+			labelVals := make([]string, len(labelNames))
+			for i := range labelVals {
+				labelVals[i] = "get"
+			}
+
+			h.ObserveWithExemplar(0.5, map[string]string{monitoring.TraceIDLabel: "4bf92f3577b34da6a3ce929d0e0e4736"}, labelVals...)
+			// Invalid exemplars are dropped, rather than causing a panic.
+			h.ObserveWithExemplar(5, map[string]string{monitoring.TraceIDLabel: strings.Repeat("a", 100)}, labelVals...)
+			h.ObserveWithExemplar(5, map[string]string{"trace-id": "a"}, labelVals...)
+
+			if count, sum := h.Info(labelVals...); count != 3 || sum != 10.5 {
+				t.Errorf("Info()=(%d, %v), want (3, 10.5)", count, sum)
+			}
+			var metric prometheus.Metric
+			if ph := h.(*Histogram); ph.vec != nil {
+				metric = ph.vec.WithLabelValues(labelVals...).(prometheus.Metric)
+			} else {
+				metric = ph.single
+			}
+			var pb dto.Metric
+			if err := metric.Write(&pb); err != nil {
+				t.Fatalf("Write()=%v", err)
+			}
+			buckets := pb.GetHistogram().GetBucket()
+			if got := buckets[0].GetExemplar().GetLabel(); len(got) != 1 || got[0].GetValue() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("bucket[0] exemplar labels=%v, want trace ID", got)
+			}
+			if got := buckets[1].GetExemplar(); got != nil {
+				t.Errorf("bucket[1] exemplar=%v, want none", got)
+			}
+		})
+	}
+}
@@ -59,10 +59,10 @@ func prefixedName(prefix, name string) string {
 	return fmt.Sprintf("%s_%s", prefix, name)
 }
 
-func (r *RPCStatsInterceptor) recordFailureLatency(labels []string, startTime time.Time) {
+func (r *RPCStatsInterceptor) recordFailureLatency(ctx context.Context, labels []string, startTime time.Time) {
 	latency := clock.SecondsSince(r.timeSource, startTime)
 	r.ReqErrorCount.Inc(labels...)
-	r.ReqErrorLatency.Observe(latency, labels...)
+	ObserveContext(ctx, r.ReqErrorLatency, latency, labels...)
 }
 
 // Interceptor returns a UnaryServerInterceptor that can be registered with an RPC server and
@@ -83,7 +83,7 @@ func (r *RPCStatsInterceptor) Interceptor() grpc.UnaryServerInterceptor {
 		defer func() {
 			if rec := recover(); rec != nil {
 				// If we reach here then the handler exited via panic, count it as a server failure
-				r.recordFailureLatency(labels, startTime)
+				r.recordFailureLatency(ctx, labels, startTime)
 				panic(rec)
 			}
 		}()
@@ -93,11 +93,11 @@ func (r *RPCStatsInterceptor) Interceptor() grpc.UnaryServerInterceptor {
 
 		// Record success / failure and latency
 		if err != nil {
-			r.recordFailureLatency(labels, startTime)
+			r.recordFailureLatency(ctx, labels, startTime)
 		} else {
 			latency := clock.SecondsSince(r.timeSource, startTime)
 			r.ReqSuccessCount.Inc(labels...)
-			r.ReqSuccessLatency.Observe(latency, labels...)
+			ObserveContext(ctx, r.ReqSuccessLatency, latency, labels...)
 		}
 
 		// Pass the result of the handler invocation back
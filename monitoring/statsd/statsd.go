@@ -0,0 +1,257 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package statsd provides an implementation of the MetricFactory abstraction
+// which pushes metric updates to a statsd server over UDP.
+//
+// Labels are sent as DogStatsD-style tags ("|#key:value,..."), which are also
+// understood by Telegraf and the Prometheus statsd_exporter. Histograms are
+// sent as "h" samples, and are aggregated by the server, so any buckets given
+// to NewHistogramWithBuckets are ignored.
+package statsd
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+)
+
+// ExporterName identifies the statsd metrics exporter.
+const ExporterName = "statsd"
+
+var (
+	address = flag.String("statsd_address", "localhost:8125", "UDP address (host:port) of the statsd server to push metrics to. "+
+		"Only effective if metrics are exported to statsd.")
+	prefix = flag.String("statsd_prefix", "trillian.", "Prefix added to the names of metrics pushed to statsd")
+)
+
+func init() {
+	if err := monitoring.RegisterExporter(ExporterName, func() (monitoring.MetricFactory, error) {
+		return NewMetricFactory(*address, *prefix)
+	}); err != nil {
+		glog.Fatalf("Failed to register metrics exporter %v: %v", ExporterName, err)
+	}
+}
+
+// replacer removes the characters which are significant in the statsd line
+// protocol from metric names and tags.
+var replacer = strings.NewReplacer(":", "_", "|", "_", "@", "_", ",", "_", "#", "_", "\n", "_")
+
+// MetricFactory allows the creation of metrics whose updates are pushed to a
+// statsd server. The current values are also tracked locally, so that they can
+// be read back.
+type MetricFactory struct {
+	// Prefix is prepended to the name of every metric.
+	Prefix string
+	conn   net.Conn
+}
+
+// NewMetricFactory returns a MetricFactory which sends updates to the statsd
+// server at the given UDP address.
+func NewMetricFactory(addr, prefix string) (*MetricFactory, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial statsd at %v: %v", addr, err)
+	}
+	return &MetricFactory{Prefix: prefix, conn: conn}, nil
+}
+
+// NewCounter creates a new Counter pushed to statsd.
+func (mf *MetricFactory) NewCounter(name, help string, labelNames ...string) monitoring.Counter {
+	return &Counter{
+		metric: mf.newMetric(name, labelNames),
+		local:  monitoring.InertMetricFactory{}.NewCounter(name, help, labelNames...),
+	}
+}
+
+// NewGauge creates a new Gauge pushed to statsd.
+func (mf *MetricFactory) NewGauge(name, help string, labelNames ...string) monitoring.Gauge {
+	return &Gauge{
+		metric: mf.newMetric(name, labelNames),
+		local:  monitoring.InertMetricFactory{}.NewGauge(name, help, labelNames...),
+	}
+}
+
+// NewHistogram creates a new Histogram pushed to statsd.
+func (mf *MetricFactory) NewHistogram(name, help string, labelNames ...string) monitoring.Histogram {
+	return &Histogram{
+		metric: mf.newMetric(name, labelNames),
+		local:  monitoring.InertMetricFactory{}.NewHistogram(name, help, labelNames...),
+	}
+}
+
+// NewHistogramWithBuckets creates a new Histogram pushed to statsd. The
+// buckets are not used, as statsd servers do their own aggregation.
+func (mf *MetricFactory) NewHistogramWithBuckets(name, help string, _ []float64, labelNames ...string) monitoring.Histogram {
+	return mf.NewHistogram(name, help, labelNames...)
+}
+
+func (mf *MetricFactory) newMetric(name string, labelNames []string) metric {
+	return metric{
+		name:       replacer.Replace(mf.Prefix + name),
+		labelNames: labelNames,
+		conn:       mf.conn,
+	}
+}
+
+// metric holds what's needed to send updates to a single statsd metric.
+type metric struct {
+	name       string
+	labelNames []string
+	conn       net.Conn
+}
+
+// checkLabels returns an error if labelVals don't match the metric's labels.
+func (m metric) checkLabels(labelVals []string) error {
+	if len(labelVals) != len(m.labelNames) {
+		return fmt.Errorf("invalid label count %d for %v; want %d", len(labelVals), m.name, len(m.labelNames))
+	}
+	return nil
+}
+
+// line formats a single statsd update.
+func (m metric) line(val float64, kind string, labelVals []string) string {
+	var b strings.Builder
+	b.WriteString(m.name)
+	b.WriteByte(':')
+	b.WriteString(strconv.FormatFloat(val, 'f', -1, 64))
+	b.WriteByte('|')
+	b.WriteString(kind)
+	for i, v := range labelVals {
+		if i == 0 {
+			b.WriteString("|#")
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(replacer.Replace(m.labelNames[i]))
+		b.WriteByte(':')
+		b.WriteString(replacer.Replace(v))
+	}
+	return b.String()
+}
+
+// send writes lines to statsd in a single packet. Failures are only logged, as
+// statsd delivery is best effort.
+func (m metric) send(lines ...string) {
+	if _, err := m.conn.Write([]byte(strings.Join(lines, "\n"))); err != nil {
+		glog.V(1).Infof("Failed to send %v to statsd: %v", m.name, err)
+	}
+}
+
+// Counter is a Counter whose increments are pushed to statsd.
+type Counter struct {
+	metric
+	local monitoring.Counter
+}
+
+// Inc adds 1 to a counter.
+func (c *Counter) Inc(labelVals ...string) {
+	c.Add(1.0, labelVals...)
+}
+
+// Add adds the given amount to a counter.
+func (c *Counter) Add(val float64, labelVals ...string) {
+	if err := c.checkLabels(labelVals); err != nil {
+		glog.Error(err.Error())
+		return
+	}
+	c.local.Add(val, labelVals...)
+	c.send(c.line(val, "c", labelVals))
+}
+
+// Value returns the current amount of a counter.
+func (c *Counter) Value(labelVals ...string) float64 {
+	return c.local.Value(labelVals...)
+}
+
+// Gauge is a Gauge whose values are pushed to statsd.
+type Gauge struct {
+	metric
+	local monitoring.Gauge
+	// mu ensures that the values are sent in the order they are computed.
+	mu sync.Mutex
+}
+
+// Inc adds 1 to a gauge.
+func (g *Gauge) Inc(labelVals ...string) {
+	g.Add(1.0, labelVals...)
+}
+
+// Dec subtracts 1 from a gauge.
+func (g *Gauge) Dec(labelVals ...string) {
+	g.Add(-1.0, labelVals...)
+}
+
+// Add adds given value to a gauge.
+func (g *Gauge) Add(val float64, labelVals ...string) {
+	g.update(func() { g.local.Add(val, labelVals...) }, labelVals)
+}
+
+// Set sets the value of a gauge.
+func (g *Gauge) Set(val float64, labelVals ...string) {
+	g.update(func() { g.local.Set(val, labelVals...) }, labelVals)
+}
+
+// Value returns the current value of a gauge.
+func (g *Gauge) Value(labelVals ...string) float64 {
+	return g.local.Value(labelVals...)
+}
+
+// update applies f to the local value, and sends the result to statsd. The
+// absolute value is always sent, rather than a delta, so that lost packets
+// don't cause the server's value to drift.
+func (g *Gauge) update(f func(), labelVals []string) {
+	if err := g.checkLabels(labelVals); err != nil {
+		glog.Error(err.Error())
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f()
+	val := g.local.Value(labelVals...)
+	if val < 0 {
+		// A signed gauge value is interpreted as a delta, so the gauge has to be
+		// zeroed first.
+		g.send(g.line(0, "g", labelVals), g.line(val, "g", labelVals))
+		return
+	}
+	g.send(g.line(val, "g", labelVals))
+}
+
+// Histogram is a Histogram whose observations are pushed to statsd.
+type Histogram struct {
+	metric
+	local monitoring.Histogram
+}
+
+// Observe adds a single observation to the histogram.
+func (h *Histogram) Observe(val float64, labelVals ...string) {
+	if err := h.checkLabels(labelVals); err != nil {
+		glog.Error(err.Error())
+		return
+	}
+	h.local.Observe(val, labelVals...)
+	h.send(h.line(val, "h", labelVals))
+}
+
+// Info returns the count and sum of observations for the histogram.
+func (h *Histogram) Info(labelVals ...string) (uint64, float64) {
+	return h.local.Info(labelVals...)
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/monitoring/testonly"
+)
+
+// newTestFactory returns a MetricFactory sending to a local UDP socket, which
+// is also returned.
+func newTestFactory(t *testing.T) (*MetricFactory, net.PacketConn) {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket()=%v", err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	mf, err := NewMetricFactory(pc.LocalAddr().String(), "test.")
+	if err != nil {
+		t.Fatalf("NewMetricFactory()=%v", err)
+	}
+	return mf, pc
+}
+
+func TestCounter(t *testing.T) {
+	mf, _ := newTestFactory(t)
+	testonly.TestCounter(t, mf)
+}
+
+func TestGauge(t *testing.T) {
+	mf, _ := newTestFactory(t)
+	testonly.TestGauge(t, mf)
+}
+
+func TestHistogram(t *testing.T) {
+	mf, _ := newTestFactory(t)
+	testonly.TestHistogram(t, mf)
+}
+
+func TestWireFormat(t *testing.T) {
+	mf, pc := newTestFactory(t)
+	counter := mf.NewCounter("requests", "Test only", "method", "code")
+	gauge := mf.NewGauge("depth", "Test only")
+	histogram := mf.NewHistogramWithBuckets("latency", "Test only", []float64{1, 2}, "tree|id")
+
+	for _, test := range []struct {
+		desc   string
+		update func()
+		want   string
+	}{
+		{
+			desc:   "counter",
+			update: func() { counter.Add(2.5, "QueueLeaf", "OK") },
+			want:   "test.requests:2.5|c|#method:QueueLeaf,code:OK",
+		},
+		{
+			desc:   "sanitized",
+			update: func() { counter.Inc("a:b", "x|y,z") },
+			want:   "test.requests:1|c|#method:a_b,code:x_y_z",
+		},
+		{
+			desc:   "gauge",
+			update: func() { gauge.Set(3) },
+			want:   "test.depth:3|g",
+		},
+		{
+			desc:   "gaugeAbsolute",
+			update: func() { gauge.Add(2) },
+			want:   "test.depth:5|g",
+		},
+		{
+			desc:   "gaugeNegative",
+			update: func() { gauge.Set(-1) },
+			want:   "test.depth:0|g\ntest.depth:-1|g",
+		},
+		{
+			desc:   "histogram",
+			update: func() { histogram.Observe(0.25, "12") },
+			want:   "test.latency:0.25|h|#tree_id:12",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			test.update()
+			buf := make([]byte, 1024)
+			if err := pc.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+				t.Fatalf("SetReadDeadline()=%v", err)
+			}
+			n, _, err := pc.ReadFrom(buf)
+			if err != nil {
+				t.Fatalf("ReadFrom()=%v", err)
+			}
+			if got := string(buf[:n]); got != test.want {
+				t.Errorf("sent %q, want %q", got, test.want)
+			}
+		})
+	}
+}
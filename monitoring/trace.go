@@ -44,3 +44,32 @@ func StartSpan(ctx context.Context, name string) (context.Context, func()) {
 func SetStartSpan(f startSpanFunc) {
 	startSpan = f
 }
+
+// TraceIDLabel is the name of the exemplar label holding trace IDs.
+const TraceIDLabel = "trace_id"
+
+// traceIDFunc is the signature of a function which returns the ID of the
+// sampled trace that a context is part of, if any.
+type traceIDFunc func(context.Context) string
+
+var traceID traceIDFunc = noopTraceID
+
+// noopTraceID is a trace ID function which never finds a trace, and is used
+// as the default implementation.
+func noopTraceID(context.Context) string {
+	return ""
+}
+
+// TraceID returns the ID of the sampled trace that ctx is part of, or an empty
+// string if there isn't one.
+//
+// The default implementation of this method never finds a trace; insert a real
+// implementation by calling SetTraceID at start of day, alongside SetStartSpan.
+func TraceID(ctx context.Context) string {
+	return traceID(ctx)
+}
+
+// SetTraceID sets the function used to find the trace IDs of contexts.
+func SetTraceID(f traceIDFunc) {
+	traceID = f
+}
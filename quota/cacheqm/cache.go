@@ -144,6 +144,29 @@ func (m *manager) GetTokens(ctx context.Context, numTokens int, specs []quota.Sp
 	return nil
 }
 
+// PeekTokens implements TokenPeeker.PeekTokens.
+// Tokens held in the cache are added to those available from the wrapped Manager, which must
+// implement TokenPeeker.
+func (m *manager) PeekTokens(ctx context.Context, specs []quota.Spec) (map[quota.Spec]int, error) {
+	peeker, ok := m.qm.(quota.TokenPeeker)
+	if !ok {
+		return nil, fmt.Errorf("cached quota manager %T doesn't implement quota.TokenPeeker", m.qm)
+	}
+	tokens, err := peeker.PeekTokens(ctx, specs)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for spec, n := range tokens {
+		if b, ok := m.cache[spec]; ok && n != quota.MaxTokens {
+			tokens[spec] = n + b.tokens
+		}
+	}
+	return tokens, nil
+}
+
 func (m *manager) evict(ctx context.Context) {
 	m.mu.Lock()
 	// m.mu is explicitly unlocked, so we don't have to hold it while we wait for goroutines to
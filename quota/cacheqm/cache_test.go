@@ -78,6 +78,61 @@ func TestCachedManager_DelegatedMethods(t *testing.T) {
 	}
 }
 
+// peekingManager is a MockManager which also implements quota.TokenPeeker.
+type peekingManager struct {
+	*quota.MockManager
+	tokens map[quota.Spec]int
+}
+
+func (m peekingManager) PeekTokens(ctx context.Context, specs []quota.Spec) (map[quota.Spec]int, error) {
+	tokens := make(map[quota.Spec]int)
+	for _, spec := range specs {
+		tokens[spec] = m.tokens[spec]
+	}
+	return tokens, nil
+}
+
+func TestCachedManager_PeekTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mock := peekingManager{
+		MockManager: quota.NewMockManager(ctrl),
+		tokens:      map[quota.Spec]int{specs[0]: quota.MaxTokens, specs[1]: 100},
+	}
+	mock.EXPECT().GetTokens(ctx, matchers.AtLeast(minBatchSize), specs).Return(nil)
+
+	qm, err := NewCachedManager(mock, minBatchSize, maxEntries)
+	if err != nil {
+		t.Fatalf("NewCachedManager() returned err = %v", err)
+	}
+	if err := qm.GetTokens(ctx, 5, specs); err != nil {
+		t.Fatalf("GetTokens() returned err = %v", err)
+	}
+
+	// The minBatchSize tokens left in the cache are still available.
+	tokens, err := qm.(quota.TokenPeeker).PeekTokens(ctx, specs)
+	if err != nil {
+		t.Fatalf("PeekTokens() returned err = %v", err)
+	}
+	if got, want := tokens[specs[0]], quota.MaxTokens; got != want {
+		t.Errorf("PeekTokens()[%v] = %v, want %v", specs[0], got, want)
+	}
+	if got, want := tokens[specs[1]], 100+minBatchSize; got != want {
+		t.Errorf("PeekTokens()[%v] = %v, want %v", specs[1], got, want)
+	}
+
+	// The wrapped Manager must be able to peek.
+	qm, err = NewCachedManager(quota.NewMockManager(ctrl), minBatchSize, maxEntries)
+	if err != nil {
+		t.Fatalf("NewCachedManager() returned err = %v", err)
+	}
+	if _, err := qm.(quota.TokenPeeker).PeekTokens(ctx, specs); err == nil {
+		t.Error("PeekTokens() returned err = nil, want non-nil")
+	}
+}
+
 func TestCachedManager_GetTokens_CachesTokens(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
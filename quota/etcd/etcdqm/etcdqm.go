@@ -39,7 +39,8 @@ func (m *Manager) GetTokens(ctx context.Context, numTokens int, specs []quota.Sp
 	return m.qs.Get(ctx, configNames(specs), int64(numTokens))
 }
 
-func (m *Manager) peekTokens(ctx context.Context, specs []quota.Spec) (map[quota.Spec]int, error) {
+// PeekTokens implements quota.TokenPeeker.PeekTokens.
+func (m *Manager) PeekTokens(ctx context.Context, specs []quota.Spec) (map[quota.Spec]int, error) {
 	names := configNames(specs)
 	nameToSpec := make(map[string]quota.Spec)
 	for i, name := range names {
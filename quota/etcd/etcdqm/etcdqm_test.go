@@ -239,12 +239,12 @@ func TestManager_ResetQuota(t *testing.T) {
 			t.Errorf("%v: ResetQuota() returned err = %v", test.desc, err)
 			continue
 		}
-		tokens, err := qm.peekTokens(ctx, test.specs)
+		tokens, err := qm.PeekTokens(ctx, test.specs)
 		if err != nil {
-			t.Fatalf("%v: peekTokens() returned err = %v", test.desc, err)
+			t.Fatalf("%v: PeekTokens() returned err = %v", test.desc, err)
 		}
 		if diff := cmp.Diff(tokens, test.want); diff != "" {
-			t.Errorf("%v: post-peekTokens() diff (-got +want):\n%v", test.desc, diff)
+			t.Errorf("%v: post-PeekTokens() diff (-got +want):\n%v", test.desc, diff)
 		}
 	}
 }
@@ -25,12 +25,13 @@ import (
 const (
 	statePath           = "state"
 	maxTokensPath       = "max_tokens"
+	burstTokensPath     = "burst_tokens"
 	sequencingBasedPath = "sequencing_based"
 	timeBasedPath       = "time_based"
 )
 
 var (
-	commonMask          = &field_mask.FieldMask{Paths: []string{statePath, maxTokensPath}}
+	commonMask          = &field_mask.FieldMask{Paths: []string{statePath, maxTokensPath, burstTokensPath}}
 	sequencingBasedMask = &field_mask.FieldMask{Paths: append(commonMask.Paths, sequencingBasedPath)}
 	timeBasedMask       = &field_mask.FieldMask{Paths: append(commonMask.Paths, timeBasedPath)}
 
@@ -46,7 +47,7 @@ func validateMask(mask *field_mask.FieldMask) error {
 	timeBasedFound := false
 	for _, path := range mask.Paths {
 		switch path {
-		case statePath, maxTokensPath:
+		case statePath, maxTokensPath, burstTokensPath:
 			// OK
 		case sequencingBasedPath:
 			if timeBasedFound {
@@ -75,6 +76,8 @@ func applyMask(src *quotapb.Config, dest *storagepb.Config, mask *field_mask.Fie
 			dest.State = storagepb.Config_State(storagepb.Config_State_value[src.State.String()])
 		case maxTokensPath:
 			dest.MaxTokens = src.MaxTokens
+		case burstTokensPath:
+			dest.BurstTokens = src.BurstTokens
 		case sequencingBasedPath:
 			if src.GetSequencingBased() == nil {
 				dest.ReplenishmentStrategy = nil
@@ -91,6 +94,7 @@ func applyMask(src *quotapb.Config, dest *storagepb.Config, mask *field_mask.Fie
 					TimeBased: &storagepb.TimeBasedStrategy{
 						TokensToReplenish:        tb.GetTokensToReplenish(),
 						ReplenishIntervalSeconds: tb.GetReplenishIntervalSeconds(),
+						WarmUpSeconds:            tb.GetWarmUpSeconds(),
 					},
 				}
 			}
@@ -101,9 +105,10 @@ func applyMask(src *quotapb.Config, dest *storagepb.Config, mask *field_mask.Fie
 // convertToAPI returns the API representation of a storagepb.Config proto.
 func convertToAPI(src *storagepb.Config) *quotapb.Config {
 	dest := &quotapb.Config{
-		Name:      src.Name,
-		State:     quotapb.Config_State(quotapb.Config_State_value[src.State.String()]),
-		MaxTokens: src.MaxTokens,
+		Name:        src.Name,
+		State:       quotapb.Config_State(quotapb.Config_State_value[src.State.String()]),
+		MaxTokens:   src.MaxTokens,
+		BurstTokens: src.BurstTokens,
 	}
 	sb := src.GetSequencingBased()
 	tb := src.GetTimeBased()
@@ -117,6 +122,7 @@ func convertToAPI(src *storagepb.Config) *quotapb.Config {
 			TimeBased: &quotapb.TimeBasedStrategy{
 				TokensToReplenish:        tb.TokensToReplenish,
 				ReplenishIntervalSeconds: tb.ReplenishIntervalSeconds,
+				WarmUpSeconds:            tb.WarmUpSeconds,
 			},
 		}
 	}
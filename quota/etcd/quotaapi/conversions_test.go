@@ -34,13 +34,15 @@ var (
 		},
 	}
 	apiTimeConfig = &quotapb.Config{
-		Name:      "quotas/users/llama/write/config",
-		State:     quotapb.Config_DISABLED,
-		MaxTokens: 200,
+		Name:        "quotas/users/llama/write/config",
+		State:       quotapb.Config_DISABLED,
+		MaxTokens:   200,
+		BurstTokens: 50,
 		ReplenishmentStrategy: &quotapb.Config_TimeBased{
 			TimeBased: &quotapb.TimeBasedStrategy{
 				TokensToReplenish:        10,
 				ReplenishIntervalSeconds: 30,
+				WarmUpSeconds:            60,
 			},
 		},
 	}
@@ -53,13 +55,15 @@ var (
 		},
 	}
 	storageTimeConfig = &storagepb.Config{
-		Name:      apiTimeConfig.Name,
-		State:     storagepb.Config_DISABLED,
-		MaxTokens: apiTimeConfig.MaxTokens,
+		Name:        apiTimeConfig.Name,
+		State:       storagepb.Config_DISABLED,
+		MaxTokens:   apiTimeConfig.MaxTokens,
+		BurstTokens: apiTimeConfig.BurstTokens,
 		ReplenishmentStrategy: &storagepb.Config_TimeBased{
 			TimeBased: &storagepb.TimeBasedStrategy{
 				TokensToReplenish:        apiTimeConfig.GetTimeBased().TokensToReplenish,
 				ReplenishIntervalSeconds: apiTimeConfig.GetTimeBased().ReplenishIntervalSeconds,
+				WarmUpSeconds:            apiTimeConfig.GetTimeBased().WarmUpSeconds,
 			},
 		},
 	}
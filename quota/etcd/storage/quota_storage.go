@@ -183,6 +183,9 @@ func validate(cfgs *storagepb.Configs) error {
 		if t := cfg.MaxTokens; t <= 0 {
 			return status.Errorf(codes.InvalidArgument, "config max tokens must be > 0 (Configs[%v].MaxTokens = %v)", i, t)
 		}
+		if t := cfg.BurstTokens; t < 0 {
+			return status.Errorf(codes.InvalidArgument, "config burst tokens must be >= 0 (Configs[%v].BurstTokens = %v)", i, t)
+		}
 		switch s := cfg.ReplenishmentStrategy.(type) {
 		case *storagepb.Config_SequencingBased:
 			if usersPattern.MatchString(cfg.Name) {
@@ -198,6 +201,9 @@ func validate(cfgs *storagepb.Configs) error {
 			if r := s.TimeBased.ReplenishIntervalSeconds; r <= 0 {
 				return status.Errorf(codes.InvalidArgument, "time based replenish interval must be > 0 (Configs[%v].TimeBased.ReplenishIntervalSeconds = %v)", i, r)
 			}
+			if w := s.TimeBased.WarmUpSeconds; w < 0 {
+				return status.Errorf(codes.InvalidArgument, "time based warm-up must be >= 0 (Configs[%v].TimeBased.WarmUpSeconds = %v)", i, w)
+			}
 		default:
 			return status.Errorf(codes.InvalidArgument, "unsupported replenishment strategy (Configs[%v].ReplenishmentStrategy = %T)", i, s)
 		}
@@ -241,7 +247,8 @@ func (qs *QuotaStorage) mod(ctx context.Context, names []string, add int64) erro
 
 // Peek returns a map of quota name to tokens for the named quotas.
 // Unknown or disabled quotas are considered infinite and returned as having quota.MaxTokens tokens,
-// therefore all requested names are guaranteed to be in the resulting map
+// therefore all requested names are guaranteed to be in the resulting map.
+// Quotas in debt due to burst tokens are returned as having no tokens.
 func (qs *QuotaStorage) Peek(ctx context.Context, names []string) (map[string]int64, error) {
 	now := timeSource.Now()
 	tokens := make(map[string]int64)
@@ -252,6 +259,9 @@ func (qs *QuotaStorage) Peek(ctx context.Context, names []string) (map[string]in
 			t = int64(quota.MaxTokens)
 		} else {
 			t, err = modBucket(s, cfg, now, 0 /* add */)
+			if t < 0 {
+				t = 0
+			}
 		}
 		tokens[name] = t
 		return err
@@ -367,7 +377,9 @@ func getConfigs(s concurrency.STM) (*storagepb.Configs, error) {
 // that are above ceiling (eg, due to lowered max tokens) will also be constrained to the
 // appropriate ceiling. As a consequence, calls with add = 0 are still useful for peeking and the
 // explained side-effects.
-// modBucket returns the current token count for cfg.
+// Tokens may be taken until the quota is cfg.BurstTokens in debt, unless it is warming up, in which
+// case its ceiling is lowered instead, see warmUpCeiling.
+// modBucket returns the current token count for cfg, which is negative for quotas in debt.
 func modBucket(s concurrency.STM, cfg *storagepb.Config, now time.Time, add int64) (int64, error) {
 	key := bucketKey(cfg)
 
@@ -378,6 +390,7 @@ func modBucket(s concurrency.STM, cfg *storagepb.Config, now time.Time, add int6
 	}
 	newBucket := proto.Clone(&prevBucket).(*storagepb.Bucket)
 
+	floor := -cfg.BurstTokens
 	if tb := cfg.GetTimeBased(); tb != nil {
 		if now.Unix() >= newBucket.LastReplenishMillisSinceEpoch/1e3+tb.ReplenishIntervalSeconds {
 			newBucket.Tokens += tb.TokensToReplenish
@@ -389,10 +402,18 @@ func modBucket(s concurrency.STM, cfg *storagepb.Config, now time.Time, add int6
 		if add > 0 {
 			add = 0 // Do not replenish time-based quotas
 		}
+		if add < 0 && tb.WarmUpSeconds > 0 {
+			if ceiling, ok := warmUpCeiling(newBucket, cfg, tb, now); ok {
+				if newBucket.Tokens > ceiling {
+					newBucket.Tokens = ceiling
+				}
+				floor = 0
+			}
+		}
 	}
 
 	newBucket.Tokens += add
-	if newBucket.Tokens < 0 {
+	if newBucket.Tokens < floor {
 		return 0, fmt.Errorf("insufficient tokens on %v (%v vs %v)", key, prevBucket.Tokens, -add)
 	}
 	if newBucket.Tokens > cfg.MaxTokens {
@@ -410,6 +431,33 @@ func modBucket(s concurrency.STM, cfg *storagepb.Config, now time.Time, add int6
 	return newBucket.Tokens, nil
 }
 
+// warmUpCeiling records the use of a time-based quota with a warm-up in bucket, and returns the
+// number of tokens it may hold if it is warming up. A warm-up starts when the quota is used after
+// being idle for tb.WarmUpSeconds, and lasts as long, during which the ceiling of the quota ramps
+// up linearly from tb.TokensToReplenish to cfg.MaxTokens.
+// Rejected requests don't count as uses, as they don't update the bucket.
+func warmUpCeiling(bucket *storagepb.Bucket, cfg *storagepb.Config, tb *storagepb.TimeBasedStrategy, now time.Time) (int64, bool) {
+	nowMillis := now.UnixNano() / 1e6
+	warmUpMillis := tb.WarmUpSeconds * 1e3
+	if nowMillis-bucket.LastUseMillisSinceEpoch >= warmUpMillis {
+		bucket.WarmUpStartMillisSinceEpoch = nowMillis
+	}
+	bucket.LastUseMillisSinceEpoch = nowMillis
+
+	elapsed := nowMillis - bucket.WarmUpStartMillisSinceEpoch
+	if elapsed >= warmUpMillis {
+		return 0, false
+	}
+	ceiling := int64(float64(cfg.MaxTokens) * float64(elapsed) / float64(warmUpMillis))
+	if ceiling < tb.TokensToReplenish {
+		ceiling = tb.TokensToReplenish
+	}
+	if ceiling > cfg.MaxTokens {
+		ceiling = cfg.MaxTokens
+	}
+	return ceiling, true
+}
+
 func bucketKey(cfg *storagepb.Config) string {
 	return fmt.Sprintf("%v/0", cfg.Name)
 }
@@ -284,6 +284,18 @@ func TestQuotaStorage_UpdateConfigsErrors(t *testing.T) {
 		},
 	}
 
+	invalidBurstTokens := deepCopy(globalWriteCfgs)
+	invalidBurstTokens.Configs[0].BurstTokens = -1
+
+	invalidWarmUp := deepCopy(globalWriteCfgs)
+	invalidWarmUp.Configs[0].ReplenishmentStrategy = &storagepb.Config_TimeBased{
+		TimeBased: &storagepb.TimeBasedStrategy{
+			TokensToReplenish:        1,
+			ReplenishIntervalSeconds: 10,
+			WarmUpSeconds:            -1,
+		},
+	}
+
 	duplicateNames := &storagepb.Configs{Configs: []*storagepb.Config{globalRead, globalWrite, globalWrite}}
 
 	sequencingBasedStrategy := &storagepb.Config_SequencingBased{SequencingBased: &storagepb.SequencingBasedStrategy{}}
@@ -367,6 +379,16 @@ func TestQuotaStorage_UpdateConfigsErrors(t *testing.T) {
 			update:  updater(invalidReplenishInterval),
 			wantErr: "replenish interval must be > 0",
 		},
+		{
+			desc:    "invalidBurstTokens",
+			update:  updater(invalidBurstTokens),
+			wantErr: "burst tokens must be >= 0",
+		},
+		{
+			desc:    "invalidWarmUp",
+			update:  updater(invalidWarmUp),
+			wantErr: "warm-up must be >= 0",
+		},
 		{
 			desc:    "duplicateNames",
 			update:  updater(duplicateNames),
@@ -594,6 +616,106 @@ func TestQuotaStorage_Get(t *testing.T) {
 	}
 }
 
+func TestQuotaStorage_GetBurst(t *testing.T) {
+	fakeTime := clock.NewFake(time.Now())
+	defer setupTimeSource(fakeTime)()
+
+	burstRead := proto.Clone(userRead).(*storagepb.Config)
+	burstRead.BurstTokens = 200
+	burstWrite := proto.Clone(globalWrite).(*storagepb.Config)
+	burstWrite.BurstTokens = 20
+	burstCfgs := &storagepb.Configs{Configs: []*storagepb.Config{burstRead, burstWrite}}
+	interval := time.Duration(burstRead.GetTimeBased().ReplenishIntervalSeconds) * time.Second
+
+	ctx := context.Background()
+	qs := &QuotaStorage{Client: client}
+	if err := setupTokens(ctx, qs, burstCfgs, nil /* initialTokens */); err != nil {
+		t.Fatalf("setupTokens() returned err = %v", err)
+	}
+
+	// Tokens can be taken until the quotas are burst tokens in debt.
+	names := []string{burstRead.Name, burstWrite.Name}
+	if err := qs.Get(ctx, []string{burstRead.Name}, burstRead.MaxTokens+150); err != nil {
+		t.Fatalf("Get(%v) returned err = %v", burstRead.Name, err)
+	}
+	if err := qs.Get(ctx, []string{burstWrite.Name}, burstWrite.MaxTokens+20); err != nil {
+		t.Fatalf("Get(%v) returned err = %v", burstWrite.Name, err)
+	}
+	if err := qs.Get(ctx, names, 51); err == nil {
+		t.Errorf("Get() beyond burst tokens returned err = nil")
+	}
+	// Quotas in debt are peeked as having no tokens.
+	if err := peekAndDiff(ctx, qs, map[string]int64{burstRead.Name: 0, burstWrite.Name: 0}); err != nil {
+		t.Error(err)
+	}
+
+	// Replenishment pays off the debt first.
+	fakeTime.Set(fakeTime.Now().Add(interval))
+	if err := qs.Put(ctx, []string{burstWrite.Name}, 25); err != nil {
+		t.Fatalf("Put() returned err = %v", err)
+	}
+	if err := peekAndDiff(ctx, qs, map[string]int64{burstRead.Name: burstRead.GetTimeBased().TokensToReplenish - 150, burstWrite.Name: 5}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestQuotaStorage_GetWarmUp(t *testing.T) {
+	fakeTime := clock.NewFake(time.Now())
+	defer setupTimeSource(fakeTime)()
+
+	// A quota of 100 tokens replenished by 10 tokens every second, which warms
+	// up over 100 seconds.
+	warmRead := proto.Clone(userRead).(*storagepb.Config)
+	warmRead.MaxTokens = 100
+	warmRead.BurstTokens = 10
+	warmRead.ReplenishmentStrategy = &storagepb.Config_TimeBased{
+		TimeBased: &storagepb.TimeBasedStrategy{
+			TokensToReplenish:        10,
+			ReplenishIntervalSeconds: 1,
+			WarmUpSeconds:            100,
+		},
+	}
+	warmCfgs := &storagepb.Configs{Configs: []*storagepb.Config{warmRead}}
+	names := []string{warmRead.Name}
+
+	ctx := context.Background()
+	qs := &QuotaStorage{Client: client}
+	if err := setupTokens(ctx, qs, warmCfgs, nil /* initialTokens */); err != nil {
+		t.Fatalf("setupTokens() returned err = %v", err)
+	}
+
+	for _, test := range []struct {
+		desc         string
+		nowIncrement time.Duration
+		tokens       int64
+		wantErr      bool
+		wantTokens   int64
+	}{
+		// The first use starts a warm-up, so only 10 tokens are available, and
+		// no burst tokens.
+		{desc: "coldTooMany", tokens: 11, wantErr: true, wantTokens: 100},
+		{desc: "cold", tokens: 10, wantTokens: 0},
+		// Half way through, the quota may hold up to half of its maximum.
+		{desc: "halfWay", nowIncrement: 50 * time.Second, tokens: 1, wantTokens: 9},
+		{desc: "halfWayNoBurst", tokens: 10, wantErr: true, wantTokens: 9},
+		// Once warm, burst tokens are available again.
+		{desc: "warm", nowIncrement: 50 * time.Second, tokens: 29, wantTokens: -10},
+		{desc: "warmBeyondBurst", nowIncrement: time.Second, tokens: 11, wantErr: true, wantTokens: 0},
+		// After an idle period, the quota warms up again.
+		{desc: "idleNoBurst", nowIncrement: 100 * time.Second, tokens: 11, wantErr: true, wantTokens: 10},
+		{desc: "idle", tokens: 10, wantTokens: 0},
+	} {
+		fakeTime.Set(fakeTime.Now().Add(test.nowIncrement))
+		err := qs.Get(ctx, names, test.tokens)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("%v: Get() returned err = %v, wantErr = %v", test.desc, err, test.wantErr)
+		}
+		if err := peekAndDiff(ctx, qs, map[string]int64{warmRead.Name: max64(test.wantTokens, 0)}); err != nil {
+			t.Errorf("%v: %v", test.desc, err)
+		}
+	}
+}
+
 func TestQuotaStorage_GetErrors(t *testing.T) {
 	tests := []struct {
 		desc   string
@@ -964,6 +1086,13 @@ func setupTokens(ctx context.Context, qs *QuotaStorage, cfgs *storagepb.Configs,
 	return nil
 }
 
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 func deepCopy(c1 *storagepb.Configs) *storagepb.Configs {
 	return proto.Clone(c1).(*storagepb.Configs)
 }
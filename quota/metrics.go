@@ -15,9 +15,12 @@
 package quota
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/golang/glog"
 	"github.com/google/trillian/monitoring"
 )
 
@@ -37,6 +40,7 @@ type m struct {
 	AcquiredTokens    monitoring.Counter
 	ReturnedTokens    monitoring.Counter
 	ReplenishedTokens monitoring.Counter
+	AvailableTokens   monitoring.Gauge
 }
 
 // IncAcquired increments the AcquiredTokens metric.
@@ -54,6 +58,21 @@ func (m *m) IncReplenished(tokens int, specs []Spec, success bool) {
 	m.add(m.ReplenishedTokens, tokens, specs, success)
 }
 
+// SetAvailable sets the AvailableTokens metric from the result of a
+// TokenPeeker.PeekTokens call. Unlimited specs are not exported.
+func (m *m) SetAvailable(tokens map[Spec]int) {
+	if m.AvailableTokens == nil {
+		return
+	}
+	for spec, n := range tokens {
+		if spec.Group == User || n == MaxTokens {
+			// Don't populate per-user labels.
+			continue
+		}
+		m.AvailableTokens.Set(float64(n), spec.Name())
+	}
+}
+
 func (m *m) add(c monitoring.Counter, tokens int, specs []Spec, success bool) {
 	if c == nil {
 		return
@@ -74,5 +93,42 @@ func InitMetrics(mf monitoring.MetricFactory) {
 		Metrics.AcquiredTokens = mf.NewCounter("quota_acquired_tokens", "Number of acquired quota tokens", "spec", "success")
 		Metrics.ReturnedTokens = mf.NewCounter("quota_returned_tokens", "Number of quota tokens returned due to overcharging (bad requests, duplicates, etc)", "spec", "success")
 		Metrics.ReplenishedTokens = mf.NewCounter("quota_replenished_tokens", "Number of quota tokens replenished due to sequencer progress", "spec", "success")
+		Metrics.AvailableTokens = mf.NewGauge("quota_available_tokens", "Number of quota tokens available when last peeked", "spec")
 	})
 }
+
+// MonitorAvailableTokens peeks the tokens available for the specs returned by
+// specs every interval, and exports them through Metrics.AvailableTokens. It
+// returns when ctx is done, or straight away if qm isn't a TokenPeeker.
+func MonitorAvailableTokens(ctx context.Context, qm Manager, interval time.Duration, specs func(context.Context) ([]Spec, error)) {
+	peeker, ok := qm.(TokenPeeker)
+	if !ok {
+		glog.Infof("Quota manager %T can't peek tokens, not exporting available tokens", qm)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := exportAvailableTokens(ctx, peeker, specs); err != nil {
+			glog.Warningf("Failed to export available quota tokens: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func exportAvailableTokens(ctx context.Context, peeker TokenPeeker, specs func(context.Context) ([]Spec, error)) error {
+	s, err := specs(ctx)
+	if err != nil {
+		return err
+	}
+	tokens, err := peeker.PeekTokens(ctx, s)
+	if err != nil {
+		return err
+	}
+	Metrics.SetAvailable(tokens)
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/trillian/monitoring"
+)
+
+type fakePeeker map[Spec]int
+
+func (f fakePeeker) PeekTokens(ctx context.Context, specs []Spec) (map[Spec]int, error) {
+	tokens := make(map[Spec]int)
+	for _, spec := range specs {
+		tokens[spec] = f[spec]
+	}
+	return tokens, nil
+}
+
+func TestExportAvailableTokens(t *testing.T) {
+	InitMetrics(monitoring.InertMetricFactory{})
+	ctx := context.Background()
+
+	globalWrite := Spec{Group: Global, Kind: Write}
+	treeRead := Spec{Group: Tree, Kind: Read, TreeID: 12345}
+	treeWrite := Spec{Group: Tree, Kind: Write, TreeID: 12345}
+	userRead := Spec{Group: User, Kind: Read, User: "dougal"}
+	peeker := fakePeeker{globalWrite: 10, treeRead: MaxTokens, treeWrite: 20, userRead: 30}
+	specs := func(context.Context) ([]Spec, error) {
+		return []Spec{globalWrite, treeRead, treeWrite, userRead}, nil
+	}
+	if err := exportAvailableTokens(ctx, peeker, specs); err != nil {
+		t.Fatalf("exportAvailableTokens() returned err = %v", err)
+	}
+
+	for _, test := range []struct {
+		spec Spec
+		want float64
+	}{
+		{spec: globalWrite, want: 10},
+		{spec: treeRead, want: 0}, // Unlimited
+		{spec: treeWrite, want: 20},
+		{spec: userRead, want: 0}, // Per-user labels aren't populated
+	} {
+		if got := Metrics.AvailableTokens.Value(test.spec.Name()); got != test.want {
+			t.Errorf("AvailableTokens(%v) = %v, want %v", test.spec, got, test.want)
+		}
+	}
+
+	wantErr := errors.New("no trees")
+	failing := func(context.Context) ([]Spec, error) { return nil, wantErr }
+	if err := exportAvailableTokens(ctx, peeker, failing); err != wantErr {
+		t.Errorf("exportAvailableTokens() returned err = %v, want %v", err, wantErr)
+	}
+}
@@ -89,6 +89,29 @@ func (m *QuotaManager) ResetQuota(ctx context.Context, specs []quota.Spec) error
 	return nil
 }
 
+// PeekTokens implements quota.TokenPeeker.PeekTokens.
+// Global/Write quotas report MaxUnsequencedRows minus the number of Unsequenced rows, all other
+// quotas are infinite.
+func (m *QuotaManager) PeekTokens(ctx context.Context, specs []quota.Spec) (map[quota.Spec]int, error) {
+	tokens := make(map[quota.Spec]int, len(specs))
+	for _, spec := range specs {
+		if spec.Group != quota.Global || spec.Kind != quota.Write {
+			tokens[spec] = quota.MaxTokens
+			continue
+		}
+		count, err := m.countUnsequenced(ctx)
+		if err != nil {
+			return nil, err
+		}
+		available := m.MaxUnsequencedRows - count
+		if available < 0 {
+			available = 0
+		}
+		tokens[spec] = available
+	}
+	return tokens, nil
+}
+
 func (m *QuotaManager) countUnsequenced(ctx context.Context) (int, error) {
 	if m.UseSelectCount {
 		return countFromTable(ctx, m.DB)
@@ -117,6 +117,55 @@ func TestQuotaManager_GetTokens(t *testing.T) {
 	}
 }
 
+func TestQuotaManager_PeekTokens(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+	ctx := context.Background()
+
+	db, done, err := testdb.NewTrillianDB(ctx)
+	if err != nil {
+		t.Fatalf("GetTestDB() returned err = %v", err)
+	}
+	defer done(ctx)
+
+	tree, err := createTree(ctx, db)
+	if err != nil {
+		t.Fatalf("createTree() returned err = %v", err)
+	}
+
+	tests := []struct {
+		desc                                string
+		unsequencedRows, maxUnsequencedRows int
+		want                                int
+	}{
+		{desc: "underQuota", unsequencedRows: 5, maxUnsequencedRows: 20, want: 15},
+		{desc: "atQuota", unsequencedRows: 20, maxUnsequencedRows: 20, want: 0},
+		{desc: "overQuota", unsequencedRows: 25, maxUnsequencedRows: 20, want: 0},
+	}
+	for _, test := range tests {
+		if err := setUnsequencedRows(ctx, db, tree, test.unsequencedRows); err != nil {
+			t.Errorf("setUnsequencedRows() returned err = %v", err)
+			continue
+		}
+
+		qm := &mysqlqm.QuotaManager{DB: db, MaxUnsequencedRows: test.maxUnsequencedRows, UseSelectCount: true}
+		specs := allSpecs(ctx, qm, tree.TreeId)
+		tokens, err := qm.PeekTokens(ctx, specs)
+		if err != nil {
+			t.Errorf("%v: PeekTokens() returned err = %v", test.desc, err)
+			continue
+		}
+		for _, spec := range specs {
+			want := quota.MaxTokens
+			if spec.Group == quota.Global && spec.Kind == quota.Write {
+				want = test.want
+			}
+			if got := tokens[spec]; got != want {
+				t.Errorf("%v: PeekTokens()[%v] = %v, want %v", test.desc, spec, got, want)
+			}
+		}
+	}
+}
+
 func TestQuotaManager_GetTokens_InformationSchema(t *testing.T) {
 	testdb.SkipIfNoMySQL(t)
 	ctx := context.Background()
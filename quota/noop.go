@@ -57,6 +57,18 @@ func (n noopManager) ResetQuota(ctx context.Context, specs []Spec) error {
 	return validateSpecs(specs)
 }
 
+// PeekTokens implements TokenPeeker.PeekTokens. All specs are unlimited.
+func (n noopManager) PeekTokens(ctx context.Context, specs []Spec) (map[Spec]int, error) {
+	if err := validateSpecs(specs); err != nil {
+		return nil, err
+	}
+	tokens := make(map[Spec]int, len(specs))
+	for _, spec := range specs {
+		tokens[spec] = MaxTokens
+	}
+	return tokens, nil
+}
+
 func (n noopManager) SetupInitialQuota(ctx context.Context, treeID int64) error {
 	return nil
 }
@@ -101,5 +101,15 @@ func TestNoop_ValidatesSpecs(t *testing.T) {
 		if hasErr := err != nil; hasErr != test.wantErr {
 			t.Errorf("%v: ResetQuota() returned err = %q, wantErr = %v", test.desc, err, test.wantErr)
 		}
+
+		tokens, err := qm.(TokenPeeker).PeekTokens(ctx, test.specs)
+		if hasErr := err != nil; hasErr != test.wantErr {
+			t.Errorf("%v: PeekTokens() returned err = %q, wantErr = %v", test.desc, err, test.wantErr)
+		}
+		for _, spec := range test.specs {
+			if err == nil && tokens[spec] != MaxTokens {
+				t.Errorf("%v: PeekTokens()[%v] = %v, want MaxTokens", test.desc, spec, tokens[spec])
+			}
+		}
 	}
 }
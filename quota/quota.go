@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // MaxTokens is the maximum number of available tokens a quota may have.
@@ -101,6 +102,25 @@ func (s Spec) String() string {
 	return s.Name()
 }
 
+// ExhaustedError is returned by Managers whose GetTokens fails because a Spec
+// doesn't have enough tokens, when they know which.
+type ExhaustedError struct {
+	// Spec is the spec which doesn't have enough tokens.
+	Spec Spec
+	// Name is the name of the token bucket of Spec in the Manager.
+	Name string
+	// Available and Requested are the numbers of tokens available and
+	// requested.
+	Available, Requested int
+	// RetryAfter is how long it will take for the tokens requested to become
+	// available, or zero if that isn't known.
+	RetryAfter time.Duration
+}
+
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("insufficient tokens on %v (%v vs %v)", e.Name, e.Available, e.Requested)
+}
+
 // Manager is the component responsible for the management of tokens.
 type Manager interface {
 	// GetTokens acquires numTokens from all specs. Tokens are taken in the order specified by
@@ -114,3 +134,11 @@ type Manager interface {
 	// ResetQuota resets the quota for all specs.
 	ResetQuota(ctx context.Context, specs []Spec) error
 }
+
+// TokenPeeker is an optional interface which may be implemented by a Manager
+// to report how many tokens are available without acquiring any.
+type TokenPeeker interface {
+	// PeekTokens returns the number of tokens currently available for each of
+	// specs. Specs which aren't limited by the Manager report MaxTokens.
+	PeekTokens(ctx context.Context, specs []Spec) (map[Spec]int, error)
+}
@@ -17,7 +17,7 @@ package redisqm
 
 import (
 	"context"
-	"fmt"
+	"time"
 
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/quota/redis/redistb"
@@ -27,12 +27,21 @@ import (
 // for a given quota specification.
 type ParameterFunc func(spec quota.Spec) (capacity int, rate float64)
 
+// BurstParameterFunc is a function that should return the burst settings of a
+// token bucket for a given quota specification.
+type BurstParameterFunc func(spec quota.Spec) redistb.Burst
+
 // ManagerOptions holds the parameters for a Manager.
 type ManagerOptions struct {
 	// Parameters should return the parameters for a given quota.Spec. This
 	// value must not be nil.
 	Parameters ParameterFunc
 
+	// BurstParameters, if not nil, should return the burst settings for a
+	// given quota.Spec, i.e. the number of tokens which may be taken beyond
+	// those available, and the warm-up of buckets after an idle period.
+	BurstParameters BurstParameterFunc
+
 	// Prefix is a static prefix to apply to all Redis keys; this is useful
 	// if running on a multi-tenant Redis cluster.
 	Prefix string
@@ -45,7 +54,10 @@ type Manager struct {
 	opts ManagerOptions
 }
 
-var _ quota.Manager = &Manager{}
+var (
+	_ quota.Manager     = &Manager{}
+	_ quota.TokenPeeker = &Manager{}
+)
 
 // RedisClient is an interface that encompasses the various methods used by
 // this quota.Manager, and allows selecting among different Redis client
@@ -83,18 +95,27 @@ func (m *Manager) getTokensSingle(ctx context.Context, numTokens int, spec quota
 	}
 
 	name := specName(m.opts.Prefix, spec)
-	allowed, remaining, err := m.tb.Call(
+	allowed, remaining, err := m.tb.CallWithBurst(
 		ctx,
 		name,
 		int64(capacity),
 		rate,
 		numTokens,
+		m.burst(spec),
 	)
 	if err != nil {
 		return err
 	}
 	if !allowed {
-		return fmt.Errorf("insufficient tokens on %v (%v vs %v)", name, remaining, numTokens)
+		err := &quota.ExhaustedError{Spec: spec, Name: name, Available: int(max64(remaining, 0)), Requested: numTokens}
+		// Tokens are replenished at rate per second, so the tokens requested
+		// are available once the shortfall has been replenished, unless
+		// there are more of them than the bucket can hold. Burst tokens are
+		// not accounted for, as they don't apply while a bucket warms up.
+		if numTokens <= capacity && rate > 0 {
+			err.RetryAfter = time.Duration(float64(int64(numTokens)-remaining) / rate * float64(time.Second))
+		}
+		return err
 	}
 
 	return nil
@@ -109,6 +130,28 @@ func (m *Manager) PutTokens(ctx context.Context, numTokens int, specs []quota.Sp
 	return nil
 }
 
+// PeekTokens implements the quota.TokenPeeker API.
+//
+// Peeking refills the token buckets with any tokens accrued since they were
+// last used, but doesn't take any tokens from them.
+func (m *Manager) PeekTokens(ctx context.Context, specs []quota.Spec) (map[quota.Spec]int, error) {
+	tokens := make(map[quota.Spec]int, len(specs))
+	for _, spec := range specs {
+		capacity, rate := m.opts.Parameters(spec)
+		if capacity == quota.MaxTokens {
+			tokens[spec] = quota.MaxTokens
+			continue
+		}
+		_, remaining, err := m.tb.CallWithBurst(ctx, specName(m.opts.Prefix, spec), int64(capacity), rate, 0, m.burst(spec))
+		if err != nil {
+			return nil, err
+		}
+		// Buckets in debt due to burst tokens have no tokens available.
+		tokens[spec] = int(max64(remaining, 0))
+	}
+	return tokens, nil
+}
+
 // ResetQuota implements the quota.Manager API.
 //
 // This function will reset every quota and return the first error encountered,
@@ -138,6 +181,21 @@ func (m *Manager) Load(ctx context.Context) error {
 	return m.tb.Load(ctx)
 }
 
+// burst returns the burst settings of the token bucket for spec.
+func (m *Manager) burst(spec quota.Spec) redistb.Burst {
+	if m.opts.BurstParameters == nil {
+		return redistb.Burst{}
+	}
+	return m.opts.BurstParameters(spec)
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 func specNames(prefix string, specs []quota.Spec) []string {
 	names := make([]string, 0, len(specs))
 	for _, spec := range specs {
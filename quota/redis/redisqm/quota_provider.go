@@ -0,0 +1,138 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redisqm
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/golang/glog"
+	"github.com/google/trillian/quota"
+)
+
+// QuotaManagerName identifies the Redis quota implementation.
+const QuotaManagerName = "redis"
+
+const limitUsage = "Token bucket of %s quotas, as capacity:rate, i.e. the number of tokens it holds and " +
+	"the number of tokens replenished per second; unlimited if empty. Only effective for --quota_system=redis."
+
+var (
+	redisAddr     = flag.String("redis_quota_addr", "", "Address (host:port) of the Redis server holding quota token buckets")
+	redisPassword = flag.String("redis_quota_password", "", "Password of the Redis server holding quota token buckets")
+	redisDB       = flag.Int("redis_quota_db", 0, "Redis database holding quota token buckets")
+	redisPrefix   = flag.String("redis_quota_prefix", "", "Prefix of the Redis keys of quota token buckets, e.g. when a Redis server is shared")
+
+	globalReadLimit  = flag.String("redis_quota_global_read", "", fmt.Sprintf(limitUsage, "global read"))
+	globalWriteLimit = flag.String("redis_quota_global_write", "", fmt.Sprintf(limitUsage, "global write"))
+	treeReadLimit    = flag.String("redis_quota_tree_read", "", fmt.Sprintf(limitUsage, "per-tree read"))
+	treeWriteLimit   = flag.String("redis_quota_tree_write", "", fmt.Sprintf(limitUsage, "per-tree write"))
+)
+
+func init() {
+	if err := quota.RegisterProvider(QuotaManagerName, newRedisQuotaManager); err != nil {
+		glog.Fatalf("Failed to register quota manager %v: %v", QuotaManagerName, err)
+	}
+}
+
+func newRedisQuotaManager() (quota.Manager, error) {
+	if *redisAddr == "" {
+		return nil, fmt.Errorf("can't create redis quotamanager - redis_quota_addr flag is unset")
+	}
+	limits := make(map[limitKey]limit)
+	for _, f := range []struct {
+		group quota.Group
+		kind  quota.Kind
+		value string
+	}{
+		{group: quota.Global, kind: quota.Read, value: *globalReadLimit},
+		{group: quota.Global, kind: quota.Write, value: *globalWriteLimit},
+		{group: quota.Tree, kind: quota.Read, value: *treeReadLimit},
+		{group: quota.Tree, kind: quota.Write, value: *treeWriteLimit},
+	} {
+		if f.value == "" {
+			continue
+		}
+		l, err := parseLimit(f.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v %v quota limit: %v", f.group, f.kind, err)
+		}
+		limits[limitKey{group: f.group, kind: f.kind}] = l
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     *redisAddr,
+		Password: *redisPassword,
+		DB:       *redisDB,
+	})
+	qm := New(client, ManagerOptions{
+		Parameters: limitParameters(limits),
+		Prefix:     *redisPrefix,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := qm.Load(ctx); err != nil {
+		// Scripts are sent in full until they are loaded.
+		glog.Warningf("Failed to load quota scripts into Redis at %v: %v", *redisAddr, err)
+	}
+	glog.Info("Using Redis QuotaManager")
+	return qm, nil
+}
+
+// limitKey identifies the specs a limit applies to.
+type limitKey struct {
+	group quota.Group
+	kind  quota.Kind
+}
+
+// limit is the size of a token bucket.
+type limit struct {
+	capacity int
+	rate     float64
+}
+
+// parseLimit parses a limit formatted as capacity:rate.
+func parseLimit(s string) (limit, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return limit{}, fmt.Errorf("%q is not of the form capacity:rate", s)
+	}
+	capacity, err := strconv.Atoi(parts[0])
+	if err != nil || capacity <= 0 {
+		return limit{}, fmt.Errorf("capacity %q must be a positive integer", parts[0])
+	}
+	rate, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || rate < 0 {
+		return limit{}, fmt.Errorf("rate %q must be a non-negative number", parts[1])
+	}
+	return limit{capacity: capacity, rate: rate}, nil
+}
+
+// limitParameters returns a ParameterFunc giving specs the limit of their
+// group and kind. Specs without a limit, such as those of users, are
+// unlimited.
+func limitParameters(limits map[limitKey]limit) ParameterFunc {
+	return func(spec quota.Spec) (int, float64) {
+		l, ok := limits[limitKey{group: spec.Group, kind: spec.Kind}]
+		if !ok {
+			return quota.MaxTokens, 0
+		}
+		return l.capacity, l.rate
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redisqm
+
+import (
+	"testing"
+
+	"github.com/google/trillian/quota"
+)
+
+func TestParseLimit(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    limit
+		wantErr bool
+	}{
+		{in: "100:10", want: limit{capacity: 100, rate: 10}},
+		{in: "5:0.5", want: limit{capacity: 5, rate: 0.5}},
+		{in: "5:0", want: limit{capacity: 5}},
+		{in: "", wantErr: true},
+		{in: "100", wantErr: true},
+		{in: "100:10:1", wantErr: true},
+		{in: "0:10", wantErr: true},
+		{in: "-1:10", wantErr: true},
+		{in: "a:10", wantErr: true},
+		{in: "100:-1", wantErr: true},
+		{in: "100:b", wantErr: true},
+	} {
+		got, err := parseLimit(test.in)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("parseLimit(%q): %v, wantErr %v", test.in, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseLimit(%q)=%+v, want %+v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestLimitParameters(t *testing.T) {
+	params := limitParameters(map[limitKey]limit{
+		{group: quota.Global, kind: quota.Write}: {capacity: 1000, rate: 100},
+		{group: quota.Tree, kind: quota.Write}:   {capacity: 50, rate: 5},
+	})
+	for _, test := range []struct {
+		spec         quota.Spec
+		wantCapacity int
+		wantRate     float64
+	}{
+		{spec: quota.Spec{Group: quota.Global, Kind: quota.Write}, wantCapacity: 1000, wantRate: 100},
+		{spec: quota.Spec{Group: quota.Tree, Kind: quota.Write, TreeID: 1}, wantCapacity: 50, wantRate: 5},
+		{spec: quota.Spec{Group: quota.Tree, Kind: quota.Write, TreeID: 2}, wantCapacity: 50, wantRate: 5},
+		{spec: quota.Spec{Group: quota.Global, Kind: quota.Read}, wantCapacity: quota.MaxTokens},
+		{spec: quota.Spec{Group: quota.Tree, Kind: quota.Read, TreeID: 1}, wantCapacity: quota.MaxTokens},
+		{spec: quota.Spec{Group: quota.User, Kind: quota.Write, User: "alice"}, wantCapacity: quota.MaxTokens},
+	} {
+		capacity, rate := params(test.spec)
+		if capacity != test.wantCapacity || rate != test.wantRate {
+			t.Errorf("params(%v)=%d, %v, want %d, %v", test.spec, capacity, rate, test.wantCapacity, test.wantRate)
+		}
+	}
+}
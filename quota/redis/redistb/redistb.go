@@ -67,12 +67,28 @@ func (tb *TokenBucket) Reset(ctx context.Context, prefix string) error {
 
 	// Use `EVAL` so that deleting all keys is atomic.
 	resp := client.Eval(
-		`redis.call("del", KEYS[1]); redis.call("del", KEYS[2]); redis.call("del", KEYS[3])`,
+		`for _, key in ipairs(KEYS) do redis.call("del", key) end`,
 		tokenBucketKeys(prefix),
 	)
 	return resp.Err()
 }
 
+// Burst holds the optional settings of a token bucket which shape bursts of
+// requests.
+type Burst struct {
+	// Tokens is the number of tokens which may be taken beyond those in the
+	// bucket, leaving it in debt until replenishment pays it off. This allows
+	// short spikes of requests, while the sustained rate is still bounded by
+	// the replenishment rate.
+	Tokens int64
+	// WarmUp, if positive, makes a bucket which hasn't had tokens taken from
+	// it for this long warm up when they are taken again. For the next WarmUp,
+	// the number of tokens the bucket may hold ramps up linearly from the
+	// tokens replenished in a second to its capacity, and burst tokens don't
+	// apply.
+	WarmUp time.Duration
+}
+
 // Call implements the actual token bucket algorithm. Given a bucket with
 // capacity `capacity` and replenishment rate of `replenishRate` tokens per
 // second, it will first ensure that the bucket has the correct number of
@@ -89,6 +105,20 @@ func (tb *TokenBucket) Call(
 	capacity int64,
 	replenishRate float64,
 	numTokens int,
+) (bool, int64, error) {
+	return tb.CallWithBurst(ctx, prefix, capacity, replenishRate, numTokens, Burst{})
+}
+
+// CallWithBurst is like Call, but for a bucket with the given burst settings.
+// The remaining number of tokens it returns is negative if the bucket is in
+// debt.
+func (tb *TokenBucket) CallWithBurst(
+	ctx context.Context,
+	prefix string,
+	capacity int64,
+	replenishRate float64,
+	numTokens int,
+	burst Burst,
 ) (bool, int64, error) {
 	client := withClientContext(ctx, tb.c)
 
@@ -111,6 +141,9 @@ func (tb *TokenBucket) Call(
 		now,
 		nowUs,
 		tb.testing,
+
+		burst.Tokens,
+		burst.WarmUp.Microseconds(),
 	}
 
 	resp := updateTokenBucketScript.Run(
@@ -174,6 +207,8 @@ func tokenBucketKeys(prefix string) []string {
 		fmt.Sprintf("{%s}.tokens", prefix),
 		fmt.Sprintf("{%s}.refreshed", prefix),
 		fmt.Sprintf("{%s}.refreshed_us", prefix),
+		fmt.Sprintf("{%s}.used", prefix),
+		fmt.Sprintf("{%s}.warm_up_start", prefix),
 	}
 }
 
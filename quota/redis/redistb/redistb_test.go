@@ -725,6 +725,107 @@ func TestChangingReplenishRate(t *testing.T) {
 	}
 }
 
+// Ensure that tokens can be taken beyond those in the bucket, up to the burst
+// size, and that the debt is paid off by replenishment.
+func TestBurst(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+
+	const burst = 3
+	keys := makeKeys()
+	mustInitKeys(t, rdb, keys, TestCapacity, TestBaseTime)
+
+	for _, test := range []struct {
+		Name      string
+		TimeDelta time.Duration
+		Requested int64
+		Allowed   bool
+		Remaining int64
+	}{
+		{Name: "into debt", Requested: TestCapacity + burst, Allowed: true, Remaining: -burst},
+		{Name: "beyond burst", Requested: 1, Allowed: false, Remaining: -burst},
+		// Two seconds replenish 4 tokens, which pay off the debt first.
+		{Name: "paid off", TimeDelta: 2 * time.Second, Requested: 1, Allowed: true, Remaining: 0},
+	} {
+		argTimeSec, argTimeUs := timeToRedisPair(TestBaseTime.Add(test.TimeDelta))
+		resp := updateTokenBucketScript.Eval(
+			rdb,
+			keys.AsSlice(),
+
+			// Args
+			TestReplenishRate,
+			TestCapacity,
+			test.Requested,
+			argTimeSec,
+			argTimeUs,
+			"true",
+			burst,
+			0,
+		)
+		allowed, remaining, _, _ := deserializeRedisResults(t, resp)
+		if allowed != test.Allowed || remaining != test.Remaining {
+			t.Errorf("%s: got allowed %t, remaining %d, want %t, %d", test.Name, allowed, remaining, test.Allowed, test.Remaining)
+		}
+	}
+}
+
+// Ensure that a bucket warms up when tokens are first taken from it, and after
+// being idle.
+func TestWarmUp(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+
+	const (
+		capacity = 100
+		rate     = 10
+		burst    = 10
+		warmUp   = 10 * time.Second
+	)
+	keys := makeKeys()
+	mustInitKeys(t, rdb, keys, capacity, TestBaseTime)
+
+	for _, test := range []struct {
+		Name      string
+		TimeDelta time.Duration
+		Requested int64
+		Allowed   bool
+		Remaining int64
+	}{
+		// The bucket may only hold the tokens replenished in a second at
+		// first, and burst tokens don't apply.
+		{Name: "cold too many", Requested: rate + 1, Allowed: false, Remaining: rate},
+		{Name: "cold", Requested: rate, Allowed: true, Remaining: 0},
+		// Half way through, the bucket may hold half of its capacity.
+		{Name: "half way", TimeDelta: 5 * time.Second, Requested: capacity/2 + 1, Allowed: false, Remaining: capacity / 2},
+		// Once warm, the bucket is full and burst tokens apply.
+		{Name: "warm", TimeDelta: 10 * time.Second, Requested: capacity + burst, Allowed: true, Remaining: -burst},
+		// After an idle period, the bucket warms up again.
+		{Name: "idle", TimeDelta: 30 * time.Second, Requested: rate + 1, Allowed: false, Remaining: rate},
+	} {
+		argTimeSec, argTimeUs := timeToRedisPair(TestBaseTime.Add(test.TimeDelta))
+		resp := updateTokenBucketScript.Eval(
+			rdb,
+			keys.AsSlice(),
+
+			// Args
+			rate,
+			capacity,
+			test.Requested,
+			argTimeSec,
+			argTimeUs,
+			"true",
+			burst,
+			warmUp.Microseconds(),
+		)
+		allowed, remaining, _, _ := deserializeRedisResults(t, resp)
+		if allowed != test.Allowed || remaining != test.Remaining {
+			t.Errorf("%s: got allowed %t, remaining %d, want %t, %d", test.Name, allowed, remaining, test.Allowed, test.Remaining)
+		}
+	}
+}
+
 func TestErrorIfMicrosecondsTooLarge(t *testing.T) {
 	rdb := redis.NewClient(&redis.Options{
 		Addr: "localhost:6379",
@@ -778,11 +879,13 @@ type redisKeys struct {
 	Tokens      string
 	Refreshed   string
 	RefreshedUs string
+	Used        string
+	WarmUpStart string
 }
 
 // Get the keys in a slice format for passing to Eval
 func (r redisKeys) AsSlice() []string {
-	return []string{r.Tokens, r.Refreshed, r.RefreshedUs}
+	return []string{r.Tokens, r.Refreshed, r.RefreshedUs, r.Used, r.WarmUpStart}
 }
 
 // Helper function to create Redis key names wrapper type
@@ -797,6 +900,8 @@ func makeKeys() redisKeys {
 	ret.Tokens = keys[0]
 	ret.Refreshed = keys[1]
 	ret.RefreshedUs = keys[2]
+	ret.Used = keys[3]
+	ret.WarmUpStart = keys[4]
 	return ret
 }
 
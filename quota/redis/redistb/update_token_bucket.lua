@@ -37,11 +37,32 @@ For each key prefix, we maintain three values:
       time above. Stored separately because a Unix epoch with a microsecond
       component brushes up uncomfortably close to integer boundaries.
 
+    * `<prefix>.used`: Time in epoch seconds, with a fractional part, when
+      tokens were last taken from the bucket. Only maintained for buckets with
+      a warm-up.
+
+    * `<prefix>.warm_up_start`: Time in epoch seconds, with a fractional part,
+      when the last warm-up of the bucket started. Only maintained for buckets
+      with a warm-up.
+
 The basic strategy is to, at update/read time, fill in all tokens
 that would have accumulated since the last update, and then if
 possible deduct the number of requested tokens (or disallow the
 requested action if there are not enough tokens).
 
+Two optional settings shape bursts of requests:
+
+    * Burst: the number of tokens which may be taken beyond those in the
+      bucket, leaving it in debt (i.e. with a negative number of tokens) until
+      replenishment pays it off. Short spikes of requests are allowed, while
+      the sustained rate is still bounded by the replenishment rate.
+
+    * Warm-up: a bucket which hasn't had tokens taken from it for the warm-up
+      period warms up when they are taken again. For the next warm-up period,
+      the number of tokens the bucket may hold ramps up linearly from the
+      tokens replenished in a second to its capacity, and burst tokens don't
+      apply. This slow start protects backends which have gone cold.
+
 The approach relies on the atomicity of EVAL in redis - only 1 command (EVAL or
 otherwise) will be running concurrently per shard in the Redis cluster. Redis
 and Lua are very fast, so in practice this works out okay.
@@ -96,6 +117,10 @@ local key_tokens = KEYS[1]
 local key_refreshed = KEYS[2]
 local key_refreshed_us = KEYS[3]
 
+-- Only used for buckets with a warm-up.
+local key_used = KEYS[4]
+local key_warm_up_start = KEYS[5]
+
 local rate = tonumber(ARGV[1])
 local capacity = tonumber(ARGV[2])
 local requested = tonumber(ARGV[3])
@@ -110,6 +135,10 @@ local now_us = tonumber(ARGV[5])
 -- This is ugly, but all values passed in from Ruby get converted to strings
 local testing = ARGV[6] == "true"
 
+-- Optional burst settings, disabled if missing.
+local burst = tonumber(ARGV[7]) or 0
+local warm_up_us = tonumber(ARGV[8]) or 0
+
 --
 -- Program body
 --
@@ -136,6 +165,10 @@ else
     now_us = tonumber(current_time[2])
 end
 
+-- The current time in epoch seconds, with a fractional part, before it's
+-- adjusted for the replenishment below.
+local now_seconds = now + now_us / MICROSECONDS_IN_SECOND
+
 local filled_tokens = capacity
 
 local last_tokens = redis.call("GET", key_tokens)
@@ -180,7 +213,32 @@ if last_tokens and last_refreshed then
     end
 end
 
-local allowed = filled_tokens >= requested
+-- Buckets with a warm-up start warming up when tokens are taken from them
+-- after the warm-up period of idleness, and may only hold up to a ceiling
+-- which ramps up to their capacity meanwhile.
+local floor = -burst
+local used = nil
+local warm_up_start = nil
+if warm_up_us > 0 and requested > 0 then
+    used = now_seconds
+    local warm_up = warm_up_us / MICROSECONDS_IN_SECOND
+
+    local last_used = tonumber(redis.call("GET", key_used))
+    warm_up_start = tonumber(redis.call("GET", key_warm_up_start))
+    if not last_used or not warm_up_start or used - last_used >= warm_up then
+        warm_up_start = used
+    end
+
+    local elapsed = used - warm_up_start
+    if elapsed < warm_up then
+        local ceiling = math.floor(capacity * elapsed / warm_up)
+        ceiling = math.min(capacity, math.max(ceiling, math.ceil(rate)))
+        filled_tokens = math.min(filled_tokens, ceiling)
+        floor = 0
+    end
+end
+
+local allowed = filled_tokens - requested >= floor
 local new_tokens = filled_tokens
 if allowed then
     new_tokens = filled_tokens - requested
@@ -194,7 +252,7 @@ end
 -- Keys that are ~always in use because their owners make frequent requests
 -- will be updated by this script constantly (which sets new TTLs), and
 -- never expire.
-local fill_time = math.ceil(capacity / rate)
+local fill_time = math.ceil((capacity + burst) / rate)
 local ttl = math.floor(fill_time * 2)
 
 -- Redis will reject a expiry of 0 to `SETEX`, so make sure TTL is always at
@@ -213,4 +271,12 @@ redis.call("SETEX", key_tokens, ttl, new_tokens)
 redis.call("SETEX", key_refreshed, ttl, now)
 redis.call("SETEX", key_refreshed_us, ttl, now_us)
 
+-- The use of the bucket must be remembered for the warm-up period at least, to
+-- tell whether it has been idle for that long.
+if used then
+    local warm_up_ttl = math.max(ttl, math.ceil(2 * warm_up_us / MICROSECONDS_IN_SECOND))
+    redis.call("SETEX", key_used, warm_up_ttl, string.format("%.6f", used))
+    redis.call("SETEX", key_warm_up_start, warm_up_ttl, string.format("%.6f", warm_up_start))
+end
+
 return { allowed, new_tokens, now, now_us }
@@ -20,13 +20,18 @@ import (
 	"fmt"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/merkle/hashers/registry"
+	serrors "github.com/google/trillian/server/errors"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
+	"github.com/google/trillian/util/clock"
 	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -34,10 +39,14 @@ import (
 	_ "github.com/google/trillian/merkle/rfc6962" // Make hashers available
 )
 
+// maxRequestIDLength is the maximum length of CreateTreeRequest.request_id.
+const maxRequestIDLength = 128
+
 // Server is an implementation of trillian.TrillianAdminServer.
 type Server struct {
 	registry         extension.Registry
 	allowedTreeTypes []trillian.TreeType
+	timeSource       clock.TimeSource
 }
 
 // New returns a trillian.TrillianAdminServer implementation.
@@ -48,6 +57,7 @@ func New(registry extension.Registry, allowedTreeTypes []trillian.TreeType) *Ser
 	return &Server{
 		registry:         registry,
 		allowedTreeTypes: allowedTreeTypes,
+		timeSource:       clock.System,
 	}
 }
 
@@ -83,39 +93,47 @@ func (s *Server) GetTree(ctx context.Context, req *trillian.GetTreeRequest) (*tr
 func (s *Server) CreateTree(ctx context.Context, req *trillian.CreateTreeRequest) (*trillian.Tree, error) {
 	tree := req.GetTree()
 	if tree == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "a tree is required")
+		return nil, serrors.InvalidArgument("tree", "a tree is required")
 	}
-	if err := s.validateAllowedTreeType(tree.TreeType); err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+	if len(req.RequestId) > maxRequestIDLength {
+		return nil, serrors.InvalidArgument("request_id", "request_id longer than %d characters", maxRequestIDLength)
 	}
-	switch tree.TreeType {
-	case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
-		if _, err := registry.NewLogHasher(tree.HashStrategy); err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "failed to create hasher for tree: %v", err.Error())
-		}
-	case trillian.TreeType_MAP:
-		if _, err := registry.NewMapHasher(tree.HashStrategy); err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "failed to create hasher for tree: %v", err.Error())
+	// Retries of a request return the tree it created, if any.
+	if req.RequestId != "" {
+		switch created, err := storage.GetTreeByCreateRequestID(ctx, s.registry.AdminStorage, req.RequestId); status.Code(err) {
+		case codes.OK:
+			return retriedTree(req, created)
+		case codes.NotFound:
+		default:
+			return nil, err
 		}
-	default:
-		return nil, status.Errorf(codes.InvalidArgument, "invalid tree type: %v", tree.TreeType)
+		// Leave the request as it was for retriedTree, as tree is changed
+		// below.
+		tree = proto.Clone(tree).(*trillian.Tree)
+	}
+	if err := s.validateTreeType(tree); err != nil {
+		return nil, err
+	}
+	treeID, err := s.newTreeID(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
 	// If a key specification was provided, generate a new key.
 	if req.KeySpec != nil {
 		if tree.PrivateKey != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "the tree.private_key and key_spec fields are mutually exclusive")
+			return nil, serrors.InvalidArgument("key_spec", "the tree.private_key and key_spec fields are mutually exclusive")
 		}
 		if tree.PublicKey != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "the tree.public_key and key_spec fields are mutually exclusive")
+			return nil, serrors.InvalidArgument("key_spec", "the tree.public_key and key_spec fields are mutually exclusive")
 		}
 		if s.registry.NewKeyProto == nil {
-			return nil, status.Errorf(codes.FailedPrecondition, "key generation is not enabled")
+			return nil, serrors.FailedPrecondition("KEY_GENERATION", "key_spec", "key generation is not enabled")
 		}
 
 		keyProto, err := s.registry.NewKeyProto(ctx, req.KeySpec)
 		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "failed to generate private key: %v", err.Error())
+			return nil, serrors.InvalidArgument("key_spec", "failed to generate private key: %v", err.Error())
 		}
 
 		tree.PrivateKey, err = ptypes.MarshalAny(keyProto)
@@ -125,44 +143,148 @@ func (s *Server) CreateTree(ctx context.Context, req *trillian.CreateTreeRequest
 	}
 
 	if tree.PrivateKey == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "tree.private_key or key_spec is required")
+		return nil, serrors.InvalidArgument("tree.private_key", "tree.private_key or key_spec is required")
+	}
+
+	publicKey, err := derivePublicKey(ctx, tree)
+	if err != nil {
+		return nil, err
 	}
+	tree.PublicKey = publicKey
 
+	// Clear generated fields, storage must set those
+	tree.TreeId = treeID
+	tree.CreateTime = nil
+	tree.UpdateTime = nil
+	tree.Deleted = false
+	tree.DeleteTime = nil
+	tree.CreateRequestId = req.RequestId
+
+	createdTree, err := storage.CreateTree(ctx, s.registry.AdminStorage, tree)
+	if err != nil {
+		// A concurrent retry of the request may have created the tree first,
+		// in which case storage rejects the duplicate request ID.
+		if req.RequestId != "" && status.Code(err) == codes.AlreadyExists {
+			if created, ferr := storage.GetTreeByCreateRequestID(ctx, s.registry.AdminStorage, req.RequestId); ferr == nil {
+				return retriedTree(req, created)
+			}
+		}
+		return nil, err
+	}
+	return redact(createdTree), nil
+}
+
+// derivePublicKey returns the public key of the private key of tree, which
+// must match tree.public_key if that's set.
+func derivePublicKey(ctx context.Context, tree *trillian.Tree) (*keyspb.PublicKey, error) {
 	// Check that the tree.PrivateKey is valid by trying to get a signer.
 	signer, err := trees.Signer(ctx, tree)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "failed to create signer for tree: %v", err.Error())
+		return nil, serrors.InvalidArgument("tree.private_key", "failed to create signer for tree: %v", err.Error())
 	}
 
 	// Derive the public key that corresponds to the private key for this tree.
 	// The caller may have provided the public key, but for safety we shouldn't rely on it being correct.
 	publicKey, err := der.ToPublicProto(signer.Public())
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "failed to marshal public key: %v", err.Error())
+		return nil, serrors.InvalidArgument("tree.private_key", "failed to marshal public key: %v", err.Error())
 	}
 
 	// If a public key was provided, check that it matches the one we derived. If it doesn't, this indicates a mistake by the caller.
 	if tree.PublicKey != nil && !bytes.Equal(tree.PublicKey.Der, publicKey.Der) {
-		return nil, status.Error(codes.InvalidArgument, "the public and private keys are not a pair")
+		return nil, serrors.InvalidArgument("tree.public_key", "the public and private keys are not a pair")
 	}
+	return publicKey, nil
+}
 
-	// If no public key was provided, use the DER that we just marshaled.
-	if tree.PublicKey == nil {
-		tree.PublicKey = publicKey
+// newTreeID returns the ID chosen for the tree of a CreateTree request, or
+// derived from the name chosen for it, or zero for storage to choose a random
+// one. It returns an AlreadyExists error if a tree has the ID.
+func (s *Server) newTreeID(ctx context.Context, req *trillian.CreateTreeRequest) (int64, error) {
+	var id int64
+	switch {
+	case req.TreeId < 0:
+		return 0, serrors.InvalidArgument("tree_id", "tree_id must not be negative, got %v", req.TreeId)
+	case req.TreeId != 0 && req.TreeIdName != "":
+		return 0, serrors.InvalidArgument("tree_id_name", "the tree_id and tree_id_name fields are mutually exclusive")
+	case req.TreeId != 0:
+		id = req.TreeId
+	case req.TreeIdName != "":
+		id = storage.DeriveTreeID(req.TreeIdName)
+	default:
+		return 0, nil
+	}
+	switch _, err := storage.GetTree(ctx, s.registry.AdminStorage, id); status.Code(err) {
+	case codes.NotFound:
+		return id, nil
+	case codes.OK:
+		return 0, status.Errorf(codes.AlreadyExists, "tree %v already exists", id)
+	default:
+		return 0, err
 	}
+}
 
-	// Clear generated fields, storage must set those
-	tree.TreeId = 0
-	tree.CreateTime = nil
-	tree.UpdateTime = nil
-	tree.Deleted = false
-	tree.DeleteTime = nil
+// retriedTree returns the tree created by an earlier CreateTree request with
+// the request ID of req. It returns a FailedPrecondition error if req asks for
+// a different tree ID, or for a value of a readonly field the created tree
+// doesn't have: the request ID was reused for another tree. Fields which may
+// have been updated since the tree was created aren't compared.
+func retriedTree(req *trillian.CreateTreeRequest, created *trillian.Tree) (*trillian.Tree, error) {
+	reused := func(field string, format string, args ...interface{}) error {
+		return serrors.FailedPrecondition("REQUEST_ID_REUSED", field, "request ID %q was used to create tree %v with %v", req.RequestId, created.TreeId, fmt.Sprintf(format, args...))
+	}
+	switch {
+	case req.TreeId != 0 && req.TreeId != created.TreeId:
+		return nil, reused("tree_id", "another tree ID than %v", req.TreeId)
+	case req.TreeIdName != "" && storage.DeriveTreeID(req.TreeIdName) != created.TreeId:
+		return nil, reused("tree_id_name", "another tree ID than the one of %q", req.TreeIdName)
+	}
+	tree := req.GetTree()
+	// A PREORDERED_LOG may have since become a LOG.
+	if want, got := tree.TreeType, created.TreeType; want != trillian.TreeType_UNKNOWN_TREE_TYPE && want != got &&
+		!(want == trillian.TreeType_PREORDERED_LOG && got == trillian.TreeType_LOG) {
+		return nil, reused("tree.tree_type", "tree_type %v, not %v", got, want)
+	}
+	for _, f := range treeFields {
+		if !f.readonly {
+			continue
+		}
+		if want, got := f.value(tree), f.value(created); want != "" && want != got {
+			return nil, reused("tree."+f.name, "%v %q, not %q", f.name, got, want)
+		}
+	}
+	return redact(created), nil
+}
 
-	createdTree, err := storage.CreateTree(ctx, s.registry.AdminStorage, tree)
-	if err != nil {
-		return nil, err
+// validateTreeType checks that a tree of the type and hash strategy of tree
+// may be created.
+func (s *Server) validateTreeType(tree *trillian.Tree) error {
+	if err := s.validateAllowedTreeType(tree.TreeType); err != nil {
+		return serrors.InvalidArgument("tree.tree_type", "%v", err)
 	}
-	return redact(createdTree), nil
+	switch tree.TreeType {
+	case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
+		if _, err := registry.NewLogHasher(tree.HashStrategy); err != nil {
+			return serrors.InvalidArgument("tree.hash_strategy", "failed to create hasher for tree: %v", err.Error())
+		}
+	case trillian.TreeType_MAP:
+		hasher, err := registry.NewMapHasherForTree(tree)
+		if err != nil {
+			return serrors.InvalidArgument("tree.hash_strategy", "failed to create hasher for tree: %v", err.Error())
+		}
+		if len(tree.MapStrata) > 0 {
+			height := 0
+			for _, h := range tree.MapStrata {
+				height += int(h)
+			}
+			if height != hasher.BitLen() {
+				return serrors.InvalidArgument("tree.map_strata", "strata %v add up to %d, want the bit length of the hasher, %d", tree.MapStrata, height, hasher.BitLen())
+			}
+		}
+	default:
+		return serrors.InvalidArgument("tree.tree_type", "invalid tree type: %v", tree.TreeType)
+	}
+	return nil
 }
 
 func (s *Server) validateAllowedTreeType(tt trillian.TreeType) error {
@@ -182,14 +304,42 @@ func (s *Server) UpdateTree(ctx context.Context, req *trillian.UpdateTreeRequest
 	tree := req.GetTree()
 	mask := req.GetUpdateMask()
 	if tree == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "a tree is required")
+		return nil, serrors.InvalidArgument("tree", "a tree is required")
 	}
 	// Apply the mask to a couple of empty trees just to check that the paths are correct.
 	if err := applyUpdateMask(&trillian.Tree{}, &trillian.Tree{}, mask); err != nil {
 		return nil, err
 	}
 
+	// Updating the public key along with the private key rotates the key of
+	// the tree, retiring its previous public key.
+	var retireTime *timestamp.Timestamp
+	if hasPath(mask, "public_key") {
+		if !hasPath(mask, "private_key") {
+			return nil, serrors.InvalidArgument("update_mask", "public_key can only be updated along with private_key, to rotate the key")
+		}
+		// The signer of the new key depends on the signature algorithm of
+		// the stored tree, which isn't part of the request.
+		stored, err := storage.GetTree(ctx, s.registry.AdminStorage, tree.TreeId)
+		if err != nil {
+			return nil, err
+		}
+		stored.PrivateKey, stored.PublicKey = tree.PrivateKey, tree.PublicKey
+		publicKey, err := derivePublicKey(ctx, stored)
+		if err != nil {
+			return nil, err
+		}
+		tree = proto.Clone(tree).(*trillian.Tree)
+		tree.PublicKey = publicKey
+		if retireTime, err = ptypes.TimestampProto(s.timeSource.Now()); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to build retire time: %v", err)
+		}
+	}
+
 	updatedTree, err := storage.UpdateTree(ctx, s.registry.AdminStorage, tree.TreeId, func(other *trillian.Tree) {
+		if retireTime != nil && !proto.Equal(other.PublicKey, tree.PublicKey) {
+			other.RetiredPublicKeys = append(other.RetiredPublicKeys, &trillian.RetiredPublicKey{PublicKey: other.PublicKey, RetireTime: retireTime})
+		}
 		if err := applyUpdateMask(tree, other, mask); err != nil {
 			// Should never happen (famous last words).
 			glog.Errorf("Error applying mask on tree update: %v", err)
@@ -203,9 +353,9 @@ func (s *Server) UpdateTree(ctx context.Context, req *trillian.UpdateTreeRequest
 
 func applyUpdateMask(from, to *trillian.Tree, mask *field_mask.FieldMask) error {
 	if mask == nil || len(mask.Paths) == 0 {
-		return status.Errorf(codes.InvalidArgument, "an update_mask is required")
+		return serrors.InvalidArgument("update_mask", "an update_mask is required")
 	}
-	for _, path := range mask.Paths {
+	for i, path := range mask.Paths {
 		switch path {
 		case "tree_state":
 			to.TreeState = from.TreeState
@@ -221,13 +371,37 @@ func applyUpdateMask(from, to *trillian.Tree, mask *field_mask.FieldMask) error
 			to.MaxRootDuration = from.MaxRootDuration
 		case "private_key":
 			to.PrivateKey = from.PrivateKey
+		case "public_key":
+			to.PublicKey = from.PublicKey
+		case "rate_limits":
+			to.RateLimits = from.RateLimits
+		case "labels":
+			to.Labels = from.Labels
+		case "maintenance":
+			to.Maintenance = from.Maintenance
+		case "map_compression":
+			to.MapCompression = from.MapCompression
+		case "auto_init":
+			to.AutoInit = from.AutoInit
+		case "max_storage_bytes":
+			to.MaxStorageBytes = from.MaxStorageBytes
 		default:
-			return status.Errorf(codes.InvalidArgument, "invalid update_mask path: %q", path)
+			return serrors.InvalidArgument(fmt.Sprintf("update_mask.paths[%d]", i), "invalid update_mask path: %q", path)
 		}
 	}
 	return nil
 }
 
+// hasPath returns whether path is one of the paths of mask.
+func hasPath(mask *field_mask.FieldMask, path string) bool {
+	for _, p := range mask.GetPaths() {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
 // DeleteTree implements trillian.TrillianAdminServer.DeleteTree.
 func (s *Server) DeleteTree(ctx context.Context, req *trillian.DeleteTreeRequest) (*trillian.Tree, error) {
 	tree, err := storage.SoftDeleteTree(ctx, s.registry.AdminStorage, req.GetTreeId())
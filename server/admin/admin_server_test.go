@@ -39,8 +39,11 @@ import (
 	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/crypto/sigpb"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/merkle/maphasher"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/util/clock"
 	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -48,6 +51,13 @@ import (
 	ttestonly "github.com/google/trillian/testonly"
 )
 
+// customMapHasher is the name of a map hasher registered for tests.
+const customMapHasher = "admin-test-hasher"
+
+func init() {
+	registry.RegisterNamedMapHasher(customMapHasher, maphasher.New(crypto.SHA256))
+}
+
 func TestServer_BeginError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -588,6 +598,40 @@ func TestServer_CreateTree_AllowedTreeTypes(t *testing.T) {
 			req:       &trillian.CreateTreeRequest{Tree: testonly.MapTree},
 			wantCode:  codes.OK,
 		},
+		{
+			desc:      "mapStrataTooShort",
+			treeTypes: []trillian.TreeType{trillian.TreeType_MAP},
+			req: &trillian.CreateTreeRequest{Tree: func() *trillian.Tree {
+				tree := proto.Clone(testonly.MapTree).(*trillian.Tree)
+				tree.MapStrata = []int32{8, 8}
+				return tree
+			}()},
+			wantCode: codes.InvalidArgument,
+			wantMsg:  "add up to 16",
+		},
+		{
+			desc:      "customMapHasher",
+			treeTypes: []trillian.TreeType{trillian.TreeType_MAP},
+			req: &trillian.CreateTreeRequest{Tree: func() *trillian.Tree {
+				tree := proto.Clone(testonly.MapTree).(*trillian.Tree)
+				tree.HashStrategy = trillian.HashStrategy_CUSTOM_MAP_HASHER
+				tree.MapHasher = customMapHasher
+				return tree
+			}()},
+			wantCode: codes.OK,
+		},
+		{
+			desc:      "unregisteredMapHasher",
+			treeTypes: []trillian.TreeType{trillian.TreeType_MAP},
+			req: &trillian.CreateTreeRequest{Tree: func() *trillian.Tree {
+				tree := proto.Clone(testonly.MapTree).(*trillian.Tree)
+				tree.HashStrategy = trillian.HashStrategy_CUSTOM_MAP_HASHER
+				tree.MapHasher = "unregistered"
+				return tree
+			}()},
+			wantCode: codes.InvalidArgument,
+			wantMsg:  "unknown hasher",
+		},
 		// treeTypes = nil is exercised by all other tests.
 	}
 
@@ -617,6 +661,280 @@ func TestServer_CreateTree_AllowedTreeTypes(t *testing.T) {
 	}
 }
 
+// requestIDTX is a ReadOnlyAdminTX which looks trees up by request ID,
+// finding tree if it's set.
+type requestIDTX struct {
+	*storage.MockReadOnlyAdminTX
+	tree *trillian.Tree
+}
+
+func (tx requestIDTX) GetTreeByCreateRequestID(ctx context.Context, requestID string) (*trillian.Tree, error) {
+	if tx.tree == nil || tx.tree.CreateRequestId != requestID {
+		return nil, status.Errorf(codes.NotFound, "no tree was created with request ID %q", requestID)
+	}
+	return proto.Clone(tx.tree).(*trillian.Tree), nil
+}
+
+func TestServer_CreateTree_RequestID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const requestID = "6f4c8a8e-4dc1-4b5c-9a3b-1f6a4c2e7d90"
+	created := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	created.TreeId = 12345
+	created.CreateRequestId = requestID
+	deleted := proto.Clone(created).(*trillian.Tree)
+	deleted.Deleted = true
+	renamed := proto.Clone(created).(*trillian.Tree)
+	renamed.DisplayName = "Renamed since"
+
+	tests := []struct {
+		desc      string
+		requestID string
+		// modifyReq changes the request from one for testonly.LogTree.
+		modifyReq func(*trillian.CreateTreeRequest)
+		// lookups holds the tree found by each lookup of the request ID,
+		// with nil meaning none.
+		lookups    []*trillian.Tree
+		wantCreate bool
+		createErr  error
+		want       *trillian.Tree
+		wantCode   codes.Code
+	}{
+		{
+			desc:       "firstAttempt",
+			requestID:  requestID,
+			lookups:    []*trillian.Tree{nil},
+			wantCreate: true,
+			want:       created,
+		},
+		{
+			desc:      "retry",
+			requestID: requestID,
+			lookups:   []*trillian.Tree{created},
+			want:      created,
+		},
+		{
+			desc:      "retryOfDeleted",
+			requestID: requestID,
+			lookups:   []*trillian.Tree{deleted},
+			want:      deleted,
+		},
+		{
+			desc:      "retryOfUpdated",
+			requestID: requestID,
+			lookups:   []*trillian.Tree{renamed},
+			want:      renamed,
+		},
+		{
+			desc:      "retryWithTreeID",
+			requestID: requestID,
+			modifyReq: func(req *trillian.CreateTreeRequest) { req.TreeId = created.TreeId },
+			lookups:   []*trillian.Tree{created},
+			want:      created,
+		},
+		{
+			desc:      "retryWithOtherTreeID",
+			requestID: requestID,
+			modifyReq: func(req *trillian.CreateTreeRequest) { req.TreeId = created.TreeId + 1 },
+			lookups:   []*trillian.Tree{created},
+			wantCode:  codes.FailedPrecondition,
+		},
+		{
+			desc:      "retryWithOtherTreeIDName",
+			requestID: requestID,
+			modifyReq: func(req *trillian.CreateTreeRequest) { req.TreeIdName = "ct/argon2021" },
+			lookups:   []*trillian.Tree{created},
+			wantCode:  codes.FailedPrecondition,
+		},
+		{
+			desc:      "retryWithOtherTreeType",
+			requestID: requestID,
+			modifyReq: func(req *trillian.CreateTreeRequest) { req.Tree.TreeType = trillian.TreeType_MAP },
+			lookups:   []*trillian.Tree{created},
+			wantCode:  codes.FailedPrecondition,
+		},
+		{
+			desc:      "retryOfFrozenPreorderedLog",
+			requestID: requestID,
+			modifyReq: func(req *trillian.CreateTreeRequest) { req.Tree.TreeType = trillian.TreeType_PREORDERED_LOG },
+			lookups:   []*trillian.Tree{created},
+			want:      created,
+		},
+		{
+			desc:      "retryWithOtherReadonlyField",
+			requestID: requestID,
+			modifyReq: func(req *trillian.CreateTreeRequest) { req.Tree.MapStrata = []int32{8, 248} },
+			lookups:   []*trillian.Tree{created},
+			wantCode:  codes.FailedPrecondition,
+		},
+		{
+			desc:       "concurrentRetry",
+			requestID:  requestID,
+			lookups:    []*trillian.Tree{nil, created},
+			wantCreate: true,
+			createErr:  status.Error(codes.AlreadyExists, "duplicate request ID"),
+			want:       created,
+		},
+		{
+			desc:       "concurrentOtherRequest",
+			requestID:  requestID,
+			modifyReq:  func(req *trillian.CreateTreeRequest) { req.Tree.MapStrata = []int32{8, 248} },
+			lookups:    []*trillian.Tree{nil, created},
+			wantCreate: true,
+			createErr:  status.Error(codes.AlreadyExists, "duplicate request ID"),
+			wantCode:   codes.FailedPrecondition,
+		},
+		{
+			desc:       "createErr",
+			requestID:  requestID,
+			lookups:    []*trillian.Tree{nil},
+			wantCreate: true,
+			createErr:  status.Error(codes.Unavailable, "database unavailable"),
+			wantCode:   codes.Unavailable,
+		},
+		{
+			desc:      "tooLong",
+			requestID: strings.Repeat("x", maxRequestIDLength+1),
+			wantCode:  codes.InvalidArgument,
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			setup := setupAdminServer(ctrl, nil /* keygen */, false /* snapshot */, test.wantCreate && test.createErr == nil, false /* commitErr */)
+			as := setup.as.(*testonly.FakeAdminStorage)
+			if test.wantCreate {
+				setup.tx.EXPECT().CreateTree(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+					if got := tree.CreateRequestId; got != test.requestID {
+						t.Errorf("CreateTree(): create_request_id %q, want %q", got, test.requestID)
+					}
+					if test.createErr != nil {
+						return nil, test.createErr
+					}
+					return proto.Clone(created).(*trillian.Tree), nil
+				})
+			}
+			for _, tree := range test.lookups {
+				tx := storage.NewMockReadOnlyAdminTX(ctrl)
+				if tree != nil {
+					tx.EXPECT().Commit().Return(nil)
+				}
+				tx.EXPECT().Close().MaxTimes(1).Return(nil)
+				as.ReadOnlyTX = append(as.ReadOnlyTX, requestIDTX{MockReadOnlyAdminTX: tx, tree: tree})
+			}
+
+			req := &trillian.CreateTreeRequest{Tree: proto.Clone(testonly.LogTree).(*trillian.Tree), RequestId: test.requestID}
+			if test.modifyReq != nil {
+				test.modifyReq(req)
+			}
+			tree, err := setup.server.CreateTree(ctx, req)
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("CreateTree(): %v, want code %v", err, test.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			want := proto.Clone(test.want).(*trillian.Tree)
+			want.PrivateKey = nil // redacted
+			if !proto.Equal(tree, want) {
+				t.Errorf("CreateTree() diff (-got +want):\n%v", cmp.Diff(tree, want, cmp.Comparer(proto.Equal)))
+			}
+		})
+	}
+}
+
+func TestServer_CreateTree_TreeID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const treeID = 12345
+	const name = "ct/argon2021"
+
+	tests := []struct {
+		desc     string
+		req      *trillian.CreateTreeRequest
+		wantID   int64
+		exists   bool
+		wantCode codes.Code
+	}{
+		{
+			desc: "random",
+			req:  &trillian.CreateTreeRequest{},
+		},
+		{
+			desc:   "chosen",
+			req:    &trillian.CreateTreeRequest{TreeId: treeID},
+			wantID: treeID,
+		},
+		{
+			desc:   "derived",
+			req:    &trillian.CreateTreeRequest{TreeIdName: name},
+			wantID: storage.DeriveTreeID(name),
+		},
+		{
+			desc:     "chosenTaken",
+			req:      &trillian.CreateTreeRequest{TreeId: treeID},
+			wantID:   treeID,
+			exists:   true,
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			desc:     "derivedTaken",
+			req:      &trillian.CreateTreeRequest{TreeIdName: name},
+			wantID:   storage.DeriveTreeID(name),
+			exists:   true,
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			desc:     "negative",
+			req:      &trillian.CreateTreeRequest{TreeId: -1},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "chosenAndDerived",
+			req:      &trillian.CreateTreeRequest{TreeId: treeID, TreeIdName: name},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			wantCreate := test.wantCode == codes.OK
+			setup := setupAdminServer(ctrl, nil /* keygen */, false /* snapshot */, wantCreate, false /* commitErr */)
+			if test.wantID != 0 {
+				tx := storage.NewMockReadOnlyAdminTX(ctrl)
+				if test.exists {
+					tx.EXPECT().GetTree(gomock.Any(), test.wantID).Return(&trillian.Tree{TreeId: test.wantID}, nil)
+					tx.EXPECT().Commit().Return(nil)
+				} else {
+					tx.EXPECT().GetTree(gomock.Any(), test.wantID).Return(nil, status.Errorf(codes.NotFound, "tree %v not found", test.wantID))
+				}
+				tx.EXPECT().Close().MaxTimes(1).Return(nil)
+				as := setup.as.(*testonly.FakeAdminStorage)
+				as.ReadOnlyTX = append(as.ReadOnlyTX, tx)
+			}
+			if wantCreate {
+				setup.tx.EXPECT().CreateTree(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+					if tree.TreeId != test.wantID {
+						t.Errorf("CreateTree(): tree_id %v, want %v", tree.TreeId, test.wantID)
+					}
+					return proto.Clone(tree).(*trillian.Tree), nil
+				})
+			}
+
+			req := proto.Clone(test.req).(*trillian.CreateTreeRequest)
+			req.Tree = proto.Clone(testonly.LogTree).(*trillian.Tree)
+			req.Tree.TreeId = 67890 // Ignored.
+			if _, err := setup.server.CreateTree(ctx, req); status.Code(err) != test.wantCode {
+				t.Errorf("CreateTree(): %v, want code %v", err, test.wantCode)
+			}
+		})
+	}
+}
+
 func TestServer_UpdateTree(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -639,9 +957,10 @@ func TestServer_UpdateTree(t *testing.T) {
 		StorageSettings: settings,
 		MaxRootDuration: ptypes.DurationProto(2 * time.Nanosecond),
 		PrivateKey:      ttestonly.MustMarshalAny(t, &empty.Empty{}),
+		RateLimits:      &trillian.TreeRateLimits{QueriesPerSecond: 10, LeavesPerSecond: 1000},
 	}
 	successMask := &field_mask.FieldMask{
-		Paths: []string{"tree_state", "display_name", "description", "storage_settings", "max_root_duration", "private_key"},
+		Paths: []string{"tree_state", "display_name", "description", "storage_settings", "max_root_duration", "private_key", "rate_limits"},
 	}
 
 	successWant := proto.Clone(existingTree).(*trillian.Tree)
@@ -651,6 +970,7 @@ func TestServer_UpdateTree(t *testing.T) {
 	successWant.StorageSettings = successTree.StorageSettings
 	successWant.PrivateKey = nil // redacted on responses
 	successWant.MaxRootDuration = successTree.MaxRootDuration
+	successWant.RateLimits = successTree.RateLimits
 
 	tests := []struct {
 		desc                           string
@@ -743,6 +1063,104 @@ func TestServer_UpdateTree(t *testing.T) {
 	}
 }
 
+func TestServer_UpdateTree_RotateKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(1600000000, 0)
+	nowPB, err := ptypes.TimestampProto(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	existingTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	existingTree.TreeId = 12345
+	// The rotated key is the one of testonly.MapTree.
+	rotatedKey, rotatedPublicKey := testonly.MapTree.PrivateKey, testonly.MapTree.PublicKey
+	rotateMask := &field_mask.FieldMask{Paths: []string{"private_key", "public_key"}}
+
+	rotatedWant := proto.Clone(existingTree).(*trillian.Tree)
+	rotatedWant.PrivateKey = nil // redacted on responses
+	rotatedWant.PublicKey = rotatedPublicKey
+	rotatedWant.RetiredPublicKeys = []*trillian.RetiredPublicKey{{PublicKey: existingTree.PublicKey, RetireTime: nowPB}}
+
+	sameKeyWant := proto.Clone(existingTree).(*trillian.Tree)
+	sameKeyWant.PrivateKey = nil
+
+	tests := []struct {
+		desc     string
+		tree     *trillian.Tree
+		mask     *field_mask.FieldMask
+		want     *trillian.Tree
+		wantCode codes.Code
+	}{
+		{
+			desc: "rotated",
+			tree: &trillian.Tree{TreeId: existingTree.TreeId, PrivateKey: rotatedKey},
+			mask: rotateMask,
+			want: rotatedWant,
+		},
+		{
+			desc: "rotatedWithPublicKey",
+			tree: &trillian.Tree{TreeId: existingTree.TreeId, PrivateKey: rotatedKey, PublicKey: rotatedPublicKey},
+			mask: rotateMask,
+			want: rotatedWant,
+		},
+		{
+			desc: "sameKey",
+			tree: &trillian.Tree{TreeId: existingTree.TreeId, PrivateKey: existingTree.PrivateKey},
+			mask: rotateMask,
+			want: sameKeyWant,
+		},
+		{
+			desc:     "mismatchedPublicKey",
+			tree:     &trillian.Tree{TreeId: existingTree.TreeId, PrivateKey: rotatedKey, PublicKey: existingTree.PublicKey},
+			mask:     rotateMask,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "publicKeyOnly",
+			tree:     &trillian.Tree{TreeId: existingTree.TreeId, PublicKey: rotatedPublicKey},
+			mask:     &field_mask.FieldMask{Paths: []string{"public_key"}},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			wantUpdate := test.wantCode == codes.OK
+			setup := setupAdminServer(ctrl, nil /* keygen */, false /* snapshot */, wantUpdate, false /* commitErr */)
+			setup.server.timeSource = clock.NewFake(now)
+			if test.tree.PrivateKey != nil {
+				snapshotTX := storage.NewMockReadOnlyAdminTX(ctrl)
+				snapshotTX.EXPECT().GetTree(gomock.Any(), existingTree.TreeId).Return(proto.Clone(existingTree), nil)
+				snapshotTX.EXPECT().Commit().Return(nil)
+				snapshotTX.EXPECT().Close().MaxTimes(1).Return(nil)
+				as := setup.as.(*testonly.FakeAdminStorage)
+				as.ReadOnlyTX = append(as.ReadOnlyTX, snapshotTX)
+			}
+			if wantUpdate {
+				setup.tx.EXPECT().UpdateTree(gomock.Any(), existingTree.TreeId, gomock.Any()).DoAndReturn(func(ctx context.Context, treeID int64, updateFn func(*trillian.Tree)) (*trillian.Tree, error) {
+					tree := proto.Clone(existingTree).(*trillian.Tree)
+					updateFn(tree)
+					return tree, nil
+				})
+			}
+
+			tree, err := setup.server.UpdateTree(ctx, &trillian.UpdateTreeRequest{Tree: test.tree, UpdateMask: test.mask})
+			if got := status.Code(err); got != test.wantCode {
+				t.Fatalf("UpdateTree(): %v, want code %v", err, test.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if !proto.Equal(tree, test.want) {
+				t.Errorf("UpdateTree() diff (-got +want):\n%v", cmp.Diff(tree, test.want, cmp.Comparer(proto.Equal)))
+			}
+		})
+	}
+}
+
 func TestServer_DeleteTree(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -960,7 +1378,7 @@ func setupAdminServer(ctrl *gomock.Controller, keygen keys.ProtoGenerator, snaps
 		NewKeyProto:  keygen,
 	}
 
-	s := &Server{registry: registry}
+	s := &Server{registry: registry, timeSource: clock.System}
 
 	return adminTestSetup{registry, as, tx, snapshotTX, s}
 }
@@ -0,0 +1,150 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	serrors "github.com/google/trillian/server/errors"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultMaxDeadLetterLeaves is the number of leaves returned by
+// ListDeadLetterLeaves when the request doesn't specify a limit.
+const defaultMaxDeadLetterLeaves = 100
+
+var deadLetterOpts = trees.NewGetOpts(trees.Admin, trillian.TreeType_LOG)
+
+// ListDeadLetterLeaves implements trillian.TrillianAdminServer.ListDeadLetterLeaves.
+func (s *Server) ListDeadLetterLeaves(ctx context.Context, req *trillian.ListDeadLetterLeavesRequest) (*trillian.ListDeadLetterLeavesResponse, error) {
+	limit := req.GetMaxLeaves()
+	if limit < 0 {
+		return nil, serrors.InvalidArgument("max_leaves", "max_leaves must be non-negative, got %d", limit)
+	}
+	if limit == 0 {
+		limit = defaultMaxDeadLetterLeaves
+	}
+
+	leaves, err := s.listDeadLetterLeaves(ctx, req.GetTreeId(), int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &trillian.ListDeadLetterLeavesResponse{Leaves: make([]*trillian.DeadLetterLeaf, 0, len(leaves))}
+	for _, l := range leaves {
+		ts, err := ptypes.TimestampProto(l.QuarantineTime)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "invalid quarantine time: %v", err)
+		}
+		resp.Leaves = append(resp.Leaves, &trillian.DeadLetterLeaf{
+			Leaf:                l.Leaf,
+			QuarantineTimestamp: ts,
+			Reason:              l.Reason,
+		})
+	}
+	return resp, nil
+}
+
+// RequeueDeadLetterLeaves implements trillian.TrillianAdminServer.RequeueDeadLetterLeaves.
+func (s *Server) RequeueDeadLetterLeaves(ctx context.Context, req *trillian.RequeueDeadLetterLeavesRequest) (*trillian.RequeueDeadLetterLeavesResponse, error) {
+	if len(req.GetLeafIdentityHash()) == 0 {
+		return nil, serrors.InvalidArgument("leaf_identity_hash", "at least one leaf_identity_hash is required")
+	}
+	var requeued int
+	err := s.deadLetterTX(ctx, req.GetTreeId(), func(ctx context.Context, tx storage.DeadLetterTX) error {
+		var err error
+		requeued, err = tx.RequeueDeadLetterLeaves(ctx, req.GetLeafIdentityHash(), s.timeSource.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.RequeueDeadLetterLeavesResponse{Requeued: int64(requeued)}, nil
+}
+
+// PurgeDeadLetterLeaves implements trillian.TrillianAdminServer.PurgeDeadLetterLeaves.
+func (s *Server) PurgeDeadLetterLeaves(ctx context.Context, req *trillian.PurgeDeadLetterLeavesRequest) (*trillian.PurgeDeadLetterLeavesResponse, error) {
+	if len(req.GetLeafIdentityHash()) == 0 {
+		return nil, serrors.InvalidArgument("leaf_identity_hash", "at least one leaf_identity_hash is required")
+	}
+	var purged int
+	err := s.deadLetterTX(ctx, req.GetTreeId(), func(ctx context.Context, tx storage.DeadLetterTX) error {
+		var err error
+		purged, err = tx.PurgeDeadLetterLeaves(ctx, req.GetLeafIdentityHash())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.PurgeDeadLetterLeavesResponse{Purged: int64(purged)}, nil
+}
+
+// listDeadLetterLeaves returns at most limit quarantined leaves of the given
+// LOG tree, read in a snapshot so that listing doesn't lock the queue.
+func (s *Server) listDeadLetterLeaves(ctx context.Context, treeID int64, limit int) ([]*storage.DeadLetterLeaf, error) {
+	tree, err := s.deadLetterTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.registry.LogStorage.SnapshotForTree(ctx, tree)
+	if tx != nil {
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	dl, ok := tx.(storage.DeadLetterReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "log storage does not support dead-letter leaves")
+	}
+	leaves, err := dl.ListDeadLetterLeaves(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// deadLetterTX runs f in a read-write transaction on the given LOG tree,
+// provided that the log storage supports dead-letter operations.
+func (s *Server) deadLetterTX(ctx context.Context, treeID int64, f func(context.Context, storage.DeadLetterTX) error) error {
+	tree, err := s.deadLetterTree(ctx, treeID)
+	if err != nil {
+		return err
+	}
+	return s.registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dl, ok := tx.(storage.DeadLetterTX)
+		if !ok {
+			return status.Error(codes.Unimplemented, "log storage does not support dead-letter leaves")
+		}
+		return f(ctx, dl)
+	})
+}
+
+// deadLetterTree returns the given LOG tree, provided that log storage is
+// available.
+func (s *Server) deadLetterTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	if s.registry.LogStorage == nil {
+		return nil, status.Error(codes.Unimplemented, "log storage is not available")
+	}
+	return trees.GetTree(ctx, s.registry.AdminStorage, treeID, deadLetterOpts)
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// deadLetterReaderTX is a ReadOnlyLogTreeTX which lists fixed dead-letter
+// leaves.
+type deadLetterReaderTX struct {
+	*storage.MockReadOnlyLogTreeTX
+	leaves []*storage.DeadLetterLeaf
+}
+
+func (d deadLetterReaderTX) ListDeadLetterLeaves(ctx context.Context, limit int) ([]*storage.DeadLetterLeaf, error) {
+	if limit < len(d.leaves) {
+		return d.leaves[:limit], nil
+	}
+	return d.leaves, nil
+}
+
+// requeueTX is a LogTreeTX which records the leaves requeued from the
+// dead-letter store.
+type requeueTX struct {
+	*storage.MockLogTreeTX
+	hashes    [][]byte
+	timestamp time.Time
+}
+
+func (r *requeueTX) QuarantineLeaves(ctx context.Context, leaves []*trillian.LogLeaf, reason string, quarantineTime time.Time) error {
+	return nil
+}
+
+func (r *requeueTX) ListDeadLetterLeaves(ctx context.Context, limit int) ([]*storage.DeadLetterLeaf, error) {
+	return nil, nil
+}
+
+func (r *requeueTX) RequeueDeadLetterLeaves(ctx context.Context, leafIdentityHashes [][]byte, queueTimestamp time.Time) (int, error) {
+	r.hashes, r.timestamp = leafIdentityHashes, queueTimestamp
+	return len(leafIdentityHashes), nil
+}
+
+func (r *requeueTX) PurgeDeadLetterLeaves(ctx context.Context, leafIdentityHashes [][]byte) (int, error) {
+	return 0, nil
+}
+
+func TestServer_ListDeadLetterLeaves(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	quarantined := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	leaves := []*storage.DeadLetterLeaf{
+		{Leaf: &trillian.LogLeaf{LeafValue: []byte("a")}, QuarantineTime: quarantined, Reason: "bad a"},
+		{Leaf: &trillian.LogLeaf{LeafValue: []byte("b")}, QuarantineTime: quarantined, Reason: "bad b"},
+	}
+
+	for _, test := range []struct {
+		desc        string
+		unsupported bool
+		maxLeaves   int64
+		wantLeaves  int
+		wantErr     codes.Code
+	}{
+		{desc: "all", wantLeaves: 2},
+		{desc: "limit", maxLeaves: 1, wantLeaves: 1},
+		{desc: "unsupported", unsupported: true, wantErr: codes.Unimplemented},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			setup := setupAdminServer(ctrl, nil /* keygen */, true /* snapshot */, true /* shouldCommit */, false /* commitErr */)
+			tree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+			setup.snapshotTX.EXPECT().GetTree(gomock.Any(), tree.TreeId).Return(tree, nil)
+
+			logTX := storage.NewMockReadOnlyLogTreeTX(ctrl)
+			logTX.EXPECT().Close().Return(nil)
+			var tx storage.ReadOnlyLogTreeTX = deadLetterReaderTX{MockReadOnlyLogTreeTX: logTX, leaves: leaves}
+			if test.unsupported {
+				tx = logTX
+			} else {
+				logTX.EXPECT().Commit(gomock.Any()).Return(nil)
+			}
+			// Listing must not take a read-write transaction.
+			ls := storage.NewMockLogStorage(ctrl)
+			ls.EXPECT().SnapshotForTree(gomock.Any(), tree).Return(tx, nil)
+			setup.server.registry.LogStorage = ls
+
+			resp, err := setup.server.ListDeadLetterLeaves(context.Background(), &trillian.ListDeadLetterLeavesRequest{TreeId: tree.TreeId, MaxLeaves: test.maxLeaves})
+			if got := status.Code(err); got != test.wantErr {
+				t.Fatalf("ListDeadLetterLeaves() = (_, %v), want code %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := len(resp.Leaves); got != test.wantLeaves {
+				t.Fatalf("ListDeadLetterLeaves() returned %d leaves, want %d", got, test.wantLeaves)
+			}
+			for i, l := range resp.Leaves {
+				if !proto.Equal(l.Leaf, leaves[i].Leaf) || l.Reason != leaves[i].Reason {
+					t.Errorf("ListDeadLetterLeaves() leaf %d = %v, want %v", i, l, leaves[i])
+				}
+				if got := l.QuarantineTimestamp.AsTime(); !got.Equal(quarantined) {
+					t.Errorf("ListDeadLetterLeaves() leaf %d quarantined at %v, want %v", i, got, quarantined)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_RequeueDeadLetterLeaves(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	setup := setupAdminServer(ctrl, nil /* keygen */, true /* snapshot */, true /* shouldCommit */, false /* commitErr */)
+	tree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	setup.snapshotTX.EXPECT().GetTree(gomock.Any(), tree.TreeId).Return(tree, nil)
+
+	tx := &requeueTX{MockLogTreeTX: storage.NewMockLogTreeTX(ctrl)}
+	ls := storage.NewMockLogStorage(ctrl)
+	ls.EXPECT().ReadWriteTransaction(gomock.Any(), tree, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+			return f(ctx, tx)
+		})
+	setup.server.registry.LogStorage = ls
+	now := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	setup.server.timeSource = clock.NewFake(now)
+
+	hashes := [][]byte{[]byte("hash a"), []byte("hash b")}
+	resp, err := setup.server.RequeueDeadLetterLeaves(context.Background(), &trillian.RequeueDeadLetterLeavesRequest{TreeId: tree.TreeId, LeafIdentityHash: hashes})
+	if err != nil {
+		t.Fatalf("RequeueDeadLetterLeaves(): %v", err)
+	}
+	if got, want := resp.Requeued, int64(len(hashes)); got != want {
+		t.Errorf("RequeueDeadLetterLeaves() requeued %d leaves, want %d", got, want)
+	}
+	if got, want := len(tx.hashes), len(hashes); got != want {
+		t.Errorf("RequeueDeadLetterLeaves() passed %d hashes to storage, want %d", got, want)
+	}
+	if !tx.timestamp.Equal(now) {
+		t.Errorf("RequeueDeadLetterLeaves() requeued at %v, want %v", tx.timestamp, now)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/features"
+	serrors "github.com/google/trillian/server/errors"
+)
+
+// SetFeatureFlags implements trillian.TrillianAdminServer.SetFeatureFlags.
+// It overrides the features.Default set of the process.
+func (s *Server) SetFeatureFlags(ctx context.Context, req *trillian.SetFeatureFlagsRequest) (*trillian.SetFeatureFlagsResponse, error) {
+	set := make([]features.Override, 0, len(req.Set))
+	for _, f := range req.Set {
+		set = append(set, features.Override{Name: f.Name, TreeID: f.TreeId, Enabled: f.Enabled})
+	}
+	clear := make([]features.Override, 0, len(req.Clear))
+	for _, f := range req.Clear {
+		clear = append(clear, features.Override{Name: f.Name, TreeID: f.TreeId})
+	}
+	if err := features.Default.Apply(set, clear); err != nil {
+		return nil, serrors.InvalidArgument("set", "%v", err)
+	}
+
+	resp := &trillian.SetFeatureFlagsResponse{Defaults: features.Defaults()}
+	for _, o := range features.Default.Overrides() {
+		resp.Flags = append(resp.Flags, &trillian.FeatureFlag{Name: o.Name, TreeId: o.TreeID, Enabled: o.Enabled})
+	}
+	return resp, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/features"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestServer_SetFeatureFlags(t *testing.T) {
+	defer features.Default.Replace(nil)
+
+	tests := []struct {
+		desc    string
+		req     *trillian.SetFeatureFlagsRequest
+		want    []*trillian.FeatureFlag
+		wantErr codes.Code
+	}{
+		{
+			desc: "set",
+			req: &trillian.SetFeatureFlagsRequest{Set: []*trillian.FeatureFlag{
+				{Name: features.TileEndpoints},
+				{Name: features.TileEndpoints, TreeId: 12, Enabled: true},
+			}},
+			want: []*trillian.FeatureFlag{
+				{Name: features.TileEndpoints},
+				{Name: features.TileEndpoints, TreeId: 12, Enabled: true},
+			},
+		},
+		{
+			desc: "list",
+			req:  &trillian.SetFeatureFlagsRequest{},
+			want: []*trillian.FeatureFlag{
+				{Name: features.TileEndpoints},
+				{Name: features.TileEndpoints, TreeId: 12, Enabled: true},
+			},
+		},
+		{
+			desc: "clear",
+			req: &trillian.SetFeatureFlagsRequest{
+				Set:   []*trillian.FeatureFlag{{Name: features.CompactMapProofs, TreeId: 3}},
+				Clear: []*trillian.FeatureFlag{{Name: features.TileEndpoints}},
+			},
+			want: []*trillian.FeatureFlag{
+				{Name: features.CompactMapProofs, TreeId: 3},
+				{Name: features.TileEndpoints, TreeId: 12, Enabled: true},
+			},
+		},
+		{
+			desc:    "unknown",
+			req:     &trillian.SetFeatureFlagsRequest{Set: []*trillian.FeatureFlag{{Name: "warp_drive", Enabled: true}}},
+			wantErr: codes.InvalidArgument,
+		},
+		{
+			desc:    "badTree",
+			req:     &trillian.SetFeatureFlagsRequest{Clear: []*trillian.FeatureFlag{{Name: features.TileEndpoints, TreeId: -1}}},
+			wantErr: codes.InvalidArgument,
+		},
+	}
+
+	ctx := context.Background()
+	s := New(extension.Registry{}, nil)
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			resp, err := s.SetFeatureFlags(ctx, test.req)
+			if got := status.Code(err); got != test.wantErr {
+				t.Fatalf("SetFeatureFlags(): %v, want code %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(resp.Flags, test.want, protocmp.Transform()); diff != "" {
+				t.Errorf("SetFeatureFlags() diff (-got +want):\n%s", diff)
+			}
+			if !resp.Defaults[features.TileEndpoints] {
+				t.Errorf("SetFeatureFlags(): defaults %v, want tile_endpoints enabled", resp.Defaults)
+			}
+		})
+	}
+	if !features.Enabled(features.TileEndpoints, 1) || features.Enabled(features.CompactMapProofs, 3) {
+		t.Error("SetFeatureFlags() didn't override the default feature set")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	serrors "github.com/google/trillian/server/errors"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetMaintenanceMode implements trillian.TrillianAdminServer.SetMaintenanceMode.
+func (s *Server) SetMaintenanceMode(ctx context.Context, req *trillian.SetMaintenanceModeRequest) (*trillian.SetMaintenanceModeResponse, error) {
+	if req.Enabled && req.Reason == "" {
+		return nil, serrors.InvalidArgument("reason", "a reason is required to enable maintenance")
+	}
+
+	resp := &trillian.SetMaintenanceModeResponse{}
+	if len(req.TreeIds) == 0 {
+		mode := s.registry.Maintenance
+		if mode == nil {
+			return nil, status.Error(codes.Unimplemented, "server does not support maintenance mode")
+		}
+		if req.Enabled {
+			mode.Set(req.Reason)
+		} else {
+			mode.Clear()
+		}
+		resp.ServerMaintenance = mode.Get()
+		return resp, nil
+	}
+
+	// Trees keep the start time of maintenance they're already in, so that
+	// repeating a request doesn't change it.
+	now := ptypes.TimestampNow()
+	for _, treeID := range req.TreeIds {
+		tree, err := storage.UpdateTree(ctx, s.registry.AdminStorage, treeID, func(tree *trillian.Tree) {
+			switch {
+			case !req.Enabled:
+				tree.Maintenance = nil
+			case tree.Maintenance == nil:
+				tree.Maintenance = &trillian.TreeMaintenance{Reason: req.Reason, StartTime: now}
+			default:
+				tree.Maintenance.Reason = req.Reason
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp.Trees = append(resp.Trees, redact(tree))
+	}
+	resp.ServerMaintenance = s.registry.Maintenance.Get()
+	return resp, nil
+}
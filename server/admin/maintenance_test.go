@@ -0,0 +1,150 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/server/maintenance"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_SetMaintenanceMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	logTree.TreeId = 1
+	maintainedTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	maintainedTree.TreeId = 2
+	started := ptypes.TimestampNow()
+	started.Seconds -= 3600
+	maintainedTree.Maintenance = &trillian.TreeMaintenance{Reason: "failover", StartTime: started}
+
+	tests := []struct {
+		desc       string
+		mode       *maintenance.Mode
+		req        *trillian.SetMaintenanceModeRequest
+		wantErr    codes.Code
+		wantTrees  []*trillian.TreeMaintenance
+		wantServer string
+		// keepStart is whether the start time of the maintenance of the
+		// tree is kept.
+		keepStart bool
+	}{
+		{
+			desc:      "enableTree",
+			mode:      &maintenance.Mode{},
+			req:       &trillian.SetMaintenanceModeRequest{TreeIds: []int64{1}, Enabled: true, Reason: "migration"},
+			wantTrees: []*trillian.TreeMaintenance{{Reason: "migration"}},
+		},
+		{
+			desc:      "changeReason",
+			mode:      &maintenance.Mode{},
+			req:       &trillian.SetMaintenanceModeRequest{TreeIds: []int64{2}, Enabled: true, Reason: "migration"},
+			wantTrees: []*trillian.TreeMaintenance{{Reason: "migration"}},
+			keepStart: true,
+		},
+		{
+			desc:      "disableTree",
+			mode:      &maintenance.Mode{},
+			req:       &trillian.SetMaintenanceModeRequest{TreeIds: []int64{2}},
+			wantTrees: []*trillian.TreeMaintenance{nil},
+		},
+		{
+			desc:       "enableServer",
+			mode:       &maintenance.Mode{},
+			req:        &trillian.SetMaintenanceModeRequest{Enabled: true, Reason: "migration"},
+			wantServer: "migration",
+		},
+		{
+			desc: "disableServer",
+			mode: func() *maintenance.Mode {
+				m := &maintenance.Mode{}
+				m.Set("migration")
+				return m
+			}(),
+			req: &trillian.SetMaintenanceModeRequest{},
+		},
+		{
+			desc:    "noReason",
+			mode:    &maintenance.Mode{},
+			req:     &trillian.SetMaintenanceModeRequest{TreeIds: []int64{1}, Enabled: true},
+			wantErr: codes.InvalidArgument,
+		},
+		{
+			desc:    "noServerMode",
+			req:     &trillian.SetMaintenanceModeRequest{Enabled: true, Reason: "migration"},
+			wantErr: codes.Unimplemented,
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			as := storage.NewMockAdminStorage(ctrl)
+			tx := storage.NewMockAdminTX(ctrl)
+			as.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, f storage.AdminTXFunc) error {
+				return f(ctx, tx)
+			})
+			for _, tree := range []*trillian.Tree{logTree, maintainedTree} {
+				tree := proto.Clone(tree).(*trillian.Tree)
+				tx.EXPECT().UpdateTree(gomock.Any(), tree.TreeId, gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, treeID int64, f func(*trillian.Tree)) (*trillian.Tree, error) {
+					f(tree)
+					return tree, nil
+				})
+			}
+
+			s := New(extension.Registry{AdminStorage: as, Maintenance: test.mode}, nil)
+			resp, err := s.SetMaintenanceMode(ctx, test.req)
+			if got := status.Code(err); got != test.wantErr {
+				t.Fatalf("SetMaintenanceMode(): %v, want code %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := len(resp.Trees), len(test.wantTrees); got != want {
+				t.Fatalf("SetMaintenanceMode(): %d trees, want %d", got, want)
+			}
+			for i, want := range test.wantTrees {
+				got := resp.Trees[i].Maintenance
+				if (got == nil) != (want == nil) || got.GetReason() != want.GetReason() {
+					t.Errorf("SetMaintenanceMode(): trees[%d].maintenance %v, want %v", i, got, want)
+				}
+				if want == nil {
+					continue
+				}
+				if kept := proto.Equal(got.StartTime, started); kept != test.keepStart {
+					t.Errorf("SetMaintenanceMode(): trees[%d] start time %v, kept %v, want %v", i, got.StartTime, kept, test.keepStart)
+				}
+			}
+			if got := resp.ServerMaintenance.GetReason(); got != test.wantServer {
+				t.Errorf("SetMaintenanceMode(): server maintenance reason %q, want %q", got, test.wantServer)
+			}
+			if got := test.mode.Get().GetReason(); got != test.wantServer {
+				t.Errorf("Mode.Get(): reason %q, want %q", got, test.wantServer)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/google/trillian"
+	serrors "github.com/google/trillian/server/errors"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/util/election2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var moveMastershipOpts = trees.NewGetOpts(trees.Admin, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
+
+// MoveMastership implements trillian.TrillianAdminServer.MoveMastership.
+func (s *Server) MoveMastership(ctx context.Context, req *trillian.MoveMastershipRequest) (*trillian.MoveMastershipResponse, error) {
+	placer, ok := s.registry.ElectionFactory.(election2.Placer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "election system does not support moving mastership")
+	}
+	if len(req.GetTreeIds()) == 0 {
+		return nil, serrors.InvalidArgument("tree_ids", "at least one tree ID is required")
+	}
+	// Check all the trees before moving any of them.
+	for _, treeID := range req.TreeIds {
+		if _, err := trees.GetTree(ctx, s.registry.AdminStorage, treeID, moveMastershipOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &trillian.MoveMastershipResponse{PreviousInstanceIds: make(map[int64]string)}
+	for _, treeID := range req.TreeIds {
+		resourceID := strconv.FormatInt(treeID, 10)
+		prev, err := placer.Placement(ctx, resourceID)
+		if err == election2.ErrPlacementUnsupported {
+			return nil, status.Error(codes.Unimplemented, "election system does not support moving mastership")
+		} else if err != nil {
+			return nil, status.Errorf(codes.Unavailable, "failed to read placement of tree %v: %v", treeID, err)
+		}
+		if err := placer.Place(ctx, resourceID, req.InstanceId); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "failed to place tree %v: %v", treeID, err)
+		}
+		if prev != "" {
+			resp.PreviousInstanceIds[treeID] = prev
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/util/election2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakePlacer is an election2.Factory which keeps placement hints in a map.
+type fakePlacer struct {
+	election2.NoopFactory
+	placements map[string]string
+}
+
+func (p *fakePlacer) Place(ctx context.Context, resourceID, instanceID string) error {
+	if instanceID == "" {
+		delete(p.placements, resourceID)
+	} else {
+		p.placements[resourceID] = instanceID
+	}
+	return nil
+}
+
+func (p *fakePlacer) Placement(ctx context.Context, resourceID string) (string, error) {
+	return p.placements[resourceID], nil
+}
+
+func TestServer_MoveMastership(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	logTree.TreeId = 1
+	mapTree := proto.Clone(testonly.MapTree).(*trillian.Tree)
+	mapTree.TreeId = 3
+	tx := storage.NewMockReadOnlyAdminTX(ctrl)
+	tx.EXPECT().GetTree(gomock.Any(), int64(1)).AnyTimes().Return(logTree, nil)
+	tx.EXPECT().GetTree(gomock.Any(), int64(2)).AnyTimes().Return(nil, status.Error(codes.NotFound, "no tree 2"))
+	tx.EXPECT().GetTree(gomock.Any(), int64(3)).AnyTimes().Return(mapTree, nil)
+	tx.EXPECT().Commit().AnyTimes().Return(nil)
+	tx.EXPECT().Close().AnyTimes().Return(nil)
+	as := storage.NewMockAdminStorage(ctrl)
+	as.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(tx, nil)
+
+	placer := &fakePlacer{placements: map[string]string{"1": "signer-a"}}
+	tests := []struct {
+		desc    string
+		factory election2.Factory
+		req     *trillian.MoveMastershipRequest
+		want    map[int64]string
+		wantErr codes.Code
+		// placements are the placement hints after the request.
+		placements map[string]string
+	}{
+		{
+			desc:       "move",
+			factory:    placer,
+			req:        &trillian.MoveMastershipRequest{TreeIds: []int64{1}, InstanceId: "signer-b"},
+			want:       map[int64]string{1: "signer-a"},
+			placements: map[string]string{"1": "signer-b"},
+		},
+		{
+			desc:       "monitored",
+			factory:    election2.NewMonitoredFactory(placer, nil, nil, nil),
+			req:        &trillian.MoveMastershipRequest{TreeIds: []int64{1}, InstanceId: "signer-c"},
+			want:       map[int64]string{1: "signer-b"},
+			placements: map[string]string{"1": "signer-c"},
+		},
+		{
+			desc:       "clear",
+			factory:    placer,
+			req:        &trillian.MoveMastershipRequest{TreeIds: []int64{1}},
+			want:       map[int64]string{1: "signer-c"},
+			placements: map[string]string{},
+		},
+		{
+			desc:       "unknownTree",
+			factory:    placer,
+			req:        &trillian.MoveMastershipRequest{TreeIds: []int64{1, 2}, InstanceId: "signer-a"},
+			wantErr:    codes.NotFound,
+			placements: map[string]string{},
+		},
+		{
+			desc:       "mapTree",
+			factory:    placer,
+			req:        &trillian.MoveMastershipRequest{TreeIds: []int64{3}, InstanceId: "signer-a"},
+			wantErr:    codes.InvalidArgument,
+			placements: map[string]string{},
+		},
+		{
+			desc:       "noTrees",
+			factory:    placer,
+			req:        &trillian.MoveMastershipRequest{InstanceId: "signer-a"},
+			wantErr:    codes.InvalidArgument,
+			placements: map[string]string{},
+		},
+		{
+			desc:       "noPlacer",
+			factory:    election2.NoopFactory{},
+			req:        &trillian.MoveMastershipRequest{TreeIds: []int64{1}, InstanceId: "signer-a"},
+			wantErr:    codes.Unimplemented,
+			placements: map[string]string{},
+		},
+		{
+			desc:       "monitoredNoPlacer",
+			factory:    election2.NewMonitoredFactory(election2.NoopFactory{}, nil, nil, nil),
+			req:        &trillian.MoveMastershipRequest{TreeIds: []int64{1}, InstanceId: "signer-a"},
+			wantErr:    codes.Unimplemented,
+			placements: map[string]string{},
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			s := New(extension.Registry{AdminStorage: as, ElectionFactory: test.factory}, nil)
+			resp, err := s.MoveMastership(ctx, test.req)
+			if got := status.Code(err); got != test.wantErr {
+				t.Fatalf("MoveMastership(): %v, want code %v", err, test.wantErr)
+			}
+			if err == nil {
+				if diff := cmp.Diff(resp.PreviousInstanceIds, test.want); diff != "" {
+					t.Errorf("MoveMastership() diff (-got +want):\n%s", diff)
+				}
+			}
+			if diff := cmp.Diff(placer.placements, test.placements); diff != "" {
+				t.Errorf("MoveMastership() placements diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
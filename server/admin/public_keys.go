@@ -0,0 +1,204 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keys/jwk"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PublicKeysPath is the path under which PublicKeysHandler is usually served.
+const PublicKeysPath = "/keys"
+
+// PublicKeysHandler serves the public keys of trees as JSON Web Key Sets
+// (RFC 7517), so that verifiers can fetch them rather than having them
+// distributed out of band. GET requests for PublicKeysPath list the keys of all
+// trees which haven't been deleted, and requests for PublicKeysPath/<tree_id>
+// list the keys of a single tree. Keys are identified by their RFC 7638
+// thumbprints, and carry the ID of their tree in a "trillian_tree_id" member.
+//
+// The current key of each tree is listed first, followed by the keys it has
+// been rotated from, most recently retired first. Retired keys carry the time
+// they were retired in a "trillian_retire_time" member, and remain listed so
+// that roots signed before the rotation can still be verified.
+type PublicKeysHandler struct {
+	as               storage.AdminStorage
+	allowedTreeTypes []trillian.TreeType
+	path             string
+}
+
+// NewPublicKeysHandler returns a PublicKeysHandler listing the keys of trees
+// in the given storage, served under path, which is usually PublicKeysPath.
+// Only trees of the allowed types are listed; nil allows all types.
+func NewPublicKeysHandler(as storage.AdminStorage, allowedTreeTypes []trillian.TreeType, path string) *PublicKeysHandler {
+	return &PublicKeysHandler{as: as, allowedTreeTypes: allowedTreeTypes, path: strings.TrimRight(path, "/")}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *PublicKeysHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var (
+		set *jwk.Set
+		err error
+	)
+	switch rest := strings.TrimPrefix(r.URL.Path, h.path); rest {
+	case "", "/":
+		set, err = h.allKeys(r.Context())
+	default:
+		treeID, perr := strconv.ParseInt(strings.TrimPrefix(rest, "/"), 10, 64)
+		if perr != nil || treeID <= 0 {
+			http.Error(w, fmt.Sprintf("invalid tree ID %q", strings.TrimPrefix(rest, "/")), http.StatusBadRequest)
+			return
+		}
+		set, err = h.treeKeys(r.Context(), treeID)
+	}
+	if err != nil {
+		if err == errTreeNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		glog.Warningf("Failed to list public keys for %v: %v", r.URL.Path, err)
+		http.Error(w, "failed to list public keys", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	// New trees may be created and keys rotated at any time.
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(set); err != nil {
+		glog.Warningf("Failed to write public keys: %v", err)
+	}
+}
+
+var errTreeNotFound = errors.New("tree not found")
+
+func (h *PublicKeysHandler) allKeys(ctx context.Context) (*jwk.Set, error) {
+	trees, err := storage.ListTrees(ctx, h.as, false /* includeDeleted */)
+	if err != nil {
+		return nil, err
+	}
+	set := &jwk.Set{Keys: []*jwk.Key{}}
+	for _, tree := range trees {
+		if !h.allowed(tree) {
+			continue
+		}
+		keys, err := treeKeys(tree)
+		if err != nil {
+			glog.Warningf("%v: not publishing public keys: %v", tree.TreeId, err)
+			continue
+		}
+		set.Keys = append(set.Keys, keys...)
+	}
+	return set, nil
+}
+
+func (h *PublicKeysHandler) treeKeys(ctx context.Context, treeID int64) (*jwk.Set, error) {
+	tree, err := storage.GetTree(ctx, h.as, treeID)
+	if status.Code(err) == codes.NotFound {
+		return nil, errTreeNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if tree.Deleted || !h.allowed(tree) {
+		return nil, errTreeNotFound
+	}
+	keys, err := treeKeys(tree)
+	if err != nil {
+		return nil, err
+	}
+	return &jwk.Set{Keys: keys}, nil
+}
+
+func (h *PublicKeysHandler) allowed(tree *trillian.Tree) bool {
+	if h.allowedTreeTypes == nil {
+		return true
+	}
+	for _, t := range h.allowedTreeTypes {
+		if t == tree.TreeType {
+			return true
+		}
+	}
+	return false
+}
+
+// treeKeys returns the JSON Web Keys for the current and retired public keys
+// of the tree. A retired key which is also the current key, or which was
+// retired again later, is only listed once.
+func treeKeys(tree *trillian.Tree) ([]*jwk.Key, error) {
+	if tree.PublicKey == nil {
+		return nil, fmt.Errorf("tree has no public key")
+	}
+	k, err := treeKey(tree, tree.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	keys := []*jwk.Key{k}
+	seen := map[string]bool{k.KeyID: true}
+	for i := len(tree.RetiredPublicKeys) - 1; i >= 0; i-- {
+		retired := tree.RetiredPublicKeys[i]
+		k, err := treeKey(tree, retired.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("retired key %d: %v", i, err)
+		}
+		if seen[k.KeyID] {
+			continue
+		}
+		seen[k.KeyID] = true
+		if retired.RetireTime != nil {
+			retireTime, err := ptypes.Timestamp(retired.RetireTime)
+			if err != nil {
+				return nil, fmt.Errorf("retired key %d: %v", i, err)
+			}
+			k.RetireTime = retireTime.UTC().Format(time.RFC3339)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// treeKey returns the JSON Web Key for a public key of the tree.
+func treeKey(tree *trillian.Tree, publicKey *keyspb.PublicKey) (*jwk.Key, error) {
+	pub, err := der.FromPublicProto(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	k, err := jwk.FromPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	k.TreeID = strconv.FormatInt(tree.TreeId, 10)
+	return k, nil
+}
@@ -0,0 +1,203 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/jwk"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPublicKeysHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	logTree.TreeId = 10
+	mapTree := proto.Clone(testonly.MapTree).(*trillian.Tree)
+	mapTree.TreeId = 11
+	deletedTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	deletedTree.TreeId = 12
+	deletedTree.Deleted = true
+	logID, mapID, deletedID := logTree.TreeId, mapTree.TreeId, deletedTree.TreeId
+	trees := map[int64]*trillian.Tree{logID: logTree, mapID: mapTree, deletedID: deletedTree}
+
+	tx := storage.NewMockReadOnlyAdminTX(ctrl)
+	tx.EXPECT().ListTrees(gomock.Any(), false).Return([]*trillian.Tree{logTree, mapTree}, nil).AnyTimes()
+	tx.EXPECT().GetTree(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, treeID int64) (*trillian.Tree, error) {
+		if tree, ok := trees[treeID]; ok {
+			return tree, nil
+		}
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", treeID)
+	}).AnyTimes()
+	tx.EXPECT().Commit().AnyTimes()
+	tx.EXPECT().Close().AnyTimes()
+	as := storage.NewMockAdminStorage(ctrl)
+	as.EXPECT().Snapshot(gomock.Any()).Return(tx, nil).AnyTimes()
+
+	for _, test := range []struct {
+		desc        string
+		method      string
+		url         string
+		allowed     []trillian.TreeType
+		wantStatus  int
+		wantTreeIDs []int64
+	}{
+		{desc: "all", url: "/keys", wantStatus: http.StatusOK, wantTreeIDs: []int64{logID, mapID}},
+		{desc: "all-slash", url: "/keys/", wantStatus: http.StatusOK, wantTreeIDs: []int64{logID, mapID}},
+		{desc: "all-head", method: http.MethodHead, url: "/keys", wantStatus: http.StatusOK},
+		{
+			desc:        "all-allowed",
+			url:         "/keys",
+			allowed:     []trillian.TreeType{trillian.TreeType_LOG},
+			wantStatus:  http.StatusOK,
+			wantTreeIDs: []int64{logID},
+		},
+		{desc: "tree", url: fmt.Sprintf("/keys/%d", mapID), wantStatus: http.StatusOK, wantTreeIDs: []int64{mapID}},
+		{
+			desc:       "tree-not-allowed",
+			url:        fmt.Sprintf("/keys/%d", mapID),
+			allowed:    []trillian.TreeType{trillian.TreeType_LOG},
+			wantStatus: http.StatusNotFound,
+		},
+		{desc: "deleted-tree", url: fmt.Sprintf("/keys/%d", deletedID), wantStatus: http.StatusNotFound},
+		{desc: "unknown-tree", url: "/keys/12345", wantStatus: http.StatusNotFound},
+		{desc: "bad-tree-id", url: "/keys/foo", wantStatus: http.StatusBadRequest},
+		{desc: "negative-tree-id", url: "/keys/-1", wantStatus: http.StatusBadRequest},
+		{desc: "post", method: http.MethodPost, url: "/keys", wantStatus: http.StatusMethodNotAllowed},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			h := NewPublicKeysHandler(as, test.allowed, PublicKeysPath)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(method, test.url, nil))
+			if got, want := w.Code, test.wantStatus; got != want {
+				t.Fatalf("ServeHTTP(%v %v): status=%v, want %v (body %q)", method, test.url, got, want, w.Body.String())
+			}
+			if w.Code != http.StatusOK || method == http.MethodHead {
+				return
+			}
+			if got, want := w.Header().Get("Content-Type"), "application/jwk-set+json"; got != want {
+				t.Errorf("Content-Type=%q, want %q", got, want)
+			}
+
+			var set jwk.Set
+			if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+				t.Fatalf("Unmarshal(): %v", err)
+			}
+			var gotIDs []string
+			for _, k := range set.Keys {
+				gotIDs = append(gotIDs, k.TreeID)
+				if k.KeyType != "EC" || k.Algorithm != "ES256" || k.Use != "sig" || k.KeyID == "" {
+					t.Errorf("key for tree %v = %+v, want a P-256 signing key", k.TreeID, k)
+				}
+			}
+			var wantIDs []string
+			for _, id := range test.wantTreeIDs {
+				wantIDs = append(wantIDs, fmt.Sprint(id))
+			}
+			sort.Strings(gotIDs)
+			sort.Strings(wantIDs)
+			if diff := cmp.Diff(gotIDs, wantIDs); diff != "" {
+				t.Errorf("tree IDs diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTreeKeys(t *testing.T) {
+	logKey, mapKey := testonly.LogTree.PublicKey, testonly.MapTree.PublicKey
+	retired := func(pub *keyspb.PublicKey, sec int64) *trillian.RetiredPublicKey {
+		return &trillian.RetiredPublicKey{PublicKey: pub, RetireTime: &timestamp.Timestamp{Seconds: sec}}
+	}
+	thumbprint := func(pub *keyspb.PublicKey) string {
+		k, err := treeKey(&trillian.Tree{}, pub)
+		if err != nil {
+			t.Fatalf("treeKey(): %v", err)
+		}
+		return k.KeyID
+	}
+	type key struct{ KeyID, RetireTime string }
+
+	for _, test := range []struct {
+		desc    string
+		tree    *trillian.Tree
+		want    []key
+		wantErr bool
+	}{
+		{
+			desc: "current",
+			tree: &trillian.Tree{PublicKey: logKey},
+			want: []key{{KeyID: thumbprint(logKey)}},
+		},
+		{
+			desc: "rotated",
+			tree: &trillian.Tree{PublicKey: mapKey, RetiredPublicKeys: []*trillian.RetiredPublicKey{retired(logKey, 1600000000)}},
+			want: []key{{KeyID: thumbprint(mapKey)}, {KeyID: thumbprint(logKey), RetireTime: "2020-09-13T12:26:40Z"}},
+		},
+		{
+			desc: "rotatedBack",
+			tree: &trillian.Tree{PublicKey: mapKey, RetiredPublicKeys: []*trillian.RetiredPublicKey{
+				retired(logKey, 1600000000),
+				retired(mapKey, 1600000001),
+				retired(logKey, 1600000002),
+			}},
+			want: []key{{KeyID: thumbprint(mapKey)}, {KeyID: thumbprint(logKey), RetireTime: "2020-09-13T12:26:42Z"}},
+		},
+		{
+			desc:    "noPublicKey",
+			tree:    &trillian.Tree{},
+			wantErr: true,
+		},
+		{
+			desc:    "badRetiredKey",
+			tree:    &trillian.Tree{PublicKey: logKey, RetiredPublicKeys: []*trillian.RetiredPublicKey{retired(&keyspb.PublicKey{Der: []byte("foo")}, 1)}},
+			wantErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			keys, err := treeKeys(test.tree)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("treeKeys(): %v, wantErr %v", err, test.wantErr)
+			}
+			var got []key
+			for _, k := range keys {
+				got = append(got, key{KeyID: k.KeyID, RetireTime: k.RetireTime})
+			}
+			if diff := cmp.Diff(got, test.want); diff != "" {
+				t.Errorf("treeKeys() diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/trees"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var quotaStateOpts = trees.NewGetOpts(trees.Admin)
+
+// GetQuotaState implements trillian.TrillianAdminServer.GetQuotaState.
+func (s *Server) GetQuotaState(ctx context.Context, req *trillian.GetQuotaStateRequest) (*trillian.GetQuotaStateResponse, error) {
+	peeker, ok := s.registry.QuotaManager.(quota.TokenPeeker)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "quota manager does not support reporting quota state")
+	}
+
+	specs := []quota.Spec{
+		{Group: quota.Global, Kind: quota.Read},
+		{Group: quota.Global, Kind: quota.Write},
+	}
+	if treeID := req.GetTreeId(); treeID != 0 {
+		if _, err := trees.GetTree(ctx, s.registry.AdminStorage, treeID, quotaStateOpts); err != nil {
+			return nil, err
+		}
+		specs = append(specs,
+			quota.Spec{Group: quota.Tree, Kind: quota.Read, TreeID: treeID},
+			quota.Spec{Group: quota.Tree, Kind: quota.Write, TreeID: treeID})
+	}
+	for _, user := range req.GetUser() {
+		specs = append(specs,
+			quota.Spec{Group: quota.User, Kind: quota.Read, User: user},
+			quota.Spec{Group: quota.User, Kind: quota.Write, User: user})
+	}
+
+	tokens, err := peeker.PeekTokens(ctx, specs)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to read quota state: %v", err)
+	}
+	quota.Metrics.SetAvailable(tokens)
+
+	resp := &trillian.GetQuotaStateResponse{Quotas: make([]*trillian.QuotaState, 0, len(specs))}
+	for _, spec := range specs {
+		state := &trillian.QuotaState{Name: spec.Name()}
+		if n := tokens[spec]; n == quota.MaxTokens {
+			state.Unlimited = true
+		} else {
+			state.AvailableTokens = int64(n)
+		}
+		resp.Quotas = append(resp.Quotas, state)
+	}
+	return resp, nil
+}
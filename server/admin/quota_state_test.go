@@ -0,0 +1,156 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakePeeker is a quota.Manager which reports fixed token levels, and
+// unlimited tokens for unknown specs.
+type fakePeeker struct {
+	quota.Manager
+	tokens map[string]int
+	err    error
+}
+
+func (f fakePeeker) PeekTokens(ctx context.Context, specs []quota.Spec) (map[quota.Spec]int, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	tokens := make(map[quota.Spec]int)
+	for _, spec := range specs {
+		n, ok := f.tokens[spec.Name()]
+		if !ok {
+			n = quota.MaxTokens
+		}
+		tokens[spec] = n
+	}
+	return tokens, nil
+}
+
+func TestServer_GetQuotaState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const treeID = 12345
+	qm := fakePeeker{tokens: map[string]int{
+		"global/write":      100,
+		"trees/12345/write": 10,
+		"users/alice/read":  0,
+	}}
+
+	tests := []struct {
+		desc    string
+		qm      quota.Manager
+		req     *trillian.GetQuotaStateRequest
+		getTree bool
+		getErr  bool
+		want    []*trillian.QuotaState
+		wantErr codes.Code
+	}{
+		{
+			desc: "global",
+			qm:   qm,
+			req:  &trillian.GetQuotaStateRequest{},
+			want: []*trillian.QuotaState{
+				{Name: "global/read", Unlimited: true},
+				{Name: "global/write", AvailableTokens: 100},
+			},
+		},
+		{
+			desc:    "treeAndUsers",
+			qm:      qm,
+			req:     &trillian.GetQuotaStateRequest{TreeId: treeID, User: []string{"alice"}},
+			getTree: true,
+			want: []*trillian.QuotaState{
+				{Name: "global/read", Unlimited: true},
+				{Name: "global/write", AvailableTokens: 100},
+				{Name: "trees/12345/read", Unlimited: true},
+				{Name: "trees/12345/write", AvailableTokens: 10},
+				{Name: "users/alice/read", AvailableTokens: 0},
+				{Name: "users/alice/write", Unlimited: true},
+			},
+		},
+		{
+			desc: "noop",
+			qm:   quota.Noop(),
+			req:  &trillian.GetQuotaStateRequest{},
+			want: []*trillian.QuotaState{
+				{Name: "global/read", Unlimited: true},
+				{Name: "global/write", Unlimited: true},
+			},
+		},
+		{
+			desc:    "unknownTree",
+			qm:      qm,
+			req:     &trillian.GetQuotaStateRequest{TreeId: treeID},
+			getTree: true,
+			getErr:  true,
+			wantErr: codes.Unknown,
+		},
+		{
+			desc:    "peekError",
+			qm:      fakePeeker{err: errors.New("etcd unavailable")},
+			req:     &trillian.GetQuotaStateRequest{},
+			wantErr: codes.Unavailable,
+		},
+		{
+			desc:    "cannotPeek",
+			qm:      quota.NewMockManager(ctrl),
+			req:     &trillian.GetQuotaStateRequest{},
+			wantErr: codes.Unimplemented,
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			setup := setupAdminServer(ctrl, nil /* keygen */, true /* snapshot */, test.getTree && !test.getErr /* shouldCommit */, false /* commitErr */)
+			setup.server.registry.QuotaManager = test.qm
+			if test.getTree {
+				tree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+				tree.TreeId = treeID
+				if test.getErr {
+					setup.snapshotTX.EXPECT().GetTree(gomock.Any(), int64(treeID)).Return(nil, errors.New("GetTree failed"))
+				} else {
+					setup.snapshotTX.EXPECT().GetTree(gomock.Any(), int64(treeID)).Return(tree, nil)
+				}
+			}
+
+			resp, err := setup.server.GetQuotaState(ctx, test.req)
+			if got := status.Code(err); got != test.wantErr {
+				t.Fatalf("GetQuotaState() = (_, %v), want code %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(resp.Quotas, test.want, cmp.Comparer(proto.Equal)); diff != "" {
+				t.Errorf("GetQuotaState() diff (-got +want):\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	serrors "github.com/google/trillian/server/errors"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// treeField is a field of trees reconciled by ApplyTreeSpec.
+type treeField struct {
+	name string
+	// readonly is whether the field can't be changed once a tree is created.
+	readonly bool
+	// keepUnset is whether the field of a tree is left unchanged if it's
+	// unset in a spec, rather than cleared.
+	keepUnset bool
+	// secret is whether values of the field are never returned.
+	secret bool
+	// value returns the value of the field of a tree in text format, or ""
+	// if it's unset.
+	value func(*trillian.Tree) string
+	// copy copies the field from one tree to another.
+	copy func(from, to *trillian.Tree)
+}
+
+// treeFields are the fields reconciled by ApplyTreeSpec, in order of their
+// numbers. Fields assigned by storage or by key rotation aren't reconciled.
+var treeFields = []treeField{
+	{
+		name:      "tree_state",
+		keepUnset: true,
+		value:     func(t *trillian.Tree) string { return enumValue(t.TreeState) },
+		copy:      func(from, to *trillian.Tree) { to.TreeState = from.TreeState },
+	},
+	{
+		// The tree type may only change from PREORDERED_LOG to LOG, which
+		// storage checks.
+		name:      "tree_type",
+		keepUnset: true,
+		value:     func(t *trillian.Tree) string { return enumValue(t.TreeType) },
+		copy:      func(from, to *trillian.Tree) { to.TreeType = from.TreeType },
+	},
+	{
+		name:      "hash_strategy",
+		readonly:  true,
+		keepUnset: true,
+		value:     func(t *trillian.Tree) string { return enumValue(t.HashStrategy) },
+	},
+	{
+		name:      "hash_algorithm",
+		readonly:  true,
+		keepUnset: true,
+		value:     func(t *trillian.Tree) string { return enumValue(t.HashAlgorithm) },
+	},
+	{
+		name:      "signature_algorithm",
+		readonly:  true,
+		keepUnset: true,
+		value:     func(t *trillian.Tree) string { return enumValue(t.SignatureAlgorithm) },
+	},
+	{
+		name:  "display_name",
+		value: func(t *trillian.Tree) string { return t.DisplayName },
+		copy:  func(from, to *trillian.Tree) { to.DisplayName = from.DisplayName },
+	},
+	{
+		name:  "description",
+		value: func(t *trillian.Tree) string { return t.Description },
+		copy:  func(from, to *trillian.Tree) { to.Description = from.Description },
+	},
+	{
+		name:      "private_key",
+		keepUnset: true,
+		secret:    true,
+		value:     func(t *trillian.Tree) string { return messageValue(t.PrivateKey) },
+		copy:      func(from, to *trillian.Tree) { to.PrivateKey = from.PrivateKey },
+	},
+	{
+		name:      "storage_settings",
+		keepUnset: true,
+		value:     func(t *trillian.Tree) string { return messageValue(t.StorageSettings) },
+		copy:      func(from, to *trillian.Tree) { to.StorageSettings = from.StorageSettings },
+	},
+	{
+		name:      "public_key",
+		readonly:  true,
+		keepUnset: true,
+		value:     func(t *trillian.Tree) string { return messageValue(t.PublicKey) },
+	},
+	{
+		name:  "max_root_duration",
+		value: func(t *trillian.Tree) string { return messageValue(t.MaxRootDuration) },
+		copy:  func(from, to *trillian.Tree) { to.MaxRootDuration = from.MaxRootDuration },
+	},
+	{
+		name:  "rate_limits",
+		value: func(t *trillian.Tree) string { return messageValue(t.RateLimits) },
+		copy:  func(from, to *trillian.Tree) { to.RateLimits = from.RateLimits },
+	},
+	{
+		name:  "labels",
+		value: func(t *trillian.Tree) string { return labelsValue(t.Labels) },
+		copy:  func(from, to *trillian.Tree) { to.Labels = from.Labels },
+	},
+	{
+		// Maintenance is usually entered and left with SetMaintenanceMode,
+		// so specs which don't mention it leave it alone.
+		name:      "maintenance",
+		keepUnset: true,
+		value:     func(t *trillian.Tree) string { return messageValue(t.Maintenance) },
+		copy:      func(from, to *trillian.Tree) { to.Maintenance = from.Maintenance },
+	},
+	{
+		name:      "map_strata",
+		readonly:  true,
+		keepUnset: true,
+		value:     func(t *trillian.Tree) string { return strataValue(t.MapStrata) },
+	},
+	{
+		name:  "map_compression",
+		value: func(t *trillian.Tree) string { return enumValue(t.MapCompression) },
+		copy:  func(from, to *trillian.Tree) { to.MapCompression = from.MapCompression },
+	},
+	{
+		name:      "map_hasher",
+		readonly:  true,
+		keepUnset: true,
+		value:     func(t *trillian.Tree) string { return t.MapHasher },
+	},
+	{
+		name:  "auto_init",
+		value: func(t *trillian.Tree) string { return boolValue(t.AutoInit) },
+		copy:  func(from, to *trillian.Tree) { to.AutoInit = from.AutoInit },
+	},
+	{
+		name:  "max_storage_bytes",
+		value: func(t *trillian.Tree) string { return intValue(t.MaxStorageBytes) },
+		copy:  func(from, to *trillian.Tree) { to.MaxStorageBytes = from.MaxStorageBytes },
+	},
+	{
+		name:      "map_key_index",
+		readonly:  true,
+		keepUnset: true,
+		value:     func(t *trillian.Tree) string { return boolValue(t.MapKeyIndex) },
+	},
+}
+
+func enumValue(e protoreflect.Enum) string {
+	if e.Number() == 0 {
+		return ""
+	}
+	return fmt.Sprint(e)
+}
+
+func messageValue(m proto.Message) string {
+	if m == nil || !proto.MessageReflect(m).IsValid() {
+		return ""
+	}
+	return proto.CompactTextString(m)
+}
+
+func boolValue(b bool) string {
+	if !b {
+		return ""
+	}
+	return strconv.FormatBool(b)
+}
+
+func intValue(i int64) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.FormatInt(i, 10)
+}
+
+func strataValue(strata []int32) string {
+	parts := make([]string, len(strata))
+	for i, height := range strata {
+		parts[i] = strconv.Itoa(int(height))
+	}
+	return strings.Join(parts, ",")
+}
+
+func labelsValue(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	// Keys are sorted, so equal labels have equal values.
+	b, err := json.Marshal(labels)
+	if err != nil {
+		// Should never happen, as labels are strings.
+		return fmt.Sprint(labels)
+	}
+	return string(b)
+}
+
+// diffTree returns the changes needed for tree to match spec. Unless create is
+// set, it returns a FailedPrecondition error if a readonly field would
+// change.
+func diffTree(spec, tree *trillian.Tree, create bool) ([]*trillian.TreeFieldChange, error) {
+	var changes []*trillian.TreeFieldChange
+	for _, f := range treeFields {
+		want := f.value(spec)
+		if want == "" && f.keepUnset {
+			continue
+		}
+		got := f.value(tree)
+		if got == want {
+			continue
+		}
+		if f.readonly && !create {
+			return nil, serrors.FailedPrecondition("READONLY_FIELD", "tree."+f.name, "readonly field %v of tree %v can't be changed from %q to %q", f.name, tree.TreeId, got, want)
+		}
+		if f.secret {
+			got, want = "", ""
+		}
+		changes = append(changes, &trillian.TreeFieldChange{Field: f.name, OldValue: got, NewValue: want})
+	}
+	return changes, nil
+}
+
+// applySpec copies the fields of spec which aren't readonly to tree.
+func applySpec(spec, tree *trillian.Tree) {
+	for _, f := range treeFields {
+		if f.readonly || (f.keepUnset && f.value(spec) == "") {
+			continue
+		}
+		f.copy(spec, tree)
+	}
+}
+
+// ApplyTreeSpec implements trillian.TrillianAdminServer.ApplyTreeSpec.
+func (s *Server) ApplyTreeSpec(ctx context.Context, req *trillian.ApplyTreeSpecRequest) (*trillian.ApplyTreeSpecResponse, error) {
+	spec := req.GetTree()
+	if spec == nil {
+		return nil, serrors.InvalidArgument("tree", "a tree is required")
+	}
+	tree, err := s.findTree(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		return s.createTreeFromSpec(ctx, req)
+	}
+	if tree.Deleted {
+		return nil, serrors.FailedPrecondition("TREE_DELETED", "tree.tree_id", "tree %v is deleted", tree.TreeId)
+	}
+
+	changes, err := diffTree(spec, tree, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 || req.DryRun {
+		tree = proto.Clone(tree).(*trillian.Tree)
+		applySpec(spec, tree)
+		return &trillian.ApplyTreeSpecResponse{Tree: redact(tree), Changes: changes}, nil
+	}
+
+	updated, err := storage.UpdateTree(ctx, s.registry.AdminStorage, tree.TreeId, func(other *trillian.Tree) {
+		// The tree may have changed since it was read, so the changes are
+		// those to the tree being updated.
+		var err error
+		if changes, err = diffTree(spec, other, false); err != nil {
+			// Readonly fields are left as they are, which storage checks.
+			glog.Warningf("ApplyTreeSpec: tree %v changed concurrently: %v", other.TreeId, err)
+		}
+		applySpec(spec, other)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.ApplyTreeSpecResponse{Tree: redact(updated), Changes: changes}, nil
+}
+
+// findTree returns the tree identified by spec, by ID, or by display name
+// among the trees which aren't deleted, or nil if there's no such tree.
+func (s *Server) findTree(ctx context.Context, spec *trillian.Tree) (*trillian.Tree, error) {
+	if spec.TreeId != 0 {
+		tree, err := storage.GetTree(ctx, s.registry.AdminStorage, spec.TreeId)
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return tree, err
+	}
+	if spec.DisplayName == "" {
+		return nil, serrors.InvalidArgument("tree.display_name", "tree.tree_id or tree.display_name is required")
+	}
+	all, err := storage.ListTrees(ctx, s.registry.AdminStorage, false)
+	if err != nil {
+		return nil, err
+	}
+	var found *trillian.Tree
+	for _, tree := range all {
+		if tree.DisplayName != spec.DisplayName {
+			continue
+		}
+		if found != nil {
+			return nil, serrors.FailedPrecondition("AMBIGUOUS_DISPLAY_NAME", "tree.display_name", "trees %v and %v both have display name %q", found.TreeId, tree.TreeId, spec.DisplayName)
+		}
+		found = tree
+	}
+	return found, nil
+}
+
+// createTreeFromSpec creates the tree of an ApplyTreeSpec request, unless it's
+// a dry run.
+func (s *Server) createTreeFromSpec(ctx context.Context, req *trillian.ApplyTreeSpecRequest) (*trillian.ApplyTreeSpecResponse, error) {
+	tree := proto.Clone(req.Tree).(*trillian.Tree)
+	if tree.TreeState == trillian.TreeState_UNKNOWN_TREE_STATE {
+		tree.TreeState = trillian.TreeState_ACTIVE
+	}
+	changes, err := diffTree(tree, &trillian.Tree{}, true)
+	if err != nil {
+		return nil, err
+	}
+	if req.DryRun {
+		if err := s.validateTreeType(tree); err != nil {
+			return nil, err
+		}
+		if tree.PrivateKey == nil && req.KeySpec == nil {
+			return nil, serrors.InvalidArgument("tree.private_key", "tree.private_key or key_spec is required")
+		}
+		return &trillian.ApplyTreeSpecResponse{Tree: redact(tree), Created: true, Changes: changes}, nil
+	}
+	created, err := s.CreateTree(ctx, &trillian.CreateTreeRequest{Tree: tree, KeySpec: req.KeySpec, TreeId: tree.TreeId})
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.ApplyTreeSpecResponse{Tree: created, Created: true, Changes: changes}, nil
+}
@@ -0,0 +1,366 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestServer_ApplyTreeSpec(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	existing := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	existing.TreeId = 12345
+	other := proto.Clone(testonly.MapTree).(*trillian.Tree)
+	other.TreeId = 67890
+
+	// tweaked returns a copy of tree, changed by fn.
+	tweaked := func(tree *trillian.Tree, fn func(*trillian.Tree)) *trillian.Tree {
+		tree = proto.Clone(tree).(*trillian.Tree)
+		fn(tree)
+		return tree
+	}
+	// spec is a spec of existing, without the fields kept if unset.
+	spec := tweaked(existing, func(tree *trillian.Tree) {
+		tree.PrivateKey = nil
+		tree.PublicKey = nil
+		tree.HashAlgorithm = 0
+	})
+	changed := tweaked(spec, func(tree *trillian.Tree) {
+		tree.TreeState = trillian.TreeState_DRAINING
+		tree.Description = ""
+		tree.RateLimits = &trillian.TreeRateLimits{LeavesPerSecond: 100}
+		tree.Labels = map[string]string{"env": "prod"}
+	})
+	wantChanges := []*trillian.TreeFieldChange{
+		{Field: "tree_state", OldValue: "ACTIVE", NewValue: "DRAINING"},
+		{Field: "description", OldValue: existing.Description},
+		{Field: "rate_limits", NewValue: messageValue(changed.RateLimits)},
+		{Field: "labels", NewValue: `{"env":"prod"}`},
+	}
+	wantChanged := tweaked(existing, func(tree *trillian.Tree) {
+		applySpec(changed, tree)
+		tree.PrivateKey = nil
+	})
+	byName := tweaked(changed, func(tree *trillian.Tree) { tree.TreeId = 0 })
+	withKey := tweaked(byName, func(tree *trillian.Tree) { tree.PrivateKey = existing.PrivateKey })
+
+	tests := []struct {
+		desc       string
+		req        *trillian.ApplyTreeSpecRequest
+		list       []*trillian.Tree
+		get        *trillian.Tree
+		wantUpdate bool
+		want       *trillian.ApplyTreeSpecResponse
+		wantErr    codes.Code
+	}{
+		{
+			desc: "unchanged",
+			req:  &trillian.ApplyTreeSpecRequest{Tree: spec},
+			get:  existing,
+			want: &trillian.ApplyTreeSpecResponse{Tree: tweaked(existing, func(tree *trillian.Tree) { tree.PrivateKey = nil })},
+		},
+		{
+			desc:       "changed",
+			req:        &trillian.ApplyTreeSpecRequest{Tree: changed},
+			get:        existing,
+			wantUpdate: true,
+			want:       &trillian.ApplyTreeSpecResponse{Tree: wantChanged, Changes: wantChanges},
+		},
+		{
+			desc: "dryRun",
+			req:  &trillian.ApplyTreeSpecRequest{Tree: changed, DryRun: true},
+			get:  existing,
+			want: &trillian.ApplyTreeSpecResponse{Tree: wantChanged, Changes: wantChanges},
+		},
+		{
+			desc:       "byDisplayName",
+			req:        &trillian.ApplyTreeSpecRequest{Tree: byName},
+			list:       []*trillian.Tree{other, existing},
+			wantUpdate: true,
+			want:       &trillian.ApplyTreeSpecResponse{Tree: wantChanged, Changes: wantChanges},
+		},
+		{
+			desc: "dryRunCreate",
+			req:  &trillian.ApplyTreeSpecRequest{Tree: withKey, DryRun: true},
+			list: []*trillian.Tree{other},
+			want: &trillian.ApplyTreeSpecResponse{
+				Tree:    byName,
+				Created: true,
+				Changes: []*trillian.TreeFieldChange{
+					{Field: "tree_state", NewValue: "DRAINING"},
+					{Field: "tree_type", NewValue: "LOG"},
+					{Field: "hash_strategy", NewValue: "RFC6962_SHA256"},
+					{Field: "signature_algorithm", NewValue: "ECDSA"},
+					{Field: "display_name", NewValue: byName.DisplayName},
+					{Field: "private_key"},
+					wantChanges[2],
+					wantChanges[3],
+				},
+			},
+		},
+		{
+			desc:    "createWithoutKey",
+			req:     &trillian.ApplyTreeSpecRequest{Tree: byName, DryRun: true},
+			list:    []*trillian.Tree{other},
+			wantErr: codes.InvalidArgument,
+		},
+		{
+			desc:    "ambiguousDisplayName",
+			req:     &trillian.ApplyTreeSpecRequest{Tree: byName},
+			list:    []*trillian.Tree{existing, tweaked(existing, func(tree *trillian.Tree) { tree.TreeId++ })},
+			wantErr: codes.FailedPrecondition,
+		},
+		{
+			desc:    "readonlyField",
+			req:     &trillian.ApplyTreeSpecRequest{Tree: tweaked(spec, func(tree *trillian.Tree) { tree.SignatureAlgorithm++ })},
+			get:     existing,
+			wantErr: codes.FailedPrecondition,
+		},
+		{
+			desc:    "deleted",
+			req:     &trillian.ApplyTreeSpecRequest{Tree: spec},
+			get:     tweaked(existing, func(tree *trillian.Tree) { tree.Deleted = true }),
+			wantErr: codes.FailedPrecondition,
+		},
+		{
+			desc:    "noTree",
+			req:     &trillian.ApplyTreeSpecRequest{},
+			wantErr: codes.InvalidArgument,
+		},
+		{
+			desc:    "noIDOrDisplayName",
+			req:     &trillian.ApplyTreeSpecRequest{Tree: &trillian.Tree{Description: "Anonymous"}},
+			wantErr: codes.InvalidArgument,
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			read := test.get != nil || test.list != nil
+			setup := setupAdminServer(ctrl, nil /* keygen */, true /* snapshot */, read /* shouldCommit */, false /* commitErr */)
+			if test.get != nil {
+				setup.snapshotTX.EXPECT().GetTree(gomock.Any(), test.get.TreeId).Return(proto.Clone(test.get), nil)
+			}
+			if test.list != nil {
+				setup.snapshotTX.EXPECT().ListTrees(gomock.Any(), false).Return(test.list, nil)
+			}
+			if test.wantUpdate {
+				tx := storage.NewMockAdminTX(ctrl)
+				tx.EXPECT().Close().MaxTimes(1).Return(nil)
+				tx.EXPECT().Commit().Return(nil)
+				tx.EXPECT().UpdateTree(gomock.Any(), existing.TreeId, gomock.Any()).DoAndReturn(func(ctx context.Context, treeID int64, updateFn func(*trillian.Tree)) (*trillian.Tree, error) {
+					tree := proto.Clone(existing).(*trillian.Tree)
+					updateFn(tree)
+					return tree, nil
+				})
+				setup.as.(*testonly.FakeAdminStorage).TX = append(setup.as.(*testonly.FakeAdminStorage).TX, tx)
+			}
+
+			resp, err := setup.server.ApplyTreeSpec(ctx, test.req)
+			if got := status.Code(err); got != test.wantErr {
+				t.Fatalf("ApplyTreeSpec(): %v, want code %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(resp, test.want, protocmp.Transform()); err == nil && diff != "" {
+				t.Errorf("ApplyTreeSpec() diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffTree(t *testing.T) {
+	tree := proto.Clone(testonly.MapTree).(*trillian.Tree)
+	tree.TreeId = 12345
+	maintenance := &trillian.TreeMaintenance{Reason: "migration"}
+
+	for _, test := range []struct {
+		desc        string
+		tree, spec  func(*trillian.Tree)
+		want        []*trillian.TreeFieldChange
+		wantApplied func(*trillian.Tree)
+		wantErr     bool
+	}{
+		{
+			desc:        "maintenanceSet",
+			spec:        func(t *trillian.Tree) { t.Maintenance = maintenance },
+			want:        []*trillian.TreeFieldChange{{Field: "maintenance", NewValue: messageValue(maintenance)}},
+			wantApplied: func(t *trillian.Tree) { t.Maintenance = maintenance },
+		},
+		{
+			desc: "maintenanceKeptUnset",
+			tree: func(t *trillian.Tree) { t.Maintenance = maintenance },
+			spec: func(t *trillian.Tree) { t.Maintenance = nil },
+		},
+		{
+			desc:    "mapStrataChanged",
+			tree:    func(t *trillian.Tree) { t.MapStrata = []int32{8, 248} },
+			spec:    func(t *trillian.Tree) { t.MapStrata = []int32{16, 240} },
+			wantErr: true,
+		},
+		{
+			desc: "mapStrataKeptUnset",
+			tree: func(t *trillian.Tree) { t.MapStrata = []int32{8, 248} },
+			spec: func(t *trillian.Tree) { t.MapStrata = nil },
+		},
+		{
+			desc:        "mapCompressionSet",
+			spec:        func(t *trillian.Tree) { t.MapCompression = trillian.MapCompression_MAP_COMPRESSION_ZSTD },
+			want:        []*trillian.TreeFieldChange{{Field: "map_compression", NewValue: "MAP_COMPRESSION_ZSTD"}},
+			wantApplied: func(t *trillian.Tree) { t.MapCompression = trillian.MapCompression_MAP_COMPRESSION_ZSTD },
+		},
+		{
+			desc:        "mapCompressionCleared",
+			tree:        func(t *trillian.Tree) { t.MapCompression = trillian.MapCompression_MAP_COMPRESSION_SNAPPY },
+			spec:        func(t *trillian.Tree) { t.MapCompression = trillian.MapCompression_NO_MAP_COMPRESSION },
+			want:        []*trillian.TreeFieldChange{{Field: "map_compression", OldValue: "MAP_COMPRESSION_SNAPPY"}},
+			wantApplied: func(t *trillian.Tree) { t.MapCompression = trillian.MapCompression_NO_MAP_COMPRESSION },
+		},
+		{
+			desc:    "mapHasherChanged",
+			tree:    func(t *trillian.Tree) { t.MapHasher = "blake2b" },
+			spec:    func(t *trillian.Tree) { t.MapHasher = "sha3" },
+			wantErr: true,
+		},
+		{
+			desc: "mapHasherKeptUnset",
+			tree: func(t *trillian.Tree) { t.MapHasher = "blake2b" },
+			spec: func(t *trillian.Tree) { t.MapHasher = "" },
+		},
+		{
+			desc:        "autoInitSet",
+			spec:        func(t *trillian.Tree) { t.AutoInit = true },
+			want:        []*trillian.TreeFieldChange{{Field: "auto_init", NewValue: "true"}},
+			wantApplied: func(t *trillian.Tree) { t.AutoInit = true },
+		},
+		{
+			desc:        "autoInitCleared",
+			tree:        func(t *trillian.Tree) { t.AutoInit = true },
+			spec:        func(t *trillian.Tree) { t.AutoInit = false },
+			want:        []*trillian.TreeFieldChange{{Field: "auto_init", OldValue: "true"}},
+			wantApplied: func(t *trillian.Tree) { t.AutoInit = false },
+		},
+		{
+			desc:        "maxStorageBytesSet",
+			spec:        func(t *trillian.Tree) { t.MaxStorageBytes = 1 << 30 },
+			want:        []*trillian.TreeFieldChange{{Field: "max_storage_bytes", NewValue: "1073741824"}},
+			wantApplied: func(t *trillian.Tree) { t.MaxStorageBytes = 1 << 30 },
+		},
+		{
+			desc:        "maxStorageBytesCleared",
+			tree:        func(t *trillian.Tree) { t.MaxStorageBytes = 1 << 30 },
+			spec:        func(t *trillian.Tree) { t.MaxStorageBytes = 0 },
+			want:        []*trillian.TreeFieldChange{{Field: "max_storage_bytes", OldValue: "1073741824"}},
+			wantApplied: func(t *trillian.Tree) { t.MaxStorageBytes = 0 },
+		},
+		{
+			desc:    "mapKeyIndexSet",
+			spec:    func(t *trillian.Tree) { t.MapKeyIndex = true },
+			wantErr: true,
+		},
+		{
+			desc: "mapKeyIndexKeptUnset",
+			tree: func(t *trillian.Tree) { t.MapKeyIndex = true },
+			spec: func(t *trillian.Tree) { t.MapKeyIndex = false },
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			tree := proto.Clone(tree).(*trillian.Tree)
+			if test.tree != nil {
+				test.tree(tree)
+			}
+			spec := proto.Clone(tree).(*trillian.Tree)
+			if test.spec != nil {
+				test.spec(spec)
+			}
+
+			changes, err := diffTree(spec, tree, false)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("diffTree(): %v, want error: %v", err, test.wantErr)
+			}
+			if err != nil {
+				if got, want := status.Code(err), codes.FailedPrecondition; got != want {
+					t.Errorf("diffTree(): %v, want code %v", err, want)
+				}
+				return
+			}
+			if diff := cmp.Diff(changes, test.want, protocmp.Transform()); diff != "" {
+				t.Errorf("diffTree() diff (-got +want):\n%s", diff)
+			}
+
+			want := proto.Clone(tree).(*trillian.Tree)
+			if test.wantApplied != nil {
+				test.wantApplied(want)
+			}
+			applySpec(spec, tree)
+			if diff := cmp.Diff(tree, want, protocmp.Transform()); diff != "" {
+				t.Errorf("applySpec() diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTreeFieldsComplete(t *testing.T) {
+	// Fields which identify trees, or are assigned by storage or by key
+	// rotation.
+	unreconciled := map[string]bool{
+		"tree_id":             true,
+		"create_time":         true,
+		"update_time":         true,
+		"deleted":             true,
+		"delete_time":         true,
+		"create_request_id":   true,
+		"retired_public_keys": true,
+	}
+
+	listed := make(map[string]bool)
+	var last protoreflect.FieldNumber
+	for _, f := range treeFields {
+		fd := (&trillian.Tree{}).ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(f.name))
+		if fd == nil {
+			t.Errorf("treeFields lists %q, which isn't a field of Tree", f.name)
+			continue
+		}
+		if fd.Number() <= last {
+			t.Errorf("treeFields lists %q out of order", f.name)
+		}
+		last = fd.Number()
+		if (f.copy == nil) != f.readonly {
+			t.Errorf("treeFields entry %q: copy set = %t, want %t", f.name, f.copy != nil, !f.readonly)
+		}
+		listed[f.name] = true
+	}
+
+	fields := (&trillian.Tree{}).ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		name := string(fields.Get(i).Name())
+		if listed[name] == unreconciled[name] {
+			t.Errorf("field %q of Tree: listed in treeFields = %t, want %t", name, listed[name], !unreconciled[name])
+		}
+	}
+}
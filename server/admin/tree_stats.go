@@ -0,0 +1,62 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var treeStatsOpts = trees.NewGetOpts(trees.Admin, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
+
+// GetTreeStats implements trillian.TrillianAdminServer.GetTreeStats.
+func (s *Server) GetTreeStats(ctx context.Context, req *trillian.GetTreeStatsRequest) (*trillian.GetTreeStatsResponse, error) {
+	if s.registry.LogStorage == nil {
+		return nil, status.Error(codes.Unimplemented, "log storage is not available")
+	}
+	tree, err := trees.GetTree(ctx, s.registry.AdminStorage, req.GetTreeId(), treeStatsOpts)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.registry.LogStorage.SnapshotForTree(ctx, tree)
+	if tx != nil {
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	st, ok := tx.(storage.TreeStatsTX)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "log storage does not support tree statistics")
+	}
+	stats, err := st.GetTreeStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &trillian.GetTreeStatsResponse{
+		LeafCount:        stats.LeafCount,
+		LeafValueBytes:   stats.LeafValueBytes,
+		ExtraDataBytes:   stats.ExtraDataBytes,
+		UnsequencedCount: stats.UnsequencedCount,
+	}, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statsTX is a ReadOnlyLogTreeTX which reports fixed statistics.
+type statsTX struct {
+	*storage.MockReadOnlyLogTreeTX
+	stats *storage.TreeStats
+	err   error
+}
+
+func (s statsTX) GetTreeStats(ctx context.Context) (*storage.TreeStats, error) {
+	return s.stats, s.err
+}
+
+func TestServer_GetTreeStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const treeID = 12345
+	stats := &storage.TreeStats{LeafCount: 10, LeafValueBytes: 200, ExtraDataBytes: 30, UnsequencedCount: 4}
+
+	tests := []struct {
+		desc        string
+		noStorage   bool
+		unsupported bool
+		statsErr    error
+		want        *trillian.GetTreeStatsResponse
+		wantErr     codes.Code
+	}{
+		{
+			desc: "stats",
+			want: &trillian.GetTreeStatsResponse{LeafCount: 10, LeafValueBytes: 200, ExtraDataBytes: 30, UnsequencedCount: 4},
+		},
+		{
+			desc:      "noLogStorage",
+			noStorage: true,
+			wantErr:   codes.Unimplemented,
+		},
+		{
+			desc:        "unsupported",
+			unsupported: true,
+			wantErr:     codes.Unimplemented,
+		},
+		{
+			desc:     "statsError",
+			statsErr: status.Error(codes.Unavailable, "database unavailable"),
+			wantErr:  codes.Unavailable,
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			setup := setupAdminServer(ctrl, nil /* keygen */, true /* snapshot */, !test.noStorage /* shouldCommit */, false /* commitErr */)
+			if !test.noStorage {
+				tree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+				tree.TreeId = treeID
+				setup.snapshotTX.EXPECT().GetTree(gomock.Any(), int64(treeID)).Return(tree, nil)
+
+				logTX := storage.NewMockReadOnlyLogTreeTX(ctrl)
+				logTX.EXPECT().Close().Return(nil)
+				var tx storage.ReadOnlyLogTreeTX = statsTX{MockReadOnlyLogTreeTX: logTX, stats: stats, err: test.statsErr}
+				if test.unsupported {
+					tx = logTX
+				} else if test.statsErr == nil {
+					logTX.EXPECT().Commit(gomock.Any()).Return(nil)
+				}
+				ls := storage.NewMockLogStorage(ctrl)
+				ls.EXPECT().SnapshotForTree(gomock.Any(), tree).Return(tx, nil)
+				setup.server.registry.LogStorage = ls
+			}
+
+			resp, err := setup.server.GetTreeStats(ctx, &trillian.GetTreeStatsRequest{TreeId: treeID})
+			if got := status.Code(err); got != test.wantErr {
+				t.Fatalf("GetTreeStats() = (_, %v), want code %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !proto.Equal(resp, test.want) {
+				t.Errorf("GetTreeStats() = %v, want %v", resp, test.want)
+			}
+		})
+	}
+}
+
+func TestServer_GetTreeStats_SnapshotError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	setup := setupAdminServer(ctrl, nil /* keygen */, true /* snapshot */, true /* shouldCommit */, false /* commitErr */)
+	tree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	setup.snapshotTX.EXPECT().GetTree(gomock.Any(), tree.TreeId).Return(tree, nil)
+	ls := storage.NewMockLogStorage(ctrl)
+	ls.EXPECT().SnapshotForTree(gomock.Any(), tree).Return(nil, errors.New("snapshot failed"))
+	setup.server.registry.LogStorage = ls
+
+	if _, err := setup.server.GetTreeStats(context.Background(), &trillian.GetTreeStatsRequest{TreeId: tree.TreeId}); err == nil {
+		t.Error("GetTreeStats() returned no error, want error")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var treeUsageOpts = trees.NewGetOpts(trees.Admin, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG, trillian.TreeType_MAP)
+
+// GetTreeUsage implements trillian.TrillianAdminServer.GetTreeUsage.
+func (s *Server) GetTreeUsage(ctx context.Context, req *trillian.GetTreeUsageRequest) (*trillian.GetTreeUsageResponse, error) {
+	tree, err := trees.GetTree(ctx, s.registry.AdminStorage, req.GetTreeId(), treeUsageOpts)
+	if err != nil {
+		return nil, err
+	}
+	var tx storage.ReadOnlyTreeTX
+	switch tree.TreeType {
+	case trillian.TreeType_MAP:
+		if s.registry.MapStorage == nil {
+			return nil, status.Error(codes.Unimplemented, "map storage is not available")
+		}
+		tx, err = s.registry.MapStorage.SnapshotForTree(ctx, tree)
+	default:
+		if s.registry.LogStorage == nil {
+			return nil, status.Error(codes.Unimplemented, "log storage is not available")
+		}
+		tx, err = s.registry.LogStorage.SnapshotForTree(ctx, tree)
+	}
+	if tx != nil {
+		defer tx.Close()
+	}
+	// Trees which haven't been initialized may still have had data written.
+	if err != nil && (err != storage.ErrTreeNeedsInit || tx == nil) {
+		return nil, err
+	}
+	ut, ok := tx.(storage.TreeUsageTX)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage does not track the usage of trees")
+	}
+	usage, err := ut.GetTreeUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &trillian.GetTreeUsageResponse{
+		LeafBytes:       usage.LeafBytes,
+		SubtreeBytes:    usage.SubtreeBytes,
+		MaxStorageBytes: tree.MaxStorageBytes,
+	}, nil
+}
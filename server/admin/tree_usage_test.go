@@ -0,0 +1,134 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// usageLogTX is a ReadOnlyLogTreeTX which reports a fixed usage.
+type usageLogTX struct {
+	*storage.MockReadOnlyLogTreeTX
+	usage *storage.TreeUsage
+}
+
+func (u usageLogTX) GetTreeUsage(ctx context.Context) (*storage.TreeUsage, error) {
+	return u.usage, nil
+}
+
+// usageMapTX is a ReadOnlyMapTreeTX which reports a fixed usage.
+type usageMapTX struct {
+	*storage.MockReadOnlyMapTreeTX
+	usage *storage.TreeUsage
+}
+
+func (u usageMapTX) GetTreeUsage(ctx context.Context) (*storage.TreeUsage, error) {
+	return u.usage, nil
+}
+
+func TestServer_GetTreeUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const treeID = 12345
+	usage := &storage.TreeUsage{LeafBytes: 200, SubtreeBytes: 3000}
+
+	tests := []struct {
+		desc        string
+		tree        *trillian.Tree
+		noStorage   bool
+		unsupported bool
+		want        *trillian.GetTreeUsageResponse
+		wantErr     codes.Code
+	}{
+		{
+			desc: "log",
+			tree: testonly.LogTree,
+			want: &trillian.GetTreeUsageResponse{LeafBytes: 200, SubtreeBytes: 3000, MaxStorageBytes: 5000},
+		},
+		{
+			desc: "map",
+			tree: testonly.MapTree,
+			want: &trillian.GetTreeUsageResponse{LeafBytes: 200, SubtreeBytes: 3000, MaxStorageBytes: 5000},
+		},
+		{
+			desc:      "noMapStorage",
+			tree:      testonly.MapTree,
+			noStorage: true,
+			wantErr:   codes.Unimplemented,
+		},
+		{
+			desc:        "unsupported",
+			tree:        testonly.LogTree,
+			unsupported: true,
+			wantErr:     codes.Unimplemented,
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			setup := setupAdminServer(ctrl, nil /* keygen */, true /* snapshot */, true /* shouldCommit */, false /* commitErr */)
+			tree := proto.Clone(test.tree).(*trillian.Tree)
+			tree.TreeId = treeID
+			tree.MaxStorageBytes = 5000
+			setup.snapshotTX.EXPECT().GetTree(gomock.Any(), int64(treeID)).Return(tree, nil)
+
+			if !test.noStorage {
+				switch tree.TreeType {
+				case trillian.TreeType_MAP:
+					mapTX := storage.NewMockReadOnlyMapTreeTX(ctrl)
+					mapTX.EXPECT().Close().Return(nil)
+					mapTX.EXPECT().Commit(gomock.Any()).Return(nil)
+					ms := storage.NewMockMapStorage(ctrl)
+					ms.EXPECT().SnapshotForTree(gomock.Any(), tree).Return(usageMapTX{MockReadOnlyMapTreeTX: mapTX, usage: usage}, nil)
+					setup.server.registry.MapStorage = ms
+				default:
+					logTX := storage.NewMockReadOnlyLogTreeTX(ctrl)
+					logTX.EXPECT().Close().Return(nil)
+					var tx storage.ReadOnlyLogTreeTX = usageLogTX{MockReadOnlyLogTreeTX: logTX, usage: usage}
+					if test.unsupported {
+						tx = logTX
+					} else {
+						logTX.EXPECT().Commit(gomock.Any()).Return(nil)
+					}
+					ls := storage.NewMockLogStorage(ctrl)
+					ls.EXPECT().SnapshotForTree(gomock.Any(), tree).Return(tx, nil)
+					setup.server.registry.LogStorage = ls
+				}
+			}
+
+			resp, err := setup.server.GetTreeUsage(ctx, &trillian.GetTreeUsageRequest{TreeId: treeID})
+			if got := status.Code(err); got != test.wantErr {
+				t.Fatalf("GetTreeUsage() = (_, %v), want code %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !proto.Equal(resp, test.want) {
+				t.Errorf("GetTreeUsage() = %v, want %v", resp, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian/quota"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the domain of the ErrorInfo details of Trillian errors.
+const Domain = "github.com/google/trillian"
+
+// Reasons of the ErrorInfo details of Trillian errors.
+const (
+	// ReasonInvalidField is the reason of errors for a request field with an
+	// invalid value. The "field" metadata holds the path of the field.
+	ReasonInvalidField = "INVALID_FIELD"
+	// ReasonPreconditionFailed is the reason of errors for a request which
+	// can't be served in the current state of the system. The "type" and
+	// "subject" metadata hold those of the PreconditionFailure violation.
+	ReasonPreconditionFailed = "PRECONDITION_FAILED"
+	// ReasonQuotaExhausted is the reason of errors for a request denied for
+	// lack of quota. The "quota" metadata holds the name of the quota
+	// exhausted, if known.
+	ReasonQuotaExhausted = "QUOTA_EXHAUSTED"
+	// ReasonRateLimited is the reason of errors for a request denied by a
+	// rate limit. The "limit" metadata holds the name of the limit.
+	ReasonRateLimited = "RATE_LIMITED"
+	// ReasonOverloaded is the reason of errors for a request shed while the
+	// server is overloaded. The "priority" metadata holds the priority of the
+	// request.
+	ReasonOverloaded = "OVERLOADED"
+)
+
+// InvalidArgument returns an InvalidArgument error for an invalid field of a
+// request, with the given message. Its details name the field, with its path
+// in the request, such as "leaves[3].leaf_value", so that clients don't have
+// to parse the message.
+func InvalidArgument(field, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return withDetails(status.New(codes.InvalidArgument, msg),
+		&errdetails.ErrorInfo{Reason: ReasonInvalidField, Domain: Domain, Metadata: map[string]string{"field": field}},
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: msg}}})
+}
+
+// FailedPrecondition returns a FailedPrecondition error with the given
+// message, whose details hold a PreconditionFailure violation of the given
+// type about subject, such as "LEAF_INDEX" and "leaves[3].leaf_index".
+func FailedPrecondition(typ, subject, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return withDetails(status.New(codes.FailedPrecondition, msg),
+		&errdetails.ErrorInfo{Reason: ReasonPreconditionFailed, Domain: Domain, Metadata: map[string]string{"type": typ, "subject": subject}},
+		&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{Type: typ, Subject: subject, Description: msg}}})
+}
+
+// QuotaExhausted returns a ResourceExhausted error for a request which
+// couldn't get tokens from the given specs, as GetTokens of a quota.Manager
+// failed with err. If err is a *quota.ExhaustedError, the details of the
+// error name the quota exhausted, and say when to retry if it's known.
+// Otherwise they name all of the quotas the request was charged against.
+func QuotaExhausted(err error, specs []quota.Spec) error {
+	st := status.Newf(codes.ResourceExhausted, "quota exhausted: %v", err)
+	info := &errdetails.ErrorInfo{Reason: ReasonQuotaExhausted, Domain: Domain}
+	failure := &errdetails.QuotaFailure{}
+	var exhausted *quota.ExhaustedError
+	if !errors.As(err, &exhausted) {
+		for _, spec := range specs {
+			failure.Violations = append(failure.Violations, &errdetails.QuotaFailure_Violation{Subject: spec.Name(), Description: err.Error()})
+		}
+		return withDetails(st, info, failure)
+	}
+
+	info.Metadata = map[string]string{"quota": exhausted.Spec.Name()}
+	failure.Violations = []*errdetails.QuotaFailure_Violation{{Subject: exhausted.Spec.Name(), Description: exhausted.Error()}}
+	if exhausted.RetryAfter <= 0 {
+		return withDetails(st, info, failure)
+	}
+	return withDetails(st, info, failure, &errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(exhausted.RetryAfter)})
+}
+
+// RateLimited returns a ResourceExhausted error for a request denied by the
+// rate limit named limit, such as "trees/123/leaves_per_second", with the
+// given message. If retryAfter is positive, the details of the error say to
+// retry after it.
+func RateLimited(limit string, retryAfter time.Duration, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	details := []proto.Message{
+		&errdetails.ErrorInfo{Reason: ReasonRateLimited, Domain: Domain, Metadata: map[string]string{"limit": limit}},
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: limit, Description: msg}}},
+	}
+	if retryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(retryAfter)})
+	}
+	return withDetails(status.New(codes.ResourceExhausted, msg), details...)
+}
+
+// Overloaded returns a ResourceExhausted error for a request of the given
+// priority, such as "bulk_read", which was shed as the server is overloaded,
+// with the given message. If retryAfter is positive, the details of the error
+// say to retry after it.
+func Overloaded(priority string, retryAfter time.Duration, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	details := []proto.Message{
+		&errdetails.ErrorInfo{Reason: ReasonOverloaded, Domain: Domain, Metadata: map[string]string{"priority": priority}},
+	}
+	if retryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(retryAfter)})
+	}
+	return withDetails(status.New(codes.ResourceExhausted, msg), details...)
+}
+
+// withDetails returns the error of st with the given details, or without them
+// if they can't be added.
+func withDetails(st *status.Status, details ...proto.Message) error {
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails.Err()
+	}
+	return st.Err()
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/quota"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestDetails(t *testing.T) {
+	specs := []quota.Spec{
+		{Group: quota.Tree, Kind: quota.Write, TreeID: 10},
+		{Group: quota.Global, Kind: quota.Write},
+	}
+	exhausted := &quota.ExhaustedError{Spec: specs[0], Name: "trees/10/write", Available: 1, Requested: 3}
+	retry := *exhausted
+	retry.RetryAfter = 2 * time.Second
+
+	for _, test := range []struct {
+		desc        string
+		err         error
+		wantCode    codes.Code
+		wantMsg     string
+		wantDetails []proto.Message
+	}{
+		{
+			desc:     "invalid-argument",
+			err:      InvalidArgument("leaves[3].leaf_value", "Leaves[%d].LeafValue: empty", 3),
+			wantCode: codes.InvalidArgument,
+			wantMsg:  "Leaves[3].LeafValue: empty",
+			wantDetails: []proto.Message{
+				&errdetails.ErrorInfo{Reason: ReasonInvalidField, Domain: Domain, Metadata: map[string]string{"field": "leaves[3].leaf_value"}},
+				&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "leaves[3].leaf_value", Description: "Leaves[3].LeafValue: empty"}}},
+			},
+		},
+		{
+			desc:     "failed-precondition",
+			err:      FailedPrecondition("REVISION", "revision", "can't write to revision %d", 7),
+			wantCode: codes.FailedPrecondition,
+			wantMsg:  "can't write to revision 7",
+			wantDetails: []proto.Message{
+				&errdetails.ErrorInfo{Reason: ReasonPreconditionFailed, Domain: Domain, Metadata: map[string]string{"type": "REVISION", "subject": "revision"}},
+				&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{Type: "REVISION", Subject: "revision", Description: "can't write to revision 7"}}},
+			},
+		},
+		{
+			desc:     "rate-limited",
+			err:      RateLimited("trees/10/leaves_per_second", time.Second, "over %v leaves per second", 5),
+			wantCode: codes.ResourceExhausted,
+			wantMsg:  "over 5 leaves per second",
+			wantDetails: []proto.Message{
+				&errdetails.ErrorInfo{Reason: ReasonRateLimited, Domain: Domain, Metadata: map[string]string{"limit": "trees/10/leaves_per_second"}},
+				&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "trees/10/leaves_per_second", Description: "over 5 leaves per second"}}},
+				&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(time.Second)},
+			},
+		},
+		{
+			desc:     "rate-limited-no-retry",
+			err:      RateLimited("trees/10/queries_per_second", 0, "over the limit"),
+			wantCode: codes.ResourceExhausted,
+			wantMsg:  "over the limit",
+			wantDetails: []proto.Message{
+				&errdetails.ErrorInfo{Reason: ReasonRateLimited, Domain: Domain, Metadata: map[string]string{"limit": "trees/10/queries_per_second"}},
+				&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "trees/10/queries_per_second", Description: "over the limit"}}},
+			},
+		},
+		{
+			desc:     "overloaded",
+			err:      Overloaded("bulk_read", 500*time.Millisecond, "server overloaded"),
+			wantCode: codes.ResourceExhausted,
+			wantMsg:  "server overloaded",
+			wantDetails: []proto.Message{
+				&errdetails.ErrorInfo{Reason: ReasonOverloaded, Domain: Domain, Metadata: map[string]string{"priority": "bulk_read"}},
+				&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(500 * time.Millisecond)},
+			},
+		},
+		{
+			desc:     "quota-unknown",
+			err:      QuotaExhausted(errors.New("no tokens"), specs),
+			wantCode: codes.ResourceExhausted,
+			wantMsg:  "quota exhausted: no tokens",
+			wantDetails: []proto.Message{
+				&errdetails.ErrorInfo{Reason: ReasonQuotaExhausted, Domain: Domain},
+				&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{
+					{Subject: "trees/10/write", Description: "no tokens"},
+					{Subject: "global/write", Description: "no tokens"},
+				}},
+			},
+		},
+		{
+			desc:     "quota-exhausted",
+			err:      QuotaExhausted(fmt.Errorf("wrapped: %w", exhausted), specs),
+			wantCode: codes.ResourceExhausted,
+			wantMsg:  "quota exhausted: wrapped: insufficient tokens on trees/10/write (1 vs 3)",
+			wantDetails: []proto.Message{
+				&errdetails.ErrorInfo{Reason: ReasonQuotaExhausted, Domain: Domain, Metadata: map[string]string{"quota": "trees/10/write"}},
+				&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "trees/10/write", Description: "insufficient tokens on trees/10/write (1 vs 3)"}}},
+			},
+		},
+		{
+			desc:     "quota-retry",
+			err:      QuotaExhausted(&retry, specs),
+			wantCode: codes.ResourceExhausted,
+			wantMsg:  "quota exhausted: insufficient tokens on trees/10/write (1 vs 3)",
+			wantDetails: []proto.Message{
+				&errdetails.ErrorInfo{Reason: ReasonQuotaExhausted, Domain: Domain, Metadata: map[string]string{"quota": "trees/10/write"}},
+				&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "trees/10/write", Description: "insufficient tokens on trees/10/write (1 vs 3)"}}},
+				&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(2 * time.Second)},
+			},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			st := status.Convert(test.err)
+			if got, want := st.Code(), test.wantCode; got != want {
+				t.Errorf("code %v, want %v", got, want)
+			}
+			if got, want := st.Message(), test.wantMsg; got != want {
+				t.Errorf("message %q, want %q", got, want)
+			}
+			var details []proto.Message
+			for _, d := range st.Details() {
+				details = append(details, d.(proto.Message))
+			}
+			if diff := cmp.Diff(details, test.wantDetails, protocmp.Transform()); diff != "" {
+				t.Errorf("details diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
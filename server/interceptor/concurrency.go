@@ -0,0 +1,226 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/server/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// ConcurrencyLimits configures a ConcurrencyLimiter.
+type ConcurrencyLimits struct {
+	// Global is the number of RPCs of any method which may be in flight at
+	// once. Zero means no limit.
+	Global int
+	// PerMethod holds the numbers of RPCs of methods which may be in flight
+	// at once, by full name, such as "/trillian.TrillianLog/GetLeavesByRange",
+	// or by name alone, such as "GetLeavesByRange", which matches the method
+	// of any service, the RPCs of all of which count towards the limit.
+	// Methods not in it have no limit of their own.
+	PerMethod map[string]int
+	// QueueTimeout is how long RPCs over the limits wait for others to
+	// complete, or until their deadline if it's sooner, before failing. Zero
+	// means they fail at once.
+	QueueTimeout time.Duration
+}
+
+// ParseMethodLimits parses a comma-separated list of method=limit pairs, such
+// as "GetLeavesByRange=10,/trillian.TrillianLog/GetLeavesByIndex=20", into the
+// PerMethod field of ConcurrencyLimits.
+func ParseMethodLimits(s string) (map[string]int, error) {
+	perMethod := make(map[string]int)
+	if s == "" {
+		return perMethod, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("concurrency limits: %q is not a method=limit pair", pair)
+		}
+		method := strings.TrimSpace(parts[0])
+		if method == "" {
+			return nil, fmt.Errorf("concurrency limits: no method in %q", pair)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("concurrency limits: bad limit in %q: %v", pair, err)
+		}
+		if limit <= 0 {
+			return nil, fmt.Errorf("concurrency limits: limit in %q must be > 0", pair)
+		}
+		if _, ok := perMethod[method]; ok {
+			return nil, fmt.Errorf("concurrency limits: duplicate method %q", method)
+		}
+		perMethod[method] = limit
+	}
+	return perMethod, nil
+}
+
+// ConcurrencyLimiter limits the number of RPCs in flight, of each method and
+// in all, so that a flood of expensive RPCs, such as GetLeavesByRange, can't
+// take all of the database connections needed by others, such as
+// QueueLeaves. RPCs over the limits are queued until others complete, for up
+// to a timeout, and fail with a ResourceExhausted error after it.
+type ConcurrencyLimiter struct {
+	queueTimeout time.Duration
+	// global and perMethod are the semaphores of the limits, by the names
+	// of the methods in ConcurrencyLimits.PerMethod. A nil global semaphore
+	// means no global limit.
+	global    semaphore
+	perMethod map[string]semaphore
+
+	limited monitoring.Counter
+}
+
+// semaphore is a counting semaphore, holding a value for each RPC in flight.
+type semaphore chan struct{}
+
+// NewConcurrencyLimiter returns a ConcurrencyLimiter enforcing the given
+// limits, which exports its metrics to mf.
+func NewConcurrencyLimiter(limits ConcurrencyLimits, mf monitoring.MetricFactory) (*ConcurrencyLimiter, error) {
+	if limits.Global < 0 {
+		return nil, fmt.Errorf("concurrency limits: global limit must be >= 0, got %v", limits.Global)
+	}
+	if limits.QueueTimeout < 0 {
+		return nil, fmt.Errorf("concurrency limits: queue timeout must be >= 0, got %v", limits.QueueTimeout)
+	}
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	l := &ConcurrencyLimiter{
+		queueTimeout: limits.QueueTimeout,
+		perMethod:    make(map[string]semaphore),
+		limited:      mf.NewCounter("interceptor_concurrency_limited_count", "Number of RPCs which failed as too many others were in flight, by limit", "limit"),
+	}
+	if limits.Global > 0 {
+		l.global = make(semaphore, limits.Global)
+	}
+	for method, limit := range limits.PerMethod {
+		if limit <= 0 {
+			return nil, fmt.Errorf("concurrency limits: limit of %q must be > 0, got %v", method, limit)
+		}
+		l.perMethod[method] = make(semaphore, limit)
+	}
+	return l, nil
+}
+
+// methodLimit returns the name of the limit of the given method in
+// ConcurrencyLimits.PerMethod, and its semaphore, or nil if it has none.
+func (l *ConcurrencyLimiter) methodLimit(fullMethod string) (string, semaphore) {
+	if sem, ok := l.perMethod[fullMethod]; ok {
+		return fullMethod, sem
+	}
+	name := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	return name, l.perMethod[name]
+}
+
+// acquire waits for an RPC of the given method to be within the limits, and
+// returns a function releasing its place once it completes. It returns a
+// ResourceExhausted error if the queue timeout passes first, or the error of
+// ctx if it's done first.
+func (l *ConcurrencyLimiter) acquire(ctx context.Context, fullMethod string) (func(), error) {
+	var timeout <-chan time.Time
+	if l.queueTimeout > 0 {
+		timer := time.NewTimer(l.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	// The limit of the method is acquired first, so that RPCs of methods
+	// over their own limit don't hold places in the global one.
+	name, sem := l.methodLimit(fullMethod)
+	var held []semaphore
+	release := func() {
+		for _, sem := range held {
+			<-sem
+		}
+	}
+	for _, lim := range []struct {
+		name string
+		sem  semaphore
+	}{
+		{"in_flight/" + strings.TrimPrefix(name, "/"), sem},
+		{"in_flight/global", l.global},
+	} {
+		if lim.sem == nil {
+			continue
+		}
+		if err := lim.sem.acquire(ctx, timeout); err != nil {
+			release()
+			if err == errQueueTimeout {
+				l.limited.Inc(lim.name)
+				return nil, errors.RateLimited(lim.name, 0, "too many RPCs in flight for %v (limit %d)", lim.name, cap(lim.sem))
+			}
+			return nil, err
+		}
+		held = append(held, lim.sem)
+	}
+	return release, nil
+}
+
+// errQueueTimeout is returned by semaphore.acquire when the queue timeout
+// passes.
+var errQueueTimeout = fmt.Errorf("queue timeout")
+
+// acquire takes a place in s, waiting for one until timeout fires, or at
+// once if it's nil. It returns errQueueTimeout if timeout fires first, or
+// the error of ctx, as a gRPC status, if it's done first.
+func (s semaphore) acquire(ctx context.Context, timeout <-chan time.Time) error {
+	select {
+	case s <- struct{}{}:
+		return nil
+	default:
+	}
+	if timeout == nil {
+		return errQueueTimeout
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-timeout:
+		return errQueueTimeout
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// UnaryInterceptor limits the number of unary RPCs in flight.
+func (l *ConcurrencyLimiter) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	release, err := l.acquire(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return handler(ctx, req)
+}
+
+// StreamInterceptor limits the number of streaming RPCs in flight, which hold
+// their places for as long as they're open.
+func (l *ConcurrencyLimiter) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	release, err := l.acquire(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return handler(srv, ss)
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseMethodLimits(t *testing.T) {
+	for _, test := range []struct {
+		s       string
+		want    map[string]int
+		wantErr bool
+	}{
+		{s: "", want: map[string]int{}},
+		{
+			s:    "GetLeavesByRange=10, /trillian.TrillianLog/GetLeavesByIndex = 20",
+			want: map[string]int{"GetLeavesByRange": 10, "/trillian.TrillianLog/GetLeavesByIndex": 20},
+		},
+		{s: "GetLeavesByRange", wantErr: true},
+		{s: "=10", wantErr: true},
+		{s: "GetLeavesByRange=many", wantErr: true},
+		{s: "GetLeavesByRange=0", wantErr: true},
+		{s: "GetLeavesByRange=1,GetLeavesByRange=2", wantErr: true},
+	} {
+		got, err := ParseMethodLimits(test.s)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("ParseMethodLimits(%q): %v, wantErr %v", test.s, err, test.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(got, test.want); err == nil && diff != "" {
+			t.Errorf("ParseMethodLimits(%q) diff (-got +want):\n%s", test.s, diff)
+		}
+	}
+}
+
+func TestNewConcurrencyLimiterErrors(t *testing.T) {
+	for _, limits := range []ConcurrencyLimits{
+		{Global: -1},
+		{QueueTimeout: -time.Second},
+		{PerMethod: map[string]int{"GetLeavesByRange": 0}},
+	} {
+		if _, err := NewConcurrencyLimiter(limits, nil); err == nil {
+			t.Errorf("NewConcurrencyLimiter(%+v): no error, want one", limits)
+		}
+	}
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	l, err := NewConcurrencyLimiter(ConcurrencyLimits{
+		Global:       3,
+		PerMethod:    map[string]int{"GetLeavesByRange": 1, queueLeaves: 2},
+		QueueTimeout: 50 * time.Millisecond,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewConcurrencyLimiter(): %v", err)
+	}
+
+	// start starts an RPC of the given method, which runs until its done channel
+	// is closed, and returns once it's been admitted or failed.
+	type rpc struct {
+		done chan struct{}
+		err  chan error
+	}
+	start := func(ctx context.Context, method string) rpc {
+		r := rpc{done: make(chan struct{}), err: make(chan error, 1)}
+		admitted := make(chan struct{})
+		go func() {
+			_, err := l.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, interface{}) (interface{}, error) {
+				close(admitted)
+				<-r.done
+				return nil, nil
+			})
+			r.err <- err
+		}()
+		select {
+		case <-admitted:
+		case err := <-r.err:
+			r.err <- err
+		}
+		return r
+	}
+	wantCode := func(r rpc, method string, want codes.Code) {
+		t.Helper()
+		select {
+		case err := <-r.err:
+			if got := status.Code(err); got != want {
+				t.Errorf("%v: %v, want code %v", method, err, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%v: still running, want code %v", method, want)
+		}
+	}
+	wantRunning := func(r rpc, method string) {
+		t.Helper()
+		select {
+		case err := <-r.err:
+			t.Errorf("%v: completed with %v, want running", method, err)
+		default:
+		}
+	}
+
+	ctx := context.Background()
+	rangeRPC := start(ctx, getLeavesByRange)
+	wantRunning(rangeRPC, getLeavesByRange)
+	// The limit of GetLeavesByRange is shared by all services.
+	wantCode(start(ctx, "/trillian.TrillianMap/GetLeavesByRange"), getLeavesByRange, codes.ResourceExhausted)
+
+	queue1 := start(ctx, queueLeaves)
+	queue2 := start(ctx, queueLeaves)
+	wantRunning(queue1, queueLeaves)
+	wantRunning(queue2, queueLeaves)
+	wantCode(start(ctx, queueLeaves), queueLeaves, codes.ResourceExhausted)
+	// The global limit applies to methods without a limit of their own.
+	wantCode(start(ctx, createTree), createTree, codes.ResourceExhausted)
+
+	// RPCs queued until others complete are admitted.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(rangeRPC.done)
+	}()
+	proof := start(ctx, getInclusionProof)
+	wantRunning(proof, getInclusionProof)
+	wantCode(rangeRPC, getLeavesByRange, codes.OK)
+
+	// RPCs whose context is done stop queueing.
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	wantCode(start(cctx, createTree), createTree, codes.Canceled)
+
+	for _, r := range []rpc{queue1, queue2, proof} {
+		close(r.done)
+		wantCode(r, "rpc", codes.OK)
+	}
+	last := start(ctx, createTree)
+	close(last.done)
+	wantCode(last, createTree, codes.OK)
+}
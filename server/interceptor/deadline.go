@@ -0,0 +1,128 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Deadlines sets default deadlines on RPCs which arrive without one, so that
+// the storage operations run for them are bounded even if clients don't set
+// deadlines, and stuck queries can't pin server goroutines. RPCs which arrive
+// with a deadline keep it, whether it's shorter or longer than the default.
+type Deadlines struct {
+	// Default is the deadline of unary RPCs whose method isn't in PerMethod.
+	// Zero means none.
+	Default time.Duration
+	// PerMethod holds the deadlines of methods, by full name, such as
+	// "/trillian.TrillianLog/GetLeavesByRange", or by name alone, such as
+	// "GetLeavesByRange", which matches the method of any service. Unlike
+	// Default, they apply to streaming RPCs as well as unary ones. Zero means
+	// none.
+	PerMethod map[string]time.Duration
+}
+
+// ParseMethodDeadlines parses a comma-separated list of method=duration
+// pairs, such as "GetLeavesByRange=10s,/trillian.TrillianLog/QueueLeaves=1s",
+// into the PerMethod field of Deadlines.
+func ParseMethodDeadlines(s string) (map[string]time.Duration, error) {
+	perMethod := make(map[string]time.Duration)
+	if s == "" {
+		return perMethod, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("deadlines: %q is not a method=duration pair", pair)
+		}
+		method := strings.TrimSpace(parts[0])
+		if method == "" {
+			return nil, fmt.Errorf("deadlines: no method in %q", pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("deadlines: bad duration in %q: %v", pair, err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("deadlines: negative duration in %q", pair)
+		}
+		if _, ok := perMethod[method]; ok {
+			return nil, fmt.Errorf("deadlines: duplicate method %q", method)
+		}
+		perMethod[method] = d
+	}
+	return perMethod, nil
+}
+
+// timeout returns the default deadline of an RPC for the given method, or zero
+// if it has none.
+func (d Deadlines) timeout(fullMethod string, stream bool) time.Duration {
+	if t, ok := d.PerMethod[fullMethod]; ok {
+		return t
+	}
+	if t, ok := d.PerMethod[fullMethod[strings.LastIndex(fullMethod, "/")+1:]]; ok {
+		return t
+	}
+	if stream {
+		return 0
+	}
+	return d.Default
+}
+
+// withDeadline returns ctx with the default deadline of an RPC for the given
+// method, unless ctx already has a deadline, or the method has none.
+func (d Deadlines) withDeadline(ctx context.Context, fullMethod string, stream bool) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	t := d.timeout(fullMethod, stream)
+	if t <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, t)
+}
+
+// UnaryInterceptor sets the default deadline of unary RPCs.
+func (d Deadlines) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, cancel := d.withDeadline(ctx, info.FullMethod, false)
+	defer cancel()
+	return handler(ctx, req)
+}
+
+// StreamInterceptor sets the default deadline of streaming RPCs.
+func (d Deadlines) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, cancel := d.withDeadline(ss.Context(), info.FullMethod, true)
+	defer cancel()
+	if ctx == ss.Context() {
+		return handler(srv, ss)
+	}
+	return handler(srv, &deadlineStream{ServerStream: ss, ctx: ctx})
+}
+
+// deadlineStream is a grpc.ServerStream with a default deadline.
+type deadlineStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream, with its default deadline.
+func (s *deadlineStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+)
+
+func TestParseMethodDeadlines(t *testing.T) {
+	for _, test := range []struct {
+		s       string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{s: "", want: map[string]time.Duration{}},
+		{
+			s:    "GetLeavesByRange=10s, /trillian.TrillianLog/QueueLeaves = 1s",
+			want: map[string]time.Duration{"GetLeavesByRange": 10 * time.Second, "/trillian.TrillianLog/QueueLeaves": time.Second},
+		},
+		{s: "GetLeavesByRange", wantErr: true},
+		{s: "=10s", wantErr: true},
+		{s: "GetLeavesByRange=soon", wantErr: true},
+		{s: "GetLeavesByRange=-1s", wantErr: true},
+		{s: "GetLeavesByRange=1s,GetLeavesByRange=2s", wantErr: true},
+	} {
+		got, err := ParseMethodDeadlines(test.s)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("ParseMethodDeadlines(%q): %v, wantErr %v", test.s, err, test.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(got, test.want); err == nil && diff != "" {
+			t.Errorf("ParseMethodDeadlines(%q) diff (-got +want):\n%s", test.s, diff)
+		}
+	}
+}
+
+func TestDeadlines(t *testing.T) {
+	d := Deadlines{
+		Default: time.Minute,
+		PerMethod: map[string]time.Duration{
+			"GetLeavesByRange":                             time.Hour,
+			"/trillian.TrillianLog/QueueLeaves":            2 * time.Hour,
+			"/trillian.TrillianLog/GetLatestSignedLogRoot": 0,
+		},
+	}
+	short, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for _, test := range []struct {
+		desc   string
+		ctx    context.Context
+		method string
+		stream bool
+		// want is the deadline wanted, zero meaning none, and -1 that of ctx.
+		want time.Duration
+	}{
+		{desc: "default", method: "/trillian.TrillianLog/GetInclusionProof", want: time.Minute},
+		{desc: "short-name", method: "/trillian.TrillianMap/GetLeavesByRange", want: time.Hour},
+		{desc: "full-name", method: "/trillian.TrillianLog/QueueLeaves", want: 2 * time.Hour},
+		{desc: "none", method: "/trillian.TrillianLog/GetLatestSignedLogRoot"},
+		{desc: "caller", ctx: short, method: "/trillian.TrillianLog/GetInclusionProof", want: -1},
+		{desc: "stream-default", method: "/trillian.TrillianLog/StreamQueueLeaves", stream: true},
+		{desc: "stream-method", method: "/trillian.TrillianLog/GetLeavesByRange", stream: true, want: time.Hour},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			start := time.Now()
+			check := func(ctx context.Context) {
+				deadline, ok := ctx.Deadline()
+				switch {
+				case test.want == 0 && ok:
+					t.Errorf("deadline %v, want none", deadline)
+				case test.want == 0:
+				case test.want < 0:
+					if want, _ := test.ctx.Deadline(); deadline != want {
+						t.Errorf("deadline %v, want that of the caller %v", deadline, want)
+					}
+				case !ok:
+					t.Errorf("no deadline, want one in %v", test.want)
+				default:
+					if got := deadline.Sub(start); got < test.want || got > test.want+time.Second {
+						t.Errorf("deadline in %v, want %v", got, test.want)
+					}
+				}
+			}
+
+			if test.stream {
+				ss := &fakeServerStream{ctx: ctx}
+				err := d.StreamInterceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: test.method}, func(_ interface{}, stream grpc.ServerStream) error {
+					check(stream.Context())
+					return nil
+				})
+				if err != nil {
+					t.Errorf("StreamInterceptor(): %v", err)
+				}
+				return
+			}
+			_, err := d.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: test.method}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+				check(ctx)
+				return nil, nil
+			})
+			if err != nil {
+				t.Errorf("UnaryInterceptor(): %v", err)
+			}
+		})
+	}
+}
@@ -23,11 +23,13 @@ import (
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
 	"github.com/google/trillian"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/quota/etcd/quotapb"
 	"github.com/google/trillian/server/errors"
+	"github.com/google/trillian/server/maintenance"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
 	"google.golang.org/grpc"
@@ -38,6 +40,7 @@ import (
 const (
 	badInfoReason            = "bad_info"
 	badTreeReason            = "bad_tree"
+	maintenanceReason        = "maintenance"
 	insufficientTokensReason = "insufficient_tokens"
 	getTreeStage             = "get_tree"
 	getTokensStage           = "get_tokens"
@@ -53,6 +56,8 @@ var (
 	requestCounter       monitoring.Counter
 	requestDeniedCounter monitoring.Counter
 	contextErrCounter    monitoring.Counter
+	requestBytes         monitoring.Histogram
+	responseBytes        monitoring.Histogram
 	metricsOnce          sync.Once
 	enabledServices      = map[string]bool{
 		"trillian.TrillianLog":      true,
@@ -83,6 +88,7 @@ type RequestProcessor interface {
 
 // TrillianInterceptor checks that:
 // * Requests addressing a tree have the correct tree type and tree state;
+// * Write requests aren't made to a server or tree in maintenance;
 // * TODO(codingllama): Requests are properly authenticated / authorized ; and
 // * Requests are rate limited appropriately.
 type TrillianInterceptor struct {
@@ -92,6 +98,11 @@ type TrillianInterceptor struct {
 	// quotaDryRun controls whether lack of tokens actually blocks requests (if set to true, no
 	// requests are blocked by lack of tokens).
 	quotaDryRun bool
+
+	// Maintenance is the maintenance mode of the server. Write requests to
+	// trees are rejected while either the server or the tree is in
+	// maintenance. May be nil.
+	Maintenance *maintenance.Mode
 }
 
 // New returns a new TrillianInterceptor instance.
@@ -121,12 +132,31 @@ func initMetrics(mf monitoring.MetricFactory) {
 		"interceptor_context_err_counter",
 		"Total number of times request context has been cancelled or deadline exceeded by stage",
 		"stage")
+	// Buckets from 64B to 64MiB, doubling each time.
+	sizeBuckets := monitoring.ExpBuckets(64, 2, 21)
+	requestBytes = mf.NewHistogramWithBuckets(
+		"interceptor_request_bytes",
+		"Size of intercepted requests in bytes, by method and tree",
+		sizeBuckets,
+		"method", monitoring.TreeIDLabel)
+	responseBytes = mf.NewHistogramWithBuckets(
+		"interceptor_response_bytes",
+		"Size of successful responses to intercepted requests in bytes, by method and tree",
+		sizeBuckets,
+		"method", monitoring.TreeIDLabel)
 }
 
 func incRequestDeniedCounter(reason string, treeID int64, quotaUser string) {
 	requestDeniedCounter.Inc(reason, fmt.Sprint(treeID), quotaUser)
 }
 
+// observeSize records the serialized size of msg in h, if msg is a proto.
+func observeSize(h monitoring.Histogram, msg interface{}, method string, treeID int64) {
+	if m, ok := msg.(proto.Message); ok {
+		h.Observe(float64(proto.Size(m)), method, fmt.Sprint(treeID))
+	}
+}
+
 // UnaryInterceptor executes the TrillianInterceptor logic for unary RPCs.
 func (i *TrillianInterceptor) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	// Implement UnaryInterceptor using a RequestProcessor, so we
@@ -152,6 +182,8 @@ func (i *TrillianInterceptor) NewProcessor() RequestProcessor {
 type trillianProcessor struct {
 	parent *TrillianInterceptor
 	info   *rpcInfo
+	// serverStream is set if the request is that of a server-streaming RPC.
+	serverStream bool
 }
 
 func (tp *trillianProcessor) Before(ctx context.Context, req interface{}, method string) (context.Context, error) {
@@ -169,8 +201,13 @@ func (tp *trillianProcessor) Before(ctx context.Context, req interface{}, method
 		incRequestDeniedCounter(badInfoReason, 0, "")
 		return ctx, err
 	}
+	if tp.serverStream && info.tokens > 1 {
+		// Responses are charged as they're sent, see StreamInterceptor.
+		info.tokens = 1
+	}
 	tp.info = info
 	requestCounter.Inc(fmt.Sprint(info.treeID))
+	observeSize(requestBytes, req, method, info.treeID)
 
 	// TODO(codingllama): Add auth interception
 
@@ -185,20 +222,20 @@ func (tp *trillianProcessor) Before(ctx context.Context, req interface{}, method
 			contextErrCounter.Inc(getTreeStage)
 			return ctx, err
 		}
+		if !info.readonly {
+			if err := tp.parent.Maintenance.CheckWrite(tree); err != nil {
+				incRequestDeniedCounter(maintenanceReason, info.treeID, info.quotaUsers)
+				return ctx, err
+			}
+		}
 		ctx = trees.NewContext(ctx, tree)
 	}
 
 	if info.tokens > 0 && len(info.specs) > 0 {
-		err := tp.parent.qm.GetTokens(innerCtx, info.tokens, info.specs)
-		if err != nil {
-			if !tp.parent.quotaDryRun {
-				incRequestDeniedCounter(insufficientTokensReason, info.treeID, info.quotaUsers)
-				return ctx, status.Errorf(codes.ResourceExhausted, "quota exhausted: %v", err)
-			}
-			glog.Warningf("(quotaDryRun) Request %+v not denied due to dry run mode: %v", req, err)
+		if err := tp.parent.getTokens(innerCtx, info.tokens, info, req); err != nil {
+			return ctx, err
 		}
-		quota.Metrics.IncAcquired(info.tokens, info.specs, err == nil)
-		if err = innerCtx.Err(); err != nil {
+		if err := innerCtx.Err(); err != nil {
 			contextErrCounter.Inc(getTokensStage)
 			return ctx, err
 		}
@@ -207,18 +244,37 @@ func (tp *trillianProcessor) Before(ctx context.Context, req interface{}, method
 	return ctx, nil
 }
 
+// getTokens acquires tokens from the quota specs of info, on behalf of msg. It
+// returns a ResourceExhausted error, whose details say which quota ran out, if
+// there aren't enough tokens, unless running in dry run mode.
+func (i *TrillianInterceptor) getTokens(ctx context.Context, tokens int, info *rpcInfo, msg interface{}) error {
+	err := i.qm.GetTokens(ctx, tokens, info.specs)
+	if err != nil {
+		if !i.quotaDryRun {
+			incRequestDeniedCounter(insufficientTokensReason, info.treeID, info.quotaUsers)
+			return errors.QuotaExhausted(err, info.specs)
+		}
+		glog.Warningf("(quotaDryRun) Request %+v not denied due to dry run mode: %v", msg, err)
+	}
+	quota.Metrics.IncAcquired(tokens, info.specs, err == nil)
+	return nil
+}
+
 func (tp *trillianProcessor) After(ctx context.Context, resp interface{}, method string, handlerErr error) {
 	if !enabledServices[serviceName(method)] {
 		return
 	}
 	_, spanEnd := spanFor(ctx, "After")
 	defer spanEnd()
-	switch {
-	case tp.info == nil:
+	if tp.info == nil {
 		glog.Warningf("After called with nil rpcInfo, resp = [%+v], handlerErr = [%v]", resp, handlerErr)
 		return
-	case tp.info.tokens == 0:
-		// After() currently only does quota processing
+	}
+	if handlerErr == nil {
+		observeSize(responseBytes, resp, method, tp.info.treeID)
+	}
+	if tp.info.tokens == 0 {
+		// The rest of After() only does quota processing
 		return
 	}
 
@@ -369,18 +425,40 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 		info.getTree = false // Tree doesn't exist
 		info.readonly = false
 
+	// Admin create or update
+	case *trillian.ApplyTreeSpecRequest:
+		info.getTree = false // Tree may not exist
+		info.readonly = false
+
 	// Admin list
 	case *trillian.ListTreesRequest:
 		info.getTree = false // Zero to many trees
 
+	// Admin feature flags
+	case *trillian.SetFeatureFlagsRequest:
+		info.getTree = false // Zero to many trees, never read
+		info.readonly = false
+
+	// Admin mastership and maintenance
+	case *trillian.MoveMastershipRequest,
+		*trillian.SetMaintenanceModeRequest:
+		info.getTree = false // Zero to many trees, read within RPC handler
+		info.readonly = false
+
 	// Admin / readonly
-	case *trillian.GetTreeRequest:
+	case *trillian.GetTreeRequest,
+		*trillian.ListDeadLetterLeavesRequest,
+		*trillian.GetQuotaStateRequest,
+		*trillian.GetTreeStatsRequest,
+		*trillian.GetTreeUsageRequest:
 		info.getTree = false // Read done within RPC handler
 
 	// Admin / readwrite
 	case *trillian.DeleteTreeRequest,
 		*trillian.UndeleteTreeRequest,
-		*trillian.UpdateTreeRequest:
+		*trillian.UpdateTreeRequest,
+		*trillian.RequeueDeadLetterLeavesRequest,
+		*trillian.PurgeDeadLetterLeavesRequest:
 		info.getTree = false // Read-modify-write done within RPC handler
 		info.readonly = false
 
@@ -392,6 +470,9 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 		*trillian.GetLatestSignedLogRootRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
 		info.tokens = 1
+	case *trillian.GetInclusionProofsRequest:
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
+		info.tokens = len(req.GetLeafIndex())
 	case *trillian.GetLeavesByHashRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
 		info.tokens = len(req.GetLeafHash())
@@ -404,6 +485,12 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 		if c := req.GetCount(); c > 1 {
 			info.tokens = int(c)
 		}
+	case *trillian.StreamLeafHashesRequest:
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
+		info.tokens = 1
+	case *trillian.StreamLeavesRequest:
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
+		info.tokens = 1
 	case *trillian.GetSequencedLeafCountRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
 
@@ -443,11 +530,19 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 	case *trillian.GetMapLeavesByRevisionRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
 		info.tokens = len(req.GetIndex())
+	case *trillian.GetMapLeavesByRevisionsRequest:
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
+		info.tokens = len(req.GetIndex()) * len(req.GetRevision())
 	case *trillian.GetMapLeavesRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
 		info.tokens = len(req.GetIndex())
+	case *trillian.GetMapLeavesCompactRequest:
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
+		info.tokens = len(req.GetIndex())
 	case *trillian.GetSignedMapRootByRevisionRequest,
-		*trillian.GetSignedMapRootRequest:
+		*trillian.GetSignedMapRootRequest,
+		*trillian.GetMapRootSignaturesRequest,
+		*trillian.ListMapLeavesByKeyPrefixRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
 		info.tokens = 1
 
@@ -460,10 +555,18 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 		info.readonly = false
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
 		info.tokens = len(req.GetLeaves())
+	case *trillian.QueueMapMutationsRequest:
+		info.readonly = false
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
+		info.tokens = len(req.GetLeaves())
 	case *trillian.InitMapRequest:
 		info.readonly = false
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
 		info.tokens = 1
+	case *trillian.AddMapRootSignatureRequest:
+		info.readonly = false
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_MAP}
+		info.tokens = 1
 
 	default:
 		return nil, status.Errorf(codes.Internal, "newRPCInfo: unmapped request type: %T", req)
@@ -28,6 +28,7 @@ import (
 	"github.com/google/trillian"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/quota/etcd/quotapb"
+	"github.com/google/trillian/server/maintenance"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/testonly"
 	"github.com/google/trillian/trees"
@@ -191,6 +192,71 @@ func TestTrillianInterceptor_TreeInterception(t *testing.T) {
 	}
 }
 
+func TestTrillianInterceptor_Maintenance(t *testing.T) {
+	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	logTree.TreeId = 10
+	maintainedTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	maintainedTree.TreeId = 11
+	maintainedTree.Maintenance = &trillian.TreeMaintenance{Reason: "failover", StartTime: ptypes.TimestampNow()}
+
+	serverMode := &maintenance.Mode{}
+	serverMode.Set("schema migration")
+
+	write := func(treeID int64) *trillian.QueueLeafRequest {
+		return &trillian.QueueLeafRequest{LogId: treeID, Leaf: &trillian.LogLeaf{}}
+	}
+	read := func(treeID int64) *trillian.GetLatestSignedLogRootRequest {
+		return &trillian.GetLatestSignedLogRootRequest{LogId: treeID}
+	}
+	for _, test := range []struct {
+		desc    string
+		mode    *maintenance.Mode
+		method  string
+		req     interface{}
+		wantErr bool
+	}{
+		{desc: "noMode", method: "/trillian.TrillianLog/QueueLeaf", req: write(logTree.TreeId)},
+		{desc: "write", mode: &maintenance.Mode{}, method: "/trillian.TrillianLog/QueueLeaf", req: write(logTree.TreeId)},
+		{desc: "treeWrite", method: "/trillian.TrillianLog/QueueLeaf", req: write(maintainedTree.TreeId), wantErr: true},
+		{desc: "treeRead", method: "/trillian.TrillianLog/GetLatestSignedLogRoot", req: read(maintainedTree.TreeId)},
+		{desc: "serverWrite", mode: serverMode, method: "/trillian.TrillianLog/QueueLeaf", req: write(logTree.TreeId), wantErr: true},
+		{desc: "serverRead", mode: serverMode, method: "/trillian.TrillianLog/GetLatestSignedLogRoot", req: read(logTree.TreeId)},
+		{desc: "serverAdmin", mode: serverMode, method: "/trillian.TrillianAdmin/UpdateTree", req: &trillian.UpdateTreeRequest{Tree: logTree}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			admin := storage.NewMockAdminStorage(ctrl)
+			adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			admin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+			adminTX.EXPECT().GetTree(gomock.Any(), logTree.TreeId).AnyTimes().Return(logTree, nil)
+			adminTX.EXPECT().GetTree(gomock.Any(), maintainedTree.TreeId).AnyTimes().Return(maintainedTree, nil)
+			adminTX.EXPECT().Close().AnyTimes().Return(nil)
+			adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+
+			intercept := New(admin, quota.Noop(), false /* quotaDryRun */, nil /* mf */)
+			intercept.Maintenance = test.mode
+			handler := &fakeHandler{resp: "handler response"}
+			_, err := intercept.UnaryInterceptor(context.Background(), test.req, &grpc.UnaryServerInfo{FullMethod: test.method}, handler.run)
+			if test.wantErr {
+				if got := status.Code(err); got != codes.FailedPrecondition {
+					t.Errorf("UnaryInterceptor(): %v, want code %v", err, codes.FailedPrecondition)
+				}
+				if handler.called {
+					t.Error("handler called")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("UnaryInterceptor(): %v", err)
+			}
+			if !handler.called {
+				t.Error("handler not called")
+			}
+		})
+	}
+}
+
 func TestTrillianInterceptor_QuotaInterception(t *testing.T) {
 	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
 	logTree.TreeId = 10
@@ -234,6 +300,16 @@ func TestTrillianInterceptor_QuotaInterception(t *testing.T) {
 			},
 			wantTokens: 3,
 		},
+		{
+			desc:   "logReadProofs",
+			method: "/trillian.TrillianLog/GetInclusionProofs",
+			req:    &trillian.GetInclusionProofsRequest{LogId: logTree.TreeId, LeafIndex: []int64{1, 2}, TreeSize: 3},
+			specs: []quota.Spec{
+				{Group: quota.Tree, Kind: quota.Read, TreeID: logTree.TreeId},
+				{Group: quota.Global, Kind: quota.Read, Refundable: true},
+			},
+			wantTokens: 2,
+		},
 		{
 			desc:   "logReadRange",
 			method: "/trillian.TrillianLog/GetLeavesByRange",
@@ -308,6 +384,40 @@ func TestTrillianInterceptor_QuotaInterception(t *testing.T) {
 			},
 			wantTokens: 2,
 		},
+		{
+			desc:   "mapReadByRevisions",
+			method: "/trillian.TrillianMap/GetLeavesByRevisions",
+			req: &trillian.GetMapLeavesByRevisionsRequest{
+				MapId:    mapTree.TreeId,
+				Index:    [][]byte{{0x01}, {0x02}},
+				Revision: []int64{1, 2, 3},
+			},
+			specs: []quota.Spec{
+				{Group: quota.Tree, Kind: quota.Read, TreeID: mapTree.TreeId},
+				{Group: quota.Global, Kind: quota.Read, Refundable: true},
+			},
+			wantTokens: 6,
+		},
+		{
+			desc:   "mapReadCompact",
+			method: "/trillian.TrillianMap/GetLeavesCompact",
+			req:    &trillian.GetMapLeavesCompactRequest{MapId: mapTree.TreeId, Index: [][]byte{{0x01}, {0x02}, {0x03}}},
+			specs: []quota.Spec{
+				{Group: quota.Tree, Kind: quota.Read, TreeID: mapTree.TreeId},
+				{Group: quota.Global, Kind: quota.Read, Refundable: true},
+			},
+			wantTokens: 3,
+		},
+		{
+			desc:   "mapListByKeyPrefix",
+			method: "/trillian.TrillianMap/ListLeavesByKeyPrefix",
+			req:    &trillian.ListMapLeavesByKeyPrefixRequest{MapId: mapTree.TreeId, KeyPrefix: []byte("users/"), PageSize: 100},
+			specs: []quota.Spec{
+				{Group: quota.Tree, Kind: quota.Read, TreeID: mapTree.TreeId},
+				{Group: quota.Global, Kind: quota.Read, Refundable: true},
+			},
+			wantTokens: 1,
+		},
 		{
 			desc:   "emptyBatchRequest",
 			method: "/trillian.TrillianLog/QueueLeaves",
@@ -384,6 +494,29 @@ func TestTrillianInterceptor_QuotaInterception(t *testing.T) {
 			},
 			wantTokens: 5,
 		},
+		{
+			desc:   "queueMapMutationsRequest",
+			method: "/trillian.TrillianMapWrite/QueueMapMutations",
+			req: &trillian.QueueMapMutationsRequest{
+				MapId:  mapTree.TreeId,
+				Leaves: []*trillian.MapLeaf{{}, {}, {}},
+			},
+			specs: []quota.Spec{
+				{Group: quota.Tree, Kind: quota.Write, TreeID: mapTree.TreeId},
+				{Group: quota.Global, Kind: quota.Write, Refundable: true},
+			},
+			wantTokens: 3,
+		},
+		{
+			desc:   "addMapRootSignatureRequest",
+			method: "/trillian.TrillianMap/AddMapRootSignature",
+			req:    &trillian.AddMapRootSignatureRequest{MapId: mapTree.TreeId, Revision: 1},
+			specs: []quota.Spec{
+				{Group: quota.Tree, Kind: quota.Write, TreeID: mapTree.TreeId},
+				{Group: quota.Global, Kind: quota.Write, Refundable: true},
+			},
+			wantTokens: 1,
+		},
 		{
 			desc:   "quotaError",
 			method: "/trillian.TrillianLog/GetLatestSignedLogRoot",
@@ -621,7 +754,11 @@ func TestTrillianInterceptor_NotIntercepted(t *testing.T) {
 	}{
 		// Admin
 		{method: "/trillian.TrillianAdmin/CreateTree", req: &trillian.CreateTreeRequest{}},
+		{method: "/trillian.TrillianAdmin/ApplyTreeSpec", req: &trillian.ApplyTreeSpecRequest{}},
 		{method: "/trillian.TrillianAdmin/ListTrees", req: &trillian.ListTreesRequest{}},
+		{method: "/trillian.TrillianAdmin/MoveMastership", req: &trillian.MoveMastershipRequest{}},
+		{method: "/trillian.TrillianAdmin/SetMaintenanceMode", req: &trillian.SetMaintenanceModeRequest{}},
+		{method: "/trillian.TrillianAdmin/SetFeatureFlags", req: &trillian.SetFeatureFlagsRequest{}},
 		// Quota
 		{method: "/quotapb.Quota/CreateConfig", req: &quotapb.CreateConfigRequest{}},
 		{method: "/quotapb.Quota/DeleteConfig", req: &quotapb.DeleteConfigRequest{}},
@@ -701,6 +838,78 @@ func TestTrillianInterceptor_BeforeAfter(t *testing.T) {
 	}
 }
 
+func TestTrillianInterceptor_SizeMetrics(t *testing.T) {
+	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	logTree.TreeId = 20
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	admin := storage.NewMockAdminStorage(ctrl)
+	adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+	admin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+	adminTX.EXPECT().GetTree(gomock.Any(), logTree.TreeId).AnyTimes().Return(logTree, nil)
+	adminTX.EXPECT().Close().AnyTimes().Return(nil)
+	adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+
+	const method = "/trillian.TrillianLog/GetLeavesByRange"
+	treeLabel := fmt.Sprint(logTree.TreeId)
+	req := &trillian.GetLeavesByRangeRequest{LogId: logTree.TreeId, StartIndex: 1, Count: 2}
+	resp := &trillian.GetLeavesByRangeResponse{Leaves: []*trillian.LogLeaf{
+		{LeafValue: []byte("leaf 1")},
+		{LeafValue: []byte("leaf 2")},
+	}}
+
+	tests := []struct {
+		desc                        string
+		handlerErr                  error
+		wantReqCount, wantRespCount uint64
+		wantReqBytes, wantRespBytes float64
+	}{
+		{
+			desc:          "success",
+			wantReqCount:  1,
+			wantReqBytes:  float64(proto.Size(req)),
+			wantRespCount: 1,
+			wantRespBytes: float64(proto.Size(resp)),
+		},
+		{
+			desc:         "handlerErr",
+			handlerErr:   errors.New("bad request"),
+			wantReqCount: 1,
+			wantReqBytes: float64(proto.Size(req)),
+		},
+	}
+
+	ctx := context.Background()
+	intercept := New(admin, quota.Noop(), false /* quotaDryRun */, nil /* mf */)
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			reqCount, reqBytes := requestBytes.Info(method, treeLabel)
+			respCount, respBytes := responseBytes.Info(method, treeLabel)
+
+			handler := &fakeHandler{resp: resp, err: test.handlerErr}
+			if _, err := intercept.UnaryInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, handler.run); err != test.handlerErr {
+				t.Fatalf("UnaryInterceptor() returned err = %v, want %v", err, test.handlerErr)
+			}
+
+			gotCount, gotBytes := requestBytes.Info(method, treeLabel)
+			if got, want := gotCount-reqCount, test.wantReqCount; got != want {
+				t.Errorf("request count delta = %v, want %v", got, want)
+			}
+			if got, want := gotBytes-reqBytes, test.wantReqBytes; got != want {
+				t.Errorf("request bytes delta = %v, want %v", got, want)
+			}
+			gotCount, gotBytes = responseBytes.Info(method, treeLabel)
+			if got, want := gotCount-respCount, test.wantRespCount; got != want {
+				t.Errorf("response count delta = %v, want %v", got, want)
+			}
+			if got, want := gotBytes-respBytes, test.wantRespBytes; got != want {
+				t.Errorf("response bytes delta = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
 func TestCombine(t *testing.T) {
 	i1 := &fakeInterceptor{key: "key1", val: "foo"}
 	i2 := &fakeInterceptor{key: "key2", val: "bar"}
@@ -0,0 +1,271 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/server/errors"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc"
+)
+
+// Defaults of LoadSheddingOptions.
+const (
+	DefaultLoadSheddingPercentile = 0.99
+	DefaultLoadSheddingInterval   = time.Second
+	DefaultLoadSheddingMinSamples = 10
+
+	// maxLoadSheddingSamples is the number of latencies kept per interval,
+	// beyond which new ones replace old ones.
+	maxLoadSheddingSamples = 10000
+)
+
+// priority is the priority of an RPC for load shedding. RPCs of the lowest
+// priorities are shed first.
+type priority int
+
+const (
+	// priorityNone is lower than that of any RPC, for shedding none.
+	priorityNone priority = iota
+	// priorityBulkRead is that of reads of leaves, which may be large.
+	priorityBulkRead
+	// priorityRead is that of reads of roots and proofs.
+	priorityRead
+	// priorityCritical is that of writes, and of RPCs which are never shed.
+	priorityCritical
+)
+
+func (p priority) String() string {
+	switch p {
+	case priorityNone:
+		return "none"
+	case priorityBulkRead:
+		return "bulk_read"
+	case priorityRead:
+		return "read"
+	}
+	return "critical"
+}
+
+// readPriorities holds the priorities of the read methods of the Trillian
+// services, by name. Methods not in it are critical.
+var readPriorities = map[string]priority{
+	// TrillianLog.
+	"GetEntryAndProof":        priorityBulkRead,
+	"GetInclusionProofs":      priorityBulkRead,
+	"GetLeavesByHash":         priorityBulkRead,
+	"GetLeavesByIndex":        priorityBulkRead,
+	"GetLeavesByRange":        priorityBulkRead,
+	"StreamLeaves":            priorityBulkRead,
+	"GetConsistencyProof":     priorityRead,
+	"GetInclusionProof":       priorityRead,
+	"GetInclusionProofByHash": priorityRead,
+	"GetLatestSignedLogRoot":  priorityRead,
+	"GetSequencedLeafCount":   priorityRead,
+	// TrillianMap and TrillianMapWrite.
+	"GetLastInRangeByRevision":   priorityBulkRead,
+	"GetLeaves":                  priorityBulkRead,
+	"GetLeavesByRevision":        priorityBulkRead,
+	"GetLeavesByRevisionNoProof": priorityBulkRead,
+	"GetLeavesByRevisions":       priorityBulkRead,
+	"GetLeavesCompact":           priorityBulkRead,
+	"GetLeaf":                    priorityRead,
+	"GetLeafByRevision":          priorityRead,
+	"GetSignedMapRoot":           priorityRead,
+	"GetSignedMapRootByRevision": priorityRead,
+}
+
+// methodPriority returns the priority of RPCs of the given method.
+func methodPriority(fullMethod string) priority {
+	if !enabledServices[serviceName(fullMethod)] {
+		return priorityCritical
+	}
+	if p, ok := readPriorities[fullMethod[strings.LastIndex(fullMethod, "/")+1:]]; ok {
+		return p
+	}
+	return priorityCritical
+}
+
+// LoadSheddingOptions configures a LoadShedder.
+type LoadSheddingOptions struct {
+	// Latency is the latency which the Percentile latency of RPCs is kept
+	// under by shedding reads.
+	Latency time.Duration
+	// Percentile is the percentile of the latency of RPCs compared to
+	// Latency, from 0 to 1. Zero means DefaultLoadSheddingPercentile.
+	Percentile float64
+	// Interval is how often the latency of RPCs is compared to Latency, over
+	// the RPCs which completed since. Zero means DefaultLoadSheddingInterval.
+	Interval time.Duration
+	// MinSamples is the number of RPCs which must complete in an interval
+	// for their latency to be compared. Zero means
+	// DefaultLoadSheddingMinSamples.
+	MinSamples int
+}
+
+// LoadShedder is an admission controller which sheds read RPCs while the
+// latency of RPCs is over a target, protecting the writes and sequencing
+// which share the storage backend with them.
+//
+// Every interval, the latency percentile of the RPCs which completed during
+// it, which is dominated by that of storage, is compared to the target. Each
+// interval over it sheds the RPCs of one more priority, starting with bulk
+// reads of leaves, then reads of roots and proofs, and each interval under it,
+// or with too few RPCs to tell, admits those of one more priority again.
+// Writes and admin RPCs are never shed.
+//
+// Shed RPCs fail with a ResourceExhausted error whose details say to retry
+// after the interval.
+type LoadShedder struct {
+	opts LoadSheddingOptions
+	ts   clock.TimeSource
+
+	shed  monitoring.Counter
+	level monitoring.Gauge
+
+	mu sync.Mutex
+	// samples holds the latencies of up to maxLoadSheddingSamples of the
+	// RPCs which completed in the current interval, of which there were
+	// completed in all.
+	samples   []time.Duration
+	completed int
+	// start is the start of the current interval.
+	start time.Time
+	// shedding is the highest priority of the RPCs shed, and latency the
+	// percentile latency of the last interval compared to the target.
+	shedding priority
+	latency  time.Duration
+}
+
+// NewLoadShedder returns a LoadShedder with the given options, which exports
+// its metrics to mf.
+func NewLoadShedder(opts LoadSheddingOptions, mf monitoring.MetricFactory, ts clock.TimeSource) (*LoadShedder, error) {
+	if opts.Latency <= 0 {
+		return nil, fmt.Errorf("load shedding: latency must be > 0, got %v", opts.Latency)
+	}
+	if opts.Percentile < 0 || opts.Percentile > 1 {
+		return nil, fmt.Errorf("load shedding: percentile must be in [0, 1], got %v", opts.Percentile)
+	}
+	if opts.Interval < 0 {
+		return nil, fmt.Errorf("load shedding: interval must be >= 0, got %v", opts.Interval)
+	}
+	if opts.MinSamples < 0 {
+		return nil, fmt.Errorf("load shedding: min samples must be >= 0, got %v", opts.MinSamples)
+	}
+	if opts.Percentile == 0 {
+		opts.Percentile = DefaultLoadSheddingPercentile
+	}
+	if opts.Interval == 0 {
+		opts.Interval = DefaultLoadSheddingInterval
+	}
+	if opts.MinSamples == 0 {
+		opts.MinSamples = DefaultLoadSheddingMinSamples
+	}
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &LoadShedder{
+		opts:  opts,
+		ts:    ts,
+		shed:  mf.NewCounter("interceptor_load_shed_count", "Number of RPCs shed while the server is overloaded, by method", "method"),
+		level: mf.NewGauge("interceptor_load_shedding_priority", "Highest priority of the RPCs being shed: 0 for none, 1 for bulk reads and 2 for all reads"),
+		start: ts.Now(),
+	}, nil
+}
+
+// admit returns a ResourceExhausted error if RPCs of the given method are
+// being shed.
+func (l *LoadShedder) admit(fullMethod string) error {
+	p := methodPriority(fullMethod)
+	l.mu.Lock()
+	l.maybeEvaluate(l.ts.Now())
+	shedding, latency := l.shedding, l.latency
+	l.mu.Unlock()
+	if p > shedding {
+		return nil
+	}
+	l.shed.Inc(fullMethod)
+	return errors.Overloaded(p.String(), l.opts.Interval,
+		"server overloaded: %v RPCs shed while the p%v latency is %v, over %v",
+		p, l.opts.Percentile*100, latency, l.opts.Latency)
+}
+
+// observe records the latency of an RPC which completed.
+func (l *LoadShedder) observe(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.samples) < maxLoadSheddingSamples {
+		l.samples = append(l.samples, latency)
+	} else {
+		l.samples[l.completed%maxLoadSheddingSamples] = latency
+	}
+	l.completed++
+}
+
+// maybeEvaluate compares the latency of the RPCs which completed during the
+// current interval to the target, if it has ended by now, and starts another.
+// It must be called with mu held.
+func (l *LoadShedder) maybeEvaluate(now time.Time) {
+	if now.Sub(l.start) < l.opts.Interval {
+		return
+	}
+	if len(l.samples) >= l.opts.MinSamples {
+		sort.Slice(l.samples, func(i, j int) bool { return l.samples[i] < l.samples[j] })
+		i := int(float64(len(l.samples)) * l.opts.Percentile)
+		if i >= len(l.samples) {
+			i = len(l.samples) - 1
+		}
+		l.latency = l.samples[i]
+	} else {
+		l.latency = 0
+	}
+	switch {
+	case l.latency > l.opts.Latency && l.shedding < priorityRead:
+		l.shedding++
+	case l.latency <= l.opts.Latency && l.shedding > priorityNone:
+		l.shedding--
+	}
+	l.level.Set(float64(l.shedding))
+	l.samples = l.samples[:0]
+	l.completed = 0
+	l.start = now
+}
+
+// UnaryInterceptor sheds unary RPCs, and records the latency of those which
+// it admits.
+func (l *LoadShedder) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := l.admit(info.FullMethod); err != nil {
+		return nil, err
+	}
+	start := l.ts.Now()
+	defer func() { l.observe(l.ts.Now().Sub(start)) }()
+	return handler(ctx, req)
+}
+
+// StreamInterceptor sheds streaming RPCs as they start. Their latency isn't
+// recorded, as it depends on how long clients keep them open.
+func (l *LoadShedder) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := l.admit(info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/server/errors"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	getLeavesByRange  = "/trillian.TrillianLog/GetLeavesByRange"
+	getInclusionProof = "/trillian.TrillianLog/GetInclusionProof"
+	queueLeaves       = "/trillian.TrillianLog/QueueLeaves"
+	createTree        = "/trillian.TrillianAdmin/CreateTree"
+)
+
+func TestMethodPriority(t *testing.T) {
+	for _, test := range []struct {
+		method string
+		want   priority
+	}{
+		{method: getLeavesByRange, want: priorityBulkRead},
+		{method: "/trillian.TrillianMap/GetLeaves", want: priorityBulkRead},
+		{method: getInclusionProof, want: priorityRead},
+		{method: "/trillian.TrillianMap/GetSignedMapRoot", want: priorityRead},
+		{method: queueLeaves, want: priorityCritical},
+		{method: "/trillian.TrillianMap/SetLeaves", want: priorityCritical},
+		{method: createTree, want: priorityCritical},
+		{method: "/grpc.health.v1.Health/GetLeaves", want: priorityCritical},
+	} {
+		if got := methodPriority(test.method); got != test.want {
+			t.Errorf("methodPriority(%q): %v, want %v", test.method, got, test.want)
+		}
+	}
+}
+
+func TestNewLoadShedderErrors(t *testing.T) {
+	ts := clock.NewFake(time.Unix(0, 0))
+	for _, opts := range []LoadSheddingOptions{
+		{},
+		{Latency: -time.Second},
+		{Latency: time.Second, Percentile: 1.5},
+		{Latency: time.Second, Percentile: -0.5},
+		{Latency: time.Second, Interval: -time.Second},
+		{Latency: time.Second, MinSamples: -1},
+	} {
+		if _, err := NewLoadShedder(opts, nil, ts); err == nil {
+			t.Errorf("NewLoadShedder(%+v): no error, want one", opts)
+		}
+	}
+}
+
+func TestLoadShedder(t *testing.T) {
+	ts := clock.NewFake(time.Unix(1000, 0))
+	l, err := NewLoadShedder(LoadSheddingOptions{Latency: 100 * time.Millisecond, Percentile: 0.5, Interval: 10 * time.Second, MinSamples: 2}, nil, ts)
+	if err != nil {
+		t.Fatalf("NewLoadShedder(): %v", err)
+	}
+
+	// call runs an RPC of the given method taking the given latency, and
+	// returns whether it was admitted.
+	call := func(method string, latency time.Duration) bool {
+		t.Helper()
+		_, err := l.UnaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, interface{}) (interface{}, error) {
+			ts.Advance(latency)
+			return nil, nil
+		})
+		if err == nil {
+			return true
+		}
+		st := status.Convert(err)
+		if st.Code() != codes.ResourceExhausted {
+			t.Errorf("%v: %v, want ResourceExhausted", method, err)
+		}
+		var info *errdetails.ErrorInfo
+		var retry *errdetails.RetryInfo
+		for _, d := range st.Details() {
+			switch d := d.(type) {
+			case *errdetails.ErrorInfo:
+				info = d
+			case *errdetails.RetryInfo:
+				retry = d
+			}
+		}
+		if info.GetReason() != errors.ReasonOverloaded {
+			t.Errorf("%v: ErrorInfo %v, want reason %v", method, info, errors.ReasonOverloaded)
+		}
+		if got, want := retry.GetRetryDelay().AsDuration(), 10*time.Second; got != want {
+			t.Errorf("%v: retry delay %v, want %v", method, got, want)
+		}
+		return false
+	}
+	// interval runs RPCs taking the given latency for the rest of the
+	// current interval.
+	interval := func(latency time.Duration, rpcs int) {
+		t.Helper()
+		for i := 0; i < rpcs; i++ {
+			if !call(queueLeaves, latency) {
+				t.Fatalf("%v shed, want admitted", queueLeaves)
+			}
+		}
+		ts.Set(l.start.Add(10 * time.Second))
+	}
+
+	for _, step := range []struct {
+		desc    string
+		latency time.Duration
+		rpcs    int
+		// admitted holds whether RPCs of the methods are admitted after
+		// the interval. The RPCs admitted are recorded in the next, so
+		// there are enough others to outweigh them.
+		admitted map[string]bool
+	}{
+		{
+			desc:     "fast",
+			latency:  10 * time.Millisecond,
+			rpcs:     10,
+			admitted: map[string]bool{getLeavesByRange: true, getInclusionProof: true, queueLeaves: true, createTree: true},
+		},
+		{
+			desc:     "slow",
+			latency:  200 * time.Millisecond,
+			rpcs:     10,
+			admitted: map[string]bool{getLeavesByRange: false, getInclusionProof: true, queueLeaves: true, createTree: true},
+		},
+		{
+			desc:     "still-slow",
+			latency:  200 * time.Millisecond,
+			rpcs:     10,
+			admitted: map[string]bool{getLeavesByRange: false, getInclusionProof: false, queueLeaves: true, createTree: true},
+		},
+		{
+			desc:     "slowest",
+			latency:  300 * time.Millisecond,
+			rpcs:     10,
+			admitted: map[string]bool{getLeavesByRange: false, getInclusionProof: false, queueLeaves: true, createTree: true},
+		},
+		{
+			desc:     "recovering",
+			latency:  10 * time.Millisecond,
+			rpcs:     10,
+			admitted: map[string]bool{getLeavesByRange: false, getInclusionProof: true, queueLeaves: true, createTree: true},
+		},
+		{
+			desc:     "idle",
+			admitted: map[string]bool{getLeavesByRange: true, getInclusionProof: true, queueLeaves: true, createTree: true},
+		},
+	} {
+		interval(step.latency, step.rpcs)
+		for method, want := range step.admitted {
+			if got := call(method, 0); got != want {
+				t.Errorf("%v: %v admitted: %v, want %v", step.desc, method, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/util/requestid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestID is a grpc.UnaryServerInterceptor which associates every request
+// with a request ID, available to the handler through requestid.FromContext.
+// A valid ID supplied by the client in the requestid.MetadataKey metadata is
+// honored, otherwise a new one is generated.
+// The ID is returned in the response header, and attached to errors as a
+// RequestInfo detail.
+func RequestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	id := incomingRequestID(ctx)
+	if id == "" {
+		id = requestid.New()
+	}
+	ctx = requestid.NewContext(ctx, id)
+	// SetHeader only fails if there's no stream to set the header on, e.g. when
+	// called directly in tests.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestid.MetadataKey, id))
+
+	rsp, err := handler(ctx, req)
+	if err != nil {
+		var method string
+		if info != nil {
+			method = info.FullMethod
+		}
+		glog.V(1).Infof("%s%s failed: %v", requestid.Prefix(ctx), method, err)
+		err = withRequestInfo(err, id)
+	}
+	return rsp, err
+}
+
+// incomingRequestID returns the request ID supplied by the client, or an empty
+// string if there isn't a valid one.
+func incomingRequestID(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, id := range md.Get(requestid.MetadataKey) {
+		if requestid.IsValid(id) {
+			return id
+		}
+	}
+	return ""
+}
+
+// withRequestInfo returns err with a RequestInfo detail naming the request ID.
+func withRequestInfo(err error, id string) error {
+	st, derr := status.Convert(err).WithDetails(&errdetails.RequestInfo{RequestId: id})
+	if derr != nil {
+		return err
+	}
+	return st.Err()
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian/util/requestid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequestID(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/trillian.TrillianLog/QueueLeaf"}
+	tests := []struct {
+		desc     string
+		incoming []string
+		err      error
+		wantID   string // Empty means a generated ID.
+		wantCode codes.Code
+	}{
+		{desc: "generated"},
+		{desc: "honored", incoming: []string{"client-id-1"}, wantID: "client-id-1"},
+		{desc: "invalidIgnored", incoming: []string{"bad id\n"}},
+		{desc: "firstValid", incoming: []string{"bad id", "good-id"}, wantID: "good-id"},
+		{
+			desc:     "statusError",
+			incoming: []string{"client-id-2"},
+			err:      status.Error(codes.NotFound, "no such tree"),
+			wantID:   "client-id-2",
+			wantCode: codes.NotFound,
+		},
+		{
+			desc:     "plainError",
+			err:      errors.New("storage failure"),
+			wantCode: codes.Unknown,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			if len(test.incoming) > 0 {
+				md := metadata.MD{}
+				md.Append(requestid.MetadataKey, test.incoming...)
+				ctx = metadata.NewIncomingContext(ctx, md)
+			}
+
+			var gotID string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				gotID = requestid.FromContext(ctx)
+				return "response", test.err
+			}
+			rsp, err := RequestID(ctx, "request", info, handler)
+
+			if test.wantID != "" && gotID != test.wantID {
+				t.Errorf("handler saw request ID %q, want %q", gotID, test.wantID)
+			}
+			if !requestid.IsValid(gotID) {
+				t.Errorf("handler saw invalid request ID %q", gotID)
+			}
+			if rsp != "response" {
+				t.Errorf("RequestID() returned response %v, want the handler's", rsp)
+			}
+			if test.err == nil {
+				if err != nil {
+					t.Errorf("RequestID() returned err = %v, want nil", err)
+				}
+				return
+			}
+
+			st := status.Convert(err)
+			if st.Code() != test.wantCode || st.Message() != status.Convert(test.err).Message() {
+				t.Errorf("RequestID() returned err = %v, want code %v and the handler's message", err, test.wantCode)
+			}
+			want := &errdetails.RequestInfo{RequestId: gotID}
+			if details := st.Details(); len(details) != 1 || !proto.Equal(details[0].(proto.Message), want) {
+				t.Errorf("RequestID() error details = %v, want [%v]", details, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+)
+
+// StreamInterceptor executes the TrillianInterceptor logic for streaming RPCs.
+//
+// Every message received from the client is intercepted as the request of a
+// unary RPC would be, and is charged the same quota. All of the messages of a
+// stream must address the same tree. For server-streaming RPCs, where the
+// number of results isn't known up front, requests are charged a single token
+// and each response is charged by the number of results it carries, before it
+// is sent. Streams are throttled as soon as tokens run out: the RecvMsg or
+// SendMsg call which needed them fails with ResourceExhausted, which ends the
+// RPC.
+//
+// Tokens charged for streamed messages are never refunded.
+func (i *TrillianInterceptor) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !enabledServices[serviceName(info.FullMethod)] {
+		return handler(srv, ss)
+	}
+	return handler(srv, &quotaStream{
+		ServerStream: ss,
+		parent:       i,
+		method:       info.FullMethod,
+		serverStream: info.IsServerStream,
+		ctx:          ss.Context(),
+	})
+}
+
+// quotaStream is a grpc.ServerStream which intercepts the messages received
+// and sent by a streaming RPC.
+type quotaStream struct {
+	grpc.ServerStream
+	parent       *TrillianInterceptor
+	method       string
+	serverStream bool
+
+	// mu guards ctx and info, as messages may be received and sent from
+	// different goroutines.
+	mu  sync.Mutex
+	ctx context.Context
+	// info describes the most recently received request.
+	info *rpcInfo
+}
+
+// Context returns the context of the stream, which holds the tree addressed
+// by the stream once its first request is received.
+func (s *quotaStream) Context() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx
+}
+
+// RecvMsg receives a request, and intercepts it.
+func (s *quotaStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	tp := &trillianProcessor{parent: s.parent, serverStream: s.serverStream}
+	ctx, err := tp.Before(s.Context(), m, s.method)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+	s.info = tp.info
+	return nil
+}
+
+// SendMsg sends a response, after charging for it if the RPC is
+// server-streaming.
+func (s *quotaStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	ctx, info := s.ctx, s.info
+	s.mu.Unlock()
+	if info == nil {
+		// Nothing was received, so there's nothing to charge against.
+		return s.ServerStream.SendMsg(m)
+	}
+
+	if s.serverStream && info.tokens > 0 && len(info.specs) > 0 {
+		innerCtx, spanEnd := spanFor(ctx, "SendMsg")
+		err := s.parent.getTokens(innerCtx, responseTokens(m), info, m)
+		spanEnd()
+		if err != nil {
+			return err
+		}
+	}
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	observeSize(responseBytes, m, s.method, info.treeID)
+	return nil
+}
+
+// responseTokens returns the number of tokens charged for a streamed
+// response, which is the number of results it carries, and at least one.
+func responseTokens(resp interface{}) int {
+	n := 0
+	switch resp := resp.(type) {
+	case *trillian.GetLeavesByRangeResponse:
+		n = len(resp.GetLeaves())
+	case *trillian.GetLeavesByIndexResponse:
+		n = len(resp.GetLeaves())
+	case *trillian.GetLeavesByHashResponse:
+		n = len(resp.GetLeaves())
+	case *trillian.StreamLeafHashesResponse:
+		n = len(resp.GetLeafHashes())
+	case *trillian.StreamLeavesResponse:
+		n = len(resp.GetLeaves())
+	case *trillian.GetMapLeavesResponse:
+		n = len(resp.GetMapLeafInclusion())
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
@@ -0,0 +1,262 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/trees"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeServerStream is a grpc.ServerStream which receives a fixed list of
+// requests, and records the responses sent.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs []proto.Message
+	sent []interface{}
+}
+
+func (f *fakeServerStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeServerStream) RecvMsg(m interface{}) error {
+	if len(f.reqs) == 0 {
+		return io.EOF
+	}
+	proto.Merge(m.(proto.Message), f.reqs[0])
+	f.reqs = f.reqs[1:]
+	return nil
+}
+
+func (f *fakeServerStream) SendMsg(m interface{}) error {
+	f.sent = append(f.sent, m)
+	return nil
+}
+
+func TestTrillianInterceptor_StreamQuota(t *testing.T) {
+	logTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	logTree.TreeId = 10
+	readSpecs := []quota.Spec{
+		{Group: quota.Tree, Kind: quota.Read, TreeID: logTree.TreeId},
+		{Group: quota.Global, Kind: quota.Read, Refundable: true},
+	}
+	writeSpecs := []quota.Spec{
+		{Group: quota.Tree, Kind: quota.Write, TreeID: logTree.TreeId},
+		{Group: quota.Global, Kind: quota.Write, Refundable: true},
+	}
+	leaves := func(n int) []*trillian.LogLeaf {
+		return make([]*trillian.LogLeaf, n)
+	}
+	errNoTokens := errors.New("not enough tokens")
+
+	type getTokens struct {
+		tokens int
+		specs  []quota.Spec
+		err    error
+	}
+	for _, test := range []struct {
+		desc         string
+		dryRun       bool
+		serverStream bool
+		method       string
+		reqs         []proto.Message
+		resps        []proto.Message
+		getTokens    []getTokens
+		wantCode     codes.Code
+		wantRecv     int
+		wantSent     int
+	}{
+		{
+			desc:         "serverStream",
+			serverStream: true,
+			method:       "/trillian.TrillianLog/StreamLeavesByRange",
+			reqs:         []proto.Message{&trillian.GetLeavesByRangeRequest{LogId: logTree.TreeId, Count: 100}},
+			resps: []proto.Message{
+				&trillian.GetLeavesByRangeResponse{Leaves: leaves(3)},
+				&trillian.GetLeavesByRangeResponse{},
+			},
+			getTokens: []getTokens{
+				{tokens: 1, specs: readSpecs},
+				{tokens: 3, specs: readSpecs},
+				{tokens: 1, specs: readSpecs},
+			},
+			wantRecv: 1,
+			wantSent: 2,
+		},
+		{
+			desc:         "serverStreamThrottled",
+			serverStream: true,
+			method:       "/trillian.TrillianLog/StreamLeavesByRange",
+			reqs:         []proto.Message{&trillian.GetLeavesByRangeRequest{LogId: logTree.TreeId, Count: 100}},
+			resps: []proto.Message{
+				&trillian.GetLeavesByRangeResponse{Leaves: leaves(3)},
+				&trillian.GetLeavesByRangeResponse{Leaves: leaves(5)},
+				&trillian.GetLeavesByRangeResponse{Leaves: leaves(5)},
+			},
+			getTokens: []getTokens{
+				{tokens: 1, specs: readSpecs},
+				{tokens: 3, specs: readSpecs},
+				{tokens: 5, specs: readSpecs, err: errNoTokens},
+			},
+			wantCode: codes.ResourceExhausted,
+			wantRecv: 1,
+			wantSent: 1,
+		},
+		{
+			desc:         "serverStreamDryRun",
+			dryRun:       true,
+			serverStream: true,
+			method:       "/trillian.TrillianLog/StreamLeavesByRange",
+			reqs:         []proto.Message{&trillian.GetLeavesByRangeRequest{LogId: logTree.TreeId, Count: 100}},
+			resps:        []proto.Message{&trillian.GetLeavesByRangeResponse{Leaves: leaves(3)}},
+			getTokens: []getTokens{
+				{tokens: 1, specs: readSpecs, err: errNoTokens},
+				{tokens: 3, specs: readSpecs, err: errNoTokens},
+			},
+			wantRecv: 1,
+			wantSent: 1,
+		},
+		{
+			desc:   "clientStream",
+			method: "/trillian.TrillianLog/StreamQueueLeaves",
+			reqs: []proto.Message{
+				&trillian.QueueLeavesRequest{LogId: logTree.TreeId, Leaves: leaves(2)},
+				&trillian.QueueLeavesRequest{LogId: logTree.TreeId, Leaves: leaves(4)},
+			},
+			resps: []proto.Message{&trillian.QueueLeavesResponse{}},
+			getTokens: []getTokens{
+				{tokens: 2, specs: writeSpecs},
+				{tokens: 4, specs: writeSpecs},
+			},
+			wantRecv: 2,
+			wantSent: 1,
+		},
+		{
+			desc:   "clientStreamThrottled",
+			method: "/trillian.TrillianLog/StreamQueueLeaves",
+			reqs: []proto.Message{
+				&trillian.QueueLeavesRequest{LogId: logTree.TreeId, Leaves: leaves(2)},
+				&trillian.QueueLeavesRequest{LogId: logTree.TreeId, Leaves: leaves(4)},
+			},
+			resps: []proto.Message{&trillian.QueueLeavesResponse{}},
+			getTokens: []getTokens{
+				{tokens: 2, specs: writeSpecs},
+				{tokens: 4, specs: writeSpecs, err: errNoTokens},
+			},
+			wantCode: codes.ResourceExhausted,
+			wantRecv: 1,
+		},
+		{
+			desc:   "otherTree",
+			method: "/trillian.TrillianLog/StreamQueueLeaves",
+			reqs: []proto.Message{
+				&trillian.QueueLeavesRequest{LogId: logTree.TreeId, Leaves: leaves(2)},
+				&trillian.QueueLeavesRequest{LogId: logTree.TreeId + 1, Leaves: leaves(4)},
+			},
+			getTokens: []getTokens{{tokens: 2, specs: writeSpecs}},
+			wantCode:  codes.Internal,
+			wantRecv:  1,
+		},
+		{
+			desc:   "notIntercepted",
+			method: "/other.Service/Stream",
+			reqs: []proto.Message{
+				&trillian.QueueLeavesRequest{LogId: logTree.TreeId, Leaves: leaves(2)},
+			},
+			resps:    []proto.Message{&trillian.QueueLeavesResponse{}},
+			wantRecv: 1,
+			wantSent: 1,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			admin := storage.NewMockAdminStorage(ctrl)
+			adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			admin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+			adminTX.EXPECT().GetTree(gomock.Any(), logTree.TreeId).AnyTimes().Return(logTree, nil)
+			adminTX.EXPECT().Close().AnyTimes().Return(nil)
+			adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+
+			qm := quota.NewMockManager(ctrl)
+			var calls []*gomock.Call
+			for _, g := range test.getTokens {
+				calls = append(calls, qm.EXPECT().GetTokens(gomock.Any(), g.tokens, g.specs).Return(g.err))
+			}
+			gomock.InOrder(calls...)
+
+			intercept := New(admin, qm, test.dryRun, nil /* mf */)
+			ss := &fakeServerStream{ctx: context.Background(), reqs: test.reqs}
+			info := &grpc.StreamServerInfo{
+				FullMethod:     test.method,
+				IsClientStream: !test.serverStream,
+				IsServerStream: test.serverStream,
+			}
+			recv := 0
+			handler := func(_ interface{}, stream grpc.ServerStream) error {
+				for {
+					var req proto.Message = &trillian.GetLeavesByRangeRequest{}
+					if !test.serverStream {
+						req = &trillian.QueueLeavesRequest{}
+					}
+					err := stream.RecvMsg(req)
+					if err == io.EOF {
+						break
+					} else if err != nil {
+						return err
+					}
+					recv++
+					if test.serverStream {
+						break
+					}
+				}
+				if _, ok := trees.FromContext(stream.Context()); !ok && test.method != "/other.Service/Stream" {
+					t.Errorf("stream.Context() has no tree")
+				}
+				for _, resp := range test.resps {
+					if err := stream.SendMsg(resp); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			err := intercept.StreamInterceptor(nil, ss, info, handler)
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Errorf("StreamInterceptor() returned err = %v, want code %v", err, want)
+			}
+			if got, want := recv, test.wantRecv; got != want {
+				t.Errorf("received %d requests, want %d", got, want)
+			}
+			if got, want := len(ss.sent), test.wantSent; got != want {
+				t.Errorf("sent %d responses, want %d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/merkle/hashers"
+	serrors "github.com/google/trillian/server/errors"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultCheckpointInterval is the number of leaves between the
+	// checkpoints of StreamLeafHashes streams which don't set one.
+	defaultCheckpointInterval = 1 << 16
+	// streamBatchSize is the maximum number of leaf hashes sent in one
+	// StreamLeafHashes response, which are read from storage together.
+	streamBatchSize = 1024
+	// streamCursorVersion is the first byte of StreamLeafHashes cursors.
+	streamCursorVersion = 1
+)
+
+// StreamLeafHashes streams the Merkle leaf hashes of a log from its first leaf,
+// or from the cursor of the request, up to a tree size, with a checkpoint
+// holding the compact range of the leaves streamed so far every
+// checkpoint_interval leaves, and at the end.
+//
+// Leaves are read from storage a batch at a time, each in its own snapshot, so
+// that streaming a large log doesn't hold a transaction open for as long as it
+// takes. The leaves of a tree size are never changed once they're covered by a
+// root, so the batches are consistent with each other. The hash of each leaf is
+// checked against its value, so that leaves corrupted in storage fail the
+// stream.
+func (t *TrillianLogRPCServer) StreamLeafHashes(req *trillian.StreamLeafHashesRequest, stream trillian.TrillianLog_StreamLeafHashesServer) error {
+	ctx, spanEnd := spanFor(stream.Context(), "StreamLeafHashes")
+	defer spanEnd()
+	if err := validateStreamLeafHashesRequest(req); err != nil {
+		return err
+	}
+
+	tree, hasher, err := t.getTreeAndHasher(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return err
+	}
+	ctx = trees.NewContext(ctx, tree)
+	slr, root, err := t.latestSignedRoot(ctx, tree)
+	if err != nil {
+		return err
+	}
+
+	treeSize := req.TreeSize
+	if treeSize == 0 {
+		treeSize = int64(root.TreeSize)
+	}
+	if treeSize > int64(root.TreeSize) {
+		return serrors.InvalidArgument("tree_size", "StreamLeafHashesRequest.TreeSize: %v, want <= %v, the size of the latest root", treeSize, root.TreeSize)
+	}
+
+	fact := &compact.RangeFactory{Hash: hasher.HashChildren}
+	rng := fact.NewEmptyRange(0)
+	digest := requestDigest(req.LogId)
+	if len(req.Cursor) > 0 {
+		if rng, err = decodeStreamCursor(req.Cursor, digest, fact, hasher.Size()); err != nil {
+			return err
+		}
+		if int64(rng.End()) > treeSize {
+			return serrors.InvalidArgument("cursor", "cursor is at leaf %d, beyond the tree size %d", rng.End(), treeSize)
+		}
+	}
+	interval := req.CheckpointInterval
+	if interval == 0 {
+		interval = defaultCheckpointInterval
+	}
+
+	resp := &trillian.StreamLeafHashesResponse{SignedLogRoot: slr}
+	for {
+		next := int64(rng.End())
+		checkpoint := (next/interval + 1) * interval
+		if checkpoint > treeSize {
+			checkpoint = treeSize
+		}
+		end := next + streamBatchSize
+		if end > checkpoint {
+			end = checkpoint
+		}
+		if next < end {
+			hashes, err := t.readLeafHashes(ctx, tree, hasher, next, end)
+			if err != nil {
+				return err
+			}
+			for _, h := range hashes {
+				if err := rng.Append(h.MerkleLeafHash, nil); err != nil {
+					return status.Errorf(codes.Internal, "failed to append leaf %d to compact range: %v", h.LeafIndex, err)
+				}
+			}
+			resp.LeafHashes = hashes
+		}
+		if int64(rng.End()) == checkpoint {
+			resp.Checkpoint = &trillian.LeafHashCheckpoint{
+				TreeSize:     checkpoint,
+				CompactRange: rng.Hashes(),
+				Cursor:       encodeStreamCursor(rng, digest),
+			}
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+		if int64(rng.End()) == treeSize {
+			return nil
+		}
+		resp = &trillian.StreamLeafHashesResponse{}
+	}
+}
+
+// latestSignedRoot reads the latest root of a log, and checks its signature if
+// roots are verified.
+func (t *TrillianLogRPCServer) latestSignedRoot(ctx context.Context, tree *trillian.Tree) (*trillian.SignedLogRoot, *types.LogRootV1, error) {
+	tx, err := t.snapshotForTree(ctx, tree, "StreamLeafHashes")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "StreamLeafHashes")
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+	}
+	if err := t.verifyRoot(ctx, tree, slr); err != nil {
+		return nil, nil, err
+	}
+	if err := t.commitAndLog(ctx, tree.TreeId, tx, "StreamLeafHashes"); err != nil {
+		return nil, nil, err
+	}
+	return slr, &root, nil
+}
+
+// readLeafHashes reads the hashes of the leaves [start, end) of a log, which
+// must all be sequenced, in a snapshot of its own.
+func (t *TrillianLogRPCServer) readLeafHashes(ctx context.Context, tree *trillian.Tree, hasher hashers.LogHasher, start, end int64) ([]*trillian.LeafHash, error) {
+	tx, err := t.snapshotForTree(ctx, tree, "StreamLeafHashes")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "StreamLeafHashes")
+
+	leaves, err := tx.GetLeavesByRange(ctx, start, end-start)
+	if err != nil {
+		return nil, err
+	}
+	if got, want := int64(len(leaves)), end-start; got != want {
+		return nil, status.Errorf(codes.DataLoss, "read %d leaves from index %d, want %d", got, start, want)
+	}
+	hashes := make([]*trillian.LeafHash, 0, len(leaves))
+	for i, leaf := range leaves {
+		if want := start + int64(i); leaf.LeafIndex != want {
+			return nil, status.Errorf(codes.DataLoss, "read leaf %d at index %d", leaf.LeafIndex, want)
+		}
+		if hash := hasher.HashLeaf(leaf.LeafValue); !bytes.Equal(hash, leaf.MerkleLeafHash) {
+			return nil, status.Errorf(codes.DataLoss, "leaf %d has Merkle leaf hash %x, but its value hashes to %x", leaf.LeafIndex, leaf.MerkleLeafHash, hash)
+		}
+		hashes = append(hashes, &trillian.LeafHash{LeafIndex: leaf.LeafIndex, MerkleLeafHash: leaf.MerkleLeafHash})
+	}
+	if err := t.commitAndLog(ctx, tree.TreeId, tx, "StreamLeafHashes"); err != nil {
+		return nil, err
+	}
+	t.fetchedLeaves.Add(float64(len(leaves)))
+	return hashes, nil
+}
+
+// encodeStreamCursor returns the cursor which resumes a stream of the log with
+// the given digest after the compact range rng, which starts at zero. It holds
+// the end of the range and its hashes, so that later checkpoints can be
+// computed without reading earlier leaves.
+func encodeStreamCursor(rng *compact.Range, digest []byte) []byte {
+	buf := make([]byte, 1+digestSize+binary.MaxVarintLen64)
+	buf[0] = streamCursorVersion
+	n := 1 + copy(buf[1:], digest)
+	n += binary.PutVarint(buf[n:], int64(rng.End()))
+	buf = buf[:n]
+	for _, h := range rng.Hashes() {
+		buf = append(buf, h...)
+	}
+	return buf
+}
+
+// decodeStreamCursor decodes the cursor of a stream of the log with the given
+// digest, whose hashes are of the given size, into the compact range which it
+// resumes after.
+func decodeStreamCursor(cursor, digest []byte, fact *compact.RangeFactory, hashSize int) (*compact.Range, error) {
+	if len(cursor) < 1+digestSize || cursor[0] != streamCursorVersion || !bytes.Equal(cursor[1:1+digestSize], digest) {
+		return nil, serrors.InvalidArgument("cursor", "cursor is malformed or was not returned for this log")
+	}
+	rest := cursor[1+digestSize:]
+	end, n := binary.Varint(rest)
+	if n <= 0 || end < 0 {
+		return nil, serrors.InvalidArgument("cursor", "cursor has a malformed index")
+	}
+	rest = rest[n:]
+	if len(rest)%hashSize != 0 {
+		return nil, serrors.InvalidArgument("cursor", "cursor has malformed hashes")
+	}
+	var hashes [][]byte
+	for ; len(rest) > 0; rest = rest[hashSize:] {
+		hashes = append(hashes, rest[:hashSize])
+	}
+	rng, err := fact.NewRange(0, uint64(end), hashes)
+	if err != nil {
+		return nil, serrors.InvalidArgument("cursor", "cursor has malformed hashes: %v", err)
+	}
+	return rng, nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeLeafHashStream collects the responses sent on a StreamLeafHashes stream.
+type fakeLeafHashStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	resps []*trillian.StreamLeafHashesResponse
+}
+
+func (s *fakeLeafHashStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeLeafHashStream) Send(resp *trillian.StreamLeafHashesResponse) error {
+	s.resps = append(s.resps, resp)
+	return nil
+}
+
+func TestStreamLeafHashes(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tree := &trillian.Tree{TreeId: 6962, TreeType: trillian.TreeType_LOG, TreeState: trillian.TreeState_ACTIVE, HashStrategy: trillian.HashStrategy_RFC6962_SHA256}
+
+	const treeSize = 2500
+	fact := &compact.RangeFactory{Hash: th.HashChildren}
+	rng := fact.NewEmptyRange(0)
+	var leaves []*trillian.LogLeaf
+	for i := int64(0); i < treeSize; i++ {
+		var value [8]byte
+		binary.BigEndian.PutUint64(value[:], uint64(i))
+		leaf := newTestLeaf(value[:], nil, i)
+		leaves = append(leaves, leaf)
+		if err := rng.Append(leaf.MerkleLeafHash, nil); err != nil {
+			t.Fatalf("Append(): %v", err)
+		}
+	}
+	rootHash, err := rng.GetRootHash(nil)
+	if err != nil {
+		t.Fatalf("GetRootHash(): %v", err)
+	}
+	root, err := fixedSigner.SignLogRoot(&types.LogRootV1{TreeSize: treeSize, RootHash: rootHash})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+
+	adminTX := storage.NewMockAdminTX(ctrl)
+	adminTX.EXPECT().GetTree(gomock.Any(), tree.TreeId).AnyTimes().Return(tree, nil)
+	adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+	adminTX.EXPECT().Close().AnyTimes().Return(nil)
+	fakeAdmin := storage.NewMockAdminStorage(ctrl)
+	fakeAdmin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+	tx := storage.NewMockLogTreeTX(ctrl)
+	tx.EXPECT().LatestSignedLogRoot(gomock.Any()).AnyTimes().Return(root, nil)
+	tx.EXPECT().GetLeavesByRange(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+			return leaves[start : start+count], nil
+		})
+	tx.EXPECT().Commit(gomock.Any()).AnyTimes().Return(nil)
+	tx.EXPECT().Close().AnyTimes().Return(nil)
+	fakeStorage := storage.NewMockLogStorage(ctrl)
+	fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).AnyTimes().Return(tx, nil)
+	server := NewTrillianLogRPCServer(extension.Registry{LogStorage: fakeStorage, AdminStorage: fakeAdmin}, fakeTimeSource)
+
+	// stream streams the leaf hashes of req, and checks that they follow on
+	// from start, and that each checkpoint hashes to the root of its size.
+	stream := func(t *testing.T, req *trillian.StreamLeafHashesRequest, start int64) []*trillian.LeafHashCheckpoint {
+		t.Helper()
+		s := &fakeLeafHashStream{ctx: ctx}
+		if err := server.StreamLeafHashes(req, s); err != nil {
+			t.Fatalf("StreamLeafHashes(): %v", err)
+		}
+		if len(s.resps) == 0 || s.resps[0].SignedLogRoot == nil {
+			t.Fatal("StreamLeafHashes(): no signed log root in the first response")
+		}
+		next := start
+		var checkpoints []*trillian.LeafHashCheckpoint
+		for _, resp := range s.resps {
+			if len(resp.LeafHashes) > streamBatchSize {
+				t.Errorf("StreamLeafHashes(): response of %d leaf hashes, want at most %d", len(resp.LeafHashes), streamBatchSize)
+			}
+			for _, h := range resp.LeafHashes {
+				if h.LeafIndex != next || !bytes.Equal(h.MerkleLeafHash, leaves[next].MerkleLeafHash) {
+					t.Fatalf("StreamLeafHashes(): got leaf hash %d, want %d", h.LeafIndex, next)
+				}
+				next++
+			}
+			if c := resp.Checkpoint; c != nil {
+				if c.TreeSize != next {
+					t.Errorf("StreamLeafHashes(): checkpoint at %d after leaf hash %d", c.TreeSize, next)
+				}
+				checkpoints = append(checkpoints, c)
+			}
+		}
+		if len(checkpoints) == 0 || s.resps[len(s.resps)-1].Checkpoint == nil {
+			t.Fatal("StreamLeafHashes(): last response has no checkpoint")
+		}
+		last := checkpoints[len(checkpoints)-1]
+		if got, want := last.TreeSize, int64(treeSize); req.TreeSize == 0 && got != want {
+			t.Errorf("StreamLeafHashes(): last checkpoint at %d, want %d", got, want)
+		}
+		cr, err := fact.NewRange(0, uint64(last.TreeSize), last.CompactRange)
+		if err != nil {
+			t.Fatalf("NewRange(): %v", err)
+		}
+		if got, err := cr.GetRootHash(nil); err != nil || (last.TreeSize == treeSize && !bytes.Equal(got, rootHash)) {
+			t.Errorf("StreamLeafHashes(): last checkpoint hashes to %x, %v, want %x", got, err, rootHash)
+		}
+		return checkpoints
+	}
+
+	checkpoints := stream(t, &trillian.StreamLeafHashesRequest{LogId: tree.TreeId, CheckpointInterval: 1000}, 0)
+	if got, want := len(checkpoints), 3; got != want {
+		t.Fatalf("StreamLeafHashes(): %d checkpoints, want %d", got, want)
+	}
+	for i, size := range []int64{1000, 2000, 2500} {
+		if got := checkpoints[i].TreeSize; got != size {
+			t.Errorf("StreamLeafHashes(): checkpoint %d at %d, want %d", i, got, size)
+		}
+	}
+
+	// Streams resume from the cursors of checkpoints, up to other sizes.
+	resumed := stream(t, &trillian.StreamLeafHashesRequest{LogId: tree.TreeId, CheckpointInterval: 1000, Cursor: checkpoints[0].Cursor}, 1000)
+	if got, want := resumed[len(resumed)-1].Cursor, checkpoints[2].Cursor; !bytes.Equal(got, want) {
+		t.Errorf("StreamLeafHashes(): resumed stream ends at cursor %x, want %x", got, want)
+	}
+	stream(t, &trillian.StreamLeafHashesRequest{LogId: tree.TreeId, TreeSize: 1500, Cursor: checkpoints[0].Cursor}, 1000)
+	stream(t, &trillian.StreamLeafHashesRequest{LogId: tree.TreeId, Cursor: checkpoints[2].Cursor}, treeSize)
+
+	for _, test := range []struct {
+		desc string
+		req  *trillian.StreamLeafHashesRequest
+	}{
+		{desc: "negative-size", req: &trillian.StreamLeafHashesRequest{LogId: tree.TreeId, TreeSize: -1}},
+		{desc: "negative-interval", req: &trillian.StreamLeafHashesRequest{LogId: tree.TreeId, CheckpointInterval: -1}},
+		{desc: "beyond-root", req: &trillian.StreamLeafHashesRequest{LogId: tree.TreeId, TreeSize: treeSize + 1}},
+		{desc: "garbage-cursor", req: &trillian.StreamLeafHashesRequest{LogId: tree.TreeId, Cursor: []byte("garbage")}},
+		{desc: "truncated-cursor", req: &trillian.StreamLeafHashesRequest{LogId: tree.TreeId, Cursor: checkpoints[0].Cursor[:len(checkpoints[0].Cursor)-1]}},
+		{desc: "cursor-beyond-size", req: &trillian.StreamLeafHashesRequest{LogId: tree.TreeId, TreeSize: 1500, Cursor: checkpoints[1].Cursor}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if err := server.StreamLeafHashes(test.req, &fakeLeafHashStream{ctx: ctx}); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("StreamLeafHashes(): %v, want InvalidArgument", err)
+			}
+		})
+	}
+
+	// Leaves whose hashes don't match their values fail the stream.
+	leaves[1234] = newTestLeaf([]byte("corrupt"), nil, 1234)
+	leaves[1234].MerkleLeafHash = th.HashLeaf([]byte("original"))
+	if err := server.StreamLeafHashes(&trillian.StreamLeafHashesRequest{LogId: tree.TreeId}, &fakeLeafHashStream{ctx: ctx}); status.Code(err) != codes.DataLoss {
+		t.Errorf("StreamLeafHashes(): %v, want DataLoss", err)
+	}
+}
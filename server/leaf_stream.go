@@ -0,0 +1,163 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// leafStreamBatchSize is the maximum number of leaves sent in one StreamLeaves
+// response, and read from storage together when it can't stream them.
+const leafStreamBatchSize = 256
+
+// StreamLeaves streams the leaves of a log from the start index of the request
+// up to the size of its latest root, or up to the count of the request.
+//
+// All of the leaves are read in a single snapshot, with a single cursor if the
+// storage implements storage.LeafStreamer, rather than in a snapshot per page
+// as GetLeavesByRange clients do. Responses hold at most leafStreamBatchSize
+// leaves, and are kept under the maximum response size of the server. Each
+// response is sent before more leaves are read, and sending blocks once the
+// client falls behind by more than the gRPC flow control window, so that slow
+// clients don't make the server buffer the log.
+func (t *TrillianLogRPCServer) StreamLeaves(req *trillian.StreamLeavesRequest, stream trillian.TrillianLog_StreamLeavesServer) error {
+	ctx, spanEnd := spanFor(stream.Context(), "StreamLeaves")
+	defer spanEnd()
+	if err := validateStreamLeavesRequest(req); err != nil {
+		return err
+	}
+
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return err
+	}
+	tx, err := t.snapshotForTree(ctx, tree, "StreamLeaves")
+	if err != nil {
+		return err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "StreamLeaves")
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+	}
+	if err := t.verifyRoot(ctx, tree, slr); err != nil {
+		return err
+	}
+
+	next, end := req.StartIndex, int64(root.TreeSize)
+	if req.Count > 0 && req.Count < end-next {
+		end = next + req.Count
+	}
+	s := &leafStreamSender{stream: stream, maxBytes: t.maxResponseBytes}
+	s.reset(&trillian.StreamLeavesResponse{SignedLogRoot: slr})
+	add := func(leaf *trillian.LogLeaf) error {
+		if leaf.LeafIndex != next {
+			return status.Errorf(codes.DataLoss, "read leaf %d at index %d", leaf.LeafIndex, next)
+		}
+		next++
+		return s.add(leaf)
+	}
+	if next < end {
+		if err := t.streamLeaves(ctx, tx, next, end-next, add); err != nil {
+			return err
+		}
+		if next < end {
+			return status.Errorf(codes.DataLoss, "read leaves up to index %d, want %d", next, end)
+		}
+	}
+	if err := s.flush(); err != nil {
+		return err
+	}
+	t.fetchedLeaves.Add(float64(next - req.StartIndex))
+	return t.commitAndLog(ctx, req.LogId, tx, "StreamLeaves")
+}
+
+// streamLeaves calls fn with each of the leaves [start, start+count) of a log,
+// in order, read with a single cursor if the storage supports it, or in
+// batches otherwise.
+func (t *TrillianLogRPCServer) streamLeaves(ctx context.Context, tx storage.ReadOnlyLogTreeTX, start, count int64, fn func(*trillian.LogLeaf) error) error {
+	if ls, ok := tx.(storage.LeafStreamer); ok {
+		return ls.StreamLeavesByRange(ctx, start, count, fn)
+	}
+	for end := start + count; start < end; {
+		batch := end - start
+		if batch > leafStreamBatchSize {
+			batch = leafStreamBatchSize
+		}
+		leaves, err := tx.GetLeavesByRange(ctx, start, batch)
+		if err != nil {
+			return err
+		}
+		if len(leaves) == 0 {
+			return nil
+		}
+		for _, leaf := range leaves {
+			if err := fn(leaf); err != nil {
+				return err
+			}
+		}
+		start += int64(len(leaves))
+	}
+	return nil
+}
+
+// leafStreamSender batches the leaves of a StreamLeaves stream into
+// responses.
+type leafStreamSender struct {
+	stream   trillian.TrillianLog_StreamLeavesServer
+	maxBytes int
+	resp     *trillian.StreamLeavesResponse
+	budget   *responseBudget
+}
+
+// reset starts a new response.
+func (s *leafStreamSender) reset(resp *trillian.StreamLeavesResponse) {
+	s.resp = resp
+	s.budget = newResponseBudget(s.maxBytes, resp)
+}
+
+// add adds a leaf to the current response, after sending it first if the leaf
+// doesn't fit in it.
+func (s *leafStreamSender) add(leaf *trillian.LogLeaf) error {
+	if len(s.resp.Leaves) >= leafStreamBatchSize || !s.budget.take(proto.Size(leaf)) {
+		if err := s.flush(); err != nil {
+			return err
+		}
+		s.budget.take(proto.Size(leaf))
+	}
+	s.resp.Leaves = append(s.resp.Leaves, leaf)
+	return nil
+}
+
+// flush sends the current response, and starts a new one.
+func (s *leafStreamSender) flush() error {
+	if err := s.stream.Send(s.resp); err != nil {
+		return err
+	}
+	s.reset(&trillian.StreamLeavesResponse{})
+	return nil
+}
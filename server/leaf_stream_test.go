@@ -0,0 +1,176 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeLeafStream collects the responses sent on a StreamLeaves stream.
+type fakeLeafStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	resps []*trillian.StreamLeavesResponse
+}
+
+func (s *fakeLeafStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeLeafStream) Send(resp *trillian.StreamLeavesResponse) error {
+	s.resps = append(s.resps, resp)
+	return nil
+}
+
+// streamingLogTreeTX is a LogTreeTX which implements storage.LeafStreamer.
+type streamingLogTreeTX struct {
+	storage.LogTreeTX
+	leaves []*trillian.LogLeaf
+}
+
+func (tx *streamingLogTreeTX) StreamLeavesByRange(ctx context.Context, start, count int64, fn func(*trillian.LogLeaf) error) error {
+	for i := start; i < start+count && i < int64(len(tx.leaves)); i++ {
+		if err := fn(tx.leaves[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestStreamLeaves(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tree := &trillian.Tree{TreeId: 6962, TreeType: trillian.TreeType_LOG, TreeState: trillian.TreeState_ACTIVE, HashStrategy: trillian.HashStrategy_RFC6962_SHA256}
+
+	const treeSize = 1000
+	var leaves []*trillian.LogLeaf
+	for i := int64(0); i < treeSize; i++ {
+		var value [8]byte
+		binary.BigEndian.PutUint64(value[:], uint64(i))
+		leaves = append(leaves, newTestLeaf(value[:], nil, i))
+	}
+	root, err := fixedSigner.SignLogRoot(&types.LogRootV1{TreeSize: treeSize, RootHash: []byte("root")})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+
+	adminTX := storage.NewMockAdminTX(ctrl)
+	adminTX.EXPECT().GetTree(gomock.Any(), tree.TreeId).AnyTimes().Return(tree, nil)
+	adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+	adminTX.EXPECT().Close().AnyTimes().Return(nil)
+	fakeAdmin := storage.NewMockAdminStorage(ctrl)
+	fakeAdmin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+	tx := storage.NewMockLogTreeTX(ctrl)
+	tx.EXPECT().LatestSignedLogRoot(gomock.Any()).AnyTimes().Return(root, nil)
+	tx.EXPECT().GetLeavesByRange(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+			if start+count > treeSize {
+				count = treeSize - start
+			}
+			return leaves[start : start+count], nil
+		})
+	tx.EXPECT().Commit(gomock.Any()).AnyTimes().Return(nil)
+	tx.EXPECT().Close().AnyTimes().Return(nil)
+	streamingTX := &streamingLogTreeTX{LogTreeTX: tx, leaves: leaves}
+
+	for _, test := range []struct {
+		desc      string
+		tx        storage.ReadOnlyLogTreeTX
+		maxBytes  int
+		req       *trillian.StreamLeavesRequest
+		wantStart int64
+		wantEnd   int64
+		maxLeaves int
+	}{
+		{desc: "all", tx: tx, req: &trillian.StreamLeavesRequest{LogId: tree.TreeId}, wantEnd: treeSize, maxLeaves: leafStreamBatchSize},
+		{desc: "all-streamed", tx: streamingTX, req: &trillian.StreamLeavesRequest{LogId: tree.TreeId}, wantEnd: treeSize, maxLeaves: leafStreamBatchSize},
+		{desc: "from-start", tx: streamingTX, req: &trillian.StreamLeavesRequest{LogId: tree.TreeId, StartIndex: 300}, wantStart: 300, wantEnd: treeSize, maxLeaves: leafStreamBatchSize},
+		{desc: "count", tx: tx, req: &trillian.StreamLeavesRequest{LogId: tree.TreeId, StartIndex: 300, Count: 400}, wantStart: 300, wantEnd: 700, maxLeaves: leafStreamBatchSize},
+		{desc: "count-beyond-root", tx: streamingTX, req: &trillian.StreamLeavesRequest{LogId: tree.TreeId, StartIndex: 900, Count: 400}, wantStart: 900, wantEnd: treeSize, maxLeaves: leafStreamBatchSize},
+		{desc: "start-at-root", tx: tx, req: &trillian.StreamLeavesRequest{LogId: tree.TreeId, StartIndex: treeSize}, wantStart: treeSize, wantEnd: treeSize},
+		{desc: "start-beyond-root", tx: streamingTX, req: &trillian.StreamLeavesRequest{LogId: tree.TreeId, StartIndex: treeSize + 1}, wantStart: treeSize + 1, wantEnd: treeSize + 1},
+		{desc: "size-limited", tx: streamingTX, maxBytes: 1000, req: &trillian.StreamLeavesRequest{LogId: tree.TreeId}, wantEnd: treeSize, maxLeaves: 20},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			fakeStorage := storage.NewMockLogStorage(ctrl)
+			fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Times(1).Return(test.tx, nil)
+			server := NewTrillianLogRPCServer(extension.Registry{LogStorage: fakeStorage, AdminStorage: fakeAdmin}, fakeTimeSource)
+			server.LimitResponseSize(test.maxBytes)
+
+			s := &fakeLeafStream{ctx: ctx}
+			if err := server.StreamLeaves(test.req, s); err != nil {
+				t.Fatalf("StreamLeaves(): %v", err)
+			}
+			if len(s.resps) == 0 || !proto.Equal(s.resps[0].SignedLogRoot, root) {
+				t.Fatal("StreamLeaves(): no signed log root in the first response")
+			}
+			next := test.wantStart
+			for i, resp := range s.resps {
+				if i > 0 && resp.SignedLogRoot != nil {
+					t.Errorf("StreamLeaves(): signed log root in response %d", i)
+				}
+				if got := len(resp.Leaves); got > test.maxLeaves || (i > 0 && got == 0) {
+					t.Errorf("StreamLeaves(): response of %d leaves, want 1 to %d", got, test.maxLeaves)
+				}
+				if test.maxBytes > 0 && len(resp.Leaves) > 1 && proto.Size(resp) > test.maxBytes {
+					t.Errorf("StreamLeaves(): response of %d bytes, want at most %d", proto.Size(resp), test.maxBytes)
+				}
+				for _, leaf := range resp.Leaves {
+					if !proto.Equal(leaf, leaves[next]) {
+						t.Fatalf("StreamLeaves(): got leaf %d, want %d", leaf.LeafIndex, next)
+					}
+					next++
+				}
+			}
+			if next != test.wantEnd {
+				t.Errorf("StreamLeaves(): streamed leaves up to %d, want %d", next, test.wantEnd)
+			}
+		})
+	}
+
+	for _, test := range []struct {
+		desc     string
+		req      *trillian.StreamLeavesRequest
+		leaves   []*trillian.LogLeaf
+		wantCode codes.Code
+	}{
+		{desc: "negative-start", req: &trillian.StreamLeavesRequest{LogId: tree.TreeId, StartIndex: -1}, wantCode: codes.InvalidArgument},
+		{desc: "negative-count", req: &trillian.StreamLeavesRequest{LogId: tree.TreeId, Count: -1}, wantCode: codes.InvalidArgument},
+		{desc: "missing-leaves", req: &trillian.StreamLeavesRequest{LogId: tree.TreeId}, leaves: leaves[:500], wantCode: codes.DataLoss},
+		{desc: "misplaced-leaves", req: &trillian.StreamLeavesRequest{LogId: tree.TreeId}, leaves: append(leaves[:10:10], leaves[11:]...), wantCode: codes.DataLoss},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			fakeStorage := storage.NewMockLogStorage(ctrl)
+			fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).MaxTimes(1).Return(&streamingLogTreeTX{LogTreeTX: tx, leaves: test.leaves}, nil)
+			server := NewTrillianLogRPCServer(extension.Registry{LogStorage: fakeStorage, AdminStorage: fakeAdmin}, fakeTimeSource)
+			if err := server.StreamLeaves(test.req, &fakeLeafStream{ctx: ctx}); status.Code(err) != test.wantCode {
+				t.Errorf("StreamLeaves(): %v, want code %v", err, test.wantCode)
+			}
+		})
+	}
+}
@@ -15,21 +15,28 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/merkle"
 	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/merkle/hashers/registry"
 	"github.com/google/trillian/monitoring"
+	serrors "github.com/google/trillian/server/errors"
+	"github.com/google/trillian/server/validators"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
 	"github.com/google/trillian/types"
 	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/requestid"
+	"github.com/google/trillian/util/rootage"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -53,6 +60,12 @@ type TrillianLogRPCServer struct {
 	leafCounter           monitoring.Counter
 	proofIndexPercentiles monitoring.Histogram
 	fetchedLeaves         monitoring.Counter
+	rootAges              *rootage.Tracker
+	rootVerifier          *rootVerifier
+	verifyRoots           bool
+	maxResponseBytes      int
+	validators            *validators.Config
+	rateLimiter           *treeRateLimiter
 }
 
 // NewTrillianLogRPCServer creates a new RPC server backed by a LogStorageProvider.
@@ -78,9 +91,66 @@ func NewTrillianLogRPCServer(registry extension.Registry, timeSource clock.TimeS
 			"fetched_leaves",
 			"Count of individual leaves fetched through GetLeaves* calls",
 		),
+		rootAges: rootage.NewTracker(mf.NewGauge(
+			"latest_root_age_seconds",
+			"Age of the latest signed root of each active log as read from storage, in seconds",
+			"logid",
+		), nil, 0, timeSource),
+		rootVerifier: newRootVerifier(mf, "log_root_signature_failures"),
+		rateLimiter:  newTreeRateLimiter(mf, "log_rate_limited_requests"),
 	}
 }
 
+// VerifyRootSignatures makes read RPCs check the signature of the root they
+// read from storage against the public key of the log before serving it, along
+// with any leaves and proofs, and fail with DataLoss if it doesn't verify. It
+// must be called before the server starts serving.
+func (t *TrillianLogRPCServer) VerifyRootSignatures() {
+	t.verifyRoots = true
+}
+
+// LimitResponseSize makes GetLeavesByRange truncate the leaves it returns to
+// keep its responses under maxBytes, which should be at most the maximum size
+// of the messages clients receive, and return a page token to continue from.
+// StreamLeaves splits its leaves between responses under maxBytes too. Zero
+// means no limit, which is the default. It must be called before the
+// server starts serving.
+func (t *TrillianLogRPCServer) LimitResponseSize(maxBytes int) {
+	t.maxResponseBytes = maxBytes
+}
+
+// ValidateLeaves makes QueueLeaves and AddSequencedLeaves reject with
+// InvalidArgument any batch of leaves with a value that the validator
+// configured for the tree in c doesn't accept. It must be called before the
+// server starts serving.
+func (t *TrillianLogRPCServer) ValidateLeaves(c *validators.Config) {
+	t.validators = c
+}
+
+// validateLeafValues checks the values of leaves with the validator configured
+// for tree, if any.
+func (t *TrillianLogRPCServer) validateLeafValues(tree *trillian.Tree, leaves []*trillian.LogLeaf) error {
+	v := t.validators.For(tree)
+	if v == nil {
+		return nil
+	}
+	for i, leaf := range leaves {
+		if err := v.Validate(leaf.LeafValue); err != nil {
+			t.leafCounter.Inc(strconv.FormatInt(tree.TreeId, 10), "invalid")
+			return serrors.InvalidArgument(fmt.Sprintf("leaves[%d].leaf_value", i), "Leaves[%d].LeafValue: rejected by %s: %v", i, v.Spec, err)
+		}
+	}
+	return nil
+}
+
+// verifyRoot checks the signature of slr, if VerifyRootSignatures was called.
+func (t *TrillianLogRPCServer) verifyRoot(ctx context.Context, tree *trillian.Tree, slr *trillian.SignedLogRoot) error {
+	if !t.verifyRoots {
+		return nil
+	}
+	return t.rootVerifier.verifyLogRoot(ctx, tree, slr)
+}
+
 // IsHealthy returns nil if the server is healthy, error otherwise.
 func (t *TrillianLogRPCServer) IsHealthy() error {
 	ctx, spanEnd := spanFor(context.Background(), "IsHealthy")
@@ -88,11 +158,81 @@ func (t *TrillianLogRPCServer) IsHealthy() error {
 	return t.registry.LogStorage.CheckDatabaseAccessible(ctx)
 }
 
+// MonitorRootAges reads the latest root of every active log from storage each
+// interval, until ctx is done, and exports how old they are. The ages are
+// those seen by clients of this server, which may differ from those seen by
+// the signer, e.g. if storage replication is lagging.
+func (t *TrillianLogRPCServer) MonitorRootAges(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := t.updateRootAges(ctx); err != nil {
+			glog.Warningf("Failed to update root ages: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateRootAges reads the latest roots of the active logs, and updates the
+// exported ages. Logs whose roots can't be read keep getting older.
+func (t *TrillianLogRPCServer) updateRootAges(ctx context.Context) error {
+	allTrees, err := storage.ListTrees(ctx, t.registry.AdminStorage, false /* includeDeleted */)
+	if err != nil {
+		return err
+	}
+	var logIDs []int64
+	for _, tree := range allTrees {
+		if tree.TreeState != trillian.TreeState_ACTIVE ||
+			(tree.TreeType != trillian.TreeType_LOG && tree.TreeType != trillian.TreeType_PREORDERED_LOG) {
+			continue
+		}
+		logIDs = append(logIDs, tree.TreeId)
+		root, err := t.latestRoot(ctx, tree)
+		if err != nil {
+			glog.Warningf("%v: failed to read latest root: %v", tree.TreeId, err)
+			continue
+		}
+		if root.TimestampNanos != 0 {
+			t.rootAges.Observe(tree.TreeId, time.Unix(0, int64(root.TimestampNanos)), 0)
+		}
+	}
+	t.rootAges.Retain(logIDs)
+	t.rootAges.Update()
+	return nil
+}
+
+// latestRoot reads the latest root of the tree from storage.
+func (t *TrillianLogRPCServer) latestRoot(ctx context.Context, tree *trillian.Tree) (*types.LogRootV1, error) {
+	ctx = trees.NewContext(ctx, tree)
+	tx, err := t.registry.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "latestRoot")
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.GetLogRoot()); err != nil {
+		return nil, err
+	}
+	if err := t.commitAndLog(ctx, tree.TreeId, tx, "latestRoot"); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
 // QueueLeaf submits one leaf to the queue.
 func (t *TrillianLogRPCServer) QueueLeaf(ctx context.Context, req *trillian.QueueLeafRequest) (*trillian.QueueLeafResponse, error) {
 	ctx, spanEnd := spanFor(ctx, "QueueLeaf")
 	defer spanEnd()
-	if err := validateLogLeaf(req.Leaf, "QueueLeafRequest.Leaf"); err != nil {
+	if err := validateLogLeaf(req.Leaf, "QueueLeafRequest.Leaf", "leaf"); err != nil {
 		return nil, err
 	}
 
@@ -135,6 +275,13 @@ func (t *TrillianLogRPCServer) QueueLeaves(ctx context.Context, req *trillian.Qu
 	if err != nil {
 		return nil, err
 	}
+	if err := t.rateLimiter.allow(tree, len(req.Leaves), t.timeSource.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := t.validateLeafValues(tree, req.Leaves); err != nil {
+		return nil, err
+	}
 
 	ctx = trees.NewContext(ctx, tree)
 
@@ -144,6 +291,7 @@ func (t *TrillianLogRPCServer) QueueLeaves(ctx context.Context, req *trillian.Qu
 	if err != nil {
 		return nil, err
 	}
+	t.describeDuplicates(ctx, tree, hasher, ret)
 
 	label := strconv.FormatInt(logID, 10)
 	for _, l := range ret {
@@ -152,16 +300,71 @@ func (t *TrillianLogRPCServer) QueueLeaves(ctx context.Context, req *trillian.Qu
 		} else if l.Status.Code == int32(codes.AlreadyExists) {
 			t.leafCounter.Inc(label, "duplicate")
 		}
+		glog.V(2).Infof("%s%v: queued leaf with identity hash %x: %v", requestid.Prefix(ctx), logID, l.GetLeaf().GetLeafIdentityHash(), codes.Code(l.GetStatus().GetCode()))
 	}
 
 	return &trillian.QueueLeavesResponse{QueuedLeaves: ret}, nil
 }
 
+// describeDuplicates sets the Merkle leaf hash of the existing leaves that
+// queued leaves duplicate, and their index and integration timestamp if they
+// have been integrated, so that callers needn't look them up. Storage
+// implementations which don't report the integration of existing leaves have
+// it looked up by Merkle leaf hash. Failures of the lookup are only logged, as
+// the leaves are queued regardless.
+func (t *TrillianLogRPCServer) describeDuplicates(ctx context.Context, tree *trillian.Tree, hasher hashers.LogHasher, queued []*trillian.QueuedLogLeaf) {
+	var pending []*trillian.LogLeaf
+	for _, q := range queued {
+		if q.GetStatus().GetCode() != int32(codes.AlreadyExists) || q.Leaf == nil {
+			continue
+		}
+		// The leaf may be shared with storage, so isn't modified in place.
+		q.Leaf = proto.Clone(q.Leaf).(*trillian.LogLeaf)
+		q.Leaf.MerkleLeafHash = hasher.HashLeaf(q.Leaf.LeafValue)
+		if q.Leaf.IntegrateTimestamp == nil {
+			pending = append(pending, q.Leaf)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	tx, err := t.snapshotForTree(ctx, tree, "QueueLeaves")
+	if err != nil {
+		glog.Warningf("%s%v: failed to look up duplicate leaves: %v", requestid.Prefix(ctx), tree.TreeId, err)
+		return
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "QueueLeaves")
+	hashes := make([][]byte, 0, len(pending))
+	for _, leaf := range pending {
+		hashes = append(hashes, leaf.MerkleLeafHash)
+	}
+	integrated, err := tx.GetLeavesByHash(ctx, hashes, false)
+	if err != nil {
+		glog.Warningf("%s%v: failed to look up duplicate leaves: %v", requestid.Prefix(ctx), tree.TreeId, err)
+		return
+	}
+	for _, leaf := range pending {
+		// Leaves with the same value have the same Merkle leaf hash, but they
+		// may have different identity hashes.
+		for _, i := range integrated {
+			if bytes.Equal(i.LeafIdentityHash, leaf.LeafIdentityHash) {
+				leaf.LeafIndex = i.LeafIndex
+				leaf.IntegrateTimestamp = i.IntegrateTimestamp
+				break
+			}
+		}
+	}
+	if err := t.commitAndLog(ctx, tree.TreeId, tx, "QueueLeaves"); err != nil {
+		glog.Warningf("%s%v: failed to look up duplicate leaves: %v", requestid.Prefix(ctx), tree.TreeId, err)
+	}
+}
+
 // AddSequencedLeaf submits one sequenced leaf to the storage.
 func (t *TrillianLogRPCServer) AddSequencedLeaf(ctx context.Context, req *trillian.AddSequencedLeafRequest) (*trillian.AddSequencedLeafResponse, error) {
 	ctx, spanEnd := spanFor(ctx, "AddSequencedLeaf")
 	defer spanEnd()
-	if err := validateLogLeaf(req.Leaf, "AddSequencedLeafRequest.Leaf"); err != nil {
+	if err := validateLogLeaf(req.Leaf, "AddSequencedLeafRequest.Leaf", "leaf"); err != nil {
 		return nil, err
 	}
 
@@ -195,6 +398,12 @@ func (t *TrillianLogRPCServer) AddSequencedLeaves(ctx context.Context, req *tril
 	if err != nil {
 		return nil, err
 	}
+	if err := t.rateLimiter.allow(tree, len(req.Leaves), t.timeSource.Now()); err != nil {
+		return nil, err
+	}
+	if err := t.validateLeafValues(tree, req.Leaves); err != nil {
+		return nil, err
+	}
 
 	hashLeaves(req.Leaves, hasher)
 
@@ -251,6 +460,9 @@ func (t *TrillianLogRPCServer) GetInclusionProof(ctx context.Context, req *trill
 	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
 	}
+	if err := t.verifyRoot(ctx, tree, slr); err != nil {
+		return nil, err
+	}
 
 	r := &trillian.GetInclusionProofResponse{SignedLogRoot: slr}
 
@@ -273,6 +485,71 @@ func (t *TrillianLogRPCServer) GetInclusionProof(ctx context.Context, req *trill
 	return r, nil
 }
 
+// GetInclusionProofs obtains proofs of inclusion for a batch of sequenced
+// leaves. The proofs are built from a single read of the nodes they need.
+func (t *TrillianLogRPCServer) GetInclusionProofs(ctx context.Context, req *trillian.GetInclusionProofsRequest) (*trillian.GetInclusionProofsResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetInclusionProofs")
+	defer spanEnd()
+	if err := validateGetInclusionProofsRequest(req); err != nil {
+		return nil, err
+	}
+
+	tree, hasher, err := t.getTreeAndHasher(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return nil, err
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	tx, err := t.snapshotForTree(ctx, tree, "GetInclusionProofs")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetInclusionProofs")
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+	}
+	if err := t.verifyRoot(ctx, tree, slr); err != nil {
+		return nil, err
+	}
+
+	r := &trillian.GetInclusionProofsResponse{SignedLogRoot: slr}
+
+	if uint64(req.TreeSize) > root.TreeSize {
+		return r, nil
+	}
+
+	fetches := make([][]merkle.NodeFetch, 0, len(req.LeafIndex))
+	for _, leafIndex := range req.LeafIndex {
+		f, err := merkle.CalcInclusionProofNodeAddresses(req.TreeSize, leafIndex, int64(root.TreeSize))
+		if err != nil {
+			return nil, err
+		}
+		fetches = append(fetches, f)
+		t.recordIndexPercent(leafIndex, root.TreeSize)
+	}
+	rev, err := tx.ReadRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+	proofs, err := fetchNodesAndBuildProofs(ctx, tx, hasher, rev, req.LeafIndex, fetches)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	r.Proof = proofs
+	return r, nil
+}
+
 // GetInclusionProofByHash obtains proofs of inclusion by leaf hash. Because some logs can
 // contain duplicate hashes it is possible for multiple proofs to be returned.
 func (t *TrillianLogRPCServer) GetInclusionProofByHash(ctx context.Context, req *trillian.GetInclusionProofByHashRequest) (*trillian.GetInclusionProofByHashResponse, error) {
@@ -312,6 +589,9 @@ func (t *TrillianLogRPCServer) GetInclusionProofByHash(ctx context.Context, req
 	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
 	}
+	if err := t.verifyRoot(ctx, tree, slr); err != nil {
+		return nil, err
+	}
 
 	// TODO(Martin2112): Need to define a limit on number of results or some form of paging etc.
 	proofs := make([]*trillian.Proof, 0, len(leaves))
@@ -374,6 +654,9 @@ func (t *TrillianLogRPCServer) GetConsistencyProof(ctx context.Context, req *tri
 	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
 	}
+	if err := t.verifyRoot(ctx, tree, slr); err != nil {
+		return nil, err
+	}
 	r := &trillian.GetConsistencyProofResponse{SignedLogRoot: slr}
 
 	if uint64(req.SecondTreeSize) > root.TreeSize {
@@ -419,6 +702,9 @@ func (t *TrillianLogRPCServer) GetLatestSignedLogRoot(ctx context.Context, req *
 	if err := root.UnmarshalBinary(slr.GetLogRoot()); err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
 	}
+	if err := t.verifyRoot(ctx, tree, slr); err != nil {
+		return nil, err
+	}
 
 	r := &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: slr}
 
@@ -536,6 +822,9 @@ func (t *TrillianLogRPCServer) GetLeavesByIndex(ctx context.Context, req *trilli
 	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
 	}
+	if err := t.verifyRoot(ctx, tree, slr); err != nil {
+		return nil, err
+	}
 
 	return &trillian.GetLeavesByIndexResponse{Leaves: leaves, SignedLogRoot: slr}, nil
 }
@@ -568,16 +857,35 @@ func (t *TrillianLogRPCServer) GetLeavesByRange(ctx context.Context, req *trilli
 	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
 	}
+	if err := t.verifyRoot(ctx, tree, slr); err != nil {
+		return nil, err
+	}
 
 	r := &trillian.GetLeavesByRangeResponse{SignedLogRoot: slr}
 
-	if req.StartIndex < int64(root.TreeSize) {
-		leaves, err := tx.GetLeavesByRange(ctx, req.StartIndex, req.Count)
+	start, end := req.StartIndex, req.StartIndex+req.Count
+	digest := requestDigest(req.LogId, int64Param(req.StartIndex), int64Param(req.Count))
+	if len(req.PageToken) > 0 {
+		token, err := decodePageToken(req.PageToken, digest, "page_token")
+		if err != nil {
+			return nil, err
+		}
+		if token.offset < start || token.offset > end {
+			return nil, serrors.InvalidArgument("page_token", "page token offset %d is outside the requested range", token.offset)
+		}
+		start = token.offset
+	}
+	if start < int64(root.TreeSize) && start < end {
+		budget := newResponseBudget(t.maxResponseBytes, r)
+		leaves, err := t.getLeavesByRange(ctx, tx, start, end-start, int64(root.TreeSize), budget)
 		if err != nil {
 			return nil, err
 		}
 		t.fetchedLeaves.Add(float64(len(leaves)))
 		r.Leaves = leaves
+		if next := start + int64(len(leaves)); next < end && next < int64(root.TreeSize) && len(leaves) > 0 {
+			r.NextPageToken = pageToken{offset: next}.encode(digest)
+		}
 	}
 
 	if err := t.commitAndLog(ctx, req.LogId, tx, "GetLeavesByRange"); err != nil {
@@ -587,6 +895,53 @@ func (t *TrillianLogRPCServer) GetLeavesByRange(ctx context.Context, req *trilli
 	return r, nil
 }
 
+// rangeBatchSize is the number of leaves read by the first storage read of a
+// range whose responses are limited in size. Later reads are sized by the
+// average size of the leaves read so far, so that reading leaves which don't
+// fit is mostly avoided.
+const rangeBatchSize = 64
+
+// getLeavesByRange reads up to count leaves from start, below treeSize, and
+// until budget is spent, if not nil.
+func (t *TrillianLogRPCServer) getLeavesByRange(ctx context.Context, tx storage.ReadOnlyLogTreeTX, start, count, treeSize int64, budget *responseBudget) ([]*trillian.LogLeaf, error) {
+	if budget == nil {
+		return tx.GetLeavesByRange(ctx, start, count)
+	}
+	if start+count > treeSize {
+		count = treeSize - start
+	}
+	var leaves []*trillian.LogLeaf
+	size, batch := 0, int64(rangeBatchSize)
+	for count > 0 {
+		if batch > count {
+			batch = count
+		}
+		got, err := tx.GetLeavesByRange(ctx, start, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, leaf := range got {
+			leafSize := proto.Size(leaf)
+			if !budget.take(leafSize) {
+				return leaves, nil
+			}
+			leaves = append(leaves, leaf)
+			size += leafSize
+		}
+		if int64(len(got)) < batch {
+			break
+		}
+		start += batch
+		count -= batch
+		// Read as many leaves as the average so far says fit, plus one to
+		// find out when they don't.
+		if batch = int64(budget.left/(size/len(leaves)+1)) + 1; batch < 1 {
+			batch = 1
+		}
+	}
+	return leaves, nil
+}
+
 // GetLeavesByHash obtains one or more leaves based on their tree hash. It is not possible
 // to fetch leaves that have been queued but not yet integrated. Logs may accept duplicate
 // entries so this may return more results than the number of hashes in the request.
@@ -623,6 +978,9 @@ func (t *TrillianLogRPCServer) GetLeavesByHash(ctx context.Context, req *trillia
 	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
 	}
+	if err := t.verifyRoot(ctx, tree, slr); err != nil {
+		return nil, err
+	}
 
 	if err := t.commitAndLog(ctx, req.LogId, tx, "GetLeavesByHash"); err != nil {
 		return nil, err
@@ -666,6 +1024,9 @@ func (t *TrillianLogRPCServer) GetEntryAndProof(ctx context.Context, req *trilli
 	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
 	}
+	if err := t.verifyRoot(ctx, tree, slr); err != nil {
+		return nil, err
+	}
 
 	r := &trillian.GetEntryAndProofResponse{SignedLogRoot: slr}
 
@@ -707,7 +1068,7 @@ func (t *TrillianLogRPCServer) GetEntryAndProof(ctx context.Context, req *trilli
 func (t *TrillianLogRPCServer) commitAndLog(ctx context.Context, logID int64, tx storage.ReadOnlyLogTreeTX, op string) error {
 	err := tx.Commit(ctx)
 	if err != nil {
-		glog.Warningf("%v: Commit failed for %v: %v", logID, op, err)
+		glog.Warningf("%s%v: Commit failed for %v: %v", requestid.Prefix(ctx), logID, op, err)
 	}
 	return err
 }
@@ -715,7 +1076,7 @@ func (t *TrillianLogRPCServer) commitAndLog(ctx context.Context, logID int64, tx
 func (t *TrillianLogRPCServer) closeAndLog(ctx context.Context, logID int64, tx storage.ReadOnlyLogTreeTX, op string) {
 	err := tx.Close()
 	if err != nil {
-		glog.Warningf("%v: Close failed for %v: %v", logID, op, err)
+		glog.Warningf("%s%v: Close failed for %v: %v", requestid.Prefix(ctx), logID, op, err)
 	}
 }
 
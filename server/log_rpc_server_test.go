@@ -15,6 +15,7 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"errors"
@@ -25,6 +26,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/trillian"
 	tcrypto "github.com/google/trillian/crypto"
@@ -33,6 +35,8 @@ import (
 	"github.com/google/trillian/extension"
 	_ "github.com/google/trillian/merkle/rfc6962" // Register the hasher.
 	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/server/validators"
 	"github.com/google/trillian/storage"
 	stestonly "github.com/google/trillian/storage/testonly"
 	"github.com/google/trillian/storage/tree"
@@ -40,6 +44,7 @@ import (
 	"github.com/google/trillian/types"
 	"github.com/google/trillian/util/clock"
 	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -484,7 +489,21 @@ func TestQueueLeaves(t *testing.T) {
 
 	mockStorage := storage.NewMockLogStorage(ctrl)
 	c1 := mockStorage.EXPECT().QueueLeaves(gomock.Any(), cmpMatcher{tree1}, cmpMatcher{[]*trillian.LogLeaf{leaf1}}, fakeTime).Return([]*trillian.QueuedLogLeaf{okQueuedLeaf(leaf1)}, nil)
-	mockStorage.EXPECT().QueueLeaves(gomock.Any(), cmpMatcher{tree1}, cmpMatcher{[]*trillian.LogLeaf{leaf1}}, fakeTime).After(c1).Return([]*trillian.QueuedLogLeaf{dupeQueuedLeaf(leaf1)}, nil)
+	c2 := mockStorage.EXPECT().QueueLeaves(gomock.Any(), cmpMatcher{tree1}, cmpMatcher{[]*trillian.LogLeaf{leaf1}}, fakeTime).After(c1).Return([]*trillian.QueuedLogLeaf{dupeQueuedLeaf(leaf1)}, nil)
+	// The storage doesn't say whether the duplicate leaf was integrated, so
+	// it's looked up by Merkle leaf hash.
+	integratedLeaf := proto.Clone(leaf1).(*trillian.LogLeaf)
+	integratedLeaf.MerkleLeafHash = th.HashLeaf(leaf1.LeafValue)
+	integratedLeaf.LeafIndex = 7
+	integratedLeaf.IntegrateTimestamp, _ = ptypes.TimestampProto(fakeTime)
+	otherLeaf := proto.Clone(integratedLeaf).(*trillian.LogLeaf)
+	otherLeaf.LeafIdentityHash = []byte("other")
+	otherLeaf.LeafIndex = 3
+	tx := storage.NewMockLogTreeTX(ctrl)
+	mockStorage.EXPECT().SnapshotForTree(gomock.Any(), cmpMatcher{tree1}).After(c2).Return(tx, nil)
+	tx.EXPECT().GetLeavesByHash(gomock.Any(), [][]byte{integratedLeaf.MerkleLeafHash}, false).Return([]*trillian.LogLeaf{otherLeaf, integratedLeaf}, nil)
+	tx.EXPECT().Commit(gomock.Any()).Return(nil)
+	tx.EXPECT().Close().Return(nil)
 
 	registry := extension.Registry{
 		AdminStorage: fakeAdminStorage(ctrl, storageParams{treeID: queueRequest0.LogId, numSnapshots: 2}),
@@ -524,12 +543,57 @@ func TestQueueLeaves(t *testing.T) {
 		}
 		t.Errorf("QueueLeaves().Status=%v,nil; want %v,nil", sc, code.Code_ALREADY_EXISTS)
 	}
-	if !proto.Equal(queueRequest0.Leaves[0], queuedLeaf.Leaf) {
-		diff := cmp.Diff(queueRequest0.Leaves[0], queuedLeaf.Leaf)
+	if !proto.Equal(integratedLeaf, queuedLeaf.Leaf) {
+		diff := cmp.Diff(integratedLeaf, queuedLeaf.Leaf)
 		t.Errorf("post-QueueLeaves() diff:\n%v", diff)
 	}
 }
 
+func TestQueueLeavesValidation(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jsonLeaf := newTestLeaf([]byte(`{"a": 1}`), []byte("extra"), 1)
+	mockStorage := storage.NewMockLogStorage(ctrl)
+	mockStorage.EXPECT().QueueLeaves(gomock.Any(), cmpMatcher{tree1}, gomock.Any(), fakeTime).Return([]*trillian.QueuedLogLeaf{okQueuedLeaf(jsonLeaf)}, nil)
+
+	registry := extension.Registry{
+		AdminStorage: fakeAdminStorage(ctrl, storageParams{treeID: logID1, numSnapshots: 2}),
+		LogStorage:   mockStorage,
+	}
+	c, err := validators.ParseConfig("LOG=json")
+	if err != nil {
+		t.Fatalf("ParseConfig(): %v", err)
+	}
+	server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+	server.ValidateLeaves(c)
+
+	// The second leaf isn't JSON, so the whole batch is rejected.
+	req := &trillian.QueueLeavesRequest{LogId: logID1, Leaves: []*trillian.LogLeaf{jsonLeaf, leaf1}}
+	_, err = server.QueueLeaves(ctx, req)
+	st, _ := status.FromError(err)
+	if got, want := st.Code(), codes.InvalidArgument; got != want {
+		t.Fatalf("QueueLeaves(invalid)=%v, want code %v", err, want)
+	}
+	var fields []string
+	for _, d := range st.Details() {
+		if d, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range d.FieldViolations {
+				fields = append(fields, v.Field)
+			}
+		}
+	}
+	if diff := cmp.Diff(fields, []string{"leaves[1].leaf_value"}); diff != "" {
+		t.Errorf("violated fields diff (-got +want):\n%s", diff)
+	}
+
+	req = &trillian.QueueLeavesRequest{LogId: logID1, Leaves: []*trillian.LogLeaf{jsonLeaf}}
+	if _, err := server.QueueLeaves(ctx, req); err != nil {
+		t.Errorf("QueueLeaves(valid)=%v, want nil", err)
+	}
+}
+
 func TestAddSequencedLeavesStorageError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -688,6 +752,68 @@ func TestGetLatestSignedLogRoot(t *testing.T) {
 	}
 }
 
+func TestUpdateRootAges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tree2 := addTreeID(stestonly.LogTree, logID2)
+	frozen := addTreeID(stestonly.LogTree, logID3)
+	frozen.TreeState = trillian.TreeState_FROZEN
+	mapTree := addTreeID(stestonly.MapTree, 4)
+
+	adminStorage := storage.NewMockAdminStorage(ctrl)
+	adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+	adminStorage.EXPECT().Snapshot(gomock.Any()).Return(adminTX, nil)
+	adminTX.EXPECT().ListTrees(gomock.Any(), false).Return([]*trillian.Tree{tree1, tree2, frozen, mapTree}, nil)
+	adminTX.EXPECT().Commit().Return(nil)
+	adminTX.EXPECT().Close().Return(nil)
+
+	logStorage := storage.NewMockLogStorage(ctrl)
+	okTX := storage.NewMockLogTreeTX(ctrl)
+	logStorage.EXPECT().SnapshotForTree(gomock.Any(), cmpMatcher{tree1}).Return(okTX, nil)
+	okTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(signedRoot1, nil)
+	okTX.EXPECT().Commit(gomock.Any()).Return(nil)
+	okTX.EXPECT().Close().Return(nil)
+	failTX := storage.NewMockLogTreeTX(ctrl)
+	logStorage.EXPECT().SnapshotForTree(gomock.Any(), cmpMatcher{tree2}).Return(failTX, nil)
+	failTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(nil, errors.New("storage failure"))
+	failTX.EXPECT().Close().Return(nil)
+
+	mf := &gaugeRecorder{MetricFactory: monitoring.InertMetricFactory{}, gauges: make(map[string]monitoring.Gauge)}
+	registry := extension.Registry{
+		AdminStorage:  adminStorage,
+		LogStorage:    logStorage,
+		MetricFactory: mf,
+	}
+	server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+	if err := server.updateRootAges(context.Background()); err != nil {
+		t.Fatalf("updateRootAges()=%v", err)
+	}
+
+	age := mf.gauges["latest_root_age_seconds"]
+	want := fakeTime.Sub(time.Unix(0, int64(root1.TimestampNanos))).Seconds()
+	if got := age.Value(fmt.Sprint(logID1)); got != want {
+		t.Errorf("latest_root_age_seconds[%v]=%v, want %v", logID1, got, want)
+	}
+	for _, id := range []int64{logID2, logID3, 4} {
+		if got := age.Value(fmt.Sprint(id)); got != 0 {
+			t.Errorf("latest_root_age_seconds[%v]=%v, want 0", id, got)
+		}
+	}
+}
+
+// gaugeRecorder is a MetricFactory which records the gauges it creates.
+type gaugeRecorder struct {
+	monitoring.MetricFactory
+	gauges map[string]monitoring.Gauge
+}
+
+func (r *gaugeRecorder) NewGauge(name, help string, labelNames ...string) monitoring.Gauge {
+	g := r.MetricFactory.NewGauge(name, help, labelNames...)
+	r.gauges[name] = g
+	return g
+}
+
 func TestGetLeavesByHash(t *testing.T) {
 	for _, tc := range []struct {
 		name         string
@@ -1272,6 +1398,78 @@ func TestGetProofByIndex(t *testing.T) {
 	}
 }
 
+func TestGetInclusionProofs(t *testing.T) {
+	ctx := context.Background()
+	// The proofs for leaves 2 and 3 at size 7 share two of their nodes.
+	nodeIDs := append(nodeIdsInclusionSize7Index2, stestonly.MustCreateNodeIDForTreeCoords(0, 2, 64))
+	nodes := []tree.Node{
+		{NodeID: nodeIDs[0], NodeRevision: 3, Hash: []byte("nodehash0")},
+		{NodeID: nodeIDs[1], NodeRevision: 2, Hash: []byte("nodehash1")},
+		{NodeID: nodeIDs[2], NodeRevision: 3, Hash: []byte("nodehash2")},
+		{NodeID: nodeIDs[3], NodeRevision: 3, Hash: []byte("nodehash3")},
+	}
+
+	for _, tc := range []struct {
+		desc       string
+		req        *trillian.GetInclusionProofsRequest
+		wantProofs []*trillian.Proof
+	}{
+		{
+			desc: "OK",
+			req:  &trillian.GetInclusionProofsRequest{LogId: logID1, TreeSize: 7, LeafIndex: []int64{2, 3}},
+			wantProofs: []*trillian.Proof{
+				{LeafIndex: 2, Hashes: [][]byte{[]byte("nodehash0"), []byte("nodehash1"), []byte("nodehash2")}},
+				{LeafIndex: 3, Hashes: [][]byte{[]byte("nodehash3"), []byte("nodehash1"), []byte("nodehash2")}},
+			},
+		},
+		{
+			desc: "TreeSizeBeyondRoot",
+			req:  &trillian.GetInclusionProofsRequest{LogId: logID1, TreeSize: 50, LeafIndex: []int64{2, 25}},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			fakeStorage := storage.NewMockLogStorage(ctrl)
+			mockTX := storage.NewMockLogTreeTX(ctrl)
+			fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), cmpMatcher{tree1}).Return(mockTX, nil)
+			mockTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(signedRoot1, nil)
+			if tc.wantProofs != nil {
+				mockTX.EXPECT().ReadRevision(gomock.Any()).Return(revision1, nil)
+				mockTX.EXPECT().GetMerkleNodes(gomock.Any(), revision1, nodeIDs).Return(nodes, nil)
+				mockTX.EXPECT().Commit(gomock.Any()).Return(nil)
+			}
+			mockTX.EXPECT().Close().Return(nil)
+
+			registry := extension.Registry{
+				AdminStorage: fakeAdminStorage(ctrl, storageParams{
+					treeID:       logID1,
+					numSnapshots: 1,
+				}),
+				LogStorage: fakeStorage,
+			}
+			server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+			resp, err := server.GetInclusionProofs(ctx, tc.req)
+			if err != nil {
+				t.Fatalf("GetInclusionProofs(): %v", err)
+			}
+			if !proto.Equal(resp.SignedLogRoot, signedRoot1) {
+				t.Errorf("GetInclusionProofs(): root %v, want %v", resp.SignedLogRoot, signedRoot1)
+			}
+			if got, want := len(resp.Proof), len(tc.wantProofs); got != want {
+				t.Fatalf("GetInclusionProofs(): %d proofs, want %d", got, want)
+			}
+			for i, want := range tc.wantProofs {
+				if got := resp.Proof[i]; !proto.Equal(got, want) {
+					t.Errorf("GetInclusionProofs(): proof %d: %v, want %v", i, proto.CompactTextString(got), proto.CompactTextString(want))
+				}
+			}
+		})
+	}
+}
+
 func TestGetEntryAndProof(t *testing.T) {
 	for _, tc := range []struct {
 		name         string
@@ -1864,6 +2062,54 @@ func TestTrillianLogRPCServer_GetInclusionProofErrors(t *testing.T) {
 	}
 }
 
+func TestTrillianLogRPCServer_GetInclusionProofsErrors(t *testing.T) {
+	tests := []struct {
+		desc string
+		req  *trillian.GetInclusionProofsRequest
+	}{
+		{
+			desc: "noLeafIndices",
+			req: &trillian.GetInclusionProofsRequest{
+				LogId:    1,
+				TreeSize: 20,
+			},
+		},
+		{
+			desc: "badLeafIndex",
+			req: &trillian.GetInclusionProofsRequest{
+				LogId:     1,
+				LeafIndex: []int64{1, -10},
+				TreeSize:  20,
+			},
+		},
+		{
+			desc: "badTreeSize",
+			req: &trillian.GetInclusionProofsRequest{
+				LogId:     1,
+				LeafIndex: []int64{10},
+				TreeSize:  -20,
+			},
+		},
+		{
+			desc: "indexGreaterThanSize",
+			req: &trillian.GetInclusionProofsRequest{
+				LogId:     1,
+				LeafIndex: []int64{1, 10},
+				TreeSize:  9,
+			},
+		},
+	}
+
+	logServer := NewTrillianLogRPCServer(extension.Registry{}, fakeTimeSource)
+	ctx := context.Background()
+	for _, test := range tests {
+		_, err := logServer.GetInclusionProofs(ctx, test.req)
+		if s, ok := status.FromError(err); !ok || s.Code() != codes.InvalidArgument {
+			t.Errorf("%v: GetInclusionProofs() returned err = %v, wantCode = %s", test.desc, err, codes.InvalidArgument)
+		}
+	}
+}
+
 func TestTrillianLogRPCServer_GetInclusionProofByHashErrors(t *testing.T) {
 	tests := []struct {
 		desc string
@@ -2349,3 +2595,152 @@ func newSignerWithFixedSig(sig []byte) crypto.Signer {
 	}
 	return testonly.NewSignerWithFixedSig(key, sig)
 }
+
+func TestValidationErrorFields(t *testing.T) {
+	leaf := &trillian.LogLeaf{LeafValue: []byte("value")}
+	for _, test := range []struct {
+		desc      string
+		err       error
+		wantCode  codes.Code
+		wantField string
+	}{
+		{
+			desc:      "leaf-value",
+			err:       validateLogLeaves([]*trillian.LogLeaf{leaf, {}}, "QueueLeavesRequest"),
+			wantCode:  codes.InvalidArgument,
+			wantField: "leaves[1].leaf_value",
+		},
+		{
+			desc:      "leaf-index",
+			err:       validateLogLeaf(&trillian.LogLeaf{LeafValue: []byte("value"), LeafIndex: -1}, "QueueLeafRequest.Leaf", "leaf"),
+			wantCode:  codes.InvalidArgument,
+			wantField: "leaf.leaf_index",
+		},
+		{
+			desc:      "leaf-index-by-index",
+			err:       validateGetLeavesByIndexRequest(&trillian.GetLeavesByIndexRequest{LeafIndex: []int64{0, 1, -2}}),
+			wantCode:  codes.InvalidArgument,
+			wantField: "leaf_index[2]",
+		},
+		{
+			desc: "sequenced-leaf-index",
+			err: validateAddSequencedLeavesRequest(&trillian.AddSequencedLeavesRequest{Leaves: []*trillian.LogLeaf{
+				{LeafValue: []byte("value"), LeafIndex: 5},
+				{LeafValue: []byte("value"), LeafIndex: 7},
+			}}),
+			wantCode:  codes.FailedPrecondition,
+			wantField: "leaves[1].leaf_index",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			st := status.Convert(test.err)
+			if got, want := st.Code(), test.wantCode; got != want {
+				t.Fatalf("err=%v, want code %v", test.err, want)
+			}
+			var fields []string
+			for _, d := range st.Details() {
+				switch d := d.(type) {
+				case *errdetails.BadRequest:
+					for _, v := range d.FieldViolations {
+						fields = append(fields, v.Field)
+					}
+				case *errdetails.PreconditionFailure:
+					for _, v := range d.Violations {
+						fields = append(fields, v.Subject)
+					}
+				}
+			}
+			if diff := cmp.Diff(fields, []string{test.wantField}); diff != "" {
+				t.Errorf("violated fields diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetLeavesByRangePaging(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tree := &trillian.Tree{TreeId: 6962, TreeType: trillian.TreeType_LOG, TreeState: trillian.TreeState_ACTIVE}
+
+	const treeSize = 300
+	var leaves []*trillian.LogLeaf
+	for i := int64(0); i < treeSize; i++ {
+		leaves = append(leaves, newTestLeaf(bytes.Repeat([]byte{byte(i)}, 100), nil, i))
+	}
+	root, err := fixedSigner.SignLogRoot(&types.LogRootV1{TreeSize: treeSize, RootHash: []byte("A NICE HASH")})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+
+	adminTX := storage.NewMockAdminTX(ctrl)
+	adminTX.EXPECT().GetTree(gomock.Any(), tree.TreeId).AnyTimes().Return(tree, nil)
+	adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+	adminTX.EXPECT().Close().AnyTimes().Return(nil)
+	fakeAdmin := storage.NewMockAdminStorage(ctrl)
+	fakeAdmin.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+	tx := storage.NewMockLogTreeTX(ctrl)
+	tx.EXPECT().LatestSignedLogRoot(gomock.Any()).AnyTimes().Return(root, nil)
+	read := 0
+	tx.EXPECT().GetLeavesByRange(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+			end := start + count
+			if end > treeSize {
+				end = treeSize
+			}
+			read += int(end - start)
+			return leaves[start:end], nil
+		})
+	tx.EXPECT().Commit(gomock.Any()).AnyTimes().Return(nil)
+	tx.EXPECT().Close().AnyTimes().Return(nil)
+	fakeStorage := storage.NewMockLogStorage(ctrl)
+	fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).AnyTimes().Return(tx, nil)
+
+	const maxBytes = 8 << 10
+	server := NewTrillianLogRPCServer(extension.Registry{LogStorage: fakeStorage, AdminStorage: fakeAdmin}, fakeTimeSource)
+	server.LimitResponseSize(maxBytes)
+
+	req := &trillian.GetLeavesByRangeRequest{LogId: tree.TreeId, StartIndex: 10, Count: 280}
+	var got []*trillian.LogLeaf
+	pages := 0
+	for {
+		rsp, err := server.GetLeavesByRange(ctx, req)
+		if err != nil {
+			t.Fatalf("GetLeavesByRange(): %v", err)
+		}
+		if size := proto.Size(rsp); size > maxBytes {
+			t.Errorf("GetLeavesByRange(): response of %d bytes, want at most %d", size, maxBytes)
+		}
+		got = append(got, rsp.Leaves...)
+		pages++
+		if len(rsp.NextPageToken) == 0 {
+			break
+		}
+		req.PageToken = rsp.NextPageToken
+	}
+	if want := leaves[10:290]; !cmp.Equal(got, want, cmp.Comparer(proto.Equal)) {
+		t.Errorf("GetLeavesByRange(): got %d leaves, want leaves [10, 290)", len(got))
+	}
+	if pages < 2 {
+		t.Errorf("GetLeavesByRange(): %d pages, want several", pages)
+	}
+	// Storage reads are sized so that few leaves are read beyond those which
+	// fit, at most a batch per page.
+	if max := len(got) + rangeBatchSize*pages; read > max {
+		t.Errorf("GetLeavesByRange(): read %d leaves from storage, want at most %d", read, max)
+	}
+
+	for _, test := range []struct {
+		desc string
+		req  *trillian.GetLeavesByRangeRequest
+	}{
+		{desc: "garbage", req: &trillian.GetLeavesByRangeRequest{LogId: tree.TreeId, StartIndex: 10, Count: 280, PageToken: []byte("garbage")}},
+		{desc: "other-request", req: &trillian.GetLeavesByRangeRequest{LogId: tree.TreeId, StartIndex: 11, Count: 280, PageToken: req.PageToken}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := server.GetLeavesByRange(ctx, test.req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("GetLeavesByRange(): %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
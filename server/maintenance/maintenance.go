@@ -0,0 +1,92 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maintenance provides the read-only maintenance mode of Trillian
+// servers and trees, during which write RPCs are rejected while reads continue.
+package maintenance
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	serrors "github.com/google/trillian/server/errors"
+)
+
+// Mode is the maintenance mode of a server, which applies to all of the trees
+// it serves. It's kept in memory, so it only lasts until the server restarts.
+// The zero value is a server which isn't in maintenance.
+type Mode struct {
+	mu          sync.RWMutex
+	maintenance *trillian.TreeMaintenance
+}
+
+// Set puts the server into maintenance for the given reason, starting now,
+// unless it's already in maintenance, in which case only the reason changes.
+func (m *Mode) Set(reason string) *trillian.TreeMaintenance {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.maintenance == nil {
+		m.maintenance = &trillian.TreeMaintenance{StartTime: ptypes.TimestampNow()}
+	}
+	m.maintenance.Reason = reason
+	return proto.Clone(m.maintenance).(*trillian.TreeMaintenance)
+}
+
+// Clear takes the server out of maintenance.
+func (m *Mode) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maintenance = nil
+}
+
+// Get returns the maintenance of the server, or nil if it isn't in
+// maintenance.
+func (m *Mode) Get() *trillian.TreeMaintenance {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.maintenance == nil {
+		return nil
+	}
+	return proto.Clone(m.maintenance).(*trillian.TreeMaintenance)
+}
+
+// CheckWrite returns a FailedPrecondition error, with the details of the
+// maintenance, if the server or tree is in maintenance, and nil otherwise.
+// The mode may be nil, for a server which is never in maintenance.
+func (m *Mode) CheckWrite(tree *trillian.Tree) error {
+	if server := m.Get(); server != nil {
+		return serrors.FailedPrecondition("MAINTENANCE", "server", "server is in read-only maintenance %v", describe(server))
+	}
+	if tree.GetMaintenance() != nil {
+		return serrors.FailedPrecondition("MAINTENANCE", "tree.maintenance", "tree %v is in read-only maintenance %v", tree.TreeId, describe(tree.Maintenance))
+	}
+	return nil
+}
+
+func describe(m *trillian.TreeMaintenance) string {
+	desc := "since an unknown time"
+	if start, err := ptypes.Timestamp(m.StartTime); err == nil {
+		desc = fmt.Sprintf("since %v", start.UTC().Format("2006-01-02T15:04:05Z"))
+	}
+	if m.Reason != "" {
+		desc = fmt.Sprintf("%v: %v", desc, m.Reason)
+	}
+	return desc
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckWrite(t *testing.T) {
+	start, err := ptypes.TimestampProto(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("TimestampProto(): %v", err)
+	}
+	tree := &trillian.Tree{TreeId: 12}
+	maintained := &trillian.Tree{TreeId: 12, Maintenance: &trillian.TreeMaintenance{Reason: "failover", StartTime: start}}
+
+	var nilMode *Mode
+	inMaintenance := &Mode{}
+	inMaintenance.Set("schema migration")
+	cleared := &Mode{}
+	cleared.Set("schema migration")
+	cleared.Clear()
+
+	for _, test := range []struct {
+		desc    string
+		mode    *Mode
+		tree    *trillian.Tree
+		wantMsg string
+	}{
+		{desc: "nilMode", mode: nilMode, tree: tree},
+		{desc: "noMaintenance", mode: &Mode{}, tree: tree},
+		{desc: "cleared", mode: cleared, tree: tree},
+		{desc: "server", mode: inMaintenance, tree: tree, wantMsg: "server is in read-only maintenance since"},
+		{desc: "serverReason", mode: inMaintenance, tree: maintained, wantMsg: ": schema migration"},
+		{desc: "tree", mode: nilMode, tree: maintained, wantMsg: "tree 12 is in read-only maintenance since 2021-03-04T05:06:07Z: failover"},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			err := test.mode.CheckWrite(test.tree)
+			if test.wantMsg == "" {
+				if err != nil {
+					t.Fatalf("CheckWrite(): %v, want nil", err)
+				}
+				return
+			}
+			if got := status.Code(err); got != codes.FailedPrecondition {
+				t.Fatalf("CheckWrite(): %v, want code %v", err, codes.FailedPrecondition)
+			}
+			if got := status.Convert(err).Message(); !strings.Contains(got, test.wantMsg) {
+				t.Errorf("CheckWrite(): %q, want it to contain %q", got, test.wantMsg)
+			}
+		})
+	}
+}
+
+func TestModeSet(t *testing.T) {
+	var m Mode
+	if got := m.Get(); got != nil {
+		t.Fatalf("Get(): %v, want nil", got)
+	}
+	first := m.Set("one")
+	second := m.Set("two")
+	if got, want := second.StartTime.AsTime(), first.StartTime.AsTime(); !got.Equal(want) {
+		t.Errorf("Set(): start time changed from %v to %v", want, got)
+	}
+	if got := m.Get().GetReason(); got != "two" {
+		t.Errorf("Get(): reason %q, want %q", got, "two")
+	}
+}
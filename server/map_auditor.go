@@ -0,0 +1,307 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	stree "github.com/google/trillian/storage/tree"
+)
+
+// mapAuditSplit is the depth of the shards which MapAuditor splits maps into.
+// The leaves of one shard are held in memory at a time, along with the roots
+// of all the shards.
+const mapAuditSplit = 16
+
+// MapAuditorOptions holds the settings of a MapAuditor.
+type MapAuditorOptions struct {
+	// MapIDs are the IDs of the maps to audit.
+	MapIDs []int64
+	// Interval is how often the maps are audited.
+	Interval time.Duration
+	// HashWorkers is the number of goroutines hashing each shard, as
+	// TrillianMapServerOptions.HashWorkers is.
+	HashWorkers int
+}
+
+// MapAuditor periodically recomputes the root hashes of maps from all of their
+// leaves at the latest revision, and checks them against the stored roots, and
+// against the tiles holding the nodes at the shard depth. This catches leaves,
+// tiles and roots corrupted in storage, which read RPCs would otherwise serve
+// along with proofs that don't verify.
+//
+// Leaves are streamed from storage in order, and hashed one shard of the map
+// at a time, so memory use is bounded by the size of the largest shard rather
+// than of the map. Auditing needs storage whose map transactions implement
+// storage.MapLeafScanner.
+type MapAuditor struct {
+	registry extension.Registry
+	opts     MapAuditorOptions
+
+	runs     monitoring.Counter
+	failures monitoring.Counter
+	leaves   monitoring.Gauge
+}
+
+// NewMapAuditor returns a MapAuditor for the maps in the given registry.
+func NewMapAuditor(registry extension.Registry, opts MapAuditorOptions) *MapAuditor {
+	mf := registry.MetricFactory
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &MapAuditor{
+		registry: registry,
+		opts:     opts,
+		runs:     mf.NewCounter("map_audit_runs", "Number of audits of the root hash of a map", "treeid"),
+		failures: mf.NewCounter("map_audit_failures", "Number of audits which found a map root hash or tile not matching the map leaves", "treeid"),
+		leaves:   mf.NewGauge("map_audit_leaves", "Number of leaves hashed by the latest audit of a map", "treeid"),
+	}
+}
+
+// Run audits the configured maps every Interval, until ctx is done.
+func (a *MapAuditor) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.opts.Interval)
+	defer ticker.Stop()
+	for {
+		for _, mapID := range a.opts.MapIDs {
+			if err := a.AuditMap(ctx, mapID); err != nil && status.Code(err) != codes.DataLoss {
+				glog.Warningf("%v: map audit failed: %v", mapID, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// AuditMap recomputes the root hash of the map with the given ID from its
+// leaves at the latest revision. It returns a DataLoss error if the hash
+// doesn't match the stored root, or the stored tiles at the shard depth.
+func (a *MapAuditor) AuditMap(ctx context.Context, mapID int64) error {
+	ctx, spanEnd := spanFor(ctx, "AuditMap")
+	defer spanEnd()
+	label := strconv.FormatInt(mapID, 10)
+
+	tree, err := trees.GetTree(ctx, a.registry.AdminStorage, mapID, optsMapRead)
+	if err != nil {
+		return err
+	}
+	hasher, err := registry.NewMapHasherForTree(tree)
+	if err != nil {
+		return err
+	}
+	layout, err := a.registry.MapStorage.Layout(tree)
+	if err != nil {
+		return err
+	}
+	tx, err := a.registry.MapStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	scanner, ok := tx.(storage.MapLeafScanner)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "tree %d: map storage %T can't scan leaves", mapID, tx)
+	}
+
+	smr, err := tx.LatestSignedMapRoot(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch the latest SignedMapRoot: %v", err)
+	}
+	var root types.MapRootV1
+	if err := root.UnmarshalBinary(smr.MapRoot); err != nil {
+		return err
+	}
+
+	split := uint(mapAuditSplit)
+	if bits := uint(hasher.BitLen()); split > bits {
+		split = bits
+	}
+	w := smt.NewParallelWriter(mapID, hasher, uint(hasher.BitLen()), split, a.opts.HashWorkers)
+	shards, leaves, err := a.hashShards(ctx, scanner, tree, hasher, w, split, int64(root.Revision))
+	if err != nil {
+		return err
+	}
+	stored, err := readShardRoots(ctx, tx, tree, hasher, layout, split, int64(root.Revision))
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	a.runs.Inc(label)
+	a.leaves.Set(float64(leaves), label)
+
+	hash := hasher.HashEmpty(mapID, nil, hasher.BitLen())
+	if len(shards) > 0 {
+		// Write reorders and overwrites the nodes passed in.
+		top, err := w.Write(ctx, append([]smt.Node(nil), shards...), emptyAccessor{})
+		if err != nil {
+			return err
+		}
+		hash = top.Hash
+	}
+	if err := checkShardRoots(shards, stored); err != nil {
+		return a.fail(mapID, root, err)
+	}
+	if !bytes.Equal(hash, root.RootHash) {
+		return a.fail(mapID, root, fmt.Errorf("root hash is %x, but %d leaves hash to %x", root.RootHash, leaves, hash))
+	}
+	glog.V(1).Infof("%v: audited map root at revision %d with %d leaves", mapID, root.Revision, leaves)
+	return nil
+}
+
+// fail counts and logs a failed audit of a map root, and returns a DataLoss
+// error describing it.
+func (a *MapAuditor) fail(mapID int64, root types.MapRootV1, err error) error {
+	a.failures.Inc(strconv.FormatInt(mapID, 10))
+	glog.Errorf("%v: map audit failed at revision %d: %v", mapID, root.Revision, err)
+	return status.Errorf(codes.DataLoss, "tree %d: map root at revision %d doesn't match its leaves: %v", mapID, root.Revision, err)
+}
+
+// hashShards reads all the leaves of the map at the given revision, and
+// returns the root nodes of the non-empty shards at the split depth, along
+// with the number of leaves read.
+func (a *MapAuditor) hashShards(ctx context.Context, scanner storage.MapLeafScanner, tree *trillian.Tree, hasher hashers.MapHasher, w *smt.Writer, split uint, revision int64) ([]smt.Node, int, error) {
+	var shards, nodes []smt.Node
+	flush := func() error {
+		if len(nodes) == 0 {
+			return nil
+		}
+		shard, err := w.Write(ctx, nodes, emptyAccessor{})
+		if err != nil {
+			return err
+		}
+		shards = append(shards, shard)
+		nodes = nodes[:0]
+		return nil
+	}
+
+	leaves := 0
+	var last []byte
+	err := scanner.ScanLeaves(ctx, revision, func(leaf *trillian.MapLeaf) error {
+		if got, want := len(leaf.Index), hasher.Size(); got != want {
+			return status.Errorf(codes.DataLoss, "tree %d: leaf %x has an index of %d bytes, want %d", tree.TreeId, leaf.Index, got, want)
+		}
+		if last != nil && bytes.Compare(leaf.Index, last) <= 0 {
+			return status.Errorf(codes.Internal, "tree %d: leaf %x scanned after leaf %x", tree.TreeId, leaf.Index, last)
+		}
+		last = leaf.Index
+		leaves++
+
+		id := stree.NewNodeID2(string(leaf.Index), uint(hasher.BitLen()))
+		if len(nodes) > 0 && nodes[0].ID.Prefix(split) != id.Prefix(split) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		nodes = append(nodes, smt.Node{ID: id, Hash: hasher.HashLeaf(tree.TreeId, leaf.Index, leaf.LeafValue)})
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := flush(); err != nil {
+		return nil, 0, err
+	}
+	return shards, leaves, nil
+}
+
+// readShardRoots returns the hashes of the non-empty nodes at the split depth
+// which are stored in the tiles of the map at the given revision.
+func readShardRoots(ctx context.Context, tx storage.ReadOnlyMapTreeTX, tree *trillian.Tree, hasher hashers.MapHasher, layout *stree.Layout, split uint, revision int64) (map[stree.NodeID2][]byte, error) {
+	// All the nodes at the split depth are in the tiles rooted at this depth.
+	depth := layout.GetTileRootID(stree.NewNodeID2(string(make([]byte, hasher.Size())), split)).BitLen()
+	ids := make([]stree.NodeID2, 0, 1<<depth)
+	for i := 0; i < 1<<depth; i++ {
+		path := make([]byte, depth/8)
+		for b := range path {
+			path[b] = byte(i >> (8 * (len(path) - 1 - b)))
+		}
+		ids = append(ids, stree.NewNodeID2(string(path), depth))
+	}
+	tiles, err := tx.GetTiles(ctx, revision, ids)
+	if err != nil {
+		return nil, err
+	}
+	ts := smt.NewTileSet(tree.TreeId, hasher, layout)
+	for _, tile := range tiles {
+		if err := ts.Add(tile); err != nil {
+			return nil, status.Errorf(codes.DataLoss, "tree %d: tile %v: %v", tree.TreeId, tile.ID, err)
+		}
+	}
+	roots := make(map[stree.NodeID2][]byte)
+	for id, hash := range ts.Hashes() {
+		if id.BitLen() == split {
+			roots[id] = hash
+		}
+	}
+	return roots, nil
+}
+
+// checkShardRoots checks the recomputed roots of the non-empty shards against
+// the stored ones.
+func checkShardRoots(shards []smt.Node, stored map[stree.NodeID2][]byte) error {
+	for _, shard := range shards {
+		if hash, ok := stored[shard.ID]; !ok {
+			return fmt.Errorf("tile node %v is empty, but its leaves hash to %x", shard.ID, shard.Hash)
+		} else if !bytes.Equal(hash, shard.Hash) {
+			return fmt.Errorf("tile node %v has hash %x, but its leaves hash to %x", shard.ID, hash, shard.Hash)
+		}
+	}
+	if len(stored) > len(shards) {
+		recomputed := make(map[stree.NodeID2]bool, len(shards))
+		for _, shard := range shards {
+			recomputed[shard.ID] = true
+		}
+		for id, hash := range stored {
+			if !recomputed[id] {
+				return fmt.Errorf("tile node %v has hash %x, but has no leaves", id, hash)
+			}
+		}
+	}
+	return nil
+}
+
+// emptyAccessor is a smt.NodeBatchAccessor over an empty tree, which discards
+// the nodes written to it. It lets smt.Writer hash leaves from scratch.
+type emptyAccessor struct{}
+
+func (emptyAccessor) Get(context.Context, []stree.NodeID2) (map[stree.NodeID2][]byte, error) {
+	return nil, nil
+}
+
+func (emptyAccessor) Set(context.Context, []smt.Node) error {
+	return nil
+}
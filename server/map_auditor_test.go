@@ -0,0 +1,233 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	_ "github.com/google/trillian/merkle/maphasher" // TEST_MAP_HASHER
+)
+
+// memTiles is an in-memory store of map tiles for fake map transactions. It
+// is safe for concurrent use, as the tree updater reads and writes the tiles
+// of each shard in its own goroutine.
+type memTiles struct {
+	mu    sync.Mutex
+	tiles map[tree.NodeID2]smt.Tile
+}
+
+func newMemTiles() *memTiles {
+	return &memTiles{tiles: make(map[tree.NodeID2]smt.Tile)}
+}
+
+func (m *memTiles) GetTiles(ctx context.Context, rev int64, ids []tree.NodeID2) ([]smt.Tile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var tiles []smt.Tile
+	for _, id := range ids {
+		if tile, ok := m.tiles[id]; ok {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles, nil
+}
+
+func (m *memTiles) SetTiles(ctx context.Context, tiles []smt.Tile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tile := range tiles {
+		m.tiles[tile.ID] = tile
+	}
+	return nil
+}
+
+// scanningMapTX is a map transaction over in-memory leaves and tiles, which
+// can scan the leaves.
+type scanningMapTX struct {
+	storage.MapTreeTX
+	leaves []*trillian.MapLeaf
+	tiles  *memTiles
+	root   *trillian.SignedMapRoot
+}
+
+func (s *scanningMapTX) ScanLeaves(ctx context.Context, revision int64, fn func(*trillian.MapLeaf) error) error {
+	for _, leaf := range s.leaves {
+		if err := fn(leaf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *scanningMapTX) GetTiles(ctx context.Context, rev int64, ids []tree.NodeID2) ([]smt.Tile, error) {
+	return s.tiles.GetTiles(ctx, rev, ids)
+}
+
+func (s *scanningMapTX) SetTiles(ctx context.Context, tiles []smt.Tile) error {
+	return s.tiles.SetTiles(ctx, tiles)
+}
+
+func (s *scanningMapTX) LatestSignedMapRoot(ctx context.Context) (*trillian.SignedMapRoot, error) {
+	return s.root, nil
+}
+
+func (s *scanningMapTX) Commit(ctx context.Context) error { return nil }
+func (s *scanningMapTX) Close() error                     { return nil }
+
+// unscannableMapTX is a map transaction which can't scan leaves.
+type unscannableMapTX struct {
+	storage.MapTreeTX
+}
+
+func (unscannableMapTX) Close() error { return nil }
+
+func TestAuditMap(t *testing.T) {
+	ctx := context.Background()
+	mapTree := stestonly.MapTree
+	hasher, err := registry.NewMapHasher(mapTree.HashStrategy)
+	if err != nil {
+		t.Fatalf("NewMapHasher(): %v", err)
+	}
+	layout := tree.NewLayout([]int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 176})
+
+	// newMap returns a transaction holding a map with the given number of
+	// leaves, whose tiles and root are written as SetLeaves writes them.
+	newMap := func(t *testing.T, n int) *scanningMapTX {
+		t.Helper()
+		tx := &scanningMapTX{tiles: newMemTiles()}
+		var nodes []smt.Node
+		for i := 0; i < n; i++ {
+			index := sha256.Sum256([]byte(fmt.Sprintf("key %d", i)))
+			leaf := &trillian.MapLeaf{Index: index[:], LeafValue: []byte(fmt.Sprintf("value %d", i))}
+			tx.leaves = append(tx.leaves, leaf)
+			nodes = append(nodes, smt.Node{
+				ID:   tree.NewNodeID2(string(leaf.Index), uint(hasher.BitLen())),
+				Hash: hasher.HashLeaf(mapID1, leaf.Index, leaf.LeafValue),
+			})
+		}
+		sort.Slice(tx.leaves, func(i, j int) bool { return string(tx.leaves[i].Index) < string(tx.leaves[j].Index) })
+
+		hash := hasher.HashEmpty(mapID1, nil, hasher.BitLen())
+		if n > 0 {
+			updater := &mapTreeUpdater{tree: mapTree, layout: layout, hasher: hasher, writeRev: 1, singleTX: true}
+			if hash, err = updater.update(ctx, tx, nodes); err != nil {
+				t.Fatalf("update(): %v", err)
+			}
+		}
+		mapRoot, err := (&types.MapRootV1{Revision: 1, RootHash: hash}).MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(): %v", err)
+		}
+		tx.root = &trillian.SignedMapRoot{MapRoot: mapRoot}
+		return tx
+	}
+
+	for _, test := range []struct {
+		desc     string
+		leaves   int
+		tamper   func(t *testing.T, tx *scanningMapTX)
+		tx       storage.MapTreeTX
+		wantCode codes.Code
+	}{
+		{desc: "empty"},
+		{desc: "one-leaf", leaves: 1},
+		{desc: "leaves", leaves: 300},
+		{
+			desc:   "leaf-value",
+			leaves: 300,
+			tamper: func(t *testing.T, tx *scanningMapTX) {
+				tx.leaves[123].LeafValue = []byte("tampered")
+			},
+			wantCode: codes.DataLoss,
+		},
+		{
+			desc:   "missing-leaf",
+			leaves: 300,
+			tamper: func(t *testing.T, tx *scanningMapTX) {
+				tx.leaves = append(tx.leaves[:123], tx.leaves[124:]...)
+			},
+			wantCode: codes.DataLoss,
+		},
+		{
+			desc:   "tile",
+			leaves: 300,
+			tamper: func(t *testing.T, tx *scanningMapTX) {
+				// Change the root of a shard in the tiles only.
+				id := tree.NewNodeID2(string(tx.leaves[0].Index[:1]), 8)
+				tile := tx.tiles.tiles[id]
+				tile.Leaves = append(smt.NodesRow(nil), tile.Leaves...)
+				tile.Leaves[0].Hash = sha256.New().Sum(nil)
+				tx.tiles.tiles[id] = tile
+			},
+			wantCode: codes.DataLoss,
+		},
+		{
+			desc:   "root",
+			leaves: 300,
+			tamper: func(t *testing.T, tx *scanningMapTX) {
+				mapRoot, err := (&types.MapRootV1{Revision: 1, RootHash: []byte("tampered")}).MarshalBinary()
+				if err != nil {
+					t.Fatalf("MarshalBinary(): %v", err)
+				}
+				tx.root = &trillian.SignedMapRoot{MapRoot: mapRoot}
+			},
+			wantCode: codes.DataLoss,
+		},
+		{desc: "unscannable", tx: unscannableMapTX{}, wantCode: codes.Unimplemented},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tx := test.tx
+			if tx == nil {
+				stx := newMap(t, test.leaves)
+				if test.tamper != nil {
+					test.tamper(t, stx)
+				}
+				tx = stx
+			}
+			ms := storage.NewMockMapStorage(ctrl)
+			ms.EXPECT().Layout(gomock.Any()).Return(layout, nil)
+			ms.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(tx, nil)
+
+			auditor := NewMapAuditor(extension.Registry{
+				AdminStorage: fakeAdminStorageForMap(ctrl, mapID1),
+				MapStorage:   ms,
+			}, MapAuditorOptions{MapIDs: []int64{mapID1}})
+			err := auditor.AuditMap(ctx, mapID1)
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Errorf("AuditMap(): %v, want code %v", err, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mapInitializer initializes the maps with auto_init set before they are
+// written, as InitMap does, so that their writers don't need to call InitMap
+// first. It remembers the maps known to be initialized, so each map is only
+// checked once per process.
+type mapInitializer struct {
+	ms          storage.MapStorage
+	initialized sync.Map // Tree ID to struct{}.
+}
+
+func newMapInitializer(ms storage.MapStorage) *mapInitializer {
+	return &mapInitializer{ms: ms}
+}
+
+// initIfNeeded writes the empty revision 0 root of the map, in a transaction
+// of its own, if the map has auto_init set and has no root yet. Concurrent
+// writers may race to initialize the map, in which case all but one of them
+// fail, and can be retried.
+func (i *mapInitializer) initIfNeeded(ctx context.Context, tree *trillian.Tree, hasher hashers.MapHasher) error {
+	if !tree.AutoInit {
+		return nil
+	}
+	if _, ok := i.initialized.Load(tree.TreeId); ok {
+		return nil
+	}
+	switch _, err := addMapRevision(ctx, i.ms, tree, hasher, 0, nil); status.Code(err) {
+	case codes.OK:
+		glog.Infof("%v: Initialized map", tree.TreeId)
+	case codes.AlreadyExists:
+	default:
+		return err
+	}
+	i.initialized.Store(tree.TreeId, struct{}{})
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle/maphasher"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+// rootsMapTX is a map transaction which only stores roots.
+type rootsMapTX struct {
+	storage.MapTreeTX
+	roots []*trillian.SignedMapRoot
+}
+
+func (r *rootsMapTX) LatestSignedMapRoot(ctx context.Context) (*trillian.SignedMapRoot, error) {
+	if len(r.roots) == 0 {
+		return nil, storage.ErrTreeNeedsInit
+	}
+	return r.roots[len(r.roots)-1], nil
+}
+
+func (r *rootsMapTX) StoreSignedMapRoot(ctx context.Context, root *trillian.SignedMapRoot) error {
+	r.roots = append(r.roots, root)
+	return nil
+}
+
+func TestMapAutoInit(t *testing.T) {
+	ctx := context.Background()
+	hasher := maphasher.Default
+
+	for _, test := range []struct {
+		desc          string
+		autoInit      bool
+		initialized   bool
+		wantCode      codes.Code
+		wantRevisions []uint64
+	}{
+		{desc: "autoInit", autoInit: true, wantRevisions: []uint64{0, 1}},
+		{desc: "autoInitInitialized", autoInit: true, initialized: true, wantRevisions: []uint64{0, 1}},
+		{desc: "initialized", initialized: true, wantRevisions: []uint64{0, 1}},
+		{desc: "notInitialized", wantCode: codes.FailedPrecondition},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tree := proto.Clone(stestonly.MapTree).(*trillian.Tree)
+			tree.TreeId = mapID1
+			tree.AutoInit = test.autoInit
+			adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			adminTX.EXPECT().GetTree(gomock.Any(), mapID1).Return(tree, nil)
+			adminTX.EXPECT().Close().AnyTimes().Return(nil)
+			adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+
+			tx := &rootsMapTX{}
+			if test.initialized {
+				root, err := makeSignedMapRoot(ctx, tree, hasher.HashEmpty(mapID1, nil, hasher.BitLen()), 0, nil)
+				if err != nil {
+					t.Fatalf("makeSignedMapRoot(): %v", err)
+				}
+				tx.roots = append(tx.roots, root)
+			}
+			ms := storage.NewMockMapStorage(ctrl)
+			ms.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, _ interface{}, f storage.MapTXFunc) error {
+				return f(ctx, tx)
+			})
+			server := NewTrillianMapServer(extension.Registry{
+				AdminStorage: &stestonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{adminTX}},
+				MapStorage:   ms,
+			}, TrillianMapServerOptions{})
+
+			_, err := server.SetLeaves(ctx, &trillian.SetMapLeavesRequest{MapId: mapID1, Revision: 1})
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Fatalf("SetLeaves(): %v, want code %v", err, want)
+			}
+			if got, want := len(tx.roots), len(test.wantRevisions); got != want {
+				t.Fatalf("%d roots stored, want %d", got, want)
+			}
+			for i, smr := range tx.roots {
+				var root types.MapRootV1
+				if err := root.UnmarshalBinary(smr.MapRoot); err != nil {
+					t.Fatalf("UnmarshalBinary(): %v", err)
+				}
+				if got, want := root.Revision, test.wantRevisions[i]; got != want {
+					t.Errorf("root %d has revision %d, want %d", i, got, want)
+				}
+				if want := hasher.HashEmpty(mapID1, nil, hasher.BitLen()); !bytes.Equal(root.RootHash, want) {
+					t.Errorf("root %d has hash %x, want %x", i, root.RootHash, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMapInitializerRemembersMaps(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tree := proto.Clone(stestonly.MapTree).(*trillian.Tree)
+	tree.TreeId = mapID1
+	tree.AutoInit = true
+	tx := &rootsMapTX{}
+	ms := storage.NewMockMapStorage(ctrl)
+	ms.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(ctx context.Context, _ interface{}, f storage.MapTXFunc) error {
+		return f(ctx, tx)
+	})
+
+	mi := newMapInitializer(ms)
+	for i := 0; i < 3; i++ {
+		if err := mi.initIfNeeded(ctx, tree, maphasher.Default); err != nil {
+			t.Fatalf("initIfNeeded(): %v", err)
+		}
+	}
+	if got, want := len(tx.roots), 1; got != want {
+		t.Errorf("%d roots stored, want %d", got, want)
+	}
+}
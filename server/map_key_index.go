@@ -0,0 +1,117 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	serrors "github.com/google/trillian/server/errors"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxKeyPrefixPageSize is the default and maximum number of leaves returned
+// by ListLeavesByKeyPrefix.
+const maxKeyPrefixPageSize = 1000
+
+// ListLeavesByKeyPrefix implements the RPC Method of the same name. Page
+// tokens hold the key and index of the last leaf returned, which the next
+// page starts after.
+func (t *TrillianMapServer) ListLeavesByKeyPrefix(ctx context.Context, req *trillian.ListMapLeavesByKeyPrefixRequest) (*trillian.ListMapLeavesByKeyPrefixResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "ListLeavesByKeyPrefix")
+	defer spanEnd()
+	if req.Revision < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "map revision %d must be >= 0", req.Revision)
+	}
+	if got, max := len(req.KeyPrefix), storage.MaxMapLeafKeySize; got > max {
+		return nil, serrors.InvalidArgument("key_prefix", "has %d bytes, want at most %d", got, max)
+	}
+	limit := int(req.PageSize)
+	switch {
+	case limit < 0:
+		return nil, serrors.InvalidArgument("page_size", "%d must be >= 0", limit)
+	case limit == 0 || limit > maxKeyPrefixPageSize:
+		limit = maxKeyPrefixPageSize
+	}
+	tree, hasher, err := t.getTreeAndHasher(ctx, req.MapId, optsMapRead)
+	if err != nil {
+		return nil, err
+	}
+	if !tree.MapKeyIndex {
+		return nil, status.Errorf(codes.FailedPrecondition, "map %d doesn't have map_key_index set", req.MapId)
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	digest := requestDigest(req.MapId, int64Param(req.Revision), req.KeyPrefix)
+	var afterKey, afterIndex []byte
+	if len(req.PageToken) > 0 {
+		token, err := decodePageToken(req.PageToken, digest, "page_token")
+		if err != nil {
+			return nil, err
+		}
+		split := len(token.after) - hasher.Size()
+		if split <= 0 || split > storage.MaxMapLeafKeySize {
+			return nil, serrors.InvalidArgument("page_token", "page token has a malformed position")
+		}
+		afterKey, afterIndex = token.after[:split], token.after[split:]
+	}
+
+	tx, err := t.snapshotAtRevision(ctx, tree, req.Revision, "ListLeavesByKeyPrefix")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "ListLeavesByKeyPrefix")
+
+	lister, ok := tx.(storage.MapLeafKeyLister)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "map storage %T can't list leaves by key", tx)
+	}
+	root, _, err := t.readRoot(ctx, tree, tx, req.Revision)
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := lister.ListLeavesByKeyPrefix(ctx, req.Revision, req.KeyPrefix, afterKey, afterIndex, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	resp := &trillian.ListMapLeavesByKeyPrefixResponse{MapRoot: root}
+	budget := newResponseBudget(t.opts.MaxResponseBytes, resp)
+	budget.reserve(binary.MaxVarintLen64 + storage.MaxMapLeafKeySize + hasher.Size())
+	more := len(leaves) == limit
+	for i, leaf := range leaves {
+		// Remove LeafHash because SetLeaves does not supply it.
+		leaf.LeafHash = nil
+		if !budget.take(proto.Size(leaf)) {
+			leaves, more = leaves[:i], true
+			break
+		}
+	}
+	resp.Leaves = leaves
+	if more && len(leaves) > 0 {
+		last := leaves[len(leaves)-1]
+		after := append(append([]byte{}, last.Key...), last.Index...)
+		resp.NextPageToken = pageToken{revision: req.Revision, after: after}.encode(digest)
+	}
+	return resp, nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle/maphasher"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+// keyMapTX is a map transaction with a single root at revision 1, whose leaves
+// are listed by key.
+type keyMapTX struct {
+	storage.MapTreeTX
+	root *trillian.SignedMapRoot
+	// leaves are ordered by key, and then by index.
+	leaves []*trillian.MapLeaf
+}
+
+func (k *keyMapTX) GetSignedMapRoot(ctx context.Context, revision int64) (*trillian.SignedMapRoot, error) {
+	if revision != 1 {
+		return nil, errors.New("no such root")
+	}
+	return k.root, nil
+}
+
+func (k *keyMapTX) ListLeavesByKeyPrefix(ctx context.Context, revision int64, prefix, afterKey, afterIndex []byte, limit int) ([]*trillian.MapLeaf, error) {
+	var ret []*trillian.MapLeaf
+	for _, l := range k.leaves {
+		if !bytes.HasPrefix(l.Key, prefix) || len(ret) == limit {
+			continue
+		}
+		if c := bytes.Compare(l.Key, afterKey); afterIndex != nil && (c < 0 || c == 0 && bytes.Compare(l.Index, afterIndex) <= 0) {
+			continue
+		}
+		ret = append(ret, proto.Clone(l).(*trillian.MapLeaf))
+	}
+	return ret, nil
+}
+
+func (k *keyMapTX) Commit(ctx context.Context) error { return nil }
+func (k *keyMapTX) Close() error                     { return nil }
+
+// keyIndexServer returns a map server of the given map transaction, whose map
+// has map_key_index set if keyIndex is, for up to maxRequests requests.
+func keyIndexServer(maxRequests int, ctrl *gomock.Controller, tx storage.ReadOnlyMapTreeTX, keyIndex bool, opts TrillianMapServerOptions) *TrillianMapServer {
+	tree := proto.Clone(stestonly.MapTree).(*trillian.Tree)
+	tree.TreeId = mapID1
+	tree.MapKeyIndex = keyIndex
+	adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+	adminTX.EXPECT().GetTree(gomock.Any(), mapID1).AnyTimes().Return(tree, nil)
+	adminTX.EXPECT().Close().AnyTimes().Return(nil)
+	adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+	adminTXs := make([]storage.ReadOnlyAdminTX, maxRequests)
+	for i := range adminTXs {
+		adminTXs[i] = adminTX
+	}
+	ms := storage.NewMockMapStorage(ctrl)
+	ms.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).AnyTimes().Return(tx, nil)
+	return NewTrillianMapServer(extension.Registry{
+		AdminStorage: &stestonly.FakeAdminStorage{ReadOnlyTX: adminTXs},
+		MapStorage:   ms,
+	}, opts)
+}
+
+func TestListLeavesByKeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	mapRoot, err := (&types.MapRootV1{RootHash: []byte("root"), Revision: 1}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	leaf := func(key string) *trillian.MapLeaf {
+		index := sha256.Sum256([]byte(key))
+		return &trillian.MapLeaf{Index: index[:], Key: []byte(key), LeafHash: []byte("hash"), LeafValue: []byte("value " + key)}
+	}
+	newTX := func() *keyMapTX {
+		return &keyMapTX{
+			root:   &trillian.SignedMapRoot{MapRoot: mapRoot},
+			leaves: []*trillian.MapLeaf{leaf("a1"), leaf("a2"), leaf("a3"), leaf("b1")},
+		}
+	}
+
+	for _, test := range []struct {
+		desc      string
+		pageSize  int32
+		maxBytes  int
+		wantPages int
+	}{
+		{desc: "onePage", wantPages: 1},
+		{desc: "pageSize", pageSize: 2, wantPages: 2},
+		{desc: "exactPageSize", pageSize: 3, wantPages: 2},
+		{desc: "maxResponseBytes", maxBytes: 1, wantPages: 3},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := keyIndexServer(test.wantPages, ctrl, newTX(), true, TrillianMapServerOptions{MaxResponseBytes: test.maxBytes})
+
+			req := &trillian.ListMapLeavesByKeyPrefixRequest{MapId: mapID1, Revision: 1, KeyPrefix: []byte("a"), PageSize: test.pageSize}
+			var got []*trillian.MapLeaf
+			pages := 0
+			for {
+				resp, err := server.ListLeavesByKeyPrefix(ctx, req)
+				if err != nil {
+					t.Fatalf("ListLeavesByKeyPrefix(): %v", err)
+				}
+				pages++
+				got = append(got, resp.Leaves...)
+				if len(resp.NextPageToken) == 0 {
+					break
+				}
+				req.PageToken = resp.NextPageToken
+			}
+			if pages != test.wantPages {
+				t.Errorf("ListLeavesByKeyPrefix() returned %d pages, want %d", pages, test.wantPages)
+			}
+			var want []*trillian.MapLeaf
+			for _, l := range newTX().leaves[:3] {
+				l.LeafHash = nil
+				want = append(want, l)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("ListLeavesByKeyPrefix() returned %d leaves, want %d", len(got), len(want))
+			}
+			for i := range got {
+				if !proto.Equal(got[i], want[i]) {
+					t.Errorf("leaf %d: %v, want %v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestListLeavesByKeyPrefixErrors(t *testing.T) {
+	ctx := context.Background()
+	otherToken := pageToken{revision: 1, after: make([]byte, 33)}.encode(requestDigest(mapID1, int64Param(1), []byte("b")))
+	for _, test := range []struct {
+		desc     string
+		req      *trillian.ListMapLeavesByKeyPrefixRequest
+		noIndex  bool
+		plainTX  bool
+		wantCode codes.Code
+	}{
+		{desc: "negativeRevision", req: &trillian.ListMapLeavesByKeyPrefixRequest{Revision: -1}, wantCode: codes.InvalidArgument},
+		{desc: "negativePageSize", req: &trillian.ListMapLeavesByKeyPrefixRequest{Revision: 1, PageSize: -1}, wantCode: codes.InvalidArgument},
+		{desc: "longPrefix", req: &trillian.ListMapLeavesByKeyPrefixRequest{Revision: 1, KeyPrefix: make([]byte, 256)}, wantCode: codes.InvalidArgument},
+		{desc: "otherToken", req: &trillian.ListMapLeavesByKeyPrefixRequest{Revision: 1, KeyPrefix: []byte("a"), PageToken: otherToken}, wantCode: codes.InvalidArgument},
+		{desc: "noKeyIndex", req: &trillian.ListMapLeavesByKeyPrefixRequest{Revision: 1}, noIndex: true, wantCode: codes.FailedPrecondition},
+		{desc: "unsupported", req: &trillian.ListMapLeavesByKeyPrefixRequest{Revision: 1}, plainTX: true, wantCode: codes.Unimplemented},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			var tx storage.ReadOnlyMapTreeTX = &keyMapTX{}
+			if test.plainTX {
+				// Hide ListLeavesByKeyPrefix.
+				tx = struct{ storage.ReadOnlyMapTreeTX }{tx}
+			}
+			server := keyIndexServer(1, ctrl, tx, !test.noIndex, TrillianMapServerOptions{})
+
+			test.req.MapId = mapID1
+			if _, err := server.ListLeavesByKeyPrefix(ctx, test.req); status.Code(err) != test.wantCode {
+				t.Errorf("ListLeavesByKeyPrefix(): %v, want code %v", err, test.wantCode)
+			}
+		})
+	}
+}
+
+func TestValidateLeafKeys(t *testing.T) {
+	index := sha256.Sum256([]byte("key"))
+	for _, test := range []struct {
+		desc     string
+		keyIndex bool
+		keySize  int
+		wantErr  bool
+	}{
+		{desc: "maxKey", keyIndex: true, keySize: storage.MaxMapLeafKeySize},
+		{desc: "longKey", keyIndex: true, keySize: storage.MaxMapLeafKeySize + 1, wantErr: true},
+		{desc: "longKeyNotIndexed", keySize: storage.MaxMapLeafKeySize + 1},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			tree := proto.Clone(stestonly.MapTree).(*trillian.Tree)
+			tree.MapKeyIndex = test.keyIndex
+			leaves := []*trillian.MapLeaf{{Index: index[:], Key: make([]byte, test.keySize), LeafValue: []byte("value")}}
+			server := NewTrillianMapServer(extension.Registry{}, TrillianMapServerOptions{})
+			err := server.validateLeaves(tree, maphasher.Default, leaves)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("validateLeaves(): %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr && status.Code(err) != codes.InvalidArgument {
+				t.Errorf("validateLeaves(): %v, want code %v", err, codes.InvalidArgument)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/treebatch"
+)
+
+// MapRevisionGCOptions holds the settings of a MapRevisionGC.
+type MapRevisionGCOptions struct {
+	// MapIDs are the IDs of the maps whose old revisions are deleted.
+	MapIDs []int64
+	// Interval is how often old revisions are deleted.
+	Interval time.Duration
+	// KeepRevisions is the number of revisions before the latest one of each
+	// map which are kept.
+	KeepRevisions int64
+	// MaxAge, if positive, additionally keeps the revisions of each map which
+	// were written less than MaxAge ago, along with the one which was latest
+	// at the time, so reads at any time since then are still served.
+	MaxAge time.Duration
+	// BatchSize is the maximum number of rows deleted per transaction.
+	BatchSize int
+	// MaxBatches is the maximum number of batches deleted per map in each run,
+	// see treebatch.Runner. Zero means no limit.
+	MaxBatches int
+}
+
+// MapRevisionGC deletes the roots, leaves and tiles of old revisions of maps.
+// Every revision of a map is stored, so that it can be read at any of them,
+// and so maps grow without bound unless revisions which are no longer read
+// are deleted. Revisions are kept by count, or by age, or both, in which case
+// a revision is kept if either keeps it. Reads at revisions which have been
+// deleted fail with NotFound, while those running as they are deleted may see
+// incomplete data, so the revisions kept should cover those which clients
+// still read. Revision 0 is always kept.
+//
+// Deleting revisions needs storage whose map transactions implement
+// storage.MapRevisionGCTX.
+type MapRevisionGC struct {
+	registry   extension.Registry
+	opts       MapRevisionGCOptions
+	timeSource clock.TimeSource
+	runner     treebatch.Runner
+
+	deleted monitoring.Counter
+	horizon monitoring.Gauge
+}
+
+// NewMapRevisionGC returns a MapRevisionGC for the maps in the given registry.
+func NewMapRevisionGC(registry extension.Registry, opts MapRevisionGCOptions) *MapRevisionGC {
+	mf := registry.MetricFactory
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1
+	}
+	return &MapRevisionGC{
+		registry:   registry,
+		opts:       opts,
+		timeSource: clock.System,
+		runner: treebatch.Runner{
+			Name:       "delete old revisions of map",
+			BatchSize:  opts.BatchSize,
+			MaxBatches: opts.MaxBatches,
+			Failures:   mf.NewCounter("map_gc_failures", "Number of times deleting the old revisions of a map failed", "treeid"),
+		},
+		deleted: mf.NewCounter("map_gc_deleted_rows", "Number of rows of old map revisions deleted from storage", "treeid"),
+		horizon: mf.NewGauge("map_gc_horizon", "Earliest revision of a map kept by the latest run of map revision GC", "treeid"),
+	}
+}
+
+// Run deletes the old revisions of the configured maps every Interval, until
+// ctx is done.
+func (g *MapRevisionGC) Run(ctx context.Context) {
+	for {
+		if err := clock.SleepContext(ctx, g.opts.Interval); err != nil {
+			return
+		}
+		if n, err := g.RunOnce(ctx); err != nil {
+			glog.Errorf("MapRevisionGC: %v", err)
+		} else if n > 0 {
+			glog.Infof("MapRevisionGC: deleted %d rows", n)
+		}
+	}
+}
+
+// RunOnce deletes the old revisions of each of the maps once, and returns the
+// number of rows deleted. It carries on past failures, and returns the first
+// one.
+func (g *MapRevisionGC) RunOnce(ctx context.Context) (int, error) {
+	return g.runner.Run(ctx, g.opts.MapIDs, g.collectMap)
+}
+
+// collectMap returns a function which deletes a batch of the rows of the old
+// revisions of the map in its own transaction.
+func (g *MapRevisionGC) collectMap(ctx context.Context, mapID int64) (treebatch.BatchFunc, error) {
+	tree, err := trees.GetTree(ctx, g.registry.AdminStorage, mapID, optsMapRead)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving map: %v", err)
+	}
+	ctx = trees.NewContext(ctx, tree)
+	label := strconv.FormatInt(mapID, 10)
+
+	return func() (int, error) {
+		var deleted int
+		err := g.registry.MapStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+			gctx, ok := tx.(storage.MapRevisionGCTX)
+			if !ok {
+				return treebatch.ErrUnsupported
+			}
+			horizon, err := g.horizonOf(ctx, tx, gctx)
+			if err != nil || horizon <= 0 {
+				return err
+			}
+			g.horizon.Set(float64(horizon), label)
+			deleted, err = gctx.DeleteRevisionsBefore(ctx, horizon, g.opts.BatchSize)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		g.deleted.Add(float64(deleted), label)
+		return deleted, nil
+	}, nil
+}
+
+// horizonOf returns the earliest revision of the map which is kept.
+func (g *MapRevisionGC) horizonOf(ctx context.Context, tx storage.MapTreeTX, gctx storage.MapRevisionGCTX) (int64, error) {
+	rev, err := tx.ReadRevision(ctx)
+	if err != nil {
+		return 0, err
+	}
+	horizon := rev - g.opts.KeepRevisions
+	if g.opts.MaxAge > 0 {
+		old, err := gctx.RevisionAt(ctx, g.timeSource.Now().Add(-g.opts.MaxAge))
+		if err != nil {
+			return 0, err
+		}
+		if old < horizon {
+			horizon = old
+		}
+	}
+	return horizon, nil
+}
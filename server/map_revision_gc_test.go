@@ -0,0 +1,169 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/clock"
+)
+
+// gcMapTX is a map transaction which records the old revisions it is asked to
+// delete, out of a number of rows left to delete.
+type gcMapTX struct {
+	storage.MapTreeTX
+	rev  int64
+	old  int64
+	left int
+
+	at       time.Time
+	horizons []int64
+}
+
+func (g *gcMapTX) ReadRevision(ctx context.Context) (int64, error) {
+	return g.rev, nil
+}
+
+func (g *gcMapTX) RevisionAt(ctx context.Context, at time.Time) (int64, error) {
+	g.at = at
+	return g.old, nil
+}
+
+func (g *gcMapTX) DeleteRevisionsBefore(ctx context.Context, horizon int64, limit int) (int, error) {
+	g.horizons = append(g.horizons, horizon)
+	n := g.left
+	if n > limit {
+		n = limit
+	}
+	g.left -= n
+	return n, nil
+}
+
+// plainMapTX is a map transaction which can't delete old revisions.
+type plainMapTX struct {
+	storage.MapTreeTX
+}
+
+func TestMapRevisionGC(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		desc        string
+		opts        MapRevisionGCOptions
+		tx          *gcMapTX
+		wantDeleted int
+		// wantHorizons are those of the transactions which deleted rows.
+		wantHorizons []int64
+	}{
+		{
+			desc:         "keepRevisions",
+			opts:         MapRevisionGCOptions{KeepRevisions: 3, BatchSize: 10},
+			tx:           &gcMapTX{rev: 10, left: 4},
+			wantDeleted:  4,
+			wantHorizons: []int64{7},
+		},
+		{
+			desc:         "maxAge",
+			opts:         MapRevisionGCOptions{KeepRevisions: 3, MaxAge: time.Hour, BatchSize: 10},
+			tx:           &gcMapTX{rev: 10, old: 5, left: 4},
+			wantDeleted:  4,
+			wantHorizons: []int64{5},
+		},
+		{
+			desc:         "maxAgeKeepsLess",
+			opts:         MapRevisionGCOptions{KeepRevisions: 3, MaxAge: time.Hour, BatchSize: 10},
+			tx:           &gcMapTX{rev: 10, old: 9, left: 4},
+			wantDeleted:  4,
+			wantHorizons: []int64{7},
+		},
+		{
+			desc: "youngMap",
+			opts: MapRevisionGCOptions{KeepRevisions: 3, BatchSize: 10},
+			tx:   &gcMapTX{rev: 3, left: 4},
+		},
+		{
+			desc:         "batches",
+			opts:         MapRevisionGCOptions{KeepRevisions: 3, BatchSize: 10},
+			tx:           &gcMapTX{rev: 10, left: 25},
+			wantDeleted:  25,
+			wantHorizons: []int64{7, 7, 7},
+		},
+		{
+			desc:         "maxBatches",
+			opts:         MapRevisionGCOptions{KeepRevisions: 3, BatchSize: 10, MaxBatches: 2},
+			tx:           &gcMapTX{rev: 10, left: 25},
+			wantDeleted:  20,
+			wantHorizons: []int64{7, 7},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ms := storage.NewMockMapStorage(ctrl)
+			ms.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, _ interface{}, f storage.MapTXFunc) error {
+				return f(ctx, test.tx)
+			})
+			test.opts.MapIDs = []int64{mapID1}
+			gc := NewMapRevisionGC(extension.Registry{
+				AdminStorage: fakeAdminStorageForMap(ctrl, mapID1),
+				MapStorage:   ms,
+			}, test.opts)
+			gc.timeSource = clock.NewFake(now)
+
+			n, err := gc.RunOnce(ctx)
+			if err != nil {
+				t.Fatalf("RunOnce(): %v", err)
+			}
+			if n != test.wantDeleted {
+				t.Errorf("RunOnce()=%d, want %d", n, test.wantDeleted)
+			}
+			if got, want := len(test.tx.horizons), len(test.wantHorizons); got != want {
+				t.Fatalf("DeleteRevisionsBefore() called %d times, want %d", got, want)
+			}
+			for i, want := range test.wantHorizons {
+				if got := test.tx.horizons[i]; got != want {
+					t.Errorf("DeleteRevisionsBefore() call %d: horizon %d, want %d", i, got, want)
+				}
+			}
+			if want := now.Add(-test.opts.MaxAge); test.opts.MaxAge > 0 && !test.tx.at.Equal(want) {
+				t.Errorf("RevisionAt(%v), want %v", test.tx.at, want)
+			}
+		})
+	}
+}
+
+func TestMapRevisionGCUnsupported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ms := storage.NewMockMapStorage(ctrl)
+	ms.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ interface{}, f storage.MapTXFunc) error {
+		return f(ctx, plainMapTX{})
+	})
+	gc := NewMapRevisionGC(extension.Registry{
+		AdminStorage: fakeAdminStorageForMap(ctrl, mapID1),
+		MapStorage:   ms,
+	}, MapRevisionGCOptions{MapIDs: []int64{mapID1}, KeepRevisions: 3, BatchSize: 10})
+	if n, err := gc.RunOnce(context.Background()); n != 0 || err != nil {
+		t.Errorf("RunOnce()=%d, %v, want 0, nil", n, err)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxWitnessNameLength is the maximum length of the names of witnesses, which
+// is that of the Witness column of MySQL storage.
+const maxWitnessNameLength = 255
+
+// AddMapRootSignature implements the RPC Method of the same name. The
+// co-signature is checked against the stored map root before it is stored,
+// so that only signatures which verify are served. Whether to trust the
+// witness is up to the clients.
+func (t *TrillianMapServer) AddMapRootSignature(ctx context.Context, req *trillian.AddMapRootSignatureRequest) (*trillian.AddMapRootSignatureResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "AddMapRootSignature")
+	defer spanEnd()
+	sig := req.GetSignature()
+	if req.Revision < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "map revision %d must be >= 0", req.Revision)
+	}
+	if len(sig.GetSignature()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "signature must be set")
+	}
+	if got, max := len(sig.Witness), maxWitnessNameLength; got > max {
+		return nil, status.Errorf(codes.InvalidArgument, "witness name has %d bytes, want at most %d", got, max)
+	}
+	pub, err := der.UnmarshalPublicKey(sig.PublicKey)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid public key: %v", err)
+	}
+	tree, ctx, err := t.getTreeAndContext(ctx, req.MapId, optsMapRead)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := trees.Hash(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.registry.MapStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+		stx, ok := tx.(storage.MapRootSignatureTX)
+		if !ok {
+			return status.Errorf(codes.Unimplemented, "map storage %T can't store map root signatures", tx)
+		}
+		root, err := tx.GetSignedMapRoot(ctx, req.Revision)
+		if err != nil {
+			return err
+		}
+		if err := tcrypto.Verify(pub, hash, root.MapRoot, sig.Signature); err != nil {
+			return status.Errorf(codes.InvalidArgument, "signature doesn't verify against the map root at revision %d: %v", req.Revision, err)
+		}
+		return stx.AddMapRootSignature(ctx, req.Revision, sig)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.AddMapRootSignatureResponse{}, nil
+}
+
+// GetMapRootSignatures implements the RPC Method of the same name.
+func (t *TrillianMapServer) GetMapRootSignatures(ctx context.Context, req *trillian.GetMapRootSignaturesRequest) (*trillian.GetMapRootSignaturesResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetMapRootSignatures")
+	defer spanEnd()
+	if req.Revision < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "map revision %d must be >= 0", req.Revision)
+	}
+	tree, ctx, err := t.getTreeAndContext(ctx, req.MapId, optsMapRead)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.snapshotAtRevision(ctx, tree, req.Revision, "GetMapRootSignatures")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetMapRootSignatures")
+
+	stx, ok := tx.(storage.MapRootSignatureTX)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "map storage %T can't store map root signatures", tx)
+	}
+	root, err := tx.GetSignedMapRoot(ctx, req.Revision)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.verifyRoot(ctx, tree, root); err != nil {
+		return nil, err
+	}
+	sigs, err := stx.GetMapRootSignatures(ctx, req.Revision)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &trillian.GetMapRootSignaturesResponse{MapRoot: root, Signatures: sigs}, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// sigMapTX is a map transaction with a single root, which stores its
+// co-signatures.
+type sigMapTX struct {
+	storage.MapTreeTX
+	root *trillian.SignedMapRoot
+	sigs []*trillian.MapRootSignature
+}
+
+func (s *sigMapTX) GetSignedMapRoot(ctx context.Context, revision int64) (*trillian.SignedMapRoot, error) {
+	if revision != 1 {
+		return nil, errors.New("no such root")
+	}
+	return s.root, nil
+}
+
+func (s *sigMapTX) AddMapRootSignature(ctx context.Context, revision int64, sig *trillian.MapRootSignature) error {
+	s.sigs = append(s.sigs, sig)
+	return nil
+}
+
+func (s *sigMapTX) GetMapRootSignatures(ctx context.Context, revision int64) ([]*trillian.MapRootSignature, error) {
+	return s.sigs, nil
+}
+
+func (s *sigMapTX) Commit(ctx context.Context) error { return nil }
+func (s *sigMapTX) Close() error                     { return nil }
+
+func TestMapRootSignatures(t *testing.T) {
+	ctx := context.Background()
+	mapRoot, err := (&types.MapRootV1{RootHash: []byte("root"), Revision: 1}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	pubDER, err := der.MarshalPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("MarshalPublicKey(): %v", err)
+	}
+	sign := func(data []byte) []byte {
+		sig, err := tcrypto.NewSigner(0, key, crypto.SHA256).Sign(data)
+		if err != nil {
+			t.Fatalf("Sign(): %v", err)
+		}
+		return sig
+	}
+	good := &trillian.MapRootSignature{Witness: "witness", PublicKey: pubDER, Signature: sign(mapRoot)}
+
+	for _, test := range []struct {
+		desc     string
+		req      *trillian.AddMapRootSignatureRequest
+		plainTX  bool
+		wantCode codes.Code
+	}{
+		{desc: "ok", req: &trillian.AddMapRootSignatureRequest{Revision: 1, Signature: good}},
+		{desc: "noSignature", req: &trillian.AddMapRootSignatureRequest{Revision: 1}, wantCode: codes.InvalidArgument},
+		{
+			desc:     "badKey",
+			req:      &trillian.AddMapRootSignatureRequest{Revision: 1, Signature: &trillian.MapRootSignature{PublicKey: []byte("key"), Signature: good.Signature}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "wrongData",
+			req:      &trillian.AddMapRootSignatureRequest{Revision: 1, Signature: &trillian.MapRootSignature{PublicKey: pubDER, Signature: sign([]byte("other"))}},
+			wantCode: codes.InvalidArgument,
+		},
+		{desc: "negativeRevision", req: &trillian.AddMapRootSignatureRequest{Revision: -1, Signature: good}, wantCode: codes.InvalidArgument},
+		{desc: "unsupported", req: &trillian.AddMapRootSignatureRequest{Revision: 1, Signature: good}, plainTX: true, wantCode: codes.Unimplemented},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tx := &sigMapTX{root: &trillian.SignedMapRoot{MapRoot: mapRoot}}
+			ms := storage.NewMockMapStorage(ctrl)
+			ms.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(1).DoAndReturn(func(ctx context.Context, _ interface{}, f storage.MapTXFunc) error {
+				if test.plainTX {
+					return f(ctx, plainMapTX{})
+				}
+				return f(ctx, tx)
+			})
+			server := NewTrillianMapServer(extension.Registry{
+				AdminStorage: fakeAdminStorageForMap(ctrl, mapID1),
+				MapStorage:   ms,
+			}, TrillianMapServerOptions{})
+
+			test.req.MapId = mapID1
+			_, err := server.AddMapRootSignature(ctx, test.req)
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Fatalf("AddMapRootSignature(): %v, want code %v", err, want)
+			}
+			if err != nil {
+				if len(tx.sigs) != 0 {
+					t.Errorf("AddMapRootSignature() failed, but stored %d signatures", len(tx.sigs))
+				}
+				return
+			}
+
+			ctrl2 := gomock.NewController(t)
+			defer ctrl2.Finish()
+			ms.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(tx, nil)
+			server = NewTrillianMapServer(extension.Registry{
+				AdminStorage: fakeAdminStorageForMap(ctrl2, mapID1),
+				MapStorage:   ms,
+			}, TrillianMapServerOptions{})
+			resp, err := server.GetMapRootSignatures(ctx, &trillian.GetMapRootSignaturesRequest{MapId: mapID1, Revision: 1})
+			if err != nil {
+				t.Fatalf("GetMapRootSignatures(): %v", err)
+			}
+			if !proto.Equal(resp.MapRoot, tx.root) {
+				t.Errorf("GetMapRootSignatures() returned root %v, want %v", resp.MapRoot, tx.root)
+			}
+			if got := resp.Signatures; len(got) != 1 || !proto.Equal(got[0], good) {
+				t.Errorf("GetMapRootSignatures() returned signatures %v, want [%v]", got, good)
+			}
+		})
+	}
+}
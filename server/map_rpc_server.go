@@ -15,23 +15,31 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/features"
 	"github.com/google/trillian/merkle"
 	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/merkle/hashers/registry"
 	"github.com/google/trillian/merkle/smt"
 	"github.com/google/trillian/monitoring"
+	serrors "github.com/google/trillian/server/errors"
+	"github.com/google/trillian/server/validators"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
 	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -61,6 +69,55 @@ type TrillianMapServerOptions struct {
 	// UseLargePreload enables the performance workaround applied when
 	// UseSingleTransaction is set.
 	UseLargePreload bool
+
+	// PinReadRevisions makes reads of a specific revision use transactions
+	// pinned to that revision up front, see MapStorage.SnapshotAtRevision,
+	// rather than ones whose revision is set by their first root read.
+	PinReadRevisions bool
+
+	// VerifyRootSignatures makes read RPCs check the signature of the roots
+	// they read from storage against the public key of the map before serving
+	// them, along with any leaves and proofs, and fail with DataLoss if it
+	// doesn't verify.
+	VerifyRootSignatures bool
+
+	// MaxResponseBytes makes GetLeaves, GetLeavesByRevision and
+	// ListLeavesByKeyPrefix truncate the leaves they return to keep their
+	// responses under it, which should be at most the maximum size of the
+	// messages clients receive, and return a page token to continue from.
+	// Zero means no limit.
+	MaxResponseBytes int
+
+	// MaxLeavesPerTransaction makes SetLeaves and WriteLeaves write the
+	// leaves of larger batches in chunks of at most that many leaves, each in
+	// a transaction of its own within the map write batch of the request,
+	// rather than all in the transaction which stores the new root, so that
+	// batches too large for one storage transaction can still be written. The
+	// map still gets a single new root, at the requested revision. Zero means
+	// no limit. It has no effect with UseSingleTransaction.
+	MaxLeavesPerTransaction int
+
+	// LeafValidators makes SetLeaves and WriteLeaves reject with
+	// InvalidArgument any batch of leaves with a value that the validator
+	// configured for the map doesn't accept. Empty values, which delete
+	// leaves, aren't validated.
+	LeafValidators *validators.Config
+
+	// HashWorkers is the number of goroutines hashing each shard of the map
+	// tree when leaves are written, see smt.NewParallelWriter. Below 2, each
+	// shard is hashed on a single goroutine.
+	HashWorkers int
+
+	// SkipUnchangedLeaves makes SetLeaves and WriteLeaves compare the leaves
+	// they write with those at the previous revision, and not store those
+	// whose hash, extra data and key are unchanged. This shrinks the storage of
+	// maps whose values mostly stay the same, at the cost of reading every
+	// written leaf.
+	SkipUnchangedLeaves bool
+
+	// TimeSource is used to timestamp queued mutations and to rate limit
+	// writes. Nil means clock.System.
+	TimeSource clock.TimeSource
 }
 
 // TrillianMapServer implements the RPC API defined in the proto
@@ -71,6 +128,10 @@ type TrillianMapServer struct {
 
 	setLeafCounter monitoring.Counter
 	getLeafCounter monitoring.Counter
+	rootVerifier   *rootVerifier
+	rateLimiter    *treeRateLimiter
+	initializer    *mapInitializer
+	timeSource     clock.TimeSource
 }
 
 // NewTrillianMapServer creates a new RPC server backed by registry
@@ -82,6 +143,10 @@ func NewTrillianMapServer(registry extension.Registry, opts TrillianMapServerOpt
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
 	}
+	ts := opts.TimeSource
+	if ts == nil {
+		ts = clock.System
+	}
 
 	return &TrillianMapServer{
 		registry: registry,
@@ -96,9 +161,21 @@ func NewTrillianMapServer(registry extension.Registry, opts TrillianMapServerOpt
 			"Number of map leaves request to be read",
 			"map_id",
 		),
+		rootVerifier: newRootVerifier(mf, "map_root_signature_failures"),
+		rateLimiter:  newTreeRateLimiter(mf, "map_rate_limited_requests"),
+		initializer:  newMapInitializer(registry.MapStorage),
+		timeSource:   ts,
 	}
 }
 
+// verifyRoot checks the signature of smr, if VerifyRootSignatures is set.
+func (t *TrillianMapServer) verifyRoot(ctx context.Context, tree *trillian.Tree, smr *trillian.SignedMapRoot) error {
+	if !t.opts.VerifyRootSignatures {
+		return nil
+	}
+	return t.rootVerifier.verifyMapRoot(ctx, tree, smr)
+}
+
 // IsHealthy returns nil if the server is healthy, error otherwise.
 func (t *TrillianMapServer) IsHealthy() error {
 	ctx, spanEnd := spanFor(context.Background(), "IsHealthy")
@@ -111,14 +188,14 @@ func (t *TrillianMapServer) IsHealthy() error {
 func (t *TrillianMapServer) GetLeaves(ctx context.Context, req *trillian.GetMapLeavesRequest) (*trillian.GetMapLeavesResponse, error) {
 	ctx, spanEnd := spanFor(ctx, "GetLeaves")
 	defer spanEnd()
-	return t.getLeavesByRevision(ctx, req.MapId, req.Index, mostRecentRevision)
+	return t.getLeavesByRevision(ctx, req.MapId, req.Index, mostRecentRevision, req.PageToken, req.CompactProofs)
 }
 
 // GetLeaf returns an inclusion proof to the leaf, or nil if the leaf does not exist.
 func (t *TrillianMapServer) GetLeaf(ctx context.Context, req *trillian.GetMapLeafRequest) (*trillian.GetMapLeafResponse, error) {
 	ctx, spanEnd := spanFor(ctx, "GetLeaf")
 	defer spanEnd()
-	ret, err := t.getLeavesByRevision(ctx, req.MapId, [][]byte{req.Index}, mostRecentRevision)
+	ret, err := t.getLeavesByRevision(ctx, req.MapId, [][]byte{req.Index}, mostRecentRevision, nil, req.CompactProofs)
 	if err != nil {
 		return nil, err
 	}
@@ -135,7 +212,7 @@ func (t *TrillianMapServer) GetLeaf(ctx context.Context, req *trillian.GetMapLea
 func (t *TrillianMapServer) GetLeafByRevision(ctx context.Context, req *trillian.GetMapLeafByRevisionRequest) (*trillian.GetMapLeafResponse, error) {
 	ctx, spanEnd := spanFor(ctx, "GetLeafByRevision")
 	defer spanEnd()
-	ret, err := t.getLeavesByRevision(ctx, req.MapId, [][]byte{req.Index}, req.Revision)
+	ret, err := t.getLeavesByRevision(ctx, req.MapId, [][]byte{req.Index}, req.Revision, nil, req.CompactProofs)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +232,7 @@ func (t *TrillianMapServer) GetLeavesByRevision(ctx context.Context, req *trilli
 	if req.Revision < 0 {
 		return nil, fmt.Errorf("map revision %d must be >= 0", req.Revision)
 	}
-	return t.getLeavesByRevision(ctx, req.MapId, req.Index, req.Revision)
+	return t.getLeavesByRevision(ctx, req.MapId, req.Index, req.Revision, req.PageToken, req.CompactProofs)
 }
 
 // GetLeavesByRevisionNoProof implements the GetLeavesByRevision RPC method.
@@ -163,15 +240,18 @@ func (t *TrillianMapServer) GetLeavesByRevisionNoProof(ctx context.Context, req
 	if req.Revision < 0 {
 		return nil, fmt.Errorf("map revision %d must be >= 0", req.Revision)
 	}
+	if len(req.PageToken) > 0 {
+		return nil, serrors.InvalidArgument("page_token", "GetLeavesByRevisionNoProof does not support page tokens")
+	}
 	tree, hasher, err := t.getTreeAndHasher(ctx, req.MapId, optsMapRead)
 	if err != nil {
 		return nil, fmt.Errorf("could not get map %v: %v", req.MapId, err)
 	}
-	if err := validateIndices(hasher.Size(), len(req.Index), func(i int) []byte { return req.Index[i] }); err != nil {
+	if err := validateIndices(hasher.Size(), len(req.Index), "index[%d]", func(i int) []byte { return req.Index[i] }); err != nil {
 		return nil, err
 	}
 
-	tx, err := t.snapshotForTree(ctx, tree, "GetLeavesByRevisionNoProof")
+	tx, err := t.snapshotAtRevision(ctx, tree, req.Revision, "GetLeavesByRevisionNoProof")
 	if err != nil {
 		return nil, fmt.Errorf("could not create database snapshot: %v", err)
 	}
@@ -190,109 +270,318 @@ func (t *TrillianMapServer) GetLeavesByRevisionNoProof(ctx context.Context, req
 	return &trillian.MapLeaves{Leaves: leaves}, nil
 }
 
-func (t *TrillianMapServer) getLeavesByRevision(ctx context.Context, mapID int64, indices [][]byte, revision int64) (*trillian.GetMapLeavesResponse, error) {
+// getLeavesByRevision returns the leaves at the given indices, and their
+// inclusion proofs, at a revision, or the latest one if it's negative,
+// continuing from where reqToken says a previous response stopped, if set.
+// The proofs are in compact form if compact is set.
+func (t *TrillianMapServer) getLeavesByRevision(ctx context.Context, mapID int64, indices [][]byte, revision int64, reqToken []byte, compact bool) (*trillian.GetMapLeavesResponse, error) {
 	tree, hasher, err := t.getTreeAndHasher(ctx, mapID, optsMapRead)
 	if err != nil {
 		return nil, fmt.Errorf("could not get map %v: %v", mapID, err)
 	}
+	compact = compact && features.Enabled(features.CompactMapProofs, mapID)
 
-	if err := validateIndices(hasher.Size(), len(indices), func(i int) []byte { return indices[i] }); err != nil {
+	if err := validateIndices(hasher.Size(), len(indices), "index[%d]", func(i int) []byte { return indices[i] }); err != nil {
 		return nil, err
 	}
 
+	digest := requestDigest(mapID, append([][]byte{int64Param(revision)}, indices...)...)
+	offset := 0
+	if len(reqToken) > 0 {
+		token, err := decodePageToken(reqToken, digest, "page_token")
+		if err != nil {
+			return nil, err
+		}
+		if token.offset > int64(len(indices)) || token.revision < 0 {
+			return nil, serrors.InvalidArgument("page_token", "page token offset %d or revision %d is out of range", token.offset, token.revision)
+		}
+		// Continuations read the revision the first response was read at.
+		offset, revision = int(token.offset), token.revision
+	}
+	indices = indices[offset:]
+
 	ctx = trees.NewContext(ctx, tree)
 	t.getLeafCounter.Add(float64(len(indices)), strconv.FormatInt(mapID, 10))
 
-	tx, err := t.snapshotForTree(ctx, tree, "GetLeavesByRevision")
+	tx, err := t.snapshotAtRevision(ctx, tree, revision, "GetLeavesByRevision")
 	if err != nil {
 		return nil, fmt.Errorf("could not create database snapshot: %v", err)
 	}
 	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetLeavesByRevision")
 
+	root, mapRoot, err := t.readRoot(ctx, tree, tx, revision)
+	if err != nil {
+		return nil, err
+	}
+	revision = int64(mapRoot.Revision)
+
+	resp := &trillian.GetMapLeavesResponse{MapRoot: root}
+	var leaves map[string]*trillian.MapLeaf
+	if budget := newResponseBudget(t.opts.MaxResponseBytes, resp); budget != nil {
+		// Only the proofs of the leaves which fit are fetched.
+		if leaves, err = getLeaves(ctx, tx, mapID, indices, revision); err != nil {
+			return nil, err
+		}
+		if n := fitLeaves(hasher, indices, leaves, compact, budget); n < len(indices) {
+			resp.NextPageToken = pageToken{offset: int64(offset + n), revision: revision}.encode(digest)
+			indices = indices[:n]
+		}
+	}
+
+	inclusions, _, err := t.getLeavesAndProofs(ctx, tx, mapID, hasher, indices, revision, leaves, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("could not commit db transaction: %v", err)
+	}
+
+	if compact {
+		compactProofs(inclusions)
+	}
+	resp.MapLeafInclusion = inclusions
+	return resp, nil
+}
+
+// readRoot returns the verified root of the given revision of the tree, or of
+// the latest one if revision is negative, and its contents.
+func (t *TrillianMapServer) readRoot(ctx context.Context, tree *trillian.Tree, tx storage.ReadOnlyMapTreeTX, revision int64) (*trillian.SignedMapRoot, *types.MapRootV1, error) {
 	var root *trillian.SignedMapRoot
 	if revision < 0 {
 		// need to know the newest published revision
 		r, err := tx.LatestSignedMapRoot(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("could not fetch the latest SignedMapRoot: %v", err)
+			return nil, nil, fmt.Errorf("could not fetch the latest SignedMapRoot: %v", err)
 		}
 		root = r
 	} else {
 		r, err := tx.GetSignedMapRoot(ctx, revision)
 		if err != nil {
-			return nil, fmt.Errorf("could not fetch SignedMapRoot %v: %v", revision, err)
+			return nil, nil, fmt.Errorf("could not fetch SignedMapRoot %v: %v", revision, err)
 		}
 		root = r
 	}
+	if err := t.verifyRoot(ctx, tree, root); err != nil {
+		return nil, nil, err
+	}
 
 	var mapRoot types.MapRootV1
 	if err := mapRoot.UnmarshalBinary(root.MapRoot); err != nil {
+		return nil, nil, err
+	}
+	return root, &mapRoot, nil
+}
+
+// fitLeaves returns how many of the leaves at the given indices fit in
+// budget, along with their inclusion proofs, whose size is bounded by that of
+// a proof with no empty siblings, plus its bitmap if the proofs are compact.
+func fitLeaves(hasher hashers.MapHasher, indices [][]byte, leaves map[string]*trillian.MapLeaf, compact bool, budget *responseBudget) int {
+	proof := make([][]byte, hasher.BitLen())
+	for i := range proof {
+		proof[i] = make([]byte, hasher.Size())
+	}
+	var bitmap []byte
+	if compact {
+		bitmap = make([]byte, (hasher.BitLen()+7)/8)
+	}
+	for i, index := range indices {
+		if !budget.take(proto.Size(&trillian.MapLeafInclusion{Leaf: leaves[string(index)], Inclusion: proof, InclusionBitmap: bitmap})) {
+			return i
+		}
+	}
+	return len(indices)
+}
+
+// GetLeavesByRevisions implements the GetLeavesByRevisions RPC method.
+func (t *TrillianMapServer) GetLeavesByRevisions(ctx context.Context, req *trillian.GetMapLeavesByRevisionsRequest) (*trillian.GetMapLeavesByRevisionsResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetLeavesByRevisions")
+	defer spanEnd()
+	if len(req.Revision) == 0 {
+		return nil, serrors.InvalidArgument("revision", "no map revisions requested")
+	}
+	seenRevisions := make(map[int64]bool)
+	for i, rev := range req.Revision {
+		if rev < 0 {
+			return nil, serrors.InvalidArgument(fmt.Sprintf("revision[%d]", i), "map revision %d must be >= 0", rev)
+		}
+		if seenRevisions[rev] {
+			return nil, serrors.InvalidArgument(fmt.Sprintf("revision[%d]", i), "duplicate map revision %d", rev)
+		}
+		seenRevisions[rev] = true
+	}
+
+	tree, hasher, err := t.getTreeAndHasher(ctx, req.MapId, optsMapRead)
+	if err != nil {
+		return nil, fmt.Errorf("could not get map %v: %v", req.MapId, err)
+	}
+	if err := validateIndices(hasher.Size(), len(req.Index), "index[%d]", func(i int) []byte { return req.Index[i] }); err != nil {
 		return nil, err
 	}
-	revision = int64(mapRoot.Revision)
 
+	ctx = trees.NewContext(ctx, tree)
+	t.getLeafCounter.Add(float64(len(req.Index)*len(req.Revision)), strconv.FormatInt(req.MapId, 10))
+
+	tx, err := t.snapshotForTree(ctx, tree, "GetLeavesByRevisions")
+	if err != nil {
+		return nil, fmt.Errorf("could not create database snapshot: %v", err)
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetLeavesByRevisions")
+
+	// The inclusion proofs of revisions with the same root hash are the same,
+	// so the tiles they need are only read once. The leaves are still read at
+	// each revision, as their extra data isn't covered by the root hash.
+	proofsByRoot := make(map[string]map[string][][]byte)
+	resps := make([]*trillian.GetMapLeavesResponse, 0, len(req.Revision))
+	for _, rev := range req.Revision {
+		root, err := tx.GetSignedMapRoot(ctx, rev)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch SignedMapRoot %v: %v", rev, err)
+		}
+		if err := t.verifyRoot(ctx, tree, root); err != nil {
+			return nil, err
+		}
+		var mapRoot types.MapRootV1
+		if err := mapRoot.UnmarshalBinary(root.MapRoot); err != nil {
+			return nil, err
+		}
+		inclusions, proofs, err := t.getLeavesAndProofs(ctx, tx, req.MapId, hasher, req.Index, rev, nil, proofsByRoot[string(mapRoot.RootHash)])
+		if err != nil {
+			return nil, err
+		}
+		proofsByRoot[string(mapRoot.RootHash)] = proofs
+		if req.CompactProofs && features.Enabled(features.CompactMapProofs, req.MapId) {
+			compactProofs(inclusions)
+		}
+		resps = append(resps, &trillian.GetMapLeavesResponse{
+			MapLeafInclusion: inclusions,
+			MapRoot:          root,
+		})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("could not commit db transaction: %v", err)
+	}
+	return &trillian.GetMapLeavesByRevisionsResponse{Revisions: resps}, nil
+}
+
+// GetLeavesCompact implements the GetLeavesCompact RPC method.
+func (t *TrillianMapServer) GetLeavesCompact(ctx context.Context, req *trillian.GetMapLeavesCompactRequest) (*trillian.GetMapLeavesCompactResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetLeavesCompact")
+	defer spanEnd()
+	if len(req.Index) == 0 {
+		return nil, serrors.InvalidArgument("index", "no map indices requested")
+	}
+	tree, hasher, err := t.getTreeAndHasher(ctx, req.MapId, optsMapRead)
+	if err != nil {
+		return nil, fmt.Errorf("could not get map %v: %v", req.MapId, err)
+	}
+	if err := validateIndices(hasher.Size(), len(req.Index), "index[%d]", func(i int) []byte { return req.Index[i] }); err != nil {
+		return nil, err
+	}
+
+	ctx = trees.NewContext(ctx, tree)
+	t.getLeafCounter.Add(float64(len(req.Index)), strconv.FormatInt(req.MapId, 10))
+
+	tx, err := t.snapshotAtRevision(ctx, tree, req.Revision, "GetLeavesCompact")
+	if err != nil {
+		return nil, fmt.Errorf("could not create database snapshot: %v", err)
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetLeavesCompact")
+
+	root, mapRoot, err := t.readRoot(ctx, tree, tx, req.Revision)
+	if err != nil {
+		return nil, err
+	}
+	revision := int64(mapRoot.Revision)
+
+	leavesByIndex, err := getLeaves(ctx, tx, req.MapId, req.Index, revision)
+	if err != nil {
+		return nil, err
+	}
+	smtReader := merkle.NewSparseMerkleTreeReader(revision, hasher, tx)
+	proof, err := smtReader.MultiInclusionProof(ctx, revision, req.Index)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch inclusion proof: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("could not commit db transaction: %v", err)
+	}
+
+	resp := &trillian.GetMapLeavesCompactResponse{
+		Leaves:  make([]*trillian.MapLeaf, len(req.Index)),
+		MapRoot: root,
+	}
+	for i, index := range req.Index {
+		resp.Leaves[i] = leavesByIndex[string(index)]
+	}
+	resp.ProofBitmap, resp.Proof = merkle.CompactInclusionProof(proof)
+	// The response can't be split into pages, as its proof is shared by all
+	// the leaves.
+	if maxBytes := t.opts.MaxResponseBytes; maxBytes > 0 && proto.Size(resp) > maxBytes {
+		return nil, status.Errorf(codes.ResourceExhausted, "response of %d bytes exceeds the maximum of %d bytes, request fewer indices", proto.Size(resp), maxBytes)
+	}
+	return resp, nil
+}
+
+// getLeavesAndProofs fetches the leaves with the given indices at revision, and
+// their inclusion proofs, unless they are passed in. Returns the leaves with
+// their proofs, and the proofs by index.
+func (t *TrillianMapServer) getLeavesAndProofs(ctx context.Context, tx storage.ReadOnlyMapTreeTX, mapID int64, hasher hashers.MapHasher, indices [][]byte, revision int64, leavesByIndex map[string]*trillian.MapLeaf, proofs map[string][][]byte) ([]*trillian.MapLeafInclusion, map[string][][]byte, error) {
 	// Fetch leaves and their inclusion proofs concurrently:
 	wg := &sync.WaitGroup{}
 
 	////////////////////////////////////////////////////
 	// Leaves
-	leavesByIndex := make(map[string]*trillian.MapLeaf)
 	errCh := make(chan error, 2)
 	defer close(errCh)
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-
-		leaves, err := tx.Get(ctx, revision, indices)
-		if err != nil {
-			errCh <- fmt.Errorf("could not fetch leaves: %v", err)
-			return
-		}
-		for _, l := range leaves {
-			leavesByIndex[string(l.Index)] = l
-		}
-		glog.V(1).Infof("%v: wanted %v leaves, found %v", mapID, len(indices), len(leaves))
-
-		// Add empty leaf values for indices that were not returned.
-		for _, index := range indices {
-			if _, ok := leavesByIndex[string(index)]; !ok {
-				leavesByIndex[string(index)] = &trillian.MapLeaf{Index: index}
+	if leavesByIndex == nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var err error
+			leavesByIndex, err = getLeaves(ctx, tx, mapID, indices, revision)
+			if err != nil {
+				errCh <- err
 			}
-		}
-	}()
+		}()
+	}
 	////////////////////////////////////////////////////
 
 	////////////////////////////////////////////////////
 	// Inclusion proofs
-	var proofs map[string][][]byte
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-
-		var err error
-		// Fetch inclusion proofs in parallel.
-		smtReader := merkle.NewSparseMerkleTreeReader(revision, hasher, tx)
-		proofs, err = smtReader.BatchInclusionProof(ctx, revision, indices)
-		if err != nil {
-			errCh <- fmt.Errorf("could not fetch inclusion proofs: %v", err)
-		}
-	}()
+	if proofs == nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var err error
+			// Fetch inclusion proofs in parallel.
+			smtReader := merkle.NewSparseMerkleTreeReader(revision, hasher, tx)
+			if features.Enabled(features.NodeID2Proofs, mapID) {
+				proofs, err = smtReader.BatchInclusionProofID2(ctx, revision, indices)
+			} else {
+				proofs, err = smtReader.BatchInclusionProof(ctx, revision, indices)
+			}
+			if err != nil {
+				errCh <- fmt.Errorf("could not fetch inclusion proofs: %v", err)
+			}
+		}()
+	}
 	////////////////////////////////////////////////////
 
 	wg.Wait()
 
 	select {
 	case e := <-errCh:
-		return nil, e
+		return nil, nil, e
 	default:
 		{
 		}
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("could not commit db transaction: %v", err)
-	}
-
 	inclusions := make([]*trillian.MapLeafInclusion, len(indices))
 	for i, index := range indices {
 		inclusions[i] = &trillian.MapLeafInclusion{
@@ -301,10 +590,36 @@ func (t *TrillianMapServer) getLeavesByRevision(ctx context.Context, mapID int64
 		}
 	}
 
-	return &trillian.GetMapLeavesResponse{
-		MapLeafInclusion: inclusions,
-		MapRoot:          root,
-	}, nil
+	return inclusions, proofs, nil
+}
+
+// compactProofs converts the inclusion proofs of inclusions into compact form.
+func compactProofs(inclusions []*trillian.MapLeafInclusion) {
+	for _, inc := range inclusions {
+		inc.InclusionBitmap, inc.Inclusion = merkle.CompactInclusionProof(inc.Inclusion)
+	}
+}
+
+// getLeaves returns the leaves at the given indices, by index, with empty
+// leaves for those which are not set.
+func getLeaves(ctx context.Context, tx storage.ReadOnlyMapTreeTX, mapID int64, indices [][]byte, revision int64) (map[string]*trillian.MapLeaf, error) {
+	leaves, err := tx.Get(ctx, revision, indices)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch leaves: %v", err)
+	}
+	leavesByIndex := make(map[string]*trillian.MapLeaf)
+	for _, l := range leaves {
+		leavesByIndex[string(l.Index)] = l
+	}
+	glog.V(1).Infof("%v: wanted %v leaves, found %v", mapID, len(indices), len(leaves))
+
+	// Add empty leaf values for indices that were not returned.
+	for _, index := range indices {
+		if _, ok := leavesByIndex[string(index)]; !ok {
+			leavesByIndex[string(index)] = &trillian.MapLeaf{Index: index}
+		}
+	}
+	return leavesByIndex, nil
 }
 
 // SetLeaves implements the SetLeaves RPC method.
@@ -314,7 +629,7 @@ func (t *TrillianMapServer) SetLeaves(ctx context.Context, req *trillian.SetMapL
 	t.setLeafCounter.Add(float64(len(req.Leaves)), strconv.FormatInt(req.MapId, 10))
 
 	if req.Revision <= 0 {
-		return nil, status.Error(codes.FailedPrecondition, "revision must be > 0")
+		return nil, serrors.FailedPrecondition("REVISION", "revision", "revision must be > 0")
 	}
 
 	if len(req.Leaves) == 0 {
@@ -329,23 +644,20 @@ func (t *TrillianMapServer) SetLeaves(ctx context.Context, req *trillian.SetMapL
 	if err != nil {
 		return nil, err
 	}
+	if err := t.rateLimiter.allow(tree, len(req.Leaves), t.timeSource.Now()); err != nil {
+		return nil, err
+	}
 	ctx = trees.NewContext(ctx, tree)
-
-	if err := validateIndices(hasher.Size(), len(req.Leaves), func(i int) []byte { return req.Leaves[i].Index }); err != nil {
+	if err := t.initializer.initIfNeeded(ctx, tree, hasher); err != nil {
 		return nil, err
 	}
 
-	// Overwrite/set the leaf hashes in the request and create a summary of
-	// the leaf indices and new hash values.
-	nodes := make([]smt.Node, 0, len(req.Leaves))
-	for _, l := range req.Leaves {
-		l.LeafHash = hasher.HashLeaf(tree.TreeId, l.Index, l.LeafValue)
-		nodes = append(nodes, smt.Node{
-			ID:   stree.NewNodeID2(string(l.Index), uint(hasher.BitLen())),
-			Hash: l.LeafHash,
-		})
+	if err := t.validateLeaves(tree, hasher, req.Leaves); err != nil {
+		return nil, err
 	}
 
+	nodes := hashMapLeaves(tree, hasher, req.Leaves)
+
 	layout, err := t.registry.MapStorage.Layout(tree)
 	if err != nil {
 		return nil, err
@@ -358,6 +670,37 @@ func (t *TrillianMapServer) SetLeaves(ctx context.Context, req *trillian.SetMapL
 		writeRev: req.Revision,
 		singleTX: t.opts.UseSingleTransaction,
 		preload:  t.opts.UseLargePreload,
+		workers:  t.opts.HashWorkers,
+	}
+
+	if !updater.singleTX {
+		// The tree is updated in multiple transactions, which storage keeps
+		// from being applied partially by tracking them as a batch.
+		batch, err := newMapWriteBatchID()
+		if err != nil {
+			return nil, err
+		}
+		ctx = storage.WithMapWriteBatch(ctx, batch, req.Revision)
+	}
+
+	leaves := req.Leaves
+	if max := t.opts.MaxLeavesPerTransaction; !updater.singleTX && max > 0 {
+		// All but the last chunk of leaves are written ahead of the
+		// transaction which stores the root, which the write batch keeps
+		// them hidden until.
+		for len(leaves) > max {
+			chunk := leaves[:max]
+			leaves = leaves[max:]
+			err := t.registry.MapStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+				if _, err := t.getWriteRevision(ctx, tree, tx, req.Revision); err != nil {
+					return err
+				}
+				return writeLeaves(ctx, tx, chunk, t.opts.SkipUnchangedLeaves)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	var newRoot *trillian.SignedMapRoot
@@ -368,14 +711,14 @@ func (t *TrillianMapServer) SetLeaves(ctx context.Context, req *trillian.SetMapL
 		}
 		glog.V(2).Infof("%v: Writing at revision %v", tree.TreeId, writeRev)
 
-		if err := t.writeLeaves(ctx, tx, req.Leaves); err != nil {
+		if err := writeLeaves(ctx, tx, leaves, t.opts.SkipUnchangedLeaves); err != nil {
 			return err
 		}
 		hash, err := updater.update(ctx, tx, nodes)
 		if err != nil {
 			return err
 		}
-		if newRoot, err = t.makeSignedMapRoot(ctx, tree, hash, writeRev, req.Metadata); err != nil {
+		if newRoot, err = makeSignedMapRoot(ctx, tree, hash, writeRev, req.Metadata); err != nil {
 			return fmt.Errorf("makeSignedMapRoot(): %v", err)
 		}
 		return tx.StoreSignedMapRoot(ctx, newRoot)
@@ -386,6 +729,61 @@ func (t *TrillianMapServer) SetLeaves(ctx context.Context, req *trillian.SetMapL
 	return &trillian.SetMapLeavesResponse{MapRoot: newRoot}, nil
 }
 
+// hashMapLeaves overwrites/sets the hashes of the given leaves, and returns a
+// summary of the leaf indices and new hash values. A leaf with neither a value
+// nor extra data deletes the key, so it gets a nil hash, which prunes it from
+// the tree.
+func hashMapLeaves(tree *trillian.Tree, hasher hashers.MapHasher, leaves []*trillian.MapLeaf) []smt.Node {
+	nodes := make([]smt.Node, 0, len(leaves))
+	for _, l := range leaves {
+		l.LeafHash = nil
+		if len(l.LeafValue) != 0 || len(l.ExtraData) != 0 {
+			l.LeafHash = hasher.HashLeaf(tree.TreeId, l.Index, l.LeafValue)
+		}
+		nodes = append(nodes, smt.Node{
+			ID:   stree.NewNodeID2(string(l.Index), uint(hasher.BitLen())),
+			Hash: l.LeafHash,
+		})
+	}
+	return nodes
+}
+
+// validateLeaves checks that the indices of leaves to be written to the map are
+// valid and unique, that their keys fit the key index of the map if it has
+// one, and that their values pass the leaf validator of the map.
+func (t *TrillianMapServer) validateLeaves(tree *trillian.Tree, hasher hashers.MapHasher, leaves []*trillian.MapLeaf) error {
+	if err := validateIndices(hasher.Size(), len(leaves), "leaves[%d].index", func(i int) []byte { return leaves[i].Index }); err != nil {
+		return err
+	}
+	if tree.MapKeyIndex {
+		for i, l := range leaves {
+			if got, max := len(l.Key), storage.MaxMapLeafKeySize; got > max {
+				return serrors.InvalidArgument(fmt.Sprintf("leaves[%d].key", i), "Leaves[%d].Key: has %d bytes, want at most %d", i, got, max)
+			}
+		}
+	}
+	if v := t.opts.LeafValidators.For(tree); v != nil {
+		for i, l := range leaves {
+			if len(l.LeafValue) == 0 {
+				continue
+			}
+			if err := v.Validate(l.LeafValue); err != nil {
+				return serrors.InvalidArgument(fmt.Sprintf("leaves[%d].leaf_value", i), "Leaves[%d].LeafValue: rejected by %s: %v", i, v.Spec, err)
+			}
+		}
+	}
+	return nil
+}
+
+// newMapWriteBatchID returns a random ID for a map write batch.
+func newMapWriteBatchID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", status.Errorf(codes.Internal, "failed to generate map write batch ID: %v", err)
+	}
+	return hex.EncodeToString(id[:]), nil
+}
+
 // getWriteRevision returns the revision that this transaction will be written
 // at, and asserts that it corresponds to assertRev and the read revision + 1.
 // Only one transaction can be committed for a given revision, so this one will
@@ -399,7 +797,7 @@ func (t *TrillianMapServer) getWriteRevision(ctx context.Context, tree *trillian
 		return 0, err
 	}
 	if writeRev != assertRev {
-		return 0, status.Errorf(codes.FailedPrecondition, "can't write to revision %v", assertRev)
+		return 0, serrors.FailedPrecondition("REVISION", "revision", "can't write to revision %v", assertRev)
 	}
 	if readRev, err := tx.ReadRevision(ctx); err != nil {
 		return 0, err
@@ -410,8 +808,42 @@ func (t *TrillianMapServer) getWriteRevision(ctx context.Context, tree *trillian
 }
 
 // writeLeaves updates the leaf values, but does not calculate nor update the Merkle tree.
-func (t *TrillianMapServer) writeLeaves(ctx context.Context, tx storage.MapTreeTX, leaves []*trillian.MapLeaf) error {
+// Deletions are only written for the keys which are set at the read revision,
+// so deleting missing keys doesn't grow the storage. If skipUnchanged is set,
+// leaves which are identical to those at the read revision, i.e. have the same
+// hash, extra data and key, aren't written either. Reads of later revisions find
+// the value written at the earlier one, so it carries forward without a
+// marker.
+func writeLeaves(ctx context.Context, tx storage.MapTreeTX, leaves []*trillian.MapLeaf, skipUnchanged bool) error {
+	var read [][]byte
+	for _, l := range leaves {
+		if skipUnchanged || storage.IsMapLeafDeletion(l) {
+			read = append(read, l.Index)
+		}
+	}
+	prev := make(map[string]*trillian.MapLeaf)
+	if len(read) > 0 {
+		readRev, err := tx.ReadRevision(ctx)
+		if err != nil {
+			return err
+		}
+		set, err := tx.Get(ctx, readRev, read)
+		if err != nil {
+			return err
+		}
+		for _, l := range set {
+			prev[string(l.Index)] = l
+		}
+	}
+
 	for _, l := range leaves {
+		p, present := prev[string(l.Index)]
+		if storage.IsMapLeafDeletion(l) && !present {
+			continue
+		}
+		if skipUnchanged && present && bytes.Equal(p.LeafHash, l.LeafHash) && bytes.Equal(p.ExtraData, l.ExtraData) && bytes.Equal(p.Key, l.Key) {
+			continue
+		}
 		if err := tx.Set(ctx, l.Index, l); err != nil {
 			return err
 		}
@@ -419,7 +851,7 @@ func (t *TrillianMapServer) writeLeaves(ctx context.Context, tx storage.MapTreeT
 	return nil
 }
 
-func (t *TrillianMapServer) makeSignedMapRoot(ctx context.Context, tree *trillian.Tree,
+func makeSignedMapRoot(ctx context.Context, tree *trillian.Tree,
 	rootHash []byte, revision int64, meta []byte) (*trillian.SignedMapRoot, error) {
 	smr := &types.MapRootV1{
 		RootHash:       rootHash,
@@ -456,6 +888,9 @@ func (t *TrillianMapServer) GetSignedMapRoot(ctx context.Context, req *trillian.
 	if err != nil {
 		return nil, err
 	}
+	if err := t.verifyRoot(ctx, tree, r); err != nil {
+		return nil, err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		glog.Warningf("%v: Commit failed for GetSignedMapRoot: %v", req.MapId, err)
@@ -477,7 +912,7 @@ func (t *TrillianMapServer) GetSignedMapRootByRevision(ctx context.Context, req
 	if err != nil {
 		return nil, err
 	}
-	tx, err := t.snapshotForTree(ctx, tree, "GetSignedMapRootByRevision")
+	tx, err := t.snapshotAtRevision(ctx, tree, req.Revision, "GetSignedMapRootByRevision")
 	if err != nil {
 		return nil, err
 	}
@@ -487,6 +922,9 @@ func (t *TrillianMapServer) GetSignedMapRootByRevision(ctx context.Context, req
 	if err != nil {
 		return nil, err
 	}
+	if err := t.verifyRoot(ctx, tree, r); err != nil {
+		return nil, err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		glog.Warningf("%v: Commit failed for GetSignedMapRootByRevision: %v", req.MapId, err)
@@ -501,7 +939,7 @@ func (t *TrillianMapServer) getTreeAndHasher(ctx context.Context, treeID int64,
 	if err != nil {
 		return nil, nil, err
 	}
-	th, err := registry.NewMapHasher(tree.HashStrategy)
+	th, err := registry.NewMapHasherForTree(tree)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -538,9 +976,19 @@ func (t *TrillianMapServer) addRevision(ctx context.Context, treeID, rev int64,
 		return nil, status.Errorf(codes.FailedPrecondition, "getTreeAndHasher(): %v", err)
 	}
 	ctx = trees.NewContext(ctx, tree)
+	if rev > 0 {
+		if err := t.initializer.initIfNeeded(ctx, tree, hasher); err != nil {
+			return nil, err
+		}
+	}
+	return addMapRevision(ctx, t.registry.MapStorage, tree, hasher, rev, meta)
+}
 
+// addMapRevision adds a new revision of the map, with the same root hash as
+// the previous one, or the empty root hash if rev is 0.
+func addMapRevision(ctx context.Context, ms storage.MapStorage, tree *trillian.Tree, hasher hashers.MapHasher, rev int64, meta []byte) (*trillian.SignedMapRoot, error) {
 	var newSMR *trillian.SignedMapRoot
-	if err := t.registry.MapStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+	if err := ms.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
 		// Check that the map has an existing root, or needs initialising.
 		smr, err := tx.LatestSignedMapRoot(ctx)
 		if err != nil && (err != storage.ErrTreeNeedsInit || rev != 0) {
@@ -559,14 +1007,14 @@ func (t *TrillianMapServer) addRevision(ctx context.Context, treeID, rev int64,
 				return status.Errorf(codes.Internal, "UnmarshalBinary: %v", err)
 			}
 			if got, want := root.Revision, uint64(rev-1); got != want {
-				return status.Errorf(codes.FailedPrecondition, "can't write revision %d, latest is %d", rev, got)
+				return serrors.FailedPrecondition("REVISION", "revision", "can't write revision %d, latest is %d", rev, got)
 			}
 			hash = root.RootHash
 		} else {
 			return status.Error(codes.Internal, "LatestSignedMapRoot is nil")
 		}
 
-		if newSMR, err = t.makeSignedMapRoot(ctx, tree, hash, rev, meta); err != nil {
+		if newSMR, err = makeSignedMapRoot(ctx, tree, hash, rev, meta); err != nil {
 			return status.Errorf(codes.Internal, "makeSignedMapRoot(): %v", err)
 		}
 		return tx.StoreSignedMapRoot(ctx, newSMR)
@@ -593,11 +1041,23 @@ func (t *TrillianMapServer) snapshotForTree(ctx context.Context, tree *trillian.
 	return tx, err
 }
 
+// snapshotAtRevision starts a read-only transaction for reading the given
+// revision of the tree, which is pinned to it if PinReadRevisions is set. A
+// negative revision stands for the latest one, which isn't pinned.
+func (t *TrillianMapServer) snapshotAtRevision(ctx context.Context, tree *trillian.Tree, revision int64, method string) (storage.ReadOnlyMapTreeTX, error) {
+	if !t.opts.PinReadRevisions || revision < 0 {
+		return t.snapshotForTree(ctx, tree, method)
+	}
+	return t.registry.MapStorage.SnapshotAtRevision(ctx, tree, revision)
+}
+
 // validateIndices confirms that all indices have the given size and there are no duplicates.
 // indexSize is the expected size of each index in bytes.
 // n is the number of indices to check.
+// field is the format of the paths of the indices in the request, given their
+// position.
 // indices is a function that returns indices from [0 .. n).
-func validateIndices(indexSize, n int, indices func(i int) []byte) error {
+func validateIndices(indexSize, n int, field string, indices func(i int) []byte) error {
 	// The parameter is named 'index' (here and in the RPC API) because it's the ordinal number
 	// of the leaf, but that number is obtained by hashing the key value that corresponds to the
 	// leaf.  Leaf "indices" are therefore sparsely scattered in the range [0, 2^hashsize) and
@@ -610,10 +1070,10 @@ func validateIndices(indexSize, n int, indices func(i int) []byte) error {
 	for i := 0; i < n; i++ {
 		index := indices(i)
 		if got, want := len(index), indexSize; got != want {
-			return status.Errorf(codes.InvalidArgument, "index at position %d has wrong length: got=%d,want=%d", i, got, want)
+			return serrors.InvalidArgument(fmt.Sprintf(field, i), "index at position %d has wrong length: got=%d,want=%d", i, got, want)
 		}
 		if seenIndices[string(index)] {
-			return status.Errorf(codes.InvalidArgument, "duplicate index detected at position %d", i)
+			return serrors.InvalidArgument(fmt.Sprintf(field, i), "duplicate index detected at position %d", i)
 		}
 		seenIndices[string(index)] = true
 	}
@@ -18,6 +18,8 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -25,6 +27,11 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/features"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/maphasher"
+	"github.com/google/trillian/merkle/mapverifier"
+	"github.com/google/trillian/merkle/smt"
 	"github.com/google/trillian/storage"
 	stestonly "github.com/google/trillian/storage/testonly"
 	"github.com/google/trillian/storage/tree"
@@ -270,6 +277,7 @@ func TestGetSignedMapRootByRevision(t *testing.T) {
 		desc               string
 		req                *trillian.GetSignedMapRootByRevisionRequest
 		mapRoot            *trillian.SignedMapRoot
+		pinned             bool
 		snapShErr, lsmrErr error
 		wantErr            bool
 	}{
@@ -297,6 +305,19 @@ func TestGetSignedMapRootByRevision(t *testing.T) {
 				Signature: []byte("0F\002!\000\307b\255\223\353\23615&\022\263\323\341\342+\276\274$\rX?\366\014U\362\006\376\0269rcm\002!\000\241*\255\220\301\263D\033\275\374\340A\377\337\354\202\331%au\3179\000O\r9\237\302\021\r\363\263"),
 			},
 		},
+		{
+			desc:    "Request pinned revision",
+			req:     &trillian.GetSignedMapRootByRevisionRequest{MapId: mapID1, Revision: 1},
+			mapRoot: makeSMR(t, 1),
+			pinned:  true,
+		},
+		{
+			desc:      "Request pinned revision which doesn't exist",
+			req:       &trillian.GetSignedMapRootByRevisionRequest{MapId: mapID1, Revision: 123},
+			pinned:    true,
+			snapShErr: status.Error(codes.NotFound, "map root 123 not found"),
+			wantErr:   true,
+		},
 	}
 
 	for _, test := range tests {
@@ -309,7 +330,11 @@ func TestGetSignedMapRootByRevision(t *testing.T) {
 			mockTX := storage.NewMockMapTreeTX(ctrl)
 
 			if !test.wantErr || !(test.lsmrErr == nil && test.snapShErr == nil) {
-				fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(mockTX, test.snapShErr)
+				if test.pinned {
+					fakeStorage.EXPECT().SnapshotAtRevision(gomock.Any(), gomock.Any(), test.req.Revision).Return(mockTX, test.snapShErr)
+				} else {
+					fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(mockTX, test.snapShErr)
+				}
 				if test.snapShErr == nil {
 					mockTX.EXPECT().GetSignedMapRoot(gomock.Any(), test.req.Revision).Return(test.mapRoot, test.lsmrErr)
 					if test.lsmrErr == nil {
@@ -323,7 +348,7 @@ func TestGetSignedMapRootByRevision(t *testing.T) {
 			server := NewTrillianMapServer(extension.Registry{
 				AdminStorage: adminStorage,
 				MapStorage:   fakeStorage,
-			}, TrillianMapServerOptions{})
+			}, TrillianMapServerOptions{PinReadRevisions: test.pinned})
 
 			smrResp, err := server.GetSignedMapRootByRevision(ctx, test.req)
 
@@ -359,6 +384,437 @@ func makeSMR(t *testing.T, rev uint64) *trillian.SignedMapRoot {
 	}
 }
 
+func TestGetLeavesByRevisions(t *testing.T) {
+	ctx := context.Background()
+	index := bytes.Repeat([]byte{0x01}, 32)
+
+	for _, test := range []struct {
+		desc          string
+		revisions     []int64
+		rootHashes    map[int64]string
+		wantCode      codes.Code
+		wantNodeReads []int64
+	}{
+		{desc: "no-revisions", wantCode: codes.InvalidArgument},
+		{desc: "negative-revision", revisions: []int64{1, -1}, wantCode: codes.InvalidArgument},
+		{desc: "duplicate-revision", revisions: []int64{1, 2, 1}, wantCode: codes.InvalidArgument},
+		{
+			desc:          "distinct-roots",
+			revisions:     []int64{2, 1},
+			rootHashes:    map[int64]string{1: "hash1", 2: "hash2"},
+			wantNodeReads: []int64{2, 1},
+		},
+		{
+			desc:          "shared-roots",
+			revisions:     []int64{1, 2, 3},
+			rootHashes:    map[int64]string{1: "hash1", 2: "hash1", 3: "hash3"},
+			wantNodeReads: []int64{1, 3},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			adminStorage := fakeAdminStorageForMap(ctrl, mapID1)
+			fakeStorage := storage.NewMockMapStorage(ctrl)
+			mockTX := storage.NewMockMapTreeTX(ctrl)
+
+			var nodeReads []int64
+			if test.wantCode == codes.OK {
+				fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(mockTX, nil)
+				for rev, hash := range test.rootHashes {
+					mapRoot, err := (&types.MapRootV1{Revision: uint64(rev), RootHash: []byte(hash)}).MarshalBinary()
+					if err != nil {
+						t.Fatalf("MarshalBinary(): %v", err)
+					}
+					mockTX.EXPECT().GetSignedMapRoot(gomock.Any(), rev).Return(&trillian.SignedMapRoot{MapRoot: mapRoot}, nil)
+					mockTX.EXPECT().Get(gomock.Any(), rev, [][]byte{index}).Return(nil, nil)
+				}
+				mockTX.EXPECT().GetMerkleNodes(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+					func(_ context.Context, rev int64, _ []tree.NodeID) ([]tree.Node, error) {
+						if n := len(nodeReads); n == 0 || nodeReads[n-1] != rev {
+							nodeReads = append(nodeReads, rev)
+						}
+						return nil, nil
+					})
+				mockTX.EXPECT().Commit(gomock.Any()).Return(nil)
+				mockTX.EXPECT().Close().Return(nil)
+				mockTX.EXPECT().IsOpen().AnyTimes().Return(false)
+			}
+
+			server := NewTrillianMapServer(extension.Registry{
+				AdminStorage: adminStorage,
+				MapStorage:   fakeStorage,
+			}, TrillianMapServerOptions{})
+
+			resp, err := server.GetLeavesByRevisions(ctx, &trillian.GetMapLeavesByRevisionsRequest{
+				MapId:    mapID1,
+				Index:    [][]byte{index},
+				Revision: test.revisions,
+			})
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Fatalf("GetLeavesByRevisions(): %v, want code %v", err, want)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := len(resp.Revisions), len(test.revisions); got != want {
+				t.Fatalf("GetLeavesByRevisions(): %d responses, want %d", got, want)
+			}
+			for i, r := range resp.Revisions {
+				var mapRoot types.MapRootV1
+				if err := mapRoot.UnmarshalBinary(r.MapRoot.MapRoot); err != nil {
+					t.Fatalf("UnmarshalBinary(): %v", err)
+				}
+				if got, want := int64(mapRoot.Revision), test.revisions[i]; got != want {
+					t.Errorf("response %d: revision %d, want %d", i, got, want)
+				}
+				if got := len(r.MapLeafInclusion); got != 1 {
+					t.Errorf("response %d: %d leaves, want 1", i, got)
+				}
+			}
+			if diff := cmp.Diff(nodeReads, test.wantNodeReads); diff != "" {
+				t.Errorf("nodes read at revisions diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetLeavesPaging(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mapTree := proto.Clone(stestonly.MapTree).(*trillian.Tree)
+	mapTree.TreeId = mapID1
+	adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+	adminTX.EXPECT().GetTree(gomock.Any(), mapID1).AnyTimes().Return(mapTree, nil)
+	adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+	adminTX.EXPECT().Close().AnyTimes().Return(nil)
+	adminStorage := storage.NewMockAdminStorage(ctrl)
+	adminStorage.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+
+	const revision = 5
+	mapRoot, err := (&types.MapRootV1{Revision: revision, RootHash: []byte("hash")}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	root := &trillian.SignedMapRoot{MapRoot: mapRoot}
+	var indices [][]byte
+	leaves := make(map[string]*trillian.MapLeaf)
+	for i := 0; i < 40; i++ {
+		index := bytes.Repeat([]byte{byte(i)}, 32)
+		indices = append(indices, index)
+		leaves[string(index)] = &trillian.MapLeaf{Index: index, LeafValue: bytes.Repeat([]byte{byte(i)}, 1000)}
+	}
+
+	mockTX := storage.NewMockMapTreeTX(ctrl)
+	// Only the first page reads the latest root: the others read the same
+	// revision, even if the map has grown since.
+	mockTX.EXPECT().LatestSignedMapRoot(gomock.Any()).Return(root, nil)
+	mockTX.EXPECT().GetSignedMapRoot(gomock.Any(), int64(revision)).AnyTimes().Return(root, nil)
+	mockTX.EXPECT().Get(gomock.Any(), int64(revision), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, _ int64, indices [][]byte) ([]*trillian.MapLeaf, error) {
+			var ret []*trillian.MapLeaf
+			for _, index := range indices {
+				ret = append(ret, leaves[string(index)])
+			}
+			return ret, nil
+		})
+	mockTX.EXPECT().GetMerkleNodes(gomock.Any(), int64(revision), gomock.Any()).AnyTimes().Return(nil, nil)
+	mockTX.EXPECT().Commit(gomock.Any()).AnyTimes().Return(nil)
+	mockTX.EXPECT().Close().AnyTimes().Return(nil)
+	mockTX.EXPECT().IsOpen().AnyTimes().Return(false)
+	fakeStorage := storage.NewMockMapStorage(ctrl)
+	fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).AnyTimes().Return(mockTX, nil)
+
+	const maxBytes = 40 << 10
+	server := NewTrillianMapServer(extension.Registry{
+		AdminStorage: adminStorage,
+		MapStorage:   fakeStorage,
+	}, TrillianMapServerOptions{MaxResponseBytes: maxBytes})
+
+	req := &trillian.GetMapLeavesRequest{MapId: mapID1, Index: indices}
+	var got []*trillian.MapLeaf
+	pages := 0
+	for {
+		resp, err := server.GetLeaves(ctx, req)
+		if err != nil {
+			t.Fatalf("GetLeaves(): %v", err)
+		}
+		if size := proto.Size(resp); size > maxBytes {
+			t.Errorf("GetLeaves(): response of %d bytes, want at most %d", size, maxBytes)
+		}
+		for _, inc := range resp.MapLeafInclusion {
+			got = append(got, inc.Leaf)
+		}
+		pages++
+		if len(resp.NextPageToken) == 0 {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	if got, want := len(got), len(indices); got != want {
+		t.Fatalf("GetLeaves(): %d leaves, want %d", got, want)
+	}
+	for i, leaf := range got {
+		if !proto.Equal(leaf, leaves[string(indices[i])]) {
+			t.Errorf("GetLeaves(): leaf %d is for index %x, want %x", i, leaf.Index, indices[i])
+		}
+	}
+	if pages < 2 {
+		t.Errorf("GetLeaves(): %d pages, want several", pages)
+	}
+
+	// Page tokens only continue the requests they were returned for.
+	for _, req := range []*trillian.GetMapLeavesRequest{
+		{MapId: mapID1, Index: indices, PageToken: []byte("garbage")},
+		{MapId: mapID1, Index: indices[1:], PageToken: req.PageToken},
+	} {
+		if _, err := server.GetLeaves(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("GetLeaves(%x): %v, want InvalidArgument", req.PageToken, err)
+		}
+	}
+}
+
+func TestGetLeavesCompactProofs(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mapTree := proto.Clone(stestonly.MapTree).(*trillian.Tree)
+	mapTree.TreeId = mapID1
+	adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+	adminTX.EXPECT().GetTree(gomock.Any(), mapID1).AnyTimes().Return(mapTree, nil)
+	adminTX.EXPECT().Commit().AnyTimes().Return(nil)
+	adminTX.EXPECT().Close().AnyTimes().Return(nil)
+	adminStorage := storage.NewMockAdminStorage(ctrl)
+	adminStorage.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(adminTX, nil)
+
+	const revision = 5
+	mapRoot, err := (&types.MapRootV1{Revision: revision, RootHash: []byte("hash")}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	index := bytes.Repeat([]byte{0x01}, 32)
+	leaf := &trillian.MapLeaf{Index: index, LeafValue: []byte("value")}
+
+	mockTX := storage.NewMockMapTreeTX(ctrl)
+	mockTX.EXPECT().GetSignedMapRoot(gomock.Any(), int64(revision)).AnyTimes().Return(&trillian.SignedMapRoot{MapRoot: mapRoot}, nil)
+	mockTX.EXPECT().Get(gomock.Any(), int64(revision), [][]byte{index}).AnyTimes().Return([]*trillian.MapLeaf{leaf}, nil)
+	// The map has no other leaves, so all the entries of the proof are nil.
+	mockTX.EXPECT().GetMerkleNodes(gomock.Any(), int64(revision), gomock.Any()).AnyTimes().Return(nil, nil)
+	mockTX.EXPECT().Commit(gomock.Any()).AnyTimes().Return(nil)
+	mockTX.EXPECT().Close().AnyTimes().Return(nil)
+	mockTX.EXPECT().IsOpen().AnyTimes().Return(false)
+	fakeStorage := storage.NewMockMapStorage(ctrl)
+	fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).AnyTimes().Return(mockTX, nil)
+
+	server := NewTrillianMapServer(extension.Registry{
+		AdminStorage: adminStorage,
+		MapStorage:   fakeStorage,
+	}, TrillianMapServerOptions{})
+
+	full, err := server.GetLeavesByRevision(ctx, &trillian.GetMapLeavesByRevisionRequest{MapId: mapID1, Index: [][]byte{index}, Revision: revision})
+	if err != nil {
+		t.Fatalf("GetLeavesByRevision(): %v", err)
+	}
+	compact, err := server.GetLeavesByRevision(ctx, &trillian.GetMapLeavesByRevisionRequest{MapId: mapID1, Index: [][]byte{index}, Revision: revision, CompactProofs: true})
+	if err != nil {
+		t.Fatalf("GetLeavesByRevision(compact): %v", err)
+	}
+
+	fullInc, compactInc := full.MapLeafInclusion[0], compact.MapLeafInclusion[0]
+	if got, want := len(fullInc.Inclusion), 256; got != want {
+		t.Errorf("full proof has %d entries, want %d", got, want)
+	}
+	if got := len(fullInc.InclusionBitmap); got != 0 {
+		t.Errorf("full proof has a bitmap of %d bytes, want none", got)
+	}
+	if got, want := compactInc.InclusionBitmap, make([]byte, 32); !bytes.Equal(got, want) {
+		t.Errorf("compact proof bitmap: %x, want %x", got, want)
+	}
+	if got := len(compactInc.Inclusion); got != 0 {
+		t.Errorf("compact proof has %d entries, want 0", got)
+	}
+	expanded, err := merkle.ExpandInclusionProof(compactInc.InclusionBitmap, compactInc.Inclusion, 256)
+	if err != nil {
+		t.Fatalf("ExpandInclusionProof(): %v", err)
+	}
+	if !cmp.Equal(expanded, fullInc.Inclusion) {
+		t.Errorf("expanded compact proof differs from the full proof")
+	}
+	if fullSize, compactSize := proto.Size(fullInc), proto.Size(compactInc); compactSize*5 > fullSize {
+		t.Errorf("compact proof of %d bytes, want it much smaller than the full proof of %d bytes", compactSize, fullSize)
+	}
+	if !proto.Equal(compactInc.Leaf, leaf) {
+		t.Errorf("compact response leaf: %v, want %v", compactInc.Leaf, leaf)
+	}
+
+	// Full proofs are returned while compact proofs are turned off.
+	defer features.Default.Replace(nil)
+	if err := features.Default.Apply([]features.Override{{Name: features.CompactMapProofs, TreeID: mapID1}}, nil); err != nil {
+		t.Fatalf("Apply(): %v", err)
+	}
+	disabled, err := server.GetLeavesByRevision(ctx, &trillian.GetMapLeavesByRevisionRequest{MapId: mapID1, Index: [][]byte{index}, Revision: revision, CompactProofs: true})
+	if err != nil {
+		t.Fatalf("GetLeavesByRevision(compact, disabled): %v", err)
+	}
+	if !proto.Equal(disabled.MapLeafInclusion[0], fullInc) {
+		t.Errorf("GetLeavesByRevision(compact, disabled): got a compact proof, want the full proof")
+	}
+
+	// Full proofs are the same when their node IDs are computed as legacy
+	// tree.NodeIDs.
+	if err := features.Default.Apply([]features.Override{{Name: features.NodeID2Proofs, TreeID: mapID1}}, nil); err != nil {
+		t.Fatalf("Apply(): %v", err)
+	}
+	legacy, err := server.GetLeavesByRevision(ctx, &trillian.GetMapLeavesByRevisionRequest{MapId: mapID1, Index: [][]byte{index}, Revision: revision})
+	if err != nil {
+		t.Fatalf("GetLeavesByRevision(legacy node IDs): %v", err)
+	}
+	if !proto.Equal(legacy.MapLeafInclusion[0], fullInc) {
+		t.Errorf("GetLeavesByRevision(legacy node IDs): got %v, want %v", legacy.MapLeafInclusion[0], fullInc)
+	}
+}
+
+func TestGetLeavesCompact(t *testing.T) {
+	ctx := context.Background()
+	hasher := maphasher.Default
+	const revision = 5
+
+	// Build a map of 100 leaves, keeping the hashes of all its nodes.
+	nodes := make(map[tree.NodeID2][]byte)
+	leaves := make(map[string]*trillian.MapLeaf)
+	var values []*merkle.HStar2LeafHash
+	for i := 0; i < 100; i++ {
+		index := testonly.HashKey(fmt.Sprintf("key-%d", i))
+		value := []byte(fmt.Sprintf("value-%d", i))
+		leaf := &trillian.MapLeaf{Index: index, LeafValue: value, LeafHash: hasher.HashLeaf(mapID1, index, value)}
+		leaves[string(index)] = leaf
+		nodes[tree.NewNodeID2(string(index), 256)] = leaf.LeafHash
+		values = append(values, &merkle.HStar2LeafHash{Index: new(big.Int).SetBytes(index), LeafHash: leaf.LeafHash})
+	}
+	hs := merkle.NewHStar2(mapID1, hasher)
+	rootHash, err := hs.HStar2Nodes(nil, hasher.BitLen(), values, nil, func(depth int, index *big.Int, hash []byte) error {
+		nodes[tree.NewNodeIDFromBigInt(depth, index, hasher.BitLen()).ToNodeID2()] = hash
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HStar2Nodes(): %v", err)
+	}
+	mapRoot, err := (&types.MapRootV1{Revision: revision, RootHash: rootHash}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	root := &trillian.SignedMapRoot{MapRoot: mapRoot}
+	keys := func(keys ...string) [][]byte {
+		var indices [][]byte
+		for _, k := range keys {
+			indices = append(indices, testonly.HashKey(k))
+		}
+		return indices
+	}
+	var all []string
+	for i := 0; i < 100; i++ {
+		all = append(all, fmt.Sprintf("key-%d", i))
+	}
+
+	for _, test := range []struct {
+		desc     string
+		indices  [][]byte
+		revision int64
+		maxBytes int
+		wantCode codes.Code
+	}{
+		{desc: "one", indices: keys("key-7"), revision: -1},
+		{desc: "some", indices: keys("key-3", "key-50", "key-21"), revision: revision},
+		{desc: "absent", indices: keys("key-3", "missing-1", "missing-2"), revision: -1},
+		{desc: "all", indices: keys(all...), revision: -1},
+		{desc: "no-indices", wantCode: codes.InvalidArgument},
+		{desc: "duplicate", indices: keys("key-3", "key-3"), wantCode: codes.InvalidArgument},
+		{desc: "too-large", indices: keys(all...), revision: -1, maxBytes: 1000, wantCode: codes.ResourceExhausted},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockTX := storage.NewMockMapTreeTX(ctrl)
+			fakeStorage := storage.NewMockMapStorage(ctrl)
+			if test.wantCode != codes.InvalidArgument {
+				fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(mockTX, nil)
+				if test.revision < 0 {
+					mockTX.EXPECT().LatestSignedMapRoot(gomock.Any()).Return(root, nil)
+				} else {
+					mockTX.EXPECT().GetSignedMapRoot(gomock.Any(), test.revision).Return(root, nil)
+				}
+				mockTX.EXPECT().Get(gomock.Any(), int64(revision), test.indices).DoAndReturn(
+					func(_ context.Context, _ int64, indices [][]byte) ([]*trillian.MapLeaf, error) {
+						var ret []*trillian.MapLeaf
+						for _, index := range indices {
+							if leaf, ok := leaves[string(index)]; ok {
+								ret = append(ret, leaf)
+							}
+						}
+						return ret, nil
+					})
+				// The nodes of the proof are read at once.
+				mockTX.EXPECT().GetMerkleNodes(gomock.Any(), int64(revision), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int64, ids []tree.NodeID) ([]tree.Node, error) {
+						var ret []tree.Node
+						for _, id := range ids {
+							if hash, ok := nodes[id.ToNodeID2()]; ok {
+								ret = append(ret, tree.Node{NodeID: id, Hash: hash})
+							}
+						}
+						return ret, nil
+					})
+				mockTX.EXPECT().Commit(gomock.Any()).Return(nil)
+				mockTX.EXPECT().Close().Return(nil)
+				mockTX.EXPECT().IsOpen().AnyTimes().Return(false)
+			}
+
+			server := NewTrillianMapServer(extension.Registry{
+				AdminStorage: fakeAdminStorageForMap(ctrl, mapID1),
+				MapStorage:   fakeStorage,
+			}, TrillianMapServerOptions{MaxResponseBytes: test.maxBytes})
+
+			resp, err := server.GetLeavesCompact(ctx, &trillian.GetMapLeavesCompactRequest{
+				MapId:    mapID1,
+				Index:    test.indices,
+				Revision: test.revision,
+			})
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Fatalf("GetLeavesCompact(): %v, want code %v", err, want)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := len(resp.Leaves), len(test.indices); got != want {
+				t.Fatalf("GetLeavesCompact(): %d leaves, want %d", got, want)
+			}
+			ids := make([]tree.NodeID2, len(test.indices))
+			for i, index := range test.indices {
+				if got := resp.Leaves[i].Index; !bytes.Equal(got, index) {
+					t.Errorf("leaf %d has index %x, want %x", i, got, index)
+				}
+				ids[i] = tree.NewNodeID2(string(index), 256)
+			}
+			proofIDs, err := smt.MultiProofIDs(ids)
+			if err != nil {
+				t.Fatalf("MultiProofIDs(): %v", err)
+			}
+			proof, err := merkle.ExpandInclusionProof(resp.ProofBitmap, resp.Proof, len(proofIDs))
+			if err != nil {
+				t.Fatalf("ExpandInclusionProof(): %v", err)
+			}
+			if err := mapverifier.VerifyMultiInclusionProof(mapID1, resp.Leaves, rootHash, proof, hasher); err != nil {
+				t.Errorf("VerifyMultiInclusionProof(): %v", err)
+			}
+		})
+	}
+}
+
 func TestSetLeavesEmpty(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -453,6 +909,10 @@ func TestSetLeaves(t *testing.T) {
 			fakeStorage.EXPECT().Layout(gomock.Any()).Return(l, nil)
 			fakeStorage.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).
 				DoAndReturn(func(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+					// Split transactions are tracked as a batch of revision 1.
+					if _, rev, ok := storage.MapWriteBatchFromContext(ctx); ok != tc.splitTX || (ok && rev != 1) {
+						t.Errorf("MapWriteBatchFromContext(): revision %d, %v, want %v", rev, ok, tc.splitTX)
+					}
 					mockTX := storage.NewMockMapTreeTX(ctrl)
 					mockTX.EXPECT().WriteRevision(gomock.Any()).Return(int64(1), nil)
 					mockTX.EXPECT().ReadRevision(gomock.Any()).Return(int64(0), nil)
@@ -478,6 +938,9 @@ func TestSetLeaves(t *testing.T) {
 				// Reading and storing each shard in a separate transaction.
 				fakeStorage.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).
 					DoAndReturn(func(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+						if _, _, ok := storage.MapWriteBatchFromContext(ctx); !ok {
+							t.Error("Shard transaction is not part of a map write batch")
+						}
 						mockTX := storage.NewMockMapTreeTX(ctrl)
 						mockTX.EXPECT().GetTiles(gomock.Any(), gomock.Any(), gomock.Any())
 						mockTX.EXPECT().SetTiles(gomock.Any(), gomock.Any())
@@ -504,6 +967,138 @@ func TestSetLeaves(t *testing.T) {
 	}
 }
 
+func TestSetLeavesChunked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	leaves := []*trillian.MapLeaf{
+		{Index: b64("gXQJloeiZiH04s3XzAOz2s7bP7liJVsar9Azyr6DFTA="), LeafValue: []byte("value1")},
+		{Index: b64("sQJTdkyLIz+zdULiNAHHtFDlpvl1HztaAU9vZ+i8mZ0="), LeafValue: []byte("value2")},
+		{Index: b64("9XYQTuvqsJZR2DrP/HfIuMbqpLdnrqsk19qA+D9R2GU="), LeafValue: []byte("value3")},
+	}
+	rootHash := b64("Ms8A+VeDImofprfgq7Hoqh9cw+YrD/P/qibTmCm5JvQ=")
+
+	fakeStorage := storage.NewMockMapStorage(ctrl)
+	adminStorage := fakeAdminStorageForMap(ctrl, 12345)
+	server := NewTrillianMapServer(extension.Registry{
+		MapStorage:   fakeStorage,
+		AdminStorage: adminStorage,
+	}, TrillianMapServerOptions{MaxLeavesPerTransaction: 2})
+
+	fakeStorage.EXPECT().Layout(gomock.Any()).Return(tree.NewLayout([]int{8, 248}), nil)
+	// The same transaction stands in for all of them, which are counted.
+	mockTX := storage.NewMockMapTreeTX(ctrl)
+	mockTX.EXPECT().WriteRevision(gomock.Any()).AnyTimes().Return(int64(1), nil)
+	mockTX.EXPECT().ReadRevision(gomock.Any()).AnyTimes().Return(int64(0), nil)
+	mockTX.EXPECT().GetTiles(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockTX.EXPECT().SetTiles(gomock.Any(), gomock.Any()).AnyTimes()
+	mockTX.EXPECT().StoreSignedMapRoot(gomock.Any(), gomock.Any())
+	// txs holds the number of leaves set by each transaction.
+	var txs []int
+	mockTX.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(len(leaves)).DoAndReturn(
+		func(context.Context, []byte, *trillian.MapLeaf) error {
+			txs[len(txs)-1]++
+			return nil
+		})
+	fakeStorage.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+			if _, rev, ok := storage.MapWriteBatchFromContext(ctx); !ok || rev != 1 {
+				t.Errorf("MapWriteBatchFromContext(): revision %d, %v, want 1", rev, ok)
+			}
+			txs = append(txs, 0)
+			return f(ctx, mockTX)
+		})
+
+	rsp, err := server.SetLeaves(ctx, &trillian.SetMapLeavesRequest{
+		MapId:    12345,
+		Revision: 1,
+		Leaves:   leaves,
+	})
+	if err != nil {
+		t.Fatalf("SetLeaves: %v", err)
+	}
+	var mapRoot types.MapRootV1
+	if err := mapRoot.UnmarshalBinary(rsp.GetMapRoot().GetMapRoot()); err != nil {
+		t.Fatalf("UnmarshalBinary(root): %v", err)
+	}
+	if got, want := mapRoot.RootHash, rootHash; !bytes.Equal(got, want) {
+		t.Errorf("Hash mismatch: got %x, want %x", got, want)
+	}
+	// Two leaves are set in a transaction of their own, the last one in the
+	// transaction storing the root, followed by those of the shards.
+	if len(txs) < 2 || txs[0] != 2 || txs[1] != 1 {
+		t.Errorf("SetLeaves(): set %v leaves per transaction, want [2 1 ...]", txs)
+	}
+}
+
+func TestSetLeavesSkipUnchanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	leaves := []*trillian.MapLeaf{
+		{Index: b64("gXQJloeiZiH04s3XzAOz2s7bP7liJVsar9Azyr6DFTA="), LeafValue: []byte("value1")},
+		{Index: b64("sQJTdkyLIz+zdULiNAHHtFDlpvl1HztaAU9vZ+i8mZ0="), LeafValue: []byte("value2")},
+		{Index: b64("9XYQTuvqsJZR2DrP/HfIuMbqpLdnrqsk19qA+D9R2GU="), LeafValue: []byte("value3"), ExtraData: []byte("extra")},
+		{Index: b64("pLNQTCdp/OlUf23aMQ3YsJTWMKBE1l9TJNSzcxCqtxQ="), LeafValue: []byte("value4")},
+		{Index: b64("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="), LeafValue: []byte("value5"), Key: []byte("key")},
+	}
+
+	fakeStorage := storage.NewMockMapStorage(ctrl)
+	adminStorage := fakeAdminStorageForMap(ctrl, 12345)
+	server := NewTrillianMapServer(extension.Registry{
+		MapStorage:   fakeStorage,
+		AdminStorage: adminStorage,
+	}, TrillianMapServerOptions{UseSingleTransaction: true, SkipUnchangedLeaves: true})
+
+	fakeStorage.EXPECT().Layout(gomock.Any()).Return(tree.NewLayout([]int{8, 248}), nil)
+	fakeStorage.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+			mockTX := storage.NewMockMapTreeTX(ctrl)
+			mockTX.EXPECT().WriteRevision(gomock.Any()).Return(int64(2), nil)
+			mockTX.EXPECT().ReadRevision(gomock.Any()).AnyTimes().Return(int64(1), nil)
+			mockTX.EXPECT().GetTiles(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			mockTX.EXPECT().SetTiles(gomock.Any(), gomock.Any()).AnyTimes()
+			mockTX.EXPECT().StoreSignedMapRoot(gomock.Any(), gomock.Any())
+			// At revision 1, the first leaf is identical, the second has
+			// another value, the third has other extra data, the fourth
+			// isn't set, and the fifth has no key.
+			mockTX.EXPECT().Get(gomock.Any(), int64(1), gomock.Any()).DoAndReturn(
+				func(context.Context, int64, [][]byte) ([]*trillian.MapLeaf, error) {
+					same := proto.Clone(leaves[0]).(*trillian.MapLeaf)
+					other := proto.Clone(leaves[1]).(*trillian.MapLeaf)
+					other.LeafValue, other.LeafHash = []byte("old"), []byte("oldhash")
+					extra := proto.Clone(leaves[2]).(*trillian.MapLeaf)
+					extra.ExtraData = nil
+					keyless := proto.Clone(leaves[4]).(*trillian.MapLeaf)
+					keyless.Key = nil
+					return []*trillian.MapLeaf{same, other, extra, keyless}, nil
+				})
+			var set []string
+			mockTX.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+				func(_ context.Context, _ []byte, l *trillian.MapLeaf) error {
+					set = append(set, string(l.LeafValue))
+					return nil
+				})
+			if err := f(ctx, mockTX); err != nil {
+				return err
+			}
+			if diff := cmp.Diff(set, []string{"value2", "value3", "value4", "value5"}); diff != "" {
+				t.Errorf("Set() leaves diff (-got +want):\n%s", diff)
+			}
+			return nil
+		})
+
+	if _, err := server.SetLeaves(ctx, &trillian.SetMapLeavesRequest{
+		MapId:    12345,
+		Revision: 2,
+		Leaves:   leaves,
+	}); err != nil {
+		t.Fatalf("SetLeaves: %v", err)
+	}
+}
+
 func fakeAdminStorageForMap(ctrl *gomock.Controller, treeID int64) storage.AdminStorage {
 	tree := proto.Clone(stestonly.MapTree).(*trillian.Tree)
 	tree.TreeId = treeID
@@ -563,7 +1158,7 @@ func TestRequestIndexValidator(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			err := validateIndices(tt.indexSize, len(tt.indices), func(i int) []byte { return tt.indices[i] })
+			err := validateIndices(tt.indexSize, len(tt.indices), "index[%d]", func(i int) []byte { return tt.indices[i] })
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validateIndices() error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,223 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/treebatch"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MapSequencerOptions holds the settings of a MapSequencer.
+type MapSequencerOptions struct {
+	// MapIDs are the IDs of the maps whose queued mutations are applied.
+	MapIDs []int64
+	// Interval is how often the queued mutations are applied.
+	Interval time.Duration
+	// BatchSize is the maximum number of queued mutations applied in each
+	// revision of a map.
+	BatchSize int
+	// MaxBatches is the maximum number of revisions written per map in each
+	// run, see treebatch.Runner. Zero means no limit.
+	MaxBatches int
+	// UseLargePreload preloads the tiles needed to apply each batch, as
+	// TrillianMapServerOptions.UseLargePreload does.
+	UseLargePreload bool
+	// HashWorkers is the number of goroutines hashing each shard of the map
+	// tree, as TrillianMapServerOptions.HashWorkers is.
+	HashWorkers int
+	// SkipUnchangedLeaves skips storing mutations which leave leaves
+	// unchanged, as TrillianMapServerOptions.SkipUnchangedLeaves does.
+	SkipUnchangedLeaves bool
+}
+
+// MapSequencer applies the mutations of map leaves queued by the
+// QueueMapMutations RPC, which decouples the latency of writers from the
+// creation of map revisions. Every Interval, the queued mutations of each map
+// are applied in batches, in order of their queue time, each batch in a new
+// revision of the map without metadata. A batch is dequeued, and its revision
+// written, in a single storage transaction, so each mutation is applied
+// exactly once. Revisions written by SetLeaves, or by the sequencers of other
+// servers, make the transaction fail, and the batch is retried in the next
+// run, so each map should be written by one sequencer.
+//
+// Sequencing needs storage which implements storage.MapMutationQueue, and
+// whose map transactions implement storage.MapMutationDequeuer.
+type MapSequencer struct {
+	registry extension.Registry
+	opts     MapSequencerOptions
+	runner   treebatch.Runner
+
+	revisions   monitoring.Counter
+	mutations   monitoring.Counter
+	initializer *mapInitializer
+}
+
+// NewMapSequencer returns a MapSequencer for the maps in the given registry.
+func NewMapSequencer(registry extension.Registry, opts MapSequencerOptions) *MapSequencer {
+	mf := registry.MetricFactory
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1
+	}
+	return &MapSequencer{
+		registry: registry,
+		opts:     opts,
+		runner: treebatch.Runner{
+			Name:       "apply queued mutations of map",
+			BatchSize:  opts.BatchSize,
+			MaxBatches: opts.MaxBatches,
+			Failures:   mf.NewCounter("map_sequencer_failures", "Number of times applying the queued mutations of a map failed", "treeid"),
+		},
+		revisions:   mf.NewCounter("map_sequencer_revisions", "Number of map revisions written by the map sequencer", "treeid"),
+		mutations:   mf.NewCounter("map_sequencer_mutations", "Number of queued map mutations applied by the map sequencer", "treeid"),
+		initializer: newMapInitializer(registry.MapStorage),
+	}
+}
+
+// Run applies the queued mutations of the configured maps every Interval,
+// until ctx is done.
+func (s *MapSequencer) Run(ctx context.Context) {
+	for {
+		if err := clock.SleepContext(ctx, s.opts.Interval); err != nil {
+			return
+		}
+		if n, err := s.RunOnce(ctx); err != nil {
+			glog.Errorf("MapSequencer: %v", err)
+		} else if n > 0 {
+			glog.V(1).Infof("MapSequencer: applied %d mutations", n)
+		}
+	}
+}
+
+// RunOnce applies the queued mutations of each of the maps once, and returns
+// the number of mutations applied. It carries on past failures, and returns
+// the first one.
+func (s *MapSequencer) RunOnce(ctx context.Context) (int, error) {
+	return s.runner.Run(ctx, s.opts.MapIDs, s.sequenceMap)
+}
+
+// sequenceMap returns a function which applies a batch of the queued mutations
+// of the map in its own revision.
+func (s *MapSequencer) sequenceMap(ctx context.Context, mapID int64) (treebatch.BatchFunc, error) {
+	tree, err := trees.GetTree(ctx, s.registry.AdminStorage, mapID, optsMapWrite)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving map: %v", err)
+	}
+	ctx = trees.NewContext(ctx, tree)
+	return func() (int, error) { return s.applyBatch(ctx, tree) }, nil
+}
+
+// applyBatch dequeues up to BatchSize queued mutations of the map, and writes
+// them in a new revision. It returns the number of mutations applied, and
+// writes no revision if there are none.
+func (s *MapSequencer) applyBatch(ctx context.Context, tree *trillian.Tree) (int, error) {
+	ctx, spanEnd := spanFor(ctx, "SequenceMap")
+	defer spanEnd()
+
+	hasher, err := registry.NewMapHasherForTree(tree)
+	if err != nil {
+		return 0, err
+	}
+	layout, err := s.registry.MapStorage.Layout(tree)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.initializer.initIfNeeded(ctx, tree, hasher); err != nil {
+		return 0, err
+	}
+	var applied int
+	err = s.registry.MapStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+		applied = 0
+		dq, ok := tx.(storage.MapMutationDequeuer)
+		if !ok {
+			return status.Errorf(codes.Unimplemented, "map storage %T can't dequeue mutations", tx)
+		}
+		queued, err := dq.DequeueMapMutations(ctx, s.opts.BatchSize)
+		if err != nil || len(queued) == 0 {
+			return err
+		}
+		writeRev, err := tx.WriteRevision(ctx)
+		if err != nil {
+			return err
+		}
+
+		leaves := latestMutations(queued)
+		nodes := hashMapLeaves(tree, hasher, leaves)
+		if err := writeLeaves(ctx, tx, leaves, s.opts.SkipUnchangedLeaves); err != nil {
+			return err
+		}
+		updater := &mapTreeUpdater{
+			tree:     tree,
+			layout:   layout,
+			hasher:   hasher,
+			ms:       s.registry.MapStorage,
+			writeRev: writeRev,
+			singleTX: true,
+			preload:  s.opts.UseLargePreload,
+			workers:  s.opts.HashWorkers,
+		}
+		hash, err := updater.update(ctx, tx, nodes)
+		if err != nil {
+			return err
+		}
+		root, err := makeSignedMapRoot(ctx, tree, hash, writeRev, nil)
+		if err != nil {
+			return fmt.Errorf("makeSignedMapRoot(): %v", err)
+		}
+		applied = len(queued)
+		return tx.StoreSignedMapRoot(ctx, root)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if applied > 0 {
+		label := strconv.FormatInt(tree.TreeId, 10)
+		s.revisions.Inc(label)
+		s.mutations.Add(float64(applied), label)
+	}
+	return applied, nil
+}
+
+// latestMutations returns the leaves of the given mutations, in order of their
+// queue time, keeping only the latest mutation of each index.
+func latestMutations(queued []*trillian.MapLeaf) []*trillian.MapLeaf {
+	latest := make(map[string]int, len(queued))
+	for i, l := range queued {
+		latest[string(l.Index)] = i
+	}
+	leaves := make([]*trillian.MapLeaf, 0, len(latest))
+	for i, l := range queued {
+		if latest[string(l.Index)] == i {
+			leaves = append(leaves, l)
+		}
+	}
+	return leaves
+}
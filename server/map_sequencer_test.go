@@ -0,0 +1,333 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/maphasher"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+// queueMapTX is a map transaction over an in-memory map, with a queue of
+// mutations to dequeue.
+type queueMapTX struct {
+	storage.MapTreeTX
+	queue  []*trillian.MapLeaf
+	rev    int64
+	leaves map[string]*trillian.MapLeaf
+	tiles  *memTiles
+	roots  []*trillian.SignedMapRoot
+}
+
+func newQueueMapTX(queue []*trillian.MapLeaf) *queueMapTX {
+	return &queueMapTX{
+		queue:  queue,
+		leaves: make(map[string]*trillian.MapLeaf),
+		tiles:  newMemTiles(),
+	}
+}
+
+func (q *queueMapTX) DequeueMapMutations(ctx context.Context, limit int) ([]*trillian.MapLeaf, error) {
+	if limit > len(q.queue) {
+		limit = len(q.queue)
+	}
+	leaves := q.queue[:limit]
+	q.queue = q.queue[limit:]
+	return leaves, nil
+}
+
+func (q *queueMapTX) ReadRevision(ctx context.Context) (int64, error)  { return q.rev, nil }
+func (q *queueMapTX) WriteRevision(ctx context.Context) (int64, error) { return q.rev + 1, nil }
+
+func (q *queueMapTX) Get(ctx context.Context, revision int64, indexes [][]byte) ([]*trillian.MapLeaf, error) {
+	var leaves []*trillian.MapLeaf
+	for _, index := range indexes {
+		if l, ok := q.leaves[string(index)]; ok {
+			leaves = append(leaves, l)
+		}
+	}
+	return leaves, nil
+}
+
+func (q *queueMapTX) Set(ctx context.Context, index []byte, leaf *trillian.MapLeaf) error {
+	if storage.IsMapLeafDeletion(leaf) {
+		delete(q.leaves, string(index))
+	} else {
+		q.leaves[string(index)] = leaf
+	}
+	return nil
+}
+
+func (q *queueMapTX) GetTiles(ctx context.Context, rev int64, ids []tree.NodeID2) ([]smt.Tile, error) {
+	return q.tiles.GetTiles(ctx, rev, ids)
+}
+
+func (q *queueMapTX) SetTiles(ctx context.Context, tiles []smt.Tile) error {
+	return q.tiles.SetTiles(ctx, tiles)
+}
+
+func (q *queueMapTX) StoreSignedMapRoot(ctx context.Context, root *trillian.SignedMapRoot) error {
+	q.roots = append(q.roots, root)
+	q.rev++
+	return nil
+}
+
+// queueMapStorage is a map storage which records the mutations queued.
+type queueMapStorage struct {
+	storage.MapStorage
+	queued    []*trillian.MapLeaf
+	timestamp time.Time
+}
+
+func (q *queueMapStorage) QueueMapMutations(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf, queueTimestamp time.Time) error {
+	q.queued = append(q.queued, leaves...)
+	q.timestamp = queueTimestamp
+	return nil
+}
+
+func TestMapSequencer(t *testing.T) {
+	ctx := context.Background()
+	hasher := maphasher.Default
+	key := func(i int) []byte { return testonly.HashKey(fmt.Sprintf("key-%d", i)) }
+
+	// 25 mutations of 20 keys: the first 4 keys are set twice, and the key
+	// set last is deleted.
+	var queue []*trillian.MapLeaf
+	for i := 0; i < 20; i++ {
+		queue = append(queue, &trillian.MapLeaf{Index: key(i), LeafValue: []byte(fmt.Sprintf("value-%d", i))})
+	}
+	for i := 0; i < 4; i++ {
+		queue = append(queue, &trillian.MapLeaf{Index: key(i), LeafValue: []byte(fmt.Sprintf("new-value-%d", i))})
+	}
+	queue = append(queue, &trillian.MapLeaf{Index: key(19)})
+
+	want := make(map[string][]byte)
+	for _, l := range queue {
+		if len(l.LeafValue) == 0 {
+			delete(want, string(l.Index))
+		} else {
+			want[string(l.Index)] = l.LeafValue
+		}
+	}
+	var values []*merkle.HStar2LeafHash
+	for index, value := range want {
+		values = append(values, &merkle.HStar2LeafHash{
+			Index:    new(big.Int).SetBytes([]byte(index)),
+			LeafHash: hasher.HashLeaf(mapID1, []byte(index), value),
+		})
+	}
+	hs2 := merkle.NewHStar2(mapID1, hasher)
+	wantRoot, err := hs2.HStar2Root(hasher.BitLen(), values)
+	if err != nil {
+		t.Fatalf("HStar2Root(): %v", err)
+	}
+
+	for _, test := range []struct {
+		desc          string
+		opts          MapSequencerOptions
+		wantApplied   int
+		wantRevisions int
+	}{
+		{desc: "batches", opts: MapSequencerOptions{BatchSize: 10}, wantApplied: 25, wantRevisions: 3},
+		{desc: "oneBatch", opts: MapSequencerOptions{BatchSize: 100}, wantApplied: 25, wantRevisions: 1},
+		{desc: "maxBatches", opts: MapSequencerOptions{BatchSize: 10, MaxBatches: 2}, wantApplied: 20, wantRevisions: 2},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tx := newQueueMapTX(append([]*trillian.MapLeaf(nil), queue...))
+			ms := storage.NewMockMapStorage(ctrl)
+			ms.EXPECT().Layout(gomock.Any()).AnyTimes().Return(tree.NewLayout([]int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 176}), nil)
+			ms.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, _ interface{}, f storage.MapTXFunc) error {
+				return f(ctx, tx)
+			})
+			test.opts.MapIDs = []int64{mapID1}
+			s := NewMapSequencer(extension.Registry{
+				AdminStorage: fakeAdminStorageForMap(ctrl, mapID1),
+				MapStorage:   ms,
+			}, test.opts)
+
+			n, err := s.RunOnce(ctx)
+			if err != nil {
+				t.Fatalf("RunOnce(): %v", err)
+			}
+			if n != test.wantApplied {
+				t.Errorf("RunOnce()=%d, want %d", n, test.wantApplied)
+			}
+			if got, want := len(tx.roots), test.wantRevisions; got != want {
+				t.Fatalf("%d revisions written, want %d", got, want)
+			}
+			for i, smr := range tx.roots {
+				var root types.MapRootV1
+				if err := root.UnmarshalBinary(smr.MapRoot); err != nil {
+					t.Fatalf("UnmarshalBinary(): %v", err)
+				}
+				if got, want := root.Revision, uint64(i+1); got != want {
+					t.Errorf("root %d has revision %d, want %d", i, got, want)
+				}
+				if i == len(tx.roots)-1 && len(tx.queue) == 0 && !bytes.Equal(root.RootHash, wantRoot) {
+					t.Errorf("root hash %x, want %x", root.RootHash, wantRoot)
+				}
+			}
+			if got, want := len(tx.queue), len(queue)-test.wantApplied; got != want {
+				t.Errorf("%d mutations left in the queue, want %d", got, want)
+			}
+			if len(tx.queue) == 0 {
+				if got, want := len(tx.leaves), len(want); got != want {
+					t.Errorf("%d leaves set, want %d", got, want)
+				}
+				for index, value := range want {
+					if l := tx.leaves[index]; l == nil || !bytes.Equal(l.LeafValue, value) {
+						t.Errorf("leaf %x: %v, want value %q", index, l, value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMapSequencerUnsupported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ms := storage.NewMockMapStorage(ctrl)
+	ms.EXPECT().Layout(gomock.Any()).AnyTimes().Return(tree.NewLayout([]int{8, 248}), nil)
+	ms.EXPECT().ReadWriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ interface{}, f storage.MapTXFunc) error {
+		return f(ctx, plainMapTX{})
+	})
+	s := NewMapSequencer(extension.Registry{
+		AdminStorage: fakeAdminStorageForMap(ctrl, mapID1),
+		MapStorage:   ms,
+	}, MapSequencerOptions{MapIDs: []int64{mapID1}, BatchSize: 10})
+	if _, err := s.RunOnce(context.Background()); err == nil {
+		t.Error("RunOnce() succeeded without a dequeuing transaction")
+	}
+}
+
+func TestQueueMapMutations(t *testing.T) {
+	ctx := context.Background()
+	leaves := []*trillian.MapLeaf{
+		{Index: testonly.HashKey("a"), LeafValue: []byte("A"), LeafHash: []byte("ignored")},
+		{Index: testonly.HashKey("b")},
+	}
+	for _, test := range []struct {
+		desc     string
+		leaves   []*trillian.MapLeaf
+		queue    bool
+		wantCode codes.Code
+	}{
+		{desc: "queued", leaves: leaves, queue: true},
+		{desc: "empty", queue: true},
+		{desc: "duplicate", leaves: []*trillian.MapLeaf{leaves[0], leaves[0]}, queue: true, wantCode: codes.InvalidArgument},
+		{desc: "badIndex", leaves: []*trillian.MapLeaf{{Index: []byte("short")}}, queue: true, wantCode: codes.InvalidArgument},
+		{desc: "unsupported", leaves: leaves, wantCode: codes.Unimplemented},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var ms storage.MapStorage = storage.NewMockMapStorage(ctrl)
+			qs := &queueMapStorage{MapStorage: ms}
+			if test.queue {
+				ms = qs
+			}
+			registry := extension.Registry{
+				AdminStorage: fakeAdminStorageForMap(ctrl, mapID1),
+				MapStorage:   ms,
+			}
+			server := NewTrillianMapWriteServer(registry, NewTrillianMapServer(registry, TrillianMapServerOptions{}))
+			_, err := server.QueueMapMutations(ctx, &trillian.QueueMapMutationsRequest{MapId: mapID1, Leaves: test.leaves})
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Fatalf("QueueMapMutations(): %v, want code %v", err, want)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := len(qs.queued), len(test.leaves); got != want {
+				t.Fatalf("%d mutations queued, want %d", got, want)
+			}
+			for _, l := range qs.queued {
+				if l.LeafHash != nil {
+					t.Errorf("leaf %x queued with hash %x", l.Index, l.LeafHash)
+				}
+			}
+		})
+	}
+}
+
+func TestQueueMapMutationsRateLimit(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tree := proto.Clone(stestonly.MapTree).(*trillian.Tree)
+	tree.TreeId = mapID1
+	tree.RateLimits = &trillian.TreeRateLimits{QueriesPerSecond: 1}
+	const calls = 3
+	adminStorage := &stestonly.FakeAdminStorage{}
+	for i := 0; i < calls; i++ {
+		adminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+		adminTX.EXPECT().GetTree(gomock.Any(), mapID1).Return(tree, nil)
+		adminTX.EXPECT().Close().MaxTimes(1).Return(nil)
+		adminTX.EXPECT().Commit().MaxTimes(1).Return(nil)
+		adminStorage.ReadOnlyTX = append(adminStorage.ReadOnlyTX, adminTX)
+	}
+	qs := &queueMapStorage{MapStorage: storage.NewMockMapStorage(ctrl)}
+	registry := extension.Registry{AdminStorage: adminStorage, MapStorage: qs}
+	mapServer := NewTrillianMapServer(registry, TrillianMapServerOptions{})
+	now := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	fakeTime := clock.NewFake(now)
+	mapServer.timeSource = fakeTime
+	server := NewTrillianMapWriteServer(registry, mapServer)
+
+	req := &trillian.QueueMapMutationsRequest{MapId: mapID1, Leaves: []*trillian.MapLeaf{{Index: testonly.HashKey("a")}}}
+	if _, err := server.QueueMapMutations(ctx, req); err != nil {
+		t.Fatalf("QueueMapMutations(): %v", err)
+	}
+	if !qs.timestamp.Equal(now) {
+		t.Errorf("QueueMapMutations() queued at %v, want %v", qs.timestamp, now)
+	}
+	if _, err := server.QueueMapMutations(ctx, req); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("QueueMapMutations() within the same second: %v, want code %v", err, codes.ResourceExhausted)
+	}
+	fakeTime.Set(now.Add(time.Second))
+	if _, err := server.QueueMapMutations(ctx, req); err != nil {
+		t.Fatalf("QueueMapMutations() a second later: %v", err)
+	}
+	if got, want := len(qs.queued), 2; got != want {
+		t.Errorf("%d mutations queued, want %d", got, want)
+	}
+}
@@ -36,6 +36,7 @@ type mapTreeUpdater struct {
 	writeRev int64
 	singleTX bool
 	preload  bool
+	workers  int // The number of goroutines hashing each shard.
 }
 
 // update updates the sparse Merkle tree at the current write revision with the
@@ -54,7 +55,7 @@ func (t *mapTreeUpdater) update(ctx context.Context, tx storage.MapTreeTX, nodes
 
 	// TODO(pavelkalinnikov): Make the layout configurable.
 	const topHeight = uint(8) // The height of the top shard.
-	w := smt.NewWriter(t.tree.TreeId, t.hasher, uint(t.hasher.BitLen()), topHeight)
+	w := smt.NewParallelWriter(t.tree.TreeId, t.hasher, uint(t.hasher.BitLen()), topHeight, t.workers)
 	shards, err := w.Split(nodes) // Split the nodes into shards below topHeight.
 	if err != nil {
 		return nil, err
@@ -157,8 +158,9 @@ func (t *mapTreeUpdater) newTXFunc(tx storage.MapTreeTX) txFunc {
 		}
 	}
 	// Execute each call in its own transaction. This allows each invocation of f
-	// to proceed independently much faster. However, If one transaction fails,
-	// the other can still succeed. In some cases this can cause data corruption.
+	// to proceed independently much faster. However, if one transaction fails,
+	// the other can still succeed. The writes of all of them are only applied
+	// once the root is stored, see storage.WithMapWriteBatch.
 	return func(ctx context.Context, f func(context.Context, storage.MapTreeTX) error) error {
 		return t.ms.ReadWriteTransaction(ctx, t.tree, f)
 	}
@@ -20,7 +20,10 @@ import (
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/maps"
+	"github.com/google/trillian/storage"
 	"github.com/google/trillian/trees"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // TrillianMapWriteServer implements the Write RPC API
@@ -67,6 +70,41 @@ func (t *TrillianMapWriteServer) WriteLeaves(ctx context.Context, req *trillian.
 	return &trillian.WriteMapLeavesResponse{Revision: int64(root.Revision)}, nil
 }
 
+// QueueMapMutations implements the QueueMapMutations write RPC method. The
+// leaves are validated as by WriteLeaves, and queued in storage for
+// MapSequencer to apply.
+func (t *TrillianMapWriteServer) QueueMapMutations(ctx context.Context, req *trillian.QueueMapMutationsRequest) (*trillian.QueueMapMutationsResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "QueueMapMutations")
+	defer spanEnd()
+	if len(req.Leaves) == 0 {
+		return &trillian.QueueMapMutationsResponse{}, nil
+	}
+
+	tree, hasher, err := t.mapServer.getTreeAndHasher(ctx, req.MapId, optsMapWrite)
+	if err != nil {
+		return nil, err
+	}
+	q, ok := t.registry.MapStorage.(storage.MapMutationQueue)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "map storage %T can't queue mutations", t.registry.MapStorage)
+	}
+	if err := t.mapServer.rateLimiter.allow(tree, len(req.Leaves), t.mapServer.timeSource.Now()); err != nil {
+		return nil, err
+	}
+	ctx = trees.NewContext(ctx, tree)
+	if err := t.mapServer.validateLeaves(tree, hasher, req.Leaves); err != nil {
+		return nil, err
+	}
+	// Leaf hashes are computed when the mutations are applied.
+	for _, l := range req.Leaves {
+		l.LeafHash = nil
+	}
+	if err := q.QueueMapMutations(ctx, tree, req.Leaves, t.mapServer.timeSource.Now()); err != nil {
+		return nil, err
+	}
+	return &trillian.QueueMapMutationsResponse{}, nil
+}
+
 // IsHealthy returns nil if the server is healthy, error otherwise.
 func (t *TrillianMapWriteServer) IsHealthy() error {
 	return t.mapServer.IsHealthy()
@@ -0,0 +1,171 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	serrors "github.com/google/trillian/server/errors"
+)
+
+// DefaultMaxResponseBytes is the suggested maximum size of the responses of
+// read RPCs, which is the default maximum size of the messages received by
+// gRPC clients.
+const DefaultMaxResponseBytes = 4 << 20
+
+const (
+	// pageTokenVersion is the first byte of page tokens.
+	pageTokenVersion = 1
+	// digestSize is the size of the digests of requests held by page tokens.
+	digestSize = 8
+	// pageTokenReserve is the size reserved in truncated responses for their
+	// page token and its framing, which is more than page tokens ever take
+	// apart from their after field.
+	pageTokenReserve = 64
+)
+
+// pageToken is the decoded form of the page token returned by a truncated
+// read RPC, which locates where it stopped. Page tokens hold a digest of the
+// request they were returned for, so that they can't be used to continue
+// other requests.
+type pageToken struct {
+	// offset is the index of the next leaf for log ranges, or the position
+	// of the next index in the request for maps.
+	offset int64
+	// revision is the map revision read, which continuations read too.
+	revision int64
+	// after is the position of the last element returned, for listings
+	// which continue after it rather than from an offset. It is only encoded
+	// if set.
+	after []byte
+}
+
+// requestDigest returns the digest of the parameters of a read request which
+// page tokens are bound to.
+func requestDigest(treeID int64, params ...[]byte) []byte {
+	h := sha256.New()
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(treeID))
+	h.Write(b[:])
+	for _, p := range params {
+		binary.BigEndian.PutUint64(b[:], uint64(len(p)))
+		h.Write(b[:])
+		h.Write(p)
+	}
+	return h.Sum(nil)[:digestSize]
+}
+
+// int64Param returns the encoding of an integer parameter of a request, for
+// requestDigest.
+func int64Param(v int64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	return b[:]
+}
+
+// encode returns the page token of a response to the request with the given
+// digest.
+func (p pageToken) encode(digest []byte) []byte {
+	buf := make([]byte, 1+digestSize+3*binary.MaxVarintLen64+len(p.after))
+	buf[0] = pageTokenVersion
+	n := 1 + copy(buf[1:], digest)
+	n += binary.PutVarint(buf[n:], p.offset)
+	n += binary.PutVarint(buf[n:], p.revision)
+	if len(p.after) > 0 {
+		n += binary.PutUvarint(buf[n:], uint64(len(p.after)))
+		n += copy(buf[n:], p.after)
+	}
+	return buf[:n]
+}
+
+// decodePageToken decodes the page token in the given field of the request
+// with the given digest.
+func decodePageToken(token, digest []byte, field string) (pageToken, error) {
+	if len(token) < 1+digestSize || token[0] != pageTokenVersion || !bytes.Equal(token[1:1+digestSize], digest) {
+		return pageToken{}, serrors.InvalidArgument(field, "page token is malformed or was not returned for this request")
+	}
+	rest := token[1+digestSize:]
+	offset, n := binary.Varint(rest)
+	if n <= 0 || offset < 0 {
+		return pageToken{}, serrors.InvalidArgument(field, "page token has a malformed offset")
+	}
+	revision, m := binary.Varint(rest[n:])
+	if m <= 0 {
+		return pageToken{}, serrors.InvalidArgument(field, "page token has a malformed revision")
+	}
+	var after []byte
+	if rest = rest[n+m:]; len(rest) > 0 {
+		size, k := binary.Uvarint(rest)
+		if k <= 0 || size == 0 || uint64(len(rest)-k) != size {
+			return pageToken{}, serrors.InvalidArgument(field, "page token has a malformed position")
+		}
+		after = append(after, rest[k:]...)
+	}
+	return pageToken{offset: offset, revision: revision, after: after}, nil
+}
+
+// responseBudget tracks how much of the maximum size of a response is left
+// for the elements of its repeated field of leaves.
+type responseBudget struct {
+	left  int
+	taken int
+}
+
+// newResponseBudget returns the budget of a response whose fields other than
+// its leaves are in fixed, or nil if maxBytes isn't positive, which means
+// that responses are not limited.
+func newResponseBudget(maxBytes int, fixed proto.Message) *responseBudget {
+	if maxBytes <= 0 {
+		return nil
+	}
+	return &responseBudget{left: maxBytes - proto.Size(fixed) - pageTokenReserve}
+}
+
+// reserve takes the given size from the budget, for fields of the response
+// whose size isn't known up front, like the after field of its page token.
+func (b *responseBudget) reserve(size int) {
+	if b != nil {
+		b.left -= size
+	}
+}
+
+// take reports whether an element of the given size fits in what's left of
+// the budget, and takes it from the budget if so. The first element always
+// fits, so that paging through results makes progress. A nil budget fits
+// everything.
+func (b *responseBudget) take(size int) bool {
+	if b == nil {
+		return true
+	}
+	size += 1 + varintSize(uint64(size)) // Field tag and length.
+	if b.taken > 0 && size > b.left {
+		return false
+	}
+	b.left -= size
+	b.taken++
+	return true
+}
+
+// varintSize returns the size of the varint encoding of v.
+func varintSize(v uint64) int {
+	n := 1
+	for ; v >= 0x80; v >>= 7 {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPageToken(t *testing.T) {
+	digest := requestDigest(1, int64Param(10), int64Param(20))
+	for _, token := range []pageToken{{}, {offset: 15}, {offset: 1 << 40, revision: 1 << 50}, {revision: 3, after: []byte("key")}} {
+		got, err := decodePageToken(token.encode(digest), digest, "page_token")
+		if err != nil {
+			t.Fatalf("decodePageToken(%+v): %v", token, err)
+		}
+		if !reflect.DeepEqual(got, token) {
+			t.Errorf("decodePageToken(encode(%+v))=%+v", token, got)
+		}
+	}
+
+	valid := pageToken{offset: 15, revision: 3}.encode(digest)
+	withAfter := pageToken{revision: 3, after: []byte("key")}.encode(digest)
+	for _, test := range []struct {
+		desc   string
+		token  []byte
+		digest []byte
+	}{
+		{desc: "empty", token: []byte{}, digest: digest},
+		{desc: "other-tree", token: valid, digest: requestDigest(2, int64Param(10), int64Param(20))},
+		{desc: "other-params", token: valid, digest: requestDigest(1, int64Param(10), int64Param(21))},
+		{desc: "bad-version", token: append([]byte{0}, valid[1:]...), digest: digest},
+		{desc: "truncated", token: valid[:len(valid)-1], digest: digest},
+		{desc: "trailing-data", token: append(append([]byte{}, valid...), 0), digest: digest},
+		{desc: "truncated-position", token: withAfter[:len(withAfter)-1], digest: digest},
+		{desc: "negative-offset", token: pageToken{offset: -1}.encode(digest), digest: digest},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := decodePageToken(test.token, test.digest, "page_token"); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("decodePageToken(): %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
+
+func TestResponseBudget(t *testing.T) {
+	var unlimited *responseBudget
+	if !unlimited.take(1 << 30) {
+		t.Error("nil budget: take()=false, want true")
+	}
+
+	b := &responseBudget{left: 100}
+	for _, test := range []struct {
+		size int
+		want bool
+	}{
+		{size: 200, want: true}, // The first element always fits.
+		{size: 1, want: false},
+	} {
+		if got := b.take(test.size); got != test.want {
+			t.Errorf("take(%d)=%v, want %v", test.size, got, test.want)
+		}
+	}
+
+	b = &responseBudget{left: 100}
+	if !b.take(48) || !b.take(48) {
+		t.Fatal("take(48) twice: want both to fit")
+	}
+	if b.take(1) {
+		t.Error("take(1) with 2 bytes left: want it not to fit, with its framing")
+	}
+}
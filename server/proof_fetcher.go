@@ -20,6 +20,7 @@ import (
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/compact"
 	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/tree"
@@ -56,6 +57,50 @@ func fetchNodesAndBuildProof(ctx context.Context, tx storage.NodeReader, th hash
 	}, nil
 }
 
+// fetchNodesAndBuildProofs builds inclusion proofs for a number of leaves at
+// the same tree revision. proofNodeFetches[i] holds the nodes of the proof for
+// leafIndices[i]. Nodes needed by more than one proof are fetched only once,
+// in a single storage read.
+func fetchNodesAndBuildProofs(ctx context.Context, tx storage.NodeReader, th hashers.LogHasher, treeRevision int64, leafIndices []int64, proofNodeFetches [][]merkle.NodeFetch) ([]*trillian.Proof, error) {
+	ctx, spanEnd := spanFor(ctx, "fetchNodesAndBuildProofs")
+	defer spanEnd()
+	if len(leafIndices) != len(proofNodeFetches) {
+		return nil, fmt.Errorf("got node fetches for %d proofs, want %d", len(proofNodeFetches), len(leafIndices))
+	}
+
+	pos := make(map[compact.NodeID]int)
+	var unique []merkle.NodeFetch
+	for _, fetches := range proofNodeFetches {
+		for _, fetch := range fetches {
+			if _, ok := pos[fetch.ID]; !ok {
+				pos[fetch.ID] = len(unique)
+				unique = append(unique, fetch)
+			}
+		}
+	}
+	nodes, err := fetchNodes(ctx, tx, treeRevision, unique)
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make([]*trillian.Proof, 0, len(leafIndices))
+	for i, fetches := range proofNodeFetches {
+		h := make([][]byte, len(fetches))
+		for j, fetch := range fetches {
+			h[j] = nodes[pos[fetch.ID]].Hash
+		}
+		proof, err := merkle.Rehash(h, fetches, th.HashChildren)
+		if err != nil {
+			return nil, err
+		}
+		proofs = append(proofs, &trillian.Proof{
+			LeafIndex: leafIndices[i],
+			Hashes:    proof,
+		})
+	}
+	return proofs, nil
+}
+
 // fetchNodes extracts the NodeIDs from a list of NodeFetch structs and passes them
 // to storage, returning the result after some additional validation checks.
 func fetchNodes(ctx context.Context, tx storage.NodeReader, treeRevision int64, fetches []merkle.NodeFetch) ([]tree.Node, error) {
@@ -23,7 +23,9 @@ import (
 	"github.com/google/trillian/internal/merkle/inmemory"
 	"github.com/google/trillian/merkle"
 	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
+	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/storage/tree"
 )
 
 // An arbitrary tree revision to be used in tests.
@@ -154,6 +156,73 @@ func TestTree32InclusionProofFetchMultiBatch(t *testing.T) {
 	}
 }
 
+// countingNodeReader counts the storage reads of a NodeReader.
+type countingNodeReader struct {
+	storage.NodeReader
+	reads int
+}
+
+func (c *countingNodeReader) GetMerkleNodes(ctx context.Context, treeRevision int64, ids []tree.NodeID) ([]tree.Node, error) {
+	c.reads++
+	return c.NodeReader.GetMerkleNodes(ctx, treeRevision, ids)
+}
+
+func TestTree32InclusionProofsFetchAll(t *testing.T) {
+	ctx := context.Background()
+	hasher := rfc6962.DefaultHasher
+	for ts := 2; ts <= 32; ts++ {
+		mt := treeAtSize(ts)
+		r := testonly.NewMultiFakeNodeReaderFromLeaves([]testonly.LeafBatch{
+			{TreeRevision: testTreeRevision, Leaves: expandLeaves(0, ts-1), ExpectedRoot: expectedRootAtSize(mt)},
+		})
+
+		for s := int64(2); s <= int64(ts); s++ {
+			// Request every leaf of the tree, in reverse order.
+			var indices []int64
+			var fetches [][]merkle.NodeFetch
+			for l := s - 1; l >= 0; l-- {
+				f, err := merkle.CalcInclusionProofNodeAddresses(s, l, int64(ts))
+				if err != nil {
+					t.Fatal(err)
+				}
+				indices = append(indices, l)
+				fetches = append(fetches, f)
+			}
+
+			cr := &countingNodeReader{NodeReader: r}
+			proofs, err := fetchNodesAndBuildProofs(ctx, cr, hasher, testTreeRevision, indices, fetches)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := cr.reads, 1; got != want {
+				t.Errorf("(%d, %d): got %d node reads, want %d", ts, s, got, want)
+			}
+			if got, want := len(proofs), len(indices); got != want {
+				t.Fatalf("(%d, %d): got %d proofs, want %d", ts, s, got, want)
+			}
+
+			for p, proof := range proofs {
+				l := indices[p]
+				if got, want := proof.LeafIndex, l; got != want {
+					t.Errorf("leaf index mismatch: got %d, want %d", got, want)
+				}
+
+				// We use +1 here because of the 1 based leaf indexing of this implementation
+				refProof := mt.PathToRootAtSnapshot(l+1, s)
+
+				if got, want := len(proof.Hashes), len(refProof); got != want {
+					t.Fatalf("(%d, %d, %d): got proof len: %d, want: %d", ts, s, l, got, want)
+				}
+				for i := 0; i < len(proof.Hashes); i++ {
+					if got, want := hex.EncodeToString(proof.Hashes[i]), hex.EncodeToString(refProof[i].Value.Hash()); got != want {
+						t.Fatalf("(%d, %d, %d): %d got proof node: %s, want: %s", ts, s, l, i, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
 func TestTree32ConsistencyProofFetchAll(t *testing.T) {
 	ctx := context.Background()
 	hasher := rfc6962.DefaultHasher
@@ -0,0 +1,143 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	serrors "github.com/google/trillian/server/errors"
+	"golang.org/x/time/rate"
+)
+
+// treeRateLimiter enforces the rate limits of trees, see
+// trillian.TreeRateLimits, with token buckets kept in memory, so each server
+// enforces them separately. The buckets follow changes to the limits of trees
+// as they're read.
+type treeRateLimiter struct {
+	mu       sync.Mutex
+	buckets  map[int64]*treeBuckets
+	rejected monitoring.Counter
+}
+
+// treeBuckets holds the token buckets of a tree, nil for no limit.
+type treeBuckets struct {
+	queries, leaves *rate.Limiter
+}
+
+// newTreeRateLimiter returns a treeRateLimiter which counts the requests it
+// rejects in a counter with the given name.
+func newTreeRateLimiter(mf monitoring.MetricFactory, name string) *treeRateLimiter {
+	return &treeRateLimiter{
+		buckets:  make(map[int64]*treeBuckets),
+		rejected: mf.NewCounter(name, "Number of write requests rejected by the rate limits of their tree", "treeid", "limit"),
+	}
+}
+
+// allow returns a ResourceExhausted error if a request writing the given
+// number of leaves to tree at now exceeds the rate limits of tree, and takes
+// its tokens otherwise.
+func (r *treeRateLimiter) allow(tree *trillian.Tree, leaves int, now time.Time) error {
+	b := r.bucketsFor(tree, now)
+	if b == nil {
+		return nil
+	}
+
+	var taken []*rate.Reservation
+	for _, l := range []struct {
+		name   string
+		bucket *rate.Limiter
+		n      int
+	}{
+		{"queries_per_second", b.queries, 1},
+		{"leaves_per_second", b.leaves, leaves},
+	} {
+		if l.bucket == nil {
+			continue
+		}
+		res := l.bucket.ReserveN(now, l.n)
+		if res.OK() && res.DelayFrom(now) == 0 {
+			taken = append(taken, res)
+			continue
+		}
+
+		// Return the tokens of this and any other limit, as the request
+		// fails.
+		var retryAfter time.Duration
+		if res.OK() {
+			retryAfter = res.DelayFrom(now)
+			res.CancelAt(now)
+		}
+		for _, t := range taken {
+			t.CancelAt(now)
+		}
+		r.rejected.Inc(strconv.FormatInt(tree.TreeId, 10), l.name)
+		limit := fmt.Sprintf("trees/%d/%s", tree.TreeId, l.name)
+		if !res.OK() {
+			return serrors.RateLimited(limit, 0, "tree %d: request of %d exceeds the burst of %d allowed by %s", tree.TreeId, l.n, l.bucket.Burst(), l.name)
+		}
+		return serrors.RateLimited(limit, retryAfter, "tree %d: rate limit of %v %s exceeded", tree.TreeId, float64(l.bucket.Limit()), l.name)
+	}
+	return nil
+}
+
+// bucketsFor returns a copy of the token buckets of tree, updated to its
+// current rate limits, or nil if it has none.
+func (r *treeRateLimiter) bucketsFor(tree *trillian.Tree, now time.Time) *treeBuckets {
+	limits := tree.GetRateLimits()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if limits.GetQueriesPerSecond() == 0 && limits.GetLeavesPerSecond() == 0 {
+		delete(r.buckets, tree.TreeId)
+		return nil
+	}
+	b, ok := r.buckets[tree.TreeId]
+	if !ok {
+		b = &treeBuckets{}
+		r.buckets[tree.TreeId] = b
+	}
+	b.queries = updateBucket(b.queries, limits.GetQueriesPerSecond(), now)
+	b.leaves = updateBucket(b.leaves, limits.GetLeavesPerSecond(), now)
+	ret := *b
+	return &ret
+}
+
+// updateBucket returns bucket with the given rate, or a new full bucket if
+// bucket is nil, or nil if rate is zero. Buckets allow bursts of one second's
+// worth of their rate, rounded up.
+func updateBucket(bucket *rate.Limiter, perSecond float64, now time.Time) *rate.Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	limit, burst := rate.Limit(perSecond), math.MaxInt32
+	if perSecond < math.MaxInt32 {
+		burst = int(math.Ceil(perSecond))
+	}
+	if bucket == nil {
+		return rate.NewLimiter(limit, burst)
+	}
+	if bucket.Limit() != limit {
+		bucket.SetLimitAt(now, limit)
+	}
+	if bucket.Burst() != burst {
+		bucket.SetBurstAt(now, burst)
+	}
+	return bucket
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTreeRateLimiter(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	tree := &trillian.Tree{TreeId: 5, RateLimits: &trillian.TreeRateLimits{QueriesPerSecond: 2, LeavesPerSecond: 10}}
+	unlimited := &trillian.Tree{TreeId: 6}
+	limiter := newTreeRateLimiter(monitoring.InertMetricFactory{}, "rate_limited_requests")
+
+	for _, test := range []struct {
+		desc      string
+		tree      *trillian.Tree
+		leaves    int
+		at        time.Duration
+		wantLimit string
+		wantRetry bool
+	}{
+		{desc: "unlimited", tree: unlimited, leaves: 1000},
+		{desc: "first", tree: tree, leaves: 6},
+		// Too many leaves, so the query token is given back.
+		{desc: "leaves", tree: tree, leaves: 6, wantLimit: "trees/5/leaves_per_second", wantRetry: true},
+		{desc: "second", tree: tree, leaves: 4},
+		{desc: "queries", tree: tree, leaves: 1, wantLimit: "trees/5/queries_per_second", wantRetry: true},
+		{desc: "over-burst", tree: tree, leaves: 11, at: time.Minute, wantLimit: "trees/5/leaves_per_second"},
+		{desc: "refilled", tree: tree, leaves: 10, at: time.Minute},
+		{desc: "lowered", tree: &trillian.Tree{TreeId: 5, RateLimits: &trillian.TreeRateLimits{LeavesPerSecond: 1}}, leaves: 2, at: 2 * time.Minute, wantLimit: "trees/5/leaves_per_second"},
+		{desc: "removed", tree: &trillian.Tree{TreeId: 5}, leaves: 1000, at: 2 * time.Minute},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			err := limiter.allow(test.tree, test.leaves, now.Add(test.at))
+			if test.wantLimit == "" {
+				if err != nil {
+					t.Fatalf("allow(): %v, want nil", err)
+				}
+				return
+			}
+			st, _ := status.FromError(err)
+			if st.Code() != codes.ResourceExhausted {
+				t.Fatalf("allow(): %v, want code %v", err, codes.ResourceExhausted)
+			}
+			var limit string
+			var retry bool
+			for _, d := range st.Details() {
+				switch d := d.(type) {
+				case *errdetails.QuotaFailure:
+					limit = d.Violations[0].Subject
+				case *errdetails.RetryInfo:
+					retry = true
+				}
+			}
+			if limit != test.wantLimit || retry != test.wantRetry {
+				t.Errorf("allow(): limit %q, retry info: %v, want %q, %v", limit, retry, test.wantLimit, test.wantRetry)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto"
+	"strconv"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/util/requestid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rootVerifier checks the signatures of roots read from storage against the
+// public keys of their trees, before they are served along with data and
+// proofs built against them. This catches trees whose keys don't match their
+// storage, and roots which were tampered with, on the read path, rather than
+// leaving them for clients to find.
+type rootVerifier struct {
+	failures monitoring.Counter
+}
+
+// newRootVerifier returns a rootVerifier which counts the roots failing
+// verification in a counter with the given name.
+func newRootVerifier(mf monitoring.MetricFactory, name string) *rootVerifier {
+	return &rootVerifier{
+		failures: mf.NewCounter(name, "Number of roots read from storage whose signature failed verification", "treeid"),
+	}
+}
+
+// verifyLogRoot returns a DataLoss error if the signature of slr doesn't
+// verify against the public key of tree.
+func (v *rootVerifier) verifyLogRoot(ctx context.Context, tree *trillian.Tree, slr *trillian.SignedLogRoot) error {
+	return v.verify(ctx, tree, func(pub crypto.PublicKey, hash crypto.Hash) error {
+		_, err := tcrypto.VerifySignedLogRoot(pub, hash, slr)
+		return err
+	})
+}
+
+// verifyMapRoot returns a DataLoss error if the signature of smr doesn't
+// verify against the public key of tree.
+func (v *rootVerifier) verifyMapRoot(ctx context.Context, tree *trillian.Tree, smr *trillian.SignedMapRoot) error {
+	return v.verify(ctx, tree, func(pub crypto.PublicKey, hash crypto.Hash) error {
+		_, err := tcrypto.VerifySignedMapRoot(pub, hash, smr)
+		return err
+	})
+}
+
+func (v *rootVerifier) verify(ctx context.Context, tree *trillian.Tree, verify func(crypto.PublicKey, crypto.Hash) error) error {
+	pub, err := der.UnmarshalPublicKey(tree.PublicKey.GetDer())
+	if err != nil {
+		return status.Errorf(codes.Internal, "tree %d: failed to parse public key: %v", tree.TreeId, err)
+	}
+	hash, err := trees.Hash(tree)
+	if err != nil {
+		return status.Errorf(codes.Internal, "tree %d: %v", tree.TreeId, err)
+	}
+	if err := verify(pub, hash); err != nil {
+		v.failures.Inc(strconv.FormatInt(tree.TreeId, 10))
+		glog.Errorf("%s%v: stored root failed signature verification: %v", requestid.Prefix(ctx), tree.TreeId, err)
+		return status.Errorf(codes.DataLoss, "tree %d: stored root failed signature verification", tree.TreeId)
+	}
+	return nil
+}
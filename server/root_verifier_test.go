@@ -0,0 +1,193 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTreeWithKey returns a copy of tree with a freshly generated public key,
+// and a signer for it.
+func newTreeWithKey(t *testing.T, tree *trillian.Tree, treeID int64) (*trillian.Tree, *tcrypto.Signer) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	pub, err := der.ToPublicProto(key.Public())
+	if err != nil {
+		t.Fatalf("ToPublicProto(): %v", err)
+	}
+	tree = proto.Clone(tree).(*trillian.Tree)
+	tree.TreeId = treeID
+	tree.PublicKey = pub
+	return tree, tcrypto.NewSigner(0, key, crypto.SHA256)
+}
+
+func TestRootVerifier(t *testing.T) {
+	ctx := context.Background()
+	logTree, logSigner := newTreeWithKey(t, stestonly.LogTree, 1)
+	mapTree, mapSigner := newTreeWithKey(t, stestonly.MapTree, 2)
+	_, otherSigner := newTreeWithKey(t, stestonly.LogTree, 3)
+
+	logRoot := &types.LogRootV1{TreeSize: 7, RootHash: []byte("log root hash"), Revision: 5}
+	mapRoot := &types.MapRootV1{RootHash: []byte("map root hash"), Revision: 5}
+	sign := func(s *tcrypto.Signer) (*trillian.SignedLogRoot, *trillian.SignedMapRoot) {
+		slr, err := s.SignLogRoot(logRoot)
+		if err != nil {
+			t.Fatalf("SignLogRoot(): %v", err)
+		}
+		smr, err := s.SignMapRoot(mapRoot)
+		if err != nil {
+			t.Fatalf("SignMapRoot(): %v", err)
+		}
+		return slr, smr
+	}
+	goodSLR, _ := sign(logSigner)
+	_, goodSMR := sign(mapSigner)
+	otherSLR, otherSMR := sign(otherSigner)
+	tamperedSLR := proto.Clone(goodSLR).(*trillian.SignedLogRoot)
+	tamperedSLR.LogRoot[len(tamperedSLR.LogRoot)-1] ^= 1
+	tamperedSMR := proto.Clone(goodSMR).(*trillian.SignedMapRoot)
+	tamperedSMR.MapRoot[len(tamperedSMR.MapRoot)-1] ^= 1
+	noKeyTree := proto.Clone(logTree).(*trillian.Tree)
+	noKeyTree.PublicKey = nil
+
+	v := newRootVerifier(monitoring.InertMetricFactory{}, "root_signature_failures")
+	for _, test := range []struct {
+		desc     string
+		tree     *trillian.Tree
+		slr      *trillian.SignedLogRoot
+		smr      *trillian.SignedMapRoot
+		wantCode codes.Code
+	}{
+		{desc: "log-ok", tree: logTree, slr: goodSLR},
+		{desc: "log-tampered", tree: logTree, slr: tamperedSLR, wantCode: codes.DataLoss},
+		{desc: "log-other-key", tree: logTree, slr: otherSLR, wantCode: codes.DataLoss},
+		{desc: "log-unsigned", tree: logTree, slr: &trillian.SignedLogRoot{LogRoot: goodSLR.LogRoot}, wantCode: codes.DataLoss},
+		{desc: "log-no-key", tree: noKeyTree, slr: goodSLR, wantCode: codes.Internal},
+		{desc: "map-ok", tree: mapTree, smr: goodSMR},
+		{desc: "map-tampered", tree: mapTree, smr: tamperedSMR, wantCode: codes.DataLoss},
+		{desc: "map-other-key", tree: mapTree, smr: otherSMR, wantCode: codes.DataLoss},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			var err error
+			if test.slr != nil {
+				err = v.verifyLogRoot(ctx, test.tree, test.slr)
+			} else {
+				err = v.verifyMapRoot(ctx, test.tree, test.smr)
+			}
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Errorf("verify: %v, want code %v", err, want)
+			}
+		})
+	}
+}
+
+func TestVerifyRootSignatures(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		desc     string
+		verify   bool
+		badSig   bool
+		wantCode codes.Code
+	}{
+		{desc: "off-bad-sig", badSig: true},
+		{desc: "on-good-sig", verify: true},
+		{desc: "on-bad-sig", verify: true, badSig: true, wantCode: codes.DataLoss},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			logTree, logSigner := newTreeWithKey(t, stestonly.LogTree, logID1)
+			mapTree, mapSigner := newTreeWithKey(t, stestonly.MapTree, mapID1)
+			slr, err := logSigner.SignLogRoot(&types.LogRootV1{TreeSize: 7, RootHash: []byte("log root hash")})
+			if err != nil {
+				t.Fatalf("SignLogRoot(): %v", err)
+			}
+			smr, err := mapSigner.SignMapRoot(&types.MapRootV1{RootHash: []byte("map root hash")})
+			if err != nil {
+				t.Fatalf("SignMapRoot(): %v", err)
+			}
+			if test.badSig {
+				slr.LogRootSignature = []byte("not a signature")
+				smr.Signature = []byte("not a signature")
+			}
+
+			logAdminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			logAdminTX.EXPECT().GetTree(gomock.Any(), logID1).Return(logTree, nil)
+			logAdminTX.EXPECT().Commit().Return(nil)
+			logAdminTX.EXPECT().Close().Return(nil)
+			logTX := storage.NewMockLogTreeTX(ctrl)
+			logTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(slr, nil)
+			logTX.EXPECT().Commit(gomock.Any()).MaxTimes(1).Return(nil)
+			logTX.EXPECT().Close().Return(nil)
+			logStorage := storage.NewMockLogStorage(ctrl)
+			logStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(logTX, nil)
+
+			logServer := NewTrillianLogRPCServer(extension.Registry{
+				AdminStorage: &stestonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{logAdminTX}},
+				LogStorage:   logStorage,
+			}, fakeTimeSource)
+			if test.verify {
+				logServer.VerifyRootSignatures()
+			}
+			_, err = logServer.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: logID1})
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Errorf("GetLatestSignedLogRoot(): %v, want code %v", err, want)
+			}
+
+			mapAdminTX := storage.NewMockReadOnlyAdminTX(ctrl)
+			mapAdminTX.EXPECT().GetTree(gomock.Any(), mapID1).Return(mapTree, nil)
+			mapAdminTX.EXPECT().Commit().Return(nil)
+			mapAdminTX.EXPECT().Close().Return(nil)
+			mapTX := storage.NewMockMapTreeTX(ctrl)
+			mapTX.EXPECT().LatestSignedMapRoot(gomock.Any()).Return(smr, nil)
+			mapTX.EXPECT().Commit(gomock.Any()).MaxTimes(1).Return(nil)
+			mapTX.EXPECT().Close().Return(nil)
+			mapTX.EXPECT().IsOpen().AnyTimes().Return(false)
+			mapStorage := storage.NewMockMapStorage(ctrl)
+			mapStorage.EXPECT().SnapshotForTree(gomock.Any(), gomock.Any()).Return(mapTX, nil)
+
+			mapServer := NewTrillianMapServer(extension.Registry{
+				AdminStorage: &stestonly.FakeAdminStorage{ReadOnlyTX: []storage.ReadOnlyAdminTX{mapAdminTX}},
+				MapStorage:   mapStorage,
+			}, TrillianMapServerOptions{VerifyRootSignatures: test.verify})
+			_, err = mapServer.GetSignedMapRoot(ctx, &trillian.GetSignedMapRootRequest{MapId: mapID1})
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Errorf("GetSignedMapRoot(): %v, want code %v", err, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/health"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// TreeHealthOptions holds the settings of a TreeHealthChecker.
+type TreeHealthOptions struct {
+	// Interval is how often the health of the trees is checked.
+	Interval time.Duration
+	// Timeout bounds each check of the health of all of the trees.
+	Timeout time.Duration
+	// MaxRootAge, if positive, is how much older than its max_root_duration,
+	// or than zero if it has none, the latest root of an active log may be
+	// before its signer is considered stalled, and the log unhealthy. Logs
+	// without a max_root_duration only get new roots when leaves are added, so
+	// the age of their roots only shows stalls of logs which are written to.
+	MaxRootAge time.Duration
+	// TreeTypes are the types of the trees whose health is reported, or all
+	// types if empty.
+	TreeTypes []trillian.TreeType
+	// IsHealthy, if set, checks the health of the server as a whole. While it
+	// fails, the server and all of its trees are reported as not serving.
+	IsHealthy func(context.Context) error
+}
+
+// TreeHealthChecker reports the health of each tree through the services of
+// a GRPC health server, named by TreeHealthService, so that load balancers
+// can route the requests for a tree away from servers which can't serve it,
+// such as when its storage is inaccessible, or its signer has stalled. The
+// health of the server as a whole is reported as the empty service.
+type TreeHealthChecker struct {
+	registry   extension.Registry
+	hs         *health.Server
+	opts       TreeHealthOptions
+	timeSource clock.TimeSource
+	// services are the tree services reported by the last check.
+	services map[string]bool
+}
+
+// NewTreeHealthChecker returns a TreeHealthChecker for the trees in the given
+// registry, which sets the statuses of hs.
+func NewTreeHealthChecker(registry extension.Registry, hs *health.Server, opts TreeHealthOptions) *TreeHealthChecker {
+	return &TreeHealthChecker{
+		registry:   registry,
+		hs:         hs,
+		opts:       opts,
+		timeSource: clock.System,
+		services:   make(map[string]bool),
+	}
+}
+
+// TreeHealthService returns the name of the health service of a tree, e.g.
+// trillian.log.123 for a log, or trillian.map.123 for a map.
+func TreeHealthService(tree *trillian.Tree) string {
+	kind := "log"
+	if tree.TreeType == trillian.TreeType_MAP {
+		kind = "map"
+	}
+	return fmt.Sprintf("trillian.%s.%d", kind, tree.TreeId)
+}
+
+// Run checks the health of the trees every Interval, until ctx is done.
+func (c *TreeHealthChecker) Run(ctx context.Context) {
+	for {
+		if err := c.CheckOnce(ctx); err != nil {
+			glog.Warningf("TreeHealthChecker: %v", err)
+		}
+		if err := clock.SleepContext(ctx, c.opts.Interval); err != nil {
+			return
+		}
+	}
+}
+
+// CheckOnce checks the health of the server and of each of its trees once, and
+// sets their statuses. Trees which are no longer served are reported as
+// unknown services. It returns an error if the server or the list of its trees
+// couldn't be checked, but not for unhealthy trees.
+func (c *TreeHealthChecker) CheckOnce(ctx context.Context) error {
+	if c.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.Timeout)
+		defer cancel()
+	}
+
+	var serverErr error
+	if c.opts.IsHealthy != nil {
+		serverErr = c.opts.IsHealthy(ctx)
+	}
+	var trees []*trillian.Tree
+	if serverErr == nil {
+		trees, serverErr = storage.ListTrees(ctx, c.registry.AdminStorage, false /* includeDeleted */)
+	}
+	if serverErr != nil {
+		c.hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		for service := range c.services {
+			c.hs.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+		return serverErr
+	}
+	c.hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	services := make(map[string]bool)
+	for _, tree := range trees {
+		if !c.reportsTreeType(tree.TreeType) {
+			continue
+		}
+		service := TreeHealthService(tree)
+		services[service] = true
+		status := healthpb.HealthCheckResponse_SERVING
+		if err := c.checkTree(ctx, tree); err != nil {
+			glog.V(1).Infof("%v: tree unhealthy: %v", tree.TreeId, err)
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		c.hs.SetServingStatus(service, status)
+	}
+	for service := range c.services {
+		if !services[service] {
+			c.hs.SetServingStatus(service, healthpb.HealthCheckResponse_SERVICE_UNKNOWN)
+		}
+	}
+	c.services = services
+	return nil
+}
+
+func (c *TreeHealthChecker) reportsTreeType(treeType trillian.TreeType) bool {
+	if len(c.opts.TreeTypes) == 0 {
+		return true
+	}
+	for _, t := range c.opts.TreeTypes {
+		if t == treeType {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTree returns an error if the latest root of the tree can't be read
+// from storage, or if the tree is an active log whose root is stale.
+func (c *TreeHealthChecker) checkTree(ctx context.Context, tree *trillian.Tree) error {
+	switch tree.TreeType {
+	case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
+		return c.checkLog(ctx, tree)
+	case trillian.TreeType_MAP:
+		return c.checkMap(ctx, tree)
+	}
+	return fmt.Errorf("unknown tree type %v", tree.TreeType)
+}
+
+func (c *TreeHealthChecker) checkLog(ctx context.Context, tree *trillian.Tree) error {
+	if c.registry.LogStorage == nil {
+		return errors.New("no log storage")
+	}
+	tx, err := c.registry.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if c.opts.MaxRootAge <= 0 || tree.TreeState != trillian.TreeState_ACTIVE {
+		return nil
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return err
+	}
+	maxAge := c.opts.MaxRootAge
+	if d, err := ptypes.Duration(tree.MaxRootDuration); err == nil {
+		maxAge += d
+	}
+	if age := c.timeSource.Now().Sub(time.Unix(0, int64(root.TimestampNanos))); age > maxAge {
+		return fmt.Errorf("latest root is %v old, over %v", age, maxAge)
+	}
+	return nil
+}
+
+func (c *TreeHealthChecker) checkMap(ctx context.Context, tree *trillian.Tree) error {
+	if c.registry.MapStorage == nil {
+		return errors.New("no map storage")
+	}
+	tx, err := c.registry.MapStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if _, err := tx.LatestSignedMapRoot(ctx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
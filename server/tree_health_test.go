@@ -0,0 +1,182 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/health"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthAdminStorage lists trees, or fails to if err is set.
+type healthAdminStorage struct {
+	storage.AdminStorage
+	trees []*trillian.Tree
+	err   error
+}
+
+func (s *healthAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &healthAdminTX{trees: s.trees}, nil
+}
+
+type healthAdminTX struct {
+	storage.ReadOnlyAdminTX
+	trees []*trillian.Tree
+}
+
+func (tx *healthAdminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	return tx.trees, nil
+}
+func (tx *healthAdminTX) Commit() error { return nil }
+func (tx *healthAdminTX) Close() error  { return nil }
+
+// healthLogStorage serves the latest roots of logs, with the given timestamps,
+// and fails to read those of logs without one.
+type healthLogStorage struct {
+	storage.LogStorage
+	timestamps map[int64]time.Time
+}
+
+func (s *healthLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	ts, ok := s.timestamps[tree.TreeId]
+	if !ok {
+		return nil, errors.New("storage inaccessible")
+	}
+	return &healthLogTX{ts: ts}, nil
+}
+
+type healthLogTX struct {
+	storage.ReadOnlyLogTreeTX
+	ts time.Time
+}
+
+func (tx *healthLogTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	root, err := (&types.LogRootV1{TimestampNanos: uint64(tx.ts.UnixNano())}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.SignedLogRoot{LogRoot: root}, nil
+}
+func (tx *healthLogTX) Commit(context.Context) error { return nil }
+func (tx *healthLogTX) Close() error                 { return nil }
+
+func TestTreeHealthChecker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	fresh := &trillian.Tree{TreeId: 1, TreeType: trillian.TreeType_LOG, TreeState: trillian.TreeState_ACTIVE}
+	stale := &trillian.Tree{TreeId: 2, TreeType: trillian.TreeType_LOG, TreeState: trillian.TreeState_ACTIVE}
+	frozen := &trillian.Tree{TreeId: 3, TreeType: trillian.TreeType_LOG, TreeState: trillian.TreeState_FROZEN}
+	longMRD := &trillian.Tree{TreeId: 4, TreeType: trillian.TreeType_PREORDERED_LOG, TreeState: trillian.TreeState_ACTIVE, MaxRootDuration: ptypes.DurationProto(time.Hour)}
+	broken := &trillian.Tree{TreeId: 5, TreeType: trillian.TreeType_LOG, TreeState: trillian.TreeState_ACTIVE}
+	mapTree := &trillian.Tree{TreeId: 6, TreeType: trillian.TreeType_MAP, TreeState: trillian.TreeState_ACTIVE}
+
+	admin := &healthAdminStorage{trees: []*trillian.Tree{fresh, stale, frozen, longMRD, broken, mapTree}}
+	logs := &healthLogStorage{timestamps: map[int64]time.Time{
+		1: now.Add(-time.Minute),
+		2: now.Add(-time.Hour),
+		3: now.Add(-time.Hour),
+		4: now.Add(-time.Hour),
+	}}
+	var serverErr error
+	hs := health.NewServer()
+	c := NewTreeHealthChecker(extension.Registry{AdminStorage: admin, LogStorage: logs}, hs, TreeHealthOptions{
+		MaxRootAge: 10 * time.Minute,
+		TreeTypes:  []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG},
+		IsHealthy:  func(context.Context) error { return serverErr },
+	})
+	c.timeSource = clock.NewFake(now)
+
+	check := func(desc string, want map[string]healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		for service, wantStatus := range want {
+			rsp, err := hs.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+			if wantStatus == healthpb.HealthCheckResponse_SERVICE_UNKNOWN && err != nil {
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: Check(%q): %v", desc, service, err)
+				continue
+			}
+			if got := rsp.Status; got != wantStatus {
+				t.Errorf("%s: Check(%q)=%v, want %v", desc, service, got, wantStatus)
+			}
+		}
+	}
+
+	if err := c.CheckOnce(ctx); err != nil {
+		t.Fatalf("CheckOnce(): %v", err)
+	}
+	check("healthy", map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":                 healthpb.HealthCheckResponse_SERVING,
+		"trillian.log.1":   healthpb.HealthCheckResponse_SERVING,
+		"trillian.log.2":   healthpb.HealthCheckResponse_NOT_SERVING,
+		"trillian.log.3":   healthpb.HealthCheckResponse_SERVING,
+		"trillian.log.4":   healthpb.HealthCheckResponse_SERVING,
+		"trillian.log.5":   healthpb.HealthCheckResponse_NOT_SERVING,
+		"trillian.map.6":   healthpb.HealthCheckResponse_SERVICE_UNKNOWN,
+		"trillian.log.123": healthpb.HealthCheckResponse_SERVICE_UNKNOWN,
+	})
+
+	// Trees which go away become unknown.
+	admin.trees = []*trillian.Tree{fresh, stale}
+	if err := c.CheckOnce(ctx); err != nil {
+		t.Fatalf("CheckOnce(): %v", err)
+	}
+	check("deleted", map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"trillian.log.1": healthpb.HealthCheckResponse_SERVING,
+		"trillian.log.3": healthpb.HealthCheckResponse_SERVICE_UNKNOWN,
+	})
+
+	// All trees stop serving with the server.
+	serverErr = errors.New("database inaccessible")
+	if err := c.CheckOnce(ctx); err == nil {
+		t.Fatal("CheckOnce() succeeded with unhealthy server")
+	}
+	check("unhealthy", map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":               healthpb.HealthCheckResponse_NOT_SERVING,
+		"trillian.log.1": healthpb.HealthCheckResponse_NOT_SERVING,
+		"trillian.log.2": healthpb.HealthCheckResponse_NOT_SERVING,
+	})
+}
+
+func TestTreeHealthService(t *testing.T) {
+	for _, test := range []struct {
+		tree *trillian.Tree
+		want string
+	}{
+		{tree: &trillian.Tree{TreeId: 12, TreeType: trillian.TreeType_LOG}, want: "trillian.log.12"},
+		{tree: &trillian.Tree{TreeId: 34, TreeType: trillian.TreeType_PREORDERED_LOG}, want: "trillian.log.34"},
+		{tree: &trillian.Tree{TreeId: 56, TreeType: trillian.TreeType_MAP}, want: "trillian.map.56"},
+	} {
+		if got := TreeHealthService(test.tree); got != test.want {
+			t.Errorf("TreeHealthService(%v)=%q, want %q", test.tree, got, test.want)
+		}
+	}
+}
@@ -19,40 +19,57 @@ import (
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/merkle/hashers"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	serrors "github.com/google/trillian/server/errors"
 )
 
 func validateGetInclusionProofRequest(req *trillian.GetInclusionProofRequest) error {
 	if req.TreeSize <= 0 {
-		return status.Errorf(codes.InvalidArgument, "GetInclusionProofRequest.TreeSize: %v, want > 0", req.TreeSize)
+		return serrors.InvalidArgument("tree_size", "GetInclusionProofRequest.TreeSize: %v, want > 0", req.TreeSize)
 	}
 	if req.LeafIndex < 0 {
-		return status.Errorf(codes.InvalidArgument, "GetInclusionProofRequest.LeafIndex: %v, want >= 0", req.LeafIndex)
+		return serrors.InvalidArgument("leaf_index", "GetInclusionProofRequest.LeafIndex: %v, want >= 0", req.LeafIndex)
 	}
 	if req.LeafIndex >= req.TreeSize {
-		return status.Errorf(codes.InvalidArgument, "GetInclusionProofRequest.LeafIndex: %v >= TreeSize: %v, want < ", req.LeafIndex, req.TreeSize)
+		return serrors.InvalidArgument("leaf_index", "GetInclusionProofRequest.LeafIndex: %v >= TreeSize: %v, want < ", req.LeafIndex, req.TreeSize)
+	}
+	return nil
+}
+
+func validateGetInclusionProofsRequest(req *trillian.GetInclusionProofsRequest) error {
+	if req.TreeSize <= 0 {
+		return serrors.InvalidArgument("tree_size", "GetInclusionProofsRequest.TreeSize: %v, want > 0", req.TreeSize)
+	}
+	if len(req.LeafIndex) == 0 {
+		return serrors.InvalidArgument("leaf_index", "GetInclusionProofsRequest.LeafIndex empty")
+	}
+	for i, leafIndex := range req.LeafIndex {
+		if leafIndex < 0 {
+			return serrors.InvalidArgument(fmt.Sprintf("leaf_index[%d]", i), "GetInclusionProofsRequest.LeafIndex[%v]: %v, want >= 0", i, leafIndex)
+		}
+		if leafIndex >= req.TreeSize {
+			return serrors.InvalidArgument(fmt.Sprintf("leaf_index[%d]", i), "GetInclusionProofsRequest.LeafIndex[%v]: %v >= TreeSize: %v, want < ", i, leafIndex, req.TreeSize)
+		}
 	}
 	return nil
 }
 
 func validateGetInclusionProofByHashRequest(req *trillian.GetInclusionProofByHashRequest, hasher hashers.LogHasher) error {
 	if req.TreeSize <= 0 {
-		return status.Errorf(codes.InvalidArgument, "GetInclusionProofByHashRequest.TreeSize: %v, want > 0", req.TreeSize)
+		return serrors.InvalidArgument("tree_size", "GetInclusionProofByHashRequest.TreeSize: %v, want > 0", req.TreeSize)
 	}
 	if err := validateLeafHash(req.LeafHash, hasher); err != nil {
-		return status.Errorf(codes.InvalidArgument, "GetInclusionProofByHashRequest.LeafHash: %v", err)
+		return serrors.InvalidArgument("leaf_hash", "GetInclusionProofByHashRequest.LeafHash: %v", err)
 	}
 	return nil
 }
 
 func validateGetLeavesByHashRequest(req *trillian.GetLeavesByHashRequest, hasher hashers.LogHasher) error {
 	if len(req.LeafHash) == 0 {
-		return status.Error(codes.InvalidArgument, "GetLeavesByHashRequest.LeafHash empty")
+		return serrors.InvalidArgument("leaf_hash", "GetLeavesByHashRequest.LeafHash empty")
 	}
 	for i, hash := range req.LeafHash {
 		if err := validateLeafHash(hash, hasher); err != nil {
-			return status.Errorf(codes.InvalidArgument, "GetLeavesByHashRequest.LeafHash[%v]: %v", i, err)
+			return serrors.InvalidArgument(fmt.Sprintf("leaf_hash[%d]", i), "GetLeavesByHashRequest.LeafHash[%v]: %v", i, err)
 		}
 	}
 	return nil
@@ -60,11 +77,11 @@ func validateGetLeavesByHashRequest(req *trillian.GetLeavesByHashRequest, hasher
 
 func validateGetLeavesByIndexRequest(req *trillian.GetLeavesByIndexRequest) error {
 	if len(req.LeafIndex) == 0 {
-		return status.Error(codes.InvalidArgument, "GetLeavesByIndexRequest.LeafIndex empty")
+		return serrors.InvalidArgument("leaf_index", "GetLeavesByIndexRequest.LeafIndex empty")
 	}
 	for i, leafIndex := range req.LeafIndex {
 		if leafIndex < 0 {
-			return status.Errorf(codes.InvalidArgument, "GetLeavesByIndexRequest.LeafIndex[%v]: %v, want >= 0", i, leafIndex)
+			return serrors.InvalidArgument(fmt.Sprintf("leaf_index[%d]", i), "GetLeavesByIndexRequest.LeafIndex[%v]: %v, want >= 0", i, leafIndex)
 		}
 	}
 	return nil
@@ -72,36 +89,56 @@ func validateGetLeavesByIndexRequest(req *trillian.GetLeavesByIndexRequest) erro
 
 func validateGetLeavesByRangeRequest(req *trillian.GetLeavesByRangeRequest) error {
 	if req.StartIndex < 0 {
-		return status.Errorf(codes.InvalidArgument, "GetLeavesByRangeRequest.StartIndex: %v, want >= 0", req.StartIndex)
+		return serrors.InvalidArgument("start_index", "GetLeavesByRangeRequest.StartIndex: %v, want >= 0", req.StartIndex)
 	}
 	if req.Count <= 0 {
-		return status.Errorf(codes.InvalidArgument, "GetLeavesByRangeRequest.Count: %v, want > 0", req.Count)
+		return serrors.InvalidArgument("count", "GetLeavesByRangeRequest.Count: %v, want > 0", req.Count)
+	}
+	return nil
+}
+
+func validateStreamLeafHashesRequest(req *trillian.StreamLeafHashesRequest) error {
+	if req.TreeSize < 0 {
+		return serrors.InvalidArgument("tree_size", "StreamLeafHashesRequest.TreeSize: %v, want >= 0", req.TreeSize)
+	}
+	if req.CheckpointInterval < 0 {
+		return serrors.InvalidArgument("checkpoint_interval", "StreamLeafHashesRequest.CheckpointInterval: %v, want >= 0", req.CheckpointInterval)
+	}
+	return nil
+}
+
+func validateStreamLeavesRequest(req *trillian.StreamLeavesRequest) error {
+	if req.StartIndex < 0 {
+		return serrors.InvalidArgument("start_index", "StreamLeavesRequest.StartIndex: %v, want >= 0", req.StartIndex)
+	}
+	if req.Count < 0 {
+		return serrors.InvalidArgument("count", "StreamLeavesRequest.Count: %v, want >= 0", req.Count)
 	}
 	return nil
 }
 
 func validateGetConsistencyProofRequest(req *trillian.GetConsistencyProofRequest) error {
 	if req.FirstTreeSize <= 0 {
-		return status.Errorf(codes.InvalidArgument, "GetConsistencyProofRequest.FirstTreeSize: %v, want > 0", req.FirstTreeSize)
+		return serrors.InvalidArgument("first_tree_size", "GetConsistencyProofRequest.FirstTreeSize: %v, want > 0", req.FirstTreeSize)
 	}
 	if req.SecondTreeSize <= 0 {
-		return status.Errorf(codes.InvalidArgument, "GetConsistencyProofRequest.SecondTreeSize: %v, want > 0", req.SecondTreeSize)
+		return serrors.InvalidArgument("second_tree_size", "GetConsistencyProofRequest.SecondTreeSize: %v, want > 0", req.SecondTreeSize)
 	}
 	if req.SecondTreeSize < req.FirstTreeSize {
-		return status.Errorf(codes.InvalidArgument, "GetConsistencyProofRequest.SecondTreeSize: %v < GetConsistencyProofRequest.FirstTreeSize: %v, want >= ", req.SecondTreeSize, req.FirstTreeSize)
+		return serrors.InvalidArgument("second_tree_size", "GetConsistencyProofRequest.SecondTreeSize: %v < GetConsistencyProofRequest.FirstTreeSize: %v, want >= ", req.SecondTreeSize, req.FirstTreeSize)
 	}
 	return nil
 }
 
 func validateGetEntryAndProofRequest(req *trillian.GetEntryAndProofRequest) error {
 	if req.TreeSize <= 0 {
-		return status.Errorf(codes.InvalidArgument, "GetEntryAndProofRequest.TreeSize: %v, want > 0", req.TreeSize)
+		return serrors.InvalidArgument("tree_size", "GetEntryAndProofRequest.TreeSize: %v, want > 0", req.TreeSize)
 	}
 	if req.LeafIndex < 0 {
-		return status.Errorf(codes.InvalidArgument, "GetEntryAndProofRequest.LeafIndex: %v, want >= 0", req.LeafIndex)
+		return serrors.InvalidArgument("leaf_index", "GetEntryAndProofRequest.LeafIndex: %v, want >= 0", req.LeafIndex)
 	}
 	if req.LeafIndex >= req.TreeSize {
-		return status.Errorf(codes.InvalidArgument, "GetEntryAndProofRequest.LeafIndex: %v >= TreeSize: %v, want < ", req.LeafIndex, req.TreeSize)
+		return serrors.InvalidArgument("leaf_index", "GetEntryAndProofRequest.LeafIndex: %v >= TreeSize: %v, want < ", req.LeafIndex, req.TreeSize)
 	}
 	return nil
 }
@@ -116,7 +153,7 @@ func validateAddSequencedLeavesRequest(req *trillian.AddSequencedLeavesRequest)
 	nextIndex := req.Leaves[0].LeafIndex
 	for i, leaf := range req.Leaves {
 		if leaf.LeafIndex != nextIndex {
-			return status.Errorf(codes.FailedPrecondition, "%v.Leaves[%v].LeafIndex=%v, want %v", prefix, i, leaf.LeafIndex, nextIndex)
+			return serrors.FailedPrecondition("LEAF_INDEX", fmt.Sprintf("leaves[%d].leaf_index", i), "%v.Leaves[%v].LeafIndex=%v, want %v", prefix, i, leaf.LeafIndex, nextIndex)
 		}
 		nextIndex++
 	}
@@ -125,25 +162,27 @@ func validateAddSequencedLeavesRequest(req *trillian.AddSequencedLeavesRequest)
 
 func validateLogLeaves(leaves []*trillian.LogLeaf, errPrefix string) error {
 	if len(leaves) == 0 {
-		return status.Errorf(codes.InvalidArgument, "%v.Leaves empty", errPrefix)
+		return serrors.InvalidArgument("leaves", "%v.Leaves empty", errPrefix)
 	}
 	for i, leaf := range leaves {
-		if err := validateLogLeaf(leaf, ""); err != nil {
-			return status.Errorf(codes.InvalidArgument, "%v.Leaves[%v]%v", errPrefix, i, err)
+		if err := validateLogLeaf(leaf, fmt.Sprintf("%v.Leaves[%v]", errPrefix, i), fmt.Sprintf("leaves[%d]", i)); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-func validateLogLeaf(leaf *trillian.LogLeaf, errPrefix string) error {
+// validateLogLeaf checks a leaf of a request, whose path in the request is
+// field, and which is named errPrefix in error messages.
+func validateLogLeaf(leaf *trillian.LogLeaf, errPrefix, field string) error {
 	if leaf == nil {
-		return status.Errorf(codes.InvalidArgument, "%v empty", errPrefix)
+		return serrors.InvalidArgument(field, "%v empty", errPrefix)
 	}
 	switch {
 	case len(leaf.LeafValue) == 0:
-		return status.Errorf(codes.InvalidArgument, "%v.LeafValue: empty", errPrefix)
+		return serrors.InvalidArgument(field+".leaf_value", "%v.LeafValue: empty", errPrefix)
 	case leaf.LeafIndex < 0:
-		return status.Errorf(codes.InvalidArgument, "%v.LeafIndex: %v, want >= 0", errPrefix, leaf.LeafIndex)
+		return serrors.InvalidArgument(field+".leaf_index", "%v.LeafIndex: %v, want >= 0", errPrefix, leaf.LeafIndex)
 	}
 	return nil
 }
@@ -0,0 +1,70 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang/glog"
+)
+
+func init() {
+	for name, f := range map[string]NewValidatorFunc{
+		"x509-der":    noArg(x509DER),
+		"json":        noArg(validJSON),
+		"json-schema": newJSONSchema,
+	} {
+		if err := Register(name, f); err != nil {
+			glog.Fatalf("Failed to register %v validator: %v", name, err)
+		}
+	}
+}
+
+// ValidatorFunc adapts a function to the Validator interface.
+type ValidatorFunc func(value []byte) error
+
+// Validate calls f(value).
+func (f ValidatorFunc) Validate(value []byte) error {
+	return f(value)
+}
+
+// noArg returns a NewValidatorFunc for validators which take no argument.
+func noArg(f ValidatorFunc) NewValidatorFunc {
+	return func(arg string) (Validator, error) {
+		if arg != "" {
+			return nil, errors.New("takes no argument")
+		}
+		return f, nil
+	}
+}
+
+// x509DER accepts DER-encoded X.509 certificates.
+func x509DER(value []byte) error {
+	if _, err := x509.ParseCertificate(value); err != nil {
+		return fmt.Errorf("not a DER-encoded X.509 certificate: %v", err)
+	}
+	return nil
+}
+
+// validJSON accepts any JSON value.
+func validJSON(value []byte) error {
+	if !json.Valid(value) {
+		return errors.New("not valid JSON")
+	}
+	return nil
+}
@@ -0,0 +1,569 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSchemaBytes is the maximum size of JSON schemas fetched over HTTP.
+const maxSchemaBytes = 1 << 20
+
+// newJSONSchema returns a validator accepting JSON values which conform to
+// the JSON schema at the URL in arg, which may be a file, http or https URL.
+//
+// Only the keywords of JSON Schema (draft 7) which don't refer to other
+// schemas or documents are supported: type, enum, const, the numeric, string,
+// array and object assertions, and allOf, anyOf, oneOf and not. Annotations
+// such as title and format are ignored. Schemas using other keywords, such as
+// $ref or patternProperties, are rejected rather than partially enforced.
+func newJSONSchema(arg string) (Validator, error) {
+	if arg == "" {
+		return nil, errors.New("needs the URL of a JSON schema")
+	}
+	raw, err := fetchSchema(arg)
+	if err != nil {
+		return nil, err
+	}
+	return compileSchema(raw)
+}
+
+// fetchSchema returns the document at the given file, http or https URL.
+func fetchSchema(rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema URL: %v", err)
+	}
+	switch u.Scheme {
+	case "file":
+		return ioutil.ReadFile(u.Path)
+	case "http", "https":
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Get(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %v: %v", rawURL, resp.Status)
+		}
+		return ioutil.ReadAll(io.LimitReader(resp.Body, maxSchemaBytes))
+	default:
+		return nil, fmt.Errorf("unsupported schema URL scheme %q", u.Scheme)
+	}
+}
+
+// annotations are the keywords which don't constrain values, and are ignored.
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "format": true, "readOnly": true, "writeOnly": true,
+	"contentMediaType": true, "contentEncoding": true,
+}
+
+// schema is a compiled JSON schema.
+type schema struct {
+	// accept is set for the boolean schemas true and false.
+	accept *bool
+
+	types    map[string]bool
+	enum     []interface{}
+	hasConst bool
+	constVal interface{}
+
+	minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf *float64
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+
+	items              *schema
+	minItems, maxItems *int
+	uniqueItems        bool
+
+	properties                   map[string]*schema
+	required                     []string
+	additionalProperties         *schema
+	minProperties, maxProperties *int
+
+	allOf, anyOf, oneOf []*schema
+	not                 *schema
+}
+
+// compileSchema parses and compiles a JSON schema.
+func compileSchema(raw []byte) (*schema, error) {
+	v, err := decodeJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %v", err)
+	}
+	return compile(v, "#")
+}
+
+// decodeJSON decodes a single JSON value, with numbers as json.Number.
+func decodeJSON(raw []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("trailing data after JSON value")
+	}
+	return v, nil
+}
+
+func compile(v interface{}, path string) (*schema, error) {
+	if b, ok := v.(bool); ok {
+		return &schema{accept: &b}, nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: schema is not an object or boolean", path)
+	}
+	s := &schema{}
+	// Compile keywords in a fixed order, so that errors are deterministic.
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := s.compileKeyword(k, m[k], path+"/"+k); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *schema) compileKeyword(k string, v interface{}, path string) error {
+	var err error
+	switch k {
+	case "type":
+		s.types = make(map[string]bool)
+		switch t := v.(type) {
+		case string:
+			s.types[t] = true
+		case []interface{}:
+			for _, e := range t {
+				name, ok := e.(string)
+				if !ok {
+					return fmt.Errorf("%s: not a type name", path)
+				}
+				s.types[name] = true
+			}
+		default:
+			return fmt.Errorf("%s: not a type name or list of them", path)
+		}
+		for t := range s.types {
+			switch t {
+			case "null", "boolean", "object", "array", "number", "integer", "string":
+			default:
+				return fmt.Errorf("%s: unknown type %q", path, t)
+			}
+		}
+	case "enum":
+		e, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: not an array", path)
+		}
+		for _, x := range e {
+			s.enum = append(s.enum, normalize(x))
+		}
+	case "const":
+		s.hasConst, s.constVal = true, normalize(v)
+	case "minimum":
+		s.minimum, err = number(v, path)
+	case "maximum":
+		s.maximum, err = number(v, path)
+	case "exclusiveMinimum":
+		s.exclusiveMinimum, err = number(v, path)
+	case "exclusiveMaximum":
+		s.exclusiveMaximum, err = number(v, path)
+	case "multipleOf":
+		if s.multipleOf, err = number(v, path); err == nil && *s.multipleOf <= 0 {
+			err = fmt.Errorf("%s: not positive", path)
+		}
+	case "minLength":
+		s.minLength, err = count(v, path)
+	case "maxLength":
+		s.maxLength, err = count(v, path)
+	case "pattern":
+		p, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: not a string", path)
+		}
+		if s.pattern, err = regexp.Compile(p); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	case "items":
+		if _, ok := v.([]interface{}); ok {
+			return fmt.Errorf("%s: tuple validation is not supported", path)
+		}
+		s.items, err = compile(v, path)
+	case "minItems":
+		s.minItems, err = count(v, path)
+	case "maxItems":
+		s.maxItems, err = count(v, path)
+	case "uniqueItems":
+		b, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("%s: not a boolean", path)
+		}
+		s.uniqueItems = b
+	case "properties":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: not an object", path)
+		}
+		s.properties = make(map[string]*schema)
+		for name, p := range m {
+			if s.properties[name], err = compile(p, path+"/"+name); err != nil {
+				return err
+			}
+		}
+	case "required":
+		r, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: not an array", path)
+		}
+		for _, e := range r {
+			name, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("%s: not a property name", path)
+			}
+			s.required = append(s.required, name)
+		}
+	case "additionalProperties":
+		s.additionalProperties, err = compile(v, path)
+	case "minProperties":
+		s.minProperties, err = count(v, path)
+	case "maxProperties":
+		s.maxProperties, err = count(v, path)
+	case "allOf":
+		s.allOf, err = compileList(v, path)
+	case "anyOf":
+		s.anyOf, err = compileList(v, path)
+	case "oneOf":
+		s.oneOf, err = compileList(v, path)
+	case "not":
+		s.not, err = compile(v, path)
+	default:
+		if !annotations[k] {
+			return fmt.Errorf("%s: unsupported keyword", path)
+		}
+	}
+	return err
+}
+
+func compileList(v interface{}, path string) ([]*schema, error) {
+	l, ok := v.([]interface{})
+	if !ok || len(l) == 0 {
+		return nil, fmt.Errorf("%s: not a non-empty array", path)
+	}
+	r := make([]*schema, 0, len(l))
+	for i, e := range l {
+		s, err := compile(e, fmt.Sprintf("%s/%d", path, i))
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, s)
+	}
+	return r, nil
+}
+
+func number(v interface{}, path string) (*float64, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("%s: not a number", path)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &f, nil
+}
+
+func count(v interface{}, path string) (*int, error) {
+	f, err := number(v, path)
+	if err != nil {
+		return nil, err
+	}
+	if *f < 0 || *f != math.Trunc(*f) || *f > math.MaxInt32 {
+		return nil, fmt.Errorf("%s: not a non-negative integer", path)
+	}
+	n := int(*f)
+	return &n, nil
+}
+
+// normalize returns v with its numbers as float64s, so that equal values
+// compare equal with reflect.DeepEqual whatever their representation.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return f
+	case []interface{}:
+		r := make([]interface{}, len(v))
+		for i, e := range v {
+			r[i] = normalize(e)
+		}
+		return r
+	case map[string]interface{}:
+		r := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			r[k] = normalize(e)
+		}
+		return r
+	}
+	return v
+}
+
+// Validate implements Validator.
+func (s *schema) Validate(value []byte) error {
+	v, err := decodeJSON(value)
+	if err != nil {
+		return fmt.Errorf("not valid JSON: %v", err)
+	}
+	return s.check(v, "")
+}
+
+// check returns an error if v doesn't conform to s. path is the JSON pointer
+// of v in the validated document.
+func (s *schema) check(v interface{}, path string) error {
+	if s.accept != nil {
+		if !*s.accept {
+			return fmt.Errorf("%s: no value is allowed", pointer(path))
+		}
+		return nil
+	}
+	if s.types != nil && !s.types[typeOf(v)] && !(s.types["number"] && typeOf(v) == "integer") {
+		return fmt.Errorf("%s: %s is not of type %v", pointer(path), typeOf(v), sortedKeys(s.types))
+	}
+	if s.enum != nil || s.hasConst {
+		nv := normalize(v)
+		if s.hasConst && !reflect.DeepEqual(nv, s.constVal) {
+			return fmt.Errorf("%s: value is not the constant", pointer(path))
+		}
+		if s.enum != nil && !contains(s.enum, nv) {
+			return fmt.Errorf("%s: value is not one of the enumerated ones", pointer(path))
+		}
+	}
+
+	var err error
+	switch v := v.(type) {
+	case json.Number:
+		err = s.checkNumber(v, path)
+	case string:
+		err = s.checkString(v, path)
+	case []interface{}:
+		err = s.checkArray(v, path)
+	case map[string]interface{}:
+		err = s.checkObject(v, path)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range s.allOf {
+		if err := sub.check(v, path); err != nil {
+			return err
+		}
+	}
+	if s.anyOf != nil {
+		ok := false
+		for _, sub := range s.anyOf {
+			if sub.check(v, path) == nil {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s: value matches none of the anyOf schemas", pointer(path))
+		}
+	}
+	if s.oneOf != nil {
+		n := 0
+		for _, sub := range s.oneOf {
+			if sub.check(v, path) == nil {
+				n++
+			}
+		}
+		if n != 1 {
+			return fmt.Errorf("%s: value matches %d of the oneOf schemas, want 1", pointer(path), n)
+		}
+	}
+	if s.not != nil && s.not.check(v, path) == nil {
+		return fmt.Errorf("%s: value matches the not schema", pointer(path))
+	}
+	return nil
+}
+
+func (s *schema) checkNumber(n json.Number, path string) error {
+	f, err := n.Float64()
+	if err != nil {
+		return fmt.Errorf("%s: %v", pointer(path), err)
+	}
+	switch {
+	case s.minimum != nil && f < *s.minimum:
+		return fmt.Errorf("%s: %v is less than the minimum %v", pointer(path), f, *s.minimum)
+	case s.maximum != nil && f > *s.maximum:
+		return fmt.Errorf("%s: %v is more than the maximum %v", pointer(path), f, *s.maximum)
+	case s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum:
+		return fmt.Errorf("%s: %v is not more than %v", pointer(path), f, *s.exclusiveMinimum)
+	case s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum:
+		return fmt.Errorf("%s: %v is not less than %v", pointer(path), f, *s.exclusiveMaximum)
+	case s.multipleOf != nil:
+		if q := f / *s.multipleOf; q != math.Trunc(q) {
+			return fmt.Errorf("%s: %v is not a multiple of %v", pointer(path), f, *s.multipleOf)
+		}
+	}
+	return nil
+}
+
+func (s *schema) checkString(str, path string) error {
+	n := utf8.RuneCountInString(str)
+	switch {
+	case s.minLength != nil && n < *s.minLength:
+		return fmt.Errorf("%s: string is shorter than %d characters", pointer(path), *s.minLength)
+	case s.maxLength != nil && n > *s.maxLength:
+		return fmt.Errorf("%s: string is longer than %d characters", pointer(path), *s.maxLength)
+	case s.pattern != nil && !s.pattern.MatchString(str):
+		return fmt.Errorf("%s: string doesn't match %q", pointer(path), s.pattern)
+	}
+	return nil
+}
+
+func (s *schema) checkArray(a []interface{}, path string) error {
+	switch {
+	case s.minItems != nil && len(a) < *s.minItems:
+		return fmt.Errorf("%s: array has fewer than %d items", pointer(path), *s.minItems)
+	case s.maxItems != nil && len(a) > *s.maxItems:
+		return fmt.Errorf("%s: array has more than %d items", pointer(path), *s.maxItems)
+	}
+	if s.uniqueItems {
+		var seen []interface{}
+		for _, e := range a {
+			ne := normalize(e)
+			if contains(seen, ne) {
+				return fmt.Errorf("%s: array items are not unique", pointer(path))
+			}
+			seen = append(seen, ne)
+		}
+	}
+	if s.items != nil {
+		for i, e := range a {
+			if err := s.items.check(e, fmt.Sprintf("%s/%d", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *schema) checkObject(o map[string]interface{}, path string) error {
+	switch {
+	case s.minProperties != nil && len(o) < *s.minProperties:
+		return fmt.Errorf("%s: object has fewer than %d properties", pointer(path), *s.minProperties)
+	case s.maxProperties != nil && len(o) > *s.maxProperties:
+		return fmt.Errorf("%s: object has more than %d properties", pointer(path), *s.maxProperties)
+	}
+	for _, name := range s.required {
+		if _, ok := o[name]; !ok {
+			return fmt.Errorf("%s: required property %q is missing", pointer(path), name)
+		}
+	}
+	for _, name := range sortedKeys(o) {
+		sub, ok := s.properties[name]
+		if !ok {
+			sub = s.additionalProperties
+		}
+		if sub == nil {
+			continue
+		}
+		if err := sub.check(o[name], path+"/"+escapePointer(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeOf returns the JSON schema type of v, which is integer for integral
+// numbers.
+func typeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func contains(l []interface{}, v interface{}) bool {
+	for _, e := range l {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pointer returns the JSON pointer path, or the root pointer if it's empty.
+func pointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// escapePointer escapes a property name for use in a JSON pointer.
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testSchema = `{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"title": "entry",
+	"type": "object",
+	"required": ["name", "version"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "maxLength": 8, "pattern": "^[a-z]+$"},
+		"version": {"type": "integer", "minimum": 1, "exclusiveMaximum": 100},
+		"score": {"type": "number", "multipleOf": 0.5},
+		"kind": {"enum": ["a", "b", 3]},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2, "uniqueItems": true},
+		"meta": {"type": ["object", "null"], "maxProperties": 1},
+		"either": {"oneOf": [{"type": "string"}, {"type": "integer"}]},
+		"fixed": {"const": {"x": 1}},
+		"other": {"not": {"type": "null"}, "anyOf": [{"type": "boolean"}, {"type": "string"}]}
+	},
+	"additionalProperties": false
+}`
+
+func TestJSONSchema(t *testing.T) {
+	s, err := compileSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("compileSchema(): %v", err)
+	}
+	for _, test := range []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: `{"name": "abc", "version": 1}`},
+		{value: `{"name": "abc", "version": 1.0, "score": 2.5, "kind": 3.0, "tags": ["x", "y"], "meta": null, "either": 2, "fixed": {"x": 1.0}, "other": true}`},
+		{value: `{"name": "abc", "version": 1} {}`, wantErr: true},
+		{value: `[]`, wantErr: true},
+		{value: `{"name": "abc"}`, wantErr: true},
+		{value: `{"name": "", "version": 1}`, wantErr: true},
+		{value: `{"name": "abcdefghi", "version": 1}`, wantErr: true},
+		{value: `{"name": "ABC", "version": 1}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1.5}`, wantErr: true},
+		{value: `{"name": "abc", "version": 0}`, wantErr: true},
+		{value: `{"name": "abc", "version": 100}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1, "score": 2.2}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1, "kind": "c"}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1, "tags": ["x", 1]}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1, "tags": ["x", "x"]}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1, "tags": ["x", "y", "z"]}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1, "meta": {"a": 1, "b": 2}}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1, "either": 1.5}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1, "fixed": {"x": 2}}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1, "other": null}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1, "other": 1}`, wantErr: true},
+		{value: `{"name": "abc", "version": 1, "extra": 1}`, wantErr: true},
+	} {
+		if err := s.Validate([]byte(test.value)); (err != nil) != test.wantErr {
+			t.Errorf("Validate(%s): %v, want error: %v", test.value, err, test.wantErr)
+		}
+	}
+}
+
+func TestCompileSchemaErrors(t *testing.T) {
+	for _, schema := range []string{
+		`[]`,
+		`{"$ref": "#/definitions/a"}`,
+		`{"patternProperties": {}}`,
+		`{"type": "float"}`,
+		`{"minLength": -1}`,
+		`{"multipleOf": 0}`,
+		`{"pattern": "("}`,
+		`{"items": [{"type": "string"}]}`,
+		`{"anyOf": []}`,
+		`{"properties": {"a": {"$ref": "#"}}}`,
+	} {
+		if _, err := compileSchema([]byte(schema)); err == nil {
+			t.Errorf("compileSchema(%s): nil error, want error", schema)
+		}
+	}
+}
+
+func TestNewJSONSchema(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validators")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "schema.json")
+	if err := ioutil.WriteFile(path, []byte(testSchema), 0644); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/schema.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testSchema))
+	}))
+	defer srv.Close()
+
+	for _, test := range []struct {
+		spec    string
+		wantErr bool
+	}{
+		{spec: "json-schema:file://" + path},
+		{spec: "json-schema:" + srv.URL + "/schema.json"},
+		{spec: "json-schema:" + srv.URL + "/missing.json", wantErr: true},
+		{spec: "json-schema:file://" + filepath.Join(dir, "missing.json"), wantErr: true},
+		{spec: "json-schema:ftp://example.com/schema.json", wantErr: true},
+		{spec: "json-schema", wantErr: true},
+	} {
+		v, err := New(test.spec)
+		if (err != nil) != test.wantErr {
+			t.Errorf("New(%q): %v, want error: %v", test.spec, err, test.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if err := v.Validate([]byte(`{"name": "abc", "version": 1}`)); err != nil {
+			t.Errorf("New(%q).Validate(): %v", test.spec, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package validators checks the content of the leaves added to trees, so that
+// servers can reject malformed content at ingestion, as a defense in depth
+// for personalities which already check it.
+//
+// Validators are named by specs of the form "name" or "name:argument", such
+// as "x509-der" or "json-schema:file:///etc/trillian/entry.json", where name
+// is that of a validator registered with Register, and the argument is passed
+// to it. Validators are assigned to trees, or to all the trees of a type, by a
+// Config.
+package validators
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/trillian"
+)
+
+// Validator checks the values of leaves.
+type Validator interface {
+	// Validate returns an error describing what's wrong with value, if
+	// anything.
+	Validate(value []byte) error
+}
+
+// NewValidatorFunc is the signature of a function which can be registered to
+// create validators, given the argument of their spec, which is empty if it
+// has none.
+type NewValidatorFunc func(arg string) (Validator, error)
+
+var (
+	vMu     sync.RWMutex
+	vByName = make(map[string]NewValidatorFunc)
+)
+
+// Register registers a validator under the given name, which must not contain
+// a colon.
+func Register(name string, f NewValidatorFunc) error {
+	if name == "" || strings.Contains(name, ":") {
+		return fmt.Errorf("invalid validator name %q", name)
+	}
+	vMu.Lock()
+	defer vMu.Unlock()
+	if _, exists := vByName[name]; exists {
+		return fmt.Errorf("validator %v already registered", name)
+	}
+	vByName[name] = f
+	return nil
+}
+
+// New returns the validator with the given spec.
+func New(spec string) (Validator, error) {
+	name, arg := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		name, arg = spec[:i], spec[i+1:]
+	}
+	vMu.RLock()
+	f := vByName[name]
+	vMu.RUnlock()
+	if f == nil {
+		return nil, fmt.Errorf("no such validator %v", name)
+	}
+	v, err := f(arg)
+	if err != nil {
+		return nil, fmt.Errorf("validator %v: %v", spec, err)
+	}
+	return v, nil
+}
+
+// Names returns the names of all registered validators, sorted.
+func Names() []string {
+	vMu.RLock()
+	defer vMu.RUnlock()
+	r := []string{}
+	for k := range vByName {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// Named is a validator along with its spec, for error messages.
+type Named struct {
+	Validator
+	Spec string
+}
+
+// Config assigns validators to trees, by tree ID, or by tree type for the
+// trees which have none of their own. The zero Config assigns none.
+type Config struct {
+	byTree map[int64]*Named
+	byType map[trillian.TreeType]*Named
+}
+
+// ParseConfig parses a comma-separated list of key=spec pairs, where key is a
+// tree ID or a tree type, such as LOG or PREORDERED_LOG, and creates their
+// validators.
+func ParseConfig(s string) (*Config, error) {
+	c := &Config{byTree: make(map[int64]*Named), byType: make(map[trillian.TreeType]*Named)}
+	if s == "" {
+		return c, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid validator assignment %q, want key=spec", pair)
+		}
+		key := strings.TrimSpace(parts[0])
+		v, err := New(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+		named := &Named{Validator: v, Spec: strings.TrimSpace(parts[1])}
+
+		if treeType, ok := trillian.TreeType_value[key]; ok && trillian.TreeType(treeType) != trillian.TreeType_UNKNOWN_TREE_TYPE {
+			if _, exists := c.byType[trillian.TreeType(treeType)]; exists {
+				return nil, fmt.Errorf("several validators for tree type %v", key)
+			}
+			c.byType[trillian.TreeType(treeType)] = named
+			continue
+		}
+		treeID, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || treeID <= 0 {
+			return nil, fmt.Errorf("invalid tree ID or type %q in %q", key, pair)
+		}
+		if _, exists := c.byTree[treeID]; exists {
+			return nil, fmt.Errorf("several validators for tree %d", treeID)
+		}
+		c.byTree[treeID] = named
+	}
+	return c, nil
+}
+
+// For returns the validator of tree, or nil if it has none. It may be called
+// on a nil Config, which assigns none.
+func (c *Config) For(tree *trillian.Tree) *Named {
+	if c == nil {
+		return nil
+	}
+	if v, ok := c.byTree[tree.TreeId]; ok {
+		return v
+	}
+	return c.byType[tree.TreeType]
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+)
+
+func TestParseConfig(t *testing.T) {
+	logTree := &trillian.Tree{TreeId: 1, TreeType: trillian.TreeType_LOG}
+	otherLogTree := &trillian.Tree{TreeId: 2, TreeType: trillian.TreeType_LOG}
+	mapTree := &trillian.Tree{TreeId: 3, TreeType: trillian.TreeType_MAP}
+
+	for _, test := range []struct {
+		desc     string
+		s        string
+		wantErr  bool
+		wantSpec map[*trillian.Tree]string
+	}{
+		{desc: "empty", wantSpec: map[*trillian.Tree]string{logTree: "", mapTree: ""}},
+		{
+			desc:     "by-type-and-tree",
+			s:        "LOG=json, 1=x509-der",
+			wantSpec: map[*trillian.Tree]string{logTree: "x509-der", otherLogTree: "json", mapTree: ""},
+		},
+		{desc: "bad-pair", s: "LOG", wantErr: true},
+		{desc: "no-spec", s: "LOG=", wantErr: true},
+		{desc: "unknown-validator", s: "LOG=foo", wantErr: true},
+		{desc: "bad-argument", s: "LOG=json:foo", wantErr: true},
+		{desc: "bad-key", s: "LOGS=json", wantErr: true},
+		{desc: "unknown-type", s: "UNKNOWN_TREE_TYPE=json", wantErr: true},
+		{desc: "negative-tree", s: "-1=json", wantErr: true},
+		{desc: "duplicate-type", s: "LOG=json,LOG=x509-der", wantErr: true},
+		{desc: "duplicate-tree", s: "1=json,1=x509-der", wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			c, err := ParseConfig(test.s)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("ParseConfig(%q): %v, want error: %v", test.s, err, test.wantErr)
+			}
+			for tree, want := range test.wantSpec {
+				got := ""
+				if v := c.For(tree); v != nil {
+					got = v.Spec
+				}
+				if got != want {
+					t.Errorf("For(tree %d): %q, want %q", tree.TreeId, got, want)
+				}
+			}
+		})
+	}
+
+	var nilConfig *Config
+	if v := nilConfig.For(logTree); v != nil {
+		t.Errorf("nil Config: For()=%v, want nil", v)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{"", "a:b", "json"} {
+		if err := Register(name, noArg(validJSON)); err == nil {
+			t.Errorf("Register(%q): nil error, want error", name)
+		}
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("CreateCertificate(): %v", err)
+	}
+
+	for _, test := range []struct {
+		spec    string
+		value   []byte
+		wantErr bool
+	}{
+		{spec: "x509-der", value: cert},
+		{spec: "x509-der", value: cert[:len(cert)-1], wantErr: true},
+		{spec: "x509-der", value: []byte("not a certificate"), wantErr: true},
+		{spec: "json", value: []byte(`{"a": [1, 2]}`)},
+		{spec: "json", value: []byte(`{"a": `), wantErr: true},
+	} {
+		v, err := New(test.spec)
+		if err != nil {
+			t.Fatalf("New(%q): %v", test.spec, err)
+		}
+		if err := v.Validate(test.value); (err != nil) != test.wantErr {
+			t.Errorf("%s: Validate(%q): %v, want error: %v", test.spec, test.value, err, test.wantErr)
+		}
+	}
+}
@@ -20,6 +20,8 @@ import (
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const traceSpanRoot = "/trillian/storage"
@@ -54,6 +56,42 @@ func ListTrees(ctx context.Context, admin AdminStorage, includeDeleted bool) ([]
 	return resp, err
 }
 
+// GetTreeByCreateRequestID reads the tree, deleted or not, which was created
+// with the given create_request_id using a snapshot transaction. It returns a
+// NotFound error if there is none.
+// It's a convenience wrapper around RunInAdminSnapshot and FindTreeByCreateRequestID.
+func GetTreeByCreateRequestID(ctx context.Context, admin AdminStorage, requestID string) (*trillian.Tree, error) {
+	ctx, spanEnd := spanFor(ctx, "GetTreeByCreateRequestID")
+	defer spanEnd()
+	var tree *trillian.Tree
+	err := RunInAdminSnapshot(ctx, admin, func(tx ReadOnlyAdminTX) error {
+		var err error
+		tree, err = FindTreeByCreateRequestID(ctx, tx, requestID)
+		return err
+	})
+	return tree, err
+}
+
+// FindTreeByCreateRequestID returns the tree, deleted or not, which was
+// created with the given create_request_id, or a NotFound error. It uses the
+// index of transactions which implement CreateRequestIDReader, and lists the
+// trees of others.
+func FindTreeByCreateRequestID(ctx context.Context, tx AdminReader, requestID string) (*trillian.Tree, error) {
+	if r, ok := tx.(CreateRequestIDReader); ok {
+		return r.GetTreeByCreateRequestID(ctx, requestID)
+	}
+	trees, err := tx.ListTrees(ctx, true /* includeDeleted */)
+	if err != nil {
+		return nil, err
+	}
+	for _, tree := range trees {
+		if tree.CreateRequestId == requestID {
+			return tree, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "no tree was created with request ID %q", requestID)
+}
+
 // CreateTree creates a tree in storage.
 // It's a convenience wrapper around ReadWriteTransaction and AdminWriter's CreateTree.
 // See ReadWriteTransaction if you need to perform more than one action per transaction.
@@ -90,6 +90,15 @@ type AdminReader interface {
 	ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error)
 }
 
+// CreateRequestIDReader is implemented by the ReadOnlyAdminTX of admin storage
+// which indexes trees by their create_request_id. FindTreeByCreateRequestID
+// lists the trees of other storage instead.
+type CreateRequestIDReader interface {
+	// GetTreeByCreateRequestID returns the tree, deleted or not, which was
+	// created with the given create_request_id, or a NotFound error.
+	GetTreeByCreateRequestID(ctx context.Context, requestID string) (*trillian.Tree, error)
+}
+
 // AdminWriter provides a write-only interface for tree data.
 type AdminWriter interface {
 	// CreateTree inserts the specified tree in storage, returning a tree
@@ -26,6 +26,6 @@ import (
 
 // NodeStorage provides an interface for storing and retrieving subtrees.
 type NodeStorage interface {
-	GetSubtree(n tree.NodeID) (*storagepb.SubtreeProto, error)
+	GetSubtree(n tree.NodeID2) (*storagepb.SubtreeProto, error)
 	SetSubtrees(ctx context.Context, s []*storagepb.SubtreeProto) error
 }
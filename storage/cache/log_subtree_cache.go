@@ -74,12 +74,7 @@ func populateLogSubtreeNodes(hasher hashers.LogHasher) storage.PopulateSubtreeFu
 			// Don't put leaves into the internal map and only update if we're rebuilding internal
 			// nodes. If the subtree was saved with internal nodes then we don't touch the map.
 			if id.Level > 0 && len(st.Leaves) == maxLeaves {
-				subDepth := logStrataDepth - int(id.Level)
-				// TODO(Martin2112): See if we can possibly avoid the expense hiding inside NewNodeIDFromPrefix.
-				nodeID := tree.NewNodeIDFromPrefix(st.Prefix, subDepth, int64(id.Index), logStrataDepth, maxLogDepth)
-				sfx := nodeID.Suffix(len(st.Prefix), int(st.Depth))
-				sfxKey := sfx.String()
-				st.InternalNodes[sfxKey] = hash
+				st.InternalNodes[logSuffix(logStrataDepth-uint(id.Level), id.Index).String()] = hash
 			}
 		}
 
@@ -88,10 +83,8 @@ func populateLogSubtreeNodes(hasher hashers.LogHasher) storage.PopulateSubtreeFu
 
 		// We need to update the subtree root hash regardless of whether it's fully populated
 		for leafIndex := int64(0); leafIndex < int64(len(st.Leaves)); leafIndex++ {
-			nodeID := tree.NewNodeIDFromPrefix(st.Prefix, logStrataDepth, leafIndex, logStrataDepth, maxLogDepth)
-			sfx := nodeID.Suffix(len(st.Prefix), int(st.Depth))
-			sfxKey := sfx.String()
-			h := st.Leaves[sfxKey]
+			sfx := logSuffix(logStrataDepth, uint64(leafIndex))
+			h := st.Leaves[sfx.String()]
 			if h == nil {
 				return fmt.Errorf("unexpectedly got nil for subtree leaf suffix %s", sfx)
 			}
@@ -121,6 +114,14 @@ func populateLogSubtreeNodes(hasher hashers.LogHasher) storage.PopulateSubtreeFu
 	}
 }
 
+// logSuffix returns the suffix of the node of a log subtree at the given depth
+// below the subtree root and with the given index at that depth, which is the
+// top depth bits of a single byte. Log subtree suffixes are cached, so this
+// doesn't allocate.
+func logSuffix(depth uint, index uint64) *tree.Suffix {
+	return tree.NewSuffix(uint8(depth), []byte{byte(index << (logStrataDepth - depth))})
+}
+
 // prepareLogSubtreeWrite prepares a log subtree for writing. If the subtree is fully
 // populated the internal nodes are cleared. Otherwise they are written.
 //
@@ -36,7 +36,7 @@ func (m *MockNodeStorage) EXPECT() *MockNodeStorageMockRecorder {
 }
 
 // GetSubtree mocks base method
-func (m *MockNodeStorage) GetSubtree(arg0 tree.NodeID) (*storagepb.SubtreeProto, error) {
+func (m *MockNodeStorage) GetSubtree(arg0 tree.NodeID2) (*storagepb.SubtreeProto, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSubtree", arg0)
 	ret0, _ := ret[0].(*storagepb.SubtreeProto)
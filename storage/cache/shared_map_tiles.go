@@ -0,0 +1,178 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/tree"
+)
+
+// SharedMapTiles is a bounded LRU cache of map tiles read from storage, which
+// is shared between transactions, unlike the SubtreeCache of each of them. It
+// saves reading the tiles near the root of a map, which almost every read of
+// the map needs, from storage on every request.
+//
+// A map tile read at a revision is the latest version of the tile at or below
+// that revision, which never changes once the revision is committed, so tiles
+// are cached by the revision they were read at. Tiles must only be read
+// through the cache at committed revisions. Once a map is read at, or
+// advanced to, a newer revision, its tiles of older revisions are evicted,
+// and reads at older revisions bypass the cache.
+type SharedMapTiles struct {
+	size      int
+	hits      monitoring.Counter
+	misses    monitoring.Counter
+	evictions monitoring.Counter
+
+	mu      sync.Mutex
+	lru     *list.List // Of *sharedEntry, most recently used first.
+	entries map[sharedKey]*list.Element
+	// latest is the newest revision of each map seen by the cache.
+	latest map[int64]int64
+}
+
+// NewSharedMapTiles returns a SharedMapTiles cache holding up to size tiles,
+// which exports its hits, misses and evictions through metrics created by mf.
+// Only one cache should be created per metric factory.
+func NewSharedMapTiles(size int, mf monitoring.MetricFactory) *SharedMapTiles {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &SharedMapTiles{
+		size:      size,
+		hits:      mf.NewCounter("shared_map_tile_cache_hits", "Number of map tiles read from the shared map tile cache"),
+		misses:    mf.NewCounter("shared_map_tile_cache_misses", "Number of map tiles read from storage for lack of them in the shared map tile cache"),
+		evictions: mf.NewCounter("shared_map_tile_cache_evictions", "Number of map tiles evicted from the shared map tile cache, as it was full or their revision was superseded"),
+		lru:       list.New(),
+		entries:   make(map[sharedKey]*list.Element),
+		latest:    make(map[int64]int64),
+	}
+}
+
+// Wrap returns a GetSubtreesFunc which reads the tiles of the map with the
+// given ID at the given committed revision through the cache, and the tiles
+// which aren't cached with get.
+func (c *SharedMapTiles) Wrap(treeID, rev int64, get GetSubtreesFunc) GetSubtreesFunc {
+	return func(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
+		if !c.Advance(treeID, rev) {
+			return get(ids)
+		}
+		ret := make([]*storagepb.SubtreeProto, 0, len(ids))
+		var missing []tree.NodeID2
+		for _, id := range ids {
+			if st := c.get(sharedKey{treeID: treeID, rev: rev, prefix: tree.TileID{Root: id}.AsKey()}); st != nil {
+				ret = append(ret, st)
+			} else {
+				missing = append(missing, id)
+			}
+		}
+		c.hits.Add(float64(len(ret)))
+		if len(missing) == 0 {
+			return ret, nil
+		}
+		c.misses.Add(float64(len(missing)))
+		read, err := get(missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range read {
+			c.put(sharedKey{treeID: treeID, rev: rev, prefix: string(st.Prefix)}, st)
+		}
+		return append(ret, read...), nil
+	}
+}
+
+// Advance records that the map with the given ID has the given revision,
+// e.g. as it has just been written, evicting its tiles of older revisions if
+// it is newer than the revisions seen before. It returns whether the revision
+// is the newest one of the map, i.e. whether its tiles are cached.
+func (c *SharedMapTiles) Advance(treeID, rev int64) bool {
+	if c.size <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	latest, ok := c.latest[treeID]
+	switch {
+	case ok && rev < latest:
+		return false
+	case ok && rev == latest:
+		return true
+	}
+	c.latest[treeID] = rev
+	if !ok {
+		return true
+	}
+	for e := c.lru.Front(); e != nil; {
+		next := e.Next()
+		if key := e.Value.(*sharedEntry).key; key.treeID == treeID && key.rev < rev {
+			c.lru.Remove(e)
+			delete(c.entries, key)
+			c.evictions.Inc()
+		}
+		e = next
+	}
+	return true
+}
+
+// get returns a copy of the cached tile with the given key, or nil.
+func (c *SharedMapTiles) get(key sharedKey) *storagepb.SubtreeProto {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(e)
+	// The caller gets a copy, as transactions populate the tiles they read.
+	return proto.Clone(e.Value.(*sharedEntry).subtree).(*storagepb.SubtreeProto)
+}
+
+// put adds a copy of the tile to the cache under the given key, evicting the
+// least recently used tiles if it is full. Tiles of revisions superseded while
+// they were read aren't added.
+func (c *SharedMapTiles) put(key sharedKey, st *storagepb.SubtreeProto) {
+	entry := &sharedEntry{key: key, subtree: proto.Clone(st).(*storagepb.SubtreeProto)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.latest[key.treeID] != key.rev {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		e.Value = entry
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*sharedEntry).key)
+		c.evictions.Inc()
+	}
+}
+
+// Len returns the number of tiles in the cache.
+func (c *SharedMapTiles) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
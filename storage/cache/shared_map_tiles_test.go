@@ -0,0 +1,149 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/tree"
+)
+
+func TestSharedMapTiles(t *testing.T) {
+	c := NewSharedMapTiles(10, nil)
+	f := &fakeSubtreeStorage{subtrees: map[byte]*storagepb.SubtreeProto{
+		1: testSubtree(1, 256),
+		2: testSubtree(2, 10),
+	}}
+
+	for _, test := range []struct {
+		desc      string
+		treeID    int64
+		rev       int64
+		roots     []byte
+		want      int
+		wantReads int
+		wantLen   int
+	}{
+		{desc: "first-read", treeID: 1, rev: 5, roots: []byte{1, 2, 3}, want: 2, wantReads: 3, wantLen: 2},
+		{desc: "same-revision", treeID: 1, rev: 5, roots: []byte{1, 2}, want: 2, wantReads: 3, wantLen: 2},
+		// Missing tiles aren't cached.
+		{desc: "missing", treeID: 1, rev: 5, roots: []byte{3}, want: 0, wantReads: 4, wantLen: 2},
+		// Other maps don't share tiles.
+		{desc: "other-map", treeID: 2, rev: 5, roots: []byte{1}, want: 1, wantReads: 5, wantLen: 3},
+		// Even complete tiles are read again at newer revisions, which evict
+		// the tiles of older revisions of the map.
+		{desc: "newer-revision", treeID: 1, rev: 6, roots: []byte{1}, want: 1, wantReads: 6, wantLen: 2},
+		{desc: "newer-revision-cached", treeID: 1, rev: 6, roots: []byte{1}, want: 1, wantReads: 6, wantLen: 2},
+		// Older revisions bypass the cache.
+		{desc: "older-revision", treeID: 1, rev: 5, roots: []byte{1, 2}, want: 2, wantReads: 8, wantLen: 2},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := c.Wrap(test.treeID, test.rev, f.get)(rootIDs(test.roots...))
+			if err != nil {
+				t.Fatalf("GetSubtreesFunc: %v", err)
+			}
+			if len(got) != test.want {
+				t.Errorf("got %d tiles, want %d", len(got), test.want)
+			}
+			if f.reads != test.wantReads {
+				t.Errorf("read %d tiles from storage, want %d", f.reads, test.wantReads)
+			}
+			if got := c.Len(); got != test.wantLen {
+				t.Errorf("Len()=%d, want %d", got, test.wantLen)
+			}
+		})
+	}
+
+	// Updating the tiles returned doesn't update the cache.
+	get := c.Wrap(1, 6, f.get)
+	got, err := get(rootIDs(1))
+	if err != nil {
+		t.Fatalf("GetSubtreesFunc: %v", err)
+	}
+	got[0].Leaves["leaf0"] = []byte("changed")
+	if got, err = get(rootIDs(1)); err != nil {
+		t.Fatalf("GetSubtreesFunc: %v", err)
+	}
+	if !proto.Equal(got[0], f.subtrees[1]) {
+		t.Errorf("cached tile changed to %v", got[0])
+	}
+}
+
+func TestSharedMapTilesAdvance(t *testing.T) {
+	c := NewSharedMapTiles(10, nil)
+	f := &fakeSubtreeStorage{subtrees: map[byte]*storagepb.SubtreeProto{1: testSubtree(1, 10)}}
+	if _, err := c.Wrap(1, 1, f.get)(rootIDs(1)); err != nil {
+		t.Fatalf("GetSubtreesFunc: %v", err)
+	}
+	if got, want := c.Len(), 1; got != want {
+		t.Fatalf("Len()=%d, want %d", got, want)
+	}
+
+	// A tile read while its revision is superseded isn't cached.
+	get := c.Wrap(1, 2, func(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
+		if !c.Advance(1, 3) {
+			t.Error("Advance(3)=false, want true")
+		}
+		return f.get(ids)
+	})
+	if _, err := get(rootIDs(1)); err != nil {
+		t.Fatalf("GetSubtreesFunc: %v", err)
+	}
+	if got := c.Len(); got != 0 {
+		t.Errorf("Len()=%d after advancing, want 0", got)
+	}
+	if c.Advance(1, 2) {
+		t.Error("Advance(2)=true after advancing to 3, want false")
+	}
+}
+
+func TestSharedMapTilesEviction(t *testing.T) {
+	c := NewSharedMapTiles(2, nil)
+	f := &fakeSubtreeStorage{subtrees: map[byte]*storagepb.SubtreeProto{
+		1: testSubtree(1, 10),
+		2: testSubtree(2, 10),
+		3: testSubtree(3, 10),
+	}}
+	get := c.Wrap(1, 1, f.get)
+	for _, roots := range [][]byte{{1}, {2}, {1}, {3}} {
+		if _, err := get(rootIDs(roots...)); err != nil {
+			t.Fatalf("GetSubtreesFunc: %v", err)
+		}
+	}
+	// Tile 2 was the least recently used, so it has been evicted.
+	f.reads = 0
+	if _, err := get(rootIDs(1, 3, 2)); err != nil {
+		t.Fatalf("GetSubtreesFunc: %v", err)
+	}
+	if got, want := f.reads, 1; got != want {
+		t.Errorf("read %d tiles from storage, want %d", got, want)
+	}
+}
+
+func TestSharedMapTilesDisabled(t *testing.T) {
+	c := NewSharedMapTiles(0, nil)
+	f := &fakeSubtreeStorage{subtrees: map[byte]*storagepb.SubtreeProto{1: testSubtree(1, 10)}}
+	get := c.Wrap(1, 1, f.get)
+	for i := 0; i < 2; i++ {
+		if _, err := get(rootIDs(1)); err != nil {
+			t.Fatalf("GetSubtreesFunc: %v", err)
+		}
+	}
+	if got, want := f.reads, 2; got != want {
+		t.Errorf("read %d tiles from storage, want %d", got, want)
+	}
+}
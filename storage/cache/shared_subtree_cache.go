@@ -0,0 +1,157 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/tree"
+)
+
+// anyRevision is the revision of sharedKeys of complete subtrees, which are
+// valid at all revisions.
+const anyRevision = -1
+
+// sharedKey identifies a subtree in a SharedLogSubtrees cache.
+type sharedKey struct {
+	treeID int64
+	// rev is the revision the subtree was read at, or anyRevision.
+	rev    int64
+	prefix string
+}
+
+type sharedEntry struct {
+	key     sharedKey
+	subtree *storagepb.SubtreeProto
+}
+
+// SharedLogSubtrees is a bounded LRU cache of log subtrees read from storage,
+// which is shared between transactions, unlike SubtreeCache. It sits between
+// the SubtreeCache of each transaction and storage, so that e.g. a sequencing
+// pass doesn't read the same subtrees from storage as the previous one did.
+//
+// As logs are append-only, a complete log subtree, i.e. one with all of its
+// leaves set, never changes, so it is returned for reads at any revision. Other
+// subtrees are only returned for reads at the same revision they were read at.
+// The cache must not be used for maps, whose subtrees are never final.
+type SharedLogSubtrees struct {
+	size   int
+	hits   monitoring.Counter
+	misses monitoring.Counter
+
+	mu      sync.Mutex
+	lru     *list.List // Of *sharedEntry, most recently used first.
+	entries map[sharedKey]*list.Element
+}
+
+// NewSharedLogSubtrees returns a SharedLogSubtrees cache holding up to size
+// subtrees, which exports its hits and misses through metrics created by mf.
+// Only one cache should be created per metric factory.
+func NewSharedLogSubtrees(size int, mf monitoring.MetricFactory) *SharedLogSubtrees {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &SharedLogSubtrees{
+		size:    size,
+		hits:    mf.NewCounter("shared_subtree_cache_hits", "Number of log subtrees read from the shared subtree cache"),
+		misses:  mf.NewCounter("shared_subtree_cache_misses", "Number of log subtrees read from storage for lack of them in the shared subtree cache"),
+		lru:     list.New(),
+		entries: make(map[sharedKey]*list.Element),
+	}
+}
+
+// Wrap returns a GetSubtreesFunc which reads the subtrees of the log with the
+// given ID through the cache, and the subtrees which aren't cached with get.
+// The subtrees returned by get must be as of the given revision.
+func (c *SharedLogSubtrees) Wrap(treeID, rev int64, get GetSubtreesFunc) GetSubtreesFunc {
+	return func(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
+		ret := make([]*storagepb.SubtreeProto, 0, len(ids))
+		var missing []tree.NodeID2
+		for _, id := range ids {
+			prefix := tree.TileID{Root: id}.AsKey()
+			if st := c.get(treeID, rev, prefix); st != nil {
+				ret = append(ret, st)
+			} else {
+				missing = append(missing, id)
+			}
+		}
+		c.hits.Add(float64(len(ret)))
+		if len(missing) == 0 {
+			return ret, nil
+		}
+		c.misses.Add(float64(len(missing)))
+		read, err := get(missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range read {
+			c.put(treeID, rev, st)
+		}
+		return append(ret, read...), nil
+	}
+}
+
+// get returns a copy of the cached subtree with the given prefix, or nil.
+func (c *SharedLogSubtrees) get(treeID, rev int64, prefix string) *storagepb.SubtreeProto {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, r := range []int64{anyRevision, rev} {
+		if e, ok := c.entries[sharedKey{treeID: treeID, rev: r, prefix: prefix}]; ok {
+			c.lru.MoveToFront(e)
+			// The caller gets a copy, as transactions populate and update the
+			// subtrees they read.
+			return proto.Clone(e.Value.(*sharedEntry).subtree).(*storagepb.SubtreeProto)
+		}
+	}
+	return nil
+}
+
+// put adds a copy of the subtree read at the given revision to the cache,
+// evicting the least recently used subtrees if it is full.
+func (c *SharedLogSubtrees) put(treeID, rev int64, st *storagepb.SubtreeProto) {
+	if c.size <= 0 {
+		return
+	}
+	key := sharedKey{treeID: treeID, rev: rev, prefix: string(st.Prefix)}
+	if st.Depth > 0 && len(st.Leaves) == 1<<uint(st.Depth) {
+		key.rev = anyRevision
+	}
+	entry := &sharedEntry{key: key, subtree: proto.Clone(st).(*storagepb.SubtreeProto)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value = entry
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*sharedEntry).key)
+	}
+}
+
+// Len returns the number of subtrees in the cache.
+func (c *SharedLogSubtrees) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
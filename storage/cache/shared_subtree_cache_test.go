@@ -0,0 +1,184 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/tree"
+)
+
+// testSubtree returns a log subtree rooted at the given byte, with the given
+// number of leaves.
+func testSubtree(root byte, leaves int) *storagepb.SubtreeProto {
+	st := &storagepb.SubtreeProto{
+		Prefix: []byte{root},
+		Depth:  8,
+		Leaves: make(map[string][]byte),
+	}
+	for i := 0; i < leaves; i++ {
+		st.Leaves[fmt.Sprintf("leaf%d", i)] = []byte{byte(i)}
+	}
+	return st
+}
+
+// fakeSubtreeStorage serves subtrees from a map by root byte, and counts the
+// subtrees read.
+type fakeSubtreeStorage struct {
+	subtrees map[byte]*storagepb.SubtreeProto
+	reads    int
+}
+
+func (f *fakeSubtreeStorage) get(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
+	var ret []*storagepb.SubtreeProto
+	for _, id := range ids {
+		f.reads++
+		if st, ok := f.subtrees[lastByte(id)]; ok {
+			ret = append(ret, proto.Clone(st).(*storagepb.SubtreeProto))
+		}
+	}
+	return ret, nil
+}
+
+func rootIDs(roots ...byte) []tree.NodeID2 {
+	ids := make([]tree.NodeID2, 0, len(roots))
+	for _, r := range roots {
+		ids = append(ids, tree.NewNodeID2(string([]byte{r}), 8))
+	}
+	return ids
+}
+
+func TestSharedLogSubtrees(t *testing.T) {
+	c := NewSharedLogSubtrees(10, monitoring.InertMetricFactory{})
+	f := &fakeSubtreeStorage{subtrees: map[byte]*storagepb.SubtreeProto{
+		1: testSubtree(1, 256), // Complete.
+		2: testSubtree(2, 10),
+	}}
+
+	for _, test := range []struct {
+		desc      string
+		rev       int64
+		ids       []tree.NodeID2
+		want      int
+		wantReads int
+	}{
+		{desc: "first-read", rev: 5, ids: rootIDs(1, 2, 3), want: 2, wantReads: 3},
+		{desc: "same-revision", rev: 5, ids: rootIDs(1, 2), want: 2, wantReads: 3},
+		// Only the complete subtree is valid at other revisions.
+		{desc: "other-revision", rev: 6, ids: rootIDs(1, 2), want: 2, wantReads: 4},
+		// Missing subtrees aren't cached.
+		{desc: "missing", rev: 5, ids: rootIDs(3), want: 0, wantReads: 5},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := c.Wrap(1, test.rev, f.get)(test.ids)
+			if err != nil {
+				t.Fatalf("GetSubtreesFunc: %v", err)
+			}
+			if len(got) != test.want {
+				t.Errorf("got %d subtrees, want %d", len(got), test.want)
+			}
+			if f.reads != test.wantReads {
+				t.Errorf("read %d subtrees from storage, want %d", f.reads, test.wantReads)
+			}
+		})
+	}
+
+	// Other trees don't share subtrees.
+	if _, err := c.Wrap(2, 5, f.get)(rootIDs(1)); err != nil {
+		t.Fatalf("GetSubtreesFunc: %v", err)
+	}
+	if got, want := f.reads, 6; got != want {
+		t.Errorf("read %d subtrees from storage for another tree, want %d", got, want)
+	}
+
+	// Updating the subtrees returned doesn't update the cache.
+	got, err := c.Wrap(1, 5, f.get)(rootIDs(1))
+	if err != nil {
+		t.Fatalf("GetSubtreesFunc: %v", err)
+	}
+	got[0].Leaves["leaf0"] = []byte("changed")
+	got, err = c.Wrap(1, 5, f.get)(rootIDs(1))
+	if err != nil {
+		t.Fatalf("GetSubtreesFunc: %v", err)
+	}
+	if !proto.Equal(got[0], f.subtrees[1]) {
+		t.Errorf("cached subtree changed to %v", got[0])
+	}
+}
+
+func TestSharedLogSubtreesEviction(t *testing.T) {
+	c := NewSharedLogSubtrees(2, nil)
+	f := &fakeSubtreeStorage{subtrees: map[byte]*storagepb.SubtreeProto{
+		1: testSubtree(1, 256),
+		2: testSubtree(2, 256),
+		3: testSubtree(3, 256),
+	}}
+	get := c.Wrap(1, 1, f.get)
+	for _, roots := range [][]byte{{1}, {2}, {1}, {3}} {
+		if _, err := get(rootIDs(roots...)); err != nil {
+			t.Fatalf("GetSubtreesFunc: %v", err)
+		}
+	}
+	if got, want := c.Len(), 2; got != want {
+		t.Errorf("Len()=%d, want %d", got, want)
+	}
+	// Subtree 2 was the least recently used, so it has been evicted.
+	f.reads = 0
+	if _, err := get(rootIDs(1, 3)); err != nil {
+		t.Fatalf("GetSubtreesFunc: %v", err)
+	}
+	if _, err := get(rootIDs(2)); err != nil {
+		t.Fatalf("GetSubtreesFunc: %v", err)
+	}
+	if got, want := f.reads, 1; got != want {
+		t.Errorf("read %d subtrees from storage, want %d", got, want)
+	}
+}
+
+func TestSharedLogSubtreesReadError(t *testing.T) {
+	c := NewSharedLogSubtrees(2, nil)
+	want := errors.New("read failed")
+	get := c.Wrap(1, 1, func([]tree.NodeID2) ([]*storagepb.SubtreeProto, error) { return nil, want })
+	if _, err := get(rootIDs(1)); err != want {
+		t.Errorf("GetSubtreesFunc: %v, want %v", err, want)
+	}
+	if got := c.Len(); got != 0 {
+		t.Errorf("Len()=%d, want 0", got)
+	}
+}
+
+func TestSharedLogSubtreesDisabled(t *testing.T) {
+	c := NewSharedLogSubtrees(0, nil)
+	f := &fakeSubtreeStorage{subtrees: map[byte]*storagepb.SubtreeProto{1: testSubtree(1, 256)}}
+	get := c.Wrap(1, 1, f.get)
+	for i := 0; i < 2; i++ {
+		if _, err := get(rootIDs(1)); err != nil {
+			t.Fatalf("GetSubtreesFunc: %v", err)
+		}
+	}
+	if got, want := f.reads, 2; got != want {
+		t.Errorf("read %d subtrees from storage, want %d", got, want)
+	}
+}
+
+func lastByte(id tree.NodeID2) byte {
+	last, _ := id.LastByte()
+	return last
+}
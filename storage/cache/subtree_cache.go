@@ -34,11 +34,13 @@ var populateConcurrency = flag.Int("populate_subtree_concurrency", 256, "Max num
 
 // TODO(pavelkalinnikov): Rename subtrees to tiles.
 
-// GetSubtreeFunc describes a function which can return a Subtree from storage.
-type GetSubtreeFunc func(id tree.NodeID) (*storagepb.SubtreeProto, error)
+// GetSubtreeFunc describes a function which can return a Subtree from storage,
+// given the ID of its root.
+type GetSubtreeFunc func(id tree.NodeID2) (*storagepb.SubtreeProto, error)
 
-// GetSubtreesFunc describes a function which can return a number of Subtrees from storage.
-type GetSubtreesFunc func(ids []tree.NodeID) ([]*storagepb.SubtreeProto, error)
+// GetSubtreesFunc describes a function which can return a number of Subtrees
+// from storage, given the IDs of their roots.
+type GetSubtreesFunc func(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error)
 
 // SetSubtreesFunc describes a function which can store a collection of Subtrees into storage.
 type SetSubtreesFunc func(ctx context.Context, s []*storagepb.SubtreeProto) error
@@ -128,8 +130,7 @@ func (s *SubtreeCache) preload(ids []tree.NodeID, getSubtrees GetSubtreesFunc) e
 		return nil
 	}
 
-	// TODO(pavelkalinnikov): Change the getters to accept []tree.TileID.
-	list := make([]tree.NodeID, 0, len(want))
+	list := make([]tree.NodeID2, 0, len(want))
 	for _, v := range want {
 		list = append(list, v.Root)
 	}
@@ -218,11 +219,11 @@ func (s *SubtreeCache) GetNodes(ids []tree.NodeID, getSubtrees GetSubtreesFunc)
 	for _, id := range ids {
 		h, err := s.getNodeHash(
 			id,
-			func(n tree.NodeID) (*storagepb.SubtreeProto, error) {
+			func(n tree.NodeID2) (*storagepb.SubtreeProto, error) {
 				// This should never happen - we should've already read all the data we
 				// need above, in Preload()
 				glog.Warningf("Unexpectedly reading from within getNodeHash(): %s", n.String())
-				ret, err := getSubtrees([]tree.NodeID{n})
+				ret, err := getSubtrees([]tree.NodeID2{n})
 				if err != nil || len(ret) == 0 {
 					return nil, err
 				}
@@ -434,7 +435,7 @@ func (s *SubtreeCache) Flush(ctx context.Context, setSubtrees SetSubtreesFunc) e
 
 // newEmptySubtree creates an empty subtree for the passed-in ID.
 func (s *SubtreeCache) newEmptySubtree(id tree.TileID) *storagepb.SubtreeProto {
-	height := s.layout.TileHeight(id.Root.PrefixLenBits)
+	height := s.layout.TileHeight(int(id.Root.BitLen()))
 	if glog.V(2) {
 		glog.Infof("Creating new empty subtree for %x, with height %d", id.AsBytes(), height)
 	}
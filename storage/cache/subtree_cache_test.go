@@ -53,7 +53,7 @@ func TestCacheFillOnlyReadsSubtrees(t *testing.T) {
 	for b := 0; b < nodeID.PrefixLenBits; b += defaultLogStrata[si] {
 		e := nodeID
 		e.PrefixLenBits = b
-		m.EXPECT().GetSubtree(stestonly.NodeIDEq(e)).Return(&storagepb.SubtreeProto{
+		m.EXPECT().GetSubtree(e.ToNodeID2()).Return(&storagepb.SubtreeProto{
 			Prefix: e.Path,
 		}, nil)
 		si++
@@ -97,7 +97,7 @@ func TestCacheGetNodesReadsSubtrees(t *testing.T) {
 		// strata that'll be everything except the last byte), so modify the prefix
 		// length here accoringly:
 		nodeID.PrefixLenBits -= 8
-		m.EXPECT().GetSubtree(stestonly.NodeIDEq(nodeID)).Return(&storagepb.SubtreeProto{
+		m.EXPECT().GetSubtree(nodeID.ToNodeID2()).Return(&storagepb.SubtreeProto{
 			Prefix: nodeID.Path[:len(nodeID.Path)-1],
 		}, nil)
 	}
@@ -107,7 +107,7 @@ func TestCacheGetNodesReadsSubtrees(t *testing.T) {
 		nodeIDs,
 		// Glue function to convert a call requesting multiple subtrees into a
 		// sequence of calls to our mock storage:
-		func(ids []tree.NodeID) ([]*storagepb.SubtreeProto, error) {
+		func(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
 			ret := make([]*storagepb.SubtreeProto, 0)
 			for _, i := range ids {
 				r, err := m.GetSubtree(i)
@@ -125,7 +125,7 @@ func TestCacheGetNodesReadsSubtrees(t *testing.T) {
 	}
 }
 
-func noFetch(_ tree.NodeID) (*storagepb.SubtreeProto, error) {
+func noFetch(_ tree.NodeID2) (*storagepb.SubtreeProto, error) {
 	return nil, errors.New("not supposed to read anything")
 }
 
@@ -148,7 +148,7 @@ func TestCacheFlush(t *testing.T) {
 		e := nodeID
 		e.PrefixLenBits = b
 		expectedSetIDs[e.String()] = "expected"
-		m.EXPECT().GetSubtree(stestonly.NodeIDEq(e)).Do(func(n tree.NodeID) {
+		m.EXPECT().GetSubtree(e.ToNodeID2()).Do(func(n tree.NodeID2) {
 			t.Logf("read %v", n)
 		}).Return((*storagepb.SubtreeProto)(nil), nil)
 	}
@@ -317,7 +317,7 @@ func TestIdempotentWrites(t *testing.T) {
 	expectedSetIDs[subtreeID.String()] = "expected"
 
 	// The first time we read the subtree we'll emulate an empty subtree:
-	m.EXPECT().GetSubtree(stestonly.NodeIDEq(subtreeID)).Do(func(n tree.NodeID) {
+	m.EXPECT().GetSubtree(subtreeID.ToNodeID2()).Do(func(n tree.NodeID2) {
 		t.Logf("read %v", n.String())
 	}).Return((*storagepb.SubtreeProto)(nil), nil)
 
@@ -340,7 +340,7 @@ func TestIdempotentWrites(t *testing.T) {
 
 			// After this write completes, subsequent reads will see the subtree
 			// being written now:
-			m.EXPECT().GetSubtree(stestonly.NodeIDEq(subID)).AnyTimes().Do(func(n tree.NodeID) {
+			m.EXPECT().GetSubtree(subID.ToNodeID2()).AnyTimes().Do(func(n tree.NodeID2) {
 				t.Logf("read again %v", n.String())
 			}).Return(s, nil)
 
@@ -134,7 +134,8 @@ func reverseSignatureAlgMap(m map[sigpb.DigitallySigned_SignatureAlgorithm]spann
 
 // adminTX implements both storage.ReadOnlyAdminTX and storage.AdminTX.
 type adminTX struct {
-	client *spanner.Client
+	client  *spanner.Client
+	dialect Dialect
 
 	// tx is either spanner.ReadOnlyTransaction or spanner.ReadWriteTransaction,
 	// according to the role adminTX is meant to fill.
@@ -153,12 +154,19 @@ type adminTX struct {
 
 // adminStorage implements storage.AdminStorage.
 type adminStorage struct {
-	client *spanner.Client
+	client  *spanner.Client
+	dialect Dialect
 }
 
 // NewAdminStorage returns a Spanner-based storage.AdminStorage implementation.
 func NewAdminStorage(client *spanner.Client) storage.AdminStorage {
-	return &adminStorage{client}
+	return NewAdminStorageWithDialect(client, GoogleSQL)
+}
+
+// NewAdminStorageWithDialect returns a Spanner-based storage.AdminStorage
+// implementation for a database of the given dialect.
+func NewAdminStorageWithDialect(client *spanner.Client, dialect Dialect) storage.AdminStorage {
+	return &adminStorage{client: client, dialect: dialect}
 }
 
 // CheckDatabaseAccessible implements AdminStorage.CheckDatabaseAccessible.
@@ -169,7 +177,7 @@ func (s *adminStorage) CheckDatabaseAccessible(ctx context.Context) error {
 // Snapshot implements AdminStorage.Snapshot.
 func (s *adminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
 	tx := s.client.ReadOnlyTransaction()
-	return &adminTX{client: s.client, tx: tx}, nil
+	return &adminTX{client: s.client, tx: tx, dialect: s.dialect}, nil
 }
 
 // Begin implements AdminStorage.Begin.
@@ -180,7 +188,7 @@ func (s *adminStorage) Begin(ctx context.Context) (storage.AdminTX, error) {
 // ReadWriteTransaction implements AdminStorage.ReadWriteTransaction.
 func (s *adminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
 	_, err := s.client.ReadWriteTransaction(ctx, func(ctx context.Context, stx *spanner.ReadWriteTransaction) error {
-		tx := &adminTX{client: s.client, tx: stx}
+		tx := &adminTX{client: s.client, tx: stx, dialect: s.dialect}
 		return f(ctx, tx)
 	})
 	return err
@@ -231,6 +239,12 @@ func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, er
 	return toTrillianTree(info)
 }
 
+// GetTreeByCreateRequestID implements storage.CreateRequestIDReader. Trees
+// are never created with a request ID in CloudSpanner storage.
+func (t *adminTX) GetTreeByCreateRequestID(ctx context.Context, requestID string) (*trillian.Tree, error) {
+	return nil, status.Errorf(codes.NotFound, "no tree was created with request ID %q", requestID)
+}
+
 func (t *adminTX) getTreeInfo(ctx context.Context, treeID int64) (*spannerpb.TreeInfo, error) {
 	cols := []string{
 		"TreeID",
@@ -345,7 +359,7 @@ func (t *adminTX) readTrees(ctx context.Context, includeDeleted, idOnly bool, f
 		stmt.SQL += " WHERE t.Deleted = @deleted"
 		stmt.Params["deleted"] = false
 	}
-	rows := t.tx.Query(ctx, stmt)
+	rows := t.tx.Query(ctx, t.dialect.statement(stmt))
 	return rows.Do(f)
 }
 
@@ -355,7 +369,7 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		return nil, err
 	}
 
-	id, err := storage.NewTreeID()
+	id, err := storage.TreeIDForCreation(tree)
 	if err != nil {
 		return nil, err
 	}
@@ -409,6 +423,9 @@ func newTreeInfo(tree *trillian.Tree, treeID int64, now time.Time) (*spannerpb.T
 		return nil, status.Errorf(codes.Internal, "unexpected TreeType: %s", tree.TreeType)
 	}
 
+	if tree.HashStrategy == trillian.HashStrategy_CUSTOM_MAP_HASHER {
+		return nil, status.Errorf(codes.InvalidArgument, "%s is not supported by CloudSpanner storage", tree.HashStrategy)
+	}
 	hs, ok := hashStrategyMap[tree.HashStrategy]
 	if !ok {
 		return nil, status.Errorf(codes.Internal, "unexpected HashStrategy: %s", tree.HashStrategy)
@@ -429,6 +446,34 @@ func newTreeInfo(tree *trillian.Tree, treeID int64, now time.Time) (*spannerpb.T
 		return nil, status.Errorf(codes.InvalidArgument, "malformed MaxRootDuration: %v", err)
 	}
 
+	if tree.RateLimits != nil {
+		return nil, status.Error(codes.InvalidArgument, "rate_limits are not supported by CloudSpanner storage")
+	}
+	if len(tree.Labels) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "labels are not supported by CloudSpanner storage")
+	}
+	if tree.Maintenance != nil {
+		return nil, status.Error(codes.InvalidArgument, "maintenance is not supported by CloudSpanner storage")
+	}
+	if len(tree.MapStrata) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "map_strata are not supported by CloudSpanner storage")
+	}
+	if tree.MapCompression != trillian.MapCompression_NO_MAP_COMPRESSION {
+		return nil, status.Error(codes.InvalidArgument, "map_compression is not supported by CloudSpanner storage")
+	}
+	if tree.AutoInit {
+		return nil, status.Error(codes.InvalidArgument, "auto_init is not supported by CloudSpanner storage")
+	}
+	if tree.MaxStorageBytes != 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_storage_bytes is not supported by CloudSpanner storage")
+	}
+	if tree.MapKeyIndex {
+		return nil, status.Error(codes.InvalidArgument, "map_key_index is not supported by CloudSpanner storage")
+	}
+	if tree.CreateRequestId != "" {
+		return nil, status.Error(codes.InvalidArgument, "create_request_id is not supported by CloudSpanner storage")
+	}
+
 	info := &spannerpb.TreeInfo{
 		TreeId:                treeID,
 		Name:                  tree.DisplayName,
@@ -523,6 +568,27 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	if !proto.Equal(beforeTree.StorageSettings, tree.StorageSettings) {
 		return nil, status.New(codes.InvalidArgument, "readonly field changed: storage_settings").Err()
 	}
+	if tree.RateLimits != nil {
+		return nil, status.Error(codes.InvalidArgument, "rate_limits are not supported by CloudSpanner storage")
+	}
+	if len(tree.Labels) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "labels are not supported by CloudSpanner storage")
+	}
+	if tree.Maintenance != nil {
+		return nil, status.Error(codes.InvalidArgument, "maintenance is not supported by CloudSpanner storage")
+	}
+	if tree.MapCompression != trillian.MapCompression_NO_MAP_COMPRESSION {
+		return nil, status.Error(codes.InvalidArgument, "map_compression is not supported by CloudSpanner storage")
+	}
+	if tree.AutoInit {
+		return nil, status.Error(codes.InvalidArgument, "auto_init is not supported by CloudSpanner storage")
+	}
+	if tree.MaxStorageBytes != 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_storage_bytes is not supported by CloudSpanner storage")
+	}
+	if len(tree.RetiredPublicKeys) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "key rotation is not supported by CloudSpanner storage")
+	}
 
 	ts, ok := treeStateMap[tree.TreeState]
 	if !ok {
@@ -626,6 +692,7 @@ func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
 		spanner.Delete("LeafData", spanner.Key{info.TreeId}.AsPrefix()),
 		spanner.Delete("SequencedLeafData", spanner.Key{info.TreeId}.AsPrefix()),
 		spanner.Delete("Unsequenced", spanner.Key{info.TreeId}.AsPrefix()),
+		spanner.Delete("DequeueLeases", spanner.Key{info.TreeId}),
 		spanner.Delete("MapLeafData", spanner.Key{info.TreeId}.AsPrefix()),
 	})
 }
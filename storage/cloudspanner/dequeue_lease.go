@@ -0,0 +1,131 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudspanner
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	dequeueLeaseTbl = "DequeueLeases"
+
+	// defaultDequeueLeaseDuration is used when LogStorageOptions doesn't
+	// specify a DequeueLeaseDuration.
+	defaultDequeueLeaseDuration = 10 * time.Second
+)
+
+// dequeueLeaseCols is the row layout of the DequeueLeases table.
+//
+// Each LOG tree has at most one row, which records who last claimed the
+// tree's Unsequenced queue, until when, and a fencing token which is
+// incremented by every claim.
+type dequeueLeaseCols struct {
+	TreeID       int64
+	FencingToken int64
+	Holder       string
+	ExpiryNanos  int64
+}
+
+// dequeueLease records the claim a logTX made over the Unsequenced queue of
+// its tree.
+//
+// The claim is buffered in the logTX, and only takes effect if it commits.
+// Every claim reads and writes the lease row, so Spanner's serializability
+// keeps two passes which overlap from both committing: one of them is aborted
+// and retried, and then sees the claim of the other. Nor can they both commit
+// having dequeued the same leaves, whose queue entries each of them reads and
+// deletes, so no further fencing is needed.
+type dequeueLease struct {
+	// token is the fencing token written by the claim.
+	token int64
+}
+
+// newLeaseHolder returns a random identifier for a logStorage instance.
+func newLeaseHolder() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// This can only happen if the system's entropy source is broken.
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// readDequeueLease returns the current lease row for the tree, or nil if the
+// queue has never been claimed.
+func (tx *logTX) readDequeueLease(ctx context.Context) (*dequeueLeaseCols, error) {
+	row, err := tx.stx.ReadRow(ctx, dequeueLeaseTbl, spanner.Key{tx.treeID},
+		[]string{"TreeID", "FencingToken", "Holder", "ExpiryNanos"})
+	if spanner.ErrCode(err) == codes.NotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var lease dequeueLeaseCols
+	if err := row.ToStruct(&lease); err != nil {
+		return nil, err
+	}
+	return &lease, nil
+}
+
+// claimDequeueLease claims the Unsequenced queue of the tree for the
+// lifetime of this transaction.
+//
+// A lease held by a different logStorage instance blocks the claim until it
+// expires, so that a signer which has just lost mastership and a signer
+// which has just gained it can't both be dequeuing at the same time.
+func (tx *logTX) claimDequeueLease(ctx context.Context) error {
+	if tx.lease != nil {
+		return nil
+	}
+	stx, ok := tx.stx.(*spanner.ReadWriteTransaction)
+	if !ok {
+		return ErrWrongTXType
+	}
+
+	cur, err := tx.readDequeueLease(ctx)
+	if err != nil {
+		return err
+	}
+	now := tx.ls.opts.TimeSource.Now()
+	var token int64
+	if cur != nil {
+		if cur.Holder != tx.ls.opts.LeaseHolder && now.UnixNano() < cur.ExpiryNanos {
+			return status.Errorf(codes.Unavailable, "dequeue lease for tree %d is held by %s until %v", tx.treeID, cur.Holder, time.Unix(0, cur.ExpiryNanos))
+		}
+		token = cur.FencingToken
+	}
+
+	m, err := spanner.InsertOrUpdateStruct(dequeueLeaseTbl, dequeueLeaseCols{
+		TreeID:       tx.treeID,
+		FencingToken: token + 1,
+		Holder:       tx.ls.opts.LeaseHolder,
+		ExpiryNanos:  now.Add(tx.ls.opts.DequeueLeaseDuration).UnixNano(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := stx.BufferWrite([]*spanner.Mutation{m}); err != nil {
+		return err
+	}
+	tx.lease = &dequeueLease{token: token + 1}
+	return nil
+}
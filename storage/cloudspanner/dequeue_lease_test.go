@@ -0,0 +1,288 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudspanner
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cloudspanner/spannerpb"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	storageto "github.com/google/trillian/storage/testonly"
+)
+
+const numLeaseTestLeaves = 5
+
+// setUpLeaseTest creates a log with queued leaves, laid out so that a single
+// DequeueLeaves call sees the whole queue.
+func setUpLeaseTest(ctx context.Context, t *testing.T) (*spanner.Client, *trillian.Tree) {
+	t.Helper()
+	db := GetTestDB(ctx, t)
+	t.Cleanup(func() { cleanTestDB(ctx, t, db) })
+
+	settings, err := ptypes.MarshalAny(&spannerpb.LogStorageConfig{NumUnseqBuckets: 1, NumMerkleBuckets: 256})
+	if err != nil {
+		t.Fatalf("MarshalAny(): %v", err)
+	}
+	tree := proto.Clone(storageto.LogTree).(*trillian.Tree)
+	tree.StorageSettings = settings
+	tree, err = storage.CreateTree(ctx, NewAdminStorage(db), tree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+
+	ls := newLeaseTestStorage(db, "setup", 0, clock.System)
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return storeLeaseTestRoot(ctx, tx.(*logTX), 0)
+	}); err != nil {
+		t.Fatalf("Failed to store initial root: %v", err)
+	}
+
+	leaves := make([]*trillian.LogLeaf, 0, numLeaseTestLeaves)
+	for i := 0; i < numLeaseTestLeaves; i++ {
+		h := sha256.Sum256([]byte(fmt.Sprintf("leaf %d", i)))
+		leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: h[:], MerkleLeafHash: h[:], LeafValue: h[:]})
+	}
+	if _, err := ls.QueueLeaves(ctx, tree, leaves, time.Now()); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	return db, tree
+}
+
+func newLeaseTestStorage(db *spanner.Client, holder string, leaseDuration time.Duration, ts clock.TimeSource) storage.LogStorage {
+	return NewLogStorageWithOpts(db, LogStorageOptions{
+		DequeueAcrossMerkleBuckets:              true,
+		DequeueAcrossMerkleBucketsRangeFraction: 1.0,
+		DequeueLeaseDuration:                    leaseDuration,
+		LeaseHolder:                             holder,
+		TimeSource:                              ts,
+	})
+}
+
+func storeLeaseTestRoot(ctx context.Context, tx *logTX, treeSize int64) error {
+	writeRev, err := tx.writeRev(ctx)
+	if err == storage.ErrTreeNeedsInit {
+		writeRev = 0
+	} else if err != nil {
+		return err
+	}
+	logRoot, err := (&types.LogRootV1{
+		TreeSize:       uint64(treeSize),
+		RootHash:       make([]byte, sha256.Size),
+		TimestampNanos: uint64(time.Now().UnixNano()),
+		Revision:       uint64(writeRev),
+	}).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: logRoot, LogRootSignature: []byte("sig")})
+}
+
+// integratePass runs a single signer pass which dequeues up to limit leaves,
+// sequences them and stores a new root.
+func integratePass(ctx context.Context, ls storage.LogStorage, tree *trillian.Tree, limit int) (int, error) {
+	var n int
+	err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		ltx := tx.(*logTX)
+		size, err := ltx.GetSequencedLeafCount(ctx)
+		if err != nil {
+			return err
+		}
+		leaves, err := ltx.DequeueLeaves(ctx, limit, time.Now())
+		if err != nil {
+			return err
+		}
+		for i, l := range leaves {
+			l.LeafIndex = size + int64(i)
+			l.IntegrateTimestamp = ptypes.TimestampNow()
+		}
+		if err := ltx.UpdateSequencedLeaves(ctx, leaves); err != nil {
+			return err
+		}
+		n = len(leaves)
+		return storeLeaseTestRoot(ctx, ltx, size+int64(n))
+	})
+	return n, err
+}
+
+func readLeaseToken(ctx context.Context, t *testing.T, db *spanner.Client, treeID int64) int64 {
+	t.Helper()
+	row, err := db.Single().ReadRow(ctx, dequeueLeaseTbl, spanner.Key{treeID}, []string{"FencingToken"})
+	if spanner.ErrCode(err) == codes.NotFound {
+		return 0
+	} else if err != nil {
+		t.Fatalf("ReadRow(%s): %v", dequeueLeaseTbl, err)
+	}
+	var token int64
+	if err := row.Columns(&token); err != nil {
+		t.Fatalf("Columns(): %v", err)
+	}
+	return token
+}
+
+// checkIntegratedOnce verifies that every queued leaf has been integrated
+// exactly once, and that none remain in the queue.
+func checkIntegratedOnce(ctx context.Context, t *testing.T, db *spanner.Client, treeID int64) {
+	t.Helper()
+	tx := db.ReadOnlyTransaction()
+	defer tx.Close()
+
+	seen := make(map[string]bool)
+	if err := tx.Read(ctx, seqDataTbl, spanner.Key{treeID}.AsPrefix(), []string{colLeafIdentityHash}).Do(func(r *spanner.Row) error {
+		var id []byte
+		if err := r.Columns(&id); err != nil {
+			return err
+		}
+		if seen[string(id)] {
+			t.Errorf("Leaf %x integrated more than once", id)
+		}
+		seen[string(id)] = true
+		return nil
+	}); err != nil {
+		t.Fatalf("Read(%s): %v", seqDataTbl, err)
+	}
+	if got, want := len(seen), numLeaseTestLeaves; got != want {
+		t.Errorf("Integrated %d distinct leaves, want %d", got, want)
+	}
+
+	queued := 0
+	if err := tx.Read(ctx, unseqTable, spanner.Key{treeID}.AsPrefix(), []string{colLeafIdentityHash}).Do(func(*spanner.Row) error {
+		queued++
+		return nil
+	}); err != nil {
+		t.Fatalf("Read(%s): %v", unseqTable, err)
+	}
+	if queued != 0 {
+		t.Errorf("%d leaves still queued, want 0", queued)
+	}
+}
+
+func TestDequeueLeaseExcludesOtherHolders(t *testing.T) {
+	ctx := context.Background()
+	db, tree := setUpLeaseTest(ctx, t)
+	old := newLeaseTestStorage(db, "old-master", time.Hour, clock.System)
+	next := newLeaseTestStorage(db, "new-master", time.Hour, clock.System)
+
+	if n, err := integratePass(ctx, old, tree, 2); err != nil || n != 2 {
+		t.Fatalf("integratePass(old) = %d, %v, want 2, nil", n, err)
+	}
+	if got, want := readLeaseToken(ctx, t, db, tree.TreeId), int64(1); got != want {
+		t.Errorf("FencingToken = %d, want %d", got, want)
+	}
+
+	// The new master mustn't dequeue while the old master's lease is live.
+	if _, err := integratePass(ctx, next, tree, numLeaseTestLeaves); status.Code(err) != codes.Unavailable {
+		t.Errorf("integratePass(next) = %v, want code %v", err, codes.Unavailable)
+	}
+	if got, want := readLeaseToken(ctx, t, db, tree.TreeId), int64(1); got != want {
+		t.Errorf("FencingToken after refused claim = %d, want %d", got, want)
+	}
+
+	// The lease holder can carry on.
+	if n, err := integratePass(ctx, old, tree, numLeaseTestLeaves); err != nil || n != numLeaseTestLeaves-2 {
+		t.Fatalf("integratePass(old) = %d, %v, want %d, nil", n, err, numLeaseTestLeaves-2)
+	}
+	checkIntegratedOnce(ctx, t, db, tree.TreeId)
+}
+
+func TestDequeueLeaseExpiry(t *testing.T) {
+	ctx := context.Background()
+	db, tree := setUpLeaseTest(ctx, t)
+	ts := clock.NewFake(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	old := newLeaseTestStorage(db, "old-master", time.Minute, ts)
+	next := newLeaseTestStorage(db, "new-master", time.Minute, ts)
+
+	if n, err := integratePass(ctx, old, tree, 2); err != nil || n != 2 {
+		t.Fatalf("integratePass(old) = %d, %v, want 2, nil", n, err)
+	}
+	// The old master's lease is live until the end of its minute.
+	ts.Set(ts.Now().Add(time.Minute - time.Nanosecond))
+	if _, err := integratePass(ctx, next, tree, numLeaseTestLeaves); status.Code(err) != codes.Unavailable {
+		t.Errorf("integratePass(next) before expiry = %v, want code %v", err, codes.Unavailable)
+	}
+
+	// Once the old master's lease has expired, the new master takes over and
+	// excludes the old one in turn.
+	ts.Set(ts.Now().Add(time.Nanosecond))
+	if n, err := integratePass(ctx, next, tree, numLeaseTestLeaves); err != nil || n != numLeaseTestLeaves-2 {
+		t.Fatalf("integratePass(next) = %d, %v, want %d, nil", n, err, numLeaseTestLeaves-2)
+	}
+	if got, want := readLeaseToken(ctx, t, db, tree.TreeId), int64(2); got != want {
+		t.Errorf("FencingToken = %d, want %d", got, want)
+	}
+	if _, err := integratePass(ctx, old, tree, numLeaseTestLeaves); status.Code(err) != codes.Unavailable {
+		t.Errorf("integratePass(old) = %v, want code %v", err, codes.Unavailable)
+	}
+	checkIntegratedOnce(ctx, t, db, tree.TreeId)
+}
+
+func TestDequeueLeaseClaimedOncePerPass(t *testing.T) {
+	ctx := context.Background()
+	db, tree := setUpLeaseTest(ctx, t)
+	ls := newLeaseTestStorage(db, "signer", time.Hour, clock.System)
+
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		for i := 0; i < 2; i++ {
+			if _, err := tx.DequeueLeaves(ctx, 1, time.Now()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+	if got, want := readLeaseToken(ctx, t, db, tree.TreeId), int64(1); got != want {
+		t.Errorf("FencingToken = %d, want %d", got, want)
+	}
+}
+
+func TestDequeueLeaseFailedPass(t *testing.T) {
+	ctx := context.Background()
+	db, tree := setUpLeaseTest(ctx, t)
+	failing := newLeaseTestStorage(db, "failing-master", time.Hour, clock.System)
+	next := newLeaseTestStorage(db, "new-master", time.Hour, clock.System)
+
+	errFail := errors.New("pass failed")
+	if err := failing.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if _, err := tx.DequeueLeaves(ctx, numLeaseTestLeaves, time.Now()); err != nil {
+			return err
+		}
+		return errFail
+	}); err != errFail {
+		t.Fatalf("ReadWriteTransaction() = %v, want %v", err, errFail)
+	}
+	// The claim is only committed with the pass which made it, so it doesn't
+	// hold up other storage instances.
+	if got, want := readLeaseToken(ctx, t, db, tree.TreeId), int64(0); got != want {
+		t.Errorf("FencingToken = %d, want %d", got, want)
+	}
+	if n, err := integratePass(ctx, next, tree, numLeaseTestLeaves); err != nil || n != numLeaseTestLeaves {
+		t.Fatalf("integratePass(next) = %d, %v, want %d, nil", n, err, numLeaseTestLeaves)
+	}
+	checkIntegratedOnce(ctx, t, db, tree.TreeId)
+}
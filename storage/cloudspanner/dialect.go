@@ -0,0 +1,92 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudspanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+)
+
+// Dialect is the SQL dialect of a Spanner database.
+type Dialect int
+
+const (
+	// GoogleSQL is the dialect of databases with the schema in spanner.sdl.
+	GoogleSQL Dialect = iota
+	// PostgreSQL is the dialect of databases with the schema in
+	// spanner_pg.sdl.
+	PostgreSQL
+)
+
+// ParseDialect returns the Dialect named by s, which is either "googlesql"
+// or "postgresql". An empty s is GoogleSQL.
+func ParseDialect(s string) (Dialect, error) {
+	switch strings.ToLower(s) {
+	case "", "googlesql":
+		return GoogleSQL, nil
+	case "postgresql":
+		return PostgreSQL, nil
+	}
+	return GoogleSQL, fmt.Errorf("unknown Spanner dialect %q", s)
+}
+
+// String returns the name of the dialect.
+func (d Dialect) String() string {
+	switch d {
+	case GoogleSQL:
+		return "googlesql"
+	case PostgreSQL:
+		return "postgresql"
+	}
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+var (
+	// inUnnestRE matches GoogleSQL array membership tests.
+	inUnnestRE = regexp.MustCompile(`(?i)\bIN\s+UNNEST\s*\(\s*(@\w+)\s*\)`)
+	// paramRE matches GoogleSQL query parameters.
+	paramRE = regexp.MustCompile(`@(\w+)`)
+)
+
+// statement returns stmt, which is written in GoogleSQL with named @params,
+// in the form expected by databases of dialect d.
+//
+// For PostgreSQL, parameters are numbered in the order in which they first
+// appear in the SQL, as PostgreSQL databases only accept positional
+// parameters named $1, $2, etc., whose values are passed as p1, p2, etc., and
+// array membership tests are rewritten to use ANY. The statements of this
+// package are otherwise written in the subset of SQL common to both dialects.
+func (d Dialect) statement(stmt spanner.Statement) spanner.Statement {
+	if d != PostgreSQL {
+		return stmt
+	}
+	sql := inUnnestRE.ReplaceAllString(stmt.SQL, "= ANY($1)")
+	positions := make(map[string]int)
+	params := make(map[string]interface{}, len(stmt.Params))
+	sql = paramRE.ReplaceAllStringFunc(sql, func(p string) string {
+		name := p[1:]
+		pos, ok := positions[name]
+		if !ok {
+			pos = len(positions) + 1
+			positions[name] = pos
+			params[fmt.Sprintf("p%d", pos)] = stmt.Params[name]
+		}
+		return fmt.Sprintf("$%d", pos)
+	})
+	return spanner.Statement{SQL: sql, Params: params}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudspanner
+
+import (
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDialect(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    Dialect
+		wantErr bool
+	}{
+		{in: "", want: GoogleSQL},
+		{in: "googlesql", want: GoogleSQL},
+		{in: "PostgreSQL", want: PostgreSQL},
+		{in: "mysql", wantErr: true},
+	} {
+		got, err := ParseDialect(test.in)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("ParseDialect(%q): %v, wantErr %v", test.in, err, test.wantErr)
+			continue
+		}
+		if err == nil && got != test.want {
+			t.Errorf("ParseDialect(%q)=%v, want %v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestDialectStatement(t *testing.T) {
+	stmt := spanner.Statement{
+		SQL: `SELECT TreeID FROM SequencedLeafData
+		WHERE TreeID = @tree_id AND LeafIdentityHash IN UNNEST(@id_hashes) AND Revision <= @tree_id`,
+		Params: map[string]interface{}{"tree_id": int64(5), "id_hashes": [][]byte{{1}}},
+	}
+	for _, test := range []struct {
+		dialect Dialect
+		want    spanner.Statement
+	}{
+		{dialect: GoogleSQL, want: stmt},
+		{
+			dialect: PostgreSQL,
+			want: spanner.Statement{
+				SQL: `SELECT TreeID FROM SequencedLeafData
+		WHERE TreeID = $1 AND LeafIdentityHash = ANY($2) AND Revision <= $1`,
+				Params: map[string]interface{}{"p1": int64(5), "p2": [][]byte{{1}}},
+			},
+		},
+	} {
+		if diff := cmp.Diff(test.dialect.statement(stmt), test.want); diff != "" {
+			t.Errorf("%v statement() diff (-got +want):\n%s", test.dialect, diff)
+		}
+	}
+}
@@ -135,6 +135,7 @@ func cleanTestDB(ctx context.Context, t *testing.T, db *spanner.Client) {
 			"LeafData",
 			"SequencedLeafData",
 			"Unsequenced",
+			"DequeueLeases",
 			"MapLeafData",
 		} {
 			mutations = append(mutations, spanner.Delete(table, spanner.AllKeys()))
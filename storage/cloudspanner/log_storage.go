@@ -34,6 +34,7 @@ import (
 	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/storage/cloudspanner/spannerpb"
 	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
 	"go.opencensus.io/trace"
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/grpc/codes"
@@ -68,6 +69,16 @@ type LogStorageOptions struct {
 	// DequeueAcrossMerkleBucketsRangeFraction specifies the fraction of Merkle
 	// keyspace to dequeue from when using multi-bucket-dequeue.
 	DequeueAcrossMerkleBucketsRangeFraction float64
+	// DequeueLeaseDuration is how long a claim on a tree's queue made by
+	// DequeueLeaves excludes other storage instances from dequeuing from the
+	// same tree. Defaults to 10s if unset.
+	DequeueLeaseDuration time.Duration
+	// LeaseHolder identifies this storage instance in dequeue leases. A random
+	// identifier is used if unset.
+	LeaseHolder string
+	// TimeSource is used to set and check the expiry of dequeue leases.
+	// Defaults to clock.System if unset.
+	TimeSource clock.TimeSource
 }
 
 var (
@@ -115,6 +126,15 @@ func NewLogStorageWithOpts(client *spanner.Client, opts LogStorageOptions) stora
 	if got := opts.DequeueAcrossMerkleBucketsRangeFraction; got <= 0 || got > 1.0 {
 		opts.DequeueAcrossMerkleBucketsRangeFraction = 1.0
 	}
+	if opts.DequeueLeaseDuration <= 0 {
+		opts.DequeueLeaseDuration = defaultDequeueLeaseDuration
+	}
+	if opts.LeaseHolder == "" {
+		opts.LeaseHolder = newLeaseHolder()
+	}
+	if opts.TimeSource == nil {
+		opts.TimeSource = clock.System
+	}
 	return &logStorage{
 		ts: newTreeStorageWithOpts(client, opts.TreeStorageOptions),
 		// This number is taken from the maximum number of in-flight
@@ -436,6 +456,10 @@ type logTX struct {
 	// This is required to recover the primary key for the unsequenced entry in
 	// UpdateSequencedLeaves.
 	dequeued map[string]*QueuedEntry
+
+	// lease is the claim this transaction holds over the tree's Unsequenced
+	// queue, or nil if DequeueLeaves hasn't been called yet.
+	lease *dequeueLease
 }
 
 func (tx *logTX) getLogStorageConfig() *spannerpb.LogStorageConfig {
@@ -562,6 +586,11 @@ func readLeaves(ctx context.Context, stx *spanner.ReadOnlyTransaction, logID int
 // only the LeafIdentityHash and MerkleLeafHash fields will contain data, this
 // should be sufficient for assigning sequence numbers with this storage impl.
 //
+// Before reading the queue, the transaction claims a per-tree dequeue lease
+// and bumps the lease's fencing token, so that the passes of another storage
+// instance (e.g. of a signer which has just lost mastership) are refused until
+// the lease expires.
+//
 // TODO(al): cutoff is currently ignored.
 func (tx *logTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error) {
 	if limit <= 0 {
@@ -576,6 +605,10 @@ func (tx *logTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time)
 		return tx.GetLeavesByRange(ctx, sth.TreeSize, int64(limit))
 	}
 
+	if err := tx.claimDequeueLease(ctx); err != nil {
+		return nil, err
+	}
+
 	// Decide which bucket(s) to dequeue from.
 	// The high 8 bits of the bucket key is a time based ring - at any given
 	// moment, FEs queueing entries will be adding them to different buckets
@@ -674,7 +707,6 @@ func (tx *logTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.L
 	if err != nil {
 		return err
 	}
-
 	for _, l := range leaves {
 		if got, want := l.LeafIndex, currentSTH.TreeSize+tx.numSequenced; got != want {
 			return fmt.Errorf("attempting to assign non-sequential leaf with sequence %d, want %d", got, want)
@@ -853,7 +885,7 @@ func (tx *logTX) GetLeavesByIndex(ctx context.Context, indices []int64) ([]*tril
 	stmt.Params["tree_id"] = tx.treeID
 	stmt.Params["seq_nums"] = indices
 	seqLeaves := make(map[string]sequencedLeafDataCols)
-	if err := tx.stx.Query(ctx, stmt).Do(func(r *spanner.Row) error {
+	if err := tx.stx.Query(ctx, tx.ts.opts.Dialect.statement(stmt)).Do(func(r *spanner.Row) error {
 		var seqLeaf sequencedLeafDataCols
 		if err := r.ToStruct(&seqLeaf); err != nil {
 			return err
@@ -884,7 +916,7 @@ func (tx *logTX) GetLeavesByIndex(ctx context.Context, indices []int64) ([]*tril
 	stmt.Params["tree_id"] = tx.treeID
 	stmt.Params["id_hashes"] = idHashes
 	leaves := make(leafmap)
-	if err := tx.stx.Query(ctx, stmt).Do(leaves.addFullRow(seqLeaves)); err != nil {
+	if err := tx.stx.Query(ctx, tx.ts.opts.Dialect.statement(stmt)).Do(leaves.addFullRow(seqLeaves)); err != nil {
 		return nil, err
 	}
 
@@ -959,7 +991,7 @@ func (tx *logTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*t
 	stmt.Params["start"] = start
 	stmt.Params["xend"] = xend
 	seqLeaves := make(map[string]sequencedLeafDataCols)
-	if err := tx.stx.Query(ctx, stmt).Do(func(r *spanner.Row) error {
+	if err := tx.stx.Query(ctx, tx.ts.opts.Dialect.statement(stmt)).Do(func(r *spanner.Row) error {
 		var seqLeaf sequencedLeafDataCols
 		if err := r.ToStruct(&seqLeaf); err != nil {
 			return err
@@ -993,7 +1025,7 @@ func (tx *logTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*t
 	// Results need to be returned in order [start, end), all of which
 	// should be available (as we restricted xend/count to TreeSize).
 	leaves := make(leafmap)
-	if err := tx.stx.Query(ctx, stmt).
+	if err := tx.stx.Query(ctx, tx.ts.opts.Dialect.statement(stmt)).
 		Do(leaves.addFullRow(seqLeaves)); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,131 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudspanner
+
+import (
+	"context"
+
+	"cloud.google.com/go/spanner"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/storagepb/convert"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ storage.MapSnapshotWriter = &mapStorage{}
+
+// BeginSnapshotWrite implements storage.MapSnapshotWriter. Leaves and tiles
+// left by an earlier import are removed with partitioned DML.
+func (ms *mapStorage) BeginSnapshotWrite(ctx context.Context, tree *trillian.Tree, revision int64) error {
+	latest, err := ms.checkSnapshotTarget(ctx, ms.ts.client.Single(), tree.TreeId, revision)
+	if err != nil {
+		return err
+	}
+	// Nothing written above the latest root has been committed.
+	for _, sql := range []string{
+		"DELETE FROM MapLeafData WHERE TreeID = @tree_id AND MapRevision > @latest",
+		"DELETE FROM SubtreeData WHERE TreeID = @tree_id AND Revision > @latest",
+	} {
+		stmt := spanner.NewStatement(sql)
+		stmt.Params["tree_id"] = tree.TreeId
+		stmt.Params["latest"] = latest
+		if _, err := ms.ts.client.PartitionedUpdate(ctx, ms.ts.opts.Dialect.statement(stmt)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSnapshotTarget checks that the given revision can be imported into the
+// map, i.e. that the map has no root, or only that of revision 0 if the
+// revision is above it, and returns the revision of its latest root, or -1.
+func (ms *mapStorage) checkSnapshotTarget(ctx context.Context, stx spanRead, treeID, revision int64) (int64, error) {
+	if revision < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "map revision %d must be >= 0", revision)
+	}
+	latest := int64(-1)
+	th, err := ms.ts.latestSTH(ctx, stx, treeID)
+	switch {
+	case err == nil:
+		latest = th.TreeRevision
+	case err != storage.ErrTreeNeedsInit:
+		return 0, err
+	}
+	if latest > 0 || latest >= revision {
+		return 0, status.Errorf(codes.FailedPrecondition, "map %d already has revision %d, can't import revision %d", treeID, latest, revision)
+	}
+	return latest, nil
+}
+
+// SnapshotWriteLeaves implements storage.MapSnapshotWriter. The leaves are
+// written with a single commit, so there must be few enough of them for the
+// mutation limits of Spanner.
+func (ms *mapStorage) SnapshotWriteLeaves(ctx context.Context, tree *trillian.Tree, revision int64, leaves []*trillian.MapLeaf) error {
+	muts := make([]*spanner.Mutation, 0, len(leaves))
+	for _, leaf := range leaves {
+		if storage.IsMapLeafDeletion(leaf) {
+			continue
+		}
+		leafValue := leaf.LeafValue
+		if leafValue == nil {
+			leafValue = []byte{}
+		}
+		muts = append(muts, spanner.InsertOrUpdate(mapLeafDataTbl,
+			[]string{colTreeID, colLeafIndex, colMapRevision, colLeafHash, colLeafValue, colExtraData},
+			[]interface{}{tree.TreeId, leaf.Index, revision, leaf.LeafHash, leafValue, leaf.ExtraData}))
+	}
+	_, err := ms.ts.client.Apply(ctx, muts)
+	return err
+}
+
+// SnapshotWriteTiles implements storage.MapSnapshotWriter.
+func (ms *mapStorage) SnapshotWriteTiles(ctx context.Context, tree *trillian.Tree, revision int64, tiles []smt.Tile) error {
+	muts := make([]*spanner.Mutation, 0, len(tiles))
+	for _, tile := range tiles {
+		height := defaultMapLayout.TileHeight(int(tile.ID.BitLen()))
+		pb, err := convert.Marshal(tile, uint(height))
+		if err != nil {
+			return err
+		}
+		b, err := proto.Marshal(pb)
+		if err != nil {
+			return err
+		}
+		muts = append(muts, spanner.InsertOrUpdate(subtreeTbl,
+			[]string{colTreeID, colSubtreeID, colRevision, colSubtree},
+			[]interface{}{tree.TreeId, pb.Prefix, revision, b}))
+	}
+	_, err := ms.ts.client.Apply(ctx, muts)
+	return err
+}
+
+// CommitSnapshotWrite implements storage.MapSnapshotWriter.
+func (ms *mapStorage) CommitSnapshotWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	var r types.MapRootV1
+	if err := r.UnmarshalBinary(root.MapRoot); err != nil {
+		return err
+	}
+	_, err := ms.ts.client.ReadWriteTransaction(ctx, func(ctx context.Context, stx *spanner.ReadWriteTransaction) error {
+		if _, err := ms.checkSnapshotTarget(ctx, stx, tree.TreeId, int64(r.Revision)); err != nil {
+			return err
+		}
+		return stx.BufferWrite([]*spanner.Mutation{mapRootMutation(tree.TreeId, &r, root.Signature)})
+	})
+	return err
+}
@@ -82,7 +82,7 @@ func (ms *mapStorage) CheckDatabaseAccessible(ctx context.Context) error {
 }
 
 func newMapCache(tree *trillian.Tree) (*cache.SubtreeCache, error) {
-	hasher, err := registry.NewMapHasher(tree.HashStrategy)
+	hasher, err := registry.NewMapHasherForTree(tree)
 	if err != nil {
 		return nil, err
 	}
@@ -116,6 +116,26 @@ func (ms *mapStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree)
 	return ms.begin(ctx, tree, true, ms.ts.client.ReadOnlyTransaction())
 }
 
+// SnapshotAtRevision starts a read-only transaction pinned to the given
+// revision of the tree, by making its root the one the transaction treats as
+// the latest.
+func (ms *mapStorage) SnapshotAtRevision(ctx context.Context, tree *trillian.Tree, revision int64) (storage.ReadOnlyMapTreeTX, error) {
+	tx, err := ms.begin(ctx, tree, true, ms.ts.client.ReadOnlyTransaction())
+	if err != nil {
+		return nil, err
+	}
+	th, err := tx.getTreeHead(ctx, revision)
+	if err != nil {
+		tx.Close()
+		return nil, err
+	}
+	tx.getLatestRootOnce.Do(func() {
+		tx._currentSTH = th
+		tx._writeRev = th.TreeRevision + 1
+	})
+	return tx, nil
+}
+
 // Layout returns the layout of the given tree.
 func (ms *mapStorage) Layout(tree *trillian.Tree) (*tree.Layout, error) {
 	return defaultMapLayout, nil
@@ -211,18 +231,15 @@ func (tx *mapTX) StoreSignedMapRoot(ctx context.Context, root *trillian.SignedMa
 	if got, want := int64(r.Revision), writeRev; got != want {
 		return status.Errorf(codes.Internal, "root.Revision: %v, want %v", got, want)
 	}
-	sth := spannerpb.TreeHead{
-		TsNanos:      int64(r.TimestampNanos),
-		RootHash:     r.RootHash,
-		Metadata:     r.Metadata,
-		TreeId:       tx.treeID,
-		TreeRevision: writeRev,
-		Signature:    root.Signature,
-	}
+	return stx.BufferWrite([]*spanner.Mutation{mapRootMutation(tx.treeID, &r, root.Signature)})
+}
 
+// mapRootMutation returns the mutation inserting the given map root, with the
+// given signature, into the TreeHeads table.
+func mapRootMutation(treeID int64, r *types.MapRootV1, sig []byte) *spanner.Mutation {
 	// TODO(al): consider replacing these with InsertStruct throughout.
 	// TODO(al): consider making TreeSize nullable.
-	m := spanner.Insert(
+	return spanner.Insert(
 		treeHeadTbl,
 		[]string{
 			"TreeID",
@@ -234,16 +251,14 @@ func (tx *mapTX) StoreSignedMapRoot(ctx context.Context, root *trillian.SignedMa
 			"TreeMetadata",
 		},
 		[]interface{}{
-			int64(tx.treeID),
-			int64(sth.TsNanos),
+			treeID,
+			int64(r.TimestampNanos),
 			0,
-			sth.RootHash,
-			sth.Signature,
-			writeRev,
-			sth.Metadata,
+			r.RootHash,
+			sig,
+			int64(r.Revision),
+			r.Metadata,
 		})
-
-	return stx.BufferWrite([]*spanner.Mutation{m})
 }
 
 // Set sets the leaf with the specified index to value.
@@ -279,16 +294,11 @@ func (tx *mapTX) Set(ctx context.Context, index []byte, value *trillian.MapLeaf)
 // GetTiles reads the Merkle tree tiles with the given root IDs at the given
 // revision. A tile is empty if it is missing from the returned slice.
 func (tx *mapTX) GetTiles(ctx context.Context, rev int64, ids []tree.NodeID2) ([]smt.Tile, error) {
-	rootIDs := make([]tree.NodeID, 0, len(ids))
-	for _, id := range ids {
-		rootIDs = append(rootIDs, tree.NewNodeIDFromID2(id))
-	}
-
 	getTilesFn, err := tx.treeTX.getTilesFunc(ctx, rev)
 	if err != nil {
 		return nil, err
 	}
-	subtrees, err := getTilesFn(rootIDs)
+	subtrees, err := getTilesFn(ids)
 	if err != nil {
 		return nil, err
 	}
@@ -304,7 +314,7 @@ func (tx *mapTX) GetTiles(ctx context.Context, rev int64, ids []tree.NodeID2) ([
 	return tiles, nil
 }
 
-func (t *treeTX) getTilesFunc(ctx context.Context, rev int64) (func([]storage.NodeID) ([]*storagepb.SubtreeProto, error), error) {
+func (t *treeTX) getTilesFunc(ctx context.Context, rev int64) (func([]tree.NodeID2) ([]*storagepb.SubtreeProto, error), error) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 	if t.stx == nil {
@@ -313,8 +323,8 @@ func (t *treeTX) getTilesFunc(ctx context.Context, rev int64) (func([]storage.No
 	return t.parallelGetMerkleNodes(ctx, rev), nil
 }
 
-func (t *treeTX) parallelGetMerkleNodes(ctx context.Context, rev int64) func([]storage.NodeID) ([]*storagepb.SubtreeProto, error) {
-	return func(ids []storage.NodeID) ([]*storagepb.SubtreeProto, error) {
+func (t *treeTX) parallelGetMerkleNodes(ctx context.Context, rev int64) func([]tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
+	return func(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
 		ctx, span := trace.StartSpan(ctx, "TreeTX.parallelGetMerkleNodes")
 		defer span.End()
 		// Request the various subtrees in parallel.
@@ -328,7 +338,7 @@ func (t *treeTX) parallelGetMerkleNodes(ctx context.Context, rev int64) func([]s
 			g.Go(func() error {
 				st, err := t.getSubtree(gctx, rev, id)
 				if err != nil {
-					return fmt.Errorf("failed to treeTX.getSubtree(rev=%d, id=%x): %v", rev, id, err)
+					return fmt.Errorf("failed to treeTX.getSubtree(rev=%d, id=%v): %v", rev, id, err)
 				}
 				c <- st
 				return nil
@@ -368,6 +378,7 @@ func (tx *mapTX) getMapLeaf(ctx context.Context, revision int64, index []byte) (
 	cols := []string{colLeafIndex, colMapRevision, colLeafHash, colLeafValue, colExtraData}
 	rowKey := spanner.Key{tx.treeID, index}.AsPrefix()
 	var l *trillian.MapLeaf
+	var lRev int64
 	rows := tx.stx.Read(ctx, mapLeafDataTbl, spanner.KeySets(rowKey), cols)
 	err := rows.Do(func(r *spanner.Row) error {
 		// TODO(alcutter): add MapRevision to trillian.MapLeaf
@@ -376,10 +387,14 @@ func (tx *mapTX) getMapLeaf(ctx context.Context, revision int64, index []byte) (
 		if err := r.Columns(&leaf.Index, &rev, &leaf.LeafHash, &leaf.LeafValue, &leaf.ExtraData); err != nil {
 			return err
 		}
-		// Leaves are stored by descending revision, so the first one we find which
-		// satisfies this condition is good:
-		if rev <= revision {
-			l = &leaf
+		if rev > revision || (l != nil && rev <= lRev) {
+			return nil
+		}
+		l, lRev = &leaf, rev
+		// GoogleSQL databases store leaves by descending revision, so the first
+		// one we find which satisfies this condition is good. The PostgreSQL
+		// schema orders them ascending, so all revisions have to be looked at.
+		if tx.ts.opts.Dialect == GoogleSQL {
 			return errFinished
 		}
 		return nil
@@ -420,7 +435,8 @@ func (tx *mapTX) Get(ctx context.Context, revision int64, indexes [][]byte) ([]*
 	close(c)
 	ret := make([]*trillian.MapLeaf, 0, len(indexes))
 	for l := range c {
-		if l != nil {
+		// A deletion of the key is stored as an empty leaf.
+		if l != nil && !storage.IsMapLeafDeletion(l) {
 			ret = append(ret, l)
 		}
 	}
@@ -430,16 +446,25 @@ func (tx *mapTX) Get(ctx context.Context, revision int64, indexes [][]byte) ([]*
 // GetSignedMapRoot returns the SignedMapRoot for revision.
 // An error will be returned if there is a problem with the underlying storage.
 func (tx *mapTX) GetSignedMapRoot(ctx context.Context, revision int64) (*trillian.SignedMapRoot, error) {
+	th, err := tx.getTreeHead(ctx, revision)
+	if err != nil {
+		return nil, err
+	}
+	return sthToSMR(th)
+}
+
+// getTreeHead returns the TreeHead of the map at revision.
+func (tx *mapTX) getTreeHead(ctx context.Context, revision int64) (*spannerpb.TreeHead, error) {
 	query := spanner.NewStatement(
-		`SELECT t.TreeID, t.TimestampNanos, t.TreeSize, t.RootHash, t.RootSignature, t.TreeRevision, t.TreeMetadata FROM TreeHeads t
-				WHERE t.TreeID = @tree_id
-				AND t.TreeRevision = @tree_rev
+		`SELECT TreeID, TimestampNanos, TreeSize, RootHash, RootSignature, TreeRevision, TreeMetadata FROM TreeHeads
+				WHERE TreeID = @tree_id
+				AND TreeRevision = @tree_rev
 				LIMIT 1`)
 	query.Params["tree_id"] = tx.treeID
 	query.Params["tree_rev"] = revision
 
 	var th *spannerpb.TreeHead
-	rows := tx.stx.Query(ctx, query)
+	rows := tx.stx.Query(ctx, tx.ts.opts.Dialect.statement(query))
 	err := rows.Do(func(r *spanner.Row) error {
 		tth := &spannerpb.TreeHead{}
 		if err := r.Columns(&tth.TreeId, &tth.TsNanos, &tth.TreeSize, &tth.RootHash, &tth.Signature, &tth.TreeRevision, &tth.Metadata); err != nil {
@@ -458,5 +483,5 @@ func (tx *mapTX) GetSignedMapRoot(ctx context.Context, revision int64) (*trillia
 		}
 		return nil, status.Errorf(codes.NotFound, "map root %v not found", revision)
 	}
-	return sthToSMR(th)
+	return th, nil
 }
@@ -48,10 +48,18 @@ a2V0ICAgICAgICAgICAgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBRdWV1ZVRpbWVzdGFtcE5hbm9z
 ICAgIElOVDY0IE5PVCBOVUxMLAogIE1lcmtsZUxlYWZIYXNoICAgICAgICAgQllURVMoMjU2KSBO
 T1QgTlVMTCwKICBMZWFmSWRlbnRpdHlIYXNoICAgICAgIEJZVEVTKDI1NikgTk9UIE5VTEwsCikg
 UFJJTUFSWSBLRVkgKFRyZWVJRCwgQnVja2V0LCBRdWV1ZVRpbWVzdGFtcE5hbm9zLCBNZXJrbGVM
-ZWFmSGFzaCk7CgpDUkVBVEUgVEFCTEUgTWFwTGVhZkRhdGEoCiAgVHJlZUlEICAgICAgICAgICAg
-ICAgIElOVDY0IE5PVCBOVUxMLAogIExlYWZJbmRleCAgICAgICAgICAgICBCWVRFUygyNTYpIE5P
-VCBOVUxMLAogIE1hcFJldmlzaW9uICAgICAgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBMZWFmSGFz
-aCAgICAgICAgICAgICAgQllURVMoMjU2KSwKICBMZWFmVmFsdWUgICAgICAgICAgICAgQllURVMo
-TUFYKSBOT1QgTlVMTCwKICBFeHRyYURhdGEgICAgICAgICAgICAgQllURVMoTUFYKSwKKSBQUklN
-QVJZIEtFWShUcmVlSUQsIExlYWZJbmRleCwgTWFwUmV2aXNpb24gREVTQyk7Cg==
+ZWFmSGFzaCk7CgotLSBEZXF1ZXVlTGVhc2VzIGhvbGRzIHRoZSBjbGFpbSBhIHNpZ25lciBwYXNz
+IGhhcyBvbiBhIGxvZydzIFVuc2VxdWVuY2VkCi0tIHF1ZXVlLiBGZW5jaW5nVG9rZW4gaXMgaW5j
+cmVtZW50ZWQgYnkgZXZlcnkgY2xhaW0sIHdoaWNoIGxldHMgYSBwYXNzIGRldGVjdAotLSB0aGF0
+IGFub3RoZXIgcGFzcyBoYXMgY2xhaW1lZCB0aGUgcXVldWUgc2luY2UgaXQgc3RhcnRlZCBkZXF1
+ZXVpbmcuCkNSRUFURSBUQUJMRSBEZXF1ZXVlTGVhc2VzKAogIFRyZWVJRCAgICAgICAgICAgICAg
+ICAgSU5UNjQgTk9UIE5VTEwsCiAgRmVuY2luZ1Rva2VuICAgICAgICAgICBJTlQ2NCBOT1QgTlVM
+TCwKICBIb2xkZXIgICAgICAgICAgICAgICAgIFNUUklORygyNTYpIE5PVCBOVUxMLAogIEV4cGly
+eU5hbm9zICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCikgUFJJTUFSWSBLRVkgKFRyZWVJRCk7
+CgpDUkVBVEUgVEFCTEUgTWFwTGVhZkRhdGEoCiAgVHJlZUlEICAgICAgICAgICAgICAgIElOVDY0
+IE5PVCBOVUxMLAogIExlYWZJbmRleCAgICAgICAgICAgICBCWVRFUygyNTYpIE5PVCBOVUxMLAog
+IE1hcFJldmlzaW9uICAgICAgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBMZWFmSGFzaCAgICAgICAg
+ICAgICAgQllURVMoMjU2KSwKICBMZWFmVmFsdWUgICAgICAgICAgICAgQllURVMoTUFYKSBOT1Qg
+TlVMTCwKICBFeHRyYURhdGEgICAgICAgICAgICAgQllURVMoTUFYKSwKKSBQUklNQVJZIEtFWShU
+cmVlSUQsIExlYWZJbmRleCwgTWFwUmV2aXNpb24gREVTQyk7Cg==
 `
@@ -43,7 +43,9 @@ var (
 	csSessionHCInterval                  = flag.Duration("cloudspanner_healthcheck_interval", 0, "Interval betweek pinging sessions.")
 	csSessionTrackHandles                = flag.Bool("cloudspanner_track_session_handles", false, "determines whether the session pool will keep track of the stacktrace of the goroutines that take sessions from the pool.")
 	csDequeueAcrossMerkleBucketsFraction = flag.Float64("cloudspanner_dequeue_bucket_fraction", 0.75, "Fraction of merkle keyspace to dequeue from, set to zero to disable.")
+	csDequeueLeaseDuration               = flag.Duration("cloudspanner_dequeue_lease_duration", 10*time.Second, "How long a signer's claim on a log's queue excludes other signers from dequeuing from it.")
 	csReadOnlyStaleness                  = flag.Duration("cloudspanner_readonly_staleness", time.Minute, "How far in the past to perform readonly operations. Within limits, raising this should help to increase performance/reduce latency.")
+	csDialect                            = flag.String("cloudspanner_dialect", "googlesql", "SQL dialect of the CloudSpanner database: googlesql for databases created with spanner.sdl, or postgresql for databases created with spanner_pg.sdl.")
 
 	csMu              sync.RWMutex
 	csStorageInstance *cloudSpannerProvider
@@ -73,7 +75,8 @@ func warn() {
 }
 
 type cloudSpannerProvider struct {
-	client *spanner.Client
+	client  *spanner.Client
+	dialect Dialect
 }
 
 func configFromFlags() spanner.ClientConfig {
@@ -105,12 +108,17 @@ func newCloudSpannerStorageProvider(_ monitoring.MetricFactory) (storage.Provide
 		return csStorageInstance, nil
 	}
 
+	dialect, err := ParseDialect(*csDialect)
+	if err != nil {
+		return nil, err
+	}
 	client, err := spanner.NewClientWithConfig(context.TODO(), *csURI, configFromFlags(), optionsFromFlags()...)
 	if err != nil {
 		return nil, err
 	}
 	csStorageInstance = &cloudSpannerProvider{
-		client: client,
+		client:  client,
+		dialect: dialect,
 	}
 	return csStorageInstance, nil
 }
@@ -130,6 +138,8 @@ func (s *cloudSpannerProvider) LogStorage() storage.LogStorage {
 	if *csReadOnlyStaleness > 0 {
 		opts.ReadOnlyStaleness = *csReadOnlyStaleness
 	}
+	opts.DequeueLeaseDuration = *csDequeueLeaseDuration
+	opts.Dialect = s.dialect
 	return NewLogStorageWithOpts(s.client, opts)
 }
 
@@ -140,13 +150,14 @@ func (s *cloudSpannerProvider) MapStorage() storage.MapStorage {
 	if *csReadOnlyStaleness > 0 {
 		opts.ReadOnlyStaleness = *csReadOnlyStaleness
 	}
+	opts.Dialect = s.dialect
 	return NewMapStorageWithOpts(s.client, opts)
 }
 
 // AdminStorage builds and returns a new storage.AdminStorage using CloudSpanner.
 func (s *cloudSpannerProvider) AdminStorage() storage.AdminStorage {
 	warn()
-	return NewAdminStorage(s.client)
+	return NewAdminStorageWithDialect(s.client, s.dialect)
 }
 
 // Close shuts down this provider. Calls to the other methods will fail
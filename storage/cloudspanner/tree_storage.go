@@ -81,6 +81,9 @@ type TreeStorageOptions struct {
 	// to help with performance.
 	// See https://cloud.google.com/spanner/docs/timestamp-bounds for more details.
 	ReadOnlyStaleness time.Duration
+
+	// Dialect is the SQL dialect of the database.
+	Dialect Dialect
 }
 
 func newTreeStorageWithOpts(client *spanner.Client, opts TreeStorageOptions) *treeStorage {
@@ -105,7 +108,7 @@ func (t *treeStorage) latestSTH(ctx context.Context, stx spanRead, treeID int64)
 	query.Params["tree_id"] = treeID
 
 	var th *spannerpb.TreeHead
-	rows := stx.Query(ctx, query)
+	rows := stx.Query(ctx, t.opts.Dialect.statement(query))
 	defer rows.Stop()
 	err := rows.Do(func(r *spanner.Row) error {
 		tth := &spannerpb.TreeHead{}
@@ -340,22 +343,20 @@ func (t *treeTX) WriteRevision(ctx context.Context) (int64, error) {
 // subtreeKey returns a non-nil []byte suitable for use as a primary key column
 // for the subtree rooted at the passed-in node ID. Returns an error if the ID
 // is not aligned to bytes.
-func subtreeKey(id tree.NodeID) ([]byte, error) {
+func subtreeKey(id tree.NodeID2) ([]byte, error) {
 	// TODO(pavelkalinnikov): Extend this check to verify strata boundaries.
-	if id.PrefixLenBits%8 != 0 {
-		return nil, fmt.Errorf("invalid subtree ID - not multiple of 8: %d", id.PrefixLenBits)
-	}
-	// The returned slice must not be nil, as it would correspond to NULL in SQL.
-	if bytes := id.Path; bytes != nil {
-		return bytes[:id.PrefixLenBits/8], nil
+	tileID, err := tree.NewTileID(id)
+	if err != nil {
+		return nil, err
 	}
-	return []byte{}, nil
+	// The returned slice is not nil, as that would correspond to NULL in SQL.
+	return tileID.AsBytes(), nil
 }
 
 // getSubtree retrieves the most recent subtree specified by id at (or below)
 // the requested revision.
 // If no such subtree exists it returns nil.
-func (t *treeTX) getSubtree(ctx context.Context, rev int64, id tree.NodeID) (p *storagepb.SubtreeProto, e error) {
+func (t *treeTX) getSubtree(ctx context.Context, rev int64, id tree.NodeID2) (p *storagepb.SubtreeProto, e error) {
 	stID, err := subtreeKey(id)
 	if err != nil {
 		return nil, err
@@ -373,7 +374,7 @@ func (t *treeTX) getSubtree(ctx context.Context, rev int64, id tree.NodeID) (p *
 	stmt.Params["subtree_id"] = stID
 	stmt.Params["revision"] = rev
 
-	rows := t.stx.Query(ctx, stmt)
+	rows := t.stx.Query(ctx, t.ts.opts.Dialect.statement(stmt))
 	err = rows.Do(func(r *spanner.Row) error {
 		if ret != nil {
 			return nil
@@ -420,7 +421,7 @@ func (t *treeTX) GetMerkleNodes(ctx context.Context, rev int64, ids []tree.NodeI
 	}
 
 	return t.cache.GetNodes(ids,
-		func(ids []tree.NodeID) ([]*storagepb.SubtreeProto, error) {
+		func(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
 			// Request the various subtrees in parallel.
 			// c will carry any retrieved subtrees
 			c := make(chan *storagepb.SubtreeProto, len(ids))
@@ -473,7 +474,7 @@ func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
 		err := t.cache.SetNodeHash(
 			n.NodeID,
 			n.Hash,
-			func(nID tree.NodeID) (*storagepb.SubtreeProto, error) {
+			func(nID tree.NodeID2) (*storagepb.SubtreeProto, error) {
 				return t.getSubtree(ctx, writeRev-1, nID)
 			})
 		if err != nil {
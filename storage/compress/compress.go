@@ -0,0 +1,98 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compress compresses the serialized protos which storage
+// implementations write for map leaves and tiles, as selected by the
+// map_compression of a tree.
+//
+// A compressed value starts with a zero byte, followed by a byte naming its
+// compression, and the compressed proto. A serialized proto never starts with
+// a zero byte, as field number 0 is invalid, so values written without
+// compression, e.g. before the compression of the tree was set, are returned
+// by Decompress as they are.
+package compress
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/google/trillian"
+	"github.com/klauspost/compress/zstd"
+)
+
+// marker is the first byte of a compressed value.
+const marker = 0x00
+
+var (
+	zstdOnce sync.Once
+	zstdEnc  *zstd.Encoder
+	zstdDec  *zstd.Decoder
+	zstdErr  error
+)
+
+// zstdCodec returns the Zstandard encoder and decoder shared by all callers,
+// both of which are safe for concurrent use with EncodeAll and DecodeAll.
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder, error) {
+	zstdOnce.Do(func() {
+		if zstdEnc, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+		zstdDec, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdEnc, zstdDec, zstdErr
+}
+
+// Compress returns b compressed with c, and marked with it. Empty values, and
+// values compressed with NO_MAP_COMPRESSION, are returned as they are.
+func Compress(c trillian.MapCompression, b []byte) ([]byte, error) {
+	if len(b) == 0 || c == trillian.MapCompression_NO_MAP_COMPRESSION {
+		return b, nil
+	}
+	dst := []byte{marker, byte(c)}
+	switch c {
+	case trillian.MapCompression_MAP_COMPRESSION_SNAPPY:
+		return append(dst, snappy.Encode(nil, b)...), nil
+	case trillian.MapCompression_MAP_COMPRESSION_ZSTD:
+		enc, _, err := zstdCodec()
+		if err != nil {
+			return nil, err
+		}
+		return enc.EncodeAll(b, dst), nil
+	}
+	return nil, fmt.Errorf("unknown map compression %v", c)
+}
+
+// Decompress returns the value which Compress returned b for. Values which
+// aren't marked as compressed are returned as they are.
+func Decompress(b []byte) ([]byte, error) {
+	if len(b) == 0 || b[0] != marker {
+		return b, nil
+	}
+	if len(b) < 2 {
+		return nil, fmt.Errorf("compressed value of %d bytes has no compression", len(b))
+	}
+	switch c := trillian.MapCompression(b[1]); c {
+	case trillian.MapCompression_MAP_COMPRESSION_SNAPPY:
+		return snappy.Decode(nil, b[2:])
+	case trillian.MapCompression_MAP_COMPRESSION_ZSTD:
+		_, dec, err := zstdCodec()
+		if err != nil {
+			return nil, err
+		}
+		return dec.DecodeAll(b[2:], nil)
+	default:
+		return nil, fmt.Errorf("value compressed with unknown map compression %v", c)
+	}
+}
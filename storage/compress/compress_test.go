@@ -0,0 +1,72 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	value := bytes.Repeat([]byte(`{"name": "value", "count": 1234}`), 100)
+	leaf, err := proto.Marshal(&trillian.MapLeaf{Index: []byte("index"), LeafValue: value})
+	if err != nil {
+		t.Fatalf("proto.Marshal(): %v", err)
+	}
+
+	for _, c := range []trillian.MapCompression{
+		trillian.MapCompression_NO_MAP_COMPRESSION,
+		trillian.MapCompression_MAP_COMPRESSION_SNAPPY,
+		trillian.MapCompression_MAP_COMPRESSION_ZSTD,
+	} {
+		t.Run(c.String(), func(t *testing.T) {
+			for _, b := range [][]byte{nil, {}, leaf} {
+				compressed, err := Compress(c, b)
+				if err != nil {
+					t.Fatalf("Compress(%d bytes): %v", len(b), err)
+				}
+				if c != trillian.MapCompression_NO_MAP_COMPRESSION && len(b) > 0 && len(compressed) >= len(b) {
+					t.Errorf("Compress(%d bytes): %d bytes, want fewer", len(b), len(compressed))
+				}
+				got, err := Decompress(compressed)
+				if err != nil {
+					t.Fatalf("Decompress(): %v", err)
+				}
+				if !bytes.Equal(got, b) {
+					t.Errorf("Decompress(Compress(%d bytes)): %d bytes differ", len(b), len(got))
+				}
+			}
+		})
+	}
+}
+
+func TestDecompressErrors(t *testing.T) {
+	if _, err := Compress(trillian.MapCompression(99), []byte("value")); err == nil {
+		t.Error("Compress() with an unknown compression succeeded")
+	}
+	for _, b := range [][]byte{
+		{marker},
+		{marker, 99, 1, 2, 3},
+		{marker, byte(trillian.MapCompression_MAP_COMPRESSION_SNAPPY), 0xff, 0xff, 0xff},
+		append([]byte{marker, byte(trillian.MapCompression_MAP_COMPRESSION_ZSTD)}, "not zstd"...),
+	} {
+		if _, err := Decompress(b); err == nil {
+			t.Errorf("Decompress(%x) succeeded, want error", b)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type signerEpochKey struct{}
+
+// WithSignerEpoch returns a context carrying the epoch of the signer which
+// writes roots with it. Epochs are fencing tokens handed out by master
+// election, and increase whenever mastership of a tree changes hands.
+//
+// Storage implementations which support epochs store the epoch of every root
+// (TreeHead/MapHead), and StoreSignedLogRoot and StoreSignedMapRoot reject
+// roots whose epoch is lower than that of a root already stored for the tree,
+// using CheckSignerEpoch. This makes a deposed master which still believes
+// that it holds mastership fail to write, rather than fork the tree. Roots
+// written without an epoch inherit the highest epoch stored for the tree.
+func WithSignerEpoch(ctx context.Context, epoch int64) context.Context {
+	return context.WithValue(ctx, signerEpochKey{}, epoch)
+}
+
+// SignerEpochFromContext returns the signer epoch carried by ctx, if any.
+func SignerEpochFromContext(ctx context.Context) (int64, bool) {
+	epoch, ok := ctx.Value(signerEpochKey{}).(int64)
+	return epoch, ok
+}
+
+// CheckSignerEpoch checks the signer epoch carried by ctx, if any, against the
+// highest epoch stored for a tree, and returns the epoch to store with its new
+// root. It returns a FailedPrecondition error if the epoch in ctx is lower
+// than the stored one.
+func CheckSignerEpoch(ctx context.Context, treeID, stored int64) (int64, error) {
+	epoch, ok := SignerEpochFromContext(ctx)
+	if !ok {
+		return stored, nil
+	}
+	if epoch < stored {
+		return 0, status.Errorf(codes.FailedPrecondition, "tree %d: signer epoch %d is lower than stored epoch %d, mastership has probably been lost", treeID, epoch, stored)
+	}
+	return epoch, nil
+}
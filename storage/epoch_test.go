@@ -0,0 +1,50 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckSignerEpoch(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		desc      string
+		ctx       context.Context
+		stored    int64
+		wantEpoch int64
+		wantCode  codes.Code
+	}{
+		{desc: "no-epoch", ctx: ctx, stored: 7, wantEpoch: 7},
+		{desc: "no-epoch-none-stored", ctx: ctx, wantEpoch: 0},
+		{desc: "higher", ctx: WithSignerEpoch(ctx, 8), stored: 7, wantEpoch: 8},
+		{desc: "equal", ctx: WithSignerEpoch(ctx, 7), stored: 7, wantEpoch: 7},
+		{desc: "lower", ctx: WithSignerEpoch(ctx, 6), stored: 7, wantCode: codes.FailedPrecondition},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			epoch, err := CheckSignerEpoch(test.ctx, 1, test.stored)
+			if got, want := status.Code(err), test.wantCode; got != want {
+				t.Fatalf("CheckSignerEpoch(): %v, want code %v", err, want)
+			}
+			if got, want := epoch, test.wantEpoch; got != want {
+				t.Errorf("CheckSignerEpoch(): epoch %d, want %d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/trillian/client/backoff"
+	"github.com/google/trillian/storage/fdb"
+	"go.etcd.io/etcd/clientv3"
+)
+
+// maxConflicts is the number of times a transaction is retried after
+// conflicting with another one.
+const maxConflicts = 10
+
+// errConflict is returned by Commit when a key read by the transaction was
+// modified after the transaction read it.
+var errConflict = errors.New("etcd: transaction conflicted with another one")
+
+// NewDatabase returns an fdb.Database which stores its keys in etcd.
+//
+// Transactions read a snapshot of the keyspace, as of the etcd revision of
+// their first read, and buffer their writes. Commit writes them in a single
+// etcd transaction, on the condition that none of the keys read have been
+// modified since that revision. This gives serializable transactions, with
+// two caveats:
+//   - Deleting a key within a range read by another transaction doesn't make
+//     it conflict, as etcd doesn't keep the revisions of deleted keys. The
+//     log storage only deletes the queued leaves of a tree along with writing
+//     a new root, which concurrent signers conflict on.
+//   - etcd limits the number of compares and operations of a transaction,
+//     128 of each by default (see the --max-txn-ops flag of etcd), which
+//     bounds the number of leaves which can be queued or sequenced at once.
+func NewDatabase(client *clientv3.Client) fdb.Database {
+	return &database{kv: client}
+}
+
+type database struct {
+	kv clientv3.KV
+}
+
+func (d *database) CreateTransaction(ctx context.Context) (fdb.Transaction, error) {
+	return &transaction{
+		ctx: ctx,
+		kv:  d.kv,
+		backoff: backoff.Backoff{
+			Min:    10 * time.Millisecond,
+			Max:    time.Second,
+			Factor: 2,
+			Jitter: true,
+		},
+	}, nil
+}
+
+// op is a buffered write. It clears the keys in [key, end) if end is set,
+// otherwise it sets key to value, or clears it if del is set.
+type op struct {
+	key, end []byte
+	value    []byte
+	del      bool
+}
+
+// covers returns whether the op writes key.
+func (o op) covers(key []byte) bool {
+	if o.end == nil {
+		return bytes.Equal(o.key, key)
+	}
+	return bytes.Compare(o.key, key) <= 0 && bytes.Compare(key, o.end) < 0
+}
+
+// overlaps returns whether the op writes any key in [begin, end).
+func (o op) overlaps(begin, end []byte) bool {
+	if o.end == nil {
+		return bytes.Compare(begin, o.key) <= 0 && bytes.Compare(o.key, end) < 0
+	}
+	return bytes.Compare(o.key, end) < 0 && bytes.Compare(begin, o.end) < 0
+}
+
+type transaction struct {
+	ctx     context.Context
+	kv      clientv3.KV
+	backoff backoff.Backoff
+
+	// rev is the etcd revision read by the transaction, or 0 until its
+	// first read.
+	rev int64
+	// read holds the ranges for which cmps has a compare.
+	read      map[[2]string]bool
+	cmps      []clientv3.Cmp
+	ops       []op
+	conflicts int
+}
+
+func (t *transaction) get(key []byte, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	if t.rev > 0 {
+		opts = append(opts, clientv3.WithRev(t.rev))
+	}
+	resp, err := t.kv.Get(t.ctx, string(key), opts...)
+	if err != nil {
+		return nil, err
+	}
+	if t.rev == 0 {
+		t.rev = resp.Header.Revision
+	}
+	return resp, nil
+}
+
+// readRange records that the keys in [begin, end) were read, or just begin if
+// end is nil, so that Commit fails if any of them has been modified since.
+func (t *transaction) readRange(begin, end []byte) {
+	r := [2]string{string(begin), string(end)}
+	if t.read[r] {
+		return
+	}
+	if t.read == nil {
+		t.read = make(map[[2]string]bool)
+	}
+	t.read[r] = true
+	cmp := clientv3.Compare(clientv3.ModRevision(string(begin)), "<", t.rev+1)
+	if end != nil {
+		cmp = cmp.WithRange(string(end))
+	}
+	t.cmps = append(t.cmps, cmp)
+}
+
+func (t *transaction) Get(key []byte) ([]byte, error) {
+	for i := len(t.ops) - 1; i >= 0; i-- {
+		if o := t.ops[i]; o.covers(key) {
+			if o.del || o.end != nil {
+				return nil, nil
+			}
+			return o.value, nil
+		}
+	}
+	resp, err := t.get(key)
+	if err != nil {
+		return nil, err
+	}
+	t.readRange(key, nil)
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	// Empty values are returned as nil, which would read as unset.
+	return append([]byte{}, resp.Kvs[0].Value...), nil
+}
+
+func (t *transaction) GetRange(begin, end []byte, limit int, reverse bool) ([]fdb.KeyValue, error) {
+	order := clientv3.SortAscend
+	if reverse {
+		order = clientv3.SortDescend
+	}
+	opts := []clientv3.OpOption{clientv3.WithRange(string(end)), clientv3.WithSort(clientv3.SortByKey, order)}
+	var written []op
+	for _, o := range t.ops {
+		if o.overlaps(begin, end) {
+			written = append(written, o)
+		}
+	}
+	if limit > 0 && len(written) == 0 {
+		opts = append(opts, clientv3.WithLimit(int64(limit)))
+	}
+	resp, err := t.get(begin, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	kvs := make([]fdb.KeyValue, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		kvs = append(kvs, fdb.KeyValue{Key: kv.Key, Value: append([]byte{}, kv.Value...)})
+	}
+	if len(written) > 0 {
+		kvs = applyOps(kvs, written, begin, end, reverse)
+	}
+	if limit > 0 && len(kvs) >= limit {
+		// Only the keys up to the last one returned were read.
+		kvs = kvs[:limit]
+		last := kvs[limit-1].Key
+		if reverse {
+			begin = last
+		} else {
+			end = append(append([]byte{}, last...), 0)
+		}
+	}
+	t.readRange(begin, end)
+	return kvs, nil
+}
+
+// applyOps returns the keys in [begin, end) once ops are applied to kvs, in
+// the order of reverse.
+func applyOps(kvs []fdb.KeyValue, ops []op, begin, end []byte, reverse bool) []fdb.KeyValue {
+	values := make(map[string][]byte, len(kvs))
+	for _, kv := range kvs {
+		values[string(kv.Key)] = kv.Value
+	}
+	for _, o := range ops {
+		switch {
+		case o.end != nil:
+			for k := range values {
+				if o.covers([]byte(k)) {
+					delete(values, k)
+				}
+			}
+		case o.del:
+			delete(values, string(o.key))
+		case bytes.Compare(begin, o.key) <= 0 && bytes.Compare(o.key, end) < 0:
+			values[string(o.key)] = o.value
+		}
+	}
+	ret := make([]fdb.KeyValue, 0, len(values))
+	for k, v := range values {
+		ret = append(ret, fdb.KeyValue{Key: []byte(k), Value: v})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return (bytes.Compare(ret[i].Key, ret[j].Key) < 0) != reverse
+	})
+	return ret
+}
+
+func (t *transaction) Set(key, value []byte) {
+	t.ops = append(t.ops, op{key: key, value: append([]byte{}, value...)})
+}
+
+func (t *transaction) Clear(key []byte) {
+	t.ops = append(t.ops, op{key: key, del: true})
+}
+
+func (t *transaction) ClearRange(begin, end []byte) {
+	t.ops = append(t.ops, op{key: begin, end: end, del: true})
+}
+
+// txnOps returns the etcd operations which apply the buffered writes. etcd
+// doesn't allow a transaction to write a key more than once, or to put a key
+// which it also deletes, so only the last write of each key is kept: the ops
+// are walked from the newest, writes of keys which a newer op wrote or
+// cleared are dropped, and cleared ranges leave out the keys put after them.
+func (t *transaction) txnOps() []clientv3.Op {
+	var ret []clientv3.Op
+	seen := make(map[string]bool)
+	var ranges []op
+	var puts []string
+	for i := len(t.ops) - 1; i >= 0; i-- {
+		o := t.ops[i]
+		if o.end != nil {
+			ranges = append(ranges, o)
+			ret = append(ret, deleteRange(o.key, o.end, puts)...)
+			continue
+		}
+		if seen[string(o.key)] {
+			continue
+		}
+		seen[string(o.key)] = true
+		covered := false
+		for _, r := range ranges {
+			covered = covered || r.covers(o.key)
+		}
+		switch {
+		case covered:
+			// The range was cleared after the key was written.
+		case o.del:
+			ret = append(ret, clientv3.OpDelete(string(o.key)))
+		default:
+			ret = append(ret, clientv3.OpPut(string(o.key), string(o.value)))
+			puts = append(puts, string(o.key))
+		}
+	}
+	return ret
+}
+
+// deleteRange returns the etcd operations which delete the keys in
+// [begin, end), except for the keys in puts.
+func deleteRange(begin, end []byte, puts []string) []clientv3.Op {
+	var keep []string
+	for _, k := range puts {
+		if bytes.Compare(begin, []byte(k)) <= 0 && bytes.Compare([]byte(k), end) < 0 {
+			keep = append(keep, k)
+		}
+	}
+	sort.Strings(keep)
+	var ret []clientv3.Op
+	from := string(begin)
+	for _, k := range append(keep, string(end)) {
+		if from < k {
+			ret = append(ret, clientv3.OpDelete(from, clientv3.WithRange(k)))
+		}
+		// The key right after k.
+		from = k + "\x00"
+	}
+	return ret
+}
+
+func (t *transaction) Commit() error {
+	if len(t.ops) == 0 {
+		return nil
+	}
+	resp, err := t.kv.Txn(t.ctx).If(t.cmps...).Then(t.txnOps()...).Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return errConflict
+	}
+	return nil
+}
+
+func (t *transaction) OnError(err error) error {
+	if err != errConflict {
+		return err
+	}
+	if t.conflicts++; t.conflicts > maxConflicts {
+		return err
+	}
+	t.Cancel()
+	select {
+	case <-t.ctx.Done():
+		return t.ctx.Err()
+	case <-time.After(t.backoff.Duration()):
+	}
+	return nil
+}
+
+func (t *transaction) Cancel() {
+	t.rev = 0
+	t.read = nil
+	t.cmps = nil
+	t.ops = nil
+}
@@ -0,0 +1,330 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/fdb"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/testonly/integration/etcd"
+	"go.etcd.io/etcd/clientv3"
+)
+
+var (
+	// Initialized by TestMain().
+	client *clientv3.Client
+
+	prefixes int64
+)
+
+func TestMain(m *testing.M) {
+	_, c, cleanup, err := etcd.StartEtcd()
+	if err != nil {
+		panic(fmt.Sprintf("StartEtcd() returned err = %v", err))
+	}
+	client = c
+	exitCode := m.Run()
+	cleanup()
+	os.Exit(exitCode)
+}
+
+// newPrefix returns a key prefix which no other test uses.
+func newPrefix() []byte {
+	return []byte(fmt.Sprintf("test%d/", atomic.AddInt64(&prefixes, 1)))
+}
+
+func keys(kvs []fdb.KeyValue) []string {
+	var ret []string
+	for _, kv := range kvs {
+		ret = append(ret, string(kv.Key))
+	}
+	return ret
+}
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := NewDatabase(client)
+	p := string(newPrefix())
+
+	tr, err := db.CreateTransaction(ctx)
+	if err != nil {
+		t.Fatalf("CreateTransaction(): %v", err)
+	}
+	for _, k := range []string{"a", "b", "c", "d"} {
+		tr.Set([]byte(p+k), []byte(k))
+	}
+	if err := tr.Commit(); err != nil {
+		t.Fatalf("Commit(): %v", err)
+	}
+
+	tr, err = db.CreateTransaction(ctx)
+	if err != nil {
+		t.Fatalf("CreateTransaction(): %v", err)
+	}
+	begin, end := []byte(p), []byte(p+"z")
+	kvs, err := tr.GetRange(begin, end, 2, true)
+	if err != nil {
+		t.Fatalf("GetRange(): %v", err)
+	}
+	if diff := cmp.Diff(keys(kvs), []string{p + "d", p + "c"}); diff != "" {
+		t.Errorf("GetRange(reverse) diff (-got +want):\n%s", diff)
+	}
+
+	// Reads see the writes of the transaction.
+	tr.Set([]byte(p+"e"), []byte("e"))
+	tr.Set([]byte(p+"a"), []byte("A"))
+	tr.Clear([]byte(p + "b"))
+	tr.ClearRange([]byte(p+"c"), []byte(p+"d"))
+	kvs, err = tr.GetRange(begin, end, 0, false)
+	if err != nil {
+		t.Fatalf("GetRange(): %v", err)
+	}
+	if diff := cmp.Diff(keys(kvs), []string{p + "a", p + "d", p + "e"}); diff != "" {
+		t.Errorf("GetRange() diff (-got +want):\n%s", diff)
+	}
+	if v, err := tr.Get([]byte(p + "a")); err != nil || string(v) != "A" {
+		t.Errorf("Get(a)=%q, %v, want %q", v, err, "A")
+	}
+	if v, err := tr.Get([]byte(p + "c")); err != nil || v != nil {
+		t.Errorf("Get(c)=%q, %v, want nil", v, err)
+	}
+	if err := tr.Commit(); err != nil {
+		t.Fatalf("Commit(): %v", err)
+	}
+
+	tr, err = db.CreateTransaction(ctx)
+	if err != nil {
+		t.Fatalf("CreateTransaction(): %v", err)
+	}
+	kvs, err = tr.GetRange(begin, end, 0, false)
+	if err != nil {
+		t.Fatalf("GetRange(): %v", err)
+	}
+	if diff := cmp.Diff(keys(kvs), []string{p + "a", p + "d", p + "e"}); diff != "" {
+		t.Errorf("GetRange(committed) diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestTransactionWriteOrder(t *testing.T) {
+	ctx := context.Background()
+	db := NewDatabase(client)
+
+	for _, test := range []struct {
+		desc  string
+		write func(tr fdb.Transaction, key func(string) []byte)
+		want  []string
+	}{
+		{
+			desc: "set-then-clear",
+			write: func(tr fdb.Transaction, key func(string) []byte) {
+				tr.Set(key("a"), []byte("A"))
+				tr.Set(key("d"), []byte("D"))
+				tr.ClearRange(key("a"), key("z"))
+			},
+		},
+		{
+			desc: "clear-then-set",
+			write: func(tr fdb.Transaction, key func(string) []byte) {
+				tr.ClearRange(key("a"), key("z"))
+				tr.Set(key("b"), []byte("B"))
+				tr.Set(key("d"), []byte("D"))
+			},
+			want: []string{"b=B", "d=D"},
+		},
+		{
+			desc: "clear-set-clear",
+			write: func(tr fdb.Transaction, key func(string) []byte) {
+				tr.ClearRange(key("a"), key("z"))
+				tr.Set(key("b"), []byte("B"))
+				tr.Set(key("d"), []byte("D"))
+				tr.ClearRange(key("a"), key("c"))
+			},
+			want: []string{"d=D"},
+		},
+		{
+			desc: "set-clear-set",
+			write: func(tr fdb.Transaction, key func(string) []byte) {
+				tr.Set(key("a"), []byte("A"))
+				tr.ClearRange(key("a"), key("z"))
+				tr.Set(key("a"), []byte("A2"))
+				tr.Set(key("c"), []byte("C"))
+			},
+			want: []string{"a=A2", "c=C"},
+		},
+		{
+			desc: "set-at-range-bounds",
+			write: func(tr fdb.Transaction, key func(string) []byte) {
+				tr.ClearRange(key("a"), key("c"))
+				tr.Set(key("a"), []byte("A"))
+				tr.Set(key("c"), []byte("C"))
+			},
+			want: []string{"a=A", "c=C"},
+		},
+		{
+			desc: "clear-then-delete",
+			write: func(tr fdb.Transaction, key func(string) []byte) {
+				tr.ClearRange(key("a"), key("z"))
+				tr.Clear(key("b"))
+			},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			p := string(newPrefix())
+			key := func(k string) []byte { return []byte(p + k) }
+			begin, end := key(""), key("~")
+			keyValues := func(kvs []fdb.KeyValue) []string {
+				var ret []string
+				for _, kv := range kvs {
+					ret = append(ret, fmt.Sprintf("%s=%s", kv.Key[len(p):], kv.Value))
+				}
+				return ret
+			}
+
+			tr, err := db.CreateTransaction(ctx)
+			if err != nil {
+				t.Fatalf("CreateTransaction(): %v", err)
+			}
+			for _, k := range []string{"a", "b", "c"} {
+				tr.Set(key(k), []byte("old"))
+			}
+			if err := tr.Commit(); err != nil {
+				t.Fatalf("Commit(): %v", err)
+			}
+
+			tr, err = db.CreateTransaction(ctx)
+			if err != nil {
+				t.Fatalf("CreateTransaction(): %v", err)
+			}
+			test.write(tr, key)
+			kvs, err := tr.GetRange(begin, end, 0, false)
+			if err != nil {
+				t.Fatalf("GetRange(): %v", err)
+			}
+			if diff := cmp.Diff(keyValues(kvs), test.want); diff != "" {
+				t.Errorf("GetRange() diff (-got +want):\n%s", diff)
+			}
+			if err := tr.Commit(); err != nil {
+				t.Fatalf("Commit(): %v", err)
+			}
+
+			tr, err = db.CreateTransaction(ctx)
+			if err != nil {
+				t.Fatalf("CreateTransaction(): %v", err)
+			}
+			kvs, err = tr.GetRange(begin, end, 0, false)
+			if err != nil {
+				t.Fatalf("GetRange(): %v", err)
+			}
+			if diff := cmp.Diff(keyValues(kvs), test.want); diff != "" {
+				t.Errorf("GetRange(committed) diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTransactionConflict(t *testing.T) {
+	ctx := context.Background()
+	db := NewDatabase(client)
+	p := string(newPrefix())
+
+	for _, test := range []struct {
+		desc         string
+		read         func(tr fdb.Transaction) error
+		write        string
+		wantConflict bool
+	}{
+		{
+			desc:         "get",
+			read:         func(tr fdb.Transaction) error { _, err := tr.Get([]byte(p + "get")); return err },
+			write:        "get",
+			wantConflict: true,
+		},
+		{
+			desc:  "get-other",
+			read:  func(tr fdb.Transaction) error { _, err := tr.Get([]byte(p + "get")); return err },
+			write: "other",
+		},
+		{
+			desc: "range",
+			read: func(tr fdb.Transaction) error {
+				_, err := tr.GetRange([]byte(p+"r0"), []byte(p+"r9"), 0, false)
+				return err
+			},
+			write:        "r5",
+			wantConflict: true,
+		},
+		{
+			// Only the keys up to the last one returned are read.
+			desc: "range-limit",
+			read: func(tr fdb.Transaction) error {
+				_, err := tr.GetRange([]byte(p+"l0"), []byte(p+"l9"), 1, false)
+				return err
+			},
+			write: "l5",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			setup, err := db.CreateTransaction(ctx)
+			if err != nil {
+				t.Fatalf("CreateTransaction(): %v", err)
+			}
+			setup.Set([]byte(p+"l1"), []byte("1"))
+			if err := setup.Commit(); err != nil {
+				t.Fatalf("Commit(setup): %v", err)
+			}
+
+			tr, err := db.CreateTransaction(ctx)
+			if err != nil {
+				t.Fatalf("CreateTransaction(): %v", err)
+			}
+			if err := test.read(tr); err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			other, err := db.CreateTransaction(ctx)
+			if err != nil {
+				t.Fatalf("CreateTransaction(): %v", err)
+			}
+			other.Set([]byte(p+test.write), []byte("x"))
+			if err := other.Commit(); err != nil {
+				t.Fatalf("Commit(other): %v", err)
+			}
+
+			tr.Set([]byte(p+"out"), []byte(test.desc))
+			err = tr.Commit()
+			if got := err == errConflict; got != test.wantConflict {
+				t.Fatalf("Commit()=%v, want conflict: %v", err, test.wantConflict)
+			}
+			if err != nil {
+				if err := tr.OnError(err); err != nil {
+					t.Errorf("OnError()=%v, want nil", err)
+				}
+			}
+		})
+	}
+}
+
+func TestAdminStorage(t *testing.T) {
+	tester := &testonly.AdminStorageTester{NewAdminStorage: func() storage.AdminStorage {
+		return fdb.NewAdminStorage(NewDatabase(client), newPrefix())
+	}}
+	tester.RunAllTests(t)
+}
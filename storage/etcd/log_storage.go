@@ -0,0 +1,104 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/fdb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewLogStorage returns a storage.LogStorage which stores logs of up to
+// maxTreeSize leaves in db, under prefix. Leaves can't be queued or added to
+// logs which are full, and no more than maxTreeSize leaves are sequenced.
+func NewLogStorage(db fdb.Database, prefix []byte, maxTreeSize int64, mf monitoring.MetricFactory) storage.LogStorage {
+	return &boundedLogStorage{LogStorage: fdb.NewLogStorage(db, prefix, mf), maxTreeSize: maxTreeSize}
+}
+
+type boundedLogStorage struct {
+	storage.LogStorage
+	maxTreeSize int64
+}
+
+// leafCount returns the number of leaves sequenced in a tree.
+func (s *boundedLogStorage) leafCount(ctx context.Context, tree *trillian.Tree) (int64, error) {
+	tx, err := s.SnapshotForTree(ctx, tree)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return 0, err
+	}
+	defer tx.Close()
+	count, err := tx.GetSequencedLeafCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return count, tx.Commit(ctx)
+}
+
+func (s *boundedLogStorage) treeFull(treeID int64) error {
+	return status.Errorf(codes.ResourceExhausted, "tree %d is full, etcd storage holds no more than %d leaves per tree", treeID, s.maxTreeSize)
+}
+
+func (s *boundedLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	return s.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return f(ctx, &boundedLogTX{LogTreeTX: tx, maxTreeSize: s.maxTreeSize})
+	})
+}
+
+func (s *boundedLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	count, err := s.leafCount(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	if count+int64(len(leaves)) > s.maxTreeSize {
+		return nil, s.treeFull(tree.TreeId)
+	}
+	return s.LogStorage.QueueLeaves(ctx, tree, leaves, queueTimestamp)
+}
+
+func (s *boundedLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	for _, leaf := range leaves {
+		if leaf.LeafIndex >= s.maxTreeSize {
+			return nil, s.treeFull(tree.TreeId)
+		}
+	}
+	return s.LogStorage.AddSequencedLeaves(ctx, tree, leaves, timestamp)
+}
+
+// boundedLogTX is a storage.LogTreeTX which dequeues no more leaves than fit
+// in the tree.
+type boundedLogTX struct {
+	storage.LogTreeTX
+	maxTreeSize int64
+}
+
+func (t *boundedLogTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	count, err := t.GetSequencedLeafCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if free := t.maxTreeSize - count; int64(limit) > free {
+		limit = int(free)
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+	return t.LogTreeTX.DequeueLeaves(ctx, limit, cutoffTime)
+}
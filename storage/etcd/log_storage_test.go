@@ -0,0 +1,124 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/fdb"
+	storageto "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func createLeaves(n, start int64) []*trillian.LogLeaf {
+	var leaves []*trillian.LogLeaf
+	for i := start; i < start+n; i++ {
+		value := []byte(fmt.Sprintf("Leaf %d", i))
+		hash := sha256.Sum256(value)
+		leaves = append(leaves, &trillian.LogLeaf{
+			LeafIdentityHash: hash[:],
+			MerkleLeafHash:   hash[:],
+			LeafValue:        value,
+			LeafIndex:        i,
+		})
+	}
+	return leaves
+}
+
+func storeRoot(ctx context.Context, tx storage.LogTreeTX, treeSize uint64) error {
+	signer := tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notnil")), crypto.SHA256)
+	rev, err := tx.WriteRevision(ctx)
+	if err != nil {
+		return err
+	}
+	root, err := signer.SignLogRoot(&types.LogRootV1{TreeSize: treeSize, RootHash: []byte{0}, Revision: uint64(rev)})
+	if err != nil {
+		return err
+	}
+	return tx.StoreSignedLogRoot(ctx, root)
+}
+
+func TestBoundedLogStorage(t *testing.T) {
+	ctx := context.Background()
+	db := NewDatabase(client)
+	prefix := newPrefix()
+	logTree, err := storage.CreateTree(ctx, fdb.NewAdminStorage(db, prefix), storageto.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	s := NewLogStorage(db, prefix, 4, nil)
+
+	if err := s.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return storeRoot(ctx, tx, 0)
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(init): %v", err)
+	}
+	if _, err := s.QueueLeaves(ctx, logTree, createLeaves(5, 0), time.Unix(10, 0)); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("QueueLeaves(5 leaves)=%v, want code %v", err, codes.ResourceExhausted)
+	}
+	if _, err := s.QueueLeaves(ctx, logTree, createLeaves(3, 0), time.Unix(10, 0)); err != nil {
+		t.Fatalf("QueueLeaves(3 leaves): %v", err)
+	}
+	if _, err := s.AddSequencedLeaves(ctx, logTree, createLeaves(1, 4), time.Unix(10, 0)); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("AddSequencedLeaves(index 4)=%v, want code %v", err, codes.ResourceExhausted)
+	}
+	added := createLeaves(2, 10)
+	for i, leaf := range added {
+		leaf.LeafIndex = int64(i)
+	}
+	if _, err := s.AddSequencedLeaves(ctx, logTree, added, time.Unix(10, 0)); err != nil {
+		t.Fatalf("AddSequencedLeaves(indices 0-1): %v", err)
+	}
+
+	// Only the 2 queued leaves which fit in the tree are dequeued.
+	if err := s.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dequeued, err := tx.DequeueLeaves(ctx, 10, time.Unix(20, 0))
+		if err != nil {
+			return err
+		}
+		if got, want := len(dequeued), 2; got != want {
+			t.Errorf("DequeueLeaves() returned %d leaves, want %d", got, want)
+		}
+		for i, leaf := range dequeued {
+			leaf.LeafIndex = int64(len(added) + i)
+		}
+		return tx.UpdateSequencedLeaves(ctx, dequeued)
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(sequence): %v", err)
+	}
+	if err := s.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dequeued, err := tx.DequeueLeaves(ctx, 10, time.Unix(20, 0))
+		if err == nil && len(dequeued) != 0 {
+			t.Errorf("DequeueLeaves(full tree) returned %d leaves, want none", len(dequeued))
+		}
+		return err
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(dequeue): %v", err)
+	}
+	if _, err := s.QueueLeaves(ctx, logTree, createLeaves(1, 20), time.Unix(10, 0)); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("QueueLeaves(full tree)=%v, want code %v", err, codes.ResourceExhausted)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	quotaetcd "github.com/google/trillian/quota/etcd"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/fdb"
+	"go.etcd.io/etcd/clientv3"
+)
+
+var (
+	keyPrefix   = flag.String("etcd_storage_key_prefix", "trillian/storage/", "Prefix of all of the etcd keys written by the etcd storage")
+	maxTreeSize = flag.Int64("etcd_storage_max_tree_size", 1<<16, "Maximum number of leaves of a log stored in etcd")
+
+	etcdOnce     sync.Once
+	etcdOnceErr  error
+	etcdInstance *etcdProvider
+)
+
+func init() {
+	if err := storage.RegisterProvider("etcd", newEtcdProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider etcd: %v", err)
+	}
+}
+
+type etcdProvider struct {
+	client *clientv3.Client
+	db     fdb.Database
+	mf     monitoring.MetricFactory
+}
+
+func newEtcdProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+	etcdOnce.Do(func() {
+		if *quotaetcd.Servers == "" {
+			etcdOnceErr = fmt.Errorf("can't use etcd storage - etcd_servers flag is unset")
+			return
+		}
+		var client *clientv3.Client
+		client, etcdOnceErr = clientv3.New(clientv3.Config{
+			Endpoints:   strings.Split(*quotaetcd.Servers, ","),
+			DialTimeout: 5 * time.Second,
+		})
+		if etcdOnceErr != nil {
+			etcdOnceErr = fmt.Errorf("failed to connect to etcd at %v: %v", *quotaetcd.Servers, etcdOnceErr)
+			return
+		}
+		etcdInstance = &etcdProvider{client: client, db: NewDatabase(client), mf: mf}
+	})
+	if etcdOnceErr != nil {
+		return nil, etcdOnceErr
+	}
+	return etcdInstance, nil
+}
+
+func (s *etcdProvider) LogStorage() storage.LogStorage {
+	return NewLogStorage(s.db, []byte(*keyPrefix), *maxTreeSize, s.mf)
+}
+
+func (s *etcdProvider) MapStorage() storage.MapStorage {
+	return nil
+}
+
+func (s *etcdProvider) AdminStorage() storage.AdminStorage {
+	return fdb.NewAdminStorage(s.db, []byte(*keyPrefix))
+}
+
+func (s *etcdProvider) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,296 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewAdminStorage returns a storage.AdminStorage implementation backed by db,
+// which stores its keys under prefix.
+func NewAdminStorage(db Database, prefix []byte) storage.AdminStorage {
+	return &fdbAdminStorage{db: db, keys: keys{prefix: prefix}}
+}
+
+type fdbAdminStorage struct {
+	db   Database
+	keys keys
+}
+
+func (s *fdbAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	tr, err := s.db.CreateTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &adminTX{tr: tr, keys: s.keys}, nil
+}
+
+func (s *fdbAdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	return runTX(ctx, s.db, func(tr Transaction) error {
+		return f(ctx, &adminTX{tr: tr, keys: s.keys, inRunTX: true})
+	})
+}
+
+func (s *fdbAdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return runTX(ctx, s.db, func(tr Transaction) error {
+		_, err := tr.Get(s.keys.key())
+		return err
+	})
+}
+
+type adminTX struct {
+	tr   Transaction
+	keys keys
+	// inRunTX is set for transactions run by runTX, which commits them.
+	inRunTX bool
+
+	// mu guards closed.
+	mu     sync.Mutex
+	closed bool
+}
+
+func (t *adminTX) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	if t.inRunTX {
+		return nil
+	}
+	return t.tr.Commit()
+}
+
+func (t *adminTX) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	if !t.inRunTX {
+		t.tr.Cancel()
+	}
+	return nil
+}
+
+func (t *adminTX) IsClosed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closed
+}
+
+func (t *adminTX) Close() error {
+	if !t.IsClosed() {
+		err := t.Rollback()
+		if err != nil {
+			glog.Warningf("Rollback error on Close(): %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	v, err := t.tr.Get(t.keys.tree(treeID))
+	if err != nil {
+		return nil, fmt.Errorf("error reading tree %v: %v", treeID, err)
+	}
+	if v == nil {
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", treeID)
+	}
+	var tree trillian.Tree
+	if err := proto.Unmarshal(v, &tree); err != nil {
+		return nil, fmt.Errorf("error unmarshaling tree %v: %v", treeID, err)
+	}
+	return &tree, nil
+}
+
+func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	trees, err := t.ListTrees(ctx, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(trees))
+	for _, tree := range trees {
+		ids = append(ids, tree.TreeId)
+	}
+	return ids, nil
+}
+
+func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	begin, end := t.keys.trees()
+	kvs, err := t.tr.GetRange(begin, end, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	trees := []*trillian.Tree{}
+	for _, kv := range kvs {
+		var tree trillian.Tree
+		if err := proto.Unmarshal(kv.Value, &tree); err != nil {
+			return nil, fmt.Errorf("error unmarshaling tree at %x: %v", kv.Key, err)
+		}
+		if tree.Deleted && !includeDeleted {
+			continue
+		}
+		trees = append(trees, &tree)
+	}
+	sort.Slice(trees, func(i, j int) bool { return trees[i].TreeId < trees[j].TreeId })
+	return trees, nil
+}
+
+func (t *adminTX) putTree(tree *trillian.Tree) error {
+	v, err := proto.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("could not marshal tree %v: %v", tree.TreeId, err)
+	}
+	t.tr.Set(t.keys.tree(tree.TreeId), v)
+	return nil
+}
+
+func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	if err := storage.ValidateTreeForCreation(ctx, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	id, err := storage.TreeIDForCreation(tree)
+	if err != nil {
+		return nil, err
+	}
+	switch v, err := t.tr.Get(t.keys.tree(id)); {
+	case err != nil:
+		return nil, err
+	case v != nil:
+		return nil, status.Errorf(codes.AlreadyExists, "tree %v already exists", id)
+	}
+	if reqID := tree.CreateRequestId; reqID != "" {
+		// Request IDs aren't indexed, so this lists the trees.
+		switch _, err := storage.FindTreeByCreateRequestID(ctx, t, reqID); status.Code(err) {
+		case codes.OK:
+			return nil, status.Errorf(codes.AlreadyExists, "a tree created with request ID %q already exists", reqID)
+		case codes.NotFound:
+		default:
+			return nil, err
+		}
+	}
+
+	now, err := ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build create time: %v", err)
+	}
+	newTree := proto.Clone(tree).(*trillian.Tree)
+	newTree.TreeId = id
+	newTree.CreateTime = now
+	newTree.UpdateTime = now
+	if err := t.putTree(newTree); err != nil {
+		return nil, err
+	}
+	return newTree, nil
+}
+
+func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	tree, err := t.GetTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeUpdate := proto.Clone(tree).(*trillian.Tree)
+	updateFunc(tree)
+	if err := storage.ValidateTreeForUpdate(ctx, beforeUpdate, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	tree.UpdateTime, err = ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update time: %v", err)
+	}
+	if err := t.putTree(tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func (t *adminTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	tree, err := t.getTreeForDelete(ctx, treeID, false /* wantDeleted */)
+	if err != nil {
+		return nil, err
+	}
+	tree.Deleted = true
+	if tree.DeleteTime, err = ptypes.TimestampProto(time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to build delete time: %v", err)
+	}
+	if err := t.putTree(tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func (t *adminTX) UndeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	tree, err := t.getTreeForDelete(ctx, treeID, true /* wantDeleted */)
+	if err != nil {
+		return nil, err
+	}
+	tree.Deleted = false
+	tree.DeleteTime = nil
+	if err := t.putTree(tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
+	if _, err := t.getTreeForDelete(ctx, treeID, true /* wantDeleted */); err != nil {
+		return err
+	}
+	t.tr.ClearRange(t.keys.treeData(treeID))
+	t.tr.Clear(t.keys.tree(treeID))
+	return nil
+}
+
+// getTreeForDelete returns the tree, or an error if its deleted state isn't
+// wantDeleted.
+func (t *adminTX) getTreeForDelete(ctx context.Context, treeID int64, wantDeleted bool) (*trillian.Tree, error) {
+	tree, err := t.GetTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case wantDeleted && !tree.Deleted:
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %v is not soft deleted", treeID)
+	case !wantDeleted && tree.Deleted:
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %v already soft deleted", treeID)
+	}
+	return tree, nil
+}
+
+func validateStorageSettings(tree *trillian.Tree) error {
+	if tree.StorageSettings != nil {
+		return fmt.Errorf("storage_settings not supported, but got %v", tree.StorageSettings)
+	}
+	return nil
+}
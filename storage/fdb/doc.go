@@ -0,0 +1,49 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fdb provides an implementation of the log and admin storage
+// interfaces on FoundationDB's ordered key-value store.
+//
+// All keys live under a configurable prefix. Tree metadata is stored under
+//
+//	<prefix> 't' <tree ID>
+//
+// and the data of each tree under
+//
+//	<prefix> 'd' <tree ID> <tag> ...
+//
+// so a tree is hard-deleted by clearing a single key range. The tags are:
+//
+//	'r' <revision>                          the signed root of the revision
+//	'e'                                     the highest signer epoch of the roots
+//	's' <len> <subtree ID> <revision>       a subtree, as written at revision
+//	'i' <leaf identity hash>                a leaf which has been queued or added
+//	'q' <queue timestamp> <identity hash>   an unsequenced leaf
+//	'l' <sequence number>                   a sequenced leaf
+//	'm' <Merkle leaf hash> <sequence number> an index of sequenced leaves by hash
+//
+// Integers are encoded big-endian, so that they sort numerically, and values
+// are marshalled protos. Reading a subtree or root at a revision is a reverse
+// range read limited to one key.
+//
+// FoundationDB transactions are strictly serializable, so concurrent writers
+// to a tree conflict and are retried rather than needing locks or epochs to
+// keep sequencing safe. Transactions can't run for more than 5 seconds nor
+// write more than 10MB, which bounds the size of sequencing batches.
+//
+// The storage is written against the Database interface, which other ordered
+// key-value stores can implement too. The FoundationDB client is adapted to it,
+// and the "fdb" storage provider registered, when building with the fdb build
+// tag; this requires the FoundationDB client library and its Go bindings.
+package fdb
@@ -0,0 +1,142 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fdb
+
+import (
+	"context"
+	"encoding/binary"
+)
+
+// KeyValue is a key and its value.
+type KeyValue struct {
+	Key   []byte
+	Value []byte
+}
+
+// Database is an ordered key-value store with serializable transactions, such
+// as FoundationDB.
+type Database interface {
+	// CreateTransaction starts a new transaction, whose requests are made
+	// with ctx, if the database supports it.
+	CreateTransaction(ctx context.Context) (Transaction, error)
+}
+
+// Transaction is a transaction of a Database. Reads see the writes made
+// earlier in the transaction. Nothing is written until Commit succeeds.
+type Transaction interface {
+	// Get returns the value of key, or nil if it isn't set.
+	Get(key []byte) ([]byte, error)
+	// GetRange returns the keys in [begin, end) and their values, in
+	// ascending order of key or in descending order if reverse is set,
+	// returning no more than limit keys if limit is positive.
+	GetRange(begin, end []byte, limit int, reverse bool) ([]KeyValue, error)
+	// Set sets the value of key.
+	Set(key, value []byte)
+	// Clear removes key.
+	Clear(key []byte)
+	// ClearRange removes the keys in [begin, end).
+	ClearRange(begin, end []byte)
+	// Commit commits the transaction.
+	Commit() error
+	// OnError returns nil if err is a retryable error of the transaction,
+	// after resetting the transaction so that it can be run again, possibly
+	// after a backoff. Otherwise it returns err, or another error.
+	OnError(err error) error
+	// Cancel discards the transaction.
+	Cancel()
+}
+
+// runTX runs f in a transaction, which is committed once f returns, and
+// retried from the start while it fails with retryable errors.
+func runTX(ctx context.Context, db Database, f func(tr Transaction) error) error {
+	tr, err := db.CreateTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		err := f(tr)
+		if err == nil {
+			err = tr.Commit()
+		}
+		if err == nil {
+			return nil
+		}
+		if err := tr.OnError(err); err != nil {
+			tr.Cancel()
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			tr.Cancel()
+			return err
+		}
+	}
+}
+
+// keys builds the keys of the storage, which all start with prefix.
+type keys struct {
+	prefix []byte
+}
+
+func (k keys) key(parts ...[]byte) []byte {
+	n := len(k.prefix)
+	for _, p := range parts {
+		n += len(p)
+	}
+	ret := make([]byte, 0, n)
+	ret = append(ret, k.prefix...)
+	for _, p := range parts {
+		ret = append(ret, p...)
+	}
+	return ret
+}
+
+// tree returns the key of the metadata of a tree.
+func (k keys) tree(treeID int64) []byte {
+	return k.key([]byte{'t'}, uint64Bytes(uint64(treeID)))
+}
+
+// trees returns the range of the keys of the metadata of all trees.
+func (k keys) trees() ([]byte, []byte) {
+	return k.key([]byte{'t'}), k.key([]byte{'t' + 1})
+}
+
+// data returns a key of the data of a tree.
+func (k keys) data(treeID int64, tag byte, parts ...[]byte) []byte {
+	return k.key(append([][]byte{{'d'}, uint64Bytes(uint64(treeID)), {tag}}, parts...)...)
+}
+
+// treeData returns the range of the keys of all the data of a tree.
+func (k keys) treeData(treeID int64) ([]byte, []byte) {
+	return k.key([]byte{'d'}, uint64Bytes(uint64(treeID))), k.key([]byte{'d'}, uint64Bytes(uint64(treeID)+1))
+}
+
+// prefixEnd returns the first key after all the keys starting with prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] != 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// FoundationDB reserves the keys starting with 0xff for itself.
+	return []byte{0xff}
+}
+
+func uint64Bytes(i uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], i)
+	return b[:]
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+)
+
+var errConflict = errors.New("transaction conflict")
+
+// memDB is an in-memory Database. Transactions work on a copy of the
+// database, and fail to commit with errConflict if another transaction has
+// committed since they started.
+type memDB struct {
+	mu      sync.Mutex
+	data    map[string][]byte
+	version int
+}
+
+func newMemDB() *memDB {
+	return &memDB{data: make(map[string][]byte)}
+}
+
+func (db *memDB) CreateTransaction(context.Context) (Transaction, error) {
+	tr := &memTX{db: db}
+	tr.reset()
+	return tr, nil
+}
+
+type memTX struct {
+	db      *memDB
+	data    map[string][]byte
+	version int
+}
+
+func (t *memTX) reset() {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.data = make(map[string][]byte, len(t.db.data))
+	for k, v := range t.db.data {
+		t.data[k] = v
+	}
+	t.version = t.db.version
+}
+
+func (t *memTX) Get(key []byte) ([]byte, error) {
+	return t.data[string(key)], nil
+}
+
+func (t *memTX) GetRange(begin, end []byte, limit int, reverse bool) ([]KeyValue, error) {
+	var ret []KeyValue
+	for k, v := range t.data {
+		if k >= string(begin) && k < string(end) {
+			ret = append(ret, KeyValue{Key: []byte(k), Value: v})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return (bytes.Compare(ret[i].Key, ret[j].Key) < 0) != reverse
+	})
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret, nil
+}
+
+func (t *memTX) Set(key, value []byte) {
+	if value == nil {
+		value = []byte{}
+	}
+	t.data[string(key)] = value
+}
+
+func (t *memTX) Clear(key []byte) {
+	delete(t.data, string(key))
+}
+
+func (t *memTX) ClearRange(begin, end []byte) {
+	for k := range t.data {
+		if k >= string(begin) && k < string(end) {
+			delete(t.data, k)
+		}
+	}
+}
+
+func (t *memTX) Commit() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	if t.version != t.db.version {
+		return errConflict
+	}
+	t.db.data = t.data
+	t.db.version++
+	t.data = nil
+	return nil
+}
+
+func (t *memTX) OnError(err error) error {
+	if err != errConflict {
+		return err
+	}
+	t.reset()
+	return nil
+}
+
+func (t *memTX) Cancel() {
+	t.data = nil
+}
+
+func TestRunTX(t *testing.T) {
+	ctx := context.Background()
+	db := newMemDB()
+	errFailed := errors.New("failed")
+	key := []byte("k")
+
+	calls := 0
+	if err := runTX(ctx, db, func(tr Transaction) error {
+		calls++
+		if calls == 1 {
+			// Commit a conflicting transaction.
+			if err := runTX(ctx, db, func(tr Transaction) error {
+				tr.Set(key, []byte("other"))
+				return nil
+			}); err != nil {
+				t.Fatalf("runTX(): %v", err)
+			}
+		}
+		v, err := tr.Get(key)
+		if err != nil {
+			return err
+		}
+		tr.Set(key, append(v, '!'))
+		return nil
+	}); err != nil {
+		t.Fatalf("runTX(): %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("runTX() called f %d times, want 2", calls)
+	}
+	if got, want := string(db.data["k"]), "other!"; got != want {
+		t.Errorf("value=%q, want %q", got, want)
+	}
+
+	if err := runTX(ctx, db, func(tr Transaction) error {
+		tr.Set(key, []byte("discarded"))
+		return errFailed
+	}); err != errFailed {
+		t.Errorf("runTX()=%v, want %v", err, errFailed)
+	}
+	if got, want := string(db.data["k"]), "other!"; got != want {
+		t.Errorf("value=%q after failed transaction, want %q", got, want)
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	for _, test := range []struct {
+		prefix, want []byte
+	}{
+		{prefix: []byte("a"), want: []byte("b")},
+		{prefix: []byte{1, 0xff}, want: []byte{2}},
+		{prefix: []byte{0xff}, want: []byte{0xff}},
+	} {
+		if got := prefixEnd(test.prefix); !bytes.Equal(got, test.want) {
+			t.Errorf("prefixEnd(%x)=%x, want %x", test.prefix, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,674 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const logIDLabel = "logid"
+
+var (
+	defaultLogStrata = []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8}
+
+	once             sync.Once
+	queuedCounter    monitoring.Counter
+	queuedDupCounter monitoring.Counter
+	dequeuedCounter  monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	queuedCounter = mf.NewCounter("fdb_queued_leaves", "Number of leaves queued", logIDLabel)
+	queuedDupCounter = mf.NewCounter("fdb_queued_dup_leaves", "Number of duplicate leaves queued", logIDLabel)
+	dequeuedCounter = mf.NewCounter("fdb_dequeued_leaves", "Number of leaves dequeued", logIDLabel)
+}
+
+func labelForTX(t *logTreeTX) string {
+	return strconv.FormatInt(t.treeID, 10)
+}
+
+type fdbLogStorage struct {
+	db            Database
+	keys          keys
+	metricFactory monitoring.MetricFactory
+}
+
+// NewLogStorage returns a storage.LogStorage implementation backed by db,
+// which stores its keys under prefix.
+func NewLogStorage(db Database, prefix []byte, mf monitoring.MetricFactory) storage.LogStorage {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &fdbLogStorage{
+		db:            db,
+		keys:          keys{prefix: prefix},
+		metricFactory: mf,
+	}
+}
+
+func (m *fdbLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return runTX(ctx, m.db, func(tr Transaction) error {
+		_, err := tr.Get(m.keys.key())
+		return err
+	})
+}
+
+func (m *fdbLogStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
+	tr, err := m.db.CreateTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyLogTX{tr: tr, keys: m.keys}, nil
+}
+
+type readOnlyLogTX struct {
+	tr   Transaction
+	keys keys
+}
+
+func (t *readOnlyLogTX) Commit(context.Context) error {
+	return t.tr.Commit()
+}
+
+func (t *readOnlyLogTX) Rollback() error {
+	t.tr.Cancel()
+	return nil
+}
+
+func (t *readOnlyLogTX) Close() error {
+	return t.Rollback()
+}
+
+func (t *readOnlyLogTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	return getActiveLogIDs(t.tr, t.keys)
+}
+
+// getActiveLogIDs returns the IDs of all logs that are currently in a state
+// that requires sequencing (e.g. ACTIVE, DRAINING).
+func getActiveLogIDs(tr Transaction, k keys) ([]int64, error) {
+	begin, end := k.trees()
+	kvs, err := tr.GetRange(begin, end, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for _, kv := range kvs {
+		var tree trillian.Tree
+		if err := proto.Unmarshal(kv.Value, &tree); err != nil {
+			return nil, fmt.Errorf("error unmarshaling tree at %x: %v", kv.Key, err)
+		}
+		if tree.Deleted {
+			continue
+		}
+		switch tree.TreeType {
+		case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
+			switch tree.TreeState {
+			case trillian.TreeState_ACTIVE, trillian.TreeState_DRAINING:
+				ids = append(ids, tree.TreeId)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// newLogTreeTX returns a transaction on tree, which doesn't know the root of
+// the tree. Its writes only depend on the leaves it reads, so it doesn't
+// conflict with transactions which write roots.
+func (m *fdbLogStorage) newLogTreeTX(tr Transaction, tree *trillian.Tree, hasher hashers.LogHasher, inRunTX bool) *logTreeTX {
+	once.Do(func() {
+		createMetrics(m.metricFactory)
+	})
+	return &logTreeTX{
+		treeTX: treeTX{
+			tr:            tr,
+			keys:          m.keys,
+			treeID:        tree.TreeId,
+			hashSizeBytes: hasher.Size(),
+			subtreeCache:  cache.NewLogSubtreeCache(defaultLogStrata, hasher),
+			writeRevision: -1,
+			inRunTX:       inRunTX,
+		},
+		treeType: tree.TreeType,
+		dequeued: make(map[string]dequeuedLeaf),
+	}
+}
+
+func (m *fdbLogStorage) beginInternal(ctx context.Context, tr Transaction, tree *trillian.Tree, inRunTX bool) (*logTreeTX, error) {
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+	ltx := m.newLogTreeTX(tr, tree, hasher, inRunTX)
+	ltx.slr, err = ltx.fetchLatestRoot(ctx)
+	if err == storage.ErrTreeNeedsInit {
+		ltx.treeTX.writeRevision = 0
+		return ltx, err
+	} else if err != nil {
+		return nil, err
+	}
+	if err := ltx.root.UnmarshalBinary(ltx.slr.LogRoot); err != nil {
+		return nil, err
+	}
+	ltx.treeTX.writeRevision = int64(ltx.root.Revision) + 1
+	return ltx, nil
+}
+
+func (m *fdbLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	return runTX(ctx, m.db, func(tr Transaction) error {
+		tx, err := m.beginInternal(ctx, tr, tree, true /* inRunTX */)
+		if err != nil && err != storage.ErrTreeNeedsInit {
+			return err
+		}
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+}
+
+func (m *fdbLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	tr, err := m.db.CreateTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := m.beginInternal(ctx, tr, tree, false /* inRunTX */)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		tr.Cancel()
+		return nil, err
+	}
+	return tx, err
+}
+
+func (m *fdbLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+	var existing []*trillian.LogLeaf
+	if err := runTX(ctx, m.db, func(tr Transaction) error {
+		var err error
+		existing, err = m.newLogTreeTX(tr, tree, hasher, true /* inRunTX */).QueueLeaves(ctx, leaves, queueTimestamp)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*trillian.QueuedLogLeaf, len(leaves))
+	for i, e := range existing {
+		if e != nil {
+			ret[i] = &trillian.QueuedLogLeaf{
+				Leaf:   e,
+				Status: status.Newf(codes.AlreadyExists, "leaf already exists: %v", e.LeafIdentityHash).Proto(),
+			}
+			continue
+		}
+		ret[i] = &trillian.QueuedLogLeaf{Leaf: leaves[i]}
+	}
+	return ret, nil
+}
+
+func (m *fdbLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+	var res []*trillian.QueuedLogLeaf
+	if err := runTX(ctx, m.db, func(tr Transaction) error {
+		var err error
+		res, err = m.newLogTreeTX(tr, tree, hasher, true /* inRunTX */).AddSequencedLeaves(ctx, leaves, timestamp)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// dequeuedLeaf is a leaf returned by DequeueLeaves, and its key in the queue.
+type dequeuedLeaf struct {
+	key  []byte
+	leaf *trillian.LogLeaf
+}
+
+type logTreeTX struct {
+	treeTX
+	treeType trillian.TreeType
+	root     types.LogRootV1
+	slr      *trillian.SignedLogRoot
+	// dequeued holds the leaves returned by DequeueLeaves, by identity hash.
+	dequeued map[string]dequeuedLeaf
+}
+
+func (t *logTreeTX) leafDataKey(leafIdentityHash []byte) []byte {
+	return t.keys.data(t.treeID, 'i', leafIdentityHash)
+}
+
+func (t *logTreeTX) queueKey(queueTimestamp int64, leafIdentityHash []byte) []byte {
+	return t.keys.data(t.treeID, 'q', uint64Bytes(uint64(queueTimestamp)), leafIdentityHash)
+}
+
+func (t *logTreeTX) sequencedKey(index int64) []byte {
+	return t.keys.data(t.treeID, 'l', uint64Bytes(uint64(index)))
+}
+
+func (t *logTreeTX) merkleHashKey(merkleLeafHash []byte, index int64) []byte {
+	return t.keys.data(t.treeID, 'm', merkleLeafHash, uint64Bytes(uint64(index)))
+}
+
+func (t *logTreeTX) rootKey(revision int64) []byte {
+	return t.keys.data(t.treeID, 'r', uint64Bytes(uint64(revision)))
+}
+
+func (t *logTreeTX) epochKey() []byte {
+	return t.keys.data(t.treeID, 'e')
+}
+
+func (t *logTreeTX) ReadRevision(ctx context.Context) (int64, error) {
+	return int64(t.root.Revision), nil
+}
+
+func (t *logTreeTX) WriteRevision(ctx context.Context) (int64, error) {
+	if t.treeTX.writeRevision < 0 {
+		return t.treeTX.writeRevision, errors.New("logTreeTX write revision not populated")
+	}
+	return t.treeTX.writeRevision, nil
+}
+
+func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	if t.treeType == trillian.TreeType_PREORDERED_LOG {
+		return t.getLeavesByRange(int64(t.root.TreeSize), int64(limit))
+	}
+
+	// Only the leaves queued before the cutoff are read, which keeps this
+	// transaction from conflicting with the ones queueing new leaves.
+	cutoff := cutoffTime.UnixNano()
+	if cutoff < 0 {
+		return nil, nil
+	}
+	kvs, err := t.tr.GetRange(t.queueKey(0, nil), t.queueKey(cutoff+1, nil), limit, false)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make([]*trillian.LogLeaf, 0, len(kvs))
+	for _, kv := range kvs {
+		var leaf trillian.LogLeaf
+		if err := proto.Unmarshal(kv.Value, &leaf); err != nil {
+			return nil, fmt.Errorf("error unmarshaling queued leaf at %x: %v", kv.Key, err)
+		}
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return nil, errors.New("dequeued a leaf with incorrect hash size")
+		}
+		k := string(leaf.LeafIdentityHash)
+		if _, ok := t.dequeued[k]; ok {
+			// dupe, user probably called DequeueLeaves more than once.
+			continue
+		}
+		t.dequeued[k] = dequeuedLeaf{key: kv.Key, leaf: proto.Clone(&leaf).(*trillian.LogLeaf)}
+		leaves = append(leaves, &leaf)
+	}
+	dequeuedCounter.Add(float64(len(leaves)), labelForTX(t))
+	return leaves, nil
+}
+
+func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.LogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	// Don't accept batches if any of the leaves are invalid.
+	for _, leaf := range leaves {
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return nil, fmt.Errorf("queued leaf must have a leaf ID hash of length %d", t.hashSizeBytes)
+		}
+		var err error
+		leaf.QueueTimestamp, err = ptypes.TimestampProto(queueTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+	}
+	label := labelForTX(t)
+
+	existing := make([]*trillian.LogLeaf, len(leaves))
+	for i, leaf := range leaves {
+		k := t.leafDataKey(leaf.LeafIdentityHash)
+		v, err := t.tr.Get(k)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			var e trillian.LogLeaf
+			if err := proto.Unmarshal(v, &e); err != nil {
+				return nil, fmt.Errorf("error unmarshaling leaf data at %x: %v", k, err)
+			}
+			existing[i] = &e
+			queuedDupCounter.Inc(label)
+			continue
+		}
+		v, err = proto.Marshal(leaf)
+		if err != nil {
+			return nil, err
+		}
+		t.tr.Set(k, v)
+		t.tr.Set(t.queueKey(queueTimestamp.UnixNano(), leaf.LeafIdentityHash), v)
+	}
+	queuedCounter.Add(float64(len(leaves)), label)
+	return existing, nil
+}
+
+func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	qTimestamp, err := ptypes.TimestampProto(timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+	}
+	res := make([]*trillian.QueuedLogLeaf, len(leaves))
+	ok := status.New(codes.OK, "OK").Proto()
+	for i, leaf := range leaves {
+		if got, want := len(leaf.LeafIdentityHash), t.hashSizeBytes; got != want {
+			return nil, status.Errorf(codes.FailedPrecondition, "leaves[%d] has incorrect hash size %d, want %d", i, got, want)
+		}
+		res[i] = &trillian.QueuedLogLeaf{Status: ok}
+
+		dataKey := t.leafDataKey(leaf.LeafIdentityHash)
+		seqKey := t.sequencedKey(leaf.LeafIndex)
+		switch v, err := t.tr.Get(dataKey); {
+		case err != nil:
+			return nil, err
+		case v != nil:
+			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIdentityHash").Proto()
+			continue
+		}
+		switch v, err := t.tr.Get(seqKey); {
+		case err != nil:
+			return nil, err
+		case v != nil:
+			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto()
+			continue
+		}
+
+		stored := proto.Clone(leaf).(*trillian.LogLeaf)
+		stored.QueueTimestamp = qTimestamp
+		v, err := proto.Marshal(stored)
+		if err != nil {
+			return nil, err
+		}
+		t.tr.Set(dataKey, v)
+		t.tr.Set(seqKey, v)
+		t.tr.Set(t.merkleHashKey(leaf.MerkleLeafHash, leaf.LeafIndex), nil)
+	}
+	return res, nil
+}
+
+func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	for _, leaf := range leaves {
+		// This should fail on insert but catch it early
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return errors.New("sequenced leaf has incorrect hash size")
+		}
+		dq, ok := t.dequeued[string(leaf.LeafIdentityHash)]
+		if !ok {
+			return fmt.Errorf("attempting to update leaf that wasn't dequeued. IdentityHash: %x", leaf.LeafIdentityHash)
+		}
+		stored := dq.leaf
+		stored.LeafIndex = leaf.LeafIndex
+		stored.MerkleLeafHash = leaf.MerkleLeafHash
+		stored.IntegrateTimestamp = leaf.IntegrateTimestamp
+		v, err := proto.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		t.tr.Set(t.sequencedKey(leaf.LeafIndex), v)
+		t.tr.Set(t.merkleHashKey(leaf.MerkleLeafHash, leaf.LeafIndex), nil)
+		t.tr.Clear(dq.key)
+	}
+	return nil
+}
+
+func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	kvs, err := t.tr.GetRange(t.sequencedKey(0), prefixEnd(t.keys.data(t.treeID, 'l')), 1, true)
+	if err != nil || len(kvs) == 0 {
+		return 0, err
+	}
+	var leaf trillian.LogLeaf
+	if err := proto.Unmarshal(kvs[0].Value, &leaf); err != nil {
+		return 0, err
+	}
+	return leaf.LeafIndex + 1, nil
+}
+
+func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		for _, leaf := range leaves {
+			if leaf < 0 {
+				return nil, status.Errorf(codes.InvalidArgument, "index %d is < 0", leaf)
+			}
+			if leaf >= treeSize {
+				return nil, status.Errorf(codes.OutOfRange, "invalid leaf index %d, want < TreeSize(%d)", leaf, treeSize)
+			}
+		}
+	}
+	ret := make([]*trillian.LogLeaf, 0, len(leaves))
+	for _, index := range leaves {
+		if index < 0 {
+			continue
+		}
+		leaf, err := t.getSequencedLeaf(t.sequencedKey(index))
+		if err != nil {
+			return nil, err
+		}
+		if leaf != nil {
+			ret = append(ret, leaf)
+		}
+	}
+	if got, want := len(ret), len(leaves); got != want {
+		return nil, status.Errorf(codes.Internal, "len(ret): %d, want %d", got, want)
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+	return t.getLeavesByRange(start, count)
+}
+
+func (t *logTreeTX) getLeavesByRange(start, count int64) ([]*trillian.LogLeaf, error) {
+	if count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+	}
+	if start < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
+	}
+
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		if treeSize <= 0 {
+			return nil, status.Errorf(codes.OutOfRange, "empty tree")
+		} else if start >= treeSize {
+			return nil, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
+		}
+		// Ensure no entries queried/returned beyond the tree.
+		if maxCount := treeSize - start; count > maxCount {
+			count = maxCount
+		}
+	}
+
+	kvs, err := t.tr.GetRange(t.sequencedKey(start), t.sequencedKey(start+count), int(count), false)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*trillian.LogLeaf, 0, len(kvs))
+	for i, kv := range kvs {
+		var leaf trillian.LogLeaf
+		if err := proto.Unmarshal(kv.Value, &leaf); err != nil {
+			return nil, fmt.Errorf("error unmarshaling leaf at %x: %v", kv.Key, err)
+		}
+		if want := start + int64(i); leaf.LeafIndex != want {
+			// Stop at the first gap, which PREORDERED_LOG trees may have
+			// beyond their size.
+			break
+		}
+		ret = append(ret, &leaf)
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	var ret []*trillian.LogLeaf
+	for _, hash := range leafHashes {
+		prefix := t.keys.data(t.treeID, 'm', hash)
+		kvs, err := t.tr.GetRange(prefix, prefixEnd(prefix), 0, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range kvs {
+			if len(kv.Key) != len(prefix)+8 {
+				// The key of another hash with the same prefix.
+				continue
+			}
+			index := int64(binary.BigEndian.Uint64(kv.Key[len(prefix):]))
+			leaf, err := t.getSequencedLeaf(t.sequencedKey(index))
+			if err != nil {
+				return nil, err
+			}
+			if leaf != nil && bytes.Equal(leaf.MerkleLeafHash, hash) {
+				ret = append(ret, leaf)
+			}
+		}
+	}
+	if orderBySequence {
+		sort.Slice(ret, func(i, j int) bool { return ret[i].LeafIndex < ret[j].LeafIndex })
+	}
+	return ret, nil
+}
+
+// getSequencedLeaf returns the sequenced leaf stored at key, or nil if there
+// is none.
+func (t *logTreeTX) getSequencedLeaf(key []byte) (*trillian.LogLeaf, error) {
+	v, err := t.tr.Get(key)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	var leaf trillian.LogLeaf
+	if err := proto.Unmarshal(v, &leaf); err != nil {
+		return nil, fmt.Errorf("error unmarshaling leaf at %x: %v", key, err)
+	}
+	return &leaf, nil
+}
+
+func (t *logTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	if t.slr == nil {
+		return nil, storage.ErrTreeNeedsInit
+	}
+	return t.slr, nil
+}
+
+// fetchLatestRoot reads the root of the latest revision of the tree.
+func (t *logTreeTX) fetchLatestRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	kvs, err := t.tr.GetRange(t.rootKey(0), prefixEnd(t.keys.data(t.treeID, 'r')), 1, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(kvs) == 0 {
+		return nil, storage.ErrTreeNeedsInit
+	}
+	var slr trillian.SignedLogRoot
+	if err := proto.Unmarshal(kvs[0].Value, &slr); err != nil {
+		return nil, fmt.Errorf("error unmarshaling root at %x: %v", kvs[0].Key, err)
+	}
+	return &slr, nil
+}
+
+func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	var logRoot types.LogRootV1
+	if err := logRoot.UnmarshalBinary(root.LogRoot); err != nil {
+		glog.Warningf("Failed to parse log root: %x %v", root.LogRoot, err)
+		return err
+	}
+	if got, want := int64(logRoot.Revision), t.treeTX.writeRevision; got != want {
+		return status.Errorf(codes.Internal, "root.Revision: %v, want %v", got, want)
+	}
+
+	var stored int64
+	v, err := t.tr.Get(t.epochKey())
+	if err != nil {
+		return err
+	}
+	if len(v) == 8 {
+		stored = int64(binary.BigEndian.Uint64(v))
+	}
+	epoch, err := storage.CheckSignerEpoch(ctx, t.treeID, stored)
+	if err != nil {
+		return err
+	}
+
+	k := t.rootKey(int64(logRoot.Revision))
+	switch v, err := t.tr.Get(k); {
+	case err != nil:
+		return err
+	case v != nil:
+		return status.Errorf(codes.AlreadyExists, "tree %d already has a root at revision %d", t.treeID, logRoot.Revision)
+	}
+	v, err = proto.Marshal(root)
+	if err != nil {
+		return err
+	}
+	t.tr.Set(k, v)
+	t.tr.Set(t.epochKey(), uint64Bytes(uint64(epoch)))
+	return nil
+}
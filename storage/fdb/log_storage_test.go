@@ -0,0 +1,277 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fdb
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/storage"
+	storageto "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var prefix = []byte("test/")
+
+func createTree(t *testing.T, db Database, spec *trillian.Tree) *trillian.Tree {
+	t.Helper()
+	tree, err := storage.CreateTree(context.Background(), NewAdminStorage(db, prefix), spec)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	return tree
+}
+
+func createLeaves(n, start int64) []*trillian.LogLeaf {
+	var leaves []*trillian.LogLeaf
+	for i := start; i < start+n; i++ {
+		value := []byte(fmt.Sprintf("Leaf %d", i))
+		hash := sha256.Sum256(value)
+		leaves = append(leaves, &trillian.LogLeaf{
+			LeafIdentityHash: hash[:],
+			MerkleLeafHash:   hash[:],
+			LeafValue:        value,
+			ExtraData:        []byte(fmt.Sprintf("Extra %d", i)),
+			LeafIndex:        i,
+		})
+	}
+	return leaves
+}
+
+func storeRoot(ctx context.Context, tx storage.LogTreeTX, treeSize uint64) error {
+	signer := tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notnil")), crypto.SHA256)
+	rev, err := tx.WriteRevision(ctx)
+	if err != nil {
+		return err
+	}
+	root, err := signer.SignLogRoot(&types.LogRootV1{TreeSize: treeSize, RootHash: []byte{0}, Revision: uint64(rev)})
+	if err != nil {
+		return err
+	}
+	return tx.StoreSignedLogRoot(ctx, root)
+}
+
+func TestLogStorage(t *testing.T) {
+	ctx := context.Background()
+	db := newMemDB()
+	logTree := createTree(t, db, storageto.LogTree)
+	s := NewLogStorage(db, prefix, nil)
+
+	if _, err := s.SnapshotForTree(ctx, logTree); err != storage.ErrTreeNeedsInit {
+		t.Fatalf("SnapshotForTree()=%v, want %v", err, storage.ErrTreeNeedsInit)
+	}
+	if err := s.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return storeRoot(ctx, tx, 0)
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(init): %v", err)
+	}
+
+	leaves := createLeaves(3, 0)
+	queued, err := s.QueueLeaves(ctx, logTree, append(leaves, leaves[0]), time.Unix(10, 0))
+	if err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	for i, q := range queued {
+		want := codes.OK
+		if i == len(leaves) {
+			want = codes.AlreadyExists
+		}
+		if got := status.FromProto(q.Status).Code(); got != want {
+			t.Errorf("QueueLeaves(): leaf %d has status %v, want %v", i, got, want)
+		}
+	}
+
+	nodeID, err := tree.NewNodeIDForTreeCoords(0, 0, 64)
+	if err != nil {
+		t.Fatalf("NewNodeIDForTreeCoords(): %v", err)
+	}
+	if err := s.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if got, err := tx.DequeueLeaves(ctx, 10, time.Unix(5, 0)); err != nil || len(got) != 0 {
+			t.Errorf("DequeueLeaves(before queue time)=%d leaves, %v, want none", len(got), err)
+		}
+		dequeued, err := tx.DequeueLeaves(ctx, 10, time.Unix(20, 0))
+		if err != nil {
+			return err
+		}
+		if got, want := len(dequeued), len(leaves); got != want {
+			t.Fatalf("DequeueLeaves() returned %d leaves, want %d", got, want)
+		}
+		for i, leaf := range dequeued {
+			leaf.LeafIndex = int64(i)
+		}
+		if err := tx.UpdateSequencedLeaves(ctx, dequeued); err != nil {
+			return err
+		}
+		if _, err := tx.GetMerkleNodes(ctx, 0, []tree.NodeID{nodeID}); err != nil {
+			return err
+		}
+		if err := tx.SetMerkleNodes(ctx, []tree.Node{{NodeID: nodeID, Hash: leaves[0].MerkleLeafHash}}); err != nil {
+			return err
+		}
+		return storeRoot(ctx, tx, uint64(len(dequeued)))
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(sequence): %v", err)
+	}
+
+	tx, err := s.SnapshotForTree(ctx, logTree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	if got, err := tx.GetSequencedLeafCount(ctx); err != nil || got != 3 {
+		t.Errorf("GetSequencedLeafCount()=%d, %v, want 3", got, err)
+	}
+	byRange, err := tx.GetLeavesByRange(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	if got, want := len(byRange), len(leaves); got != want {
+		t.Fatalf("GetLeavesByRange() returned %d leaves, want %d", got, want)
+	}
+	for i, leaf := range byRange {
+		if leaf.LeafIndex != int64(i) || !containsValue(leaves, leaf.LeafValue) {
+			t.Errorf("GetLeavesByRange(): leaf %d = %v", i, leaf)
+		}
+	}
+	byIndex, err := tx.GetLeavesByIndex(ctx, []int64{2})
+	if err != nil || len(byIndex) != 1 || !proto.Equal(byIndex[0], byRange[2]) {
+		t.Errorf("GetLeavesByIndex(2)=%v, %v, want %v", byIndex, err, byRange[2])
+	}
+	if _, err := tx.GetLeavesByIndex(ctx, []int64{3}); status.Code(err) != codes.OutOfRange {
+		t.Errorf("GetLeavesByIndex(3)=%v, want code %v", err, codes.OutOfRange)
+	}
+	byHash, err := tx.GetLeavesByHash(ctx, [][]byte{byRange[1].MerkleLeafHash}, false)
+	if err != nil || len(byHash) != 1 || !proto.Equal(byHash[0], byRange[1]) {
+		t.Errorf("GetLeavesByHash()=%v, %v, want %v", byHash, err, byRange[1])
+	}
+	nodes, err := tx.GetMerkleNodes(ctx, 1, []tree.NodeID{nodeID})
+	if err != nil || len(nodes) != 1 || !bytes.Equal(nodes[0].Hash, leaves[0].MerkleLeafHash) {
+		t.Errorf("GetMerkleNodes()=%v, %v, want hash %x", nodes, err, leaves[0].MerkleLeafHash)
+	}
+	root, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		t.Fatalf("LatestSignedLogRoot(): %v", err)
+	}
+	var logRoot types.LogRootV1
+	if err := logRoot.UnmarshalBinary(root.LogRoot); err != nil || logRoot.TreeSize != 3 || logRoot.Revision != 1 {
+		t.Errorf("LatestSignedLogRoot()=%+v, %v, want size 3 at revision 1", logRoot, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Errorf("Commit(): %v", err)
+	}
+
+	// Everything has been sequenced, so the queue is empty.
+	if err := s.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		got, err := tx.DequeueLeaves(ctx, 10, time.Unix(20, 0))
+		if err == nil && len(got) != 0 {
+			t.Errorf("DequeueLeaves() returned %d leaves, want none", len(got))
+		}
+		return err
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(dequeue): %v", err)
+	}
+}
+
+func containsValue(leaves []*trillian.LogLeaf, value []byte) bool {
+	for _, leaf := range leaves {
+		if bytes.Equal(leaf.LeafValue, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAddSequencedLeaves(t *testing.T) {
+	ctx := context.Background()
+	db := newMemDB()
+	logTree := createTree(t, db, storageto.PreorderedLogTree)
+	s := NewLogStorage(db, prefix, nil)
+
+	leaves := createLeaves(3, 0)
+	if _, err := s.AddSequencedLeaves(ctx, logTree, leaves[:2], time.Unix(10, 0)); err != nil {
+		t.Fatalf("AddSequencedLeaves(): %v", err)
+	}
+	conflictingIndex := proto.Clone(leaves[2]).(*trillian.LogLeaf)
+	conflictingIndex.LeafIndex = 1
+	res, err := s.AddSequencedLeaves(ctx, logTree, []*trillian.LogLeaf{leaves[0], conflictingIndex, leaves[2]}, time.Unix(10, 0))
+	if err != nil {
+		t.Fatalf("AddSequencedLeaves(): %v", err)
+	}
+	for i, want := range []codes.Code{codes.FailedPrecondition, codes.FailedPrecondition, codes.OK} {
+		if got := status.FromProto(res[i].Status).Code(); got != want {
+			t.Errorf("AddSequencedLeaves(): leaf %d has status %v, want %v", i, got, want)
+		}
+	}
+
+	if err := s.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if err := storeRoot(ctx, tx, 0); err != nil {
+			return err
+		}
+		got, err := tx.DequeueLeaves(ctx, 10, time.Unix(20, 0))
+		if err == nil && len(got) != len(leaves) {
+			t.Errorf("DequeueLeaves() returned %d leaves, want %d", len(got), len(leaves))
+		}
+		return err
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+}
+
+func TestHardDeleteTreeData(t *testing.T) {
+	ctx := context.Background()
+	db := newMemDB()
+	logTree := createTree(t, db, storageto.LogTree)
+	otherTree := createTree(t, db, storageto.LogTree)
+	s := NewLogStorage(db, prefix, nil)
+	for _, tree := range []*trillian.Tree{logTree, otherTree} {
+		if _, err := s.QueueLeaves(ctx, tree, createLeaves(2, 0), time.Unix(10, 0)); err != nil {
+			t.Fatalf("QueueLeaves(): %v", err)
+		}
+	}
+
+	as := NewAdminStorage(db, prefix)
+	if _, err := storage.SoftDeleteTree(ctx, as, logTree.TreeId); err != nil {
+		t.Fatalf("SoftDeleteTree(): %v", err)
+	}
+	if err := storage.HardDeleteTree(ctx, as, logTree.TreeId); err != nil {
+		t.Fatalf("HardDeleteTree(): %v", err)
+	}
+	for _, test := range []struct {
+		tree *trillian.Tree
+		want bool
+	}{
+		{tree: logTree, want: false},
+		{tree: otherTree, want: true},
+	} {
+		begin, end := keys{prefix: prefix}.treeData(test.tree.TreeId)
+		tr, _ := db.CreateTransaction(ctx)
+		kvs, _ := tr.GetRange(begin, end, 0, false)
+		if got := len(kvs) > 0; got != test.want {
+			t.Errorf("tree %d has data: %v, want %v", test.tree.TreeId, got, test.want)
+		}
+	}
+}
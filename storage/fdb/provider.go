@@ -0,0 +1,149 @@
+// +build fdb
+
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fdb
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"sync"
+
+	"github.com/apple/foundationdb/bindings/go/src/fdb"
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+)
+
+// apiVersion is the version of the FoundationDB client API used.
+const apiVersion = 620
+
+var (
+	clusterFile = flag.String("fdb_cluster_file", "", "Path to the FoundationDB cluster file, the default cluster file is used if empty")
+	keyPrefix   = flag.String("fdb_key_prefix", "trillian/", "Prefix of all of the FoundationDB keys written by Trillian")
+
+	fdbOnce     sync.Once
+	fdbOnceErr  error
+	fdbInstance *fdbProvider
+)
+
+func init() {
+	if err := storage.RegisterProvider("fdb", newFDBProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider fdb: %v", err)
+	}
+}
+
+type fdbProvider struct {
+	db Database
+	mf monitoring.MetricFactory
+}
+
+func newFDBProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+	fdbOnce.Do(func() {
+		if fdbOnceErr = fdb.APIVersion(apiVersion); fdbOnceErr != nil {
+			return
+		}
+		var db fdb.Database
+		if db, fdbOnceErr = fdb.OpenDatabase(*clusterFile); fdbOnceErr != nil {
+			return
+		}
+		fdbInstance = &fdbProvider{db: database{db: db}, mf: mf}
+	})
+	if fdbOnceErr != nil {
+		return nil, fdbOnceErr
+	}
+	return fdbInstance, nil
+}
+
+func (s *fdbProvider) LogStorage() storage.LogStorage {
+	return NewLogStorage(s.db, []byte(*keyPrefix), s.mf)
+}
+
+func (s *fdbProvider) MapStorage() storage.MapStorage {
+	return nil
+}
+
+func (s *fdbProvider) AdminStorage() storage.AdminStorage {
+	return NewAdminStorage(s.db, []byte(*keyPrefix))
+}
+
+func (s *fdbProvider) Close() error {
+	return nil
+}
+
+// database adapts a FoundationDB database to the Database interface.
+type database struct {
+	db fdb.Database
+}
+
+func (d database) CreateTransaction(context.Context) (Transaction, error) {
+	tr, err := d.db.CreateTransaction()
+	if err != nil {
+		return nil, err
+	}
+	return transaction{tr: tr}, nil
+}
+
+type transaction struct {
+	tr fdb.Transaction
+}
+
+func (t transaction) Get(key []byte) ([]byte, error) {
+	return t.tr.Get(fdb.Key(key)).Get()
+}
+
+func (t transaction) GetRange(begin, end []byte, limit int, reverse bool) ([]KeyValue, error) {
+	r := fdb.KeyRange{Begin: fdb.Key(begin), End: fdb.Key(end)}
+	kvs, err := t.tr.GetRange(r, fdb.RangeOptions{Limit: limit, Reverse: reverse}).GetSliceWithError()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		ret = append(ret, KeyValue{Key: kv.Key, Value: kv.Value})
+	}
+	return ret, nil
+}
+
+func (t transaction) Set(key, value []byte) {
+	t.tr.Set(fdb.Key(key), value)
+}
+
+func (t transaction) Clear(key []byte) {
+	t.tr.Clear(fdb.Key(key))
+}
+
+func (t transaction) ClearRange(begin, end []byte) {
+	t.tr.ClearRange(fdb.KeyRange{Begin: fdb.Key(begin), End: fdb.Key(end)})
+}
+
+func (t transaction) Commit() error {
+	return t.tr.Commit().Get()
+}
+
+func (t transaction) OnError(err error) error {
+	var fdbErr fdb.Error
+	if !errors.As(err, &fdbErr) {
+		return err
+	}
+	// OnError waits for a backoff and resets the transaction if fdbErr is
+	// retryable, and fails with it otherwise.
+	return t.tr.OnError(fdbErr).Get()
+}
+
+func (t transaction) Cancel() {
+	t.tr.Cancel()
+}
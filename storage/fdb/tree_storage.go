@@ -0,0 +1,189 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fdb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/tree"
+)
+
+// treeTX is the part of a transaction on a tree which deals with its Merkle
+// nodes.
+type treeTX struct {
+	// mu guards all of the fields below, as the subtree cache isn't safe for
+	// concurrent use.
+	mu sync.Mutex
+
+	tr            Transaction
+	keys          keys
+	treeID        int64
+	hashSizeBytes int
+	subtreeCache  *cache.SubtreeCache
+	writeRevision int64
+	// inRunTX is set for transactions run by runTX, which commits them.
+	inRunTX bool
+	closed  bool
+}
+
+// subtreeKey returns the key of the subtree with the given ID at revision
+// rev.
+func (t *treeTX) subtreeKey(id []byte, rev int64) []byte {
+	return t.keys.data(t.treeID, 's', []byte{byte(len(id))}, id, uint64Bytes(uint64(rev)))
+}
+
+func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, id tree.NodeID2) (*storagepb.SubtreeProto, error) {
+	s, err := t.getSubtrees(ctx, treeRevision, []tree.NodeID2{id})
+	if err != nil {
+		return nil, err
+	}
+	switch len(s) {
+	case 0:
+		return nil, nil
+	case 1:
+		return s[0], nil
+	default:
+		return nil, fmt.Errorf("got %d subtrees, but expected 1", len(s))
+	}
+}
+
+// getSubtrees returns the latest version of each of the subtrees at or below
+// treeRevision. Subtrees which have never been written are skipped.
+func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
+	if treeRevision < 0 {
+		return nil, nil
+	}
+	ret := make([]*storagepb.SubtreeProto, 0, len(ids))
+	for _, id := range ids {
+		tileID, err := tree.NewTileID(id)
+		if err != nil {
+			return nil, err
+		}
+		idBytes := tileID.AsBytes()
+		kvs, err := t.tr.GetRange(t.subtreeKey(idBytes, 0), t.subtreeKey(idBytes, treeRevision+1), 1, true)
+		if err != nil {
+			glog.Warningf("Failed to get merkle subtrees: %s", err)
+			return nil, err
+		}
+		if len(kvs) == 0 {
+			continue
+		}
+		var subtree storagepb.SubtreeProto
+		if err := proto.Unmarshal(kvs[0].Value, &subtree); err != nil {
+			glog.Warningf("Failed to unmarshal SubtreeProto: %s", err)
+			return nil, err
+		}
+		if subtree.Prefix == nil {
+			subtree.Prefix = []byte{}
+		}
+		ret = append(ret, &subtree)
+	}
+
+	// The InternalNodes cache is possibly nil here, but the SubtreeCache (which called
+	// this method) will re-populate it.
+	return ret, nil
+}
+
+func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.SubtreeProto) error {
+	for _, s := range subtrees {
+		if s.Prefix == nil {
+			return fmt.Errorf("nil prefix on %v", s)
+		}
+		v, err := proto.Marshal(s)
+		if err != nil {
+			return err
+		}
+		t.tr.Set(t.subtreeKey(s.Prefix, t.writeRevision), v)
+	}
+	return nil
+}
+
+// getSubtreesAtRev returns a GetSubtreesFunc which reads at the passed in rev.
+func (t *treeTX) getSubtreesAtRev(ctx context.Context, rev int64) cache.GetSubtreesFunc {
+	return func(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
+		return t.getSubtrees(ctx, rev, ids)
+	}
+}
+
+// GetMerkleNodes returns the requests nodes at (or below) the passed in treeRevision.
+func (t *treeTX) GetMerkleNodes(ctx context.Context, treeRevision int64, ids []tree.NodeID) ([]tree.Node, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.subtreeCache.GetNodes(ids, t.getSubtreesAtRev(ctx, treeRevision))
+}
+
+func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, n := range nodes {
+		err := t.subtreeCache.SetNodeHash(n.NodeID, n.Hash,
+			func(id tree.NodeID2) (*storagepb.SubtreeProto, error) {
+				return t.getSubtree(ctx, t.writeRevision, id)
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *treeTX) Commit(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.writeRevision > -1 {
+		if err := t.subtreeCache.Flush(ctx, t.storeSubtrees); err != nil {
+			glog.Warningf("TX commit flush error: %v", err)
+			return err
+		}
+	}
+	t.closed = true
+	if t.inRunTX {
+		return nil
+	}
+	return t.tr.Commit()
+}
+
+func (t *treeTX) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	if !t.inRunTX {
+		t.tr.Cancel()
+	}
+	return nil
+}
+
+func (t *treeTX) Close() error {
+	if t.IsOpen() {
+		if err := t.Rollback(); err != nil {
+			glog.Warningf("Rollback error on Close(): %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *treeTX) IsOpen() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.closed
+}
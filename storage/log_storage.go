@@ -179,3 +179,90 @@ type LogMetadata interface {
 	// configured in storage and are eligible to have entries sequenced.
 	GetActiveLogIDs(ctx context.Context) ([]int64, error)
 }
+
+// LeafStreamer is implemented by ReadOnlyLogTreeTX implementations which can
+// read a range of leaves with a single cursor, rather than a query per batch.
+// Callers should use a type assertion to check whether it is supported.
+type LeafStreamer interface {
+	// StreamLeavesByRange calls fn with each leaf in [start, start+count), in
+	// order of their indices, as they are read, and stops at the first error
+	// returned by fn. Like GetLeavesByRange, it stops early at the end of the
+	// tree, or at the first missing leaf. fn must not use the transaction.
+	StreamLeavesByRange(ctx context.Context, start, count int64, fn func(*trillian.LogLeaf) error) error
+}
+
+// DeadLetterLeaf is a queued leaf which has been quarantined because it
+// repeatedly caused sequencing of its tree to fail.
+type DeadLetterLeaf struct {
+	// Leaf holds the LeafIdentityHash, MerkleLeafHash and QueueTimestamp of
+	// the quarantined leaf.
+	Leaf *trillian.LogLeaf
+	// QuarantineTime is when the leaf was moved out of the queue.
+	QuarantineTime time.Time
+	// Reason describes why the leaf was quarantined.
+	Reason string
+}
+
+// DeadLetterReader is implemented by ReadOnlyLogTreeTX implementations which
+// can list the leaves in the dead-letter store of the tree, see DeadLetterTX.
+// Callers should use a type assertion to check whether it is supported.
+type DeadLetterReader interface {
+	// ListDeadLetterLeaves returns at most limit quarantined leaves of the tree,
+	// ordered by quarantine time.
+	ListDeadLetterLeaves(ctx context.Context, limit int) ([]*DeadLetterLeaf, error)
+}
+
+// DeadLetterTX is implemented by LogTreeTX implementations which can move
+// queued leaves out of the sequencing queue into a per-tree dead-letter store.
+// Callers should use a type assertion to check whether it is supported.
+type DeadLetterTX interface {
+	DeadLetterReader
+
+	// QuarantineLeaves moves leaves previously returned by DequeueLeaves in the
+	// same transaction into the dead-letter store, recording the given reason.
+	// Quarantined leaves are not returned by subsequent DequeueLeaves calls.
+	QuarantineLeaves(ctx context.Context, leaves []*trillian.LogLeaf, reason string, quarantineTime time.Time) error
+
+	// RequeueDeadLetterLeaves moves the quarantined leaves with the given
+	// identity hashes back into the sequencing queue, and returns the number of
+	// leaves requeued. Unknown hashes are ignored.
+	RequeueDeadLetterLeaves(ctx context.Context, leafIdentityHashes [][]byte, queueTimestamp time.Time) (int, error)
+
+	// PurgeDeadLetterLeaves permanently deletes the quarantined leaves with the
+	// given identity hashes, along with their leaf data, and returns the number
+	// of leaves purged. Unknown hashes are ignored.
+	PurgeDeadLetterLeaves(ctx context.Context, leafIdentityHashes [][]byte) (int, error)
+}
+
+// SubtreeVacuumTX is implemented by LogTreeTX implementations which store
+// revisions of subtrees, and can delete the revisions which are no longer
+// needed. Callers should use a type assertion to check whether it is supported.
+type SubtreeVacuumTX interface {
+	// VacuumSubtrees deletes up to limit revisions of subtrees of the tree
+	// which are superseded by a later revision no later than horizon, and so
+	// aren't needed by reads at horizon or any later revision. It returns the
+	// number of revisions deleted, which is less than limit once there are no
+	// more of them.
+	VacuumSubtrees(ctx context.Context, horizon int64, limit int) (int, error)
+}
+
+// TreeStats holds statistics of the data stored for a log.
+type TreeStats struct {
+	// LeafCount is the number of sequenced leaves.
+	LeafCount int64
+	// LeafValueBytes and ExtraDataBytes are the total sizes of the values and
+	// extra data of all leaves stored for the log, whether sequenced, queued
+	// or quarantined.
+	LeafValueBytes int64
+	ExtraDataBytes int64
+	// UnsequencedCount is the number of queued leaves awaiting sequencing.
+	UnsequencedCount int64
+}
+
+// TreeStatsTX is implemented by ReadOnlyLogTreeTX implementations which can
+// report statistics of the log. Callers should use a type assertion to check
+// whether it is supported.
+type TreeStatsTX interface {
+	// GetTreeStats returns the current statistics of the log.
+	GetTreeStats(ctx context.Context) (*TreeStats, error)
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import "context"
+
+type mapWriteBatchKey struct{}
+
+type mapWriteBatch struct {
+	id  string
+	rev int64
+}
+
+// WithMapWriteBatch returns a context carrying the ID of a batch of map writes
+// which spans multiple transactions, all of which write revision rev, and the
+// last of which stores its map root. The map root is the commit marker of
+// the batch: nothing written by the batch is read before it is stored, as
+// readers only read at revisions which have a root.
+//
+// Storage implementations which support batches record the batch which owns
+// the next revision of a map before any of its transactions write to it, and
+// only let transactions of that batch write at the revision. A batch which
+// fails before storing its root can be taken over by a later one, which first
+// removes whatever the failed batch wrote. This makes batches all-or-nothing,
+// even if the transactions writing them are not.
+func WithMapWriteBatch(ctx context.Context, id string, rev int64) context.Context {
+	return context.WithValue(ctx, mapWriteBatchKey{}, mapWriteBatch{id: id, rev: rev})
+}
+
+// MapWriteBatchFromContext returns the ID and revision of the map write batch
+// carried by ctx, if any.
+func MapWriteBatchFromContext(ctx context.Context) (string, int64, bool) {
+	b, ok := ctx.Value(mapWriteBatchKey{}).(mapWriteBatch)
+	return b.id, b.rev, ok
+}
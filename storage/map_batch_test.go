@@ -0,0 +1,31 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMapWriteBatchFromContext(t *testing.T) {
+	ctx := context.Background()
+	if _, _, ok := MapWriteBatchFromContext(ctx); ok {
+		t.Error("MapWriteBatchFromContext(): found a batch in an empty context")
+	}
+	id, rev, ok := MapWriteBatchFromContext(WithMapWriteBatch(ctx, "batch", 5))
+	if !ok || id != "batch" || rev != 5 {
+		t.Errorf("MapWriteBatchFromContext(): %q, %d, %v, want %q, %d, true", id, rev, ok, "batch", 5)
+	}
+}
@@ -15,7 +15,9 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"time"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/merkle/smt"
@@ -52,7 +54,8 @@ type ReadOnlyMapTreeTX interface {
 	// Setting revision to -1 will fetch the latest revision.
 	// The returned array of MapLeaves will only contain entries for which values
 	// exist.  i.e. requesting a set of unknown keys would result in a
-	// zero-length array being returned.
+	// zero-length array being returned. Keys whose latest write at the given
+	// revision is a deletion are unknown too.
 	Get(ctx context.Context, revision int64, keyHashes [][]byte) ([]*trillian.MapLeaf, error)
 
 	// GetTiles reads the Merkle tree tiles with the given root IDs at the given
@@ -71,7 +74,9 @@ type MapTreeTX interface {
 
 	// StoreSignedMapRoot stores root.
 	StoreSignedMapRoot(ctx context.Context, root *trillian.SignedMapRoot) error
-	// Set sets key to leaf
+	// Set sets key to leaf. A leaf for which IsMapLeafDeletion is true
+	// deletes the key instead, and implementations should not store its
+	// contents.
 	// TODO(mhutchinson): Remove the keyHash parameter or document why it is redundantly passed in
 	// (it is also inside the MapLeaf)
 	Set(ctx context.Context, keyHash []byte, value *trillian.MapLeaf) error
@@ -90,6 +95,14 @@ type ReadOnlyMapStorage interface {
 	// without error.
 	SnapshotForTree(ctx context.Context, tree *trillian.Tree) (ReadOnlyMapTreeTX, error)
 
+	// SnapshotAtRevision starts a new read-only transaction pinned to the
+	// given revision, whose SignedMapRoot is read before it returns. Unlike
+	// with SnapshotForTree, whose read revision is set by the first root read,
+	// ReadRevision and LatestSignedMapRoot of the returned transaction always
+	// refer to the pinned revision, so reads spread across multiple calls are
+	// consistent. Returns a NotFound error if the revision has no root.
+	SnapshotAtRevision(ctx context.Context, tree *trillian.Tree, revision int64) (ReadOnlyMapTreeTX, error)
+
 	// Layout returns the layout of the given tree.
 	// TODO(pavelkalinnikov): Return plain data rather than a data structure.
 	// TODO(pavelkalinnikov, v2): Consider moving it to TreeStorage.
@@ -109,3 +122,174 @@ type MapStorage interface {
 	// retry with a new transaction, and f MUST NOT keep state across calls.
 	ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f MapTXFunc) error
 }
+
+// IsMapLeafDeletion returns whether the given leaf, passed in to
+// MapTreeTX.Set, deletes its key, i.e. has no value, extra data nor leaf hash.
+func IsMapLeafDeletion(leaf *trillian.MapLeaf) bool {
+	return len(leaf.LeafValue) == 0 && len(leaf.ExtraData) == 0 && len(leaf.LeafHash) == 0
+}
+
+// MaxMapLeafKeySize is the maximum size of the keys of the leaves of maps with
+// map_key_index set, which storage indexes.
+const MaxMapLeafKeySize = 255
+
+// MapLeafIndexedKey returns the key which the given leaf, written to a map
+// with map_key_index set, is indexed by in storage, or nil if it isn't
+// indexed, as it deletes its key or was written without one.
+func MapLeafIndexedKey(leaf *trillian.MapLeaf) []byte {
+	if IsMapLeafDeletion(leaf) || len(leaf.Key) == 0 {
+		return nil
+	}
+	return leaf.Key
+}
+
+// MapLeafKeyPrefixEnd returns the least key which follows all the keys of up
+// to MaxMapLeafKeySize bytes that start with prefix, which is the exclusive
+// end of the range of indexed keys with the prefix.
+func MapLeafKeyPrefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] != 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return bytes.Repeat([]byte{0xff}, MaxMapLeafKeySize+1)
+}
+
+// MapLeafScanner is implemented by ReadOnlyMapTreeTX implementations which can
+// read all the leaves of a map. Callers should use a type assertion to check
+// whether it is supported.
+type MapLeafScanner interface {
+	// ScanLeaves calls fn with each leaf of the map at the given revision, in
+	// increasing order of their indices, and stops at the first error returned
+	// by fn. Leaves are read in batches, so the map is never held in memory as
+	// a whole. Deleted keys are skipped.
+	ScanLeaves(ctx context.Context, revision int64, fn func(*trillian.MapLeaf) error) error
+}
+
+// MapLeafKeyLister is implemented by ReadOnlyMapTreeTX implementations which
+// index the keys of the leaves of maps with map_key_index set, see
+// MapLeafIndexedKey. Callers should use a type assertion to check whether it
+// is supported.
+type MapLeafKeyLister interface {
+	// ListLeavesByKeyPrefix returns up to limit leaves of the map at the
+	// given revision whose keys start with prefix, in increasing order of
+	// their keys, and then of their indices. If afterIndex is set, only the
+	// leaves which follow the one with key afterKey and index afterIndex in
+	// that order are returned, so that listing continues where an earlier
+	// call stopped. Deleted keys are skipped, as are leaves whose value at
+	// the revision was written without a key.
+	ListLeavesByKeyPrefix(ctx context.Context, revision int64, prefix, afterKey, afterIndex []byte, limit int) ([]*trillian.MapLeaf, error)
+}
+
+// MapTileScanner is implemented by ReadOnlyMapTreeTX implementations which can
+// read all the tiles of a map. Callers should use a type assertion to check
+// whether it is supported.
+type MapTileScanner interface {
+	// ScanTiles calls fn with the latest version of each tile of the map at
+	// the given revision, starting with the root tile, and stops at the
+	// first error returned by fn. Tiles are read in batches, as leaves are
+	// by ScanLeaves. Tiles emptied by deletions may be passed to fn with no
+	// leaves.
+	ScanTiles(ctx context.Context, revision int64, fn func(smt.Tile) error) error
+}
+
+// MapRevisionGCTX is implemented by MapTreeTX implementations which can delete
+// the data of old revisions of a map. Callers should use a type assertion to
+// check whether it is supported.
+type MapRevisionGCTX interface {
+	// RevisionAt returns the latest revision of the map whose root was written
+	// no later than the given time, or -1 if there is none.
+	RevisionAt(ctx context.Context, at time.Time) (int64, error)
+
+	// DeleteRevisionsBefore deletes up to limit rows of roots, along with
+	// any co-signatures, and leaves and tiles of the map which are only
+	// needed by reads at revisions before horizon. Reads at horizon and any later revision are unaffected, while
+	// those at the earlier revisions, other than revision 0, fail as their
+	// roots are deleted first. It returns the number of rows deleted, which
+	// is less than limit once there are no more of them.
+	DeleteRevisionsBefore(ctx context.Context, horizon int64, limit int) (int, error)
+}
+
+// MapBulkWriter is implemented by MapStorage implementations which can
+// populate a fresh map in bulk, by writing its leaves and tiles directly at
+// revision 1 rather than in map tree transactions. It is meant for the initial
+// load of a map which isn't in use yet: the writes aren't read until the root
+// of revision 1 is stored, but nothing stops other writers from writing the
+// map in the meantime. Callers should use a type assertion to check whether
+// it is supported.
+type MapBulkWriter interface {
+	// BeginBulkWrite checks that the map has no root above revision 0, and
+	// removes whatever an earlier bulk write which failed before storing its
+	// root wrote at revision 1.
+	BeginBulkWrite(ctx context.Context, tree *trillian.Tree) error
+	// BulkWriteLeaves writes the given leaves at revision 1 of the map, in
+	// as few statements as the storage allows. Deletions are skipped, as
+	// there is nothing to delete in a fresh map.
+	BulkWriteLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf) error
+	// BulkWriteTiles writes the given tiles at revision 1 of the map.
+	BulkWriteTiles(ctx context.Context, tree *trillian.Tree, tiles []smt.Tile) error
+	// CommitBulkWrite stores the given root of revision 1 of the map, which
+	// makes the leaves and tiles written in bulk visible to readers.
+	CommitBulkWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error
+}
+
+// MapSnapshotWriter is implemented by MapStorage implementations which can
+// import a single revision of a map, exported from another storage, by
+// writing its leaves and tiles directly at that revision, as MapBulkWriter
+// does at revision 1. The map must have the same tree ID and keys as the one
+// exported, and must not have any roots other than that of revision 0, so
+// that the imported root is the latest one. Callers should use a type
+// assertion to check whether it is supported.
+type MapSnapshotWriter interface {
+	// BeginSnapshotWrite checks that the map can take the given revision,
+	// and removes whatever an earlier import which failed before storing
+	// its root wrote.
+	BeginSnapshotWrite(ctx context.Context, tree *trillian.Tree, revision int64) error
+	// SnapshotWriteLeaves writes the given leaves at the given revision of
+	// the map. Deletions are skipped.
+	SnapshotWriteLeaves(ctx context.Context, tree *trillian.Tree, revision int64, leaves []*trillian.MapLeaf) error
+	// SnapshotWriteTiles writes the given tiles at the given revision of
+	// the map.
+	SnapshotWriteTiles(ctx context.Context, tree *trillian.Tree, revision int64, tiles []smt.Tile) error
+	// CommitSnapshotWrite stores the given root, as it was signed, which
+	// makes the leaves and tiles written at its revision visible to readers.
+	CommitSnapshotWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error
+}
+
+// MapMutationQueue is implemented by MapStorage implementations which can
+// queue mutations of map leaves, for a map sequencer to apply later in a new
+// revision of the map, in the way the leaves of logs are queued. Callers
+// should use a type assertion to check whether it is supported.
+type MapMutationQueue interface {
+	// QueueMapMutations queues the given leaves to be set, in the way
+	// MapTreeTX.Set sets them, with the given queue time. The indices of
+	// the leaves must be unique.
+	QueueMapMutations(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf, queueTimestamp time.Time) error
+}
+
+// MapRootSignatureTX is implemented by ReadOnlyMapTreeTX implementations which
+// can store co-signatures of map roots by witnesses. Callers should use a type
+// assertion to check whether it is supported.
+type MapRootSignatureTX interface {
+	// AddMapRootSignature stores the given co-signature of the map root at
+	// the given revision, replacing any earlier one by the same public key.
+	// It fails in read-only transactions.
+	AddMapRootSignature(ctx context.Context, revision int64, sig *trillian.MapRootSignature) error
+
+	// GetMapRootSignatures returns the co-signatures of the map root at the
+	// given revision, ordered by the hashes of their public keys.
+	GetMapRootSignatures(ctx context.Context, revision int64) ([]*trillian.MapRootSignature, error)
+}
+
+// MapMutationDequeuer is implemented by MapTreeTX implementations whose map
+// storage implements MapMutationQueue. Callers should use a type assertion to
+// check whether it is supported.
+type MapMutationDequeuer interface {
+	// DequeueMapMutations removes up to limit of the oldest queued
+	// mutations of the map from the queue, and returns their leaves in order
+	// of their queue time. The mutations are only removed if the transaction
+	// commits.
+	DequeueMapMutations(ctx context.Context, limit int) ([]*trillian.MapLeaf, error)
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/trillian"
+)
+
+func TestMapLeafKeyPrefixEnd(t *testing.T) {
+	maxEnd := bytes.Repeat([]byte{0xff}, MaxMapLeafKeySize+1)
+	for _, tc := range []struct {
+		prefix []byte
+		want   []byte
+	}{
+		{prefix: nil, want: maxEnd},
+		{prefix: []byte("a"), want: []byte("b")},
+		{prefix: []byte("ab"), want: []byte("ac")},
+		{prefix: []byte{0x01, 0xff}, want: []byte{0x02}},
+		{prefix: []byte{0x01, 0xfe, 0xff, 0xff}, want: []byte{0x01, 0xff}},
+		{prefix: []byte{0xff, 0xff}, want: maxEnd},
+	} {
+		prefix := append([]byte(nil), tc.prefix...)
+		if got := MapLeafKeyPrefixEnd(tc.prefix); !bytes.Equal(got, tc.want) {
+			t.Errorf("MapLeafKeyPrefixEnd(%x): %x, want %x", tc.prefix, got, tc.want)
+		}
+		if !bytes.Equal(tc.prefix, prefix) {
+			t.Errorf("MapLeafKeyPrefixEnd(%x) modified its argument", prefix)
+		}
+	}
+}
+
+func TestMapLeafIndexedKey(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		leaf *trillian.MapLeaf
+		want []byte
+	}{
+		{desc: "keyed", leaf: &trillian.MapLeaf{Key: []byte("k"), LeafValue: []byte("v")}, want: []byte("k")},
+		{desc: "no-key", leaf: &trillian.MapLeaf{LeafValue: []byte("v")}},
+		{desc: "deletion", leaf: &trillian.MapLeaf{Key: []byte("k")}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := MapLeafIndexedKey(tc.leaf); !bytes.Equal(got, tc.want) {
+				t.Errorf("MapLeafIndexedKey(): %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -25,6 +25,8 @@ import (
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
 	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // NewAdminStorage returns a storage.AdminStorage implementation backed by
@@ -107,7 +109,7 @@ func (t *adminTX) Close() error {
 func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
 	tree := t.ms.getTree(treeID)
 	if tree == nil {
-		return nil, fmt.Errorf("no such treeID %d", treeID)
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", treeID)
 	}
 	tree.RLock()
 	defer tree.RUnlock()
@@ -145,7 +147,7 @@ func (t *adminTX) CreateTree(ctx context.Context, tr *trillian.Tree) (*trillian.
 		return nil, err
 	}
 
-	id, err := storage.NewTreeID()
+	id, err := storage.TreeIDForCreation(tr)
 	if err != nil {
 		return nil, err
 	}
@@ -165,6 +167,16 @@ func (t *adminTX) CreateTree(ctx context.Context, tr *trillian.Tree) (*trillian.
 
 	t.ms.mu.Lock()
 	defer t.ms.mu.Unlock()
+	if _, ok := t.ms.trees[id]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "tree %v already exists", id)
+	}
+	if reqID := meta.CreateRequestId; reqID != "" {
+		for _, v := range t.ms.trees {
+			if v.meta.CreateRequestId == reqID {
+				return nil, status.Errorf(codes.AlreadyExists, "a tree created with request ID %q already exists", reqID)
+			}
+		}
+	}
 	t.ms.trees[id] = newTree(meta)
 
 	glog.V(1).Infof("trees: %v", t.ms.trees)
@@ -367,6 +367,10 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, slr *trillian.Signed
 	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
 		return err
 	}
+	epoch, err := storage.CheckSignerEpoch(ctx, t.treeID, t.tree.signerEpoch)
+	if err != nil {
+		return err
+	}
 	k := sthKey(t.treeID, root.TimestampNanos)
 	k.(*kv).v = slr
 	t.tx.ReplaceOrInsert(k)
@@ -375,6 +379,7 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, slr *trillian.Signed
 	if root.TimestampNanos > t.tree.currentSTH {
 		t.tree.currentSTH = root.TimestampNanos
 	}
+	t.tree.signerEpoch = epoch
 	return nil
 }
 
@@ -35,8 +35,8 @@ const degree = 8
 // unseqKey formats a key for use in a tree's BTree store.
 // The associated Item value will be the stubtreeProto with the given nodeID
 // prefix.
-func subtreeKey(treeID, rev int64, nodeID stree.NodeID) btree.Item {
-	return &kv{k: fmt.Sprintf("/%d/subtree/%s/%d", treeID, nodeID.String(), rev)}
+func subtreeKey(treeID, rev int64, id stree.TileID) btree.Item {
+	return &kv{k: fmt.Sprintf("/%d/subtree/%x/%d", treeID, id.AsBytes(), rev)}
 }
 
 // tree stores all data for a given treeID
@@ -58,7 +58,9 @@ type tree struct {
 	store *btree.BTree
 	// currentSTH is the timestamp of the current STH.
 	currentSTH uint64
-	meta       *trillian.Tree
+	// signerEpoch is the highest signer epoch of the stored STHs.
+	signerEpoch int64
+	meta        *trillian.Tree
 }
 
 func (t *tree) Lock() {
@@ -163,8 +165,8 @@ type treeTX struct {
 	unlock        func()
 }
 
-func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID stree.NodeID) (*storagepb.SubtreeProto, error) {
-	s, err := t.getSubtrees(ctx, treeRevision, []stree.NodeID{nodeID})
+func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID stree.NodeID2) (*storagepb.SubtreeProto, error) {
+	s, err := t.getSubtrees(ctx, treeRevision, []stree.NodeID2{nodeID})
 	if err != nil {
 		return nil, err
 	}
@@ -178,7 +180,7 @@ func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID stre
 	}
 }
 
-func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []stree.NodeID) ([]*storagepb.SubtreeProto, error) {
+func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []stree.NodeID2) ([]*storagepb.SubtreeProto, error) {
 	if len(nodeIDs) == 0 {
 		return nil, nil
 	}
@@ -186,13 +188,14 @@ func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []
 	ret := make([]*storagepb.SubtreeProto, 0, len(nodeIDs))
 
 	for _, nodeID := range nodeIDs {
-		if nodeID.PrefixLenBits%8 != 0 {
-			return nil, fmt.Errorf("invalid subtree ID - not multiple of 8: %d", nodeID.PrefixLenBits)
+		id, err := stree.NewTileID(nodeID)
+		if err != nil {
+			return nil, err
 		}
 
 		// Look for a nodeID at or below treeRevision:
 		for r := treeRevision; r >= 0; r-- {
-			s := t.tx.Get(subtreeKey(t.treeID, r, nodeID))
+			s := t.tx.Get(subtreeKey(t.treeID, r, id))
 			if s == nil {
 				continue
 			}
@@ -220,7 +223,7 @@ func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.Subtre
 		if s.Prefix == nil {
 			panic(fmt.Errorf("nil prefix on %v", s))
 		}
-		k := subtreeKey(t.treeID, t.writeRevision, stree.NewNodeIDFromHash(s.Prefix))
+		k := subtreeKey(t.treeID, t.writeRevision, stree.TileIDFromBytes(s.Prefix))
 		k.(*kv).v = s
 		t.tx.ReplaceOrInsert(k)
 	}
@@ -229,7 +232,7 @@ func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.Subtre
 
 // getSubtreesAtRev returns a GetSubtreesFunc which reads at the passed in rev.
 func (t *treeTX) getSubtreesAtRev(ctx context.Context, rev int64) cache.GetSubtreesFunc {
-	return func(ids []stree.NodeID) ([]*storagepb.SubtreeProto, error) {
+	return func(ids []stree.NodeID2) ([]*storagepb.SubtreeProto, error) {
 		return t.getSubtrees(ctx, rev, ids)
 	}
 }
@@ -242,7 +245,7 @@ func (t *treeTX) GetMerkleNodes(ctx context.Context, treeRevision int64, nodeIDs
 func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []stree.Node) error {
 	for _, n := range nodes {
 		err := t.subtreeCache.SetNodeHash(n.NodeID, n.Hash,
-			func(nID stree.NodeID) (*storagepb.SubtreeProto, error) {
+			func(nID stree.NodeID2) (*storagepb.SubtreeProto, error) {
 				return t.getSubtree(ctx, t.writeRevision, nID)
 			})
 		if err != nil {
@@ -711,6 +711,21 @@ func (mr *MockMapStorageMockRecorder) ReadWriteTransaction(arg0, arg1, arg2 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWriteTransaction", reflect.TypeOf((*MockMapStorage)(nil).ReadWriteTransaction), arg0, arg1, arg2)
 }
 
+// SnapshotAtRevision mocks base method
+func (m *MockMapStorage) SnapshotAtRevision(arg0 context.Context, arg1 *trillian.Tree, arg2 int64) (ReadOnlyMapTreeTX, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotAtRevision", arg0, arg1, arg2)
+	ret0, _ := ret[0].(ReadOnlyMapTreeTX)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotAtRevision indicates an expected call of SnapshotAtRevision
+func (mr *MockMapStorageMockRecorder) SnapshotAtRevision(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotAtRevision", reflect.TypeOf((*MockMapStorage)(nil).SnapshotAtRevision), arg0, arg1, arg2)
+}
+
 // SnapshotForTree mocks base method
 func (m *MockMapStorage) SnapshotForTree(arg0 context.Context, arg1 *trillian.Tree) (ReadOnlyMapTreeTX, error) {
 	m.ctrl.T.Helper()
@@ -54,13 +54,25 @@ const (
 			PublicKey,
 			MaxRootDurationMillis,
 			Deleted,
-			DeleteTimeMillis
+			DeleteTimeMillis,
+			RateLimits,
+			Labels,
+			CreateRequestId,
+			Maintenance,
+			MapStrata,
+			MapCompression,
+			MapHasher,
+			AutoInit,
+			MaxStorageBytes,
+			MapKeyIndex,
+			RetiredPublicKeys
 		FROM Trees`
-	selectNonDeletedTrees = selectTrees + nonDeletedWhere
-	selectTreeByID        = selectTrees + " WHERE TreeId = ?"
+	selectNonDeletedTrees       = selectTrees + nonDeletedWhere
+	selectTreeByID              = selectTrees + " WHERE TreeId = ?"
+	selectTreeByCreateRequestID = selectTrees + " WHERE CreateRequestId = ?"
 
 	updateTreeSQL = `UPDATE Trees
-		SET TreeState = ?, TreeType = ?, DisplayName = ?, Description = ?, UpdateTimeMillis = ?, MaxRootDurationMillis = ?, PrivateKey = ?
+		SET TreeState = ?, TreeType = ?, DisplayName = ?, Description = ?, UpdateTimeMillis = ?, MaxRootDurationMillis = ?, PrivateKey = ?, RateLimits = ?, Labels = ?, Maintenance = ?, MapCompression = ?, AutoInit = ?, MaxStorageBytes = ?, PublicKey = ?, RetiredPublicKeys = ?
 		WHERE TreeId = ?`
 )
 
@@ -169,6 +181,24 @@ func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, er
 	return tree, nil
 }
 
+// GetTreeByCreateRequestID implements storage.CreateRequestIDReader.
+func (t *adminTX) GetTreeByCreateRequestID(ctx context.Context, requestID string) (*trillian.Tree, error) {
+	stmt, err := t.tx.PrepareContext(ctx, selectTreeByCreateRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	tree, err := storage.ReadTree(stmt.QueryRowContext(ctx, requestID))
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, status.Errorf(codes.NotFound, "no tree was created with request ID %q", requestID)
+	case err != nil:
+		return nil, fmt.Errorf("error reading tree created with request ID %q: %v", requestID, err)
+	}
+	return tree, nil
+}
+
 func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
 	var query string
 	if includeDeleted {
@@ -237,10 +267,20 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		return nil, err
 	}
 
-	id, err := storage.NewTreeID()
+	id, err := storage.TreeIDForCreation(tree)
 	if err != nil {
 		return nil, err
 	}
+	if tree.TreeId != 0 {
+		// IDs chosen by the creators of trees may already be taken.
+		switch _, err := t.GetTree(ctx, id); status.Code(err) {
+		case codes.NotFound:
+		case codes.OK:
+			return nil, status.Errorf(codes.AlreadyExists, "tree %v already exists", id)
+		default:
+			return nil, err
+		}
+	}
 
 	// Use the time truncated-to-millis throughout, as that's what's stored.
 	nowMillis := storage.ToMillisSinceEpoch(time.Now())
@@ -276,8 +316,18 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 			UpdateTimeMillis,
 			PrivateKey,
 			PublicKey,
-			MaxRootDurationMillis)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+			MaxRootDurationMillis,
+			RateLimits,
+			Labels,
+			CreateRequestId,
+			Maintenance,
+			MapStrata,
+			MapCompression,
+			MapHasher,
+			AutoInit,
+			MaxStorageBytes,
+			MapKeyIndex)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return nil, err
 	}
@@ -287,6 +337,22 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
 	}
+	rateLimits, err := storage.MarshalRateLimits(newTree)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := storage.MarshalLabels(newTree)
+	if err != nil {
+		return nil, err
+	}
+	maintenance, err := storage.MarshalMaintenance(newTree)
+	if err != nil {
+		return nil, err
+	}
+	mapStrata, err := storage.MarshalMapStrata(newTree)
+	if err != nil {
+		return nil, err
+	}
 
 	_, err = insertTreeStmt.ExecContext(
 		ctx,
@@ -303,8 +369,22 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		privateKey,
 		newTree.PublicKey.GetDer(),
 		rootDuration/time.Millisecond,
+		rateLimits,
+		labels,
+		sql.NullString{String: newTree.CreateRequestId, Valid: newTree.CreateRequestId != ""},
+		maintenance,
+		mapStrata,
+		storage.MarshalMapCompression(newTree),
+		sql.NullString{String: newTree.MapHasher, Valid: newTree.MapHasher != ""},
+		newTree.AutoInit,
+		newTree.MaxStorageBytes,
+		newTree.MapKeyIndex,
 	)
 	if err != nil {
+		if isDuplicateErr(err) {
+			// The tree or request IDs are taken, maybe by a concurrent request.
+			return nil, status.Errorf(codes.AlreadyExists, "tree %v or a tree created with request ID %q already exists", newTree.TreeId, newTree.CreateRequestId)
+		}
 		return nil, err
 	}
 
@@ -377,6 +457,22 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
 	}
+	rateLimits, err := storage.MarshalRateLimits(tree)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := storage.MarshalLabels(tree)
+	if err != nil {
+		return nil, err
+	}
+	maintenance, err := storage.MarshalMaintenance(tree)
+	if err != nil {
+		return nil, err
+	}
+	retiredPublicKeys, err := storage.MarshalRetiredPublicKeys(tree)
+	if err != nil {
+		return nil, err
+	}
 
 	stmt, err := t.tx.PrepareContext(ctx, updateTreeSQL)
 	if err != nil {
@@ -393,6 +489,14 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		nowMillis,
 		rootDuration/time.Millisecond,
 		privateKey,
+		rateLimits,
+		labels,
+		maintenance,
+		storage.MarshalMapCompression(tree),
+		tree.AutoInit,
+		tree.MaxStorageBytes,
+		tree.PublicKey.GetDer(),
+		retiredPublicKeys,
 		tree.TreeId); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,325 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/backoff"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// clusterGalera is the cluster mode of Galera clusters, e.g. MariaDB
+	// Galera Cluster or Percona XtraDB Cluster.
+	clusterGalera = "galera"
+	// clusterGroupReplication is the cluster mode of MySQL Group Replication,
+	// e.g. InnoDB Cluster.
+	clusterGroupReplication = "group_replication"
+
+	// ER_UNKNOWN_COM_ERROR: Returned by Galera nodes which aren't synced with
+	// the cluster.
+	errNumUnknownCommand = 1047
+	// ER_LOCK_WAIT_TIMEOUT: Error returned when a lock wait timed out.
+	errNumLockWaitTimeout = 1205
+	// ER_TRANSACTION_ROLLBACK_DURING_COMMIT: Returned by Group Replication when
+	// a transaction fails certification.
+	errNumRollbackDuringCommit = 3101
+)
+
+var (
+	clusterMode    = flag.String("mysql_cluster", "", "If set, the kind of multi-primary MySQL cluster --mysql_uri is a node of, galera or group_replication. Connections are made to the --mysql_uri node as long as it's ready, and to the --mysql_cluster_nodes otherwise, read-write transactions which fail certification are retried, and trees are hard-deleted without relying on cascading deletes")
+	clusterNodes   = flag.String("mysql_cluster_nodes", "", "Comma-separated list of host:port addresses of the other nodes of the --mysql_cluster, in order of preference, which are connected to while the --mysql_uri node is down or not ready")
+	clusterRetries = flag.Int("mysql_cluster_retries", 3, "Number of times read-write transactions are retried after failing certification in a --mysql_cluster")
+)
+
+// clusterConnector is a driver.Connector which connects to the first node of
+// a cluster which is ready to serve writes, in order of preference. This
+// sends all writes to the same node while it's up, which avoids conflicts
+// between transactions executed by different nodes.
+type clusterConnector struct {
+	c     *connector
+	mode  string
+	addrs []string
+}
+
+// newClusterConnector returns a connector to the cluster of the given mode,
+// which prefers the node c connects to, and then the nodes in the
+// comma-separated list nodes.
+func newClusterConnector(c *connector, mode, nodes string) (*clusterConnector, error) {
+	if mode != clusterGalera && mode != clusterGroupReplication {
+		return nil, fmt.Errorf("unknown MySQL cluster mode %q", mode)
+	}
+	addrs := []string{c.cfg.Addr}
+	for _, node := range strings.Split(nodes, ",") {
+		if node = strings.TrimSpace(node); node != "" {
+			addrs = append(addrs, node)
+		}
+	}
+	return &clusterConnector{c: c, mode: mode, addrs: addrs}, nil
+}
+
+// Connect implements driver.Connector.
+func (c *clusterConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	var errs []string
+	for _, addr := range c.addrs {
+		conn, err := c.c.connectTo(ctx, addr)
+		if err == nil {
+			if err = nodeReady(ctx, conn, c.mode); err == nil {
+				return conn, nil
+			}
+			conn.Close()
+		}
+		glog.Warningf("MySQL cluster node %s unavailable: %v", addr, err)
+		errs = append(errs, fmt.Sprintf("%s: %v", addr, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("no MySQL cluster node available: %s", strings.Join(errs, "; "))
+}
+
+// Driver implements driver.Connector.
+func (c *clusterConnector) Driver() driver.Driver {
+	return c.c.Driver()
+}
+
+// nodeReady returns an error if the cluster node connected to by conn isn't
+// ready to serve writes.
+func nodeReady(ctx context.Context, conn driver.Conn, mode string) error {
+	var query, want string
+	switch mode {
+	case clusterGalera:
+		query, want = "SHOW STATUS LIKE 'wsrep_ready'", "ON"
+	case clusterGroupReplication:
+		query, want = "SELECT IF(@@global.super_read_only, 'READ_ONLY', MEMBER_STATE) FROM performance_schema.replication_group_members WHERE MEMBER_ID = @@server_uuid", "ONLINE"
+	}
+	q, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return errors.New("connection doesn't support queries")
+	}
+	rows, err := q.QueryContext(ctx, query, nil)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	// The state is in the last column.
+	vals := make([]driver.Value, len(rows.Columns()))
+	switch err := rows.Next(vals); {
+	case err == io.EOF:
+		return errors.New("node state unknown")
+	case err != nil:
+		return err
+	}
+	if got := fmt.Sprintf("%s", vals[len(vals)-1]); !strings.EqualFold(got, want) {
+		return fmt.Errorf("node state is %s, want %s", got, want)
+	}
+	return nil
+}
+
+// isRetryableClusterErr returns whether err is a transient error of a
+// cluster, after which the failed transaction can be retried.
+func isRetryableClusterErr(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case errNumDeadlock, errNumLockWaitTimeout, errNumUnknownCommand, errNumRollbackDuringCommit,
+			errNumTiDBWriteConflict, errNumTiDBTxnRetryable:
+			return true
+		}
+		return false
+	}
+	// Deadlocks, which is how Galera reports certification failures, may
+	// have been converted by mysqlToGRPC.
+	return status.Code(err) == codes.Aborted
+}
+
+// retryCluster calls f until it succeeds, fails with an error which isn't
+// retryable, or has been retried the given number of times.
+func retryCluster(ctx context.Context, retries int, f func() error) error {
+	b := &backoff.Backoff{
+		Min:    10 * time.Millisecond,
+		Max:    time.Second,
+		Factor: 2,
+		Jitter: true,
+	}
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= retries || !isRetryableClusterErr(err) {
+			return err
+		}
+		glog.V(1).Infof("Retrying transaction after cluster error: %v", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(b.Duration()):
+		}
+	}
+}
+
+// clusterLogStorage is a LogStorage which retries read-write transactions
+// which fail certification.
+type clusterLogStorage struct {
+	storage.LogStorage
+	retries int
+}
+
+func (s *clusterLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	return retryCluster(ctx, s.retries, func() error {
+		return s.LogStorage.ReadWriteTransaction(ctx, tree, f)
+	})
+}
+
+// clusterMapStorage is a MapStorage which retries read-write transactions
+// which fail certification.
+type clusterMapStorage struct {
+	storage.MapStorage
+	retries int
+}
+
+func (s *clusterMapStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+	return retryCluster(ctx, s.retries, func() error {
+		return s.MapStorage.ReadWriteTransaction(ctx, tree, f)
+	})
+}
+
+// QueueMapMutations implements storage.MapMutationQueue, retrying the queueing
+// transactions which fail certification.
+func (s *clusterMapStorage) QueueMapMutations(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf, queueTimestamp time.Time) error {
+	q, ok := s.MapStorage.(storage.MapMutationQueue)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "map storage %T can't queue mutations", s.MapStorage)
+	}
+	return retryCluster(ctx, s.retries, func() error {
+		return q.QueueMapMutations(ctx, tree, leaves, queueTimestamp)
+	})
+}
+
+// snapshotWriter returns the wrapped storage as a storage.MapSnapshotWriter.
+func (s *clusterMapStorage) snapshotWriter() (storage.MapSnapshotWriter, error) {
+	w, ok := s.MapStorage.(storage.MapSnapshotWriter)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "map storage %T can't import map snapshots", s.MapStorage)
+	}
+	return w, nil
+}
+
+// BeginSnapshotWrite implements storage.MapSnapshotWriter.
+func (s *clusterMapStorage) BeginSnapshotWrite(ctx context.Context, tree *trillian.Tree, revision int64) error {
+	w, err := s.snapshotWriter()
+	if err != nil {
+		return err
+	}
+	return retryCluster(ctx, s.retries, func() error {
+		return w.BeginSnapshotWrite(ctx, tree, revision)
+	})
+}
+
+// SnapshotWriteLeaves implements storage.MapSnapshotWriter.
+func (s *clusterMapStorage) SnapshotWriteLeaves(ctx context.Context, tree *trillian.Tree, revision int64, leaves []*trillian.MapLeaf) error {
+	w, err := s.snapshotWriter()
+	if err != nil {
+		return err
+	}
+	return retryCluster(ctx, s.retries, func() error {
+		return w.SnapshotWriteLeaves(ctx, tree, revision, leaves)
+	})
+}
+
+// SnapshotWriteTiles implements storage.MapSnapshotWriter.
+func (s *clusterMapStorage) SnapshotWriteTiles(ctx context.Context, tree *trillian.Tree, revision int64, tiles []smt.Tile) error {
+	w, err := s.snapshotWriter()
+	if err != nil {
+		return err
+	}
+	return retryCluster(ctx, s.retries, func() error {
+		return w.SnapshotWriteTiles(ctx, tree, revision, tiles)
+	})
+}
+
+// CommitSnapshotWrite implements storage.MapSnapshotWriter.
+func (s *clusterMapStorage) CommitSnapshotWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	w, err := s.snapshotWriter()
+	if err != nil {
+		return err
+	}
+	return retryCluster(ctx, s.retries, func() error {
+		return w.CommitSnapshotWrite(ctx, tree, root)
+	})
+}
+
+// clusterAdminStorage is an AdminStorage which retries read-write
+// transactions which fail certification, and deletes the rows of trees
+// explicitly, as cascading deletes aren't supported by multi-primary Group
+// Replication, make for large write sets on Galera, and aren't performed by
+// TiDB, which doesn't have the foreign keys of storage.sql.
+type clusterAdminStorage struct {
+	*mysqlAdminStorage
+	retries int
+}
+
+func (s *clusterAdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	return retryCluster(ctx, s.retries, func() error {
+		return s.mysqlAdminStorage.ReadWriteTransaction(ctx, func(ctx context.Context, tx storage.AdminTX) error {
+			return f(ctx, &clusterAdminTX{adminTX: tx.(*adminTX)})
+		})
+	})
+}
+
+// treeDataTables lists the tables which hold the data of trees, in an order
+// in which rows can be deleted from them without violating foreign keys.
+var treeDataTables = []string{
+	"SequencedLeafData",
+	"DeadLetter",
+	"TreeStats",
+	"TreeUsage",
+	"LeafData",
+	"Subtree",
+	"TreeHead",
+	"MapLeaf",
+	"MapHead",
+	"MapWriteBatch",
+	"MapMutation",
+	"MapRootSignatures",
+}
+
+type clusterAdminTX struct {
+	*adminTX
+}
+
+func (t *clusterAdminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
+	if err := validateDeleted(ctx, t.tx, treeID, true /* wantDeleted */); err != nil {
+		return err
+	}
+	for _, table := range treeDataTables {
+		if _, err := t.tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE TreeId = ?", table), treeID); err != nil {
+			return err
+		}
+	}
+	return t.adminTX.HardDeleteTree(ctx, treeID)
+}
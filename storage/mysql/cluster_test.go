@@ -0,0 +1,87 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewClusterConnector(t *testing.T) {
+	c := &connector{cfg: &mysql.Config{Addr: "writer:3306"}}
+	for _, test := range []struct {
+		mode      string
+		nodes     string
+		wantAddrs []string
+		wantErr   bool
+	}{
+		{mode: clusterGalera, wantAddrs: []string{"writer:3306"}},
+		{mode: clusterGroupReplication, nodes: "a:3306, b:3306,", wantAddrs: []string{"writer:3306", "a:3306", "b:3306"}},
+		{mode: "ndb", wantErr: true},
+	} {
+		cc, err := newClusterConnector(c, test.mode, test.nodes)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("newClusterConnector(%q, %q): %v, wantErr %v", test.mode, test.nodes, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if diff := cmp.Diff(cc.addrs, test.wantAddrs); diff != "" {
+			t.Errorf("newClusterConnector(%q, %q): addrs diff (-got +want):\n%s", test.mode, test.nodes, diff)
+		}
+	}
+}
+
+func TestRetryCluster(t *testing.T) {
+	certErr := &mysql.MySQLError{Number: errNumRollbackDuringCommit, Message: "certification failed"}
+	for _, test := range []struct {
+		desc      string
+		errs      []error
+		retries   int
+		wantCalls int
+		wantErr   bool
+	}{
+		{desc: "ok", errs: []error{nil}, retries: 3, wantCalls: 1},
+		{desc: "certification", errs: []error{certErr, nil}, retries: 3, wantCalls: 2},
+		{desc: "wrapped-deadlock", errs: []error{fmt.Errorf("commit: %w", &mysql.MySQLError{Number: errNumDeadlock}), nil}, retries: 3, wantCalls: 2},
+		{desc: "aborted", errs: []error{status.Error(codes.Aborted, "deadlock"), nil}, retries: 3, wantCalls: 2},
+		{desc: "exhausted", errs: []error{certErr, certErr, certErr}, retries: 2, wantCalls: 3, wantErr: true},
+		{desc: "not-retryable", errs: []error{errors.New("bad"), nil}, retries: 3, wantCalls: 1, wantErr: true},
+		{desc: "duplicate", errs: []error{&mysql.MySQLError{Number: errNumDuplicate}, nil}, retries: 3, wantCalls: 1, wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			calls := 0
+			err := retryCluster(context.Background(), test.retries, func() error {
+				err := test.errs[calls]
+				calls++
+				return err
+			})
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("retryCluster(): %v, wantErr %v", err, test.wantErr)
+			}
+			if got, want := calls, test.wantCalls; got != want {
+				t.Errorf("retryCluster() made %d calls, want %d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql/driver"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/trillian/storage/sqlcomment"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// tlsConfigName is the name under which the TLS configuration set by
+	// flags is registered with the MySQL driver.
+	tlsConfigName = "trillian"
+	// iamLoginScope is the OAuth2 scope of Cloud SQL IAM database
+	// authentication tokens.
+	iamLoginScope = "https://www.googleapis.com/auth/sqlservice.login"
+)
+
+var (
+	tlsCAFile     = flag.String("mysql_tls_ca", "", "Path to a PEM file with the CA certificates to verify the MySQL server with. Setting any of the --mysql_tls_* flags enables TLS")
+	tlsCertFile   = flag.String("mysql_tls_cert", "", "Path to a PEM client certificate to present to the MySQL server. Requires --mysql_tls_key")
+	tlsKeyFile    = flag.String("mysql_tls_key", "", "Path to the PEM private key of --mysql_tls_cert")
+	tlsServerName = flag.String("mysql_tls_server_name", "", "Server name to verify the MySQL server certificate against, if it differs from the host of --mysql_uri")
+	iamAuth       = flag.Bool("mysql_iam_auth", false, "If true, authenticate as the --mysql_uri user with short-lived Cloud SQL IAM database authentication tokens from the application default credentials, rather than a password. Requires TLS or --mysql_dialer")
+	dialerName    = flag.String("mysql_dialer", "", "If set, the name of a dialer registered with RegisterDialer, which connects to the MySQL server instead of --mysql_uri's network, e.g. through a managed database connector. The address of --mysql_uri is passed to the dialer")
+)
+
+var (
+	dialersMu sync.RWMutex
+	dialers   = make(map[string]bool)
+)
+
+// DialFunc connects to the MySQL server at addr.
+type DialFunc func(ctx context.Context, addr string) (net.Conn, error)
+
+// RegisterDialer registers a dialer which can be selected with --mysql_dialer,
+// e.g. one based on a managed database connector such as the Cloud SQL Go
+// connector, which handles TLS and authorization to the database instance
+// named by the address in --mysql_uri.
+func RegisterDialer(name string, dial DialFunc) {
+	dialersMu.Lock()
+	defer dialersMu.Unlock()
+	dialers[name] = true
+	mysql.RegisterDialContext(name, mysql.DialContextFunc(dial))
+}
+
+func dialerRegistered(name string) bool {
+	dialersMu.RLock()
+	defer dialersMu.RUnlock()
+	return dialers[name]
+}
+
+// newConnector returns a connector to the MySQL database at dbURL, which sets
+// up connections as configured by the TLS, authentication and dialer flags.
+// If comments is true, statements are annotated with comments identifying the
+// requests they are issued for. SELECT statements are bounded by the deadlines
+// of their requests if --mysql_deadline_hints is set.
+func newConnector(dbURL string, comments bool) (driver.Connector, error) {
+	cfg, err := mysql.ParseDSN(dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := tlsConfigFromFlags()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		if tlsConfig.ServerName == "" {
+			if host, _, err := net.SplitHostPort(cfg.Addr); err == nil {
+				tlsConfig.ServerName = host
+			}
+		}
+		if err := mysql.RegisterTLSConfig(tlsConfigName, tlsConfig); err != nil {
+			return nil, err
+		}
+		cfg.TLSConfig = tlsConfigName
+	}
+	if *dialerName != "" {
+		if !dialerRegistered(*dialerName) {
+			return nil, fmt.Errorf("no MySQL dialer %q registered", *dialerName)
+		}
+		cfg.Net = *dialerName
+	}
+
+	mc := &connector{cfg: cfg}
+	if *iamAuth {
+		if tlsConfig == nil && *dialerName == "" {
+			return nil, errors.New("--mysql_iam_auth requires TLS or --mysql_dialer, as tokens are sent in cleartext")
+		}
+		tokens, err := google.DefaultTokenSource(context.Background(), iamLoginScope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get credentials for IAM authentication: %v", err)
+		}
+		cfg.AllowCleartextPasswords = true
+		mc.tokens = tokens
+	}
+	var c driver.Connector = mc
+	if *clusterMode != "" {
+		if c, err = newClusterConnector(mc, *clusterMode, *clusterNodes); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case comments && *deadlineHints:
+		c = sqlcomment.WrapConnectorFunc(c, func(ctx context.Context, query string) string {
+			// Hints are comments, so they're added after annotating the
+			// query, which skips queries with comments.
+			return withExecutionTime(ctx, sqlcomment.Annotate(ctx, query))
+		})
+	case comments:
+		c = sqlcomment.WrapConnector(c)
+	case *deadlineHints:
+		c = sqlcomment.WrapConnectorFunc(c, withExecutionTime)
+	}
+	return c, nil
+}
+
+// tlsConfigFromFlags returns the TLS configuration set by flags, or nil if
+// TLS isn't enabled.
+func tlsConfigFromFlags() (*tls.Config, error) {
+	if *tlsCAFile == "" && *tlsCertFile == "" && *tlsKeyFile == "" && *tlsServerName == "" {
+		return nil, nil
+	}
+	cfg := &tls.Config{ServerName: *tlsServerName}
+	if *tlsCAFile != "" {
+		pem, err := ioutil.ReadFile(*tlsCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --mysql_tls_ca: %v", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in --mysql_tls_ca %q", *tlsCAFile)
+		}
+	}
+	if *tlsCertFile != "" || *tlsKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(*tlsCertFile, *tlsKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load --mysql_tls_cert and --mysql_tls_key: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// connector is a driver.Connector for MySQL, which uses a fresh password from
+// tokens, if set, for every connection.
+type connector struct {
+	cfg    *mysql.Config
+	tokens oauth2.TokenSource
+}
+
+// Connect implements driver.Connector.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.connectTo(ctx, c.cfg.Addr)
+}
+
+// connectTo connects to the MySQL server at addr.
+func (c *connector) connectTo(ctx context.Context, addr string) (driver.Conn, error) {
+	cfg := c.cfg
+	if c.tokens != nil || addr != cfg.Addr {
+		cfg = cfg.Clone()
+		cfg.Addr = addr
+	}
+	if c.tokens != nil {
+		token, err := c.tokens.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get IAM authentication token: %v", err)
+		}
+		cfg.Passwd = token.AccessToken
+	}
+	mc, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return mc.Connect(ctx)
+}
+
+// Driver implements driver.Connector.
+func (c *connector) Driver() driver.Driver {
+	return &mysql.MySQLDriver{}
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/trillian/testonly/flagsaver"
+)
+
+func TestNewConnector(t *testing.T) {
+	RegisterDialer("test_dialer", func(ctx context.Context, addr string) (net.Conn, error) {
+		return nil, nil
+	})
+	dir, err := ioutil.TempDir("", "connector")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	badCA := filepath.Join(dir, "ca.pem")
+	if err := ioutil.WriteFile(badCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+
+	const uri = "user@tcp(db.example.com:3306)/trillian"
+	for _, test := range []struct {
+		desc    string
+		flags   map[string]string
+		wantErr bool
+		wantTLS string
+		wantNet string
+	}{
+		{desc: "plain", wantNet: "tcp"},
+		{
+			desc:    "tls",
+			flags:   map[string]string{"mysql_tls_server_name": "other.example.com"},
+			wantTLS: tlsConfigName,
+			wantNet: "tcp",
+		},
+		{desc: "tls-bad-ca", flags: map[string]string{"mysql_tls_ca": badCA}, wantErr: true},
+		{desc: "tls-missing-key", flags: map[string]string{"mysql_tls_cert": filepath.Join(dir, "cert.pem")}, wantErr: true},
+		{desc: "dialer", flags: map[string]string{"mysql_dialer": "test_dialer"}, wantNet: "test_dialer"},
+		{desc: "unknown-dialer", flags: map[string]string{"mysql_dialer": "bogus"}, wantErr: true},
+		{desc: "iam-cleartext", flags: map[string]string{"mysql_iam_auth": "true"}, wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			defer flagsaver.Save().MustRestore()
+			for name, value := range test.flags {
+				if err := flag.Set(name, value); err != nil {
+					t.Fatalf("flag.Set(%q): %v", name, err)
+				}
+			}
+			c, err := newConnector(uri, false)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("newConnector(): %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			cfg := c.(*connector).cfg
+			if got, want := cfg.TLSConfig, test.wantTLS; got != want {
+				t.Errorf("TLSConfig=%q, want %q", got, want)
+			}
+			if got, want := cfg.Net, test.wantNet; got != want {
+				t.Errorf("Net=%q, want %q", got, want)
+			}
+		})
+	}
+}
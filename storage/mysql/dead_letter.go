@@ -0,0 +1,213 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	insertDeadLetterSQL = `INSERT INTO DeadLetter(TreeId,LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,QuarantineTimestampNanos,Reason)
+			VALUES(?,?,?,?,?,?)`
+	selectDeadLettersSQL = `SELECT LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,QuarantineTimestampNanos,Reason
+			FROM DeadLetter
+			WHERE TreeId=?
+			ORDER BY QuarantineTimestampNanos,LeafIdentityHash ASC LIMIT ?`
+	selectDeadLetterMerkleHashSQL = "SELECT MerkleLeafHash FROM DeadLetter WHERE TreeId=? AND LeafIdentityHash=?"
+	deleteDeadLetterSQL           = "DELETE FROM DeadLetter WHERE TreeId=? AND LeafIdentityHash=?"
+	// Only leaf data which was never integrated can be purged, deleting other
+	// rows would cascade into SequencedLeafData.
+	deleteDeadLetterLeafDataSQL = `DELETE FROM LeafData WHERE TreeId=? AND LeafIdentityHash=?
+			AND NOT EXISTS (SELECT 1 FROM SequencedLeafData s WHERE s.TreeId=? AND s.LeafIdentityHash=?)`
+
+	// maxDeadLetterReasonLen matches the width of the DeadLetter.Reason column,
+	// in characters.
+	maxDeadLetterReasonLen = 1024
+)
+
+var _ storage.DeadLetterTX = &logTreeTX{}
+
+// truncateReason returns reason cut down to maxDeadLetterReasonLen characters,
+// without splitting a multi-byte character.
+func truncateReason(reason string) string {
+	n := 0
+	for i := range reason {
+		if n == maxDeadLetterReasonLen {
+			return reason[:i]
+		}
+		n++
+	}
+	return reason
+}
+
+// QuarantineLeaves moves dequeued leaves from the Unsequenced table into the
+// DeadLetter table.
+func (t *logTreeTX) QuarantineLeaves(ctx context.Context, leaves []*trillian.LogLeaf, reason string, quarantineTime time.Time) error {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	if t.treeType != trillian.TreeType_LOG {
+		return status.Errorf(codes.FailedPrecondition, "leaves can only be quarantined in LOG trees, got %v", t.treeType)
+	}
+	reason = truncateReason(reason)
+
+	dequeuedLeaves := make([]dequeuedLeaf, 0, len(leaves))
+	for _, leaf := range leaves {
+		qe, ok := t.dequeued[string(leaf.LeafIdentityHash)]
+		if !ok {
+			return fmt.Errorf("attempting to quarantine leaf that wasn't dequeued. IdentityHash: %x", leaf.LeafIdentityHash)
+		}
+		qTimestamp, err := ptypes.Timestamp(leaf.QueueTimestamp)
+		if err != nil {
+			return fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		if _, err := t.tx.ExecContext(ctx, insertDeadLetterSQL, t.treeID, leaf.LeafIdentityHash, leaf.MerkleLeafHash,
+			qTimestamp.UnixNano(), quarantineTime.UnixNano(), reason); err != nil {
+			glog.Warningf("Failed to insert into DeadLetter: %s", err)
+			return mysqlToGRPC(err)
+		}
+		dequeuedLeaves = append(dequeuedLeaves, qe)
+	}
+	if len(dequeuedLeaves) == 0 {
+		return nil
+	}
+	if err := t.removeSequencedLeaves(ctx, dequeuedLeaves); err != nil {
+		return err
+	}
+	return t.updateTreeStats(ctx, treeStatsDelta{unsequenced: -int64(len(dequeuedLeaves))})
+}
+
+// ListDeadLetterLeaves returns the oldest quarantined leaves of the tree.
+func (t *logTreeTX) ListDeadLetterLeaves(ctx context.Context, limit int) ([]*storage.DeadLetterLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	rows, err := t.tx.QueryContext(ctx, selectDeadLettersSQL, t.treeID, limit)
+	if err != nil {
+		glog.Warningf("Failed to select from DeadLetter: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []*storage.DeadLetterLeaf
+	for rows.Next() {
+		var leafIDHash, merkleHash []byte
+		var queueTimestamp, quarantineTimestamp int64
+		var reason sql.NullString
+		if err := rows.Scan(&leafIDHash, &merkleHash, &queueTimestamp, &quarantineTimestamp, &reason); err != nil {
+			glog.Warningf("Error scanning DeadLetter rows: %s", err)
+			return nil, err
+		}
+		queueTimestampProto, err := ptypes.TimestampProto(time.Unix(0, queueTimestamp))
+		if err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		ret = append(ret, &storage.DeadLetterLeaf{
+			Leaf: &trillian.LogLeaf{
+				LeafIdentityHash: leafIDHash,
+				MerkleLeafHash:   merkleHash,
+				QueueTimestamp:   queueTimestampProto,
+			},
+			QuarantineTime: time.Unix(0, quarantineTimestamp),
+			Reason:         reason.String,
+		})
+	}
+	return ret, rows.Err()
+}
+
+// RequeueDeadLetterLeaves moves quarantined leaves back into the Unsequenced
+// table, so that they are picked up by the next sequencing pass.
+func (t *logTreeTX) RequeueDeadLetterLeaves(ctx context.Context, leafIdentityHashes [][]byte, queueTimestamp time.Time) (int, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	requeued := 0
+	for _, hash := range leafIdentityHashes {
+		var merkleHash []byte
+		err := t.tx.QueryRowContext(ctx, selectDeadLetterMerkleHashSQL, t.treeID, hash).Scan(&merkleHash)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		if _, err := t.tx.ExecContext(ctx, deleteDeadLetterSQL, t.treeID, hash); err != nil {
+			return 0, mysqlToGRPC(err)
+		}
+		args := []interface{}{t.treeID, queueBucket(hash, t.ls.opts.QueueBuckets), hash, merkleHash}
+		args = append(args, queueArgs(t.treeID, hash, queueTimestamp)...)
+		if _, err := t.tx.ExecContext(ctx, insertUnsequencedEntrySQL, args...); err != nil {
+			glog.Warningf("Error inserting into Unsequenced: %s", err)
+			return 0, mysqlToGRPC(err)
+		}
+		requeued++
+	}
+	if err := t.updateTreeStats(ctx, treeStatsDelta{unsequenced: int64(requeued)}); err != nil {
+		return 0, err
+	}
+	return requeued, nil
+}
+
+// PurgeDeadLetterLeaves deletes quarantined leaves along with their LeafData
+// rows, which allows the same leaves to be submitted again.
+func (t *logTreeTX) PurgeDeadLetterLeaves(ctx context.Context, leafIdentityHashes [][]byte) (int, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	purged := 0
+	var stats treeStatsDelta
+	for _, hash := range leafIdentityHashes {
+		res, err := t.tx.ExecContext(ctx, deleteDeadLetterSQL, t.treeID, hash)
+		if err != nil {
+			return 0, mysqlToGRPC(err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return 0, mysqlToGRPC(err)
+		} else if n == 0 {
+			continue
+		}
+		var valueBytes, extraDataBytes int64
+		if t.treeStatsEnabled() {
+			err := t.tx.QueryRowContext(ctx, selectLeafDataBytesByIDSQL, t.treeID, hash).Scan(&valueBytes, &extraDataBytes)
+			if err != nil && err != sql.ErrNoRows {
+				return 0, mysqlToGRPC(err)
+			}
+		}
+		res, err = t.tx.ExecContext(ctx, deleteDeadLetterLeafDataSQL, t.treeID, hash, t.treeID, hash)
+		if err != nil {
+			return 0, mysqlToGRPC(err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return 0, mysqlToGRPC(err)
+		} else if n > 0 {
+			stats.leafValueBytes -= valueBytes
+			stats.extraDataBytes -= extraDataBytes
+		}
+		purged++
+	}
+	if err := t.updateTreeStats(ctx, stats); err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+)
+
+func TestDeadLetterLeaves(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
+
+	leaves := createTestLeaves(3, 20)
+	if _, err := s.QueueLeaves(ctx, tree, leaves, fakeQueueTime); err != nil {
+		t.Fatalf("Failed to queue leaves: %v", err)
+	}
+	quarantineTime := fakeQueueTime.Add(time.Minute)
+
+	// Quarantine the two leaves at the head of the queue.
+	var quarantined []*trillian.LogLeaf
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dequeued, err := tx.DequeueLeaves(ctx, 2, fakeDequeueCutoffTime)
+		if err != nil {
+			t.Fatalf("Failed to dequeue leaves: %v", err)
+		}
+		quarantined = dequeued
+		return tx.(storage.DeadLetterTX).QuarantineLeaves(ctx, dequeued, "poison", quarantineTime)
+	})
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dequeued, err := tx.DequeueLeaves(ctx, 99, fakeDequeueCutoffTime)
+		if err != nil {
+			t.Fatalf("Failed to dequeue leaves: %v", err)
+		}
+		if got, want := len(dequeued), 1; got != want {
+			t.Errorf("Dequeued %d leaves after quarantine, want %d", got, want)
+		}
+		dl, err := tx.(storage.DeadLetterTX).ListDeadLetterLeaves(ctx, 10)
+		if err != nil {
+			t.Fatalf("ListDeadLetterLeaves(): %v", err)
+		}
+		if got, want := len(dl), 2; got != want {
+			t.Fatalf("ListDeadLetterLeaves() returned %d leaves, want %d", got, want)
+		}
+		for _, l := range dl {
+			if l.Reason != "poison" || !l.QuarantineTime.Equal(quarantineTime) {
+				t.Errorf("ListDeadLetterLeaves() returned %+v, want reason %q at %v", l, "poison", quarantineTime)
+			}
+		}
+		return nil
+	})
+
+	hashes := [][]byte{quarantined[0].LeafIdentityHash, []byte("unknown")}
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		n, err := tx.(storage.DeadLetterTX).RequeueDeadLetterLeaves(ctx, hashes, fakeQueueTime)
+		if err != nil {
+			t.Fatalf("RequeueDeadLetterLeaves(): %v", err)
+		}
+		if n != 1 {
+			t.Errorf("RequeueDeadLetterLeaves()=%d, want 1", n)
+		}
+		return nil
+	})
+
+	hashes = [][]byte{quarantined[1].LeafIdentityHash}
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		n, err := tx.(storage.DeadLetterTX).PurgeDeadLetterLeaves(ctx, hashes)
+		if err != nil {
+			t.Fatalf("PurgeDeadLetterLeaves(): %v", err)
+		}
+		if n != 1 {
+			t.Errorf("PurgeDeadLetterLeaves()=%d, want 1", n)
+		}
+		dl, err := tx.(storage.DeadLetterTX).ListDeadLetterLeaves(ctx, 10)
+		if err != nil {
+			t.Fatalf("ListDeadLetterLeaves(): %v", err)
+		}
+		if len(dl) != 0 {
+			t.Errorf("ListDeadLetterLeaves() returned %d leaves after purge, want 0", len(dl))
+		}
+		// The requeued leaf is available for sequencing again.
+		dequeued, err := tx.DequeueLeaves(ctx, 99, fakeDequeueCutoffTime)
+		if err != nil {
+			t.Fatalf("Failed to dequeue leaves: %v", err)
+		}
+		if got, want := len(dequeued), 2; got != want {
+			t.Errorf("Dequeued %d leaves after requeue, want %d", got, want)
+		}
+		return nil
+	})
+
+	// The purged leaf can be queued again.
+	var purged *trillian.LogLeaf
+	for _, l := range leaves {
+		if bytes.Equal(l.LeafIdentityHash, quarantined[1].LeafIdentityHash) {
+			purged = l
+		}
+	}
+	queued, err := s.QueueLeaves(ctx, tree, []*trillian.LogLeaf{purged}, fakeQueueTime)
+	if err != nil {
+		t.Fatalf("Failed to queue purged leaf: %v", err)
+	}
+	if st := queued[0].GetStatus(); st != nil && st.Code != 0 {
+		t.Errorf("QueueLeaves() of purged leaf returned status %v, want OK", st)
+	}
+}
+
+func TestTruncateReason(t *testing.T) {
+	for _, test := range []struct {
+		desc   string
+		reason string
+		want   string
+	}{
+		{desc: "short", reason: "bad leaf", want: "bad leaf"},
+		{desc: "ascii", reason: strings.Repeat("a", maxDeadLetterReasonLen+10), want: strings.Repeat("a", maxDeadLetterReasonLen)},
+		{desc: "multi-byte", reason: strings.Repeat("é", maxDeadLetterReasonLen+10), want: strings.Repeat("é", maxDeadLetterReasonLen)},
+		{desc: "exact", reason: strings.Repeat("é", maxDeadLetterReasonLen), want: strings.Repeat("é", maxDeadLetterReasonLen)},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got := truncateReason(test.reason)
+			if got != test.want {
+				t.Errorf("truncateReason() = %d bytes, want %d", len(got), len(test.want))
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateReason() = %q, which isn't valid UTF-8", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// withExecutionTime adds a MAX_EXECUTION_TIME optimizer hint to query if it's
+// a SELECT statement, and ctx has a deadline, so that the MySQL server aborts
+// the statement once the deadline has passed. Cancelling the context of a
+// statement only makes the driver drop its connection, which the server
+// doesn't notice until the statement completes, so without the hint a slow
+// query keeps running, and holding its locks, long after its caller gave up.
+//
+// Only SELECT statements are bounded by MySQL, other statements are bounded by
+// the server's lock wait timeouts.
+func withExecutionTime(ctx context.Context, query string) string {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return query
+	}
+	trimmed := strings.TrimLeft(query, " \t\n")
+	if len(trimmed) < len("SELECT") || !strings.EqualFold(trimmed[:len("SELECT")], "SELECT") || strings.Contains(query, "/*+") {
+		return query
+	}
+	// The hint is in milliseconds, and zero means no limit.
+	ms := time.Until(deadline).Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return fmt.Sprintf("%s /*+ MAX_EXECUTION_TIME(%d) */%s", trimmed[:len("SELECT")], ms, trimmed[len("SELECT"):])
+}
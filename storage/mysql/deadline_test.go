@@ -0,0 +1,49 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestWithExecutionTime(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	for _, test := range []struct {
+		desc  string
+		ctx   context.Context
+		query string
+		want  string
+	}{
+		{desc: "no-deadline", ctx: context.Background(), query: "SELECT 1", want: `^SELECT 1$`},
+		{desc: "select", ctx: ctx, query: "\n  select a FROM b", want: `^select /\*\+ MAX_EXECUTION_TIME\(5\d{4}|60000\) \*/ a FROM b$`},
+		{desc: "expired", ctx: expired, query: "SELECT 1", want: `^SELECT /\*\+ MAX_EXECUTION_TIME\(1\) \*/ 1$`},
+		{desc: "insert", ctx: ctx, query: "INSERT INTO a SELECT 1", want: `^INSERT INTO a SELECT 1$`},
+		{desc: "hinted", ctx: ctx, query: "SELECT /*+ BKA(a) */ 1", want: `^SELECT /\*\+ BKA\(a\) \*/ 1$`},
+		{desc: "commented", ctx: ctx, query: "SELECT 1 /*request_id='x'*/", want: `^SELECT /\*\+ MAX_EXECUTION_TIME\(\d+\) \*/ 1 /\*request_id='x'\*/$`},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if got := withExecutionTime(test.ctx, test.query); !regexp.MustCompile(test.want).MatchString(got) {
+				t.Errorf("withExecutionTime(%q)=%q, want match for %q", test.query, got, test.want)
+			}
+		})
+	}
+}
@@ -25,6 +25,9 @@ const (
 	errNumDuplicate = 1062
 	// ER_LOCK_DEADLOCK: Error returned when there was a deadlock.
 	errNumDeadlock = 1213
+	// ER_QUERY_TIMEOUT: Error returned when a statement ran for longer than
+	// its MAX_EXECUTION_TIME, see withExecutionTime.
+	errNumQueryTimeout = 3024
 )
 
 // mysqlToGRPC converts some types of MySQL errors to GRPC errors. This gives
@@ -34,8 +37,11 @@ func mysqlToGRPC(err error) error {
 	if !ok {
 		return err
 	}
-	if mysqlErr.Number == errNumDeadlock {
+	switch mysqlErr.Number {
+	case errNumDeadlock:
 		return status.Errorf(codes.Aborted, "MySQL: %v", mysqlErr)
+	case errNumQueryTimeout:
+		return status.Errorf(codes.DeadlineExceeded, "MySQL: %v", mysqlErr)
 	}
 	return err
 }
@@ -28,11 +28,13 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/features"
 	"github.com/google/trillian/merkle/hashers/registry"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/requestid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -54,10 +56,15 @@ const (
 			FROM TreeHead WHERE TreeId=?
 			ORDER BY TreeHeadTimestamp DESC LIMIT 1`
 
+	// selectLeavesByRangeSQL reads a range of leaves with a single scan of the
+	// SequencedLeafData primary key, which is already in sequence order, and a
+	// primary key lookup of the LeafData row of each leaf. STRAIGHT_JOIN stops
+	// the optimizer from driving the join from LeafData instead, which reads
+	// all leaves of the tree on large logs.
 	selectLeavesByRangeSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
-			FROM LeafData l,SequencedLeafData s
-			WHERE l.LeafIdentityHash = s.LeafIdentityHash
-			AND s.SequenceNumber >= ? AND s.SequenceNumber < ? AND l.TreeId = ? AND s.TreeId = l.TreeId` + orderBySequenceNumberSQL
+			FROM SequencedLeafData s STRAIGHT_JOIN LeafData l
+			ON l.TreeId = s.TreeId AND l.LeafIdentityHash = s.LeafIdentityHash
+			WHERE s.TreeId = ? AND s.SequenceNumber >= ? AND s.SequenceNumber < ?` + orderBySequenceNumberSQL
 
 	// These statements need to be expanded to provide the correct number of parameter placeholders.
 	selectLeavesByIndexSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
@@ -70,10 +77,11 @@ const (
 			AND s.MerkleLeafHash IN (` + placeholderSQL + `) AND l.TreeId = ? AND s.TreeId = l.TreeId`
 	// TODO(#1548): rework the code so the dummy hash isn't needed (e.g. this assumes hash size is 32)
 	dummyMerkleLeafHash = "00000000000000000000000000000000"
-	// This statement returns a dummy Merkle leaf hash value (which must be
-	// of the right size) so that its signature matches that of the other
-	// leaf-selection statements.
-	selectLeavesByLeafIdentityHashSQL = `SELECT '` + dummyMerkleLeafHash + `',l.LeafIdentityHash,l.LeafValue,-1,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+	// This statement returns the Merkle leaf hash and index of leaves which
+	// have been integrated, and a dummy Merkle leaf hash value (which must be
+	// of the right size) and index -1 for those which haven't, so that its
+	// signature matches that of the other leaf-selection statements.
+	selectLeavesByLeafIdentityHashSQL = `SELECT COALESCE(s.MerkleLeafHash,'` + dummyMerkleLeafHash + `'),l.LeafIdentityHash,l.LeafValue,COALESCE(s.SequenceNumber,-1),l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
 			FROM LeafData l LEFT JOIN SequencedLeafData s ON (l.LeafIdentityHash = s.LeafIdentityHash AND l.TreeID = s.TreeID)
 			WHERE l.LeafIdentityHash IN (` + placeholderSQL + `) AND l.TreeId = ?`
 
@@ -122,30 +130,92 @@ func labelForTX(t *logTreeTX) string {
 	return strconv.FormatInt(t.treeID, 10)
 }
 
-func observe(hist monitoring.Histogram, duration time.Duration, label string) {
-	hist.Observe(duration.Seconds(), label)
+// observe records the duration of an operation performed for ctx, linking it
+// to the trace ctx is part of if there is one.
+func observe(ctx context.Context, hist monitoring.Histogram, duration time.Duration, label string) {
+	monitoring.ObserveContext(ctx, hist, duration.Seconds(), label)
 }
 
 type mySQLLogStorage struct {
 	*mySQLTreeStorage
 	admin         storage.AdminStorage
 	metricFactory monitoring.MetricFactory
+	opts          LogStorageOptions
+	// replica, if set, runs the read-only transactions of trees on
+	// opts.ReplicaDB.
+	replica *mySQLLogStorage
+}
+
+// LogStorageOptions holds optional settings of the MySQL log storage.
+type LogStorageOptions struct {
+	// QueueBuckets is the number of buckets of the Unsequenced table which
+	// queued leaves are spread across, by their identity hash. If it's less
+	// than 2, all leaves are queued in bucket 0.
+	QueueBuckets int
+	// SharedSubtrees, if set, caches the subtrees of logs read by all
+	// transactions.
+	SharedSubtrees *cache.SharedLogSubtrees
+	// TreeStatsShards, if positive, is the number of rows of the TreeStats
+	// table which the statistics of each log are spread across. The rows are
+	// updated by the write paths in the same transactions as the leaves, so
+	// that reading the statistics doesn't require scanning the leaves. If
+	// zero, the table isn't maintained.
+	TreeStatsShards int
+	// TreeUsageShards, if positive, is the number of rows of the TreeUsage
+	// table which the storage written for each tree is spread across. The
+	// rows are updated by the transactions which write leaves and subtrees,
+	// which enforce the max_storage_bytes of trees. If zero, the table isn't
+	// maintained, and max_storage_bytes isn't enforced.
+	TreeUsageShards int
+	// ReplicaDB, if set, is a read-only replica of the database, which the
+	// transactions of SnapshotForTree run on, so that reads don't load the
+	// primary, which sequences and writes the logs. Snapshots then see the
+	// logs as of the replication lag, which must be tolerable to clients,
+	// e.g. a leaf just added may not be found yet. All other transactions
+	// run on the primary.
+	ReplicaDB *sql.DB
 }
 
 // NewLogStorage creates a storage.LogStorage instance for the specified MySQL URL.
 // It assumes storage.AdminStorage is backed by the same MySQL database as well.
 func NewLogStorage(db *sql.DB, mf monitoring.MetricFactory) storage.LogStorage {
+	return NewLogStorageWithOpts(db, mf, LogStorageOptions{})
+}
+
+// NewLogStorageWithOpts creates a storage.LogStorage instance with the given
+// options.
+func NewLogStorageWithOpts(db *sql.DB, mf monitoring.MetricFactory, opts LogStorageOptions) storage.LogStorage {
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
 	}
-	return &mySQLLogStorage{
+	m := &mySQLLogStorage{
 		admin:            NewAdminStorage(db),
 		mySQLTreeStorage: newTreeStorage(db),
 		metricFactory:    mf,
+		opts:             opts,
+	}
+	if opts.ReplicaDB != nil {
+		replicaOpts := opts
+		replicaOpts.ReplicaDB = nil
+		m.replica = NewLogStorageWithOpts(opts.ReplicaDB, mf, replicaOpts).(*mySQLLogStorage)
+	}
+	return m
+}
+
+// reader returns the storage which runs the read-only transactions of trees.
+func (m *mySQLLogStorage) reader() *mySQLLogStorage {
+	if m.replica != nil {
+		return m.replica
 	}
+	return m
 }
 
 func (m *mySQLLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	if m.replica != nil {
+		if err := m.replica.CheckDatabaseAccessible(ctx); err != nil {
+			return err
+		}
+	}
 	return m.db.PingContext(ctx)
 }
 
@@ -245,6 +315,10 @@ func (m *mySQLLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree
 		return nil, err
 	}
 
+	if features.Enabled(features.SharedSubtreeCache, tree.TreeId) {
+		ttx.shared = m.opts.SharedSubtrees
+	}
+	ttx.usageShards = m.opts.TreeUsageShards
 	ltx := &logTreeTX{
 		treeTX:   ttx,
 		ls:       m,
@@ -306,7 +380,7 @@ func (m *mySQLLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian
 }
 
 func (m *mySQLLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
-	tx, err := m.beginInternal(ctx, tree)
+	tx, err := m.reader().beginInternal(ctx, tree)
 	if err != nil && err != storage.ErrTreeNeedsInit {
 		return nil, err
 	}
@@ -383,17 +457,25 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	}
 
 	start := time.Now()
-	stx, err := t.tx.PrepareContext(ctx, selectQueuedLeavesSQL)
+	query := selectQueuedLeavesSQL
+	if t.ls.opts.QueueBuckets > 1 {
+		query = selectQueuedLeavesFromBucketsSQL
+	}
+	stx, err := t.tx.PrepareContext(ctx, query)
 	if err != nil {
-		glog.Warningf("Failed to prepare dequeue select: %s", err)
+		glog.Warningf("%sFailed to prepare dequeue select: %s", requestid.Prefix(ctx), err)
 		return nil, err
 	}
 	defer stx.Close()
 
 	leaves := make([]*trillian.LogLeaf, 0, limit)
-	rows, err := stx.QueryContext(ctx, t.treeID, cutoffTime.UnixNano(), limit)
+	args := []interface{}{t.treeID, cutoffTime.UnixNano(), limit}
+	if buckets := t.ls.opts.QueueBuckets; buckets > 1 {
+		args = []interface{}{t.treeID, buckets, cutoffTime.UnixNano(), limit}
+	}
+	rows, err := stx.QueryContext(ctx, args...)
 	if err != nil {
-		glog.Warningf("Failed to select rows for work: %s", err)
+		glog.Warningf("%sFailed to select rows for work: %s", requestid.Prefix(ctx), err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -401,7 +483,7 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	for rows.Next() {
 		leaf, dqInfo, err := t.dequeueLeaf(rows)
 		if err != nil {
-			glog.Warningf("Error dequeuing leaf: %v", err)
+			glog.Warningf("%sError dequeuing leaf: %v", requestid.Prefix(ctx), err)
 			return nil, err
 		}
 
@@ -422,12 +504,12 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 		return nil, rows.Err()
 	}
 	label := labelForTX(t)
-	observe(dequeueSelectLatency, time.Since(start), label)
+	observe(ctx, dequeueSelectLatency, time.Since(start), label)
 
 	if err != nil {
 		return nil, err
 	}
-	observe(dequeueLatency, time.Since(start), label)
+	observe(ctx, dequeueLatency, time.Since(start), label)
 	dequeuedCounter.Add(float64(len(leaves)), label)
 
 	return leaves, nil
@@ -467,6 +549,7 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 	ordLeaves := sortLeavesForInsert(leaves)
 	existingCount := 0
 	existingLeaves := make([]*trillian.LogLeaf, len(leaves))
+	var stats treeStatsDelta
 
 	for _, ol := range ordLeaves {
 		i, leaf := ol.idx, ol.leaf
@@ -478,7 +561,7 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 		}
 		_, err = t.tx.ExecContext(ctx, insertLeafDataSQL, t.treeID, leaf.LeafIdentityHash, leaf.LeafValue, leaf.ExtraData, qTimestamp.UnixNano())
 		insertDuration := time.Since(leafStart)
-		observe(queueInsertLeafLatency, insertDuration, label)
+		observe(ctx, queueInsertLeafLatency, insertDuration, label)
 		if isDuplicateErr(err) {
 			// Remember the duplicate leaf, using the requested leaf for now.
 			existingLeaves[i] = leaf
@@ -487,13 +570,14 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 			continue
 		}
 		if err != nil {
-			glog.Warningf("Error inserting %d into LeafData: %s", i, err)
+			glog.Warningf("%sError inserting %d into LeafData: %s", requestid.Prefix(ctx), i, err)
 			return nil, mysqlToGRPC(err)
 		}
 
 		// Create the work queue entry
 		args := []interface{}{
 			t.treeID,
+			queueBucket(leaf.LeafIdentityHash, t.ls.opts.QueueBuckets),
 			leaf.LeafIdentityHash,
 			leaf.MerkleLeafHash,
 		}
@@ -508,14 +592,20 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 			args...,
 		)
 		if err != nil {
-			glog.Warningf("Error inserting into Unsequenced: %s", err)
+			glog.Warningf("%sError inserting into Unsequenced: %s", requestid.Prefix(ctx), err)
 			return nil, mysqlToGRPC(err)
 		}
+		stats.unsequenced++
+		stats.addLeafData(leaf)
 		leafDuration := time.Since(leafStart)
-		observe(queueInsertEntryLatency, (leafDuration - insertDuration), label)
+		observe(ctx, queueInsertEntryLatency, (leafDuration - insertDuration), label)
+	}
+	if err := t.updateTreeStats(ctx, stats); err != nil {
+		return nil, err
 	}
+	t.usage.leafBytes += stats.leafValueBytes + stats.extraDataBytes
 	insertDuration := time.Since(start)
-	observe(queueInsertLatency, insertDuration, label)
+	observe(ctx, queueInsertLatency, insertDuration, label)
 	queuedCounter.Add(float64(len(leaves)), label)
 
 	if existingCount == 0 {
@@ -555,8 +645,8 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 	}
 	totalDuration := time.Since(start)
 	readDuration := totalDuration - insertDuration
-	observe(queueReadLatency, readDuration, label)
-	observe(queueLatency, totalDuration, label)
+	observe(ctx, queueReadLatency, readDuration, label)
+	observe(ctx, queueLatency, totalDuration, label)
 
 	return existingLeaves, nil
 }
@@ -573,7 +663,7 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 	// a savepoint installed before the first insert of the two.
 	const savepoint = "SAVEPOINT AddSequencedLeaves"
 	if _, err := t.tx.ExecContext(ctx, savepoint); err != nil {
-		glog.Errorf("Error adding savepoint: %s", err)
+		glog.Errorf("%sError adding savepoint: %s", requestid.Prefix(ctx), err)
 		return nil, mysqlToGRPC(err)
 	}
 	// TODO(pavelkalinnikov): Consider performance implication of executing this
@@ -585,6 +675,7 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 	// supplied in contiguous non-intersecting batches, the chance of having
 	// circular dependencies between transactions is significantly lower.
 	ordLeaves := sortLeavesForInsert(leaves)
+	var stats treeStatsDelta
 	for _, ol := range ordLeaves {
 		i, leaf := ol.idx, ol.leaf
 
@@ -594,7 +685,7 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 		}
 
 		if _, err := t.tx.ExecContext(ctx, savepoint); err != nil {
-			glog.Errorf("Error updating savepoint: %s", err)
+			glog.Errorf("%sError updating savepoint: %s", requestid.Prefix(ctx), err)
 			return nil, mysqlToGRPC(err)
 		}
 
@@ -611,7 +702,7 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 			// Note: No rolling back to savepoint because there is no side effect.
 			continue
 		} else if err != nil {
-			glog.Errorf("Error inserting leaves[%d] into LeafData: %s", i, err)
+			glog.Errorf("%sError inserting leaves[%d] into LeafData: %s", requestid.Prefix(ctx), i, err)
 			return nil, mysqlToGRPC(err)
 		}
 
@@ -622,19 +713,26 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 		if isDuplicateErr(err) {
 			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto()
 			if _, err := t.tx.ExecContext(ctx, "ROLLBACK TO "+savepoint); err != nil {
-				glog.Errorf("Error rolling back to savepoint: %s", err)
+				glog.Errorf("%sError rolling back to savepoint: %s", requestid.Prefix(ctx), err)
 				return nil, mysqlToGRPC(err)
 			}
 		} else if err != nil {
-			glog.Errorf("Error inserting leaves[%d] into SequencedLeafData: %s", i, err)
+			glog.Errorf("%sError inserting leaves[%d] into SequencedLeafData: %s", requestid.Prefix(ctx), i, err)
 			return nil, mysqlToGRPC(err)
+		} else {
+			stats.leaves++
+			stats.addLeafData(leaf)
 		}
 
 		// TODO(pavelkalinnikov): Load LeafData for conflicting entries.
 	}
 
+	if err := t.updateTreeStats(ctx, stats); err != nil {
+		return nil, err
+	}
+	t.usage.leafBytes += stats.leafValueBytes + stats.extraDataBytes
 	if _, err := t.tx.ExecContext(ctx, "RELEASE "+savepoint); err != nil {
-		glog.Errorf("Error releasing savepoint: %s", err)
+		glog.Errorf("%sError releasing savepoint: %s", requestid.Prefix(ctx), err)
 		return nil, mysqlToGRPC(err)
 	}
 
@@ -647,7 +745,11 @@ func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
 
 	var sequencedLeafCount int64
 
-	err := t.tx.QueryRowContext(ctx, selectSequencedLeafCountSQL, t.treeID).Scan(&sequencedLeafCount)
+	query := selectSequencedLeafCountSQL
+	if t.treeStatsEnabled() {
+		query = selectTreeStatsLeafCountSQL
+	}
+	err := t.tx.QueryRowContext(ctx, query, t.treeID).Scan(&sequencedLeafCount)
 	if err != nil {
 		glog.Warningf("Error getting sequenced leaf count: %s", err)
 	}
@@ -730,19 +832,50 @@ func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([
 }
 
 func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	count, err := t.clipLeafRange(start, count)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*trillian.LogLeaf, 0, count)
+	if err := t.streamLeavesByRangeInternal(ctx, start, count, func(leaf *trillian.LogLeaf) error {
+		ret = append(ret, leaf)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+var _ storage.LeafStreamer = &logTreeTX{}
+
+// StreamLeavesByRange calls fn with each leaf in [start, start+count), read
+// with a single query. It implements storage.LeafStreamer.
+func (t *logTreeTX) StreamLeavesByRange(ctx context.Context, start, count int64, fn func(*trillian.LogLeaf) error) error {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+	count, err := t.clipLeafRange(start, count)
+	if err != nil {
+		return err
+	}
+	return t.streamLeavesByRangeInternal(ctx, start, count, fn)
+}
+
+// clipLeafRange checks the range of leaves [start, start+count), and returns
+// its count clipped to the size of the tree for LOG trees.
+func (t *logTreeTX) clipLeafRange(start, count int64) (int64, error) {
 	if count <= 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+		return 0, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
 	}
 	if start < 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
+		return 0, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
 	}
 
 	if t.treeType == trillian.TreeType_LOG {
 		treeSize := int64(t.root.TreeSize)
 		if treeSize <= 0 {
-			return nil, status.Errorf(codes.OutOfRange, "empty tree")
+			return 0, status.Errorf(codes.OutOfRange, "empty tree")
 		} else if start >= treeSize {
-			return nil, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
+			return 0, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
 		}
 		// Ensure no entries queried/returned beyond the tree.
 		if maxCount := treeSize - start; count > maxCount {
@@ -750,16 +883,20 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 		}
 	}
 	// TODO(pavelkalinnikov): Further clip `count` to a safe upper bound like 64k.
+	return count, nil
+}
 
-	args := []interface{}{start, start + count, t.treeID}
+// streamLeavesByRangeInternal calls fn with each leaf in the checked range
+// [start, start+count), as they are scanned.
+func (t *logTreeTX) streamLeavesByRangeInternal(ctx context.Context, start, count int64, fn func(*trillian.LogLeaf) error) error {
+	args := []interface{}{t.treeID, start, start + count}
 	rows, err := t.tx.QueryContext(ctx, selectLeavesByRangeSQL, args...)
 	if err != nil {
 		glog.Warningf("Failed to get leaves by range: %s", err)
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	ret := make([]*trillian.LogLeaf, 0, count)
 	for wantIndex := start; rows.Next(); wantIndex++ {
 		leaf := &trillian.LogLeaf{}
 		var qTimestamp, iTimestamp int64
@@ -772,31 +909,32 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 			&qTimestamp,
 			&iTimestamp); err != nil {
 			glog.Warningf("Failed to scan merkle leaves: %s", err)
-			return nil, err
+			return err
 		}
 		if leaf.LeafIndex != wantIndex {
 			if wantIndex < int64(t.root.TreeSize) {
-				return nil, fmt.Errorf("got unexpected index %d, want %d", leaf.LeafIndex, wantIndex)
+				return fmt.Errorf("got unexpected index %d, want %d", leaf.LeafIndex, wantIndex)
 			}
 			break
 		}
 		var err error
 		leaf.QueueTimestamp, err = ptypes.TimestampProto(time.Unix(0, qTimestamp))
 		if err != nil {
-			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+			return fmt.Errorf("got invalid queue timestamp: %v", err)
 		}
 		leaf.IntegrateTimestamp, err = ptypes.TimestampProto(time.Unix(0, iTimestamp))
 		if err != nil {
-			return nil, fmt.Errorf("got invalid integrate timestamp: %v", err)
+			return fmt.Errorf("got invalid integrate timestamp: %v", err)
+		}
+		if err := fn(leaf); err != nil {
+			return err
 		}
-		ret = append(ret, leaf)
 	}
 	if err := rows.Err(); err != nil {
 		glog.Warningf("Failed to read returned leaves: %s", err)
-		return nil, err
+		return err
 	}
-
-	return ret, nil
+	return nil
 }
 
 func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
@@ -813,7 +951,8 @@ func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, or
 
 // getLeafDataByIdentityHash retrieves leaf data by LeafIdentityHash, returned
 // as a slice of LogLeaf objects for convenience.  However, note that the
-// returned LogLeaf objects will not have a valid MerkleLeafHash, LeafIndex, or IntegrateTimestamp.
+// returned LogLeaf objects will only have a valid MerkleLeafHash, LeafIndex and
+// IntegrateTimestamp if they have been integrated.
 func (t *logTreeTX) getLeafDataByIdentityHash(ctx context.Context, leafHashes [][]byte) ([]*trillian.LogLeaf, error) {
 	tmpl, err := t.ls.getLeavesByLeafIdentityHashStmt(ctx, len(leafHashes))
 	if err != nil {
@@ -879,6 +1018,15 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 		return fmt.Errorf("unimplemented: mysql storage does not support log root metadata")
 	}
 
+	stored, err := latestSignerEpoch(ctx, t.tx, selectTreeHeadEpochSQL, t.treeID)
+	if err != nil {
+		return err
+	}
+	epoch, err := storage.CheckSignerEpoch(ctx, t.treeID, stored)
+	if err != nil {
+		return err
+	}
+
 	res, err := t.tx.ExecContext(
 		ctx,
 		insertTreeHeadSQL,
@@ -887,7 +1035,8 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 		logRoot.TreeSize,
 		logRoot.RootHash,
 		logRoot.Revision,
-		root.LogRootSignature)
+		root.LogRootSignature,
+		epoch)
 	if err != nil {
 		glog.Warningf("Failed to store signed root: %s", err)
 	}
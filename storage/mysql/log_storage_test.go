@@ -42,7 +42,7 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 )
 
-var allTables = []string{"Unsequenced", "TreeHead", "SequencedLeafData", "LeafData", "Subtree", "TreeControl", "Trees", "MapLeaf", "MapHead"}
+var allTables = []string{"Unsequenced", "DeadLetter", "TreeStats", "TreeUsage", "TreeHead", "SequencedLeafData", "LeafData", "Subtree", "TreeControl", "Trees", "MapLeaf", "MapHead", "MapWriteBatch", "MapMutation", "MapRootSignatures"}
 
 // Must be 32 bytes to match sha256 length if it was a real hash
 var (
@@ -505,16 +505,29 @@ func TestGetLeafDataByIdentityHash(t *testing.T) {
 	s := NewLogStorage(DB, nil)
 	data := []byte("some data")
 	leaf := createFakeLeaf(ctx, DB, tree.TreeId, dummyRawHash, dummyHash, data, someExtraData, sequenceNumber, t)
-	leaf.LeafIndex = -1
-	leaf.MerkleLeafHash = []byte(dummyMerkleLeafHash)
 	leaf2 := createFakeLeaf(ctx, DB, tree.TreeId, dummyHash2, dummyHash2, data, someExtraData, sequenceNumber+1, t)
-	leaf2.LeafIndex = -1
-	leaf2.MerkleLeafHash = []byte(dummyMerkleLeafHash)
+	// Create fake leaf as if it had only been queued.
+	queuedHash := []byte("QUEUEDxxhashxxxxhashxxxxhashxxxx")
+	if _, err := DB.ExecContext(ctx, "INSERT INTO LeafData(TreeId, LeafIdentityHash, LeafValue, ExtraData, QueueTimestampNanos) VALUES(?,?,?,?,?)", tree.TreeId, queuedHash, data, someExtraData, fakeQueueTime.UnixNano()); err != nil {
+		t.Fatalf("Failed to create test leaf: %v", err)
+	}
+	queuedLeaf := &trillian.LogLeaf{
+		MerkleLeafHash:   []byte(dummyMerkleLeafHash),
+		LeafValue:        data,
+		ExtraData:        someExtraData,
+		LeafIndex:        -1,
+		LeafIdentityHash: queuedHash,
+		QueueTimestamp:   leaf.QueueTimestamp,
+	}
 
 	tests := []struct {
 		hashes [][]byte
 		want   []*trillian.LogLeaf
 	}{
+		{
+			hashes: [][]byte{queuedHash},
+			want:   []*trillian.LogLeaf{queuedLeaf},
+		},
 		{
 			hashes: [][]byte{dummyRawHash},
 			want:   []*trillian.LogLeaf{leaf},
@@ -670,6 +683,132 @@ func TestGetLeavesByIndex(t *testing.T) {
 
 // -----------------------------------------------------------------------------
 
+func TestGetLeavesByRange(t *testing.T) {
+	for _, test := range []struct {
+		desc    string
+		tree    *trillian.Tree
+		start   int64
+		count   int64
+		want    []int64
+		wantErr bool
+	}{
+		{desc: "first", tree: testonly.LogTree, start: 0, count: 1, want: []int64{0}},
+		{desc: "middle", tree: testonly.LogTree, start: 1, count: 3, want: []int64{1, 2, 3}},
+		{desc: "clipped-to-tree-size", tree: testonly.LogTree, start: 10, count: 7, want: []int64{10, 11, 12, 13}},
+		{desc: "after-tree-size", tree: testonly.LogTree, start: 14, count: 4, wantErr: true},
+		{desc: "non-contiguous", tree: testonly.LogTree, start: 3, count: 5, wantErr: true},
+		{desc: "missing-start", tree: testonly.LogTree, start: 5, count: 5, wantErr: true},
+		{desc: "empty-range", tree: testonly.LogTree, start: 1, count: 0, wantErr: true},
+		{desc: "negative-start", tree: testonly.LogTree, start: -1, count: 1, wantErr: true},
+		{desc: "preordered-beyond-tree-size", tree: testonly.PreorderedLogTree, start: 14, count: 4, want: []int64{14, 15, 16, 17}},
+		{desc: "preordered-end-of-leaves", tree: testonly.PreorderedLogTree, start: 19, count: 2, want: []int64{19}},
+		{desc: "preordered-after-all-leaves", tree: testonly.PreorderedLogTree, start: 100, count: 30, want: []int64{}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			cleanTestDB(DB)
+			tree := mustCreateTree(ctx, t, NewAdminStorage(DB), test.tree)
+			s := NewLogStorage(DB, nil)
+			mustSignAndStoreLogRoot(ctx, t, s, tree, 14)
+
+			// Create leaves [0]..[19] except for [5].
+			for i := int64(0); i < 20; i++ {
+				if i == 5 {
+					continue
+				}
+				data := []byte{byte(i)}
+				hash := sha256.Sum256(data)
+				createFakeLeaf(ctx, DB, tree.TreeId, hash[:], hash[:], data, someExtraData, i, t)
+			}
+
+			runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+				got := []int64{}
+				err := tx.(storage.LeafStreamer).StreamLeavesByRange(ctx, test.start, test.count, func(leaf *trillian.LogLeaf) error {
+					got = append(got, leaf.LeafIndex)
+					return nil
+				})
+				if gotErr := err != nil; gotErr != test.wantErr {
+					t.Fatalf("StreamLeavesByRange(%d, +%d)=%v; want err %v", test.start, test.count, err, test.wantErr)
+				}
+				if diff := cmp.Diff(got, test.want); err == nil && diff != "" {
+					t.Errorf("StreamLeavesByRange(%d, +%d) diff (-got +want):\n%v", test.start, test.count, diff)
+				}
+
+				leaves, err := tx.GetLeavesByRange(ctx, test.start, test.count)
+				if gotErr := err != nil; gotErr != test.wantErr {
+					t.Fatalf("GetLeavesByRange(%d, +%d)=_,%v; want err %v", test.start, test.count, err, test.wantErr)
+				}
+				if err != nil {
+					return nil
+				}
+				got = make([]int64, 0, len(leaves))
+				for _, leaf := range leaves {
+					if want := []byte{byte(leaf.LeafIndex)}; !bytes.Equal(leaf.LeafValue, want) {
+						t.Errorf("GetLeavesByRange(): leaf %d has value %x, want %x", leaf.LeafIndex, leaf.LeafValue, want)
+					}
+					got = append(got, leaf.LeafIndex)
+				}
+				if diff := cmp.Diff(got, test.want); diff != "" {
+					t.Errorf("GetLeavesByRange(%d, +%d) diff (-got +want):\n%v", test.start, test.count, diff)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+// BenchmarkGetLeavesByRange reads pages of leaves from a log, which should
+// take time proportional to the page size rather than to the size of the log.
+func BenchmarkGetLeavesByRange(b *testing.B) {
+	const (
+		numLeaves = 10000
+		pageSize  = 256
+	)
+	ctx := context.Background()
+	cleanTestDB(DB)
+	tree, err := storage.CreateTree(ctx, NewAdminStorage(DB), testonly.LogTree)
+	if err != nil {
+		b.Fatalf("CreateTree(): %v", err)
+	}
+	s := NewLogStorage(DB, nil)
+	if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		root, err := tcrypto.NewSigner(0, ttestonly.NewSignerWithFixedSig(nil, []byte("notnil")), crypto.SHA256).SignLogRoot(&types.LogRootV1{TreeSize: numLeaves, RootHash: []byte{0}})
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, root)
+	}); err != nil {
+		b.Fatalf("Failed to store root: %v", err)
+	}
+	leaves := createTestLeaves(numLeaves, 0)
+	for i := 0; i < numLeaves; i += pageSize {
+		end := i + pageSize
+		if end > numLeaves {
+			end = numLeaves
+		}
+		if _, err := s.AddSequencedLeaves(ctx, tree, leaves[i:end], fakeIntegrateTime); err != nil {
+			b.Fatalf("AddSequencedLeaves(): %v", err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := int64(i*pageSize) % (numLeaves - pageSize)
+		if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			got, err := tx.GetLeavesByRange(ctx, start, pageSize)
+			if err != nil {
+				return err
+			}
+			if len(got) != pageSize {
+				return fmt.Errorf("got %d leaves, want %d", len(got), pageSize)
+			}
+			return nil
+		}); err != nil {
+			b.Fatalf("GetLeavesByRange(): %v", err)
+		}
+	}
+}
+
 func TestLatestSignedRootNoneWritten(t *testing.T) {
 	ctx := context.Background()
 
@@ -801,6 +940,50 @@ func TestLogRootUpdate(t *testing.T) {
 	})
 }
 
+func TestSignedLogRootEpoch(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+
+	signer := tcrypto.NewSigner(tree.TreeId, ttestonly.NewSignerWithFixedSig(nil, []byte("notempty")), crypto.SHA256)
+	for i, test := range []struct {
+		epoch    int64 // 0 means no epoch
+		wantCode codes.Code
+	}{
+		{epoch: 5},
+		{epoch: 3, wantCode: codes.FailedPrecondition},
+		{}, // Inherits epoch 5.
+		{epoch: 4, wantCode: codes.FailedPrecondition}, // Still lower than 5.
+		{epoch: 5},
+		{epoch: 6},
+	} {
+		err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			rev, err := tx.WriteRevision(ctx)
+			if err != nil {
+				return err
+			}
+			root, err := signer.SignLogRoot(&types.LogRootV1{
+				TimestampNanos: uint64(98765 + rev),
+				TreeSize:       16,
+				Revision:       uint64(rev),
+				RootHash:       []byte(dummyHash),
+			})
+			if err != nil {
+				t.Fatalf("SignLogRoot(): %v", err)
+			}
+			if test.epoch != 0 {
+				ctx = storage.WithSignerEpoch(ctx, test.epoch)
+			}
+			return tx.StoreSignedLogRoot(ctx, root)
+		})
+		if got, want := status.Code(err), test.wantCode; got != want {
+			t.Errorf("%d: StoreSignedLogRoot() with epoch %d: %v, want code %v", i, test.epoch, err, want)
+		}
+	}
+}
+
 func TestGetActiveLogIDs(t *testing.T) {
 	ctx := context.Background()
 
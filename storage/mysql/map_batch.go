@@ -0,0 +1,159 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	selectMapWriteBatchSQL          = "SELECT MapRevision, BatchId FROM MapWriteBatch WHERE TreeId=?"
+	selectMapWriteBatchForUpdateSQL = selectMapWriteBatchSQL + " FOR UPDATE"
+	// checkMapWriteBatchSQL holds a shared lock on the batch marker until the
+	// end of the transaction, so that the batch can't be taken over while any
+	// of its transactions is still writing.
+	checkMapWriteBatchSQL  = "SELECT BatchId FROM MapWriteBatch WHERE TreeId=? AND MapRevision=? LOCK IN SHARE MODE"
+	insertMapWriteBatchSQL = "INSERT INTO MapWriteBatch(TreeId, MapRevision, BatchId) VALUES(?, ?, ?)"
+	deleteMapWriteBatchSQL = "DELETE FROM MapWriteBatch WHERE TreeId=?"
+	deleteUncommittedSQL   = "DELETE FROM Subtree WHERE TreeId=? AND SubtreeRevision>=?"
+	commitMapWriteBatchSQL = "DELETE FROM MapWriteBatch WHERE TreeId=? AND MapRevision=? AND BatchId=?"
+)
+
+// claimedMapWriteBatchKey marks the contexts passed to the functions of
+// read-write transactions with the map write batch which the transaction
+// claimed, so that the transactions started from them don't claim it again.
+type claimedMapWriteBatchKey struct{}
+
+// withClaimedMapWriteBatch returns ctx marked as coming from a transaction
+// which claimed the map write batch it carries, if any.
+func withClaimedMapWriteBatch(ctx context.Context) context.Context {
+	batch, _, ok := storage.MapWriteBatchFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, claimedMapWriteBatchKey{}, batch)
+}
+
+// claimMapRevision makes the map write batch carried by ctx, if any, the
+// owner of revision rev of the tree, before the first transaction of the
+// batch writes anything. If another batch owns the tree, it failed before
+// storing a root, and the tiles it wrote at rev or above are deleted first.
+// Without a batch in ctx, only the writes of a failed batch are deleted.
+//
+// The marker is claimed in a transaction of its own, which must commit before
+// any transaction of the batch writes, so that a failed batch is always
+// visible to the next writer. Later transactions of the batch, which are
+// started from the context passed to the function of the first one, don't
+// claim the revision again, so they can't take it back from a batch which
+// took it over.
+func (m *mySQLMapStorage) claimMapRevision(ctx context.Context, treeID, rev int64) error {
+	batch, batchRev, ok := storage.MapWriteBatchFromContext(ctx)
+	if ok && batchRev != rev {
+		// The batch can't write at rev, and its transactions fail if they try.
+		return nil
+	}
+	if claimed, _ := ctx.Value(claimedMapWriteBatchKey{}).(string); ok && claimed == batch {
+		return nil
+	}
+	var ownerRev int64
+	var owner string
+	switch err := m.db.QueryRowContext(ctx, selectMapWriteBatchSQL, treeID).Scan(&ownerRev, &owner); {
+	case err == sql.ErrNoRows:
+		if batch == "" {
+			return nil
+		}
+	case err != nil:
+		return err
+	case owner == batch && ownerRev == rev:
+		// Already claimed by an earlier transaction of the batch.
+		return nil
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the marker waits for the transactions of its owner which are
+	// still running, as they hold shared locks on it.
+	switch err := tx.QueryRowContext(ctx, selectMapWriteBatchForUpdateSQL, treeID).Scan(&ownerRev, &owner); {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return err
+	case owner == batch && ownerRev == rev:
+		return nil
+	default:
+		glog.Warningf("%v: Removing writes of failed map write batch %q at revision %d", treeID, owner, ownerRev)
+		// No root is stored above rev-1, so nothing at or above rev has been
+		// committed.
+		if _, err := tx.ExecContext(ctx, deleteUncommittedSQL, treeID, rev); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, deleteMapWriteBatchSQL, treeID); err != nil {
+			return err
+		}
+	}
+	if batch != "" {
+		if _, err := tx.ExecContext(ctx, insertMapWriteBatchSQL, treeID, rev, batch); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// checkMapWriteBatch checks that the map write batch carried by ctx, if any,
+// still owns the write revision of the transaction, and stops it from being
+// taken over until the transaction ends.
+func (m *mapTreeTX) checkMapWriteBatch(ctx context.Context) error {
+	batch, rev, ok := storage.MapWriteBatchFromContext(ctx)
+	if !ok || rev != m.writeRevision {
+		return nil
+	}
+	var owner string
+	err := m.tx.QueryRowContext(ctx, checkMapWriteBatchSQL, m.treeID, m.writeRevision).Scan(&owner)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if owner != batch {
+		return status.Errorf(codes.Aborted, "tree %d: revision %d is no longer owned by map write batch %q", m.treeID, m.writeRevision, batch)
+	}
+	return nil
+}
+
+// commitMapWriteBatch removes the marker of the map write batch carried by
+// ctx, if any, in the transaction which stores the root of the batch.
+func (m *mapTreeTX) commitMapWriteBatch(ctx context.Context, rev int64) error {
+	batch, _, ok := storage.MapWriteBatchFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	res, err := m.tx.ExecContext(ctx, commitMapWriteBatchSQL, m.treeID, rev, batch)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n != 1 {
+		return status.Errorf(codes.Aborted, "tree %d: revision %d is no longer owned by map write batch %q", m.treeID, rev, batch)
+	}
+	return nil
+}
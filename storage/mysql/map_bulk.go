@@ -0,0 +1,227 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/compress"
+	"github.com/google/trillian/storage/storagepb/convert"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// bulkWriteRevision is the revision of maps written by MapBulkWriter.
+	bulkWriteRevision = 1
+	// bulkWriteStatementRows is the maximum number of rows inserted by each
+	// statement of a bulk write. Tiles can be large, so this keeps the
+	// statements well within the default max_allowed_packet.
+	bulkWriteStatementRows = 500
+
+	selectLatestMapRevisionSQL = "SELECT COALESCE(MAX(MapRevision), -1) FROM MapHead WHERE TreeId=?"
+	deleteUncommittedLeavesSQL = "DELETE FROM MapLeaf WHERE TreeId=? AND MapRevision>=?"
+)
+
+// BeginBulkWrite implements storage.MapBulkWriter.
+func (m *mySQLMapStorage) BeginBulkWrite(ctx context.Context, tree *trillian.Tree) error {
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkFreshMap(ctx, tx, tree.TreeId); err != nil {
+		return err
+	}
+	// No root is stored above revision 0, so nothing written at revision 1
+	// or above has been committed.
+	if err := m.deleteUncommittedWrites(ctx, tx, tree.TreeId, bulkWriteRevision); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// deleteUncommittedWrites deletes the leaves and tiles of the map written at
+// revision rev or above, none of which may have a root, along with any claim
+// of a map write batch.
+func (m *mySQLMapStorage) deleteUncommittedWrites(ctx context.Context, tx *sql.Tx, treeID, rev int64) error {
+	if _, err := tx.ExecContext(ctx, deleteUncommittedSQL, treeID, rev); err != nil {
+		return err
+	}
+	for shard := 0; shard < m.leafShards(); shard++ {
+		if _, err := tx.ExecContext(ctx, m.leafSQL(deleteUncommittedLeavesSQL, shard), treeID, rev); err != nil {
+			return err
+		}
+	}
+	_, err := tx.ExecContext(ctx, deleteMapWriteBatchSQL, treeID)
+	return err
+}
+
+// checkFreshMap checks that the map has a root at revision 0, and none above.
+func checkFreshMap(ctx context.Context, tx *sql.Tx, treeID int64) error {
+	var rev int64
+	if err := tx.QueryRowContext(ctx, selectLatestMapRevisionSQL, treeID).Scan(&rev); err != nil {
+		return err
+	}
+	switch {
+	case rev < 0:
+		return storage.ErrTreeNeedsInit
+	case rev > 0:
+		return status.Errorf(codes.FailedPrecondition, "map %d already has revision %d, can only bulk write fresh maps", treeID, rev)
+	}
+	return nil
+}
+
+// BulkWriteLeaves implements storage.MapBulkWriter.
+func (m *mySQLMapStorage) BulkWriteLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf) error {
+	return m.writeLeavesAt(ctx, tree, bulkWriteRevision, leaves)
+}
+
+// writeLeavesAt writes the given leaves at revision rev of the map, skipping
+// deletions, in as few statements as bulkWriteStatementRows allows.
+func (m *mySQLMapStorage) writeLeavesAt(ctx context.Context, tree *trillian.Tree, rev int64, leaves []*trillian.MapLeaf) error {
+	rows := make([][]interface{}, m.leafShards())
+	var usage treeUsageDelta
+	for _, leaf := range leaves {
+		if storage.IsMapLeafDeletion(leaf) {
+			continue
+		}
+		value, err := proto.Marshal(leaf)
+		if err != nil {
+			return err
+		}
+		if value, err = compress.Compress(tree.MapCompression, value); err != nil {
+			return err
+		}
+		var key []byte
+		if tree.MapKeyIndex {
+			key = storage.MapLeafIndexedKey(leaf)
+		}
+		shard := m.leafShard(leaf.Index)
+		rows[shard] = append(rows[shard], tree.TreeId, leaf.Index, rev, value, key)
+		usage.leafBytes += int64(len(value))
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for shard, args := range rows {
+		if err := m.bulkInsert(ctx, tx, m.leafSQL(insertMapLeafMultiSQL, shard), 5, args); err != nil {
+			return err
+		}
+	}
+	if err := updateTreeUsage(ctx, tx, tree.TreeId, m.opts.TreeUsageShards, tree.MaxStorageBytes, usage); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// BulkWriteTiles implements storage.MapBulkWriter.
+func (m *mySQLMapStorage) BulkWriteTiles(ctx context.Context, tree *trillian.Tree, tiles []smt.Tile) error {
+	return m.writeTilesAt(ctx, tree, bulkWriteRevision, tiles)
+}
+
+// writeTilesAt writes the given tiles at revision rev of the map.
+func (m *mySQLMapStorage) writeTilesAt(ctx context.Context, tree *trillian.Tree, rev int64, tiles []smt.Tile) error {
+	layout, err := m.Layout(tree)
+	if err != nil {
+		return err
+	}
+	args := make([]interface{}, 0, 4*len(tiles))
+	var usage treeUsageDelta
+	for _, tile := range tiles {
+		height := layout.TileHeight(int(tile.ID.BitLen()))
+		pb, err := convert.Marshal(tile, uint(height))
+		if err != nil {
+			return err
+		}
+		b, err := proto.Marshal(pb)
+		if err != nil {
+			return err
+		}
+		if b, err = compress.Compress(tree.MapCompression, b); err != nil {
+			return err
+		}
+		args = append(args, tree.TreeId, pb.Prefix, b, rev)
+		usage.subtreeBytes += int64(len(b))
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := m.bulkInsert(ctx, tx, insertSubtreeMultiSQL, 4, args); err != nil {
+		return err
+	}
+	if err := updateTreeUsage(ctx, tx, tree.TreeId, m.opts.TreeUsageShards, tree.MaxStorageBytes, usage); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// bulkInsert inserts rows of the given number of columns, given as a flat
+// list of their values, with the given multi-row INSERT statement, in as few
+// statements as bulkWriteStatementRows allows.
+func (m *mySQLMapStorage) bulkInsert(ctx context.Context, tx *sql.Tx, statement string, columns int, args []interface{}) error {
+	row := "(?" + strings.Repeat(", ?", columns-1) + ")"
+	for len(args) > 0 {
+		n := len(args) / columns
+		if n > bulkWriteStatementRows {
+			n = bulkWriteStatementRows
+		}
+		stmt, err := m.getStmt(ctx, statement, n, "VALUES"+row, row)
+		if err != nil {
+			return err
+		}
+		stx := tx.StmtContext(ctx, stmt)
+		res, err := stx.ExecContext(ctx, args[:columns*n]...)
+		stx.Close()
+		if err := checkResultOkAndRowCountIs(res, err, int64(n)); err != nil {
+			return err
+		}
+		args = args[columns*n:]
+	}
+	return nil
+}
+
+// CommitBulkWrite implements storage.MapBulkWriter.
+func (m *mySQLMapStorage) CommitBulkWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	var r types.MapRootV1
+	if err := r.UnmarshalBinary(root.MapRoot); err != nil {
+		return err
+	}
+	if r.Revision != bulkWriteRevision {
+		return status.Errorf(codes.InvalidArgument, "map %d: bulk written root has revision %d, want %d", tree.TreeId, r.Revision, bulkWriteRevision)
+	}
+	return m.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if rev, err := tx.WriteRevision(ctx); err != nil {
+			return err
+		} else if rev != bulkWriteRevision {
+			return status.Errorf(codes.FailedPrecondition, "map %d already has revision %d, can only bulk write fresh maps", tree.TreeId, rev-1)
+		}
+		return tx.StoreSignedMapRoot(ctx, root)
+	})
+}
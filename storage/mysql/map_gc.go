@@ -0,0 +1,195 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/storage"
+)
+
+const (
+	selectMapRevisionAtSQL = "SELECT COALESCE(MAX(MapRevision), -1) FROM MapHead WHERE TreeId=? AND MapHeadTimestamp<=?"
+	// deleteOldMapHeadsSQL deletes the roots of revisions before a horizon,
+	// other than that of revision 0, which reads of an initialized map need.
+	deleteOldMapHeadsSQL = "DELETE FROM MapHead WHERE TreeId=? AND MapRevision>0 AND MapRevision<? LIMIT ?"
+	// selectSupersededMapLeavesSQL selects the revisions of leaves which are
+	// older than the latest revision of the same key at or below a horizon.
+	selectSupersededMapLeavesSQL = `SELECT l.KeyHash, l.MapRevision
+		FROM MapLeaf l
+		INNER JOIN (
+			SELECT KeyHash, MAX(MapRevision) AS Horizon
+			FROM MapLeaf
+			WHERE TreeId=? AND MapRevision<=?
+			GROUP BY KeyHash
+		) h ON l.KeyHash=h.KeyHash
+		WHERE l.TreeId=? AND l.MapRevision<h.Horizon
+		LIMIT ?`
+	// selectOldMapLeafDeletionsSQL selects the deletions of keys at or below
+	// a horizon which have no earlier revisions left to mask, so reads see
+	// the keys as deleted without them.
+	selectOldMapLeafDeletionsSQL = `SELECT l.KeyHash, l.MapRevision
+		FROM MapLeaf l
+		WHERE l.TreeId=? AND l.MapRevision<=? AND LENGTH(l.LeafValue)=0
+		AND NOT EXISTS (
+			SELECT 1 FROM MapLeaf e
+			WHERE e.TreeId=l.TreeId AND e.KeyHash=l.KeyHash AND e.MapRevision<l.MapRevision
+		)
+		LIMIT ?`
+	deleteMapLeafRevisionSQL = "DELETE FROM MapLeaf WHERE TreeId=? AND KeyHash=? AND MapRevision=?"
+)
+
+var _ storage.MapRevisionGCTX = &mapTreeTX{}
+
+// RevisionAt returns the latest revision of the map whose root was written no
+// later than the given time, or -1 if there is none.
+func (m *mapTreeTX) RevisionAt(ctx context.Context, at time.Time) (int64, error) {
+	m.treeTX.mu.Lock()
+	defer m.treeTX.mu.Unlock()
+
+	var rev int64
+	if err := m.tx.QueryRowContext(ctx, selectMapRevisionAtSQL, m.treeID, at.UnixNano()).Scan(&rev); err != nil {
+		return 0, mysqlToGRPC(err)
+	}
+	return rev, nil
+}
+
+// DeleteRevisionsBefore deletes the roots of the revisions of the map before
+// horizon, along with their co-signatures, and then the revisions of its
+// leaves and tiles which only those revisions need, in all of the MapLeaf
+// table shards.
+func (m *mapTreeTX) DeleteRevisionsBefore(ctx context.Context, horizon int64, limit int) (int, error) {
+	m.treeTX.mu.Lock()
+	defer m.treeTX.mu.Unlock()
+
+	deleted, err := m.exec(ctx, deleteOldMapHeadsSQL, m.treeID, horizon, limit)
+	if err != nil {
+		return 0, err
+	}
+	if deleted < limit {
+		n, err := m.exec(ctx, deleteOldMapRootSignaturesSQL, m.treeID, horizon, limit-deleted)
+		if err != nil {
+			return 0, err
+		}
+		deleted += n
+	}
+
+	for shard := 0; shard < m.ms.leafShards() && deleted < limit; shard++ {
+		n, err := m.deleteLeafRevisions(ctx, shard, selectSupersededMapLeavesSQL, m.treeID, horizon, m.treeID, limit-deleted)
+		if err != nil {
+			return 0, err
+		}
+		deleted += n
+	}
+	// Deletions are only dropped once the revisions they mask are gone.
+	for shard := 0; shard < m.ms.leafShards() && deleted < limit; shard++ {
+		n, err := m.deleteLeafRevisions(ctx, shard, selectOldMapLeafDeletionsSQL, m.treeID, horizon, limit-deleted)
+		if err != nil {
+			return 0, err
+		}
+		deleted += n
+	}
+
+	if deleted < limit {
+		// MySQL doesn't allow LIMIT in a DELETE joining Subtree with itself,
+		// so select the revisions first, and delete them one by one.
+		revs, err := m.selectSupersededSubtrees(ctx, horizon, limit-deleted)
+		if err != nil {
+			return 0, err
+		}
+		for _, r := range revs {
+			n, err := m.exec(ctx, deleteSubtreeRevisionSQL, m.treeID, r.id, r.rev)
+			if err != nil {
+				return 0, err
+			}
+			deleted += n
+		}
+	}
+	return deleted, nil
+}
+
+// deleteLeafRevisions deletes the leaf revisions selected by the given query
+// with args from the given MapLeaf table shard.
+func (m *mapTreeTX) deleteLeafRevisions(ctx context.Context, shard int, query string, args ...interface{}) (int, error) {
+	rows, err := m.tx.QueryContext(ctx, m.ms.leafSQL(query, shard), args...)
+	if err != nil {
+		glog.Warningf("Failed to select old map leaves: %s", err)
+		return 0, mysqlToGRPC(err)
+	}
+	var revs []subtreeRevision
+	for rows.Next() {
+		var r subtreeRevision
+		if err := rows.Scan(&r.id, &r.rev); err != nil {
+			rows.Close()
+			glog.Warningf("Error scanning MapLeaf rows: %s", err)
+			return 0, err
+		}
+		revs = append(revs, r)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, r := range revs {
+		n, err := m.exec(ctx, m.ms.leafSQL(deleteMapLeafRevisionSQL, shard), m.treeID, r.id, r.rev)
+		if err != nil {
+			return 0, err
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// selectSupersededSubtrees returns up to limit revisions of tiles which are
+// superseded by a later revision no later than horizon.
+func (m *mapTreeTX) selectSupersededSubtrees(ctx context.Context, horizon int64, limit int) ([]subtreeRevision, error) {
+	rows, err := m.tx.QueryContext(ctx, selectSupersededSubtreesSQL, m.treeID, horizon, m.treeID, limit)
+	if err != nil {
+		glog.Warningf("Failed to select superseded subtrees: %s", err)
+		return nil, mysqlToGRPC(err)
+	}
+	defer rows.Close()
+
+	var revs []subtreeRevision
+	for rows.Next() {
+		var r subtreeRevision
+		if err := rows.Scan(&r.id, &r.rev); err != nil {
+			glog.Warningf("Error scanning Subtree rows: %s", err)
+			return nil, err
+		}
+		revs = append(revs, r)
+	}
+	return revs, rows.Err()
+}
+
+// exec runs the given statement in the transaction, and returns the number of
+// rows it affected.
+func (m *mapTreeTX) exec(ctx context.Context, query string, args ...interface{}) (int, error) {
+	res, err := m.tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, mysqlToGRPC(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, mysqlToGRPC(err)
+	}
+	return int(n), nil
+}
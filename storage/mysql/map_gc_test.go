@@ -0,0 +1,129 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testdb"
+)
+
+func TestMapDeleteRevisionsBefore(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	s := NewMapStorage(DB)
+	tree := createInitializedMapForTests(ctx, t, s, as)
+
+	for rev := int64(1); rev <= 5; rev++ {
+		if _, err := DB.ExecContext(ctx, "INSERT INTO MapHead(TreeId, MapHeadTimestamp, RootHash, MapRevision, RootSignature, MapperData) VALUES(?, ?, ?, ?, ?, ?)",
+			tree.TreeId, rev*int64(time.Second), []byte("root"), rev, []byte("sig"), []byte{}); err != nil {
+			t.Fatalf("Failed to insert map root: %v", err)
+		}
+	}
+	// An empty value is a deletion of the key.
+	for _, row := range []struct {
+		key   string
+		rev   int64
+		value string
+	}{
+		{"a", 1, "a1"}, {"a", 2, "a2"}, {"a", 3, "a3"}, {"a", 5, "a5"},
+		{"b", 1, "b1"},
+		{"c", 1, "c1"}, {"c", 2, ""},
+		{"d", 4, "d4"},
+	} {
+		if _, err := DB.ExecContext(ctx, "INSERT INTO MapLeaf(TreeId, KeyHash, MapRevision, LeafValue) VALUES(?, ?, ?, ?)", tree.TreeId, []byte(row.key), row.rev, []byte(row.value)); err != nil {
+			t.Fatalf("Failed to insert map leaf: %v", err)
+		}
+	}
+	for _, row := range []struct {
+		id  string
+		rev int64
+	}{{"x", 1}, {"x", 3}, {"y", 4}} {
+		if _, err := DB.ExecContext(ctx, "INSERT INTO Subtree(TreeId, SubtreeId, Nodes, SubtreeRevision) VALUES(?, ?, ?, ?)", tree.TreeId, []byte(row.id), []byte("nodes"), row.rev); err != nil {
+			t.Fatalf("Failed to insert subtree: %v", err)
+		}
+	}
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		for _, tc := range []struct {
+			at   time.Duration
+			want int64
+		}{{at: 0, want: 0}, {at: 3500 * time.Millisecond, want: 3}, {at: time.Hour, want: 5}} {
+			got, err := tx.(storage.MapRevisionGCTX).RevisionAt(ctx, time.Unix(0, int64(tc.at)))
+			if err != nil {
+				t.Fatalf("RevisionAt(%v): %v", tc.at, err)
+			}
+			if got != tc.want {
+				t.Errorf("RevisionAt(%v)=%d, want %d", tc.at, got, tc.want)
+			}
+		}
+		return nil
+	})
+
+	// At horizon 3, the roots of revisions 1 and 2, a@1, a@2, c@1 and x@1 are
+	// only needed by earlier revisions, and then so is the deletion c@2 which
+	// masked c@1.
+	for _, want := range []int{4, 3, 0} {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			n, err := tx.(storage.MapRevisionGCTX).DeleteRevisionsBefore(ctx, 3, 4)
+			if err != nil {
+				t.Fatalf("DeleteRevisionsBefore(): %v", err)
+			}
+			if n != want {
+				t.Errorf("DeleteRevisionsBefore()=%d, want %d", n, want)
+			}
+			return nil
+		})
+	}
+
+	got := func(query string) []string {
+		t.Helper()
+		rows, err := DB.QueryContext(ctx, query, tree.TreeId)
+		if err != nil {
+			t.Fatalf("Failed to select rows: %v", err)
+		}
+		defer rows.Close()
+		var ret []string
+		for rows.Next() {
+			var id []byte
+			var rev int64
+			if err := rows.Scan(&id, &rev); err != nil {
+				t.Fatalf("Failed to scan row: %v", err)
+			}
+			ret = append(ret, fmt.Sprintf("%s@%d", id, rev))
+		}
+		return ret
+	}
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{query: "SELECT RootHash, MapRevision FROM MapHead WHERE TreeId=? ORDER BY MapRevision", want: []string{"rootHash@0", "root@3", "root@4", "root@5"}},
+		{query: "SELECT KeyHash, MapRevision FROM MapLeaf WHERE TreeId=? ORDER BY KeyHash, MapRevision", want: []string{"a@3", "a@5", "b@1", "d@4"}},
+		{query: "SELECT SubtreeId, SubtreeRevision FROM Subtree WHERE TreeId=? ORDER BY SubtreeId, SubtreeRevision", want: []string{"x@3", "y@4"}},
+	} {
+		if diff := cmp.Diff(got(tc.query), tc.want); diff != "" {
+			t.Errorf("%s diff (-got +want):\n%s", tc.query, diff)
+		}
+	}
+}
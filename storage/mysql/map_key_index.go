@@ -0,0 +1,124 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+)
+
+// selectMapLeafByKeySQL reads the leaves of a map at a revision whose indexed
+// keys are in a range, and follow a given key and key hash, in order of their
+// keys and key hashes. The key hashes which ever had a key in the range are
+// found with MapLeafKeyIdx, and those whose latest value at the revision is
+// still in the range are returned, which skips deleted keys.
+const selectMapLeafByKeySQL = `
+ SELECT t1.KeyHash, t1.LeafValue
+ FROM MapLeaf t1
+ INNER JOIN
+ (
+	SELECT t0.TreeId, t0.KeyHash, MAX(t0.MapRevision) AS maxrev
+	FROM MapLeaf t0
+	WHERE t0.TreeId = ? AND t0.MapRevision <= ? AND t0.KeyHash IN (
+		SELECT k.KeyHash FROM MapLeaf k
+		WHERE k.TreeId = ? AND k.MapRevision <= ?
+		AND k.LeafKey >= ? AND k.LeafKey < ?
+		AND (k.LeafKey > ? OR (k.LeafKey = ? AND k.KeyHash > ?))
+	)
+	GROUP BY t0.TreeId, t0.KeyHash
+ ) t2
+ ON t1.TreeId=t2.TreeId
+ AND t1.KeyHash=t2.KeyHash
+ AND t1.MapRevision=t2.maxrev
+ WHERE t1.LeafKey >= ? AND t1.LeafKey < ?
+ AND (t1.LeafKey > ? OR (t1.LeafKey = ? AND t1.KeyHash > ?))
+ ORDER BY t1.LeafKey, t1.KeyHash LIMIT ?`
+
+var _ storage.MapLeafKeyLister = &mapTreeTX{}
+
+// ListLeavesByKeyPrefix implements storage.MapLeafKeyLister. With
+// LeafTableShards, each of the MapLeaf tables is read in turn, and the leaves
+// read from all of them are merged.
+func (m *mapTreeTX) ListLeavesByKeyPrefix(ctx context.Context, revision int64, prefix, afterKey, afterIndex []byte, limit int) ([]*trillian.MapLeaf, error) {
+	m.treeTX.mu.Lock()
+	defer m.treeTX.mu.Unlock()
+
+	if err := m.flushLeaves(ctx); err != nil {
+		return nil, err
+	}
+	end := storage.MapLeafKeyPrefixEnd(prefix)
+	if prefix == nil {
+		// The driver binds nil byte slices as NULL.
+		prefix = []byte{}
+	}
+	if afterIndex == nil {
+		// Every key hash follows an empty one.
+		afterKey, afterIndex = prefix, []byte{}
+	}
+
+	var ret []*trillian.MapLeaf
+	for shard := 0; shard < m.ms.leafShards(); shard++ {
+		leaves, err := m.listShardLeavesByKey(ctx, shard, revision, prefix, end, afterKey, afterIndex, limit)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, leaves...)
+	}
+	if m.ms.leafShards() > 1 {
+		sort.Slice(ret, func(i, j int) bool {
+			if c := bytes.Compare(ret[i].Key, ret[j].Key); c != 0 {
+				return c < 0
+			}
+			return bytes.Compare(ret[i].Index, ret[j].Index) < 0
+		})
+		if len(ret) > limit {
+			ret = ret[:limit]
+		}
+	}
+	return ret, nil
+}
+
+// listShardLeavesByKey returns up to limit leaves of ListLeavesByKeyPrefix
+// stored in the MapLeaf table of the given shard, whose keys are in
+// [start, end).
+func (m *mapTreeTX) listShardLeavesByKey(ctx context.Context, shard int, revision int64, start, end, afterKey, afterIndex []byte, limit int) ([]*trillian.MapLeaf, error) {
+	rows, err := m.tx.QueryContext(ctx, m.ms.leafSQL(selectMapLeafByKeySQL, shard),
+		m.treeID, revision,
+		m.treeID, revision, start, end, afterKey, afterKey, afterIndex,
+		start, end, afterKey, afterKey, afterIndex,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []*trillian.MapLeaf
+	for rows.Next() {
+		var mapKeyHash, flatData []byte
+		if err := rows.Scan(&mapKeyHash, &flatData); err != nil {
+			return nil, err
+		}
+		mapLeaf, err := unmarshalMapLeaf(flatData, mapKeyHash)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, mapLeaf)
+	}
+	return ret, rows.Err()
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+)
+
+// maxMapLeafWriteBatchSize is the largest supported LeafWriteBatchSize, which
+// keeps the INSERT statements within the limit of 65535 placeholders.
+const maxMapLeafWriteBatchSize = 65535 / 5
+
+// pendingMapLeaf is a leaf buffered by mapTreeTX.Set.
+type pendingMapLeaf struct {
+	keyHash []byte
+	value   []byte
+	key     []byte
+}
+
+// bufferLeaf buffers the given leaf value and indexed key, and writes the
+// buffered leaves of its MapLeaf table shard if there are LeafWriteBatchSize
+// of them.
+func (m *mapTreeTX) bufferLeaf(ctx context.Context, keyHash, value, key []byte) error {
+	if m.pendingLeaves == nil {
+		m.pendingLeaves = make([][]pendingMapLeaf, m.ms.leafShards())
+	}
+	shard := m.ms.leafShard(keyHash)
+	m.pendingLeaves[shard] = append(m.pendingLeaves[shard], pendingMapLeaf{keyHash: keyHash, value: value, key: key})
+	if len(m.pendingLeaves[shard]) < m.ms.opts.LeafWriteBatchSize {
+		return nil
+	}
+	return m.flushShardLeaves(ctx, shard)
+}
+
+// flushLeaves writes all of the buffered leaves.
+func (m *mapTreeTX) flushLeaves(ctx context.Context) error {
+	for shard := range m.pendingLeaves {
+		if err := m.flushShardLeaves(ctx, shard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushShardLeaves writes the buffered leaves of the given MapLeaf table shard
+// with a single INSERT statement.
+func (m *mapTreeTX) flushShardLeaves(ctx context.Context, shard int) error {
+	leaves := m.pendingLeaves[shard]
+	if len(leaves) == 0 {
+		return nil
+	}
+	m.pendingLeaves[shard] = nil
+
+	args := make([]interface{}, 0, 5*len(leaves))
+	for _, l := range leaves {
+		args = append(args, m.treeID, l.keyHash, m.writeRevision, l.value, l.key)
+	}
+	stmt, err := m.ms.getStmt(ctx, m.ms.leafSQL(insertMapLeafMultiSQL, shard), len(leaves), "VALUES(?, ?, ?, ?, ?)", "(?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	stx := m.tx.StmtContext(ctx, stmt)
+	defer stx.Close()
+	res, err := stx.ExecContext(ctx, args...)
+	return checkResultOkAndRowCountIs(res, err, int64(len(leaves)))
+}
+
+// Commit writes the buffered leaves, and commits the transaction.
+func (m *mapTreeTX) Commit(ctx context.Context) error {
+	m.treeTX.mu.Lock()
+	err := m.flushLeaves(ctx)
+	m.treeTX.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return m.treeTX.Commit(ctx)
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+)
+
+const (
+	// queueMapMutationsStatementRows is the maximum number of rows inserted
+	// by each statement of QueueMapMutations.
+	queueMapMutationsStatementRows = 500
+
+	insertMapMutationMultiSQL = "INSERT INTO MapMutation(TreeId, QueueTimestampNanos, MutationId, Leaf) " + placeholderSQL
+	selectMapMutationsSQL     = `SELECT QueueTimestampNanos, MutationId, Leaf
+		 FROM MapMutation WHERE TreeId=?
+		 ORDER BY QueueTimestampNanos, MutationId LIMIT ? FOR UPDATE`
+	deleteMapMutationSQL = "DELETE FROM MapMutation WHERE TreeId=? AND QueueTimestampNanos=? AND MutationId=?"
+)
+
+var (
+	_ storage.MapMutationQueue    = &mySQLMapStorage{}
+	_ storage.MapMutationDequeuer = &mapTreeTX{}
+)
+
+// QueueMapMutations implements storage.MapMutationQueue.
+func (m *mySQLMapStorage) QueueMapMutations(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf, queueTimestamp time.Time) error {
+	if got, want := tree.TreeType, trillian.TreeType_MAP; got != want {
+		return fmt.Errorf("QueueMapMutations(tree.TreeType: %v), want %v", got, want)
+	}
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for len(leaves) > 0 {
+		n := len(leaves)
+		if n > queueMapMutationsStatementRows {
+			n = queueMapMutationsStatementRows
+		}
+		args := make([]interface{}, 0, 4*n)
+		for _, l := range leaves[:n] {
+			leaf, err := proto.Marshal(l)
+			if err != nil {
+				return err
+			}
+			args = append(args, tree.TreeId, queueTimestamp.UnixNano(), rand.Int63(), leaf)
+		}
+		stmt, err := m.getStmt(ctx, insertMapMutationMultiSQL, n, "VALUES(?, ?, ?, ?)", "(?, ?, ?, ?)")
+		if err != nil {
+			return err
+		}
+		stx := tx.StmtContext(ctx, stmt)
+		res, err := stx.ExecContext(ctx, args...)
+		stx.Close()
+		if err := checkResultOkAndRowCountIs(res, err, int64(n)); err != nil {
+			return err
+		}
+		leaves = leaves[n:]
+	}
+	return tx.Commit()
+}
+
+// DequeueMapMutations implements storage.MapMutationDequeuer. The rows of the
+// dequeued mutations are locked until the transaction ends, so concurrent
+// transactions dequeuing the same mutations wait for it.
+func (m *mapTreeTX) DequeueMapMutations(ctx context.Context, limit int) ([]*trillian.MapLeaf, error) {
+	m.treeTX.mu.Lock()
+	defer m.treeTX.mu.Unlock()
+
+	rows, err := m.tx.QueryContext(ctx, selectMapMutationsSQL, m.treeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type mutationKey struct {
+		queueTimestamp, id int64
+	}
+	var keys []mutationKey
+	var leaves []*trillian.MapLeaf
+	for rows.Next() {
+		var key mutationKey
+		var b []byte
+		if err := rows.Scan(&key.queueTimestamp, &key.id, &b); err != nil {
+			return nil, err
+		}
+		var leaf trillian.MapLeaf
+		if err := proto.Unmarshal(b, &leaf); err != nil {
+			return nil, fmt.Errorf("could not unmarshal queued MapLeaf: %v", err)
+		}
+		keys = append(keys, key)
+		leaves = append(leaves, &leaf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for _, key := range keys {
+		res, err := m.tx.ExecContext(ctx, deleteMapMutationSQL, m.treeID, key.queueTimestamp, key.id)
+		if err := checkResultOkAndRowCountIs(res, err, 1); err != nil {
+			return nil, err
+		}
+	}
+	return leaves, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testdb"
+)
+
+func TestQueueMapMutations(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	s := NewMapStorage(DB)
+	tree := createInitializedMapForTests(ctx, t, s, as)
+
+	// Queue more mutations than fit in one statement, in two batches queued at
+	// different times.
+	var leaves []*trillian.MapLeaf
+	for i := 0; i < queueMapMutationsStatementRows+10; i++ {
+		leaves = append(leaves, &trillian.MapLeaf{Index: []byte(fmt.Sprintf("key-%d", i)), LeafValue: []byte{byte(i)}})
+	}
+	now := time.Now()
+	q := s.(storage.MapMutationQueue)
+	if err := q.QueueMapMutations(ctx, tree, leaves[10:], now); err != nil {
+		t.Fatalf("QueueMapMutations(): %v", err)
+	}
+	if err := q.QueueMapMutations(ctx, tree, leaves[:10], now.Add(-time.Second)); err != nil {
+		t.Fatalf("QueueMapMutations(): %v", err)
+	}
+
+	// The mutations queued earlier are dequeued first.
+	for _, tc := range []struct{ limit, want int }{{10, 10}, {1000, queueMapMutationsStatementRows}, {1000, 0}} {
+		want := tc.want
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			got, err := tx.(storage.MapMutationDequeuer).DequeueMapMutations(ctx, tc.limit)
+			if err != nil {
+				t.Fatalf("DequeueMapMutations(): %v", err)
+			}
+			if len(got) != want {
+				t.Fatalf("DequeueMapMutations() returned %d mutations, want %d", len(got), want)
+			}
+			if want == 10 {
+				for i, l := range got {
+					if string(l.Index) != fmt.Sprintf("key-%d", i) {
+						t.Errorf("DequeueMapMutations()[%d] has index %q, want key-%d", i, l.Index, i)
+					}
+				}
+			}
+			return nil
+		})
+	}
+}
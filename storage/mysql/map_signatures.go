@@ -0,0 +1,67 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"crypto/sha256"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+)
+
+const (
+	replaceMapRootSignatureSQL = `REPLACE INTO MapRootSignatures(TreeId, MapRevision, KeyHash, Witness, PublicKey, Signature)
+		VALUES(?, ?, ?, ?, ?, ?)`
+	selectMapRootSignaturesSQL = `SELECT Witness, PublicKey, Signature
+		FROM MapRootSignatures WHERE TreeId=? AND MapRevision=?
+		ORDER BY KeyHash`
+	// deleteOldMapRootSignaturesSQL deletes the co-signatures of the roots
+	// deleted by deleteOldMapHeadsSQL.
+	deleteOldMapRootSignaturesSQL = "DELETE FROM MapRootSignatures WHERE TreeId=? AND MapRevision>0 AND MapRevision<? LIMIT ?"
+)
+
+var _ storage.MapRootSignatureTX = &mapTreeTX{}
+
+// AddMapRootSignature implements storage.MapRootSignatureTX.
+func (m *mapTreeTX) AddMapRootSignature(ctx context.Context, revision int64, sig *trillian.MapRootSignature) error {
+	m.treeTX.mu.Lock()
+	defer m.treeTX.mu.Unlock()
+
+	keyHash := sha256.Sum256(sig.PublicKey)
+	_, err := m.exec(ctx, replaceMapRootSignatureSQL, m.treeID, revision, keyHash[:], sig.Witness, sig.PublicKey, sig.Signature)
+	return err
+}
+
+// GetMapRootSignatures implements storage.MapRootSignatureTX.
+func (m *mapTreeTX) GetMapRootSignatures(ctx context.Context, revision int64) ([]*trillian.MapRootSignature, error) {
+	m.treeTX.mu.Lock()
+	defer m.treeTX.mu.Unlock()
+
+	rows, err := m.tx.QueryContext(ctx, selectMapRootSignaturesSQL, m.treeID, revision)
+	if err != nil {
+		return nil, mysqlToGRPC(err)
+	}
+	defer rows.Close()
+	var sigs []*trillian.MapRootSignature
+	for rows.Next() {
+		var sig trillian.MapRootSignature
+		if err := rows.Scan(&sig.Witness, &sig.PublicKey, &sig.Signature); err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, &sig)
+	}
+	return sigs, rows.Err()
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testdb"
+)
+
+func TestMapRootSignatures(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	s := NewMapStorage(DB)
+	tree := createInitializedMapForTests(ctx, t, s, as)
+
+	a := &trillian.MapRootSignature{Witness: "a", PublicKey: []byte("key a"), Signature: []byte("sig a")}
+	b := &trillian.MapRootSignature{Witness: "b", PublicKey: []byte("key b"), Signature: []byte("sig b")}
+	b2 := &trillian.MapRootSignature{Witness: "b", PublicKey: []byte("key b"), Signature: []byte("sig b2")}
+	for _, sig := range []*trillian.MapRootSignature{a, b, b2} {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			return tx.(storage.MapRootSignatureTX).AddMapRootSignature(ctx, 0, sig)
+		})
+	}
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		for _, tc := range []struct {
+			rev  int64
+			want map[string]*trillian.MapRootSignature
+		}{
+			// The second signature by key b replaced the first.
+			{rev: 0, want: map[string]*trillian.MapRootSignature{"a": a, "b": b2}},
+			{rev: 1, want: map[string]*trillian.MapRootSignature{}},
+		} {
+			sigs, err := tx.(storage.MapRootSignatureTX).GetMapRootSignatures(ctx, tc.rev)
+			if err != nil {
+				t.Fatalf("GetMapRootSignatures(%d): %v", tc.rev, err)
+			}
+			if got, want := len(sigs), len(tc.want); got != want {
+				t.Errorf("GetMapRootSignatures(%d) returned %d signatures, want %d", tc.rev, got, want)
+			}
+			for _, sig := range sigs {
+				if want := tc.want[sig.Witness]; !proto.Equal(sig, want) {
+					t.Errorf("GetMapRootSignatures(%d) returned %v, want %v", tc.rev, sig, want)
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,204 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/compress"
+	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/storagepb/convert"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	stree "github.com/google/trillian/storage/tree"
+)
+
+// selectSubtreeScanSQL reads the latest version at a given revision of the
+// tiles of a map which follow a given tile ID, in order of their IDs. The root
+// tile, whose ID is empty, never follows another.
+const selectSubtreeScanSQL = `SELECT s.SubtreeId, s.Nodes
+	 FROM Subtree s
+	 INNER JOIN (
+		SELECT SubtreeId, MAX(SubtreeRevision) AS MaxRevision
+		FROM Subtree
+		WHERE TreeId=? AND SubtreeId>? AND SubtreeRevision<=?
+		GROUP BY SubtreeId
+		ORDER BY SubtreeId
+		LIMIT ?
+	 ) x
+	 ON s.SubtreeId=x.SubtreeId AND s.SubtreeRevision=x.MaxRevision
+	 WHERE s.TreeId=?
+	 ORDER BY s.SubtreeId`
+
+var (
+	_ storage.MapTileScanner    = &mapTreeTX{}
+	_ storage.MapSnapshotWriter = &mySQLMapStorage{}
+)
+
+// ScanTiles calls fn with each tile of the map at the given revision, in
+// order of their IDs. It implements storage.MapTileScanner.
+func (m *mapTreeTX) ScanTiles(ctx context.Context, revision int64, fn func(smt.Tile) error) error {
+	roots, err := m.GetTiles(ctx, revision, []stree.NodeID2{{}})
+	if err != nil {
+		return err
+	}
+	for _, root := range roots {
+		if err := fn(root); err != nil {
+			return err
+		}
+	}
+
+	after := []byte{}
+	for {
+		tiles, last, err := m.scanTilesAfter(ctx, revision, after)
+		if err != nil {
+			return err
+		}
+		for _, tile := range tiles {
+			if err := fn(tile); err != nil {
+				return err
+			}
+		}
+		if len(tiles) < mapLeafScanBatchSize {
+			return nil
+		}
+		after = last
+	}
+}
+
+// scanTilesAfter returns up to mapLeafScanBatchSize tiles of the map at the
+// given revision whose IDs follow after, read with a single query, along with
+// the ID of the last one.
+func (m *mapTreeTX) scanTilesAfter(ctx context.Context, revision int64, after []byte) ([]smt.Tile, []byte, error) {
+	m.treeTX.mu.Lock()
+	defer m.treeTX.mu.Unlock()
+
+	rows, err := m.tx.QueryContext(ctx, selectSubtreeScanSQL, m.treeID, after, revision, mapLeafScanBatchSize, m.treeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var tiles []smt.Tile
+	var last []byte
+	for rows.Next() {
+		var nodes []byte
+		if err := rows.Scan(&last, &nodes); err != nil {
+			return nil, nil, err
+		}
+		if nodes, err = compress.Decompress(nodes); err != nil {
+			return nil, nil, err
+		}
+		var sub storagepb.SubtreeProto
+		if err := proto.Unmarshal(nodes, &sub); err != nil {
+			return nil, nil, err
+		}
+		tile, err := convert.Unmarshal(&sub)
+		if err != nil {
+			return nil, nil, err
+		}
+		tiles = append(tiles, tile)
+	}
+	return tiles, last, rows.Err()
+}
+
+// BeginSnapshotWrite implements storage.MapSnapshotWriter.
+func (m *mySQLMapStorage) BeginSnapshotWrite(ctx context.Context, tree *trillian.Tree, revision int64) error {
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	latest, err := checkSnapshotTarget(ctx, tx, tree.TreeId, revision)
+	if err != nil {
+		return err
+	}
+	// Nothing written above the latest root has been committed.
+	if err := m.deleteUncommittedWrites(ctx, tx, tree.TreeId, latest+1); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkSnapshotTarget checks that the given revision can be imported into the
+// map, i.e. that the map has no root, or only that of revision 0 if the
+// revision is above it, and returns the revision of its latest root, or -1.
+func checkSnapshotTarget(ctx context.Context, tx *sql.Tx, treeID, revision int64) (int64, error) {
+	if revision < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "map revision %d must be >= 0", revision)
+	}
+	var latest int64
+	if err := tx.QueryRowContext(ctx, selectLatestMapRevisionSQL, treeID).Scan(&latest); err != nil {
+		return 0, err
+	}
+	if latest > 0 || latest >= revision {
+		return 0, status.Errorf(codes.FailedPrecondition, "map %d already has revision %d, can't import revision %d", treeID, latest, revision)
+	}
+	return latest, nil
+}
+
+// SnapshotWriteLeaves implements storage.MapSnapshotWriter.
+func (m *mySQLMapStorage) SnapshotWriteLeaves(ctx context.Context, tree *trillian.Tree, revision int64, leaves []*trillian.MapLeaf) error {
+	return m.writeLeavesAt(ctx, tree, revision, leaves)
+}
+
+// SnapshotWriteTiles implements storage.MapSnapshotWriter.
+func (m *mySQLMapStorage) SnapshotWriteTiles(ctx context.Context, tree *trillian.Tree, revision int64, tiles []smt.Tile) error {
+	return m.writeTilesAt(ctx, tree, revision, tiles)
+}
+
+// CommitSnapshotWrite implements storage.MapSnapshotWriter.
+func (m *mySQLMapStorage) CommitSnapshotWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	var r types.MapRootV1
+	if err := r.UnmarshalBinary(root.MapRoot); err != nil {
+		return err
+	}
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := checkSnapshotTarget(ctx, tx, tree.TreeId, int64(r.Revision)); err != nil {
+		return err
+	}
+	stored, err := latestSignerEpoch(ctx, tx, selectMapHeadEpochSQL, tree.TreeId)
+	if err != nil {
+		return err
+	}
+	epoch, err := storage.CheckSignerEpoch(ctx, tree.TreeId, stored)
+	if err != nil {
+		return err
+	}
+	stmt, err := m.getStmt(ctx, insertMapHeadSQL, 1, "?", "?")
+	if err != nil {
+		return err
+	}
+	stx := tx.StmtContext(ctx, stmt)
+	defer stx.Close()
+	res, err := stx.ExecContext(ctx, tree.TreeId, r.TimestampNanos, r.RootHash, r.Revision, root.Signature, r.Metadata, epoch)
+	if err := checkResultOkAndRowCountIs(res, err, 1); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testdb"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	storageto "github.com/google/trillian/storage/testonly"
+	stree "github.com/google/trillian/storage/tree"
+)
+
+func TestMapScanTiles(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	as := NewAdminStorage(DB)
+	s := NewMapStorage(DB)
+	tree := createInitializedMapForTests(ctx, t, s, as)
+
+	// More tiles than fit in one scan batch, below the root tile, all of them
+	// written at revision 1, and every other one rewritten at revision 2.
+	tile := func(i, rev int) smt.Tile {
+		var id [2]byte
+		binary.BigEndian.PutUint16(id[:], uint16(i))
+		leaf := stree.NewNodeID2(string(id[:])+"\x01", 24)
+		return smt.Tile{ID: stree.NewNodeID2(string(id[:]), 16), Leaves: []smt.Node{{ID: leaf, Hash: []byte(fmt.Sprintf("%d@%d", i, rev))}}}
+	}
+	root := func(rev int) smt.Tile {
+		return smt.Tile{ID: stree.NewNodeID2("", 0), Leaves: []smt.Node{{ID: stree.NewNodeID2("\x00", 8), Hash: []byte(fmt.Sprintf("root@%d", rev))}}}
+	}
+	want := make(map[int][]smt.Tile)
+	for rev := 1; rev <= 2; rev++ {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = int64(rev)
+			tiles := []smt.Tile{root(rev)}
+			for i := rev - 1; i < mapLeafScanBatchSize+10; i += rev {
+				tiles = append(tiles, tile(i, rev))
+			}
+			return tx.SetTiles(ctx, tiles)
+		})
+		want[rev] = []smt.Tile{root(rev)}
+		for i := 0; i < mapLeafScanBatchSize+10; i++ {
+			if rev == 2 && i%2 == 1 {
+				want[rev] = append(want[rev], tile(i, rev))
+			} else {
+				want[rev] = append(want[rev], tile(i, 1))
+			}
+		}
+	}
+
+	opt := cmp.Comparer(func(x, y stree.NodeID2) bool { return x == y })
+	for rev := 1; rev <= 2; rev++ {
+		var got []smt.Tile
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			return tx.(storage.MapTileScanner).ScanTiles(ctx, int64(rev), func(tile smt.Tile) error {
+				got = append(got, tile)
+				return nil
+			})
+		})
+		if diff := cmp.Diff(got, want[rev], opt); diff != "" {
+			t.Errorf("ScanTiles(%d) diff (-got +want):\n%s", rev, diff)
+		}
+	}
+}
+
+func TestMapSnapshotWrite(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	as := NewAdminStorage(DB)
+	s := NewMapStorage(DB)
+	tree := mustCreateTree(ctx, t, as, storageto.MapTree)
+	w := s.(storage.MapSnapshotWriter)
+	const rev = 5
+
+	var leaves []*trillian.MapLeaf
+	for i := 0; i < 10; i++ {
+		h := sha256.Sum256([]byte(fmt.Sprintf("key %d", i)))
+		leaves = append(leaves, &trillian.MapLeaf{Index: h[:], LeafHash: []byte{1}, LeafValue: []byte(fmt.Sprintf("value %d", i))})
+	}
+	nodes, err := smt.NewNodesRow([]smt.Node{{ID: stree.NewNodeID2("01", 16), Hash: dummyHash}})
+	if err != nil {
+		t.Fatalf("NewNodesRow(): %v", err)
+	}
+	tiles := []smt.Tile{{ID: stree.NewNodeID2("0", 8), Leaves: nodes}}
+
+	// What a failed import left behind is removed.
+	if err := w.BeginSnapshotWrite(ctx, tree, rev); err != nil {
+		t.Fatalf("BeginSnapshotWrite(): %v", err)
+	}
+	stale := sha256.Sum256([]byte("stale"))
+	if err := w.SnapshotWriteLeaves(ctx, tree, rev, []*trillian.MapLeaf{{Index: stale[:], LeafValue: []byte("stale")}}); err != nil {
+		t.Fatalf("SnapshotWriteLeaves(): %v", err)
+	}
+	if err := w.BeginSnapshotWrite(ctx, tree, rev); err != nil {
+		t.Fatalf("BeginSnapshotWrite(): %v", err)
+	}
+
+	if err := w.SnapshotWriteLeaves(ctx, tree, rev, leaves); err != nil {
+		t.Fatalf("SnapshotWriteLeaves(): %v", err)
+	}
+	if err := w.SnapshotWriteTiles(ctx, tree, rev, tiles); err != nil {
+		t.Fatalf("SnapshotWriteTiles(): %v", err)
+	}
+	root := MustSignMapRoot(t, &types.MapRootV1{RootHash: []byte("rootHash"), TimestampNanos: 1, Revision: rev})
+	if err := w.CommitSnapshotWrite(ctx, tree, root); err != nil {
+		t.Fatalf("CommitSnapshotWrite(): %v", err)
+	}
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if got, err := tx.LatestSignedMapRoot(ctx); err != nil || !proto.Equal(got, root) {
+			t.Errorf("LatestSignedMapRoot()=%v, %v, want %v", got, err, root)
+		}
+		if rev, err := tx.WriteRevision(ctx); err != nil || rev != 6 {
+			t.Errorf("WriteRevision()=%d, %v, want 6", rev, err)
+		}
+		var got []*trillian.MapLeaf
+		if err := tx.(storage.MapLeafScanner).ScanLeaves(ctx, rev, func(leaf *trillian.MapLeaf) error {
+			got = append(got, leaf)
+			return nil
+		}); err != nil {
+			t.Fatalf("ScanLeaves(): %v", err)
+		}
+		if len(got) != len(leaves) {
+			t.Errorf("ScanLeaves() returned %d leaves, want %d", len(got), len(leaves))
+		}
+		gotTiles, err := tx.GetTiles(ctx, rev, []stree.NodeID2{tiles[0].ID})
+		if err != nil {
+			t.Fatalf("GetTiles(): %v", err)
+		}
+		opt := cmp.Comparer(func(x, y stree.NodeID2) bool { return x.String() == y.String() })
+		if diff := cmp.Diff(gotTiles, tiles, opt); diff != "" {
+			t.Errorf("GetTiles() diff (-got +want):\n%s", diff)
+		}
+		return nil
+	})
+
+	// The map has a root above revision 0 now.
+	if err := w.BeginSnapshotWrite(ctx, tree, rev+1); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("BeginSnapshotWrite(): %v, want code %v", err, codes.FailedPrecondition)
+	}
+	if err := w.CommitSnapshotWrite(ctx, tree, root); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CommitSnapshotWrite(): %v, want code %v", err, codes.FailedPrecondition)
+	}
+
+	// Revision 0 can't be imported over an initialized map.
+	initialized := createInitializedMapForTests(ctx, t, s, as)
+	if err := w.BeginSnapshotWrite(ctx, initialized, 0); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("BeginSnapshotWrite(0): %v, want code %v", err, codes.FailedPrecondition)
+	}
+}
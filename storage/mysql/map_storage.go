@@ -15,10 +15,12 @@
 package mysql
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto" //nolint:staticcheck
@@ -28,24 +30,59 @@ import (
 	"github.com/google/trillian/merkle/smt"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/compress"
 	"github.com/google/trillian/storage/storagepb"
 	"github.com/google/trillian/storage/storagepb/convert"
 	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	stree "github.com/google/trillian/storage/tree"
 )
 
 const (
-	insertMapHeadSQL = `INSERT INTO MapHead(TreeId, MapHeadTimestamp, RootHash, MapRevision, RootSignature, MapperData)
-	VALUES(?, ?, ?, ?, ?, ?)`
+	insertMapHeadSQL = `INSERT INTO MapHead(TreeId, MapHeadTimestamp, RootHash, MapRevision, RootSignature, MapperData, SignerEpoch)
+	VALUES(?, ?, ?, ?, ?, ?, ?)`
+	selectMapHeadEpochSQL = `SELECT SignerEpoch FROM MapHead WHERE TreeId=?
+		 ORDER BY MapHeadTimestamp DESC LIMIT 1 FOR UPDATE`
 	selectLatestSignedMapRootSQL = `SELECT MapHeadTimestamp, RootHash, MapRevision, RootSignature, MapperData
 		 FROM MapHead WHERE TreeId=?
 		 ORDER BY MapHeadTimestamp DESC LIMIT 1`
 	selectGetSignedMapRootSQL = `SELECT MapHeadTimestamp, RootHash, MapRevision, RootSignature, MapperData
 		 FROM MapHead WHERE TreeId=? AND MapRevision=?`
-	insertMapLeafSQL = `INSERT INTO MapLeaf(TreeId, KeyHash, MapRevision, LeafValue) VALUES (?, ?, ?, ?)`
+	insertMapLeafSQL      = `INSERT INTO MapLeaf(TreeId, KeyHash, MapRevision, LeafValue, LeafKey) VALUES (?, ?, ?, ?, ?)`
+	insertMapLeafMultiSQL = `INSERT INTO MapLeaf(TreeId, KeyHash, MapRevision, LeafValue, LeafKey) ` + placeholderSQL
+	selectMapLeafSQL      = `
+ SELECT t1.KeyHash, t1.LeafValue
+ FROM MapLeaf t1
+ INNER JOIN
+ (
+	SELECT TreeId, KeyHash, MAX(MapRevision) as maxrev
+	FROM MapLeaf t0
+	WHERE t0.KeyHash IN (` + placeholderSQL + `) AND
+	      t0.TreeId = ? AND t0.MapRevision <= ?
+	GROUP BY t0.TreeId, t0.KeyHash
+ ) t2
+ ON t1.TreeId=t2.TreeId
+ AND t1.KeyHash=t2.KeyHash
+ AND t1.MapRevision=t2.maxrev`
+	// selectMapLeafPointSQL reads a single leaf without joining MapLeaf with
+	// itself, so only touches the rows of one key.
+	selectMapLeafPointSQL = `SELECT KeyHash, LeafValue
+		 FROM MapLeaf WHERE TreeId=? AND KeyHash=? AND MapRevision<=?
+		 ORDER BY MapRevision DESC LIMIT 1`
+	// selectMapLeafScanSQL reads the leaves of a map in order of their key
+	// hashes, starting after a given key hash. Each key hash comes with all
+	// of its revisions up to the given one, latest first.
+	selectMapLeafScanSQL = `SELECT KeyHash, LeafValue
+		 FROM MapLeaf WHERE TreeId=? AND KeyHash>? AND MapRevision<=?
+		 ORDER BY KeyHash, MapRevision DESC LIMIT ?`
 )
 
+// mapLeafScanBatchSize is the number of MapLeaf rows read by each query of
+// ScanLeaves.
+const mapLeafScanBatchSize = 1000
+
 var (
 	defaultMapStrata = []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 176}
 	defaultLayout    = stree.NewLayout(defaultMapStrata)
@@ -54,18 +91,142 @@ var (
 type mySQLMapStorage struct {
 	*mySQLTreeStorage
 	admin storage.AdminStorage
+	opts  MapStorageOptions
+	// replica, if set, runs the read-only transactions of trees on
+	// opts.ReplicaDB.
+	replica *mySQLMapStorage
+}
+
+// MapStorageOptions holds optional settings of the MySQL map storage.
+type MapStorageOptions struct {
+	// PointLeafReads makes leaves be read with a query per leaf, which only
+	// touches the rows of that leaf, rather than with a single query which
+	// joins MapLeaf with itself. This suits sharded deployments such as
+	// Vitess, where each of the queries can be routed to a single shard.
+	PointLeafReads bool
+	// TileReadBatchSize, if positive, makes GetTiles split reads of more
+	// than this many tiles into queries for batches of this many tiles, which
+	// run concurrently on separate connections, outside of the transaction.
+	// This cuts the latency of wide reads, such as GetLeaves for thousands of
+	// keys, at the cost of more connections.
+	TileReadBatchSize int
+	// TileReadConcurrency is the maximum number of concurrent queries of a
+	// GetTiles call when TileReadBatchSize is set. Defaults to 4.
+	TileReadConcurrency int
+	// LeafTableShards, if above 1, makes map leaves be stored across this
+	// many tables named MapLeaf_0, MapLeaf_1, etc. rather than in MapLeaf.
+	// Each of the tables holds a contiguous range of key hashes, by their
+	// first byte, so that writes to a single map don't contend on one table,
+	// and the tables can be placed in separate partitions or shards. The
+	// tables must exist and have the schema of MapLeaf, and the number of
+	// shards must not change once a map has leaves. At most 256.
+	LeafTableShards int
+	// LeafWriteBatchSize, if above 1, makes Set buffer the leaves written by
+	// a transaction, and write them with INSERT statements of up to this many
+	// rows, once this many leaves of a MapLeaf table are buffered, and before
+	// the transaction reads leaves or commits. This saves a round trip per
+	// leaf on large map updates. Errors writing buffered leaves, such as
+	// setting the same key twice in a revision, are then returned by the
+	// later call which writes them rather than by Set. At most 13107.
+	LeafWriteBatchSize int
+	// ReplicaDB, if set, is a read-only replica of the database, which the
+	// transactions of SnapshotForTree and SnapshotAtRevision run on, so that
+	// reads don't load the primary, which writes the maps. Snapshots then see
+	// the maps as of the replication lag, e.g. the latest revision may not be
+	// readable yet. All other transactions run on the primary.
+	ReplicaDB *sql.DB
+	// SharedTiles, if set, caches the map tiles read by all transactions,
+	// such as the tiles near the roots of maps, which almost every read
+	// needs.
+	SharedTiles *cache.SharedMapTiles
+	// TreeUsageShards is the number of rows of the TreeUsage table which the
+	// storage written for each map is spread across, as
+	// LogStorageOptions.TreeUsageShards is.
+	TreeUsageShards int
 }
 
+// defaultTileReadConcurrency is the default of
+// MapStorageOptions.TileReadConcurrency.
+const defaultTileReadConcurrency = 4
+
+// maxMapLeafTableShards is the maximum of MapStorageOptions.LeafTableShards,
+// as key hashes are assigned to shards by their first byte.
+const maxMapLeafTableShards = 256
+
 // NewMapStorage creates a storage.MapStorage instance for the specified MySQL URL.
 // It assumes storage.AdminStorage is backed by the same MySQL database as well.
 func NewMapStorage(db *sql.DB) storage.MapStorage {
-	return &mySQLMapStorage{
+	return NewMapStorageWithOpts(db, MapStorageOptions{})
+}
+
+// NewMapStorageWithOpts creates a storage.MapStorage instance with the given
+// options.
+func NewMapStorageWithOpts(db *sql.DB, opts MapStorageOptions) storage.MapStorage {
+	m := &mySQLMapStorage{
 		admin:            NewAdminStorage(db),
 		mySQLTreeStorage: newTreeStorage(db),
+		opts:             opts,
+	}
+	if opts.ReplicaDB != nil {
+		replicaOpts := opts
+		replicaOpts.ReplicaDB = nil
+		m.replica = NewMapStorageWithOpts(opts.ReplicaDB, replicaOpts).(*mySQLMapStorage)
+	}
+	return m
+}
+
+// reader returns the storage which runs the read-only transactions of trees.
+func (m *mySQLMapStorage) reader() *mySQLMapStorage {
+	if m.replica != nil {
+		return m.replica
+	}
+	return m
+}
+
+// leafShards returns the number of tables which store map leaves.
+func (m *mySQLMapStorage) leafShards() int {
+	if m.opts.LeafTableShards > 1 {
+		return m.opts.LeafTableShards
+	}
+	return 1
+}
+
+// leafShard returns the index of the MapLeaf table shard which stores the
+// given key hash. Shards hold contiguous ranges of key hashes, so reading them
+// in order of their indices reads the key hashes in order.
+func (m *mySQLMapStorage) leafShard(keyHash []byte) int {
+	if len(keyHash) == 0 {
+		return 0
+	}
+	return int(keyHash[0]) * m.leafShards() / maxMapLeafTableShards
+}
+
+// leafSQL returns the given MapLeaf statement rewritten to use the table of
+// the given shard.
+func (m *mySQLMapStorage) leafSQL(statement string, shard int) string {
+	if m.leafShards() == 1 {
+		return statement
 	}
+	return strings.Replace(statement, "MapLeaf", fmt.Sprintf("MapLeaf_%d", shard), -1)
+}
+
+// splitByLeafShard groups the given key hashes by the MapLeaf table shards
+// which store them. The result is indexed by shard.
+func (m *mySQLMapStorage) splitByLeafShard(keyHashes [][]byte) [][][]byte {
+	ret := make([][][]byte, m.leafShards())
+	for _, keyHash := range keyHashes {
+		shard := m.leafShard(keyHash)
+		ret[shard] = append(ret[shard], keyHash)
+	}
+	return ret
 }
 
 func (m *mySQLMapStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	if m.replica != nil {
+		if err := m.replica.CheckDatabaseAccessible(ctx); err != nil {
+			return err
+		}
+	}
 	return m.db.PingContext(ctx)
 }
 
@@ -78,26 +239,28 @@ func (m *mySQLMapStorage) begin(ctx context.Context, tree *trillian.Tree, readon
 	if got, want := tree.TreeType, trillian.TreeType_MAP; got != want {
 		return nil, fmt.Errorf("begin(tree.TreeType: %v), want %v", got, want)
 	}
-	hasher, err := registry.NewMapHasher(tree.HashStrategy)
+	hasher, err := registry.NewMapHasherForTree(tree)
 	if err != nil {
 		return nil, err
 	}
 
-	stCache := cache.NewMapSubtreeCache(defaultMapStrata, tree.TreeId, hasher)
-	ttx, err := m.beginTreeTx(ctx, tree, hasher.Size(), stCache)
+	l, err := m.Layout(tree)
 	if err != nil {
 		return nil, err
 	}
-	l, err := m.Layout(tree)
+	stCache := cache.NewMapSubtreeCache(mapStrata(tree), tree.TreeId, hasher)
+	ttx, err := m.beginTreeTx(ctx, tree, hasher.Size(), stCache)
 	if err != nil {
 		return nil, err
 	}
+	ttx.usageShards = m.opts.TreeUsageShards
 	mtx := &mapTreeTX{
 		treeTX:       ttx,
 		layout:       l,
 		ms:           m,
 		hasher:       hasher,
 		readRevision: -1,
+		keyIndex:     tree.MapKeyIndex,
 	}
 
 	if readonly {
@@ -123,16 +286,78 @@ func (m *mySQLMapStorage) begin(ctx context.Context, tree *trillian.Tree, readon
 
 	mtx.readRevision = int64(mr.Revision)
 	mtx.treeTX.writeRevision = int64(mr.Revision) + 1
+	if shared := m.opts.SharedTiles; shared != nil {
+		// The tiles of older revisions won't be read by writers any more.
+		shared.Advance(tree.TreeId, mtx.readRevision)
+	}
+	if err := m.claimMapRevision(ctx, tree.TreeId, mtx.treeTX.writeRevision); err != nil {
+		mtx.Close()
+		return nil, err
+	}
+	if err := mtx.checkMapWriteBatch(ctx); err != nil {
+		mtx.Close()
+		return nil, err
+	}
 	return mtx, nil
 }
 
 func (m *mySQLMapStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyMapTreeTX, error) {
-	return m.begin(ctx, tree, true /* readonly */)
+	return m.reader().begin(ctx, tree, true /* readonly */)
 }
 
-// Layout returns the layout of the given tree.
-func (m *mySQLMapStorage) Layout(*trillian.Tree) (*stree.Layout, error) {
-	return defaultLayout, nil
+// SnapshotAtRevision starts a read-only transaction pinned to the given
+// revision of the tree.
+func (m *mySQLMapStorage) SnapshotAtRevision(ctx context.Context, tree *trillian.Tree, revision int64) (storage.ReadOnlyMapTreeTX, error) {
+	tx, err := m.reader().begin(ctx, tree, true /* readonly */)
+	if err != nil {
+		return nil, err
+	}
+	mtx := tx.(*mapTreeTX)
+	root, err := mtx.GetSignedMapRoot(ctx, revision)
+	if err == sql.ErrNoRows {
+		err = status.Errorf(codes.NotFound, "tree %d: map root %d not found", tree.TreeId, revision)
+	}
+	if err != nil {
+		mtx.Close()
+		return nil, err
+	}
+	mtx.pinnedRoot = root
+	return mtx, nil
+}
+
+// Layout returns the layout of the given tree, which is that of its map strata
+// if it has them, or the default one.
+func (m *mySQLMapStorage) Layout(tree *trillian.Tree) (*stree.Layout, error) {
+	if len(tree.MapStrata) == 0 {
+		return defaultLayout, nil
+	}
+	hasher, err := registry.NewMapHasherForTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	height := 0
+	for _, h := range tree.MapStrata {
+		if h <= 0 || h%8 != 0 {
+			return nil, status.Errorf(codes.FailedPrecondition, "tree %d: invalid map strata %v", tree.TreeId, tree.MapStrata)
+		}
+		height += int(h)
+	}
+	if height != hasher.BitLen() {
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %d: map strata %v add up to %d, want %d", tree.TreeId, tree.MapStrata, height, hasher.BitLen())
+	}
+	return stree.NewLayout(mapStrata(tree)), nil
+}
+
+// mapStrata returns the heights of the strata of the given map.
+func mapStrata(tree *trillian.Tree) []int {
+	if len(tree.MapStrata) == 0 {
+		return defaultMapStrata
+	}
+	ret := make([]int, len(tree.MapStrata))
+	for i, h := range tree.MapStrata {
+		ret[i] = int(h)
+	}
+	return ret
 }
 
 func (m *mySQLMapStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
@@ -143,7 +368,7 @@ func (m *mySQLMapStorage) ReadWriteTransaction(ctx context.Context, tree *trilli
 	if err != nil && err != storage.ErrTreeNeedsInit {
 		return err
 	}
-	if err := f(ctx, tx); err != nil {
+	if err := f(withClaimedMapWriteBatch(ctx), tx); err != nil {
 		return err
 	}
 	return tx.Commit(ctx)
@@ -155,6 +380,15 @@ type mapTreeTX struct {
 	ms           *mySQLMapStorage
 	hasher       hashers.MapHasher
 	readRevision int64
+	// pinnedRoot is the root of the revision which the transaction is pinned
+	// to, if it was started by SnapshotAtRevision.
+	pinnedRoot *trillian.SignedMapRoot
+	// pendingLeaves holds the leaves buffered by Set, if LeafWriteBatchSize
+	// is set, indexed by MapLeaf table shard.
+	pendingLeaves [][]pendingMapLeaf
+	// keyIndex is whether the keys of the leaves are stored in LeafKey, as
+	// the map has map_key_index set.
+	keyIndex bool
 }
 
 func (m *mapTreeTX) ReadRevision(ctx context.Context) (int64, error) {
@@ -178,22 +412,40 @@ func (m *mapTreeTX) Set(ctx context.Context, keyHash []byte, value *trillian.Map
 	m.treeTX.mu.Lock()
 	defer m.treeTX.mu.Unlock()
 
-	// TODO(al): consider storing some sort of value which represents the group of keys being set in this Tx.
-	//           That way, if this attempt partially fails (i.e. because some subset of the in-the-future Merkle
-	//           nodes do get written), we can enforce that future map update attempts are a complete replay of
-	//           the failed set.
-	flatValue, err := proto.Marshal(value)
-	if err != nil {
-		return nil
+	// Leaves are written in the transaction which stores the root of the
+	// revision. Tiles written by other transactions of the same revision are
+	// tracked as a map write batch, see storage.WithMapWriteBatch, and are
+	// removed if the batch fails before storing its root.
+	//
+	// A deletion is stored as a row with an empty LeafValue, which masks the
+	// earlier revisions of the key.
+	flatValue := []byte{}
+	if !storage.IsMapLeafDeletion(value) {
+		var err error
+		if flatValue, err = proto.Marshal(value); err != nil {
+			return nil
+		}
+		if flatValue, err = compress.Compress(m.treeTX.compression, flatValue); err != nil {
+			return err
+		}
+	}
+	m.treeTX.usage.leafBytes += int64(len(flatValue))
+	var leafKey []byte
+	if m.keyIndex {
+		leafKey = storage.MapLeafIndexedKey(value)
 	}
 
-	stmt, err := m.tx.PrepareContext(ctx, insertMapLeafSQL)
+	if m.ms.opts.LeafWriteBatchSize > 1 {
+		return m.bufferLeaf(ctx, keyHash, flatValue, leafKey)
+	}
+
+	stmt, err := m.tx.PrepareContext(ctx, m.ms.leafSQL(insertMapLeafSQL, m.ms.leafShard(keyHash)))
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx, m.treeID, keyHash, m.writeRevision, flatValue)
+	_, err = stmt.ExecContext(ctx, m.treeID, keyHash, m.writeRevision, flatValue, leafKey)
 	return err
 }
 
@@ -208,22 +460,33 @@ func (m *mapTreeTX) Get(ctx context.Context, revision int64, indexes [][]byte) (
 	if len(indexes) == 0 {
 		return []*trillian.MapLeaf{}, nil
 	}
-	const selectMapLeafSQL = `
- SELECT t1.KeyHash, t1.LeafValue
- FROM MapLeaf t1
- INNER JOIN
- (
-	SELECT TreeId, KeyHash, MAX(MapRevision) as maxrev
-	FROM MapLeaf t0
-	WHERE t0.KeyHash IN (` + placeholderSQL + `) AND
-	      t0.TreeId = ? AND t0.MapRevision <= ?
-	GROUP BY t0.TreeId, t0.KeyHash
- ) t2
- ON t1.TreeId=t2.TreeId
- AND t1.KeyHash=t2.KeyHash
- AND t1.MapRevision=t2.maxrev`
+	if err := m.flushLeaves(ctx); err != nil {
+		return nil, err
+	}
+	get := m.getShard
+	if m.ms.opts.PointLeafReads {
+		get = m.getPoints
+	}
+
+	// Leaves are read from each of the MapLeaf table shards in turn.
+	ret := make([]*trillian.MapLeaf, 0, len(indexes))
+	for shard, keyHashes := range m.ms.splitByLeafShard(indexes) {
+		if len(keyHashes) == 0 {
+			continue
+		}
+		leaves, err := get(ctx, shard, revision, keyHashes)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, leaves...)
+	}
+	return ret, nil
+}
 
-	stmt, err := m.ms.getStmt(ctx, selectMapLeafSQL, len(indexes), "?", "?")
+// getShard returns the leaves with the given indexes stored in the MapLeaf
+// table of the given shard, read with a single query.
+func (m *mapTreeTX) getShard(ctx context.Context, shard int, revision int64, indexes [][]byte) ([]*trillian.MapLeaf, error) {
+	stmt, err := m.ms.getStmt(ctx, m.ms.leafSQL(selectMapLeafSQL, shard), len(indexes), "?", "?")
 	if err != nil {
 		return nil, err
 	}
@@ -252,6 +515,45 @@ func (m *mapTreeTX) Get(ctx context.Context, revision int64, indexes [][]byte) (
 		if err := rows.Scan(&mapKeyHash, &flatData); err != nil {
 			return nil, err
 		}
+		if len(flatData) == 0 {
+			continue // The key is deleted.
+		}
+		mapLeaf, err := unmarshalMapLeaf(flatData, mapKeyHash)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, mapLeaf)
+	}
+	return ret, nil
+}
+
+// getPoints returns the same leaves as getShard, reading each of them with a
+// query of its own.
+func (m *mapTreeTX) getPoints(ctx context.Context, shard int, revision int64, indexes [][]byte) ([]*trillian.MapLeaf, error) {
+	stmt, err := m.ms.getStmt(ctx, m.ms.leafSQL(selectMapLeafPointSQL, shard), 1, "?", "?")
+	if err != nil {
+		return nil, err
+	}
+	stx := m.tx.StmtContext(ctx, stmt)
+	defer stx.Close()
+
+	ret := make([]*trillian.MapLeaf, 0, len(indexes))
+	seen := make(map[string]bool, len(indexes))
+	for _, index := range indexes {
+		if seen[string(index)] {
+			continue
+		}
+		seen[string(index)] = true
+
+		var mapKeyHash, flatData []byte
+		err := stx.QueryRowContext(ctx, m.treeID, index, revision).Scan(&mapKeyHash, &flatData)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return nil, err
+		} else if len(flatData) == 0 {
+			continue // The key is deleted.
+		}
 		mapLeaf, err := unmarshalMapLeaf(flatData, mapKeyHash)
 		if err != nil {
 			return nil, err
@@ -261,15 +563,96 @@ func (m *mapTreeTX) Get(ctx context.Context, revision int64, indexes [][]byte) (
 	return ret, nil
 }
 
+var _ storage.MapLeafScanner = &mapTreeTX{}
+
+// ScanLeaves calls fn with each leaf of the map at the given revision, in
+// increasing order of their indices. It implements storage.MapLeafScanner.
+func (m *mapTreeTX) ScanLeaves(ctx context.Context, revision int64, fn func(*trillian.MapLeaf) error) error {
+	m.treeTX.mu.Lock()
+	err := m.flushLeaves(ctx)
+	m.treeTX.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for shard := 0; shard < m.ms.leafShards(); shard++ {
+		after := []byte{}
+		for {
+			leaves, last, err := m.scanLeavesAfter(ctx, shard, revision, after)
+			if err != nil {
+				return err
+			}
+			if last == nil {
+				break
+			}
+			for _, leaf := range leaves {
+				if err := fn(leaf); err != nil {
+					return err
+				}
+			}
+			after = last
+		}
+	}
+	return nil
+}
+
+// scanLeavesAfter returns the leaves of the map at the given revision whose
+// key hashes follow after, read from the MapLeaf table of the given shard with
+// a single query, along with the last key hash read. The latter is nil once
+// the end of the shard is reached. The leaves can be empty before that, if all
+// the keys read are deleted.
+func (m *mapTreeTX) scanLeavesAfter(ctx context.Context, shard int, revision int64, after []byte) ([]*trillian.MapLeaf, []byte, error) {
+	m.treeTX.mu.Lock()
+	defer m.treeTX.mu.Unlock()
+
+	rows, err := m.tx.QueryContext(ctx, m.ms.leafSQL(selectMapLeafScanSQL, shard), m.treeID, after, revision, mapLeafScanBatchSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var ret []*trillian.MapLeaf
+	var last []byte
+	for rows.Next() {
+		var mapKeyHash, flatData []byte
+		if err := rows.Scan(&mapKeyHash, &flatData); err != nil {
+			return nil, nil, err
+		}
+		if last != nil && bytes.Equal(last, mapKeyHash) {
+			continue // An earlier revision of the previous leaf.
+		}
+		last = mapKeyHash
+		if len(flatData) == 0 {
+			continue // The key is deleted.
+		}
+		mapLeaf, err := unmarshalMapLeaf(flatData, mapKeyHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		ret = append(ret, mapLeaf)
+	}
+	return ret, last, rows.Err()
+}
+
 // GetTiles reads the Merkle tree tiles with the given root IDs at the given
 // revision. A tile is empty if it is missing from the returned slice.
 func (m *mapTreeTX) GetTiles(ctx context.Context, rev int64, ids []stree.NodeID2) ([]smt.Tile, error) {
-	// TODO(pavelkalinnikov): Use NodeID2 directly.
-	rootIDs := make([]stree.NodeID, 0, len(ids))
-	for _, id := range ids {
-		rootIDs = append(rootIDs, stree.NewNodeIDFromID2(id))
+	get := func(ids []stree.NodeID2) ([]*storagepb.SubtreeProto, error) {
+		if batch := m.ms.opts.TileReadBatchSize; batch > 0 && len(ids) > batch {
+			// Tiles are only ever read at revisions committed before the
+			// transaction started, so they can be read outside of it.
+			concurrency := m.ms.opts.TileReadConcurrency
+			if concurrency <= 0 {
+				concurrency = defaultTileReadConcurrency
+			}
+			return m.treeTX.getSubtreesConcurrently(ctx, rev, ids, batch, concurrency)
+		}
+		return m.treeTX.getSubtreesWithLock(ctx, rev, ids)
+	}
+	if shared := m.ms.opts.SharedTiles; shared != nil {
+		get = shared.Wrap(m.treeID, rev, get)
 	}
-	subs, err := m.treeTX.getSubtreesWithLock(ctx, rev, rootIDs)
+	subs, err := get(ids)
 	if err != nil {
 		return nil, err
 	}
@@ -286,6 +669,9 @@ func (m *mapTreeTX) GetTiles(ctx context.Context, rev int64, ids []stree.NodeID2
 
 // SetTiles stores the given tiles at the current write revision.
 func (m *mapTreeTX) SetTiles(ctx context.Context, tiles []smt.Tile) error {
+	if batch, rev, ok := storage.MapWriteBatchFromContext(ctx); ok && rev != m.writeRevision {
+		return status.Errorf(codes.Aborted, "tree %d: map write batch %q of revision %d can't write at revision %d", m.treeID, batch, rev, m.writeRevision)
+	}
 	subs := make([]*storagepb.SubtreeProto, 0, len(tiles))
 	for _, tile := range tiles {
 		height := m.layout.TileHeight(int(tile.ID.BitLen()))
@@ -303,6 +689,10 @@ func unmarshalMapLeaf(marshaledLeaf, mapKeyHash []byte) (*trillian.MapLeaf, erro
 	if len(marshaledLeaf) == 0 {
 		return nil, errors.New("len(marshaledLeaf): 0 want > 0")
 	}
+	marshaledLeaf, err := compress.Decompress(marshaledLeaf)
+	if err != nil {
+		return nil, err
+	}
 	var mapLeaf trillian.MapLeaf
 	if err := proto.Unmarshal(marshaledLeaf, &mapLeaf); err != nil {
 		return nil, err
@@ -319,13 +709,14 @@ func (m *mapTreeTX) GetSignedMapRoot(ctx context.Context, revision int64) (*tril
 	var rootHash, rootSignatureBytes []byte
 	var mapperMetaBytes []byte
 
-	stmt, err := m.tx.PrepareContext(ctx, selectGetSignedMapRootSQL)
+	stmt, err := m.ms.getStmt(ctx, selectGetSignedMapRootSQL, 1, "?", "?")
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
+	stx := m.tx.StmtContext(ctx, stmt)
+	defer stx.Close()
 
-	err = stmt.QueryRowContext(ctx, m.treeID, revision).Scan(
+	err = stx.QueryRowContext(ctx, m.treeID, revision).Scan(
 		&timestamp, &rootHash, &mapRevision, &rootSignatureBytes, &mapperMetaBytes)
 	if err != nil {
 		if revision == 0 {
@@ -333,7 +724,9 @@ func (m *mapTreeTX) GetSignedMapRoot(ctx context.Context, revision int64) (*tril
 		}
 		return nil, err
 	}
-	m.readRevision = mapRevision
+	if m.pinnedRoot == nil {
+		m.readRevision = mapRevision
+	}
 	return m.signedMapRoot(timestamp, mapRevision, rootHash, rootSignatureBytes, mapperMetaBytes)
 }
 
@@ -341,17 +734,22 @@ func (m *mapTreeTX) LatestSignedMapRoot(ctx context.Context) (*trillian.SignedMa
 	m.treeTX.mu.Lock()
 	defer m.treeTX.mu.Unlock()
 
+	if m.pinnedRoot != nil {
+		return m.pinnedRoot, nil
+	}
+
 	var timestamp, mapRevision int64
 	var rootHash, rootSignatureBytes []byte
 	var mapperMetaBytes []byte
 
-	stmt, err := m.tx.PrepareContext(ctx, selectLatestSignedMapRootSQL)
+	stmt, err := m.ms.getStmt(ctx, selectLatestSignedMapRootSQL, 1, "?", "?")
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
+	stx := m.tx.StmtContext(ctx, stmt)
+	defer stx.Close()
 
-	err = stmt.QueryRowContext(ctx, m.treeID).Scan(
+	err = stx.QueryRowContext(ctx, m.treeID).Scan(
 		&timestamp, &rootHash, &mapRevision, &rootSignatureBytes, &mapperMetaBytes)
 
 	// It's possible there are no roots for this tree yet
@@ -390,14 +788,27 @@ func (m *mapTreeTX) StoreSignedMapRoot(ctx context.Context, root *trillian.Signe
 		return err
 	}
 
-	stmt, err := m.tx.PrepareContext(ctx, insertMapHeadSQL)
+	stored, err := latestSignerEpoch(ctx, m.tx, selectMapHeadEpochSQL, m.treeID)
 	if err != nil {
 		return err
 	}
-	defer stmt.Close()
+	epoch, err := storage.CheckSignerEpoch(ctx, m.treeID, stored)
+	if err != nil {
+		return err
+	}
+	if err := m.commitMapWriteBatch(ctx, int64(r.Revision)); err != nil {
+		return err
+	}
+
+	stmt, err := m.ms.getStmt(ctx, insertMapHeadSQL, 1, "?", "?")
+	if err != nil {
+		return err
+	}
+	stx := m.tx.StmtContext(ctx, stmt)
+	defer stx.Close()
 
 	// TODO(al): store transactionLogHead too
-	res, err := stmt.ExecContext(ctx, m.treeID, r.TimestampNanos, r.RootHash, r.Revision, root.Signature, r.Metadata)
+	res, err := stx.ExecContext(ctx, m.treeID, r.TimestampNanos, r.RootHash, r.Revision, root.Signature, r.Metadata, epoch)
 	if err != nil {
 		glog.Warningf("Failed to store signed map root: %s", err)
 	}
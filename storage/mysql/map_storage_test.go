@@ -20,6 +20,7 @@ import (
 	"crypto"
 	"crypto/sha256"
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
 
@@ -28,10 +29,14 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/trillian"
 	"github.com/google/trillian/integration/storagetest"
+	"github.com/google/trillian/merkle/smt"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/storage/testdb"
 	"github.com/google/trillian/testonly"
 	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	tcrypto "github.com/google/trillian/crypto"
 	storageto "github.com/google/trillian/storage/testonly"
@@ -340,6 +345,560 @@ func TestMapSetGetMultipleRevisions(t *testing.T) {
 	}
 }
 
+func TestMapPointLeafReads(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	as := NewAdminStorage(DB)
+	s := NewMapStorage(DB)
+	points := NewMapStorageWithOpts(DB, MapStorageOptions{PointLeafReads: true})
+	tree := createInitializedMapForTests(ctx, t, s, as)
+
+	keys := make([][]byte, 3)
+	for i := range keys {
+		h := sha256.Sum256([]byte(fmt.Sprintf("key %d", i)))
+		keys[i] = h[:]
+	}
+	// keys[0] is written at revisions 0 to 2, keys[1] at revisions 1 and 2, and
+	// keys[2] is never written.
+	for rev := int64(0); rev < 3; rev++ {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = rev
+			for i := int64(0); i <= rev && i < 2; i++ {
+				leaf := &trillian.MapLeaf{Index: keys[i], LeafHash: []byte{byte(rev)}, LeafValue: []byte(fmt.Sprintf("%d@%d", i, rev))}
+				if err := tx.Set(ctx, keys[i], leaf); err != nil {
+					t.Fatalf("Set(%x): %v", keys[i], err)
+				}
+			}
+			return nil
+		})
+	}
+
+	get := func(s storage.MapStorage, rev int64, indexes [][]byte) []*trillian.MapLeaf {
+		t.Helper()
+		var leaves []*trillian.MapLeaf
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			var err error
+			if leaves, err = tx.Get(ctx, rev, indexes); err != nil {
+				t.Fatalf("Get(%d): %v", rev, err)
+			}
+			return nil
+		})
+		sort.Slice(leaves, func(i, j int) bool { return bytes.Compare(leaves[i].Index, leaves[j].Index) < 0 })
+		return leaves
+	}
+	indexes := append(keys, keys[0])
+	for rev := int64(0); rev < 4; rev++ {
+		want := get(s, rev, indexes)
+		if diff := cmp.Diff(get(points, rev, indexes), want, cmp.Comparer(proto.Equal)); diff != "" {
+			t.Errorf("Get(%d) with point reads diff (-got +want):\n%s", rev, diff)
+		}
+	}
+}
+
+func TestMapScanLeaves(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	as := NewAdminStorage(DB)
+	s := NewMapStorage(DB)
+	tree := createInitializedMapForTests(ctx, t, s, as)
+
+	// More keys than fit in one scan batch, all of them written at revision 0,
+	// and every other one rewritten at revision 1.
+	keys := make([][]byte, mapLeafScanBatchSize+10)
+	for i := range keys {
+		h := sha256.Sum256([]byte(fmt.Sprintf("key %d", i)))
+		keys[i] = h[:]
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	for rev := int64(0); rev < 2; rev++ {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = rev
+			for i := int(rev); i < len(keys); i += int(rev) + 1 {
+				leaf := &trillian.MapLeaf{Index: keys[i], LeafHash: []byte{byte(rev)}, LeafValue: []byte(fmt.Sprintf("%d@%d", i, rev))}
+				if err := tx.Set(ctx, keys[i], leaf); err != nil {
+					t.Fatalf("Set(%x): %v", keys[i], err)
+				}
+			}
+			return nil
+		})
+	}
+
+	for rev := int64(0); rev < 3; rev++ {
+		var got []*trillian.MapLeaf
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			return tx.(storage.MapLeafScanner).ScanLeaves(ctx, rev, func(leaf *trillian.MapLeaf) error {
+				got = append(got, leaf)
+				return nil
+			})
+		})
+		var want []*trillian.MapLeaf
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			var err error
+			want, err = tx.Get(ctx, rev, keys)
+			return err
+		})
+		sort.Slice(want, func(i, j int) bool { return bytes.Compare(want[i].Index, want[j].Index) < 0 })
+		if diff := cmp.Diff(got, want, cmp.Comparer(proto.Equal)); diff != "" {
+			t.Errorf("ScanLeaves(%d) diff (-got +want):\n%s", rev, diff)
+		}
+	}
+}
+
+func TestMapLeafShard(t *testing.T) {
+	for _, tc := range []struct {
+		shards  int
+		keyHash []byte
+		want    int
+	}{
+		{shards: 0, keyHash: []byte{0xff}, want: 0},
+		{shards: 1, keyHash: []byte{0xff}, want: 0},
+		{shards: 4, keyHash: nil, want: 0},
+		{shards: 4, keyHash: []byte{0x00, 0xff}, want: 0},
+		{shards: 4, keyHash: []byte{0x3f}, want: 0},
+		{shards: 4, keyHash: []byte{0x40}, want: 1},
+		{shards: 4, keyHash: []byte{0xbf}, want: 2},
+		{shards: 4, keyHash: []byte{0xff}, want: 3},
+		{shards: 3, keyHash: []byte{0x56}, want: 1},
+		{shards: 256, keyHash: []byte{0x12}, want: 0x12},
+	} {
+		m := &mySQLMapStorage{opts: MapStorageOptions{LeafTableShards: tc.shards}}
+		if got := m.leafShard(tc.keyHash); got != tc.want {
+			t.Errorf("leafShard(%x) with %d shards: got %d, want %d", tc.keyHash, tc.shards, got, tc.want)
+		}
+	}
+}
+
+func TestMapLayout(t *testing.T) {
+	s := &mySQLMapStorage{}
+	for _, tc := range []struct {
+		strata     []int32
+		wantHeight int
+		wantErr    bool
+	}{
+		{strata: nil, wantHeight: 256},
+		{strata: []int32{16, 16, 224}, wantHeight: 256},
+		{strata: []int32{16, 16}, wantErr: true},
+		{strata: []int32{12, 244}, wantErr: true},
+	} {
+		tree := mapTree(1)
+		tree.MapStrata = tc.strata
+		l, err := s.Layout(tree)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("Layout(%v): %v, wantErr %v", tc.strata, err, tc.wantErr)
+			continue
+		}
+		if err == nil && l.Height != tc.wantHeight {
+			t.Errorf("Layout(%v): height %d, want %d", tc.strata, l.Height, tc.wantHeight)
+		}
+	}
+}
+
+func TestMapLeafTableShards(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	const shards = 4
+	for i := 0; i < shards; i++ {
+		table := fmt.Sprintf("MapLeaf_%d", i)
+		if _, err := DB.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s LIKE MapLeaf", table)); err != nil {
+			t.Fatalf("Failed to create %s: %v", table, err)
+		}
+		if _, err := DB.Exec(fmt.Sprintf("DELETE FROM %s", table)); err != nil {
+			t.Fatalf("Failed to delete rows in %s: %v", table, err)
+		}
+	}
+	cleanTestDB(DB)
+	ctx := context.Background()
+	as := NewAdminStorage(DB)
+	s := NewMapStorageWithOpts(DB, MapStorageOptions{LeafTableShards: shards})
+	tree := createInitializedMapForTests(ctx, t, s, as)
+
+	keys := make([][]byte, 20)
+	want := make([]*trillian.MapLeaf, len(keys))
+	for i := range keys {
+		h := sha256.Sum256([]byte(fmt.Sprintf("key %d", i)))
+		keys[i] = h[:]
+		want[i] = &trillian.MapLeaf{Index: keys[i], LeafHash: []byte{1}, LeafValue: []byte(fmt.Sprintf("value %d", i))}
+	}
+	sort.Slice(want, func(i, j int) bool { return bytes.Compare(want[i].Index, want[j].Index) < 0 })
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		tx.(*mapTreeTX).treeTX.writeRevision = 0
+		for _, leaf := range want {
+			if err := tx.Set(ctx, leaf.Index, leaf); err != nil {
+				t.Fatalf("Set(%x): %v", leaf.Index, err)
+			}
+		}
+		return nil
+	})
+
+	var count int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM MapLeaf").Scan(&count); err != nil {
+		t.Fatalf("Failed to count MapLeaf rows: %v", err)
+	} else if count != 0 {
+		t.Errorf("MapLeaf has %d rows, want 0", count)
+	}
+
+	for _, opts := range []MapStorageOptions{{LeafTableShards: shards}, {LeafTableShards: shards, PointLeafReads: true}} {
+		s := NewMapStorageWithOpts(DB, opts)
+		var got, scanned []*trillian.MapLeaf
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			var err error
+			if got, err = tx.Get(ctx, 0, keys); err != nil {
+				return err
+			}
+			return tx.(storage.MapLeafScanner).ScanLeaves(ctx, 0, func(leaf *trillian.MapLeaf) error {
+				scanned = append(scanned, leaf)
+				return nil
+			})
+		})
+		sort.Slice(got, func(i, j int) bool { return bytes.Compare(got[i].Index, got[j].Index) < 0 })
+		if diff := cmp.Diff(got, want, cmp.Comparer(proto.Equal)); diff != "" {
+			t.Errorf("Get() with %+v diff (-got +want):\n%s", opts, diff)
+		}
+		if diff := cmp.Diff(scanned, want, cmp.Comparer(proto.Equal)); diff != "" {
+			t.Errorf("ScanLeaves() with %+v diff (-got +want):\n%s", opts, diff)
+		}
+	}
+}
+
+func TestMapLeafWriteBatches(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	as := NewAdminStorage(DB)
+	s := NewMapStorageWithOpts(DB, MapStorageOptions{LeafWriteBatchSize: 3})
+	tree := createInitializedMapForTests(ctx, t, s, as)
+
+	keys := make([][]byte, 10)
+	want := make([]*trillian.MapLeaf, len(keys))
+	for i := range keys {
+		h := sha256.Sum256([]byte(fmt.Sprintf("key %d", i)))
+		keys[i] = h[:]
+		want[i] = &trillian.MapLeaf{Index: keys[i], LeafHash: []byte{1}, LeafValue: []byte(fmt.Sprintf("value %d", i))}
+	}
+	sort.Slice(want, func(i, j int) bool { return bytes.Compare(want[i].Index, want[j].Index) < 0 })
+	get := func(ctx context.Context, tx storage.MapTreeTX) {
+		t.Helper()
+		got, err := tx.Get(ctx, 0, keys)
+		if err != nil {
+			t.Fatalf("Get(): %v", err)
+		}
+		sort.Slice(got, func(i, j int) bool { return bytes.Compare(got[i].Index, got[j].Index) < 0 })
+		if diff := cmp.Diff(got, want, cmp.Comparer(proto.Equal)); diff != "" {
+			t.Errorf("Get() diff (-got +want):\n%s", diff)
+		}
+	}
+
+	// Buffered leaves are written before the transaction reads them.
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		tx.(*mapTreeTX).treeTX.writeRevision = 0
+		for _, leaf := range want {
+			if err := tx.Set(ctx, leaf.Index, leaf); err != nil {
+				t.Fatalf("Set(%x): %v", leaf.Index, err)
+			}
+		}
+		get(ctx, tx)
+		return nil
+	})
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		get(ctx, tx)
+		return nil
+	})
+
+	// Setting a key twice in a revision fails when the leaves are written.
+	err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+		tx.(*mapTreeTX).treeTX.writeRevision = 0
+		return tx.Set(ctx, want[0].Index, want[0])
+	})
+	if err == nil {
+		t.Error("ReadWriteTransaction() setting a key twice: nil error")
+	}
+}
+
+func TestMapConcurrentTileReads(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	as := NewAdminStorage(DB)
+	s := NewMapStorage(DB)
+	batched := NewMapStorageWithOpts(DB, MapStorageOptions{TileReadBatchSize: 3, TileReadConcurrency: 2})
+	shared := NewMapStorageWithOpts(DB, MapStorageOptions{TileReadBatchSize: 3, SharedTiles: cache.NewSharedMapTiles(100, nil)})
+	tree := createInitializedMapForTests(ctx, t, s, as)
+
+	var tiles []smt.Tile
+	var ids []stree.NodeID2
+	for i := 0; i < 10; i++ {
+		nodes, err := smt.NewNodesRow([]smt.Node{{ID: stree.NewNodeID2(fmt.Sprintf("%d1", i), 16), Hash: []byte{1}}})
+		if err != nil {
+			t.Fatalf("NewNodesRow(): %v", err)
+		}
+		tile := smt.Tile{ID: stree.NewNodeID2(fmt.Sprintf("%d", i), 8), Leaves: nodes}
+		tiles = append(tiles, tile)
+		ids = append(ids, tile.ID)
+	}
+	var rev int64
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		var err error
+		if rev, err = tx.WriteRevision(ctx); err != nil {
+			return err
+		}
+		return tx.SetTiles(ctx, tiles)
+	})
+
+	// Tiles are read at committed revisions, so in later transactions. The
+	// shared tile cache is read twice, to read from it.
+	for _, ms := range []storage.MapStorage{s, batched, shared, shared} {
+		runMapTX(ctx, ms, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			got, err := tx.GetTiles(ctx, rev, ids)
+			if err != nil {
+				t.Fatalf("GetTiles(): %v", err)
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i].ID.String() < got[j].ID.String() })
+			opt := cmp.Comparer(func(x, y stree.NodeID2) bool { return x.String() == y.String() })
+			if diff := cmp.Diff(got, tiles, opt); diff != "" {
+				t.Errorf("GetTiles() diff (-got +want):\n%s", diff)
+			}
+			return nil
+		})
+	}
+}
+
+func TestMapWriteBatch(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	as := NewAdminStorage(DB)
+	s := NewMapStorage(DB)
+	tree := createInitializedMapForTests(ctx, t, s, as)
+
+	tile := func(id string) smt.Tile {
+		nodes, err := smt.NewNodesRow([]smt.Node{{ID: stree.NewNodeID2(id+"1", 16), Hash: []byte{1}}})
+		if err != nil {
+			t.Fatalf("NewNodesRow(): %v", err)
+		}
+		return smt.Tile{ID: stree.NewNodeID2(id, 8), Leaves: nodes}
+	}
+	setTiles := func(ctx context.Context, tiles ...smt.Tile) error {
+		return s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+			return tx.SetTiles(ctx, tiles)
+		})
+	}
+
+	// Batch a fails after writing a tile at revision 1, without a root. Its
+	// later transactions are started from the context of the first one.
+	var ctxA context.Context
+	runMapTX(storage.WithMapWriteBatch(ctx, "a", 1), s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		ctxA = ctx
+		return tx.SetTiles(ctx, []smt.Tile{tile("a")})
+	})
+
+	// Batch b takes revision 1 over, which removes the tile of batch a.
+	ctxB := storage.WithMapWriteBatch(ctx, "b", 1)
+	if err := setTiles(ctxB, tile("b")); err != nil {
+		t.Fatalf("SetTiles(b): %v", err)
+	}
+	if err := setTiles(ctxA, tile("c")); status.Code(err) != codes.Aborted {
+		t.Errorf("SetTiles(c) of the failed batch: %v, want code %v", err, codes.Aborted)
+	}
+	if err := setTiles(storage.WithMapWriteBatch(ctx, "b", 2), tile("d")); status.Code(err) != codes.Aborted {
+		t.Errorf("SetTiles(d) at another revision: %v, want code %v", err, codes.Aborted)
+	}
+	root := MustSignMapRoot(t, &types.MapRootV1{Revision: 1, RootHash: []byte(dummyHash)})
+	runMapTX(ctxB, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		return tx.StoreSignedMapRoot(ctx, root)
+	})
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		ids := []stree.NodeID2{tile("a").ID, tile("b").ID, tile("c").ID}
+		got, err := tx.GetTiles(ctx, 1, ids)
+		if err != nil {
+			t.Fatalf("GetTiles(): %v", err)
+		}
+		if len(got) != 1 || got[0].ID != tile("b").ID {
+			t.Errorf("GetTiles(): %v, want only tile b", got)
+		}
+		return nil
+	})
+
+	var n int
+	if err := DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM MapWriteBatch WHERE TreeId=?", tree.TreeId).Scan(&n); err != nil {
+		t.Fatalf("Failed to count map write batches: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("%d map write batches left after storing the root, want 0", n)
+	}
+}
+
+func TestMapCompression(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	as := NewAdminStorage(DB)
+	s := NewMapStorage(DB)
+	tree := createInitializedMapForTests(ctx, t, s, as)
+
+	// Each revision is written with another compression, as after the
+	// compression of the tree is changed, and all of them read back.
+	compressions := []trillian.MapCompression{
+		trillian.MapCompression_NO_MAP_COMPRESSION,
+		trillian.MapCompression_MAP_COMPRESSION_SNAPPY,
+		trillian.MapCompression_MAP_COMPRESSION_ZSTD,
+	}
+	var keys [][]byte
+	var leaves []*trillian.MapLeaf
+	var tiles []smt.Tile
+	var ids []stree.NodeID2
+	for i, c := range compressions {
+		h := sha256.Sum256([]byte(c.String()))
+		keys = append(keys, h[:])
+		value := strings.Repeat(fmt.Sprintf(`{"compression": %q}`, c), 100)
+		leaves = append(leaves, &trillian.MapLeaf{Index: h[:], LeafHash: []byte{1}, LeafValue: []byte(value)})
+		nodes, err := smt.NewNodesRow([]smt.Node{{ID: stree.NewNodeID2(fmt.Sprintf("%d1", i), 16), Hash: dummyHash}})
+		if err != nil {
+			t.Fatalf("NewNodesRow(): %v", err)
+		}
+		tiles = append(tiles, smt.Tile{ID: stree.NewNodeID2(fmt.Sprintf("%d", i), 8), Leaves: nodes})
+		ids = append(ids, tiles[i].ID)
+
+		compressed := proto.Clone(tree).(*trillian.Tree)
+		compressed.MapCompression = c
+		runMapTX(ctx, s, compressed, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = int64(i + 1)
+			if err := tx.Set(ctx, keys[i], leaves[i]); err != nil {
+				t.Fatalf("Set(%v): %v", c, err)
+			}
+			return tx.SetTiles(ctx, tiles[i:i+1])
+		})
+
+		var leafValue []byte
+		if err := DB.QueryRowContext(ctx, "SELECT LeafValue FROM MapLeaf WHERE TreeId=? AND KeyHash=?", tree.TreeId, keys[i]).Scan(&leafValue); err != nil {
+			t.Fatalf("Failed to read leaf value: %v", err)
+		}
+		if got, want := leafValue[0] == 0, c != trillian.MapCompression_NO_MAP_COMPRESSION; got != want {
+			t.Errorf("%v: leaf value compressed: %v, want %v", c, got, want)
+		}
+		if want := len(value); c != trillian.MapCompression_NO_MAP_COMPRESSION && len(leafValue) >= want {
+			t.Errorf("%v: leaf value of %d bytes, want fewer than %d", c, len(leafValue), want)
+		}
+	}
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		got, err := tx.Get(ctx, int64(len(compressions)), keys)
+		if err != nil {
+			t.Fatalf("Get(): %v", err)
+		}
+		byIndex := func(l []*trillian.MapLeaf) func(i, j int) bool {
+			return func(i, j int) bool { return bytes.Compare(l[i].Index, l[j].Index) < 0 }
+		}
+		sort.Slice(got, byIndex(got))
+		sort.Slice(leaves, byIndex(leaves))
+		if diff := cmp.Diff(got, leaves, cmp.Comparer(proto.Equal)); diff != "" {
+			t.Errorf("Get() diff (-got +want):\n%s", diff)
+		}
+		gotTiles, err := tx.GetTiles(ctx, int64(len(compressions)), ids)
+		if err != nil {
+			t.Fatalf("GetTiles(): %v", err)
+		}
+		sort.Slice(gotTiles, func(i, j int) bool { return gotTiles[i].ID.String() < gotTiles[j].ID.String() })
+		opt := cmp.Comparer(func(x, y stree.NodeID2) bool { return x.String() == y.String() })
+		if diff := cmp.Diff(gotTiles, tiles, opt); diff != "" {
+			t.Errorf("GetTiles() diff (-got +want):\n%s", diff)
+		}
+		return nil
+	})
+}
+
+func TestMapBulkWrite(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	as := NewAdminStorage(DB)
+	s := NewMapStorage(DB)
+	tree := createInitializedMapForTests(ctx, t, s, as)
+	w := s.(storage.MapBulkWriter)
+
+	// More leaves than fit in one statement, and a deletion, which is skipped.
+	var keys [][]byte
+	var leaves []*trillian.MapLeaf
+	for i := 0; i < bulkWriteStatementRows+10; i++ {
+		h := sha256.Sum256([]byte(fmt.Sprintf("key %d", i)))
+		keys = append(keys, h[:])
+		leaves = append(leaves, &trillian.MapLeaf{Index: h[:], LeafHash: []byte{1}, LeafValue: []byte(fmt.Sprintf("value %d", i))})
+	}
+	deleted := sha256.Sum256([]byte("deleted"))
+	nodes, err := smt.NewNodesRow([]smt.Node{{ID: stree.NewNodeID2("01", 16), Hash: dummyHash}})
+	if err != nil {
+		t.Fatalf("NewNodesRow(): %v", err)
+	}
+	tiles := []smt.Tile{{ID: stree.NewNodeID2("0", 8), Leaves: nodes}}
+
+	// What a failed load left behind is removed.
+	stale := sha256.Sum256([]byte("stale"))
+	if err := w.BeginBulkWrite(ctx, tree); err != nil {
+		t.Fatalf("BeginBulkWrite(): %v", err)
+	}
+	if err := w.BulkWriteLeaves(ctx, tree, []*trillian.MapLeaf{{Index: stale[:], LeafValue: []byte("stale")}}); err != nil {
+		t.Fatalf("BulkWriteLeaves(): %v", err)
+	}
+	if err := w.BulkWriteTiles(ctx, tree, tiles); err != nil {
+		t.Fatalf("BulkWriteTiles(): %v", err)
+	}
+	if err := w.BeginBulkWrite(ctx, tree); err != nil {
+		t.Fatalf("BeginBulkWrite(): %v", err)
+	}
+
+	if err := w.BulkWriteLeaves(ctx, tree, append(leaves, &trillian.MapLeaf{Index: deleted[:]})); err != nil {
+		t.Fatalf("BulkWriteLeaves(): %v", err)
+	}
+	if err := w.BulkWriteTiles(ctx, tree, tiles); err != nil {
+		t.Fatalf("BulkWriteTiles(): %v", err)
+	}
+	root := MustSignMapRoot(t, &types.MapRootV1{RootHash: []byte("rootHash"), TimestampNanos: 1, Revision: 1})
+	if err := w.CommitBulkWrite(ctx, tree, root); err != nil {
+		t.Fatalf("CommitBulkWrite(): %v", err)
+	}
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		got, err := tx.Get(ctx, 1, append(keys, stale[:], deleted[:]))
+		if err != nil {
+			t.Fatalf("Get(): %v", err)
+		}
+		byIndex := func(l []*trillian.MapLeaf) func(i, j int) bool {
+			return func(i, j int) bool { return bytes.Compare(l[i].Index, l[j].Index) < 0 }
+		}
+		sort.Slice(got, byIndex(got))
+		sort.Slice(leaves, byIndex(leaves))
+		if diff := cmp.Diff(got, leaves, cmp.Comparer(proto.Equal)); diff != "" {
+			t.Errorf("Get() diff (-got +want):\n%s", diff)
+		}
+		gotTiles, err := tx.GetTiles(ctx, 1, []stree.NodeID2{tiles[0].ID})
+		if err != nil {
+			t.Fatalf("GetTiles(): %v", err)
+		}
+		opt := cmp.Comparer(func(x, y stree.NodeID2) bool { return x.String() == y.String() })
+		if diff := cmp.Diff(gotTiles, tiles, opt); diff != "" {
+			t.Errorf("GetTiles() diff (-got +want):\n%s", diff)
+		}
+		return nil
+	})
+
+	// The map isn't fresh any more.
+	if err := w.BeginBulkWrite(ctx, tree); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("BeginBulkWrite(): %v, want code %v", err, codes.FailedPrecondition)
+	}
+	if err := w.CommitBulkWrite(ctx, tree, root); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CommitBulkWrite(): %v, want code %v", err, codes.FailedPrecondition)
+	}
+}
+
 func TestMapMultiRevisionFetchBigBatch(t *testing.T) {
 	t.Skip("Known Issue: https://github.com/google/trillian/issues/1845")
 	testdb.SkipIfNoMySQL(t)
@@ -618,6 +1177,87 @@ func TestReadOnlyMapTX_Rollback(t *testing.T) {
 	}
 }
 
+func TestMapRootStatementsCached(t *testing.T) {
+	testdb.SkipIfNoMySQL(t)
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	tree := createInitializedMapForTests(ctx, t, NewMapStorage(DB), NewAdminStorage(DB))
+
+	// A fresh storage, whose statements are only cached by the reads and
+	// writes of the roots below.
+	s := NewMapStorage(DB)
+	ms := s.(*mySQLMapStorage)
+	queries := []string{selectLatestSignedMapRootSQL, selectGetSignedMapRootSQL, insertMapHeadSQL}
+	for _, query := range queries {
+		if ms.statements[query] != nil {
+			t.Fatalf("statement %q cached before use", query)
+		}
+	}
+
+	root := MustSignMapRoot(t, &types.MapRootV1{Revision: 1, RootHash: []byte("rootHash"), TimestampNanos: 1})
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if _, err := tx.LatestSignedMapRoot(ctx); err != nil {
+			t.Fatalf("LatestSignedMapRoot(): %v", err)
+		}
+		if _, err := tx.GetSignedMapRoot(ctx, 0); err != nil {
+			t.Fatalf("GetSignedMapRoot(): %v", err)
+		}
+		return tx.StoreSignedMapRoot(ctx, root)
+	})
+
+	for _, query := range queries {
+		cached := ms.statements[query][1]
+		if cached == nil {
+			t.Errorf("statement %q was not cached", query)
+			continue
+		}
+		stmt, err := ms.getStmt(ctx, query, 1, "?", "?")
+		if err != nil {
+			t.Fatalf("getStmt(): %v", err)
+		}
+		if stmt != cached {
+			t.Errorf("getStmt(%q) prepared the statement again, want the cached one", query)
+		}
+	}
+}
+
+// BenchmarkLatestSignedMapRoot reads the latest root of a map in a new
+// transaction, as every map RPC does.
+func BenchmarkLatestSignedMapRoot(b *testing.B) {
+	cleanTestDB(DB)
+	ctx := context.Background()
+	s := NewMapStorage(DB)
+	tree, err := storage.CreateTree(ctx, NewAdminStorage(DB), storageto.MapTree)
+	if err != nil {
+		b.Fatalf("CreateTree(): %v", err)
+	}
+	signer := tcrypto.NewSigner(tree.TreeId, testonly.NewSignerWithFixedSig(nil, []byte("sig")), crypto.SHA256)
+	root, err := signer.SignMapRoot(&types.MapRootV1{RootHash: []byte("rootHash")})
+	if err != nil {
+		b.Fatalf("SignMapRoot(): %v", err)
+	}
+	if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+		return tx.StoreSignedMapRoot(ctx, root)
+	}); err != nil {
+		b.Fatalf("StoreSignedMapRoot(): %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx, err := s.SnapshotForTree(ctx, tree)
+		if err != nil {
+			b.Fatalf("SnapshotForTree(): %v", err)
+		}
+		if _, err := tx.LatestSignedMapRoot(ctx); err != nil {
+			b.Fatalf("LatestSignedMapRoot(): %v", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			b.Fatalf("Commit(): %v", err)
+		}
+	}
+}
+
 func runMapTX(ctx context.Context, s storage.MapStorage, tree *trillian.Tree, t *testing.T, f storage.MapTXFunc) {
 	if err := s.ReadWriteTransaction(ctx, tree, f); err != nil {
 		t.Fatalf("Failed to begin map tx: %v", err)
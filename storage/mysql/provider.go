@@ -16,21 +16,47 @@ package mysql
 
 import (
 	"database/sql"
+	"errors"
 	"flag"
+	"fmt"
 	"sync"
 
 	"github.com/golang/glog"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
-
-	// Load MySQL driver
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/trillian/storage/cache"
 )
 
 var (
 	mySQLURI = flag.String("mysql_uri", "test:zaphod@tcp(127.0.0.1:3306)/test", "Connection URI for MySQL database")
 	maxConns = flag.Int("mysql_max_conns", 0, "Maximum connections to the database")
 	maxIdle  = flag.Int("mysql_max_idle_conns", -1, "Maximum idle database connections in the connection pool")
+	maxLife  = flag.Duration("mysql_conn_max_lifetime", 0, "Maximum time a database connection may be reused for, 0 means no limit")
+	comments = flag.Bool("mysql_sql_comments", false, "If true, SQL statements are annotated with comments identifying the trace, RPC method, request ID and tree they are issued for. Statements are then prepared for every execution rather than reused")
+
+	deadlineHints = flag.Bool("mysql_deadline_hints", false, "If true, SELECT statements issued for requests with a deadline are given a MAX_EXECUTION_TIME optimizer hint of the time left, so that the MySQL server aborts them once the deadline has passed, rather than running them to completion. Statements are then prepared for every execution rather than reused")
+
+	treeDBTemplate = flag.String("mysql_tree_database_template", "", "If set, the data of each tree is stored in its own database on the --mysql_uri server, named by this template, in which {tree_id} is replaced by the ID of the tree, e.g. trillian_{tree_id}. Tree metadata stays in the --mysql_uri database. Tree databases must exist and have the Trillian schema")
+	replicaURI = flag.String("mysql_replica_uri", "", "If set, connection URI of a read-only replica of the --mysql_uri database, which the read-only transactions of trees, e.g. those of GetLeavesByRange and GetLeaves, run on, keeping the primary free for sequencing and map writes. Reads then lag behind writes by the replication delay. Not supported with tree databases")
+
+	treeDBs        = flag.String("mysql_tree_databases", "", "Comma-separated list of treeID=database pairs, which store the data of the given trees in the given databases on the --mysql_uri server, overriding --mysql_tree_database_template. Several trees may share a database")
+
+	mapPointReads = flag.Bool("mysql_map_point_reads", false, "If true, map leaves are read with a query per leaf rather than with a single query joining the MapLeaf table with itself. The per-leaf queries only touch the rows of one leaf, which suits sharded deployments such as Vitess")
+
+	mapTileReadBatch       = flag.Int("mysql_map_tile_read_batch_size", 0, "If positive, map reads of more than this many tiles are split into queries for batches of this many tiles, which run concurrently on separate connections. This cuts the latency of reading thousands of map leaves at once")
+	mapTileReadConcurrency = flag.Int("mysql_map_tile_read_concurrency", defaultTileReadConcurrency, "Maximum number of concurrent queries of a map tile read split by --mysql_map_tile_read_batch_size")
+
+	mapLeafTableShards = flag.Int("mysql_map_leaf_table_shards", 0, "If above 1, map leaves are stored across this many tables named MapLeaf_0, MapLeaf_1, etc., each holding a contiguous range of key hashes, rather than in the MapLeaf table. This spreads the writes to a single map across tables, which can be placed in separate partitions or shards. The tables must exist and have the schema of MapLeaf, and the number of shards must not change once maps have leaves. At most 256")
+
+	mapLeafWriteBatch = flag.Int("mysql_map_leaf_write_batch_size", 0, "If above 1, the map leaves written by a transaction are buffered, and written with multi-row INSERT statements of up to this many rows, rather than a statement per leaf. This saves round trips on large map updates. At most 13107")
+
+	sharedSubtreeCacheSize = flag.Int("mysql_shared_subtree_cache_size", 0, "If positive, the number of log subtrees cached across transactions, which saves reading the same subtrees of a log on every sequencing pass, and lets the signer pre-fetch the subtrees of logs it becomes master for")
+
+	sharedMapTileCacheSize = flag.Int("mysql_shared_map_tile_cache_size", 0, "If positive, the number of map tiles cached across transactions, which saves reading the tiles near the roots of maps from the database on every request. Tiles of a map are evicted once a newer revision of it is read or written")
+
+	treeUsageShards = flag.Int("mysql_tree_usage_shards", 0, "If positive, the bytes of leaves and subtrees written for each tree are maintained in the TreeUsage table across this many rows by the transactions which write them, and the max_storage_bytes of trees is enforced. Usage written before enabling it isn't counted")
+
+	treeStatsShards = flag.Int("mysql_tree_stats_shards", 0, "If positive, the statistics of each log, such as its leaf count, are maintained in the TreeStats table across this many rows by the transactions which write leaves, so that reading them doesn't require scanning the leaves. The statistics of existing logs must be backfilled before enabling it")
 
 	mysqlMu              sync.Mutex
 	mysqlErr             error
@@ -55,8 +81,21 @@ func init() {
 }
 
 type mysqlProvider struct {
-	db *sql.DB
-	mf monitoring.MetricFactory
+	db          *sql.DB
+	mf          monitoring.MetricFactory
+	stopMonitor func()
+	// replica, if set, is the read-only replica of db which the read-only
+	// transactions of trees run on.
+	replica            *sql.DB
+	stopReplicaMonitor func()
+	// treeDBs, if set, stores the data of trees in their own databases.
+	treeDBs *TreeDatabases
+	// cluster is whether the database is a multi-primary cluster or TiDB, in
+	// which read-write transactions are retried up to clusterRetries times.
+	cluster        bool
+	clusterRetries int
+	logOpts        LogStorageOptions
+	mapOpts        MapStorageOptions
 }
 
 func newMySQLStorageProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
@@ -67,9 +106,58 @@ func newMySQLStorageProvider(mf monitoring.MetricFactory) (storage.Provider, err
 		if err != nil {
 			return nil, err
 		}
+		overrides, err := ParseTreeDatabases(*treeDBs)
+		if err != nil {
+			return nil, err
+		}
+		if *mapLeafTableShards > maxMapLeafTableShards {
+			return nil, fmt.Errorf("--mysql_map_leaf_table_shards is %d, want at most %d", *mapLeafTableShards, maxMapLeafTableShards)
+		}
+		if *mapLeafWriteBatch > maxMapLeafWriteBatchSize {
+			return nil, fmt.Errorf("--mysql_map_leaf_write_batch_size is %d, want at most %d", *mapLeafWriteBatch, maxMapLeafWriteBatchSize)
+		}
+		if *replicaURI != "" && (*treeDBTemplate != "" || len(overrides) > 0) {
+			return nil, errors.New("--mysql_replica_uri is not supported with tree databases")
+		}
 		mysqlStorageInstance = &mysqlProvider{
-			db: db,
-			mf: mf,
+			db:          db,
+			mf:          mf,
+			stopMonitor: storage.MonitorSQLPool(db, "mysql", mf, storage.SQLPoolStatsInterval),
+		}
+		if *replicaURI != "" {
+			replica, err := openReplicaDatabase(*replicaURI)
+			if err != nil {
+				return nil, err
+			}
+			mysqlStorageInstance.replica = replica
+			mysqlStorageInstance.stopReplicaMonitor = storage.MonitorSQLPool(replica, "mysql_replica", mf, storage.SQLPoolStatsInterval)
+			mysqlStorageInstance.logOpts.ReplicaDB = replica
+			mysqlStorageInstance.mapOpts.ReplicaDB = replica
+		}
+		if *clusterMode != "" || *tidb {
+			mysqlStorageInstance.cluster = true
+			mysqlStorageInstance.clusterRetries = *clusterRetries
+		}
+		if *tidb {
+			mysqlStorageInstance.logOpts.QueueBuckets = *tidbQueueBuckets
+		}
+		if *sharedSubtreeCacheSize > 0 {
+			mysqlStorageInstance.logOpts.SharedSubtrees = cache.NewSharedLogSubtrees(*sharedSubtreeCacheSize, mf)
+		}
+		mysqlStorageInstance.logOpts.TreeStatsShards = *treeStatsShards
+		mysqlStorageInstance.logOpts.TreeUsageShards = *treeUsageShards
+		mysqlStorageInstance.mapOpts.TreeUsageShards = *treeUsageShards
+		mysqlStorageInstance.mapOpts.PointLeafReads = *mapPointReads
+		mysqlStorageInstance.mapOpts.TileReadBatchSize = *mapTileReadBatch
+		mysqlStorageInstance.mapOpts.TileReadConcurrency = *mapTileReadConcurrency
+		mysqlStorageInstance.mapOpts.LeafTableShards = *mapLeafTableShards
+		mysqlStorageInstance.mapOpts.LeafWriteBatchSize = *mapLeafWriteBatch
+		if *sharedMapTileCacheSize > 0 {
+			mysqlStorageInstance.mapOpts.SharedTiles = cache.NewSharedMapTiles(*sharedMapTileCacheSize, mf)
+		}
+		if *treeDBTemplate != "" || len(overrides) > 0 {
+			open := OpenTreeDatabase(*mySQLURI, *comments, poolConfig())
+			mysqlStorageInstance.treeDBs = NewTreeDatabases(db, TreeDatabaseNames(*treeDBTemplate, overrides), open, mf, mysqlStorageInstance.logOpts, mysqlStorageInstance.mapOpts)
 		}
 	}
 	return mysqlStorageInstance, nil
@@ -81,33 +169,94 @@ func getMySQLDatabaseLocked() (*sql.DB, error) {
 	if mysqlDB != nil || mysqlErr != nil {
 		return mysqlDB, mysqlErr
 	}
-	db, err := OpenDB(*mySQLURI)
+	c, err := newConnector(*mySQLURI, *comments)
 	if err != nil {
+		// Don't log uri as it could contain credentials
+		glog.Warningf("Could not open MySQL database, check config: %s", err)
 		mysqlErr = err
 		return nil, err
 	}
-	if *maxConns > 0 {
-		db.SetMaxOpenConns(*maxConns)
-	}
-	if *maxIdle >= 0 {
-		db.SetMaxIdleConns(*maxIdle)
+	db, err := openDB(c)
+	if err != nil {
+		mysqlErr = err
+		return nil, err
 	}
+	poolConfig().Apply(db)
 	mysqlDB, mysqlErr = db, nil
 	return db, nil
 }
 
+// openReplicaDatabase opens the read-only replica database at uri, with the
+// same settings as the primary database.
+func openReplicaDatabase(uri string) (*sql.DB, error) {
+	c, err := newConnector(uri, *comments)
+	if err != nil {
+		// Don't log uri as it could contain credentials
+		glog.Warningf("Could not open MySQL replica database, check config: %s", err)
+		return nil, err
+	}
+	db, err := openDB(c)
+	if err != nil {
+		return nil, err
+	}
+	poolConfig().Apply(db)
+	return db, nil
+}
+
+// poolConfig returns the configuration of database connection pools.
+func poolConfig() storage.SQLPoolConfig {
+	return storage.SQLPoolConfig{
+		MaxOpenConns:    *maxConns,
+		MaxIdleConns:    *maxIdle,
+		ConnMaxLifetime: *maxLife,
+	}
+}
+
 func (s *mysqlProvider) LogStorage() storage.LogStorage {
-	return NewLogStorage(s.db, s.mf)
+	var ls storage.LogStorage
+	if s.treeDBs != nil {
+		ls = s.treeDBs.LogStorage()
+	} else {
+		ls = NewLogStorageWithOpts(s.db, s.mf, s.logOpts)
+	}
+	if s.cluster {
+		return &clusterLogStorage{LogStorage: ls, retries: s.clusterRetries}
+	}
+	return ls
 }
 
 func (s *mysqlProvider) MapStorage() storage.MapStorage {
-	return NewMapStorage(s.db)
+	var ms storage.MapStorage
+	if s.treeDBs != nil {
+		ms = s.treeDBs.MapStorage()
+	} else {
+		ms = NewMapStorageWithOpts(s.db, s.mapOpts)
+	}
+	if s.cluster {
+		return &clusterMapStorage{MapStorage: ms, retries: s.clusterRetries}
+	}
+	return ms
 }
 
 func (s *mysqlProvider) AdminStorage() storage.AdminStorage {
+	if s.cluster {
+		return &clusterAdminStorage{mysqlAdminStorage: &mysqlAdminStorage{s.db}, retries: s.clusterRetries}
+	}
 	return NewAdminStorage(s.db)
 }
 
 func (s *mysqlProvider) Close() error {
+	s.stopMonitor()
+	if s.replica != nil {
+		s.stopReplicaMonitor()
+		if err := s.replica.Close(); err != nil {
+			glog.Warningf("Failed to close replica database: %v", err)
+		}
+	}
+	if s.treeDBs != nil {
+		if err := s.treeDBs.Close(); err != nil {
+			glog.Warningf("Failed to close tree databases: %v", err)
+		}
+	}
 	return s.db.Close()
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/util/requestid"
 )
 
 const (
@@ -36,9 +37,17 @@ const (
 			AND Bucket=0
 			AND QueueTimestampNanos<=?
 			ORDER BY QueueTimestampNanos,LeafIdentityHash ASC LIMIT ?`
+	// selectQueuedLeavesFromBucketsSQL is selectQueuedLeavesSQL for leaves
+	// queued in several buckets, see LogStorageOptions.QueueBuckets.
+	selectQueuedLeavesFromBucketsSQL = `SELECT LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos
+			FROM Unsequenced
+			WHERE TreeID=?
+			AND Bucket<?
+			AND QueueTimestampNanos<=?
+			ORDER BY QueueTimestampNanos,LeafIdentityHash ASC LIMIT ?`
 	insertUnsequencedEntrySQL = `INSERT INTO Unsequenced(TreeId,Bucket,LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos)
-			VALUES(?,0,?,?,?)`
-	deleteUnsequencedSQL = "DELETE FROM Unsequenced WHERE TreeId=? AND Bucket=0 AND QueueTimestampNanos=? AND LeafIdentityHash=?"
+			VALUES(?,?,?,?,?)`
+	deleteUnsequencedSQL = "DELETE FROM Unsequenced WHERE TreeId=? AND Bucket=? AND QueueTimestampNanos=? AND LeafIdentityHash=?"
 )
 
 type dequeuedLeaf struct {
@@ -101,7 +110,7 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 			leaf.LeafIndex,
 			iTimestamp.UnixNano())
 		if err != nil {
-			glog.Warningf("Failed to update sequenced leaves: %s", err)
+			glog.Warningf("%sFailed to update sequenced leaves: %s", requestid.Prefix(ctx), err)
 			return err
 		}
 
@@ -112,7 +121,11 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 		dequeuedLeaves = append(dequeuedLeaves, qe)
 	}
 
-	return t.removeSequencedLeaves(ctx, dequeuedLeaves)
+	if err := t.removeSequencedLeaves(ctx, dequeuedLeaves); err != nil {
+		return err
+	}
+	n := int64(len(leaves))
+	return t.updateTreeStats(ctx, treeStatsDelta{leaves: n, unsequenced: -n})
 }
 
 // removeSequencedLeaves removes the passed in leaves slice (which may be
@@ -124,18 +137,19 @@ func (t *logTreeTX) removeSequencedLeaves(ctx context.Context, leaves []dequeued
 	// QueueLeaves.
 	stx, err := t.tx.PrepareContext(ctx, deleteUnsequencedSQL)
 	if err != nil {
-		glog.Warningf("Failed to prep delete statement for sequenced work: %v", err)
+		glog.Warningf("%sFailed to prep delete statement for sequenced work: %v", requestid.Prefix(ctx), err)
 		return err
 	}
 	defer stx.Close()
 	for _, dql := range leaves {
-		result, err := stx.ExecContext(ctx, t.treeID, dql.queueTimestampNanos, dql.leafIdentityHash)
+		bucket := queueBucket(dql.leafIdentityHash, t.ls.opts.QueueBuckets)
+		result, err := stx.ExecContext(ctx, t.treeID, bucket, dql.queueTimestampNanos, dql.leafIdentityHash)
 		err = checkResultOkAndRowCountIs(result, err, int64(1))
 		if err != nil {
 			return err
 		}
 	}
 
-	observe(dequeueRemoveLatency, time.Since(start), labelForTX(t))
+	observe(ctx, dequeueRemoveLatency, time.Since(start), labelForTX(t))
 	return nil
 }
@@ -28,6 +28,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/util/requestid"
 )
 
 const (
@@ -38,7 +39,15 @@ const (
 			AND Bucket=0
 			AND QueueTimestampNanos<=?
 			ORDER BY QueueTimestampNanos,LeafIdentityHash ASC LIMIT ?`
-	insertUnsequencedEntrySQL = `INSERT INTO Unsequenced(TreeId,Bucket,LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,QueueID) VALUES(?,0,?,?,?,?)`
+	// selectQueuedLeavesFromBucketsSQL is selectQueuedLeavesSQL for leaves
+	// queued in several buckets, see LogStorageOptions.QueueBuckets.
+	selectQueuedLeavesFromBucketsSQL = `SELECT LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,QueueID
+			FROM Unsequenced
+			WHERE TreeID=?
+			AND Bucket<?
+			AND QueueTimestampNanos<=?
+			ORDER BY QueueTimestampNanos,LeafIdentityHash ASC LIMIT ?`
+	insertUnsequencedEntrySQL = `INSERT INTO Unsequenced(TreeId,Bucket,LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,QueueID) VALUES(?,?,?,?,?,?)`
 	deleteUnsequencedSQL      = "DELETE FROM Unsequenced WHERE QueueID IN (<placeholder>)"
 )
 
@@ -111,13 +120,17 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 	}
 	result, err := t.tx.ExecContext(ctx, insertSequencedLeafSQL+strings.Join(querySuffix, ","), args...)
 	if err != nil {
-		glog.Warningf("Failed to update sequenced leaves: %s", err)
+		glog.Warningf("%sFailed to update sequenced leaves: %s", requestid.Prefix(ctx), err)
 	}
 	if err := checkResultOkAndRowCountIs(result, err, int64(len(leaves))); err != nil {
 		return err
 	}
 
-	return t.removeSequencedLeaves(ctx, dequeuedLeaves)
+	if err := t.removeSequencedLeaves(ctx, dequeuedLeaves); err != nil {
+		return err
+	}
+	n := int64(len(leaves))
+	return t.updateTreeStats(ctx, treeStatsDelta{leaves: n, unsequenced: -n})
 }
 
 func (m *mySQLLogStorage) getDeleteUnsequencedStmt(ctx context.Context, num int) (*sql.Stmt, error) {
@@ -132,7 +145,7 @@ func (t *logTreeTX) removeSequencedLeaves(ctx context.Context, queueIDs []dequeu
 	// QueueLeaves.
 	tmpl, err := t.ls.getDeleteUnsequencedStmt(ctx, len(queueIDs))
 	if err != nil {
-		glog.Warningf("Failed to get delete statement for sequenced work: %s", err)
+		glog.Warningf("%sFailed to get delete statement for sequenced work: %s", requestid.Prefix(ctx), err)
 		return err
 	}
 	stx := t.tx.StmtContext(ctx, tmpl)
@@ -143,7 +156,7 @@ func (t *logTreeTX) removeSequencedLeaves(ctx context.Context, queueIDs []dequeu
 	result, err := stx.ExecContext(ctx, args...)
 	if err != nil {
 		// Error is handled by checkResultOkAndRowCountIs() below
-		glog.Warningf("Failed to delete sequenced work: %s", err)
+		glog.Warningf("%sFailed to delete sequenced work: %s", requestid.Prefix(ctx), err)
 	}
 	return checkResultOkAndRowCountIs(result, err, int64(len(queueIDs)))
 }
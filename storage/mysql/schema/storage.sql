@@ -10,7 +10,7 @@ CREATE TABLE IF NOT EXISTS Trees(
   TreeId                BIGINT NOT NULL,
   TreeState             ENUM('ACTIVE', 'FROZEN', 'DRAINING') NOT NULL,
   TreeType              ENUM('LOG', 'MAP', 'PREORDERED_LOG') NOT NULL,
-  HashStrategy          ENUM('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256', 'CONIKS_SHA256') NOT NULL,
+  HashStrategy          ENUM('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256', 'CONIKS_SHA256', 'CUSTOM_MAP_HASHER') NOT NULL,
   HashAlgorithm         ENUM('SHA256') NOT NULL,
   SignatureAlgorithm    ENUM('ECDSA', 'RSA', 'ED25519') NOT NULL,
   DisplayName           VARCHAR(20),
@@ -22,6 +22,30 @@ CREATE TABLE IF NOT EXISTS Trees(
   PublicKey             MEDIUMBLOB NOT NULL,
   Deleted               BOOLEAN,
   DeleteTimeMillis      BIGINT,
+  -- Serialized TreeRateLimits proto, or NULL if the tree has no rate limits.
+  RateLimits            BLOB,
+  -- Labels of the tree as a JSON object, or NULL if the tree has no labels.
+  Labels                TEXT,
+  -- ID of the CreateTree request which created the tree, or NULL if none.
+  CreateRequestId       VARCHAR(128) UNIQUE,
+  -- Maintenance mode of the tree as a serialized TreeMaintenance, or NULL if
+  -- the tree is not in maintenance.
+  Maintenance           BLOB,
+  -- Map strata of the tree as a JSON array, or NULL if it uses the default.
+  MapStrata             TEXT,
+  -- MapCompression of the leaves and tiles of the tree, or NULL if none.
+  MapCompression        VARCHAR(32),
+  -- Name of the registered hasher of a CUSTOM_MAP_HASHER map, or NULL.
+  MapHasher             VARCHAR(255),
+  -- Whether the first write to the map initializes it.
+  AutoInit              BOOLEAN NOT NULL DEFAULT FALSE,
+  -- Maximum bytes of storage written for the tree, or 0 for no limit.
+  MaxStorageBytes       BIGINT NOT NULL DEFAULT 0,
+  -- Whether the keys of the leaves of the map are indexed.
+  MapKeyIndex           BOOLEAN NOT NULL DEFAULT FALSE,
+  -- Public keys the tree's key was rotated away from, as a serialized Tree
+  -- with only retired_public_keys set, or NULL if it was never rotated.
+  RetiredPublicKeys     BLOB,
   PRIMARY KEY(TreeId)
 );
 
@@ -56,6 +80,8 @@ CREATE TABLE IF NOT EXISTS TreeHead(
   RootHash             VARBINARY(255) NOT NULL,
   RootSignature        VARBINARY(1024) NOT NULL,
   TreeRevision         BIGINT,
+  -- The epoch of the signer which wrote the root, see storage.WithSignerEpoch.
+  SignerEpoch          BIGINT NOT NULL DEFAULT 0,
   PRIMARY KEY(TreeId, TreeHeadTimestamp),
   FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
 );
@@ -137,6 +163,48 @@ CREATE TABLE IF NOT EXISTS Unsequenced(
   PRIMARY KEY (TreeId, Bucket, QueueTimestampNanos, LeafIdentityHash)
 );
 
+-- Queued leaves which repeatedly caused sequencing to fail are moved here from
+-- the Unsequenced table, so that they no longer block the rest of the queue.
+-- The leaf data itself stays in LeafData until the entry is purged.
+CREATE TABLE IF NOT EXISTS DeadLetter(
+  TreeId                   BIGINT NOT NULL,
+  LeafIdentityHash         VARBINARY(255) NOT NULL,
+  MerkleLeafHash           VARBINARY(255) NOT NULL,
+  QueueTimestampNanos      BIGINT NOT NULL,
+  QuarantineTimestampNanos BIGINT NOT NULL,
+  Reason                   VARCHAR(1024),
+  PRIMARY KEY (TreeId, LeafIdentityHash),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE,
+  FOREIGN KEY(TreeId, LeafIdentityHash) REFERENCES LeafData(TreeId, LeafIdentityHash) ON DELETE CASCADE
+);
+
+-- Statistics of logs, maintained by the write paths if the log storage is
+-- configured with TreeStatsShards. The statistics of a log are spread across
+-- several shard rows, which are summed up when read, so that concurrent
+-- writers don't contend for a single row.
+CREATE TABLE IF NOT EXISTS TreeStats(
+  TreeId               BIGINT NOT NULL,
+  Shard                INTEGER NOT NULL,
+  LeafCount            BIGINT NOT NULL DEFAULT 0,
+  LeafValueBytes       BIGINT NOT NULL DEFAULT 0,
+  ExtraDataBytes       BIGINT NOT NULL DEFAULT 0,
+  UnsequencedCount     BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY(TreeId, Shard),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- Bytes of leaves and subtrees written for trees, maintained by the write
+-- paths if the storage is configured with TreeUsageShards. The usage of a tree
+-- is spread across several shard rows, as the statistics in TreeStats are.
+CREATE TABLE IF NOT EXISTS TreeUsage(
+  TreeId               BIGINT NOT NULL,
+  Shard                INTEGER NOT NULL,
+  LeafBytes            BIGINT NOT NULL DEFAULT 0,
+  SubtreeBytes         BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY(TreeId, Shard),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
 
 -- ---------------------------------------------
 -- Map specific stuff here
@@ -149,10 +217,24 @@ CREATE TABLE IF NOT EXISTS MapLeaf(
   -- st. more recent revisions come first.
   MapRevision           BIGINT NOT NULL,
   LeafValue             LONGBLOB NOT NULL,
+  -- The key the leaf was written with, if the map has map_key_index set, or
+  -- NULL.
+  LeafKey               VARBINARY(255),
   PRIMARY KEY(TreeId, KeyHash, MapRevision),
   FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
 );
 
+-- Indexes the keys of the leaves of maps with map_key_index set, for
+-- ListLeavesByKeyPrefix.
+CREATE INDEX MapLeafKeyIdx
+  ON MapLeaf(TreeId, LeafKey, KeyHash);
+
+-- With --mysql_map_leaf_table_shards=N, map leaves are stored in tables
+-- MapLeaf_0 to MapLeaf_<N-1> instead, which must be created with the same
+-- columns, indexes and foreign key as MapLeaf, e.g.:
+--   CREATE TABLE IF NOT EXISTS MapLeaf_0 LIKE MapLeaf;
+--   ALTER TABLE MapLeaf_0 ADD FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE;
+
 
 CREATE TABLE IF NOT EXISTS MapHead(
   TreeId               BIGINT NOT NULL,
@@ -161,9 +243,48 @@ CREATE TABLE IF NOT EXISTS MapHead(
   MapRevision          BIGINT,
   RootSignature        VARBINARY(1024) NOT NULL,
   MapperData           MEDIUMBLOB,
+  -- The epoch of the signer which wrote the root, see storage.WithSignerEpoch.
+  SignerEpoch          BIGINT NOT NULL DEFAULT 0,
   PRIMARY KEY(TreeId, MapHeadTimestamp),
   FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
 );
 
 CREATE UNIQUE INDEX MapHeadRevisionIdx
   ON MapHead(TreeId, MapRevision);
+
+-- The map write batch which owns the next revision of a map, see
+-- storage.WithMapWriteBatch. The row is removed by the transaction which
+-- stores the root of the revision.
+CREATE TABLE IF NOT EXISTS MapWriteBatch(
+  TreeId               BIGINT NOT NULL,
+  MapRevision          BIGINT NOT NULL,
+  BatchId              VARBINARY(255) NOT NULL,
+  PRIMARY KEY(TreeId),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- Mutations of map leaves queued by QueueMapMutations, until the map sequencer
+-- applies them in a new revision of the map, in order of their queue time.
+CREATE TABLE IF NOT EXISTS MapMutation(
+  TreeId               BIGINT NOT NULL,
+  QueueTimestampNanos  BIGINT NOT NULL,
+  -- Random ID of the mutation, which orders mutations queued at the same time.
+  MutationId           BIGINT NOT NULL,
+  -- The MapLeaf set by the mutation, as a serialized proto.
+  Leaf                 LONGBLOB NOT NULL,
+  PRIMARY KEY(TreeId, QueueTimestampNanos, MutationId),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- Co-signatures of map roots by witnesses, see AddMapRootSignature.
+CREATE TABLE IF NOT EXISTS MapRootSignatures(
+  TreeId               BIGINT NOT NULL,
+  MapRevision          BIGINT NOT NULL,
+  -- The SHA-256 hash of PublicKey, which identifies the witness.
+  KeyHash              VARBINARY(255) NOT NULL,
+  Witness              VARCHAR(255) NOT NULL,
+  PublicKey            BLOB NOT NULL,
+  Signature            VARBINARY(1024) NOT NULL,
+  PRIMARY KEY(TreeId, MapRevision, KeyHash),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
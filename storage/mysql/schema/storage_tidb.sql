@@ -0,0 +1,275 @@
+# TiDB version of the tree schema, for use with --mysql_tidb.
+#
+# It differs from storage.sql in that tables have no foreign keys, which older
+# TiDB versions don't enforce, and in that the tables which leaves and nodes
+# are written to have nonclustered primary keys with sharded row IDs, so that
+# writes to a tree are spread across TiDB regions rather than all going to the
+# region which holds the end of its key range.
+
+-- ---------------------------------------------
+-- Tree stuff here
+-- ---------------------------------------------
+
+-- Tree parameters should not be changed after creation. Doing so can
+-- render the data in the tree unusable or inconsistent.
+CREATE TABLE IF NOT EXISTS Trees(
+  TreeId                BIGINT NOT NULL,
+  TreeState             ENUM('ACTIVE', 'FROZEN', 'DRAINING') NOT NULL,
+  TreeType              ENUM('LOG', 'MAP', 'PREORDERED_LOG') NOT NULL,
+  HashStrategy          ENUM('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256', 'CONIKS_SHA256', 'CUSTOM_MAP_HASHER') NOT NULL,
+  HashAlgorithm         ENUM('SHA256') NOT NULL,
+  SignatureAlgorithm    ENUM('ECDSA', 'RSA', 'ED25519') NOT NULL,
+  DisplayName           VARCHAR(20),
+  Description           VARCHAR(200),
+  CreateTimeMillis      BIGINT NOT NULL,
+  UpdateTimeMillis      BIGINT NOT NULL,
+  MaxRootDurationMillis BIGINT NOT NULL,
+  PrivateKey            MEDIUMBLOB NOT NULL,
+  PublicKey             MEDIUMBLOB NOT NULL,
+  Deleted               BOOLEAN,
+  DeleteTimeMillis      BIGINT,
+  -- Serialized TreeRateLimits proto, or NULL if the tree has no rate limits.
+  RateLimits            BLOB,
+  -- Labels of the tree as a JSON object, or NULL if the tree has no labels.
+  Labels                TEXT,
+  -- ID of the CreateTree request which created the tree, or NULL if none.
+  CreateRequestId       VARCHAR(128) UNIQUE,
+  -- Maintenance mode of the tree as a serialized TreeMaintenance, or NULL if
+  -- the tree is not in maintenance.
+  Maintenance           BLOB,
+  -- Map strata of the tree as a JSON array, or NULL if it uses the default.
+  MapStrata             TEXT,
+  -- MapCompression of the leaves and tiles of the tree, or NULL if none.
+  MapCompression        VARCHAR(32),
+  -- Name of the registered hasher of a CUSTOM_MAP_HASHER map, or NULL.
+  MapHasher             VARCHAR(255),
+  -- Whether the first write to the map initializes it.
+  AutoInit              BOOLEAN NOT NULL DEFAULT FALSE,
+  -- Maximum bytes of storage written for the tree, or 0 for no limit.
+  MaxStorageBytes       BIGINT NOT NULL DEFAULT 0,
+  -- Whether the keys of the leaves of the map are indexed.
+  MapKeyIndex           BOOLEAN NOT NULL DEFAULT FALSE,
+  -- Public keys the tree's key was rotated away from, as a serialized Tree
+  -- with only retired_public_keys set, or NULL if it was never rotated.
+  RetiredPublicKeys     BLOB,
+  PRIMARY KEY(TreeId)
+);
+
+-- This table contains tree parameters that can be changed at runtime such as for
+-- administrative purposes.
+CREATE TABLE IF NOT EXISTS TreeControl(
+  TreeId                  BIGINT NOT NULL,
+  SigningEnabled          BOOLEAN NOT NULL,
+  SequencingEnabled       BOOLEAN NOT NULL,
+  SequenceIntervalSeconds INTEGER NOT NULL,
+  PRIMARY KEY(TreeId)
+);
+
+CREATE TABLE IF NOT EXISTS Subtree(
+  TreeId               BIGINT NOT NULL,
+  SubtreeId            VARBINARY(255) NOT NULL,
+  Nodes                MEDIUMBLOB NOT NULL,
+  SubtreeRevision      INTEGER NOT NULL,
+  -- Key columns must be in ASC order in order to benefit from group-by/min-max
+  -- optimization in MySQL.
+  PRIMARY KEY(TreeId, SubtreeId, SubtreeRevision) NONCLUSTERED
+) SHARD_ROW_ID_BITS=4 PRE_SPLIT_REGIONS=4;
+
+-- The TreeRevisionIdx is used to enforce that there is only one STH at any
+-- tree revision
+CREATE TABLE IF NOT EXISTS TreeHead(
+  TreeId               BIGINT NOT NULL,
+  TreeHeadTimestamp    BIGINT,
+  TreeSize             BIGINT,
+  RootHash             VARBINARY(255) NOT NULL,
+  RootSignature        VARBINARY(1024) NOT NULL,
+  TreeRevision         BIGINT,
+  -- The epoch of the signer which wrote the root, see storage.WithSignerEpoch.
+  SignerEpoch          BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY(TreeId, TreeHeadTimestamp)
+);
+
+CREATE UNIQUE INDEX TreeHeadRevisionIdx
+  ON TreeHead(TreeId, TreeRevision);
+
+-- ---------------------------------------------
+-- Log specific stuff here
+-- ---------------------------------------------
+
+-- Creating index at same time as table allows some storage engines to better
+-- optimize physical storage layout. Most engines allow multiple nulls in a
+-- unique index but some may not.
+
+-- A leaf that has not been sequenced has a row in this table. If duplicate leaves
+-- are allowed they will all reference this row.
+CREATE TABLE IF NOT EXISTS LeafData(
+  TreeId               BIGINT NOT NULL,
+  -- This is a personality specific has of some subset of the leaf data.
+  -- It's only purpose is to allow Trillian to identify duplicate entries in
+  -- the context of the personality.
+  LeafIdentityHash     VARBINARY(255) NOT NULL,
+  -- This is the data stored in the leaf for example in CT it contains a DER encoded
+  -- X.509 certificate but is application dependent
+  LeafValue            LONGBLOB NOT NULL,
+  -- This is extra data that the application can associate with the leaf should it wish to.
+  -- This data is not included in signing and hashing.
+  ExtraData            LONGBLOB,
+  -- The timestamp from when this leaf data was first queued for inclusion.
+  QueueTimestampNanos  BIGINT NOT NULL,
+  PRIMARY KEY(TreeId, LeafIdentityHash) NONCLUSTERED
+) SHARD_ROW_ID_BITS=4 PRE_SPLIT_REGIONS=4;
+
+-- When a leaf is sequenced a row is added to this table. If logs allow duplicates then
+-- multiple rows will exist with different sequence numbers. The signed timestamp
+-- will be communicated via the unsequenced table as this might need to be unique, depending
+-- on the log parameters and we can't insert into this table until we have the sequence number
+-- which is not available at the time we queue the entry. We need both hashes because the
+-- LeafData table is keyed by the raw data hash.
+CREATE TABLE IF NOT EXISTS SequencedLeafData(
+  TreeId               BIGINT NOT NULL,
+  SequenceNumber       BIGINT UNSIGNED NOT NULL,
+  -- This is a personality specific has of some subset of the leaf data.
+  -- It's only purpose is to allow Trillian to identify duplicate entries in
+  -- the context of the personality.
+  LeafIdentityHash     VARBINARY(255) NOT NULL,
+  -- This is a MerkleLeafHash as defined by the treehasher that the log uses. For example for
+  -- CT this hash will include the leaf prefix byte as well as the leaf data.
+  MerkleLeafHash       VARBINARY(255) NOT NULL,
+  IntegrateTimestampNanos BIGINT NOT NULL,
+  PRIMARY KEY(TreeId, SequenceNumber) NONCLUSTERED
+) SHARD_ROW_ID_BITS=4 PRE_SPLIT_REGIONS=4;
+
+
+CREATE INDEX SequencedLeafMerkleIdx
+  ON SequencedLeafData(TreeId, MerkleLeafHash);
+
+CREATE TABLE IF NOT EXISTS Unsequenced(
+  TreeId               BIGINT NOT NULL,
+  -- The bucket field is to allow the use of time based ring bucketed schemes if desired. If
+  -- unused this should be set to zero for all entries.
+  Bucket               INTEGER NOT NULL,
+  -- This is a personality specific hash of some subset of the leaf data.
+  -- It's only purpose is to allow Trillian to identify duplicate entries in
+  -- the context of the personality.
+  LeafIdentityHash     VARBINARY(255) NOT NULL,
+  -- This is a MerkleLeafHash as defined by the treehasher that the log uses. For example for
+  -- CT this hash will include the leaf prefix byte as well as the leaf data.
+  MerkleLeafHash       VARBINARY(255) NOT NULL,
+  QueueTimestampNanos  BIGINT NOT NULL,
+  -- This is a SHA256 hash of the TreeID, LeafIdentityHash and QueueTimestampNanos. It is used
+  -- for batched deletes from the table when trillian_log_server and trillian_log_signer are
+  -- built with the batched_queue tag.
+  QueueID VARBINARY(32) DEFAULT NULL UNIQUE,
+  PRIMARY KEY (TreeId, Bucket, QueueTimestampNanos, LeafIdentityHash)
+);
+
+-- Queued leaves which repeatedly caused sequencing to fail are moved here from
+-- the Unsequenced table, so that they no longer block the rest of the queue.
+-- The leaf data itself stays in LeafData until the entry is purged.
+CREATE TABLE IF NOT EXISTS DeadLetter(
+  TreeId                   BIGINT NOT NULL,
+  LeafIdentityHash         VARBINARY(255) NOT NULL,
+  MerkleLeafHash           VARBINARY(255) NOT NULL,
+  QueueTimestampNanos      BIGINT NOT NULL,
+  QuarantineTimestampNanos BIGINT NOT NULL,
+  Reason                   VARCHAR(1024),
+  PRIMARY KEY (TreeId, LeafIdentityHash)
+);
+
+-- Statistics of logs, maintained by the write paths if the log storage is
+-- configured with TreeStatsShards. The statistics of a log are spread across
+-- several shard rows, which are summed up when read, so that concurrent
+-- writers don't contend for a single row.
+CREATE TABLE IF NOT EXISTS TreeStats(
+  TreeId               BIGINT NOT NULL,
+  Shard                INTEGER NOT NULL,
+  LeafCount            BIGINT NOT NULL DEFAULT 0,
+  LeafValueBytes       BIGINT NOT NULL DEFAULT 0,
+  ExtraDataBytes       BIGINT NOT NULL DEFAULT 0,
+  UnsequencedCount     BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY(TreeId, Shard)
+);
+
+-- Bytes of leaves and subtrees written for trees, maintained by the write
+-- paths if the storage is configured with TreeUsageShards. The usage of a tree
+-- is spread across several shard rows, as the statistics in TreeStats are.
+CREATE TABLE IF NOT EXISTS TreeUsage(
+  TreeId               BIGINT NOT NULL,
+  Shard                INTEGER NOT NULL,
+  LeafBytes            BIGINT NOT NULL DEFAULT 0,
+  SubtreeBytes         BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY(TreeId, Shard)
+);
+
+
+-- ---------------------------------------------
+-- Map specific stuff here
+-- ---------------------------------------------
+
+CREATE TABLE IF NOT EXISTS MapLeaf(
+  TreeId                BIGINT NOT NULL,
+  KeyHash               VARBINARY(255) NOT NULL,
+  -- MapRevision is stored negated to invert ordering in the primary key index
+  -- st. more recent revisions come first.
+  MapRevision           BIGINT NOT NULL,
+  LeafValue             LONGBLOB NOT NULL,
+  -- The key the leaf was written with, if the map has map_key_index set, or
+  -- NULL.
+  LeafKey               VARBINARY(255),
+  PRIMARY KEY(TreeId, KeyHash, MapRevision) NONCLUSTERED
+) SHARD_ROW_ID_BITS=4 PRE_SPLIT_REGIONS=4;
+
+-- Indexes the keys of the leaves of maps with map_key_index set, for
+-- ListLeavesByKeyPrefix.
+CREATE INDEX MapLeafKeyIdx
+  ON MapLeaf(TreeId, LeafKey, KeyHash);
+
+
+CREATE TABLE IF NOT EXISTS MapHead(
+  TreeId               BIGINT NOT NULL,
+  MapHeadTimestamp     BIGINT,
+  RootHash             VARBINARY(255) NOT NULL,
+  MapRevision          BIGINT,
+  RootSignature        VARBINARY(1024) NOT NULL,
+  MapperData           MEDIUMBLOB,
+  -- The epoch of the signer which wrote the root, see storage.WithSignerEpoch.
+  SignerEpoch          BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY(TreeId, MapHeadTimestamp)
+);
+
+CREATE UNIQUE INDEX MapHeadRevisionIdx
+  ON MapHead(TreeId, MapRevision);
+
+-- The map write batch which owns the next revision of a map, see
+-- storage.WithMapWriteBatch. The row is removed by the transaction which
+-- stores the root of the revision.
+CREATE TABLE IF NOT EXISTS MapWriteBatch(
+  TreeId               BIGINT NOT NULL,
+  MapRevision          BIGINT NOT NULL,
+  BatchId              VARBINARY(255) NOT NULL,
+  PRIMARY KEY(TreeId)
+);
+
+-- Mutations of map leaves queued by QueueMapMutations, until the map sequencer
+-- applies them in a new revision of the map, in order of their queue time.
+CREATE TABLE IF NOT EXISTS MapMutation(
+  TreeId               BIGINT NOT NULL,
+  QueueTimestampNanos  BIGINT NOT NULL,
+  -- Random ID of the mutation, which orders mutations queued at the same time.
+  MutationId           BIGINT NOT NULL,
+  -- The MapLeaf set by the mutation, as a serialized proto.
+  Leaf                 LONGBLOB NOT NULL,
+  PRIMARY KEY(TreeId, QueueTimestampNanos, MutationId)
+);
+
+-- Co-signatures of map roots by witnesses, see AddMapRootSignature.
+CREATE TABLE IF NOT EXISTS MapRootSignatures(
+  TreeId               BIGINT NOT NULL,
+  MapRevision          BIGINT NOT NULL,
+  -- The SHA-256 hash of PublicKey, which identifies the witness.
+  KeyHash              VARBINARY(255) NOT NULL,
+  Witness              VARCHAR(255) NOT NULL,
+  PublicKey            BLOB NOT NULL,
+  Signature            VARBINARY(1024) NOT NULL,
+  PRIMARY KEY(TreeId, MapRevision, KeyHash)
+);
@@ -153,6 +153,53 @@ func TestLogNodeRoundTripMultiSubtree(t *testing.T) {
 	}
 }
 
+func TestReplicaSnapshots(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+
+	// The replica is the primary itself, so that the snapshots read what was
+	// just written, but the transactions are run by separate storages.
+	ls := NewLogStorageWithOpts(DB, nil, LogStorageOptions{ReplicaDB: DB}).(*mySQLLogStorage)
+	logTree := mustCreateTree(ctx, t, as, storageto.LogTree)
+	mustSignAndStoreLogRoot(ctx, t, ls, logTree, 0)
+	logTX, err := ls.SnapshotForTree(ctx, logTree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(log): %v", err)
+	}
+	defer logTX.Close()
+	if got, want := logTX.(*logTreeTX).ts, ls.replica.mySQLTreeStorage; got != want {
+		t.Error("SnapshotForTree(log) didn't run on the replica")
+	}
+	if err := ls.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if tx.(*logTreeTX).ts != ls.mySQLTreeStorage {
+			t.Error("ReadWriteTransaction(log) didn't run on the primary")
+		}
+		return nil
+	}); err != nil {
+		t.Errorf("ReadWriteTransaction(log): %v", err)
+	}
+
+	ms := NewMapStorageWithOpts(DB, MapStorageOptions{ReplicaDB: DB}).(*mySQLMapStorage)
+	mTree := createInitializedMapForTests(ctx, t, ms, as)
+	for _, snapshot := range []func() (storage.ReadOnlyMapTreeTX, error){
+		func() (storage.ReadOnlyMapTreeTX, error) { return ms.SnapshotForTree(ctx, mTree) },
+		func() (storage.ReadOnlyMapTreeTX, error) { return ms.SnapshotAtRevision(ctx, mTree, 0) },
+	} {
+		tx, err := snapshot()
+		if err != nil {
+			t.Fatalf("Map snapshot: %v", err)
+		}
+		if _, err := tx.LatestSignedMapRoot(ctx); err != nil {
+			t.Errorf("LatestSignedMapRoot(): %v", err)
+		}
+		if got, want := tx.(*mapTreeTX).ts, ms.replica.mySQLTreeStorage; got != want {
+			t.Error("Map snapshot didn't run on the replica")
+		}
+		tx.Close()
+	}
+}
+
 func forceWriteRevision(rev int64, tx storage.TreeTX) {
 	mtx, ok := tx.(*logTreeTX)
 	if !ok {
@@ -0,0 +1,102 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// selectSupersededSubtreesSQL selects the revisions of subtrees which are
+	// older than the latest revision of the same subtree at or below a horizon.
+	selectSupersededSubtreesSQL = `SELECT s.SubtreeId, s.SubtreeRevision
+		FROM Subtree s
+		INNER JOIN (
+			SELECT SubtreeId, MAX(SubtreeRevision) AS Horizon
+			FROM Subtree
+			WHERE TreeId=? AND SubtreeRevision<=?
+			GROUP BY SubtreeId
+		) h ON s.SubtreeId=h.SubtreeId
+		WHERE s.TreeId=? AND s.SubtreeRevision<h.Horizon
+		LIMIT ?`
+	deleteSubtreeRevisionSQL = "DELETE FROM Subtree WHERE TreeId=? AND SubtreeId=? AND SubtreeRevision=?"
+)
+
+var _ storage.SubtreeVacuumTX = &logTreeTX{}
+
+type subtreeRevision struct {
+	id  []byte
+	rev int64
+}
+
+// VacuumSubtrees deletes superseded revisions of the log's subtrees. Those of
+// maps are deleted along with their old revisions, see DeleteRevisionsBefore.
+func (t *logTreeTX) VacuumSubtrees(ctx context.Context, horizon int64, limit int) (int, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	if t.treeType != trillian.TreeType_LOG && t.treeType != trillian.TreeType_PREORDERED_LOG {
+		return 0, status.Errorf(codes.FailedPrecondition, "only subtrees of logs can be vacuumed, got %v", t.treeType)
+	}
+
+	// MySQL doesn't allow LIMIT in a DELETE joining Subtree with itself, so
+	// select the revisions first, and delete them one by one.
+	revs, err := t.selectSupersededSubtrees(ctx, horizon, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, r := range revs {
+		res, err := t.tx.ExecContext(ctx, deleteSubtreeRevisionSQL, t.treeID, r.id, r.rev)
+		if err != nil {
+			return 0, mysqlToGRPC(err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, mysqlToGRPC(err)
+		}
+		deleted += int(n)
+	}
+	return deleted, nil
+}
+
+// selectSupersededSubtrees returns up to limit revisions of subtrees which are
+// superseded by a later revision no later than horizon.
+func (t *logTreeTX) selectSupersededSubtrees(ctx context.Context, horizon int64, limit int) ([]subtreeRevision, error) {
+	rows, err := t.tx.QueryContext(ctx, selectSupersededSubtreesSQL, t.treeID, horizon, t.treeID, limit)
+	if err != nil {
+		glog.Warningf("Failed to select superseded subtrees: %s", err)
+		return nil, mysqlToGRPC(err)
+	}
+	defer rows.Close()
+
+	var revs []subtreeRevision
+	for rows.Next() {
+		var r subtreeRevision
+		if err := rows.Scan(&r.id, &r.rev); err != nil {
+			glog.Warningf("Error scanning Subtree rows: %s", err)
+			return nil, err
+		}
+		revs = append(revs, r)
+	}
+	return revs, rows.Err()
+}
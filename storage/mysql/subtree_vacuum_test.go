@@ -0,0 +1,80 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+)
+
+func TestVacuumSubtrees(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	s := NewLogStorage(DB, nil)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
+
+	for _, row := range []struct {
+		id  string
+		rev int64
+	}{
+		{"a", 1}, {"a", 2}, {"a", 3}, {"a", 5},
+		{"b", 1},
+		{"c", 4}, {"c", 5},
+	} {
+		if _, err := DB.ExecContext(ctx, "INSERT INTO Subtree(TreeId, SubtreeId, Nodes, SubtreeRevision) VALUES(?, ?, ?, ?)", tree.TreeId, []byte(row.id), []byte("nodes"), row.rev); err != nil {
+			t.Fatalf("Failed to insert subtree: %v", err)
+		}
+	}
+
+	// At horizon 3, a@1 and a@2 are superseded by a@3, and the others are
+	// either the latest revision at or below the horizon, or above it.
+	for _, want := range []int{1, 1, 0} {
+		runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+			n, err := tx.(storage.SubtreeVacuumTX).VacuumSubtrees(ctx, 3, 1)
+			if err != nil {
+				t.Fatalf("VacuumSubtrees(): %v", err)
+			}
+			if n != want {
+				t.Errorf("VacuumSubtrees()=%d, want %d", n, want)
+			}
+			return nil
+		})
+	}
+
+	rows, err := DB.QueryContext(ctx, "SELECT SubtreeId, SubtreeRevision FROM Subtree WHERE TreeId=? ORDER BY SubtreeId, SubtreeRevision", tree.TreeId)
+	if err != nil {
+		t.Fatalf("Failed to select subtrees: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var id []byte
+		var rev int64
+		if err := rows.Scan(&id, &rev); err != nil {
+			t.Fatalf("Failed to scan subtree: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%s@%d", id, rev))
+	}
+	if want := []string{"a@3", "a@5", "b@1", "c@4", "c@5"}; !cmp.Equal(got, want) {
+		t.Errorf("Subtree revisions after vacuum: %v, want %v", got, want)
+	}
+}
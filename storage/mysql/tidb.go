@@ -0,0 +1,44 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"flag"
+	"hash/fnv"
+)
+
+const (
+	// ErrWriteConflict: Returned by TiDB when an optimistic transaction
+	// conflicts with another one at commit time.
+	errNumTiDBWriteConflict = 9007
+	// ErrTxnRetryable: Returned by TiDB for transient storage errors.
+	errNumTiDBTxnRetryable = 8022
+)
+
+var (
+	tidb             = flag.Bool("mysql_tidb", false, "If true, --mysql_uri is a TiDB database with the schema in storage_tidb.sql. Queued leaves are then spread across --mysql_tidb_queue_buckets buckets, read-write transactions which conflict are retried up to --mysql_cluster_retries times, and trees are hard-deleted without relying on foreign keys")
+	tidbQueueBuckets = flag.Int("mysql_tidb_queue_buckets", 16, "Number of buckets of the Unsequenced table which queued leaves are spread across with --mysql_tidb, which spreads queueing and dequeuing across TiDB regions")
+)
+
+// queueBucket returns the bucket of the Unsequenced table which a leaf with
+// the given identity hash is queued in, out of the given number of buckets.
+func queueBucket(leafIdentityHash []byte, buckets int) int64 {
+	if buckets < 2 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write(leafIdentityHash)
+	return int64(h.Sum32() % uint32(buckets))
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+func TestQueueBucket(t *testing.T) {
+	for _, buckets := range []int{0, 1} {
+		if got := queueBucket([]byte("leaf"), buckets); got != 0 {
+			t.Errorf("queueBucket(%d)=%d, want 0", buckets, got)
+		}
+	}
+
+	const buckets = 16
+	seen := make(map[int64]bool)
+	for i := 0; i < 1000; i++ {
+		hash := sha256.Sum256([]byte(fmt.Sprint(i)))
+		bucket := queueBucket(hash[:], buckets)
+		if bucket < 0 || bucket >= buckets {
+			t.Fatalf("queueBucket(%x, %d)=%d, out of range", hash, buckets, bucket)
+		}
+		if again := queueBucket(hash[:], buckets); again != bucket {
+			t.Fatalf("queueBucket(%x, %d) returned %d then %d", hash, buckets, bucket, again)
+		}
+		seen[bucket] = true
+	}
+	if got, want := len(seen), buckets; got != want {
+		t.Errorf("leaves were queued in %d buckets, want %d", got, want)
+	}
+}
@@ -0,0 +1,415 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+)
+
+// treeIDPlaceholder is replaced by the ID of a tree in tree database names.
+const treeIDPlaceholder = "{tree_id}"
+
+// TreeDatabaseFunc returns the name of the database which stores the data of
+// a tree, or "" if it's stored in the main database.
+type TreeDatabaseFunc func(treeID int64) string
+
+// TreeDatabaseNames returns a TreeDatabaseFunc which places the trees in
+// overrides in the databases they map to, and other trees in the database
+// named by template, in which "{tree_id}" is replaced by the ID of the tree.
+// Several trees may share a database. An empty template places other trees in
+// the main database.
+func TreeDatabaseNames(template string, overrides map[int64]string) TreeDatabaseFunc {
+	return func(treeID int64) string {
+		if name, ok := overrides[treeID]; ok {
+			return name
+		}
+		return strings.Replace(template, treeIDPlaceholder, strconv.FormatInt(treeID, 10), -1)
+	}
+}
+
+// ParseTreeDatabases parses a comma-separated list of treeID=database pairs.
+func ParseTreeDatabases(s string) (map[int64]string, error) {
+	dbs := make(map[int64]string)
+	if s == "" {
+		return dbs, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid tree database %q, want treeID=database", pair)
+		}
+		treeID, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || treeID <= 0 {
+			return nil, fmt.Errorf("invalid tree ID in %q", pair)
+		}
+		dbs[treeID] = parts[1]
+	}
+	return dbs, nil
+}
+
+// TreeDatabases places the data of trees in databases of their own, on the
+// same MySQL server as the main database, which stores the metadata of all
+// trees. This allows the trees of a busy tenant to be moved to another server,
+// or dropped, independently of the others.
+//
+// Tree databases must already exist, and have the same schema as the main
+// database. The Trees and TreeControl rows of a tree are copied into its
+// database before it's first used, as the other tables refer to them. Hard
+// deleting a tree doesn't delete the data in its tree database, and the MySQL
+// quota manager only counts the unsequenced leaves in the main database.
+type TreeDatabases struct {
+	main   *sql.DB
+	nameFn TreeDatabaseFunc
+	open   func(name string) (*sql.DB, error)
+	mf     monitoring.MetricFactory
+	opts   LogStorageOptions
+	mOpts  MapStorageOptions
+
+	mu       sync.Mutex
+	mainName string
+	dbs      map[string]*treeDatabase
+}
+
+// treeDatabase is an open tree database.
+type treeDatabase struct {
+	db   *sql.DB
+	logs storage.LogStorage
+	maps storage.MapStorage
+
+	mu sync.Mutex
+	// copied holds the IDs of the trees whose metadata has been copied into
+	// the database.
+	copied map[int64]bool
+}
+
+// NewTreeDatabases returns a TreeDatabases which places trees in the
+// databases named by nameFn, which are opened with open. Log and map storages
+// are created with opts and mOpts.
+func NewTreeDatabases(main *sql.DB, nameFn TreeDatabaseFunc, open func(name string) (*sql.DB, error), mf monitoring.MetricFactory, opts LogStorageOptions, mOpts MapStorageOptions) *TreeDatabases {
+	return &TreeDatabases{
+		main:   main,
+		nameFn: nameFn,
+		open:   open,
+		mf:     mf,
+		opts:   opts,
+		mOpts:  mOpts,
+		dbs:    make(map[string]*treeDatabase),
+	}
+}
+
+// OpenTreeDatabase returns a function which opens databases on the server of
+// the given connection URI, for use with NewTreeDatabases. Connections are set
+// up as configured by the TLS, authentication and dialer flags, and statements
+// are annotated with comments if comments is true.
+func OpenTreeDatabase(uri string, comments bool, config storage.SQLPoolConfig) func(name string) (*sql.DB, error) {
+	return func(name string) (*sql.DB, error) {
+		cfg, err := mysql.ParseDSN(uri)
+		if err != nil {
+			return nil, err
+		}
+		cfg.DBName = name
+		c, err := newConnector(cfg.FormatDSN(), comments)
+		if err != nil {
+			return nil, err
+		}
+		db, err := openDB(c)
+		if err != nil {
+			return nil, err
+		}
+		config.Apply(db)
+		return db, nil
+	}
+}
+
+// LogStorage returns a LogStorage which stores the data of every log in its
+// tree database, and reads the IDs of active logs from the main database.
+func (d *TreeDatabases) LogStorage() storage.LogStorage {
+	return &treeDatabaseLogStorage{LogStorage: NewLogStorageWithOpts(d.main, d.mf, d.opts), d: d}
+}
+
+// MapStorage returns a MapStorage which stores the data of every map in its
+// tree database.
+func (d *TreeDatabases) MapStorage() storage.MapStorage {
+	return &treeDatabaseMapStorage{MapStorage: NewMapStorageWithOpts(d.main, d.mOpts), d: d}
+}
+
+// Close closes the tree databases which have been opened.
+func (d *TreeDatabases) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var firstErr error
+	for name, tdb := range d.dbs {
+		if err := tdb.db.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close tree database %q: %v", name, err)
+		}
+	}
+	d.dbs = make(map[string]*treeDatabase)
+	return firstErr
+}
+
+// database returns the tree database of a tree, or nil if its data is stored
+// in the main database.
+func (d *TreeDatabases) database(ctx context.Context, treeID int64) (*treeDatabase, error) {
+	name := d.nameFn(treeID)
+	if name == "" {
+		return nil, nil
+	}
+
+	d.mu.Lock()
+	if d.mainName == "" {
+		if err := d.main.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&d.mainName); err != nil {
+			d.mu.Unlock()
+			return nil, fmt.Errorf("failed to get the name of the main database: %v", err)
+		}
+	}
+	if name == d.mainName {
+		d.mu.Unlock()
+		return nil, nil
+	}
+	tdb, ok := d.dbs[name]
+	if !ok {
+		db, err := d.open(name)
+		if err != nil {
+			d.mu.Unlock()
+			return nil, fmt.Errorf("failed to open tree database %q: %v", name, err)
+		}
+		tdb = &treeDatabase{
+			db:     db,
+			logs:   NewLogStorageWithOpts(db, d.mf, d.opts),
+			maps:   NewMapStorageWithOpts(db, d.mOpts),
+			copied: make(map[int64]bool),
+		}
+		d.dbs[name] = tdb
+	}
+	mainName := d.mainName
+	d.mu.Unlock()
+
+	if err := tdb.copyTree(ctx, mainName, treeID); err != nil {
+		return nil, fmt.Errorf("failed to copy tree %d into tree database %q: %v", treeID, name, err)
+	}
+	return tdb, nil
+}
+
+// copyTree copies the Trees and TreeControl rows of a tree from the main
+// database into the tree database, if they aren't there yet.
+func (t *treeDatabase) copyTree(ctx context.Context, mainName string, treeID int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.copied[treeID] {
+		return nil
+	}
+	for _, table := range []string{"Trees", "TreeControl"} {
+		query := fmt.Sprintf("INSERT IGNORE INTO %s SELECT * FROM %s.%s WHERE TreeId = ?", table, quoteIdentifier(mainName), table)
+		if _, err := t.db.ExecContext(ctx, query, treeID); err != nil {
+			return err
+		}
+	}
+	t.copied[treeID] = true
+	return nil
+}
+
+// quoteIdentifier quotes a MySQL identifier.
+func quoteIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// treeDatabaseLogStorage is a LogStorage which stores the data of logs in
+// their tree databases.
+type treeDatabaseLogStorage struct {
+	storage.LogStorage
+	d *TreeDatabases
+}
+
+func (s *treeDatabaseLogStorage) backend(ctx context.Context, tree *trillian.Tree) (storage.LogStorage, error) {
+	tdb, err := s.d.database(ctx, tree.TreeId)
+	if err != nil || tdb == nil {
+		return s.LogStorage, err
+	}
+	return tdb.logs, nil
+}
+
+func (s *treeDatabaseLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	ls, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ls.SnapshotForTree(ctx, tree)
+}
+
+func (s *treeDatabaseLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	ls, err := s.backend(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return ls.ReadWriteTransaction(ctx, tree, f)
+}
+
+func (s *treeDatabaseLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	ls, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ls.QueueLeaves(ctx, tree, leaves, queueTimestamp)
+}
+
+func (s *treeDatabaseLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	ls, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ls.AddSequencedLeaves(ctx, tree, leaves, timestamp)
+}
+
+// treeDatabaseMapStorage is a MapStorage which stores the data of maps in
+// their tree databases.
+type treeDatabaseMapStorage struct {
+	storage.MapStorage
+	d *TreeDatabases
+}
+
+func (s *treeDatabaseMapStorage) backend(ctx context.Context, tree *trillian.Tree) (storage.MapStorage, error) {
+	tdb, err := s.d.database(ctx, tree.TreeId)
+	if err != nil || tdb == nil {
+		return s.MapStorage, err
+	}
+	return tdb.maps, nil
+}
+
+func (s *treeDatabaseMapStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyMapTreeTX, error) {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ms.SnapshotForTree(ctx, tree)
+}
+
+func (s *treeDatabaseMapStorage) SnapshotAtRevision(ctx context.Context, tree *trillian.Tree, revision int64) (storage.ReadOnlyMapTreeTX, error) {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ms.SnapshotAtRevision(ctx, tree, revision)
+}
+
+func (s *treeDatabaseMapStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return ms.ReadWriteTransaction(ctx, tree, f)
+}
+
+// bulkWriter returns the storage.MapBulkWriter which writes the given map.
+func (s *treeDatabaseMapStorage) bulkWriter(ctx context.Context, tree *trillian.Tree) (storage.MapBulkWriter, error) {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ms.(storage.MapBulkWriter), nil
+}
+
+func (s *treeDatabaseMapStorage) BeginBulkWrite(ctx context.Context, tree *trillian.Tree) error {
+	w, err := s.bulkWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.BeginBulkWrite(ctx, tree)
+}
+
+func (s *treeDatabaseMapStorage) BulkWriteLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf) error {
+	w, err := s.bulkWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.BulkWriteLeaves(ctx, tree, leaves)
+}
+
+func (s *treeDatabaseMapStorage) BulkWriteTiles(ctx context.Context, tree *trillian.Tree, tiles []smt.Tile) error {
+	w, err := s.bulkWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.BulkWriteTiles(ctx, tree, tiles)
+}
+
+func (s *treeDatabaseMapStorage) CommitBulkWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	w, err := s.bulkWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.CommitBulkWrite(ctx, tree, root)
+}
+
+// snapshotWriter returns the storage.MapSnapshotWriter which writes the given
+// map.
+func (s *treeDatabaseMapStorage) snapshotWriter(ctx context.Context, tree *trillian.Tree) (storage.MapSnapshotWriter, error) {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ms.(storage.MapSnapshotWriter), nil
+}
+
+func (s *treeDatabaseMapStorage) BeginSnapshotWrite(ctx context.Context, tree *trillian.Tree, revision int64) error {
+	w, err := s.snapshotWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.BeginSnapshotWrite(ctx, tree, revision)
+}
+
+func (s *treeDatabaseMapStorage) SnapshotWriteLeaves(ctx context.Context, tree *trillian.Tree, revision int64, leaves []*trillian.MapLeaf) error {
+	w, err := s.snapshotWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.SnapshotWriteLeaves(ctx, tree, revision, leaves)
+}
+
+func (s *treeDatabaseMapStorage) SnapshotWriteTiles(ctx context.Context, tree *trillian.Tree, revision int64, tiles []smt.Tile) error {
+	w, err := s.snapshotWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.SnapshotWriteTiles(ctx, tree, revision, tiles)
+}
+
+func (s *treeDatabaseMapStorage) CommitSnapshotWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	w, err := s.snapshotWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.CommitSnapshotWrite(ctx, tree, root)
+}
+
+func (s *treeDatabaseMapStorage) QueueMapMutations(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf, queueTimestamp time.Time) error {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return ms.(storage.MapMutationQueue).QueueMapMutations(ctx, tree, leaves, queueTimestamp)
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"crypto"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testdb"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+
+	tcrypto "github.com/google/trillian/crypto"
+	ttestonly "github.com/google/trillian/testonly"
+)
+
+func TestTreeDatabaseNames(t *testing.T) {
+	names := TreeDatabaseNames("trillian_{tree_id}", map[int64]string{7: "hot"})
+	for _, test := range []struct {
+		treeID int64
+		want   string
+	}{
+		{treeID: 7, want: "hot"},
+		{treeID: 8, want: "trillian_8"},
+	} {
+		if got := names(test.treeID); got != test.want {
+			t.Errorf("TreeDatabaseNames()(%d)=%q, want %q", test.treeID, got, test.want)
+		}
+	}
+	if got := TreeDatabaseNames("", nil)(8); got != "" {
+		t.Errorf("TreeDatabaseNames() with no template=%q, want the main database", got)
+	}
+}
+
+func TestParseTreeDatabases(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    map[int64]string
+		wantErr bool
+	}{
+		{in: "", want: map[int64]string{}},
+		{in: "1=a", want: map[int64]string{1: "a"}},
+		{in: "1=a,2=a,3=b", want: map[int64]string{1: "a", 2: "a", 3: "b"}},
+		{in: "1", wantErr: true},
+		{in: "1=", wantErr: true},
+		{in: "x=a", wantErr: true},
+		{in: "-1=a", wantErr: true},
+	} {
+		got, err := ParseTreeDatabases(test.in)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("ParseTreeDatabases(%q)=%v, want err=%v", test.in, err, test.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(got, test.want); !test.wantErr && diff != "" {
+			t.Errorf("ParseTreeDatabases(%q) diff (-got +want):\n%s", test.in, diff)
+		}
+	}
+}
+
+func TestTreeDatabases(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	tree := mustCreateTree(ctx, t, NewAdminStorage(DB), testonly.LogTree)
+
+	treeDB, done, err := testdb.NewTrillianDB(ctx)
+	if err != nil {
+		t.Fatalf("NewTrillianDB(): %v", err)
+	}
+	defer done(ctx)
+	var treeDBName string
+	if err := treeDB.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&treeDBName); err != nil {
+		t.Fatalf("SELECT DATABASE(): %v", err)
+	}
+
+	opened := 0
+	open := func(name string) (*sql.DB, error) {
+		if name != treeDBName {
+			t.Fatalf("opened database %q, want %q", name, treeDBName)
+		}
+		opened++
+		return treeDB, nil
+	}
+	names := TreeDatabaseNames("", map[int64]string{tree.TreeId: treeDBName})
+	s := NewTreeDatabases(DB, names, open, nil, LogStorageOptions{}, MapStorageOptions{}).LogStorage()
+
+	signer := tcrypto.NewSigner(tree.TreeId, ttestonly.NewSignerWithFixedSig(nil, []byte("notempty")), crypto.SHA256)
+	root, err := signer.SignLogRoot(&types.LogRootV1{TimestampNanos: 98765, RootHash: []byte(dummyHash)})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			if i > 0 {
+				return nil
+			}
+			return tx.StoreSignedLogRoot(ctx, root)
+		}); err != nil {
+			t.Fatalf("ReadWriteTransaction(): %v", err)
+		}
+	}
+	if got, want := opened, 1; got != want {
+		t.Errorf("opened the tree database %d times, want %d", got, want)
+	}
+
+	// The root must only be in the tree database, which also has a copy of
+	// the tree's metadata.
+	for _, test := range []struct {
+		desc  string
+		db    *sql.DB
+		query string
+		want  int
+	}{
+		{desc: "main-heads", db: DB, query: "SELECT COUNT(*) FROM TreeHead WHERE TreeId = ?", want: 0},
+		{desc: "tree-heads", db: treeDB, query: "SELECT COUNT(*) FROM TreeHead WHERE TreeId = ?", want: 1},
+		{desc: "tree-trees", db: treeDB, query: "SELECT COUNT(*) FROM Trees WHERE TreeId = ?", want: 1},
+		{desc: "tree-control", db: treeDB, query: "SELECT COUNT(*) FROM TreeControl WHERE TreeId = ?", want: 1},
+	} {
+		var got int
+		if err := test.db.QueryRowContext(ctx, test.query, tree.TreeId).Scan(&got); err != nil {
+			t.Fatalf("%s: %v", test.desc, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %d rows, want %d", test.desc, got, test.want)
+		}
+	}
+
+	tx, err := s.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	if _, err := tx.LatestSignedLogRoot(ctx); err != nil {
+		t.Errorf("LatestSignedLogRoot(): %v", err)
+	}
+}
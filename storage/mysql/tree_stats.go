@@ -0,0 +1,114 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+)
+
+const (
+	// upsertTreeStatsSQL adds deltas to a shard of the statistics of a tree.
+	upsertTreeStatsSQL = `INSERT INTO TreeStats(TreeId,Shard,LeafCount,LeafValueBytes,ExtraDataBytes,UnsequencedCount)
+			VALUES(?,?,?,?,?,?)
+			ON DUPLICATE KEY UPDATE
+			LeafCount=LeafCount+VALUES(LeafCount),
+			LeafValueBytes=LeafValueBytes+VALUES(LeafValueBytes),
+			ExtraDataBytes=ExtraDataBytes+VALUES(ExtraDataBytes),
+			UnsequencedCount=UnsequencedCount+VALUES(UnsequencedCount)`
+	selectTreeStatsSQL = `SELECT COALESCE(SUM(LeafCount),0),COALESCE(SUM(LeafValueBytes),0),COALESCE(SUM(ExtraDataBytes),0),COALESCE(SUM(UnsequencedCount),0)
+			FROM TreeStats WHERE TreeId=?`
+	selectTreeStatsLeafCountSQL = "SELECT COALESCE(SUM(LeafCount),0) FROM TreeStats WHERE TreeId=?"
+
+	// These statements compute the statistics of trees which don't maintain
+	// the TreeStats table, by scanning all of their leaves.
+	selectLeafDataBytesSQL     = "SELECT COALESCE(SUM(LENGTH(LeafValue)),0),COALESCE(SUM(LENGTH(ExtraData)),0) FROM LeafData WHERE TreeId=?"
+	selectUnsequencedCountSQL  = "SELECT COUNT(*) FROM Unsequenced WHERE TreeId=?"
+	selectLeafDataBytesByIDSQL = "SELECT LENGTH(LeafValue),COALESCE(LENGTH(ExtraData),0) FROM LeafData WHERE TreeId=? AND LeafIdentityHash=?"
+)
+
+var _ storage.TreeStatsTX = &logTreeTX{}
+
+// treeStatsDelta is a change to the statistics of a tree made by a write.
+type treeStatsDelta struct {
+	leaves         int64
+	leafValueBytes int64
+	extraDataBytes int64
+	unsequenced    int64
+}
+
+// addLeafData accounts for the data of a leaf stored in LeafData.
+func (d *treeStatsDelta) addLeafData(leaf *trillian.LogLeaf) {
+	d.leafValueBytes += int64(len(leaf.LeafValue))
+	d.extraDataBytes += int64(len(leaf.ExtraData))
+}
+
+// treeStatsEnabled returns whether the write paths maintain the TreeStats
+// table, see LogStorageOptions.TreeStatsShards.
+func (t *logTreeTX) treeStatsEnabled() bool {
+	return t.ls.opts.TreeStatsShards > 0
+}
+
+// updateTreeStats adds d to a random shard of the statistics of the tree, if
+// they are maintained. Spreading the updates across shards stops concurrent
+// writers of the same tree from queueing up on a single row lock.
+func (t *logTreeTX) updateTreeStats(ctx context.Context, d treeStatsDelta) error {
+	if !t.treeStatsEnabled() || d == (treeStatsDelta{}) {
+		return nil
+	}
+	shard := rand.Intn(t.ls.opts.TreeStatsShards)
+	if _, err := t.tx.ExecContext(ctx, upsertTreeStatsSQL, t.treeID, shard,
+		d.leaves, d.leafValueBytes, d.extraDataBytes, d.unsequenced); err != nil {
+		glog.Warningf("Failed to update TreeStats: %s", err)
+		return mysqlToGRPC(err)
+	}
+	return nil
+}
+
+// GetTreeStats returns the statistics of the log. If the TreeStats table is
+// maintained they are read from it, otherwise they are computed by scanning
+// the leaves of the log, which is slow for large logs.
+func (t *logTreeTX) GetTreeStats(ctx context.Context) (*storage.TreeStats, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	stats := &storage.TreeStats{}
+	if t.treeStatsEnabled() {
+		if err := t.tx.QueryRowContext(ctx, selectTreeStatsSQL, t.treeID).Scan(
+			&stats.LeafCount, &stats.LeafValueBytes, &stats.ExtraDataBytes, &stats.UnsequencedCount); err != nil {
+			glog.Warningf("Error reading TreeStats: %s", err)
+			return nil, err
+		}
+		return stats, nil
+	}
+
+	if err := t.tx.QueryRowContext(ctx, selectSequencedLeafCountSQL, t.treeID).Scan(&stats.LeafCount); err != nil {
+		glog.Warningf("Error counting sequenced leaves: %s", err)
+		return nil, err
+	}
+	if err := t.tx.QueryRowContext(ctx, selectLeafDataBytesSQL, t.treeID).Scan(&stats.LeafValueBytes, &stats.ExtraDataBytes); err != nil {
+		glog.Warningf("Error summing leaf data sizes: %s", err)
+		return nil, err
+	}
+	if err := t.tx.QueryRowContext(ctx, selectUnsequencedCountSQL, t.treeID).Scan(&stats.UnsequencedCount); err != nil {
+		glog.Warningf("Error counting unsequenced leaves: %s", err)
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+)
+
+func TestTreeStats(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	s := NewLogStorageWithOpts(DB, nil, LogStorageOptions{TreeStatsShards: 4})
+	// The statistics computed by scanning the leaves must match the ones
+	// maintained in the TreeStats table.
+	scan := NewLogStorage(DB, nil)
+
+	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
+
+	// The value and extra data of each of these leaves is 7 bytes long.
+	leaves := createTestLeaves(3, 20)
+	if _, err := s.QueueLeaves(ctx, tree, leaves, fakeQueueTime); err != nil {
+		t.Fatalf("Failed to queue leaves: %v", err)
+	}
+	// Queueing duplicates doesn't change the statistics.
+	if _, err := s.QueueLeaves(ctx, tree, leaves[:1], fakeQueueTime); err != nil {
+		t.Fatalf("Failed to queue duplicate leaf: %v", err)
+	}
+	checkTreeStats(ctx, t, s, scan, tree, storage.TreeStats{LeafValueBytes: 21, ExtraDataBytes: 21, UnsequencedCount: 3})
+
+	// Sequence one leaf and quarantine another.
+	var quarantined []byte
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dequeued, err := tx.DequeueLeaves(ctx, 2, fakeDequeueCutoffTime)
+		if err != nil {
+			t.Fatalf("Failed to dequeue leaves: %v", err)
+		}
+		if got, want := len(dequeued), 2; got != want {
+			t.Fatalf("Dequeued %d leaves, want %d", got, want)
+		}
+		dequeued[0].LeafIndex = 0
+		dequeued[0].IntegrateTimestamp = ptypes.TimestampNow()
+		if err := tx.UpdateSequencedLeaves(ctx, dequeued[:1]); err != nil {
+			t.Fatalf("UpdateSequencedLeaves(): %v", err)
+		}
+		quarantined = dequeued[1].LeafIdentityHash
+		return tx.(storage.DeadLetterTX).QuarantineLeaves(ctx, dequeued[1:], "poison", fakeQueueTime)
+	})
+	checkTreeStats(ctx, t, s, scan, tree, storage.TreeStats{LeafCount: 1, LeafValueBytes: 21, ExtraDataBytes: 21, UnsequencedCount: 1})
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		_, err := tx.(storage.DeadLetterTX).PurgeDeadLetterLeaves(ctx, [][]byte{quarantined})
+		return err
+	})
+	checkTreeStats(ctx, t, s, scan, tree, storage.TreeStats{LeafCount: 1, LeafValueBytes: 14, ExtraDataBytes: 14, UnsequencedCount: 1})
+
+	// Leaves added to a pre-ordered log count as sequenced straight away.
+	preordered := mustCreateTree(ctx, t, as, testonly.PreorderedLogTree)
+	mustSignAndStoreLogRoot(ctx, t, s, preordered, 0)
+	if _, err := s.AddSequencedLeaves(ctx, preordered, createTestLeaves(2, 0), fakeQueueTime); err != nil {
+		t.Fatalf("AddSequencedLeaves(): %v", err)
+	}
+	checkTreeStats(ctx, t, s, scan, preordered, storage.TreeStats{LeafCount: 2, LeafValueBytes: 12, ExtraDataBytes: 14})
+}
+
+func checkTreeStats(ctx context.Context, t *testing.T, s, scan storage.LogStorage, tree *trillian.Tree, want storage.TreeStats) {
+	t.Helper()
+	for _, ls := range []storage.LogStorage{s, scan} {
+		tx, err := ls.SnapshotForTree(ctx, tree)
+		if err != nil {
+			t.Fatalf("SnapshotForTree(): %v", err)
+		}
+		got, err := tx.(storage.TreeStatsTX).GetTreeStats(ctx)
+		tx.Close()
+		if err != nil {
+			t.Fatalf("GetTreeStats(): %v", err)
+		}
+		if *got != want {
+			t.Errorf("GetTreeStats()=%+v, want %+v", *got, want)
+		}
+	}
+}
@@ -18,25 +18,35 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/base64"
 	"fmt"
 	"runtime/debug"
 	"strings"
 	"sync"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto" //nolint:staticcheck
 	"github.com/google/trillian"
 	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/compress"
+	"github.com/google/trillian/storage/sqltrace"
 	"github.com/google/trillian/storage/storagepb"
 	"github.com/google/trillian/storage/tree"
+	"go.opencensus.io/trace"
+	"golang.org/x/sync/errgroup"
 )
 
 // These statements are fixed
 const (
 	insertSubtreeMultiSQL = `INSERT INTO Subtree(TreeId, SubtreeId, Nodes, SubtreeRevision) ` + placeholderSQL
-	insertTreeHeadSQL     = `INSERT INTO TreeHead(TreeId,TreeHeadTimestamp,TreeSize,RootHash,TreeRevision,RootSignature)
-		 VALUES(?,?,?,?,?,?)`
+	insertTreeHeadSQL     = `INSERT INTO TreeHead(TreeId,TreeHeadTimestamp,TreeSize,RootHash,TreeRevision,RootSignature,SignerEpoch)
+		 VALUES(?,?,?,?,?,?,?)`
+	// The epoch of the latest head is read with FOR UPDATE, whose next-key
+	// locks hold off other signers inserting a head until the transaction ends.
+	selectTreeHeadEpochSQL = `SELECT SignerEpoch FROM TreeHead WHERE TreeId=?
+		 ORDER BY TreeHeadTimestamp DESC LIMIT 1 FOR UPDATE`
 
 	selectSubtreeSQL = `
  SELECT x.SubtreeId, x.MaxRevision, Subtree.Nodes
@@ -70,13 +80,20 @@ type mySQLTreeStorage struct {
 
 // OpenDB opens a database connection for all MySQL-based storage implementations.
 func OpenDB(dbURL string) (*sql.DB, error) {
-	db, err := sql.Open("mysql", dbURL)
+	c, err := mysql.MySQLDriver{}.OpenConnector(dbURL)
 	if err != nil {
 		// Don't log uri as it could contain credentials
 		glog.Warningf("Could not open MySQL database, check config: %s", err)
 		return nil, err
 	}
+	return openDB(c)
+}
 
+// openDB opens a database through c. Statements executed for traced requests
+// are recorded in trace spans, which are started before any comments are added
+// to the statements by c, so that the comments identify the spans.
+func openDB(c driver.Connector) (*sql.DB, error) {
+	db := sql.OpenDB(sqltrace.WrapConnector(c, "mysql"))
 	if _, err := db.ExecContext(context.TODO(), "SET sql_mode = 'STRICT_ALL_TABLES'"); err != nil {
 		glog.Warningf("Failed to set strict mode on mysql db: %s", err)
 		return nil, err
@@ -141,21 +158,30 @@ func (m *mySQLTreeStorage) setSubtreeStmt(ctx context.Context, num int) (*sql.St
 	return m.getStmt(ctx, insertSubtreeMultiSQL, num, "VALUES(?, ?, ?, ?)", "(?, ?, ?, ?)")
 }
 
+// beginTreeTx begins a transaction on the tree. Its trace span covers the
+// transaction until it is committed or rolled back, and is the parent of the
+// spans of its statements.
 func (m *mySQLTreeStorage) beginTreeTx(ctx context.Context, tree *trillian.Tree, hashSizeBytes int, subtreeCache *cache.SubtreeCache) (treeTX, error) {
+	ctx, span := trace.StartSpan(ctx, "mysql.treeTX")
+	span.AddAttributes(trace.Int64Attribute("tree_id", tree.TreeId), trace.StringAttribute("tree_type", tree.TreeType.String()))
 	t, err := m.db.BeginTx(ctx, nil /* opts */)
 	if err != nil {
 		glog.Warningf("Could not start tree TX: %s", err)
+		endTxSpan(span, err)
 		return treeTX{}, err
 	}
 	return treeTX{
 		tx:            t,
+		span:          span,
 		mu:            &sync.Mutex{},
 		ts:            m,
 		treeID:        tree.TreeId,
 		treeType:      tree.TreeType,
 		hashSizeBytes: hashSizeBytes,
+		compression:   tree.MapCompression,
 		subtreeCache:  subtreeCache,
 		writeRevision: -1,
+		maxBytes:      tree.MaxStorageBytes,
 	}, nil
 }
 
@@ -164,17 +190,31 @@ type treeTX struct {
 	mu            *sync.Mutex
 	closed        bool
 	tx            *sql.Tx
+	span          *trace.Span
 	ts            *mySQLTreeStorage
 	treeID        int64
 	treeType      trillian.TreeType
 	hashSizeBytes int
-	subtreeCache  *cache.SubtreeCache
+	// compression is the compression of the subtrees written by this
+	// transaction. Subtrees are read whatever their compression.
+	compression  trillian.MapCompression
+	subtreeCache *cache.SubtreeCache
+	// shared, if set, caches subtrees read by this and other transactions.
+	// It is only set for logs.
+	shared        *cache.SharedLogSubtrees
 	dirty         []*storagepb.SubtreeProto
 	writeRevision int64
+	// usageShards is the number of rows of the TreeUsage table which the
+	// usage of the tree is spread across, or zero if it isn't tracked.
+	usageShards int
+	// maxBytes is the max_storage_bytes of the tree.
+	maxBytes int64
+	// usage is the storage written by this transaction.
+	usage treeUsageDelta
 }
 
-func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID tree.NodeID) (*storagepb.SubtreeProto, error) {
-	s, err := t.getSubtrees(ctx, treeRevision, []tree.NodeID{nodeID})
+func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID tree.NodeID2) (*storagepb.SubtreeProto, error) {
+	s, err := t.getSubtrees(ctx, treeRevision, []tree.NodeID2{nodeID})
 	if err != nil {
 		return nil, err
 	}
@@ -188,13 +228,13 @@ func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID tree
 	}
 }
 
-func (t *treeTX) getSubtreesWithLock(ctx context.Context, rev int64, ids []tree.NodeID) ([]*storagepb.SubtreeProto, error) {
+func (t *treeTX) getSubtreesWithLock(ctx context.Context, rev int64, ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	return t.getSubtrees(ctx, rev, ids)
 }
 
-func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []tree.NodeID) ([]*storagepb.SubtreeProto, error) {
+func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
 	glog.V(2).Infof("getSubtrees(len(nodeIDs)=%d)", len(nodeIDs))
 	glog.V(4).Infof("getSubtrees(")
 	if len(nodeIDs) == 0 {
@@ -207,7 +247,59 @@ func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []
 	}
 	stx := t.tx.StmtContext(ctx, tmpl)
 	defer stx.Close()
+	return t.querySubtrees(ctx, stx, treeRevision, nodeIDs)
+}
 
+// getSubtreesConcurrently reads the subtrees with the given IDs in batches of
+// no more than batchSize IDs, with up to concurrency queries running at once.
+// The queries run outside of the transaction, each on a connection of its
+// own, so this must only be used to read revisions which were committed
+// before the transaction started: subtrees don't change once written at a
+// revision, so such reads see the same subtrees as getSubtrees.
+func (t *treeTX) getSubtreesConcurrently(ctx context.Context, treeRevision int64, nodeIDs []tree.NodeID2, batchSize, concurrency int) ([]*storagepb.SubtreeProto, error) {
+	glog.V(2).Infof("getSubtreesConcurrently(len(nodeIDs)=%d)", len(nodeIDs))
+	var batches [][]tree.NodeID2
+	for len(nodeIDs) > batchSize {
+		batches = append(batches, nodeIDs[:batchSize])
+		nodeIDs = nodeIDs[batchSize:]
+	}
+	batches = append(batches, nodeIDs)
+
+	results := make([][]*storagepb.SubtreeProto, len(batches))
+	sem := make(chan struct{}, concurrency)
+	g, gctx := errgroup.WithContext(ctx)
+	for i, batch := range batches {
+		i, batch := i, batch
+		g.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-gctx.Done():
+				return gctx.Err()
+			}
+			defer func() { <-sem }()
+			stmt, err := t.ts.getSubtreeStmt(gctx, len(batch))
+			if err != nil {
+				return err
+			}
+			results[i], err = t.querySubtrees(gctx, stmt, treeRevision, batch)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	var ret []*storagepb.SubtreeProto
+	for _, r := range results {
+		ret = append(ret, r...)
+	}
+	return ret, nil
+}
+
+// querySubtrees reads the subtrees with the given IDs at treeRevision, using
+// stmt, which is a statement prepared from selectSubtreeSQL for len(nodeIDs)
+// IDs.
+func (t *treeTX) querySubtrees(ctx context.Context, stmt *sql.Stmt, treeRevision int64, nodeIDs []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
 	args := make([]interface{}, 0, len(nodeIDs)+3)
 
 	// populate args with nodeIDs
@@ -224,7 +316,7 @@ func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []
 	args = append(args, treeRevision)
 	args = append(args, t.treeID)
 
-	rows, err := stx.QueryContext(ctx, args...)
+	rows, err := stmt.QueryContext(ctx, args...)
 	if err != nil {
 		glog.Warningf("Failed to get merkle subtrees: %s", err)
 		return nil, err
@@ -247,6 +339,10 @@ func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []
 			glog.Warningf("Failed to scan merkle subtree: %s", err)
 			return nil, err
 		}
+		if nodesRaw, err = compress.Decompress(nodesRaw); err != nil {
+			glog.Warningf("Failed to decompress SubtreeProto: %s", err)
+			return nil, err
+		}
 		var subtree storagepb.SubtreeProto
 		if err := proto.Unmarshal(nodesRaw, &subtree); err != nil {
 			glog.Warningf("Failed to unmarshal SubtreeProto: %s", err)
@@ -313,6 +409,10 @@ func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.Subtre
 		if err != nil {
 			return err
 		}
+		if subtreeBytes, err = compress.Compress(t.compression, subtreeBytes); err != nil {
+			return err
+		}
+		t.usage.subtreeBytes += int64(len(subtreeBytes))
 		args = append(args, t.treeID)
 		args = append(args, s.Prefix)
 		args = append(args, subtreeBytes)
@@ -335,6 +435,16 @@ func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.Subtre
 	return nil
 }
 
+// latestSignerEpoch returns the signer epoch of the latest root of the tree,
+// or 0 if it has none, read by the given locking query.
+func latestSignerEpoch(ctx context.Context, tx *sql.Tx, query string, treeID int64) (int64, error) {
+	var epoch int64
+	if err := tx.QueryRowContext(ctx, query, treeID).Scan(&epoch); err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+	return epoch, nil
+}
+
 func checkResultOkAndRowCountIs(res sql.Result, err error, count int64) error {
 	// The Exec() might have just failed
 	if err != nil {
@@ -356,11 +466,16 @@ func checkResultOkAndRowCountIs(res sql.Result, err error, count int64) error {
 	return nil
 }
 
-// getSubtreesAtRev returns a GetSubtreesFunc which reads at the passed in rev.
+// getSubtreesAtRev returns a GetSubtreesFunc which reads at the passed in rev,
+// through the shared cache if there is one.
 func (t *treeTX) getSubtreesAtRev(ctx context.Context, rev int64) cache.GetSubtreesFunc {
-	return func(ids []tree.NodeID) ([]*storagepb.SubtreeProto, error) {
+	get := func(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
 		return t.getSubtrees(ctx, rev, ids)
 	}
+	if t.shared != nil {
+		return t.shared.Wrap(t.treeID, rev, get)
+	}
+	return get
 }
 
 // GetMerkleNodes returns the requests nodes at (or below) the passed in treeRevision.
@@ -377,7 +492,7 @@ func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
 
 	for _, n := range nodes {
 		err := t.subtreeCache.SetNodeHash(n.NodeID, n.Hash,
-			func(nID tree.NodeID) (*storagepb.SubtreeProto, error) {
+			func(nID tree.NodeID2) (*storagepb.SubtreeProto, error) {
 				return t.getSubtree(ctx, t.writeRevision, nID)
 			})
 		if err != nil {
@@ -403,8 +518,13 @@ func (t *treeTX) Commit(ctx context.Context) error {
 			return err
 		}
 	}
+	if err := updateTreeUsage(ctx, t.tx, t.treeID, t.usageShards, t.maxBytes, t.usage); err != nil {
+		return err
+	}
 	t.closed = true
-	if err := t.tx.Commit(); err != nil {
+	err := t.tx.Commit()
+	endTxSpan(t.span, err)
+	if err != nil {
 		glog.Warningf("TX commit error: %s, stack:\n%s", err, string(debug.Stack()))
 		return err
 	}
@@ -420,7 +540,10 @@ func (t *treeTX) Rollback() error {
 
 func (t *treeTX) rollbackInternal() error {
 	t.closed = true
-	if err := t.tx.Rollback(); err != nil {
+	err := t.tx.Rollback()
+	t.span.AddAttributes(trace.BoolAttribute("rolled_back", true))
+	endTxSpan(t.span, err)
+	if err != nil {
 		glog.Warningf("TX rollback error: %s, stack:\n%s", err, string(debug.Stack()))
 		return err
 	}
@@ -441,6 +564,14 @@ func (t *treeTX) Close() error {
 	return nil
 }
 
+// endTxSpan ends the trace span of a transaction, recording err, if any.
+func endTxSpan(span *trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+	}
+	span.End()
+}
+
 func (t *treeTX) IsOpen() bool {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -455,14 +586,12 @@ func (t *treeTX) IsOpen() bool {
 // TODO(pavelkalinnikov): This function is duplicated in multiple storage
 // implementations. We should create a common "tree layout" type in the
 // top-level storage package and reuse it for ID/strata validation.
-func subtreeKey(id tree.NodeID) ([]byte, error) {
+func subtreeKey(id tree.NodeID2) ([]byte, error) {
 	// TODO(pavelkalinnikov): Extend this check to verify strata boundaries.
-	if id.PrefixLenBits%8 != 0 {
-		return nil, fmt.Errorf("invalid subtree ID - not multiple of 8: %d", id.PrefixLenBits)
-	}
-	// The returned slice must not be nil, as it would correspond to NULL in SQL.
-	if bytes := id.Path; bytes != nil {
-		return bytes[:id.PrefixLenBits/8], nil
+	tileID, err := tree.NewTileID(id)
+	if err != nil {
+		return nil, err
 	}
-	return []byte{}, nil
+	// The returned slice is not nil, as that would correspond to NULL in SQL.
+	return tileID.AsBytes(), nil
 }
@@ -0,0 +1,96 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// upsertTreeUsageSQL adds deltas to a shard of the usage of a tree.
+	upsertTreeUsageSQL = `INSERT INTO TreeUsage(TreeId,Shard,LeafBytes,SubtreeBytes)
+			VALUES(?,?,?,?)
+			ON DUPLICATE KEY UPDATE
+			LeafBytes=LeafBytes+VALUES(LeafBytes),
+			SubtreeBytes=SubtreeBytes+VALUES(SubtreeBytes)`
+	selectTreeUsageSQL = "SELECT COALESCE(SUM(LeafBytes),0),COALESCE(SUM(SubtreeBytes),0) FROM TreeUsage WHERE TreeId=?"
+)
+
+var (
+	_ storage.TreeUsageTX = &logTreeTX{}
+	_ storage.TreeUsageTX = &mapTreeTX{}
+)
+
+// treeUsageDelta is the storage written for a tree by a transaction.
+type treeUsageDelta struct {
+	leafBytes    int64
+	subtreeBytes int64
+}
+
+// updateTreeUsage adds d to a random one of the given number of shards of
+// the usage of the tree in tx, if there are any. If the tree has a positive
+// maxBytes, and d adds leaves, it fails with ResourceExhausted if the usage
+// of the tree is then over maxBytes, so that the caller rolls tx back.
+// Writes which add no leaves, such as the sequencing of queued log leaves,
+// always succeed, so that leaves which were admitted aren't stranded.
+//
+// The usage of the tree is read as of the start of tx, so concurrent writers
+// may all be admitted, and take the tree over maxBytes by up to the size of
+// their writes.
+func updateTreeUsage(ctx context.Context, tx *sql.Tx, treeID int64, shards int, maxBytes int64, d treeUsageDelta) error {
+	if shards <= 0 || d == (treeUsageDelta{}) {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, upsertTreeUsageSQL, treeID, rand.Intn(shards), d.leafBytes, d.subtreeBytes); err != nil {
+		glog.Warningf("Failed to update TreeUsage: %s", err)
+		return mysqlToGRPC(err)
+	}
+	if maxBytes <= 0 || d.leafBytes == 0 {
+		return nil
+	}
+	var leafBytes, subtreeBytes int64
+	if err := tx.QueryRowContext(ctx, selectTreeUsageSQL, treeID).Scan(&leafBytes, &subtreeBytes); err != nil {
+		glog.Warningf("Error reading TreeUsage: %s", err)
+		return mysqlToGRPC(err)
+	}
+	if used := leafBytes + subtreeBytes; used > maxBytes {
+		return status.Errorf(codes.ResourceExhausted, "tree %d: write would take storage usage to %d bytes, over the max_storage_bytes of %d", treeID, used, maxBytes)
+	}
+	return nil
+}
+
+// GetTreeUsage implements storage.TreeUsageTX. It fails if the usage of
+// trees isn't tracked, see LogStorageOptions.TreeUsageShards.
+func (t *treeTX) GetTreeUsage(ctx context.Context) (*storage.TreeUsage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.usageShards <= 0 {
+		return nil, status.Error(codes.FailedPrecondition, "the storage usage of trees isn't tracked")
+	}
+	usage := &storage.TreeUsage{}
+	if err := t.tx.QueryRowContext(ctx, selectTreeUsageSQL, t.treeID).Scan(&usage.LeafBytes, &usage.SubtreeBytes); err != nil {
+		glog.Warningf("Error reading TreeUsage: %s", err)
+		return nil, err
+	}
+	return usage, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTreeUsage(t *testing.T) {
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	s := NewLogStorageWithOpts(DB, nil, LogStorageOptions{TreeUsageShards: 4})
+
+	capped := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	capped.MaxStorageBytes = 50
+	tree := mustCreateTree(ctx, t, as, capped)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
+
+	// The value and extra data of each of these leaves is 7 bytes long.
+	if _, err := s.QueueLeaves(ctx, tree, createTestLeaves(3, 20), fakeQueueTime); err != nil {
+		t.Fatalf("Failed to queue leaves: %v", err)
+	}
+	checkTreeUsage(ctx, t, s, tree, 42)
+
+	// Another 14 bytes take the log over its limit.
+	_, err := s.QueueLeaves(ctx, tree, createTestLeaves(1, 30), fakeQueueTime)
+	if got, want := status.Code(err), codes.ResourceExhausted; got != want {
+		t.Fatalf("QueueLeaves() over the limit: %v, want code %v", err, want)
+	}
+	checkTreeUsage(ctx, t, s, tree, 42)
+
+	// Without TreeUsageShards the usage isn't tracked.
+	tx, err := NewLogStorage(DB, nil).SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	if _, err := tx.(storage.TreeUsageTX).GetTreeUsage(ctx); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("GetTreeUsage() without TreeUsageShards: %v, want code %v", err, codes.FailedPrecondition)
+	}
+}
+
+func checkTreeUsage(ctx context.Context, t *testing.T, s storage.LogStorage, tree *trillian.Tree, wantLeafBytes int64) {
+	t.Helper()
+	tx, err := s.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	got, err := tx.(storage.TreeUsageTX).GetTreeUsage(ctx)
+	if err != nil {
+		t.Fatalf("GetTreeUsage(): %v", err)
+	}
+	if got.LeafBytes != wantLeafBytes {
+		t.Errorf("GetTreeUsage().LeafBytes=%d, want %d", got.LeafBytes, wantLeafBytes)
+	}
+}
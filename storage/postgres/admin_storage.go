@@ -49,7 +49,18 @@ const (
 		public_key,
 		max_root_duration_millis,
 		deleted,
-		delete_time_millis
+		delete_time_millis,
+		rate_limits,
+		labels,
+		create_request_id,
+		maintenance,
+		map_strata,
+		map_compression,
+		map_hasher,
+		auto_init,
+		max_storage_bytes,
+		map_key_index,
+		retired_public_keys
 	FROM trees`
 
 	nonDeletedWhere       = " WHERE deleted = false"
@@ -58,7 +69,8 @@ const (
 	selectTreeIDs           = "SELECT tree_id FROM trees"
 	selectNonDeletedTreeIDs = selectTreeIDs + nonDeletedWhere
 
-	selectTreeByID = selectTrees + " WHERE tree_id = $1"
+	selectTreeByID              = selectTrees + " WHERE tree_id = $1"
+	selectTreeByCreateRequestID = selectTrees + " WHERE create_request_id = $1"
 
 	insertSQL = `INSERT INTO trees(
 		tree_id,
@@ -73,8 +85,18 @@ const (
 		update_time_millis,
 		private_key,
 		public_key,
-		max_root_duration_millis)
-	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+		max_root_duration_millis,
+		rate_limits,
+		labels,
+		create_request_id,
+		maintenance,
+		map_strata,
+		map_compression,
+		map_hasher,
+		auto_init,
+		max_storage_bytes,
+		map_key_index)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)`
 
 	insertTreeControlSQL = `INSERT INTO tree_control(
 		tree_id,
@@ -84,8 +106,10 @@ const (
 	VALUES($1, $2, $3, $4)`
 
 	updateTreeSQL = `UPDATE trees SET tree_state = $1, tree_type = $2, display_name = $3, 
-		description = $4, update_time_millis = $5, max_root_duration_millis = $6, private_key = $7
-		WHERE tree_id = $8`
+		description = $4, update_time_millis = $5, max_root_duration_millis = $6, private_key = $7,
+		rate_limits = $8, labels = $9, maintenance = $10, map_compression = $11,
+		auto_init = $12, max_storage_bytes = $13, public_key = $14, retired_public_keys = $15
+		WHERE tree_id = $16`
 
 	softDeleteSQL = "UPDATE trees SET deleted = $1, delete_time_millis = $2 WHERE tree_id = $3"
 
@@ -200,6 +224,24 @@ func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, er
 	return tree, nil
 }
 
+// GetTreeByCreateRequestID implements storage.CreateRequestIDReader.
+func (t *adminTX) GetTreeByCreateRequestID(ctx context.Context, requestID string) (*trillian.Tree, error) {
+	stmt, err := t.tx.PrepareContext(ctx, selectTreeByCreateRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	tree, err := storage.ReadTree(stmt.QueryRowContext(ctx, requestID))
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, status.Errorf(codes.NotFound, "no tree was created with request ID %q", requestID)
+	case err != nil:
+		return nil, fmt.Errorf("error reading tree created with request ID %q: %v", requestID, err)
+	}
+	return tree, nil
+}
+
 func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
 	var query string
 	if includeDeleted {
@@ -276,10 +318,20 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		return nil, err
 	}
 
-	id, err := storage.NewTreeID()
+	id, err := storage.TreeIDForCreation(tree)
 	if err != nil {
 		return nil, err
 	}
+	if tree.TreeId != 0 {
+		// IDs chosen by the creators of trees may already be taken.
+		switch _, err := t.GetTree(ctx, id); status.Code(err) {
+		case codes.NotFound:
+		case codes.OK:
+			return nil, status.Errorf(codes.AlreadyExists, "tree %v already exists", id)
+		default:
+			return nil, err
+		}
+	}
 
 	// Use the time truncated-to-millis throughout, as that's what's stored.
 	nowMillis := storage.ToMillisSinceEpoch(time.Now())
@@ -310,6 +362,22 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
 	}
+	rateLimits, err := storage.MarshalRateLimits(newTree)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := storage.MarshalLabels(newTree)
+	if err != nil {
+		return nil, err
+	}
+	maintenance, err := storage.MarshalMaintenance(newTree)
+	if err != nil {
+		return nil, err
+	}
+	mapStrata, err := storage.MarshalMapStrata(newTree)
+	if err != nil {
+		return nil, err
+	}
 
 	_, err = insertTreeStmt.ExecContext(
 		ctx,
@@ -326,8 +394,22 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		privateKey,
 		newTree.PublicKey.GetDer(),
 		rootDuration/time.Millisecond,
+		rateLimits,
+		labels,
+		sql.NullString{String: newTree.CreateRequestId, Valid: newTree.CreateRequestId != ""},
+		maintenance,
+		mapStrata,
+		storage.MarshalMapCompression(newTree),
+		sql.NullString{String: newTree.MapHasher, Valid: newTree.MapHasher != ""},
+		newTree.AutoInit,
+		newTree.MaxStorageBytes,
+		newTree.MapKeyIndex,
 	)
 	if err != nil {
+		if isUniqueViolation(err) {
+			// The tree or request IDs are taken, maybe by a concurrent request.
+			return nil, status.Errorf(codes.AlreadyExists, "tree %v or a tree created with request ID %q already exists", newTree.TreeId, newTree.CreateRequestId)
+		}
 		return nil, err
 	}
 
@@ -381,6 +463,22 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
 	}
+	rateLimits, err := storage.MarshalRateLimits(tree)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := storage.MarshalLabels(tree)
+	if err != nil {
+		return nil, err
+	}
+	maintenance, err := storage.MarshalMaintenance(tree)
+	if err != nil {
+		return nil, err
+	}
+	retiredPublicKeys, err := storage.MarshalRetiredPublicKeys(tree)
+	if err != nil {
+		return nil, err
+	}
 
 	stmt, err := t.tx.PrepareContext(ctx, updateTreeSQL)
 	if err != nil {
@@ -397,6 +495,14 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		nowMillis,
 		rootDuration/time.Millisecond,
 		privateKey,
+		rateLimits,
+		labels,
+		maintenance,
+		storage.MarshalMapCompression(tree),
+		tree.AutoInit,
+		tree.MaxStorageBytes,
+		tree.PublicKey.GetDer(),
+		retiredPublicKeys,
 		tree.TreeId); err != nil {
 		return nil, err
 	}
@@ -29,7 +29,7 @@ import (
 )
 
 var (
-	allTables = []string{"unsequenced", "tree_head", "sequenced_leaf_data", "leaf_data", "subtree", "tree_control", "trees"}
+	allTables = []string{"unsequenced", "tree_head", "sequenced_leaf_data", "leaf_data", "map_leaf", "map_head", "subtree", "tree_control", "trees"}
 	db        *sql.DB
 )
 
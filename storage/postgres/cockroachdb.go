@@ -0,0 +1,81 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"errors"
+	"flag"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/lib/pq"
+)
+
+var crdbRetries = flag.Int("cockroachdb_retries", 5, "Number of times transactions are retried after failing with retryable errors in the cockroachdb storage")
+
+// crdbRetryMessage is a fragment of the messages of the errors CockroachDB
+// returns when a transaction must be retried, which is matched when the
+// *pq.Error has been formatted into another error.
+const crdbRetryMessage = "restart transaction"
+
+func init() {
+	if err := storage.RegisterProvider("cockroachdb", newCockroachDBProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider cockroachdb: %v", err)
+	}
+}
+
+// cockroachDBProvider is a storage.Provider for CockroachDB, which uses the
+// Postgres storage in the CockroachDB dialect, and retries transactions which
+// fail with retryable errors. CockroachDB runs transactions with serializable
+// isolation, and expects clients to retry those it aborts.
+type cockroachDBProvider struct {
+	*pgProvider
+	r retrier
+}
+
+func newCockroachDBProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+	p, err := newPGProvider(mf)
+	if err != nil {
+		return nil, err
+	}
+	r := retrier{retries: *crdbRetries, retryable: isRetryableCockroachDBErr}
+	return &cockroachDBProvider{pgProvider: p.(*pgProvider), r: r}, nil
+}
+
+func (s *cockroachDBProvider) LogStorage() storage.LogStorage {
+	opts := s.logOpts
+	opts.Dialect = CockroachDBDialect
+	return &retryingLogStorage{LogStorage: NewLogStorageWithOpts(s.db, s.mf, opts), retrier: s.r}
+}
+
+func (s *cockroachDBProvider) MapStorage() storage.MapStorage {
+	return &retryingMapStorage{MapStorage: NewMapStorage(s.db), retrier: s.r}
+}
+
+func (s *cockroachDBProvider) AdminStorage() storage.AdminStorage {
+	return &retryingAdminStorage{AdminStorage: NewAdminStorage(s.db), retrier: s.r}
+}
+
+// isRetryableCockroachDBErr returns whether err is a retryable error, with
+// SQLSTATE 40001, after which the failed transaction can be retried.
+func isRetryableCockroachDBErr(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code.Name() == "serialization_failure"
+	}
+	return strings.Contains(strings.ToLower(err.Error()), crdbRetryMessage)
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsRetryableCockroachDBErr(t *testing.T) {
+	for _, test := range []struct {
+		desc string
+		err  error
+		want bool
+	}{
+		{desc: "serialization", err: &pq.Error{Code: "40001"}, want: true},
+		{desc: "wrapped", err: fmt.Errorf("commit: %w", &pq.Error{Code: "40001"}), want: true},
+		{desc: "ambiguous", err: &pq.Error{Code: "40003", Message: "result is ambiguous"}},
+		{desc: "unique", err: &pq.Error{Code: "23505"}},
+		{desc: "formatted", err: fmt.Errorf("Unsequenced: %v", &pq.Error{Message: "restart transaction: TransactionRetryWithProtoRefreshError: ReadWithinUncertaintyIntervalError"}), want: true},
+		{desc: "other", err: errors.New("pq: relation \"trees\" does not exist")},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if got := isRetryableCockroachDBErr(test.err); got != test.want {
+				t.Errorf("isRetryableCockroachDBErr(%v)=%v, want %v", test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDialectSQL(t *testing.T) {
+	for _, test := range []struct {
+		dialect   Dialect
+		wantFuncs bool
+	}{
+		{dialect: PostgresDialect, wantFuncs: true},
+		{dialect: CockroachDBDialect},
+	} {
+		t.Run(test.dialect.String(), func(t *testing.T) {
+			for _, sql := range []string{
+				test.dialect.insertLeafDataSQL(),
+				test.dialect.insertSequencedLeafSQL(),
+				unsequencedEntrySQL(test.dialect),
+			} {
+				// The batched queue inserts unsequenced entries without a
+				// function in all dialects.
+				if sql == insertUnsequencedEntrySQL && !strings.Contains(sql, "ignore_duplicates") {
+					continue
+				}
+				if got := strings.Contains(sql, "ignore_duplicates"); got != test.wantFuncs {
+					t.Errorf("%q calls functions: %v, want %v", sql, got, test.wantFuncs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+// Dialect is the dialect of SQL spoken by the database behind the Postgres
+// storage. Most statements are shared by all dialects, only those which
+// insert leaves while ignoring duplicates differ.
+type Dialect int
+
+const (
+	// PostgresDialect is spoken by Postgres and YugabyteDB. Duplicate leaves
+	// are ignored by the PL/pgSQL functions of schema/storage.sql.
+	PostgresDialect Dialect = iota
+	// CockroachDBDialect is spoken by CockroachDB, which doesn't run the
+	// PL/pgSQL functions. Duplicate leaves are ignored with ON CONFLICT DO
+	// NOTHING, and RETURNING clauses report whether they were inserted.
+	CockroachDBDialect
+)
+
+// Statements which insert a leaf unless it is a duplicate, and return a single
+// boolean row which is false if it was.
+const (
+	crdbInsertLeafDataSQL = `WITH ins AS (
+		INSERT INTO leaf_data(tree_id,leaf_identity_hash,leaf_value,extra_data,queue_timestamp_nanos)
+		VALUES($1,$2,$3,$4,$5) ON CONFLICT DO NOTHING RETURNING true)
+		SELECT EXISTS(SELECT 1 FROM ins)`
+	crdbInsertSequencedLeafSQL = `WITH ins AS (
+		INSERT INTO sequenced_leaf_data(tree_id,sequence_number,leaf_identity_hash,merkle_leaf_hash,integrate_timestamp_nanos)
+		VALUES($1,$2,$3,$4,$5) ON CONFLICT DO NOTHING RETURNING true)
+		SELECT EXISTS(SELECT 1 FROM ins)`
+)
+
+// String returns the name of the dialect.
+func (d Dialect) String() string {
+	switch d {
+	case PostgresDialect:
+		return "postgres"
+	case CockroachDBDialect:
+		return "cockroachdb"
+	}
+	return "unknown"
+}
+
+// insertLeafDataSQL returns the statement which inserts a row of leaf_data,
+// and returns whether it wasn't a duplicate.
+func (d Dialect) insertLeafDataSQL() string {
+	if d == CockroachDBDialect {
+		return crdbInsertLeafDataSQL
+	}
+	return insertLeafDataSQL
+}
+
+// insertSequencedLeafSQL returns the statement which inserts a row of
+// sequenced_leaf_data, and returns whether it wasn't a duplicate.
+func (d Dialect) insertSequencedLeafSQL() string {
+	if d == CockroachDBDialect {
+		return crdbInsertSequencedLeafSQL
+	}
+	return insertSequencedLeafSQL
+}
@@ -29,11 +29,13 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/features"
 	"github.com/google/trillian/merkle/hashers/registry"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/requestid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -68,10 +70,11 @@ const (
                         AND s.merkle_leaf_hash IN (` + placeholderSQL + `) AND l.tree_id = <param> AND s.tree_id = l.tree_id`
 	// TODO(drysdale): rework the code so the dummy hash isn't needed (e.g. this assumes hash size is 32)
 	dummymerkleLeafHash = "00000000000000000000000000000000"
-	// This statement returns a dummy Merkle leaf hash value (which must be
-	// of the right size) so that its signature matches that of the other
-	// leaf-selection statements.
-	selectLeavesByLeafIdentityHashSQL = `SELECT '` + dummymerkleLeafHash + `',l.leaf_identity_hash,l.leaf_value,-1,l.extra_data,l.queue_timestamp_nanos,s.integrate_timestamp_nanos
+	// This statement returns the Merkle leaf hash and index of leaves which
+	// have been integrated, and a dummy Merkle leaf hash value (which must be
+	// of the right size) and index -1 for those which haven't, so that its
+	// signature matches that of the other leaf-selection statements.
+	selectLeavesByLeafIdentityHashSQL = `SELECT COALESCE(s.merkle_leaf_hash,'` + dummymerkleLeafHash + `'),l.leaf_identity_hash,l.leaf_value,COALESCE(s.sequence_number,-1),l.extra_data,l.queue_timestamp_nanos,s.integrate_timestamp_nanos
                         FROM leaf_data l LEFT JOIN sequenced_leaf_data s ON (l.leaf_identity_hash = s.leaf_identity_hash AND l.tree_id = s.tree_id)
                         WHERE l.leaf_identity_hash IN (` + placeholderSQL + `) AND l.tree_id = <param>`
 
@@ -122,19 +125,38 @@ func labelForTX(t *logTreeTX) string {
 	return strconv.FormatInt(t.treeID, 10)
 }
 
-func observe(hist monitoring.Histogram, duration time.Duration, label string) {
-	hist.Observe(duration.Seconds(), label)
+// observe records the duration of an operation performed for ctx, linking it
+// to the trace ctx is part of if there is one.
+func observe(ctx context.Context, hist monitoring.Histogram, duration time.Duration, label string) {
+	monitoring.ObserveContext(ctx, hist, duration.Seconds(), label)
 }
 
 type postgresLogStorage struct {
 	*pgTreeStorage
 	admin         storage.AdminStorage
 	metricFactory monitoring.MetricFactory
+	opts          LogStorageOptions
+}
+
+// LogStorageOptions holds optional settings of the PostgreSQL log storage.
+type LogStorageOptions struct {
+	// SharedSubtrees, if set, caches the subtrees of logs read by all
+	// transactions.
+	SharedSubtrees *cache.SharedLogSubtrees
+	// Dialect is the dialect of SQL spoken by the database. Defaults to
+	// PostgresDialect.
+	Dialect Dialect
 }
 
 // NewLogStorage creates a storage.LogStorage instance for the specified PostgreSQL URL.
 // It assumes storage.AdminStorage is backed by the same PostgreSQL database as well.
 func NewLogStorage(db *sql.DB, mf monitoring.MetricFactory) storage.LogStorage {
+	return NewLogStorageWithOpts(db, mf, LogStorageOptions{})
+}
+
+// NewLogStorageWithOpts creates a storage.LogStorage instance with the given
+// options.
+func NewLogStorageWithOpts(db *sql.DB, mf monitoring.MetricFactory, opts LogStorageOptions) storage.LogStorage {
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
 	}
@@ -142,6 +164,7 @@ func NewLogStorage(db *sql.DB, mf monitoring.MetricFactory) storage.LogStorage {
 		admin:         NewAdminStorage(db),
 		pgTreeStorage: newTreeStorage(db),
 		metricFactory: mf,
+		opts:          opts,
 	}
 }
 
@@ -269,6 +292,9 @@ func (m *postgresLogStorage) beginInternal(ctx context.Context, tree *trillian.T
 		return nil, err
 	}
 
+	if features.Enabled(features.SharedSubtreeCache, tree.TreeId) {
+		ttx.shared = m.opts.SharedSubtrees
+	}
 	ltx := &logTreeTX{
 		treeTX: ttx,
 		ls:     m,
@@ -380,7 +406,7 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	start := time.Now()
 	stx, err := t.tx.PrepareContext(ctx, selectQueuedLeavesSQL)
 	if err != nil {
-		glog.Warningf("Failed to prepare dequeue select: %s", err)
+		glog.Warningf("%sFailed to prepare dequeue select: %s", requestid.Prefix(ctx), err)
 		return nil, err
 	}
 	defer stx.Close()
@@ -389,7 +415,7 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	dq := make([]dequeuedLeaf, 0, limit)
 	rows, err := stx.QueryContext(ctx, t.treeID, cutoffTime.UnixNano(), limit)
 	if err != nil {
-		glog.Warningf("Failed to select rows for work: %s", err)
+		glog.Warningf("%sFailed to select rows for work: %s", requestid.Prefix(ctx), err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -397,7 +423,7 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	for rows.Next() {
 		leaf, dqInfo, err := t.dequeueLeaf(rows)
 		if err != nil {
-			glog.Warningf("Error dequeuing leaf: %v %v", err, selectQueuedLeavesSQL)
+			glog.Warningf("%sError dequeuing leaf: %v %v", requestid.Prefix(ctx), err, selectQueuedLeavesSQL)
 			return nil, err
 		}
 
@@ -414,7 +440,7 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	}
 	label := labelForTX(t)
 	selectDuration := time.Since(start)
-	observe(dequeueSelectLatency, selectDuration, label)
+	observe(ctx, dequeueSelectLatency, selectDuration, label)
 
 	// The convention is that if leaf processing succeeds (by committing this tx)
 	// then the unsequenced entries for them are removed
@@ -428,8 +454,8 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 
 	totalDuration := time.Since(start)
 	removeDuration := totalDuration - selectDuration
-	observe(dequeueRemoveLatency, removeDuration, label)
-	observe(dequeueLatency, totalDuration, label)
+	observe(ctx, dequeueRemoveLatency, removeDuration, label)
+	observe(ctx, dequeueLatency, totalDuration, label)
 	dequeuedCounter.Add(float64(len(leaves)), label)
 
 	return leaves, nil
@@ -475,12 +501,12 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 		if err != nil {
 			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
 		}
-		dupCheckRow, err := t.tx.QueryContext(ctx, insertLeafDataSQL, t.treeID, leaf.LeafIdentityHash, leaf.LeafValue, leaf.ExtraData, qTimestamp.UnixNano())
+		dupCheckRow, err := t.tx.QueryContext(ctx, t.ls.opts.Dialect.insertLeafDataSQL(), t.treeID, leaf.LeafIdentityHash, leaf.LeafValue, leaf.ExtraData, qTimestamp.UnixNano())
 		if err != nil {
 			return nil, fmt.Errorf("dupecheck failed: %v", err)
 		}
 		insertDuration := time.Since(leafStart)
-		observe(queueInsertLeafLatency, insertDuration, label)
+		observe(ctx, queueInsertLeafLatency, insertDuration, label)
 		resultData := false
 		for dupCheckRow.Next() {
 			err := dupCheckRow.Scan(&resultData)
@@ -497,7 +523,7 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 			existingLeaves[i] = leaf
 			existingCount++
 			queuedDupCounter.Inc(label)
-			glog.Warningf("Found duplicate %v %v", t.treeID, leaf)
+			glog.Warningf("%sFound duplicate %v %v", requestid.Prefix(ctx), t.treeID, leaf)
 			continue
 		}
 
@@ -512,20 +538,21 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
 		}
 		args = append(args, queueArgs(t.treeID, leaf.LeafIdentityHash, queueTimestamp)...)
+		insertSQL := unsequencedEntrySQL(t.ls.opts.Dialect)
 		_, err = t.tx.ExecContext(
 			ctx,
-			insertUnsequencedEntrySQL,
+			insertSQL,
 			args...,
 		)
 		if err != nil {
-			glog.Warningf("Error inserting into Unsequenced: %s query %v arguments: %v", err, insertUnsequencedEntrySQL, args)
+			glog.Warningf("%sError inserting into Unsequenced: %s query %v arguments: %v", requestid.Prefix(ctx), err, insertSQL, args)
 			return nil, fmt.Errorf("Unsequenced: %v -- %v", err, args)
 		}
 		leafDuration := time.Since(leafStart)
-		observe(queueInsertEntryLatency, leafDuration-insertDuration, label)
+		observe(ctx, queueInsertEntryLatency, leafDuration-insertDuration, label)
 	}
 	insertDuration := time.Since(start)
-	observe(queueInsertLatency, insertDuration, label)
+	observe(ctx, queueInsertLatency, insertDuration, label)
 	queuedCounter.Add(float64(len(leaves)), label)
 
 	if existingCount == 0 {
@@ -565,8 +592,8 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 	}
 	totalDuration := time.Since(start)
 	readDuration := totalDuration - insertDuration
-	observe(queueReadLatency, readDuration, label)
-	observe(queueLatency, totalDuration, label)
+	observe(ctx, queueReadLatency, readDuration, label)
+	observe(ctx, queueLatency, totalDuration, label)
 
 	return existingLeaves, nil
 }
@@ -580,7 +607,7 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 	// a savepoint installed before the first insert of the two.
 	const savepoint = "SAVEPOINT AddSequencedLeaves"
 	if _, err := t.tx.ExecContext(ctx, savepoint); err != nil {
-		glog.Errorf("Error adding savepoint: %s", err)
+		glog.Errorf("%sError adding savepoint: %s", requestid.Prefix(ctx), err)
 		return nil, err
 	}
 	// TODO(pavelkalinnikov): Consider performance implication of executing this
@@ -601,22 +628,22 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 		}
 
 		if _, err := t.tx.ExecContext(ctx, savepoint); err != nil {
-			glog.Errorf("Error updating savepoint: %s", err)
+			glog.Errorf("%sError updating savepoint: %s", requestid.Prefix(ctx), err)
 			return nil, err
 		}
 
 		res[i] = &trillian.QueuedLogLeaf{Status: ok}
 
 		// TODO(pavelkalinnikov): Measure latencies.
-		_, err := t.tx.ExecContext(ctx, insertLeafDataSQL,
+		_, err := t.tx.ExecContext(ctx, t.ls.opts.Dialect.insertLeafDataSQL(),
 			t.treeID, leaf.LeafIdentityHash, leaf.LeafValue, leaf.ExtraData, timestamp.UnixNano())
 		// TODO(pavelkalinnikov): Detach PREORDERED_LOG integration latency metric.
 		if err != nil {
-			glog.Errorf("Error inserting leaves[%d] into LeafData: %s", i, err)
+			glog.Errorf("%sError inserting leaves[%d] into LeafData: %s", requestid.Prefix(ctx), i, err)
 			return nil, err
 		}
 
-		dupCheckRow, err := t.tx.QueryContext(ctx, insertSequencedLeafSQL,
+		dupCheckRow, err := t.tx.QueryContext(ctx, t.ls.opts.Dialect.insertSequencedLeafSQL(),
 			t.treeID, leaf.LeafIndex, leaf.LeafIdentityHash, leaf.MerkleLeafHash, 0)
 		// TODO(pavelkalinnikov): Update IntegrateTimestamp on integrating the leaf.
 		resultData := true
@@ -630,11 +657,11 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 		if !resultData {
 			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto()
 			if _, err := t.tx.ExecContext(ctx, "ROLLBACK TO "+savepoint); err != nil {
-				glog.Errorf("Error rolling back to savepoint: %s", err)
+				glog.Errorf("%sError rolling back to savepoint: %s", requestid.Prefix(ctx), err)
 				return nil, err
 			}
 		} else if err != nil {
-			glog.Errorf("Error inserting leaves[%d] into SequencedLeafData: %s %s", i, err, leaf.LeafIdentityHash)
+			glog.Errorf("%sError inserting leaves[%d] into SequencedLeafData: %s %s", requestid.Prefix(ctx), i, err, leaf.LeafIdentityHash)
 			return nil, err
 		}
 
@@ -642,7 +669,7 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 	}
 
 	if _, err := t.tx.ExecContext(ctx, "RELEASE "+savepoint); err != nil {
-		glog.Errorf("Error releasing savepoint: %s", err)
+		glog.Errorf("%sError releasing savepoint: %s", requestid.Prefix(ctx), err)
 		return nil, err
 	}
 
@@ -801,7 +828,8 @@ func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, or
 
 // getLeafDataByIdentityHash retrieves leaf data by LeafIdentityHash, returned
 // as a slice of LogLeaf objects for convenience.  However, note that the
-// returned LogLeaf objects will not have a valid MerkleLeafHash, LeafIndex, or IntegrateTimestamp.
+// returned LogLeaf objects will only have a valid MerkleLeafHash, LeafIndex and
+// IntegrateTimestamp if they have been integrated.
 func (t *logTreeTX) getLeafDataByIdentityHash(ctx context.Context, leafHashes [][]byte) ([]*trillian.LogLeaf, error) {
 	tmpl, err := t.ls.getLeavesByLeafIdentityHashStmt(ctx, len(leafHashes))
 	if err != nil {
@@ -846,6 +874,14 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 	if len(logRoot.Metadata) != 0 {
 		return fmt.Errorf("unimplemented: postgres storage does not support log root metadata")
 	}
+	stored, err := latestSignerEpoch(ctx, t.tx, selectTreeHeadEpochSQL, t.treeID)
+	if err != nil {
+		return err
+	}
+	epoch, err := storage.CheckSignerEpoch(ctx, t.treeID, stored)
+	if err != nil {
+		return err
+	}
 	// get a json copy of the tree_head
 	data, _ := json.Marshal(logRoot)
 	t.tx.ExecContext(
@@ -862,7 +898,8 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 		logRoot.TreeSize,
 		logRoot.RootHash,
 		logRoot.Revision,
-		root.LogRootSignature)
+		root.LogRootSignature,
+		epoch)
 	if err != nil {
 		glog.Warningf("Failed to store signed root: %s", err)
 	}
@@ -0,0 +1,533 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/compress"
+	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/storagepb/convert"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	stree "github.com/google/trillian/storage/tree"
+)
+
+const (
+	insertMapHeadSQL = `INSERT INTO map_head(tree_id, map_head_timestamp, root_hash, map_revision, root_signature, mapper_data, signer_epoch)
+		VALUES($1, $2, $3, $4, $5, $6, $7)`
+	selectMapHeadEpochSQL = `SELECT signer_epoch FROM map_head WHERE tree_id=$1
+		ORDER BY map_head_timestamp DESC LIMIT 1`
+	selectLatestSignedMapRootSQL = `SELECT map_head_timestamp, root_hash, map_revision, root_signature, mapper_data
+		FROM map_head WHERE tree_id=$1
+		ORDER BY map_head_timestamp DESC LIMIT 1`
+	selectGetSignedMapRootSQL = `SELECT map_head_timestamp, root_hash, map_revision, root_signature, mapper_data
+		FROM map_head WHERE tree_id=$1 AND map_revision=$2`
+	insertMapLeafSQL = "INSERT INTO map_leaf(tree_id, key_hash, map_revision, leaf_value) VALUES($1, $2, $3, $4)"
+	// selectMapLeafSQL reads the latest revision of each of the given keys,
+	// up to a given revision.
+	selectMapLeafSQL = `SELECT DISTINCT ON (key_hash) key_hash, leaf_value
+		FROM map_leaf
+		WHERE key_hash IN (` + placeholderSQL + `) AND
+		tree_id = <param> AND map_revision <= <param>
+		ORDER BY key_hash, map_revision DESC`
+	// selectMapLeafScanSQL reads the leaves of a map in order of their key
+	// hashes, starting after a given key hash.
+	selectMapLeafScanSQL = `SELECT DISTINCT ON (key_hash) key_hash, leaf_value
+		FROM map_leaf WHERE tree_id=$1 AND key_hash>$2 AND map_revision<=$3
+		ORDER BY key_hash, map_revision DESC LIMIT $4`
+)
+
+// mapLeafScanBatchSize is the number of leaves read by each query of
+// ScanLeaves.
+const mapLeafScanBatchSize = 1000
+
+// maxSubtreesPerInsert is the number of tiles written by each INSERT, as
+// Postgres allows at most 65535 parameters per statement.
+const maxSubtreesPerInsert = 65535 / 4
+
+var (
+	defaultMapStrata = []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 176}
+	defaultLayout    = stree.NewLayout(defaultMapStrata)
+)
+
+type pgMapStorage struct {
+	*pgTreeStorage
+	admin storage.AdminStorage
+}
+
+// NewMapStorage creates a storage.MapStorage instance for the specified
+// Postgres database. It assumes storage.AdminStorage is backed by the same
+// database as well.
+func NewMapStorage(db *sql.DB) storage.MapStorage {
+	return &pgMapStorage{
+		admin:         NewAdminStorage(db),
+		pgTreeStorage: newTreeStorage(db),
+	}
+}
+
+func (m *pgMapStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return m.db.PingContext(ctx)
+}
+
+func (m *pgMapStorage) begin(ctx context.Context, tree *trillian.Tree, readonly bool) (storage.MapTreeTX, error) {
+	// TODO: Find a stronger way to ensure that tree has been pulled from storage.
+	// This is a cheap safety-belt check to help us use this API consistently.
+	if tree.UpdateTime == nil {
+		return nil, fmt.Errorf("tree.UpdateTime: %v. tree must be pulled from storage", tree.UpdateTime)
+	}
+	if got, want := tree.TreeType, trillian.TreeType_MAP; got != want {
+		return nil, fmt.Errorf("begin(tree.TreeType: %v), want %v", got, want)
+	}
+	hasher, err := registry.NewMapHasherForTree(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := m.Layout(tree)
+	if err != nil {
+		return nil, err
+	}
+	stCache := cache.NewMapSubtreeCache(mapStrata(tree), tree.TreeId, hasher)
+	ttx, err := m.beginTreeTx(ctx, tree, hasher.Size(), stCache)
+	if err != nil {
+		return nil, err
+	}
+	mtx := &mapTreeTX{
+		treeTX:       ttx,
+		layout:       l,
+		ms:           m,
+		hasher:       hasher,
+		readRevision: -1,
+	}
+
+	if readonly {
+		// readRevision will be set later, by the first
+		// GetSignedMapRoot/LatestSignedMapRoot operation.
+		return mtx, nil
+	}
+
+	// A read-write transaction needs to know the current revision
+	// so it can write at revision+1.
+	root, err := mtx.LatestSignedMapRoot(ctx)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return nil, err
+	}
+	if err == storage.ErrTreeNeedsInit {
+		return mtx, err
+	}
+
+	var mr types.MapRootV1
+	if err := mr.UnmarshalBinary(root.MapRoot); err != nil {
+		return nil, err
+	}
+
+	mtx.readRevision = int64(mr.Revision)
+	mtx.treeTX.writeRevision = int64(mr.Revision) + 1
+	return mtx, nil
+}
+
+func (m *pgMapStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyMapTreeTX, error) {
+	return m.begin(ctx, tree, true /* readonly */)
+}
+
+// SnapshotAtRevision starts a read-only transaction pinned to the given
+// revision of the tree.
+func (m *pgMapStorage) SnapshotAtRevision(ctx context.Context, tree *trillian.Tree, revision int64) (storage.ReadOnlyMapTreeTX, error) {
+	tx, err := m.begin(ctx, tree, true /* readonly */)
+	if err != nil {
+		return nil, err
+	}
+	mtx := tx.(*mapTreeTX)
+	root, err := mtx.GetSignedMapRoot(ctx, revision)
+	if err == sql.ErrNoRows {
+		err = status.Errorf(codes.NotFound, "tree %d: map root %d not found", tree.TreeId, revision)
+	}
+	if err != nil {
+		mtx.Close()
+		return nil, err
+	}
+	mtx.pinnedRoot = root
+	return mtx, nil
+}
+
+// Layout returns the layout of the given tree, which is that of its map strata
+// if it has them, or the default one.
+func (m *pgMapStorage) Layout(tree *trillian.Tree) (*stree.Layout, error) {
+	if len(tree.MapStrata) == 0 {
+		return defaultLayout, nil
+	}
+	hasher, err := registry.NewMapHasherForTree(tree)
+	if err != nil {
+		return nil, err
+	}
+	height := 0
+	for _, h := range tree.MapStrata {
+		if h <= 0 || h%8 != 0 {
+			return nil, status.Errorf(codes.FailedPrecondition, "tree %d: invalid map strata %v", tree.TreeId, tree.MapStrata)
+		}
+		height += int(h)
+	}
+	if height != hasher.BitLen() {
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %d: map strata %v add up to %d, want %d", tree.TreeId, tree.MapStrata, height, hasher.BitLen())
+	}
+	return stree.NewLayout(mapStrata(tree)), nil
+}
+
+// mapStrata returns the heights of the strata of the given map.
+func mapStrata(tree *trillian.Tree) []int {
+	if len(tree.MapStrata) == 0 {
+		return defaultMapStrata
+	}
+	ret := make([]int, len(tree.MapStrata))
+	for i, h := range tree.MapStrata {
+		ret[i] = int(h)
+	}
+	return ret
+}
+
+// ReadWriteTransaction runs f in a read-write transaction of the tree.
+//
+// The Postgres map storage doesn't track map write batches, see
+// storage.WithMapWriteBatch, which would leave the writes of a batch which
+// fails before storing its root in place, so it refuses them. Map servers
+// using it must update maps in a single transaction.
+func (m *pgMapStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+	if batch, _, ok := storage.MapWriteBatchFromContext(ctx); ok {
+		return status.Errorf(codes.FailedPrecondition, "tree %d: map write batch %q: postgres storage only supports map updates in a single transaction", tree.TreeId, batch)
+	}
+	tx, err := m.begin(ctx, tree, false /* readonly */)
+	if tx != nil {
+		defer tx.Close()
+	}
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return err
+	}
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+type mapTreeTX struct {
+	treeTX
+	layout       *stree.Layout
+	ms           *pgMapStorage
+	hasher       hashers.MapHasher
+	readRevision int64
+	// pinnedRoot is the root of the revision which the transaction is pinned
+	// to, if it was started by SnapshotAtRevision.
+	pinnedRoot *trillian.SignedMapRoot
+	// tiles holds the tiles written by SetTiles, which are stored on Commit.
+	tiles []*storagepb.SubtreeProto
+}
+
+func (m *mapTreeTX) ReadRevision(ctx context.Context) (int64, error) {
+	return m.readRevision, nil
+}
+
+func (m *mapTreeTX) WriteRevision(ctx context.Context) (int64, error) {
+	if m.treeTX.writeRevision < 0 {
+		return m.treeTX.writeRevision, errors.New("mapTreeTX write revision not populated")
+	}
+	return m.treeTX.writeRevision, nil
+}
+
+func (m *mapTreeTX) Set(ctx context.Context, keyHash []byte, value *trillian.MapLeaf) error {
+	// A deletion is stored as a row with an empty leaf_value, which masks the
+	// earlier revisions of the key.
+	flatValue := []byte{}
+	if !storage.IsMapLeafDeletion(value) {
+		var err error
+		if flatValue, err = proto.Marshal(value); err != nil {
+			return err
+		}
+		if flatValue, err = compress.Compress(m.treeTX.compression, flatValue); err != nil {
+			return err
+		}
+	}
+
+	res, err := m.tx.ExecContext(ctx, insertMapLeafSQL, m.treeID, keyHash, m.writeRevision, flatValue)
+	if err != nil {
+		glog.Warningf("Failed to set map leaf: %s", err)
+	}
+	return checkResultOkAndRowCountIs(res, err, 1)
+}
+
+// Get returns a list of map leaves indicated by indexes.
+// If an index is not found, no corresponding entry is returned.
+// Each MapLeaf.Index is overwritten with the index the leaf was found at.
+func (m *mapTreeTX) Get(ctx context.Context, revision int64, indexes [][]byte) ([]*trillian.MapLeaf, error) {
+	// If no indexes are requested, return an empty set.
+	if len(indexes) == 0 {
+		return []*trillian.MapLeaf{}, nil
+	}
+	stmt, err := m.ms.getStmt(ctx, &statementSkeleton{
+		sql:               selectMapLeafSQL,
+		firstInsertion:    "%s",
+		firstPlaceholders: 1,
+		restInsertion:     "%s",
+		restPlaceholders:  1,
+		num:               len(indexes),
+	})
+	if err != nil {
+		return nil, err
+	}
+	stx := m.tx.StmtContext(ctx, stmt)
+	defer stx.Close()
+
+	args := make([]interface{}, 0, len(indexes)+2)
+	for _, index := range indexes {
+		args = append(args, index)
+	}
+	args = append(args, m.treeID)
+	args = append(args, revision)
+
+	rows, err := stx.QueryContext(ctx, args...)
+	if err != nil {
+		glog.Warningf("Failed to get map leaves: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := make([]*trillian.MapLeaf, 0, len(indexes))
+	for rows.Next() {
+		var mapKeyHash, flatData []byte
+		if err := rows.Scan(&mapKeyHash, &flatData); err != nil {
+			return nil, err
+		}
+		if len(flatData) == 0 {
+			continue // The key is deleted.
+		}
+		mapLeaf, err := unmarshalMapLeaf(flatData, mapKeyHash)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, mapLeaf)
+	}
+	return ret, rows.Err()
+}
+
+var _ storage.MapLeafScanner = &mapTreeTX{}
+
+// ScanLeaves calls fn with each leaf of the map at the given revision, in
+// increasing order of their indices. It implements storage.MapLeafScanner.
+func (m *mapTreeTX) ScanLeaves(ctx context.Context, revision int64, fn func(*trillian.MapLeaf) error) error {
+	after := []byte{}
+	for {
+		leaves, last, err := m.scanLeavesAfter(ctx, revision, after)
+		if err != nil {
+			return err
+		}
+		if last == nil {
+			return nil
+		}
+		for _, leaf := range leaves {
+			if err := fn(leaf); err != nil {
+				return err
+			}
+		}
+		after = last
+	}
+}
+
+// scanLeavesAfter returns the leaves of the map at the given revision whose
+// key hashes follow after, read with a single query, along with the last key
+// hash read. The latter is nil once the end of the map is reached. The leaves
+// can be empty before that, if all the keys read are deleted.
+func (m *mapTreeTX) scanLeavesAfter(ctx context.Context, revision int64, after []byte) ([]*trillian.MapLeaf, []byte, error) {
+	rows, err := m.tx.QueryContext(ctx, selectMapLeafScanSQL, m.treeID, after, revision, mapLeafScanBatchSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var ret []*trillian.MapLeaf
+	var last []byte
+	for rows.Next() {
+		var mapKeyHash, flatData []byte
+		if err := rows.Scan(&mapKeyHash, &flatData); err != nil {
+			return nil, nil, err
+		}
+		last = mapKeyHash
+		if len(flatData) == 0 {
+			continue // The key is deleted.
+		}
+		mapLeaf, err := unmarshalMapLeaf(flatData, mapKeyHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		ret = append(ret, mapLeaf)
+	}
+	return ret, last, rows.Err()
+}
+
+// GetTiles reads the Merkle tree tiles with the given root IDs at the given
+// revision. A tile is empty if it is missing from the returned slice.
+func (m *mapTreeTX) GetTiles(ctx context.Context, rev int64, ids []stree.NodeID2) ([]smt.Tile, error) {
+	subs, err := m.treeTX.getSubtrees(ctx, rev, ids)
+	if err != nil {
+		return nil, err
+	}
+	tiles := make([]smt.Tile, 0, len(subs))
+	for _, sub := range subs {
+		tile, err := convert.Unmarshal(sub)
+		if err != nil {
+			return nil, err
+		}
+		tiles = append(tiles, tile)
+	}
+	return tiles, nil
+}
+
+// SetTiles stores the given tiles at the current write revision.
+func (m *mapTreeTX) SetTiles(ctx context.Context, tiles []smt.Tile) error {
+	for _, tile := range tiles {
+		height := m.layout.TileHeight(int(tile.ID.BitLen()))
+		pb, err := convert.Marshal(tile, uint(height))
+		if err != nil {
+			return err
+		}
+		m.tiles = append(m.tiles, pb)
+	}
+	return nil
+}
+
+// Commit stores the tiles written by SetTiles, and commits the transaction.
+func (m *mapTreeTX) Commit(ctx context.Context) error {
+	if m.writeRevision > -1 {
+		for tiles := m.tiles; len(tiles) > 0; {
+			n := len(tiles)
+			if n > maxSubtreesPerInsert {
+				n = maxSubtreesPerInsert
+			}
+			if err := m.storeSubtrees(ctx, tiles[:n]); err != nil {
+				glog.Warningf("TX commit flush error: %v", err)
+				return err
+			}
+			tiles = tiles[n:]
+		}
+	}
+	return m.treeTX.Commit(ctx)
+}
+
+func unmarshalMapLeaf(marshaledLeaf, mapKeyHash []byte) (*trillian.MapLeaf, error) {
+	if len(marshaledLeaf) == 0 {
+		return nil, errors.New("len(marshaledLeaf): 0 want > 0")
+	}
+	marshaledLeaf, err := compress.Decompress(marshaledLeaf)
+	if err != nil {
+		return nil, err
+	}
+	var mapLeaf trillian.MapLeaf
+	if err := proto.Unmarshal(marshaledLeaf, &mapLeaf); err != nil {
+		return nil, err
+	}
+	mapLeaf.Index = mapKeyHash
+	return &mapLeaf, nil
+}
+
+func (m *mapTreeTX) GetSignedMapRoot(ctx context.Context, revision int64) (*trillian.SignedMapRoot, error) {
+	var timestamp, mapRevision int64
+	var rootHash, rootSignatureBytes []byte
+	var mapperMetaBytes []byte
+
+	err := m.tx.QueryRowContext(ctx, selectGetSignedMapRootSQL, m.treeID, revision).Scan(
+		&timestamp, &rootHash, &mapRevision, &rootSignatureBytes, &mapperMetaBytes)
+	if err != nil {
+		if revision == 0 {
+			return nil, storage.ErrTreeNeedsInit
+		}
+		return nil, err
+	}
+	if m.pinnedRoot == nil {
+		m.readRevision = mapRevision
+	}
+	return m.signedMapRoot(timestamp, mapRevision, rootHash, rootSignatureBytes, mapperMetaBytes)
+}
+
+func (m *mapTreeTX) LatestSignedMapRoot(ctx context.Context) (*trillian.SignedMapRoot, error) {
+	if m.pinnedRoot != nil {
+		return m.pinnedRoot, nil
+	}
+
+	var timestamp, mapRevision int64
+	var rootHash, rootSignatureBytes []byte
+	var mapperMetaBytes []byte
+
+	err := m.tx.QueryRowContext(ctx, selectLatestSignedMapRootSQL, m.treeID).Scan(
+		&timestamp, &rootHash, &mapRevision, &rootSignatureBytes, &mapperMetaBytes)
+
+	// It's possible there are no roots for this tree yet
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrTreeNeedsInit
+	} else if err != nil {
+		return nil, err
+	}
+	m.readRevision = mapRevision
+	return m.signedMapRoot(timestamp, mapRevision, rootHash, rootSignatureBytes, mapperMetaBytes)
+}
+
+func (m *mapTreeTX) signedMapRoot(timestamp, mapRevision int64, rootHash, rootSignature, mapperMeta []byte) (*trillian.SignedMapRoot, error) {
+	mapRoot, err := (&types.MapRootV1{
+		RootHash:       rootHash,
+		TimestampNanos: uint64(timestamp),
+		Revision:       uint64(mapRevision),
+		Metadata:       mapperMeta,
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &trillian.SignedMapRoot{
+		MapRoot:   mapRoot,
+		Signature: rootSignature,
+	}, nil
+}
+
+func (m *mapTreeTX) StoreSignedMapRoot(ctx context.Context, root *trillian.SignedMapRoot) error {
+	var r types.MapRootV1
+	if err := r.UnmarshalBinary(root.MapRoot); err != nil {
+		return err
+	}
+
+	stored, err := latestSignerEpoch(ctx, m.tx, selectMapHeadEpochSQL, m.treeID)
+	if err != nil {
+		return err
+	}
+	epoch, err := storage.CheckSignerEpoch(ctx, m.treeID, stored)
+	if err != nil {
+		return err
+	}
+
+	res, err := m.tx.ExecContext(ctx, insertMapHeadSQL, m.treeID, r.TimestampNanos, r.RootHash, r.Revision, root.Signature, r.Metadata, epoch)
+	if err != nil {
+		glog.Warningf("Failed to store signed map root: %s", err)
+	}
+	return checkResultOkAndRowCountIs(res, err, 1)
+}
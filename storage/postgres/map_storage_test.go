@@ -0,0 +1,245 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/integration/storagetest"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tcrypto "github.com/google/trillian/crypto"
+	storageto "github.com/google/trillian/storage/testonly"
+)
+
+func TestMapIntegration(t *testing.T) {
+	storageFactory := func(context.Context, *testing.T) (storage.MapStorage, storage.AdminStorage) {
+		cleanTestDB(db, t)
+		return NewMapStorage(db), NewAdminStorage(db)
+	}
+
+	storagetest.RunMapStorageTests(t, storageFactory)
+}
+
+func TestMapSetGetMultipleRevisions(t *testing.T) {
+	cleanTestDB(db, t)
+	ctx := context.Background()
+	s := NewMapStorage(db)
+	tree := createInitializedMapForTests(ctx, t, s)
+
+	key := []byte("A Key Hash")
+	leaves := []*trillian.MapLeaf{
+		{Index: key, LeafHash: []byte{0}, LeafValue: []byte{0}, ExtraData: []byte{0}},
+		{Index: key, LeafHash: []byte{1}, LeafValue: []byte{1}, ExtraData: []byte{1}},
+		{Index: key}, // Deletes the key.
+		{Index: key, LeafHash: []byte{3}, LeafValue: []byte{3}, ExtraData: []byte{3}},
+	}
+	for rev, leaf := range leaves {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = int64(rev)
+			if err := tx.Set(ctx, key, leaf); err != nil {
+				t.Fatalf("Set(%d): %v", rev, err)
+			}
+			return nil
+		})
+	}
+
+	for rev, leaf := range leaves {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			got, err := tx.Get(ctx, int64(rev), [][]byte{key, []byte("Unknown")})
+			if err != nil {
+				t.Fatalf("Get(%d): %v", rev, err)
+			}
+			var want []*trillian.MapLeaf
+			if !storage.IsMapLeafDeletion(leaf) {
+				want = []*trillian.MapLeaf{leaf}
+			}
+			if diff := cmp.Diff(got, want, cmp.Comparer(proto.Equal)); diff != "" {
+				t.Errorf("Get(%d) diff (-got +want):\n%s", rev, diff)
+			}
+			return nil
+		})
+	}
+}
+
+func TestMapSetSameKeyInSameRevisionFails(t *testing.T) {
+	cleanTestDB(db, t)
+	ctx := context.Background()
+	s := NewMapStorage(db)
+	tree := createInitializedMapForTests(ctx, t, s)
+
+	key := []byte("A Key Hash")
+	leaf := &trillian.MapLeaf{Index: key, LeafHash: []byte("A Hash"), LeafValue: []byte("A Value")}
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if err := tx.Set(ctx, key, leaf); err != nil {
+			t.Fatalf("Set(): %v", err)
+		}
+		if err := tx.Set(ctx, key, leaf); err == nil {
+			t.Error("Set() of the same key twice succeeded")
+		}
+		return nil
+	})
+}
+
+func TestMapScanLeaves(t *testing.T) {
+	cleanTestDB(db, t)
+	ctx := context.Background()
+	s := NewMapStorage(db)
+	tree := createInitializedMapForTests(ctx, t, s)
+
+	// More keys than fit in one scan batch, all of them written at revision 0,
+	// and every other one rewritten at revision 1.
+	keys := make([][]byte, mapLeafScanBatchSize+10)
+	for i := range keys {
+		h := sha256.Sum256([]byte(fmt.Sprintf("key %d", i)))
+		keys[i] = h[:]
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	for rev := int64(0); rev < 2; rev++ {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = rev
+			for i := int(rev); i < len(keys); i += int(rev) + 1 {
+				leaf := &trillian.MapLeaf{Index: keys[i], LeafHash: []byte{byte(rev)}, LeafValue: []byte(fmt.Sprintf("%d@%d", i, rev))}
+				if err := tx.Set(ctx, keys[i], leaf); err != nil {
+					t.Fatalf("Set(%x): %v", keys[i], err)
+				}
+			}
+			return nil
+		})
+	}
+
+	for rev := int64(0); rev < 3; rev++ {
+		var got []*trillian.MapLeaf
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			return tx.(storage.MapLeafScanner).ScanLeaves(ctx, rev, func(leaf *trillian.MapLeaf) error {
+				got = append(got, leaf)
+				return nil
+			})
+		})
+		var want []*trillian.MapLeaf
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			var err error
+			want, err = tx.Get(ctx, rev, keys)
+			return err
+		})
+		if diff := cmp.Diff(got, want, cmp.Comparer(proto.Equal)); diff != "" {
+			t.Errorf("ScanLeaves(%d) diff (-got +want):\n%s", rev, diff)
+		}
+	}
+}
+
+func TestMapCompression(t *testing.T) {
+	cleanTestDB(db, t)
+	ctx := context.Background()
+	s := NewMapStorage(db)
+	tree := createInitializedMapForTests(ctx, t, s)
+
+	// Each revision is written with another compression, as after the
+	// compression of the tree is changed, and all of them read back.
+	key := []byte("A Key Hash")
+	var leaves []*trillian.MapLeaf
+	for rev, c := range []trillian.MapCompression{
+		trillian.MapCompression_NO_MAP_COMPRESSION,
+		trillian.MapCompression_MAP_COMPRESSION_SNAPPY,
+		trillian.MapCompression_MAP_COMPRESSION_ZSTD,
+	} {
+		value := bytes.Repeat([]byte(fmt.Sprintf(`{"compression": %q}`, c)), 100)
+		leaf := &trillian.MapLeaf{Index: key, LeafHash: []byte{byte(rev)}, LeafValue: value}
+		leaves = append(leaves, leaf)
+		compressed := proto.Clone(tree).(*trillian.Tree)
+		compressed.MapCompression = c
+		runMapTX(ctx, s, compressed, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			tx.(*mapTreeTX).treeTX.writeRevision = int64(rev)
+			return tx.Set(ctx, key, leaf)
+		})
+	}
+
+	for rev, leaf := range leaves {
+		runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+			got, err := tx.Get(ctx, int64(rev), [][]byte{key})
+			if err != nil {
+				t.Fatalf("Get(%d): %v", rev, err)
+			}
+			if diff := cmp.Diff(got, []*trillian.MapLeaf{leaf}, cmp.Comparer(proto.Equal)); diff != "" {
+				t.Errorf("Get(%d) diff (-got +want):\n%s", rev, diff)
+			}
+			return nil
+		})
+	}
+}
+
+func TestMapWriteBatchRejected(t *testing.T) {
+	cleanTestDB(db, t)
+	ctx := context.Background()
+	s := NewMapStorage(db)
+	tree := createInitializedMapForTests(ctx, t, s)
+
+	err := s.ReadWriteTransaction(storage.WithMapWriteBatch(ctx, "a", 1), tree, func(ctx context.Context, tx storage.MapTreeTX) error {
+		t.Error("ReadWriteTransaction() ran the function of a map write batch")
+		return nil
+	})
+	if got, want := status.Code(err), codes.FailedPrecondition; got != want {
+		t.Errorf("ReadWriteTransaction(): %v, want code %v", err, want)
+	}
+}
+
+func TestGetSignedMapRootNotExist(t *testing.T) {
+	cleanTestDB(db, t)
+	ctx := context.Background()
+	s := NewMapStorage(db)
+	tree := createTreeOrPanic(db, storageto.MapTree) // Uninitialized: no revision 0 MapRoot exists.
+
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		if _, err := tx.GetSignedMapRoot(ctx, 0); err != storage.ErrTreeNeedsInit {
+			t.Errorf("GetSignedMapRoot(): %v, want %v", err, storage.ErrTreeNeedsInit)
+		}
+		return nil
+	})
+}
+
+func runMapTX(ctx context.Context, s storage.MapStorage, tree *trillian.Tree, t *testing.T, f storage.MapTXFunc) {
+	t.Helper()
+	if err := s.ReadWriteTransaction(ctx, tree, f); err != nil {
+		t.Fatalf("Failed to run map tx: %v", err)
+	}
+}
+
+func createInitializedMapForTests(ctx context.Context, t *testing.T, s storage.MapStorage) *trillian.Tree {
+	t.Helper()
+	tree := createTreeOrPanic(db, storageto.MapTree)
+
+	signer := tcrypto.NewSigner(tree.TreeId, testonly.NewSignerWithFixedSig(nil, []byte("sig")), crypto.SHA256)
+	root, err := signer.SignMapRoot(&types.MapRootV1{RootHash: []byte("rootHash")})
+	if err != nil {
+		t.Fatalf("SignMapRoot(): %v", err)
+	}
+	runMapTX(ctx, s, tree, t, func(ctx context.Context, tx storage.MapTreeTX) error {
+		return tx.StoreSignedMapRoot(ctx, root)
+	})
+	return tree
+}
@@ -22,40 +22,65 @@ import (
 	"github.com/golang/glog"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
-
-	// Load PG driver
-	_ "github.com/lib/pq"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/sqlcomment"
+	"github.com/lib/pq"
 )
 
+// commentedDriverName is the name of the Postgres driver which annotates
+// statements with sqlcommenter comments.
+const commentedDriverName = "trillian_postgres_sqlcomment"
+
 var (
 	pgConnStr         = flag.String("pg_conn_str", "user=postgres dbname=test port=5432 sslmode=disable", "Connection string for Postgres database")
+	pgMaxConns        = flag.Int("pg_max_conns", 0, "Maximum connections to the database")
+	pgMaxIdle         = flag.Int("pg_max_idle_conns", -1, "Maximum idle database connections in the connection pool")
+	pgMaxLife         = flag.Duration("pg_conn_max_lifetime", 0, "Maximum time a database connection may be reused for, 0 means no limit")
+	pgSharedSubtrees  = flag.Int("pg_shared_subtree_cache_size", 0, "If positive, the number of log subtrees cached across transactions, which saves reading the same subtrees of a log on every sequencing pass, and lets the signer pre-fetch the subtrees of logs it becomes master for")
+	pgComments        = flag.Bool("pg_sql_comments", false, "If true, SQL statements are annotated with comments identifying the trace, RPC method, request ID and tree they are issued for. Statements are then prepared for every execution rather than reused")
 	pgOnce            sync.Once
 	pgOnceErr         error
 	pgStorageInstance *pgProvider
 )
 
 func init() {
+	sql.Register(commentedDriverName, sqlcomment.WrapDriver(&pq.Driver{}))
 	if err := storage.RegisterProvider("postgres", newPGProvider); err != nil {
 		glog.Fatalf("Failed to register storage provider postgres: %v", err)
 	}
 }
 
 type pgProvider struct {
-	db *sql.DB
-	mf monitoring.MetricFactory
+	db          *sql.DB
+	mf          monitoring.MetricFactory
+	stopMonitor func()
+	logOpts     LogStorageOptions
 }
 
 func newPGProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
 	pgOnce.Do(func() {
 		var db *sql.DB
-		db, pgOnceErr = OpenDB(*pgConnStr)
+		driverName := "postgres"
+		if *pgComments {
+			driverName = commentedDriverName
+		}
+		db, pgOnceErr = openDB(driverName, *pgConnStr)
 		if pgOnceErr != nil {
 			return
 		}
+		storage.SQLPoolConfig{
+			MaxOpenConns:    *pgMaxConns,
+			MaxIdleConns:    *pgMaxIdle,
+			ConnMaxLifetime: *pgMaxLife,
+		}.Apply(db)
 
 		pgStorageInstance = &pgProvider{
-			db: db,
-			mf: mf,
+			db:          db,
+			mf:          mf,
+			stopMonitor: storage.MonitorSQLPool(db, "postgres", mf, storage.SQLPoolStatsInterval),
+		}
+		if *pgSharedSubtrees > 0 {
+			pgStorageInstance.logOpts.SharedSubtrees = cache.NewSharedLogSubtrees(*pgSharedSubtrees, mf)
 		}
 	})
 	if pgOnceErr != nil {
@@ -66,11 +91,12 @@ func newPGProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
 
 func (s *pgProvider) LogStorage() storage.LogStorage {
 	glog.Warningf("Support for the PostgreSQL log is experimental.  Please use at your own risk!!!")
-	return NewLogStorage(s.db, s.mf)
+	return NewLogStorageWithOpts(s.db, s.mf, s.logOpts)
 }
 
 func (s *pgProvider) MapStorage() storage.MapStorage {
-	panic("Not Implemented")
+	glog.Warningf("Support for the PostgreSQL map is experimental.  Please use at your own risk!!!")
+	return NewMapStorage(s.db)
 }
 
 func (s *pgProvider) AdminStorage() storage.AdminStorage {
@@ -78,5 +104,6 @@ func (s *pgProvider) AdminStorage() storage.AdminStorage {
 }
 
 func (s *pgProvider) Close() error {
+	s.stopMonitor()
 	return s.db.Close()
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/util/requestid"
 )
 
 const (
@@ -36,8 +37,9 @@ const (
                         AND bucket=0
                         AND queue_timestamp_nanos<=$2
                         ORDER BY queue_timestamp_nanos,leaf_identity_hash ASC LIMIT $3`
-	insertUnsequencedEntrySQL = "select insert_leaf_data_ignore_duplicates($1,$2,$3,$4)"
-	deleteUnsequencedSQL      = "DELETE FROM unsequenced WHERE tree_id = $1 and bucket=0 and queue_timestamp_nanos = $2 and leaf_identity_hash=$3"
+	insertUnsequencedEntrySQL     = "select insert_leaf_data_ignore_duplicates($1,$2,$3,$4)"
+	crdbInsertUnsequencedEntrySQL = "INSERT INTO unsequenced(tree_id,bucket,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos) VALUES($1,0,$2,$3,$4) ON CONFLICT DO NOTHING"
+	deleteUnsequencedSQL          = "DELETE FROM unsequenced WHERE tree_id = $1 and bucket=0 and queue_timestamp_nanos = $2 and leaf_identity_hash=$3"
 )
 
 type dequeuedLeaf struct {
@@ -75,6 +77,15 @@ func (t *logTreeTX) dequeueLeaf(rows *sql.Rows) (*trillian.LogLeaf, dequeuedLeaf
 	return leaf, dequeueInfo(leafIDHash, queueTimestamp), nil
 }
 
+// unsequencedEntrySQL returns the statement which queues a leaf in the given
+// dialect, ignoring duplicates.
+func unsequencedEntrySQL(d Dialect) string {
+	if d == CockroachDBDialect {
+		return crdbInsertUnsequencedEntrySQL
+	}
+	return insertUnsequencedEntrySQL
+}
+
 func queueArgs(_ int64, _ []byte, queueTimestamp time.Time) []interface{} {
 	return []interface{}{queueTimestamp.UnixNano()}
 }
@@ -99,7 +110,7 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 			leaf.LeafIndex,
 			iTimestamp.UnixNano())
 		if err != nil {
-			glog.Warningf("Failed to update sequenced leaves: %s", err)
+			glog.Warningf("%sFailed to update sequenced leaves: %s", requestid.Prefix(ctx), err)
 			return err
 		}
 	}
@@ -115,7 +126,7 @@ func (t *logTreeTX) removeSequencedLeaves(ctx context.Context, leaves []dequeued
 	// QueueLeaves.
 	stx, err := t.tx.PrepareContext(ctx, deleteUnsequencedSQL)
 	if err != nil {
-		glog.Warningf("Failed to prep delete statement for sequenced work: %v", err)
+		glog.Warningf("%sFailed to prep delete statement for sequenced work: %v", requestid.Prefix(ctx), err)
 		return err
 	}
 	for _, dql := range leaves {
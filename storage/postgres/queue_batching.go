@@ -28,6 +28,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/util/requestid"
 )
 
 const (
@@ -87,6 +88,12 @@ func generateQueueID(treeID int64, leafIdentityHash []byte, timestamp int64) []b
 	return h.Sum(nil)
 }
 
+// unsequencedEntrySQL returns the statement which queues a leaf, which is the
+// same in all dialects.
+func unsequencedEntrySQL(Dialect) string {
+	return insertUnsequencedEntrySQL
+}
+
 func queueArgs(treeID int64, identityHash []byte, queueTimestamp time.Time) []interface{} {
 	timestamp := queueTimestamp.UnixNano()
 	return []interface{}{timestamp, generateQueueID(treeID, identityHash, timestamp)}
@@ -105,7 +112,7 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 	}
 	result, err := t.tx.ExecContext(ctx, insertSequencedLeafSQL+strings.Join(querySuffix, ","), args...)
 	if err != nil {
-		glog.Warningf("Failed to update sequenced leaves: %s", err)
+		glog.Warningf("%sFailed to update sequenced leaves: %s", requestid.Prefix(ctx), err)
 	}
 	return checkResultOkAndRowCountIs(result, err, int64(len(leaves)))
 }
@@ -130,7 +137,7 @@ func (t *logTreeTX) removeSequencedLeaves(ctx context.Context, queueIDs []dequeu
 	// QueueLeaves.
 	tmpl, err := t.ls.getDeleteUnsequencedStmt(ctx, len(queueIDs))
 	if err != nil {
-		glog.Warningf("Failed to get delete statement for sequenced work: %s", err)
+		glog.Warningf("%sFailed to get delete statement for sequenced work: %s", requestid.Prefix(ctx), err)
 		return err
 	}
 	stx := t.tx.StmtContext(ctx, tmpl)
@@ -141,7 +148,7 @@ func (t *logTreeTX) removeSequencedLeaves(ctx context.Context, queueIDs []dequeu
 	result, err := stx.ExecContext(ctx, args...)
 	if err != nil {
 		// Error is handled by checkResultOkAndRowCountIs() below
-		glog.Warningf("Failed to delete sequenced work: %s", err)
+		glog.Warningf("%sFailed to delete sequenced work: %s", requestid.Prefix(ctx), err)
 	}
 	return checkResultOkAndRowCountIs(result, err, int64(len(queueIDs)))
 }
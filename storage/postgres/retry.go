@@ -0,0 +1,118 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/backoff"
+	"github.com/google/trillian/storage"
+)
+
+// retrier retries transactions which fail as they conflict with other ones,
+// as distributed databases compatible with Postgres report far more often
+// than Postgres does.
+type retrier struct {
+	// retries is the number of times a transaction is retried.
+	retries int
+	// retryable returns whether err reports a conflict between transactions,
+	// after which the failed transaction can be retried.
+	retryable func(err error) bool
+}
+
+// retry calls f until it succeeds, fails with an error which isn't retryable,
+// or has been retried the given number of times.
+func (r retrier) retry(ctx context.Context, f func() error) error {
+	b := &backoff.Backoff{
+		Min:    10 * time.Millisecond,
+		Max:    time.Second,
+		Factor: 2,
+		Jitter: true,
+	}
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= r.retries || !r.retryable(err) {
+			return err
+		}
+		glog.V(1).Infof("Retrying transaction after conflict: %v", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(b.Duration()):
+		}
+	}
+}
+
+// retryingLogStorage is a LogStorage which retries the transactions it runs
+// when they conflict with other ones.
+type retryingLogStorage struct {
+	storage.LogStorage
+	retrier
+}
+
+func (s *retryingLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	return s.retry(ctx, func() error {
+		return s.LogStorage.ReadWriteTransaction(ctx, tree, f)
+	})
+}
+
+func (s *retryingLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	var ret []*trillian.QueuedLogLeaf
+	err := s.retry(ctx, func() error {
+		var err error
+		ret, err = s.LogStorage.QueueLeaves(ctx, tree, leaves, queueTimestamp)
+		return err
+	})
+	return ret, err
+}
+
+func (s *retryingLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	var ret []*trillian.QueuedLogLeaf
+	err := s.retry(ctx, func() error {
+		var err error
+		ret, err = s.LogStorage.AddSequencedLeaves(ctx, tree, leaves, timestamp)
+		return err
+	})
+	return ret, err
+}
+
+// retryingMapStorage is a MapStorage which retries read-write transactions
+// when they conflict with other ones.
+type retryingMapStorage struct {
+	storage.MapStorage
+	retrier
+}
+
+func (s *retryingMapStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+	return s.retry(ctx, func() error {
+		return s.MapStorage.ReadWriteTransaction(ctx, tree, f)
+	})
+}
+
+// retryingAdminStorage is an AdminStorage which retries read-write
+// transactions when they conflict with other ones.
+type retryingAdminStorage struct {
+	storage.AdminStorage
+	retrier
+}
+
+func (s *retryingAdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	return s.retry(ctx, func() error {
+		return s.AdminStorage.ReadWriteTransaction(ctx, f)
+	})
+}
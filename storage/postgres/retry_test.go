@@ -0,0 +1,55 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	conflict := errors.New("conflict")
+	other := errors.New("other")
+	for _, test := range []struct {
+		desc      string
+		errs      []error
+		retries   int
+		wantCalls int
+		wantErr   error
+	}{
+		{desc: "ok", errs: []error{nil}, retries: 3, wantCalls: 1},
+		{desc: "retried", errs: []error{conflict, conflict, nil}, retries: 3, wantCalls: 3},
+		{desc: "exhausted", errs: []error{conflict, conflict, conflict}, retries: 2, wantCalls: 3, wantErr: conflict},
+		{desc: "not-retryable", errs: []error{other, nil}, retries: 3, wantCalls: 1, wantErr: other},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			calls := 0
+			r := retrier{retries: test.retries, retryable: func(err error) bool { return err == conflict }}
+			err := r.retry(ctx, func() error {
+				err := test.errs[calls]
+				calls++
+				return err
+			})
+			if err != test.wantErr {
+				t.Errorf("retry()=%v, want %v", err, test.wantErr)
+			}
+			if calls != test.wantCalls {
+				t.Errorf("retry() made %d calls, want %d", calls, test.wantCalls)
+			}
+		})
+	}
+}
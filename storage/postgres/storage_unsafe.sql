@@ -6,7 +6,7 @@
 -- Tree Enums
 CREATE TYPE E_TREE_STATE AS ENUM('ACTIVE', 'FROZEN', 'DRAINING');
 CREATE TYPE E_TREE_TYPE AS ENUM('LOG', 'MAP', 'PREORDERED_LOG');
-CREATE TYPE E_HASH_STRATEGY AS ENUM('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256', 'CONIKS_SHA256');
+CREATE TYPE E_HASH_STRATEGY AS ENUM('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256', 'CONIKS_SHA256', 'CUSTOM_MAP_HASHER');
 CREATE TYPE E_HASH_ALGORITHM AS ENUM('SHA256');
 CREATE TYPE E_SIGNATURE_ALGORITHM AS ENUM('ECDSA', 'RSA');
 
@@ -28,6 +28,30 @@ CREATE TABLE IF NOT EXISTS trees (
   public_key               BYTEA NOT NULL,
   deleted                  BOOLEAN NOT NULL DEFAULT FALSE,
   delete_time_millis       BIGINT,
+  -- Serialized TreeRateLimits proto, or NULL if the tree has no rate limits.
+  rate_limits              BYTEA,
+  -- Labels of the tree as a JSON object, or NULL if the tree has no labels.
+  labels                   TEXT,
+  -- ID of the CreateTree request which created the tree, or NULL if none.
+  create_request_id        VARCHAR(128) UNIQUE,
+  -- Maintenance mode of the tree as a serialized TreeMaintenance, or NULL if
+  -- the tree is not in maintenance.
+  maintenance              BYTEA,
+  -- Map strata of the tree as a JSON array, or NULL if it uses the default.
+  map_strata               TEXT,
+  -- MapCompression of the leaves and tiles of the tree, or NULL if none.
+  map_compression          VARCHAR(32),
+  -- Name of the registered hasher of a CUSTOM_MAP_HASHER map, or NULL.
+  map_hasher               VARCHAR(255),
+  -- Whether the first write to the map initializes it.
+  auto_init                BOOLEAN NOT NULL DEFAULT FALSE,
+  -- Maximum bytes of storage written for the tree, or 0 for no limit.
+  max_storage_bytes        BIGINT NOT NULL DEFAULT 0,
+  -- Whether the keys of the leaves of the map are indexed.
+  map_key_index            BOOLEAN NOT NULL DEFAULT FALSE,
+  -- Public keys the tree's key was rotated away from, as a serialized Tree
+  -- with only retired_public_keys set, or NULL if it was never rotated.
+  retired_public_keys      BYTEA,
   current_tree_data        json,
   root_signature	   BYTEA,
   PRIMARY KEY(tree_id)
@@ -60,6 +84,7 @@ CREATE TABLE IF NOT EXISTS tree_head(
   root_hash              BYTEA NOT NULL,
   root_signature         BYTEA NOT NULL,
   tree_revision          BIGINT,
+  signer_epoch           BIGINT NOT NULL DEFAULT 0,
   PRIMARY KEY(tree_id, tree_revision)
 );
 
@@ -136,6 +161,31 @@ CREATE TABLE IF NOT EXISTS unsequenced(
   PRIMARY KEY (queue_timestamp_nanos, leaf_identity_hash)
 );
 
+-- ---------------------------------------------
+-- Map specific stuff here
+-- ---------------------------------------------
+
+CREATE TABLE IF NOT EXISTS map_leaf(
+  tree_id               BIGINT NOT NULL,
+  key_hash              BYTEA NOT NULL,
+  map_revision          BIGINT NOT NULL,
+  leaf_value            BYTEA NOT NULL,
+  PRIMARY KEY(key_hash, map_revision)
+);
+
+CREATE TABLE IF NOT EXISTS map_head(
+  tree_id               BIGINT NOT NULL,
+  map_head_timestamp    BIGINT,
+  root_hash             BYTEA NOT NULL,
+  map_revision          BIGINT,
+  root_signature        BYTEA NOT NULL,
+  mapper_data           BYTEA,
+  signer_epoch          BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY(map_head_timestamp)
+);
+
+CREATE UNIQUE INDEX MapHeadRevisionIdx ON map_head(tree_id, map_revision);
+
 CREATE OR REPLACE FUNCTION public.insert_leaf_data_ignore_duplicates(tree_id bigint, leaf_identity_hash bytea, leaf_value bytea, extra_data bytea, queue_timestamp_nanos bigint)
  RETURNS boolean
  LANGUAGE plpgsql
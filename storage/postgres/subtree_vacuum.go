@@ -0,0 +1,61 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// deleteSupersededSubtreesSQL deletes the revisions of subtrees which are
+// older than the latest revision of the same subtree at or below a horizon.
+const deleteSupersededSubtreesSQL = `DELETE FROM subtree d
+	USING (
+		SELECT s.subtree_id, s.subtree_revision
+		FROM subtree s
+		INNER JOIN (
+			SELECT subtree_id, MAX(subtree_revision) AS horizon
+			FROM subtree
+			WHERE tree_id=$1 AND subtree_revision<=$2
+			GROUP BY subtree_id
+		) h ON s.subtree_id=h.subtree_id
+		WHERE s.tree_id=$1 AND s.subtree_revision<h.horizon
+		LIMIT $3
+	) v
+	WHERE d.tree_id=$1 AND d.subtree_id=v.subtree_id AND d.subtree_revision=v.subtree_revision`
+
+var _ storage.SubtreeVacuumTX = &logTreeTX{}
+
+// VacuumSubtrees deletes superseded revisions of the log's subtrees.
+func (t *logTreeTX) VacuumSubtrees(ctx context.Context, horizon int64, limit int) (int, error) {
+	if t.treeType != trillian.TreeType_LOG && t.treeType != trillian.TreeType_PREORDERED_LOG {
+		return 0, status.Errorf(codes.FailedPrecondition, "only subtrees of logs can be vacuumed, got %v", t.treeType)
+	}
+	res, err := t.tx.ExecContext(ctx, deleteSupersededSubtreesSQL, t.treeID, horizon, limit)
+	if err != nil {
+		glog.Warningf("Failed to delete superseded subtrees: %s", err)
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/golang/protobuf/proto" //nolint:staticcheck
 	"github.com/google/trillian"
 	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/compress"
 	"github.com/google/trillian/storage/storagepb"
 	"github.com/google/trillian/storage/tree"
 )
@@ -51,8 +52,11 @@ const (
 		ON subtree.subtree_id = x.subtree_id
 		AND subtree.subtree_revision = x.max_revision
 		AND subtree.tree_id = <param>`
-	insertTreeHeadSQL = `INSERT INTO tree_head(tree_id,tree_head_timestamp,tree_size,root_hash,tree_revision,root_signature)
-                 VALUES($1,$2,$3,$4,$5,$6)`
+	insertTreeHeadSQL = `INSERT INTO tree_head(tree_id,tree_head_timestamp,tree_size,root_hash,tree_revision,root_signature,signer_epoch)
+                 VALUES($1,$2,$3,$4,$5,$6,$7)`
+	lockTreeSQL            = "SELECT tree_id FROM trees WHERE tree_id=$1 FOR UPDATE"
+	selectTreeHeadEpochSQL = `SELECT signer_epoch FROM tree_head WHERE tree_id=$1
+		 ORDER BY tree_revision DESC LIMIT 1`
 )
 
 // pgTreeStorage contains the pgLogStorage implementation.
@@ -69,7 +73,11 @@ type pgTreeStorage struct {
 
 // OpenDB opens a database connection for all PG-based storage implementations.
 func OpenDB(connStr string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", connStr)
+	return openDB("postgres", connStr)
+}
+
+func openDB(driverName, connStr string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, connStr)
 	if err != nil {
 		// Don't log conn str as it could contain credentials.
 		glog.Warningf("Could not open Postgres database, check config: %s", err)
@@ -202,6 +210,7 @@ func (p *pgTreeStorage) beginTreeTx(ctx context.Context, tree *trillian.Tree, ha
 		treeID:        tree.TreeId,
 		treeType:      tree.TreeType,
 		hashSizeBytes: hashSizeBytes,
+		compression:   tree.MapCompression,
 		subtreeCache:  subtreeCache,
 		writeRevision: -1,
 	}, nil
@@ -214,12 +223,18 @@ type treeTX struct {
 	treeID        int64
 	treeType      trillian.TreeType
 	hashSizeBytes int
-	subtreeCache  *cache.SubtreeCache
+	// compression is the compression of the subtrees written by this
+	// transaction. Subtrees are read whatever their compression.
+	compression  trillian.MapCompression
+	subtreeCache *cache.SubtreeCache
+	// shared, if set, caches subtrees read by this and other transactions.
+	// It is only set for logs.
+	shared        *cache.SharedLogSubtrees
 	writeRevision int64
 }
 
-func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID tree.NodeID) (*storagepb.SubtreeProto, error) {
-	s, err := t.getSubtrees(ctx, treeRevision, []tree.NodeID{nodeID})
+func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID tree.NodeID2) (*storagepb.SubtreeProto, error) {
+	s, err := t.getSubtrees(ctx, treeRevision, []tree.NodeID2{nodeID})
 	if err != nil {
 		return nil, err
 	}
@@ -233,7 +248,7 @@ func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID tree
 	}
 }
 
-func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []tree.NodeID) ([]*storagepb.SubtreeProto, error) {
+func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
 	glog.V(4).Infof("getSubtrees(")
 	if len(nodeIDs) == 0 {
 		return nil, nil
@@ -285,6 +300,10 @@ func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []
 			glog.Warningf("Failed to scan merkle subtree: %s", err)
 			return nil, err
 		}
+		if nodesRaw, err = compress.Decompress(nodesRaw); err != nil {
+			glog.Warningf("Failed to decompress SubtreeProto: %s", err)
+			return nil, err
+		}
 		if err := proto.Unmarshal(nodesRaw, &subtree); err != nil {
 			glog.Warningf("Failed to unmarshal SubtreeProto: %s", err)
 			return nil, err
@@ -342,6 +361,9 @@ func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.Subtre
 		if err != nil {
 			return err
 		}
+		if subtreeBytes, err = compress.Compress(t.compression, subtreeBytes); err != nil {
+			return err
+		}
 		args = append(args, t.treeID)
 		args = append(args, st.Prefix)
 		args = append(args, subtreeBytes)
@@ -407,7 +429,7 @@ func (t *treeTX) GetMerkleNodes(ctx context.Context, treeRevision int64, nodeIDs
 func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
 	for _, n := range nodes {
 		err := t.subtreeCache.SetNodeHash(n.NodeID, n.Hash,
-			func(nID tree.NodeID) (*storagepb.SubtreeProto, error) {
+			func(nID tree.NodeID2) (*storagepb.SubtreeProto, error) {
 				return t.getSubtree(ctx, t.writeRevision, nID)
 			})
 		if err != nil {
@@ -421,11 +443,31 @@ func (t *treeTX) IsOpen() bool {
 	return !t.closed
 }
 
-// getSubtreesAtRev returns a GetSubtreesFunc which reads at the passed in rev.
+// getSubtreesAtRev returns a GetSubtreesFunc which reads at the passed in rev,
+// through the shared cache if there is one.
 func (t *treeTX) getSubtreesAtRev(ctx context.Context, rev int64) cache.GetSubtreesFunc {
-	return func(ids []tree.NodeID) ([]*storagepb.SubtreeProto, error) {
+	get := func(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
 		return t.getSubtrees(ctx, rev, ids)
 	}
+	if t.shared != nil {
+		return t.shared.Wrap(t.treeID, rev, get)
+	}
+	return get
+}
+
+// latestSignerEpoch returns the signer epoch of the latest root of the tree,
+// or 0 if it has none, read by the given query. The row of the tree is locked
+// first, so that other signers can't store a root until the transaction
+// ends, and the query, which gets a fresh snapshot, sees any they stored.
+func latestSignerEpoch(ctx context.Context, tx *sql.Tx, query string, treeID int64) (int64, error) {
+	if _, err := tx.ExecContext(ctx, lockTreeSQL, treeID); err != nil {
+		return 0, err
+	}
+	var epoch int64
+	if err := tx.QueryRowContext(ctx, query, treeID).Scan(&epoch); err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+	return epoch, nil
 }
 
 func checkResultOkAndRowCountIs(res sql.Result, err error, count int64) error {
@@ -452,14 +494,12 @@ func checkResultOkAndRowCountIs(res sql.Result, err error, count int64) error {
 // subtreeKey returns a non-nil []byte suitable for use as a primary key column
 // for the subtree rooted at the passed-in node ID. Returns an error if the ID
 // is not aligned to bytes.
-func subtreeKey(id tree.NodeID) ([]byte, error) {
+func subtreeKey(id tree.NodeID2) ([]byte, error) {
 	// TODO(pavelkalinnikov): Extend this check to verify strata boundaries.
-	if id.PrefixLenBits%8 != 0 {
-		return nil, fmt.Errorf("invalid subtree ID - not multiple of 8: %d", id.PrefixLenBits)
-	}
-	// The returned slice must not be nil, as it would correspond to NULL in SQL.
-	if bytes := id.Path; bytes != nil {
-		return bytes[:id.PrefixLenBits/8], nil
+	tileID, err := tree.NewTileID(id)
+	if err != nil {
+		return nil, err
 	}
-	return []byte{}, nil
+	// The returned slice is not nil, as that would correspond to NULL in SQL.
+	return tileID.AsBytes(), nil
 }
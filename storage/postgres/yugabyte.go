@@ -0,0 +1,95 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"errors"
+	"flag"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/lib/pq"
+)
+
+var ybRetries = flag.Int("yugabyte_retries", 3, "Number of times transactions are retried after conflicting with other transactions in the yugabyte storage")
+
+// yugabyteConflicts are fragments of the messages of the errors YugabyteDB
+// returns when a transaction conflicts with another one, which are matched
+// when the *pq.Error has been formatted into another error.
+var yugabyteConflicts = []string{
+	"restart read required",
+	"could not serialize access",
+	"conflicts with higher priority transaction",
+	"transaction aborted",
+	"deadlock detected",
+}
+
+func init() {
+	if err := storage.RegisterProvider("yugabyte", newYugabyteProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider yugabyte: %v", err)
+	}
+}
+
+// yugabyteProvider is a storage.Provider for YugabyteDB, which uses the
+// Postgres storage through YSQL, and retries transactions which conflict with
+// other ones. YugabyteDB reports such conflicts far more often than Postgres
+// does, as it detects them optimistically.
+type yugabyteProvider struct {
+	*pgProvider
+	r retrier
+}
+
+func newYugabyteProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+	p, err := newPGProvider(mf)
+	if err != nil {
+		return nil, err
+	}
+	r := retrier{retries: *ybRetries, retryable: isRetryableYugabyteErr}
+	return &yugabyteProvider{pgProvider: p.(*pgProvider), r: r}, nil
+}
+
+func (s *yugabyteProvider) LogStorage() storage.LogStorage {
+	return &retryingLogStorage{LogStorage: NewLogStorageWithOpts(s.db, s.mf, s.logOpts), retrier: s.r}
+}
+
+func (s *yugabyteProvider) MapStorage() storage.MapStorage {
+	return &retryingMapStorage{MapStorage: NewMapStorage(s.db), retrier: s.r}
+}
+
+func (s *yugabyteProvider) AdminStorage() storage.AdminStorage {
+	return &retryingAdminStorage{AdminStorage: NewAdminStorage(s.db), retrier: s.r}
+}
+
+// isRetryableYugabyteErr returns whether err reports a conflict between
+// transactions, after which the failed transaction can be retried.
+func isRetryableYugabyteErr(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "serialization_failure", "deadlock_detected":
+			return true
+		}
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, c := range yugabyteConflicts {
+		if strings.Contains(msg, c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage/cache"
+	"github.com/lib/pq"
+)
+
+func TestIsRetryableYugabyteErr(t *testing.T) {
+	for _, test := range []struct {
+		desc string
+		err  error
+		want bool
+	}{
+		{desc: "serialization", err: &pq.Error{Code: "40001"}, want: true},
+		{desc: "deadlock", err: &pq.Error{Code: "40P01"}, want: true},
+		{desc: "wrapped", err: fmt.Errorf("commit: %w", &pq.Error{Code: "40001"}), want: true},
+		{desc: "unique", err: &pq.Error{Code: "23505", Message: "transaction aborted"}},
+		{desc: "formatted", err: fmt.Errorf("Unsequenced: %v", &pq.Error{Message: "Restart read required at: { read: 1 }"}), want: true},
+		{desc: "conflict", err: errors.New("pq: Operation failed. Try again: Conflicts with higher priority transaction"), want: true},
+		{desc: "other", err: errors.New("pq: relation \"trees\" does not exist")},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if got := isRetryableYugabyteErr(test.err); got != test.want {
+				t.Errorf("isRetryableYugabyteErr(%v)=%v, want %v", test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestYugabyteLogStorageOpts(t *testing.T) {
+	shared := cache.NewSharedLogSubtrees(10, monitoring.InertMetricFactory{})
+	p := &yugabyteProvider{pgProvider: &pgProvider{logOpts: LogStorageOptions{SharedSubtrees: shared}}}
+	ls := p.LogStorage().(*retryingLogStorage).LogStorage.(*postgresLogStorage)
+	if ls.opts.SharedSubtrees != shared {
+		t.Errorf("LogStorage() doesn't use the shared subtrees of the provider")
+	}
+	if got, want := ls.opts.Dialect, PostgresDialect; got != want {
+		t.Errorf("LogStorage() dialect: %v, want %v", got, want)
+	}
+}
@@ -46,9 +46,8 @@ func RegisterProvider(name string, sp NewProviderFunc) error {
 // NewProvider returns a new Provider instance of the type specified by name.
 func NewProvider(name string, mf monitoring.MetricFactory) (Provider, error) {
 	spMu.RLock()
-	defer spMu.RUnlock()
-
 	sp := spByName[name]
+	spMu.RUnlock()
 	if sp == nil {
 		return nil, fmt.Errorf("no such storage provider %v", name)
 	}
@@ -0,0 +1,274 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// adminStorage is a storage.AdminStorage which combines the trees of all the
+// backends of a Provider.
+type adminStorage struct {
+	p *Provider
+}
+
+// Snapshot starts a read-only transaction, which starts transactions in the
+// backends as it needs to read from them.
+func (s *adminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	return &adminTX{
+		p:   s.p,
+		ctx: ctx,
+		txs: make([]storage.ReadOnlyAdminTX, len(s.p.backends)),
+	}, nil
+}
+
+// ReadWriteTransaction starts a transaction in every backend, and runs f with
+// them. The backend transactions are committed one after the other, so a
+// transaction which writes to several backends isn't atomic.
+func (s *adminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	n := len(s.p.backends)
+	tx := &adminTX{
+		p:   s.p,
+		ctx: ctx,
+		txs: make([]storage.ReadOnlyAdminTX, n),
+		rw:  make([]storage.AdminTX, n),
+	}
+	var run func(ctx context.Context, i int) error
+	run = func(ctx context.Context, i int) error {
+		if i == n {
+			return f(ctx, tx)
+		}
+		return s.p.admins[i].ReadWriteTransaction(ctx, func(ctx context.Context, btx storage.AdminTX) error {
+			tx.txs[i], tx.rw[i] = btx, btx
+			return run(ctx, i+1)
+		})
+	}
+	return run(ctx, 0)
+}
+
+// CheckDatabaseAccessible checks that all the backends are accessible.
+func (s *adminStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	for i, admin := range s.p.admins {
+		if err := admin.CheckDatabaseAccessible(ctx); err != nil {
+			return fmt.Errorf("storage backend %q: %v", s.p.backends[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// adminTX is a storage.AdminTX over transactions in the backends of a
+// Provider. Read-only transactions are started in the backends lazily.
+type adminTX struct {
+	p   *Provider
+	ctx context.Context
+	// txs holds the transactions started in the backends, by index.
+	txs []storage.ReadOnlyAdminTX
+	// rw holds the same transactions as txs, for read-write transactions.
+	rw     []storage.AdminTX
+	closed bool
+}
+
+// tx returns the transaction in the i-th backend, starting it if needed.
+func (t *adminTX) tx(i int) (storage.ReadOnlyAdminTX, error) {
+	if t.closed {
+		return nil, status.Error(codes.FailedPrecondition, "transaction is closed")
+	}
+	if t.txs[i] == nil {
+		tx, err := t.p.admins[i].Snapshot(t.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("storage backend %q: %v", t.p.backends[i].Name, err)
+		}
+		t.txs[i] = tx
+	}
+	return t.txs[i], nil
+}
+
+func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	_, tree, err := t.p.find(treeID, func(i int) (*trillian.Tree, error) {
+		tx, err := t.tx(i)
+		if err != nil {
+			return nil, err
+		}
+		return tx.GetTree(ctx, treeID)
+	})
+	return tree, err
+}
+
+func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	var ids []int64
+	for i := range t.p.backends {
+		tx, err := t.tx(i)
+		if err != nil {
+			return nil, err
+		}
+		bids, err := tx.ListTreeIDs(ctx, includeDeleted)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range bids {
+			t.p.remember(id, i)
+		}
+		ids = append(ids, bids...)
+	}
+	return ids, nil
+}
+
+func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	var trees []*trillian.Tree
+	for i := range t.p.backends {
+		tx, err := t.tx(i)
+		if err != nil {
+			return nil, err
+		}
+		btrees, err := tx.ListTrees(ctx, includeDeleted)
+		if err != nil {
+			return nil, err
+		}
+		for _, tree := range btrees {
+			t.p.remember(tree.TreeId, i)
+		}
+		trees = append(trees, btrees...)
+	}
+	return trees, nil
+}
+
+// GetTreeByCreateRequestID implements storage.CreateRequestIDReader, using
+// the lookup of each backend in turn.
+func (t *adminTX) GetTreeByCreateRequestID(ctx context.Context, requestID string) (*trillian.Tree, error) {
+	for i := range t.p.backends {
+		tx, err := t.tx(i)
+		if err != nil {
+			return nil, err
+		}
+		switch tree, err := storage.FindTreeByCreateRequestID(ctx, tx, requestID); status.Code(err) {
+		case codes.OK:
+			t.p.remember(tree.TreeId, i)
+			return tree, nil
+		case codes.NotFound:
+		default:
+			return nil, err
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "no tree was created with request ID %q", requestID)
+}
+
+// writer returns the transaction in the backend which stores a tree.
+func (t *adminTX) writer(ctx context.Context, treeID int64) (storage.AdminTX, error) {
+	if t.rw == nil {
+		return nil, status.Error(codes.FailedPrecondition, "read-only transaction")
+	}
+	i, _, err := t.p.find(treeID, func(i int) (*trillian.Tree, error) {
+		return t.rw[i].GetTree(ctx, treeID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t.rw[i], nil
+}
+
+func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	if t.rw == nil {
+		return nil, status.Error(codes.FailedPrecondition, "read-only transaction")
+	}
+	i, err := t.p.backendFor(tree)
+	if err != nil {
+		return nil, err
+	}
+	created, err := t.rw[i].CreateTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	t.p.remember(created.TreeId, i)
+	return created, nil
+}
+
+func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	tx, err := t.writer(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	return tx.UpdateTree(ctx, treeID, updateFunc)
+}
+
+func (t *adminTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	tx, err := t.writer(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	return tx.SoftDeleteTree(ctx, treeID)
+}
+
+func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
+	tx, err := t.writer(ctx, treeID)
+	if err != nil {
+		return err
+	}
+	if err := tx.HardDeleteTree(ctx, treeID); err != nil {
+		return err
+	}
+	t.p.forget(treeID)
+	return nil
+}
+
+func (t *adminTX) UndeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	tx, err := t.writer(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	return tx.UndeleteTree(ctx, treeID)
+}
+
+// Commit commits the transactions started in the backends.
+func (t *adminTX) Commit() error {
+	return t.end(storage.ReadOnlyAdminTX.Commit)
+}
+
+// Rollback rolls back the transactions started in the backends.
+func (t *adminTX) Rollback() error {
+	return t.end(storage.ReadOnlyAdminTX.Rollback)
+}
+
+func (t *adminTX) end(fn func(storage.ReadOnlyAdminTX) error) error {
+	t.closed = true
+	var firstErr error
+	for i, tx := range t.txs {
+		if tx == nil || tx.IsClosed() {
+			continue
+		}
+		if err := fn(tx); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("storage backend %q: %v", t.p.backends[i].Name, err)
+		}
+	}
+	return firstErr
+}
+
+func (t *adminTX) IsClosed() bool {
+	return t.closed
+}
+
+// Close rolls back the transactions started in the backends, unless the
+// transaction is already closed.
+func (t *adminTX) Close() error {
+	if t.closed {
+		return nil
+	}
+	return t.Rollback()
+}
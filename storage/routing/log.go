@@ -0,0 +1,139 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+)
+
+// logStorage is a storage.LogStorage which routes every log to the backend
+// of a Provider which stores it.
+type logStorage struct {
+	p *Provider
+}
+
+func (s *logStorage) backend(ctx context.Context, tree *trillian.Tree) (storage.LogStorage, error) {
+	i, err := s.p.locate(ctx, tree.TreeId)
+	if err != nil {
+		return nil, err
+	}
+	return s.p.logs[i], nil
+}
+
+// CheckDatabaseAccessible checks that all the backends are accessible.
+func (s *logStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	for i, ls := range s.p.logs {
+		if err := ls.CheckDatabaseAccessible(ctx); err != nil {
+			return fmt.Errorf("storage backend %q: %v", s.p.backends[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// Snapshot starts a read-only transaction in every backend.
+func (s *logStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
+	tx := &logTX{p: s.p}
+	for i, ls := range s.p.logs {
+		btx, err := ls.Snapshot(ctx)
+		if err != nil {
+			tx.Close()
+			return nil, fmt.Errorf("storage backend %q: %v", s.p.backends[i].Name, err)
+		}
+		tx.txs = append(tx.txs, btx)
+	}
+	return tx, nil
+}
+
+func (s *logStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	ls, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ls.SnapshotForTree(ctx, tree)
+}
+
+func (s *logStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	ls, err := s.backend(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return ls.ReadWriteTransaction(ctx, tree, f)
+}
+
+func (s *logStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	ls, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ls.QueueLeaves(ctx, tree, leaves, queueTimestamp)
+}
+
+func (s *logStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	ls, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ls.AddSequencedLeaves(ctx, tree, leaves, timestamp)
+}
+
+// logTX is a storage.ReadOnlyLogTX over read-only transactions in all the
+// backends of a Provider.
+type logTX struct {
+	p   *Provider
+	txs []storage.ReadOnlyLogTX
+}
+
+// GetActiveLogIDs returns the IDs of the active logs of all the backends.
+func (t *logTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	var ids []int64
+	for i, tx := range t.txs {
+		bids, err := tx.GetActiveLogIDs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("storage backend %q: %v", t.p.backends[i].Name, err)
+		}
+		for _, id := range bids {
+			t.p.remember(id, i)
+		}
+		ids = append(ids, bids...)
+	}
+	return ids, nil
+}
+
+func (t *logTX) Commit(ctx context.Context) error {
+	return t.end(func(tx storage.ReadOnlyLogTX) error { return tx.Commit(ctx) })
+}
+
+func (t *logTX) Rollback() error {
+	return t.end(storage.ReadOnlyLogTX.Rollback)
+}
+
+func (t *logTX) Close() error {
+	return t.end(storage.ReadOnlyLogTX.Close)
+}
+
+func (t *logTX) end(fn func(storage.ReadOnlyLogTX) error) error {
+	var firstErr error
+	for i, tx := range t.txs {
+		if err := fn(tx); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("storage backend %q: %v", t.p.backends[i].Name, err)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,208 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mapStorage is a storage.MapStorage which routes every map to the backend
+// of a Provider which stores it.
+type mapStorage struct {
+	p *Provider
+}
+
+func (s *mapStorage) backend(ctx context.Context, tree *trillian.Tree) (storage.MapStorage, error) {
+	i, err := s.p.locate(ctx, tree.TreeId)
+	if err != nil {
+		return nil, err
+	}
+	ms := s.p.maps[i]
+	if ms == nil {
+		return nil, status.Errorf(codes.Unimplemented, "storage backend %q doesn't support maps", s.p.backends[i].Name)
+	}
+	return ms, nil
+}
+
+// CheckDatabaseAccessible checks that all the backends which support maps are
+// accessible.
+func (s *mapStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	for i, ms := range s.p.maps {
+		if ms == nil {
+			continue
+		}
+		if err := ms.CheckDatabaseAccessible(ctx); err != nil {
+			return fmt.Errorf("storage backend %q: %v", s.p.backends[i].Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *mapStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyMapTreeTX, error) {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ms.SnapshotForTree(ctx, tree)
+}
+
+func (s *mapStorage) SnapshotAtRevision(ctx context.Context, tree *trillian.Tree, revision int64) (storage.ReadOnlyMapTreeTX, error) {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return ms.SnapshotAtRevision(ctx, tree, revision)
+}
+
+func (s *mapStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.MapTXFunc) error {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return ms.ReadWriteTransaction(ctx, tree, f)
+}
+
+// Layout returns the layout of the given tree, from the backend which stores
+// it. The tree is looked up without a deadline if it hasn't been seen yet.
+func (s *mapStorage) Layout(t *trillian.Tree) (*tree.Layout, error) {
+	ms, err := s.backend(context.Background(), t)
+	if err != nil {
+		return nil, err
+	}
+	return ms.Layout(t)
+}
+
+// QueueMapMutations implements storage.MapMutationQueue, by queueing the
+// mutations in the backend which stores the map.
+func (s *mapStorage) QueueMapMutations(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf, queueTimestamp time.Time) error {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return err
+	}
+	q, ok := ms.(storage.MapMutationQueue)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "map storage %T can't queue mutations", ms)
+	}
+	return q.QueueMapMutations(ctx, tree, leaves, queueTimestamp)
+}
+
+// bulkWriter returns the storage.MapBulkWriter of the backend which stores the
+// given map.
+func (s *mapStorage) bulkWriter(ctx context.Context, tree *trillian.Tree) (storage.MapBulkWriter, error) {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	w, ok := ms.(storage.MapBulkWriter)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "map storage %T can't write maps in bulk", ms)
+	}
+	return w, nil
+}
+
+// BeginBulkWrite implements storage.MapBulkWriter.
+func (s *mapStorage) BeginBulkWrite(ctx context.Context, tree *trillian.Tree) error {
+	w, err := s.bulkWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.BeginBulkWrite(ctx, tree)
+}
+
+// BulkWriteLeaves implements storage.MapBulkWriter.
+func (s *mapStorage) BulkWriteLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf) error {
+	w, err := s.bulkWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.BulkWriteLeaves(ctx, tree, leaves)
+}
+
+// BulkWriteTiles implements storage.MapBulkWriter.
+func (s *mapStorage) BulkWriteTiles(ctx context.Context, tree *trillian.Tree, tiles []smt.Tile) error {
+	w, err := s.bulkWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.BulkWriteTiles(ctx, tree, tiles)
+}
+
+// CommitBulkWrite implements storage.MapBulkWriter.
+func (s *mapStorage) CommitBulkWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	w, err := s.bulkWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.CommitBulkWrite(ctx, tree, root)
+}
+
+// snapshotWriter returns the storage.MapSnapshotWriter of the backend which
+// stores the given map.
+func (s *mapStorage) snapshotWriter(ctx context.Context, tree *trillian.Tree) (storage.MapSnapshotWriter, error) {
+	ms, err := s.backend(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	w, ok := ms.(storage.MapSnapshotWriter)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "map storage %T can't import map snapshots", ms)
+	}
+	return w, nil
+}
+
+// BeginSnapshotWrite implements storage.MapSnapshotWriter.
+func (s *mapStorage) BeginSnapshotWrite(ctx context.Context, tree *trillian.Tree, revision int64) error {
+	w, err := s.snapshotWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.BeginSnapshotWrite(ctx, tree, revision)
+}
+
+// SnapshotWriteLeaves implements storage.MapSnapshotWriter.
+func (s *mapStorage) SnapshotWriteLeaves(ctx context.Context, tree *trillian.Tree, revision int64, leaves []*trillian.MapLeaf) error {
+	w, err := s.snapshotWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.SnapshotWriteLeaves(ctx, tree, revision, leaves)
+}
+
+// SnapshotWriteTiles implements storage.MapSnapshotWriter.
+func (s *mapStorage) SnapshotWriteTiles(ctx context.Context, tree *trillian.Tree, revision int64, tiles []smt.Tile) error {
+	w, err := s.snapshotWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.SnapshotWriteTiles(ctx, tree, revision, tiles)
+}
+
+// CommitSnapshotWrite implements storage.MapSnapshotWriter.
+func (s *mapStorage) CommitSnapshotWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	w, err := s.snapshotWriter(ctx, tree)
+	if err != nil {
+		return err
+	}
+	return w.CommitSnapshotWrite(ctx, tree, root)
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+)
+
+// ProviderName is the name under which the routing storage provider is
+// registered.
+const ProviderName = "routing"
+
+var (
+	backendsFlag  = flag.String("routing_backends", "", "Comma-separated list of the storage providers which the routing storage provider serves trees from. New trees are created in the first one, unless --routing_tree_types routes them elsewhere")
+	treeTypesFlag = flag.String("routing_tree_types", "", "Comma-separated list of TREE_TYPE=provider pairs, which route new trees of the given types to one of --routing_backends")
+)
+
+func init() {
+	if err := storage.RegisterProvider(ProviderName, newRoutingProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider %v: %v", ProviderName, err)
+	}
+}
+
+func newRoutingProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+	names := strings.Split(*backendsFlag, ",")
+	if *backendsFlag == "" {
+		return nil, fmt.Errorf("--routing_backends must be set to use the %v storage provider", ProviderName)
+	}
+	byType, err := parseTreeTypes(*treeTypesFlag, names)
+	if err != nil {
+		return nil, err
+	}
+
+	var backends []Backend
+	closeAll := func() {
+		for _, b := range backends {
+			b.Close()
+		}
+	}
+	for _, name := range names {
+		if name == ProviderName {
+			closeAll()
+			return nil, fmt.Errorf("the %v storage provider can't route to itself", ProviderName)
+		}
+		sp, err := storage.NewProvider(name, mf)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to create storage backend %q: %v", name, err)
+		}
+		backends = append(backends, Backend{Name: name, Provider: sp})
+	}
+	p, err := NewProvider(backends, RouteByTreeType(byType, names[0]))
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	return p, nil
+}
+
+// parseTreeTypes parses a comma-separated list of TREE_TYPE=backend pairs,
+// where every backend must be one of names.
+func parseTreeTypes(s string, names []string) (map[trillian.TreeType]string, error) {
+	byType := make(map[trillian.TreeType]string)
+	if s == "" {
+		return byType, nil
+	}
+	known := make(map[string]bool)
+	for _, name := range names {
+		known[name] = true
+	}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid tree type route %q, want TREE_TYPE=provider", pair)
+		}
+		treeType, ok := trillian.TreeType_value[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("unknown tree type %q", parts[0])
+		}
+		if !known[parts[1]] {
+			return nil, fmt.Errorf("tree type %v routed to %q, which isn't one of --routing_backends", parts[0], parts[1])
+		}
+		byType[trillian.TreeType(treeType)] = parts[1]
+	}
+	return byType, nil
+}
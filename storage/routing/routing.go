@@ -0,0 +1,195 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package routing provides a storage.Provider which serves trees from several
+// storage backends, e.g. some trees from MySQL and some from CloudSpanner.
+//
+// Each tree, including its metadata, lives in a single backend. New trees are
+// created in the backend chosen by a RouteFunc from their metadata, and
+// existing trees are found by looking them up in every backend, which allows
+// trees to be moved between backends incrementally.
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Backend is a storage.Provider which trees can be routed to.
+type Backend struct {
+	// Name identifies the backend to a RouteFunc.
+	Name string
+	storage.Provider
+}
+
+// RouteFunc returns the name of the backend in which a new tree is created.
+type RouteFunc func(tree *trillian.Tree) (string, error)
+
+// RouteByTreeType returns a RouteFunc which routes new trees by their type,
+// and trees of other types to the backend named def.
+func RouteByTreeType(byType map[trillian.TreeType]string, def string) RouteFunc {
+	return func(tree *trillian.Tree) (string, error) {
+		if name, ok := byType[tree.TreeType]; ok {
+			return name, nil
+		}
+		return def, nil
+	}
+}
+
+// Provider is a storage.Provider which routes every tree to one of a number
+// of backends.
+type Provider struct {
+	backends []Backend
+	route    RouteFunc
+
+	admins []storage.AdminStorage
+	logs   []storage.LogStorage
+	maps   []storage.MapStorage
+
+	mu sync.RWMutex
+	// locations maps the IDs of the trees seen so far to the index of the
+	// backend which stores them.
+	locations map[int64]int
+}
+
+// NewProvider returns a Provider which routes trees to the given backends.
+// New trees are created in the backend named by route.
+func NewProvider(backends []Backend, route RouteFunc) (*Provider, error) {
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("no storage backends")
+	}
+	p := &Provider{
+		backends:  backends,
+		route:     route,
+		locations: make(map[int64]int),
+	}
+	seen := make(map[string]bool)
+	for _, b := range backends {
+		if seen[b.Name] {
+			return nil, fmt.Errorf("duplicate storage backend %q", b.Name)
+		}
+		seen[b.Name] = true
+		p.admins = append(p.admins, b.AdminStorage())
+		p.logs = append(p.logs, b.LogStorage())
+		p.maps = append(p.maps, b.MapStorage())
+	}
+	return p, nil
+}
+
+// AdminStorage returns an AdminStorage which combines the trees of all the
+// backends.
+func (p *Provider) AdminStorage() storage.AdminStorage {
+	return &adminStorage{p: p}
+}
+
+// LogStorage returns a LogStorage which routes every log to the backend
+// which stores it.
+func (p *Provider) LogStorage() storage.LogStorage {
+	return &logStorage{p: p}
+}
+
+// MapStorage returns a MapStorage which routes every map to the backend
+// which stores it.
+func (p *Provider) MapStorage() storage.MapStorage {
+	return &mapStorage{p: p}
+}
+
+// Close closes all the backends.
+func (p *Provider) Close() error {
+	var firstErr error
+	for _, b := range p.backends {
+		if err := b.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close storage backend %q: %v", b.Name, err)
+		}
+	}
+	return firstErr
+}
+
+// backendFor returns the index of the backend named by the route of a new
+// tree.
+func (p *Provider) backendFor(tree *trillian.Tree) (int, error) {
+	name, err := p.route(tree)
+	if err != nil {
+		return 0, err
+	}
+	for i, b := range p.backends {
+		if b.Name == name {
+			return i, nil
+		}
+	}
+	return 0, status.Errorf(codes.Internal, "tree routed to unknown storage backend %q", name)
+}
+
+// find returns the index of the backend which stores a tree, and the tree.
+// It reads the tree from the backend it was last seen in, if any, or else
+// tries each backend in turn, using get.
+func (p *Provider) find(treeID int64, get func(i int) (*trillian.Tree, error)) (int, *trillian.Tree, error) {
+	p.mu.RLock()
+	i, ok := p.locations[treeID]
+	p.mu.RUnlock()
+	if ok {
+		tree, err := get(i)
+		if status.Code(err) != codes.NotFound {
+			return i, tree, err
+		}
+		// The tree may have been moved to another backend.
+		p.forget(treeID)
+	}
+
+	for i := range p.backends {
+		tree, err := get(i)
+		switch {
+		case status.Code(err) == codes.NotFound:
+			continue
+		case err != nil:
+			return 0, nil, err
+		}
+		p.remember(treeID, i)
+		return i, tree, nil
+	}
+	return 0, nil, status.Errorf(codes.NotFound, "tree %v not found", treeID)
+}
+
+// locate returns the index of the backend which stores a tree. Unlike find,
+// it doesn't read the tree if it has been seen before.
+func (p *Provider) locate(ctx context.Context, treeID int64) (int, error) {
+	p.mu.RLock()
+	i, ok := p.locations[treeID]
+	p.mu.RUnlock()
+	if ok {
+		return i, nil
+	}
+	i, _, err := p.find(treeID, func(i int) (*trillian.Tree, error) {
+		return storage.GetTree(ctx, p.admins[i], treeID)
+	})
+	return i, err
+}
+
+func (p *Provider) remember(treeID int64, i int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.locations[treeID] = i
+}
+
+func (p *Provider) forget(treeID int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.locations, treeID)
+}
@@ -0,0 +1,429 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/smt"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	_ "github.com/google/trillian/crypto/keys/der/proto" // Register PrivateKey ProtoHandler
+	_ "github.com/google/trillian/storage/memory"        // Register memory storage provider
+)
+
+func newMemoryBackends(t *testing.T, names ...string) []Backend {
+	t.Helper()
+	var backends []Backend
+	for _, name := range names {
+		sp, err := storage.NewProvider("memory", nil)
+		if err != nil {
+			t.Fatalf("NewProvider(memory): %v", err)
+		}
+		backends = append(backends, Backend{Name: name, Provider: sp})
+	}
+	return backends
+}
+
+func TestProvider(t *testing.T) {
+	ctx := context.Background()
+	backends := newMemoryBackends(t, "a", "b")
+	route := RouteByTreeType(map[trillian.TreeType]string{trillian.TreeType_PREORDERED_LOG: "b"}, "a")
+	p, err := NewProvider(backends, route)
+	if err != nil {
+		t.Fatalf("NewProvider(): %v", err)
+	}
+	defer p.Close()
+
+	logTree, err := storage.CreateTree(ctx, p.AdminStorage(), testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(LOG): %v", err)
+	}
+	preorderedTree, err := storage.CreateTree(ctx, p.AdminStorage(), testonly.PreorderedLogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(PREORDERED_LOG): %v", err)
+	}
+
+	// Each tree must only exist in the backend it was routed to.
+	for _, test := range []struct {
+		tree    *trillian.Tree
+		backend int
+	}{
+		{tree: logTree, backend: 0},
+		{tree: preorderedTree, backend: 1},
+	} {
+		for i, b := range backends {
+			_, err := storage.GetTree(ctx, b.AdminStorage(), test.tree.TreeId)
+			if got, want := err == nil, i == test.backend; got != want {
+				t.Errorf("backend %v: GetTree(%v %v)=%v, want found=%v", b.Name, test.tree.TreeType, test.tree.TreeId, err, want)
+			}
+		}
+	}
+
+	// A new Provider over the same backends has to look the trees up.
+	for _, p := range []*Provider{p, mustNewProvider(t, backends, route)} {
+		for _, tree := range []*trillian.Tree{logTree, preorderedTree} {
+			got, err := storage.GetTree(ctx, p.AdminStorage(), tree.TreeId)
+			if err != nil {
+				t.Fatalf("GetTree(%v): %v", tree.TreeId, err)
+			}
+			if got.TreeId != tree.TreeId {
+				t.Errorf("GetTree(%v) returned tree %v", tree.TreeId, got.TreeId)
+			}
+		}
+		if _, err := storage.GetTree(ctx, p.AdminStorage(), 12345); status.Code(err) != codes.NotFound {
+			t.Errorf("GetTree(12345)=%v, want code %v", err, codes.NotFound)
+		}
+
+		trees, err := storage.ListTrees(ctx, p.AdminStorage(), false)
+		if err != nil {
+			t.Fatalf("ListTrees(): %v", err)
+		}
+		var ids []int64
+		for _, tree := range trees {
+			ids = append(ids, tree.TreeId)
+		}
+		sortIDs(ids)
+		if diff := cmp.Diff(ids, sortIDs([]int64{logTree.TreeId, preorderedTree.TreeId})); diff != "" {
+			t.Errorf("ListTrees() IDs diff (-got +want):\n%s", diff)
+		}
+	}
+}
+
+func TestProviderLogStorage(t *testing.T) {
+	ctx := context.Background()
+	backends := newMemoryBackends(t, "a", "b")
+	route := RouteByTreeType(map[trillian.TreeType]string{trillian.TreeType_PREORDERED_LOG: "b"}, "a")
+	p := mustNewProvider(t, backends, route)
+
+	var trees []*trillian.Tree
+	for _, tree := range []*trillian.Tree{testonly.LogTree, testonly.PreorderedLogTree} {
+		tree, err := storage.CreateTree(ctx, p.AdminStorage(), tree)
+		if err != nil {
+			t.Fatalf("CreateTree(): %v", err)
+		}
+		trees = append(trees, tree)
+	}
+
+	// Store roots through a fresh Provider, which has to look the trees up.
+	ls := mustNewProvider(t, backends, route).LogStorage()
+	for i, tree := range trees {
+		root, err := (&types.LogRootV1{TreeSize: uint64(i), RootHash: []byte{byte(i)}}).MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(): %v", err)
+		}
+		if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+		}); err != nil {
+			t.Fatalf("StoreSignedLogRoot(%v): %v", tree.TreeId, err)
+		}
+		hash := sha256.Sum256([]byte("value"))
+		leaf := &trillian.LogLeaf{LeafIdentityHash: hash[:], MerkleLeafHash: hash[:], LeafValue: []byte("value")}
+		if _, err := ls.QueueLeaves(ctx, tree, []*trillian.LogLeaf{leaf}, time.Now()); err != nil {
+			t.Fatalf("QueueLeaves(%v): %v", tree.TreeId, err)
+		}
+	}
+
+	// Each root must be read back from the backend of its tree.
+	for i, tree := range trees {
+		backendLS := backends[i].LogStorage()
+		tx, err := backendLS.SnapshotForTree(ctx, tree)
+		if err != nil {
+			t.Fatalf("backend %v: SnapshotForTree(%v): %v", backends[i].Name, tree.TreeId, err)
+		}
+		slr, err := tx.LatestSignedLogRoot(ctx)
+		tx.Close()
+		if err != nil {
+			t.Fatalf("backend %v: LatestSignedLogRoot(%v): %v", backends[i].Name, tree.TreeId, err)
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			t.Fatalf("UnmarshalBinary(): %v", err)
+		}
+		if got, want := root.TreeSize, uint64(i); got != want {
+			t.Errorf("backend %v: tree %v has root of size %d, want %d", backends[i].Name, tree.TreeId, got, want)
+		}
+	}
+
+	tx, err := ls.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot(): %v", err)
+	}
+	defer tx.Close()
+	ids, err := tx.GetActiveLogIDs(ctx)
+	if err != nil {
+		t.Fatalf("GetActiveLogIDs(): %v", err)
+	}
+	if diff := cmp.Diff(sortIDs(ids), sortIDs([]int64{trees[0].TreeId, trees[1].TreeId})); diff != "" {
+		t.Errorf("GetActiveLogIDs() diff (-got +want):\n%s", diff)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Errorf("Commit(): %v", err)
+	}
+
+	if _, err := ls.SnapshotForTree(ctx, &trillian.Tree{TreeId: 12345}); status.Code(err) != codes.NotFound {
+		t.Errorf("SnapshotForTree(12345)=%v, want code %v", err, codes.NotFound)
+	}
+}
+
+// mapProvider is a storage.Provider whose maps are stored in ms.
+type mapProvider struct {
+	storage.Provider
+	ms storage.MapStorage
+}
+
+func (p mapProvider) MapStorage() storage.MapStorage {
+	return p.ms
+}
+
+// plainMapStorage is a storage.MapStorage which supports none of the optional
+// map storage interfaces.
+type plainMapStorage struct {
+	storage.MapStorage
+}
+
+// writerMapStorage is a storage.MapStorage which records the writes made
+// outside of map transactions, as "method:treeID".
+type writerMapStorage struct {
+	storage.MapStorage
+	calls []string
+}
+
+func (s *writerMapStorage) record(method string, tree *trillian.Tree) error {
+	s.calls = append(s.calls, fmt.Sprintf("%s:%d", method, tree.TreeId))
+	return nil
+}
+
+func (s *writerMapStorage) QueueMapMutations(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf, queueTimestamp time.Time) error {
+	return s.record("QueueMapMutations", tree)
+}
+
+func (s *writerMapStorage) BeginBulkWrite(ctx context.Context, tree *trillian.Tree) error {
+	return s.record("BeginBulkWrite", tree)
+}
+
+func (s *writerMapStorage) BulkWriteLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.MapLeaf) error {
+	return s.record("BulkWriteLeaves", tree)
+}
+
+func (s *writerMapStorage) BulkWriteTiles(ctx context.Context, tree *trillian.Tree, tiles []smt.Tile) error {
+	return s.record("BulkWriteTiles", tree)
+}
+
+func (s *writerMapStorage) CommitBulkWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	return s.record("CommitBulkWrite", tree)
+}
+
+func (s *writerMapStorage) BeginSnapshotWrite(ctx context.Context, tree *trillian.Tree, revision int64) error {
+	return s.record("BeginSnapshotWrite", tree)
+}
+
+func (s *writerMapStorage) SnapshotWriteLeaves(ctx context.Context, tree *trillian.Tree, revision int64, leaves []*trillian.MapLeaf) error {
+	return s.record("SnapshotWriteLeaves", tree)
+}
+
+func (s *writerMapStorage) SnapshotWriteTiles(ctx context.Context, tree *trillian.Tree, revision int64, tiles []smt.Tile) error {
+	return s.record("SnapshotWriteTiles", tree)
+}
+
+func (s *writerMapStorage) CommitSnapshotWrite(ctx context.Context, tree *trillian.Tree, root *trillian.SignedMapRoot) error {
+	return s.record("CommitSnapshotWrite", tree)
+}
+
+// setupMapBackends returns two backends with the maps created by a Provider
+// over them: "a" supports the writes of writerMapStorage, while "b" supports
+// none of them. The map stored in each of them is returned, along with a
+// fresh Provider which has to look them up.
+func setupMapBackends(t *testing.T) (*writerMapStorage, []*trillian.Tree, *Provider) {
+	t.Helper()
+	ctx := context.Background()
+	w := &writerMapStorage{}
+	backends := newMemoryBackends(t, "a", "b")
+	backends[0].Provider = mapProvider{Provider: backends[0].Provider, ms: w}
+	backends[1].Provider = mapProvider{Provider: backends[1].Provider, ms: plainMapStorage{}}
+	route := func(tree *trillian.Tree) (string, error) {
+		return tree.DisplayName, nil
+	}
+	p := mustNewProvider(t, backends, route)
+
+	var trees []*trillian.Tree
+	for _, name := range []string{"a", "b"} {
+		tree := proto.Clone(testonly.MapTree).(*trillian.Tree)
+		tree.DisplayName = name
+		tree, err := storage.CreateTree(ctx, p.AdminStorage(), tree)
+		if err != nil {
+			t.Fatalf("CreateTree(%v): %v", name, err)
+		}
+		trees = append(trees, tree)
+	}
+	return w, trees, mustNewProvider(t, backends, route)
+}
+
+func TestProviderMapMutationQueue(t *testing.T) {
+	ctx := context.Background()
+	w, trees, p := setupMapBackends(t)
+	q, ok := p.MapStorage().(storage.MapMutationQueue)
+	if !ok {
+		t.Fatalf("MapStorage() is not a storage.MapMutationQueue")
+	}
+
+	if err := q.QueueMapMutations(ctx, trees[0], nil, time.Now()); err != nil {
+		t.Fatalf("QueueMapMutations(%v): %v", trees[0].TreeId, err)
+	}
+	if diff := cmp.Diff(w.calls, []string{fmt.Sprintf("QueueMapMutations:%d", trees[0].TreeId)}); diff != "" {
+		t.Errorf("backend a calls diff (-got +want):\n%s", diff)
+	}
+	if err := q.QueueMapMutations(ctx, trees[1], nil, time.Now()); status.Code(err) != codes.Unimplemented {
+		t.Errorf("QueueMapMutations(%v)=%v, want code %v", trees[1].TreeId, err, codes.Unimplemented)
+	}
+	if err := q.QueueMapMutations(ctx, &trillian.Tree{TreeId: 12345}, nil, time.Now()); status.Code(err) != codes.NotFound {
+		t.Errorf("QueueMapMutations(12345)=%v, want code %v", err, codes.NotFound)
+	}
+}
+
+func TestProviderMapBulkWriter(t *testing.T) {
+	ctx := context.Background()
+	w, trees, p := setupMapBackends(t)
+	bw, ok := p.MapStorage().(storage.MapBulkWriter)
+	if !ok {
+		t.Fatalf("MapStorage() is not a storage.MapBulkWriter")
+	}
+
+	write := func(tree *trillian.Tree) error {
+		if err := bw.BeginBulkWrite(ctx, tree); err != nil {
+			return err
+		}
+		if err := bw.BulkWriteLeaves(ctx, tree, nil); err != nil {
+			return err
+		}
+		if err := bw.BulkWriteTiles(ctx, tree, nil); err != nil {
+			return err
+		}
+		return bw.CommitBulkWrite(ctx, tree, &trillian.SignedMapRoot{})
+	}
+	if err := write(trees[0]); err != nil {
+		t.Fatalf("bulk write of %v: %v", trees[0].TreeId, err)
+	}
+	var want []string
+	for _, method := range []string{"BeginBulkWrite", "BulkWriteLeaves", "BulkWriteTiles", "CommitBulkWrite"} {
+		want = append(want, fmt.Sprintf("%s:%d", method, trees[0].TreeId))
+	}
+	if diff := cmp.Diff(w.calls, want); diff != "" {
+		t.Errorf("backend a calls diff (-got +want):\n%s", diff)
+	}
+	if err := write(trees[1]); status.Code(err) != codes.Unimplemented {
+		t.Errorf("bulk write of %v: %v, want code %v", trees[1].TreeId, err, codes.Unimplemented)
+	}
+}
+
+func TestProviderMapSnapshotWriter(t *testing.T) {
+	ctx := context.Background()
+	w, trees, p := setupMapBackends(t)
+	sw, ok := p.MapStorage().(storage.MapSnapshotWriter)
+	if !ok {
+		t.Fatalf("MapStorage() is not a storage.MapSnapshotWriter")
+	}
+
+	const rev = 5
+	write := func(tree *trillian.Tree) error {
+		if err := sw.BeginSnapshotWrite(ctx, tree, rev); err != nil {
+			return err
+		}
+		if err := sw.SnapshotWriteLeaves(ctx, tree, rev, nil); err != nil {
+			return err
+		}
+		if err := sw.SnapshotWriteTiles(ctx, tree, rev, nil); err != nil {
+			return err
+		}
+		return sw.CommitSnapshotWrite(ctx, tree, &trillian.SignedMapRoot{})
+	}
+	if err := write(trees[0]); err != nil {
+		t.Fatalf("snapshot write of %v: %v", trees[0].TreeId, err)
+	}
+	var want []string
+	for _, method := range []string{"BeginSnapshotWrite", "SnapshotWriteLeaves", "SnapshotWriteTiles", "CommitSnapshotWrite"} {
+		want = append(want, fmt.Sprintf("%s:%d", method, trees[0].TreeId))
+	}
+	if diff := cmp.Diff(w.calls, want); diff != "" {
+		t.Errorf("backend a calls diff (-got +want):\n%s", diff)
+	}
+	if err := write(trees[1]); status.Code(err) != codes.Unimplemented {
+		t.Errorf("snapshot write of %v: %v, want code %v", trees[1].TreeId, err, codes.Unimplemented)
+	}
+}
+
+func TestNewProviderErrors(t *testing.T) {
+	route := RouteByTreeType(nil, "a")
+	if _, err := NewProvider(nil, route); err == nil {
+		t.Error("NewProvider(no backends) succeeded, want error")
+	}
+	if _, err := NewProvider(newMemoryBackends(t, "a", "a"), route); err == nil {
+		t.Error("NewProvider(duplicate backends) succeeded, want error")
+	}
+}
+
+func TestParseTreeTypes(t *testing.T) {
+	names := []string{"mysql", "cloud_spanner"}
+	for _, test := range []struct {
+		in      string
+		want    map[trillian.TreeType]string
+		wantErr bool
+	}{
+		{in: "", want: map[trillian.TreeType]string{}},
+		{in: "LOG=cloud_spanner", want: map[trillian.TreeType]string{trillian.TreeType_LOG: "cloud_spanner"}},
+		{
+			in:   "LOG=cloud_spanner,MAP=mysql",
+			want: map[trillian.TreeType]string{trillian.TreeType_LOG: "cloud_spanner", trillian.TreeType_MAP: "mysql"},
+		},
+		{in: "LOG", wantErr: true},
+		{in: "TREE=mysql", wantErr: true},
+		{in: "LOG=postgres", wantErr: true},
+	} {
+		got, err := parseTreeTypes(test.in, names)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("parseTreeTypes(%q)=%v, want err=%v", test.in, err, test.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(got, test.want); !test.wantErr && diff != "" {
+			t.Errorf("parseTreeTypes(%q) diff (-got +want):\n%s", test.in, diff)
+		}
+	}
+}
+
+func mustNewProvider(t *testing.T, backends []Backend, route RouteFunc) *Provider {
+	t.Helper()
+	p, err := NewProvider(backends, route)
+	if err != nil {
+		t.Fatalf("NewProvider(): %v", err)
+	}
+	return p
+}
+
+func sortIDs(ids []int64) []int64 {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
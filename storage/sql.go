@@ -16,6 +16,7 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -56,10 +57,10 @@ func ReadTree(row Row) (*trillian.Tree, error) {
 	// Enums and Datetimes need an extra conversion step
 	var treeState, treeType, hashStrategy, hashAlgorithm, signatureAlgorithm string
 	var createMillis, updateMillis, maxRootDurationMillis int64
-	var displayName, description sql.NullString
-	var privateKey, publicKey []byte
-	var deleted sql.NullBool
-	var deleteMillis sql.NullInt64
+	var displayName, description, createRequestID, mapCompression, mapHasher sql.NullString
+	var privateKey, publicKey, rateLimits, labels, maintenance, mapStrata, retiredPublicKeys []byte
+	var deleted, autoInit, mapKeyIndex sql.NullBool
+	var deleteMillis, maxStorageBytes sql.NullInt64
 	err := row.Scan(
 		&tree.TreeId,
 		&treeState,
@@ -76,6 +77,17 @@ func ReadTree(row Row) (*trillian.Tree, error) {
 		&maxRootDurationMillis,
 		&deleted,
 		&deleteMillis,
+		&rateLimits,
+		&labels,
+		&createRequestID,
+		&maintenance,
+		&mapStrata,
+		&mapCompression,
+		&mapHasher,
+		&autoInit,
+		&maxStorageBytes,
+		&mapKeyIndex,
+		&retiredPublicKeys,
 	)
 	if err != nil {
 		return nil, err
@@ -140,6 +152,54 @@ func ReadTree(row Row) (*trillian.Tree, error) {
 	}
 	tree.PublicKey = &keyspb.PublicKey{Der: publicKey}
 
+	if len(rateLimits) > 0 {
+		tree.RateLimits = &trillian.TreeRateLimits{}
+		if err := proto.Unmarshal(rateLimits, tree.RateLimits); err != nil {
+			return nil, fmt.Errorf("could not unmarshal RateLimits: %v", err)
+		}
+	}
+
+	if len(labels) > 0 {
+		if err := json.Unmarshal(labels, &tree.Labels); err != nil {
+			return nil, fmt.Errorf("could not unmarshal Labels: %v", err)
+		}
+	}
+	SetNullStringIfValid(createRequestID, &tree.CreateRequestId)
+
+	if len(maintenance) > 0 {
+		tree.Maintenance = &trillian.TreeMaintenance{}
+		if err := proto.Unmarshal(maintenance, tree.Maintenance); err != nil {
+			return nil, fmt.Errorf("could not unmarshal Maintenance: %v", err)
+		}
+	}
+	if len(mapStrata) > 0 {
+		if err := json.Unmarshal(mapStrata, &tree.MapStrata); err != nil {
+			return nil, fmt.Errorf("could not unmarshal MapStrata: %v", err)
+		}
+	}
+	if mapCompression.Valid {
+		mc, ok := trillian.MapCompression_value[mapCompression.String]
+		if !ok {
+			return nil, fmt.Errorf("unknown MapCompression: %v", mapCompression.String)
+		}
+		tree.MapCompression = trillian.MapCompression(mc)
+	}
+	if mapHasher.Valid {
+		tree.MapHasher = mapHasher.String
+	}
+	tree.AutoInit = autoInit.Valid && autoInit.Bool
+	if maxStorageBytes.Valid {
+		tree.MaxStorageBytes = maxStorageBytes.Int64
+	}
+	tree.MapKeyIndex = mapKeyIndex.Valid && mapKeyIndex.Bool
+	if len(retiredPublicKeys) > 0 {
+		var keys trillian.Tree
+		if err := proto.Unmarshal(retiredPublicKeys, &keys); err != nil {
+			return nil, fmt.Errorf("could not unmarshal RetiredPublicKeys: %v", err)
+		}
+		tree.RetiredPublicKeys = keys.RetiredPublicKeys
+	}
+
 	tree.Deleted = deleted.Valid && deleted.Bool
 	if tree.Deleted && deleteMillis.Valid {
 		tree.DeleteTime, err = ptypes.TimestampProto(FromMillisSinceEpoch(deleteMillis.Int64))
@@ -150,3 +210,78 @@ func ReadTree(row Row) (*trillian.Tree, error) {
 
 	return tree, nil
 }
+
+// MarshalRateLimits returns the serialized rate limits of a tree, as stored
+// along with its other fields, or nil if it has none.
+func MarshalRateLimits(tree *trillian.Tree) ([]byte, error) {
+	if tree.RateLimits == nil {
+		return nil, nil
+	}
+	b, err := proto.Marshal(tree.RateLimits)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal RateLimits: %v", err)
+	}
+	return b, nil
+}
+
+// MarshalLabels returns the labels of a tree as a JSON object, as stored along
+// with its other fields, or nil if it has none.
+func MarshalLabels(tree *trillian.Tree) ([]byte, error) {
+	if len(tree.Labels) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(tree.Labels)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal Labels: %v", err)
+	}
+	return b, nil
+}
+
+// MarshalMaintenance returns the serialized maintenance of a tree, as stored
+// along with its other fields, or nil if it isn't in maintenance.
+func MarshalMaintenance(tree *trillian.Tree) ([]byte, error) {
+	if tree.Maintenance == nil {
+		return nil, nil
+	}
+	b, err := proto.Marshal(tree.Maintenance)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal Maintenance: %v", err)
+	}
+	return b, nil
+}
+
+// MarshalRetiredPublicKeys returns the serialized retired public keys of a
+// tree, as stored along with its other fields, or nil if it has none. They're
+// serialized as a Tree with only retired_public_keys set.
+func MarshalRetiredPublicKeys(tree *trillian.Tree) ([]byte, error) {
+	if len(tree.RetiredPublicKeys) == 0 {
+		return nil, nil
+	}
+	b, err := proto.Marshal(&trillian.Tree{RetiredPublicKeys: tree.RetiredPublicKeys})
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal RetiredPublicKeys: %v", err)
+	}
+	return b, nil
+}
+
+// MarshalMapStrata returns the map strata of a tree as a JSON array, as stored
+// along with its other fields, or nil if it has none.
+func MarshalMapStrata(tree *trillian.Tree) ([]byte, error) {
+	if len(tree.MapStrata) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(tree.MapStrata)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal MapStrata: %v", err)
+	}
+	return b, nil
+}
+
+// MarshalMapCompression returns the map compression of a tree as stored along
+// with its other fields, which is NULL if it has none.
+func MarshalMapCompression(tree *trillian.Tree) sql.NullString {
+	if tree.MapCompression == trillian.MapCompression_NO_MAP_COMPRESSION {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: tree.MapCompression.String(), Valid: true}
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+)
+
+// SQLPoolStatsInterval is how often MonitorSQLPool exports pool statistics.
+const SQLPoolStatsInterval = 10 * time.Second
+
+const dbLabel = "db"
+
+var (
+	sqlPoolMetricsOnce sync.Once
+	sqlPoolMaxOpen     monitoring.Gauge
+	sqlPoolOpen        monitoring.Gauge
+	sqlPoolInUse       monitoring.Gauge
+	sqlPoolIdle        monitoring.Gauge
+	sqlPoolWaitCount   monitoring.Counter
+	sqlPoolWaitSeconds monitoring.Counter
+	sqlPoolIdleClosed  monitoring.Counter
+	sqlPoolLifeClosed  monitoring.Counter
+)
+
+func createSQLPoolMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	sqlPoolMaxOpen = mf.NewGauge("sql_pool_max_open_connections", "Maximum number of open connections to the database", dbLabel)
+	sqlPoolOpen = mf.NewGauge("sql_pool_open_connections", "Number of established connections to the database, both in use and idle", dbLabel)
+	sqlPoolInUse = mf.NewGauge("sql_pool_in_use_connections", "Number of connections to the database currently in use", dbLabel)
+	sqlPoolIdle = mf.NewGauge("sql_pool_idle_connections", "Number of idle connections to the database", dbLabel)
+	sqlPoolWaitCount = mf.NewCounter("sql_pool_wait_count", "Total number of times a database connection had to be waited for", dbLabel)
+	sqlPoolWaitSeconds = mf.NewCounter("sql_pool_wait_seconds", "Total time spent waiting for database connections, in seconds", dbLabel)
+	sqlPoolIdleClosed = mf.NewCounter("sql_pool_max_idle_closed", "Total number of database connections closed due to the idle connection limit", dbLabel)
+	sqlPoolLifeClosed = mf.NewCounter("sql_pool_max_lifetime_closed", "Total number of database connections closed due to the connection lifetime limit", dbLabel)
+}
+
+// SQLPoolConfig holds the connection pool settings of a *sql.DB.
+type SQLPoolConfig struct {
+	// MaxOpenConns is the maximum number of open connections. Values <= 0
+	// leave the number unlimited.
+	MaxOpenConns int
+	// MaxIdleConns is the maximum number of idle connections kept in the pool.
+	// Negative values leave the database/sql default in place, and 0 disables
+	// idle connections altogether.
+	MaxIdleConns int
+	// ConnMaxLifetime is the maximum time a connection may be reused for.
+	// Values <= 0 let connections be reused forever.
+	ConnMaxLifetime time.Duration
+}
+
+// Apply sets the pool settings on db.
+func (c SQLPoolConfig) Apply(db *sql.DB) {
+	if c.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns >= 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
+}
+
+// MonitorSQLPool exports the connection pool statistics of db as metrics
+// labelled with name, every interval until the returned function is called.
+// Only the first MetricFactory passed in is used to create the metrics.
+func MonitorSQLPool(db *sql.DB, name string, mf monitoring.MetricFactory, interval time.Duration) func() {
+	sqlPoolMetricsOnce.Do(func() { createSQLPoolMetrics(mf) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var last sql.DBStats
+		for {
+			last = exportSQLPoolStats(name, db.Stats(), last)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// exportSQLPoolStats updates the pool metrics from cur, using prev to compute
+// increments of the cumulative statistics. It returns cur.
+func exportSQLPoolStats(name string, cur, prev sql.DBStats) sql.DBStats {
+	sqlPoolMaxOpen.Set(float64(cur.MaxOpenConnections), name)
+	sqlPoolOpen.Set(float64(cur.OpenConnections), name)
+	sqlPoolInUse.Set(float64(cur.InUse), name)
+	sqlPoolIdle.Set(float64(cur.Idle), name)
+	sqlPoolWaitCount.Add(float64(cur.WaitCount-prev.WaitCount), name)
+	sqlPoolWaitSeconds.Add((cur.WaitDuration - prev.WaitDuration).Seconds(), name)
+	sqlPoolIdleClosed.Add(float64(cur.MaxIdleClosed-prev.MaxIdleClosed), name)
+	sqlPoolLifeClosed.Add(float64(cur.MaxLifetimeClosed-prev.MaxLifetimeClosed), name)
+	return cur
+}
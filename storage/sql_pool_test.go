@@ -0,0 +1,136 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeDriver hands out connections which can't do anything, which is enough
+// to exercise the connection pool.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not implemented") }
+
+func init() {
+	sql.Register("trillian_fake_pool", fakeDriver{})
+}
+
+func TestSQLPoolConfigApply(t *testing.T) {
+	db, err := sql.Open("trillian_fake_pool", "")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+
+	SQLPoolConfig{MaxOpenConns: 3, MaxIdleConns: -1}.Apply(db)
+	if got, want := db.Stats().MaxOpenConnections, 3; got != want {
+		t.Errorf("MaxOpenConnections = %d, want %d", got, want)
+	}
+
+	// With idle connections disabled, a released connection is closed.
+	SQLPoolConfig{MaxIdleConns: 0, ConnMaxLifetime: time.Minute}.Apply(db)
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Conn(): %v", err)
+	}
+	conn.Close()
+	stats := db.Stats()
+	if got, want := stats.MaxOpenConnections, 3; got != want {
+		t.Errorf("MaxOpenConnections = %d, want %d", got, want)
+	}
+	if stats.Idle != 0 || stats.MaxIdleClosed != 1 {
+		t.Errorf("Idle, MaxIdleClosed = %d, %d, want 0, 1", stats.Idle, stats.MaxIdleClosed)
+	}
+}
+
+func TestMonitorSQLPool(t *testing.T) {
+	db, err := sql.Open("trillian_fake_pool", "")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(5)
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Conn(): %v", err)
+	}
+	defer conn.Close()
+
+	// The first export happens straight away.
+	stop := MonitorSQLPool(db, "monitor", nil, time.Hour)
+	stop()
+	stop() // Must be safe to call again.
+	for _, m := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"max_open", sqlPoolMaxOpen.Value("monitor"), 5},
+		{"open", sqlPoolOpen.Value("monitor"), 1},
+		{"in_use", sqlPoolInUse.Value("monitor"), 1},
+		{"idle", sqlPoolIdle.Value("monitor"), 0},
+	} {
+		if m.got != m.want {
+			t.Errorf("%s = %v, want %v", m.name, m.got, m.want)
+		}
+	}
+}
+
+func TestExportSQLPoolStats(t *testing.T) {
+	sqlPoolMetricsOnce.Do(func() { createSQLPoolMetrics(nil) })
+	const name = "export"
+	prev := exportSQLPoolStats(name, sql.DBStats{
+		WaitCount:         2,
+		WaitDuration:      time.Second,
+		MaxIdleClosed:     3,
+		MaxLifetimeClosed: 4,
+	}, sql.DBStats{})
+	exportSQLPoolStats(name, sql.DBStats{
+		WaitCount:         5,
+		WaitDuration:      2500 * time.Millisecond,
+		MaxIdleClosed:     3,
+		MaxLifetimeClosed: 10,
+	}, prev)
+
+	// Cumulative statistics are exported as counters, so they must match the
+	// latest values rather than the sum of all values seen.
+	for _, m := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"wait_count", sqlPoolWaitCount.Value(name), 5},
+		{"wait_seconds", sqlPoolWaitSeconds.Value(name), 2.5},
+		{"max_idle_closed", sqlPoolIdleClosed.Value(name), 3},
+		{"max_lifetime_closed", sqlPoolLifeClosed.Value(name), 10},
+	} {
+		if m.got != m.want {
+			t.Errorf("%s = %v, want %v", m.name, m.got, m.want)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcomment
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+)
+
+// WrapDriver returns a driver.Driver which annotates every statement executed
+// through d with the comment describing the request it is executed for.
+//
+// database/sql prepares a statement at most once per connection, using the
+// context of whichever request happens to need it first. The returned driver
+// therefore defers preparing statements until they are executed, and prepares
+// them afresh for every execution so that the comment matches the request.
+// This trades away the reuse of server-side prepared statements, so it costs
+// an extra round-trip per statement with drivers which can't execute
+// parameterized queries directly.
+func WrapDriver(d driver.Driver) driver.Driver {
+	return WrapDriverFunc(d, Annotate)
+}
+
+// RewriteFunc rewrites a statement executed for the request associated with
+// ctx, such as to annotate it.
+type RewriteFunc func(ctx context.Context, query string) string
+
+// WrapDriverFunc returns a driver.Driver which rewrites every statement
+// executed through d with rewrite, like WrapDriver does with Annotate.
+func WrapDriverFunc(d driver.Driver, rewrite RewriteFunc) driver.Driver {
+	return &wrappedDriver{d: d, rewrite: rewrite}
+}
+
+type wrappedDriver struct {
+	d       driver.Driver
+	rewrite RewriteFunc
+}
+
+// Open implements driver.Driver.
+func (w *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := w.d.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{c: c, rewrite: w.rewrite}, nil
+}
+
+// WrapConnector returns a driver.Connector which annotates every statement
+// executed through the connections of c, like WrapDriver.
+func WrapConnector(c driver.Connector) driver.Connector {
+	return WrapConnectorFunc(c, Annotate)
+}
+
+// WrapConnectorFunc returns a driver.Connector which rewrites every statement
+// executed through the connections of c with rewrite, like WrapDriverFunc.
+func WrapConnectorFunc(c driver.Connector, rewrite RewriteFunc) driver.Connector {
+	return &wrappedConnector{c: c, rewrite: rewrite}
+}
+
+type wrappedConnector struct {
+	c       driver.Connector
+	rewrite RewriteFunc
+}
+
+// Connect implements driver.Connector.
+func (w *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c, err := w.c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{c: c, rewrite: w.rewrite}, nil
+}
+
+// Driver implements driver.Connector.
+func (w *wrappedConnector) Driver() driver.Driver {
+	return WrapDriverFunc(w.c.Driver(), w.rewrite)
+}
+
+// conn rewrites the statements run through the driver.Conn it wraps.
+type conn struct {
+	c       driver.Conn
+	rewrite RewriteFunc
+}
+
+var (
+	_ driver.ConnBeginTx        = &conn{}
+	_ driver.ConnPrepareContext = &conn{}
+	_ driver.ExecerContext      = &conn{}
+	_ driver.QueryerContext     = &conn{}
+	_ driver.Pinger             = &conn{}
+	_ driver.SessionResetter    = &conn{}
+	_ driver.NamedValueChecker  = &conn{}
+)
+
+// Prepare implements driver.Conn.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext implements driver.ConnPrepareContext. The statement is only
+// prepared when executed.
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return &stmt{c: c, query: query}, nil
+}
+
+// Close implements driver.Conn.
+func (c *conn) Close() error {
+	return c.c.Close()
+}
+
+// Begin implements driver.Conn.
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if cbt, ok := c.c.(driver.ConnBeginTx); ok {
+		return cbt.BeginTx(ctx, opts)
+	}
+	if opts.ReadOnly || opts.Isolation != driver.IsolationLevel(0) {
+		return nil, errors.New("sqlcomment: driver does not support transaction options")
+	}
+	return c.c.Begin() // nolint: staticcheck
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ex, ok := c.c.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return ex.ExecContext(ctx, c.rewrite(ctx, query), args)
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.c.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return q.QueryContext(ctx, c.rewrite(ctx, query), args)
+}
+
+// Ping implements driver.Pinger.
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.c.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *conn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.c.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.c.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// prepare prepares the rewritten query on the wrapped connection.
+func (c *conn) prepare(ctx context.Context, query string) (driver.Stmt, error) {
+	if cpc, ok := c.c.(driver.ConnPrepareContext); ok {
+		return cpc.PrepareContext(ctx, query)
+	}
+	return c.c.Prepare(query)
+}
+
+// stmt is a statement which is rewritten and prepared on every execution.
+type stmt struct {
+	c     *conn
+	query string
+}
+
+var (
+	_ driver.StmtExecContext  = &stmt{}
+	_ driver.StmtQueryContext = &stmt{}
+)
+
+// Close implements driver.Stmt. There's nothing to close until the statement
+// is executed.
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput implements driver.Stmt. The number of placeholders isn't known
+// until the statement is prepared, so it isn't checked by database/sql.
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+// Exec implements driver.Stmt.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+// Query implements driver.Stmt.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext.
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	res, err := s.c.ExecContext(ctx, s.query, args)
+	if err != driver.ErrSkip {
+		return res, err
+	}
+	ps, err := s.c.prepare(ctx, s.c.rewrite(ctx, s.query))
+	if err != nil {
+		return nil, err
+	}
+	defer ps.Close()
+	if sec, ok := ps.(driver.StmtExecContext); ok {
+		return sec.ExecContext(ctx, args)
+	}
+	vals, err := values(args)
+	if err != nil {
+		return nil, err
+	}
+	return ps.Exec(vals) // nolint: staticcheck
+}
+
+// QueryContext implements driver.StmtQueryContext.
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := s.c.QueryContext(ctx, s.query, args)
+	if err != driver.ErrSkip {
+		return rows, err
+	}
+	ps, err := s.c.prepare(ctx, s.c.rewrite(ctx, s.query))
+	if err != nil {
+		return nil, err
+	}
+	if sqc, ok := ps.(driver.StmtQueryContext); ok {
+		rows, err = sqc.QueryContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		if vals, err = values(args); err == nil {
+			rows, err = ps.Query(vals) // nolint: staticcheck
+		}
+	}
+	if err != nil {
+		ps.Close()
+		return nil, err
+	}
+	return &stmtRows{Rows: rows, stmt: ps}, nil
+}
+
+// stmtRows closes the statement the rows were queried from along with them.
+type stmtRows struct {
+	driver.Rows
+	stmt driver.Stmt
+}
+
+// Close implements driver.Rows.
+func (r *stmtRows) Close() error {
+	err := r.Rows.Close()
+	if serr := r.stmt.Close(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+func values(args []driver.NamedValue) ([]driver.Value, error) {
+	vals := make([]driver.Value, len(args))
+	for i, nv := range args {
+		if nv.Name != "" {
+			return nil, errors.New("sqlcomment: driver does not support named parameters")
+		}
+		vals[i] = nv.Value
+	}
+	return vals, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcomment
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/trees"
+)
+
+// recordingDriver records the queries prepared through it. Its connections
+// can only execute prepared statements, like MySQL's do for queries with
+// arguments.
+type recordingDriver struct {
+	mu       sync.Mutex
+	prepared []string
+	open     int // Number of open statements.
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return &recordingConn{d: d}, nil }
+
+func (d *recordingDriver) reset() ([]string, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	prepared := d.prepared
+	d.prepared = nil
+	return prepared, d.open
+}
+
+type recordingConn struct {
+	d *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.prepared = append(c.d.prepared, query)
+	c.d.open++
+	return &recordingStmt{d: c.d}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return recordingTx{}, nil }
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error   { return nil }
+func (recordingTx) Rollback() error { return nil }
+
+type recordingStmt struct {
+	d *recordingDriver
+}
+
+func (s *recordingStmt) Close() error {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.open--
+	return nil
+}
+func (s *recordingStmt) NumInput() int { return -1 }
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &oneRow{}, nil
+}
+
+type oneRow struct {
+	done bool
+}
+
+func (r *oneRow) Columns() []string { return []string{"n"} }
+func (r *oneRow) Close() error      { return nil }
+func (r *oneRow) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+var rd = &recordingDriver{}
+
+func init() {
+	sql.Register("trillian_sqlcomment_test", WrapDriver(rd))
+}
+
+func TestWrapDriver(t *testing.T) {
+	db, err := sql.Open("trillian_sqlcomment_test", "")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	rd.reset()
+
+	ctx1 := trees.NewContext(context.Background(), &trillian.Tree{TreeId: 1})
+	ctx2 := trees.NewContext(context.Background(), &trillian.Tree{TreeId: 2})
+
+	if _, err := db.ExecContext(ctx1, "DELETE FROM Unsequenced WHERE TreeId=?", 1); err != nil {
+		t.Fatalf("ExecContext(): %v", err)
+	}
+
+	// A statement prepared once must be annotated for each execution.
+	stmt, err := db.PrepareContext(ctx1, "SELECT COUNT(*) FROM Unsequenced WHERE TreeId=?")
+	if err != nil {
+		t.Fatalf("PrepareContext(): %v", err)
+	}
+	defer stmt.Close()
+	for _, ctx := range []context.Context{ctx1, ctx2} {
+		var n int
+		if err := stmt.QueryRowContext(ctx, 1).Scan(&n); err != nil {
+			t.Fatalf("QueryRowContext(): %v", err)
+		}
+	}
+
+	tx, err := db.BeginTx(ctx2, nil)
+	if err != nil {
+		t.Fatalf("BeginTx(): %v", err)
+	}
+	if _, err := tx.StmtContext(ctx2, stmt).ExecContext(ctx2, 1); err != nil {
+		t.Fatalf("ExecContext(): %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit(): %v", err)
+	}
+
+	prepared, open := rd.reset()
+	want := []string{
+		"DELETE FROM Unsequenced WHERE TreeId=? /*tree_id='1'*/",
+		"SELECT COUNT(*) FROM Unsequenced WHERE TreeId=? /*tree_id='1'*/",
+		"SELECT COUNT(*) FROM Unsequenced WHERE TreeId=? /*tree_id='2'*/",
+		"SELECT COUNT(*) FROM Unsequenced WHERE TreeId=? /*tree_id='2'*/",
+	}
+	if diff := cmp.Diff(prepared, want); diff != "" {
+		t.Errorf("prepared queries diff (-got +want):\n%s", diff)
+	}
+	if open != 0 {
+		t.Errorf("%d statements left open, want 0", open)
+	}
+}
+
+type recordingConnector struct {
+	d *recordingDriver
+}
+
+func (c recordingConnector) Connect(context.Context) (driver.Conn, error) { return c.d.Open("") }
+func (c recordingConnector) Driver() driver.Driver                        { return c.d }
+
+func TestWrapConnector(t *testing.T) {
+	d := &recordingDriver{}
+	db := sql.OpenDB(WrapConnector(recordingConnector{d: d}))
+	defer db.Close()
+
+	ctx := trees.NewContext(context.Background(), &trillian.Tree{TreeId: 1})
+	if _, err := db.ExecContext(ctx, "DELETE FROM Unsequenced WHERE TreeId=?", 1); err != nil {
+		t.Fatalf("ExecContext(): %v", err)
+	}
+	prepared, _ := d.reset()
+	want := []string{"DELETE FROM Unsequenced WHERE TreeId=? /*tree_id='1'*/"}
+	if diff := cmp.Diff(prepared, want); diff != "" {
+		t.Errorf("prepared queries diff (-got +want):\n%s", diff)
+	}
+}
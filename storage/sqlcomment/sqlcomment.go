@@ -0,0 +1,95 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlcomment annotates SQL statements with comments describing the
+// request they are issued for, in the format defined by sqlcommenter
+// (https://google.github.io/sqlcommenter/spec/). This allows slow query logs
+// and database-side profiles to be correlated with Trillian RPCs.
+package sqlcomment
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/util/requestid"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc"
+)
+
+// Keys of the tags added to statements.
+const (
+	TraceParentKey = "traceparent"
+	RPCMethodKey   = "rpc_method"
+	TreeIDKey      = "tree_id"
+	RequestIDKey   = "request_id"
+)
+
+// Tags returns the tags describing the request associated with ctx: the
+// current trace span, in W3C Trace Context format, the gRPC method being
+// served, the request ID and the ID of the tree being operated on. Tags which
+// aren't known are omitted.
+func Tags(ctx context.Context) map[string]string {
+	tags := make(map[string]string)
+	if span := trace.FromContext(ctx); span != nil {
+		sc := span.SpanContext()
+		tags[TraceParentKey] = fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, uint32(sc.TraceOptions))
+	}
+	if method, ok := grpc.Method(ctx); ok {
+		tags[RPCMethodKey] = method
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		tags[RequestIDKey] = id
+	}
+	if tree, ok := trees.FromContext(ctx); ok {
+		tags[TreeIDKey] = fmt.Sprint(tree.TreeId)
+	}
+	return tags
+}
+
+// Comment formats tags as a sqlcommenter comment. Keys are sorted, and keys
+// and values are URL-encoded so that they can't terminate the comment. An
+// empty string is returned if there are no tags.
+func Comment(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, fmt.Sprintf("%s='%s'", url.QueryEscape(k), url.QueryEscape(v)))
+	}
+	sort.Strings(pairs)
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+// Annotate appends the comment describing the request associated with ctx to
+// query. Queries which already contain a comment are returned unchanged, as
+// required by the sqlcommenter specification.
+func Annotate(ctx context.Context, query string) string {
+	if strings.Contains(query, "/*") || strings.Contains(query, "--") {
+		return query
+	}
+	comment := Comment(Tags(ctx))
+	if comment == "" {
+		return query
+	}
+	query = strings.TrimRight(query, " \t\n")
+	if strings.HasSuffix(query, ";") {
+		return query[:len(query)-1] + " " + comment + ";"
+	}
+	return query + " " + comment
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcomment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/util/requestid"
+	"go.opencensus.io/trace"
+)
+
+func TestTags(t *testing.T) {
+	ctx := context.Background()
+	if got := Tags(ctx); len(got) != 0 {
+		t.Errorf("Tags(empty) = %v, want none", got)
+	}
+
+	ctx = trees.NewContext(ctx, &trillian.Tree{TreeId: 12345})
+	ctx = requestid.NewContext(ctx, "req-1")
+	ctx, span := trace.StartSpan(ctx, "test", trace.WithSampler(trace.AlwaysSample()))
+	defer span.End()
+	sc := span.SpanContext()
+	want := map[string]string{
+		TraceParentKey: "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-01",
+		TreeIDKey:      "12345",
+		RequestIDKey:   "req-1",
+	}
+	if diff := cmp.Diff(Tags(ctx), want); diff != "" {
+		t.Errorf("Tags() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestComment(t *testing.T) {
+	for _, test := range []struct {
+		tags map[string]string
+		want string
+	}{
+		{tags: nil, want: ""},
+		{
+			tags: map[string]string{TreeIDKey: "1", RPCMethodKey: "/trillian.TrillianLog/QueueLeaf"},
+			want: "/*rpc_method='%2Ftrillian.TrillianLog%2FQueueLeaf',tree_id='1'*/",
+		},
+		{
+			// Values mustn't be able to close the comment or the quotes.
+			tags: map[string]string{"k": "*/ DROP TABLE Trees; '"},
+			want: "/*k='%2A%2F+DROP+TABLE+Trees%3B+%27'*/",
+		},
+	} {
+		if got := Comment(test.tags); got != test.want {
+			t.Errorf("Comment(%v) = %q, want %q", test.tags, got, test.want)
+		}
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	ctx := trees.NewContext(context.Background(), &trillian.Tree{TreeId: 7})
+	for _, test := range []struct {
+		ctx   context.Context
+		query string
+		want  string
+	}{
+		{ctx: context.Background(), query: "SELECT 1", want: "SELECT 1"},
+		{ctx: ctx, query: "SELECT 1", want: "SELECT 1 /*tree_id='7'*/"},
+		{ctx: ctx, query: "SELECT 1;\n", want: "SELECT 1 /*tree_id='7'*/;"},
+		{ctx: ctx, query: "SELECT /* hint */ 1", want: "SELECT /* hint */ 1"},
+		{ctx: ctx, query: "SELECT 1 -- note", want: "SELECT 1 -- note"},
+	} {
+		if got := Annotate(test.ctx, test.query); got != test.want {
+			t.Errorf("Annotate(%q) = %q, want %q", test.query, got, test.want)
+		}
+	}
+}
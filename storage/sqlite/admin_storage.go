@@ -0,0 +1,572 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultSequenceIntervalSeconds = 60
+
+	nonDeletedWhere = " WHERE (Deleted IS NULL OR Deleted = 0)"
+
+	selectTreeIDs           = "SELECT TreeId FROM Trees"
+	selectNonDeletedTreeIDs = selectTreeIDs + nonDeletedWhere
+
+	selectTrees = `
+		SELECT
+			TreeId,
+			TreeState,
+			TreeType,
+			HashStrategy,
+			HashAlgorithm,
+			SignatureAlgorithm,
+			DisplayName,
+			Description,
+			CreateTimeMillis,
+			UpdateTimeMillis,
+			PrivateKey,
+			PublicKey,
+			MaxRootDurationMillis,
+			Deleted,
+			DeleteTimeMillis,
+			RateLimits,
+			Labels,
+			CreateRequestId,
+			Maintenance,
+			MapStrata,
+			MapCompression,
+			MapHasher,
+			AutoInit,
+			MaxStorageBytes,
+			MapKeyIndex,
+			RetiredPublicKeys
+		FROM Trees`
+	selectNonDeletedTrees       = selectTrees + nonDeletedWhere
+	selectTreeByID              = selectTrees + " WHERE TreeId = ?"
+	selectTreeByCreateRequestID = selectTrees + " WHERE CreateRequestId = ?"
+
+	insertTreeSQL = `INSERT INTO Trees(
+			TreeId,
+			TreeState,
+			TreeType,
+			HashStrategy,
+			HashAlgorithm,
+			SignatureAlgorithm,
+			DisplayName,
+			Description,
+			CreateTimeMillis,
+			UpdateTimeMillis,
+			PrivateKey,
+			PublicKey,
+			MaxRootDurationMillis,
+			RateLimits,
+			Labels,
+			CreateRequestId,
+			Maintenance,
+			MapStrata,
+			MapCompression,
+			MapHasher,
+			AutoInit,
+			MaxStorageBytes,
+			MapKeyIndex)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	insertTreeControlSQL = `INSERT INTO TreeControl(
+			TreeId,
+			SigningEnabled,
+			SequencingEnabled,
+			SequenceIntervalSeconds)
+		VALUES(?, ?, ?, ?)`
+
+	updateTreeSQL = `UPDATE Trees
+		SET TreeState = ?, TreeType = ?, DisplayName = ?, Description = ?, UpdateTimeMillis = ?, MaxRootDurationMillis = ?, PrivateKey = ?, RateLimits = ?, Labels = ?, Maintenance = ?, MapCompression = ?, AutoInit = ?, MaxStorageBytes = ?, PublicKey = ?, RetiredPublicKeys = ?
+		WHERE TreeId = ?`
+
+	softDeleteSQL = "UPDATE Trees SET Deleted = ?, DeleteTimeMillis = ? WHERE TreeId = ?"
+
+	selectDeletedSQL = "SELECT Deleted FROM Trees WHERE TreeId = ?"
+
+	deleteFromTreeControlSQL = "DELETE FROM TreeControl WHERE TreeId = ?"
+
+	deleteFromTreesSQL = "DELETE FROM Trees WHERE TreeId = ?"
+)
+
+// NewAdminStorage returns a SQLite storage.AdminStorage implementation backed
+// by db.
+func NewAdminStorage(db *sql.DB) storage.AdminStorage {
+	return &sqliteAdminStorage{db}
+}
+
+// sqliteAdminStorage implements storage.AdminStorage
+type sqliteAdminStorage struct {
+	db *sql.DB
+}
+
+func (s *sqliteAdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	tx, err := s.beginInternal(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteAdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *sqliteAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	return s.beginInternal(ctx)
+}
+
+func (s *sqliteAdminStorage) beginInternal(ctx context.Context) (storage.AdminTX, error) {
+	tx, err := s.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return nil, err
+	}
+	return &adminTX{tx: tx}, nil
+}
+
+type adminTX struct {
+	tx *sql.Tx
+
+	// mu guards *direct* reads/writes on closed, which happen only on
+	// Commit/Rollback/IsClosed/Close methods.
+	// We don't check closed on *all* methods (apart from the ones above),
+	// as we trust tx to keep tabs on its state (and consequently fail to do
+	// queries after closed).
+	mu     sync.RWMutex
+	closed bool
+}
+
+func (t *adminTX) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return t.tx.Commit()
+}
+
+func (t *adminTX) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return t.tx.Rollback()
+}
+
+func (t *adminTX) IsClosed() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.closed
+}
+
+func (t *adminTX) Close() error {
+	// Acquire and release read lock manually, without defer, as if the txn
+	// is not closed Rollback() will attempt to acquire the rw lock.
+	t.mu.RLock()
+	closed := t.closed
+	t.mu.RUnlock()
+	if !closed {
+		err := t.Rollback()
+		if err != nil {
+			glog.Warningf("Rollback error on Close(): %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	stmt, err := t.tx.PrepareContext(ctx, selectTreeByID)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	// GetTree is an entry point for most RPCs, let's provide somewhat nicer error messages.
+	tree, err := storage.ReadTree(stmt.QueryRowContext(ctx, treeID))
+	switch {
+	case err == sql.ErrNoRows:
+		// ErrNoRows doesn't provide useful information, so we don't forward it.
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", treeID)
+	case err != nil:
+		return nil, fmt.Errorf("error reading tree %v: %v", treeID, err)
+	}
+	return tree, nil
+}
+
+// GetTreeByCreateRequestID implements storage.CreateRequestIDReader.
+func (t *adminTX) GetTreeByCreateRequestID(ctx context.Context, requestID string) (*trillian.Tree, error) {
+	stmt, err := t.tx.PrepareContext(ctx, selectTreeByCreateRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	tree, err := storage.ReadTree(stmt.QueryRowContext(ctx, requestID))
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, status.Errorf(codes.NotFound, "no tree was created with request ID %q", requestID)
+	case err != nil:
+		return nil, fmt.Errorf("error reading tree created with request ID %q: %v", requestID, err)
+	}
+	return tree, nil
+}
+
+func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	var query string
+	if includeDeleted {
+		query = selectTrees
+	} else {
+		query = selectNonDeletedTrees
+	}
+
+	stmt, err := t.tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trees := []*trillian.Tree{}
+	for rows.Next() {
+		tree, err := storage.ReadTree(rows)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, tree)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return trees, nil
+}
+
+func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	var query string
+	if includeDeleted {
+		query = selectTreeIDs
+	} else {
+		query = selectNonDeletedTreeIDs
+	}
+
+	stmt, err := t.tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	treeIDs := []int64{}
+	var treeID int64
+	for rows.Next() {
+		if err := rows.Scan(&treeID); err != nil {
+			return nil, err
+		}
+		treeIDs = append(treeIDs, treeID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return treeIDs, nil
+}
+
+func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	if err := storage.ValidateTreeForCreation(ctx, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	id, err := storage.TreeIDForCreation(tree)
+	if err != nil {
+		return nil, err
+	}
+	if tree.TreeId != 0 {
+		// IDs chosen by the creators of trees may already be taken.
+		switch _, err := t.GetTree(ctx, id); status.Code(err) {
+		case codes.NotFound:
+		case codes.OK:
+			return nil, status.Errorf(codes.AlreadyExists, "tree %v already exists", id)
+		default:
+			return nil, err
+		}
+	}
+
+	// Use the time truncated-to-millis throughout, as that's what's stored.
+	nowMillis := storage.ToMillisSinceEpoch(time.Now())
+	now := storage.FromMillisSinceEpoch(nowMillis)
+
+	newTree := proto.Clone(tree).(*trillian.Tree)
+	newTree.TreeId = id
+	newTree.CreateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build create time: %v", err)
+	}
+	newTree.UpdateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update time: %v", err)
+	}
+	rootDuration, err := ptypes.Duration(newTree.MaxRootDuration)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse MaxRootDuration: %v", err)
+	}
+
+	insertTreeStmt, err := t.tx.PrepareContext(ctx, insertTreeSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer insertTreeStmt.Close()
+
+	privateKey, err := proto.Marshal(newTree.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
+	}
+	rateLimits, err := storage.MarshalRateLimits(newTree)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := storage.MarshalLabels(newTree)
+	if err != nil {
+		return nil, err
+	}
+	maintenance, err := storage.MarshalMaintenance(newTree)
+	if err != nil {
+		return nil, err
+	}
+	mapStrata, err := storage.MarshalMapStrata(newTree)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = insertTreeStmt.ExecContext(
+		ctx,
+		newTree.TreeId,
+		newTree.TreeState.String(),
+		newTree.TreeType.String(),
+		newTree.HashStrategy.String(),
+		newTree.HashAlgorithm.String(),
+		newTree.SignatureAlgorithm.String(),
+		newTree.DisplayName,
+		newTree.Description,
+		nowMillis,
+		nowMillis,
+		privateKey,
+		newTree.PublicKey.GetDer(),
+		rootDuration/time.Millisecond,
+		rateLimits,
+		labels,
+		sql.NullString{String: newTree.CreateRequestId, Valid: newTree.CreateRequestId != ""},
+		maintenance,
+		mapStrata,
+		storage.MarshalMapCompression(newTree),
+		sql.NullString{String: newTree.MapHasher, Valid: newTree.MapHasher != ""},
+		newTree.AutoInit,
+		newTree.MaxStorageBytes,
+		newTree.MapKeyIndex,
+	)
+	if err != nil {
+		if isDuplicateErr(err) {
+			// The tree or request IDs are taken, maybe by a concurrent request.
+			return nil, status.Errorf(codes.AlreadyExists, "tree %v or a tree created with request ID %q already exists", newTree.TreeId, newTree.CreateRequestId)
+		}
+		return nil, err
+	}
+
+	insertControlStmt, err := t.tx.PrepareContext(ctx, insertTreeControlSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer insertControlStmt.Close()
+	_, err = insertControlStmt.ExecContext(
+		ctx,
+		newTree.TreeId,
+		true, /* SigningEnabled */
+		true, /* SequencingEnabled */
+		defaultSequenceIntervalSeconds,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTree, nil
+}
+
+func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	tree, err := t.GetTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeUpdate := proto.Clone(tree).(*trillian.Tree)
+	updateFunc(tree)
+	if err := storage.ValidateTreeForUpdate(ctx, beforeUpdate, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	// Use the time truncated-to-millis throughout, as that's what's stored.
+	nowMillis := storage.ToMillisSinceEpoch(time.Now())
+	now := storage.FromMillisSinceEpoch(nowMillis)
+	tree.UpdateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tree.UpdateTime: %v", err)
+	}
+	rootDuration, err := ptypes.Duration(tree.MaxRootDuration)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse MaxRootDuration: %v", err)
+	}
+
+	privateKey, err := proto.Marshal(tree.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
+	}
+	rateLimits, err := storage.MarshalRateLimits(tree)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := storage.MarshalLabels(tree)
+	if err != nil {
+		return nil, err
+	}
+	maintenance, err := storage.MarshalMaintenance(tree)
+	if err != nil {
+		return nil, err
+	}
+	retiredPublicKeys, err := storage.MarshalRetiredPublicKeys(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := t.tx.PrepareContext(ctx, updateTreeSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.ExecContext(
+		ctx,
+		tree.TreeState.String(),
+		tree.TreeType.String(),
+		tree.DisplayName,
+		tree.Description,
+		nowMillis,
+		rootDuration/time.Millisecond,
+		privateKey,
+		rateLimits,
+		labels,
+		maintenance,
+		storage.MarshalMapCompression(tree),
+		tree.AutoInit,
+		tree.MaxStorageBytes,
+		tree.PublicKey.GetDer(),
+		retiredPublicKeys,
+		tree.TreeId); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+func (t *adminTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.updateDeleted(ctx, treeID, true /* deleted */, storage.ToMillisSinceEpoch(time.Now()) /* deleteTimeMillis */)
+}
+
+func (t *adminTX) UndeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.updateDeleted(ctx, treeID, false /* deleted */, nil /* deleteTimeMillis */)
+}
+
+func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
+	if err := validateDeleted(ctx, t.tx, treeID, true /* wantDeleted */); err != nil {
+		return err
+	}
+
+	if _, err := t.tx.ExecContext(ctx, deleteFromTreeControlSQL, treeID); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, deleteFromTreesSQL, treeID)
+	return err
+}
+
+// updateDeleted updates the Deleted and DeleteTimeMillis fields of the specified tree.
+// deleteTimeMillis must be either an int64 (in millis since epoch) or nil.
+func (t *adminTX) updateDeleted(ctx context.Context, treeID int64, deleted bool, deleteTimeMillis interface{}) (*trillian.Tree, error) {
+	if err := validateDeleted(ctx, t.tx, treeID, !deleted); err != nil {
+		return nil, err
+	}
+	if _, err := t.tx.ExecContext(
+		ctx,
+		softDeleteSQL,
+		deleted,
+		deleteTimeMillis,
+		treeID); err != nil {
+		return nil, err
+	}
+	return t.GetTree(ctx, treeID)
+}
+
+func validateDeleted(ctx context.Context, tx *sql.Tx, treeID int64, wantDeleted bool) error {
+	var nullDeleted sql.NullBool
+	switch err := tx.QueryRowContext(ctx, selectDeletedSQL, treeID).Scan(&nullDeleted); {
+	case err == sql.ErrNoRows:
+		return status.Errorf(codes.NotFound, "tree %v not found", treeID)
+	case err != nil:
+		return err
+	}
+
+	switch deleted := nullDeleted.Valid && nullDeleted.Bool; {
+	case wantDeleted && !deleted:
+		return status.Errorf(codes.FailedPrecondition, "tree %v is not soft deleted", treeID)
+	case !wantDeleted && deleted:
+		return status.Errorf(codes.FailedPrecondition, "tree %v already soft deleted", treeID)
+	}
+	return nil
+}
+
+func validateStorageSettings(tree *trillian.Tree) error {
+	if tree.StorageSettings != nil {
+		return fmt.Errorf("storage_settings not supported, but got %v", tree.StorageSettings)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"modernc.org/sqlite"
+
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// isDuplicateErr returns whether err is the error returned by the driver when
+// inserting a row whose primary or unique key is already taken.
+func isDuplicateErr(err error) bool {
+	switch err := err.(type) {
+	case *sqlite.Error:
+		return err.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || err.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+	default:
+		return false
+	}
+}
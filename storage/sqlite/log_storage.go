@@ -0,0 +1,917 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/features"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/requestid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	insertLeafDataSQL      = "INSERT INTO LeafData(TreeId,LeafIdentityHash,LeafValue,ExtraData,QueueTimestampNanos) VALUES(?,?,?,?,?)"
+	insertSequencedLeafSQL = "INSERT INTO SequencedLeafData(TreeId,LeafIdentityHash,MerkleLeafHash,SequenceNumber,IntegrateTimestampNanos) VALUES(?,?,?,?,?)"
+
+	selectNonDeletedTreeIDByTypeAndStateSQL = `
+		SELECT TreeId FROM Trees
+		  WHERE TreeType IN(?,?)
+		  AND TreeState IN(?,?)
+		  AND (Deleted IS NULL OR Deleted = 0)`
+
+	selectSequencedLeafCountSQL  = "SELECT COUNT(*) FROM SequencedLeafData WHERE TreeId=?"
+	selectLatestSignedLogRootSQL = `SELECT TreeHeadTimestamp,TreeSize,RootHash,TreeRevision,RootSignature
+			FROM TreeHead WHERE TreeId=?
+			ORDER BY TreeHeadTimestamp DESC LIMIT 1`
+
+	selectLeavesByRangeSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+			FROM SequencedLeafData s JOIN LeafData l
+			ON l.TreeId = s.TreeId AND l.LeafIdentityHash = s.LeafIdentityHash
+			WHERE s.TreeId = ? AND s.SequenceNumber >= ? AND s.SequenceNumber < ?` + orderBySequenceNumberSQL
+
+	// These statements need to be expanded to provide the correct number of parameter placeholders.
+	selectLeavesByIndexSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+			FROM LeafData l,SequencedLeafData s
+			WHERE l.LeafIdentityHash = s.LeafIdentityHash
+			AND s.SequenceNumber IN (` + placeholderSQL + `) AND l.TreeId = ? AND s.TreeId = l.TreeId`
+	selectLeavesByMerkleHashSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+			FROM LeafData l,SequencedLeafData s
+			WHERE l.LeafIdentityHash = s.LeafIdentityHash
+			AND s.MerkleLeafHash IN (` + placeholderSQL + `) AND l.TreeId = ? AND s.TreeId = l.TreeId`
+	// TODO(#1548): rework the code so the dummy hash isn't needed (e.g. this assumes hash size is 32)
+	dummyMerkleLeafHash = "00000000000000000000000000000000"
+	// This statement returns the Merkle leaf hash and index of leaves which
+	// have been integrated, and a dummy Merkle leaf hash value (which must be
+	// of the right size) and index -1 for those which haven't, so that its
+	// signature matches that of the other leaf-selection statements.
+	selectLeavesByLeafIdentityHashSQL = `SELECT COALESCE(s.MerkleLeafHash,CAST('` + dummyMerkleLeafHash + `' AS BLOB)),l.LeafIdentityHash,l.LeafValue,COALESCE(s.SequenceNumber,-1),l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+			FROM LeafData l LEFT JOIN SequencedLeafData s ON (l.LeafIdentityHash = s.LeafIdentityHash AND l.TreeId = s.TreeId)
+			WHERE l.LeafIdentityHash IN (` + placeholderSQL + `) AND l.TreeId = ?`
+
+	// Same as above except with leaves ordered by sequence so we only incur this cost when necessary
+	orderBySequenceNumberSQL                     = " ORDER BY s.SequenceNumber"
+	selectLeavesByMerkleHashOrderedBySequenceSQL = selectLeavesByMerkleHashSQL + orderBySequenceNumberSQL
+
+	logIDLabel = "logid"
+)
+
+var (
+	defaultLogStrata = []int{8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8}
+
+	once             sync.Once
+	queuedCounter    monitoring.Counter
+	queuedDupCounter monitoring.Counter
+	dequeuedCounter  monitoring.Counter
+
+	queueLatency            monitoring.Histogram
+	queueInsertLatency      monitoring.Histogram
+	queueReadLatency        monitoring.Histogram
+	queueInsertLeafLatency  monitoring.Histogram
+	queueInsertEntryLatency monitoring.Histogram
+	dequeueLatency          monitoring.Histogram
+	dequeueSelectLatency    monitoring.Histogram
+	dequeueRemoveLatency    monitoring.Histogram
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	queuedCounter = mf.NewCounter("sqlite_queued_leaves", "Number of leaves queued", logIDLabel)
+	queuedDupCounter = mf.NewCounter("sqlite_queued_dup_leaves", "Number of duplicate leaves queued", logIDLabel)
+	dequeuedCounter = mf.NewCounter("sqlite_dequeued_leaves", "Number of leaves dequeued", logIDLabel)
+
+	queueLatency = mf.NewHistogram("sqlite_queue_leaves_latency", "Latency of queue leaves operation in seconds", logIDLabel)
+	queueInsertLatency = mf.NewHistogram("sqlite_queue_leaves_latency_insert", "Latency of insertion part of queue leaves operation in seconds", logIDLabel)
+	queueReadLatency = mf.NewHistogram("sqlite_queue_leaves_latency_read_dups", "Latency of read-duplicates part of queue leaves operation in seconds", logIDLabel)
+	queueInsertLeafLatency = mf.NewHistogram("sqlite_queue_leaf_latency_leaf", "Latency of insert-leaf part of queue (single) leaf operation in seconds", logIDLabel)
+	queueInsertEntryLatency = mf.NewHistogram("sqlite_queue_leaf_latency_entry", "Latency of insert-entry part of queue (single) leaf operation in seconds", logIDLabel)
+
+	dequeueLatency = mf.NewHistogram("sqlite_dequeue_leaves_latency", "Latency of dequeue leaves operation in seconds", logIDLabel)
+	dequeueSelectLatency = mf.NewHistogram("sqlite_dequeue_leaves_latency_select", "Latency of selection part of dequeue leaves operation in seconds", logIDLabel)
+	dequeueRemoveLatency = mf.NewHistogram("sqlite_dequeue_leaves_latency_remove", "Latency of removal part of dequeue leaves operation in seconds", logIDLabel)
+}
+
+func labelForTX(t *logTreeTX) string {
+	return strconv.FormatInt(t.treeID, 10)
+}
+
+// observe records the duration of an operation performed for ctx, linking it
+// to the trace ctx is part of if there is one.
+func observe(ctx context.Context, hist monitoring.Histogram, duration time.Duration, label string) {
+	monitoring.ObserveContext(ctx, hist, duration.Seconds(), label)
+}
+
+type sqliteLogStorage struct {
+	*sqliteTreeStorage
+	admin         storage.AdminStorage
+	metricFactory monitoring.MetricFactory
+	opts          LogStorageOptions
+}
+
+// LogStorageOptions holds optional settings of the SQLite log storage.
+type LogStorageOptions struct {
+	// SharedSubtrees, if set, caches the subtrees of logs read by all
+	// transactions.
+	SharedSubtrees *cache.SharedLogSubtrees
+}
+
+// NewLogStorage creates a storage.LogStorage instance for the given SQLite
+// database, opened by OpenDB. It assumes storage.AdminStorage is backed by the
+// same database as well.
+func NewLogStorage(db *sql.DB, mf monitoring.MetricFactory) storage.LogStorage {
+	return NewLogStorageWithOpts(db, mf, LogStorageOptions{})
+}
+
+// NewLogStorageWithOpts creates a storage.LogStorage instance with the given
+// options.
+func NewLogStorageWithOpts(db *sql.DB, mf monitoring.MetricFactory, opts LogStorageOptions) storage.LogStorage {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &sqliteLogStorage{
+		admin:             NewAdminStorage(db),
+		sqliteTreeStorage: newTreeStorage(db),
+		metricFactory:     mf,
+		opts:              opts,
+	}
+}
+
+func (m *sqliteLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return m.db.PingContext(ctx)
+}
+
+// readOnlyLogTX implements storage.ReadOnlyLogTX
+type readOnlyLogTX struct {
+	ls *sqliteLogStorage
+	tx *sql.Tx
+}
+
+func (m *sqliteLogStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
+	tx, err := m.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		glog.Warningf("Could not start ReadOnlyLogTX: %s", err)
+		return nil, err
+	}
+	return &readOnlyLogTX{m, tx}, nil
+}
+
+func (t *readOnlyLogTX) Commit(context.Context) error {
+	return t.tx.Commit()
+}
+
+func (t *readOnlyLogTX) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func (t *readOnlyLogTX) Close() error {
+	if err := t.Rollback(); err != nil && err != sql.ErrTxDone {
+		glog.Warningf("Rollback error on Close(): %v", err)
+		return err
+	}
+	return nil
+}
+
+func (t *readOnlyLogTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	// Include logs that are DRAINING in the active list as we're still
+	// integrating leaves into them.
+	rows, err := t.tx.QueryContext(
+		ctx, selectNonDeletedTreeIDByTypeAndStateSQL,
+		trillian.TreeType_LOG.String(), trillian.TreeType_PREORDERED_LOG.String(),
+		trillian.TreeState_ACTIVE.String(), trillian.TreeState_DRAINING.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []int64{}
+	for rows.Next() {
+		var treeID int64
+		if err := rows.Scan(&treeID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, treeID)
+	}
+	return ids, rows.Err()
+}
+
+func (m *sqliteLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree) (*logTreeTX, error) {
+	once.Do(func() {
+		createMetrics(m.metricFactory)
+	})
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	stCache := cache.NewLogSubtreeCache(defaultLogStrata, hasher)
+	ttx, err := m.beginTreeTx(ctx, tree, hasher.Size(), stCache)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return nil, err
+	}
+
+	if features.Enabled(features.SharedSubtreeCache, tree.TreeId) {
+		ttx.shared = m.opts.SharedSubtrees
+	}
+	ltx := &logTreeTX{
+		treeTX:   ttx,
+		ls:       m,
+		dequeued: make(map[string]dequeuedLeaf),
+	}
+	ltx.slr, err = ltx.fetchLatestRoot(ctx)
+	if err == storage.ErrTreeNeedsInit {
+		ltx.treeTX.writeRevision = 0
+		return ltx, err
+	} else if err != nil {
+		ttx.Rollback()
+		return nil, err
+	}
+
+	if err := ltx.root.UnmarshalBinary(ltx.slr.LogRoot); err != nil {
+		ttx.Rollback()
+		return nil, err
+	}
+
+	ltx.treeTX.writeRevision = int64(ltx.root.Revision) + 1
+	return ltx, nil
+}
+
+func (m *sqliteLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	tx, err := m.beginInternal(ctx, tree)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return err
+	}
+	defer tx.Close()
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (m *sqliteLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	tx, err := m.beginInternal(ctx, tree)
+	if tx != nil {
+		// Ensure we don't leak the transaction. For example if we get an
+		// ErrTreeNeedsInit from beginInternal() or if AddSequencedLeaves fails
+		// below.
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, err := tx.AddSequencedLeaves(ctx, leaves, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *sqliteLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	tx, err := m.beginInternal(ctx, tree)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return nil, err
+	}
+	return tx, err
+}
+
+func (m *sqliteLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	tx, err := m.beginInternal(ctx, tree)
+	if tx != nil {
+		// Ensure we don't leak the transaction. For example if we get an
+		// ErrTreeNeedsInit from beginInternal() or if QueueLeaves fails
+		// below.
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	existing, err := tx.QueueLeaves(ctx, leaves, queueTimestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*trillian.QueuedLogLeaf, len(leaves))
+	for i, e := range existing {
+		if e != nil {
+			ret[i] = &trillian.QueuedLogLeaf{
+				Leaf:   e,
+				Status: status.Newf(codes.AlreadyExists, "leaf already exists: %v", e.LeafIdentityHash).Proto(),
+			}
+			continue
+		}
+		ret[i] = &trillian.QueuedLogLeaf{Leaf: leaves[i]}
+	}
+	return ret, nil
+}
+
+type logTreeTX struct {
+	treeTX
+	ls       *sqliteLogStorage
+	root     types.LogRootV1
+	slr      *trillian.SignedLogRoot
+	dequeued map[string]dequeuedLeaf
+}
+
+func (t *logTreeTX) ReadRevision(ctx context.Context) (int64, error) {
+	return int64(t.root.Revision), nil
+}
+
+func (t *logTreeTX) WriteRevision(ctx context.Context) (int64, error) {
+	if t.treeTX.writeRevision < 0 {
+		return t.treeTX.writeRevision, errors.New("logTreeTX write revision not populated")
+	}
+	return t.treeTX.writeRevision, nil
+}
+
+func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	if t.treeType == trillian.TreeType_PREORDERED_LOG {
+		// TODO(pavelkalinnikov): Optimize this by fetching only the required
+		// fields of LogLeaf. We can avoid joining with LeafData table here.
+		return t.GetLeavesByRange(ctx, int64(t.root.TreeSize), int64(limit))
+	}
+
+	start := time.Now()
+	rows, err := t.tx.QueryContext(ctx, selectQueuedLeavesSQL, t.treeID, cutoffTime.UnixNano(), limit)
+	if err != nil {
+		glog.Warningf("%sFailed to select rows for work: %s", requestid.Prefix(ctx), err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	leaves := make([]*trillian.LogLeaf, 0, limit)
+	for rows.Next() {
+		leaf, dqInfo, err := t.dequeueLeaf(rows)
+		if err != nil {
+			glog.Warningf("%sError dequeuing leaf: %v", requestid.Prefix(ctx), err)
+			return nil, err
+		}
+
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return nil, errors.New("dequeued a leaf with incorrect hash size")
+		}
+
+		k := string(leaf.LeafIdentityHash)
+		if _, ok := t.dequeued[k]; ok {
+			// dupe, user probably called DequeueLeaves more than once.
+			continue
+		}
+		t.dequeued[k] = dqInfo
+		leaves = append(leaves, leaf)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	label := labelForTX(t)
+	observe(ctx, dequeueSelectLatency, time.Since(start), label)
+	observe(ctx, dequeueLatency, time.Since(start), label)
+	dequeuedCounter.Add(float64(len(leaves)), label)
+
+	return leaves, nil
+}
+
+// sortLeavesForInsert returns a slice containing the passed in leaves sorted
+// by LeafIdentityHash, and paired with their original positions.
+func sortLeavesForInsert(leaves []*trillian.LogLeaf) []leafAndPosition {
+	ordLeaves := make([]leafAndPosition, len(leaves))
+	for i, leaf := range leaves {
+		ordLeaves[i] = leafAndPosition{leaf: leaf, idx: i}
+	}
+	sort.Sort(byLeafIdentityHashWithPosition(ordLeaves))
+	return ordLeaves
+}
+
+func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.LogLeaf, error) {
+	// Don't accept batches if any of the leaves are invalid.
+	for _, leaf := range leaves {
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return nil, fmt.Errorf("queued leaf must have a leaf ID hash of length %d", t.hashSizeBytes)
+		}
+		var err error
+		leaf.QueueTimestamp, err = ptypes.TimestampProto(queueTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+	}
+	start := time.Now()
+	label := labelForTX(t)
+
+	ordLeaves := sortLeavesForInsert(leaves)
+	existingCount := 0
+	existingLeaves := make([]*trillian.LogLeaf, len(leaves))
+
+	for _, ol := range ordLeaves {
+		i, leaf := ol.idx, ol.leaf
+
+		leafStart := time.Now()
+		qTimestamp, err := ptypes.Timestamp(leaf.QueueTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		_, err = t.tx.ExecContext(ctx, insertLeafDataSQL, t.treeID, leaf.LeafIdentityHash, leaf.LeafValue, leaf.ExtraData, qTimestamp.UnixNano())
+		insertDuration := time.Since(leafStart)
+		observe(ctx, queueInsertLeafLatency, insertDuration, label)
+		if isDuplicateErr(err) {
+			// Remember the duplicate leaf, using the requested leaf for now.
+			existingLeaves[i] = leaf
+			existingCount++
+			queuedDupCounter.Inc(label)
+			continue
+		}
+		if err != nil {
+			glog.Warningf("%sError inserting %d into LeafData: %s", requestid.Prefix(ctx), i, err)
+			return nil, err
+		}
+
+		// Create the work queue entry
+		_, err = t.tx.ExecContext(ctx, insertUnsequencedEntrySQL, t.treeID, leaf.LeafIdentityHash, leaf.MerkleLeafHash, qTimestamp.UnixNano())
+		if err != nil {
+			glog.Warningf("%sError inserting into Unsequenced: %s", requestid.Prefix(ctx), err)
+			return nil, err
+		}
+		leafDuration := time.Since(leafStart)
+		observe(ctx, queueInsertEntryLatency, (leafDuration - insertDuration), label)
+	}
+	insertDuration := time.Since(start)
+	observe(ctx, queueInsertLatency, insertDuration, label)
+	queuedCounter.Add(float64(len(leaves)), label)
+
+	if existingCount == 0 {
+		return existingLeaves, nil
+	}
+
+	// For existing leaves, we need to retrieve the contents.  First collate the desired LeafIdentityHash values.
+	var toRetrieve [][]byte
+	for _, existing := range existingLeaves {
+		if existing != nil {
+			toRetrieve = append(toRetrieve, existing.LeafIdentityHash)
+		}
+	}
+	results, err := t.getLeafDataByIdentityHash(ctx, toRetrieve)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve existing leaves: %v", err)
+	}
+	if len(results) != len(toRetrieve) {
+		return nil, fmt.Errorf("failed to retrieve all existing leaves: got %d, want %d", len(results), len(toRetrieve))
+	}
+	// Replace the requested leaves with the actual leaves.
+	for i, requested := range existingLeaves {
+		if requested == nil {
+			continue
+		}
+		found := false
+		for _, result := range results {
+			if bytes.Equal(result.LeafIdentityHash, requested.LeafIdentityHash) {
+				existingLeaves[i] = result
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("failed to find existing leaf for hash %x", requested.LeafIdentityHash)
+		}
+	}
+	totalDuration := time.Since(start)
+	readDuration := totalDuration - insertDuration
+	observe(ctx, queueReadLatency, readDuration, label)
+	observe(ctx, queueLatency, totalDuration, label)
+
+	return existingLeaves, nil
+}
+
+func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	res := make([]*trillian.QueuedLogLeaf, len(leaves))
+	ok := status.New(codes.OK, "OK").Proto()
+
+	// Leaves in this transaction are inserted in two tables. For each leaf, if
+	// the second of the two inserts fails, we remove the side effect of the
+	// first by rolling back to a savepoint installed before it. Unlike in
+	// MySQL, SQLite savepoints of the same name nest rather than replace each
+	// other, so each leaf's savepoint is released once the leaf is done.
+	const savepoint = "SAVEPOINT AddSequencedLeaves"
+	const release = "RELEASE AddSequencedLeaves"
+
+	ordLeaves := sortLeavesForInsert(leaves)
+	for _, ol := range ordLeaves {
+		i, leaf := ol.idx, ol.leaf
+
+		// This should fail on insert, but catch it early.
+		if got, want := len(leaf.LeafIdentityHash), t.hashSizeBytes; got != want {
+			return nil, status.Errorf(codes.FailedPrecondition, "leaves[%d] has incorrect hash size %d, want %d", i, got, want)
+		}
+
+		if _, err := t.tx.ExecContext(ctx, savepoint); err != nil {
+			glog.Errorf("%sError adding savepoint: %s", requestid.Prefix(ctx), err)
+			return nil, err
+		}
+
+		res[i] = &trillian.QueuedLogLeaf{Status: ok}
+
+		_, err := t.tx.ExecContext(ctx, insertLeafDataSQL,
+			t.treeID, leaf.LeafIdentityHash, leaf.LeafValue, leaf.ExtraData, timestamp.UnixNano())
+		if isDuplicateErr(err) {
+			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIdentityHash").Proto()
+		} else if err != nil {
+			glog.Errorf("%sError inserting leaves[%d] into LeafData: %s", requestid.Prefix(ctx), i, err)
+			return nil, err
+		} else {
+			_, err = t.tx.ExecContext(ctx, insertSequencedLeafSQL,
+				t.treeID, leaf.LeafIdentityHash, leaf.MerkleLeafHash, leaf.LeafIndex, 0)
+			if isDuplicateErr(err) {
+				res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto()
+				if _, err := t.tx.ExecContext(ctx, "ROLLBACK TO AddSequencedLeaves"); err != nil {
+					glog.Errorf("%sError rolling back to savepoint: %s", requestid.Prefix(ctx), err)
+					return nil, err
+				}
+			} else if err != nil {
+				glog.Errorf("%sError inserting leaves[%d] into SequencedLeafData: %s", requestid.Prefix(ctx), i, err)
+				return nil, err
+			}
+		}
+
+		if _, err := t.tx.ExecContext(ctx, release); err != nil {
+			glog.Errorf("%sError releasing savepoint: %s", requestid.Prefix(ctx), err)
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
+	var sequencedLeafCount int64
+
+	err := t.tx.QueryRowContext(ctx, selectSequencedLeafCountSQL, t.treeID).Scan(&sequencedLeafCount)
+	if err != nil {
+		glog.Warningf("Error getting sequenced leaf count: %s", err)
+	}
+
+	return sequencedLeafCount, err
+}
+
+func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		for _, leaf := range leaves {
+			if leaf < 0 {
+				return nil, status.Errorf(codes.InvalidArgument, "index %d is < 0", leaf)
+			}
+			if leaf >= treeSize {
+				return nil, status.Errorf(codes.OutOfRange, "invalid leaf index %d, want < TreeSize(%d)", leaf, treeSize)
+			}
+		}
+	}
+	if len(leaves) == 0 {
+		return []*trillian.LogLeaf{}, nil
+	}
+
+	var args []interface{}
+	for _, nodeID := range leaves {
+		args = append(args, int64(nodeID))
+	}
+	args = append(args, t.treeID)
+	rows, err := t.tx.QueryContext(ctx, expandPlaceholderSQL(selectLeavesByIndexSQL, len(leaves), "?", "?"), args...)
+	if err != nil {
+		glog.Warningf("Failed to get leaves by idx: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := make([]*trillian.LogLeaf, 0, len(leaves))
+	for rows.Next() {
+		leaf, err := scanLeaf(rows)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, leaf)
+	}
+	if err := rows.Err(); err != nil {
+		glog.Warningf("Failed to read returned leaves: %s", err)
+		return nil, err
+	}
+
+	if got, want := len(ret), len(leaves); got != want {
+		return nil, status.Errorf(codes.Internal, "len(ret): %d, want %d", got, want)
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	count, err := t.clipLeafRange(start, count)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*trillian.LogLeaf, 0, count)
+	if err := t.streamLeavesByRangeInternal(ctx, start, count, func(leaf *trillian.LogLeaf) error {
+		ret = append(ret, leaf)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+var _ storage.LeafStreamer = &logTreeTX{}
+
+// StreamLeavesByRange calls fn with each leaf in [start, start+count), read
+// with a single query. It implements storage.LeafStreamer.
+func (t *logTreeTX) StreamLeavesByRange(ctx context.Context, start, count int64, fn func(*trillian.LogLeaf) error) error {
+	count, err := t.clipLeafRange(start, count)
+	if err != nil {
+		return err
+	}
+	return t.streamLeavesByRangeInternal(ctx, start, count, fn)
+}
+
+// clipLeafRange checks the range of leaves [start, start+count), and returns
+// its count clipped to the size of the tree for LOG trees.
+func (t *logTreeTX) clipLeafRange(start, count int64) (int64, error) {
+	if count <= 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+	}
+	if start < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
+	}
+
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		if treeSize <= 0 {
+			return 0, status.Errorf(codes.OutOfRange, "empty tree")
+		} else if start >= treeSize {
+			return 0, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
+		}
+		// Ensure no entries queried/returned beyond the tree.
+		if maxCount := treeSize - start; count > maxCount {
+			count = maxCount
+		}
+	}
+	return count, nil
+}
+
+// streamLeavesByRangeInternal calls fn with each leaf in the checked range
+// [start, start+count), as they are scanned.
+func (t *logTreeTX) streamLeavesByRangeInternal(ctx context.Context, start, count int64, fn func(*trillian.LogLeaf) error) error {
+	rows, err := t.tx.QueryContext(ctx, selectLeavesByRangeSQL, t.treeID, start, start+count)
+	if err != nil {
+		glog.Warningf("Failed to get leaves by range: %s", err)
+		return err
+	}
+	defer rows.Close()
+
+	for wantIndex := start; rows.Next(); wantIndex++ {
+		leaf, err := scanLeaf(rows)
+		if err != nil {
+			return err
+		}
+		if leaf.LeafIndex != wantIndex {
+			if wantIndex < int64(t.root.TreeSize) {
+				return fmt.Errorf("got unexpected index %d, want %d", leaf.LeafIndex, wantIndex)
+			}
+			break
+		}
+		if err := fn(leaf); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		glog.Warningf("Failed to read returned leaves: %s", err)
+		return err
+	}
+	return nil
+}
+
+// scanLeaf reads a sequenced leaf from a row of one of the leaf-selection
+// statements.
+func scanLeaf(rows *sql.Rows) (*trillian.LogLeaf, error) {
+	leaf := &trillian.LogLeaf{}
+	var qTimestamp, iTimestamp int64
+	if err := rows.Scan(
+		&leaf.MerkleLeafHash,
+		&leaf.LeafIdentityHash,
+		&leaf.LeafValue,
+		&leaf.LeafIndex,
+		&leaf.ExtraData,
+		&qTimestamp,
+		&iTimestamp); err != nil {
+		glog.Warningf("Failed to scan merkle leaves: %s", err)
+		return nil, err
+	}
+	var err error
+	leaf.QueueTimestamp, err = ptypes.TimestampProto(time.Unix(0, qTimestamp))
+	if err != nil {
+		return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+	}
+	leaf.IntegrateTimestamp, err = ptypes.TimestampProto(time.Unix(0, iTimestamp))
+	if err != nil {
+		return nil, fmt.Errorf("got invalid integrate timestamp: %v", err)
+	}
+	return leaf, nil
+}
+
+func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	query := selectLeavesByMerkleHashSQL
+	if orderBySequence {
+		query = selectLeavesByMerkleHashOrderedBySequenceSQL
+	}
+	return t.getLeavesByHashInternal(ctx, leafHashes, query, "merkle")
+}
+
+// getLeafDataByIdentityHash retrieves leaf data by LeafIdentityHash, returned
+// as a slice of LogLeaf objects for convenience.  However, note that the
+// returned LogLeaf objects will only have a valid MerkleLeafHash, LeafIndex and
+// IntegrateTimestamp if they have been integrated.
+func (t *logTreeTX) getLeafDataByIdentityHash(ctx context.Context, leafHashes [][]byte) ([]*trillian.LogLeaf, error) {
+	return t.getLeavesByHashInternal(ctx, leafHashes, selectLeavesByLeafIdentityHashSQL, "leaf-identity")
+}
+
+func (t *logTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	if t.slr == nil {
+		return nil, storage.ErrTreeNeedsInit
+	}
+
+	return t.slr, nil
+}
+
+// fetchLatestRoot reads the latest SignedLogRoot from the DB and returns it.
+func (t *logTreeTX) fetchLatestRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	var timestamp, treeSize, treeRevision int64
+	var rootHash, rootSignatureBytes []byte
+	if err := t.tx.QueryRowContext(
+		ctx, selectLatestSignedLogRootSQL, t.treeID).Scan(
+		&timestamp, &treeSize, &rootHash, &treeRevision, &rootSignatureBytes,
+	); err == sql.ErrNoRows {
+		// It's possible there are no roots for this tree yet
+		return nil, storage.ErrTreeNeedsInit
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Put logRoot back together. Fortunately LogRoot has a deterministic serialization.
+	logRoot, err := (&types.LogRootV1{
+		RootHash:       rootHash,
+		TimestampNanos: uint64(timestamp),
+		Revision:       uint64(treeRevision),
+		TreeSize:       uint64(treeSize),
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &trillian.SignedLogRoot{
+		KeyHint:          types.SerializeKeyHint(t.treeID),
+		LogRoot:          logRoot,
+		LogRootSignature: rootSignatureBytes,
+	}, nil
+}
+
+func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
+	var logRoot types.LogRootV1
+	if err := logRoot.UnmarshalBinary(root.LogRoot); err != nil {
+		glog.Warningf("Failed to parse log root: %x %v", root.LogRoot, err)
+		return err
+	}
+	if got, want := int64(logRoot.Revision), t.treeTX.writeRevision; got != want {
+		return status.Errorf(codes.Internal, "root.Revision: %v, want %v", got, want)
+	}
+	if len(logRoot.Metadata) != 0 {
+		return fmt.Errorf("unimplemented: sqlite storage does not support log root metadata")
+	}
+
+	stored, err := latestSignerEpoch(ctx, t.tx, selectTreeHeadEpochSQL, t.treeID)
+	if err != nil {
+		return err
+	}
+	epoch, err := storage.CheckSignerEpoch(ctx, t.treeID, stored)
+	if err != nil {
+		return err
+	}
+
+	res, err := t.tx.ExecContext(
+		ctx,
+		insertTreeHeadSQL,
+		t.treeID,
+		int64(logRoot.TimestampNanos),
+		int64(logRoot.TreeSize),
+		logRoot.RootHash,
+		int64(logRoot.Revision),
+		root.LogRootSignature,
+		epoch)
+	if err != nil {
+		glog.Warningf("Failed to store signed root: %s", err)
+	}
+
+	return checkResultOkAndRowCountIs(res, err, 1)
+}
+
+func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]byte, query, desc string) ([]*trillian.LogLeaf, error) {
+	if len(leafHashes) == 0 {
+		return nil, nil
+	}
+	var args []interface{}
+	for _, hash := range leafHashes {
+		args = append(args, []byte(hash))
+	}
+	args = append(args, t.treeID)
+	rows, err := t.tx.QueryContext(ctx, expandPlaceholderSQL(query, len(leafHashes), "?", "?"), args...)
+	if err != nil {
+		glog.Warningf("Query() %s hash = %v", desc, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// The tree could include duplicates so we don't know how many results will be returned
+	var ret []*trillian.LogLeaf
+	for rows.Next() {
+		leaf := &trillian.LogLeaf{}
+		// We might be using a LEFT JOIN in our statement, so leaves which are
+		// queued but not yet integrated will have a NULL IntegrateTimestamp
+		// when there's no corresponding entry in SequencedLeafData, even though
+		// the table definition forbids that, so we use a nullable type here and
+		// check its validity below.
+		var integrateTS sql.NullInt64
+		var queueTS int64
+
+		if err := rows.Scan(&leaf.MerkleLeafHash, &leaf.LeafIdentityHash, &leaf.LeafValue, &leaf.LeafIndex, &leaf.ExtraData, &queueTS, &integrateTS); err != nil {
+			glog.Warningf("LogID: %d Scan() %s = %s", t.treeID, desc, err)
+			return nil, err
+		}
+		var err error
+		leaf.QueueTimestamp, err = ptypes.TimestampProto(time.Unix(0, queueTS))
+		if err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		if integrateTS.Valid {
+			leaf.IntegrateTimestamp, err = ptypes.TimestampProto(time.Unix(0, integrateTS.Int64))
+			if err != nil {
+				return nil, fmt.Errorf("got invalid integrate timestamp: %v", err)
+			}
+		}
+
+		if got, want := len(leaf.MerkleLeafHash), t.hashSizeBytes; got != want {
+			return nil, fmt.Errorf("LogID: %d Scanned leaf %s does not have hash length %d, got %d", t.treeID, desc, want, got)
+		}
+
+		ret = append(ret, leaf)
+	}
+	if err := rows.Err(); err != nil {
+		glog.Warningf("Failed to read returned leaves: %s", err)
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// leafAndPosition records original position before sort.
+type leafAndPosition struct {
+	leaf *trillian.LogLeaf
+	idx  int
+}
+
+// byLeafIdentityHashWithPosition allows sorting (as above), but where we need
+// to remember the original position
+type byLeafIdentityHashWithPosition []leafAndPosition
+
+func (l byLeafIdentityHashWithPosition) Len() int {
+	return len(l)
+}
+
+func (l byLeafIdentityHashWithPosition) Swap(i, j int) {
+	l[i], l[j] = l[j], l[i]
+}
+
+func (l byLeafIdentityHashWithPosition) Less(i, j int) bool {
+	return bytes.Compare(l[i].leaf.LeafIdentityHash, l[j].leaf.LeafIdentityHash) == -1
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"flag"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+)
+
+var (
+	sqlitePath            = flag.String("sqlite_path", "trillian.db", "Path of the SQLite database file, which is created if it doesn't exist, or :memory: for a database held in memory until the server exits")
+	sqliteSharedSubtrees  = flag.Int("sqlite_shared_subtree_cache_size", 0, "If positive, the number of log subtrees cached across transactions, which saves reading the same subtrees of a log on every sequencing pass")
+	sqliteOnce            sync.Once
+	sqliteOnceErr         error
+	sqliteStorageInstance *sqliteProvider
+)
+
+func init() {
+	if err := storage.RegisterProvider("sqlite", newSQLiteProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider sqlite: %v", err)
+	}
+}
+
+type sqliteProvider struct {
+	db          *sql.DB
+	mf          monitoring.MetricFactory
+	stopMonitor func()
+	logOpts     LogStorageOptions
+}
+
+func newSQLiteProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+	sqliteOnce.Do(func() {
+		var db *sql.DB
+		db, sqliteOnceErr = OpenDB(*sqlitePath)
+		if sqliteOnceErr != nil {
+			return
+		}
+		sqliteStorageInstance = &sqliteProvider{
+			db:          db,
+			mf:          mf,
+			stopMonitor: storage.MonitorSQLPool(db, "sqlite", mf, storage.SQLPoolStatsInterval),
+		}
+		if *sqliteSharedSubtrees > 0 {
+			sqliteStorageInstance.logOpts.SharedSubtrees = cache.NewSharedLogSubtrees(*sqliteSharedSubtrees, mf)
+		}
+	})
+	if sqliteOnceErr != nil {
+		return nil, sqliteOnceErr
+	}
+	return sqliteStorageInstance, nil
+}
+
+func (s *sqliteProvider) LogStorage() storage.LogStorage {
+	return NewLogStorageWithOpts(s.db, s.mf, s.logOpts)
+}
+
+func (s *sqliteProvider) MapStorage() storage.MapStorage {
+	return NewMapStorage(s.db)
+}
+
+func (s *sqliteProvider) AdminStorage() storage.AdminStorage {
+	return NewAdminStorage(s.db)
+}
+
+func (s *sqliteProvider) Close() error {
+	s.stopMonitor()
+	return s.db.Close()
+}
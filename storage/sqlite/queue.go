@@ -0,0 +1,120 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/util/requestid"
+)
+
+const (
+	selectQueuedLeavesSQL = `SELECT LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos
+			FROM Unsequenced
+			WHERE TreeId=?
+			AND QueueTimestampNanos<=?
+			ORDER BY QueueTimestampNanos,LeafIdentityHash ASC LIMIT ?`
+	insertUnsequencedEntrySQL = `INSERT INTO Unsequenced(TreeId,LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos)
+			VALUES(?,?,?,?)`
+	deleteUnsequencedSQL = "DELETE FROM Unsequenced WHERE TreeId=? AND QueueTimestampNanos=? AND LeafIdentityHash=?"
+)
+
+type dequeuedLeaf struct {
+	queueTimestampNanos int64
+	leafIdentityHash    []byte
+}
+
+func (t *logTreeTX) dequeueLeaf(rows *sql.Rows) (*trillian.LogLeaf, dequeuedLeaf, error) {
+	var leafIDHash []byte
+	var merkleHash []byte
+	var queueTimestamp int64
+
+	err := rows.Scan(&leafIDHash, &merkleHash, &queueTimestamp)
+	if err != nil {
+		glog.Warningf("Error scanning work rows: %s", err)
+		return nil, dequeuedLeaf{}, err
+	}
+
+	// Note: the LeafData and ExtraData being nil here is OK as this is only used by the
+	// sequencer. The sequencer only writes to the SequencedLeafData table and the client
+	// supplied data was already written to LeafData as part of queueing the leaf.
+	queueTimestampProto, err := ptypes.TimestampProto(time.Unix(0, queueTimestamp))
+	if err != nil {
+		return nil, dequeuedLeaf{}, fmt.Errorf("got invalid queue timestamp: %v", err)
+	}
+	leaf := &trillian.LogLeaf{
+		LeafIdentityHash: leafIDHash,
+		MerkleLeafHash:   merkleHash,
+		QueueTimestamp:   queueTimestampProto,
+	}
+	return leaf, dequeuedLeaf{queueTimestampNanos: queueTimestamp, leafIdentityHash: leafIDHash}, nil
+}
+
+func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	dequeuedLeaves := make([]dequeuedLeaf, 0, len(leaves))
+	for _, leaf := range leaves {
+		// This should fail on insert but catch it early
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return errors.New("sequenced leaf has incorrect hash size")
+		}
+
+		iTimestamp, err := ptypes.Timestamp(leaf.IntegrateTimestamp)
+		if err != nil {
+			return fmt.Errorf("got invalid integrate timestamp: %v", err)
+		}
+		_, err = t.tx.ExecContext(
+			ctx,
+			insertSequencedLeafSQL,
+			t.treeID,
+			leaf.LeafIdentityHash,
+			leaf.MerkleLeafHash,
+			leaf.LeafIndex,
+			iTimestamp.UnixNano())
+		if err != nil {
+			glog.Warningf("%sFailed to update sequenced leaves: %s", requestid.Prefix(ctx), err)
+			return err
+		}
+
+		qe, ok := t.dequeued[string(leaf.LeafIdentityHash)]
+		if !ok {
+			return fmt.Errorf("attempting to update leaf that wasn't dequeued. IdentityHash: %x", leaf.LeafIdentityHash)
+		}
+		dequeuedLeaves = append(dequeuedLeaves, qe)
+	}
+
+	return t.removeSequencedLeaves(ctx, dequeuedLeaves)
+}
+
+// removeSequencedLeaves removes the passed in leaves from the queue.
+func (t *logTreeTX) removeSequencedLeaves(ctx context.Context, leaves []dequeuedLeaf) error {
+	start := time.Now()
+	for _, dql := range leaves {
+		result, err := t.tx.ExecContext(ctx, deleteUnsequencedSQL, t.treeID, dql.queueTimestampNanos, dql.leafIdentityHash)
+		if err := checkResultOkAndRowCountIs(result, err, 1); err != nil {
+			glog.Warningf("%sFailed to remove sequenced leaves: %s", requestid.Prefix(ctx), err)
+			return err
+		}
+	}
+
+	observe(ctx, dequeueRemoveLatency, time.Since(start), labelForTX(t))
+	return nil
+}
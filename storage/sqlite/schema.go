@@ -0,0 +1,147 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+// schema is the SQLite version of the tree schema, which OpenDB creates in
+// databases which don't have it yet. It follows the MySQL schema in
+// storage/mysql/schema/storage.sql, without the tables of the features which
+// the SQLite storage doesn't support.
+//
+// The driver binds empty byte slices as NULL, so the BLOB columns which may
+// hold empty values, like the root hash of an empty tree, are nullable.
+const schema = `
+CREATE TABLE IF NOT EXISTS Trees(
+  TreeId                INTEGER NOT NULL,
+  TreeState             TEXT NOT NULL CHECK(TreeState IN ('ACTIVE', 'FROZEN', 'DRAINING')),
+  TreeType              TEXT NOT NULL CHECK(TreeType IN ('LOG', 'MAP', 'PREORDERED_LOG')),
+  HashStrategy          TEXT NOT NULL,
+  HashAlgorithm         TEXT NOT NULL,
+  SignatureAlgorithm    TEXT NOT NULL,
+  DisplayName           TEXT,
+  Description           TEXT,
+  CreateTimeMillis      INTEGER NOT NULL,
+  UpdateTimeMillis      INTEGER NOT NULL,
+  MaxRootDurationMillis INTEGER NOT NULL,
+  PrivateKey            BLOB NOT NULL,
+  PublicKey             BLOB NOT NULL,
+  Deleted               BOOLEAN,
+  DeleteTimeMillis      INTEGER,
+  RateLimits            BLOB,
+  Labels                TEXT,
+  CreateRequestId       TEXT UNIQUE,
+  Maintenance           BLOB,
+  MapStrata             TEXT,
+  MapCompression        TEXT,
+  MapHasher             TEXT,
+  AutoInit              BOOLEAN NOT NULL DEFAULT FALSE,
+  MaxStorageBytes       INTEGER NOT NULL DEFAULT 0,
+  MapKeyIndex           BOOLEAN NOT NULL DEFAULT FALSE,
+  RetiredPublicKeys     BLOB,
+  PRIMARY KEY(TreeId)
+);
+
+CREATE TABLE IF NOT EXISTS TreeControl(
+  TreeId                  INTEGER NOT NULL,
+  SigningEnabled          BOOLEAN NOT NULL,
+  SequencingEnabled       BOOLEAN NOT NULL,
+  SequenceIntervalSeconds INTEGER NOT NULL,
+  PRIMARY KEY(TreeId),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS Subtree(
+  TreeId               INTEGER NOT NULL,
+  SubtreeId            BLOB NOT NULL,
+  Nodes                BLOB NOT NULL,
+  SubtreeRevision      INTEGER NOT NULL,
+  PRIMARY KEY(TreeId, SubtreeId, SubtreeRevision),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS TreeHead(
+  TreeId               INTEGER NOT NULL,
+  TreeHeadTimestamp    INTEGER,
+  TreeSize             INTEGER,
+  RootHash             BLOB,
+  RootSignature        BLOB,
+  TreeRevision         INTEGER,
+  SignerEpoch          INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY(TreeId, TreeHeadTimestamp),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS TreeHeadRevisionIdx
+  ON TreeHead(TreeId, TreeRevision);
+
+CREATE TABLE IF NOT EXISTS LeafData(
+  TreeId               INTEGER NOT NULL,
+  LeafIdentityHash     BLOB NOT NULL,
+  LeafValue            BLOB,
+  ExtraData            BLOB,
+  QueueTimestampNanos  INTEGER NOT NULL,
+  PRIMARY KEY(TreeId, LeafIdentityHash),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS SequencedLeafData(
+  TreeId                  INTEGER NOT NULL,
+  SequenceNumber          INTEGER NOT NULL CHECK(SequenceNumber >= 0),
+  LeafIdentityHash        BLOB NOT NULL,
+  MerkleLeafHash          BLOB NOT NULL,
+  IntegrateTimestampNanos INTEGER NOT NULL,
+  PRIMARY KEY(TreeId, SequenceNumber),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE,
+  FOREIGN KEY(TreeId, LeafIdentityHash) REFERENCES LeafData(TreeId, LeafIdentityHash) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS SequencedLeafMerkleIdx
+  ON SequencedLeafData(TreeId, MerkleLeafHash);
+
+CREATE TABLE IF NOT EXISTS Unsequenced(
+  TreeId               INTEGER NOT NULL,
+  LeafIdentityHash     BLOB NOT NULL,
+  MerkleLeafHash       BLOB NOT NULL,
+  QueueTimestampNanos  INTEGER NOT NULL,
+  PRIMARY KEY (TreeId, QueueTimestampNanos, LeafIdentityHash)
+);
+
+CREATE TABLE IF NOT EXISTS MapLeaf(
+  TreeId                INTEGER NOT NULL,
+  KeyHash               BLOB NOT NULL,
+  MapRevision           INTEGER NOT NULL,
+  LeafValue             BLOB,
+  LeafKey               BLOB,
+  PRIMARY KEY(TreeId, KeyHash, MapRevision),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS MapLeafKeyIdx
+  ON MapLeaf(TreeId, LeafKey, KeyHash);
+
+CREATE TABLE IF NOT EXISTS MapHead(
+  TreeId               INTEGER NOT NULL,
+  MapHeadTimestamp     INTEGER,
+  RootHash             BLOB,
+  MapRevision          INTEGER,
+  RootSignature        BLOB,
+  MapperData           BLOB,
+  SignerEpoch          INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY(TreeId, MapHeadTimestamp),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS MapHeadRevisionIdx
+  ON MapHead(TreeId, MapRevision);
+`
@@ -0,0 +1,97 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/trillian/integration/storagetest"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+)
+
+// openTestDB opens a fresh in-memory database, which is closed when the test
+// finishes.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenDB(memoryPath)
+	if err != nil {
+		t.Fatalf("OpenDB(): %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestAdminStorage(t *testing.T) {
+	tester := &testonly.AdminStorageTester{NewAdminStorage: func() storage.AdminStorage {
+		return NewAdminStorage(openTestDB(t))
+	}}
+	tester.RunAllTests(t)
+}
+
+func TestLogIntegration(t *testing.T) {
+	storageFactory := func(context.Context, *testing.T) (storage.LogStorage, storage.AdminStorage) {
+		db := openTestDB(t)
+		return NewLogStorage(db, nil), NewAdminStorage(db)
+	}
+
+	storagetest.RunLogStorageTests(t, storageFactory)
+}
+
+func TestMapIntegration(t *testing.T) {
+	storageFactory := func(context.Context, *testing.T) (storage.MapStorage, storage.AdminStorage) {
+		db := openTestDB(t)
+		return NewMapStorage(db), NewAdminStorage(db)
+	}
+
+	storagetest.RunMapStorageTests(t, storageFactory)
+}
+
+func TestOpenDBFile(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "trillian.db")
+
+	db, err := OpenDB(path)
+	if err != nil {
+		t.Fatalf("OpenDB(): %v", err)
+	}
+	tree, err := storage.CreateTree(ctx, NewAdminStorage(db), testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+
+	// The tree outlives the database, whose schema is left alone on reopening.
+	db, err = OpenDB(path)
+	if err != nil {
+		t.Fatalf("OpenDB() again: %v", err)
+	}
+	defer db.Close()
+	if _, err := storage.GetTree(ctx, NewAdminStorage(db), tree.TreeId); err != nil {
+		t.Errorf("GetTree() after reopening: %v", err)
+	}
+}
@@ -0,0 +1,437 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/compress"
+	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/tree"
+	"modernc.org/sqlite"
+)
+
+const (
+	placeholderSQL        = "<placeholder>"
+	insertSubtreeMultiSQL = `INSERT INTO Subtree(TreeId, SubtreeId, Nodes, SubtreeRevision) ` + placeholderSQL
+	selectSubtreeSQL      = `
+		SELECT x.SubtreeId, x.MaxRevision, Subtree.Nodes
+		FROM (
+			SELECT n.SubtreeId, max(n.SubtreeRevision) AS MaxRevision
+			FROM Subtree n
+			WHERE n.SubtreeId IN (` + placeholderSQL + `) AND
+			n.TreeId = ? AND n.SubtreeRevision <= ?
+			GROUP BY n.SubtreeId
+		) AS x
+		INNER JOIN Subtree
+		ON Subtree.SubtreeId = x.SubtreeId
+		AND Subtree.SubtreeRevision = x.MaxRevision
+		AND Subtree.TreeId = ?`
+	insertTreeHeadSQL = `INSERT INTO TreeHead(TreeId,TreeHeadTimestamp,TreeSize,RootHash,TreeRevision,RootSignature,SignerEpoch)
+		VALUES(?,?,?,?,?,?,?)`
+	selectTreeHeadEpochSQL = `SELECT SignerEpoch FROM TreeHead WHERE TreeId=?
+		 ORDER BY TreeHeadTimestamp DESC LIMIT 1`
+
+	// memoryPath is the path of a database which is held in memory, and is
+	// lost when it is closed.
+	memoryPath = ":memory:"
+)
+
+// maxSubtreesPerInsert is the number of subtrees written by each INSERT, as
+// SQLite allows at most 32766 parameters per statement.
+const maxSubtreesPerInsert = 32766 / 4
+
+// sqliteTreeStorage contains the sqliteLogStorage implementation.
+type sqliteTreeStorage struct {
+	db *sql.DB
+}
+
+// OpenDB opens the SQLite database at the given path, or a fresh in-memory
+// database if path is ":memory:", and creates the tree schema in it if it
+// doesn't have it yet.
+//
+// The database is used through a single connection, so its transactions
+// are serialized, as SQLite only allows one writer at a time anyway. This
+// also keeps in-memory databases, which are private to their connection,
+// alive until the database is closed.
+func OpenDB(path string) (*sql.DB, error) {
+	db := sql.OpenDB(&connector{path: path})
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	if _, err := db.Exec(schema); err != nil {
+		glog.Warningf("Could not create SQLite schema in %q: %s", path, err)
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// connector opens connections to the SQLite database at path, and configures
+// them for use by the storage.
+type connector struct {
+	path string
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.path)
+	if err != nil {
+		return nil, err
+	}
+	pragmas := []string{
+		"PRAGMA foreign_keys = ON",
+		"PRAGMA busy_timeout = 10000",
+	}
+	if c.path != memoryPath {
+		pragmas = append(pragmas, "PRAGMA journal_mode = WAL")
+	}
+	execer := conn.(driver.ExecerContext)
+	for _, p := range pragmas {
+		if _, err := execer.ExecContext(ctx, p, nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: %v", p, err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return &sqlite.Driver{}
+}
+
+func newTreeStorage(db *sql.DB) *sqliteTreeStorage {
+	return &sqliteTreeStorage{db: db}
+}
+
+// expandPlaceholderSQL expands an sql statement by adding a specified number
+// of '?' placeholder slots. At most one placeholder will be expanded.
+//
+// Statements are not prepared and cached as the other SQL storages do, as
+// preparing them on the database would wait for its only connection, which
+// the transaction running them holds.
+func expandPlaceholderSQL(sql string, num int, first, rest string) string {
+	if num <= 0 {
+		panic(fmt.Errorf("trying to expand SQL placeholder with <= 0 parameters: %s", sql))
+	}
+
+	parameters := first + strings.Repeat(","+rest, num-1)
+
+	return strings.Replace(sql, placeholderSQL, parameters, 1)
+}
+
+func (s *sqliteTreeStorage) beginTreeTx(ctx context.Context, tree *trillian.Tree, hashSizeBytes int, subtreeCache *cache.SubtreeCache) (treeTX, error) {
+	t, err := s.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		glog.Warningf("Could not start tree TX: %s", err)
+		return treeTX{}, err
+	}
+	return treeTX{
+		tx:            t,
+		ts:            s,
+		treeID:        tree.TreeId,
+		treeType:      tree.TreeType,
+		hashSizeBytes: hashSizeBytes,
+		compression:   tree.MapCompression,
+		subtreeCache:  subtreeCache,
+		writeRevision: -1,
+	}, nil
+}
+
+type treeTX struct {
+	closed        bool
+	tx            *sql.Tx
+	ts            *sqliteTreeStorage
+	treeID        int64
+	treeType      trillian.TreeType
+	hashSizeBytes int
+	// compression is the compression of the subtrees written by this
+	// transaction. Subtrees are read whatever their compression.
+	compression  trillian.MapCompression
+	subtreeCache *cache.SubtreeCache
+	// shared, if set, caches subtrees read by this and other transactions.
+	// It is only set for logs.
+	shared        *cache.SharedLogSubtrees
+	writeRevision int64
+}
+
+func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID tree.NodeID2) (*storagepb.SubtreeProto, error) {
+	s, err := t.getSubtrees(ctx, treeRevision, []tree.NodeID2{nodeID})
+	if err != nil {
+		return nil, err
+	}
+	switch len(s) {
+	case 0:
+		return nil, nil
+	case 1:
+		return s[0], nil
+	default:
+		return nil, fmt.Errorf("got %d subtrees, but expected 1", len(s))
+	}
+}
+
+func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
+	glog.V(4).Infof("getSubtrees(")
+	if len(nodeIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(nodeIDs)+3)
+
+	// Populate args with node IDs.
+	for _, nodeID := range nodeIDs {
+		nodeIDBytes, err := subtreeKey(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		glog.V(4).Infof("  nodeID: %x", nodeIDBytes)
+		args = append(args, nodeIDBytes)
+	}
+
+	args = append(args, t.treeID)
+	args = append(args, treeRevision)
+	args = append(args, t.treeID)
+	rows, err := t.tx.QueryContext(ctx, expandPlaceholderSQL(selectSubtreeSQL, len(nodeIDs), "?", "?"), args...)
+	if err != nil {
+		glog.Warningf("Failed to get merkle subtrees: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := make([]*storagepb.SubtreeProto, 0, len(nodeIDs))
+
+	for rows.Next() {
+		var subtreeIDBytes []byte
+		var subtreeRev int64
+		var nodesRaw []byte
+		if err := rows.Scan(&subtreeIDBytes, &subtreeRev, &nodesRaw); err != nil {
+			glog.Warningf("Failed to scan merkle subtree: %s", err)
+			return nil, err
+		}
+		if nodesRaw, err = compress.Decompress(nodesRaw); err != nil {
+			glog.Warningf("Failed to decompress SubtreeProto: %s", err)
+			return nil, err
+		}
+		var subtree storagepb.SubtreeProto
+		if err := proto.Unmarshal(nodesRaw, &subtree); err != nil {
+			glog.Warningf("Failed to unmarshal SubtreeProto: %s", err)
+			return nil, err
+		}
+		if subtree.Prefix == nil {
+			subtree.Prefix = []byte{}
+		}
+		ret = append(ret, &subtree)
+
+		if glog.V(4) {
+			glog.Infof("  subtree: NID: %x, prefix: %x, depth: %d",
+				subtreeIDBytes, subtree.Prefix, subtree.Depth)
+			for k, v := range subtree.Leaves {
+				b, err := base64.StdEncoding.DecodeString(k)
+				if err != nil {
+					glog.Errorf("base64.DecodeString(%v): %v", k, err)
+				}
+				glog.Infof("     %x: %x", b, v)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		glog.Warningf("Failed to read merkle subtrees: %s", err)
+		return nil, err
+	}
+
+	// The InternalNodes cache is possibly nil here, but the SubtreeCache (which called
+	// this method) will re-populate it.
+	return ret, nil
+}
+
+// storeSubtrees writes the given subtrees at the write revision, in as few
+// statements as SQLite's limit on their parameters allows.
+func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.SubtreeProto) error {
+	if glog.V(4) {
+		glog.Infof("storeSubtrees(")
+		for _, s := range subtrees {
+			glog.Infof("  prefix: %x, depth: %d", s.Prefix, s.Depth)
+			for k, v := range s.Leaves {
+				b, err := base64.StdEncoding.DecodeString(k)
+				if err != nil {
+					glog.Errorf("base64.DecodeString(%v): %v", k, err)
+				}
+				glog.Infof("     %x: %x", b, v)
+			}
+		}
+	}
+	for len(subtrees) > 0 {
+		n := len(subtrees)
+		if n > maxSubtreesPerInsert {
+			n = maxSubtreesPerInsert
+		}
+		if err := t.insertSubtrees(ctx, subtrees[:n]); err != nil {
+			return err
+		}
+		subtrees = subtrees[n:]
+	}
+	return nil
+}
+
+func (t *treeTX) insertSubtrees(ctx context.Context, subtrees []*storagepb.SubtreeProto) error {
+	args := make([]interface{}, 0, len(subtrees)*4)
+
+	for _, s := range subtrees {
+		if s.Prefix == nil {
+			panic(fmt.Errorf("nil prefix on %v", s))
+		}
+		subtreeBytes, err := proto.Marshal(s)
+		if err != nil {
+			return err
+		}
+		if subtreeBytes, err = compress.Compress(t.compression, subtreeBytes); err != nil {
+			return err
+		}
+		args = append(args, t.treeID)
+		args = append(args, s.Prefix)
+		args = append(args, subtreeBytes)
+		args = append(args, t.writeRevision)
+	}
+
+	query := expandPlaceholderSQL(insertSubtreeMultiSQL, len(subtrees), "VALUES(?, ?, ?, ?)", "(?, ?, ?, ?)")
+	if _, err := t.tx.ExecContext(ctx, query, args...); err != nil {
+		glog.Warningf("Failed to set merkle subtrees: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (t *treeTX) Commit(ctx context.Context) error {
+	if t.writeRevision > -1 {
+		if err := t.subtreeCache.Flush(ctx, func(ctx context.Context, st []*storagepb.SubtreeProto) error {
+			return t.storeSubtrees(ctx, st)
+		}); err != nil {
+			glog.Warningf("TX commit flush error: %v", err)
+			return err
+		}
+	}
+	t.closed = true
+	if err := t.tx.Commit(); err != nil {
+		glog.Warningf("TX commit error: %s, stack:\n%s", err, string(debug.Stack()))
+		return err
+	}
+	return nil
+}
+
+func (t *treeTX) Rollback() error {
+	t.closed = true
+	if err := t.tx.Rollback(); err != nil {
+		glog.Warningf("TX rollback error: %s, stack:\n%s", err, string(debug.Stack()))
+		return err
+	}
+	return nil
+}
+
+func (t *treeTX) Close() error {
+	if !t.closed {
+		err := t.Rollback()
+		if err != nil {
+			glog.Warningf("Rollback error on Close(): %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *treeTX) GetMerkleNodes(ctx context.Context, treeRevision int64, nodeIDs []tree.NodeID) ([]tree.Node, error) {
+	return t.subtreeCache.GetNodes(nodeIDs, t.getSubtreesAtRev(ctx, treeRevision))
+}
+
+func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
+	for _, n := range nodes {
+		err := t.subtreeCache.SetNodeHash(n.NodeID, n.Hash,
+			func(nID tree.NodeID2) (*storagepb.SubtreeProto, error) {
+				return t.getSubtree(ctx, t.writeRevision, nID)
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *treeTX) IsOpen() bool {
+	return !t.closed
+}
+
+// getSubtreesAtRev returns a GetSubtreesFunc which reads at the passed in rev,
+// through the shared cache if there is one.
+func (t *treeTX) getSubtreesAtRev(ctx context.Context, rev int64) cache.GetSubtreesFunc {
+	get := func(ids []tree.NodeID2) ([]*storagepb.SubtreeProto, error) {
+		return t.getSubtrees(ctx, rev, ids)
+	}
+	if t.shared != nil {
+		return t.shared.Wrap(t.treeID, rev, get)
+	}
+	return get
+}
+
+// latestSignerEpoch returns the signer epoch of the latest root of the tree,
+// or 0 if it has none, read by the given query. No locking is needed, as the
+// transactions on the database are serialized.
+func latestSignerEpoch(ctx context.Context, tx *sql.Tx, query string, treeID int64) (int64, error) {
+	var epoch int64
+	if err := tx.QueryRowContext(ctx, query, treeID).Scan(&epoch); err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+	return epoch, nil
+}
+
+func checkResultOkAndRowCountIs(res sql.Result, err error, count int64) error {
+	// The Exec() might have just failed
+	if err != nil {
+		return err
+	}
+
+	// Otherwise we have to look at the result of the operation
+	rowsAffected, rowsError := res.RowsAffected()
+
+	if rowsError != nil {
+		return rowsError
+	}
+
+	if rowsAffected != count {
+		return fmt.Errorf("expected %d row(s) to be affected but saw: %d", count,
+			rowsAffected)
+	}
+
+	return nil
+}
+
+// subtreeKey returns a non-nil []byte suitable for use as a primary key column
+// for the subtree rooted at the passed-in node ID. Returns an error if the ID
+// is not aligned to bytes.
+func subtreeKey(id tree.NodeID2) ([]byte, error) {
+	tileID, err := tree.NewTileID(id)
+	if err != nil {
+		return nil, err
+	}
+	// The returned slice is not nil, as that would correspond to NULL in SQL.
+	return tileID.AsBytes(), nil
+}
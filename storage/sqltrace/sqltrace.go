@@ -0,0 +1,367 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqltrace records a trace span for every SQL statement executed for
+// a traced request, so that the statements which dominate the latency of RPCs
+// can be seen in a tracing backend.
+//
+// Statements executed in a transaction are recorded as children of the span
+// which was current when the transaction began, such as that of a storage
+// transaction, rather than of the span they are executed in. Statements which
+// aren't executed for a traced request, i.e. whose contexts have no span, are
+// not recorded.
+package sqltrace
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+
+	"go.opencensus.io/trace"
+)
+
+// Attributes of statement spans, as named by the OpenTelemetry semantic
+// conventions for databases.
+const (
+	SystemAttribute    = "db.system"
+	StatementAttribute = "db.statement"
+)
+
+// maxStatementLen is the length beyond which statements are truncated in
+// spans. Statements with many placeholders can be very long, and their
+// beginning identifies them well enough.
+const maxStatementLen = 1024
+
+// WrapConnector returns a driver.Connector whose connections record spans of
+// the statements executed through them. The system identifies the database,
+// e.g. "mysql", and prefixes span names.
+func WrapConnector(c driver.Connector, system string) driver.Connector {
+	return &connector{c: c, system: system}
+}
+
+type connector struct {
+	c      driver.Connector
+	system string
+}
+
+// Connect implements driver.Connector.
+func (w *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	c, err := w.c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{c: c, system: w.system}, nil
+}
+
+// Driver implements driver.Connector.
+func (w *connector) Driver() driver.Driver {
+	return w.c.Driver()
+}
+
+// conn records the spans of statements run through the driver.Conn it wraps.
+// Like driver connections, it isn't used concurrently.
+type conn struct {
+	c      driver.Conn
+	system string
+	// tx is the span that statements of the transaction in progress, if any,
+	// are recorded as children of.
+	tx *trace.Span
+}
+
+var (
+	_ driver.ConnBeginTx        = &conn{}
+	_ driver.ConnPrepareContext = &conn{}
+	_ driver.ExecerContext      = &conn{}
+	_ driver.QueryerContext     = &conn{}
+	_ driver.Pinger             = &conn{}
+	_ driver.SessionResetter    = &conn{}
+	_ driver.NamedValueChecker  = &conn{}
+)
+
+// startSpan starts the span of a statement executed with ctx. It returns a
+// nil span if the statement isn't executed for a traced request.
+func (c *conn) startSpan(ctx context.Context, op, query string) (context.Context, *trace.Span) {
+	parent := trace.FromContext(ctx)
+	if c.tx != nil {
+		parent = c.tx
+	}
+	if parent == nil {
+		return ctx, nil
+	}
+	ctx, span := trace.StartSpanWithRemoteParent(ctx, c.system+"."+op, parent.SpanContext(), trace.WithSpanKind(trace.SpanKindClient))
+	span.AddAttributes(trace.StringAttribute(SystemAttribute, c.system))
+	if len(query) > maxStatementLen {
+		query = query[:maxStatementLen]
+	}
+	if query != "" {
+		span.AddAttributes(trace.StringAttribute(StatementAttribute, query))
+	}
+	return ctx, span
+}
+
+// endSpan ends span, if any, recording err unless it is driver.ErrSkip, in
+// which case the statement is executed again by database/sql in another way,
+// and the span is dropped.
+func endSpan(span *trace.Span, err error) {
+	if span == nil || err == driver.ErrSkip {
+		return
+	}
+	if err != nil && err != io.EOF {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+	}
+	span.End()
+}
+
+// Prepare implements driver.Conn.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext implements driver.ConnPrepareContext. Preparing a statement
+// isn't recorded, only its executions.
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var s driver.Stmt
+	var err error
+	if cpc, ok := c.c.(driver.ConnPrepareContext); ok {
+		s, err = cpc.PrepareContext(ctx, query)
+	} else {
+		s, err = c.c.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{s: s, c: c, query: query}, nil
+}
+
+// Close implements driver.Conn.
+func (c *conn) Close() error {
+	return c.c.Close()
+}
+
+// Begin implements driver.Conn.
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements driver.ConnBeginTx. The span current in ctx becomes the
+// parent of the statements of the transaction.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var t driver.Tx
+	var err error
+	if cbt, ok := c.c.(driver.ConnBeginTx); ok {
+		t, err = cbt.BeginTx(ctx, opts)
+	} else if opts.ReadOnly || opts.Isolation != driver.IsolationLevel(0) {
+		return nil, errors.New("sqltrace: driver does not support transaction options")
+	} else {
+		t, err = c.c.Begin() // nolint: staticcheck
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.tx = trace.FromContext(ctx)
+	return &tx{t: t, c: c}, nil
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ex, ok := c.c.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := c.startSpan(ctx, "Exec", query)
+	res, err := ex.ExecContext(ctx, query, args)
+	endSpan(span, err)
+	return res, err
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.c.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := c.startSpan(ctx, "Query", query)
+	rows, err := q.QueryContext(ctx, query, args)
+	return wrapRows(rows, span, err)
+}
+
+// Ping implements driver.Pinger.
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.c.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *conn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.c.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.c.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// tx records the span of committing or rolling back a transaction.
+type tx struct {
+	t driver.Tx
+	c *conn
+}
+
+// Commit implements driver.Tx.
+func (t *tx) Commit() error {
+	return t.end("Commit", t.t.Commit)
+}
+
+// Rollback implements driver.Tx.
+func (t *tx) Rollback() error {
+	return t.end("Rollback", t.t.Rollback)
+}
+
+func (t *tx) end(op string, f func() error) error {
+	_, span := t.c.startSpan(context.Background(), op, "")
+	t.c.tx = nil
+	err := f()
+	endSpan(span, err)
+	return err
+}
+
+// stmt records the spans of the executions of a prepared statement.
+type stmt struct {
+	s     driver.Stmt
+	c     *conn
+	query string
+}
+
+var (
+	_ driver.StmtExecContext  = &stmt{}
+	_ driver.StmtQueryContext = &stmt{}
+)
+
+// Close implements driver.Stmt.
+func (s *stmt) Close() error {
+	return s.s.Close()
+}
+
+// NumInput implements driver.Stmt.
+func (s *stmt) NumInput() int {
+	return s.s.NumInput()
+}
+
+// Exec implements driver.Stmt.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+// Query implements driver.Stmt.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext.
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span := s.c.startSpan(ctx, "Exec", s.query)
+	var res driver.Result
+	var err error
+	if sec, ok := s.s.(driver.StmtExecContext); ok {
+		res, err = sec.ExecContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		if vals, err = values(args); err == nil {
+			res, err = s.s.Exec(vals) // nolint: staticcheck
+		}
+	}
+	endSpan(span, err)
+	return res, err
+}
+
+// QueryContext implements driver.StmtQueryContext.
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, span := s.c.startSpan(ctx, "Query", s.query)
+	var rows driver.Rows
+	var err error
+	if sqc, ok := s.s.(driver.StmtQueryContext); ok {
+		rows, err = sqc.QueryContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		if vals, err = values(args); err == nil {
+			rows, err = s.s.Query(vals) // nolint: staticcheck
+		}
+	}
+	return wrapRows(rows, span, err)
+}
+
+// wrapRows returns rows which end span when they are closed, so that the span
+// of a query covers reading its results. The span is ended at once if the
+// query failed.
+func wrapRows(r driver.Rows, span *trace.Span, err error) (driver.Rows, error) {
+	if err != nil || span == nil {
+		endSpan(span, err)
+		return r, err
+	}
+	return &rows{Rows: r, span: span}, nil
+}
+
+// rows ends the span of the query they were read by when closed.
+type rows struct {
+	driver.Rows
+	span *trace.Span
+	err  error
+}
+
+// Next implements driver.Rows.
+func (r *rows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return err
+}
+
+// Close implements driver.Rows.
+func (r *rows) Close() error {
+	err := r.Rows.Close()
+	if r.err == nil {
+		r.err = err
+	}
+	endSpan(r.span, r.err)
+	return err
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+func values(args []driver.NamedValue) ([]driver.Value, error) {
+	vals := make([]driver.Value, len(args))
+	for i, nv := range args {
+		if nv.Name != "" {
+			return nil, errors.New("sqltrace: driver does not support named parameters")
+		}
+		vals[i] = nv.Value
+	}
+	return vals, nil
+}
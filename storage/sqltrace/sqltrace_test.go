@@ -0,0 +1,173 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqltrace
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/trace"
+)
+
+// fakeConnector connects to a database whose connections can only execute
+// prepared statements, like MySQL's do for queries with arguments. Statements
+// containing "FAIL" fail.
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query: query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.Contains(s.query, "FAIL") {
+		return nil, errors.New("failed")
+	}
+	return driver.RowsAffected(1), nil
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &oneRow{}, nil
+}
+
+type oneRow struct {
+	done bool
+}
+
+func (r *oneRow) Columns() []string { return []string{"n"} }
+func (r *oneRow) Close() error      { return nil }
+func (r *oneRow) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+// spanRecorder is a trace.Exporter which records the spans it is passed.
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(sd *trace.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, sd)
+}
+
+// span describes a recorded span by its name, the name of its parent, its
+// statement and whether it failed.
+type span struct {
+	Name, Parent, Statement string
+	Failed                  bool
+}
+
+func TestWrapConnector(t *testing.T) {
+	rec := &spanRecorder{}
+	trace.RegisterExporter(rec)
+	defer trace.UnregisterExporter(rec)
+
+	db := sql.OpenDB(WrapConnector(fakeConnector{}, "fake"))
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	// Statements executed for untraced requests aren't recorded.
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, "DELETE FROM Unsequenced WHERE TreeId=?", 1); err != nil {
+		t.Fatalf("ExecContext(): %v", err)
+	}
+
+	ctx, rpc := trace.StartSpan(ctx, "rpc", trace.WithSampler(trace.AlwaysSample()))
+	if _, err := db.ExecContext(ctx, "DELETE FROM Unsequenced WHERE TreeId=?", 1); err != nil {
+		t.Fatalf("ExecContext(): %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "FAIL", 1); err == nil {
+		t.Fatal("ExecContext() succeeded")
+	}
+
+	// Statements of a transaction are children of its span, whatever the
+	// contexts they are executed with.
+	txCtx, txSpan := trace.StartSpan(ctx, "tx")
+	tx, err := db.BeginTx(txCtx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx(): %v", err)
+	}
+	var n int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM Unsequenced WHERE TreeId=?", 1).Scan(&n); err != nil {
+		t.Fatalf("QueryRowContext(): %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit(): %v", err)
+	}
+	txSpan.End()
+
+	// Later statements on the connection aren't.
+	if _, err := db.ExecContext(ctx, "DELETE FROM Unsequenced WHERE TreeId=?", 2); err != nil {
+		t.Fatalf("ExecContext(): %v", err)
+	}
+	rpc.End()
+
+	names := make(map[trace.SpanID]string)
+	for _, sd := range rec.spans {
+		names[sd.SpanID] = sd.Name
+	}
+	var got []span
+	for _, sd := range rec.spans {
+		if sd.Name == "rpc" || sd.Name == "tx" {
+			continue
+		}
+		if sd.TraceID != rpc.SpanContext().TraceID {
+			t.Errorf("span %q has trace ID %v, want %v", sd.Name, sd.TraceID, rpc.SpanContext().TraceID)
+		}
+		if got, want := sd.Attributes[SystemAttribute], "fake"; got != want {
+			t.Errorf("span %q has system %v, want %v", sd.Name, got, want)
+		}
+		statement, _ := sd.Attributes[StatementAttribute].(string)
+		got = append(got, span{Name: sd.Name, Parent: names[sd.ParentSpanID], Statement: statement, Failed: sd.Code != 0})
+	}
+	want := []span{
+		{Name: "fake.Exec", Parent: "rpc", Statement: "DELETE FROM Unsequenced WHERE TreeId=?"},
+		{Name: "fake.Exec", Parent: "rpc", Statement: "FAIL", Failed: true},
+		{Name: "fake.Query", Parent: "tx", Statement: "SELECT COUNT(*) FROM Unsequenced WHERE TreeId=?"},
+		{Name: "fake.Commit", Parent: "tx"},
+		{Name: "fake.Exec", Parent: "rpc", Statement: "DELETE FROM Unsequenced WHERE TreeId=?"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("spans diff (-got +want):\n%s", diff)
+	}
+}
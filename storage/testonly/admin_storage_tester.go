@@ -142,6 +142,7 @@ func (tester *AdminStorageTester) RunAllTests(t *testing.T) {
 	t.Run("TestCreateTree", tester.TestCreateTree)
 	t.Run("TestUpdateTree", tester.TestUpdateTree)
 	t.Run("TestListTrees", tester.TestListTrees)
+	t.Run("TestGetTreeByCreateRequestID", tester.TestGetTreeByCreateRequestID)
 	t.Run("TestSoftDeleteTree", tester.TestSoftDeleteTree)
 	t.Run("TestSoftDeleteTreeErrors", tester.TestSoftDeleteTreeErrors)
 	t.Run("TestHardDeleteTree", tester.TestHardDeleteTree)
@@ -166,6 +167,31 @@ func (tester *AdminStorageTester) TestCreateTree(t *testing.T) {
 	validTreeWithoutOptionals.DisplayName = ""
 	validTreeWithoutOptionals.Description = ""
 
+	validTreeWithRequestID := proto.Clone(LogTree).(*trillian.Tree)
+	validTreeWithRequestID.CreateRequestId = "6f4c8a8e-4dc1-4b5c-9a3b-1f6a4c2e7d90"
+
+	validTreeWithID := proto.Clone(LogTree).(*trillian.Tree)
+	validTreeWithID.TreeId = 8345729384
+
+	validMapWithStrata := proto.Clone(MapTree).(*trillian.Tree)
+	validMapWithStrata.MapStrata = []int32{16, 16, 224}
+
+	validMapWithCompression := proto.Clone(MapTree).(*trillian.Tree)
+	validMapWithCompression.MapCompression = trillian.MapCompression_MAP_COMPRESSION_ZSTD
+
+	validMapWithCustomHasher := proto.Clone(MapTree).(*trillian.Tree)
+	validMapWithCustomHasher.HashStrategy = trillian.HashStrategy_CUSTOM_MAP_HASHER
+	validMapWithCustomHasher.MapHasher = "custom"
+
+	validMapWithAutoInit := proto.Clone(MapTree).(*trillian.Tree)
+	validMapWithAutoInit.AutoInit = true
+
+	validMapWithKeyIndex := proto.Clone(MapTree).(*trillian.Tree)
+	validMapWithKeyIndex.MapKeyIndex = true
+
+	validTreeWithMaxStorageBytes := proto.Clone(LogTree).(*trillian.Tree)
+	validTreeWithMaxStorageBytes.MaxStorageBytes = 1 << 30
+
 	tests := []struct {
 		desc    string
 		tree    *trillian.Tree
@@ -192,6 +218,43 @@ func (tester *AdminStorageTester) TestCreateTree(t *testing.T) {
 			desc: "validTreeWithoutOptionals",
 			tree: validTreeWithoutOptionals,
 		},
+		{
+			desc: "validTreeWithRequestID",
+			tree: validTreeWithRequestID,
+		},
+		{
+			desc: "validTreeWithID",
+			tree: validTreeWithID,
+		},
+		{
+			desc: "validMapWithStrata",
+			tree: validMapWithStrata,
+		},
+		{
+			desc: "validMapWithCompression",
+			tree: validMapWithCompression,
+		},
+		{
+			desc: "validMapWithCustomHasher",
+			tree: validMapWithCustomHasher,
+		},
+		{
+			desc: "validMapWithAutoInit",
+			tree: validMapWithAutoInit,
+		},
+		{
+			desc: "validMapWithKeyIndex",
+			tree: validMapWithKeyIndex,
+		},
+		{
+			desc: "validTreeWithMaxStorageBytes",
+			tree: validTreeWithMaxStorageBytes,
+		},
+		{
+			desc:    "duplicateTreeID",
+			tree:    validTreeWithID,
+			wantErr: true,
+		},
 	}
 
 	ctx := context.Background()
@@ -283,6 +346,51 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 		tree.DisplayName = validMap.DisplayName
 	}
 
+	rateLimitedLog := proto.Clone(referenceLog).(*trillian.Tree)
+	rateLimitedLog.RateLimits = &trillian.TreeRateLimits{QueriesPerSecond: 10, LeavesPerSecond: 1000}
+	rateLimitedLogFunc := func(tree *trillian.Tree) {
+		tree.RateLimits = rateLimitedLog.RateLimits
+	}
+	rateLimitsRemovedFunc := func(tree *trillian.Tree) {
+		tree.RateLimits = nil
+	}
+
+	labeledLog := proto.Clone(referenceLog).(*trillian.Tree)
+	labeledLog.Labels = map[string]string{"env": "prod", "owner": "ct"}
+	labeledLogFunc := func(tree *trillian.Tree) {
+		tree.Labels = labeledLog.Labels
+	}
+	labelsRemovedFunc := func(tree *trillian.Tree) {
+		tree.Labels = nil
+	}
+
+	maintainedLog := proto.Clone(referenceLog).(*trillian.Tree)
+	maintainedLog.Maintenance = &trillian.TreeMaintenance{Reason: "migration", StartTime: ptypes.TimestampNow()}
+	maintainedLogFunc := func(tree *trillian.Tree) {
+		tree.Maintenance = maintainedLog.Maintenance
+	}
+	maintenanceEndedFunc := func(tree *trillian.Tree) {
+		tree.Maintenance = nil
+	}
+
+	compressedMap := proto.Clone(referenceMap).(*trillian.Tree)
+	compressedMap.MapCompression = trillian.MapCompression_MAP_COMPRESSION_SNAPPY
+	compressedMapFunc := func(tree *trillian.Tree) {
+		tree.MapCompression = compressedMap.MapCompression
+	}
+
+	autoInitMap := proto.Clone(referenceMap).(*trillian.Tree)
+	autoInitMap.AutoInit = true
+	autoInitMapFunc := func(tree *trillian.Tree) {
+		tree.AutoInit = true
+	}
+
+	cappedLog := proto.Clone(referenceLog).(*trillian.Tree)
+	cappedLog.MaxStorageBytes = 1 << 30
+	cappedLogFunc := func(tree *trillian.Tree) {
+		tree.MaxStorageBytes = cappedLog.MaxStorageBytes
+	}
+
 	newPrivateKey := &empty.Empty{}
 	privateKeyChangedButKeyMaterialSameTree := tweakedCopy(LogTree, func(tree *trillian.Tree) {
 		tree.PrivateKey = testonly.MustMarshalAny(t, newPrivateKey)
@@ -302,6 +410,29 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 		})
 	}
 
+	rotatedKeyLog := tweakedCopy(LogTree, func(tree *trillian.Tree) {
+		tree.RetiredPublicKeys = []*trillian.RetiredPublicKey{{PublicKey: tree.PublicKey, RetireTime: ptypes.TimestampNow()}}
+		tree.PrivateKey = testonly.MustMarshalAny(t, &keyspb.PrivateKey{
+			Der: ktestonly.MustMarshalPrivatePEMToDER(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass),
+		})
+		tree.PublicKey = &keyspb.PublicKey{Der: ktestonly.MustMarshalPublicPEMToDER(testonly.DemoPublicKey)}
+	})
+	rotatedKeyFunc := func(tree *trillian.Tree) {
+		tree.RetiredPublicKeys = rotatedKeyLog.RetiredPublicKeys
+		tree.PrivateKey = rotatedKeyLog.PrivateKey
+		tree.PublicKey = rotatedKeyLog.PublicKey
+	}
+	keyRotatedTwiceLog := tweakedCopy(rotatedKeyLog, func(tree *trillian.Tree) {
+		tree.RetiredPublicKeys = append(tree.RetiredPublicKeys, &trillian.RetiredPublicKey{PublicKey: tree.PublicKey, RetireTime: ptypes.TimestampNow()})
+		tree.PrivateKey = LogTree.PrivateKey
+		tree.PublicKey = LogTree.PublicKey
+	})
+	keyRotatedTwiceFunc := func(tree *trillian.Tree) {
+		tree.RetiredPublicKeys = keyRotatedTwiceLog.RetiredPublicKeys
+		tree.PrivateKey = keyRotatedTwiceLog.PrivateKey
+		tree.PublicKey = keyRotatedTwiceLog.PublicKey
+	}
+
 	// Test for an unknown tree outside the loop: it makes the test logic simpler
 	if _, err := storage.UpdateTree(ctx, s, -1, func(tree *trillian.Tree) {}); err == nil {
 		t.Error("UpdateTree() for treeID -1 returned nil err")
@@ -343,6 +474,60 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 			updateFunc: validMapFunc,
 			want:       validMap,
 		},
+		{
+			desc:       "rateLimits",
+			create:     referenceLog,
+			updateFunc: rateLimitedLogFunc,
+			want:       rateLimitedLog,
+		},
+		{
+			desc:       "rateLimitsRemoved",
+			create:     rateLimitedLog,
+			updateFunc: rateLimitsRemovedFunc,
+			want:       referenceLog,
+		},
+		{
+			desc:       "labels",
+			create:     referenceLog,
+			updateFunc: labeledLogFunc,
+			want:       labeledLog,
+		},
+		{
+			desc:       "labelsRemoved",
+			create:     labeledLog,
+			updateFunc: labelsRemovedFunc,
+			want:       referenceLog,
+		},
+		{
+			desc:       "maintainedLog",
+			create:     referenceLog,
+			updateFunc: maintainedLogFunc,
+			want:       maintainedLog,
+		},
+		{
+			desc:       "maintenanceEnded",
+			create:     maintainedLog,
+			updateFunc: maintenanceEndedFunc,
+			want:       referenceLog,
+		},
+		{
+			desc:       "compressedMap",
+			create:     referenceMap,
+			updateFunc: compressedMapFunc,
+			want:       compressedMap,
+		},
+		{
+			desc:       "autoInitMap",
+			create:     referenceMap,
+			updateFunc: autoInitMapFunc,
+			want:       autoInitMap,
+		},
+		{
+			desc:       "cappedLog",
+			create:     referenceLog,
+			updateFunc: cappedLogFunc,
+			want:       cappedLog,
+		},
 		{
 			desc:       "privateKeyChangedButKeyMaterialSame",
 			create:     referenceLog,
@@ -355,6 +540,21 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 			updateFunc: privateKeyChangedAndKeyMaterialDifferentFunc,
 			wantErr:    true,
 		},
+		{
+			desc:       "keyRotated",
+			create:     referenceLog,
+			updateFunc: rotatedKeyFunc,
+			want:       rotatedKeyLog,
+		},
+		{
+			desc:   "keyRotatedTwice",
+			create: referenceLog,
+			updateFunc: func(tree *trillian.Tree) {
+				rotatedKeyFunc(tree)
+				keyRotatedTwiceFunc(tree)
+			},
+			wantErr: true,
+		},
 	}
 	for _, test := range tests {
 		createdTree, err := storage.CreateTree(ctx, s, test.create)
@@ -486,6 +686,48 @@ func runListTreesTest(ctx context.Context, tx storage.ReadOnlyAdminTX, includeDe
 	return nil
 }
 
+// TestGetTreeByCreateRequestID tests the lookup of trees by the request ID
+// they were created with.
+func (tester *AdminStorageTester) TestGetTreeByCreateRequestID(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	const requestID = "6f4c8a8e-4dc1-4b5c-9a3b-1f6a4c2e7d90"
+	makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+	created := makeTreeOrFail(ctx, s, spec{Tree: tweakedCopy(LogTree, func(tree *trillian.Tree) {
+		tree.CreateRequestId = requestID
+	})}, t.Fatalf)
+
+	tree, err := storage.GetTreeByCreateRequestID(ctx, s, requestID)
+	if err != nil {
+		t.Fatalf("GetTreeByCreateRequestID() returned err = %v", err)
+	}
+	if got, want := tree, created; !proto.Equal(got, want) {
+		t.Errorf("GetTreeByCreateRequestID() diff (-got +want):\n%v", cmp.Diff(got, want))
+	}
+
+	if _, err := storage.GetTreeByCreateRequestID(ctx, s, "unknown"); status.Code(err) != codes.NotFound {
+		t.Errorf("GetTreeByCreateRequestID() of an unknown request ID returned err = %v, want code %v", err, codes.NotFound)
+	}
+
+	duplicate := tweakedCopy(MapTree, func(tree *trillian.Tree) { tree.CreateRequestId = requestID })
+	if _, err := storage.CreateTree(ctx, s, duplicate); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("CreateTree() with a used request ID returned err = %v, want code %v", err, codes.AlreadyExists)
+	}
+
+	deleted, err := storage.SoftDeleteTree(ctx, s, created.TreeId)
+	if err != nil {
+		t.Fatalf("SoftDeleteTree() returned err = %v", err)
+	}
+	tree, err = storage.GetTreeByCreateRequestID(ctx, s, requestID)
+	if err != nil {
+		t.Fatalf("GetTreeByCreateRequestID() of a deleted tree returned err = %v", err)
+	}
+	if got, want := tree, deleted; !proto.Equal(got, want) {
+		t.Errorf("GetTreeByCreateRequestID() of a deleted tree diff (-got +want):\n%v", cmp.Diff(got, want))
+	}
+}
+
 // TestSoftDeleteTree tests success scenarios of SoftDeleteTree.
 func (tester *AdminStorageTester) TestSoftDeleteTree(t *testing.T) {
 	ctx := context.Background()
@@ -132,6 +132,11 @@ func (f *FakeMapStorage) SnapshotForTree(ctx context.Context, _ *trillian.Tree)
 	return f.ReadOnlyTX, f.SnapshotErr
 }
 
+// SnapshotAtRevision implements MapStorage.SnapshotAtRevision
+func (f *FakeMapStorage) SnapshotAtRevision(ctx context.Context, _ *trillian.Tree, _ int64) (storage.ReadOnlyMapTreeTX, error) {
+	return f.ReadOnlyTX, f.SnapshotErr
+}
+
 // Layout is not implemented.
 func (f *FakeMapStorage) Layout(*trillian.Tree) (*tree.Layout, error) {
 	return nil, errors.New("not implemented")
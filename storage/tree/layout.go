@@ -73,13 +73,9 @@ func NewLayout(heights []int) *Layout {
 func (l *Layout) GetTileID(id NodeID) TileID {
 	if depth := id.PrefixLenBits; depth > 0 {
 		info := l.getStratumAt(depth - 1)
-		// TODO(pavelkalinnikov): Use Prefix method once it no longer copies Path.
-		// TODO(pavelkalinnikov): Rename *FromHash to something sensible.
-		root := NewNodeIDFromHash(id.Path[:info.idBytes])
-		return TileID{Root: root}
+		return tileIDFromPath(id.Path, info.idBytes)
 	}
-	// TODO(pavelkalinnikov): Leave Path == nil when it's safe.
-	return TileID{Root: NodeID{Path: []byte{}}}
+	return TileID{}
 }
 
 // Split returns the ID of the that the given node belongs to, and the
@@ -87,12 +83,19 @@ func (l *Layout) GetTileID(id NodeID) TileID {
 func (l *Layout) Split(id NodeID) (TileID, *Suffix) {
 	if depth := id.PrefixLenBits; depth > 0 {
 		info := l.getStratumAt(depth - 1)
-		root := NewNodeIDFromHash(id.Path[:info.idBytes])
 		suffix := id.Suffix(info.idBytes, info.height)
-		return TileID{Root: root}, suffix
+		return tileIDFromPath(id.Path, info.idBytes), suffix
 	}
-	// TODO(pavelkalinnikov): Leave Path == nil when it's safe.
-	return TileID{Root: NodeID{Path: []byte{}}}, EmptySuffix
+	return TileID{}, EmptySuffix
+}
+
+// tileIDFromPath returns the ID of the tile whose root ID is the given number
+// of leading bytes of path.
+func tileIDFromPath(path []byte, idBytes int) TileID {
+	if idBytes == 0 {
+		return TileID{}
+	}
+	return TileID{Root: NewNodeID2WithLast(string(path[:idBytes-1]), path[idBytes-1], 8)}
 }
 
 // TileHeight returns the height of a tile with its root located at the
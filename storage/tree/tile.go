@@ -14,23 +14,52 @@
 
 package tree
 
+import "fmt"
+
 // TileID holds the ID of a tile, which is aligned with the tree layout.
 //
-// It assumes that strata heights are multiples of 8, and so the byte
-// representation of the TileID matches NodeID.
+// It assumes that strata heights are multiples of 8, and so the ID of the tile
+// root is a whole number of bytes. These bytes are the key of the tile, both in
+// memory and in storage, e.g. in the SubtreeId column of the SQL schemas. They
+// are the same bytes as those of the tile root's NodeID, so tiles written using
+// NodeID keys are read back unchanged.
 type TileID struct {
-	Root NodeID
+	Root NodeID2
+}
+
+// NewTileID returns the ID of the tile rooted at the node with the given ID,
+// which must be a whole number of bytes.
+func NewTileID(root NodeID2) (TileID, error) {
+	if bits := root.BitLen(); bits%8 != 0 {
+		return TileID{}, fmt.Errorf("invalid tile root ID - not multiple of 8: %d", bits)
+	}
+	return TileID{Root: root}, nil
+}
+
+// TileIDFromBytes returns the ID of the tile with the given key bytes, as
+// returned by AsBytes.
+func TileIDFromBytes(key []byte) TileID {
+	return TileID{Root: NewNodeID2(string(key), uint(len(key))*8)}
 }
 
 // AsKey returns the ID as a string suitable for in-memory mapping.
 func (t TileID) AsKey() string {
-	return string(t.AsBytes())
+	last, bits := t.Root.LastByte()
+	if bits == 0 {
+		return ""
+	}
+	return t.Root.FullBytes() + string([]byte{last})
 }
 
 // AsBytes returns the ID as a byte slice suitable for passing in to the
-// storage layer. The returned bytes must not be modified.
+// storage layer. The returned slice is never nil, so that it never corresponds
+// to NULL in SQL.
 func (t TileID) AsBytes() []byte {
-	// TODO(pavelkalinnikov): We could simply return t.Root.Path, but some NodeID
-	// constructors allocate more bytes in Path than necessary.
-	return t.Root.Path[:t.Root.PrefixLenBits/8]
+	full := t.Root.FullBytes()
+	ret := make([]byte, len(full), len(full)+1)
+	copy(ret, full)
+	if last, bits := t.Root.LastByte(); bits != 0 {
+		ret = append(ret, last)
+	}
+	return ret
 }
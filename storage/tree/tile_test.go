@@ -0,0 +1,61 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tree
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestTileIDBytes(t *testing.T) {
+	for _, tc := range []struct {
+		path    []byte
+		bits    int
+		wantErr bool
+	}{
+		{path: []byte{}, bits: 0},
+		{path: []byte{0x12}, bits: 8},
+		{path: []byte{0x12, 0x00, 0xff}, bits: 24},
+		{path: []byte{0x12, 0x34, 0x56, 0x78}, bits: 16},
+		{path: []byte{0x12, 0x34}, bits: 12, wantErr: true},
+	} {
+		t.Run(fmt.Sprintf("%x:%d", tc.path, tc.bits), func(t *testing.T) {
+			id := NodeID{Path: tc.path, PrefixLenBits: tc.bits}
+			tile, err := NewTileID(id.ToNodeID2())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("NewTileID(): %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			// Tile keys must stay the same as the bytes of the legacy NodeID, which
+			// existing tiles are stored under.
+			want := tc.path[:tc.bits/8]
+			if got := tile.AsBytes(); got == nil || !bytes.Equal(got, want) {
+				t.Errorf("AsBytes()=%x, want %x", got, want)
+			}
+			if got := tile.AsKey(); got != string(want) {
+				t.Errorf("AsKey()=%x, want %x", got, want)
+			}
+			if got := TileIDFromBytes(want); got != tile {
+				t.Errorf("TileIDFromBytes(%x)=%v, want %v", want, got.Root, tile.Root)
+			}
+			if got := NewLayout(defaultMapStrata).GetTileID(NodeID{Path: tc.path, PrefixLenBits: tc.bits + 1}); tc.bits < len(tc.path)*8 && got != tile {
+				t.Errorf("GetTileID()=%v, want %v", got.Root, tile.Root)
+			}
+		})
+	}
+}
@@ -16,8 +16,12 @@ package storage
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
 	"math"
 	"math/big"
+
+	"github.com/google/trillian"
 )
 
 // NewTreeID generates a random, positive, non-zero tree ID.
@@ -28,3 +32,21 @@ func NewTreeID() (int64, error) {
 	}
 	return id.Int64() + 1, nil
 }
+
+// DeriveTreeID returns the tree ID derived from name, a positive, non-zero ID
+// in the same range as those of NewTreeID. The same name always gives the
+// same ID, so that deployments may derive stable IDs from the names of their
+// trees.
+func DeriveTreeID(name string) int64 {
+	h := sha256.Sum256([]byte("Trillian tree ID\x00" + name))
+	return int64(binary.BigEndian.Uint64(h[:8])%math.MaxInt64) + 1
+}
+
+// TreeIDForCreation returns the ID of a tree being created: the ID chosen for
+// it by its creator, if any, or else a new random one.
+func TreeIDForCreation(tree *trillian.Tree) (int64, error) {
+	if tree.TreeId != 0 {
+		return tree.TreeId, nil
+	}
+	return NewTreeID()
+}
@@ -16,6 +16,8 @@ package storage
 
 import (
 	"testing"
+
+	"github.com/google/trillian"
 )
 
 func TestNewTreeID(t *testing.T) {
@@ -35,3 +37,31 @@ func TestNewTreeID(t *testing.T) {
 		}
 	}
 }
+
+func TestDeriveTreeID(t *testing.T) {
+	// IDs must not change, as deployments depend on them.
+	const name, want = "ct/argon2021", 7029706558422184124
+	if got := DeriveTreeID(name); got != want {
+		t.Errorf("DeriveTreeID(%q) = %v, want %v", name, got, want)
+	}
+	ids := make(map[int64]string)
+	for _, name := range []string{"", "ct/argon2021", "ct/argon2022", "ct/argon2021 "} {
+		id := DeriveTreeID(name)
+		if id <= 0 {
+			t.Errorf("DeriveTreeID(%q) = %v, want > 0", name, id)
+		}
+		if other, ok := ids[id]; ok {
+			t.Errorf("DeriveTreeID(%q) = DeriveTreeID(%q) = %v, want different IDs", name, other, id)
+		}
+		ids[id] = name
+	}
+}
+
+func TestTreeIDForCreation(t *testing.T) {
+	if got, err := TreeIDForCreation(&trillian.Tree{TreeId: 12345}); err != nil || got != 12345 {
+		t.Errorf("TreeIDForCreation(chosen ID) = (%v, %v), want (12345, nil)", got, err)
+	}
+	if got, err := TreeIDForCreation(&trillian.Tree{}); err != nil || got <= 0 {
+		t.Errorf("TreeIDForCreation(no ID) = (%v, %v), want (> 0, nil)", got, err)
+	}
+}
@@ -82,3 +82,20 @@ type NodeReader interface {
 	// treeRevision, and returns them in the same order.
 	GetMerkleNodes(ctx context.Context, treeRevision int64, ids []tree.NodeID) ([]tree.Node, error)
 }
+
+// TreeUsage is the storage written for a tree.
+type TreeUsage struct {
+	// LeafBytes is the total size of the leaf data written for the tree.
+	LeafBytes int64
+	// SubtreeBytes is the total size of the subtrees, or map tiles, written
+	// for the tree.
+	SubtreeBytes int64
+}
+
+// TreeUsageTX is implemented by ReadOnlyLogTreeTX and ReadOnlyMapTreeTX
+// implementations which track the storage written for trees. Callers should
+// use a type assertion to check whether it is supported.
+type TreeUsageTX interface {
+	// GetTreeUsage returns the storage written for the tree so far.
+	GetTreeUsage(ctx context.Context) (*TreeUsage, error)
+}
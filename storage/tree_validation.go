@@ -17,6 +17,7 @@ package storage
 import (
 	"bytes"
 	"context"
+	"math"
 
 	"github.com/golang/protobuf/proto" //nolint:staticcheck
 	"github.com/golang/protobuf/ptypes"
@@ -36,6 +37,8 @@ func ValidateTreeForCreation(ctx context.Context, tree *trillian.Tree) error {
 	switch {
 	case tree == nil:
 		return status.Error(codes.InvalidArgument, "a tree is required")
+	case tree.TreeId < 0:
+		return status.Errorf(codes.InvalidArgument, "invalid tree_id: %v", tree.TreeId)
 	case tree.TreeState != trillian.TreeState_ACTIVE:
 		return status.Errorf(codes.InvalidArgument, "invalid tree_state: %s", tree.TreeState)
 	case tree.TreeType == trillian.TreeType_UNKNOWN_TREE_TYPE:
@@ -54,6 +57,23 @@ func ValidateTreeForCreation(ctx context.Context, tree *trillian.Tree) error {
 		return status.Errorf(codes.InvalidArgument, "invalid deleted: %v", tree.Deleted)
 	case tree.DeleteTime != nil:
 		return status.Errorf(codes.InvalidArgument, "invalid delete_time: %+v (must be nil)", tree.DeleteTime)
+	case len(tree.MapStrata) > 0 && tree.TreeType != trillian.TreeType_MAP:
+		return status.Errorf(codes.InvalidArgument, "invalid map_strata: %v (only valid for maps)", tree.MapStrata)
+	case tree.HashStrategy == trillian.HashStrategy_CUSTOM_MAP_HASHER && tree.TreeType != trillian.TreeType_MAP:
+		return status.Errorf(codes.InvalidArgument, "invalid hash_strategy: %s (only valid for maps)", tree.HashStrategy)
+	case tree.HashStrategy == trillian.HashStrategy_CUSTOM_MAP_HASHER && tree.MapHasher == "":
+		return status.Errorf(codes.InvalidArgument, "a map_hasher is required with hash_strategy %s", tree.HashStrategy)
+	case tree.HashStrategy != trillian.HashStrategy_CUSTOM_MAP_HASHER && tree.MapHasher != "":
+		return status.Errorf(codes.InvalidArgument, "invalid map_hasher: %q (only valid with hash_strategy %s)", tree.MapHasher, trillian.HashStrategy_CUSTOM_MAP_HASHER)
+	case tree.MapKeyIndex && tree.TreeType != trillian.TreeType_MAP:
+		return status.Error(codes.InvalidArgument, "invalid map_key_index: only valid for maps")
+	case len(tree.RetiredPublicKeys) > 0:
+		return status.Error(codes.InvalidArgument, "invalid retired_public_keys: only set when keys are rotated")
+	}
+	for _, h := range tree.MapStrata {
+		if h <= 0 || h%8 != 0 {
+			return status.Errorf(codes.InvalidArgument, "invalid map_strata: %v (must be positive multiples of 8)", tree.MapStrata)
+		}
 	}
 
 	return validateMutableTreeFields(ctx, tree)
@@ -105,16 +125,69 @@ func ValidateTreeForUpdate(ctx context.Context, storedTree, newTree *trillian.Tr
 		return status.Error(codes.InvalidArgument, "readonly field changed: create_time")
 	case !proto.Equal(storedTree.UpdateTime, newTree.UpdateTime):
 		return status.Error(codes.InvalidArgument, "readonly field changed: update_time")
-	case !proto.Equal(storedTree.PublicKey, newTree.PublicKey):
-		return status.Error(codes.InvalidArgument, "readonly field changed: public_key")
 	case storedTree.Deleted != newTree.Deleted:
 		return status.Error(codes.InvalidArgument, "readonly field changed: deleted")
 	case !proto.Equal(storedTree.DeleteTime, newTree.DeleteTime):
 		return status.Error(codes.InvalidArgument, "readonly field changed: delete_time")
+	case storedTree.CreateRequestId != newTree.CreateRequestId:
+		return status.Error(codes.InvalidArgument, "readonly field changed: create_request_id")
+	case !equalStrata(storedTree.MapStrata, newTree.MapStrata):
+		return status.Error(codes.InvalidArgument, "readonly field changed: map_strata")
+	case storedTree.MapHasher != newTree.MapHasher:
+		return status.Error(codes.InvalidArgument, "readonly field changed: map_hasher")
+	case storedTree.MapKeyIndex != newTree.MapKeyIndex:
+		return status.Error(codes.InvalidArgument, "readonly field changed: map_key_index")
+	}
+	if err := validateKeyRotation(storedTree, newTree); err != nil {
+		return err
 	}
 	return validateMutableTreeFields(ctx, newTree)
 }
 
+// validateKeyRotation returns nil if the public key of storedTree is unchanged
+// in newTree, or if it's added to the retired public keys. Retired keys can't
+// otherwise change.
+func validateKeyRotation(storedTree, newTree *trillian.Tree) error {
+	stored, retired := storedTree.RetiredPublicKeys, newTree.RetiredPublicKeys
+	rotated := !proto.Equal(storedTree.PublicKey, newTree.PublicKey)
+	want := len(stored)
+	if rotated {
+		want++
+	}
+	if len(retired) != want {
+		if rotated {
+			return status.Error(codes.InvalidArgument, "readonly field changed: public_key (unless the previous key is retired)")
+		}
+		return status.Error(codes.InvalidArgument, "readonly field changed: retired_public_keys")
+	}
+	for i := range stored {
+		if !proto.Equal(stored[i], retired[i]) {
+			return status.Error(codes.InvalidArgument, "readonly field changed: retired_public_keys")
+		}
+	}
+	if rotated {
+		switch last := retired[len(retired)-1]; {
+		case !proto.Equal(last.PublicKey, storedTree.PublicKey):
+			return status.Error(codes.InvalidArgument, "invalid retired_public_keys: the rotated public_key must be retired")
+		case last.RetireTime == nil:
+			return status.Error(codes.InvalidArgument, "invalid retired_public_keys: a retire_time is required")
+		}
+	}
+	return nil
+}
+
+func equalStrata(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
 	if tree.TreeState == trillian.TreeState_UNKNOWN_TREE_STATE {
 		return status.Errorf(codes.InvalidArgument, "invalid tree_state: %v", tree.TreeState)
@@ -125,6 +198,39 @@ func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
 		return status.Errorf(codes.InvalidArgument, "max_root_duration negative: %v", tree.MaxRootDuration)
 	}
 
+	if limits := tree.RateLimits; limits != nil {
+		for _, l := range []struct {
+			name string
+			rate float64
+		}{
+			{"queries_per_second", limits.QueriesPerSecond},
+			{"leaves_per_second", limits.LeavesPerSecond},
+		} {
+			if l.rate < 0 || math.IsNaN(l.rate) || math.IsInf(l.rate, 0) {
+				return status.Errorf(codes.InvalidArgument, "invalid rate_limits.%s: %v", l.name, l.rate)
+			}
+		}
+	}
+
+	for key := range tree.Labels {
+		if key == "" {
+			return status.Error(codes.InvalidArgument, "invalid labels: empty key")
+		}
+	}
+
+	if _, ok := trillian.MapCompression_name[int32(tree.MapCompression)]; !ok {
+		return status.Errorf(codes.InvalidArgument, "invalid map_compression: %v", tree.MapCompression)
+	}
+	if tree.MapCompression != trillian.MapCompression_NO_MAP_COMPRESSION && tree.TreeType != trillian.TreeType_MAP {
+		return status.Errorf(codes.InvalidArgument, "invalid map_compression: %v (only valid for maps)", tree.MapCompression)
+	}
+	if tree.AutoInit && tree.TreeType != trillian.TreeType_MAP {
+		return status.Error(codes.InvalidArgument, "invalid auto_init: only valid for maps")
+	}
+	if tree.MaxStorageBytes < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid max_storage_bytes: %d", tree.MaxStorageBytes)
+	}
+
 	// Implementations may vary, so let's assume storage_settings is mutable.
 	// Other than checking that it's a valid Any there isn't much to do at this layer, though.
 	if tree.StorageSettings != nil {
@@ -16,6 +16,7 @@ package storage
 
 import (
 	"context"
+	"math"
 	"testing"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/crypto/sigpb"
@@ -118,6 +120,68 @@ func TestValidateTreeForCreation(t *testing.T) {
 	deleteTimeTree := newTree()
 	deleteTimeTree.DeleteTime = ptypes.TimestampNow()
 
+	mapStrata := newTree()
+	mapStrata.TreeType = trillian.TreeType_MAP
+	mapStrata.MapStrata = []int32{16, 16, 224}
+
+	logStrata := newTree()
+	logStrata.MapStrata = []int32{16, 16, 224}
+
+	invalidStrata := newTree()
+	invalidStrata.TreeType = trillian.TreeType_MAP
+	invalidStrata.MapStrata = []int32{12, 244}
+
+	mapCompression := newTree()
+	mapCompression.TreeType = trillian.TreeType_MAP
+	mapCompression.MapCompression = trillian.MapCompression_MAP_COMPRESSION_ZSTD
+
+	logCompression := newTree()
+	logCompression.MapCompression = trillian.MapCompression_MAP_COMPRESSION_SNAPPY
+
+	unknownCompression := newTree()
+	unknownCompression.TreeType = trillian.TreeType_MAP
+	unknownCompression.MapCompression = trillian.MapCompression(99)
+
+	customHasher := newTree()
+	customHasher.TreeType = trillian.TreeType_MAP
+	customHasher.HashStrategy = trillian.HashStrategy_CUSTOM_MAP_HASHER
+	customHasher.MapHasher = "poseidon"
+
+	customLogHasher := newTree()
+	customLogHasher.HashStrategy = trillian.HashStrategy_CUSTOM_MAP_HASHER
+	customLogHasher.MapHasher = "poseidon"
+
+	unnamedHasher := newTree()
+	unnamedHasher.TreeType = trillian.TreeType_MAP
+	unnamedHasher.HashStrategy = trillian.HashStrategy_CUSTOM_MAP_HASHER
+
+	namedBuiltinHasher := newTree()
+	namedBuiltinHasher.TreeType = trillian.TreeType_MAP
+	namedBuiltinHasher.MapHasher = "poseidon"
+
+	autoInitMap := newTree()
+	autoInitMap.TreeType = trillian.TreeType_MAP
+	autoInitMap.AutoInit = true
+
+	autoInitLog := newTree()
+	autoInitLog.AutoInit = true
+
+	keyIndexMap := newTree()
+	keyIndexMap.TreeType = trillian.TreeType_MAP
+	keyIndexMap.MapKeyIndex = true
+
+	keyIndexLog := newTree()
+	keyIndexLog.MapKeyIndex = true
+
+	maxStorageBytes := newTree()
+	maxStorageBytes.MaxStorageBytes = 1 << 30
+
+	retiredKeys := newTree()
+	retiredKeys.RetiredPublicKeys = []*trillian.RetiredPublicKey{{PublicKey: retiredKeys.PublicKey, RetireTime: ptypes.TimestampNow()}}
+
+	negativeMaxStorageBytes := newTree()
+	negativeMaxStorageBytes.MaxStorageBytes = -1
+
 	tests := []struct {
 		desc    string
 		tree    *trillian.Tree
@@ -220,6 +284,85 @@ func TestValidateTreeForCreation(t *testing.T) {
 			tree:    deleteTimeTree,
 			wantErr: true,
 		},
+		{
+			desc: "mapStrata",
+			tree: mapStrata,
+		},
+		{
+			desc:    "logStrata",
+			tree:    logStrata,
+			wantErr: true,
+		},
+		{
+			desc:    "invalidStrata",
+			tree:    invalidStrata,
+			wantErr: true,
+		},
+		{
+			desc: "mapCompression",
+			tree: mapCompression,
+		},
+		{
+			desc:    "logCompression",
+			tree:    logCompression,
+			wantErr: true,
+		},
+		{
+			desc:    "unknownCompression",
+			tree:    unknownCompression,
+			wantErr: true,
+		},
+		{
+			desc: "customHasher",
+			tree: customHasher,
+		},
+		{
+			desc:    "customLogHasher",
+			tree:    customLogHasher,
+			wantErr: true,
+		},
+		{
+			desc:    "unnamedHasher",
+			tree:    unnamedHasher,
+			wantErr: true,
+		},
+		{
+			desc:    "namedBuiltinHasher",
+			tree:    namedBuiltinHasher,
+			wantErr: true,
+		},
+		{
+			desc: "autoInitMap",
+			tree: autoInitMap,
+		},
+		{
+			desc:    "autoInitLog",
+			tree:    autoInitLog,
+			wantErr: true,
+		},
+		{
+			desc: "keyIndexMap",
+			tree: keyIndexMap,
+		},
+		{
+			desc:    "keyIndexLog",
+			tree:    keyIndexLog,
+			wantErr: true,
+		},
+		{
+			desc: "maxStorageBytes",
+			tree: maxStorageBytes,
+		},
+		{
+			desc:    "negativeMaxStorageBytes",
+			tree:    negativeMaxStorageBytes,
+			wantErr: true,
+		},
+		{
+			desc:    "retiredKeys",
+			tree:    retiredKeys,
+			wantErr: true,
+		},
 	}
 	for _, test := range tests {
 		err := ValidateTreeForCreation(ctx, test.tree)
@@ -235,6 +378,17 @@ func TestValidateTreeForCreation(t *testing.T) {
 func TestValidateTreeForUpdate(t *testing.T) {
 	ctx := context.Background()
 
+	rotatedKey := testonly.MustMarshalAny(t, &keyspb.PrivateKey{
+		Der: ktestonly.MustMarshalPrivatePEMToDER(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass),
+	})
+	// rotateKey changes the key of a tree to another one, retiring the
+	// current public key at retireTime.
+	rotateKey := func(tree *trillian.Tree, retireTime *timestamp.Timestamp) {
+		tree.RetiredPublicKeys = append(tree.RetiredPublicKeys, &trillian.RetiredPublicKey{PublicKey: tree.PublicKey, RetireTime: retireTime})
+		tree.PrivateKey = rotatedKey
+		tree.PublicKey = &keyspb.PublicKey{Der: ktestonly.MustMarshalPublicPEMToDER(testonly.DemoPublicKey)}
+	}
+
 	tests := []struct {
 		desc      string
 		treeState trillian.TreeState
@@ -285,6 +439,39 @@ func TestValidateTreeForUpdate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			desc: "validRateLimits",
+			updatefn: func(tree *trillian.Tree) {
+				tree.RateLimits = &trillian.TreeRateLimits{QueriesPerSecond: 0.5, LeavesPerSecond: 100}
+			},
+		},
+		{
+			desc: "negativeRateLimit",
+			updatefn: func(tree *trillian.Tree) {
+				tree.RateLimits = &trillian.TreeRateLimits{LeavesPerSecond: -1}
+			},
+			wantErr: true,
+		},
+		{
+			desc: "infiniteRateLimit",
+			updatefn: func(tree *trillian.Tree) {
+				tree.RateLimits = &trillian.TreeRateLimits{QueriesPerSecond: math.Inf(1)}
+			},
+			wantErr: true,
+		},
+		{
+			desc: "validLabels",
+			updatefn: func(tree *trillian.Tree) {
+				tree.Labels = map[string]string{"env": "prod", "team": ""}
+			},
+		},
+		{
+			desc: "emptyLabelKey",
+			updatefn: func(tree *trillian.Tree) {
+				tree.Labels = map[string]string{"": "prod"}
+			},
+			wantErr: true,
+		},
 		{
 			desc: "differentPrivateKeyProtoButSameKeyMaterial",
 			updatefn: func(tree *trillian.Tree) {
@@ -414,6 +601,92 @@ func TestValidateTreeForUpdate(t *testing.T) {
 			updatefn: func(tree *trillian.Tree) { tree.DeleteTime = ptypes.TimestampNow() },
 			wantErr:  true,
 		},
+		{
+			desc:     "MapStrata",
+			updatefn: func(tree *trillian.Tree) { tree.MapStrata = []int32{128, 128} },
+			wantErr:  true,
+		},
+		{
+			desc:     "MapCompression",
+			treeType: trillian.TreeType_MAP,
+			updatefn: func(tree *trillian.Tree) { tree.MapCompression = trillian.MapCompression_MAP_COMPRESSION_SNAPPY },
+		},
+		{
+			desc:     "LogCompression",
+			updatefn: func(tree *trillian.Tree) { tree.MapCompression = trillian.MapCompression_MAP_COMPRESSION_SNAPPY },
+			wantErr:  true,
+		},
+		{
+			desc:     "AutoInit",
+			treeType: trillian.TreeType_MAP,
+			updatefn: func(tree *trillian.Tree) { tree.AutoInit = true },
+		},
+		{
+			desc:     "LogAutoInit",
+			updatefn: func(tree *trillian.Tree) { tree.AutoInit = true },
+			wantErr:  true,
+		},
+		{
+			desc:     "MaxStorageBytes",
+			updatefn: func(tree *trillian.Tree) { tree.MaxStorageBytes = 1 << 30 },
+		},
+		{
+			desc:     "NegativeMaxStorageBytes",
+			updatefn: func(tree *trillian.Tree) { tree.MaxStorageBytes = -1 },
+			wantErr:  true,
+		},
+		{
+			desc:     "MapHasher",
+			treeType: trillian.TreeType_MAP,
+			updatefn: func(tree *trillian.Tree) { tree.MapHasher = "poseidon" },
+			wantErr:  true,
+		},
+		{
+			desc:     "MapKeyIndex",
+			treeType: trillian.TreeType_MAP,
+			updatefn: func(tree *trillian.Tree) { tree.MapKeyIndex = true },
+			wantErr:  true,
+		},
+		{
+			desc:     "KeyRotated",
+			updatefn: func(tree *trillian.Tree) { rotateKey(tree, ptypes.TimestampNow()) },
+		},
+		{
+			desc: "KeyRotatedTwice",
+			updatefn: func(tree *trillian.Tree) {
+				rotateKey(tree, ptypes.TimestampNow())
+				rotateKey(tree, ptypes.TimestampNow())
+			},
+			wantErr: true,
+		},
+		{
+			desc:     "KeyRotatedWithoutRetireTime",
+			updatefn: func(tree *trillian.Tree) { rotateKey(tree, nil) },
+			wantErr:  true,
+		},
+		{
+			desc: "KeyRotatedWithoutRetiring",
+			updatefn: func(tree *trillian.Tree) {
+				rotateKey(tree, ptypes.TimestampNow())
+				tree.RetiredPublicKeys = nil
+			},
+			wantErr: true,
+		},
+		{
+			desc: "OtherKeyRetired",
+			updatefn: func(tree *trillian.Tree) {
+				rotateKey(tree, ptypes.TimestampNow())
+				tree.RetiredPublicKeys[0].PublicKey = tree.PublicKey
+			},
+			wantErr: true,
+		},
+		{
+			desc: "KeyRetiredWithoutRotation",
+			updatefn: func(tree *trillian.Tree) {
+				tree.RetiredPublicKeys = []*trillian.RetiredPublicKey{{PublicKey: tree.PublicKey, RetireTime: ptypes.TimestampNow()}}
+			},
+			wantErr: true,
+		},
 	}
 	for _, test := range tests {
 		tree := newTree()
@@ -39,6 +39,7 @@ import (
 	"github.com/google/trillian/storage/mysql"
 	"github.com/google/trillian/storage/testdb"
 	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/election"
 
 	_ "github.com/go-sql-driver/mysql"                   // Load MySQL driver
 	_ "github.com/google/trillian/crypto/keys/der/proto" // Register PrivateKey ProtoHandler
@@ -49,9 +50,26 @@ var (
 	batchSize       = 50
 	// SequencerInterval is the time between runs of the sequencer.
 	SequencerInterval = 500 * time.Millisecond
-	timeSource        = clock.System
 )
 
+// LogEnvOptions holds the optional settings of a LogEnv.
+type LogEnvOptions struct {
+	// ServerOpts are additional options for the gRPC server.
+	ServerOpts []grpc.ServerOption
+	// ClientOpts are options for dialing the server. If nil, an insecure
+	// connection is used.
+	ClientOpts []grpc.DialOption
+	// TimeSource is used by the log server, the sequencer and its mastership
+	// elections. If nil, clock.System is used. With a clock.FakeTimeSource,
+	// a sequencing pass runs whenever time is advanced by SequencerInterval,
+	// so tests can deterministically exercise the guard window, max root
+	// durations and mastership lease expiry.
+	TimeSource clock.TimeSource
+	// GuardWindow is how long leaves must have been queued for before the
+	// sequencer integrates them.
+	GuardWindow time.Duration
+}
+
 // LogEnv is a test environment that contains both a log server and a connection to it.
 type LogEnv struct {
 	registry        extension.Registry
@@ -122,6 +140,22 @@ func NewLogEnvWithRegistry(ctx context.Context, numSequencers int, registry exte
 
 // NewLogEnvWithRegistryAndGRPCOptions works the same way as NewLogEnv, but allows callers to also set additional grpc.ServerOption and grpc.DialOption values.
 func NewLogEnvWithRegistryAndGRPCOptions(ctx context.Context, numSequencers int, registry extension.Registry, serverOpts []grpc.ServerOption, clientOpts []grpc.DialOption) (*LogEnv, error) {
+	return NewLogEnvWithRegistryAndOptions(ctx, numSequencers, registry, LogEnvOptions{
+		ServerOpts:  serverOpts,
+		ClientOpts:  clientOpts,
+		GuardWindow: sequencerWindow,
+	})
+}
+
+// NewLogEnvWithRegistryAndOptions works the same way as
+// NewLogEnvWithRegistry, but allows callers to set the options in opts.
+func NewLogEnvWithRegistryAndOptions(ctx context.Context, numSequencers int, registry extension.Registry, opts LogEnvOptions) (*LogEnv, error) {
+	timeSource := opts.TimeSource
+	if timeSource == nil {
+		timeSource = clock.System
+	}
+	serverOpts, clientOpts := opts.ServerOpts, opts.ClientOpts
+
 	// Create the GRPC Server.
 	serverOpts = append(serverOpts, grpc.UnaryInterceptor(interceptor.ErrorWrapper))
 	grpcServer := grpc.NewServer(serverOpts...)
@@ -135,7 +169,7 @@ func NewLogEnvWithRegistryAndGRPCOptions(ctx context.Context, numSequencers int,
 	trillian.RegisterTrillianLogServer(grpcServer, logServer)
 
 	// Create Sequencer.
-	sequencerManager := log.NewSequencerManager(registry, sequencerWindow)
+	sequencerManager := log.NewSequencerManager(registry, opts.GuardWindow)
 	var wg sync.WaitGroup
 	var sequencerTask *log.OperationManager
 	ctx, cancel := context.WithCancel(ctx)
@@ -145,6 +179,9 @@ func NewLogEnvWithRegistryAndGRPCOptions(ctx context.Context, numSequencers int,
 		NumWorkers:  numSequencers,
 		RunInterval: SequencerInterval,
 		TimeSource:  timeSource,
+		ElectionConfig: election.RunnerConfig{
+			TimeSource: timeSource,
+		},
 	}
 	// Start a live sequencer in a goroutine.
 	sequencerTask = log.NewOperationManager(info, sequencerManager)
@@ -118,6 +118,7 @@ func NewMapEnvWithRegistry(registry extension.Registry, singleTX bool) (*MapEnv,
 			interceptor.ErrorWrapper,
 			ti.UnaryInterceptor,
 		)),
+		grpc.StreamInterceptor(ti.StreamInterceptor),
 	)
 	mapServer := server.NewTrillianMapServer(registry, server.TrillianMapServerOptions{UseSingleTransaction: singleTX})
 	writeServer := server.NewTrillianMapWriteServer(registry, mapServer)
@@ -159,6 +159,10 @@ type MapConfig struct {
 	// KeepFailedTree indicates whether ephemeral trees should be left intact
 	// after a failed hammer run.
 	KeepFailedTree bool
+	// AdvanceClock, if set, is called after every write operation, e.g. to
+	// advance the fake clocks of servers run with --fake_clock, so that their
+	// time-dependent behaviour happens at the same points of every run.
+	AdvanceClock func(context.Context) error
 }
 
 // String conforms with Stringer for MapConfig.
@@ -449,6 +453,11 @@ func (w *writeWorker) run(ctx context.Context, done <-chan struct{}) (uint64, er
 		if err := w.writeOnce(ctx); err != nil {
 			return count, err
 		}
+		if advance := w.s.cfg.AdvanceClock; advance != nil {
+			if err := advance(ctx); err != nil {
+				return count, fmt.Errorf("failed to advance clock: %v", err)
+			}
+		}
 	}
 	return count, nil
 }
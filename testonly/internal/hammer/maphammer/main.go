@@ -25,6 +25,7 @@ import (
 	"io"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -37,6 +38,7 @@ import (
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/prometheus"
 	"github.com/google/trillian/testonly/internal/hammer"
+	"github.com/google/trillian/util/clock"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 
@@ -64,6 +66,8 @@ var (
 	opDeadline      = flag.Duration("op_deadline", 60*time.Second, "How long to wait for operation success")
 	emitInterval    = flag.Duration("emit_interval", 0, "How often to output the Hammer state")
 	keepFailedTree  = flag.Bool("keep_failed_tree", false, "Whether to preserve ephemeral trees on failed run")
+	fakeClocks      = flag.String("fake_clocks", "", "Comma-separated list of HTTP endpoints (host:port) of servers run with --fake_clock, whose clocks are advanced after every write operation")
+	fakeClockStep   = flag.Duration("fake_clock_step", time.Second, "How far --fake_clocks are advanced after every write operation")
 )
 
 var (
@@ -96,6 +100,30 @@ func hammerTime() {
 	fmt.Print("\n\nLet me hammer him today?\n\n")
 }
 
+// advanceClocks returns a function which advances the fake clocks served on
+// each of the given HTTP endpoints by step.
+func advanceClocks(endpoints []string, step time.Duration) func(context.Context) error {
+	form := url.Values{"advance": {step.String()}}
+	return func(ctx context.Context) error {
+		for _, ep := range endpoints {
+			req, err := http.NewRequest(http.MethodPost, "http://"+ep+clock.DebugPath, strings.NewReader(form.Encode()))
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rsp, err := http.DefaultClient.Do(req.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+			rsp.Body.Close()
+			if rsp.StatusCode != http.StatusOK {
+				return fmt.Errorf("advancing clock of %s: %s", ep, rsp.Status)
+			}
+		}
+		return nil
+	}
+}
+
 func main() {
 	flag.Parse()
 	defer glog.Flush()
@@ -201,6 +229,9 @@ func main() {
 			OperationDeadline: *opDeadline,
 			KeepFailedTree:    *keepFailedTree,
 		}
+		if *fakeClocks != "" {
+			cfg.AdvanceClock = advanceClocks(strings.Split(*fakeClocks, ","), *fakeClockStep)
+		}
 		fmt.Printf("%v\n\n", cfg)
 		wg.Add(1)
 		go func(cfg hammer.MapConfig) {
@@ -34,6 +34,21 @@ func (m *MockTrillianAdminServer) EXPECT() *MockTrillianAdminServerMockRecorder
 	return m.recorder
 }
 
+// ApplyTreeSpec mocks base method
+func (m *MockTrillianAdminServer) ApplyTreeSpec(arg0 context.Context, arg1 *trillian.ApplyTreeSpecRequest) (*trillian.ApplyTreeSpecResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyTreeSpec", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.ApplyTreeSpecResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyTreeSpec indicates an expected call of ApplyTreeSpec
+func (mr *MockTrillianAdminServerMockRecorder) ApplyTreeSpec(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyTreeSpec", reflect.TypeOf((*MockTrillianAdminServer)(nil).ApplyTreeSpec), arg0, arg1)
+}
+
 // CreateTree mocks base method
 func (m *MockTrillianAdminServer) CreateTree(arg0 context.Context, arg1 *trillian.CreateTreeRequest) (*trillian.Tree, error) {
 	m.ctrl.T.Helper()
@@ -64,6 +79,21 @@ func (mr *MockTrillianAdminServerMockRecorder) DeleteTree(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTree", reflect.TypeOf((*MockTrillianAdminServer)(nil).DeleteTree), arg0, arg1)
 }
 
+// GetQuotaState mocks base method
+func (m *MockTrillianAdminServer) GetQuotaState(arg0 context.Context, arg1 *trillian.GetQuotaStateRequest) (*trillian.GetQuotaStateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuotaState", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetQuotaStateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuotaState indicates an expected call of GetQuotaState
+func (mr *MockTrillianAdminServerMockRecorder) GetQuotaState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuotaState", reflect.TypeOf((*MockTrillianAdminServer)(nil).GetQuotaState), arg0, arg1)
+}
+
 // GetTree mocks base method
 func (m *MockTrillianAdminServer) GetTree(arg0 context.Context, arg1 *trillian.GetTreeRequest) (*trillian.Tree, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +109,51 @@ func (mr *MockTrillianAdminServerMockRecorder) GetTree(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTree", reflect.TypeOf((*MockTrillianAdminServer)(nil).GetTree), arg0, arg1)
 }
 
+// GetTreeStats mocks base method
+func (m *MockTrillianAdminServer) GetTreeStats(arg0 context.Context, arg1 *trillian.GetTreeStatsRequest) (*trillian.GetTreeStatsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTreeStats", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetTreeStatsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreeStats indicates an expected call of GetTreeStats
+func (mr *MockTrillianAdminServerMockRecorder) GetTreeStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeStats", reflect.TypeOf((*MockTrillianAdminServer)(nil).GetTreeStats), arg0, arg1)
+}
+
+// GetTreeUsage mocks base method
+func (m *MockTrillianAdminServer) GetTreeUsage(arg0 context.Context, arg1 *trillian.GetTreeUsageRequest) (*trillian.GetTreeUsageResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTreeUsage", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetTreeUsageResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreeUsage indicates an expected call of GetTreeUsage
+func (mr *MockTrillianAdminServerMockRecorder) GetTreeUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeUsage", reflect.TypeOf((*MockTrillianAdminServer)(nil).GetTreeUsage), arg0, arg1)
+}
+
+// ListDeadLetterLeaves mocks base method
+func (m *MockTrillianAdminServer) ListDeadLetterLeaves(arg0 context.Context, arg1 *trillian.ListDeadLetterLeavesRequest) (*trillian.ListDeadLetterLeavesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeadLetterLeaves", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.ListDeadLetterLeavesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeadLetterLeaves indicates an expected call of ListDeadLetterLeaves
+func (mr *MockTrillianAdminServerMockRecorder) ListDeadLetterLeaves(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeadLetterLeaves", reflect.TypeOf((*MockTrillianAdminServer)(nil).ListDeadLetterLeaves), arg0, arg1)
+}
+
 // ListTrees mocks base method
 func (m *MockTrillianAdminServer) ListTrees(arg0 context.Context, arg1 *trillian.ListTreesRequest) (*trillian.ListTreesResponse, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +169,81 @@ func (mr *MockTrillianAdminServerMockRecorder) ListTrees(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrees", reflect.TypeOf((*MockTrillianAdminServer)(nil).ListTrees), arg0, arg1)
 }
 
+// MoveMastership mocks base method
+func (m *MockTrillianAdminServer) MoveMastership(arg0 context.Context, arg1 *trillian.MoveMastershipRequest) (*trillian.MoveMastershipResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveMastership", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.MoveMastershipResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveMastership indicates an expected call of MoveMastership
+func (mr *MockTrillianAdminServerMockRecorder) MoveMastership(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveMastership", reflect.TypeOf((*MockTrillianAdminServer)(nil).MoveMastership), arg0, arg1)
+}
+
+// PurgeDeadLetterLeaves mocks base method
+func (m *MockTrillianAdminServer) PurgeDeadLetterLeaves(arg0 context.Context, arg1 *trillian.PurgeDeadLetterLeavesRequest) (*trillian.PurgeDeadLetterLeavesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeadLetterLeaves", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.PurgeDeadLetterLeavesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeadLetterLeaves indicates an expected call of PurgeDeadLetterLeaves
+func (mr *MockTrillianAdminServerMockRecorder) PurgeDeadLetterLeaves(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeadLetterLeaves", reflect.TypeOf((*MockTrillianAdminServer)(nil).PurgeDeadLetterLeaves), arg0, arg1)
+}
+
+// RequeueDeadLetterLeaves mocks base method
+func (m *MockTrillianAdminServer) RequeueDeadLetterLeaves(arg0 context.Context, arg1 *trillian.RequeueDeadLetterLeavesRequest) (*trillian.RequeueDeadLetterLeavesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueDeadLetterLeaves", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.RequeueDeadLetterLeavesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueDeadLetterLeaves indicates an expected call of RequeueDeadLetterLeaves
+func (mr *MockTrillianAdminServerMockRecorder) RequeueDeadLetterLeaves(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueDeadLetterLeaves", reflect.TypeOf((*MockTrillianAdminServer)(nil).RequeueDeadLetterLeaves), arg0, arg1)
+}
+
+// SetFeatureFlags mocks base method
+func (m *MockTrillianAdminServer) SetFeatureFlags(arg0 context.Context, arg1 *trillian.SetFeatureFlagsRequest) (*trillian.SetFeatureFlagsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFeatureFlags", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.SetFeatureFlagsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFeatureFlags indicates an expected call of SetFeatureFlags
+func (mr *MockTrillianAdminServerMockRecorder) SetFeatureFlags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFeatureFlags", reflect.TypeOf((*MockTrillianAdminServer)(nil).SetFeatureFlags), arg0, arg1)
+}
+
+// SetMaintenanceMode mocks base method
+func (m *MockTrillianAdminServer) SetMaintenanceMode(arg0 context.Context, arg1 *trillian.SetMaintenanceModeRequest) (*trillian.SetMaintenanceModeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMaintenanceMode", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.SetMaintenanceModeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetMaintenanceMode indicates an expected call of SetMaintenanceMode
+func (mr *MockTrillianAdminServerMockRecorder) SetMaintenanceMode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaintenanceMode", reflect.TypeOf((*MockTrillianAdminServer)(nil).SetMaintenanceMode), arg0, arg1)
+}
+
 // UndeleteTree mocks base method
 func (m *MockTrillianAdminServer) UndeleteTree(arg0 context.Context, arg1 *trillian.UndeleteTreeRequest) (*trillian.Tree, error) {
 	m.ctrl.T.Helper()
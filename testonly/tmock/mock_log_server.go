@@ -124,6 +124,21 @@ func (mr *MockTrillianLogServerMockRecorder) GetInclusionProofByHash(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInclusionProofByHash", reflect.TypeOf((*MockTrillianLogServer)(nil).GetInclusionProofByHash), arg0, arg1)
 }
 
+// GetInclusionProofs mocks base method
+func (m *MockTrillianLogServer) GetInclusionProofs(arg0 context.Context, arg1 *trillian.GetInclusionProofsRequest) (*trillian.GetInclusionProofsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInclusionProofs", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetInclusionProofsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInclusionProofs indicates an expected call of GetInclusionProofs
+func (mr *MockTrillianLogServerMockRecorder) GetInclusionProofs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInclusionProofs", reflect.TypeOf((*MockTrillianLogServer)(nil).GetInclusionProofs), arg0, arg1)
+}
+
 // GetLatestSignedLogRoot mocks base method
 func (m *MockTrillianLogServer) GetLatestSignedLogRoot(arg0 context.Context, arg1 *trillian.GetLatestSignedLogRootRequest) (*trillian.GetLatestSignedLogRootResponse, error) {
 	m.ctrl.T.Helper()
@@ -243,3 +258,31 @@ func (mr *MockTrillianLogServerMockRecorder) QueueLeaves(arg0, arg1 interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueLeaves", reflect.TypeOf((*MockTrillianLogServer)(nil).QueueLeaves), arg0, arg1)
 }
+
+// StreamLeafHashes mocks base method
+func (m *MockTrillianLogServer) StreamLeafHashes(arg0 *trillian.StreamLeafHashesRequest, arg1 trillian.TrillianLog_StreamLeafHashesServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamLeafHashes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamLeafHashes indicates an expected call of StreamLeafHashes
+func (mr *MockTrillianLogServerMockRecorder) StreamLeafHashes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamLeafHashes", reflect.TypeOf((*MockTrillianLogServer)(nil).StreamLeafHashes), arg0, arg1)
+}
+
+// StreamLeaves mocks base method
+func (m *MockTrillianLogServer) StreamLeaves(arg0 *trillian.StreamLeavesRequest, arg1 trillian.TrillianLog_StreamLeavesServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamLeaves", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamLeaves indicates an expected call of StreamLeaves
+func (mr *MockTrillianLogServerMockRecorder) StreamLeaves(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamLeaves", reflect.TypeOf((*MockTrillianLogServer)(nil).StreamLeaves), arg0, arg1)
+}
@@ -34,6 +34,21 @@ func (m *MockTrillianMapServer) EXPECT() *MockTrillianMapServerMockRecorder {
 	return m.recorder
 }
 
+// AddMapRootSignature mocks base method
+func (m *MockTrillianMapServer) AddMapRootSignature(arg0 context.Context, arg1 *trillian.AddMapRootSignatureRequest) (*trillian.AddMapRootSignatureResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMapRootSignature", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.AddMapRootSignatureResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMapRootSignature indicates an expected call of AddMapRootSignature
+func (mr *MockTrillianMapServerMockRecorder) AddMapRootSignature(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMapRootSignature", reflect.TypeOf((*MockTrillianMapServer)(nil).AddMapRootSignature), arg0, arg1)
+}
+
 // GetLastInRangeByRevision mocks base method
 func (m *MockTrillianMapServer) GetLastInRangeByRevision(arg0 context.Context, arg1 *trillian.GetLastInRangeByRevisionRequest) (*trillian.MapLeaf, error) {
 	m.ctrl.T.Helper()
@@ -124,6 +139,51 @@ func (mr *MockTrillianMapServerMockRecorder) GetLeavesByRevisionNoProof(arg0, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByRevisionNoProof", reflect.TypeOf((*MockTrillianMapServer)(nil).GetLeavesByRevisionNoProof), arg0, arg1)
 }
 
+// GetLeavesByRevisions mocks base method
+func (m *MockTrillianMapServer) GetLeavesByRevisions(arg0 context.Context, arg1 *trillian.GetMapLeavesByRevisionsRequest) (*trillian.GetMapLeavesByRevisionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLeavesByRevisions", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetMapLeavesByRevisionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeavesByRevisions indicates an expected call of GetLeavesByRevisions
+func (mr *MockTrillianMapServerMockRecorder) GetLeavesByRevisions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByRevisions", reflect.TypeOf((*MockTrillianMapServer)(nil).GetLeavesByRevisions), arg0, arg1)
+}
+
+// GetLeavesCompact mocks base method
+func (m *MockTrillianMapServer) GetLeavesCompact(arg0 context.Context, arg1 *trillian.GetMapLeavesCompactRequest) (*trillian.GetMapLeavesCompactResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLeavesCompact", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetMapLeavesCompactResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeavesCompact indicates an expected call of GetLeavesCompact
+func (mr *MockTrillianMapServerMockRecorder) GetLeavesCompact(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesCompact", reflect.TypeOf((*MockTrillianMapServer)(nil).GetLeavesCompact), arg0, arg1)
+}
+
+// GetMapRootSignatures mocks base method
+func (m *MockTrillianMapServer) GetMapRootSignatures(arg0 context.Context, arg1 *trillian.GetMapRootSignaturesRequest) (*trillian.GetMapRootSignaturesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMapRootSignatures", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetMapRootSignaturesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMapRootSignatures indicates an expected call of GetMapRootSignatures
+func (mr *MockTrillianMapServerMockRecorder) GetMapRootSignatures(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMapRootSignatures", reflect.TypeOf((*MockTrillianMapServer)(nil).GetMapRootSignatures), arg0, arg1)
+}
+
 // GetSignedMapRoot mocks base method
 func (m *MockTrillianMapServer) GetSignedMapRoot(arg0 context.Context, arg1 *trillian.GetSignedMapRootRequest) (*trillian.GetSignedMapRootResponse, error) {
 	m.ctrl.T.Helper()
@@ -169,6 +229,21 @@ func (mr *MockTrillianMapServerMockRecorder) InitMap(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitMap", reflect.TypeOf((*MockTrillianMapServer)(nil).InitMap), arg0, arg1)
 }
 
+// ListLeavesByKeyPrefix mocks base method
+func (m *MockTrillianMapServer) ListLeavesByKeyPrefix(arg0 context.Context, arg1 *trillian.ListMapLeavesByKeyPrefixRequest) (*trillian.ListMapLeavesByKeyPrefixResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLeavesByKeyPrefix", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.ListMapLeavesByKeyPrefixResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLeavesByKeyPrefix indicates an expected call of ListLeavesByKeyPrefix
+func (mr *MockTrillianMapServerMockRecorder) ListLeavesByKeyPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLeavesByKeyPrefix", reflect.TypeOf((*MockTrillianMapServer)(nil).ListLeavesByKeyPrefix), arg0, arg1)
+}
+
 // SetLeaves mocks base method
 func (m *MockTrillianMapServer) SetLeaves(arg0 context.Context, arg1 *trillian.SetMapLeavesRequest) (*trillian.SetMapLeavesResponse, error) {
 	m.ctrl.T.Helper()
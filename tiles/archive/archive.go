@@ -0,0 +1,216 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package archive writes the trees of frozen logs to self-contained archives
+// for long-term escrow, and verifies them offline.
+//
+// An archive is a gzip-compressed tar file holding, in order:
+//   - tree.json: the tree of the log, without its private key, which holds the
+//     public key of the log and its hash strategy,
+//   - log_root.json: the latest SignedLogRoot of the log,
+//   - checkpoint.key: the note verifier key of the checkpoint,
+//   - checkpoint: a checkpoint note of the latest root of the log,
+//   - the hash tiles and entry bundles of the tree, in the tlog-tiles layout.
+//
+// Tiles are ordered so that every hash tile follows the tiles whose subtree
+// roots it holds, which lets archives of any size be verified in one pass
+// with little memory.
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/tiles"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Names of the files of an archive, before its tiles.
+const (
+	TreeFile          = "tree.json"
+	LogRootFile       = "log_root.json"
+	CheckpointKeyFile = "checkpoint.key"
+	CheckpointFile    = "checkpoint"
+)
+
+// Options holds the settings of Write.
+type Options struct {
+	// LogID is the ID of the log to archive.
+	LogID int64
+	// Origin is the origin of the checkpoint.
+	Origin string
+	// Signer signs the checkpoint.
+	Signer *tiles.NoteSigner
+}
+
+// Write writes an archive of a frozen log in the given storage to w, and
+// returns the size of its tree. It fails with FailedPrecondition if the log
+// isn't frozen, as archives of logs which can still grow would be stale.
+func Write(ctx context.Context, as storage.AdminStorage, ls storage.LogStorage, w io.Writer, opts Options) (uint64, error) {
+	var (
+		tree *trillian.Tree
+		slr  *trillian.SignedLogRoot
+		root types.LogRootV1
+	)
+	if err := tiles.Snapshot(ctx, as, ls, opts.LogID, func(ctx context.Context, tx storage.ReadOnlyLogTreeTX, r *types.LogRootV1) error {
+		t, _ := trees.FromContext(ctx)
+		if t.TreeState != trillian.TreeState_FROZEN {
+			return status.Errorf(codes.FailedPrecondition, "log %d is %v, not %v", opts.LogID, t.TreeState, trillian.TreeState_FROZEN)
+		}
+		tree = proto.Clone(t).(*trillian.Tree)
+		tree.PrivateKey = nil
+		tree.StorageSettings = nil
+		var err error
+		slr, err = tx.LatestSignedLogRoot(ctx)
+		root = *r
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	now := time.Now()
+	add := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: now}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	marshal := protojson.MarshalOptions{Multiline: true}
+	treeJSON, err := marshal.Marshal(proto.MessageV2(tree))
+	if err != nil {
+		return 0, err
+	}
+	rootJSON, err := marshal.Marshal(proto.MessageV2(slr))
+	if err != nil {
+		return 0, err
+	}
+	cp := tiles.Checkpoint{Origin: opts.Origin, Size: root.TreeSize, Hash: root.RootHash}
+	note, err := opts.Signer.Sign(cp.String())
+	if err != nil {
+		return 0, err
+	}
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{name: TreeFile, data: treeJSON},
+		{name: LogRootFile, data: rootJSON},
+		{name: CheckpointKeyFile, data: []byte(opts.Signer.VerifierKey() + "\n")},
+		{name: CheckpointFile, data: note},
+	} {
+		if err := add(f.name, f.data); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := forEachTile(root.TreeSize, func(t tiles.Tile) error {
+		var data []byte
+		if err := tiles.Snapshot(ctx, as, ls, opts.LogID, func(ctx context.Context, tx storage.ReadOnlyLogTreeTX, r *types.LogRootV1) error {
+			if r.TreeSize != root.TreeSize {
+				return status.Errorf(codes.Aborted, "log %d grew from %d to %d leaves while it was archived", opts.LogID, root.TreeSize, r.TreeSize)
+			}
+			var err error
+			data, err = tiles.ReadTile(ctx, tx, root.TreeSize, t)
+			return err
+		}); err != nil {
+			return fmt.Errorf("failed to read tile %s: %v", t.Path(), err)
+		}
+		return add(t.Path(), data)
+	}); err != nil {
+		return 0, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return 0, err
+	}
+	if err := gw.Close(); err != nil {
+		return 0, err
+	}
+	return root.TreeSize, nil
+}
+
+// forEachTile calls f with every tile of a tree of the given size, in the
+// order of archives: the entry bundle and hash tile of each full tile of
+// leaves, each followed by the full tiles above it which it completes, and
+// then the partial tiles of every level, from the bottom up.
+func forEachTile(size uint64, f func(tiles.Tile) error) error {
+	full, partial := tiles.TreeTiles(0, size)
+	for n := uint64(0); n < full; n++ {
+		if err := f(tiles.Tile{Level: tiles.EntriesLevel, Index: n, Width: tiles.Width}); err != nil {
+			return err
+		}
+		if err := f(tiles.Tile{Level: 0, Index: n, Width: tiles.Width}); err != nil {
+			return err
+		}
+		for level, m := 1, n; (m+1)%tiles.Width == 0; level++ {
+			m /= tiles.Width
+			if err := f(tiles.Tile{Level: level, Index: m, Width: tiles.Width}); err != nil {
+				return err
+			}
+		}
+	}
+	if partial > 0 {
+		if err := f(tiles.Tile{Level: tiles.EntriesLevel, Index: full, Width: partial}); err != nil {
+			return err
+		}
+		if err := f(tiles.Tile{Level: 0, Index: full, Width: partial}); err != nil {
+			return err
+		}
+	}
+	for level := 1; ; level++ {
+		full, partial := tiles.TreeTiles(level, size)
+		if full == 0 && partial == 0 {
+			return nil
+		}
+		if partial > 0 {
+			if err := f(tiles.Tile{Level: level, Index: full, Width: partial}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readFile reads the next file of an archive, which must have the given name.
+func readFile(tr *tar.Reader, name string) ([]byte, error) {
+	hdr, err := tr.Next()
+	if err == io.EOF {
+		return nil, fmt.Errorf("archive ends before %s", name)
+	} else if err != nil {
+		return nil, err
+	}
+	if hdr.Name != name {
+		return nil, fmt.Errorf("archive has %s where %s should be", hdr.Name, name)
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, tr); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	return buf.Bytes(), nil
+}
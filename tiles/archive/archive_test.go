@@ -0,0 +1,233 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/tiles"
+	"github.com/google/trillian/tiles/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestForEachTile(t *testing.T) {
+	for _, test := range []struct {
+		size uint64
+		want []string
+	}{
+		{size: 0},
+		{size: 3, want: []string{"tile/entries/000.p/3", "tile/0/000.p/3"}},
+		{size: 256, want: []string{"tile/entries/000", "tile/0/000", "tile/1/000.p/1"}},
+		{
+			size: 513,
+			want: []string{
+				"tile/entries/000", "tile/0/000",
+				"tile/entries/001", "tile/0/001",
+				"tile/entries/002.p/1", "tile/0/002.p/1",
+				"tile/1/000.p/2",
+			},
+		},
+	} {
+		var got []string
+		if err := forEachTile(test.size, func(t tiles.Tile) error {
+			got = append(got, t.Path())
+			return nil
+		}); err != nil {
+			t.Fatalf("forEachTile(%d): %v", test.size, err)
+		}
+		if diff := cmp.Diff(got, test.want); diff != "" {
+			t.Errorf("forEachTile(%d) diff (-got +want):\n%s", test.size, diff)
+		}
+	}
+
+	// The tiles are those of the tree, with every full tile at a level
+	// followed by the one above it.
+	const size = 1<<16 + 300
+	var got []tiles.Tile
+	forEachTile(size, func(t tiles.Tile) error {
+		got = append(got, t)
+		return nil
+	})
+	if got, want := len(got), len(tiles.NewTiles(0, size)); got != want {
+		t.Errorf("forEachTile(%d) returned %d tiles, want %d", size, got, want)
+	}
+	for i, tile := range got {
+		if tile.Level == 1 && tile.Index == 0 && tile.Width == tiles.Width {
+			if prev := got[i-1]; prev.Level != 0 || prev.Index != 255 {
+				t.Errorf("tile/1/000 follows %s, want tile/0/255", prev.Path())
+			}
+		}
+	}
+}
+
+// newArchive returns the archive of a frozen log with the given number of
+// leaves, and the verifier key of its checkpoint.
+func newArchive(ctx context.Context, t *testing.T, numLeaves int) ([]byte, string) {
+	t.Helper()
+	l := testonly.NewLog(ctx, t, stestonly.LogTree)
+	l.Append(ctx, t, numLeaves, 2000)
+	skey, vkey, err := tiles.GenerateNoteKey("example.com/log")
+	if err != nil {
+		t.Fatalf("GenerateNoteKey(): %v", err)
+	}
+	signer, err := tiles.NewNoteSigner(skey)
+	if err != nil {
+		t.Fatalf("NewNoteSigner(): %v", err)
+	}
+	opts := Options{LogID: l.Tree.TreeId, Origin: "example.com/log", Signer: signer}
+
+	if _, err := Write(ctx, l.AdminStorage, l.LogStorage, ioutil.Discard, opts); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Write(active log)=%v, want FailedPrecondition", err)
+	}
+	l.Freeze(ctx, t)
+	var buf bytes.Buffer
+	size, err := Write(ctx, l.AdminStorage, l.LogStorage, &buf, opts)
+	if err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	if got, want := size, uint64(numLeaves); got != want {
+		t.Fatalf("Write()=%d, want %d", got, want)
+	}
+	return buf.Bytes(), vkey
+}
+
+// rewrite returns a copy of an archive, with the contents of each file
+// replaced by edit, files dropped if edit returns nil, and an empty file with
+// the given name appended if it isn't empty.
+func rewrite(t *testing.T, archive []byte, edit func(name string, data []byte) []byte, extra string) []byte {
+	t.Helper()
+	gr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("gzip.NewReader(): %v", err)
+	}
+	tr := tar.NewReader(gr)
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("ReadAll(): %v", err)
+		}
+		if data = edit(hdr.Name, data); data == nil {
+			continue
+		}
+		hdr.Size = int64(len(data))
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("WriteHeader(): %v", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+	}
+	if extra != "" {
+		if err := tw.WriteHeader(&tar.Header{Name: extra, Mode: 0644}); err != nil {
+			t.Fatalf("WriteHeader(): %v", err)
+		}
+	}
+	tw.Close()
+	gw.Close()
+	return buf.Bytes()
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	const numLeaves = 1<<16 + 300
+	archive, vkey := newArchive(ctx, t, numLeaves)
+	empty, emptyKey := newArchive(ctx, t, 0)
+	_, otherKey, err := tiles.GenerateNoteKey("example.com/log")
+	if err != nil {
+		t.Fatalf("GenerateNoteKey(): %v", err)
+	}
+
+	flip := func(file string, i int) func(string, []byte) []byte {
+		return func(name string, data []byte) []byte {
+			if name == file {
+				data = append([]byte(nil), data...)
+				data[i] ^= 1
+			}
+			return data
+		}
+	}
+	drop := func(file string) func(string, []byte) []byte {
+		return func(name string, data []byte) []byte {
+			if name == file {
+				return nil
+			}
+			return data
+		}
+	}
+
+	for _, test := range []struct {
+		desc       string
+		archive    []byte
+		trustedKey string
+		edit       func(name string, data []byte) []byte
+		extra      string
+		wantErr    string
+	}{
+		{desc: "ok", archive: archive, trustedKey: vkey},
+		{desc: "ok-untrusted", archive: archive},
+		{desc: "empty", archive: empty, trustedKey: emptyKey},
+		{desc: "other-key", archive: archive, trustedKey: otherKey, wantErr: "isn't the trusted key"},
+		{desc: "entry", archive: archive, edit: flip("tile/entries/256", 10), wantErr: "tile/0/256"},
+		{desc: "partial-entry", archive: archive, edit: flip("tile/entries/257.p/44", 10), wantErr: "tile/0/257.p/44"},
+		{desc: "leaf-hash", archive: archive, edit: flip("tile/0/256", 0), wantErr: "tile/0/256"},
+		{desc: "level-1", archive: archive, edit: flip("tile/1/000", 0), wantErr: "tile/1/000"},
+		{desc: "level-2", archive: archive, edit: flip("tile/2/000.p/1", 0), wantErr: "tile/2/000.p/1"},
+		{desc: "missing-tile", archive: archive, edit: drop("tile/1/000"), wantErr: "tile/1/000"},
+		{desc: "missing-checkpoint", archive: archive, edit: drop(CheckpointFile), wantErr: "checkpoint"},
+		{desc: "checkpoint", archive: archive, edit: flip(CheckpointFile, 20), wantErr: "checkpoint"},
+		{desc: "log-root", archive: archive, edit: flip(LogRootFile, 30), wantErr: "log_root.json"},
+		{desc: "extra-file", archive: empty, extra: "tile/0/000.p/1", wantErr: "unexpected file tile/0/000.p/1"},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			archive := test.archive
+			if test.edit != nil || test.extra != "" {
+				edit := test.edit
+				if edit == nil {
+					edit = func(_ string, data []byte) []byte { return data }
+				}
+				archive = rewrite(t, archive, edit, test.extra)
+			}
+			cp, err := Verify(bytes.NewReader(archive), test.trustedKey)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Verify()=%v, want error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify(): %v", err)
+			}
+			if cp.Origin != "example.com/log" {
+				t.Errorf("Verify() returned checkpoint %+v", cp)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/tiles"
+	"github.com/google/trillian/types"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Verify checks an archive read from r end to end, and returns its checkpoint.
+//
+// It checks that the tree is that of a frozen log, that the log root is signed
+// by the key of the tree, and the checkpoint by the checkpoint key, that both
+// commit to the same tree, and that the archive holds exactly the tiles of
+// that tree, whose hashes match the entries, and whose root hash is that of
+// the checkpoint. If trustedKey isn't empty, the checkpoint key must be
+// trustedKey; otherwise the keys in the archive are trusted, which only checks
+// the archive's integrity, not where it came from.
+func Verify(r io.Reader, trustedKey string) (tiles.Checkpoint, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return tiles.Checkpoint{}, err
+	}
+	tr := tar.NewReader(gr)
+
+	data, err := readFile(tr, TreeFile)
+	if err != nil {
+		return tiles.Checkpoint{}, err
+	}
+	var tree trillian.Tree
+	if err := protojson.Unmarshal(data, proto.MessageV2(&tree)); err != nil {
+		return tiles.Checkpoint{}, fmt.Errorf("malformed %s: %v", TreeFile, err)
+	}
+	if tree.TreeState != trillian.TreeState_FROZEN {
+		return tiles.Checkpoint{}, fmt.Errorf("tree is %v, not %v", tree.TreeState, trillian.TreeState_FROZEN)
+	}
+	v, err := client.NewLogVerifierFromTree(&tree)
+	if err != nil {
+		return tiles.Checkpoint{}, err
+	}
+
+	if data, err = readFile(tr, LogRootFile); err != nil {
+		return tiles.Checkpoint{}, err
+	}
+	var slr trillian.SignedLogRoot
+	if err := protojson.Unmarshal(data, proto.MessageV2(&slr)); err != nil {
+		return tiles.Checkpoint{}, fmt.Errorf("malformed %s: %v", LogRootFile, err)
+	}
+	root, err := v.VerifyRoot(&types.LogRootV1{}, &slr, nil)
+	if err != nil {
+		return tiles.Checkpoint{}, fmt.Errorf("log root: %v", err)
+	}
+
+	if data, err = readFile(tr, CheckpointKeyFile); err != nil {
+		return tiles.Checkpoint{}, err
+	}
+	key := strings.TrimSpace(string(data))
+	if trustedKey != "" && key != trustedKey {
+		return tiles.Checkpoint{}, fmt.Errorf("checkpoint key %q isn't the trusted key %q", key, trustedKey)
+	}
+	nv, err := tiles.NewNoteVerifier(key)
+	if err != nil {
+		return tiles.Checkpoint{}, err
+	}
+	if data, err = readFile(tr, CheckpointFile); err != nil {
+		return tiles.Checkpoint{}, err
+	}
+	text, err := nv.Open(data)
+	if err != nil {
+		return tiles.Checkpoint{}, fmt.Errorf("checkpoint: %v", err)
+	}
+	cp, err := tiles.ParseCheckpoint(text)
+	if err != nil {
+		return tiles.Checkpoint{}, err
+	}
+	if cp.Size != root.TreeSize || !bytes.Equal(cp.Hash, root.RootHash) {
+		return tiles.Checkpoint{}, fmt.Errorf("checkpoint of size %d and hash %x doesn't match log root of size %d and hash %x", cp.Size, cp.Hash, root.TreeSize, root.RootHash)
+	}
+
+	tv := newTileVerifier(v.Hasher)
+	if err := forEachTile(cp.Size, func(t tiles.Tile) error {
+		data, err := readFile(tr, t.Path())
+		if err != nil {
+			return err
+		}
+		if err := tv.verify(t, data); err != nil {
+			return fmt.Errorf("tile %s: %v", t.Path(), err)
+		}
+		return nil
+	}); err != nil {
+		return tiles.Checkpoint{}, err
+	}
+	if hdr, err := tr.Next(); err == nil {
+		return tiles.Checkpoint{}, fmt.Errorf("archive has unexpected file %s", hdr.Name)
+	} else if err != io.EOF {
+		return tiles.Checkpoint{}, err
+	}
+
+	rootHash, err := tv.rootHash()
+	if err != nil {
+		return tiles.Checkpoint{}, err
+	}
+	if !bytes.Equal(rootHash, cp.Hash) {
+		return tiles.Checkpoint{}, fmt.Errorf("root hash of tiles is %x, but checkpoint has %x", rootHash, cp.Hash)
+	}
+	return cp, nil
+}
+
+// tileVerifier verifies the tiles of a tree, in the order of archives.
+type tileVerifier struct {
+	hasher hashers.LogHasher
+	// leaves is the compact range of the leaves verified so far.
+	leaves *compact.Range
+	// leafHashes holds the hashes of the entries of the latest entry bundle.
+	leafHashes [][]byte
+	// roots holds, for each level, the roots of the full tiles at the level
+	// which haven't been checked against the tile above them yet.
+	roots map[int][][]byte
+}
+
+func newTileVerifier(hasher hashers.LogHasher) *tileVerifier {
+	fact := compact.RangeFactory{Hash: hasher.HashChildren}
+	return &tileVerifier{hasher: hasher, leaves: fact.NewEmptyRange(0), roots: make(map[int][][]byte)}
+}
+
+// verify checks the contents of a tile.
+func (v *tileVerifier) verify(t tiles.Tile, data []byte) error {
+	if t.Level == tiles.EntriesLevel {
+		hashes, err := v.entryHashes(data)
+		if err != nil {
+			return err
+		}
+		if got, want := len(hashes), t.Width; got != want {
+			return fmt.Errorf("has %d entries, want %d", got, want)
+		}
+		for _, hash := range hashes {
+			if err := v.leaves.Append(hash, nil); err != nil {
+				return err
+			}
+		}
+		v.leafHashes = hashes
+		return nil
+	}
+
+	want := v.leafHashes
+	if t.Level > 0 {
+		want = v.roots[t.Level-1]
+		delete(v.roots, t.Level-1)
+	}
+	if got, want := len(data), len(want)*v.hasher.Size(); got != want {
+		return fmt.Errorf("has %d bytes, want %d", got, want)
+	}
+	if !bytes.Equal(data, bytes.Join(want, nil)) {
+		return errors.New("hashes don't match the tiles below it")
+	}
+	if t.Width == tiles.Width {
+		v.roots[t.Level] = append(v.roots[t.Level], v.subtreeRoot(want))
+	}
+	return nil
+}
+
+// entryHashes returns the leaf hashes of the entries of an entry bundle.
+func (v *tileVerifier) entryHashes(data []byte) ([][]byte, error) {
+	var hashes [][]byte
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, errors.New("truncated entry length")
+		}
+		n := int(binary.BigEndian.Uint16(data))
+		if len(data) < 2+n {
+			return nil, errors.New("truncated entry")
+		}
+		hashes = append(hashes, v.hasher.HashLeaf(data[2:2+n]))
+		data = data[2+n:]
+	}
+	return hashes, nil
+}
+
+// subtreeRoot returns the root hash of the perfect subtree with the given
+// hashes at its bottom level.
+func (v *tileVerifier) subtreeRoot(hashes [][]byte) []byte {
+	level := append([][]byte(nil), hashes...)
+	for len(level) > 1 {
+		for i := 0; i < len(level)/2; i++ {
+			level[i] = v.hasher.HashChildren(level[2*i], level[2*i+1])
+		}
+		level = level[:len(level)/2]
+	}
+	return level[0]
+}
+
+// rootHash returns the root hash of the tree of the leaves verified, after
+// checking that all the full tiles were covered by the tiles above them.
+func (v *tileVerifier) rootHash() ([]byte, error) {
+	for level, roots := range v.roots {
+		if len(roots) > 0 {
+			return nil, fmt.Errorf("%d full tiles at level %d aren't in any tile above them", len(roots), level)
+		}
+	}
+	if v.leaves.End() == 0 {
+		return v.hasher.EmptyRoot(), nil
+	}
+	return v.leaves.GetRootHash(nil)
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Checkpoint is the body of a checkpoint note (https://c2sp.org/tlog-checkpoint),
+// which commits to the size and root hash of a log.
+type Checkpoint struct {
+	// Origin uniquely identifies the log, e.g. "example.com/log".
+	Origin string
+	Size   uint64
+	Hash   []byte
+}
+
+// String returns the note text of the checkpoint.
+func (c Checkpoint) String() string {
+	return fmt.Sprintf("%s\n%d\n%s\n", c.Origin, c.Size, base64.StdEncoding.EncodeToString(c.Hash))
+}
+
+// ParseCheckpoint parses the text of a checkpoint note. Extension lines after
+// the root hash are ignored.
+func ParseCheckpoint(text string) (Checkpoint, error) {
+	lines := strings.SplitN(text, "\n", 4)
+	if len(lines) < 4 || lines[0] == "" {
+		return Checkpoint{}, fmt.Errorf("malformed checkpoint %q", text)
+	}
+	size, err := strconv.ParseUint(lines[1], 10, 64)
+	if err != nil || lines[1] != strconv.FormatUint(size, 10) {
+		return Checkpoint{}, fmt.Errorf("malformed checkpoint size %q", lines[1])
+	}
+	hash, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil || len(hash) == 0 {
+		return Checkpoint{}, fmt.Errorf("malformed checkpoint hash %q", lines[2])
+	}
+	return Checkpoint{Origin: lines[0], Size: size, Hash: hash}, nil
+}
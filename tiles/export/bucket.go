@@ -0,0 +1,74 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// OpenBucket returns the Bucket at dest, which is a directory, or a
+// gs://<bucket>[/<prefix>] or s3://<bucket>[/<prefix>] URL. Credentials for
+// object storage are found in the environment, as the Google Cloud and AWS
+// SDKs do by default.
+func OpenBucket(ctx context.Context, dest string) (Bucket, error) {
+	switch {
+	case strings.HasPrefix(dest, "gs://"):
+		bucket, prefix := splitBucketURL(strings.TrimPrefix(dest, "gs://"))
+		client, err := gcs.NewClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return NewGCSBucket(client.Bucket(bucket), prefix), nil
+	case strings.HasPrefix(dest, "s3://"):
+		bucket, prefix := splitBucketURL(strings.TrimPrefix(dest, "s3://"))
+		sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+		if err != nil {
+			return nil, err
+		}
+		return NewS3Bucket(s3.New(sess), bucket, prefix), nil
+	default:
+		return NewDirBucket(dest), nil
+	}
+}
+
+// splitBucketURL splits the path of a bucket URL into the bucket name and the
+// prefix of the objects in it.
+func splitBucketURL(p string) (bucket, prefix string) {
+	i := strings.Index(p, "/")
+	if i < 0 {
+		return p, ""
+	}
+	return p[:i], strings.Trim(p[i+1:], "/")
+}
+
+// prefixBucket is a Bucket of the files of another Bucket under a directory.
+type prefixBucket struct {
+	Bucket
+	prefix string
+}
+
+func (b prefixBucket) Read(ctx context.Context, name string) ([]byte, error) {
+	return b.Bucket.Read(ctx, path.Join(b.prefix, name))
+}
+
+func (b prefixBucket) Write(ctx context.Context, name string, data []byte, immutable bool) error {
+	return b.Bucket.Write(ctx, path.Join(b.prefix, name), data, immutable)
+}
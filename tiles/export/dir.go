@@ -0,0 +1,68 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// DirBucket is a Bucket of files in a local directory, which can be served by
+// a static web server.
+type DirBucket struct {
+	root string
+}
+
+// NewDirBucket returns a DirBucket of the files under root.
+func NewDirBucket(root string) *DirBucket {
+	return &DirBucket{root: root}
+}
+
+// Read implements Bucket.
+func (b *DirBucket) Read(ctx context.Context, path string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(b.root, filepath.FromSlash(path)))
+}
+
+// Write implements Bucket. Files are written to a temporary file, and renamed
+// into place, so readers never see partial files.
+func (b *DirBucket) Write(ctx context.Context, path string, data []byte, immutable bool) error {
+	name := filepath.Join(b.root, filepath.FromSlash(path))
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, ".tmp-"+filepath.Base(name))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
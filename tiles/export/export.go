@@ -0,0 +1,274 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package export materializes Trillian logs into the static layouts served by
+// the tiles package, in directories or object storage buckets, so they can be
+// read from a CDN or static web server without a Trillian server.
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/tiles"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Bucket stores the files of an exported log.
+type Bucket interface {
+	// Read returns the contents of the file at path, or an error wrapping
+	// os.ErrNotExist if there's none.
+	Read(ctx context.Context, path string) ([]byte, error)
+	// Write creates or replaces the file at path. Immutable files are tiles,
+	// which never change once written, and may be cached indefinitely. Other
+	// files are checkpoints.
+	Write(ctx context.Context, path string, data []byte, immutable bool) error
+}
+
+// httpHeaders returns the Content-Type and Cache-Control headers with which
+// files are served, for buckets which store them.
+func httpHeaders(immutable bool) (contentType, cacheControl string) {
+	if immutable {
+		return "application/octet-stream", "public, max-age=31536000, immutable"
+	}
+	return "text/plain; charset=utf-8", "no-cache"
+}
+
+// Options holds the settings of an Exporter.
+type Options struct {
+	// LogID is the ID of the log to export.
+	LogID int64
+	// Origin is the origin of the exported checkpoints.
+	Origin string
+	// Signer signs the exported checkpoints.
+	Signer *tiles.NoteSigner
+	// SumDB selects the layout of Go checksum databases, rather than that of
+	// tlog-tiles. Origin is ignored, as tree notes have a fixed origin.
+	SumDB bool
+	// MetricFactory creates the metrics of the Exporter, if set.
+	MetricFactory monitoring.MetricFactory
+}
+
+// Exporter keeps a copy of a log in a Bucket, in the layout served by
+// tiles.Handler or tiles.NewSumDBHandler.
+//
+// Each export writes the tiles which the latest root of the log has, and the
+// root exported to the bucket before it hadn't, and then the checkpoint of the
+// root. The previous export is found from the checkpoint in the bucket, so
+// exports pick up where they left off, and writing the checkpoint last means
+// readers never see a checkpoint whose tiles aren't there yet. Tiles written by
+// an export which failed before its checkpoint are written again by the next.
+type Exporter struct {
+	as       storage.AdminStorage
+	ls       storage.LogStorage
+	bucket   Bucket
+	opts     Options
+	verifier *tiles.NoteVerifier
+	metrics  *metrics
+}
+
+// metrics holds the metrics of Exporters, which are shared by all of the
+// Exporters of a Replicator.
+type metrics struct {
+	runs     monitoring.Counter
+	failures monitoring.Counter
+	files    monitoring.Counter
+	size     monitoring.Gauge
+}
+
+func newMetrics(mf monitoring.MetricFactory) *metrics {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &metrics{
+		runs:     mf.NewCounter("export_runs", "Number of exports of a log", "logid"),
+		failures: mf.NewCounter("export_failures", "Number of exports of a log which failed", "logid"),
+		files:    mf.NewCounter("export_files", "Number of tiles and checkpoints written by exports of a log", "logid"),
+		size:     mf.NewGauge("export_tree_size", "Size of the tree of the latest checkpoint exported for a log", "logid"),
+	}
+}
+
+// New returns an Exporter of a log in the given storage to bucket.
+func New(as storage.AdminStorage, ls storage.LogStorage, bucket Bucket, opts Options) (*Exporter, error) {
+	return newExporter(as, ls, bucket, opts, newMetrics(opts.MetricFactory))
+}
+
+func newExporter(as storage.AdminStorage, ls storage.LogStorage, bucket Bucket, opts Options, m *metrics) (*Exporter, error) {
+	if opts.Signer == nil {
+		return nil, errors.New("no checkpoint signer")
+	}
+	if opts.SumDB {
+		opts.Origin = tiles.SumDBOrigin
+	} else if opts.Origin == "" {
+		return nil, errors.New("no checkpoint origin")
+	}
+	verifier, err := tiles.NewNoteVerifier(opts.Signer.VerifierKey())
+	if err != nil {
+		return nil, err
+	}
+	return &Exporter{
+		as:       as,
+		ls:       ls,
+		bucket:   bucket,
+		opts:     opts,
+		verifier: verifier,
+		metrics:  m,
+	}, nil
+}
+
+// Run exports the log every interval, until ctx is done.
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if size, err := e.Export(ctx); err != nil {
+			glog.Warningf("%v: export failed: %v", e.opts.LogID, err)
+		} else {
+			glog.V(1).Infof("%v: exported tree of size %d", e.opts.LogID, size)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Export brings the bucket up to date with the latest root of the log, and
+// returns the size of its tree. It fails with FailedPrecondition if the bucket
+// holds a checkpoint which doesn't belong to the log, or isn't a prefix of it.
+func (e *Exporter) Export(ctx context.Context) (uint64, error) {
+	label := strconv.FormatInt(e.opts.LogID, 10)
+	e.metrics.runs.Inc(label)
+	size, err := e.export(ctx, label)
+	if err != nil {
+		e.metrics.failures.Inc(label)
+		return 0, err
+	}
+	e.metrics.size.Set(float64(size), label)
+	return size, nil
+}
+
+func (e *Exporter) export(ctx context.Context, label string) (uint64, error) {
+	prev, exported, err := e.exported(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var root types.LogRootV1
+	if err := e.snapshot(ctx, func(ctx context.Context, tx storage.ReadOnlyLogTreeTX, r *types.LogRootV1) error {
+		root = *r
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	if exported {
+		switch {
+		case root.TreeSize < prev.Size:
+			return 0, status.Errorf(codes.FailedPrecondition, "log %d has %d leaves, fewer than the %d exported", e.opts.LogID, root.TreeSize, prev.Size)
+		case root.TreeSize == prev.Size && !bytes.Equal(root.RootHash, prev.Hash):
+			return 0, status.Errorf(codes.FailedPrecondition, "log %d has root hash %x at size %d, but %x was exported", e.opts.LogID, root.RootHash, root.TreeSize, prev.Hash)
+		case root.TreeSize == prev.Size:
+			return root.TreeSize, nil
+		}
+	}
+
+	for _, t := range tiles.NewTiles(prev.Size, root.TreeSize) {
+		var data []byte
+		if err := e.snapshot(ctx, func(ctx context.Context, tx storage.ReadOnlyLogTreeTX, _ *types.LogRootV1) error {
+			var err error
+			if e.opts.SumDB {
+				data, err = tiles.ReadSumDBTile(ctx, tx, root.TreeSize, t)
+			} else {
+				data, err = tiles.ReadTile(ctx, tx, root.TreeSize, t)
+			}
+			return err
+		}); err != nil {
+			return 0, fmt.Errorf("failed to read tile %s: %v", t.Path(), err)
+		}
+		if err := e.bucket.Write(ctx, e.tilePath(t), data, true); err != nil {
+			return 0, fmt.Errorf("failed to write tile %s: %v", t.Path(), err)
+		}
+		e.metrics.files.Inc(label)
+	}
+
+	cp := tiles.Checkpoint{Origin: e.opts.Origin, Size: root.TreeSize, Hash: root.RootHash}
+	note, err := e.opts.Signer.Sign(cp.String())
+	if err != nil {
+		return 0, err
+	}
+	if err := e.bucket.Write(ctx, e.checkpointPath(), note, false); err != nil {
+		return 0, fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	e.metrics.files.Inc(label)
+	return root.TreeSize, nil
+}
+
+// exported returns the checkpoint in the bucket, and whether there is one.
+func (e *Exporter) exported(ctx context.Context) (tiles.Checkpoint, bool, error) {
+	note, err := e.bucket.Read(ctx, e.checkpointPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return tiles.Checkpoint{}, false, nil
+	} else if err != nil {
+		return tiles.Checkpoint{}, false, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+	text, err := e.verifier.Open(note)
+	if err != nil {
+		return tiles.Checkpoint{}, false, status.Errorf(codes.FailedPrecondition, "checkpoint in bucket isn't signed by %s: %v", e.opts.Signer.Name(), err)
+	}
+	cp, err := tiles.ParseCheckpoint(text)
+	if err != nil {
+		return tiles.Checkpoint{}, false, status.Errorf(codes.FailedPrecondition, "checkpoint in bucket: %v", err)
+	}
+	if cp.Origin != e.opts.Origin {
+		return tiles.Checkpoint{}, false, status.Errorf(codes.FailedPrecondition, "checkpoint in bucket has origin %q, want %q", cp.Origin, e.opts.Origin)
+	}
+	return cp, true, nil
+}
+
+// snapshot calls f with a read-only transaction on the log, and its latest
+// root.
+func (e *Exporter) snapshot(ctx context.Context, f func(context.Context, storage.ReadOnlyLogTreeTX, *types.LogRootV1) error) error {
+	return tiles.Snapshot(ctx, e.as, e.ls, e.opts.LogID, func(ctx context.Context, tx storage.ReadOnlyLogTreeTX, root *types.LogRootV1) error {
+		if tree, _ := trees.FromContext(ctx); e.opts.SumDB && tree.HashStrategy != trillian.HashStrategy_SUMDB_TLOG_SHA256 {
+			return status.Errorf(codes.FailedPrecondition, "log %d doesn't use %v hashing", e.opts.LogID, trillian.HashStrategy_SUMDB_TLOG_SHA256)
+		}
+		return f(ctx, tx, root)
+	})
+}
+
+func (e *Exporter) checkpointPath() string {
+	if e.opts.SumDB {
+		return "latest"
+	}
+	return "checkpoint"
+}
+
+func (e *Exporter) tilePath(t tiles.Tile) string {
+	if e.opts.SumDB {
+		return t.SumDBPath()
+	}
+	return t.Path()
+}
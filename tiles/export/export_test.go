@@ -0,0 +1,240 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/tiles"
+	"github.com/google/trillian/tiles/testonly"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordingBucket is a Bucket which records the paths written to it.
+type recordingBucket struct {
+	Bucket
+	written []string
+}
+
+func (b *recordingBucket) Write(ctx context.Context, path string, data []byte, immutable bool) error {
+	b.written = append(b.written, path)
+	return b.Bucket.Write(ctx, path, data, immutable)
+}
+
+func newSigner(t *testing.T, name string) *tiles.NoteSigner {
+	t.Helper()
+	skey, _, err := tiles.GenerateNoteKey(name)
+	if err != nil {
+		t.Fatalf("GenerateNoteKey(): %v", err)
+	}
+	signer, err := tiles.NewNoteSigner(skey)
+	if err != nil {
+		t.Fatalf("NewNoteSigner(): %v", err)
+	}
+	return signer
+}
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := testonly.NewLog(ctx, t, stestonly.LogTree)
+	signer := newSigner(t, "example.com/log")
+	bucket := &recordingBucket{Bucket: NewDirBucket(dir)}
+	e, err := New(l.AdminStorage, l.LogStorage, bucket, Options{LogID: l.Tree.TreeId, Origin: "example.com/log", Signer: signer})
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	h := tiles.NewHandler(l.AdminStorage, l.LogStorage, "/", "", signer)
+
+	for _, test := range []struct {
+		desc      string
+		leaves    int
+		wantFiles []string
+	}{
+		{desc: "empty", wantFiles: []string{"checkpoint"}},
+		{
+			desc:      "first",
+			leaves:    300,
+			wantFiles: []string{"checkpoint", "tile/0/000", "tile/0/001.p/44", "tile/1/000.p/1", "tile/entries/000", "tile/entries/001.p/44"},
+		},
+		{desc: "unchanged"},
+		{
+			desc:      "grown",
+			leaves:    300,
+			wantFiles: []string{"checkpoint", "tile/0/001", "tile/0/002.p/88", "tile/1/000.p/2", "tile/entries/001", "tile/entries/002.p/88"},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			l.Append(ctx, t, test.leaves, 100)
+			bucket.written = nil
+			size, err := e.Export(ctx)
+			if err != nil {
+				t.Fatalf("Export(): %v", err)
+			}
+			if got, want := size, uint64(len(l.Leaves)); got != want {
+				t.Errorf("Export()=%d, want %d", got, want)
+			}
+			sort.Strings(bucket.written)
+			if diff := cmp.Diff(bucket.written, test.wantFiles); diff != "" {
+				t.Errorf("written files diff (-got +want):\n%s", diff)
+			}
+			// Tiles are exported as they are served.
+			for _, path := range bucket.written {
+				if path == "checkpoint" {
+					continue
+				}
+				got, err := bucket.Read(ctx, path)
+				if err != nil {
+					t.Fatalf("Read(%v): %v", path, err)
+				}
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%d/%s", l.Tree.TreeId, path), nil))
+				if want := w.Body.Bytes(); !bytes.Equal(got, want) {
+					t.Errorf("exported %v=%x, served %x", path, got, want)
+				}
+			}
+		})
+	}
+
+	cp, ok, err := e.exported(ctx)
+	if err != nil || !ok {
+		t.Fatalf("exported()=%v, %v", ok, err)
+	}
+	if cp.Origin != "example.com/log" || cp.Size != 600 {
+		t.Errorf("exported checkpoint %+v, want 600 leaves of example.com/log", cp)
+	}
+
+	// The bucket now holds a checkpoint of a bigger tree than that of another
+	// log, and one which other signers can't export over.
+	other := testonly.NewLog(ctx, t, stestonly.LogTree)
+	other.Append(ctx, t, 10, 10)
+	for _, test := range []struct {
+		desc   string
+		log    *testonly.Log
+		signer *tiles.NoteSigner
+	}{
+		{desc: "smaller-log", log: other, signer: signer},
+		{desc: "other-signer", log: l, signer: newSigner(t, "example.com/log")},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			e, err := New(test.log.AdminStorage, test.log.LogStorage, bucket, Options{LogID: test.log.Tree.TreeId, Origin: "example.com/log", Signer: test.signer})
+			if err != nil {
+				t.Fatalf("New(): %v", err)
+			}
+			if _, err := e.Export(ctx); status.Code(err) != codes.FailedPrecondition {
+				t.Errorf("Export()=%v, want FailedPrecondition", err)
+			}
+		})
+	}
+}
+
+func TestExportSumDB(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	tree.HashStrategy = trillian.HashStrategy_SUMDB_TLOG_SHA256
+	l := testonly.NewLog(ctx, t, tree)
+	l.Append(ctx, t, 3, 3)
+	signer := newSigner(t, "sum.example.com")
+	bucket := NewDirBucket(dir)
+	e, err := New(l.AdminStorage, l.LogStorage, bucket, Options{LogID: l.Tree.TreeId, Signer: signer, SumDB: true})
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	if _, err := e.Export(ctx); err != nil {
+		t.Fatalf("Export(): %v", err)
+	}
+	data, err := bucket.Read(ctx, "tile/8/data/000.p/3")
+	if err != nil {
+		t.Fatalf("Read(): %v", err)
+	}
+	if got, want := string(data), "0\nleaf 0\n\n1\nleaf 1\n\n2\nleaf 2\n\n"; got != want {
+		t.Errorf("data tile %q, want %q", got, want)
+	}
+	cp, ok, err := e.exported(ctx)
+	if err != nil || !ok {
+		t.Fatalf("exported()=%v, %v", ok, err)
+	}
+	if cp.Origin != tiles.SumDBOrigin || cp.Size != 3 {
+		t.Errorf("exported checkpoint %+v, want 3 leaves of %q", cp, tiles.SumDBOrigin)
+	}
+
+	// Logs hashed otherwise can't be exported in this layout.
+	other := testonly.NewLog(ctx, t, stestonly.LogTree)
+	e, err = New(other.AdminStorage, other.LogStorage, NewDirBucket(dir+"/other"), Options{LogID: other.Tree.TreeId, Signer: signer, SumDB: true})
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	if _, err := e.Export(ctx); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Export()=%v, want FailedPrecondition", err)
+	}
+}
+
+func TestDirBucket(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := NewDirBucket(dir)
+	if _, err := b.Read(ctx, "tile/0/000"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Read(missing)=%v, want os.ErrNotExist", err)
+	}
+	for _, data := range []string{"one", "two"} {
+		if err := b.Write(ctx, "tile/0/000", []byte(data), true); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+		got, err := b.Read(ctx, "tile/0/000")
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if string(got) != data {
+			t.Errorf("Read()=%q, want %q", got, data)
+		}
+	}
+	files, err := ioutil.ReadDir(dir + "/tile/0")
+	if err != nil {
+		t.Fatalf("ReadDir(): %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("tile/0 has %d files, want 1", len(files))
+	}
+}
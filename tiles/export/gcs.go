@@ -0,0 +1,64 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"cloud.google.com/go/storage"
+)
+
+// GCSBucket is a Bucket of objects in a Google Cloud Storage bucket.
+type GCSBucket struct {
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+// NewGCSBucket returns a GCSBucket of the objects in bucket whose names start
+// with prefix, which is followed by a slash unless it's empty.
+func NewGCSBucket(bucket *storage.BucketHandle, prefix string) *GCSBucket {
+	return &GCSBucket{bucket: bucket, prefix: prefix}
+}
+
+func (b *GCSBucket) object(name string) *storage.ObjectHandle {
+	return b.bucket.Object(path.Join(b.prefix, name))
+}
+
+// Read implements Bucket.
+func (b *GCSBucket) Read(ctx context.Context, name string) ([]byte, error) {
+	r, err := b.object(name).NewReader(ctx)
+	if err == storage.ErrObjectNotExist {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	} else if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// Write implements Bucket.
+func (b *GCSBucket) Write(ctx context.Context, name string, data []byte, immutable bool) error {
+	w := b.object(name).NewWriter(ctx)
+	w.ContentType, w.CacheControl = httpHeaders(immutable)
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
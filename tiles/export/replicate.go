@@ -0,0 +1,216 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/tiles"
+	"github.com/google/trillian/trees"
+	"golang.org/x/sync/semaphore"
+)
+
+var replicateOpts = trees.NewGetOpts(trees.Query, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
+
+// ReplicatorOptions holds the settings of a Replicator.
+type ReplicatorOptions struct {
+	// OriginPrefix is followed by the ID of a log in the origin of its
+	// checkpoints, as with tiles.NewHandler.
+	OriginPrefix string
+	// Signer signs the replicated checkpoints.
+	Signer *tiles.NoteSigner
+	// RetryInterval is how often logs whose replication failed are retried.
+	RetryInterval time.Duration
+	// Workers is the number of logs replicated in parallel.
+	Workers int
+	// MetricFactory creates the metrics of the Replicator, if set.
+	MetricFactory monitoring.MetricFactory
+}
+
+// Replicator keeps an off-site copy of the logs in storage in a Bucket, by
+// exporting each log as soon as it's notified that the log has grown. The
+// files of the log with ID <id> are written under <id>/ in the bucket, in the
+// layout of Go checksum databases for logs with SUMDB_TLOG_SHA256 hashing, as
+// tiles.NewSumDBHandler serves them, and in the tlog-tiles layout, as served
+// by tiles.Handler, for other logs.
+//
+// Notifications only say which logs to replicate, so they are coalesced, and a
+// log which grows several times while it's being replicated is replicated once
+// more afterwards. Each replication catches up from the checkpoint in the
+// bucket, so nothing is lost if the Replicator falls behind or restarts.
+type Replicator struct {
+	as     storage.AdminStorage
+	ls     storage.LogStorage
+	bucket Bucket
+	opts   ReplicatorOptions
+
+	metrics *metrics
+	lag     monitoring.Histogram
+
+	// wake is signalled when a log is notified.
+	wake chan struct{}
+
+	// mu guards the fields below.
+	mu sync.Mutex
+	// pending holds the logs to replicate, and when each of them was first
+	// notified since it was last replicated.
+	pending map[int64]time.Time
+	// exporters holds the Exporter of each log replicated so far.
+	exporters map[int64]*Exporter
+}
+
+// NewReplicator returns a Replicator of the logs in the given storage to
+// bucket.
+func NewReplicator(as storage.AdminStorage, ls storage.LogStorage, bucket Bucket, opts ReplicatorOptions) (*Replicator, error) {
+	if opts.Signer == nil {
+		return nil, errors.New("no checkpoint signer")
+	}
+	if opts.RetryInterval <= 0 {
+		return nil, errors.New("retry interval must be positive")
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	mf := opts.MetricFactory
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &Replicator{
+		as:        as,
+		ls:        ls,
+		bucket:    bucket,
+		opts:      opts,
+		metrics:   newMetrics(mf),
+		lag:       mf.NewHistogram("replication_lag_seconds", "Time from a log being notified to its checkpoint being replicated, in seconds", "logid"),
+		wake:      make(chan struct{}, 1),
+		pending:   make(map[int64]time.Time),
+		exporters: make(map[int64]*Exporter),
+	}, nil
+}
+
+// Notify tells the Replicator that the log with the given ID has grown. It
+// doesn't block.
+func (r *Replicator) Notify(logID int64) {
+	r.mu.Lock()
+	if _, ok := r.pending[logID]; !ok {
+		r.pending[logID] = time.Now()
+	}
+	r.mu.Unlock()
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run replicates logs as they are notified, until ctx is done.
+func (r *Replicator) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.opts.RetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-ticker.C:
+		}
+		r.replicatePending(ctx)
+	}
+}
+
+// replicatePending replicates the logs notified so far. Logs whose replication
+// fails stay pending, and are retried on the next call.
+func (r *Replicator) replicatePending(ctx context.Context) {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[int64]time.Time)
+	r.mu.Unlock()
+
+	sem := semaphore.NewWeighted(int64(r.opts.Workers))
+	var wg sync.WaitGroup
+	for logID, since := range pending {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			break // Terminate because the context is canceled.
+		}
+		wg.Add(1)
+		go func(logID int64, since time.Time) {
+			defer wg.Done()
+			defer sem.Release(1)
+			size, err := r.replicate(ctx, logID)
+			if err != nil {
+				glog.Warningf("%v: replication failed: %v", logID, err)
+				r.retry(logID, since)
+				return
+			}
+			r.lag.Observe(time.Since(since).Seconds(), strconv.FormatInt(logID, 10))
+			glog.V(1).Infof("%v: replicated tree of size %d", logID, size)
+		}(logID, since)
+	}
+	wg.Wait()
+}
+
+// retry marks a log as pending again, as of when it was first notified.
+func (r *Replicator) retry(logID int64, since time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.pending[logID]; !ok || since.Before(t) {
+		r.pending[logID] = since
+	}
+}
+
+// replicate exports a log to the bucket, and returns the size of its tree.
+func (r *Replicator) replicate(ctx context.Context, logID int64) (uint64, error) {
+	e, err := r.exporter(ctx, logID)
+	if err != nil {
+		return 0, err
+	}
+	return e.Export(ctx)
+}
+
+// exporter returns the Exporter of a log, which is created on first use.
+func (r *Replicator) exporter(ctx context.Context, logID int64) (*Exporter, error) {
+	r.mu.Lock()
+	e, ok := r.exporters[logID]
+	r.mu.Unlock()
+	if ok {
+		return e, nil
+	}
+
+	tree, err := trees.GetTree(ctx, r.as, logID, replicateOpts)
+	if err != nil {
+		return nil, err
+	}
+	id := strconv.FormatInt(logID, 10)
+	e, err = newExporter(r.as, r.ls, prefixBucket{Bucket: r.bucket, prefix: id}, Options{
+		LogID:  logID,
+		Origin: r.opts.OriginPrefix + id,
+		Signer: r.opts.Signer,
+		SumDB:  tree.HashStrategy == trillian.HashStrategy_SUMDB_TLOG_SHA256,
+	}, r.metrics)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exporters[logID] = e
+	return e, nil
+}
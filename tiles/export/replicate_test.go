@@ -0,0 +1,154 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/tiles"
+	"github.com/google/trillian/tiles/testonly"
+)
+
+// failingBucket is a Bucket whose writes fail while fail is set.
+type failingBucket struct {
+	Bucket
+	fail bool
+}
+
+func (b *failingBucket) Write(ctx context.Context, path string, data []byte, immutable bool) error {
+	if b.fail {
+		return errors.New("write failed")
+	}
+	return b.Bucket.Write(ctx, path, data, immutable)
+}
+
+// replicated returns the checkpoint replicated to bucket at path.
+func replicated(ctx context.Context, t *testing.T, bucket Bucket, signer *tiles.NoteSigner, path string) tiles.Checkpoint {
+	t.Helper()
+	note, err := bucket.Read(ctx, path)
+	if err != nil {
+		t.Fatalf("Read(%v): %v", path, err)
+	}
+	verifier, err := tiles.NewNoteVerifier(signer.VerifierKey())
+	if err != nil {
+		t.Fatalf("NewNoteVerifier(): %v", err)
+	}
+	text, err := verifier.Open(note)
+	if err != nil {
+		t.Fatalf("Open(%v): %v", path, err)
+	}
+	cp, err := tiles.ParseCheckpoint(text)
+	if err != nil {
+		t.Fatalf("ParseCheckpoint(%v): %v", path, err)
+	}
+	return cp
+}
+
+func TestReplicator(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "replicate")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := testonly.NewLog(ctx, t, stestonly.LogTree)
+	signer := newSigner(t, "example.com/log")
+	bucket := &failingBucket{Bucket: NewDirBucket(dir)}
+	r, err := NewReplicator(l.AdminStorage, l.LogStorage, bucket, ReplicatorOptions{OriginPrefix: "example.com/log/", Signer: signer, RetryInterval: time.Minute})
+	if err != nil {
+		t.Fatalf("NewReplicator(): %v", err)
+	}
+	checkpoint := fmt.Sprintf("%d/checkpoint", l.Tree.TreeId)
+	origin := fmt.Sprintf("example.com/log/%d", l.Tree.TreeId)
+
+	for _, test := range []struct {
+		desc     string
+		leaves   int
+		fail     bool
+		wantSize uint64
+	}{
+		{desc: "first", leaves: 300, wantSize: 300},
+		{desc: "failed", leaves: 10, fail: true, wantSize: 300},
+		// The log stays pending after a failure, so it's retried without
+		// being notified again.
+		{desc: "retried", wantSize: 310},
+		{desc: "grown", leaves: 5, wantSize: 315},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if test.leaves > 0 {
+				l.Append(ctx, t, test.leaves, 100)
+				r.Notify(l.Tree.TreeId)
+			}
+			bucket.fail = test.fail
+			r.replicatePending(ctx)
+			cp := replicated(ctx, t, bucket, signer, checkpoint)
+			if cp.Origin != origin || cp.Size != test.wantSize {
+				t.Errorf("replicated checkpoint %+v, want %d leaves of %v", cp, test.wantSize, origin)
+			}
+			if _, err := bucket.Read(ctx, fmt.Sprintf("%d/tile/0/000", l.Tree.TreeId)); err != nil {
+				t.Errorf("Read(): %v", err)
+			}
+		})
+	}
+}
+
+func TestReplicatorSumDB(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir, err := ioutil.TempDir("", "replicate")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	tree.HashStrategy = trillian.HashStrategy_SUMDB_TLOG_SHA256
+	l := testonly.NewLog(ctx, t, tree)
+	signer := newSigner(t, "sum.example.com")
+	bucket := NewDirBucket(dir)
+	r, err := NewReplicator(l.AdminStorage, l.LogStorage, bucket, ReplicatorOptions{Signer: signer, RetryInterval: time.Minute})
+	if err != nil {
+		t.Fatalf("NewReplicator(): %v", err)
+	}
+	go r.Run(ctx)
+
+	l.Append(ctx, t, 3, 3)
+	r.Notify(l.Tree.TreeId)
+	path := fmt.Sprintf("%d/latest", l.Tree.TreeId)
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := bucket.Read(ctx, path); err == nil {
+			break
+		} else if time.Since(start) > 10*time.Second {
+			t.Fatalf("%v not replicated: %v", path, err)
+		}
+	}
+	cp := replicated(ctx, t, bucket, signer, path)
+	if cp.Origin != tiles.SumDBOrigin || cp.Size != 3 {
+		t.Errorf("replicated checkpoint %+v, want 3 leaves of %q", cp, tiles.SumDBOrigin)
+	}
+	if _, err := bucket.Read(ctx, fmt.Sprintf("%d/tile/8/data/000.p/3", l.Tree.TreeId)); err != nil {
+		t.Errorf("Read(): %v", err)
+	}
+}
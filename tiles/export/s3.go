@@ -0,0 +1,70 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// S3Bucket is a Bucket of objects in an Amazon S3 bucket.
+type S3Bucket struct {
+	client s3iface.S3API
+	bucket string
+	prefix string
+}
+
+// NewS3Bucket returns an S3Bucket of the objects in bucket whose keys start
+// with prefix, which is followed by a slash unless it's empty.
+func NewS3Bucket(client s3iface.S3API, bucket, prefix string) *S3Bucket {
+	return &S3Bucket{client: client, bucket: bucket, prefix: prefix}
+}
+
+// Read implements Bucket.
+func (b *S3Bucket) Read(ctx context.Context, name string) ([]byte, error) {
+	out, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(path.Join(b.prefix, name)),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	} else if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return ioutil.ReadAll(out.Body)
+}
+
+// Write implements Bucket.
+func (b *S3Bucket) Write(ctx context.Context, name string, data []byte, immutable bool) error {
+	contentType, cacheControl := httpHeaders(immutable)
+	_, err := b.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:       aws.String(b.bucket),
+		Key:          aws.String(path.Join(b.prefix, name)),
+		Body:         bytes.NewReader(data),
+		ContentType:  aws.String(contentType),
+		CacheControl: aws.String(cacheControl),
+	})
+	return err
+}
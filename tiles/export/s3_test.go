@@ -0,0 +1,98 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// fakeS3 is an S3 API holding objects in memory.
+type fakeS3 struct {
+	s3iface.S3API
+	objects map[string]*s3.PutObjectInput
+}
+
+func (f *fakeS3) GetObjectWithContext(_ aws.Context, in *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	obj, ok := f.objects[*in.Bucket+"/"+*in.Key]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "no such key", nil)
+	}
+	data, err := ioutil.ReadAll(obj.Body)
+	if err != nil {
+		return nil, err
+	}
+	obj.Body = bytes.NewReader(data)
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (f *fakeS3) PutObjectWithContext(_ aws.Context, in *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	f.objects[*in.Bucket+"/"+*in.Key] = in
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestS3Bucket(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeS3{objects: make(map[string]*s3.PutObjectInput)}
+	b := NewS3Bucket(client, "bucket", "logs/1")
+
+	if _, err := b.Read(ctx, "checkpoint"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Read(missing)=%v, want os.ErrNotExist", err)
+	}
+	if err := b.Write(ctx, "checkpoint", []byte("note"), false); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	if err := b.Write(ctx, "tile/0/000", []byte("tile"), true); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	got, err := b.Read(ctx, "checkpoint")
+	if err != nil {
+		t.Fatalf("Read(): %v", err)
+	}
+	if string(got) != "note" {
+		t.Errorf("Read()=%q, want %q", got, "note")
+	}
+
+	for _, test := range []struct {
+		key              string
+		wantContentType  string
+		wantCacheControl string
+	}{
+		{key: "bucket/logs/1/checkpoint", wantContentType: "text/plain; charset=utf-8", wantCacheControl: "no-cache"},
+		{key: "bucket/logs/1/tile/0/000", wantContentType: "application/octet-stream", wantCacheControl: "public, max-age=31536000, immutable"},
+	} {
+		obj, ok := client.objects[test.key]
+		if !ok {
+			t.Errorf("object %v wasn't written", test.key)
+			continue
+		}
+		if got := aws.StringValue(obj.ContentType); got != test.wantContentType {
+			t.Errorf("object %v has Content-Type %q, want %q", test.key, got, test.wantContentType)
+		}
+		if got := aws.StringValue(obj.CacheControl); got != test.wantCacheControl {
+			t.Errorf("object %v has Cache-Control %q, want %q", test.key, got, test.wantCacheControl)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/features"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Handler serves the logs in storage in the tlog-tiles layout. The checkpoint
+// of the log with ID <id> is served at <prefix>/<id>/checkpoint, and its tiles
+// at <prefix>/<id>/tile/..., where prefix is the path the Handler is served
+// under.
+//
+// Tiles are immutable, so they are served with long cache lifetimes, whereas
+// checkpoints change as logs grow.
+type Handler struct {
+	as           storage.AdminStorage
+	ls           storage.LogStorage
+	prefix       string
+	originPrefix string
+	signer       *NoteSigner
+	// sumdb is set if logs are served in the layout of Go checksum databases.
+	sumdb bool
+}
+
+// NewHandler returns a Handler for the logs in the given storage, served
+// under prefix. The origin of the checkpoints of a log is originPrefix
+// followed by the ID of the log, and they are signed by signer.
+func NewHandler(as storage.AdminStorage, ls storage.LogStorage, prefix, originPrefix string, signer *NoteSigner) *Handler {
+	return &Handler{as: as, ls: ls, prefix: strings.TrimRight(prefix, "/"), originPrefix: originPrefix, signer: signer}
+}
+
+// Origin returns the origin of the checkpoints of the log with the given ID.
+func (h *Handler) Origin(logID int64) string {
+	if h.sumdb {
+		return SumDBOrigin
+	}
+	return h.originPrefix + strconv.FormatInt(logID, 10)
+}
+
+var (
+	errNotFound = errors.New("not found")
+)
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, rest := cut(strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, h.prefix), "/"), "/")
+	logID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || logID <= 0 || !features.Enabled(features.TileEndpoints, logID) {
+		http.NotFound(w, r)
+		return
+	}
+
+	var (
+		body  []byte
+		cache string
+	)
+	checkpoint, parsePath := "checkpoint", ParsePath
+	if h.sumdb {
+		checkpoint, parsePath = "latest", ParseSumDBPath
+	}
+	switch {
+	case rest == checkpoint:
+		body, err = h.checkpoint(r.Context(), logID)
+		cache = "no-cache"
+	case strings.HasPrefix(rest, "tile/"):
+		var t Tile
+		if t, err = parsePath(rest); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body, err = h.tile(r.Context(), logID, t)
+		cache = "public, max-age=31536000, immutable"
+	default:
+		err = errNotFound
+	}
+	if err != nil {
+		switch code := status.Code(err); {
+		case err == errNotFound || err == storage.ErrTreeNeedsInit || code == codes.NotFound:
+			http.NotFound(w, r)
+		case code == codes.InvalidArgument:
+			http.Error(w, status.Convert(err).Message(), http.StatusBadRequest)
+		default:
+			glog.Warningf("%v: failed to serve %v: %v", logID, r.URL.Path, err)
+			http.Error(w, "failed to read log", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if rest == checkpoint {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	w.Header().Set("Cache-Control", cache)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := w.Write(body); err != nil {
+		glog.V(1).Infof("%v: failed to write %v: %v", logID, r.URL.Path, err)
+	}
+}
+
+// checkpoint returns the signed checkpoint of the latest root of a log.
+func (h *Handler) checkpoint(ctx context.Context, logID int64) ([]byte, error) {
+	var cp Checkpoint
+	err := h.snapshot(ctx, logID, func(ctx context.Context, tx storage.ReadOnlyLogTreeTX, root *types.LogRootV1) error {
+		cp = Checkpoint{Origin: h.Origin(logID), Size: root.TreeSize, Hash: root.RootHash}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h.signer.Sign(cp.String())
+}
+
+// tile returns a tile of the tree of the latest root of a log.
+func (h *Handler) tile(ctx context.Context, logID int64, t Tile) ([]byte, error) {
+	var body []byte
+	err := h.snapshot(ctx, logID, func(ctx context.Context, tx storage.ReadOnlyLogTreeTX, root *types.LogRootV1) error {
+		var err error
+		if h.sumdb {
+			body, err = ReadSumDBTile(ctx, tx, root.TreeSize, t)
+		} else {
+			body, err = ReadTile(ctx, tx, root.TreeSize, t)
+		}
+		return err
+	})
+	return body, err
+}
+
+// snapshot calls f with a read-only transaction on a log, and its latest root.
+func (h *Handler) snapshot(ctx context.Context, logID int64, f func(context.Context, storage.ReadOnlyLogTreeTX, *types.LogRootV1) error) error {
+	return Snapshot(ctx, h.as, h.ls, logID, func(ctx context.Context, tx storage.ReadOnlyLogTreeTX, root *types.LogRootV1) error {
+		if tree, _ := trees.FromContext(ctx); h.sumdb && tree.HashStrategy != trillian.HashStrategy_SUMDB_TLOG_SHA256 {
+			return status.Errorf(codes.NotFound, "log %d doesn't use %v hashing", logID, trillian.HashStrategy_SUMDB_TLOG_SHA256)
+		}
+		return f(ctx, tx, root)
+	})
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/trillian/features"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/merkle/rfc6962"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/tiles/testonly"
+)
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+	const numLeaves = 600
+	l := testonly.NewLog(ctx, t, stestonly.LogTree)
+	l.Append(ctx, t, numLeaves, 250)
+	as, ls, logTree := l.AdminStorage, l.LogStorage, l.Tree
+	skey, vkey, err := GenerateNoteKey("example.com/log")
+	if err != nil {
+		t.Fatalf("GenerateNoteKey(): %v", err)
+	}
+	signer, err := NewNoteSigner(skey)
+	if err != nil {
+		t.Fatalf("NewNoteSigner(): %v", err)
+	}
+	verifier, err := NewNoteVerifier(vkey)
+	if err != nil {
+		t.Fatalf("NewNoteVerifier(): %v", err)
+	}
+	h := NewHandler(as, ls, "/tlog/", "example.com/log/", signer)
+	prefix := fmt.Sprintf("/tlog/%d/", logTree.TreeId)
+
+	get := func(method, path string, wantStatus int) []byte {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		if got, want := w.Code, wantStatus; got != want {
+			t.Fatalf("ServeHTTP(%v %v): status=%v, want %v (body %q)", method, path, got, want, w.Body.String())
+		}
+		return w.Body.Bytes()
+	}
+
+	// The checkpoint commits to the leaves, which are read back from the
+	// entry bundles.
+	text, err := verifier.Open(get(http.MethodGet, prefix+"checkpoint", http.StatusOK))
+	if err != nil {
+		t.Fatalf("Open(checkpoint): %v", err)
+	}
+	cp, err := ParseCheckpoint(text)
+	if err != nil {
+		t.Fatalf("ParseCheckpoint(): %v", err)
+	}
+	if got, want := cp.Origin, fmt.Sprintf("example.com/log/%d", logTree.TreeId); got != want {
+		t.Errorf("checkpoint origin %q, want %q", got, want)
+	}
+	if got, want := cp.Size, uint64(numLeaves); got != want {
+		t.Fatalf("checkpoint size %d, want %d", got, want)
+	}
+
+	hasher := rfc6962.DefaultHasher
+	var leafHashes [][]byte
+	for _, tile := range []Tile{
+		{Level: EntriesLevel, Index: 0, Width: Width},
+		{Level: EntriesLevel, Index: 1, Width: Width},
+		{Level: EntriesLevel, Index: 2, Width: numLeaves - 2*Width},
+	} {
+		bundle := get(http.MethodGet, prefix+tile.Path(), http.StatusOK)
+		for len(bundle) > 0 {
+			n := int(binary.BigEndian.Uint16(bundle))
+			leafHashes = append(leafHashes, hasher.HashLeaf(bundle[2:2+n]))
+			bundle = bundle[2+n:]
+		}
+	}
+	if got, want := len(leafHashes), numLeaves; got != want {
+		t.Fatalf("read %d entries, want %d", got, want)
+	}
+	fact := compact.RangeFactory{Hash: hasher.HashChildren}
+	cr := fact.NewEmptyRange(0)
+	var subtrees [][]byte
+	for i, hash := range leafHashes {
+		if err := cr.Append(hash, nil); err != nil {
+			t.Fatalf("Append(): %v", err)
+		}
+		if (i+1)%Width == 0 {
+			sub := fact.NewEmptyRange(0)
+			for _, hash := range leafHashes[i+1-Width : i+1] {
+				if err := sub.Append(hash, nil); err != nil {
+					t.Fatalf("Append(): %v", err)
+				}
+			}
+			root, err := sub.GetRootHash(nil)
+			if err != nil {
+				t.Fatalf("GetRootHash(): %v", err)
+			}
+			subtrees = append(subtrees, root)
+		}
+	}
+	rootHash, err := cr.GetRootHash(nil)
+	if err != nil {
+		t.Fatalf("GetRootHash(): %v", err)
+	}
+	if !bytes.Equal(cp.Hash, rootHash) {
+		t.Errorf("checkpoint hash %x, want %x", cp.Hash, rootHash)
+	}
+
+	// Hash tiles hold the leaf hashes, and the roots of full subtrees.
+	for _, test := range []struct {
+		tile Tile
+		want [][]byte
+	}{
+		{tile: Tile{Level: 0, Index: 0, Width: Width}, want: leafHashes[:Width]},
+		{tile: Tile{Level: 0, Index: 1, Width: 16}, want: leafHashes[Width : Width+16]},
+		{tile: Tile{Level: 0, Index: 2, Width: numLeaves - 2*Width}, want: leafHashes[2*Width:]},
+		{tile: Tile{Level: 1, Index: 0, Width: 2}, want: subtrees},
+		{tile: Tile{Level: 1, Index: 0, Width: 1}, want: subtrees[:1]},
+	} {
+		got := get(http.MethodGet, prefix+test.tile.Path(), http.StatusOK)
+		if want := bytes.Join(test.want, nil); !bytes.Equal(got, want) {
+			t.Errorf("tile %s=%x, want %x", test.tile.Path(), got, want)
+		}
+	}
+
+	for _, test := range []struct {
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{method: http.MethodHead, path: prefix + "checkpoint", wantStatus: http.StatusOK},
+		{method: http.MethodPost, path: prefix + "checkpoint", wantStatus: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, path: prefix + "tile/0/002", wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: prefix + "tile/0/002.p/89", wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: prefix + "tile/0/003.p/1", wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: prefix + "tile/1/000", wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: prefix + "tile/2/000.p/1", wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: prefix + "tile/entries/002.p/89", wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: prefix + "tile/0/2", wantStatus: http.StatusBadRequest},
+		{method: http.MethodGet, path: prefix + "other", wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: "/tlog/12345/checkpoint", wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: "/tlog/foo/checkpoint", wantStatus: http.StatusNotFound},
+	} {
+		get(test.method, test.path, test.wantStatus)
+	}
+
+	// Logs aren't served while the tile endpoints are turned off for them.
+	defer features.Default.Replace(nil)
+	if err := features.Default.Apply([]features.Override{{Name: features.TileEndpoints, TreeID: logTree.TreeId}}, nil); err != nil {
+		t.Fatalf("Apply(): %v", err)
+	}
+	get(http.MethodGet, prefix+"checkpoint", http.StatusNotFound)
+}
@@ -0,0 +1,224 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// Notes are signed as described by https://c2sp.org/signed-note, with keys in
+// the format of golang.org/x/mod/sumdb/note, so that keys and notes can be
+// shared with its tools.
+
+// algEd25519 is the signature algorithm identifier of Ed25519 note keys.
+const algEd25519 = 1
+
+// errMalformedNote is returned for notes which can't be parsed.
+var errMalformedNote = errors.New("malformed note")
+
+// NoteSigner signs notes with an Ed25519 key.
+type NoteSigner struct {
+	name string
+	hash uint32
+	key  ed25519.PrivateKey
+}
+
+// NewNoteSigner returns a NoteSigner for an encoded private key of the form
+// "PRIVATE+KEY+<name>+<hash>+<key>", where key is the base64 encoding of the
+// algorithm byte, 1 for Ed25519, followed by the 32-byte seed of the key.
+func NewNoteSigner(skey string) (*NoteSigner, error) {
+	priv, rest := cut(skey, "+")
+	key, rest := cut(rest, "+")
+	name, rest := cut(rest, "+")
+	hash, data := cut(rest, "+")
+	if priv != "PRIVATE" || key != "KEY" {
+		return nil, errors.New("malformed note signer key")
+	}
+	h, k, err := decodeNoteKey(name, hash, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(k) != ed25519.SeedSize {
+		return nil, errors.New("malformed note signer key")
+	}
+	s := &NoteSigner{name: name, hash: h, key: ed25519.NewKeyFromSeed(k)}
+	if h != noteKeyHash(name, s.key.Public().(ed25519.PublicKey)) {
+		return nil, errors.New("note signer key hash doesn't match its key")
+	}
+	return s, nil
+}
+
+// GenerateNoteKey generates an Ed25519 note key with the given name, and
+// returns its encoded private key, for NewNoteSigner, and its encoded public
+// key, for NewNoteVerifier.
+func GenerateNoteKey(name string) (skey, vkey string, err error) {
+	if !validKeyName(name) {
+		return "", "", fmt.Errorf("invalid note key name %q", name)
+	}
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return "", "", err
+	}
+	hash := noteKeyHash(name, pub)
+	skey = fmt.Sprintf("PRIVATE+KEY+%s+%08x+%s", name, hash, base64.StdEncoding.EncodeToString(append([]byte{algEd25519}, priv.Seed()...)))
+	vkey = fmt.Sprintf("%s+%08x+%s", name, hash, base64.StdEncoding.EncodeToString(append([]byte{algEd25519}, pub...)))
+	return skey, vkey, nil
+}
+
+// Name returns the name of the key.
+func (s *NoteSigner) Name() string {
+	return s.name
+}
+
+// VerifierKey returns the encoded public key of the signer, for
+// NewNoteVerifier.
+func (s *NoteSigner) VerifierKey() string {
+	pub := s.key.Public().(ed25519.PublicKey)
+	return fmt.Sprintf("%s+%08x+%s", s.name, s.hash, base64.StdEncoding.EncodeToString(append([]byte{algEd25519}, pub...)))
+}
+
+// Sign returns the signed note with the given text, which must be non-empty
+// and end with a newline.
+func (s *NoteSigner) Sign(text string) ([]byte, error) {
+	if text == "" || !strings.HasSuffix(text, "\n") || strings.Contains(text, "\n\n") || !utf8.ValidString(text) {
+		return nil, errors.New("malformed note text")
+	}
+	var hash [4]byte
+	binary.BigEndian.PutUint32(hash[:], s.hash)
+	sig := append(hash[:], ed25519.Sign(s.key, []byte(text))...)
+	return []byte(fmt.Sprintf("%s\n— %s %s\n", text, s.name, base64.StdEncoding.EncodeToString(sig))), nil
+}
+
+// NoteVerifier verifies notes signed with an Ed25519 key.
+type NoteVerifier struct {
+	name string
+	hash uint32
+	key  ed25519.PublicKey
+}
+
+// NewNoteVerifier returns a NoteVerifier for an encoded public key of the form
+// "<name>+<hash>+<key>", where key is the base64 encoding of the algorithm
+// byte, 1 for Ed25519, followed by the 32-byte public key.
+func NewNoteVerifier(vkey string) (*NoteVerifier, error) {
+	name, rest := cut(vkey, "+")
+	hash, data := cut(rest, "+")
+	h, k, err := decodeNoteKey(name, hash, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(k) != ed25519.PublicKeySize {
+		return nil, errors.New("malformed note verifier key")
+	}
+	if h != noteKeyHash(name, k) {
+		return nil, errors.New("note verifier key hash doesn't match its key")
+	}
+	return &NoteVerifier{name: name, hash: h, key: ed25519.PublicKey(k)}, nil
+}
+
+// Name returns the name of the key.
+func (v *NoteVerifier) Name() string {
+	return v.name
+}
+
+// Open checks that the signed note msg carries a valid signature by the key of
+// v, and returns the text of the note. Signatures by other keys are ignored.
+func (v *NoteVerifier) Open(msg []byte) (string, error) {
+	s := string(msg)
+	split := strings.LastIndex(s, "\n\n")
+	if split < 0 || !utf8.ValidString(s) {
+		return "", errMalformedNote
+	}
+	text, sigs := s[:split+1], s[split+2:]
+	if !strings.HasSuffix(sigs, "\n") {
+		return "", errMalformedNote
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(sigs, "\n"), "\n") {
+		line = strings.TrimPrefix(line, "— ")
+		name, b64 := cut(line, " ")
+		sig, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil || len(sig) < 5 || name == "" || b64 == "" {
+			return "", errMalformedNote
+		}
+		if name != v.name || binary.BigEndian.Uint32(sig) != v.hash {
+			continue
+		}
+		if !ed25519.Verify(v.key, []byte(text), sig[4:]) {
+			return "", fmt.Errorf("invalid signature by %s", v.name)
+		}
+		return text, nil
+	}
+	return "", fmt.Errorf("note has no signature by %s", v.name)
+}
+
+// decodeNoteKey decodes the hash and key of an encoded note key, and checks
+// that its name is valid and its algorithm is Ed25519.
+func decodeNoteKey(name, hash, data string) (uint32, []byte, error) {
+	if !validKeyName(name) || len(hash) != 8 {
+		return 0, nil, errors.New("malformed note key")
+	}
+	h, err := strconv.ParseUint(hash, 16, 32)
+	if err != nil {
+		return 0, nil, errors.New("malformed note key")
+	}
+	k, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || len(k) == 0 {
+		return 0, nil, errors.New("malformed note key")
+	}
+	if k[0] != algEd25519 {
+		return 0, nil, fmt.Errorf("unsupported note key algorithm %d", k[0])
+	}
+	return uint32(h), k[1:], nil
+}
+
+// noteKeyHash returns the hash identifying an Ed25519 note key.
+func noteKeyHash(name string, key ed25519.PublicKey) uint32 {
+	h := sha256.New()
+	h.Write([]byte(name))
+	h.Write([]byte{'\n', algEd25519})
+	h.Write(key)
+	return binary.BigEndian.Uint32(h.Sum(nil))
+}
+
+// validKeyName returns whether name is a valid note key name: non-empty, and
+// made of printable characters other than spaces and "+".
+func validKeyName(name string) bool {
+	if name == "" || !utf8.ValidString(name) {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) || unicode.IsSpace(r) || r == '+' {
+			return false
+		}
+	}
+	return true
+}
+
+// cut slices s around the first instance of sep.
+func cut(s, sep string) (before, after string) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):]
+	}
+	return s, ""
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"strings"
+	"testing"
+)
+
+// The key and signed note of the golang.org/x/mod/sumdb/note documentation.
+const (
+	testSignerKey   = "PRIVATE+KEY+PeterNeumann+c74f20a3+AYEKFALVFGyNhPJEMzD1QIDr+Y7hfZx09iUvxdXHKDFz"
+	testVerifierKey = "PeterNeumann+c74f20a3+ARpc2QcUPDhMQegwxbzhKqiBfsVkmqq/LDE4izWy10TW"
+	testNoteText    = "If you think cryptography is the answer to your problem,\nthen you don't know what your problem is.\n"
+	testNote        = testNoteText + "\n— PeterNeumann x08go/ZJkuBS9UG/SffcvIAQxVBtiFupLLr8pAcElZInNIuGUgYN1FFYC2pZSNXgKvqfqdngotpRZb6KE6RyyBwJnAM=\n"
+)
+
+func TestNoteSign(t *testing.T) {
+	s, err := NewNoteSigner(testSignerKey)
+	if err != nil {
+		t.Fatalf("NewNoteSigner(): %v", err)
+	}
+	msg, err := s.Sign(testNoteText)
+	if err != nil {
+		t.Fatalf("Sign(): %v", err)
+	}
+	if got, want := string(msg), testNote; got != want {
+		t.Errorf("Sign()=%q, want %q", got, want)
+	}
+	if got, want := s.VerifierKey(), testVerifierKey; got != want {
+		t.Errorf("VerifierKey()=%q, want %q", got, want)
+	}
+
+	for _, text := range []string{"", "no newline", "blank\n\nline\n"} {
+		if _, err := s.Sign(text); err == nil {
+			t.Errorf("Sign(%q) succeeded, want error", text)
+		}
+	}
+}
+
+func TestNoteOpen(t *testing.T) {
+	v, err := NewNoteVerifier(testVerifierKey)
+	if err != nil {
+		t.Fatalf("NewNoteVerifier(): %v", err)
+	}
+	otherSKey, otherVKey, err := GenerateNoteKey("Other")
+	if err != nil {
+		t.Fatalf("GenerateNoteKey(): %v", err)
+	}
+	other, err := NewNoteSigner(otherSKey)
+	if err != nil {
+		t.Fatalf("NewNoteSigner(): %v", err)
+	}
+	if _, err := NewNoteVerifier(otherVKey); err != nil {
+		t.Fatalf("NewNoteVerifier(): %v", err)
+	}
+	otherNote, err := other.Sign(testNoteText)
+	if err != nil {
+		t.Fatalf("Sign(): %v", err)
+	}
+	otherSig := string(otherNote[len(testNoteText)+1:])
+
+	for _, test := range []struct {
+		desc    string
+		msg     string
+		wantErr bool
+	}{
+		{desc: "signed", msg: testNote},
+		{desc: "cosigned", msg: testNote + otherSig},
+		{desc: "cosigned-first", msg: testNoteText + "\n" + otherSig + strings.TrimPrefix(testNote, testNoteText+"\n")},
+		{desc: "other-signer", msg: string(otherNote), wantErr: true},
+		{desc: "tampered", msg: strings.Replace(testNote, "cryptography", "Cryptography", 1), wantErr: true},
+		{desc: "unsigned", msg: testNoteText, wantErr: true},
+		{desc: "bad-signature-line", msg: testNoteText + "\n— PeterNeumann\n", wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			text, err := v.Open([]byte(test.msg))
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Open()=%v, want error %v", err, test.wantErr)
+			}
+			if err == nil && text != testNoteText {
+				t.Errorf("Open()=%q, want %q", text, testNoteText)
+			}
+		})
+	}
+}
+
+func TestNoteKeys(t *testing.T) {
+	for _, key := range []string{
+		"",
+		"PRIVATE+KEY+PeterNeumann+c74f20a4+AYEKFALVFGyNhPJEMzD1QIDr+Y7hfZx09iUvxdXHKDFz",
+		"PRIVATE+KEY+PeterNeumann+c74f20a3+AoEKFALVFGyNhPJEMzD1QIDr+Y7hfZx09iUvxdXHKDFz",
+		"PRIVATE+KEY+Peter Neumann+c74f20a3+AYEKFALVFGyNhPJEMzD1QIDr+Y7hfZx09iUvxdXHKDFz",
+		testVerifierKey,
+	} {
+		if _, err := NewNoteSigner(key); err == nil {
+			t.Errorf("NewNoteSigner(%q) succeeded, want error", key)
+		}
+	}
+	for _, key := range []string{
+		"",
+		"PeterNeumann+c74f20a4+ARpc2QcUPDhMQegwxbzhKqiBfsVkmqq/LDE4izWy10TW",
+		"PeterNeumann+c74f20a3+ARpc2QcUPDhMQegwxbzhKqiBfsVkmqq",
+		testSignerKey,
+	} {
+		if _, err := NewNoteVerifier(key); err == nil {
+			t.Errorf("NewNoteVerifier(%q) succeeded, want error", key)
+		}
+	}
+}
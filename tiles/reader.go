@@ -0,0 +1,161 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxTreeDepth is the depth of log trees, used for tree.NodeID creation.
+const maxTreeDepth = 64
+
+var optsRead = trees.NewGetOpts(trees.Query, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
+
+// Snapshot calls f with a read-only transaction on the log with the given ID,
+// and its latest root, and commits the transaction if f succeeds. The context
+// passed to f carries the tree of the log.
+func Snapshot(ctx context.Context, as storage.AdminStorage, ls storage.LogStorage, logID int64, f func(context.Context, storage.ReadOnlyLogTreeTX, *types.LogRootV1) error) error {
+	tree, err := trees.GetTree(ctx, as, logID, optsRead)
+	if err != nil {
+		return err
+	}
+	ctx = trees.NewContext(ctx, tree)
+	tx, err := ls.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.GetLogRoot()); err != nil {
+		return fmt.Errorf("failed to unmarshal log root: %v", err)
+	}
+	if err := f(ctx, tx, &root); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ReadTile returns the contents of a tile of a log, read with tx from the tree
+// of the given size, which must be the size of the latest root read by tx.
+// Hash tiles hold the concatenated hashes of their nodes, and entry bundles
+// hold each of their leaf values prefixed with its big-endian 16-bit length.
+//
+// Tiles beyond the edge of the tree are reported as NotFound.
+func ReadTile(ctx context.Context, tx storage.ReadOnlyLogTreeTX, size uint64, t Tile) ([]byte, error) {
+	return readTile(ctx, tx, size, t, appendBundleEntry)
+}
+
+// appendEntry appends the encoding of the leaf value with the given index to
+// an entry tile.
+type appendEntry func(buf *bytes.Buffer, index int64, value []byte) error
+
+// readTile returns the contents of a tile, with entries encoded by appendEntry.
+func readTile(ctx context.Context, tx storage.ReadOnlyLogTreeTX, size uint64, t Tile, appendEntry appendEntry) ([]byte, error) {
+	if t.Width < 1 || t.Width > Width || t.Level < EntriesLevel || t.Level > maxLevel {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid tile %+v", t)
+	}
+	if full, partial := TreeTiles(t.Level, size); t.Index > full || t.Index == full && t.Width > partial {
+		return nil, status.Errorf(codes.NotFound, "tile %s is beyond the tree of size %d", t.Path(), size)
+	}
+	start := t.Index * Width
+	if t.Level == EntriesLevel {
+		return readEntries(ctx, tx, start, t.Width, appendEntry)
+	}
+	return readHashes(ctx, tx, t.Level*Height, start, t.Width)
+}
+
+// readHashes returns the concatenated hashes of count nodes at the given level
+// of the tree, starting at index start.
+func readHashes(ctx context.Context, tx storage.ReadOnlyLogTreeTX, level int, start uint64, count int) ([]byte, error) {
+	ids := make([]tree.NodeID, 0, count)
+	for i := 0; i < count; i++ {
+		id, err := tree.NewNodeIDForTreeCoords(int64(level), int64(start)+int64(i), maxTreeDepth)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rev, err := tx.ReadRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := tx.GetMerkleNodes(ctx, rev, ids)
+	if err != nil {
+		return nil, err
+	}
+	if got, want := len(nodes), len(ids); got != want {
+		return nil, status.Errorf(codes.Internal, "read %d nodes at level %d from %d, want %d", got, level, start, want)
+	}
+	var buf bytes.Buffer
+	for i, node := range nodes {
+		if !node.NodeID.Equivalent(ids[i]) || len(node.Hash) == 0 {
+			return nil, status.Errorf(codes.Internal, "node %d at level %d is missing from storage", start+uint64(i), level)
+		}
+		buf.Write(node.Hash)
+	}
+	return buf.Bytes(), nil
+}
+
+// readEntries returns the entry tile of count leaves, starting at index start.
+func readEntries(ctx context.Context, tx storage.ReadOnlyLogTreeTX, start uint64, count int, appendEntry appendEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	for next, end := int64(start), int64(start)+int64(count); next < end; {
+		leaves, err := tx.GetLeavesByRange(ctx, next, end-next)
+		if err != nil {
+			return nil, err
+		}
+		if len(leaves) == 0 {
+			return nil, status.Errorf(codes.Internal, "leaf %d is missing from storage", next)
+		}
+		for _, leaf := range leaves {
+			if leaf.LeafIndex != next {
+				return nil, status.Errorf(codes.Internal, "read leaf %d, want leaf %d", leaf.LeafIndex, next)
+			}
+			if err := appendEntry(&buf, next, leaf.LeafValue); err != nil {
+				return nil, err
+			}
+			next++
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// appendBundleEntry appends a leaf value to an entry bundle, prefixed with its
+// big-endian 16-bit length.
+func appendBundleEntry(buf *bytes.Buffer, index int64, value []byte) error {
+	if len(value) > 0xffff {
+		return status.Errorf(codes.FailedPrecondition, "leaf %d has %d bytes, too many for an entry bundle", index, len(value))
+	}
+	var n [2]byte
+	binary.BigEndian.PutUint16(n[:], uint16(len(value)))
+	buf.Write(n[:])
+	buf.Write(value)
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SumDBOrigin is the origin of the tree notes of Go checksum databases, which
+// golang.org/x/mod/sumdb/tlog.ParseTree requires.
+const SumDBOrigin = "go.sum database tree"
+
+// sumDBPrefix is the prefix of the paths of tiles of Go checksum databases,
+// which include the tile height.
+var sumDBPrefix = "tile/" + strconv.Itoa(Height) + "/"
+
+// NewSumDBHandler returns a Handler which serves the logs in the given storage
+// in the layout of Go checksum databases (golang.org/x/mod/sumdb), rather than
+// that of tlog-tiles. The signed tree note of the log with ID <id> is served at
+// <prefix>/<id>/latest, and its tiles at <prefix>/<id>/tile/8/..., so the log
+// can be read by the tile readers of golang.org/x/mod/sumdb/tlog.
+//
+// Only logs with the SUMDB_TLOG_SHA256 hash strategy are served, as the root
+// hash of their empty tree matches that of tlog. Their leaf values are the
+// record texts, and data tiles of leaves which aren't valid record texts can't
+// be served. The lookup endpoint isn't served, as logs aren't indexed by
+// module version.
+func NewSumDBHandler(as storage.AdminStorage, ls storage.LogStorage, prefix string, signer *NoteSigner) *Handler {
+	h := NewHandler(as, ls, prefix, "", signer)
+	h.sumdb = true
+	return h
+}
+
+// SumDBPath returns the path of the tile in the layout of Go checksum
+// databases, e.g. "tile/8/1/x001/234.p/16", or "tile/8/data/567".
+func (t Tile) SumDBPath() string {
+	p := t.Path()
+	if t.Level == EntriesLevel {
+		return sumDBPrefix + "data" + strings.TrimPrefix(p, "tile/entries")
+	}
+	return sumDBPrefix + strings.TrimPrefix(p, "tile/")
+}
+
+// ParseSumDBPath parses a path returned by Tile.SumDBPath.
+func ParseSumDBPath(path string) (Tile, error) {
+	rest := strings.TrimPrefix(path, sumDBPrefix)
+	if rest == path || strings.HasPrefix(rest, "entries/") {
+		return Tile{}, fmt.Errorf("malformed tile path %q", path)
+	}
+	if strings.HasPrefix(rest, "data/") {
+		rest = "entries" + strings.TrimPrefix(rest, "data")
+	}
+	t, err := ParsePath("tile/" + rest)
+	if err != nil {
+		return Tile{}, fmt.Errorf("malformed tile path %q", path)
+	}
+	return t, nil
+}
+
+// ReadSumDBTile returns the contents of a tile of a log as ReadTile does,
+// except that data tiles hold the records of their leaves, each formatted as
+// by golang.org/x/mod/sumdb/tlog.FormatRecord.
+func ReadSumDBTile(ctx context.Context, tx storage.ReadOnlyLogTreeTX, size uint64, t Tile) ([]byte, error) {
+	return readTile(ctx, tx, size, t, appendRecord)
+}
+
+// appendRecord appends a leaf value to a data tile as a record: its index,
+// then its text, then a blank line.
+func appendRecord(buf *bytes.Buffer, index int64, text []byte) error {
+	if !validRecordText(text) {
+		return status.Errorf(codes.FailedPrecondition, "leaf %d isn't a valid record text", index)
+	}
+	buf.WriteString(strconv.FormatInt(index, 10))
+	buf.WriteByte('\n')
+	buf.Write(text)
+	buf.WriteByte('\n')
+	return nil
+}
+
+// validRecordText returns whether text is valid UTF-8 without control
+// characters other than newlines, ends in a newline and has no blank lines, as
+// tlog requires of records.
+func validRecordText(text []byte) bool {
+	var last rune
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRune(text[i:])
+		if r < 0x20 && r != '\n' || r == utf8.RuneError && size == 1 || last == '\n' && r == '\n' {
+			return false
+		}
+		i += size
+		last = r
+	}
+	return last == '\n'
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/sumdb"
+	stestonly "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/tiles/testonly"
+)
+
+func TestSumDBPath(t *testing.T) {
+	for _, test := range []struct {
+		tile Tile
+		path string
+	}{
+		{tile: Tile{Level: 0, Index: 0, Width: Width}, path: "tile/8/0/000"},
+		{tile: Tile{Level: 2, Index: 1000, Width: 16}, path: "tile/8/2/x001/000.p/16"},
+		{tile: Tile{Level: EntriesLevel, Index: 567, Width: Width}, path: "tile/8/data/567"},
+		{tile: Tile{Level: EntriesLevel, Index: 1001, Width: 1}, path: "tile/8/data/x001/001.p/1"},
+	} {
+		if got, want := test.tile.SumDBPath(), test.path; got != want {
+			t.Errorf("%+v.SumDBPath()=%q, want %q", test.tile, got, want)
+		}
+		got, err := ParseSumDBPath(test.path)
+		if err != nil {
+			t.Errorf("ParseSumDBPath(%q): %v", test.path, err)
+		} else if got != test.tile {
+			t.Errorf("ParseSumDBPath(%q)=%+v, want %+v", test.path, got, test.tile)
+		}
+	}
+
+	for _, path := range []string{
+		"",
+		"tile/0/000",
+		"tile/entries/000",
+		"tile/8/entries/000",
+		"tile/4/0/000",
+		"tile/8/data/0",
+		"tile/8/0/000.p/256",
+	} {
+		if tile, err := ParseSumDBPath(path); err == nil {
+			t.Errorf("ParseSumDBPath(%q)=%+v, want error", path, tile)
+		}
+	}
+}
+
+func TestValidRecordText(t *testing.T) {
+	for _, test := range []struct {
+		text string
+		want bool
+	}{
+		{text: "golang.org/x/text v0.3.0 h1:abc=\n", want: true},
+		{text: "a\nb\n", want: true},
+		{text: "\na\n", want: true},
+		{text: ""},
+		{text: "a"},
+		{text: "a\n\nb\n"},
+		{text: "a\tb\n"},
+		{text: "a\xffb\n"},
+	} {
+		if got := validRecordText([]byte(test.text)); got != test.want {
+			t.Errorf("validRecordText(%q)=%v, want %v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestSumDBHandler(t *testing.T) {
+	ctx := context.Background()
+	const numLeaves = 300
+	tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+	tree.HashStrategy = trillian.HashStrategy_SUMDB_TLOG_SHA256
+	l := testonly.NewLog(ctx, t, tree)
+	l.Append(ctx, t, numLeaves, 100)
+	as, ls, logTree := l.AdminStorage, l.LogStorage, l.Tree
+
+	skey, vkey, err := GenerateNoteKey("sum.example.com")
+	if err != nil {
+		t.Fatalf("GenerateNoteKey(): %v", err)
+	}
+	signer, err := NewNoteSigner(skey)
+	if err != nil {
+		t.Fatalf("NewNoteSigner(): %v", err)
+	}
+	verifier, err := NewNoteVerifier(vkey)
+	if err != nil {
+		t.Fatalf("NewNoteVerifier(): %v", err)
+	}
+	prefix := fmt.Sprintf("/sumdb/%d/", logTree.TreeId)
+
+	h := NewSumDBHandler(as, ls, "/sumdb", signer)
+	get := func(path string, wantStatus int) []byte {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if got, want := w.Code, wantStatus; got != want {
+			t.Fatalf("ServeHTTP(%v): status=%v, want %v (body %q)", path, got, want, w.Body.String())
+		}
+		return w.Body.Bytes()
+	}
+
+	text, err := verifier.Open(get(prefix+"latest", http.StatusOK))
+	if err != nil {
+		t.Fatalf("Open(latest): %v", err)
+	}
+	cp, err := ParseCheckpoint(text)
+	if err != nil {
+		t.Fatalf("ParseCheckpoint(): %v", err)
+	}
+	if cp.Origin != SumDBOrigin || cp.Size != numLeaves {
+		t.Errorf("tree note %q, want %d leaves with origin %q", text, numLeaves, SumDBOrigin)
+	}
+
+	// Data tiles hold formatted records, whose hashes are in the hash tiles.
+	var records []string
+	for _, tile := range []Tile{
+		{Level: EntriesLevel, Index: 0, Width: Width},
+		{Level: EntriesLevel, Index: 1, Width: numLeaves - Width},
+	} {
+		data := strings.TrimSuffix(string(get(prefix+tile.SumDBPath(), http.StatusOK)), "\n\n")
+		records = append(records, strings.Split(data, "\n\n")...)
+	}
+	var hashes [][]byte
+	for i, record := range records {
+		id, text := cut(record, "\n")
+		if got, want := id, strconv.Itoa(i); got != want {
+			t.Fatalf("record %d has ID %q", i, got)
+		}
+		text += "\n"
+		if got, want := text, fmt.Sprintf("leaf %d\n", i); got != want {
+			t.Errorf("record %d has text %q, want %q", i, got, want)
+		}
+		hashes = append(hashes, sumdb.DefaultHasher.HashLeaf([]byte(text)))
+	}
+	if got, want := len(records), numLeaves; got != want {
+		t.Fatalf("read %d records, want %d", got, want)
+	}
+	if got, want := get(prefix+"tile/8/0/001.p/44", http.StatusOK), bytes.Join(hashes[Width:], nil); !bytes.Equal(got, want) {
+		t.Errorf("tile/8/0/001.p/44=%x, want %x", got, want)
+	}
+
+	get(prefix+"checkpoint", http.StatusNotFound)
+	get(prefix+"tile/8/entries/000", http.StatusBadRequest)
+	get(prefix+"tile/0/000", http.StatusBadRequest)
+	get(prefix+"tile/8/0/002", http.StatusNotFound)
+
+	// The root of the empty tree is the zero hash, as in tlog.
+	empty := testonly.NewLog(ctx, t, tree)
+	h = NewSumDBHandler(empty.AdminStorage, empty.LogStorage, "/sumdb", signer)
+	text, err = verifier.Open(get(fmt.Sprintf("/sumdb/%d/latest", empty.Tree.TreeId), http.StatusOK))
+	if err != nil {
+		t.Fatalf("Open(latest): %v", err)
+	}
+	if want := SumDBOrigin + "\n0\nAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n"; text != want {
+		t.Errorf("empty tree note %q, want %q", text, want)
+	}
+
+	// Logs hashed otherwise aren't served.
+	other := testonly.NewLog(ctx, t, stestonly.LogTree)
+	other.Append(ctx, t, 1, 1)
+	h = NewSumDBHandler(other.AdminStorage, other.LogStorage, "/sumdb", signer)
+	get(fmt.Sprintf("/sumdb/%d/latest", other.Tree.TreeId), http.StatusNotFound)
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testonly contains code and data that should only be used by tests
+// of the tiles packages.
+package testonly
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+
+	// Register the hashers of logs.
+	_ "github.com/google/trillian/merkle/rfc6962"
+	_ "github.com/google/trillian/merkle/sumdb"
+)
+
+// Log is a log in memory storage, whose leaves are integrated on demand.
+type Log struct {
+	AdminStorage storage.AdminStorage
+	LogStorage   storage.LogStorage
+	Tree         *trillian.Tree
+	// Leaves holds the values of the leaves of the log, in order.
+	Leaves [][]byte
+
+	hasher    hashers.LogHasher
+	clock     *clock.FakeTimeSource
+	sequencer *log.Sequencer
+}
+
+// NewLog returns an empty Log created from the given tree.
+func NewLog(ctx context.Context, t *testing.T, tree *trillian.Tree) *Log {
+	t.Helper()
+	keys.RegisterHandler(&keyspb.PrivateKey{}, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		return der.FromProto(pb.(*keyspb.PrivateKey))
+	})
+	defer keys.UnregisterHandler(&keyspb.PrivateKey{})
+
+	ts := memory.NewTreeStorage()
+	as := memory.NewAdminStorage(ts)
+	ls := memory.NewLogStorage(ts, nil)
+	logTree, err := storage.CreateTree(ctx, as, tree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	hasher, err := registry.NewLogHasher(logTree.HashStrategy)
+	if err != nil {
+		t.Fatalf("NewLogHasher(): %v", err)
+	}
+
+	signer, err := trees.Signer(ctx, logTree)
+	if err != nil {
+		t.Fatalf("Signer(): %v", err)
+	}
+	timeSource := clock.NewFake(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	root, err := signer.SignLogRoot(&types.LogRootV1{RootHash: hasher.EmptyRoot(), TimestampNanos: uint64(timeSource.Now().UnixNano())})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return tx.StoreSignedLogRoot(ctx, root)
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+	return &Log{
+		AdminStorage: as,
+		LogStorage:   ls,
+		Tree:         logTree,
+		hasher:       hasher,
+		clock:        timeSource,
+		sequencer:    log.NewSequencer(hasher, timeSource, ls, signer, nil /* mf */, quota.Noop()),
+	}
+}
+
+// Append appends n leaves to the log, with values "leaf <index>\n", and
+// integrates them in batches of the given size.
+func (l *Log) Append(ctx context.Context, t *testing.T, n, batch int) {
+	t.Helper()
+	leaves := make([]*trillian.LogLeaf, n)
+	for i := range leaves {
+		value := []byte(fmt.Sprintf("leaf %d\n", len(l.Leaves)))
+		l.Leaves = append(l.Leaves, value)
+		leaves[i] = &trillian.LogLeaf{LeafValue: value, LeafIdentityHash: l.hasher.HashLeaf(value), MerkleLeafHash: l.hasher.HashLeaf(value)}
+	}
+	if _, err := l.LogStorage.QueueLeaves(ctx, l.Tree, leaves, l.clock.Now()); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	for left := n; left > 0; left -= batch {
+		l.clock.Advance(time.Second)
+		if _, err := l.sequencer.IntegrateBatch(ctx, l.Tree, batch, 0, 0); err != nil {
+			t.Fatalf("IntegrateBatch(): %v", err)
+		}
+	}
+}
+
+// Freeze sets the state of the tree of the log to FROZEN.
+func (l *Log) Freeze(ctx context.Context, t *testing.T) {
+	t.Helper()
+	keys.RegisterHandler(&keyspb.PrivateKey{}, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		return der.FromProto(pb.(*keyspb.PrivateKey))
+	})
+	defer keys.UnregisterHandler(&keyspb.PrivateKey{})
+	tree, err := storage.UpdateTree(ctx, l.AdminStorage, l.Tree.TreeId, func(tree *trillian.Tree) {
+		tree.TreeState = trillian.TreeState_FROZEN
+	})
+	if err != nil {
+		t.Fatalf("UpdateTree(): %v", err)
+	}
+	l.Tree = tree
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tiles serves Trillian logs in the tiled layout of the C2SP
+// tlog-tiles specification (https://c2sp.org/tlog-tiles), where the Merkle
+// tree hashes and entries of a log are published as immutable tiles of 256
+// hashes or entries, along with a checkpoint signed as a note
+// (https://c2sp.org/signed-note), so that logs can be read and verified from
+// plain HTTP servers and caches.
+//
+// Tiles are built from the subtrees of the tree in storage. Tiles at the edge
+// of the tree, which have fewer than 256 hashes, are served as partial tiles,
+// and synthesized on request from the nodes stored for the latest root.
+package tiles
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Height is the height of a tile, in levels of the Merkle tree.
+	Height = 8
+	// Width is the number of hashes or entries in a full tile.
+	Width = 1 << Height
+	// EntriesLevel is the level of entry bundles, the tiles of log entries.
+	EntriesLevel = -1
+	// maxLevel is the highest level of a tile whose hashes fit in a tree of
+	// 64-bit size.
+	maxLevel = 63 / Height
+)
+
+// Tile identifies a tile. The tile at Level L with Index N holds the hashes of
+// the nodes at height L*Height of the Merkle tree, with indices N*Width to
+// N*Width+Width-1, or the entries with those indices if L is EntriesLevel.
+// Partial tiles hold the first Width hashes or entries of a tile.
+type Tile struct {
+	Level int
+	Index uint64
+	// Width is the number of hashes or entries in the tile, from 1 to Width.
+	Width int
+}
+
+// Path returns the path of the tile, relative to the root of the log, e.g.
+// "tile/1/x001/234.p/16", or "tile/entries/567".
+func (t Tile) Path() string {
+	level := strconv.Itoa(t.Level)
+	if t.Level == EntriesLevel {
+		level = "entries"
+	}
+	p := "tile/" + level + "/" + encodeIndex(t.Index)
+	if t.Width < Width {
+		p += ".p/" + strconv.Itoa(t.Width)
+	}
+	return p
+}
+
+// encodeIndex encodes a tile index as groups of three decimal digits, all but
+// the last prefixed with "x", e.g. 1234067 as "x001/x234/067".
+func encodeIndex(n uint64) string {
+	s := fmt.Sprintf("%03d", n%1000)
+	for n /= 1000; n > 0; n /= 1000 {
+		s = fmt.Sprintf("x%03d/%s", n%1000, s)
+	}
+	return s
+}
+
+// ParsePath parses a path returned by Tile.Path.
+func ParsePath(path string) (Tile, error) {
+	elems := strings.Split(path, "/")
+	if len(elems) < 3 || elems[0] != "tile" {
+		return Tile{}, fmt.Errorf("malformed tile path %q", path)
+	}
+	t := Tile{Width: Width}
+	if n := len(elems); n > 3 && strings.HasSuffix(elems[n-2], ".p") {
+		w, err := strconv.Atoi(elems[n-1])
+		if err != nil || w < 1 || w >= Width || elems[n-1] != strconv.Itoa(w) {
+			return Tile{}, fmt.Errorf("malformed width in tile path %q", path)
+		}
+		t.Width = w
+		elems[n-2] = strings.TrimSuffix(elems[n-2], ".p")
+		elems = elems[:n-1]
+	}
+
+	if elems[1] == "entries" {
+		t.Level = EntriesLevel
+	} else {
+		l, err := strconv.Atoi(elems[1])
+		if err != nil || l < 0 || l > maxLevel || elems[1] != strconv.Itoa(l) {
+			return Tile{}, fmt.Errorf("malformed level in tile path %q", path)
+		}
+		t.Level = l
+	}
+
+	groups := elems[2:]
+	for i, g := range groups {
+		if last := i == len(groups)-1; !last {
+			if !strings.HasPrefix(g, "x") {
+				return Tile{}, fmt.Errorf("malformed index in tile path %q", path)
+			}
+			g = g[1:]
+		}
+		d, err := strconv.ParseUint(g, 10, 64)
+		if err != nil || len(g) != 3 {
+			return Tile{}, fmt.Errorf("malformed index in tile path %q", path)
+		}
+		if t.Index > (1<<64-1-d)/1000 {
+			return Tile{}, fmt.Errorf("index overflows in tile path %q", path)
+		}
+		t.Index = t.Index*1000 + d
+	}
+	if len(groups) > 1 && strings.TrimPrefix(groups[0], "x") == "000" {
+		return Tile{}, fmt.Errorf("non-canonical index in tile path %q", path)
+	}
+	return t, nil
+}
+
+// TreeTiles returns the number of full tiles at the given level of a tree of
+// the given size, and the width of the partial tile following them, which is
+// zero if there is none.
+func TreeTiles(level int, size uint64) (full uint64, partial int) {
+	if level > 0 {
+		size >>= uint(level * Height)
+	}
+	return size / Width, int(size % Width)
+}
+
+// NewTiles returns the tiles of a tree of size newSize which aren't tiles of
+// the tree of size oldSize: the full tiles completed as the tree grew from
+// oldSize, and the partial tiles at the edge of the new tree, at every level,
+// starting with entry bundles. It returns nil if newSize is smaller than
+// oldSize.
+func NewTiles(oldSize, newSize uint64) []Tile {
+	if newSize < oldSize {
+		return nil
+	}
+	var ts []Tile
+	for level := EntriesLevel; level <= maxLevel; level++ {
+		oldFull, oldPartial := TreeTiles(level, oldSize)
+		full, partial := TreeTiles(level, newSize)
+		if full == 0 && partial == 0 {
+			break
+		}
+		for n := oldFull; n < full; n++ {
+			ts = append(ts, Tile{Level: level, Index: n, Width: Width})
+		}
+		if partial > 0 && (full != oldFull || partial != oldPartial) {
+			ts = append(ts, Tile{Level: level, Index: full, Width: partial})
+		}
+	}
+	return ts
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTilePath(t *testing.T) {
+	for _, test := range []struct {
+		tile Tile
+		path string
+	}{
+		{tile: Tile{Level: 0, Index: 0, Width: Width}, path: "tile/0/000"},
+		{tile: Tile{Level: 1, Index: 999, Width: Width}, path: "tile/1/999"},
+		{tile: Tile{Level: 2, Index: 1000, Width: 16}, path: "tile/2/x001/000.p/16"},
+		{tile: Tile{Level: 0, Index: 1234067, Width: Width}, path: "tile/0/x001/x234/067"},
+		{tile: Tile{Level: EntriesLevel, Index: 567, Width: Width}, path: "tile/entries/567"},
+		{tile: Tile{Level: EntriesLevel, Index: 1001, Width: 1}, path: "tile/entries/x001/001.p/1"},
+	} {
+		if got, want := test.tile.Path(), test.path; got != want {
+			t.Errorf("%+v.Path()=%q, want %q", test.tile, got, want)
+		}
+		got, err := ParsePath(test.path)
+		if err != nil {
+			t.Errorf("ParsePath(%q): %v", test.path, err)
+		} else if got != test.tile {
+			t.Errorf("ParsePath(%q)=%+v, want %+v", test.path, got, test.tile)
+		}
+	}
+
+	for _, path := range []string{
+		"",
+		"tile",
+		"tile/0",
+		"tiles/0/000",
+		"tile/0/0",
+		"tile/0/0000",
+		"tile/0/x000/001",
+		"tile/0/001/002",
+		"tile/0/x001",
+		"tile/-1/000",
+		"tile/01/000",
+		"tile/8/000",
+		"tile/data/000",
+		"tile/0/000.p/0",
+		"tile/0/000.p/256",
+		"tile/0/000.p/016",
+		"tile/0/000.p",
+		"tile/0/x018/x446/x744/x073/x709/x551/616",
+	} {
+		if tile, err := ParsePath(path); err == nil {
+			t.Errorf("ParsePath(%q)=%+v, want error", path, tile)
+		}
+	}
+}
+
+func TestTreeTiles(t *testing.T) {
+	for _, test := range []struct {
+		level       int
+		size        uint64
+		wantFull    uint64
+		wantPartial int
+	}{
+		{level: 0, size: 0},
+		{level: 0, size: 255, wantPartial: 255},
+		{level: 0, size: 256, wantFull: 1},
+		{level: EntriesLevel, size: 1000, wantFull: 3, wantPartial: 232},
+		{level: 1, size: 1000, wantPartial: 3},
+		{level: 1, size: 70000, wantFull: 1, wantPartial: 17},
+		{level: 2, size: 70000, wantPartial: 1},
+	} {
+		full, partial := TreeTiles(test.level, test.size)
+		if full != test.wantFull || partial != test.wantPartial {
+			t.Errorf("TreeTiles(%d, %d)=%d, %d, want %d, %d", test.level, test.size, full, partial, test.wantFull, test.wantPartial)
+		}
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	cp := Checkpoint{Origin: "example.com/log", Size: 123, Hash: bytes.Repeat([]byte{0x01}, 32)}
+	text := "example.com/log\n123\nAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=\n"
+	if got := cp.String(); got != text {
+		t.Errorf("String()=%q, want %q", got, text)
+	}
+	for _, text := range []string{text, text + "extension\n"} {
+		got, err := ParseCheckpoint(text)
+		if err != nil {
+			t.Fatalf("ParseCheckpoint(%q): %v", text, err)
+		}
+		if got.Origin != cp.Origin || got.Size != cp.Size || !bytes.Equal(got.Hash, cp.Hash) {
+			t.Errorf("ParseCheckpoint(%q)=%+v, want %+v", text, got, cp)
+		}
+	}
+	for _, text := range []string{"", "example.com/log\n123\n", "example.com/log\n0123\nAQ==\n", "\n123\nAQ==\n", "example.com/log\n123\n!!\n"} {
+		if _, err := ParseCheckpoint(text); err == nil {
+			t.Errorf("ParseCheckpoint(%q) succeeded, want error", text)
+		}
+	}
+}
+
+func TestNewTiles(t *testing.T) {
+	for _, test := range []struct {
+		oldSize, newSize uint64
+		want             []Tile
+	}{
+		{oldSize: 0, newSize: 0},
+		{oldSize: 10, newSize: 5},
+		{oldSize: 5, newSize: 5},
+		{
+			oldSize: 0,
+			newSize: 3,
+			want:    []Tile{{Level: EntriesLevel, Index: 0, Width: 3}, {Level: 0, Index: 0, Width: 3}},
+		},
+		{
+			oldSize: 200,
+			newSize: 600,
+			want: []Tile{
+				{Level: EntriesLevel, Index: 0, Width: Width},
+				{Level: EntriesLevel, Index: 1, Width: Width},
+				{Level: EntriesLevel, Index: 2, Width: 88},
+				{Level: 0, Index: 0, Width: Width},
+				{Level: 0, Index: 1, Width: Width},
+				{Level: 0, Index: 2, Width: 88},
+				{Level: 1, Index: 0, Width: 2},
+			},
+		},
+		{
+			oldSize: 600,
+			newSize: 610,
+			want:    []Tile{{Level: EntriesLevel, Index: 2, Width: 98}, {Level: 0, Index: 2, Width: 98}},
+		},
+		{
+			oldSize: 255,
+			newSize: 256,
+			want:    []Tile{{Level: EntriesLevel, Index: 0, Width: Width}, {Level: 0, Index: 0, Width: Width}, {Level: 1, Index: 0, Width: 1}},
+		},
+	} {
+		if diff := cmp.Diff(NewTiles(test.oldSize, test.newSize), test.want); diff != "" {
+			t.Errorf("NewTiles(%d, %d) diff (-got +want):\n%s", test.oldSize, test.newSize, diff)
+		}
+	}
+}
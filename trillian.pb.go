@@ -22,7 +22,7 @@ package trillian
 
 import (
 	proto "github.com/golang/protobuf/proto"
-	any "github.com/golang/protobuf/ptypes/any"
+	any1 "github.com/golang/protobuf/ptypes/any"
 	duration "github.com/golang/protobuf/ptypes/duration"
 	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	keyspb "github.com/google/trillian/crypto/keyspb"
@@ -162,6 +162,14 @@ const (
 	HashStrategy_CONIKS_SHA512_256 HashStrategy = 4
 	// The CONIKS sparse tree hasher with SHA256 as the hash algorithm.
 	HashStrategy_CONIKS_SHA256 HashStrategy = 5
+	// Go checksum database strategy, as implemented by
+	// golang.org/x/mod/sumdb/tlog: leaf and node hashes are as RFC6962_SHA256,
+	// but the hash of the empty tree is all zeros.
+	HashStrategy_SUMDB_TLOG_SHA256 HashStrategy = 6
+	// A map hasher registered at runtime with
+	// registry.RegisterNamedMapHasher, under the name in the map_hasher field
+	// of the tree. Only valid for maps.
+	HashStrategy_CUSTOM_MAP_HASHER HashStrategy = 7
 )
 
 // Enum value maps for HashStrategy.
@@ -173,6 +181,8 @@ var (
 		3: "OBJECT_RFC6962_SHA256",
 		4: "CONIKS_SHA512_256",
 		5: "CONIKS_SHA256",
+		6: "SUMDB_TLOG_SHA256",
+		7: "CUSTOM_MAP_HASHER",
 	}
 	HashStrategy_value = map[string]int32{
 		"UNKNOWN_HASH_STRATEGY": 0,
@@ -181,6 +191,8 @@ var (
 		"OBJECT_RFC6962_SHA256": 3,
 		"CONIKS_SHA512_256":     4,
 		"CONIKS_SHA256":         5,
+		"SUMDB_TLOG_SHA256":     6,
+		"CUSTOM_MAP_HASHER":     7,
 	}
 )
 
@@ -344,6 +356,59 @@ func (TreeType) EnumDescriptor() ([]byte, []int) {
 	return file_trillian_proto_rawDescGZIP(), []int{4}
 }
 
+// Compression of the leaf values and tiles of a map at rest.
+type MapCompression int32
+
+const (
+	// Values are stored uncompressed.
+	MapCompression_NO_MAP_COMPRESSION MapCompression = 0
+	// Values are compressed with Snappy: fast, with a moderate ratio.
+	MapCompression_MAP_COMPRESSION_SNAPPY MapCompression = 1
+	// Values are compressed with Zstandard: slower, with a better ratio.
+	MapCompression_MAP_COMPRESSION_ZSTD MapCompression = 2
+)
+
+// Enum value maps for MapCompression.
+var (
+	MapCompression_name = map[int32]string{
+		0: "NO_MAP_COMPRESSION",
+		1: "MAP_COMPRESSION_SNAPPY",
+		2: "MAP_COMPRESSION_ZSTD",
+	}
+	MapCompression_value = map[string]int32{
+		"NO_MAP_COMPRESSION":     0,
+		"MAP_COMPRESSION_SNAPPY": 1,
+		"MAP_COMPRESSION_ZSTD":   2,
+	}
+)
+
+func (x MapCompression) Enum() *MapCompression {
+	p := new(MapCompression)
+	*p = x
+	return p
+}
+
+func (x MapCompression) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MapCompression) Descriptor() protoreflect.EnumDescriptor {
+	return file_trillian_proto_enumTypes[5].Descriptor()
+}
+
+func (MapCompression) Type() protoreflect.EnumType {
+	return &file_trillian_proto_enumTypes[5]
+}
+
+func (x MapCompression) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MapCompression.Descriptor instead.
+func (MapCompression) EnumDescriptor() ([]byte, []int) {
+	return file_trillian_proto_rawDescGZIP(), []int{5}
+}
+
 // Represents a tree, which may be either a verifiable log or map.
 // Readonly attributes are assigned at tree creation, after which they may not
 // be modified.
@@ -389,11 +454,12 @@ type Tree struct {
 	// Private keys are write-only: they're never returned by RPCs.
 	// The private_key message can be changed after a tree is created, but the
 	// underlying key must remain the same - this is to enable migrating a key
-	// from one provider to another.
-	PrivateKey *any.Any `protobuf:"bytes,12,opt,name=private_key,json=privateKey,proto3" json:"private_key,omitempty"`
+	// from one provider to another - unless the key is rotated, see
+	// retired_public_keys.
+	PrivateKey *any1.Any `protobuf:"bytes,12,opt,name=private_key,json=privateKey,proto3" json:"private_key,omitempty"`
 	// Storage-specific settings.
 	// Varies according to the storage implementation backing Trillian.
-	StorageSettings *any.Any `protobuf:"bytes,13,opt,name=storage_settings,json=storageSettings,proto3" json:"storage_settings,omitempty"`
+	StorageSettings *any1.Any `protobuf:"bytes,13,opt,name=storage_settings,json=storageSettings,proto3" json:"storage_settings,omitempty"`
 	// The public key used for verifying tree heads and entry timestamps.
 	// Readonly.
 	PublicKey *keyspb.PublicKey `protobuf:"bytes,14,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
@@ -414,6 +480,64 @@ type Tree struct {
 	// Time of tree deletion, if any.
 	// Readonly.
 	DeleteTime *timestamp.Timestamp `protobuf:"bytes,20,opt,name=delete_time,json=deleteTime,proto3" json:"delete_time,omitempty"`
+	// Hard ceilings on the rate of writes to the tree, enforced by each server
+	// independently of any quotas.
+	// Optional.
+	RateLimits *TreeRateLimits `protobuf:"bytes,21,opt,name=rate_limits,json=rateLimits,proto3" json:"rate_limits,omitempty"`
+	// Labels of the tree, such as those of the deployment or resource which
+	// manages it. Keys must not be empty.
+	// Optional.
+	Labels map[string]string `protobuf:"bytes,22,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// ID of the CreateTree request which created the tree, if it had one.
+	// Readonly.
+	CreateRequestId string `protobuf:"bytes,23,opt,name=create_request_id,json=createRequestId,proto3" json:"create_request_id,omitempty"`
+	// If set, the tree is in maintenance, e.g. while its storage is migrated:
+	// writes to it fail with FAILED_PRECONDITION, while reads continue.
+	// Optional.
+	Maintenance *TreeMaintenance `protobuf:"bytes,24,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	// Heights of the strata of the tiles which the nodes of a map are stored
+	// in, from the root down. Each is a positive multiple of 8, and they add up
+	// to the bit length of the map hasher. Taller strata mean fewer, larger
+	// tiles are read and written per leaf. If empty, the default layout of the
+	// storage is used. Only valid for maps.
+	// Optional. Readonly.
+	MapStrata []int32 `protobuf:"varint,25,rep,packed,name=map_strata,json=mapStrata,proto3" json:"map_strata,omitempty"`
+	// Compression of the leaf values and tiles of a map when they are written.
+	// Stored values are marked with their compression, so values written
+	// before it changed still read correctly. Only valid for maps.
+	// Optional.
+	MapCompression MapCompression `protobuf:"varint,26,opt,name=map_compression,json=mapCompression,proto3,enum=trillian.MapCompression" json:"map_compression,omitempty"`
+	// Name of the map hasher of a map with the CUSTOM_MAP_HASHER hash strategy,
+	// which servers must have registered with registry.RegisterNamedMapHasher.
+	// Required for, and only valid with, the CUSTOM_MAP_HASHER hash strategy.
+	// Readonly.
+	MapHasher string `protobuf:"bytes,27,opt,name=map_hasher,json=mapHasher,proto3" json:"map_hasher,omitempty"`
+	// If set, the first write to a map which hasn't been initialized with
+	// InitMap implicitly initializes it, i.e. writes its empty revision 0 root
+	// first, so that it can be written without calling InitMap. Only valid for
+	// maps.
+	// Optional.
+	AutoInit bool `protobuf:"varint,28,opt,name=auto_init,json=autoInit,proto3" json:"auto_init,omitempty"`
+	// Maximum number of bytes of leaves and subtrees which may be written for
+	// the tree, as reported by GetTreeUsage. Writes which add leaves to a tree
+	// at or over it fail with RESOURCE_EXHAUSTED; writes which add no leaves,
+	// such as the sequencing of queued log leaves, still succeed. Only
+	// enforced by storage which tracks the usage of trees. Zero means no limit.
+	// Optional.
+	MaxStorageBytes int64 `protobuf:"varint,29,opt,name=max_storage_bytes,json=maxStorageBytes,proto3" json:"max_storage_bytes,omitempty"`
+	// If set, the map stores the keys which leaves are written with, see
+	// MapLeaf.key, in an index, so that ListLeavesByKeyPrefix can list the
+	// leaves whose keys start with a prefix. Only implemented by MySQL and
+	// SQLite storage. Only valid for maps.
+	// Optional. Readonly.
+	MapKeyIndex bool `protobuf:"varint,30,opt,name=map_key_index,json=mapKeyIndex,proto3" json:"map_key_index,omitempty"`
+	// The public keys the tree was signed with before its key was rotated,
+	// oldest first, so that verifiers can still check tree heads and entry
+	// timestamps signed with them. A key is rotated by an UpdateTree request
+	// whose update_mask has both private_key and public_key, which retires the
+	// previous public key.
+	// Readonly.
+	RetiredPublicKeys []*RetiredPublicKey `protobuf:"bytes,31,rep,name=retired_public_keys,json=retiredPublicKeys,proto3" json:"retired_public_keys,omitempty"`
 }
 
 func (x *Tree) Reset() {
@@ -504,14 +628,14 @@ func (x *Tree) GetDescription() string {
 	return ""
 }
 
-func (x *Tree) GetPrivateKey() *any.Any {
+func (x *Tree) GetPrivateKey() *any1.Any {
 	if x != nil {
 		return x.PrivateKey
 	}
 	return nil
 }
 
-func (x *Tree) GetStorageSettings() *any.Any {
+func (x *Tree) GetStorageSettings() *any1.Any {
 	if x != nil {
 		return x.StorageSettings
 	}
@@ -560,6 +684,264 @@ func (x *Tree) GetDeleteTime() *timestamp.Timestamp {
 	return nil
 }
 
+func (x *Tree) GetRateLimits() *TreeRateLimits {
+	if x != nil {
+		return x.RateLimits
+	}
+	return nil
+}
+
+func (x *Tree) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Tree) GetCreateRequestId() string {
+	if x != nil {
+		return x.CreateRequestId
+	}
+	return ""
+}
+
+func (x *Tree) GetMaintenance() *TreeMaintenance {
+	if x != nil {
+		return x.Maintenance
+	}
+	return nil
+}
+
+func (x *Tree) GetMapStrata() []int32 {
+	if x != nil {
+		return x.MapStrata
+	}
+	return nil
+}
+
+func (x *Tree) GetMapCompression() MapCompression {
+	if x != nil {
+		return x.MapCompression
+	}
+	return MapCompression_NO_MAP_COMPRESSION
+}
+
+func (x *Tree) GetMapHasher() string {
+	if x != nil {
+		return x.MapHasher
+	}
+	return ""
+}
+
+func (x *Tree) GetAutoInit() bool {
+	if x != nil {
+		return x.AutoInit
+	}
+	return false
+}
+
+func (x *Tree) GetMaxStorageBytes() int64 {
+	if x != nil {
+		return x.MaxStorageBytes
+	}
+	return 0
+}
+
+func (x *Tree) GetMapKeyIndex() bool {
+	if x != nil {
+		return x.MapKeyIndex
+	}
+	return false
+}
+
+func (x *Tree) GetRetiredPublicKeys() []*RetiredPublicKey {
+	if x != nil {
+		return x.RetiredPublicKeys
+	}
+	return nil
+}
+
+// RetiredPublicKey is a public key a tree was signed with before its key was
+// rotated.
+type RetiredPublicKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The public key, which was the tree's public_key.
+	PublicKey *keyspb.PublicKey `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	// When the key was retired, after which the tree wasn't signed with it.
+	RetireTime *timestamp.Timestamp `protobuf:"bytes,2,opt,name=retire_time,json=retireTime,proto3" json:"retire_time,omitempty"`
+}
+
+func (x *RetiredPublicKey) Reset() {
+	*x = RetiredPublicKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RetiredPublicKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetiredPublicKey) ProtoMessage() {}
+
+func (x *RetiredPublicKey) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetiredPublicKey.ProtoReflect.Descriptor instead.
+func (*RetiredPublicKey) Descriptor() ([]byte, []int) {
+	return file_trillian_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RetiredPublicKey) GetPublicKey() *keyspb.PublicKey {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *RetiredPublicKey) GetRetireTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.RetireTime
+	}
+	return nil
+}
+
+// TreeRateLimits caps the rate at which leaves are written to a tree, by
+// QueueLeaves and AddSequencedLeaves for logs, and SetLeaves and WriteLeaves
+// for maps. Requests over a limit fail with RESOURCE_EXHAUSTED.
+// Each limit applies to each server separately, and allows bursts of up to one
+// second's worth of its rate, rounded up, so a request with more leaves than
+// that always fails. Zero means no limit.
+type TreeRateLimits struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Maximum rate of write requests to the tree, per second.
+	QueriesPerSecond float64 `protobuf:"fixed64,1,opt,name=queries_per_second,json=queriesPerSecond,proto3" json:"queries_per_second,omitempty"`
+	// Maximum rate of leaves written to the tree, per second.
+	LeavesPerSecond float64 `protobuf:"fixed64,2,opt,name=leaves_per_second,json=leavesPerSecond,proto3" json:"leaves_per_second,omitempty"`
+}
+
+func (x *TreeRateLimits) Reset() {
+	*x = TreeRateLimits{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeRateLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeRateLimits) ProtoMessage() {}
+
+func (x *TreeRateLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeRateLimits.ProtoReflect.Descriptor instead.
+func (*TreeRateLimits) Descriptor() ([]byte, []int) {
+	return file_trillian_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TreeRateLimits) GetQueriesPerSecond() float64 {
+	if x != nil {
+		return x.QueriesPerSecond
+	}
+	return 0
+}
+
+func (x *TreeRateLimits) GetLeavesPerSecond() float64 {
+	if x != nil {
+		return x.LeavesPerSecond
+	}
+	return 0
+}
+
+// TreeMaintenance describes the maintenance of a tree, or of a whole server.
+type TreeMaintenance struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Why writes are refused, which is returned to the clients whose writes
+	// fail, such as "schema migration until 14:00 UTC".
+	Reason string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	// When the maintenance started.
+	StartTime *timestamp.Timestamp `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+}
+
+func (x *TreeMaintenance) Reset() {
+	*x = TreeMaintenance{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeMaintenance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeMaintenance) ProtoMessage() {}
+
+func (x *TreeMaintenance) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeMaintenance.ProtoReflect.Descriptor instead.
+func (*TreeMaintenance) Descriptor() ([]byte, []int) {
+	return file_trillian_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TreeMaintenance) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *TreeMaintenance) GetStartTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
 type SignedEntryTimestamp struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -573,7 +955,7 @@ type SignedEntryTimestamp struct {
 func (x *SignedEntryTimestamp) Reset() {
 	*x = SignedEntryTimestamp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_trillian_proto_msgTypes[1]
+		mi := &file_trillian_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -586,7 +968,7 @@ func (x *SignedEntryTimestamp) String() string {
 func (*SignedEntryTimestamp) ProtoMessage() {}
 
 func (x *SignedEntryTimestamp) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_proto_msgTypes[1]
+	mi := &file_trillian_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -599,7 +981,7 @@ func (x *SignedEntryTimestamp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SignedEntryTimestamp.ProtoReflect.Descriptor instead.
 func (*SignedEntryTimestamp) Descriptor() ([]byte, []int) {
-	return file_trillian_proto_rawDescGZIP(), []int{1}
+	return file_trillian_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *SignedEntryTimestamp) GetTimestampNanos() int64 {
@@ -679,7 +1061,7 @@ type SignedLogRoot struct {
 func (x *SignedLogRoot) Reset() {
 	*x = SignedLogRoot{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_trillian_proto_msgTypes[2]
+		mi := &file_trillian_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -692,7 +1074,7 @@ func (x *SignedLogRoot) String() string {
 func (*SignedLogRoot) ProtoMessage() {}
 
 func (x *SignedLogRoot) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_proto_msgTypes[2]
+	mi := &file_trillian_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -705,7 +1087,7 @@ func (x *SignedLogRoot) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SignedLogRoot.ProtoReflect.Descriptor instead.
 func (*SignedLogRoot) Descriptor() ([]byte, []int) {
-	return file_trillian_proto_rawDescGZIP(), []int{2}
+	return file_trillian_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *SignedLogRoot) GetKeyHint() []byte {
@@ -759,7 +1141,7 @@ type SignedMapRoot struct {
 func (x *SignedMapRoot) Reset() {
 	*x = SignedMapRoot{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_trillian_proto_msgTypes[3]
+		mi := &file_trillian_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -772,7 +1154,7 @@ func (x *SignedMapRoot) String() string {
 func (*SignedMapRoot) ProtoMessage() {}
 
 func (x *SignedMapRoot) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_proto_msgTypes[3]
+	mi := &file_trillian_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -785,7 +1167,7 @@ func (x *SignedMapRoot) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SignedMapRoot.ProtoReflect.Descriptor instead.
 func (*SignedMapRoot) Descriptor() ([]byte, []int) {
-	return file_trillian_proto_rawDescGZIP(), []int{3}
+	return file_trillian_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *SignedMapRoot) GetMapRoot() []byte {
@@ -819,7 +1201,7 @@ type Proof struct {
 func (x *Proof) Reset() {
 	*x = Proof{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_trillian_proto_msgTypes[4]
+		mi := &file_trillian_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -832,7 +1214,7 @@ func (x *Proof) String() string {
 func (*Proof) ProtoMessage() {}
 
 func (x *Proof) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_proto_msgTypes[4]
+	mi := &file_trillian_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -845,7 +1227,7 @@ func (x *Proof) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Proof.ProtoReflect.Descriptor instead.
 func (*Proof) Descriptor() ([]byte, []int) {
-	return file_trillian_proto_rawDescGZIP(), []int{4}
+	return file_trillian_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Proof) GetLeafIndex() int64 {
@@ -875,7 +1257,7 @@ var file_trillian_proto_rawDesc = []byte{
 	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72,
 	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f,
 	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x89, 0x07, 0x0a,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xd6, 0x0b, 0x0a,
 	0x04, 0x54, 0x72, 0x65, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x12, 0x32,
 	0x0a, 0x0a, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01,
@@ -931,75 +1313,142 @@ var file_trillian_proto_rawDesc = []byte{
 	0x6d, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
 	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
 	0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65,
-	0x4a, 0x04, 0x08, 0x12, 0x10, 0x13, 0x4a, 0x04, 0x08, 0x07, 0x10, 0x08, 0x4a, 0x04, 0x08, 0x0a,
-	0x10, 0x0b, 0x4a, 0x04, 0x08, 0x0b, 0x10, 0x0c, 0x22, 0x8c, 0x01, 0x0a, 0x14, 0x53, 0x69, 0x67,
-	0x6e, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
-	0x70, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x6e,
-	0x61, 0x6e, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x74, 0x69, 0x6d, 0x65,
-	0x73, 0x74, 0x61, 0x6d, 0x70, 0x4e, 0x61, 0x6e, 0x6f, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f,
-	0x67, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49,
-	0x64, 0x12, 0x34, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x73, 0x69, 0x67, 0x70, 0x62, 0x2e, 0x44, 0x69, 0x67,
-	0x69, 0x74, 0x61, 0x6c, 0x6c, 0x79, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x52, 0x09, 0x73, 0x69,
-	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x97, 0x01, 0x0a, 0x0d, 0x53, 0x69, 0x67, 0x6e,
-	0x65, 0x64, 0x4c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79,
-	0x5f, 0x68, 0x69, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6b, 0x65, 0x79,
-	0x48, 0x69, 0x6e, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x6f, 0x67, 0x5f, 0x72, 0x6f, 0x6f, 0x74,
-	0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74, 0x12,
-	0x2c, 0x0a, 0x12, 0x6c, 0x6f, 0x67, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x73, 0x69, 0x67, 0x6e,
-	0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x6c, 0x6f, 0x67,
-	0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x4a, 0x04, 0x08,
-	0x01, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x4a,
-	0x04, 0x08, 0x04, 0x10, 0x05, 0x4a, 0x04, 0x08, 0x05, 0x10, 0x06, 0x4a, 0x04, 0x08, 0x06, 0x10,
-	0x07, 0x22, 0x72, 0x0a, 0x0d, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f,
-	0x6f, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x09,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x1c, 0x0a,
-	0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x4a, 0x04, 0x08, 0x01, 0x10,
-	0x02, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x4a, 0x04, 0x08,
-	0x05, 0x10, 0x06, 0x4a, 0x04, 0x08, 0x06, 0x10, 0x07, 0x4a, 0x04, 0x08, 0x07, 0x10, 0x08, 0x4a,
-	0x04, 0x08, 0x08, 0x10, 0x09, 0x22, 0x44, 0x0a, 0x05, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x1d,
-	0x0a, 0x0a, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x09, 0x6c, 0x65, 0x61, 0x66, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x16, 0x0a,
-	0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x68,
-	0x61, 0x73, 0x68, 0x65, 0x73, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x2a, 0x44, 0x0a, 0x0d, 0x4c,
-	0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x1b, 0x0a, 0x17,
+	0x12, 0x39, 0x0a, 0x0b, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x18,
+	0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
+	0x2e, 0x54, 0x72, 0x65, 0x65, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52,
+	0x0a, 0x72, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x32, 0x0a, 0x06, 0x6c,
+	0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x16, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x72,
+	0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x2e, 0x4c, 0x61, 0x62, 0x65,
+	0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12,
+	0x2a, 0x0a, 0x11, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x17, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x3b, 0x0a, 0x0b, 0x6d,
+	0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x18, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65,
+	0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x0b, 0x6d, 0x61, 0x69,
+	0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x70, 0x5f,
+	0x73, 0x74, 0x72, 0x61, 0x74, 0x61, 0x18, 0x19, 0x20, 0x03, 0x28, 0x05, 0x52, 0x09, 0x6d, 0x61,
+	0x70, 0x53, 0x74, 0x72, 0x61, 0x74, 0x61, 0x12, 0x41, 0x0a, 0x0f, 0x6d, 0x61, 0x70, 0x5f, 0x63,
+	0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x18, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x43,
+	0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0e, 0x6d, 0x61, 0x70, 0x43,
+	0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61,
+	0x70, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x65, 0x72, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x6d, 0x61, 0x70, 0x48, 0x61, 0x73, 0x68, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x61, 0x75, 0x74,
+	0x6f, 0x5f, 0x69, 0x6e, 0x69, 0x74, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x61, 0x75,
+	0x74, 0x6f, 0x49, 0x6e, 0x69, 0x74, 0x12, 0x2a, 0x0a, 0x11, 0x6d, 0x61, 0x78, 0x5f, 0x73, 0x74,
+	0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x1d, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0f, 0x6d, 0x61, 0x78, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x42, 0x79, 0x74,
+	0x65, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x61, 0x70, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x6d, 0x61, 0x70, 0x4b, 0x65,
+	0x79, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x4a, 0x0a, 0x13, 0x72, 0x65, 0x74, 0x69, 0x72, 0x65,
+	0x64, 0x5f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x1f, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x52,
+	0x65, 0x74, 0x69, 0x72, 0x65, 0x64, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x52,
+	0x11, 0x72, 0x65, 0x74, 0x69, 0x72, 0x65, 0x64, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65,
+	0x79, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x4a, 0x04, 0x08,
+	0x12, 0x10, 0x13, 0x4a, 0x04, 0x08, 0x07, 0x10, 0x08, 0x4a, 0x04, 0x08, 0x0a, 0x10, 0x0b, 0x4a,
+	0x04, 0x08, 0x0b, 0x10, 0x0c, 0x22, 0x81, 0x01, 0x0a, 0x10, 0x52, 0x65, 0x74, 0x69, 0x72, 0x65,
+	0x64, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x30, 0x0a, 0x0a, 0x70, 0x75,
+	0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x62, 0x2e, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65,
+	0x79, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x3b, 0x0a, 0x0b,
+	0x72, 0x65, 0x74, 0x69, 0x72, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x72,
+	0x65, 0x74, 0x69, 0x72, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x22, 0x6a, 0x0a, 0x0e, 0x54, 0x72, 0x65,
+	0x65, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x71,
+	0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x10, 0x71, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73,
+	0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x11, 0x6c, 0x65, 0x61,
+	0x76, 0x65, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x50, 0x65, 0x72, 0x53,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x22, 0x64, 0x0a, 0x0f, 0x54, 0x72, 0x65, 0x65, 0x4d, 0x61, 0x69,
+	0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x22, 0x8c, 0x01, 0x0a, 0x14,
+	0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x5f, 0x6e, 0x61, 0x6e, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x4e, 0x61, 0x6e, 0x6f, 0x73, 0x12, 0x15, 0x0a,
+	0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c,
+	0x6f, 0x67, 0x49, 0x64, 0x12, 0x34, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x73, 0x69, 0x67, 0x70, 0x62, 0x2e,
+	0x44, 0x69, 0x67, 0x69, 0x74, 0x61, 0x6c, 0x6c, 0x79, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x52,
+	0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x97, 0x01, 0x0a, 0x0d, 0x53,
+	0x69, 0x67, 0x6e, 0x65, 0x64, 0x4c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x19, 0x0a, 0x08,
+	0x6b, 0x65, 0x79, 0x5f, 0x68, 0x69, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07,
+	0x6b, 0x65, 0x79, 0x48, 0x69, 0x6e, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x6f, 0x67, 0x5f, 0x72,
+	0x6f, 0x6f, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6c, 0x6f, 0x67, 0x52, 0x6f,
+	0x6f, 0x74, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x6f, 0x67, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10,
+	0x6c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x4a, 0x04, 0x08, 0x01, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x4a, 0x04, 0x08, 0x03,
+	0x10, 0x04, 0x4a, 0x04, 0x08, 0x04, 0x10, 0x05, 0x4a, 0x04, 0x08, 0x05, 0x10, 0x06, 0x4a, 0x04,
+	0x08, 0x06, 0x10, 0x07, 0x22, 0x72, 0x0a, 0x0d, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61,
+	0x70, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f,
+	0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74,
+	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x4a, 0x04,
+	0x08, 0x01, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04,
+	0x4a, 0x04, 0x08, 0x05, 0x10, 0x06, 0x4a, 0x04, 0x08, 0x06, 0x10, 0x07, 0x4a, 0x04, 0x08, 0x07,
+	0x10, 0x08, 0x4a, 0x04, 0x08, 0x08, 0x10, 0x09, 0x22, 0x44, 0x0a, 0x05, 0x50, 0x72, 0x6f, 0x6f,
+	0x66, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x65, 0x61, 0x66, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x12, 0x16, 0x0a, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c,
+	0x52, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x2a, 0x44,
+	0x0a, 0x0d, 0x4c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12,
+	0x1b, 0x0a, 0x17, 0x4c, 0x4f, 0x47, 0x5f, 0x52, 0x4f, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d,
+	0x41, 0x54, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12,
 	0x4c, 0x4f, 0x47, 0x5f, 0x52, 0x4f, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f,
-	0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x4c, 0x4f, 0x47,
-	0x5f, 0x52, 0x4f, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x56, 0x31, 0x10,
-	0x01, 0x2a, 0x44, 0x0a, 0x0d, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x46, 0x6f, 0x72, 0x6d,
-	0x61, 0x74, 0x12, 0x1b, 0x0a, 0x17, 0x4d, 0x41, 0x50, 0x5f, 0x52, 0x4f, 0x4f, 0x54, 0x5f, 0x46,
-	0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12,
-	0x16, 0x0a, 0x12, 0x4d, 0x41, 0x50, 0x5f, 0x52, 0x4f, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d,
-	0x41, 0x54, 0x5f, 0x56, 0x31, 0x10, 0x01, 0x2a, 0x97, 0x01, 0x0a, 0x0c, 0x48, 0x61, 0x73, 0x68,
-	0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12, 0x19, 0x0a, 0x15, 0x55, 0x4e, 0x4b, 0x4e,
-	0x4f, 0x57, 0x4e, 0x5f, 0x48, 0x41, 0x53, 0x48, 0x5f, 0x53, 0x54, 0x52, 0x41, 0x54, 0x45, 0x47,
-	0x59, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x52, 0x46, 0x43, 0x36, 0x39, 0x36, 0x32, 0x5f, 0x53,
-	0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x54, 0x45, 0x53, 0x54, 0x5f,
-	0x4d, 0x41, 0x50, 0x5f, 0x48, 0x41, 0x53, 0x48, 0x45, 0x52, 0x10, 0x02, 0x12, 0x19, 0x0a, 0x15,
-	0x4f, 0x42, 0x4a, 0x45, 0x43, 0x54, 0x5f, 0x52, 0x46, 0x43, 0x36, 0x39, 0x36, 0x32, 0x5f, 0x53,
-	0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x03, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x4f, 0x4e, 0x49, 0x4b,
-	0x53, 0x5f, 0x53, 0x48, 0x41, 0x35, 0x31, 0x32, 0x5f, 0x32, 0x35, 0x36, 0x10, 0x04, 0x12, 0x11,
-	0x0a, 0x0d, 0x43, 0x4f, 0x4e, 0x49, 0x4b, 0x53, 0x5f, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10,
-	0x05, 0x2a, 0x8b, 0x01, 0x0a, 0x09, 0x54, 0x72, 0x65, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
-	0x16, 0x0a, 0x12, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x54, 0x52, 0x45, 0x45, 0x5f,
-	0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x41, 0x43, 0x54, 0x49, 0x56,
-	0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x52, 0x4f, 0x5a, 0x45, 0x4e, 0x10, 0x02, 0x12,
-	0x1f, 0x0a, 0x17, 0x44, 0x45, 0x50, 0x52, 0x45, 0x43, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x53, 0x4f,
-	0x46, 0x54, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x03, 0x1a, 0x02, 0x08, 0x01,
-	0x12, 0x1f, 0x0a, 0x17, 0x44, 0x45, 0x50, 0x52, 0x45, 0x43, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x48,
-	0x41, 0x52, 0x44, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x04, 0x1a, 0x02, 0x08,
-	0x01, 0x12, 0x0c, 0x0a, 0x08, 0x44, 0x52, 0x41, 0x49, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x05, 0x2a,
-	0x47, 0x0a, 0x08, 0x54, 0x72, 0x65, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x55,
-	0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45,
-	0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x4c, 0x4f, 0x47, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x4d,
-	0x41, 0x50, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x50, 0x52, 0x45, 0x4f, 0x52, 0x44, 0x45, 0x52,
-	0x45, 0x44, 0x5f, 0x4c, 0x4f, 0x47, 0x10, 0x03, 0x42, 0x48, 0x0a, 0x19, 0x63, 0x6f, 0x6d, 0x2e,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x42, 0x0d, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x50,
-	0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x1a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
-	0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69,
-	0x61, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x56, 0x31, 0x10, 0x01, 0x2a, 0x44, 0x0a, 0x0d, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x46,
+	0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x1b, 0x0a, 0x17, 0x4d, 0x41, 0x50, 0x5f, 0x52, 0x4f, 0x4f,
+	0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e,
+	0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x4d, 0x41, 0x50, 0x5f, 0x52, 0x4f, 0x4f, 0x54, 0x5f, 0x46,
+	0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x56, 0x31, 0x10, 0x01, 0x2a, 0xc5, 0x01, 0x0a, 0x0c, 0x48,
+	0x61, 0x73, 0x68, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12, 0x19, 0x0a, 0x15, 0x55,
+	0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x48, 0x41, 0x53, 0x48, 0x5f, 0x53, 0x54, 0x52, 0x41,
+	0x54, 0x45, 0x47, 0x59, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x52, 0x46, 0x43, 0x36, 0x39, 0x36,
+	0x32, 0x5f, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x54, 0x45,
+	0x53, 0x54, 0x5f, 0x4d, 0x41, 0x50, 0x5f, 0x48, 0x41, 0x53, 0x48, 0x45, 0x52, 0x10, 0x02, 0x12,
+	0x19, 0x0a, 0x15, 0x4f, 0x42, 0x4a, 0x45, 0x43, 0x54, 0x5f, 0x52, 0x46, 0x43, 0x36, 0x39, 0x36,
+	0x32, 0x5f, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x03, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x4f,
+	0x4e, 0x49, 0x4b, 0x53, 0x5f, 0x53, 0x48, 0x41, 0x35, 0x31, 0x32, 0x5f, 0x32, 0x35, 0x36, 0x10,
+	0x04, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x4f, 0x4e, 0x49, 0x4b, 0x53, 0x5f, 0x53, 0x48, 0x41, 0x32,
+	0x35, 0x36, 0x10, 0x05, 0x12, 0x15, 0x0a, 0x11, 0x53, 0x55, 0x4d, 0x44, 0x42, 0x5f, 0x54, 0x4c,
+	0x4f, 0x47, 0x5f, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x06, 0x12, 0x15, 0x0a, 0x11, 0x43,
+	0x55, 0x53, 0x54, 0x4f, 0x4d, 0x5f, 0x4d, 0x41, 0x50, 0x5f, 0x48, 0x41, 0x53, 0x48, 0x45, 0x52,
+	0x10, 0x07, 0x2a, 0x8b, 0x01, 0x0a, 0x09, 0x54, 0x72, 0x65, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x12, 0x16, 0x0a, 0x12, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x54, 0x52, 0x45, 0x45,
+	0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x41, 0x43, 0x54, 0x49,
+	0x56, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x52, 0x4f, 0x5a, 0x45, 0x4e, 0x10, 0x02,
+	0x12, 0x1f, 0x0a, 0x17, 0x44, 0x45, 0x50, 0x52, 0x45, 0x43, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x53,
+	0x4f, 0x46, 0x54, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x03, 0x1a, 0x02, 0x08,
+	0x01, 0x12, 0x1f, 0x0a, 0x17, 0x44, 0x45, 0x50, 0x52, 0x45, 0x43, 0x41, 0x54, 0x45, 0x44, 0x5f,
+	0x48, 0x41, 0x52, 0x44, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x04, 0x1a, 0x02,
+	0x08, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x44, 0x52, 0x41, 0x49, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x05,
+	0x2a, 0x47, 0x0a, 0x08, 0x54, 0x72, 0x65, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x15, 0x0a, 0x11,
+	0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x4c, 0x4f, 0x47, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03,
+	0x4d, 0x41, 0x50, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x50, 0x52, 0x45, 0x4f, 0x52, 0x44, 0x45,
+	0x52, 0x45, 0x44, 0x5f, 0x4c, 0x4f, 0x47, 0x10, 0x03, 0x2a, 0x5e, 0x0a, 0x0e, 0x4d, 0x61, 0x70,
+	0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x12, 0x4e,
+	0x4f, 0x5f, 0x4d, 0x41, 0x50, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x52, 0x45, 0x53, 0x53, 0x49, 0x4f,
+	0x4e, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x4d, 0x41, 0x50, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x52,
+	0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x50, 0x59, 0x10, 0x01, 0x12,
+	0x18, 0x0a, 0x14, 0x4d, 0x41, 0x50, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x52, 0x45, 0x53, 0x53, 0x49,
+	0x4f, 0x4e, 0x5f, 0x5a, 0x53, 0x54, 0x44, 0x10, 0x02, 0x42, 0x48, 0x0a, 0x19, 0x63, 0x6f, 0x6d,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x42, 0x0d, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
+	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x1a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1014,46 +1463,59 @@ func file_trillian_proto_rawDescGZIP() []byte {
 	return file_trillian_proto_rawDescData
 }
 
-var file_trillian_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
-var file_trillian_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_trillian_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_trillian_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_trillian_proto_goTypes = []interface{}{
-	(LogRootFormat)(0),                            // 0: trillian.LogRootFormat
-	(MapRootFormat)(0),                            // 1: trillian.MapRootFormat
-	(HashStrategy)(0),                             // 2: trillian.HashStrategy
-	(TreeState)(0),                                // 3: trillian.TreeState
-	(TreeType)(0),                                 // 4: trillian.TreeType
-	(*Tree)(nil),                                  // 5: trillian.Tree
-	(*SignedEntryTimestamp)(nil),                  // 6: trillian.SignedEntryTimestamp
-	(*SignedLogRoot)(nil),                         // 7: trillian.SignedLogRoot
-	(*SignedMapRoot)(nil),                         // 8: trillian.SignedMapRoot
-	(*Proof)(nil),                                 // 9: trillian.Proof
-	(sigpb.DigitallySigned_HashAlgorithm)(0),      // 10: sigpb.DigitallySigned.HashAlgorithm
-	(sigpb.DigitallySigned_SignatureAlgorithm)(0), // 11: sigpb.DigitallySigned.SignatureAlgorithm
-	(*any.Any)(nil),                               // 12: google.protobuf.Any
-	(*keyspb.PublicKey)(nil),                      // 13: keyspb.PublicKey
-	(*duration.Duration)(nil),                     // 14: google.protobuf.Duration
-	(*timestamp.Timestamp)(nil),                   // 15: google.protobuf.Timestamp
-	(*sigpb.DigitallySigned)(nil),                 // 16: sigpb.DigitallySigned
+	(LogRootFormat)(0),                       // 0: trillian.LogRootFormat
+	(MapRootFormat)(0),                       // 1: trillian.MapRootFormat
+	(HashStrategy)(0),                        // 2: trillian.HashStrategy
+	(TreeState)(0),                           // 3: trillian.TreeState
+	(TreeType)(0),                            // 4: trillian.TreeType
+	(MapCompression)(0),                      // 5: trillian.MapCompression
+	(*Tree)(nil),                             // 6: trillian.Tree
+	(*RetiredPublicKey)(nil),                 // 7: trillian.RetiredPublicKey
+	(*TreeRateLimits)(nil),                   // 8: trillian.TreeRateLimits
+	(*TreeMaintenance)(nil),                  // 9: trillian.TreeMaintenance
+	(*SignedEntryTimestamp)(nil),             // 10: trillian.SignedEntryTimestamp
+	(*SignedLogRoot)(nil),                    // 11: trillian.SignedLogRoot
+	(*SignedMapRoot)(nil),                    // 12: trillian.SignedMapRoot
+	(*Proof)(nil),                            // 13: trillian.Proof
+	nil,                                      // 14: trillian.Tree.LabelsEntry
+	(sigpb.DigitallySigned_HashAlgorithm)(0), // 15: sigpb.DigitallySigned.HashAlgorithm
+	(sigpb.DigitallySigned_SignatureAlgorithm)(0), // 16: sigpb.DigitallySigned.SignatureAlgorithm
+	(*any1.Any)(nil),              // 17: google.protobuf.Any
+	(*keyspb.PublicKey)(nil),      // 18: keyspb.PublicKey
+	(*duration.Duration)(nil),     // 19: google.protobuf.Duration
+	(*timestamp.Timestamp)(nil),   // 20: google.protobuf.Timestamp
+	(*sigpb.DigitallySigned)(nil), // 21: sigpb.DigitallySigned
 }
 var file_trillian_proto_depIdxs = []int32{
 	3,  // 0: trillian.Tree.tree_state:type_name -> trillian.TreeState
 	4,  // 1: trillian.Tree.tree_type:type_name -> trillian.TreeType
 	2,  // 2: trillian.Tree.hash_strategy:type_name -> trillian.HashStrategy
-	10, // 3: trillian.Tree.hash_algorithm:type_name -> sigpb.DigitallySigned.HashAlgorithm
-	11, // 4: trillian.Tree.signature_algorithm:type_name -> sigpb.DigitallySigned.SignatureAlgorithm
-	12, // 5: trillian.Tree.private_key:type_name -> google.protobuf.Any
-	12, // 6: trillian.Tree.storage_settings:type_name -> google.protobuf.Any
-	13, // 7: trillian.Tree.public_key:type_name -> keyspb.PublicKey
-	14, // 8: trillian.Tree.max_root_duration:type_name -> google.protobuf.Duration
-	15, // 9: trillian.Tree.create_time:type_name -> google.protobuf.Timestamp
-	15, // 10: trillian.Tree.update_time:type_name -> google.protobuf.Timestamp
-	15, // 11: trillian.Tree.delete_time:type_name -> google.protobuf.Timestamp
-	16, // 12: trillian.SignedEntryTimestamp.signature:type_name -> sigpb.DigitallySigned
-	13, // [13:13] is the sub-list for method output_type
-	13, // [13:13] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	15, // 3: trillian.Tree.hash_algorithm:type_name -> sigpb.DigitallySigned.HashAlgorithm
+	16, // 4: trillian.Tree.signature_algorithm:type_name -> sigpb.DigitallySigned.SignatureAlgorithm
+	17, // 5: trillian.Tree.private_key:type_name -> google.protobuf.Any
+	17, // 6: trillian.Tree.storage_settings:type_name -> google.protobuf.Any
+	18, // 7: trillian.Tree.public_key:type_name -> keyspb.PublicKey
+	19, // 8: trillian.Tree.max_root_duration:type_name -> google.protobuf.Duration
+	20, // 9: trillian.Tree.create_time:type_name -> google.protobuf.Timestamp
+	20, // 10: trillian.Tree.update_time:type_name -> google.protobuf.Timestamp
+	20, // 11: trillian.Tree.delete_time:type_name -> google.protobuf.Timestamp
+	8,  // 12: trillian.Tree.rate_limits:type_name -> trillian.TreeRateLimits
+	14, // 13: trillian.Tree.labels:type_name -> trillian.Tree.LabelsEntry
+	9,  // 14: trillian.Tree.maintenance:type_name -> trillian.TreeMaintenance
+	5,  // 15: trillian.Tree.map_compression:type_name -> trillian.MapCompression
+	7,  // 16: trillian.Tree.retired_public_keys:type_name -> trillian.RetiredPublicKey
+	18, // 17: trillian.RetiredPublicKey.public_key:type_name -> keyspb.PublicKey
+	20, // 18: trillian.RetiredPublicKey.retire_time:type_name -> google.protobuf.Timestamp
+	20, // 19: trillian.TreeMaintenance.start_time:type_name -> google.protobuf.Timestamp
+	21, // 20: trillian.SignedEntryTimestamp.signature:type_name -> sigpb.DigitallySigned
+	21, // [21:21] is the sub-list for method output_type
+	21, // [21:21] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_trillian_proto_init() }
@@ -1075,7 +1537,7 @@ func file_trillian_proto_init() {
 			}
 		}
 		file_trillian_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SignedEntryTimestamp); i {
+			switch v := v.(*RetiredPublicKey); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1087,7 +1549,7 @@ func file_trillian_proto_init() {
 			}
 		}
 		file_trillian_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SignedLogRoot); i {
+			switch v := v.(*TreeRateLimits); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1099,7 +1561,7 @@ func file_trillian_proto_init() {
 			}
 		}
 		file_trillian_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SignedMapRoot); i {
+			switch v := v.(*TreeMaintenance); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1111,6 +1573,42 @@ func file_trillian_proto_init() {
 			}
 		}
 		file_trillian_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignedEntryTimestamp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignedLogRoot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignedMapRoot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Proof); i {
 			case 0:
 				return &v.state
@@ -1128,8 +1626,8 @@ func file_trillian_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_trillian_proto_rawDesc,
-			NumEnums:      5,
-			NumMessages:   5,
+			NumEnums:      6,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -23,6 +23,7 @@ package trillian
 import (
 	context "context"
 	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	keyspb "github.com/google/trillian/crypto/keyspb"
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	field_mask "google.golang.org/genproto/protobuf/field_mask"
@@ -207,6 +208,25 @@ type CreateTreeRequest struct {
 	// Describes how the tree's private key should be generated.
 	// Only needs to be set if tree.private_key is not set.
 	KeySpec *keyspb.Specification `protobuf:"bytes,2,opt,name=key_spec,json=keySpec,proto3" json:"key_spec,omitempty"`
+	// Client-chosen ID of the request, such as a UUID, which makes it
+	// idempotent: retries of a request with the same ID return the tree created
+	// by the first instead of creating another, even if it has since been
+	// changed or deleted. A retry asking for another tree ID, or for another
+	// value of a field which can't be updated, fails with FAILED_PRECONDITION.
+	// Optional. At most 128 characters.
+	RequestId string `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// ID to create the tree with, instead of a random one, such as the ID of
+	// the same tree in another environment. Creation fails with ALREADY_EXISTS
+	// if a tree, even a deleted one, has the ID.
+	// Optional. Must not be negative, or set along with tree_id_name.
+	TreeId int64 `protobuf:"varint,4,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	// Name from which the ID of the tree is derived, such as
+	// "ct/argon2021", instead of choosing a random one. The same name always
+	// gives the same ID, so trees keep their IDs when their environments are
+	// rebuilt. Creation fails with ALREADY_EXISTS if a tree, even a deleted
+	// one, has the ID.
+	// Optional.
+	TreeIdName string `protobuf:"bytes,5,opt,name=tree_id_name,json=treeIdName,proto3" json:"tree_id_name,omitempty"`
 }
 
 func (x *CreateTreeRequest) Reset() {
@@ -255,6 +275,27 @@ func (x *CreateTreeRequest) GetKeySpec() *keyspb.Specification {
 	return nil
 }
 
+func (x *CreateTreeRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *CreateTreeRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *CreateTreeRequest) GetTreeIdName() string {
+	if x != nil {
+		return x.TreeIdName
+	}
+	return ""
+}
+
 // UpdateTree request.
 type UpdateTreeRequest struct {
 	state         protoimpl.MessageState
@@ -265,6 +306,9 @@ type UpdateTreeRequest struct {
 	Tree *Tree `protobuf:"bytes,1,opt,name=tree,proto3" json:"tree,omitempty"`
 	// Fields modified by the update request.
 	// For example: "tree_state", "display_name", "description".
+	// Updating "public_key" along with "private_key" rotates the key of the tree,
+	// see Tree.retired_public_keys; the public key is derived from the private
+	// key if it's unset.
 	UpdateMask *field_mask.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
 }
 
@@ -412,150 +456,2193 @@ func (x *UndeleteTreeRequest) GetTreeId() int64 {
 	return 0
 }
 
-var File_trillian_admin_api_proto protoreflect.FileDescriptor
+// DeadLetterLeaf is a queued log leaf which was quarantined by the sequencer
+// because it repeatedly caused sequencing of its log to fail.
+type DeadLetterLeaf struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_trillian_admin_api_proto_rawDesc = []byte{
-	0x0a, 0x18, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x5f, 0x61, 0x64, 0x6d, 0x69, 0x6e,
-	0x5f, 0x61, 0x70, 0x69, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08, 0x74, 0x72, 0x69, 0x6c,
-	0x6c, 0x69, 0x61, 0x6e, 0x1a, 0x0e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1a, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x2f, 0x6b, 0x65, 0x79,
-	0x73, 0x70, 0x62, 0x2f, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x62, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e,
-	0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x20,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f,
-	0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x22, 0x35, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x65, 0x65, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x68, 0x6f, 0x77, 0x5f, 0x64, 0x65, 0x6c,
-	0x65, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x73, 0x68, 0x6f, 0x77,
-	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x22, 0x37, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x54,
-	0x72, 0x65, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x04,
-	0x74, 0x72, 0x65, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x74, 0x72, 0x69,
-	0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65,
-	0x22, 0x29, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x22, 0x69, 0x0a, 0x11, 0x43,
-	0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x22, 0x0a, 0x04, 0x74, 0x72, 0x65, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e,
-	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x52, 0x04,
-	0x74, 0x72, 0x65, 0x65, 0x12, 0x30, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x73, 0x70, 0x65, 0x63,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x62, 0x2e,
-	0x53, 0x70, 0x65, 0x63, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x6b,
-	0x65, 0x79, 0x53, 0x70, 0x65, 0x63, 0x22, 0x74, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
-	0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x04, 0x74,
-	0x72, 0x65, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c,
-	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65, 0x12,
-	0x3b, 0x0a, 0x0b, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4d, 0x61, 0x73, 0x6b,
-	0x52, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x61, 0x73, 0x6b, 0x22, 0x2c, 0x0a, 0x11,
-	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x22, 0x2e, 0x0a, 0x13, 0x55, 0x6e,
-	0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x32, 0xb8, 0x04, 0x0a, 0x0d, 0x54,
-	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x46, 0x0a, 0x09,
-	0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x65, 0x65, 0x73, 0x12, 0x1a, 0x2e, 0x74, 0x72, 0x69, 0x6c,
-	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x65, 0x65, 0x73, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
-	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x65, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x00, 0x12, 0x57, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x54, 0x72, 0x65, 0x65, 0x12,
-	0x18, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x72,
-	0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c,
-	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02,
-	0x1c, 0x12, 0x1a, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65,
-	0x73, 0x2f, 0x7b, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x3d, 0x2a, 0x7d, 0x12, 0x54, 0x0a,
-	0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x12, 0x1b, 0x2e, 0x74, 0x72,
-	0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x72, 0x65,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
-	0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x22, 0x19, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x13,
-	0x22, 0x0e, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73,
-	0x3a, 0x01, 0x2a, 0x12, 0x65, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x72, 0x65,
-	0x65, 0x12, 0x1b, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x55, 0x70, 0x64,
-	0x61, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e,
-	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x22, 0x2a,
-	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x24, 0x32, 0x1f, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31,
-	0x2f, 0x74, 0x72, 0x65, 0x65, 0x73, 0x2f, 0x7b, 0x74, 0x72, 0x65, 0x65, 0x2e, 0x74, 0x72, 0x65,
-	0x65, 0x5f, 0x69, 0x64, 0x3d, 0x2a, 0x7d, 0x3a, 0x01, 0x2a, 0x12, 0x5d, 0x0a, 0x0a, 0x44, 0x65,
-	0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x12, 0x1b, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
-	0x69, 0x61, 0x6e, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
-	0x2e, 0x54, 0x72, 0x65, 0x65, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x2a, 0x1a, 0x2f,
-	0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73, 0x2f, 0x7b, 0x74,
-	0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x3d, 0x2a, 0x7d, 0x12, 0x6a, 0x0a, 0x0c, 0x55, 0x6e, 0x64,
-	0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x12, 0x1d, 0x2e, 0x74, 0x72, 0x69, 0x6c,
-	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x55, 0x6e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
-	0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x22, 0x2b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x25,
-	0x2a, 0x23, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73,
-	0x2f, 0x7b, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x3d, 0x2a, 0x7d, 0x3a, 0x75, 0x6e, 0x64,
-	0x65, 0x6c, 0x65, 0x74, 0x65, 0x42, 0x50, 0x0a, 0x19, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x42, 0x15, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x41, 0x64, 0x6d, 0x69,
-	0x6e, 0x41, 0x70, 0x69, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x1a, 0x67, 0x69, 0x74,
-	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x74,
-	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	// The quarantined leaf. Only leaf_identity_hash, merkle_leaf_hash and
+	// queue_timestamp are populated.
+	Leaf *LogLeaf `protobuf:"bytes,1,opt,name=leaf,proto3" json:"leaf,omitempty"`
+	// The time at which the leaf was quarantined.
+	QuarantineTimestamp *timestamp.Timestamp `protobuf:"bytes,2,opt,name=quarantine_timestamp,json=quarantineTimestamp,proto3" json:"quarantine_timestamp,omitempty"`
+	// Describes why the leaf was quarantined.
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
 }
 
-var (
-	file_trillian_admin_api_proto_rawDescOnce sync.Once
-	file_trillian_admin_api_proto_rawDescData = file_trillian_admin_api_proto_rawDesc
-)
+func (x *DeadLetterLeaf) Reset() {
+	*x = DeadLetterLeaf{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
 
-func file_trillian_admin_api_proto_rawDescGZIP() []byte {
-	file_trillian_admin_api_proto_rawDescOnce.Do(func() {
-		file_trillian_admin_api_proto_rawDescData = protoimpl.X.CompressGZIP(file_trillian_admin_api_proto_rawDescData)
-	})
-	return file_trillian_admin_api_proto_rawDescData
+func (x *DeadLetterLeaf) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var file_trillian_admin_api_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
-var file_trillian_admin_api_proto_goTypes = []interface{}{
-	(*ListTreesRequest)(nil),     // 0: trillian.ListTreesRequest
-	(*ListTreesResponse)(nil),    // 1: trillian.ListTreesResponse
-	(*GetTreeRequest)(nil),       // 2: trillian.GetTreeRequest
-	(*CreateTreeRequest)(nil),    // 3: trillian.CreateTreeRequest
-	(*UpdateTreeRequest)(nil),    // 4: trillian.UpdateTreeRequest
-	(*DeleteTreeRequest)(nil),    // 5: trillian.DeleteTreeRequest
-	(*UndeleteTreeRequest)(nil),  // 6: trillian.UndeleteTreeRequest
-	(*Tree)(nil),                 // 7: trillian.Tree
-	(*keyspb.Specification)(nil), // 8: keyspb.Specification
-	(*field_mask.FieldMask)(nil), // 9: google.protobuf.FieldMask
+func (*DeadLetterLeaf) ProtoMessage() {}
+
+func (x *DeadLetterLeaf) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
-var file_trillian_admin_api_proto_depIdxs = []int32{
-	7,  // 0: trillian.ListTreesResponse.tree:type_name -> trillian.Tree
-	7,  // 1: trillian.CreateTreeRequest.tree:type_name -> trillian.Tree
-	8,  // 2: trillian.CreateTreeRequest.key_spec:type_name -> keyspb.Specification
-	7,  // 3: trillian.UpdateTreeRequest.tree:type_name -> trillian.Tree
-	9,  // 4: trillian.UpdateTreeRequest.update_mask:type_name -> google.protobuf.FieldMask
-	0,  // 5: trillian.TrillianAdmin.ListTrees:input_type -> trillian.ListTreesRequest
-	2,  // 6: trillian.TrillianAdmin.GetTree:input_type -> trillian.GetTreeRequest
-	3,  // 7: trillian.TrillianAdmin.CreateTree:input_type -> trillian.CreateTreeRequest
-	4,  // 8: trillian.TrillianAdmin.UpdateTree:input_type -> trillian.UpdateTreeRequest
-	5,  // 9: trillian.TrillianAdmin.DeleteTree:input_type -> trillian.DeleteTreeRequest
-	6,  // 10: trillian.TrillianAdmin.UndeleteTree:input_type -> trillian.UndeleteTreeRequest
-	1,  // 11: trillian.TrillianAdmin.ListTrees:output_type -> trillian.ListTreesResponse
-	7,  // 12: trillian.TrillianAdmin.GetTree:output_type -> trillian.Tree
-	7,  // 13: trillian.TrillianAdmin.CreateTree:output_type -> trillian.Tree
-	7,  // 14: trillian.TrillianAdmin.UpdateTree:output_type -> trillian.Tree
-	7,  // 15: trillian.TrillianAdmin.DeleteTree:output_type -> trillian.Tree
-	7,  // 16: trillian.TrillianAdmin.UndeleteTree:output_type -> trillian.Tree
-	11, // [11:17] is the sub-list for method output_type
-	5,  // [5:11] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+
+// Deprecated: Use DeadLetterLeaf.ProtoReflect.Descriptor instead.
+func (*DeadLetterLeaf) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{7}
 }
 
-func init() { file_trillian_admin_api_proto_init() }
-func file_trillian_admin_api_proto_init() {
-	if File_trillian_admin_api_proto != nil {
-		return
+func (x *DeadLetterLeaf) GetLeaf() *LogLeaf {
+	if x != nil {
+		return x.Leaf
 	}
-	file_trillian_proto_init()
-	if !protoimpl.UnsafeEnabled {
-		file_trillian_admin_api_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListTreesRequest); i {
+	return nil
+}
+
+func (x *DeadLetterLeaf) GetQuarantineTimestamp() *timestamp.Timestamp {
+	if x != nil {
+		return x.QuarantineTimestamp
+	}
+	return nil
+}
+
+func (x *DeadLetterLeaf) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// ListDeadLetterLeaves request.
+type ListDeadLetterLeavesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID of the log whose quarantined leaves are listed.
+	TreeId int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	// Maximum number of leaves to return. If zero, a server-defined default is
+	// used.
+	MaxLeaves int64 `protobuf:"varint,2,opt,name=max_leaves,json=maxLeaves,proto3" json:"max_leaves,omitempty"`
+}
+
+func (x *ListDeadLetterLeavesRequest) Reset() {
+	*x = ListDeadLetterLeavesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListDeadLetterLeavesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLetterLeavesRequest) ProtoMessage() {}
+
+func (x *ListDeadLetterLeavesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLetterLeavesRequest.ProtoReflect.Descriptor instead.
+func (*ListDeadLetterLeavesRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListDeadLetterLeavesRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *ListDeadLetterLeavesRequest) GetMaxLeaves() int64 {
+	if x != nil {
+		return x.MaxLeaves
+	}
+	return 0
+}
+
+// ListDeadLetterLeaves response.
+type ListDeadLetterLeavesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Quarantined leaves, ordered by quarantine time.
+	Leaves []*DeadLetterLeaf `protobuf:"bytes,1,rep,name=leaves,proto3" json:"leaves,omitempty"`
+}
+
+func (x *ListDeadLetterLeavesResponse) Reset() {
+	*x = ListDeadLetterLeavesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListDeadLetterLeavesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLetterLeavesResponse) ProtoMessage() {}
+
+func (x *ListDeadLetterLeavesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLetterLeavesResponse.ProtoReflect.Descriptor instead.
+func (*ListDeadLetterLeavesResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListDeadLetterLeavesResponse) GetLeaves() []*DeadLetterLeaf {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+// RequeueDeadLetterLeaves request.
+type RequeueDeadLetterLeavesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID of the log whose quarantined leaves are requeued.
+	TreeId int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	// Identity hashes of the quarantined leaves to put back into the queue.
+	LeafIdentityHash [][]byte `protobuf:"bytes,2,rep,name=leaf_identity_hash,json=leafIdentityHash,proto3" json:"leaf_identity_hash,omitempty"`
+}
+
+func (x *RequeueDeadLetterLeavesRequest) Reset() {
+	*x = RequeueDeadLetterLeavesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequeueDeadLetterLeavesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequeueDeadLetterLeavesRequest) ProtoMessage() {}
+
+func (x *RequeueDeadLetterLeavesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequeueDeadLetterLeavesRequest.ProtoReflect.Descriptor instead.
+func (*RequeueDeadLetterLeavesRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RequeueDeadLetterLeavesRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *RequeueDeadLetterLeavesRequest) GetLeafIdentityHash() [][]byte {
+	if x != nil {
+		return x.LeafIdentityHash
+	}
+	return nil
+}
+
+// RequeueDeadLetterLeaves response.
+type RequeueDeadLetterLeavesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of leaves which were requeued.
+	Requeued int64 `protobuf:"varint,1,opt,name=requeued,proto3" json:"requeued,omitempty"`
+}
+
+func (x *RequeueDeadLetterLeavesResponse) Reset() {
+	*x = RequeueDeadLetterLeavesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequeueDeadLetterLeavesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequeueDeadLetterLeavesResponse) ProtoMessage() {}
+
+func (x *RequeueDeadLetterLeavesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequeueDeadLetterLeavesResponse.ProtoReflect.Descriptor instead.
+func (*RequeueDeadLetterLeavesResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RequeueDeadLetterLeavesResponse) GetRequeued() int64 {
+	if x != nil {
+		return x.Requeued
+	}
+	return 0
+}
+
+// PurgeDeadLetterLeaves request.
+type PurgeDeadLetterLeavesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID of the log whose quarantined leaves are purged.
+	TreeId int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	// Identity hashes of the quarantined leaves to delete.
+	LeafIdentityHash [][]byte `protobuf:"bytes,2,rep,name=leaf_identity_hash,json=leafIdentityHash,proto3" json:"leaf_identity_hash,omitempty"`
+}
+
+func (x *PurgeDeadLetterLeavesRequest) Reset() {
+	*x = PurgeDeadLetterLeavesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PurgeDeadLetterLeavesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeDeadLetterLeavesRequest) ProtoMessage() {}
+
+func (x *PurgeDeadLetterLeavesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeDeadLetterLeavesRequest.ProtoReflect.Descriptor instead.
+func (*PurgeDeadLetterLeavesRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PurgeDeadLetterLeavesRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *PurgeDeadLetterLeavesRequest) GetLeafIdentityHash() [][]byte {
+	if x != nil {
+		return x.LeafIdentityHash
+	}
+	return nil
+}
+
+// PurgeDeadLetterLeaves response.
+type PurgeDeadLetterLeavesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of leaves which were purged.
+	Purged int64 `protobuf:"varint,1,opt,name=purged,proto3" json:"purged,omitempty"`
+}
+
+func (x *PurgeDeadLetterLeavesResponse) Reset() {
+	*x = PurgeDeadLetterLeavesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PurgeDeadLetterLeavesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeDeadLetterLeavesResponse) ProtoMessage() {}
+
+func (x *PurgeDeadLetterLeavesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeDeadLetterLeavesResponse.ProtoReflect.Descriptor instead.
+func (*PurgeDeadLetterLeavesResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PurgeDeadLetterLeavesResponse) GetPurged() int64 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
+}
+
+// GetQuotaState request.
+type GetQuotaStateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID of the tree whose quotas are reported. If zero, only global quotas are
+	// reported.
+	TreeId int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	// Users whose quotas are reported, in addition to the global and tree
+	// quotas.
+	User []string `protobuf:"bytes,2,rep,name=user,proto3" json:"user,omitempty"`
+}
+
+func (x *GetQuotaStateRequest) Reset() {
+	*x = GetQuotaStateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetQuotaStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaStateRequest) ProtoMessage() {}
+
+func (x *GetQuotaStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaStateRequest.ProtoReflect.Descriptor instead.
+func (*GetQuotaStateRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetQuotaStateRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *GetQuotaStateRequest) GetUser() []string {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// QuotaState is the live state of a single quota.
+type QuotaState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name of the quota, e.g. "global/write", "trees/123/read" or
+	// "users/alice/write".
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Whether the quota is not limited by the quota system.
+	Unlimited bool `protobuf:"varint,2,opt,name=unlimited,proto3" json:"unlimited,omitempty"`
+	// Number of tokens currently available. Not set for unlimited quotas.
+	AvailableTokens int64 `protobuf:"varint,3,opt,name=available_tokens,json=availableTokens,proto3" json:"available_tokens,omitempty"`
+}
+
+func (x *QuotaState) Reset() {
+	*x = QuotaState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QuotaState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaState) ProtoMessage() {}
+
+func (x *QuotaState) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaState.ProtoReflect.Descriptor instead.
+func (*QuotaState) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *QuotaState) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *QuotaState) GetUnlimited() bool {
+	if x != nil {
+		return x.Unlimited
+	}
+	return false
+}
+
+func (x *QuotaState) GetAvailableTokens() int64 {
+	if x != nil {
+		return x.AvailableTokens
+	}
+	return 0
+}
+
+// GetQuotaState response.
+type GetQuotaStateResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// State of the global quotas, followed by the tree quotas and the user
+	// quotas, if requested. Read quotas come before write quotas.
+	Quotas []*QuotaState `protobuf:"bytes,1,rep,name=quotas,proto3" json:"quotas,omitempty"`
+}
+
+func (x *GetQuotaStateResponse) Reset() {
+	*x = GetQuotaStateResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetQuotaStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaStateResponse) ProtoMessage() {}
+
+func (x *GetQuotaStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaStateResponse.ProtoReflect.Descriptor instead.
+func (*GetQuotaStateResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetQuotaStateResponse) GetQuotas() []*QuotaState {
+	if x != nil {
+		return x.Quotas
+	}
+	return nil
+}
+
+// GetTreeStats request.
+type GetTreeStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID of the log whose statistics are returned.
+	TreeId int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+}
+
+func (x *GetTreeStatsRequest) Reset() {
+	*x = GetTreeStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTreeStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTreeStatsRequest) ProtoMessage() {}
+
+func (x *GetTreeStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTreeStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetTreeStatsRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetTreeStatsRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+// GetTreeStats response.
+type GetTreeStatsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of sequenced leaves.
+	LeafCount int64 `protobuf:"varint,1,opt,name=leaf_count,json=leafCount,proto3" json:"leaf_count,omitempty"`
+	// Total size in bytes of the values of all leaves stored for the log,
+	// whether sequenced, queued or quarantined.
+	LeafValueBytes int64 `protobuf:"varint,2,opt,name=leaf_value_bytes,json=leafValueBytes,proto3" json:"leaf_value_bytes,omitempty"`
+	// Total size in bytes of the extra data of all leaves stored for the log.
+	ExtraDataBytes int64 `protobuf:"varint,3,opt,name=extra_data_bytes,json=extraDataBytes,proto3" json:"extra_data_bytes,omitempty"`
+	// Number of queued leaves awaiting sequencing.
+	UnsequencedCount int64 `protobuf:"varint,4,opt,name=unsequenced_count,json=unsequencedCount,proto3" json:"unsequenced_count,omitempty"`
+}
+
+func (x *GetTreeStatsResponse) Reset() {
+	*x = GetTreeStatsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTreeStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTreeStatsResponse) ProtoMessage() {}
+
+func (x *GetTreeStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTreeStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetTreeStatsResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetTreeStatsResponse) GetLeafCount() int64 {
+	if x != nil {
+		return x.LeafCount
+	}
+	return 0
+}
+
+func (x *GetTreeStatsResponse) GetLeafValueBytes() int64 {
+	if x != nil {
+		return x.LeafValueBytes
+	}
+	return 0
+}
+
+func (x *GetTreeStatsResponse) GetExtraDataBytes() int64 {
+	if x != nil {
+		return x.ExtraDataBytes
+	}
+	return 0
+}
+
+func (x *GetTreeStatsResponse) GetUnsequencedCount() int64 {
+	if x != nil {
+		return x.UnsequencedCount
+	}
+	return 0
+}
+
+// GetTreeUsage request.
+type GetTreeUsageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID of the tree whose storage usage is returned.
+	TreeId int64 `protobuf:"varint,1,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+}
+
+func (x *GetTreeUsageRequest) Reset() {
+	*x = GetTreeUsageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTreeUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTreeUsageRequest) ProtoMessage() {}
+
+func (x *GetTreeUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTreeUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetTreeUsageRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetTreeUsageRequest) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+// GetTreeUsage response.
+type GetTreeUsageResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Total size in bytes of the leaves written for the tree, i.e. of the
+	// values and extra data of log leaves, or of the values of map leaves.
+	LeafBytes int64 `protobuf:"varint,1,opt,name=leaf_bytes,json=leafBytes,proto3" json:"leaf_bytes,omitempty"`
+	// Total size in bytes of the subtrees, or map tiles, written for the tree.
+	SubtreeBytes int64 `protobuf:"varint,2,opt,name=subtree_bytes,json=subtreeBytes,proto3" json:"subtree_bytes,omitempty"`
+	// The max_storage_bytes of the tree, or zero if it has no limit.
+	MaxStorageBytes int64 `protobuf:"varint,3,opt,name=max_storage_bytes,json=maxStorageBytes,proto3" json:"max_storage_bytes,omitempty"`
+}
+
+func (x *GetTreeUsageResponse) Reset() {
+	*x = GetTreeUsageResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTreeUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTreeUsageResponse) ProtoMessage() {}
+
+func (x *GetTreeUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTreeUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetTreeUsageResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetTreeUsageResponse) GetLeafBytes() int64 {
+	if x != nil {
+		return x.LeafBytes
+	}
+	return 0
+}
+
+func (x *GetTreeUsageResponse) GetSubtreeBytes() int64 {
+	if x != nil {
+		return x.SubtreeBytes
+	}
+	return 0
+}
+
+func (x *GetTreeUsageResponse) GetMaxStorageBytes() int64 {
+	if x != nil {
+		return x.MaxStorageBytes
+	}
+	return 0
+}
+
+// ApplyTreeSpec request.
+type ApplyTreeSpecRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Desired state of the tree.
+	// The tree is identified by tree_id if it's set, and otherwise by
+	// display_name, which must then be unique among the trees which aren't
+	// deleted. If there's no such tree, the tree is created, with the tree_id if
+	// it's set.
+	// Unset tree_state, tree_type, hash_strategy, hash_algorithm,
+	// signature_algorithm, private_key, storage_settings and public_key fields
+	// leave those of an existing tree unchanged, while other unset fields are
+	// cleared. Trees are created ACTIVE if tree_state is unset.
+	Tree *Tree `protobuf:"bytes,1,opt,name=tree,proto3" json:"tree,omitempty"`
+	// Describes how the tree's private key should be generated if the tree is
+	// created. Only needs to be set if tree.private_key is not set.
+	KeySpec *keyspb.Specification `protobuf:"bytes,2,opt,name=key_spec,json=keySpec,proto3" json:"key_spec,omitempty"`
+	// If true, the changes needed to reconcile the tree are returned, but not
+	// made.
+	DryRun bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+}
+
+func (x *ApplyTreeSpecRequest) Reset() {
+	*x = ApplyTreeSpecRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApplyTreeSpecRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyTreeSpecRequest) ProtoMessage() {}
+
+func (x *ApplyTreeSpecRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyTreeSpecRequest.ProtoReflect.Descriptor instead.
+func (*ApplyTreeSpecRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ApplyTreeSpecRequest) GetTree() *Tree {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
+func (x *ApplyTreeSpecRequest) GetKeySpec() *keyspb.Specification {
+	if x != nil {
+		return x.KeySpec
+	}
+	return nil
+}
+
+func (x *ApplyTreeSpecRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// TreeFieldChange is a change of a field of a tree made by ApplyTreeSpec.
+type TreeFieldChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name of the field of Tree, such as "display_name".
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// Value of the field before the change, in text format, or empty if it was
+	// unset. Values of private_key are never returned.
+	OldValue string `protobuf:"bytes,2,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
+	// Value of the field after the change, in text format, or empty if it's
+	// unset. Values of private_key are never returned.
+	NewValue string `protobuf:"bytes,3,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+}
+
+func (x *TreeFieldChange) Reset() {
+	*x = TreeFieldChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeFieldChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeFieldChange) ProtoMessage() {}
+
+func (x *TreeFieldChange) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeFieldChange.ProtoReflect.Descriptor instead.
+func (*TreeFieldChange) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *TreeFieldChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *TreeFieldChange) GetOldValue() string {
+	if x != nil {
+		return x.OldValue
+	}
+	return ""
+}
+
+func (x *TreeFieldChange) GetNewValue() string {
+	if x != nil {
+		return x.NewValue
+	}
+	return ""
+}
+
+// ApplyTreeSpec response.
+type ApplyTreeSpecResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The tree after the changes. If dry_run was set, the tree as it would be,
+	// without the fields assigned by storage if it would be created.
+	Tree *Tree `protobuf:"bytes,1,opt,name=tree,proto3" json:"tree,omitempty"`
+	// True if the tree was, or would be, created.
+	Created bool `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	// Changes made, or which would be made, to the tree, in order of the
+	// fields of Tree. Empty if the tree already matched the spec.
+	Changes []*TreeFieldChange `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`
+}
+
+func (x *ApplyTreeSpecResponse) Reset() {
+	*x = ApplyTreeSpecResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApplyTreeSpecResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyTreeSpecResponse) ProtoMessage() {}
+
+func (x *ApplyTreeSpecResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyTreeSpecResponse.ProtoReflect.Descriptor instead.
+func (*ApplyTreeSpecResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ApplyTreeSpecResponse) GetTree() *Tree {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
+func (x *ApplyTreeSpecResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+func (x *ApplyTreeSpecResponse) GetChanges() []*TreeFieldChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+// MoveMastership request.
+type MoveMastershipRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// IDs of the logs whose mastership is moved.
+	TreeIds []int64 `protobuf:"varint,1,rep,packed,name=tree_ids,json=treeIds,proto3" json:"tree_ids,omitempty"`
+	// ID of the signer instance which should be the master of the logs, as set
+	// by its --instance_id flag. If empty, the placement hints of the logs are
+	// cleared, and their mastership is left to elections.
+	InstanceId string `protobuf:"bytes,2,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
+}
+
+func (x *MoveMastershipRequest) Reset() {
+	*x = MoveMastershipRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MoveMastershipRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveMastershipRequest) ProtoMessage() {}
+
+func (x *MoveMastershipRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveMastershipRequest.ProtoReflect.Descriptor instead.
+func (*MoveMastershipRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *MoveMastershipRequest) GetTreeIds() []int64 {
+	if x != nil {
+		return x.TreeIds
+	}
+	return nil
+}
+
+func (x *MoveMastershipRequest) GetInstanceId() string {
+	if x != nil {
+		return x.InstanceId
+	}
+	return ""
+}
+
+// MoveMastership response.
+type MoveMastershipResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Signer instances which the placement hints of the logs named before the
+	// move, by tree ID. Logs which had no hint are omitted.
+	PreviousInstanceIds map[int64]string `protobuf:"bytes,1,rep,name=previous_instance_ids,json=previousInstanceIds,proto3" json:"previous_instance_ids,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *MoveMastershipResponse) Reset() {
+	*x = MoveMastershipResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MoveMastershipResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveMastershipResponse) ProtoMessage() {}
+
+func (x *MoveMastershipResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveMastershipResponse.ProtoReflect.Descriptor instead.
+func (*MoveMastershipResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *MoveMastershipResponse) GetPreviousInstanceIds() map[int64]string {
+	if x != nil {
+		return x.PreviousInstanceIds
+	}
+	return nil
+}
+
+// SetMaintenanceMode request.
+type SetMaintenanceModeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// IDs of the trees put into or taken out of maintenance. If empty, the whole
+	// server which receives the request is, until it restarts.
+	TreeIds []int64 `protobuf:"varint,1,rep,packed,name=tree_ids,json=treeIds,proto3" json:"tree_ids,omitempty"`
+	// If true, the trees or server are put into maintenance, and otherwise
+	// taken out of it.
+	Enabled bool `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// Why writes are refused, which is returned to the clients whose writes
+	// fail. Required if enabled is set.
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *SetMaintenanceModeRequest) Reset() {
+	*x = SetMaintenanceModeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetMaintenanceModeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMaintenanceModeRequest) ProtoMessage() {}
+
+func (x *SetMaintenanceModeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMaintenanceModeRequest.ProtoReflect.Descriptor instead.
+func (*SetMaintenanceModeRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SetMaintenanceModeRequest) GetTreeIds() []int64 {
+	if x != nil {
+		return x.TreeIds
+	}
+	return nil
+}
+
+func (x *SetMaintenanceModeRequest) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *SetMaintenanceModeRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// SetMaintenanceMode response.
+type SetMaintenanceModeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The trees after the change, in the order of the request.
+	Trees []*Tree `protobuf:"bytes,1,rep,name=trees,proto3" json:"trees,omitempty"`
+	// Maintenance of the server which received the request, if it's in
+	// maintenance.
+	ServerMaintenance *TreeMaintenance `protobuf:"bytes,2,opt,name=server_maintenance,json=serverMaintenance,proto3" json:"server_maintenance,omitempty"`
+}
+
+func (x *SetMaintenanceModeResponse) Reset() {
+	*x = SetMaintenanceModeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetMaintenanceModeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMaintenanceModeResponse) ProtoMessage() {}
+
+func (x *SetMaintenanceModeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMaintenanceModeResponse.ProtoReflect.Descriptor instead.
+func (*SetMaintenanceModeResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SetMaintenanceModeResponse) GetTrees() []*Tree {
+	if x != nil {
+		return x.Trees
+	}
+	return nil
+}
+
+func (x *SetMaintenanceModeResponse) GetServerMaintenance() *TreeMaintenance {
+	if x != nil {
+		return x.ServerMaintenance
+	}
+	return nil
+}
+
+// FeatureFlag overrides whether a feature is enabled, for a tree or for all
+// trees.
+type FeatureFlag struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name of the feature, such as "shared_subtree_cache".
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// ID of the tree the override applies to, or 0 for all trees. Overrides
+	// for a tree take precedence over those for all trees.
+	TreeId int64 `protobuf:"varint,2,opt,name=tree_id,json=treeId,proto3" json:"tree_id,omitempty"`
+	// Whether the feature is enabled.
+	Enabled bool `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+}
+
+func (x *FeatureFlag) Reset() {
+	*x = FeatureFlag{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FeatureFlag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeatureFlag) ProtoMessage() {}
+
+func (x *FeatureFlag) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeatureFlag.ProtoReflect.Descriptor instead.
+func (*FeatureFlag) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *FeatureFlag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FeatureFlag) GetTreeId() int64 {
+	if x != nil {
+		return x.TreeId
+	}
+	return 0
+}
+
+func (x *FeatureFlag) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+// SetFeatureFlags request.
+type SetFeatureFlagsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Overrides to set, replacing any others of the same feature and tree.
+	Set []*FeatureFlag `protobuf:"bytes,1,rep,name=set,proto3" json:"set,omitempty"`
+	// Overrides to remove, whose enabled fields are ignored. Removals are done
+	// before the overrides of set are.
+	Clear []*FeatureFlag `protobuf:"bytes,2,rep,name=clear,proto3" json:"clear,omitempty"`
+}
+
+func (x *SetFeatureFlagsRequest) Reset() {
+	*x = SetFeatureFlagsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetFeatureFlagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFeatureFlagsRequest) ProtoMessage() {}
+
+func (x *SetFeatureFlagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFeatureFlagsRequest.ProtoReflect.Descriptor instead.
+func (*SetFeatureFlagsRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SetFeatureFlagsRequest) GetSet() []*FeatureFlag {
+	if x != nil {
+		return x.Set
+	}
+	return nil
+}
+
+func (x *SetFeatureFlagsRequest) GetClear() []*FeatureFlag {
+	if x != nil {
+		return x.Clear
+	}
+	return nil
+}
+
+// SetFeatureFlags response.
+type SetFeatureFlagsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// All of the overrides of the server after the change.
+	Flags []*FeatureFlag `protobuf:"bytes,1,rep,name=flags,proto3" json:"flags,omitempty"`
+	// The features known to the server, with whether each is enabled by
+	// default, keyed by feature name.
+	Defaults map[string]bool `protobuf:"bytes,2,rep,name=defaults,proto3" json:"defaults,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *SetFeatureFlagsResponse) Reset() {
+	*x = SetFeatureFlagsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_admin_api_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetFeatureFlagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFeatureFlagsResponse) ProtoMessage() {}
+
+func (x *SetFeatureFlagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_admin_api_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFeatureFlagsResponse.ProtoReflect.Descriptor instead.
+func (*SetFeatureFlagsResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_admin_api_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SetFeatureFlagsResponse) GetFlags() []*FeatureFlag {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+func (x *SetFeatureFlagsResponse) GetDefaults() map[string]bool {
+	if x != nil {
+		return x.Defaults
+	}
+	return nil
+}
+
+var File_trillian_admin_api_proto protoreflect.FileDescriptor
+
+var file_trillian_admin_api_proto_rawDesc = []byte{
+	0x0a, 0x18, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x5f, 0x61, 0x64, 0x6d, 0x69, 0x6e,
+	0x5f, 0x61, 0x70, 0x69, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x1a, 0x0e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x16, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x5f, 0x6c,
+	0x6f, 0x67, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1a, 0x63, 0x72,
+	0x79, 0x70, 0x74, 0x6f, 0x2f, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x62, 0x2f, 0x6b, 0x65, 0x79, 0x73,
+	0x70, 0x62, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x6d, 0x61,
+	0x73, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x35, 0x0a, 0x10, 0x4c, 0x69, 0x73,
+	0x74, 0x54, 0x72, 0x65, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a,
+	0x0c, 0x73, 0x68, 0x6f, 0x77, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0b, 0x73, 0x68, 0x6f, 0x77, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64,
+	0x22, 0x37, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x65, 0x65, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x04, 0x74, 0x72, 0x65, 0x65, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54,
+	0x72, 0x65, 0x65, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65, 0x22, 0x29, 0x0a, 0x0e, 0x47, 0x65, 0x74,
+	0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x74,
+	0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72,
+	0x65, 0x65, 0x49, 0x64, 0x22, 0xc3, 0x01, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54,
+	0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x04, 0x74, 0x72,
+	0x65, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65, 0x12, 0x30,
+	0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x73, 0x70, 0x65, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x62, 0x2e, 0x53, 0x70, 0x65, 0x63, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x53, 0x70, 0x65, 0x63,
+	0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12,
+	0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x12, 0x20, 0x0a, 0x0c, 0x74, 0x72, 0x65, 0x65,
+	0x5f, 0x69, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x74, 0x0a, 0x11, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x22, 0x0a, 0x04, 0x74, 0x72, 0x65, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x52, 0x04, 0x74,
+	0x72, 0x65, 0x65, 0x12, 0x3b, 0x0a, 0x0b, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x6d, 0x61,
+	0x73, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64,
+	0x4d, 0x61, 0x73, 0x6b, 0x52, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x61, 0x73, 0x6b,
+	0x22, 0x2c, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x22, 0x2e,
+	0x0a, 0x13, 0x55, 0x6e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x22, 0x9e,
+	0x01, 0x0a, 0x0e, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65, 0x61,
+	0x66, 0x12, 0x25, 0x0a, 0x04, 0x6c, 0x65, 0x61, 0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4c, 0x6f, 0x67, 0x4c, 0x65,
+	0x61, 0x66, 0x52, 0x04, 0x6c, 0x65, 0x61, 0x66, 0x12, 0x4d, 0x0a, 0x14, 0x71, 0x75, 0x61, 0x72,
+	0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x13, 0x71, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22,
+	0x55, 0x0a, 0x1b, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65,
+	0x72, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17,
+	0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x6c,
+	0x65, 0x61, 0x76, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6d, 0x61, 0x78,
+	0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x22, 0x50, 0x0a, 0x1c, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65,
+	0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61,
+	0x6e, 0x2e, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65, 0x61, 0x66,
+	0x52, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x22, 0x67, 0x0a, 0x1e, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x75, 0x65, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65, 0x61,
+	0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72,
+	0x65, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65,
+	0x65, 0x49, 0x64, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x69, 0x64, 0x65, 0x6e,
+	0x74, 0x69, 0x74, 0x79, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52,
+	0x10, 0x6c, 0x65, 0x61, 0x66, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x48, 0x61, 0x73,
+	0x68, 0x22, 0x3d, 0x0a, 0x1f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x61, 0x64,
+	0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x64,
+	0x22, 0x65, 0x0a, 0x1c, 0x50, 0x75, 0x72, 0x67, 0x65, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74,
+	0x74, 0x65, 0x72, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x65, 0x61,
+	0x66, 0x5f, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x10, 0x6c, 0x65, 0x61, 0x66, 0x49, 0x64, 0x65, 0x6e, 0x74,
+	0x69, 0x74, 0x79, 0x48, 0x61, 0x73, 0x68, 0x22, 0x37, 0x0a, 0x1d, 0x50, 0x75, 0x72, 0x67, 0x65,
+	0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x75, 0x72, 0x67,
+	0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x70, 0x75, 0x72, 0x67, 0x65, 0x64,
+	0x22, 0x43, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x04, 0x75, 0x73, 0x65, 0x72, 0x22, 0x69, 0x0a, 0x0a, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x75, 0x6e, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x75, 0x6e, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62,
+	0x6c, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0f, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73,
+	0x22, 0x45, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x06, 0x71, 0x75, 0x6f,
+	0x74, 0x61, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x06, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x73, 0x22, 0x2e, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x54, 0x72,
+	0x65, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17,
+	0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x22, 0xb6, 0x01, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x54,
+	0x72, 0x65, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x65, 0x61, 0x66, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12,
+	0x28, 0x0a, 0x10, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x6c, 0x65, 0x61, 0x66, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x65, 0x78, 0x74,
+	0x72, 0x61, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0e, 0x65, 0x78, 0x74, 0x72, 0x61, 0x44, 0x61, 0x74, 0x61, 0x42, 0x79,
+	0x74, 0x65, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x75, 0x6e, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63,
+	0x65, 0x64, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10,
+	0x75, 0x6e, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x64, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x22, 0x2e, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x54, 0x72, 0x65, 0x65, 0x55, 0x73, 0x61, 0x67, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64,
+	0x22, 0x86, 0x01, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x54, 0x72, 0x65, 0x65, 0x55, 0x73, 0x61, 0x67,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x65, 0x61,
+	0x66, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c,
+	0x65, 0x61, 0x66, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x75, 0x62, 0x74,
+	0x72, 0x65, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0c, 0x73, 0x75, 0x62, 0x74, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x2a, 0x0a,
+	0x11, 0x6d, 0x61, 0x78, 0x5f, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x6d, 0x61, 0x78, 0x53, 0x74, 0x6f,
+	0x72, 0x61, 0x67, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x85, 0x01, 0x0a, 0x14, 0x41, 0x70,
+	0x70, 0x6c, 0x79, 0x54, 0x72, 0x65, 0x65, 0x53, 0x70, 0x65, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x22, 0x0a, 0x04, 0x74, 0x72, 0x65, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65,
+	0x52, 0x04, 0x74, 0x72, 0x65, 0x65, 0x12, 0x30, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x73, 0x70,
+	0x65, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x6b, 0x65, 0x79, 0x73, 0x70,
+	0x62, 0x2e, 0x53, 0x70, 0x65, 0x63, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x07, 0x6b, 0x65, 0x79, 0x53, 0x70, 0x65, 0x63, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f,
+	0x72, 0x75, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75,
+	0x6e, 0x22, 0x61, 0x0a, 0x0f, 0x54, 0x72, 0x65, 0x65, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6f, 0x6c,
+	0x64, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6f,
+	0x6c, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x77, 0x5f, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x65, 0x77, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x22, 0x8a, 0x01, 0x0a, 0x15, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x54, 0x72,
+	0x65, 0x65, 0x53, 0x70, 0x65, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22,
+	0x0a, 0x04, 0x74, 0x72, 0x65, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x74,
+	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x52, 0x04, 0x74, 0x72,
+	0x65, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x12, 0x33, 0x0a, 0x07,
+	0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x46, 0x69, 0x65,
+	0x6c, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x73, 0x22, 0x53, 0x0a, 0x15, 0x4d, 0x6f, 0x76, 0x65, 0x4d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x73,
+	0x68, 0x69, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x72,
+	0x65, 0x65, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03, 0x52, 0x07, 0x74, 0x72,
+	0x65, 0x65, 0x49, 0x64, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x6e, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x49, 0x64, 0x22, 0xcf, 0x01, 0x0a, 0x16, 0x4d, 0x6f, 0x76, 0x65, 0x4d,
+	0x61, 0x73, 0x74, 0x65, 0x72, 0x73, 0x68, 0x69, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x6d, 0x0a, 0x15, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x69, 0x6e,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x39, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x6f, 0x76, 0x65,
+	0x4d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x73, 0x68, 0x69, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x2e, 0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x49, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x49, 0x64, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x13, 0x70, 0x72, 0x65,
+	0x76, 0x69, 0x6f, 0x75, 0x73, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x64, 0x73,
+	0x1a, 0x46, 0x0a, 0x18, 0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x49, 0x6e, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x49, 0x64, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x68, 0x0a, 0x19, 0x53, 0x65, 0x74, 0x4d,
+	0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03, 0x52, 0x07, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x73,
+	0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x22, 0x8c, 0x01, 0x0a, 0x1a, 0x53, 0x65, 0x74, 0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65,
+	0x6e, 0x61, 0x6e, 0x63, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x24, 0x0a, 0x05, 0x74, 0x72, 0x65, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65,
+	0x52, 0x05, 0x74, 0x72, 0x65, 0x65, 0x73, 0x12, 0x48, 0x0a, 0x12, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x5f, 0x6d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54,
+	0x72, 0x65, 0x65, 0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x11,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63,
+	0x65, 0x22, 0x54, 0x0a, 0x0b, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x46, 0x6c, 0x61, 0x67,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x12, 0x18, 0x0a,
+	0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x22, 0x6e, 0x0a, 0x16, 0x53, 0x65, 0x74, 0x46, 0x65,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x27, 0x0a, 0x03, 0x73, 0x65, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15,
+	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x46, 0x6c, 0x61, 0x67, 0x52, 0x03, 0x73, 0x65, 0x74, 0x12, 0x2b, 0x0a, 0x05, 0x63, 0x6c,
+	0x65, 0x61, 0x72, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x46, 0x6c, 0x61, 0x67,
+	0x52, 0x05, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x22, 0xd0, 0x01, 0x0a, 0x17, 0x53, 0x65, 0x74, 0x46,
+	0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x46, 0x65,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73,
+	0x12, 0x4b, 0x0a, 0x08, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x65,
+	0x74, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x08, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x1a, 0x3b, 0x0a,
+	0x0d, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x32, 0x8e, 0x0f, 0x0a, 0x0d, 0x54,
+	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x46, 0x0a, 0x09,
+	0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x65, 0x65, 0x73, 0x12, 0x1a, 0x2e, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x65, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
+	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x65, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x57, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x54, 0x72, 0x65, 0x65, 0x12,
+	0x18, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x72,
+	0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x1c, 0x12, 0x1a, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65,
+	0x73, 0x2f, 0x7b, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x3d, 0x2a, 0x7d, 0x12, 0x54, 0x0a,
+	0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x12, 0x1b, 0x2e, 0x74, 0x72,
+	0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x72, 0x65,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x22, 0x19, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x13,
+	0x22, 0x0e, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73,
+	0x3a, 0x01, 0x2a, 0x12, 0x65, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x72, 0x65,
+	0x65, 0x12, 0x1b, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e,
+	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x22, 0x2a,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x24, 0x32, 0x1f, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31,
+	0x2f, 0x74, 0x72, 0x65, 0x65, 0x73, 0x2f, 0x7b, 0x74, 0x72, 0x65, 0x65, 0x2e, 0x74, 0x72, 0x65,
+	0x65, 0x5f, 0x69, 0x64, 0x3d, 0x2a, 0x7d, 0x3a, 0x01, 0x2a, 0x12, 0x5d, 0x0a, 0x0a, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x12, 0x1b, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
+	0x2e, 0x54, 0x72, 0x65, 0x65, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x2a, 0x1a, 0x2f,
+	0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73, 0x2f, 0x7b, 0x74,
+	0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x3d, 0x2a, 0x7d, 0x12, 0x6a, 0x0a, 0x0c, 0x55, 0x6e, 0x64,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65, 0x65, 0x12, 0x1d, 0x2e, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x55, 0x6e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x72, 0x65,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x22, 0x2b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x25,
+	0x2a, 0x23, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73,
+	0x2f, 0x7b, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x3d, 0x2a, 0x7d, 0x3a, 0x75, 0x6e, 0x64,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x95, 0x01, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65,
+	0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x12, 0x25,
+	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65,
+	0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
+	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c,
+	0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2e, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x28, 0x12, 0x26, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f,
+	0x74, 0x72, 0x65, 0x65, 0x73, 0x2f, 0x7b, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x3d, 0x2a,
+	0x7d, 0x2f, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x73, 0x12, 0xa9, 0x01,
+	0x0a, 0x17, 0x52, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74,
+	0x74, 0x65, 0x72, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x12, 0x28, 0x2e, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x61, 0x64,
+	0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72,
+	0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x39,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x33, 0x22, 0x2e, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31,
+	0x2f, 0x74, 0x72, 0x65, 0x65, 0x73, 0x2f, 0x7b, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x3d,
+	0x2a, 0x7d, 0x2f, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x73, 0x3a, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x3a, 0x01, 0x2a, 0x12, 0xa1, 0x01, 0x0a, 0x15, 0x50, 0x75,
+	0x72, 0x67, 0x65, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65, 0x61,
+	0x76, 0x65, 0x73, 0x12, 0x26, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x50,
+	0x75, 0x72, 0x67, 0x65, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65,
+	0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x74, 0x72,
+	0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x50, 0x75, 0x72, 0x67, 0x65, 0x44, 0x65, 0x61, 0x64,
+	0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x37, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x31, 0x22, 0x2c, 0x2f, 0x76,
+	0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73, 0x2f, 0x7b, 0x74, 0x72,
+	0x65, 0x65, 0x5f, 0x69, 0x64, 0x3d, 0x2a, 0x7d, 0x2f, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x65, 0x74,
+	0x74, 0x65, 0x72, 0x73, 0x3a, 0x70, 0x75, 0x72, 0x67, 0x65, 0x3a, 0x01, 0x2a, 0x12, 0x7a, 0x0a,
+	0x0d, 0x47, 0x65, 0x74, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1e,
+	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x51, 0x75, 0x6f,
+	0x74, 0x61, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
+	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x51, 0x75, 0x6f,
+	0x74, 0x61, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x28, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x22, 0x12, 0x20, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61,
+	0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73, 0x2f, 0x7b, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64,
+	0x3d, 0x2a, 0x7d, 0x2f, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x77, 0x0a, 0x0c, 0x47, 0x65, 0x74,
+	0x54, 0x72, 0x65, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1d, 0x2e, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x72, 0x65, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x72, 0x65, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x28, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x22,
+	0x12, 0x20, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73,
+	0x2f, 0x7b, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64, 0x3d, 0x2a, 0x7d, 0x2f, 0x73, 0x74, 0x61,
+	0x74, 0x73, 0x12, 0x77, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x54, 0x72, 0x65, 0x65, 0x55, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x1d, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65,
+	0x74, 0x54, 0x72, 0x65, 0x65, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74,
+	0x54, 0x72, 0x65, 0x65, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x28, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x22, 0x12, 0x20, 0x2f, 0x76, 0x31, 0x62, 0x65,
+	0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73, 0x2f, 0x7b, 0x74, 0x72, 0x65, 0x65, 0x5f,
+	0x69, 0x64, 0x3d, 0x2a, 0x7d, 0x2f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x12, 0x71, 0x0a, 0x0d, 0x41,
+	0x70, 0x70, 0x6c, 0x79, 0x54, 0x72, 0x65, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12, 0x1e, 0x2e, 0x74,
+	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x54, 0x72, 0x65,
+	0x65, 0x53, 0x70, 0x65, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x74,
+	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x54, 0x72, 0x65,
+	0x65, 0x53, 0x70, 0x65, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1f, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x19, 0x22, 0x14, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f,
+	0x74, 0x72, 0x65, 0x65, 0x73, 0x3a, 0x61, 0x70, 0x70, 0x6c, 0x79, 0x3a, 0x01, 0x2a, 0x12, 0x7d,
+	0x0a, 0x0e, 0x4d, 0x6f, 0x76, 0x65, 0x4d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x73, 0x68, 0x69, 0x70,
+	0x12, 0x1f, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x6f, 0x76, 0x65,
+	0x4d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x73, 0x68, 0x69, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x20, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x6f, 0x76,
+	0x65, 0x4d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x73, 0x68, 0x69, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x28, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x22, 0x22, 0x1d, 0x2f, 0x76, 0x31,
+	0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73, 0x3a, 0x6d, 0x6f, 0x76, 0x65,
+	0x4d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x73, 0x68, 0x69, 0x70, 0x3a, 0x01, 0x2a, 0x12, 0x8d, 0x01,
+	0x0a, 0x12, 0x53, 0x65, 0x74, 0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65,
+	0x4d, 0x6f, 0x64, 0x65, 0x12, 0x23, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e,
+	0x53, 0x65, 0x74, 0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x4d, 0x6f,
+	0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x65, 0x74, 0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61,
+	0x6e, 0x63, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x2c, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x26, 0x22, 0x21, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61,
+	0x31, 0x2f, 0x74, 0x72, 0x65, 0x65, 0x73, 0x3a, 0x73, 0x65, 0x74, 0x4d, 0x61, 0x69, 0x6e, 0x74,
+	0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x3a, 0x01, 0x2a, 0x12, 0x7c, 0x0a,
+	0x0f, 0x53, 0x65, 0x74, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73,
+	0x12, 0x20, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x65, 0x74, 0x46,
+	0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x21, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x65,
+	0x74, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x22, 0x19, 0x2f,
+	0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x46,
+	0x6c, 0x61, 0x67, 0x73, 0x3a, 0x73, 0x65, 0x74, 0x3a, 0x01, 0x2a, 0x42, 0x50, 0x0a, 0x19, 0x63,
+	0x6f, 0x6d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69,
+	0x61, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x42, 0x15, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69,
+	0x61, 0x6e, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x41, 0x70, 0x69, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50,
+	0x01, 0x5a, 0x1a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_trillian_admin_api_proto_rawDescOnce sync.Once
+	file_trillian_admin_api_proto_rawDescData = file_trillian_admin_api_proto_rawDesc
+)
+
+func file_trillian_admin_api_proto_rawDescGZIP() []byte {
+	file_trillian_admin_api_proto_rawDescOnce.Do(func() {
+		file_trillian_admin_api_proto_rawDescData = protoimpl.X.CompressGZIP(file_trillian_admin_api_proto_rawDescData)
+	})
+	return file_trillian_admin_api_proto_rawDescData
+}
+
+var file_trillian_admin_api_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
+var file_trillian_admin_api_proto_goTypes = []interface{}{
+	(*ListTreesRequest)(nil),                // 0: trillian.ListTreesRequest
+	(*ListTreesResponse)(nil),               // 1: trillian.ListTreesResponse
+	(*GetTreeRequest)(nil),                  // 2: trillian.GetTreeRequest
+	(*CreateTreeRequest)(nil),               // 3: trillian.CreateTreeRequest
+	(*UpdateTreeRequest)(nil),               // 4: trillian.UpdateTreeRequest
+	(*DeleteTreeRequest)(nil),               // 5: trillian.DeleteTreeRequest
+	(*UndeleteTreeRequest)(nil),             // 6: trillian.UndeleteTreeRequest
+	(*DeadLetterLeaf)(nil),                  // 7: trillian.DeadLetterLeaf
+	(*ListDeadLetterLeavesRequest)(nil),     // 8: trillian.ListDeadLetterLeavesRequest
+	(*ListDeadLetterLeavesResponse)(nil),    // 9: trillian.ListDeadLetterLeavesResponse
+	(*RequeueDeadLetterLeavesRequest)(nil),  // 10: trillian.RequeueDeadLetterLeavesRequest
+	(*RequeueDeadLetterLeavesResponse)(nil), // 11: trillian.RequeueDeadLetterLeavesResponse
+	(*PurgeDeadLetterLeavesRequest)(nil),    // 12: trillian.PurgeDeadLetterLeavesRequest
+	(*PurgeDeadLetterLeavesResponse)(nil),   // 13: trillian.PurgeDeadLetterLeavesResponse
+	(*GetQuotaStateRequest)(nil),            // 14: trillian.GetQuotaStateRequest
+	(*QuotaState)(nil),                      // 15: trillian.QuotaState
+	(*GetQuotaStateResponse)(nil),           // 16: trillian.GetQuotaStateResponse
+	(*GetTreeStatsRequest)(nil),             // 17: trillian.GetTreeStatsRequest
+	(*GetTreeStatsResponse)(nil),            // 18: trillian.GetTreeStatsResponse
+	(*GetTreeUsageRequest)(nil),             // 19: trillian.GetTreeUsageRequest
+	(*GetTreeUsageResponse)(nil),            // 20: trillian.GetTreeUsageResponse
+	(*ApplyTreeSpecRequest)(nil),            // 21: trillian.ApplyTreeSpecRequest
+	(*TreeFieldChange)(nil),                 // 22: trillian.TreeFieldChange
+	(*ApplyTreeSpecResponse)(nil),           // 23: trillian.ApplyTreeSpecResponse
+	(*MoveMastershipRequest)(nil),           // 24: trillian.MoveMastershipRequest
+	(*MoveMastershipResponse)(nil),          // 25: trillian.MoveMastershipResponse
+	(*SetMaintenanceModeRequest)(nil),       // 26: trillian.SetMaintenanceModeRequest
+	(*SetMaintenanceModeResponse)(nil),      // 27: trillian.SetMaintenanceModeResponse
+	(*FeatureFlag)(nil),                     // 28: trillian.FeatureFlag
+	(*SetFeatureFlagsRequest)(nil),          // 29: trillian.SetFeatureFlagsRequest
+	(*SetFeatureFlagsResponse)(nil),         // 30: trillian.SetFeatureFlagsResponse
+	nil,                                     // 31: trillian.MoveMastershipResponse.PreviousInstanceIdsEntry
+	nil,                                     // 32: trillian.SetFeatureFlagsResponse.DefaultsEntry
+	(*Tree)(nil),                            // 33: trillian.Tree
+	(*keyspb.Specification)(nil),            // 34: keyspb.Specification
+	(*field_mask.FieldMask)(nil),            // 35: google.protobuf.FieldMask
+	(*LogLeaf)(nil),                         // 36: trillian.LogLeaf
+	(*timestamp.Timestamp)(nil),             // 37: google.protobuf.Timestamp
+	(*TreeMaintenance)(nil),                 // 38: trillian.TreeMaintenance
+}
+var file_trillian_admin_api_proto_depIdxs = []int32{
+	33, // 0: trillian.ListTreesResponse.tree:type_name -> trillian.Tree
+	33, // 1: trillian.CreateTreeRequest.tree:type_name -> trillian.Tree
+	34, // 2: trillian.CreateTreeRequest.key_spec:type_name -> keyspb.Specification
+	33, // 3: trillian.UpdateTreeRequest.tree:type_name -> trillian.Tree
+	35, // 4: trillian.UpdateTreeRequest.update_mask:type_name -> google.protobuf.FieldMask
+	36, // 5: trillian.DeadLetterLeaf.leaf:type_name -> trillian.LogLeaf
+	37, // 6: trillian.DeadLetterLeaf.quarantine_timestamp:type_name -> google.protobuf.Timestamp
+	7,  // 7: trillian.ListDeadLetterLeavesResponse.leaves:type_name -> trillian.DeadLetterLeaf
+	15, // 8: trillian.GetQuotaStateResponse.quotas:type_name -> trillian.QuotaState
+	33, // 9: trillian.ApplyTreeSpecRequest.tree:type_name -> trillian.Tree
+	34, // 10: trillian.ApplyTreeSpecRequest.key_spec:type_name -> keyspb.Specification
+	33, // 11: trillian.ApplyTreeSpecResponse.tree:type_name -> trillian.Tree
+	22, // 12: trillian.ApplyTreeSpecResponse.changes:type_name -> trillian.TreeFieldChange
+	31, // 13: trillian.MoveMastershipResponse.previous_instance_ids:type_name -> trillian.MoveMastershipResponse.PreviousInstanceIdsEntry
+	33, // 14: trillian.SetMaintenanceModeResponse.trees:type_name -> trillian.Tree
+	38, // 15: trillian.SetMaintenanceModeResponse.server_maintenance:type_name -> trillian.TreeMaintenance
+	28, // 16: trillian.SetFeatureFlagsRequest.set:type_name -> trillian.FeatureFlag
+	28, // 17: trillian.SetFeatureFlagsRequest.clear:type_name -> trillian.FeatureFlag
+	28, // 18: trillian.SetFeatureFlagsResponse.flags:type_name -> trillian.FeatureFlag
+	32, // 19: trillian.SetFeatureFlagsResponse.defaults:type_name -> trillian.SetFeatureFlagsResponse.DefaultsEntry
+	0,  // 20: trillian.TrillianAdmin.ListTrees:input_type -> trillian.ListTreesRequest
+	2,  // 21: trillian.TrillianAdmin.GetTree:input_type -> trillian.GetTreeRequest
+	3,  // 22: trillian.TrillianAdmin.CreateTree:input_type -> trillian.CreateTreeRequest
+	4,  // 23: trillian.TrillianAdmin.UpdateTree:input_type -> trillian.UpdateTreeRequest
+	5,  // 24: trillian.TrillianAdmin.DeleteTree:input_type -> trillian.DeleteTreeRequest
+	6,  // 25: trillian.TrillianAdmin.UndeleteTree:input_type -> trillian.UndeleteTreeRequest
+	8,  // 26: trillian.TrillianAdmin.ListDeadLetterLeaves:input_type -> trillian.ListDeadLetterLeavesRequest
+	10, // 27: trillian.TrillianAdmin.RequeueDeadLetterLeaves:input_type -> trillian.RequeueDeadLetterLeavesRequest
+	12, // 28: trillian.TrillianAdmin.PurgeDeadLetterLeaves:input_type -> trillian.PurgeDeadLetterLeavesRequest
+	14, // 29: trillian.TrillianAdmin.GetQuotaState:input_type -> trillian.GetQuotaStateRequest
+	17, // 30: trillian.TrillianAdmin.GetTreeStats:input_type -> trillian.GetTreeStatsRequest
+	19, // 31: trillian.TrillianAdmin.GetTreeUsage:input_type -> trillian.GetTreeUsageRequest
+	21, // 32: trillian.TrillianAdmin.ApplyTreeSpec:input_type -> trillian.ApplyTreeSpecRequest
+	24, // 33: trillian.TrillianAdmin.MoveMastership:input_type -> trillian.MoveMastershipRequest
+	26, // 34: trillian.TrillianAdmin.SetMaintenanceMode:input_type -> trillian.SetMaintenanceModeRequest
+	29, // 35: trillian.TrillianAdmin.SetFeatureFlags:input_type -> trillian.SetFeatureFlagsRequest
+	1,  // 36: trillian.TrillianAdmin.ListTrees:output_type -> trillian.ListTreesResponse
+	33, // 37: trillian.TrillianAdmin.GetTree:output_type -> trillian.Tree
+	33, // 38: trillian.TrillianAdmin.CreateTree:output_type -> trillian.Tree
+	33, // 39: trillian.TrillianAdmin.UpdateTree:output_type -> trillian.Tree
+	33, // 40: trillian.TrillianAdmin.DeleteTree:output_type -> trillian.Tree
+	33, // 41: trillian.TrillianAdmin.UndeleteTree:output_type -> trillian.Tree
+	9,  // 42: trillian.TrillianAdmin.ListDeadLetterLeaves:output_type -> trillian.ListDeadLetterLeavesResponse
+	11, // 43: trillian.TrillianAdmin.RequeueDeadLetterLeaves:output_type -> trillian.RequeueDeadLetterLeavesResponse
+	13, // 44: trillian.TrillianAdmin.PurgeDeadLetterLeaves:output_type -> trillian.PurgeDeadLetterLeavesResponse
+	16, // 45: trillian.TrillianAdmin.GetQuotaState:output_type -> trillian.GetQuotaStateResponse
+	18, // 46: trillian.TrillianAdmin.GetTreeStats:output_type -> trillian.GetTreeStatsResponse
+	20, // 47: trillian.TrillianAdmin.GetTreeUsage:output_type -> trillian.GetTreeUsageResponse
+	23, // 48: trillian.TrillianAdmin.ApplyTreeSpec:output_type -> trillian.ApplyTreeSpecResponse
+	25, // 49: trillian.TrillianAdmin.MoveMastership:output_type -> trillian.MoveMastershipResponse
+	27, // 50: trillian.TrillianAdmin.SetMaintenanceMode:output_type -> trillian.SetMaintenanceModeResponse
+	30, // 51: trillian.TrillianAdmin.SetFeatureFlags:output_type -> trillian.SetFeatureFlagsResponse
+	36, // [36:52] is the sub-list for method output_type
+	20, // [20:36] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_trillian_admin_api_proto_init() }
+func file_trillian_admin_api_proto_init() {
+	if File_trillian_admin_api_proto != nil {
+		return
+	}
+	file_trillian_proto_init()
+	file_trillian_log_api_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_trillian_admin_api_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListTreesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListTreesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTreeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateTreeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateTreeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteTreeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UndeleteTreeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeadLetterLeaf); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDeadLetterLeavesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDeadLetterLeavesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequeueDeadLetterLeavesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequeueDeadLetterLeavesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PurgeDeadLetterLeavesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PurgeDeadLetterLeavesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetQuotaStateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QuotaState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetQuotaStateResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTreeStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTreeStatsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTreeUsageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTreeUsageResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApplyTreeSpecRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TreeFieldChange); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -566,8 +2653,8 @@ func file_trillian_admin_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_admin_api_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListTreesResponse); i {
+		file_trillian_admin_api_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApplyTreeSpecResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -578,8 +2665,8 @@ func file_trillian_admin_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_admin_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetTreeRequest); i {
+		file_trillian_admin_api_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MoveMastershipRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -590,8 +2677,8 @@ func file_trillian_admin_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_admin_api_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateTreeRequest); i {
+		file_trillian_admin_api_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MoveMastershipResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -602,8 +2689,8 @@ func file_trillian_admin_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_admin_api_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateTreeRequest); i {
+		file_trillian_admin_api_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetMaintenanceModeRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -614,8 +2701,8 @@ func file_trillian_admin_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_admin_api_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeleteTreeRequest); i {
+		file_trillian_admin_api_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetMaintenanceModeResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -626,8 +2713,32 @@ func file_trillian_admin_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_admin_api_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UndeleteTreeRequest); i {
+		file_trillian_admin_api_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FeatureFlag); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetFeatureFlagsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_admin_api_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetFeatureFlagsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -645,7 +2756,7 @@ func file_trillian_admin_api_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_trillian_admin_api_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   33,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -691,6 +2802,50 @@ type TrillianAdminClient interface {
 	// A soft-deleted tree may be undeleted for a certain period, after which
 	// it'll be permanently deleted.
 	UndeleteTree(ctx context.Context, in *UndeleteTreeRequest, opts ...grpc.CallOption) (*Tree, error)
+	// Lists leaves of a log which were quarantined by the sequencer because
+	// they could not be integrated.
+	ListDeadLetterLeaves(ctx context.Context, in *ListDeadLetterLeavesRequest, opts ...grpc.CallOption) (*ListDeadLetterLeavesResponse, error)
+	// Puts quarantined leaves back into the sequencing queue of their log.
+	RequeueDeadLetterLeaves(ctx context.Context, in *RequeueDeadLetterLeavesRequest, opts ...grpc.CallOption) (*RequeueDeadLetterLeavesResponse, error)
+	// Permanently deletes quarantined leaves and their data, after which the
+	// same leaves may be submitted to the log again.
+	PurgeDeadLetterLeaves(ctx context.Context, in *PurgeDeadLetterLeavesRequest, opts ...grpc.CallOption) (*PurgeDeadLetterLeavesResponse, error)
+	// Reports how many quota tokens are currently available for a tree and
+	// optionally a set of users, along with the global quotas.
+	GetQuotaState(ctx context.Context, in *GetQuotaStateRequest, opts ...grpc.CallOption) (*GetQuotaStateResponse, error)
+	// Returns statistics of the data stored for a log, such as its number of
+	// leaves and the size of its sequencing backlog.
+	GetTreeStats(ctx context.Context, in *GetTreeStatsRequest, opts ...grpc.CallOption) (*GetTreeStatsResponse, error)
+	// Returns the number of bytes of storage written for a tree. Usage counts
+	// the bytes written, so it doesn't go down when old map revisions are
+	// garbage collected. Only supported by storage which tracks the usage of
+	// trees.
+	GetTreeUsage(ctx context.Context, in *GetTreeUsageRequest, opts ...grpc.CallOption) (*GetTreeUsageResponse, error)
+	// Creates or updates a tree to match a declarative spec, and returns the
+	// changes made. Applying the same spec again makes no further changes, so
+	// tools such as Kubernetes operators may reconcile trees with it.
+	// Readonly fields of existing trees can't be changed.
+	ApplyTreeSpec(ctx context.Context, in *ApplyTreeSpecRequest, opts ...grpc.CallOption) (*ApplyTreeSpecResponse, error)
+	// Sets placement hints which move mastership of logs to a named signer,
+	// e.g. to drain a signer before maintenance. The current master of each log
+	// resigns once the named signer is running an election for it, and other
+	// signers hold back from elections for a while, so the named signer takes
+	// over. Only supported if the log server shares an election system which
+	// takes placement hints with the signers, such as etcd.
+	MoveMastership(ctx context.Context, in *MoveMastershipRequest, opts ...grpc.CallOption) (*MoveMastershipResponse, error)
+	// Puts trees, or the whole server, into maintenance, or takes them out of
+	// it. Writes to trees in maintenance, or to any tree of a server in
+	// maintenance, fail with FAILED_PRECONDITION while reads continue, e.g. for
+	// schema migrations and storage failovers. The maintenance of trees is
+	// stored with them, and so applies to all servers, while that of a server
+	// only applies to the server which receives the request.
+	SetMaintenanceMode(ctx context.Context, in *SetMaintenanceModeRequest, opts ...grpc.CallOption) (*SetMaintenanceModeResponse, error)
+	// Overrides the feature flags of the server which receives the request,
+	// which turn features on and off for all trees or single trees, and returns
+	// all of its overrides. A request without changes only returns them.
+	// Overrides last until the server restarts, or reloads its feature flags
+	// file after it changes.
+	SetFeatureFlags(ctx context.Context, in *SetFeatureFlagsRequest, opts ...grpc.CallOption) (*SetFeatureFlagsResponse, error)
 }
 
 type trillianAdminClient struct {
@@ -755,6 +2910,96 @@ func (c *trillianAdminClient) UndeleteTree(ctx context.Context, in *UndeleteTree
 	return out, nil
 }
 
+func (c *trillianAdminClient) ListDeadLetterLeaves(ctx context.Context, in *ListDeadLetterLeavesRequest, opts ...grpc.CallOption) (*ListDeadLetterLeavesResponse, error) {
+	out := new(ListDeadLetterLeavesResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianAdmin/ListDeadLetterLeaves", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) RequeueDeadLetterLeaves(ctx context.Context, in *RequeueDeadLetterLeavesRequest, opts ...grpc.CallOption) (*RequeueDeadLetterLeavesResponse, error) {
+	out := new(RequeueDeadLetterLeavesResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianAdmin/RequeueDeadLetterLeaves", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) PurgeDeadLetterLeaves(ctx context.Context, in *PurgeDeadLetterLeavesRequest, opts ...grpc.CallOption) (*PurgeDeadLetterLeavesResponse, error) {
+	out := new(PurgeDeadLetterLeavesResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianAdmin/PurgeDeadLetterLeaves", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) GetQuotaState(ctx context.Context, in *GetQuotaStateRequest, opts ...grpc.CallOption) (*GetQuotaStateResponse, error) {
+	out := new(GetQuotaStateResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianAdmin/GetQuotaState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) GetTreeStats(ctx context.Context, in *GetTreeStatsRequest, opts ...grpc.CallOption) (*GetTreeStatsResponse, error) {
+	out := new(GetTreeStatsResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianAdmin/GetTreeStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) GetTreeUsage(ctx context.Context, in *GetTreeUsageRequest, opts ...grpc.CallOption) (*GetTreeUsageResponse, error) {
+	out := new(GetTreeUsageResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianAdmin/GetTreeUsage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) ApplyTreeSpec(ctx context.Context, in *ApplyTreeSpecRequest, opts ...grpc.CallOption) (*ApplyTreeSpecResponse, error) {
+	out := new(ApplyTreeSpecResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianAdmin/ApplyTreeSpec", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) MoveMastership(ctx context.Context, in *MoveMastershipRequest, opts ...grpc.CallOption) (*MoveMastershipResponse, error) {
+	out := new(MoveMastershipResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianAdmin/MoveMastership", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) SetMaintenanceMode(ctx context.Context, in *SetMaintenanceModeRequest, opts ...grpc.CallOption) (*SetMaintenanceModeResponse, error) {
+	out := new(SetMaintenanceModeResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianAdmin/SetMaintenanceMode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianAdminClient) SetFeatureFlags(ctx context.Context, in *SetFeatureFlagsRequest, opts ...grpc.CallOption) (*SetFeatureFlagsResponse, error) {
+	out := new(SetFeatureFlagsResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianAdmin/SetFeatureFlags", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TrillianAdminServer is the server API for TrillianAdmin service.
 type TrillianAdminServer interface {
 	// Lists all trees the requester has access to.
@@ -777,6 +3022,50 @@ type TrillianAdminServer interface {
 	// A soft-deleted tree may be undeleted for a certain period, after which
 	// it'll be permanently deleted.
 	UndeleteTree(context.Context, *UndeleteTreeRequest) (*Tree, error)
+	// Lists leaves of a log which were quarantined by the sequencer because
+	// they could not be integrated.
+	ListDeadLetterLeaves(context.Context, *ListDeadLetterLeavesRequest) (*ListDeadLetterLeavesResponse, error)
+	// Puts quarantined leaves back into the sequencing queue of their log.
+	RequeueDeadLetterLeaves(context.Context, *RequeueDeadLetterLeavesRequest) (*RequeueDeadLetterLeavesResponse, error)
+	// Permanently deletes quarantined leaves and their data, after which the
+	// same leaves may be submitted to the log again.
+	PurgeDeadLetterLeaves(context.Context, *PurgeDeadLetterLeavesRequest) (*PurgeDeadLetterLeavesResponse, error)
+	// Reports how many quota tokens are currently available for a tree and
+	// optionally a set of users, along with the global quotas.
+	GetQuotaState(context.Context, *GetQuotaStateRequest) (*GetQuotaStateResponse, error)
+	// Returns statistics of the data stored for a log, such as its number of
+	// leaves and the size of its sequencing backlog.
+	GetTreeStats(context.Context, *GetTreeStatsRequest) (*GetTreeStatsResponse, error)
+	// Returns the number of bytes of storage written for a tree. Usage counts
+	// the bytes written, so it doesn't go down when old map revisions are
+	// garbage collected. Only supported by storage which tracks the usage of
+	// trees.
+	GetTreeUsage(context.Context, *GetTreeUsageRequest) (*GetTreeUsageResponse, error)
+	// Creates or updates a tree to match a declarative spec, and returns the
+	// changes made. Applying the same spec again makes no further changes, so
+	// tools such as Kubernetes operators may reconcile trees with it.
+	// Readonly fields of existing trees can't be changed.
+	ApplyTreeSpec(context.Context, *ApplyTreeSpecRequest) (*ApplyTreeSpecResponse, error)
+	// Sets placement hints which move mastership of logs to a named signer,
+	// e.g. to drain a signer before maintenance. The current master of each log
+	// resigns once the named signer is running an election for it, and other
+	// signers hold back from elections for a while, so the named signer takes
+	// over. Only supported if the log server shares an election system which
+	// takes placement hints with the signers, such as etcd.
+	MoveMastership(context.Context, *MoveMastershipRequest) (*MoveMastershipResponse, error)
+	// Puts trees, or the whole server, into maintenance, or takes them out of
+	// it. Writes to trees in maintenance, or to any tree of a server in
+	// maintenance, fail with FAILED_PRECONDITION while reads continue, e.g. for
+	// schema migrations and storage failovers. The maintenance of trees is
+	// stored with them, and so applies to all servers, while that of a server
+	// only applies to the server which receives the request.
+	SetMaintenanceMode(context.Context, *SetMaintenanceModeRequest) (*SetMaintenanceModeResponse, error)
+	// Overrides the feature flags of the server which receives the request,
+	// which turn features on and off for all trees or single trees, and returns
+	// all of its overrides. A request without changes only returns them.
+	// Overrides last until the server restarts, or reloads its feature flags
+	// file after it changes.
+	SetFeatureFlags(context.Context, *SetFeatureFlagsRequest) (*SetFeatureFlagsResponse, error)
 }
 
 // UnimplementedTrillianAdminServer can be embedded to have forward compatible implementations.
@@ -801,6 +3090,36 @@ func (*UnimplementedTrillianAdminServer) DeleteTree(context.Context, *DeleteTree
 func (*UnimplementedTrillianAdminServer) UndeleteTree(context.Context, *UndeleteTreeRequest) (*Tree, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UndeleteTree not implemented")
 }
+func (*UnimplementedTrillianAdminServer) ListDeadLetterLeaves(context.Context, *ListDeadLetterLeavesRequest) (*ListDeadLetterLeavesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeadLetterLeaves not implemented")
+}
+func (*UnimplementedTrillianAdminServer) RequeueDeadLetterLeaves(context.Context, *RequeueDeadLetterLeavesRequest) (*RequeueDeadLetterLeavesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequeueDeadLetterLeaves not implemented")
+}
+func (*UnimplementedTrillianAdminServer) PurgeDeadLetterLeaves(context.Context, *PurgeDeadLetterLeavesRequest) (*PurgeDeadLetterLeavesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeDeadLetterLeaves not implemented")
+}
+func (*UnimplementedTrillianAdminServer) GetQuotaState(context.Context, *GetQuotaStateRequest) (*GetQuotaStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuotaState not implemented")
+}
+func (*UnimplementedTrillianAdminServer) GetTreeStats(context.Context, *GetTreeStatsRequest) (*GetTreeStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTreeStats not implemented")
+}
+func (*UnimplementedTrillianAdminServer) GetTreeUsage(context.Context, *GetTreeUsageRequest) (*GetTreeUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTreeUsage not implemented")
+}
+func (*UnimplementedTrillianAdminServer) ApplyTreeSpec(context.Context, *ApplyTreeSpecRequest) (*ApplyTreeSpecResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyTreeSpec not implemented")
+}
+func (*UnimplementedTrillianAdminServer) MoveMastership(context.Context, *MoveMastershipRequest) (*MoveMastershipResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveMastership not implemented")
+}
+func (*UnimplementedTrillianAdminServer) SetMaintenanceMode(context.Context, *SetMaintenanceModeRequest) (*SetMaintenanceModeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMaintenanceMode not implemented")
+}
+func (*UnimplementedTrillianAdminServer) SetFeatureFlags(context.Context, *SetFeatureFlagsRequest) (*SetFeatureFlagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetFeatureFlags not implemented")
+}
 
 func RegisterTrillianAdminServer(s *grpc.Server, srv TrillianAdminServer) {
 	s.RegisterService(&_TrillianAdmin_serviceDesc, srv)
@@ -914,6 +3233,186 @@ func _TrillianAdmin_UndeleteTree_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrillianAdmin_ListDeadLetterLeaves_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeadLetterLeavesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).ListDeadLetterLeaves(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianAdmin/ListDeadLetterLeaves",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).ListDeadLetterLeaves(ctx, req.(*ListDeadLetterLeavesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_RequeueDeadLetterLeaves_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequeueDeadLetterLeavesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).RequeueDeadLetterLeaves(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianAdmin/RequeueDeadLetterLeaves",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).RequeueDeadLetterLeaves(ctx, req.(*RequeueDeadLetterLeavesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_PurgeDeadLetterLeaves_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeDeadLetterLeavesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).PurgeDeadLetterLeaves(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianAdmin/PurgeDeadLetterLeaves",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).PurgeDeadLetterLeaves(ctx, req.(*PurgeDeadLetterLeavesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_GetQuotaState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuotaStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).GetQuotaState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianAdmin/GetQuotaState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).GetQuotaState(ctx, req.(*GetQuotaStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_GetTreeStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTreeStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).GetTreeStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianAdmin/GetTreeStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).GetTreeStats(ctx, req.(*GetTreeStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_GetTreeUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTreeUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).GetTreeUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianAdmin/GetTreeUsage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).GetTreeUsage(ctx, req.(*GetTreeUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_ApplyTreeSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyTreeSpecRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).ApplyTreeSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianAdmin/ApplyTreeSpec",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).ApplyTreeSpec(ctx, req.(*ApplyTreeSpecRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_MoveMastership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveMastershipRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).MoveMastership(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianAdmin/MoveMastership",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).MoveMastership(ctx, req.(*MoveMastershipRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_SetMaintenanceMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetMaintenanceModeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).SetMaintenanceMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianAdmin/SetMaintenanceMode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).SetMaintenanceMode(ctx, req.(*SetMaintenanceModeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianAdmin_SetFeatureFlags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFeatureFlagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianAdminServer).SetFeatureFlags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianAdmin/SetFeatureFlags",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianAdminServer).SetFeatureFlags(ctx, req.(*SetFeatureFlagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _TrillianAdmin_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "trillian.TrillianAdmin",
 	HandlerType: (*TrillianAdminServer)(nil),
@@ -942,6 +3441,46 @@ var _TrillianAdmin_serviceDesc = grpc.ServiceDesc{
 			MethodName: "UndeleteTree",
 			Handler:    _TrillianAdmin_UndeleteTree_Handler,
 		},
+		{
+			MethodName: "ListDeadLetterLeaves",
+			Handler:    _TrillianAdmin_ListDeadLetterLeaves_Handler,
+		},
+		{
+			MethodName: "RequeueDeadLetterLeaves",
+			Handler:    _TrillianAdmin_RequeueDeadLetterLeaves_Handler,
+		},
+		{
+			MethodName: "PurgeDeadLetterLeaves",
+			Handler:    _TrillianAdmin_PurgeDeadLetterLeaves_Handler,
+		},
+		{
+			MethodName: "GetQuotaState",
+			Handler:    _TrillianAdmin_GetQuotaState_Handler,
+		},
+		{
+			MethodName: "GetTreeStats",
+			Handler:    _TrillianAdmin_GetTreeStats_Handler,
+		},
+		{
+			MethodName: "GetTreeUsage",
+			Handler:    _TrillianAdmin_GetTreeUsage_Handler,
+		},
+		{
+			MethodName: "ApplyTreeSpec",
+			Handler:    _TrillianAdmin_ApplyTreeSpec_Handler,
+		},
+		{
+			MethodName: "MoveMastership",
+			Handler:    _TrillianAdmin_MoveMastership_Handler,
+		},
+		{
+			MethodName: "SetMaintenanceMode",
+			Handler:    _TrillianAdmin_SetMaintenanceMode_Handler,
+		},
+		{
+			MethodName: "SetFeatureFlags",
+			Handler:    _TrillianAdmin_SetFeatureFlags_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "trillian_admin_api.proto",
@@ -70,6 +70,11 @@ type MapLeaf struct {
 	LeafValue []byte `protobuf:"bytes,3,opt,name=leaf_value,json=leafValue,proto3" json:"leaf_value,omitempty"`
 	// extra_data holds related contextual data, but is not covered by any hash.
 	ExtraData []byte `protobuf:"bytes,4,opt,name=extra_data,json=extraData,proto3" json:"extra_data,omitempty"`
+	// key is the key which index was derived from, if the writer provides it.
+	// It is not covered by any hash. The keys of the leaves of maps with
+	// map_key_index set are indexed, so that ListLeavesByKeyPrefix can find
+	// them, and must be at most 255 bytes long.
+	Key []byte `protobuf:"bytes,5,opt,name=key,proto3" json:"key,omitempty"`
 }
 
 func (x *MapLeaf) Reset() {
@@ -132,6 +137,13 @@ func (x *MapLeaf) GetExtraData() []byte {
 	return nil
 }
 
+func (x *MapLeaf) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
 type MapLeaves struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -192,7 +204,15 @@ type MapLeafInclusion struct {
 	// that the node in question has an empty subtree beneath it (and so its
 	// associated hash value is hasher.HashEmpty(index, height) rather than
 	// hasher.HashChildren(l_hash, r_hash)).
+	//
+	// If inclusion_bitmap is set, the proof is in compact form, and inclusion
+	// only holds the non-nil entries.
 	Inclusion [][]byte `protobuf:"bytes,2,rep,name=inclusion,proto3" json:"inclusion,omitempty"`
+	// inclusion_bitmap is set for proofs in compact form. It holds one bit for
+	// each level of the tree, which is set if the entry for that level is
+	// non-nil. The bit for level i is bit i%8 of byte i/8, counting from the
+	// least significant bit.
+	InclusionBitmap []byte `protobuf:"bytes,3,opt,name=inclusion_bitmap,json=inclusionBitmap,proto3" json:"inclusion_bitmap,omitempty"`
 }
 
 func (x *MapLeafInclusion) Reset() {
@@ -241,6 +261,13 @@ func (x *MapLeafInclusion) GetInclusion() [][]byte {
 	return nil
 }
 
+func (x *MapLeafInclusion) GetInclusionBitmap() []byte {
+	if x != nil {
+		return x.InclusionBitmap
+	}
+	return nil
+}
+
 type GetMapLeavesRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -248,6 +275,11 @@ type GetMapLeavesRequest struct {
 
 	MapId int64    `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
 	Index [][]byte `protobuf:"bytes,2,rep,name=index,proto3" json:"index,omitempty"`
+	// The `next_page_token` of a previous response to the same request, to
+	// continue reading the indices where it stopped, at the same revision.
+	PageToken []byte `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Return the inclusion proofs in compact form.
+	CompactProofs bool `protobuf:"varint,5,opt,name=compact_proofs,json=compactProofs,proto3" json:"compact_proofs,omitempty"`
 }
 
 func (x *GetMapLeavesRequest) Reset() {
@@ -296,6 +328,20 @@ func (x *GetMapLeavesRequest) GetIndex() [][]byte {
 	return nil
 }
 
+func (x *GetMapLeavesRequest) GetPageToken() []byte {
+	if x != nil {
+		return x.PageToken
+	}
+	return nil
+}
+
+func (x *GetMapLeavesRequest) GetCompactProofs() bool {
+	if x != nil {
+		return x.CompactProofs
+	}
+	return false
+}
+
 type GetMapLeafRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -303,6 +349,8 @@ type GetMapLeafRequest struct {
 
 	MapId int64  `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
 	Index []byte `protobuf:"bytes,2,opt,name=index,proto3" json:"index,omitempty"`
+	// Return the inclusion proof in compact form.
+	CompactProofs bool `protobuf:"varint,3,opt,name=compact_proofs,json=compactProofs,proto3" json:"compact_proofs,omitempty"`
 }
 
 func (x *GetMapLeafRequest) Reset() {
@@ -351,6 +399,13 @@ func (x *GetMapLeafRequest) GetIndex() []byte {
 	return nil
 }
 
+func (x *GetMapLeafRequest) GetCompactProofs() bool {
+	if x != nil {
+		return x.CompactProofs
+	}
+	return false
+}
+
 type GetMapLeafByRevisionRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -359,6 +414,8 @@ type GetMapLeafByRevisionRequest struct {
 	MapId    int64  `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
 	Index    []byte `protobuf:"bytes,2,opt,name=index,proto3" json:"index,omitempty"`
 	Revision int64  `protobuf:"varint,3,opt,name=revision,proto3" json:"revision,omitempty"`
+	// Return the inclusion proof in compact form.
+	CompactProofs bool `protobuf:"varint,4,opt,name=compact_proofs,json=compactProofs,proto3" json:"compact_proofs,omitempty"`
 }
 
 func (x *GetMapLeafByRevisionRequest) Reset() {
@@ -414,6 +471,13 @@ func (x *GetMapLeafByRevisionRequest) GetRevision() int64 {
 	return 0
 }
 
+func (x *GetMapLeafByRevisionRequest) GetCompactProofs() bool {
+	if x != nil {
+		return x.CompactProofs
+	}
+	return false
+}
+
 // This message replaces the current implementation of GetMapLeavesRequest
 // with the difference that revision must be >=0.
 type GetMapLeavesByRevisionRequest struct {
@@ -426,6 +490,13 @@ type GetMapLeavesByRevisionRequest struct {
 	Index [][]byte `protobuf:"bytes,2,rep,name=index,proto3" json:"index,omitempty"`
 	// revision >= 0.
 	Revision int64 `protobuf:"varint,3,opt,name=revision,proto3" json:"revision,omitempty"`
+	// The `next_page_token` of a previous response to the same request, to
+	// continue reading the indices where it stopped. Not supported by
+	// GetLeavesByRevisionNoProof.
+	PageToken []byte `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Return the inclusion proofs in compact form. Ignored by
+	// GetLeavesByRevisionNoProof.
+	CompactProofs bool `protobuf:"varint,5,opt,name=compact_proofs,json=compactProofs,proto3" json:"compact_proofs,omitempty"`
 }
 
 func (x *GetMapLeavesByRevisionRequest) Reset() {
@@ -481,17 +552,39 @@ func (x *GetMapLeavesByRevisionRequest) GetRevision() int64 {
 	return 0
 }
 
-type GetMapLeafResponse struct {
+func (x *GetMapLeavesByRevisionRequest) GetPageToken() []byte {
+	if x != nil {
+		return x.PageToken
+	}
+	return nil
+}
+
+func (x *GetMapLeavesByRevisionRequest) GetCompactProofs() bool {
+	if x != nil {
+		return x.CompactProofs
+	}
+	return false
+}
+
+// GetMapLeavesByRevisionsRequest requests the same set of leaves at multiple
+// revisions of a map.
+type GetMapLeavesByRevisionsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	MapLeafInclusion *MapLeafInclusion `protobuf:"bytes,1,opt,name=map_leaf_inclusion,json=mapLeafInclusion,proto3" json:"map_leaf_inclusion,omitempty"`
-	MapRoot          *SignedMapRoot    `protobuf:"bytes,2,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
+	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
+	// index(es) to query.  It is an error to request the same index more than once.
+	Index [][]byte `protobuf:"bytes,2,rep,name=index,proto3" json:"index,omitempty"`
+	// revision(s) to query, each >= 0.  It is an error to request the same
+	// revision more than once.
+	Revision []int64 `protobuf:"varint,3,rep,packed,name=revision,proto3" json:"revision,omitempty"`
+	// Return the inclusion proofs in compact form.
+	CompactProofs bool `protobuf:"varint,4,opt,name=compact_proofs,json=compactProofs,proto3" json:"compact_proofs,omitempty"`
 }
 
-func (x *GetMapLeafResponse) Reset() {
-	*x = GetMapLeafResponse{}
+func (x *GetMapLeavesByRevisionsRequest) Reset() {
+	*x = GetMapLeavesByRevisionsRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_trillian_map_api_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -499,13 +592,13 @@ func (x *GetMapLeafResponse) Reset() {
 	}
 }
 
-func (x *GetMapLeafResponse) String() string {
+func (x *GetMapLeavesByRevisionsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetMapLeafResponse) ProtoMessage() {}
+func (*GetMapLeavesByRevisionsRequest) ProtoMessage() {}
 
-func (x *GetMapLeafResponse) ProtoReflect() protoreflect.Message {
+func (x *GetMapLeavesByRevisionsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_trillian_map_api_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -517,36 +610,50 @@ func (x *GetMapLeafResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetMapLeafResponse.ProtoReflect.Descriptor instead.
-func (*GetMapLeafResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetMapLeavesByRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*GetMapLeavesByRevisionsRequest) Descriptor() ([]byte, []int) {
 	return file_trillian_map_api_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *GetMapLeafResponse) GetMapLeafInclusion() *MapLeafInclusion {
+func (x *GetMapLeavesByRevisionsRequest) GetMapId() int64 {
 	if x != nil {
-		return x.MapLeafInclusion
+		return x.MapId
+	}
+	return 0
+}
+
+func (x *GetMapLeavesByRevisionsRequest) GetIndex() [][]byte {
+	if x != nil {
+		return x.Index
 	}
 	return nil
 }
 
-func (x *GetMapLeafResponse) GetMapRoot() *SignedMapRoot {
+func (x *GetMapLeavesByRevisionsRequest) GetRevision() []int64 {
 	if x != nil {
-		return x.MapRoot
+		return x.Revision
 	}
 	return nil
 }
 
-type GetMapLeavesResponse struct {
+func (x *GetMapLeavesByRevisionsRequest) GetCompactProofs() bool {
+	if x != nil {
+		return x.CompactProofs
+	}
+	return false
+}
+
+type GetMapLeafResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	MapLeafInclusion []*MapLeafInclusion `protobuf:"bytes,2,rep,name=map_leaf_inclusion,json=mapLeafInclusion,proto3" json:"map_leaf_inclusion,omitempty"`
-	MapRoot          *SignedMapRoot      `protobuf:"bytes,3,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
+	MapLeafInclusion *MapLeafInclusion `protobuf:"bytes,1,opt,name=map_leaf_inclusion,json=mapLeafInclusion,proto3" json:"map_leaf_inclusion,omitempty"`
+	MapRoot          *SignedMapRoot    `protobuf:"bytes,2,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
 }
 
-func (x *GetMapLeavesResponse) Reset() {
-	*x = GetMapLeavesResponse{}
+func (x *GetMapLeafResponse) Reset() {
+	*x = GetMapLeafResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_trillian_map_api_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -554,13 +661,13 @@ func (x *GetMapLeavesResponse) Reset() {
 	}
 }
 
-func (x *GetMapLeavesResponse) String() string {
+func (x *GetMapLeafResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetMapLeavesResponse) ProtoMessage() {}
+func (*GetMapLeafResponse) ProtoMessage() {}
 
-func (x *GetMapLeavesResponse) ProtoReflect() protoreflect.Message {
+func (x *GetMapLeafResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_trillian_map_api_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -572,44 +679,41 @@ func (x *GetMapLeavesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetMapLeavesResponse.ProtoReflect.Descriptor instead.
-func (*GetMapLeavesResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetMapLeafResponse.ProtoReflect.Descriptor instead.
+func (*GetMapLeafResponse) Descriptor() ([]byte, []int) {
 	return file_trillian_map_api_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *GetMapLeavesResponse) GetMapLeafInclusion() []*MapLeafInclusion {
+func (x *GetMapLeafResponse) GetMapLeafInclusion() *MapLeafInclusion {
 	if x != nil {
 		return x.MapLeafInclusion
 	}
 	return nil
 }
 
-func (x *GetMapLeavesResponse) GetMapRoot() *SignedMapRoot {
+func (x *GetMapLeafResponse) GetMapRoot() *SignedMapRoot {
 	if x != nil {
 		return x.MapRoot
 	}
 	return nil
 }
 
-// GetLastInRangeByRevisionRequest specifies a range in the map at a revision.
-// The range is defined as the entire subtree below a particular point in the
-// Merkle tree. Another way of saying this is that the range matches all leaves
-// that share a common prefix of `prefix_bits` with `prefix`.
-type GetLastInRangeByRevisionRequest struct {
+type GetMapLeavesResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	MapId    int64  `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
-	Revision int64  `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
-	Prefix   []byte `protobuf:"bytes,3,opt,name=prefix,proto3" json:"prefix,omitempty"`
-	// prefix_bits is the number of bits to include, starting from the left, or
-	// most significant bit (MSB).
-	PrefixBits int32 `protobuf:"varint,4,opt,name=prefix_bits,json=prefixBits,proto3" json:"prefix_bits,omitempty"`
+	MapLeafInclusion []*MapLeafInclusion `protobuf:"bytes,2,rep,name=map_leaf_inclusion,json=mapLeafInclusion,proto3" json:"map_leaf_inclusion,omitempty"`
+	MapRoot          *SignedMapRoot      `protobuf:"bytes,3,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
+	// Set if the leaves were truncated to keep the response under the maximum
+	// message size of the server, in which case `map_leaf_inclusion` only holds
+	// those of the first requested indices. The rest can be read by sending the
+	// request again with it as the `page_token`.
+	NextPageToken []byte `protobuf:"bytes,4,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 }
 
-func (x *GetLastInRangeByRevisionRequest) Reset() {
-	*x = GetLastInRangeByRevisionRequest{}
+func (x *GetMapLeavesResponse) Reset() {
+	*x = GetMapLeavesResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_trillian_map_api_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -617,13 +721,13 @@ func (x *GetLastInRangeByRevisionRequest) Reset() {
 	}
 }
 
-func (x *GetLastInRangeByRevisionRequest) String() string {
+func (x *GetMapLeavesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetLastInRangeByRevisionRequest) ProtoMessage() {}
+func (*GetMapLeavesResponse) ProtoMessage() {}
 
-func (x *GetLastInRangeByRevisionRequest) ProtoReflect() protoreflect.Message {
+func (x *GetMapLeavesResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_trillian_map_api_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -635,56 +739,44 @@ func (x *GetLastInRangeByRevisionRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetLastInRangeByRevisionRequest.ProtoReflect.Descriptor instead.
-func (*GetLastInRangeByRevisionRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetMapLeavesResponse.ProtoReflect.Descriptor instead.
+func (*GetMapLeavesResponse) Descriptor() ([]byte, []int) {
 	return file_trillian_map_api_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *GetLastInRangeByRevisionRequest) GetMapId() int64 {
-	if x != nil {
-		return x.MapId
-	}
-	return 0
-}
-
-func (x *GetLastInRangeByRevisionRequest) GetRevision() int64 {
+func (x *GetMapLeavesResponse) GetMapLeafInclusion() []*MapLeafInclusion {
 	if x != nil {
-		return x.Revision
+		return x.MapLeafInclusion
 	}
-	return 0
+	return nil
 }
 
-func (x *GetLastInRangeByRevisionRequest) GetPrefix() []byte {
+func (x *GetMapLeavesResponse) GetMapRoot() *SignedMapRoot {
 	if x != nil {
-		return x.Prefix
+		return x.MapRoot
 	}
 	return nil
 }
 
-func (x *GetLastInRangeByRevisionRequest) GetPrefixBits() int32 {
+func (x *GetMapLeavesResponse) GetNextPageToken() []byte {
 	if x != nil {
-		return x.PrefixBits
+		return x.NextPageToken
 	}
-	return 0
+	return nil
 }
 
-type SetMapLeavesRequest struct {
+type GetMapLeavesByRevisionsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
-	// The leaves being set must have unique Index values within the request.
-	Leaves   []*MapLeaf `protobuf:"bytes,2,rep,name=leaves,proto3" json:"leaves,omitempty"`
-	Metadata []byte     `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`
-	// The map revision to associate the leaves with. The request will fail if
-	// this revision already exists, does not match the current write revision, or
-	// is not positive. Note that revision = 0 is reserved for the empty tree.
-	Revision int64 `protobuf:"varint,6,opt,name=revision,proto3" json:"revision,omitempty"`
+	// The leaves and their inclusion proofs at each requested revision, in the
+	// order of the request.
+	Revisions []*GetMapLeavesResponse `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
 }
 
-func (x *SetMapLeavesRequest) Reset() {
-	*x = SetMapLeavesRequest{}
+func (x *GetMapLeavesByRevisionsResponse) Reset() {
+	*x = GetMapLeavesByRevisionsResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_trillian_map_api_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -692,13 +784,13 @@ func (x *SetMapLeavesRequest) Reset() {
 	}
 }
 
-func (x *SetMapLeavesRequest) String() string {
+func (x *GetMapLeavesByRevisionsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SetMapLeavesRequest) ProtoMessage() {}
+func (*GetMapLeavesByRevisionsResponse) ProtoMessage() {}
 
-func (x *SetMapLeavesRequest) ProtoReflect() protoreflect.Message {
+func (x *GetMapLeavesByRevisionsResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_trillian_map_api_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -710,49 +802,34 @@ func (x *SetMapLeavesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SetMapLeavesRequest.ProtoReflect.Descriptor instead.
-func (*SetMapLeavesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetMapLeavesByRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*GetMapLeavesByRevisionsResponse) Descriptor() ([]byte, []int) {
 	return file_trillian_map_api_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *SetMapLeavesRequest) GetMapId() int64 {
-	if x != nil {
-		return x.MapId
-	}
-	return 0
-}
-
-func (x *SetMapLeavesRequest) GetLeaves() []*MapLeaf {
-	if x != nil {
-		return x.Leaves
-	}
-	return nil
-}
-
-func (x *SetMapLeavesRequest) GetMetadata() []byte {
+func (x *GetMapLeavesByRevisionsResponse) GetRevisions() []*GetMapLeavesResponse {
 	if x != nil {
-		return x.Metadata
+		return x.Revisions
 	}
 	return nil
 }
 
-func (x *SetMapLeavesRequest) GetRevision() int64 {
-	if x != nil {
-		return x.Revision
-	}
-	return 0
-}
-
-type SetMapLeavesResponse struct {
+// GetMapLeavesCompactRequest requests leaves of a map with a single inclusion
+// proof shared by all of them.
+type GetMapLeavesCompactRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	MapRoot *SignedMapRoot `protobuf:"bytes,2,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
+	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
+	// index(es) to query.  It is an error to request the same index more than once.
+	Index [][]byte `protobuf:"bytes,2,rep,name=index,proto3" json:"index,omitempty"`
+	// The revision to read, or the latest revision if negative.
+	Revision int64 `protobuf:"varint,3,opt,name=revision,proto3" json:"revision,omitempty"`
 }
 
-func (x *SetMapLeavesResponse) Reset() {
-	*x = SetMapLeavesResponse{}
+func (x *GetMapLeavesCompactRequest) Reset() {
+	*x = GetMapLeavesCompactRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_trillian_map_api_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -760,13 +837,13 @@ func (x *SetMapLeavesResponse) Reset() {
 	}
 }
 
-func (x *SetMapLeavesResponse) String() string {
+func (x *GetMapLeavesCompactRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SetMapLeavesResponse) ProtoMessage() {}
+func (*GetMapLeavesCompactRequest) ProtoMessage() {}
 
-func (x *SetMapLeavesResponse) ProtoReflect() protoreflect.Message {
+func (x *GetMapLeavesCompactRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_trillian_map_api_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -778,40 +855,56 @@ func (x *SetMapLeavesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SetMapLeavesResponse.ProtoReflect.Descriptor instead.
-func (*SetMapLeavesResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetMapLeavesCompactRequest.ProtoReflect.Descriptor instead.
+func (*GetMapLeavesCompactRequest) Descriptor() ([]byte, []int) {
 	return file_trillian_map_api_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *SetMapLeavesResponse) GetMapRoot() *SignedMapRoot {
+func (x *GetMapLeavesCompactRequest) GetMapId() int64 {
 	if x != nil {
-		return x.MapRoot
+		return x.MapId
+	}
+	return 0
+}
+
+func (x *GetMapLeavesCompactRequest) GetIndex() [][]byte {
+	if x != nil {
+		return x.Index
 	}
 	return nil
 }
 
-type WriteMapLeavesRequest struct {
+func (x *GetMapLeavesCompactRequest) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+type GetMapLeavesCompactResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
-	// The leaves being set must have unique Index values within the request.
-	Leaves []*MapLeaf `protobuf:"bytes,2,rep,name=leaves,proto3" json:"leaves,omitempty"`
-	// Metadata that the Map should associate with the new Map root after
-	// incorporating the leaf changes.  The metadata will be reflected in the
-	// Map Root published for this revision.
-	// Map personalities should use metadata to persist any state needed later
-	// to continue mapping from an external data source.
-	Metadata []byte `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`
-	// The map revision to associate the leaves with. The request will fail if
-	// this revision already exists, does not match the current write revision, or
-	// is not positive. Note that revision = 0 is reserved for the empty tree.
-	ExpectRevision int64 `protobuf:"varint,4,opt,name=expect_revision,json=expectRevision,proto3" json:"expect_revision,omitempty"`
-}
-
-func (x *WriteMapLeavesRequest) Reset() {
-	*x = WriteMapLeavesRequest{}
+	// The leaves at the requested indices, in the order of the request, with
+	// empty leaves for indices which are not set.
+	Leaves []*MapLeaf `protobuf:"bytes,1,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	// proof holds the non-nil entries of the inclusion proof of all the leaves.
+	// The proof has an entry for each sibling of an ancestor of a leaf which is
+	// not an ancestor of a leaf itself, ordered from the leaves up to the root,
+	// and from left to right within each level. Combining the leaves with these
+	// reproduces the root hash. A nil entry indicates that the node has an empty
+	// subtree beneath it.
+	Proof [][]byte `protobuf:"bytes,2,rep,name=proof,proto3" json:"proof,omitempty"`
+	// proof_bitmap holds one bit for each entry of the proof, which is set if the
+	// entry is non-nil. The bit for entry i is bit i%8 of byte i/8, counting from
+	// the least significant bit.
+	ProofBitmap []byte         `protobuf:"bytes,3,opt,name=proof_bitmap,json=proofBitmap,proto3" json:"proof_bitmap,omitempty"`
+	MapRoot     *SignedMapRoot `protobuf:"bytes,4,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
+}
+
+func (x *GetMapLeavesCompactResponse) Reset() {
+	*x = GetMapLeavesCompactResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_trillian_map_api_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -819,13 +912,13 @@ func (x *WriteMapLeavesRequest) Reset() {
 	}
 }
 
-func (x *WriteMapLeavesRequest) String() string {
+func (x *GetMapLeavesCompactResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WriteMapLeavesRequest) ProtoMessage() {}
+func (*GetMapLeavesCompactResponse) ProtoMessage() {}
 
-func (x *WriteMapLeavesRequest) ProtoReflect() protoreflect.Message {
+func (x *GetMapLeavesCompactResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_trillian_map_api_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -837,52 +930,58 @@ func (x *WriteMapLeavesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WriteMapLeavesRequest.ProtoReflect.Descriptor instead.
-func (*WriteMapLeavesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetMapLeavesCompactResponse.ProtoReflect.Descriptor instead.
+func (*GetMapLeavesCompactResponse) Descriptor() ([]byte, []int) {
 	return file_trillian_map_api_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *WriteMapLeavesRequest) GetMapId() int64 {
+func (x *GetMapLeavesCompactResponse) GetLeaves() []*MapLeaf {
 	if x != nil {
-		return x.MapId
+		return x.Leaves
 	}
-	return 0
+	return nil
 }
 
-func (x *WriteMapLeavesRequest) GetLeaves() []*MapLeaf {
+func (x *GetMapLeavesCompactResponse) GetProof() [][]byte {
 	if x != nil {
-		return x.Leaves
+		return x.Proof
 	}
 	return nil
 }
 
-func (x *WriteMapLeavesRequest) GetMetadata() []byte {
+func (x *GetMapLeavesCompactResponse) GetProofBitmap() []byte {
 	if x != nil {
-		return x.Metadata
+		return x.ProofBitmap
 	}
 	return nil
 }
 
-func (x *WriteMapLeavesRequest) GetExpectRevision() int64 {
+func (x *GetMapLeavesCompactResponse) GetMapRoot() *SignedMapRoot {
 	if x != nil {
-		return x.ExpectRevision
+		return x.MapRoot
 	}
-	return 0
+	return nil
 }
 
-type WriteMapLeavesResponse struct {
+// GetLastInRangeByRevisionRequest specifies a range in the map at a revision.
+// The range is defined as the entire subtree below a particular point in the
+// Merkle tree. Another way of saying this is that the range matches all leaves
+// that share a common prefix of `prefix_bits` with `prefix`.
+type GetLastInRangeByRevisionRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The map revision that the leaves will be published at.
-	// This may be accompanied by a proof that the write request has been included
-	// in an input log in the future.
-	Revision int64 `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
-}
-
-func (x *WriteMapLeavesResponse) Reset() {
-	*x = WriteMapLeavesResponse{}
+	MapId    int64  `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
+	Revision int64  `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	Prefix   []byte `protobuf:"bytes,3,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// prefix_bits is the number of bits to include, starting from the left, or
+	// most significant bit (MSB).
+	PrefixBits int32 `protobuf:"varint,4,opt,name=prefix_bits,json=prefixBits,proto3" json:"prefix_bits,omitempty"`
+}
+
+func (x *GetLastInRangeByRevisionRequest) Reset() {
+	*x = GetLastInRangeByRevisionRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_trillian_map_api_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -890,14 +989,682 @@ func (x *WriteMapLeavesResponse) Reset() {
 	}
 }
 
-func (x *WriteMapLeavesResponse) String() string {
+func (x *GetLastInRangeByRevisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLastInRangeByRevisionRequest) ProtoMessage() {}
+
+func (x *GetLastInRangeByRevisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLastInRangeByRevisionRequest.ProtoReflect.Descriptor instead.
+func (*GetLastInRangeByRevisionRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetLastInRangeByRevisionRequest) GetMapId() int64 {
+	if x != nil {
+		return x.MapId
+	}
+	return 0
+}
+
+func (x *GetLastInRangeByRevisionRequest) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *GetLastInRangeByRevisionRequest) GetPrefix() []byte {
+	if x != nil {
+		return x.Prefix
+	}
+	return nil
+}
+
+func (x *GetLastInRangeByRevisionRequest) GetPrefixBits() int32 {
+	if x != nil {
+		return x.PrefixBits
+	}
+	return 0
+}
+
+type SetMapLeavesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
+	// The leaves being set must have unique Index values within the request.
+	Leaves   []*MapLeaf `protobuf:"bytes,2,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	Metadata []byte     `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// The map revision to associate the leaves with. The request will fail if
+	// this revision already exists, does not match the current write revision, or
+	// is not positive. Note that revision = 0 is reserved for the empty tree.
+	Revision int64 `protobuf:"varint,6,opt,name=revision,proto3" json:"revision,omitempty"`
+}
+
+func (x *SetMapLeavesRequest) Reset() {
+	*x = SetMapLeavesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetMapLeavesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMapLeavesRequest) ProtoMessage() {}
+
+func (x *SetMapLeavesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMapLeavesRequest.ProtoReflect.Descriptor instead.
+func (*SetMapLeavesRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SetMapLeavesRequest) GetMapId() int64 {
+	if x != nil {
+		return x.MapId
+	}
+	return 0
+}
+
+func (x *SetMapLeavesRequest) GetLeaves() []*MapLeaf {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+func (x *SetMapLeavesRequest) GetMetadata() []byte {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *SetMapLeavesRequest) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+type SetMapLeavesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MapRoot *SignedMapRoot `protobuf:"bytes,2,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
+}
+
+func (x *SetMapLeavesResponse) Reset() {
+	*x = SetMapLeavesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetMapLeavesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMapLeavesResponse) ProtoMessage() {}
+
+func (x *SetMapLeavesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMapLeavesResponse.ProtoReflect.Descriptor instead.
+func (*SetMapLeavesResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SetMapLeavesResponse) GetMapRoot() *SignedMapRoot {
+	if x != nil {
+		return x.MapRoot
+	}
+	return nil
+}
+
+type WriteMapLeavesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
+	// The leaves being set must have unique Index values within the request.
+	Leaves []*MapLeaf `protobuf:"bytes,2,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	// Metadata that the Map should associate with the new Map root after
+	// incorporating the leaf changes.  The metadata will be reflected in the
+	// Map Root published for this revision.
+	// Map personalities should use metadata to persist any state needed later
+	// to continue mapping from an external data source.
+	Metadata []byte `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// The map revision to associate the leaves with. The request will fail if
+	// this revision already exists, does not match the current write revision, or
+	// is not positive. Note that revision = 0 is reserved for the empty tree.
+	ExpectRevision int64 `protobuf:"varint,4,opt,name=expect_revision,json=expectRevision,proto3" json:"expect_revision,omitempty"`
+}
+
+func (x *WriteMapLeavesRequest) Reset() {
+	*x = WriteMapLeavesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WriteMapLeavesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteMapLeavesRequest) ProtoMessage() {}
+
+func (x *WriteMapLeavesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteMapLeavesRequest.ProtoReflect.Descriptor instead.
+func (*WriteMapLeavesRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WriteMapLeavesRequest) GetMapId() int64 {
+	if x != nil {
+		return x.MapId
+	}
+	return 0
+}
+
+func (x *WriteMapLeavesRequest) GetLeaves() []*MapLeaf {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+func (x *WriteMapLeavesRequest) GetMetadata() []byte {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *WriteMapLeavesRequest) GetExpectRevision() int64 {
+	if x != nil {
+		return x.ExpectRevision
+	}
+	return 0
+}
+
+type WriteMapLeavesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The map revision that the leaves will be published at.
+	// This may be accompanied by a proof that the write request has been included
+	// in an input log in the future.
+	Revision int64 `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
+}
+
+func (x *WriteMapLeavesResponse) Reset() {
+	*x = WriteMapLeavesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WriteMapLeavesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteMapLeavesResponse) ProtoMessage() {}
+
+func (x *WriteMapLeavesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteMapLeavesResponse.ProtoReflect.Descriptor instead.
+func (*WriteMapLeavesResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WriteMapLeavesResponse) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+type QueueMapMutationsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
+	// The leaves to set, which must have unique Index values within the request.
+	// A leaf with neither a value nor extra data deletes its index.
+	Leaves []*MapLeaf `protobuf:"bytes,2,rep,name=leaves,proto3" json:"leaves,omitempty"`
+}
+
+func (x *QueueMapMutationsRequest) Reset() {
+	*x = QueueMapMutationsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueMapMutationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueMapMutationsRequest) ProtoMessage() {}
+
+func (x *QueueMapMutationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueMapMutationsRequest.ProtoReflect.Descriptor instead.
+func (*QueueMapMutationsRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *QueueMapMutationsRequest) GetMapId() int64 {
+	if x != nil {
+		return x.MapId
+	}
+	return 0
+}
+
+func (x *QueueMapMutationsRequest) GetLeaves() []*MapLeaf {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+type QueueMapMutationsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *QueueMapMutationsResponse) Reset() {
+	*x = QueueMapMutationsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueMapMutationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueMapMutationsResponse) ProtoMessage() {}
+
+func (x *QueueMapMutationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueMapMutationsResponse.ProtoReflect.Descriptor instead.
+func (*QueueMapMutationsResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{19}
+}
+
+type GetSignedMapRootRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
+}
+
+func (x *GetSignedMapRootRequest) Reset() {
+	*x = GetSignedMapRootRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSignedMapRootRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSignedMapRootRequest) ProtoMessage() {}
+
+func (x *GetSignedMapRootRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSignedMapRootRequest.ProtoReflect.Descriptor instead.
+func (*GetSignedMapRootRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetSignedMapRootRequest) GetMapId() int64 {
+	if x != nil {
+		return x.MapId
+	}
+	return 0
+}
+
+type GetSignedMapRootByRevisionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MapId    int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
+	Revision int64 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+}
+
+func (x *GetSignedMapRootByRevisionRequest) Reset() {
+	*x = GetSignedMapRootByRevisionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSignedMapRootByRevisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSignedMapRootByRevisionRequest) ProtoMessage() {}
+
+func (x *GetSignedMapRootByRevisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSignedMapRootByRevisionRequest.ProtoReflect.Descriptor instead.
+func (*GetSignedMapRootByRevisionRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetSignedMapRootByRevisionRequest) GetMapId() int64 {
+	if x != nil {
+		return x.MapId
+	}
+	return 0
+}
+
+func (x *GetSignedMapRootByRevisionRequest) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+type GetSignedMapRootResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MapRoot *SignedMapRoot `protobuf:"bytes,2,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
+}
+
+func (x *GetSignedMapRootResponse) Reset() {
+	*x = GetSignedMapRootResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSignedMapRootResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSignedMapRootResponse) ProtoMessage() {}
+
+func (x *GetSignedMapRootResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSignedMapRootResponse.ProtoReflect.Descriptor instead.
+func (*GetSignedMapRootResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetSignedMapRootResponse) GetMapRoot() *SignedMapRoot {
+	if x != nil {
+		return x.MapRoot
+	}
+	return nil
+}
+
+type InitMapRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
+}
+
+func (x *InitMapRequest) Reset() {
+	*x = InitMapRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InitMapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitMapRequest) ProtoMessage() {}
+
+func (x *InitMapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitMapRequest.ProtoReflect.Descriptor instead.
+func (*InitMapRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *InitMapRequest) GetMapId() int64 {
+	if x != nil {
+		return x.MapId
+	}
+	return 0
+}
+
+type InitMapResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Created *SignedMapRoot `protobuf:"bytes,1,opt,name=created,proto3" json:"created,omitempty"`
+}
+
+func (x *InitMapResponse) Reset() {
+	*x = InitMapResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InitMapResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitMapResponse) ProtoMessage() {}
+
+func (x *InitMapResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitMapResponse.ProtoReflect.Descriptor instead.
+func (*InitMapResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *InitMapResponse) GetCreated() *SignedMapRoot {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+// MapRootSignature is a co-signature of a map root by a witness, which vouches
+// for having seen it, so that clients can require roots seen by several
+// witnesses rather than trust the map operator alone.
+type MapRootSignature struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// witness names the witness. It is not covered by the signature.
+	Witness string `protobuf:"bytes,1,opt,name=witness,proto3" json:"witness,omitempty"`
+	// public_key is the DER encoded public key of the witness, which identifies
+	// it: a map root has at most one co-signature by each key.
+	PublicKey []byte `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	// signature is the signature of the witness over the map_root bytes of the
+	// SignedMapRoot, using the hash algorithm of the map.
+	Signature []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *MapRootSignature) Reset() {
+	*x = MapRootSignature{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MapRootSignature) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WriteMapLeavesResponse) ProtoMessage() {}
+func (*MapRootSignature) ProtoMessage() {}
 
-func (x *WriteMapLeavesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_map_api_proto_msgTypes[13]
+func (x *MapRootSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -908,43 +1675,60 @@ func (x *WriteMapLeavesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WriteMapLeavesResponse.ProtoReflect.Descriptor instead.
-func (*WriteMapLeavesResponse) Descriptor() ([]byte, []int) {
-	return file_trillian_map_api_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use MapRootSignature.ProtoReflect.Descriptor instead.
+func (*MapRootSignature) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *WriteMapLeavesResponse) GetRevision() int64 {
+func (x *MapRootSignature) GetWitness() string {
 	if x != nil {
-		return x.Revision
+		return x.Witness
 	}
-	return 0
+	return ""
 }
 
-type GetSignedMapRootRequest struct {
+func (x *MapRootSignature) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *MapRootSignature) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type AddMapRootSignatureRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
+	// The revision of the map root signed.
+	Revision  int64             `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	Signature *MapRootSignature `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
 }
 
-func (x *GetSignedMapRootRequest) Reset() {
-	*x = GetSignedMapRootRequest{}
+func (x *AddMapRootSignatureRequest) Reset() {
+	*x = AddMapRootSignatureRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_trillian_map_api_proto_msgTypes[14]
+		mi := &file_trillian_map_api_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetSignedMapRootRequest) String() string {
+func (x *AddMapRootSignatureRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetSignedMapRootRequest) ProtoMessage() {}
+func (*AddMapRootSignatureRequest) ProtoMessage() {}
 
-func (x *GetSignedMapRootRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_map_api_proto_msgTypes[14]
+func (x *AddMapRootSignatureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -955,19 +1739,71 @@ func (x *GetSignedMapRootRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetSignedMapRootRequest.ProtoReflect.Descriptor instead.
-func (*GetSignedMapRootRequest) Descriptor() ([]byte, []int) {
-	return file_trillian_map_api_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use AddMapRootSignatureRequest.ProtoReflect.Descriptor instead.
+func (*AddMapRootSignatureRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *GetSignedMapRootRequest) GetMapId() int64 {
+func (x *AddMapRootSignatureRequest) GetMapId() int64 {
 	if x != nil {
 		return x.MapId
 	}
 	return 0
 }
 
-type GetSignedMapRootByRevisionRequest struct {
+func (x *AddMapRootSignatureRequest) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *AddMapRootSignatureRequest) GetSignature() *MapRootSignature {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type AddMapRootSignatureResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *AddMapRootSignatureResponse) Reset() {
+	*x = AddMapRootSignatureResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_map_api_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddMapRootSignatureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddMapRootSignatureResponse) ProtoMessage() {}
+
+func (x *AddMapRootSignatureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddMapRootSignatureResponse.ProtoReflect.Descriptor instead.
+func (*AddMapRootSignatureResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{27}
+}
+
+type GetMapRootSignaturesRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
@@ -976,23 +1812,23 @@ type GetSignedMapRootByRevisionRequest struct {
 	Revision int64 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
 }
 
-func (x *GetSignedMapRootByRevisionRequest) Reset() {
-	*x = GetSignedMapRootByRevisionRequest{}
+func (x *GetMapRootSignaturesRequest) Reset() {
+	*x = GetMapRootSignaturesRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_trillian_map_api_proto_msgTypes[15]
+		mi := &file_trillian_map_api_proto_msgTypes[28]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetSignedMapRootByRevisionRequest) String() string {
+func (x *GetMapRootSignaturesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetSignedMapRootByRevisionRequest) ProtoMessage() {}
+func (*GetMapRootSignaturesRequest) ProtoMessage() {}
 
-func (x *GetSignedMapRootByRevisionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_map_api_proto_msgTypes[15]
+func (x *GetMapRootSignaturesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[28]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1003,50 +1839,52 @@ func (x *GetSignedMapRootByRevisionRequest) ProtoReflect() protoreflect.Message
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetSignedMapRootByRevisionRequest.ProtoReflect.Descriptor instead.
-func (*GetSignedMapRootByRevisionRequest) Descriptor() ([]byte, []int) {
-	return file_trillian_map_api_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use GetMapRootSignaturesRequest.ProtoReflect.Descriptor instead.
+func (*GetMapRootSignaturesRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *GetSignedMapRootByRevisionRequest) GetMapId() int64 {
+func (x *GetMapRootSignaturesRequest) GetMapId() int64 {
 	if x != nil {
 		return x.MapId
 	}
 	return 0
 }
 
-func (x *GetSignedMapRootByRevisionRequest) GetRevision() int64 {
+func (x *GetMapRootSignaturesRequest) GetRevision() int64 {
 	if x != nil {
 		return x.Revision
 	}
 	return 0
 }
 
-type GetSignedMapRootResponse struct {
+type GetMapRootSignaturesResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	MapRoot *SignedMapRoot `protobuf:"bytes,2,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
+	MapRoot *SignedMapRoot `protobuf:"bytes,1,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
+	// The co-signatures of map_root by witnesses, ordered by public key.
+	Signatures []*MapRootSignature `protobuf:"bytes,2,rep,name=signatures,proto3" json:"signatures,omitempty"`
 }
 
-func (x *GetSignedMapRootResponse) Reset() {
-	*x = GetSignedMapRootResponse{}
+func (x *GetMapRootSignaturesResponse) Reset() {
+	*x = GetMapRootSignaturesResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_trillian_map_api_proto_msgTypes[16]
+		mi := &file_trillian_map_api_proto_msgTypes[29]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetSignedMapRootResponse) String() string {
+func (x *GetMapRootSignaturesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetSignedMapRootResponse) ProtoMessage() {}
+func (*GetMapRootSignaturesResponse) ProtoMessage() {}
 
-func (x *GetSignedMapRootResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_map_api_proto_msgTypes[16]
+func (x *GetMapRootSignaturesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[29]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1057,43 +1895,61 @@ func (x *GetSignedMapRootResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetSignedMapRootResponse.ProtoReflect.Descriptor instead.
-func (*GetSignedMapRootResponse) Descriptor() ([]byte, []int) {
-	return file_trillian_map_api_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use GetMapRootSignaturesResponse.ProtoReflect.Descriptor instead.
+func (*GetMapRootSignaturesResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *GetSignedMapRootResponse) GetMapRoot() *SignedMapRoot {
+func (x *GetMapRootSignaturesResponse) GetMapRoot() *SignedMapRoot {
 	if x != nil {
 		return x.MapRoot
 	}
 	return nil
 }
 
-type InitMapRequest struct {
+func (x *GetMapRootSignaturesResponse) GetSignatures() []*MapRootSignature {
+	if x != nil {
+		return x.Signatures
+	}
+	return nil
+}
+
+type ListMapLeavesByKeyPrefixRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
-}
-
-func (x *InitMapRequest) Reset() {
-	*x = InitMapRequest{}
+	MapId    int64 `protobuf:"varint,1,opt,name=map_id,json=mapId,proto3" json:"map_id,omitempty"`
+	Revision int64 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	// key_prefix is the prefix of the keys of the leaves listed. An empty
+	// prefix lists all the leaves written with a key.
+	KeyPrefix []byte `protobuf:"bytes,3,opt,name=key_prefix,json=keyPrefix,proto3" json:"key_prefix,omitempty"`
+	// page_size is the maximum number of leaves returned. Zero means the
+	// default of the server. Responses may hold fewer leaves than this, to stay
+	// within the maximum size of responses.
+	PageSize int32 `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token continues listing from where the response it was returned in
+	// stopped. The rest of the request must be the same.
+	PageToken []byte `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+}
+
+func (x *ListMapLeavesByKeyPrefixRequest) Reset() {
+	*x = ListMapLeavesByKeyPrefixRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_trillian_map_api_proto_msgTypes[17]
+		mi := &file_trillian_map_api_proto_msgTypes[30]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *InitMapRequest) String() string {
+func (x *ListMapLeavesByKeyPrefixRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InitMapRequest) ProtoMessage() {}
+func (*ListMapLeavesByKeyPrefixRequest) ProtoMessage() {}
 
-func (x *InitMapRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_map_api_proto_msgTypes[17]
+func (x *ListMapLeavesByKeyPrefixRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[30]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1104,43 +1960,77 @@ func (x *InitMapRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InitMapRequest.ProtoReflect.Descriptor instead.
-func (*InitMapRequest) Descriptor() ([]byte, []int) {
-	return file_trillian_map_api_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use ListMapLeavesByKeyPrefixRequest.ProtoReflect.Descriptor instead.
+func (*ListMapLeavesByKeyPrefixRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *InitMapRequest) GetMapId() int64 {
+func (x *ListMapLeavesByKeyPrefixRequest) GetMapId() int64 {
 	if x != nil {
 		return x.MapId
 	}
 	return 0
 }
 
-type InitMapResponse struct {
+func (x *ListMapLeavesByKeyPrefixRequest) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *ListMapLeavesByKeyPrefixRequest) GetKeyPrefix() []byte {
+	if x != nil {
+		return x.KeyPrefix
+	}
+	return nil
+}
+
+func (x *ListMapLeavesByKeyPrefixRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListMapLeavesByKeyPrefixRequest) GetPageToken() []byte {
+	if x != nil {
+		return x.PageToken
+	}
+	return nil
+}
+
+type ListMapLeavesByKeyPrefixResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Created *SignedMapRoot `protobuf:"bytes,1,opt,name=created,proto3" json:"created,omitempty"`
+	MapRoot *SignedMapRoot `protobuf:"bytes,1,opt,name=map_root,json=mapRoot,proto3" json:"map_root,omitempty"`
+	// The leaves whose keys start with the prefix, ordered by key, and then by
+	// index, without their leaf hashes.
+	Leaves []*MapLeaf `protobuf:"bytes,2,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	// next_page_token is set if there may be more leaves to list, which the
+	// next request with it as its page_token returns.
+	NextPageToken []byte `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 }
 
-func (x *InitMapResponse) Reset() {
-	*x = InitMapResponse{}
+func (x *ListMapLeavesByKeyPrefixResponse) Reset() {
+	*x = ListMapLeavesByKeyPrefixResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_trillian_map_api_proto_msgTypes[18]
+		mi := &file_trillian_map_api_proto_msgTypes[31]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *InitMapResponse) String() string {
+func (x *ListMapLeavesByKeyPrefixResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InitMapResponse) ProtoMessage() {}
+func (*ListMapLeavesByKeyPrefixResponse) ProtoMessage() {}
 
-func (x *InitMapResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_map_api_proto_msgTypes[18]
+func (x *ListMapLeavesByKeyPrefixResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_map_api_proto_msgTypes[31]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1151,14 +2041,28 @@ func (x *InitMapResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InitMapResponse.ProtoReflect.Descriptor instead.
-func (*InitMapResponse) Descriptor() ([]byte, []int) {
-	return file_trillian_map_api_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use ListMapLeavesByKeyPrefixResponse.ProtoReflect.Descriptor instead.
+func (*ListMapLeavesByKeyPrefixResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_map_api_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *InitMapResponse) GetCreated() *SignedMapRoot {
+func (x *ListMapLeavesByKeyPrefixResponse) GetMapRoot() *SignedMapRoot {
 	if x != nil {
-		return x.Created
+		return x.MapRoot
+	}
+	return nil
+}
+
+func (x *ListMapLeavesByKeyPrefixResponse) GetLeaves() []*MapLeaf {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+func (x *ListMapLeavesByKeyPrefixResponse) GetNextPageToken() []byte {
+	if x != nil {
+		return x.NextPageToken
 	}
 	return nil
 }
@@ -1171,207 +2075,361 @@ var file_trillian_map_api_proto_rawDesc = []byte{
 	0x61, 0x6e, 0x1a, 0x0e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61,
 	0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x22, 0x7a, 0x0a, 0x07, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x12, 0x14, 0x0a, 0x05, 0x69,
-	0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65,
-	0x78, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6c, 0x65, 0x61, 0x66, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1d,
-	0x0a, 0x0a, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x09, 0x6c, 0x65, 0x61, 0x66, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1d, 0x0a,
-	0x0a, 0x65, 0x78, 0x74, 0x72, 0x61, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x09, 0x65, 0x78, 0x74, 0x72, 0x61, 0x44, 0x61, 0x74, 0x61, 0x22, 0x36, 0x0a, 0x09,
-	0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x06, 0x6c, 0x65, 0x61,
-	0x76, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74, 0x72, 0x69, 0x6c,
-	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x06, 0x6c, 0x65,
-	0x61, 0x76, 0x65, 0x73, 0x22, 0x57, 0x0a, 0x10, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x49,
-	0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x04, 0x6c, 0x65, 0x61, 0x66,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61,
-	0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x04, 0x6c, 0x65, 0x61, 0x66, 0x12,
-	0x1c, 0x0a, 0x09, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x03,
-	0x28, 0x0c, 0x52, 0x09, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x48, 0x0a,
+	0x22, 0x8c, 0x01, 0x0a, 0x07, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x12, 0x14, 0x0a, 0x05,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6c, 0x65, 0x61, 0x66, 0x48, 0x61, 0x73, 0x68, 0x12,
+	0x1d, 0x0a, 0x0a, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x09, 0x6c, 0x65, 0x61, 0x66, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1d,
+	0x0a, 0x0a, 0x65, 0x78, 0x74, 0x72, 0x61, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x09, 0x65, 0x78, 0x74, 0x72, 0x61, 0x44, 0x61, 0x74, 0x61, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22,
+	0x36, 0x0a, 0x09, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x06,
+	0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74,
+	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52,
+	0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x22, 0x82, 0x01, 0x0a, 0x10, 0x4d, 0x61, 0x70, 0x4c,
+	0x65, 0x61, 0x66, 0x49, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x04,
+	0x6c, 0x65, 0x61, 0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74, 0x72, 0x69,
+	0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x04, 0x6c,
+	0x65, 0x61, 0x66, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x09, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x29, 0x0a, 0x10, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x62,
+	0x69, 0x74, 0x6d, 0x61, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x69, 0x6e, 0x63,
+	0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x69, 0x74, 0x6d, 0x61, 0x70, 0x22, 0x8e, 0x01, 0x0a,
 	0x13, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71,
 	0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01,
 	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69,
 	0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65,
-	0x78, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x22, 0x40, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x4d, 0x61,
-	0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06,
-	0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61,
-	0x70, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x22, 0x66, 0x0a, 0x1b, 0x47, 0x65, 0x74,
-	0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x78, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x5f, 0x70, 0x72, 0x6f, 0x6f,
+	0x66, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x63,
+	0x74, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x22, 0x67, 0x0a,
+	0x11, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12,
+	0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x5f, 0x70, 0x72, 0x6f, 0x6f, 0x66,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74,
+	0x50, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x22, 0x8d, 0x01, 0x0a, 0x1b, 0x47, 0x65, 0x74, 0x4d, 0x61,
+	0x70, 0x4c, 0x65, 0x61, 0x66, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x14, 0x0a,
+	0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x5f, 0x70, 0x72, 0x6f, 0x6f, 0x66,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74,
+	0x50, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x22, 0xae, 0x01, 0x0a, 0x1d, 0x47, 0x65, 0x74, 0x4d, 0x61,
+	0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
 	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f,
 	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12,
-	0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x05,
 	0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
 	0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
-	0x6e, 0x22, 0x68, 0x0a, 0x1d, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65,
-	0x73, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64,
-	0x65, 0x78, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12,
-	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x92, 0x01, 0x0a, 0x12,
-	0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x48, 0x0a, 0x12, 0x6d, 0x61, 0x70, 0x5f, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x69,
-	0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
-	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61,
-	0x66, 0x49, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x6d, 0x61, 0x70, 0x4c,
-	0x65, 0x61, 0x66, 0x49, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x32, 0x0a, 0x08,
-	0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17,
-	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64,
-	0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74,
-	0x22, 0x94, 0x01, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65,
-	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x12, 0x6d, 0x61, 0x70,
-	0x5f, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x18,
-	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
-	0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x49, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f,
-	0x6e, 0x52, 0x10, 0x6d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x49, 0x6e, 0x63, 0x6c, 0x75, 0x73,
-	0x69, 0x6f, 0x6e, 0x12, 0x32, 0x0a, 0x08, 0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
-	0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07,
-	0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x22, 0x8d, 0x01, 0x0a, 0x1f, 0x47, 0x65, 0x74, 0x4c,
-	0x61, 0x73, 0x74, 0x49, 0x6e, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69,
-	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d,
-	0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70,
-	0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16,
-	0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06,
-	0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78,
-	0x5f, 0x62, 0x69, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x70, 0x72, 0x65,
-	0x66, 0x69, 0x78, 0x42, 0x69, 0x74, 0x73, 0x22, 0x9b, 0x01, 0x0a, 0x13, 0x53, 0x65, 0x74, 0x4d,
-	0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73,
-	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61,
+	0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x5f, 0x70, 0x72, 0x6f, 0x6f,
+	0x66, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x63,
+	0x74, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x22, 0x90, 0x01, 0x0a, 0x1e, 0x47, 0x65, 0x74, 0x4d,
+	0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61,
+	0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c,
+	0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x03, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x5f, 0x70,
+	0x72, 0x6f, 0x6f, 0x66, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x63, 0x6f, 0x6d,
+	0x70, 0x61, 0x63, 0x74, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x22, 0x92, 0x01, 0x0a, 0x12, 0x47,
+	0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x48, 0x0a, 0x12, 0x6d, 0x61, 0x70, 0x5f, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x69, 0x6e,
+	0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66,
+	0x49, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x6d, 0x61, 0x70, 0x4c, 0x65,
+	0x61, 0x66, 0x49, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x32, 0x0a, 0x08, 0x6d,
+	0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d,
+	0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x22,
+	0xbc, 0x01, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x12, 0x6d, 0x61, 0x70, 0x5f,
+	0x6c, 0x65, 0x61, 0x66, 0x5f, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e,
+	0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x49, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x10, 0x6d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x49, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x32, 0x0a, 0x08, 0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e,
+	0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07, 0x6d,
+	0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70,
+	0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x5f,
+	0x0a, 0x1f, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79,
+	0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x3c, 0x0a, 0x09, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e,
+	0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x52, 0x09, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22,
+	0x65, 0x0a, 0x1a, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x43,
+	0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a,
+	0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d,
+	0x61, 0x70, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65,
+	0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65,
+	0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xb5, 0x01, 0x0a, 0x1b, 0x47, 0x65, 0x74, 0x4d, 0x61,
+	0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61,
 	0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65,
-	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1a, 0x0a,
-	0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x4a,
-	0x04, 0x08, 0x04, 0x10, 0x05, 0x22, 0x4a, 0x0a, 0x14, 0x53, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c,
-	0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a,
-	0x08, 0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x17, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65,
-	0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f,
-	0x74, 0x22, 0x9e, 0x01, 0x0a, 0x15, 0x57, 0x72, 0x69, 0x74, 0x65, 0x4d, 0x61, 0x70, 0x4c, 0x65,
-	0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c,
+	0x52, 0x05, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x6f, 0x66,
+	0x5f, 0x62, 0x69, 0x74, 0x6d, 0x61, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x70,
+	0x72, 0x6f, 0x6f, 0x66, 0x42, 0x69, 0x74, 0x6d, 0x61, 0x70, 0x12, 0x32, 0x0a, 0x08, 0x6d, 0x61,
+	0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74,
+	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61,
+	0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x22, 0x8d,
+	0x01, 0x0a, 0x1f, 0x47, 0x65, 0x74, 0x4c, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x1f, 0x0a,
+	0x0b, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x5f, 0x62, 0x69, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0a, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x42, 0x69, 0x74, 0x73, 0x22, 0x9b,
+	0x01, 0x0a, 0x13, 0x53, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x29, 0x0a,
+	0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66,
+	0x52, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61,
+	0x64, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61,
+	0x64, 0x61, 0x74, 0x61, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x4a, 0x04, 0x08, 0x04, 0x10, 0x05, 0x22, 0x4a, 0x0a, 0x14,
+	0x53, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x08, 0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61,
+	0x6e, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52,
+	0x07, 0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x22, 0x9e, 0x01, 0x0a, 0x15, 0x57, 0x72, 0x69,
+	0x74, 0x65, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x06, 0x6c, 0x65, 0x61,
+	0x76, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x06, 0x6c, 0x65,
+	0x61, 0x76, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x12, 0x27, 0x0a, 0x0f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x65, 0x78, 0x70, 0x65, 0x63,
+	0x74, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x34, 0x0a, 0x16, 0x57, 0x72, 0x69,
+	0x74, 0x65, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22,
+	0x5c, 0x0a, 0x18, 0x51, 0x75, 0x65, 0x75, 0x65, 0x4d, 0x61, 0x70, 0x4d, 0x75, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d,
 	0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70,
 	0x49, 0x64, 0x12, 0x29, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
 	0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61,
-	0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x12, 0x1a, 0x0a,
-	0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x27, 0x0a, 0x0f, 0x65, 0x78, 0x70,
-	0x65, 0x63, 0x74, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x0e, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69,
-	0x6f, 0x6e, 0x22, 0x34, 0x0a, 0x16, 0x57, 0x72, 0x69, 0x74, 0x65, 0x4d, 0x61, 0x70, 0x4c, 0x65,
-	0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08,
-	0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08,
-	0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x30, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x53,
-	0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x22, 0x56, 0x0a, 0x21, 0x47, 0x65,
-	0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x42, 0x79,
-	0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69,
-	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69,
-	0x6f, 0x6e, 0x22, 0x4e, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d,
-	0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32,
-	0x0a, 0x08, 0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x17, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x69, 0x67, 0x6e,
-	0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x52, 0x6f,
-	0x6f, 0x74, 0x22, 0x27, 0x0a, 0x0e, 0x49, 0x6e, 0x69, 0x74, 0x4d, 0x61, 0x70, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x22, 0x44, 0x0a, 0x0f, 0x49,
-	0x6e, 0x69, 0x74, 0x4d, 0x61, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31,
-	0x0a, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x17, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65,
-	0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x64, 0x32, 0xc3, 0x08, 0x0a, 0x0b, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x4d, 0x61,
-	0x70, 0x12, 0x46, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x66, 0x12, 0x1b, 0x2e, 0x74,
-	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65,
-	0x61, 0x66, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x74, 0x72, 0x69, 0x6c,
-	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x5a, 0x0a, 0x11, 0x47, 0x65, 0x74,
-	0x4c, 0x65, 0x61, 0x66, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x25,
+	0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x22, 0x1b, 0x0a,
+	0x19, 0x51, 0x75, 0x65, 0x75, 0x65, 0x4d, 0x61, 0x70, 0x4d, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x30, 0x0a, 0x17, 0x47, 0x65,
+	0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x22, 0x56, 0x0a, 0x21,
+	0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74,
+	0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x22, 0x4e, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65,
+	0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x32, 0x0a, 0x08, 0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x69,
+	0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07, 0x6d, 0x61, 0x70,
+	0x52, 0x6f, 0x6f, 0x74, 0x22, 0x27, 0x0a, 0x0e, 0x49, 0x6e, 0x69, 0x74, 0x4d, 0x61, 0x70, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x22, 0x44, 0x0a,
+	0x0f, 0x49, 0x6e, 0x69, 0x74, 0x4d, 0x61, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x31, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x17, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x69, 0x67,
+	0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x22, 0x69, 0x0a, 0x10, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x69, 0x74, 0x6e, 0x65,
+	0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x77, 0x69, 0x74, 0x6e, 0x65, 0x73,
+	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79,
+	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x89,
+	0x01, 0x0a, 0x1a, 0x41, 0x64, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a,
+	0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d,
+	0x61, 0x70, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x38, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d,
+	0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52,
+	0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x1d, 0x0a, 0x1b, 0x41, 0x64,
+	0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x50, 0x0a, 0x1b, 0x47, 0x65, 0x74,
+	0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x8e, 0x01, 0x0a, 0x1c,
+	0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x08,
+	0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17,
+	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64,
+	0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74,
+	0x12, 0x3a, 0x0a, 0x0a, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e,
+	0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x52, 0x0a, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x22, 0xaf, 0x01, 0x0a,
+	0x1f, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79,
+	0x4b, 0x65, 0x79, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x15, 0x0a, 0x06, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x05, 0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x50, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12,
+	0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0xa9,
+	0x01, 0x0a, 0x20, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73,
+	0x42, 0x79, 0x4b, 0x65, 0x79, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x08, 0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
+	0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x07,
+	0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x29, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x76, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69,
+	0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x06, 0x6c, 0x65, 0x61, 0x76,
+	0x65, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x6e, 0x65, 0x78,
+	0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x32, 0xd6, 0x0c, 0x0a, 0x0b, 0x54,
+	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x4d, 0x61, 0x70, 0x12, 0x46, 0x0a, 0x07, 0x47, 0x65,
+	0x74, 0x4c, 0x65, 0x61, 0x66, 0x12, 0x1b, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
+	0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65,
+	0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x12, 0x5a, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x66, 0x42, 0x79, 0x52,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69,
+	0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x42, 0x79, 0x52,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c,
 	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70,
-	0x4c, 0x65, 0x61, 0x66, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
-	0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4c, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x76,
-	0x65, 0x73, 0x12, 0x1d, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65,
-	0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x1e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74,
-	0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x22, 0x00, 0x12, 0x60, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73,
-	0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x27, 0x2e, 0x74, 0x72, 0x69,
+	0x4c, 0x65, 0x61, 0x66, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4c,
+	0x0a, 0x09, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x12, 0x1d, 0x2e, 0x74, 0x72,
+	0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61,
+	0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x74, 0x72, 0x69,
 	0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76,
-	0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47,
-	0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x5f, 0x0a, 0x1a, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x76,
-	0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x4e, 0x6f, 0x50, 0x72,
-	0x6f, 0x6f, 0x66, 0x12, 0x27, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47,
-	0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76,
-	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x74,
-	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65,
-	0x73, 0x22, 0x03, 0x88, 0x02, 0x01, 0x12, 0x9e, 0x01, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x4c, 0x61,
-	0x73, 0x74, 0x49, 0x6e, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73,
-	0x69, 0x6f, 0x6e, 0x12, 0x29, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47,
-	0x65, 0x74, 0x4c, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x42, 0x79, 0x52,
-	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11,
-	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61,
-	0x66, 0x22, 0x44, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x3e, 0x12, 0x3c, 0x2f, 0x76, 0x31, 0x62, 0x65,
-	0x74, 0x61, 0x31, 0x2f, 0x6d, 0x61, 0x70, 0x73, 0x2f, 0x7b, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64,
-	0x7d, 0x2f, 0x72, 0x6f, 0x6f, 0x74, 0x73, 0x2f, 0x7b, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
-	0x6e, 0x7d, 0x2f, 0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x3a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x69,
-	0x6e, 0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x4f, 0x0a, 0x09, 0x53, 0x65, 0x74, 0x4c, 0x65,
-	0x61, 0x76, 0x65, 0x73, 0x12, 0x1d, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e,
-	0x53, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53,
-	0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x03, 0x88, 0x02, 0x01, 0x12, 0x86, 0x01, 0x0a, 0x10, 0x47, 0x65, 0x74,
-	0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x21, 0x2e,
-	0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e,
-	0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x22, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53,
-	0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x25, 0x12, 0x23, 0x2f, 0x76,
-	0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x6d, 0x61, 0x70, 0x73, 0x2f, 0x7b, 0x6d, 0x61, 0x70,
-	0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x72, 0x6f, 0x6f, 0x74, 0x73, 0x3a, 0x6c, 0x61, 0x74, 0x65, 0x73,
-	0x74, 0x12, 0x9e, 0x01, 0x0a, 0x1a, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d,
-	0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
-	0x12, 0x2b, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53,
-	0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x42, 0x79, 0x52, 0x65,
-	0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e,
-	0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e,
-	0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x22, 0x2f, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x29, 0x12, 0x27, 0x2f, 0x76, 0x31, 0x62, 0x65,
-	0x74, 0x61, 0x31, 0x2f, 0x6d, 0x61, 0x70, 0x73, 0x2f, 0x7b, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64,
-	0x7d, 0x2f, 0x72, 0x6f, 0x6f, 0x74, 0x73, 0x2f, 0x7b, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
-	0x6e, 0x7d, 0x12, 0x63, 0x0a, 0x07, 0x49, 0x6e, 0x69, 0x74, 0x4d, 0x61, 0x70, 0x12, 0x18, 0x2e,
-	0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x49, 0x6e, 0x69, 0x74, 0x4d, 0x61, 0x70,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69,
-	0x61, 0x6e, 0x2e, 0x49, 0x6e, 0x69, 0x74, 0x4d, 0x61, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x22, 0x1b, 0x2f, 0x76, 0x31, 0x62,
-	0x65, 0x74, 0x61, 0x31, 0x2f, 0x6d, 0x61, 0x70, 0x73, 0x2f, 0x7b, 0x6d, 0x61, 0x70, 0x5f, 0x69,
-	0x64, 0x7d, 0x3a, 0x69, 0x6e, 0x69, 0x74, 0x32, 0xbd, 0x01, 0x0a, 0x10, 0x54, 0x72, 0x69, 0x6c,
-	0x6c, 0x69, 0x61, 0x6e, 0x4d, 0x61, 0x70, 0x57, 0x72, 0x69, 0x74, 0x65, 0x12, 0x55, 0x0a, 0x13,
+	0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x60, 0x0a, 0x13,
 	0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73,
 	0x69, 0x6f, 0x6e, 0x12, 0x27, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47,
 	0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76,
-	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x74,
-	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65,
-	0x73, 0x22, 0x00, 0x12, 0x52, 0x0a, 0x0b, 0x57, 0x72, 0x69, 0x74, 0x65, 0x4c, 0x65, 0x61, 0x76,
-	0x65, 0x73, 0x12, 0x1f, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x57, 0x72,
-	0x69, 0x74, 0x65, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x57,
-	0x72, 0x69, 0x74, 0x65, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x4e, 0x0a, 0x19, 0x63, 0x6f, 0x6d, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x42, 0x13, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x4d, 0x61,
-	0x70, 0x41, 0x70, 0x69, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x1a, 0x67, 0x69, 0x74,
-	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x74,
-	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x74,
+	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65,
+	0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x6d,
+	0x0a, 0x14, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x28, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61,
+	0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79,
+	0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x29, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d,
+	0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x61, 0x0a,
+	0x10, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63,
+	0x74, 0x12, 0x24, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74,
+	0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69,
+	0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x43,
+	0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
+	0x12, 0x5f, 0x0a, 0x1a, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79, 0x52,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x4e, 0x6f, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x27,
+	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70,
+	0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69,
+	0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x22, 0x03, 0x88, 0x02,
+	0x01, 0x12, 0x9e, 0x01, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x4c, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x52,
+	0x61, 0x6e, 0x67, 0x65, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x29,
+	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x61, 0x73,
+	0x74, 0x49, 0x6e, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x22, 0x44, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x3e, 0x12, 0x3c, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x6d,
+	0x61, 0x70, 0x73, 0x2f, 0x7b, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x72, 0x6f, 0x6f,
+	0x74, 0x73, 0x2f, 0x7b, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x7d, 0x2f, 0x6c, 0x65,
+	0x61, 0x76, 0x65, 0x73, 0x3a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x69, 0x6e, 0x5f, 0x72, 0x61, 0x6e,
+	0x67, 0x65, 0x12, 0x4f, 0x0a, 0x09, 0x53, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x12,
+	0x1d, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x65, 0x74, 0x4d, 0x61,
+	0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e,
+	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x53, 0x65, 0x74, 0x4d, 0x61, 0x70,
+	0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x03,
+	0x88, 0x02, 0x01, 0x12, 0x86, 0x01, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65,
+	0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x21, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70,
+	0x52, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x74, 0x72,
+	0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64,
+	0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x2b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x25, 0x12, 0x23, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61,
+	0x31, 0x2f, 0x6d, 0x61, 0x70, 0x73, 0x2f, 0x7b, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x7d, 0x2f,
+	0x72, 0x6f, 0x6f, 0x74, 0x73, 0x3a, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x12, 0x9e, 0x01, 0x0a,
+	0x1a, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f,
+	0x74, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2b, 0x2e, 0x74, 0x72,
+	0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64,
+	0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70,
+	0x52, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2f, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x29, 0x12, 0x27, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f, 0x6d,
+	0x61, 0x70, 0x73, 0x2f, 0x7b, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x72, 0x6f, 0x6f,
+	0x74, 0x73, 0x2f, 0x7b, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x7d, 0x12, 0x63, 0x0a,
+	0x07, 0x49, 0x6e, 0x69, 0x74, 0x4d, 0x61, 0x70, 0x12, 0x18, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x49, 0x6e, 0x69, 0x74, 0x4d, 0x61, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x19, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x49, 0x6e,
+	0x69, 0x74, 0x4d, 0x61, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x22, 0x1b, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f,
+	0x6d, 0x61, 0x70, 0x73, 0x2f, 0x7b, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x7d, 0x3a, 0x69, 0x6e,
+	0x69, 0x74, 0x12, 0x64, 0x0a, 0x13, 0x41, 0x64, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74,
+	0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x24, 0x2e, 0x74, 0x72, 0x69, 0x6c,
+	0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x41, 0x64, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x25, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x41, 0x64, 0x64, 0x4d, 0x61,
+	0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x67, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x4d,
+	0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73,
+	0x12, 0x25, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d,
+	0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69,
+	0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x12, 0x70, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42,
+	0x79, 0x4b, 0x65, 0x79, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x29, 0x2e, 0x74, 0x72, 0x69,
+	0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61,
+	0x76, 0x65, 0x73, 0x42, 0x79, 0x4b, 0x65, 0x79, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
+	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79,
+	0x4b, 0x65, 0x79, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x32, 0x9d, 0x02, 0x0a, 0x10, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e,
+	0x4d, 0x61, 0x70, 0x57, 0x72, 0x69, 0x74, 0x65, 0x12, 0x55, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x4c,
+	0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x27, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x61,
+	0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x42, 0x79, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x22, 0x00, 0x12,
+	0x52, 0x0a, 0x0b, 0x57, 0x72, 0x69, 0x74, 0x65, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x12, 0x1f,
+	0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x4d,
+	0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x20, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65,
+	0x4d, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x12, 0x5e, 0x0a, 0x11, 0x51, 0x75, 0x65, 0x75, 0x65, 0x4d, 0x61, 0x70, 0x4d,
+	0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x22, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x2e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x4d, 0x61, 0x70, 0x4d, 0x75, 0x74, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x74,
+	0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x4d, 0x61, 0x70,
+	0x4d, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x42, 0x4e, 0x0a, 0x19, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x42, 0x13, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x4d, 0x61, 0x70, 0x41, 0x70, 0x69,
+	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x1a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x74, 0x72, 0x69, 0x6c, 0x6c,
+	0x69, 0x61, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1386,7 +2444,7 @@ func file_trillian_map_api_proto_rawDescGZIP() []byte {
 	return file_trillian_map_api_proto_rawDescData
 }
 
-var file_trillian_map_api_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_trillian_map_api_proto_msgTypes = make([]protoimpl.MessageInfo, 32)
 var file_trillian_map_api_proto_goTypes = []interface{}{
 	(*MapLeaf)(nil),                           // 0: trillian.MapLeaf
 	(*MapLeaves)(nil),                         // 1: trillian.MapLeaves
@@ -1395,61 +2453,95 @@ var file_trillian_map_api_proto_goTypes = []interface{}{
 	(*GetMapLeafRequest)(nil),                 // 4: trillian.GetMapLeafRequest
 	(*GetMapLeafByRevisionRequest)(nil),       // 5: trillian.GetMapLeafByRevisionRequest
 	(*GetMapLeavesByRevisionRequest)(nil),     // 6: trillian.GetMapLeavesByRevisionRequest
-	(*GetMapLeafResponse)(nil),                // 7: trillian.GetMapLeafResponse
-	(*GetMapLeavesResponse)(nil),              // 8: trillian.GetMapLeavesResponse
-	(*GetLastInRangeByRevisionRequest)(nil),   // 9: trillian.GetLastInRangeByRevisionRequest
-	(*SetMapLeavesRequest)(nil),               // 10: trillian.SetMapLeavesRequest
-	(*SetMapLeavesResponse)(nil),              // 11: trillian.SetMapLeavesResponse
-	(*WriteMapLeavesRequest)(nil),             // 12: trillian.WriteMapLeavesRequest
-	(*WriteMapLeavesResponse)(nil),            // 13: trillian.WriteMapLeavesResponse
-	(*GetSignedMapRootRequest)(nil),           // 14: trillian.GetSignedMapRootRequest
-	(*GetSignedMapRootByRevisionRequest)(nil), // 15: trillian.GetSignedMapRootByRevisionRequest
-	(*GetSignedMapRootResponse)(nil),          // 16: trillian.GetSignedMapRootResponse
-	(*InitMapRequest)(nil),                    // 17: trillian.InitMapRequest
-	(*InitMapResponse)(nil),                   // 18: trillian.InitMapResponse
-	(*SignedMapRoot)(nil),                     // 19: trillian.SignedMapRoot
+	(*GetMapLeavesByRevisionsRequest)(nil),    // 7: trillian.GetMapLeavesByRevisionsRequest
+	(*GetMapLeafResponse)(nil),                // 8: trillian.GetMapLeafResponse
+	(*GetMapLeavesResponse)(nil),              // 9: trillian.GetMapLeavesResponse
+	(*GetMapLeavesByRevisionsResponse)(nil),   // 10: trillian.GetMapLeavesByRevisionsResponse
+	(*GetMapLeavesCompactRequest)(nil),        // 11: trillian.GetMapLeavesCompactRequest
+	(*GetMapLeavesCompactResponse)(nil),       // 12: trillian.GetMapLeavesCompactResponse
+	(*GetLastInRangeByRevisionRequest)(nil),   // 13: trillian.GetLastInRangeByRevisionRequest
+	(*SetMapLeavesRequest)(nil),               // 14: trillian.SetMapLeavesRequest
+	(*SetMapLeavesResponse)(nil),              // 15: trillian.SetMapLeavesResponse
+	(*WriteMapLeavesRequest)(nil),             // 16: trillian.WriteMapLeavesRequest
+	(*WriteMapLeavesResponse)(nil),            // 17: trillian.WriteMapLeavesResponse
+	(*QueueMapMutationsRequest)(nil),          // 18: trillian.QueueMapMutationsRequest
+	(*QueueMapMutationsResponse)(nil),         // 19: trillian.QueueMapMutationsResponse
+	(*GetSignedMapRootRequest)(nil),           // 20: trillian.GetSignedMapRootRequest
+	(*GetSignedMapRootByRevisionRequest)(nil), // 21: trillian.GetSignedMapRootByRevisionRequest
+	(*GetSignedMapRootResponse)(nil),          // 22: trillian.GetSignedMapRootResponse
+	(*InitMapRequest)(nil),                    // 23: trillian.InitMapRequest
+	(*InitMapResponse)(nil),                   // 24: trillian.InitMapResponse
+	(*MapRootSignature)(nil),                  // 25: trillian.MapRootSignature
+	(*AddMapRootSignatureRequest)(nil),        // 26: trillian.AddMapRootSignatureRequest
+	(*AddMapRootSignatureResponse)(nil),       // 27: trillian.AddMapRootSignatureResponse
+	(*GetMapRootSignaturesRequest)(nil),       // 28: trillian.GetMapRootSignaturesRequest
+	(*GetMapRootSignaturesResponse)(nil),      // 29: trillian.GetMapRootSignaturesResponse
+	(*ListMapLeavesByKeyPrefixRequest)(nil),   // 30: trillian.ListMapLeavesByKeyPrefixRequest
+	(*ListMapLeavesByKeyPrefixResponse)(nil),  // 31: trillian.ListMapLeavesByKeyPrefixResponse
+	(*SignedMapRoot)(nil),                     // 32: trillian.SignedMapRoot
 }
 var file_trillian_map_api_proto_depIdxs = []int32{
 	0,  // 0: trillian.MapLeaves.leaves:type_name -> trillian.MapLeaf
 	0,  // 1: trillian.MapLeafInclusion.leaf:type_name -> trillian.MapLeaf
 	2,  // 2: trillian.GetMapLeafResponse.map_leaf_inclusion:type_name -> trillian.MapLeafInclusion
-	19, // 3: trillian.GetMapLeafResponse.map_root:type_name -> trillian.SignedMapRoot
+	32, // 3: trillian.GetMapLeafResponse.map_root:type_name -> trillian.SignedMapRoot
 	2,  // 4: trillian.GetMapLeavesResponse.map_leaf_inclusion:type_name -> trillian.MapLeafInclusion
-	19, // 5: trillian.GetMapLeavesResponse.map_root:type_name -> trillian.SignedMapRoot
-	0,  // 6: trillian.SetMapLeavesRequest.leaves:type_name -> trillian.MapLeaf
-	19, // 7: trillian.SetMapLeavesResponse.map_root:type_name -> trillian.SignedMapRoot
-	0,  // 8: trillian.WriteMapLeavesRequest.leaves:type_name -> trillian.MapLeaf
-	19, // 9: trillian.GetSignedMapRootResponse.map_root:type_name -> trillian.SignedMapRoot
-	19, // 10: trillian.InitMapResponse.created:type_name -> trillian.SignedMapRoot
-	4,  // 11: trillian.TrillianMap.GetLeaf:input_type -> trillian.GetMapLeafRequest
-	5,  // 12: trillian.TrillianMap.GetLeafByRevision:input_type -> trillian.GetMapLeafByRevisionRequest
-	3,  // 13: trillian.TrillianMap.GetLeaves:input_type -> trillian.GetMapLeavesRequest
-	6,  // 14: trillian.TrillianMap.GetLeavesByRevision:input_type -> trillian.GetMapLeavesByRevisionRequest
-	6,  // 15: trillian.TrillianMap.GetLeavesByRevisionNoProof:input_type -> trillian.GetMapLeavesByRevisionRequest
-	9,  // 16: trillian.TrillianMap.GetLastInRangeByRevision:input_type -> trillian.GetLastInRangeByRevisionRequest
-	10, // 17: trillian.TrillianMap.SetLeaves:input_type -> trillian.SetMapLeavesRequest
-	14, // 18: trillian.TrillianMap.GetSignedMapRoot:input_type -> trillian.GetSignedMapRootRequest
-	15, // 19: trillian.TrillianMap.GetSignedMapRootByRevision:input_type -> trillian.GetSignedMapRootByRevisionRequest
-	17, // 20: trillian.TrillianMap.InitMap:input_type -> trillian.InitMapRequest
-	6,  // 21: trillian.TrillianMapWrite.GetLeavesByRevision:input_type -> trillian.GetMapLeavesByRevisionRequest
-	12, // 22: trillian.TrillianMapWrite.WriteLeaves:input_type -> trillian.WriteMapLeavesRequest
-	7,  // 23: trillian.TrillianMap.GetLeaf:output_type -> trillian.GetMapLeafResponse
-	7,  // 24: trillian.TrillianMap.GetLeafByRevision:output_type -> trillian.GetMapLeafResponse
-	8,  // 25: trillian.TrillianMap.GetLeaves:output_type -> trillian.GetMapLeavesResponse
-	8,  // 26: trillian.TrillianMap.GetLeavesByRevision:output_type -> trillian.GetMapLeavesResponse
-	1,  // 27: trillian.TrillianMap.GetLeavesByRevisionNoProof:output_type -> trillian.MapLeaves
-	0,  // 28: trillian.TrillianMap.GetLastInRangeByRevision:output_type -> trillian.MapLeaf
-	11, // 29: trillian.TrillianMap.SetLeaves:output_type -> trillian.SetMapLeavesResponse
-	16, // 30: trillian.TrillianMap.GetSignedMapRoot:output_type -> trillian.GetSignedMapRootResponse
-	16, // 31: trillian.TrillianMap.GetSignedMapRootByRevision:output_type -> trillian.GetSignedMapRootResponse
-	18, // 32: trillian.TrillianMap.InitMap:output_type -> trillian.InitMapResponse
-	1,  // 33: trillian.TrillianMapWrite.GetLeavesByRevision:output_type -> trillian.MapLeaves
-	13, // 34: trillian.TrillianMapWrite.WriteLeaves:output_type -> trillian.WriteMapLeavesResponse
-	23, // [23:35] is the sub-list for method output_type
-	11, // [11:23] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	32, // 5: trillian.GetMapLeavesResponse.map_root:type_name -> trillian.SignedMapRoot
+	9,  // 6: trillian.GetMapLeavesByRevisionsResponse.revisions:type_name -> trillian.GetMapLeavesResponse
+	0,  // 7: trillian.GetMapLeavesCompactResponse.leaves:type_name -> trillian.MapLeaf
+	32, // 8: trillian.GetMapLeavesCompactResponse.map_root:type_name -> trillian.SignedMapRoot
+	0,  // 9: trillian.SetMapLeavesRequest.leaves:type_name -> trillian.MapLeaf
+	32, // 10: trillian.SetMapLeavesResponse.map_root:type_name -> trillian.SignedMapRoot
+	0,  // 11: trillian.WriteMapLeavesRequest.leaves:type_name -> trillian.MapLeaf
+	0,  // 12: trillian.QueueMapMutationsRequest.leaves:type_name -> trillian.MapLeaf
+	32, // 13: trillian.GetSignedMapRootResponse.map_root:type_name -> trillian.SignedMapRoot
+	32, // 14: trillian.InitMapResponse.created:type_name -> trillian.SignedMapRoot
+	25, // 15: trillian.AddMapRootSignatureRequest.signature:type_name -> trillian.MapRootSignature
+	32, // 16: trillian.GetMapRootSignaturesResponse.map_root:type_name -> trillian.SignedMapRoot
+	25, // 17: trillian.GetMapRootSignaturesResponse.signatures:type_name -> trillian.MapRootSignature
+	32, // 18: trillian.ListMapLeavesByKeyPrefixResponse.map_root:type_name -> trillian.SignedMapRoot
+	0,  // 19: trillian.ListMapLeavesByKeyPrefixResponse.leaves:type_name -> trillian.MapLeaf
+	4,  // 20: trillian.TrillianMap.GetLeaf:input_type -> trillian.GetMapLeafRequest
+	5,  // 21: trillian.TrillianMap.GetLeafByRevision:input_type -> trillian.GetMapLeafByRevisionRequest
+	3,  // 22: trillian.TrillianMap.GetLeaves:input_type -> trillian.GetMapLeavesRequest
+	6,  // 23: trillian.TrillianMap.GetLeavesByRevision:input_type -> trillian.GetMapLeavesByRevisionRequest
+	7,  // 24: trillian.TrillianMap.GetLeavesByRevisions:input_type -> trillian.GetMapLeavesByRevisionsRequest
+	11, // 25: trillian.TrillianMap.GetLeavesCompact:input_type -> trillian.GetMapLeavesCompactRequest
+	6,  // 26: trillian.TrillianMap.GetLeavesByRevisionNoProof:input_type -> trillian.GetMapLeavesByRevisionRequest
+	13, // 27: trillian.TrillianMap.GetLastInRangeByRevision:input_type -> trillian.GetLastInRangeByRevisionRequest
+	14, // 28: trillian.TrillianMap.SetLeaves:input_type -> trillian.SetMapLeavesRequest
+	20, // 29: trillian.TrillianMap.GetSignedMapRoot:input_type -> trillian.GetSignedMapRootRequest
+	21, // 30: trillian.TrillianMap.GetSignedMapRootByRevision:input_type -> trillian.GetSignedMapRootByRevisionRequest
+	23, // 31: trillian.TrillianMap.InitMap:input_type -> trillian.InitMapRequest
+	26, // 32: trillian.TrillianMap.AddMapRootSignature:input_type -> trillian.AddMapRootSignatureRequest
+	28, // 33: trillian.TrillianMap.GetMapRootSignatures:input_type -> trillian.GetMapRootSignaturesRequest
+	30, // 34: trillian.TrillianMap.ListLeavesByKeyPrefix:input_type -> trillian.ListMapLeavesByKeyPrefixRequest
+	6,  // 35: trillian.TrillianMapWrite.GetLeavesByRevision:input_type -> trillian.GetMapLeavesByRevisionRequest
+	16, // 36: trillian.TrillianMapWrite.WriteLeaves:input_type -> trillian.WriteMapLeavesRequest
+	18, // 37: trillian.TrillianMapWrite.QueueMapMutations:input_type -> trillian.QueueMapMutationsRequest
+	8,  // 38: trillian.TrillianMap.GetLeaf:output_type -> trillian.GetMapLeafResponse
+	8,  // 39: trillian.TrillianMap.GetLeafByRevision:output_type -> trillian.GetMapLeafResponse
+	9,  // 40: trillian.TrillianMap.GetLeaves:output_type -> trillian.GetMapLeavesResponse
+	9,  // 41: trillian.TrillianMap.GetLeavesByRevision:output_type -> trillian.GetMapLeavesResponse
+	10, // 42: trillian.TrillianMap.GetLeavesByRevisions:output_type -> trillian.GetMapLeavesByRevisionsResponse
+	12, // 43: trillian.TrillianMap.GetLeavesCompact:output_type -> trillian.GetMapLeavesCompactResponse
+	1,  // 44: trillian.TrillianMap.GetLeavesByRevisionNoProof:output_type -> trillian.MapLeaves
+	0,  // 45: trillian.TrillianMap.GetLastInRangeByRevision:output_type -> trillian.MapLeaf
+	15, // 46: trillian.TrillianMap.SetLeaves:output_type -> trillian.SetMapLeavesResponse
+	22, // 47: trillian.TrillianMap.GetSignedMapRoot:output_type -> trillian.GetSignedMapRootResponse
+	22, // 48: trillian.TrillianMap.GetSignedMapRootByRevision:output_type -> trillian.GetSignedMapRootResponse
+	24, // 49: trillian.TrillianMap.InitMap:output_type -> trillian.InitMapResponse
+	27, // 50: trillian.TrillianMap.AddMapRootSignature:output_type -> trillian.AddMapRootSignatureResponse
+	29, // 51: trillian.TrillianMap.GetMapRootSignatures:output_type -> trillian.GetMapRootSignaturesResponse
+	31, // 52: trillian.TrillianMap.ListLeavesByKeyPrefix:output_type -> trillian.ListMapLeavesByKeyPrefixResponse
+	1,  // 53: trillian.TrillianMapWrite.GetLeavesByRevision:output_type -> trillian.MapLeaves
+	17, // 54: trillian.TrillianMapWrite.WriteLeaves:output_type -> trillian.WriteMapLeavesResponse
+	19, // 55: trillian.TrillianMapWrite.QueueMapMutations:output_type -> trillian.QueueMapMutationsResponse
+	38, // [38:56] is the sub-list for method output_type
+	20, // [20:38] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_trillian_map_api_proto_init() }
@@ -1471,8 +2563,80 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MapLeaves); i {
+		file_trillian_map_api_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MapLeaves); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MapLeafInclusion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapLeavesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapLeafRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapLeafByRevisionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapLeavesByRevisionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapLeavesByRevisionsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1483,8 +2647,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MapLeafInclusion); i {
+		file_trillian_map_api_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapLeafResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1495,8 +2659,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetMapLeavesRequest); i {
+		file_trillian_map_api_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapLeavesResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1507,8 +2671,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetMapLeafRequest); i {
+		file_trillian_map_api_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapLeavesByRevisionsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1519,8 +2683,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetMapLeafByRevisionRequest); i {
+		file_trillian_map_api_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapLeavesCompactRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1531,8 +2695,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetMapLeavesByRevisionRequest); i {
+		file_trillian_map_api_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapLeavesCompactResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1543,8 +2707,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetMapLeafResponse); i {
+		file_trillian_map_api_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLastInRangeByRevisionRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1555,8 +2719,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetMapLeavesResponse); i {
+		file_trillian_map_api_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetMapLeavesRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1567,8 +2731,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetLastInRangeByRevisionRequest); i {
+		file_trillian_map_api_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetMapLeavesResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1579,8 +2743,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetMapLeavesRequest); i {
+		file_trillian_map_api_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WriteMapLeavesRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1591,8 +2755,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetMapLeavesResponse); i {
+		file_trillian_map_api_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WriteMapLeavesResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1603,8 +2767,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WriteMapLeavesRequest); i {
+		file_trillian_map_api_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueMapMutationsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1615,8 +2779,8 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WriteMapLeavesResponse); i {
+		file_trillian_map_api_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueMapMutationsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1627,7 +2791,7 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_trillian_map_api_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetSignedMapRootRequest); i {
 			case 0:
 				return &v.state
@@ -1639,7 +2803,7 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+		file_trillian_map_api_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetSignedMapRootByRevisionRequest); i {
 			case 0:
 				return &v.state
@@ -1651,7 +2815,7 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+		file_trillian_map_api_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetSignedMapRootResponse); i {
 			case 0:
 				return &v.state
@@ -1663,7 +2827,7 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+		file_trillian_map_api_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*InitMapRequest); i {
 			case 0:
 				return &v.state
@@ -1675,7 +2839,7 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
-		file_trillian_map_api_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+		file_trillian_map_api_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*InitMapResponse); i {
 			case 0:
 				return &v.state
@@ -1687,6 +2851,90 @@ func file_trillian_map_api_proto_init() {
 				return nil
 			}
 		}
+		file_trillian_map_api_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MapRootSignature); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddMapRootSignatureRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddMapRootSignatureResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapRootSignaturesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMapRootSignaturesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListMapLeavesByKeyPrefixRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_map_api_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListMapLeavesByKeyPrefixResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1694,7 +2942,7 @@ func file_trillian_map_api_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_trillian_map_api_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   19,
+			NumMessages:   32,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
@@ -1727,6 +2975,17 @@ type TrillianMapClient interface {
 	GetLeafByRevision(ctx context.Context, in *GetMapLeafByRevisionRequest, opts ...grpc.CallOption) (*GetMapLeafResponse, error)
 	GetLeaves(ctx context.Context, in *GetMapLeavesRequest, opts ...grpc.CallOption) (*GetMapLeavesResponse, error)
 	GetLeavesByRevision(ctx context.Context, in *GetMapLeavesByRevisionRequest, opts ...grpc.CallOption) (*GetMapLeavesResponse, error)
+	// GetLeavesByRevisions returns an inclusion proof for each index requested,
+	// at each revision requested. All revisions are read from the same storage
+	// snapshot, and revisions with the same root hash share their reads, which
+	// suits clients which diff the states of the map across revisions.
+	GetLeavesByRevisions(ctx context.Context, in *GetMapLeavesByRevisionsRequest, opts ...grpc.CallOption) (*GetMapLeavesByRevisionsResponse, error)
+	// GetLeavesCompact returns the leaves at the indexes requested with a single
+	// inclusion proof for all of them, in which the nodes shared by the proofs
+	// of multiple leaves appear once, and those which can be computed from the
+	// leaves are left out. This is much smaller than the proofs of each leaf for
+	// large batches of indexes, and each node is read from storage once.
+	GetLeavesCompact(ctx context.Context, in *GetMapLeavesCompactRequest, opts ...grpc.CallOption) (*GetMapLeavesCompactResponse, error)
 	// Deprecated: Do not use.
 	// Deprecated: this should only be used by writers, which should migrate
 	// to TrillianMapWrite#GetLeavesByRevision
@@ -1740,6 +2999,19 @@ type TrillianMapClient interface {
 	GetSignedMapRoot(ctx context.Context, in *GetSignedMapRootRequest, opts ...grpc.CallOption) (*GetSignedMapRootResponse, error)
 	GetSignedMapRootByRevision(ctx context.Context, in *GetSignedMapRootByRevisionRequest, opts ...grpc.CallOption) (*GetSignedMapRootResponse, error)
 	InitMap(ctx context.Context, in *InitMapRequest, opts ...grpc.CallOption) (*InitMapResponse, error)
+	// AddMapRootSignature stores a co-signature of the map root at the given
+	// revision by a witness, replacing any earlier one by the same key. The
+	// signature must verify against the public key it comes with.
+	AddMapRootSignature(ctx context.Context, in *AddMapRootSignatureRequest, opts ...grpc.CallOption) (*AddMapRootSignatureResponse, error)
+	// GetMapRootSignatures returns the map root at the given revision, along
+	// with its co-signatures by witnesses, so that distributors can gossip
+	// multi-signed roots.
+	GetMapRootSignatures(ctx context.Context, in *GetMapRootSignaturesRequest, opts ...grpc.CallOption) (*GetMapRootSignaturesResponse, error)
+	// ListLeavesByKeyPrefix lists the leaves of a map with map_key_index set at
+	// the given revision whose keys start with a prefix, so that personalities
+	// can enumerate a namespace of keys without an index of their own. Leaves
+	// come without inclusion proofs, which GetLeavesByRevision returns.
+	ListLeavesByKeyPrefix(ctx context.Context, in *ListMapLeavesByKeyPrefixRequest, opts ...grpc.CallOption) (*ListMapLeavesByKeyPrefixResponse, error)
 }
 
 type trillianMapClient struct {
@@ -1786,6 +3058,24 @@ func (c *trillianMapClient) GetLeavesByRevision(ctx context.Context, in *GetMapL
 	return out, nil
 }
 
+func (c *trillianMapClient) GetLeavesByRevisions(ctx context.Context, in *GetMapLeavesByRevisionsRequest, opts ...grpc.CallOption) (*GetMapLeavesByRevisionsResponse, error) {
+	out := new(GetMapLeavesByRevisionsResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianMap/GetLeavesByRevisions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianMapClient) GetLeavesCompact(ctx context.Context, in *GetMapLeavesCompactRequest, opts ...grpc.CallOption) (*GetMapLeavesCompactResponse, error) {
+	out := new(GetMapLeavesCompactResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianMap/GetLeavesCompact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Deprecated: Do not use.
 func (c *trillianMapClient) GetLeavesByRevisionNoProof(ctx context.Context, in *GetMapLeavesByRevisionRequest, opts ...grpc.CallOption) (*MapLeaves, error) {
 	out := new(MapLeaves)
@@ -1842,6 +3132,33 @@ func (c *trillianMapClient) InitMap(ctx context.Context, in *InitMapRequest, opt
 	return out, nil
 }
 
+func (c *trillianMapClient) AddMapRootSignature(ctx context.Context, in *AddMapRootSignatureRequest, opts ...grpc.CallOption) (*AddMapRootSignatureResponse, error) {
+	out := new(AddMapRootSignatureResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianMap/AddMapRootSignature", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianMapClient) GetMapRootSignatures(ctx context.Context, in *GetMapRootSignaturesRequest, opts ...grpc.CallOption) (*GetMapRootSignaturesResponse, error) {
+	out := new(GetMapRootSignaturesResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianMap/GetMapRootSignatures", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trillianMapClient) ListLeavesByKeyPrefix(ctx context.Context, in *ListMapLeavesByKeyPrefixRequest, opts ...grpc.CallOption) (*ListMapLeavesByKeyPrefixResponse, error) {
+	out := new(ListMapLeavesByKeyPrefixResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianMap/ListLeavesByKeyPrefix", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TrillianMapServer is the server API for TrillianMap service.
 type TrillianMapServer interface {
 	// GetLeaves returns an inclusion proof for each index requested.
@@ -1851,6 +3168,17 @@ type TrillianMapServer interface {
 	GetLeafByRevision(context.Context, *GetMapLeafByRevisionRequest) (*GetMapLeafResponse, error)
 	GetLeaves(context.Context, *GetMapLeavesRequest) (*GetMapLeavesResponse, error)
 	GetLeavesByRevision(context.Context, *GetMapLeavesByRevisionRequest) (*GetMapLeavesResponse, error)
+	// GetLeavesByRevisions returns an inclusion proof for each index requested,
+	// at each revision requested. All revisions are read from the same storage
+	// snapshot, and revisions with the same root hash share their reads, which
+	// suits clients which diff the states of the map across revisions.
+	GetLeavesByRevisions(context.Context, *GetMapLeavesByRevisionsRequest) (*GetMapLeavesByRevisionsResponse, error)
+	// GetLeavesCompact returns the leaves at the indexes requested with a single
+	// inclusion proof for all of them, in which the nodes shared by the proofs
+	// of multiple leaves appear once, and those which can be computed from the
+	// leaves are left out. This is much smaller than the proofs of each leaf for
+	// large batches of indexes, and each node is read from storage once.
+	GetLeavesCompact(context.Context, *GetMapLeavesCompactRequest) (*GetMapLeavesCompactResponse, error)
 	// Deprecated: Do not use.
 	// Deprecated: this should only be used by writers, which should migrate
 	// to TrillianMapWrite#GetLeavesByRevision
@@ -1864,6 +3192,19 @@ type TrillianMapServer interface {
 	GetSignedMapRoot(context.Context, *GetSignedMapRootRequest) (*GetSignedMapRootResponse, error)
 	GetSignedMapRootByRevision(context.Context, *GetSignedMapRootByRevisionRequest) (*GetSignedMapRootResponse, error)
 	InitMap(context.Context, *InitMapRequest) (*InitMapResponse, error)
+	// AddMapRootSignature stores a co-signature of the map root at the given
+	// revision by a witness, replacing any earlier one by the same key. The
+	// signature must verify against the public key it comes with.
+	AddMapRootSignature(context.Context, *AddMapRootSignatureRequest) (*AddMapRootSignatureResponse, error)
+	// GetMapRootSignatures returns the map root at the given revision, along
+	// with its co-signatures by witnesses, so that distributors can gossip
+	// multi-signed roots.
+	GetMapRootSignatures(context.Context, *GetMapRootSignaturesRequest) (*GetMapRootSignaturesResponse, error)
+	// ListLeavesByKeyPrefix lists the leaves of a map with map_key_index set at
+	// the given revision whose keys start with a prefix, so that personalities
+	// can enumerate a namespace of keys without an index of their own. Leaves
+	// come without inclusion proofs, which GetLeavesByRevision returns.
+	ListLeavesByKeyPrefix(context.Context, *ListMapLeavesByKeyPrefixRequest) (*ListMapLeavesByKeyPrefixResponse, error)
 }
 
 // UnimplementedTrillianMapServer can be embedded to have forward compatible implementations.
@@ -1882,6 +3223,12 @@ func (*UnimplementedTrillianMapServer) GetLeaves(context.Context, *GetMapLeavesR
 func (*UnimplementedTrillianMapServer) GetLeavesByRevision(context.Context, *GetMapLeavesByRevisionRequest) (*GetMapLeavesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLeavesByRevision not implemented")
 }
+func (*UnimplementedTrillianMapServer) GetLeavesByRevisions(context.Context, *GetMapLeavesByRevisionsRequest) (*GetMapLeavesByRevisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLeavesByRevisions not implemented")
+}
+func (*UnimplementedTrillianMapServer) GetLeavesCompact(context.Context, *GetMapLeavesCompactRequest) (*GetMapLeavesCompactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLeavesCompact not implemented")
+}
 func (*UnimplementedTrillianMapServer) GetLeavesByRevisionNoProof(context.Context, *GetMapLeavesByRevisionRequest) (*MapLeaves, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLeavesByRevisionNoProof not implemented")
 }
@@ -1900,6 +3247,15 @@ func (*UnimplementedTrillianMapServer) GetSignedMapRootByRevision(context.Contex
 func (*UnimplementedTrillianMapServer) InitMap(context.Context, *InitMapRequest) (*InitMapResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InitMap not implemented")
 }
+func (*UnimplementedTrillianMapServer) AddMapRootSignature(context.Context, *AddMapRootSignatureRequest) (*AddMapRootSignatureResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMapRootSignature not implemented")
+}
+func (*UnimplementedTrillianMapServer) GetMapRootSignatures(context.Context, *GetMapRootSignaturesRequest) (*GetMapRootSignaturesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMapRootSignatures not implemented")
+}
+func (*UnimplementedTrillianMapServer) ListLeavesByKeyPrefix(context.Context, *ListMapLeavesByKeyPrefixRequest) (*ListMapLeavesByKeyPrefixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLeavesByKeyPrefix not implemented")
+}
 
 func RegisterTrillianMapServer(s *grpc.Server, srv TrillianMapServer) {
 	s.RegisterService(&_TrillianMap_serviceDesc, srv)
@@ -1977,6 +3333,42 @@ func _TrillianMap_GetLeavesByRevision_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrillianMap_GetLeavesByRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMapLeavesByRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianMapServer).GetLeavesByRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianMap/GetLeavesByRevisions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianMapServer).GetLeavesByRevisions(ctx, req.(*GetMapLeavesByRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianMap_GetLeavesCompact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMapLeavesCompactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianMapServer).GetLeavesCompact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianMap/GetLeavesCompact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianMapServer).GetLeavesCompact(ctx, req.(*GetMapLeavesCompactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TrillianMap_GetLeavesByRevisionNoProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetMapLeavesByRevisionRequest)
 	if err := dec(in); err != nil {
@@ -2085,6 +3477,60 @@ func _TrillianMap_InitMap_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrillianMap_AddMapRootSignature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMapRootSignatureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianMapServer).AddMapRootSignature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianMap/AddMapRootSignature",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianMapServer).AddMapRootSignature(ctx, req.(*AddMapRootSignatureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianMap_GetMapRootSignatures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMapRootSignaturesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianMapServer).GetMapRootSignatures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianMap/GetMapRootSignatures",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianMapServer).GetMapRootSignatures(ctx, req.(*GetMapRootSignaturesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrillianMap_ListLeavesByKeyPrefix_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMapLeavesByKeyPrefixRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianMapServer).ListLeavesByKeyPrefix(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianMap/ListLeavesByKeyPrefix",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianMapServer).ListLeavesByKeyPrefix(ctx, req.(*ListMapLeavesByKeyPrefixRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _TrillianMap_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "trillian.TrillianMap",
 	HandlerType: (*TrillianMapServer)(nil),
@@ -2105,6 +3551,14 @@ var _TrillianMap_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetLeavesByRevision",
 			Handler:    _TrillianMap_GetLeavesByRevision_Handler,
 		},
+		{
+			MethodName: "GetLeavesByRevisions",
+			Handler:    _TrillianMap_GetLeavesByRevisions_Handler,
+		},
+		{
+			MethodName: "GetLeavesCompact",
+			Handler:    _TrillianMap_GetLeavesCompact_Handler,
+		},
 		{
 			MethodName: "GetLeavesByRevisionNoProof",
 			Handler:    _TrillianMap_GetLeavesByRevisionNoProof_Handler,
@@ -2129,6 +3583,18 @@ var _TrillianMap_serviceDesc = grpc.ServiceDesc{
 			MethodName: "InitMap",
 			Handler:    _TrillianMap_InitMap_Handler,
 		},
+		{
+			MethodName: "AddMapRootSignature",
+			Handler:    _TrillianMap_AddMapRootSignature_Handler,
+		},
+		{
+			MethodName: "GetMapRootSignatures",
+			Handler:    _TrillianMap_GetMapRootSignatures_Handler,
+		},
+		{
+			MethodName: "ListLeavesByKeyPrefix",
+			Handler:    _TrillianMap_ListLeavesByKeyPrefix_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "trillian_map_api.proto",
@@ -2144,6 +3610,11 @@ type TrillianMapWriteClient interface {
 	// WriteLeaves sets the values for the provided leaves, and returns the new map
 	// revision if successful.
 	WriteLeaves(ctx context.Context, in *WriteMapLeavesRequest, opts ...grpc.CallOption) (*WriteMapLeavesResponse, error)
+	// QueueMapMutations queues the provided leaves to be set in a later revision
+	// of the map, and returns once they are queued. The map sequencer applies
+	// the queued leaves periodically, in batches, each in a new revision. Leaves
+	// queued later take precedence over earlier ones with the same index.
+	QueueMapMutations(ctx context.Context, in *QueueMapMutationsRequest, opts ...grpc.CallOption) (*QueueMapMutationsResponse, error)
 }
 
 type trillianMapWriteClient struct {
@@ -2172,6 +3643,15 @@ func (c *trillianMapWriteClient) WriteLeaves(ctx context.Context, in *WriteMapLe
 	return out, nil
 }
 
+func (c *trillianMapWriteClient) QueueMapMutations(ctx context.Context, in *QueueMapMutationsRequest, opts ...grpc.CallOption) (*QueueMapMutationsResponse, error) {
+	out := new(QueueMapMutationsResponse)
+	err := c.cc.Invoke(ctx, "/trillian.TrillianMapWrite/QueueMapMutations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TrillianMapWriteServer is the server API for TrillianMapWrite service.
 type TrillianMapWriteServer interface {
 	// GetLeavesByRevision returns the requested map leaves without inclusion proofs.
@@ -2180,6 +3660,11 @@ type TrillianMapWriteServer interface {
 	// WriteLeaves sets the values for the provided leaves, and returns the new map
 	// revision if successful.
 	WriteLeaves(context.Context, *WriteMapLeavesRequest) (*WriteMapLeavesResponse, error)
+	// QueueMapMutations queues the provided leaves to be set in a later revision
+	// of the map, and returns once they are queued. The map sequencer applies
+	// the queued leaves periodically, in batches, each in a new revision. Leaves
+	// queued later take precedence over earlier ones with the same index.
+	QueueMapMutations(context.Context, *QueueMapMutationsRequest) (*QueueMapMutationsResponse, error)
 }
 
 // UnimplementedTrillianMapWriteServer can be embedded to have forward compatible implementations.
@@ -2192,6 +3677,9 @@ func (*UnimplementedTrillianMapWriteServer) GetLeavesByRevision(context.Context,
 func (*UnimplementedTrillianMapWriteServer) WriteLeaves(context.Context, *WriteMapLeavesRequest) (*WriteMapLeavesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method WriteLeaves not implemented")
 }
+func (*UnimplementedTrillianMapWriteServer) QueueMapMutations(context.Context, *QueueMapMutationsRequest) (*QueueMapMutationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueueMapMutations not implemented")
+}
 
 func RegisterTrillianMapWriteServer(s *grpc.Server, srv TrillianMapWriteServer) {
 	s.RegisterService(&_TrillianMapWrite_serviceDesc, srv)
@@ -2233,6 +3721,24 @@ func _TrillianMapWrite_WriteLeaves_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TrillianMapWrite_QueueMapMutations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueueMapMutationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrillianMapWriteServer).QueueMapMutations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/trillian.TrillianMapWrite/QueueMapMutations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrillianMapWriteServer).QueueMapMutations(ctx, req.(*QueueMapMutationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _TrillianMapWrite_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "trillian.TrillianMapWrite",
 	HandlerType: (*TrillianMapWriteServer)(nil),
@@ -2245,6 +3751,10 @@ var _TrillianMapWrite_serviceDesc = grpc.ServiceDesc{
 			MethodName: "WriteLeaves",
 			Handler:    _TrillianMapWrite_WriteLeaves_Handler,
 		},
+		{
+			MethodName: "QueueMapMutations",
+			Handler:    _TrillianMapWrite_QueueMapMutations_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "trillian_map_api.proto",
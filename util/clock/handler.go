@@ -0,0 +1,55 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DebugPath is the HTTP path on which binaries run on a fake clock serve it.
+const DebugPath = "/debug/clock"
+
+// ServeHTTP lets tests control a FakeTimeSource running in another process.
+// A POST with an "advance" duration, e.g. "advance=1m", moves time forward,
+// and one with a "set" RFC 3339 time sets it. Every request is answered with
+// the resulting time, in RFC 3339 format.
+func (f *FakeTimeSource) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if v := r.FormValue("advance"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				http.Error(w, fmt.Sprintf("invalid advance %q", v), http.StatusBadRequest)
+				return
+			}
+			f.Advance(d)
+		}
+		if v := r.FormValue("set"); v != "" {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid set %q: %v", v, err), http.StatusBadRequest)
+				return
+			}
+			f.Set(t)
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	fmt.Fprintln(w, f.Now().Format(time.RFC3339Nano))
+}
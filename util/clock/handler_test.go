@@ -0,0 +1,65 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFakeTimeSourceServeHTTP(t *testing.T) {
+	fake := NewFake(date1)
+	timer := fake.NewTimer(time.Hour)
+
+	for _, tc := range []struct {
+		desc, method, target string
+		wantCode             int
+		wantNow              time.Time
+	}{
+		{desc: "get", method: "GET", target: "/debug/clock", wantCode: http.StatusOK, wantNow: date1},
+		{desc: "advance", method: "POST", target: "/debug/clock?advance=1h", wantCode: http.StatusOK, wantNow: date1.Add(time.Hour)},
+		{desc: "advance-negative", method: "POST", target: "/debug/clock?advance=-1h", wantCode: http.StatusBadRequest, wantNow: date1.Add(time.Hour)},
+		{desc: "advance-invalid", method: "POST", target: "/debug/clock?advance=soon", wantCode: http.StatusBadRequest, wantNow: date1.Add(time.Hour)},
+		{desc: "set", method: "POST", target: "/debug/clock?set=" + date2.Format(time.RFC3339Nano), wantCode: http.StatusOK, wantNow: date2},
+		{desc: "set-invalid", method: "POST", target: "/debug/clock?set=tomorrow", wantCode: http.StatusBadRequest, wantNow: date2},
+		{desc: "get-ignores-advance", method: "GET", target: "/debug/clock?advance=1h", wantCode: http.StatusOK, wantNow: date2},
+		{desc: "put", method: "PUT", target: "/debug/clock", wantCode: http.StatusMethodNotAllowed, wantNow: date2},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			fake.ServeHTTP(w, httptest.NewRequest(tc.method, tc.target, nil))
+			if got, want := w.Code, tc.wantCode; got != want {
+				t.Errorf("status code = %d, want %d", got, want)
+			}
+			if got, want := fake.Now(), tc.wantNow; !got.Equal(want) {
+				t.Errorf("Now() = %v, want %v", got, want)
+			}
+			if tc.wantCode == http.StatusOK {
+				if got, want := strings.TrimSpace(w.Body.String()), tc.wantNow.Format(time.RFC3339Nano); got != want {
+					t.Errorf("body = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+
+	select {
+	case <-timer.Chan():
+	default:
+		t.Error("timer didn't fire when time was advanced over HTTP")
+	}
+}
@@ -102,6 +102,19 @@ func (f *FakeTimeSource) unsubscribe(id int) bool {
 func (f *FakeTimeSource) Set(t time.Time) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	f.setLocked(t)
+}
+
+// Advance moves the time that this instance will report forward by d.
+func (f *FakeTimeSource) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setLocked(f.now.Add(d))
+}
+
+// setLocked updates the current time, and fires the timers which are due. It
+// must be called with mu held.
+func (f *FakeTimeSource) setLocked(t time.Time) {
 	f.now = t
 	for id, timer := range f.timers {
 		if timer.tryFire(t) {
@@ -37,6 +37,17 @@ func TestFakeTimeSource(t *testing.T) {
 	if got, want := ts.Now(), date2; got != want {
 		t.Errorf("ts.Now=%v; want %v", got, want)
 	}
+
+	timer := fake.NewTimer(time.Minute)
+	fake.Advance(time.Minute)
+	if got, want := ts.Now(), date2.Add(time.Minute); got != want {
+		t.Errorf("ts.Now=%v; want %v", got, want)
+	}
+	select {
+	case <-timer.Chan():
+	default:
+		t.Error("timer didn't fire when time was advanced")
+	}
 }
 
 func TestSecondsSince(t *testing.T) {
@@ -0,0 +1,106 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compression configures the compression of the responses of
+// Trillian RPCs which return many leaves, and measures how well they compress.
+//
+// gRPC servers compress the responses of RPCs with the compressor the client
+// compressed the request with, provided that it's registered with them, so
+// compression is chosen by clients. Clients can enable it for the methods
+// whose responses are worth it with DialOptions. This package registers gzip;
+// other compressors, such as zstd, can be used by linking in a package which
+// registers them with google.golang.org/grpc/encoding, in both the clients
+// and the servers.
+package compression
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// Gzip is the name of the gzip compressor.
+const Gzip = gzip.Name
+
+// DefaultMethods are the methods whose responses are compressed by default:
+// those which return many leaves.
+var DefaultMethods = []string{
+	"/trillian.TrillianLog/GetLeavesByRange",
+	"/trillian.TrillianLog/StreamLeaves",
+	"/trillian.TrillianMap/GetLeaves",
+	"/trillian.TrillianMap/GetLeavesByRevision",
+	"/trillian.TrillianMap/GetLeavesByRevisionNoProof",
+	"/trillian.TrillianMap/GetLeavesCompact",
+}
+
+// SetGzipLevel sets the level of gzip compression, from gzip.BestSpeed (1) to
+// gzip.BestCompression (9), or -1 for the default level. It must only be
+// called at initialization time.
+func SetGzipLevel(level int) error {
+	return gzip.SetLevel(level)
+}
+
+// ParseMethods parses a comma-separated list of methods, named by their full
+// name, such as "/trillian.TrillianLog/GetLeavesByRange", or by name alone,
+// such as "GetLeavesByRange", which matches the method of any service.
+func ParseMethods(s string) []string {
+	var methods []string
+	for _, m := range strings.Split(s, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+// DialOptions returns options making a client compress the requests of the
+// given methods with the named compressor, which makes servers compress the
+// responses. Methods are named as for ParseMethods; nil means DefaultMethods.
+// An empty compressor name disables compression.
+func DialOptions(compressor string, methods []string) ([]grpc.DialOption, error) {
+	if compressor == "" {
+		return nil, nil
+	}
+	if encoding.GetCompressor(compressor) == nil {
+		return nil, fmt.Errorf("compression: compressor %q is not registered", compressor)
+	}
+	if methods == nil {
+		methods = DefaultMethods
+	}
+	set := make(map[string]bool)
+	for _, m := range methods {
+		set[m] = true
+	}
+	compressed := func(fullMethod string) bool {
+		return set[fullMethod] || set[fullMethod[strings.LastIndex(fullMethod, "/")+1:]]
+	}
+
+	unary := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if compressed(method) {
+			opts = append(opts, grpc.UseCompressor(compressor))
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	stream := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if compressed(method) {
+			opts = append(opts, grpc.UseCompressor(compressor))
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+	return []grpc.DialOption{grpc.WithChainUnaryInterceptor(unary), grpc.WithChainStreamInterceptor(stream)}, nil
+}
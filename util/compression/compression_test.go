@@ -0,0 +1,109 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const checkMethod = "/grpc.health.v1.Health/Check"
+
+func TestParseMethods(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		want []string
+	}{
+		{s: "", want: nil},
+		{s: "GetLeaves", want: []string{"GetLeaves"}},
+		{s: " GetLeaves, ,/trillian.TrillianLog/GetLeavesByRange ", want: []string{"GetLeaves", "/trillian.TrillianLog/GetLeavesByRange"}},
+	} {
+		got := ParseMethods(test.s)
+		if len(got) != len(test.want) {
+			t.Errorf("ParseMethods(%q)=%q, want %q", test.s, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("ParseMethods(%q)=%q, want %q", test.s, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestDialOptionsUnknownCompressor(t *testing.T) {
+	if _, err := DialOptions("unknown", nil); err == nil {
+		t.Error("DialOptions(unknown): got nil error, want error")
+	}
+}
+
+func TestCompression(t *testing.T) {
+	for _, test := range []struct {
+		desc           string
+		compressor     string
+		methods        []string
+		wantCompressor string
+	}{
+		{desc: "none"},
+		{desc: "other-method", compressor: Gzip, methods: []string{"GetLeaves"}},
+		{desc: "short-name", compressor: Gzip, methods: []string{"Check"}, wantCompressor: Gzip},
+		{desc: "full-name", compressor: Gzip, methods: []string{checkMethod}, wantCompressor: Gzip},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			mf := monitoring.InertMetricFactory{}
+			serverStats := NewStatsHandler("server", mf, nil)
+			srv := grpc.NewServer(grpc.StatsHandler(serverStats))
+			grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+			lis, err := net.Listen("tcp", "localhost:0")
+			if err != nil {
+				t.Fatalf("Listen(): %v", err)
+			}
+			go srv.Serve(lis)
+			defer srv.Stop()
+
+			opts, err := DialOptions(test.compressor, test.methods)
+			if err != nil {
+				t.Fatalf("DialOptions(): %v", err)
+			}
+			clientStats := NewStatsHandler("client", mf, nil)
+			opts = append(opts, grpc.WithInsecure(), grpc.WithStatsHandler(clientStats))
+			conn, err := grpc.Dial(lis.Addr().String(), opts...)
+			if err != nil {
+				t.Fatalf("Dial(): %v", err)
+			}
+			defer conn.Close()
+			if _, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+				t.Fatalf("Check(): %v", err)
+			}
+
+			for _, h := range []*StatsHandler{serverStats, clientStats} {
+				if got := h.wireBytes.(*monitoring.InertFloat).Value(checkMethod, test.wantCompressor); got <= 0 {
+					t.Errorf("wire bytes for compressor %q = %v, want > 0", test.wantCompressor, got)
+				}
+				count, _ := h.ratio.(*monitoring.InertDistribution).Info(checkMethod, test.wantCompressor)
+				if want := test.wantCompressor != ""; (count > 0) != want {
+					t.Errorf("compression ratio observations = %d, want observations: %v", count, want)
+				}
+			}
+		})
+	}
+}
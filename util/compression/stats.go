@@ -0,0 +1,135 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/stats"
+)
+
+// StatsHandler is a gRPC stats.Handler which measures the compression of
+// responses: those sent by servers, or received by clients. It passes all
+// stats on to another handler, if any, as gRPC only takes one.
+type StatsHandler struct {
+	next      stats.Handler
+	bytes     monitoring.Counter
+	wireBytes monitoring.Counter
+	ratio     monitoring.Histogram
+}
+
+// NewStatsHandler returns a StatsHandler exporting metrics with the given
+// name prefix, which passes stats on to next, unless it's nil.
+func NewStatsHandler(prefix string, mf monitoring.MetricFactory, next stats.Handler) *StatsHandler {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &StatsHandler{
+		next:      next,
+		bytes:     mf.NewCounter(prefix+"_rpc_response_bytes", "Size of RPC responses before compression, in bytes", "method", "compressor"),
+		wireBytes: mf.NewCounter(prefix+"_rpc_response_wire_bytes", "Size of RPC responses on the wire, after compression, in bytes", "method", "compressor"),
+		ratio:     mf.NewHistogramWithBuckets(prefix+"_rpc_response_compression_ratio", "Ratio of the size of compressed RPC responses before compression to their size on the wire", monitoring.ExpBuckets(0.5, 1.25, 24), "method", "compressor"),
+	}
+}
+
+type rpcKey struct{}
+
+// rpcStats is the state of an RPC. Messages of streaming RPCs may be sent
+// and received from different goroutines, so it's guarded by mu.
+type rpcStats struct {
+	method     string
+	mu         sync.Mutex
+	compressor string
+}
+
+func (r *rpcStats) setCompressor(c string) {
+	if c == encoding.Identity {
+		c = ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.compressor = c
+}
+
+func (r *rpcStats) getCompressor() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.compressor
+}
+
+// TagRPC implements stats.Handler.
+func (h *StatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	if h.next != nil {
+		ctx = h.next.TagRPC(ctx, info)
+	}
+	return context.WithValue(ctx, rpcKey{}, &rpcStats{method: info.FullMethodName})
+}
+
+// HandleRPC implements stats.Handler.
+func (h *StatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if h.next != nil {
+		h.next.HandleRPC(ctx, s)
+	}
+	r, ok := ctx.Value(rpcKey{}).(*rpcStats)
+	if !ok {
+		return
+	}
+	switch s := s.(type) {
+	case *stats.OutHeader:
+		if !s.Client {
+			r.setCompressor(s.Compression)
+		}
+	case *stats.InHeader:
+		if s.Client {
+			r.setCompressor(s.Compression)
+		}
+	case *stats.OutPayload:
+		if !s.Client {
+			h.observe(r, s.Length, s.WireLength)
+		}
+	case *stats.InPayload:
+		if s.Client {
+			h.observe(r, s.Length, s.WireLength)
+		}
+	}
+}
+
+// observe records the size of a response before compression, and on the wire.
+func (h *StatsHandler) observe(r *rpcStats, length, wireLength int) {
+	compressor := r.getCompressor()
+	h.bytes.Add(float64(length), r.method, compressor)
+	h.wireBytes.Add(float64(wireLength), r.method, compressor)
+	if compressor != "" && wireLength > 0 {
+		h.ratio.Observe(float64(length)/float64(wireLength), r.method, compressor)
+	}
+}
+
+// TagConn implements stats.Handler.
+func (h *StatsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	if h.next != nil {
+		return h.next.TagConn(ctx, info)
+	}
+	return ctx
+}
+
+// HandleConn implements stats.Handler.
+func (h *StatsHandler) HandleConn(ctx context.Context, s stats.ConnStats) {
+	if h.next != nil {
+		h.next.HandleConn(ctx, s)
+	}
+}
@@ -42,6 +42,10 @@ type RunnerConfig struct {
 	MasterHoldInterval time.Duration
 	// MasterHoldJitter is the maximum addition to MasterHoldInterval.
 	MasterHoldJitter time.Duration
+	// Sticky says that mastership is held until it's lost, or moved to another
+	// instance by a placement hint, rather than resigned after the hold
+	// interval. This avoids churn when there are many resources.
+	Sticky bool
 
 	TimeSource clock.TimeSource
 }
@@ -128,6 +132,9 @@ func (er *Runner) beMaster(ctx context.Context, pending chan<- Resignation) erro
 		return fmt.Errorf("election.Await() failed: %v", err)
 	}
 	glog.Infof("%s: Now, I am the master", er.id)
+	if f, ok := er.election.(election2.Fencer); ok {
+		er.tracker.SetEpoch(er.id, f.FencingToken())
+	}
 	er.tracker.Set(er.id, true)
 	defer er.tracker.Set(er.id, false)
 
@@ -136,28 +143,46 @@ func (er *Runner) beMaster(ctx context.Context, pending chan<- Resignation) erro
 		return fmt.Errorf("election.WithMastership() failed: %v", err)
 	}
 
-	timer := er.cfg.TimeSource.NewTimer(er.cfg.ResignDelay())
-	defer timer.Stop()
+	var expired <-chan time.Time
+	if !er.cfg.Sticky {
+		timer := er.cfg.TimeSource.NewTimer(er.cfg.ResignDelay())
+		defer timer.Stop()
+		expired = timer.Chan()
+	}
+	var moved <-chan struct{}
+	if m, ok := er.election.(election2.Mover); ok {
+		mvctx, cancel := context.WithCancel(mctx)
+		defer cancel()
+		moved = m.MoveRequested(mvctx)
+	}
 
 	select {
 	case <-mctx.Done(): // Mastership context is canceled.
 		glog.Errorf("%s: no longer the master!", er.id)
 		return mctx.Err()
 
-	case <-timer.Chan():
-		glog.Infof("%s: queue up resignation of mastership", er.id)
-		done := make(chan struct{})
-		r := Resignation{ID: er.id, er: er, done: done}
-		select {
-		case pending <- r:
-			<-done // Block until acted on.
-		default:
-			glog.Warning("Dropping resignation because operation manager seems to be exiting")
-		}
+	case <-moved:
+		glog.Infof("%s: mastership is moving to another instance", er.id)
+		er.resign(pending)
+	case <-expired:
+		er.resign(pending)
 	}
 	return nil
 }
 
+// resign queues up resignation of mastership, and blocks until it's done.
+func (er *Runner) resign(pending chan<- Resignation) {
+	glog.Infof("%s: queue up resignation of mastership", er.id)
+	done := make(chan struct{})
+	r := Resignation{ID: er.id, er: er, done: done}
+	select {
+	case pending <- r:
+		<-done // Block until acted on.
+	default:
+		glog.Warning("Dropping resignation because operation manager seems to be exiting")
+	}
+}
+
 // Resignation indicates that a master should explicitly resign mastership, and
 // call the Execute() method as soon as no master-related activity is ongoing.
 type Resignation struct {
@@ -74,12 +74,16 @@ func TestElectionRunnerRun(t *testing.T) {
 		wantMaster bool
 		loseMaster bool
 		resign     bool
+		sticky     bool
+		move       bool
 	}{
 		// Basic cases.
 		{desc: "not-master"},
 		{desc: "is-master", isMaster: true, wantMaster: true},
 		{desc: "lose-master", isMaster: true, wantMaster: true, loseMaster: true},
 		{desc: "resign", isMaster: true, wantMaster: true, resign: true},
+		{desc: "sticky", isMaster: true, wantMaster: true, sticky: true},
+		{desc: "move", isMaster: true, wantMaster: true, sticky: true, move: true},
 		// Error cases.
 		{desc: "err-await", errs: to.Errs{Await: errors.New("ErrAwait")}},
 		{desc: "err-mctx", errs: to.Errs{WithMastership: errors.New("ErrMastership")}},
@@ -108,8 +112,9 @@ func TestElectionRunnerRun(t *testing.T) {
 			start := time.Now()
 			ts := clock.NewFake(start)
 			tracker := election.NewMasterTracker([]string{logID}, nil)
-			cfg := election.RunnerConfig{TimeSource: ts}
-			er := election.NewRunner(logID, &cfg, tracker, nil, d)
+			cfg := election.RunnerConfig{TimeSource: ts, Sticky: tc.sticky}
+			el := movableElection{Decorator: d, moved: make(chan struct{})}
+			er := election.NewRunner(logID, &cfg, tracker, nil, el)
 			resignations := make(chan election.Resignation, 100)
 
 			var wg sync.WaitGroup
@@ -138,6 +143,26 @@ func TestElectionRunnerRun(t *testing.T) {
 				checkMaster(t, tracker.Held(), false)
 			}
 
+			if tc.sticky {
+				// Sticky mastership isn't resigned, however much time passes.
+				ts.Set(start.Add(24 * 60 * time.Hour))
+				time.Sleep(100 * time.Millisecond)
+				if got := len(resignations); got != 0 {
+					t.Errorf("Sticky mastership: %d resignations, want none", got)
+				}
+				checkMaster(t, tracker.Held(), true)
+			}
+			if tc.move {
+				d.BlockAwait(true)
+				close(el.moved)
+				time.Sleep(100 * time.Millisecond)
+				for len(resignations) > 0 {
+					r := <-resignations
+					r.Execute(ctx)
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+
 			if tc.resign {
 				d.BlockAwait(true)
 				// Advance fake time so that resignation triggers too, if still master.
@@ -150,10 +175,21 @@ func TestElectionRunnerRun(t *testing.T) {
 				time.Sleep(100 * time.Millisecond)
 			}
 
-			checkMaster(t, tracker.Held(), tc.wantMaster && !tc.loseMaster && !tc.resign)
+			checkMaster(t, tracker.Held(), tc.wantMaster && !tc.loseMaster && !tc.resign && !tc.move)
 			cancel()  // If Runner is still running, it should stop now.
 			wg.Wait() // Wait until it stops.
 			checkMaster(t, tracker.Held(), false)
 		})
 	}
 }
+
+// movableElection is an Election whose mastership is moved away once its moved
+// channel is closed.
+type movableElection struct {
+	*to.Decorator
+	moved chan struct{}
+}
+
+func (e movableElection) MoveRequested(ctx context.Context) <-chan struct{} {
+	return e.moved
+}
@@ -28,7 +28,10 @@ type MasterTracker struct {
 	mu          sync.RWMutex
 	masterFor   map[string]bool
 	masterCount int
-	notify      func(id string, isMaster bool)
+	// epochs holds the fencing tokens of the IDs we are master for, if their
+	// elections provide them.
+	epochs map[string]int64
+	notify func(id string, isMaster bool)
 }
 
 // NewMasterTracker creates a new MasterTracker instance to track the
@@ -38,7 +41,7 @@ func NewMasterTracker(ids []string, notify func(id string, isMaster bool)) *Mast
 	for _, id := range ids {
 		mf[id] = false
 	}
-	return &MasterTracker{masterFor: mf, notify: notify}
+	return &MasterTracker{masterFor: mf, epochs: make(map[string]int64), notify: notify}
 }
 
 // Set changes the tracked mastership status for the given ID. This method
@@ -56,11 +59,30 @@ func (mt *MasterTracker) Set(id string, isMaster bool) {
 	} else if !isMaster && wasMaster {
 		mt.masterCount--
 	}
+	if !isMaster {
+		delete(mt.epochs, id)
+	}
 	if mt.notify != nil {
 		mt.notify(id, isMaster)
 	}
 }
 
+// SetEpoch records the fencing token of the current mastership tenure for the
+// given ID, see election2.Fencer. It is forgotten when mastership is lost.
+func (mt *MasterTracker) SetEpoch(id string, epoch int64) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	mt.epochs[id] = epoch
+}
+
+// Epoch returns the fencing token of the current mastership tenure for the
+// given ID, or 0 if there is none.
+func (mt *MasterTracker) Epoch(id string) int64 {
+	mt.mu.RLock()
+	defer mt.mu.RUnlock()
+	return mt.epochs[id]
+}
+
 // Count returns the number of IDs for which we are currently master.
 func (mt *MasterTracker) Count() int {
 	mt.mu.RLock()
@@ -107,3 +107,20 @@ func TestMasterTracker(t *testing.T) {
 		}
 	}
 }
+
+func TestMasterTrackerEpoch(t *testing.T) {
+	mt := NewMasterTracker([]string{"1", "2"}, nil)
+	mt.SetEpoch("1", 10)
+	mt.Set("1", true)
+	mt.Set("2", true)
+	if got, want := mt.Epoch("1"), int64(10); got != want {
+		t.Errorf("Epoch(1)=%d, want %d", got, want)
+	}
+	if got, want := mt.Epoch("2"), int64(0); got != want {
+		t.Errorf("Epoch(2)=%d, want %d", got, want)
+	}
+	mt.Set("1", false)
+	if got, want := mt.Epoch("1"), int64(0); got != want {
+		t.Errorf("Epoch(1)=%d after losing mastership, want %d", got, want)
+	}
+}
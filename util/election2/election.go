@@ -31,7 +31,14 @@
 // TODO(pavelkalinnikov): Merge this package with util/election.
 package election2
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrPlacementUnsupported is returned by Placer implementations which wrap a
+// Factory that doesn't take placement hints.
+var ErrPlacementUnsupported = errors.New("placement hints are not supported")
 
 // Election controls an instance's participation in master election process.
 // Note: Implementations are not intended to be thread-safe.
@@ -80,8 +87,61 @@ type Election interface {
 	Close(ctx context.Context) error
 }
 
+// MasterReporter is an optional interface which an Election can implement to
+// report which instance currently holds mastership of its resource.
+type MasterReporter interface {
+	// Master returns the ID of the instance which is currently the master, or
+	// an empty string if there is no master.
+	Master(ctx context.Context) (string, error)
+}
+
+// Fencer is an optional interface which an Election can implement to hand out
+// fencing tokens, which allow the resource to reject writes from an instance
+// which has lost mastership without noticing yet.
+type Fencer interface {
+	// FencingToken returns the token of the current or most recent mastership
+	// tenure of the instance, or 0 if it has never been the master. The token
+	// of a tenure is greater than those of all earlier tenures, whichever
+	// instances held them.
+	FencingToken() int64
+}
+
 // Factory encapsulates the creation of an Election instance for a resource
 // with the specified ID.
 type Factory interface {
 	NewElection(ctx context.Context, resourceID string) (Election, error)
 }
+
+// HealthChecker is an optional interface which a Factory can implement to
+// report whether the system that elections are run on is reachable.
+type HealthChecker interface {
+	// CheckHealth returns an error if Elections created by the Factory can't
+	// currently make progress.
+	CheckHealth(ctx context.Context) error
+}
+
+// Placer is an optional interface which a Factory can implement to take
+// placement hints, which name the instance that should be the master of a
+// resource, e.g. to drain an instance before maintenance. Elections created by
+// the Factory honor the hints: other instances hold back from capturing
+// mastership of the resource for a while, so that the named instance captures
+// it, and implement Mover to tell the master to resign once the named instance
+// is waiting to take over.
+type Placer interface {
+	// Place sets the hint of the instance which should be the master of the
+	// resource, or clears it if instanceID is empty.
+	Place(ctx context.Context, resourceID, instanceID string) error
+	// Placement returns the instance which should be the master of the
+	// resource, or an empty string if there's no hint.
+	Placement(ctx context.Context, resourceID string) (string, error)
+}
+
+// Mover is an optional interface which an Election can implement if it honors
+// placement hints, see Placer.
+type Mover interface {
+	// MoveRequested returns a channel which is closed once mastership of the
+	// resource should move to another instance, which is named by its
+	// placement hint and waiting to take over. It's watched until the passed
+	// in context is canceled.
+	MoveRequested(ctx context.Context) <-chan struct{}
+}
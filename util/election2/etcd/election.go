@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/google/trillian/util/election2"
@@ -33,14 +34,24 @@ type Election struct {
 	resourceID string
 	instanceID string
 	lockFile   string
+	// placementKey holds the placement hint of the resource.
+	placementKey string
+	// placementDelay is how long the instance holds back from capturing
+	// mastership if the placement hint names another instance.
+	placementDelay time.Duration
 
 	client   *clientv3.Client
 	session  *concurrency.Session
 	election *concurrency.Election
 }
 
-// Await blocks until the instance captures mastership.
+// Await blocks until the instance captures mastership. If the placement hint
+// of the resource names another instance, it holds back from campaigning for
+// a while first, see holdBack.
 func (e *Election) Await(ctx context.Context) error {
+	if err := e.holdBack(ctx); err != nil {
+		return err
+	}
 	return e.election.Campaign(ctx, e.instanceID)
 }
 
@@ -113,6 +124,28 @@ func (e *Election) Resign(ctx context.Context) error {
 	return e.election.Resign(ctx)
 }
 
+// FencingToken implements election2.Fencer. The token is the etcd revision at
+// which the instance became the master, which is greater than that of any
+// previous master.
+func (e *Election) FencingToken() int64 {
+	return e.election.Rev()
+}
+
+// Master returns the instance ID of the current master, or an empty string if
+// there is none.
+func (e *Election) Master(ctx context.Context) (string, error) {
+	rsp, err := e.election.Leader(ctx)
+	if err == concurrency.ErrElectionNoLeader {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	if id := string(rsp.Kvs[0].Value); id != resignID {
+		return id, nil
+	}
+	return "", nil
+}
+
 // Close resigns and permanently stops participating in election. No other
 // method should be called after Close.
 func (e *Election) Close(ctx context.Context) error {
@@ -130,16 +163,29 @@ type Factory struct {
 	client     *clientv3.Client
 	instanceID string
 	lockDir    string
+	// placementDelay is how long Elections hold back from campaigning if the
+	// placement hint of their resource names another instance.
+	placementDelay time.Duration
 }
 
 // NewFactory builds an election factory that uses the given parameters. The
 // passed in etcd client should remain valid for the lifetime of the object.
 func NewFactory(instanceID string, client *clientv3.Client, lockDir string) *Factory {
 	return &Factory{
-		client:     client,
-		instanceID: instanceID,
-		lockDir:    lockDir,
+		client:         client,
+		instanceID:     instanceID,
+		lockDir:        lockDir,
+		placementDelay: DefaultPlacementDelay,
+	}
+}
+
+// CheckHealth implements election2.HealthChecker by reading from the lock
+// directory, which fails if etcd doesn't have a reachable quorum.
+func (f *Factory) CheckHealth(ctx context.Context) error {
+	if _, err := f.client.Get(ctx, f.lockDir, clientv3.WithPrefix(), clientv3.WithKeysOnly(), clientv3.WithLimit(1)); err != nil {
+		return fmt.Errorf("failed to read from etcd: %v", err)
 	}
+	return nil
 }
 
 // NewElection creates a specific Election instance.
@@ -154,12 +200,14 @@ func (f *Factory) NewElection(ctx context.Context, resourceID string) (election2
 	election := concurrency.NewElection(session, lockFile)
 
 	el := Election{
-		resourceID: resourceID,
-		instanceID: f.instanceID,
-		lockFile:   lockFile,
-		client:     f.client,
-		session:    session,
-		election:   election,
+		resourceID:     resourceID,
+		instanceID:     f.instanceID,
+		lockFile:       lockFile,
+		placementKey:   f.placementKey(resourceID),
+		placementDelay: f.placementDelay,
+		client:         f.client,
+		session:        session,
+		election:       election,
 	}
 	glog.Infof("Election created: %+v", el)
 
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/trillian/testonly/integration/etcd"
 	"github.com/google/trillian/util/election2/testonly"
@@ -72,3 +73,74 @@ func TestElection(t *testing.T) {
 		})
 	}
 }
+
+func TestElectionMaster(t *testing.T) {
+	_, client, cleanup, err := etcd.StartEtcd()
+	if err != nil {
+		t.Fatalf("StartEtcd(): %v", err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	elections := make(map[string]*Election)
+	for _, id := range []string{"a", "b"} {
+		el, err := NewFactory(id, client, "res/").NewElection(ctx, "10")
+		if err != nil {
+			t.Fatalf("NewElection(%s): %v", id, err)
+		}
+		elections[id] = el.(*Election)
+	}
+	checkMaster := func(want string) {
+		t.Helper()
+		for id, el := range elections {
+			if got, err := el.Master(ctx); err != nil || got != want {
+				t.Errorf("%s: Master()=%q, %v, want %q, nil", id, got, err, want)
+			}
+		}
+	}
+
+	checkMaster("")
+	if err := elections["a"].Await(ctx); err != nil {
+		t.Fatalf("Await(a): %v", err)
+	}
+	checkMaster("a")
+	tokenA := elections["a"].FencingToken()
+	if tokenA <= 0 {
+		t.Errorf("FencingToken(a)=%d, want > 0", tokenA)
+	}
+	if err := elections["a"].Resign(ctx); err != nil {
+		t.Fatalf("Resign(a): %v", err)
+	}
+	checkMaster("")
+	if err := elections["b"].Await(ctx); err != nil {
+		t.Fatalf("Await(b): %v", err)
+	}
+	checkMaster("b")
+	if tokenB := elections["b"].FencingToken(); tokenB <= tokenA {
+		t.Errorf("FencingToken(b)=%d, want > %d", tokenB, tokenA)
+	}
+	for id, el := range elections {
+		if err := el.Close(ctx); err != nil {
+			t.Errorf("Close(%s): %v", id, err)
+		}
+	}
+}
+
+func TestFactoryCheckHealth(t *testing.T) {
+	_, client, cleanup, err := etcd.StartEtcd()
+	if err != nil {
+		t.Fatalf("StartEtcd(): %v", err)
+	}
+	fact := NewFactory("serv", client, "res/")
+	ctx := context.Background()
+	if err := fact.CheckHealth(ctx); err != nil {
+		t.Errorf("CheckHealth()=%v, want nil", err)
+	}
+
+	cleanup()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := fact.CheckHealth(ctx); err == nil {
+		t.Error("CheckHealth() succeeded after etcd stopped, want error")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/trillian/util/election2"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/clientv3/concurrency"
+)
+
+// Membership is an implementation of election2.Membership based on etcd. Each
+// instance registers itself under a directory with a key bound to the lease of
+// its session, so that it's removed once the instance stops, or can't reach
+// etcd for as long as the session's TTL.
+type Membership struct {
+	client  *clientv3.Client
+	dir     string
+	session *concurrency.Session
+}
+
+var _ election2.Membership = (*Membership)(nil)
+
+// NewMembership registers the instance with the given ID under the directory,
+// and returns a Membership which lists the instances registered there. The
+// passed in etcd client should remain valid for the lifetime of the object.
+func NewMembership(ctx context.Context, instanceID string, client *clientv3.Client, dir string) (*Membership, error) {
+	session, err := concurrency.NewSession(client, concurrency.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd session: %v", err)
+	}
+	dir = strings.TrimRight(dir, "/") + "/"
+	if _, err := client.Put(ctx, dir+instanceID, "", clientv3.WithLease(session.Lease())); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("failed to register instance %q: %v", instanceID, err)
+	}
+	return &Membership{client: client, dir: dir, session: session}, nil
+}
+
+// Members returns the IDs of the instances registered under the directory.
+func (m *Membership) Members(ctx context.Context) ([]string, error) {
+	resp, err := m.client.Get(ctx, m.dir, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %v", err)
+	}
+	members := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		members = append(members, strings.TrimPrefix(string(kv.Key), m.dir))
+	}
+	return members, nil
+}
+
+// Close unregisters the instance, by revoking the lease of its session.
+func (m *Membership) Close() error {
+	return m.session.Close()
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/google/trillian/testonly/integration/etcd"
+)
+
+func TestMembership(t *testing.T) {
+	_, client, cleanup, err := etcd.StartEtcd()
+	if err != nil {
+		t.Fatalf("StartEtcd(): %v", err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	var all []*Membership
+	for _, id := range []string{"serv1", "serv2", "serv3"} {
+		m, err := NewMembership(ctx, id, client, "members/")
+		if err != nil {
+			t.Fatalf("NewMembership(%s): %v", id, err)
+		}
+		all = append(all, m)
+	}
+	check := func(want ...string) {
+		t.Helper()
+		got, err := all[0].Members(ctx)
+		if err != nil {
+			t.Fatalf("Members(): %v", err)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Members(): %v, want %v", got, want)
+		}
+	}
+
+	check("serv1", "serv2", "serv3")
+	if err := all[1].Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	check("serv1", "serv3")
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/util/election2"
+	"go.etcd.io/etcd/clientv3"
+)
+
+// DefaultPlacementDelay is how long Elections hold back from campaigning if
+// the placement hint of their resource names another instance, which gives the
+// named instance time to capture mastership. Instances campaign after the
+// delay anyway, so that the resource gets a master if the named instance is
+// down.
+const DefaultPlacementDelay = 30 * time.Second
+
+var (
+	_ election2.Placer = (*Factory)(nil)
+	_ election2.Mover  = (*Election)(nil)
+)
+
+// placementKey returns the key holding the placement hint of a resource. Hints
+// are kept next to the lock directory rather than in it, so that they aren't
+// mistaken for election keys.
+func (f *Factory) placementKey(resourceID string) string {
+	return fmt.Sprintf("%s-placement/%s", strings.TrimRight(f.lockDir, "/"), resourceID)
+}
+
+// Place implements election2.Placer.
+func (f *Factory) Place(ctx context.Context, resourceID, instanceID string) error {
+	key := f.placementKey(resourceID)
+	var err error
+	if instanceID == "" {
+		_, err = f.client.Delete(ctx, key)
+	} else {
+		_, err = f.client.Put(ctx, key, instanceID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write placement of %s: %v", resourceID, err)
+	}
+	return nil
+}
+
+// Placement implements election2.Placer.
+func (f *Factory) Placement(ctx context.Context, resourceID string) (string, error) {
+	return placement(ctx, f.client, f.placementKey(resourceID))
+}
+
+func placement(ctx context.Context, client *clientv3.Client, key string) (string, error) {
+	rsp, err := client.Get(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read placement: %v", err)
+	}
+	if len(rsp.Kvs) == 0 {
+		return "", nil
+	}
+	return string(rsp.Kvs[0].Value), nil
+}
+
+// holdBack waits while the placement hint of the resource names another
+// instance, for up to the placement delay. Failing to read the hint doesn't
+// hold the instance back, as elections shouldn't depend on hints.
+func (e *Election) holdBack(ctx context.Context) error {
+	placed, err := placement(ctx, e.client, e.placementKey)
+	if err != nil {
+		glog.Warningf("%s: %v", e.resourceID, err)
+		return nil
+	}
+	if placed == "" || placed == e.instanceID {
+		return nil
+	}
+	glog.Infof("%s: placed on %s, holding back from election for %v", e.resourceID, placed, e.placementDelay)
+	wctx, cancel := context.WithTimeout(ctx, e.placementDelay)
+	defer cancel()
+	for rsp := range e.client.Watch(wctx, e.placementKey) {
+		for _, ev := range rsp.Events {
+			if ev.Type == clientv3.EventTypeDelete || string(ev.Kv.Value) == e.instanceID {
+				return nil
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// MoveRequested implements election2.Mover. The channel is closed once the
+// placement hint of the resource names another instance which is campaigning
+// for mastership of it, so that mastership is never moved to an instance which
+// isn't there to take over.
+func (e *Election) MoveRequested(ctx context.Context) <-chan struct{} {
+	moved := make(chan struct{})
+	go func() {
+		wctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		// Watch the hint, and the candidates, which are the keys under the
+		// lock file, from before they're first checked.
+		hints := e.client.Watch(wctx, e.placementKey)
+		candidates := e.client.Watch(wctx, e.lockFile+"/", clientv3.WithPrefix())
+		for {
+			if placed, err := e.placedOnCandidate(wctx); err != nil {
+				glog.Warningf("%s: %v", e.resourceID, err)
+			} else if placed != "" {
+				glog.Infof("%s: placed on %s, which is waiting to take over", e.resourceID, placed)
+				close(moved)
+				return
+			}
+			select {
+			case _, ok := <-hints:
+				if !ok {
+					return
+				}
+			case _, ok := <-candidates:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return moved
+}
+
+// placedOnCandidate returns the instance named by the placement hint of the
+// resource, if it's another instance which is campaigning for mastership.
+// Otherwise it returns an empty string.
+func (e *Election) placedOnCandidate(ctx context.Context) (string, error) {
+	placed, err := placement(ctx, e.client, e.placementKey)
+	if err != nil || placed == "" || placed == e.instanceID {
+		return "", err
+	}
+	rsp, err := e.client.Get(ctx, e.lockFile+"/", clientv3.WithPrefix())
+	if err != nil {
+		return "", fmt.Errorf("failed to read candidates: %v", err)
+	}
+	for _, kv := range rsp.Kvs {
+		if string(kv.Value) == placed {
+			return placed, nil
+		}
+	}
+	return "", nil
+}
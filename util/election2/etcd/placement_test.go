@@ -0,0 +1,100 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/testonly/integration/etcd"
+)
+
+func TestPlacement(t *testing.T) {
+	_, client, cleanup, err := etcd.StartEtcd()
+	if err != nil {
+		t.Fatalf("StartEtcd(): %v", err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	fact1 := NewFactory("serv1", client, "res/")
+	fact2 := NewFactory("serv2", client, "res/")
+	if err := fact1.Place(ctx, "10", "serv2"); err != nil {
+		t.Fatalf("Place(): %v", err)
+	}
+	if got, err := fact2.Placement(ctx, "10"); err != nil || got != "serv2" {
+		t.Fatalf("Placement(): %q, %v, want serv2", got, err)
+	}
+
+	// Other instances hold back from campaigning.
+	el1, err := fact1.NewElection(ctx, "10")
+	if err != nil {
+		t.Fatalf("NewElection(): %v", err)
+	}
+	cctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if err := el1.Await(cctx); err != context.DeadlineExceeded {
+		t.Fatalf("Await(): %v, want %v", err, context.DeadlineExceeded)
+	}
+	// But campaign after the placement delay if the named instance doesn't
+	// take over.
+	el1.(*Election).placementDelay = 100 * time.Millisecond
+	if err := el1.Await(ctx); err != nil {
+		t.Fatalf("Await(): %v", err)
+	}
+
+	// Mastership only moves once the named instance is campaigning.
+	mctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	moved := el1.(*Election).MoveRequested(mctx)
+	select {
+	case <-moved:
+		t.Fatal("MoveRequested(): closed before serv2 is campaigning")
+	case <-time.After(200 * time.Millisecond):
+	}
+	el2, err := fact2.NewElection(ctx, "10")
+	if err != nil {
+		t.Fatalf("NewElection(): %v", err)
+	}
+	awaited := make(chan error, 1)
+	go func() { awaited <- el2.Await(ctx) }()
+	select {
+	case <-moved:
+	case <-time.After(5 * time.Second):
+		t.Fatal("MoveRequested(): not closed once serv2 is campaigning")
+	}
+	if err := el1.Resign(ctx); err != nil {
+		t.Fatalf("Resign(): %v", err)
+	}
+	if err := <-awaited; err != nil {
+		t.Fatalf("Await(serv2): %v", err)
+	}
+	if got, err := el1.(*Election).Master(ctx); err != nil || got != "serv2" {
+		t.Errorf("Master(): %q, %v, want serv2", got, err)
+	}
+
+	if err := fact1.Place(ctx, "10", ""); err != nil {
+		t.Fatalf("Place(): %v", err)
+	}
+	if got, err := fact1.Placement(ctx, "10"); err != nil || got != "" {
+		t.Errorf("Placement(): %q, %v, want none", got, err)
+	}
+	for _, el := range []*Election{el1.(*Election), el2.(*Election)} {
+		if err := el.Close(ctx); err != nil {
+			t.Errorf("Close(): %v", err)
+		}
+	}
+}
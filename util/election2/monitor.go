@@ -0,0 +1,378 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package election2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/util/clock"
+)
+
+const resourceIDLabel = "resource_id"
+
+// EventType identifies a mastership state transition.
+type EventType string
+
+const (
+	// EventAcquired is emitted when the instance becomes the master.
+	EventAcquired EventType = "acquired"
+	// EventResigned is emitted when the master gives up mastership through
+	// Resign or Close.
+	EventResigned EventType = "resigned"
+	// EventLost is emitted when the master's mastership context is canceled
+	// without it resigning, e.g. because its lease expired or another instance
+	// overtook mastership.
+	EventLost EventType = "lost"
+)
+
+// Event describes a change in mastership of a resource.
+type Event struct {
+	Type       EventType
+	ResourceID string
+	Time       time.Time
+	// Tenure is how long mastership was held for. Only set for EventResigned
+	// and EventLost.
+	Tenure time.Duration
+}
+
+// String returns the event in a key=value form suitable for logging.
+func (e Event) String() string {
+	s := fmt.Sprintf("election_event=%s resource_id=%s time=%s", e.Type, e.ResourceID, e.Time.UTC().Format(time.RFC3339Nano))
+	if e.Type != EventAcquired {
+		s += fmt.Sprintf(" tenure=%s", e.Tenure)
+	}
+	return s
+}
+
+// MasterStatus describes the mastership state of a single resource.
+type MasterStatus struct {
+	ResourceID string
+	// IsMaster is whether this instance is currently the master.
+	IsMaster bool
+	// Since is when this instance became the master, if IsMaster is set.
+	Since time.Time
+	// Master is the ID of the current master instance, if the Election
+	// implements MasterReporter.
+	Master string
+	// Err is set if the current master couldn't be determined.
+	Err error
+}
+
+type monitorMetrics struct {
+	acquired     monitoring.Counter
+	resigned     monitoring.Counter
+	lost         monitoring.Counter
+	timeAsMaster monitoring.Histogram
+}
+
+func newMonitorMetrics(mf monitoring.MetricFactory) *monitorMetrics {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &monitorMetrics{
+		acquired:     mf.NewCounter("election_mastership_acquired", "Number of times mastership was acquired", resourceIDLabel),
+		resigned:     mf.NewCounter("election_mastership_resigned", "Number of times mastership was resigned", resourceIDLabel),
+		lost:         mf.NewCounter("election_mastership_lost", "Number of times mastership was lost without resigning, e.g. due to lease expiry", resourceIDLabel),
+		timeAsMaster: mf.NewHistogram("election_time_as_master", "Duration of mastership tenures in seconds", resourceIDLabel),
+	}
+}
+
+// MonitoredFactory is a Factory which decorates the Elections created by
+// another Factory with metrics and mastership events, and keeps track of their
+// state so that it can be reported through Status or over HTTP.
+//
+// There should be at most one MonitoredFactory per MetricFactory.
+type MonitoredFactory struct {
+	f       Factory
+	ts      clock.TimeSource
+	metrics *monitorMetrics
+	notify  func(Event)
+
+	mu        sync.Mutex
+	elections map[string]*monitoredElection
+	// createErr is the error returned by the last failed NewElection call, if
+	// no Election has been created successfully since.
+	createErr error
+}
+
+// NewMonitoredFactory returns a MonitoredFactory wrapping f. If notify is not
+// nil, it is called synchronously for every mastership Event, in addition to
+// the event being logged.
+func NewMonitoredFactory(f Factory, mf monitoring.MetricFactory, ts clock.TimeSource, notify func(Event)) *MonitoredFactory {
+	if ts == nil {
+		ts = clock.System
+	}
+	return &MonitoredFactory{
+		f:         f,
+		ts:        ts,
+		metrics:   newMonitorMetrics(mf),
+		notify:    notify,
+		elections: make(map[string]*monitoredElection),
+	}
+}
+
+// NewElection creates an Election for the given resource using the wrapped
+// Factory. It replaces any Election previously created for the same resource
+// in the status report.
+func (mf *MonitoredFactory) NewElection(ctx context.Context, resourceID string) (Election, error) {
+	e, err := mf.f.NewElection(ctx, resourceID)
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+	if err != nil {
+		mf.createErr = fmt.Errorf("failed to create election for %v: %v", resourceID, err)
+		return nil, err
+	}
+	mf.createErr = nil
+	me := &monitoredElection{Election: e, parent: mf, resourceID: resourceID}
+	mf.elections[resourceID] = me
+	return me, nil
+}
+
+// Place implements Placer, if the wrapped Factory does. Otherwise it returns
+// ErrPlacementUnsupported.
+func (mf *MonitoredFactory) Place(ctx context.Context, resourceID, instanceID string) error {
+	if p, ok := mf.f.(Placer); ok {
+		return p.Place(ctx, resourceID, instanceID)
+	}
+	return ErrPlacementUnsupported
+}
+
+// Placement implements Placer, if the wrapped Factory does. Otherwise it
+// returns ErrPlacementUnsupported.
+func (mf *MonitoredFactory) Placement(ctx context.Context, resourceID string) (string, error) {
+	if p, ok := mf.f.(Placer); ok {
+		return p.Placement(ctx, resourceID)
+	}
+	return "", ErrPlacementUnsupported
+}
+
+// CheckHealth implements HealthChecker. It returns an error if the last
+// attempt to create an Election failed, or if the wrapped Factory implements
+// HealthChecker and reports an error.
+func (mf *MonitoredFactory) CheckHealth(ctx context.Context) error {
+	mf.mu.Lock()
+	err := mf.createErr
+	mf.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if hc, ok := mf.f.(HealthChecker); ok {
+		return hc.CheckHealth(ctx)
+	}
+	return nil
+}
+
+// Status returns the mastership state of all resources that Elections have
+// been created for, sorted by resource ID.
+func (mf *MonitoredFactory) Status(ctx context.Context) []MasterStatus {
+	mf.mu.Lock()
+	elections := make([]*monitoredElection, 0, len(mf.elections))
+	for _, e := range mf.elections {
+		elections = append(elections, e)
+	}
+	mf.mu.Unlock()
+
+	ret := make([]MasterStatus, 0, len(elections))
+	for _, e := range elections {
+		st := e.status()
+		if mr, ok := e.Election.(MasterReporter); ok {
+			st.Master, st.Err = mr.Master(ctx)
+		}
+		ret = append(ret, st)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ResourceID < ret[j].ResourceID })
+	return ret
+}
+
+// ServeHTTP writes a plain-text table of the mastership state of every
+// resource, as returned by Status.
+func (mf *MonitoredFactory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESOURCE\tIS_MASTER\tSINCE\tCURRENT_MASTER")
+	for _, st := range mf.Status(ctx) {
+		since := "-"
+		if st.IsMaster {
+			since = st.Since.UTC().Format(time.RFC3339)
+		}
+		master := st.Master
+		switch {
+		case st.Err != nil:
+			master = fmt.Sprintf("error: %v", st.Err)
+		case master == "":
+			master = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%v\t%s\t%s\n", st.ResourceID, st.IsMaster, since, master)
+	}
+	if err := tw.Flush(); err != nil {
+		glog.Warningf("Failed to write mastership status: %v", err)
+	}
+}
+
+func (mf *MonitoredFactory) emit(ev Event) {
+	glog.Info(ev)
+	if mf.notify != nil {
+		mf.notify(ev)
+	}
+}
+
+// monitoredElection is an Election which tracks the mastership tenures of the
+// Election it wraps.
+type monitoredElection struct {
+	Election
+	parent     *MonitoredFactory
+	resourceID string
+
+	mu       sync.Mutex
+	isMaster bool
+	since    time.Time
+	// tenure is incremented every time mastership is acquired, so that a
+	// stale mastership context can't end a later tenure.
+	tenure int
+	// resigning is the tenure that is being resigned, if any.
+	resigning int
+}
+
+// Await implements Election.Await.
+func (e *monitoredElection) Await(ctx context.Context) error {
+	if err := e.Election.Await(ctx); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	if e.isMaster {
+		e.mu.Unlock()
+		return nil
+	}
+	now := e.parent.ts.Now()
+	e.isMaster, e.since = true, now
+	e.tenure++
+	e.mu.Unlock()
+
+	e.parent.metrics.acquired.Inc(e.resourceID)
+	e.parent.emit(Event{Type: EventAcquired, ResourceID: e.resourceID, Time: now})
+	return nil
+}
+
+// WithMastership implements Election.WithMastership. If the returned context
+// is canceled while this instance is master and neither ctx was canceled nor
+// mastership resigned, mastership is reported as lost.
+func (e *monitoredElection) WithMastership(ctx context.Context) (context.Context, error) {
+	mctx, err := e.Election.WithMastership(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	isMaster, tenure := e.isMaster, e.tenure
+	e.mu.Unlock()
+	if isMaster {
+		go func() {
+			<-mctx.Done()
+			if ctx.Err() != nil {
+				// The caller stopped watching, which says nothing about mastership.
+				return
+			}
+			e.end(tenure, EventLost)
+		}()
+	}
+	return mctx, nil
+}
+
+// FencingToken implements Fencer, if the wrapped Election does. Otherwise it
+// returns 0.
+func (e *monitoredElection) FencingToken() int64 {
+	if f, ok := e.Election.(Fencer); ok {
+		return f.FencingToken()
+	}
+	return 0
+}
+
+// MoveRequested implements Mover, if the wrapped Election does. Otherwise it
+// returns a channel which is never closed.
+func (e *monitoredElection) MoveRequested(ctx context.Context) <-chan struct{} {
+	if m, ok := e.Election.(Mover); ok {
+		return m.MoveRequested(ctx)
+	}
+	return nil
+}
+
+// Resign implements Election.Resign.
+func (e *monitoredElection) Resign(ctx context.Context) error {
+	return e.release(ctx, e.Election.Resign)
+}
+
+// Close implements Election.Close.
+func (e *monitoredElection) Close(ctx context.Context) error {
+	return e.release(ctx, e.Election.Close)
+}
+
+func (e *monitoredElection) release(ctx context.Context, f func(context.Context) error) error {
+	e.mu.Lock()
+	tenure := e.tenure
+	e.resigning = tenure
+	e.mu.Unlock()
+
+	err := f(ctx)
+	if err == nil {
+		e.end(tenure, EventResigned)
+	}
+
+	e.mu.Lock()
+	e.resigning = 0
+	e.mu.Unlock()
+	return err
+}
+
+// end finishes the given mastership tenure, unless it has already ended.
+func (e *monitoredElection) end(tenure int, typ EventType) {
+	e.mu.Lock()
+	if !e.isMaster || e.tenure != tenure || (typ == EventLost && e.resigning == tenure) {
+		e.mu.Unlock()
+		return
+	}
+	now := e.parent.ts.Now()
+	held := now.Sub(e.since)
+	e.isMaster = false
+	e.mu.Unlock()
+
+	m := e.parent.metrics
+	if typ == EventLost {
+		m.lost.Inc(e.resourceID)
+	} else {
+		m.resigned.Inc(e.resourceID)
+	}
+	m.timeAsMaster.Observe(held.Seconds(), e.resourceID)
+	e.parent.emit(Event{Type: typ, ResourceID: e.resourceID, Time: now, Tenure: held})
+}
+
+func (e *monitoredElection) status() MasterStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st := MasterStatus{ResourceID: e.resourceID, IsMaster: e.isMaster}
+	if e.isMaster {
+		st.Since = e.since
+	}
+	return st
+}
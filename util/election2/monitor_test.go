@@ -0,0 +1,245 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package election2_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/util/clock"
+	"github.com/google/trillian/util/election2"
+	"github.com/google/trillian/util/election2/testonly"
+)
+
+// recordingMetricFactory keeps hold of the metrics it creates, by name.
+type recordingMetricFactory struct {
+	monitoring.InertMetricFactory
+	counters   map[string]monitoring.Counter
+	histograms map[string]monitoring.Histogram
+}
+
+func (r *recordingMetricFactory) NewCounter(name, help string, labelNames ...string) monitoring.Counter {
+	c := r.InertMetricFactory.NewCounter(name, help, labelNames...)
+	r.counters[name] = c
+	return c
+}
+
+func (r *recordingMetricFactory) NewHistogram(name, help string, labelNames ...string) monitoring.Histogram {
+	h := r.InertMetricFactory.NewHistogram(name, help, labelNames...)
+	r.histograms[name] = h
+	return h
+}
+
+type singleFactory struct {
+	e election2.Election
+}
+
+func (f singleFactory) NewElection(ctx context.Context, resourceID string) (election2.Election, error) {
+	return f.e, nil
+}
+
+// flakyFactory fails to create Elections, and reports itself unhealthy, while
+// err is set.
+type flakyFactory struct {
+	err error
+}
+
+func (f *flakyFactory) NewElection(ctx context.Context, resourceID string) (election2.Election, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return testonly.Factory.NewElection(ctx, resourceID)
+}
+
+func (f *flakyFactory) CheckHealth(ctx context.Context) error {
+	return f.err
+}
+
+func TestMonitoredFactoryPassesElectionTests(t *testing.T) {
+	for _, nt := range testonly.Tests {
+		t.Run(nt.Name, func(t *testing.T) {
+			nt.Run(t, election2.NewMonitoredFactory(testonly.Factory, nil, nil, nil))
+		})
+	}
+}
+
+func TestMonitoredElection(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	ts := clock.NewFake(start)
+	mf := &recordingMetricFactory{
+		counters:   make(map[string]monitoring.Counter),
+		histograms: make(map[string]monitoring.Histogram),
+	}
+	var mu sync.Mutex
+	var events []election2.Event
+	lost := make(chan struct{})
+	notify := func(ev election2.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+		if ev.Type == election2.EventLost {
+			close(lost)
+		}
+	}
+
+	inner := testonly.NewElection()
+	fact := election2.NewMonitoredFactory(singleFactory{e: inner}, mf, ts, notify)
+	e, err := fact.NewElection(ctx, "10")
+	if err != nil {
+		t.Fatalf("NewElection(): %v", err)
+	}
+
+	// A tenure which ends with resignation.
+	if err := e.Await(ctx); err != nil {
+		t.Fatalf("Await(): %v", err)
+	}
+	if err := e.Await(ctx); err != nil { // Already master, not a new tenure.
+		t.Fatalf("Await(): %v", err)
+	}
+	if _, err := e.WithMastership(ctx); err != nil {
+		t.Fatalf("WithMastership(): %v", err)
+	}
+	if got, want := fact.Status(ctx), []election2.MasterStatus{{ResourceID: "10", IsMaster: true, Since: start}}; !cmp.Equal(got, want) {
+		t.Errorf("Status(): %v", cmp.Diff(got, want))
+	}
+	ts.Set(start.Add(10 * time.Second))
+	if err := e.Resign(ctx); err != nil {
+		t.Fatalf("Resign(): %v", err)
+	}
+
+	// A tenure which ends with the mastership being lost, e.g. to lease expiry.
+	if err := e.Await(ctx); err != nil {
+		t.Fatalf("Await(): %v", err)
+	}
+	mctx, err := e.WithMastership(ctx)
+	if err != nil {
+		t.Fatalf("WithMastership(): %v", err)
+	}
+	ts.Set(start.Add(15 * time.Second))
+	if err := inner.Resign(ctx); err != nil {
+		t.Fatalf("inner.Resign(): %v", err)
+	}
+	<-mctx.Done()
+	select {
+	case <-lost:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for lost event")
+	}
+	if got, want := fact.Status(ctx), []election2.MasterStatus{{ResourceID: "10"}}; !cmp.Equal(got, want) {
+		t.Errorf("Status(): %v", cmp.Diff(got, want))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []election2.Event{
+		{Type: election2.EventAcquired, ResourceID: "10", Time: start},
+		{Type: election2.EventResigned, ResourceID: "10", Time: start.Add(10 * time.Second), Tenure: 10 * time.Second},
+		{Type: election2.EventAcquired, ResourceID: "10", Time: start.Add(10 * time.Second)},
+		{Type: election2.EventLost, ResourceID: "10", Time: start.Add(15 * time.Second), Tenure: 5 * time.Second},
+	}
+	if diff := cmp.Diff(events, want); diff != "" {
+		t.Errorf("events diff (-got +want):\n%s", diff)
+	}
+
+	for name, want := range map[string]float64{
+		"election_mastership_acquired": 2,
+		"election_mastership_resigned": 1,
+		"election_mastership_lost":     1,
+	} {
+		if got := mf.counters[name].Value("10"); got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	if count, sum := mf.histograms["election_time_as_master"].Info("10"); count != 2 || sum != 15 {
+		t.Errorf("election_time_as_master = (%d, %v), want (2, 15)", count, sum)
+	}
+}
+
+func TestMonitoredFactoryServeHTTP(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	fact := election2.NewMonitoredFactory(testonly.Factory, nil, clock.NewFake(start), nil)
+	for _, id := range []string{"20", "10"} {
+		e, err := fact.NewElection(ctx, id)
+		if err != nil {
+			t.Fatalf("NewElection(%s): %v", id, err)
+		}
+		if id == "10" {
+			if err := e.Await(ctx); err != nil {
+				t.Fatalf("Await(): %v", err)
+			}
+		}
+	}
+
+	w := httptest.NewRecorder()
+	fact.ServeHTTP(w, httptest.NewRequest("GET", "/debug/mastership", nil))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Got %d lines, want 3:\n%s", len(lines), w.Body.String())
+	}
+	for i, want := range [][]string{
+		{"RESOURCE", "IS_MASTER", "SINCE", "CURRENT_MASTER"},
+		{"10", "true", "2021-03-01T12:00:00Z", "-"},
+		{"20", "false", "-", "-"},
+	} {
+		if got := strings.Fields(lines[i]); !cmp.Equal(got, want) {
+			t.Errorf("line %d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestMonitoredFactoryCheckHealth(t *testing.T) {
+	ctx := context.Background()
+	flaky := &flakyFactory{}
+	fact := election2.NewMonitoredFactory(flaky, nil, nil, nil)
+	if err := fact.CheckHealth(ctx); err != nil {
+		t.Errorf("CheckHealth()=%v, want nil", err)
+	}
+
+	flaky.err = errors.New("etcd unavailable")
+	if _, err := fact.NewElection(ctx, "10"); err == nil {
+		t.Fatal("NewElection() succeeded, want error")
+	}
+	// The wrapped factory recovers, but the failure to create an election
+	// is still reported until one is created.
+	flaky.err = nil
+	if err := fact.CheckHealth(ctx); err == nil || !strings.Contains(err.Error(), "etcd unavailable") {
+		t.Errorf("CheckHealth()=%v, want NewElection error", err)
+	}
+	if _, err := fact.NewElection(ctx, "10"); err != nil {
+		t.Fatalf("NewElection(): %v", err)
+	}
+	if err := fact.CheckHealth(ctx); err != nil {
+		t.Errorf("CheckHealth()=%v after NewElection succeeded, want nil", err)
+	}
+
+	flaky.err = errors.New("no quorum")
+	if err := fact.CheckHealth(ctx); err == nil || !strings.Contains(err.Error(), "no quorum") {
+		t.Errorf("CheckHealth()=%v, want wrapped factory error", err)
+	}
+
+	// Factories which can't check their health are healthy.
+	if err := election2.NewMonitoredFactory(election2.NoopFactory{}, nil, nil, nil).CheckHealth(ctx); err != nil {
+		t.Errorf("CheckHealth()=%v for NoopFactory, want nil", err)
+	}
+}
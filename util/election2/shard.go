@@ -0,0 +1,78 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package election2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+)
+
+// Membership lists the instances which resources can be sharded between, so
+// that each instance only runs elections for the resources it owns, rather
+// than for all of them.
+type Membership interface {
+	// Members returns the IDs of the instances which are currently live.
+	Members(ctx context.Context) ([]string, error)
+}
+
+// StaticMembership is a Membership with a fixed list of instances.
+type StaticMembership []string
+
+// Members returns the list of instances.
+func (m StaticMembership) Members(ctx context.Context) ([]string, error) {
+	return m, nil
+}
+
+// Owners returns the n instances among members which own the resource with
+// the given ID, in order of preference, by rendezvous hashing: each instance
+// is ranked by a hash of its ID and the resource ID, so that adding or
+// removing an instance only moves the resources it owns, or comes to own.
+func Owners(resourceID string, members []string, n int) []string {
+	type ranked struct {
+		id   string
+		rank uint64
+	}
+	ranks := make([]ranked, 0, len(members))
+	for _, id := range members {
+		ranks = append(ranks, ranked{id: id, rank: rendezvousHash(resourceID, id)})
+	}
+	sort.Slice(ranks, func(i, j int) bool {
+		if ranks[i].rank != ranks[j].rank {
+			return ranks[i].rank > ranks[j].rank
+		}
+		return ranks[i].id < ranks[j].id
+	})
+	if n > len(ranks) {
+		n = len(ranks)
+	}
+	owners := make([]string, 0, n)
+	for _, r := range ranks[:n] {
+		owners = append(owners, r.id)
+	}
+	return owners
+}
+
+// rendezvousHash returns the rank of an instance for a resource.
+func rendezvousHash(resourceID, instanceID string) uint64 {
+	h := sha256.New()
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(resourceID)))
+	h.Write(l[:])
+	h.Write([]byte(resourceID))
+	h.Write([]byte(instanceID))
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package election2
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestOwners(t *testing.T) {
+	members := []string{"a", "b", "c", "d"}
+	counts := make(map[string]int)
+	const resources = 4000
+	for i := 0; i < resources; i++ {
+		id := fmt.Sprint(i)
+		owners := Owners(id, members, 2)
+		if len(owners) != 2 || owners[0] == owners[1] {
+			t.Fatalf("Owners(%s): %v, want 2 distinct owners", id, owners)
+		}
+		counts[owners[0]]++
+
+		// The order of members doesn't matter.
+		if got := Owners(id, []string{"d", "c", "b", "a"}, 2); !reflect.DeepEqual(got, owners) {
+			t.Errorf("Owners(%s) of reordered members: %v, want %v", id, got, owners)
+		}
+		// Removing an instance only moves the resources it owned.
+		rest := Owners(id, []string{"a", "b", "c"}, 1)
+		if owners[0] != "d" && rest[0] != owners[0] {
+			t.Errorf("Owners(%s) without d: %v, want %v", id, rest, owners[0])
+		}
+		if owners[0] == "d" && rest[0] != owners[1] {
+			t.Errorf("Owners(%s) without d: %v, want the next owner %v", id, rest, owners[1])
+		}
+	}
+	for _, id := range members {
+		if got := counts[id]; got < resources/8 {
+			t.Errorf("%s owns %d of %d resources, want about a quarter", id, got, resources)
+		}
+	}
+
+	if got := Owners("1", members, 10); len(got) != len(members) {
+		t.Errorf("Owners(n=10): %v, want all members", got)
+	}
+	if got := Owners("1", nil, 1); len(got) != 0 {
+		t.Errorf("Owners(no members): %v, want none", got)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mergedelay tracks how long leaves wait between being queued to logs
+// and being integrated into them, against the maximum merge delay (MMD) which
+// each log promises to meet.
+package mergedelay
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+)
+
+// DefaultRiskFraction is the fraction of its MMD which the merge delay of a log
+// has to reach for the log to be at risk of violating the MMD, unless the
+// Policy says otherwise.
+const DefaultRiskFraction = 0.5
+
+// Policy sets the maximum merge delays of logs.
+type Policy struct {
+	// Default is the MMD of logs which have no entry in PerTree. Zero means
+	// that those logs have no MMD.
+	Default time.Duration
+	// PerTree holds the MMDs of individual logs, by tree ID. Zero means that
+	// the log has no MMD, whatever Default is.
+	PerTree map[int64]time.Duration
+	// RiskFraction is the fraction of its MMD which the merge delay of a log
+	// has to reach for the log to be at risk of violating the MMD. Zero means
+	// DefaultRiskFraction.
+	RiskFraction float64
+	// RefuseWhenAtRisk says that logs at risk of violating their MMD should
+	// stop accepting new leaves until they have caught up.
+	RefuseWhenAtRisk bool
+}
+
+// MMD returns the maximum merge delay of the tree, or zero if it has none.
+func (p Policy) MMD(treeID int64) time.Duration {
+	if mmd, ok := p.PerTree[treeID]; ok {
+		return mmd
+	}
+	return p.Default
+}
+
+// riskThreshold returns the merge delay at which the tree is at risk of
+// violating its MMD, or zero if it has none.
+func (p Policy) riskThreshold(treeID int64) time.Duration {
+	fraction := p.RiskFraction
+	if fraction <= 0 {
+		fraction = DefaultRiskFraction
+	}
+	return time.Duration(float64(p.MMD(treeID)) * fraction)
+}
+
+// ParsePerTree parses a comma-separated list of treeID=duration pairs, such
+// as "1234=24h,5678=30m", into the PerTree field of a Policy.
+func ParsePerTree(s string) (map[int64]time.Duration, error) {
+	perTree := make(map[int64]time.Duration)
+	if s == "" {
+		return perTree, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("mergedelay: %q is not a treeID=duration pair", pair)
+		}
+		treeID, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("mergedelay: bad tree ID in %q: %v", pair, err)
+		}
+		mmd, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("mergedelay: bad duration in %q: %v", pair, err)
+		}
+		if mmd < 0 {
+			return nil, fmt.Errorf("mergedelay: negative duration in %q", pair)
+		}
+		if _, ok := perTree[treeID]; ok {
+			return nil, fmt.Errorf("mergedelay: duplicate tree ID %d", treeID)
+		}
+		perTree[treeID] = mmd
+	}
+	return perTree, nil
+}
+
+// Tracker checks the merge delays of the batches of leaves integrated into
+// logs against their MMDs, and exports the results through metrics labelled
+// by tree ID:
+//   - the largest merge delay in the latest batch of each log, in seconds;
+//   - the number of leaves which were integrated later than their MMD allows;
+//   - whether each log is at risk of violating its MMD (0/1).
+//
+// A log is at risk while the largest merge delay in its latest batch is above
+// the fraction of its MMD set by the Policy. As leaves are mostly integrated
+// in the order they were queued, this means that the log is falling behind,
+// and that leaves still queued are likely to miss the MMD if that carries on.
+type Tracker struct {
+	policy     Policy
+	maxDelay   monitoring.Gauge
+	violations monitoring.Counter
+	atRisk     monitoring.Gauge
+
+	mu sync.Mutex
+	// risky holds whether each tree observed is at risk.
+	risky map[int64]bool
+}
+
+// NewTracker returns a Tracker which applies the policy, and exports the
+// largest merge delays through the maxDelay gauge, the violations through the
+// violations counter, and whether logs are at risk through the atRisk gauge.
+// All of them must have a single label, for the tree ID.
+func NewTracker(policy Policy, maxDelay monitoring.Gauge, violations monitoring.Counter, atRisk monitoring.Gauge) *Tracker {
+	return &Tracker{
+		policy:     policy,
+		maxDelay:   maxDelay,
+		violations: violations,
+		atRisk:     atRisk,
+		risky:      make(map[int64]bool),
+	}
+}
+
+// Policy returns the policy applied by the tracker.
+func (t *Tracker) Policy() Policy {
+	return t.policy
+}
+
+// Observe records the merge delays of a batch of leaves integrated into the
+// tree, which may be empty if there were no leaves to integrate. It returns
+// whether the tree is at risk of violating its MMD after the batch, and
+// whether that has changed, which it has on the first batch observed for the
+// tree. Trees without an MMD are never at risk.
+func (t *Tracker) Observe(treeID int64, delays []time.Duration) (atRisk, changed bool) {
+	mmd := t.policy.MMD(treeID)
+	if mmd <= 0 {
+		return false, false
+	}
+	label := strconv.FormatInt(treeID, 10)
+
+	var maxDelay time.Duration
+	violations := 0
+	for _, d := range delays {
+		if d > maxDelay {
+			maxDelay = d
+		}
+		if d > mmd {
+			violations++
+		}
+	}
+	t.maxDelay.Set(maxDelay.Seconds(), label)
+	if violations > 0 {
+		t.violations.Add(float64(violations), label)
+		glog.Errorf("%v: %d leaves were integrated later than the MMD of %v allows, after up to %v", treeID, violations, mmd, maxDelay)
+	}
+
+	atRisk = maxDelay >= t.policy.riskThreshold(treeID)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	wasAtRisk, known := t.risky[treeID]
+	changed = !known || atRisk != wasAtRisk
+	t.risky[treeID] = atRisk
+	if atRisk {
+		t.atRisk.Set(1, label)
+	} else {
+		t.atRisk.Set(0, label)
+	}
+	if atRisk && !wasAtRisk {
+		glog.Warningf("%v: at risk of violating the MMD of %v, leaves were integrated after up to %v", treeID, mmd, maxDelay)
+	} else if wasAtRisk && !atRisk {
+		glog.Infof("%v: no longer at risk of violating the MMD of %v", treeID, mmd)
+	}
+	return atRisk, changed
+}
+
+// AtRisk returns whether the tree was at risk of violating its MMD after the
+// latest batch observed.
+func (t *Tracker) AtRisk(treeID int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.risky[treeID]
+}
+
+// Retain forgets about all trees other than those with the given IDs, e.g.
+// because this instance is no longer master for them, so that the next batch
+// observed for them counts as a change.
+func (t *Tracker) Retain(treeIDs []int64) {
+	keep := make(map[int64]bool, len(treeIDs))
+	for _, id := range treeIDs {
+		keep[id] = true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id := range t.risky {
+		if !keep[id] {
+			delete(t.risky, id)
+			t.atRisk.Set(0, strconv.FormatInt(id, 10))
+		}
+	}
+}
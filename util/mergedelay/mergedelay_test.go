@@ -0,0 +1,107 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mergedelay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/monitoring"
+)
+
+func TestParsePerTree(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    map[int64]time.Duration
+		wantErr bool
+	}{
+		{in: "", want: map[int64]time.Duration{}},
+		{in: "1=1h", want: map[int64]time.Duration{1: time.Hour}},
+		{in: "1=24h, 2 = 30m,3=0s", want: map[int64]time.Duration{1: 24 * time.Hour, 2: 30 * time.Minute, 3: 0}},
+		{in: "1", wantErr: true},
+		{in: "x=1h", wantErr: true},
+		{in: "1=soon", wantErr: true},
+		{in: "1=-1h", wantErr: true},
+		{in: "1=1h,1=2h", wantErr: true},
+	} {
+		got, err := ParsePerTree(test.in)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("ParsePerTree(%q): %v, wantErr %v", test.in, err, test.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(got, test.want); err == nil && diff != "" {
+			t.Errorf("ParsePerTree(%q) diff (-got +want):\n%s", test.in, diff)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	mf := monitoring.InertMetricFactory{}
+	maxDelay := mf.NewGauge("max_delay", "Test only", "tree_id")
+	violations := mf.NewCounter("violations", "Test only", "tree_id")
+	atRisk := mf.NewGauge("at_risk", "Test only", "tree_id")
+	policy := Policy{
+		Default:      time.Hour,
+		PerTree:      map[int64]time.Duration{2: 0, 3: 10 * time.Minute},
+		RiskFraction: 0.5,
+	}
+	tr := NewTracker(policy, maxDelay, violations, atRisk)
+
+	type result struct{ atRisk, changed bool }
+	for _, step := range []struct {
+		desc           string
+		treeID         int64
+		delays         []time.Duration
+		want           result
+		wantMaxDelay   float64
+		wantViolations float64
+	}{
+		{desc: "first-ok", treeID: 1, delays: []time.Duration{time.Minute}, want: result{false, true}, wantMaxDelay: 60},
+		{desc: "still-ok", treeID: 1, delays: []time.Duration{10 * time.Minute, 29 * time.Minute}, want: result{false, false}, wantMaxDelay: 1740},
+		{desc: "at-risk", treeID: 1, delays: []time.Duration{time.Minute, 30 * time.Minute}, want: result{true, true}, wantMaxDelay: 1800},
+		{desc: "violation", treeID: 1, delays: []time.Duration{61 * time.Minute, 62 * time.Minute}, want: result{true, false}, wantMaxDelay: 3720, wantViolations: 2},
+		{desc: "caught-up", treeID: 1, want: result{false, true}, wantViolations: 2},
+		{desc: "no-mmd", treeID: 2, delays: []time.Duration{24 * time.Hour}},
+		{desc: "per-tree-mmd", treeID: 3, delays: []time.Duration{6 * time.Minute}, want: result{true, true}, wantMaxDelay: 360},
+	} {
+		atRisk, changed := tr.Observe(step.treeID, step.delays)
+		if got := (result{atRisk, changed}); got != step.want {
+			t.Errorf("%s: Observe()=%+v, want %+v", step.desc, got, step.want)
+		}
+		label := map[int64]string{1: "1", 2: "2", 3: "3"}[step.treeID]
+		if got := maxDelay.Value(label); got != step.wantMaxDelay {
+			t.Errorf("%s: maxDelay=%v, want %v", step.desc, got, step.wantMaxDelay)
+		}
+		if got := violations.Value(label); got != step.wantViolations {
+			t.Errorf("%s: violations=%v, want %v", step.desc, got, step.wantViolations)
+		}
+		if got := tr.AtRisk(step.treeID); got != step.want.atRisk {
+			t.Errorf("%s: AtRisk()=%v, want %v", step.desc, got, step.want.atRisk)
+		}
+	}
+
+	// Forgotten trees are no longer at risk, and their next batch is a change.
+	tr.Retain([]int64{1})
+	if tr.AtRisk(3) {
+		t.Errorf("AtRisk(3)=true after Retain, want false")
+	}
+	if got, want := atRisk.Value("3"), 0.0; got != want {
+		t.Errorf("atRisk[3]=%v, want %v", got, want)
+	}
+	if _, changed := tr.Observe(3, nil); !changed {
+		t.Errorf("Observe(3) after Retain: changed=false, want true")
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package priority orders the logs which a signer sequences in each pass by
+// the priority classes assigned to them, so that e.g. production logs are
+// sequenced ahead of best-effort ones when there aren't enough workers to
+// sequence all logs at once.
+package priority
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+)
+
+// Class is the priority class of a log. Logs in higher classes are scheduled
+// ahead of logs in lower ones.
+type Class int
+
+// The priority classes, from lowest to highest.
+const (
+	BestEffort Class = iota
+	Normal
+	High
+)
+
+var classNames = map[Class]string{
+	BestEffort: "best-effort",
+	Normal:     "normal",
+	High:       "high",
+}
+
+// String returns the name of the class, as accepted by ParseClass.
+func (c Class) String() string {
+	if name, ok := classNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Class(%d)", int(c))
+}
+
+// ParseClass returns the class with the given name.
+func ParseClass(s string) (Class, error) {
+	for c, name := range classNames {
+		if name == s {
+			return c, nil
+		}
+	}
+	return Normal, fmt.Errorf("priority: unknown class %q, want one of high, normal or best-effort", s)
+}
+
+// Policy assigns priority classes to logs.
+type Policy struct {
+	// Default is the class of logs which have no entry in PerTree. The zero
+	// value is BestEffort, so this should usually be set to Normal.
+	Default Class
+	// PerTree holds the classes of individual logs, by tree ID.
+	PerTree map[int64]Class
+	// StarveAfter is the number of passes in a row that a log can go without
+	// being started, e.g. because higher priority logs took up the workers
+	// until the pass timed out, before it is scheduled ahead of all logs
+	// which aren't starved. Zero means that logs are never promoted.
+	StarveAfter int
+}
+
+// Class returns the priority class of the tree.
+func (p Policy) Class(treeID int64) Class {
+	if c, ok := p.PerTree[treeID]; ok {
+		return c
+	}
+	return p.Default
+}
+
+// ParsePerTree parses a comma-separated list of treeID=class pairs, such as
+// "1234=high,5678=best-effort", into the PerTree field of a Policy.
+func ParsePerTree(s string) (map[int64]Class, error) {
+	perTree := make(map[int64]Class)
+	if s == "" {
+		return perTree, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("priority: %q is not a treeID=class pair", pair)
+		}
+		treeID, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("priority: bad tree ID in %q: %v", pair, err)
+		}
+		c, err := ParseClass(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := perTree[treeID]; ok {
+			return nil, fmt.Errorf("priority: duplicate tree ID %d", treeID)
+		}
+		perTree[treeID] = c
+	}
+	return perTree, nil
+}
+
+// Scheduler orders the logs of each pass by their priority classes, and keeps
+// track of the logs which weren't started in the passes they were scheduled
+// for, so that they can be promoted once they have starved for long enough.
+// It exports the delay between the start of each pass and the start of each
+// log in it through a histogram labelled by class, and the number of times
+// that logs were promoted through a counter labelled by class.
+type Scheduler struct {
+	policy   Policy
+	delay    monitoring.Histogram
+	promoted monitoring.Counter
+
+	mu sync.Mutex
+	// skipped holds the number of passes in a row that each tree was
+	// scheduled for, but not started in.
+	skipped map[int64]int
+}
+
+// NewScheduler returns a Scheduler which applies the policy, and exports the
+// scheduling delays through the delay histogram and the promotions of starved
+// logs through the promoted counter. Both of them must have a single label,
+// for the class.
+func NewScheduler(policy Policy, delay monitoring.Histogram, promoted monitoring.Counter) *Scheduler {
+	return &Scheduler{
+		policy:   policy,
+		delay:    delay,
+		promoted: promoted,
+		skipped:  make(map[int64]int),
+	}
+}
+
+// Order returns the given tree IDs in the order that their passes should be
+// started: starved trees first, then by class from high to best-effort,
+// otherwise keeping the order they were given in. Each of the trees counts as
+// skipped until Started is called for it.
+func (s *Scheduler) Order(treeIDs []int64) []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	starved := make(map[int64]bool)
+	for _, id := range treeIDs {
+		if s.policy.StarveAfter > 0 && s.skipped[id] >= s.policy.StarveAfter {
+			starved[id] = true
+			c := s.policy.Class(id)
+			s.promoted.Inc(c.String())
+			glog.Warningf("%v: not sequenced for %d passes, scheduling ahead of %s logs", id, s.skipped[id], c)
+		}
+		s.skipped[id]++
+	}
+
+	ordered := make([]int64, len(treeIDs))
+	copy(ordered, treeIDs)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if starved[a] != starved[b] {
+			return starved[a]
+		}
+		return s.policy.Class(a) > s.policy.Class(b)
+	})
+	return ordered
+}
+
+// Started records that the pass for the tree was started, the given delay
+// after the pass over all trees was.
+func (s *Scheduler) Started(treeID int64, delay time.Duration) {
+	s.delay.Observe(delay.Seconds(), s.policy.Class(treeID).String())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.skipped, treeID)
+}
+
+// Retain forgets about all trees other than those with the given IDs, e.g.
+// because this instance is no longer master for them.
+func (s *Scheduler) Retain(treeIDs []int64) {
+	keep := make(map[int64]bool, len(treeIDs))
+	for _, id := range treeIDs {
+		keep[id] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := range s.skipped {
+		if !keep[id] {
+			delete(s.skipped, id)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package priority
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/monitoring"
+)
+
+func TestParseClass(t *testing.T) {
+	for _, c := range []Class{BestEffort, Normal, High} {
+		got, err := ParseClass(c.String())
+		if err != nil || got != c {
+			t.Errorf("ParseClass(%q): %v, %v, want %v, nil", c.String(), got, err, c)
+		}
+	}
+	if _, err := ParseClass("urgent"); err == nil {
+		t.Error("ParseClass(urgent): nil error, want error")
+	}
+}
+
+func TestParsePerTree(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    map[int64]Class
+		wantErr bool
+	}{
+		{in: "", want: map[int64]Class{}},
+		{in: "1=high", want: map[int64]Class{1: High}},
+		{in: "1=high, 2 = best-effort,3=normal", want: map[int64]Class{1: High, 2: BestEffort, 3: Normal}},
+		{in: "1", wantErr: true},
+		{in: "x=high", wantErr: true},
+		{in: "1=urgent", wantErr: true},
+		{in: "1=high,1=normal", wantErr: true},
+	} {
+		got, err := ParsePerTree(test.in)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("ParsePerTree(%q): %v, wantErr %v", test.in, err, test.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(got, test.want); err == nil && diff != "" {
+			t.Errorf("ParsePerTree(%q) diff (-got +want):\n%s", test.in, diff)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	mf := monitoring.InertMetricFactory{}
+	delay := mf.NewHistogram("delay", "Test only", "class")
+	promoted := mf.NewCounter("promoted", "Test only", "class")
+	policy := Policy{
+		Default:     Normal,
+		PerTree:     map[int64]Class{1: BestEffort, 2: High, 5: High},
+		StarveAfter: 2,
+	}
+	s := NewScheduler(policy, delay, promoted)
+	ids := []int64{1, 2, 3, 4, 5}
+
+	for i, want := range [][]int64{
+		{2, 5, 3, 4, 1},
+		{2, 5, 3, 4, 1},
+		// Tree 1 hasn't been started for two passes, so it is promoted.
+		{1, 2, 5, 3, 4},
+		// Once started, it goes back to its class.
+		{2, 5, 3, 4, 1},
+	} {
+		got := s.Order(ids)
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Fatalf("Order() in pass %d diff (-got +want):\n%s", i, diff)
+		}
+		// Only the first 4 trees get started in each pass.
+		for _, id := range got[:4] {
+			s.Started(id, time.Second)
+		}
+	}
+	if got, want := promoted.Value(BestEffort.String()), 1.0; got != want {
+		t.Errorf("promotions of best-effort trees: %v, want %v", got, want)
+	}
+	if count, sum := delay.Info(High.String()); count != 8 || sum != 8 {
+		t.Errorf("delays of high trees: count %d, sum %v, want 8, 8", count, sum)
+	}
+
+	// Forgotten trees start counting from scratch.
+	s.Order(ids)
+	s.Retain([]int64{2, 3, 4, 5})
+	if got, want := s.Order(ids), []int64{2, 5, 3, 4, 1}; !cmp.Equal(got, want) {
+		t.Errorf("Order() after Retain: %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package requestid carries request IDs through contexts, so that the log
+// lines and errors produced while serving a request can be correlated.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+)
+
+// MetadataKey is the gRPC metadata key which request IDs are read from and
+// returned in.
+const MetadataKey = "x-request-id"
+
+// maxLen is the maximum length of an acceptable request ID.
+const maxLen = 128
+
+type contextKey struct{}
+
+// New returns a new random request ID.
+func New() string {
+	var b [16]byte
+	if _, err := io.ReadFull(rand.Reader, b[:]); err != nil {
+		// crypto/rand doesn't fail in practice, and an ID is better than none.
+		return "unknown"
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// NewContext returns a copy of ctx carrying the given request ID.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or an empty string if
+// there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Prefix returns a log line prefix naming the request ID carried by ctx, or an
+// empty string if there is none.
+func Prefix(ctx context.Context) string {
+	if id := FromContext(ctx); id != "" {
+		return "request_id=" + id + ": "
+	}
+	return ""
+}
+
+// IsValid returns whether id is acceptable as a request ID supplied by a
+// client. Valid IDs are non-empty, reasonably short and made of characters
+// which can't garble log lines.
+func IsValid(id string) bool {
+	if len(id) == 0 || len(id) > maxLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
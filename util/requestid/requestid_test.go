@@ -0,0 +1,71 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	a, b := New(), New()
+	if a == b {
+		t.Errorf("New() returned %q twice", a)
+	}
+	for _, id := range []string{a, b} {
+		if !IsValid(id) {
+			t.Errorf("IsValid(%q) = false, want true", id)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	if got := FromContext(ctx); got != "" {
+		t.Errorf("FromContext(empty) = %q, want none", got)
+	}
+	if got := Prefix(ctx); got != "" {
+		t.Errorf("Prefix(empty) = %q, want none", got)
+	}
+
+	ctx = NewContext(ctx, "abc")
+	if got, want := FromContext(ctx), "abc"; got != want {
+		t.Errorf("FromContext() = %q, want %q", got, want)
+	}
+	if got, want := Prefix(ctx), "request_id=abc: "; got != want {
+		t.Errorf("Prefix() = %q, want %q", got, want)
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	for _, test := range []struct {
+		id   string
+		want bool
+	}{
+		{id: "0af7651916cd43dd8448eb211c80319c", want: true},
+		{id: "req-1_2.3:4", want: true},
+		{id: strings.Repeat("a", maxLen), want: true},
+		{id: ""},
+		{id: strings.Repeat("a", maxLen+1)},
+		{id: "two words"},
+		{id: "line\nbreak"},
+		{id: "quote'"},
+	} {
+		if got := IsValid(test.id); got != test.want {
+			t.Errorf("IsValid(%q) = %v, want %v", test.id, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rootage tracks how old the latest signed roots of trees are, so that
+// trees whose roots have stopped being updated can be detected.
+package rootage
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/util/clock"
+)
+
+// Tracker keeps the timestamps of the latest roots of a set of trees, and
+// exports their ages through a gauge labelled by tree ID. The ages are
+// recomputed by Update, so they keep growing while no newer roots are
+// observed.
+//
+// A tree can also be given a maximum age. A tree whose root is older than
+// that, plus a grace period, is overdue: this is logged, and reported through
+// a second gauge which is 1 while the tree is overdue and 0 otherwise.
+type Tracker struct {
+	age     monitoring.Gauge
+	overdue monitoring.Gauge
+	grace   time.Duration
+	ts      clock.TimeSource
+
+	mu    sync.Mutex
+	trees map[int64]*treeState
+}
+
+type treeState struct {
+	rootTime time.Time
+	maxAge   time.Duration
+	overdue  bool
+}
+
+// NewTracker returns a Tracker which exports root ages, in seconds, through
+// the age gauge, and whether trees are overdue through the overdue gauge,
+// which may be nil. Both gauges must have a single label, for the tree ID.
+func NewTracker(age, overdue monitoring.Gauge, grace time.Duration, ts clock.TimeSource) *Tracker {
+	return &Tracker{
+		age:     age,
+		overdue: overdue,
+		grace:   grace,
+		ts:      ts,
+		trees:   make(map[int64]*treeState),
+	}
+}
+
+// Observe records that the tree has a root with the given timestamp, which
+// should be no older than maxAge. Zero means that there is no maximum age.
+// Roots older than one already observed for the tree are ignored.
+func (t *Tracker) Observe(treeID int64, rootTime time.Time, maxAge time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.trees[treeID]
+	if s == nil {
+		s = &treeState{}
+		t.trees[treeID] = s
+	}
+	if rootTime.After(s.rootTime) {
+		s.rootTime = rootTime
+	}
+	s.maxAge = maxAge
+}
+
+// Retain stops tracking all trees other than those listed. The ages of trees
+// which are no longer tracked are reset to 0, and they are no longer overdue.
+func (t *Tracker) Retain(treeIDs []int64) {
+	keep := make(map[int64]bool)
+	for _, id := range treeIDs {
+		keep[id] = true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, s := range t.trees {
+		if keep[id] {
+			continue
+		}
+		label := strconv.FormatInt(id, 10)
+		t.age.Set(0, label)
+		if s.overdue {
+			glog.Infof("%v: no longer tracking root age, was overdue", id)
+		}
+		if t.overdue != nil && s.maxAge > 0 {
+			t.overdue.Set(0, label)
+		}
+		delete(t.trees, id)
+	}
+}
+
+// Update recomputes the ages of the tracked trees' roots, and returns the IDs
+// of the trees which are overdue, sorted.
+func (t *Tracker) Update() []int64 {
+	now := t.ts.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var overdue []int64
+	for id, s := range t.trees {
+		label := strconv.FormatInt(id, 10)
+		age := now.Sub(s.rootTime)
+		t.age.Set(age.Seconds(), label)
+		if s.maxAge <= 0 {
+			continue
+		}
+
+		isOverdue := age > s.maxAge+t.grace
+		switch {
+		case isOverdue && !s.overdue:
+			glog.Warningf("%v: latest root is %v old, exceeding the max root duration of %v", id, age, s.maxAge)
+		case !isOverdue && s.overdue:
+			glog.Infof("%v: latest root is %v old, no longer overdue", id, age)
+		}
+		s.overdue = isOverdue
+		if isOverdue {
+			overdue = append(overdue, id)
+		}
+		if t.overdue != nil {
+			val := 0.0
+			if isOverdue {
+				val = 1.0
+			}
+			t.overdue.Set(val, label)
+		}
+	}
+	sort.Slice(overdue, func(i, j int) bool { return overdue[i] < overdue[j] })
+	return overdue
+}
+
+// Run calls Update every interval until ctx is done.
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		t.Update()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rootage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/util/clock"
+)
+
+func TestTracker(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := clock.NewFake(start)
+	mf := monitoring.InertMetricFactory{}
+	age := mf.NewGauge("age", "Test only", "tree_id")
+	overdue := mf.NewGauge("overdue", "Test only", "tree_id")
+	tr := NewTracker(age, overdue, time.Minute, ts)
+
+	tr.Observe(1, start.Add(-time.Hour), time.Hour)
+	tr.Observe(2, start.Add(-time.Hour), 0)
+	tr.Observe(3, start.Add(-10*time.Minute), time.Hour)
+	// Older roots don't replace newer ones.
+	tr.Observe(3, start.Add(-20*time.Minute), time.Hour)
+
+	if got := tr.Update(); len(got) != 0 {
+		t.Errorf("Update()=%v, want no overdue trees within the grace period", got)
+	}
+	for _, test := range []struct {
+		label string
+		want  float64
+	}{
+		{label: "1", want: 3600},
+		{label: "2", want: 3600},
+		{label: "3", want: 600},
+	} {
+		if got := age.Value(test.label); got != test.want {
+			t.Errorf("age[%v]=%v, want %v", test.label, got, test.want)
+		}
+	}
+
+	// Ages keep growing without new roots, and tree 1 exceeds its maximum age
+	// plus the grace period. Tree 2 has no maximum age.
+	ts.Set(start.Add(2 * time.Minute))
+	if got, want := tr.Update(), []int64{1}; !cmp.Equal(got, want) {
+		t.Errorf("Update()=%v, want %v", got, want)
+	}
+	if got, want := age.Value("1"), 3720.0; got != want {
+		t.Errorf("age[1]=%v, want %v", got, want)
+	}
+	if got, want := overdue.Value("1"), 1.0; got != want {
+		t.Errorf("overdue[1]=%v, want %v", got, want)
+	}
+	if got, want := overdue.Value("3"), 0.0; got != want {
+		t.Errorf("overdue[3]=%v, want %v", got, want)
+	}
+
+	// A new root brings tree 1 back within its maximum age.
+	tr.Observe(1, start.Add(time.Minute), time.Hour)
+	if got := tr.Update(); len(got) != 0 {
+		t.Errorf("Update()=%v, want no overdue trees", got)
+	}
+	if got, want := overdue.Value("1"), 0.0; got != want {
+		t.Errorf("overdue[1]=%v, want %v", got, want)
+	}
+
+	// Trees which aren't retained stop being reported.
+	tr.Retain([]int64{3})
+	tr.Update()
+	if got, want := age.Value("1"), 0.0; got != want {
+		t.Errorf("age[1]=%v after Retain, want %v", got, want)
+	}
+	if got, want := age.Value("3"), 720.0; got != want {
+		t.Errorf("age[3]=%v after Retain, want %v", got, want)
+	}
+}
+
+func TestTrackerWithoutOverdueGauge(t *testing.T) {
+	ts := clock.NewFake(time.Unix(1000, 0))
+	age := monitoring.InertMetricFactory{}.NewGauge("age", "Test only", "tree_id")
+	tr := NewTracker(age, nil, 0, ts)
+	tr.Observe(1, time.Unix(0, 0), time.Second)
+	if got, want := tr.Update(), []int64{1}; !cmp.Equal(got, want) {
+		t.Errorf("Update()=%v, want %v", got, want)
+	}
+	tr.Retain(nil)
+	if got := tr.Update(); len(got) != 0 {
+		t.Errorf("Update()=%v after Retain(nil), want none", got)
+	}
+}
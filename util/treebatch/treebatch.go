@@ -0,0 +1,99 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package treebatch runs background work, such as deleting old data or
+// applying queued writes, on each of a set of trees in batches, each of which
+// is typically done in its own storage transaction.
+package treebatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+)
+
+// ErrUnsupported is returned by the work on a tree whose storage doesn't
+// support it. It ends the work on the tree without failing it.
+var ErrUnsupported = errors.New("not supported by storage")
+
+// StartFunc prepares the work on a tree, and returns the BatchFunc which runs
+// it.
+type StartFunc func(ctx context.Context, treeID int64) (BatchFunc, error)
+
+// BatchFunc runs a batch of the work on a tree, and returns the number of
+// items it processed.
+type BatchFunc func() (int, error)
+
+// Runner runs work on trees in batches.
+type Runner struct {
+	// Name describes the work done on each tree in errors, e.g. "vacuum log".
+	Name string
+	// BatchSize is the maximum number of items processed in each batch.
+	BatchSize int
+	// MaxBatches is the maximum number of batches run on each tree by each
+	// call to Run, so that a tree with a large backlog doesn't hold up the
+	// others. Zero means no limit.
+	MaxBatches int
+	// Failures, if set, counts the trees whose work failed, by tree ID.
+	Failures monitoring.Counter
+}
+
+// Run runs the work on each of the trees once, and returns the total number
+// of items processed. For each tree, start is called to prepare the work, and
+// the BatchFunc it returns is then called until it processes fewer than
+// BatchSize items, MaxBatches have been run, or ctx is done. Run carries on
+// past failures, and returns the first one.
+func (r *Runner) Run(ctx context.Context, treeIDs []int64, start StartFunc) (int, error) {
+	total := 0
+	var firstErr error
+	for _, treeID := range treeIDs {
+		n, err := r.runTree(ctx, treeID, start)
+		total += n
+		if err == ErrUnsupported {
+			glog.V(1).Infof("%v: can't %s: %v", treeID, r.Name, err)
+		} else if err != nil {
+			if r.Failures != nil {
+				r.Failures.Inc(strconv.FormatInt(treeID, 10))
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to %s %v: %v", r.Name, treeID, err)
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return total, firstErr
+}
+
+// runTree runs the batches of the work on a tree.
+func (r *Runner) runTree(ctx context.Context, treeID int64, start StartFunc) (int, error) {
+	batch, err := start(ctx, treeID)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for batches := 0; r.MaxBatches <= 0 || batches < r.MaxBatches; batches++ {
+		n, err := batch()
+		total += n
+		if err != nil || n < r.BatchSize || ctx.Err() != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
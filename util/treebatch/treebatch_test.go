@@ -0,0 +1,113 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package treebatch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+
+	for _, test := range []struct {
+		desc       string
+		maxBatches int
+		// backlog is the number of items to process of each tree, or -1 if
+		// its storage doesn't support the work.
+		backlog     map[int64]int
+		failStart   int64
+		failBatch   int64
+		want        int
+		wantBatches map[int64]int
+		wantErr     string
+	}{
+		{
+			desc:        "drained",
+			backlog:     map[int64]int{1: 25, 2: 0, 3: 10},
+			want:        35,
+			wantBatches: map[int64]int{1: 3, 2: 1, 3: 2},
+		},
+		{
+			desc:        "max-batches",
+			maxBatches:  2,
+			backlog:     map[int64]int{1: 25, 2: 0, 3: 10},
+			want:        30,
+			wantBatches: map[int64]int{1: 2, 2: 1, 3: 2},
+		},
+		{
+			desc:        "unsupported",
+			backlog:     map[int64]int{1: -1, 2: 0, 3: 10},
+			want:        10,
+			wantBatches: map[int64]int{1: 1, 2: 1, 3: 2},
+		},
+		{
+			desc:        "failures",
+			backlog:     map[int64]int{1: 25, 2: 5, 3: 10},
+			failStart:   1,
+			failBatch:   2,
+			want:        10,
+			wantBatches: map[int64]int{2: 1, 3: 2},
+			wantErr:     "failed to do work 1: boom",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			backlog := make(map[int64]int)
+			for id, n := range test.backlog {
+				backlog[id] = n
+			}
+			batches := make(map[int64]int)
+			start := func(ctx context.Context, treeID int64) (BatchFunc, error) {
+				if treeID == test.failStart {
+					return nil, errBoom
+				}
+				return func() (int, error) {
+					batches[treeID]++
+					switch {
+					case backlog[treeID] < 0:
+						return 0, ErrUnsupported
+					case treeID == test.failBatch:
+						return 0, errBoom
+					}
+					n := backlog[treeID]
+					if n > 10 {
+						n = 10
+					}
+					backlog[treeID] -= n
+					return n, nil
+				}, nil
+			}
+
+			r := &Runner{Name: "do work", BatchSize: 10, MaxBatches: test.maxBatches}
+			got, err := r.Run(ctx, []int64{1, 2, 3}, start)
+			if gotErr, wantErr := err != nil, test.wantErr != ""; gotErr != wantErr {
+				t.Fatalf("Run(): %v, want err: %v", err, wantErr)
+			} else if gotErr && !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("Run(): %v, want err containing %q", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("Run(): %d, want %d", got, test.want)
+			}
+			if diff := cmp.Diff(test.wantBatches, batches); diff != "" {
+				t.Errorf("batches run diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}